@@ -0,0 +1,151 @@
+// Package ack loads acknowledgements: problems that were triaged and
+// accepted, optionally snoozed until a date. Acknowledged problems stay in
+// reports but are listed apart from active ones, so shared reports do not
+// re-open issues that were already decided.
+package ack
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Acknowledgement accepts the problems matching its selector. Namespace and
+// Pod are glob patterns ("*" and "?"); empty Pod, Container, or Type match
+// any value.
+type Acknowledgement struct {
+	Namespace string     `yaml:"namespace"`
+	Pod       string     `yaml:"pod,omitempty"`
+	Container string     `yaml:"container,omitempty"`
+	Type      string     `yaml:"type,omitempty"` // problem type, e.g. CrashLoopBackOff
+	Reason    string     `yaml:"reason"`         // why the problem is accepted
+	By        string     `yaml:"by,omitempty"`
+	Until     *time.Time `yaml:"until,omitempty"` // snooze expiry; nil = until removed
+}
+
+// List is an acknowledgements file.
+type List struct {
+	Acknowledgements []Acknowledgement `yaml:"acknowledgements"`
+}
+
+// Problem is an acknowledged problem as reported in exports and prompts.
+type Problem struct {
+	Namespace string     `json:"namespace"`
+	Pod       string     `json:"pod"`
+	Container string     `json:"container,omitempty"`
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason"`
+	By        string     `json:"by,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// Load reads and validates an acknowledgements file.
+func Load(file string) (*List, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read acknowledgements file: %w", err)
+	}
+	var list List
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledgements file %s: %w", file, err)
+	}
+	for i := range list.Acknowledgements {
+		if err := list.Acknowledgements[i].validate(); err != nil {
+			return nil, fmt.Errorf("acknowledgement %d in %s: %w", i+1, file, err)
+		}
+	}
+	return &list, nil
+}
+
+func (a *Acknowledgement) validate() error {
+	if a.Namespace == "" {
+		return fmt.Errorf("namespace is required (use \"*\" for all namespaces)")
+	}
+	if strings.TrimSpace(a.Reason) == "" {
+		return fmt.Errorf("reason is required")
+	}
+	for _, pattern := range []string{a.Namespace, a.Pod} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Expired reports whether the snooze has run out at now.
+func (a *Acknowledgement) Expired(now time.Time) bool {
+	return a.Until != nil && !now.Before(*a.Until)
+}
+
+// Matches reports whether the acknowledgement covers a problem, ignoring
+// expiry.
+func (a *Acknowledgement) Matches(namespace, pod, container, problemType string) bool {
+	if ok, _ := path.Match(a.Namespace, namespace); !ok {
+		return false
+	}
+	if a.Pod != "" {
+		if ok, _ := path.Match(a.Pod, pod); !ok {
+			return false
+		}
+	}
+	if a.Container != "" && a.Container != container {
+		return false
+	}
+	return a.Type == "" || strings.EqualFold(a.Type, problemType)
+}
+
+// Match returns the first unexpired acknowledgement covering a problem, or
+// nil. A nil list matches nothing.
+func (l *List) Match(namespace, pod, container, problemType string, now time.Time) *Acknowledgement {
+	if l == nil {
+		return nil
+	}
+	for i := range l.Acknowledgements {
+		a := &l.Acknowledgements[i]
+		if !a.Expired(now) && a.Matches(namespace, pod, container, problemType) {
+			return a
+		}
+	}
+	return nil
+}
+
+// Problem describes a problem accepted by this acknowledgement.
+func (a *Acknowledgement) Problem(namespace, pod, container, problemType string) Problem {
+	return Problem{
+		Namespace: namespace,
+		Pod:       pod,
+		Container: container,
+		Type:      problemType,
+		Reason:    a.Reason,
+		By:        a.By,
+		Until:     a.Until,
+	}
+}
+
+// Location formats where the problem occurs: namespace/pod[/container].
+func (p *Problem) Location() string {
+	loc := p.Namespace + "/" + p.Pod
+	if p.Container != "" {
+		loc += "/" + p.Container
+	}
+	return loc
+}
+
+// Note formats the acceptance: the reason, who accepted it, and until when.
+func (p *Problem) Note() string {
+	var extra []string
+	if p.By != "" {
+		extra = append(extra, "by "+p.By)
+	}
+	if p.Until != nil {
+		extra = append(extra, "until "+p.Until.Format("2006-01-02"))
+	}
+	if len(extra) == 0 {
+		return p.Reason
+	}
+	return fmt.Sprintf("%s (%s)", p.Reason, strings.Join(extra, ", "))
+}
@@ -0,0 +1,112 @@
+package ack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAcks(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "acks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	file := writeAcks(t, `
+acknowledgements:
+  - namespace: batch
+    pod: "report-*"
+    type: CrashLoopBackOff
+    reason: nightly report flakes, fix scheduled
+    by: data-team
+    until: 2026-11-01
+  - namespace: "*"
+    container: istio-proxy
+    reason: sidecar restarts during mesh upgrade
+`)
+	list, err := Load(file)
+	require.NoError(t, err)
+	require.Len(t, list.Acknowledgements, 2)
+	require.NotNil(t, list.Acknowledgements[0].Until)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), *list.Acknowledgements[0].Until)
+	assert.Nil(t, list.Acknowledgements[1].Until)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"missing namespace", "acknowledgements:\n  - reason: x\n", "namespace is required"},
+		{"missing reason", "acknowledgements:\n  - namespace: prod\n", "reason is required"},
+		{"bad glob", "acknowledgements:\n  - namespace: prod\n    pod: \"[\"\n    reason: x\n", "invalid pattern"},
+		{"bad yaml", "acknowledgements: [", "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeAcks(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	until := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	list := &List{Acknowledgements: []Acknowledgement{
+		{Namespace: "batch", Pod: "report-*", Type: "CrashLoopBackOff", Reason: "flaky", Until: &until},
+		{Namespace: "*", Container: "istio-proxy", Reason: "mesh upgrade"},
+	}}
+	before := until.Add(-time.Hour)
+
+	tests := []struct {
+		name                            string
+		namespace, pod, container, kind string
+		now                             time.Time
+		wantReason                      string
+	}{
+		{"glob and type match", "batch", "report-29", "app", "CrashLoopBackOff", before, "flaky"},
+		{"type is case-insensitive", "batch", "report-29", "", "crashloopbackoff", before, "flaky"},
+		{"other type", "batch", "report-29", "app", "OOMKilled", before, ""},
+		{"other pod", "batch", "ingest-1", "app", "CrashLoopBackOff", before, ""},
+		{"snooze expired", "batch", "report-29", "app", "CrashLoopBackOff", until, ""},
+		{"container in any namespace", "prod", "api-1", "istio-proxy", "OOMKilled", before, "mesh upgrade"},
+		{"pod-level problem skips container ack", "prod", "api-1", "", "OOMKilled", before, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := list.Match(tt.namespace, tt.pod, tt.container, tt.kind, tt.now)
+			if tt.wantReason == "" {
+				assert.Nil(t, a)
+				return
+			}
+			require.NotNil(t, a)
+			assert.Equal(t, tt.wantReason, a.Reason)
+		})
+	}
+
+	var nilList *List
+	assert.Nil(t, nilList.Match("prod", "api", "", "OOMKilled", before))
+}
+
+func TestProblemNote(t *testing.T) {
+	until := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	a := Acknowledgement{Namespace: "batch", Reason: "flaky", By: "data-team", Until: &until}
+	p := a.Problem("batch", "report-29", "app", "CrashLoopBackOff")
+	assert.Equal(t, "batch/report-29/app", p.Location())
+	assert.Equal(t, "flaky (by data-team, until 2026-11-01)", p.Note())
+
+	p = Problem{Namespace: "prod", Pod: "api-1", Reason: "known"}
+	assert.Equal(t, "prod/api-1", p.Location())
+	assert.Equal(t, "known", p.Note())
+}
@@ -0,0 +1,207 @@
+// Package agent runs latch sampling continuously inside the cluster and
+// serves the latest window of each workload's latch data over HTTP, so long
+// latches do not need a local session.
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// Defaults for the agent's sampling.
+const (
+	DefaultInterval = 5 * time.Second
+	DefaultWindow   = time.Hour
+)
+
+// Config controls the agent's sampling.
+type Config struct {
+	Interval   time.Duration // sample interval (1-5s)
+	Window     time.Duration // length of one latch window
+	Namespaces []string      // namespaces to sample (empty = all but kube-system)
+	Log        *slog.Logger  // nil = the default logger as the "agent" component
+}
+
+// WorkloadLatch is the latch data of one workload over one window.
+type WorkloadLatch struct {
+	Namespace   string             `json:"namespace"`
+	Workload    string             `json:"workload"`
+	Complete    bool               `json:"complete"` // false while the window is still being sampled
+	WindowStart time.Time          `json:"window_start"`
+	WindowEnd   time.Time          `json:"window_end"`
+	Interval    time.Duration      `json:"interval"`
+	Data        *metrics.SpikeData `json:"data"`
+}
+
+// Duration returns how long the window sampled.
+func (w *WorkloadLatch) Duration() time.Duration {
+	return w.WindowEnd.Sub(w.WindowStart)
+}
+
+// WorkloadSummary lists a workload the agent has samples for.
+type WorkloadSummary struct {
+	Namespace string    `json:"namespace"`
+	Workload  string    `json:"workload"`
+	Samples   int       `json:"samples"`
+	Complete  bool      `json:"complete"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// sampler is the part of metrics.LatchMonitor the agent drives.
+type sampler interface {
+	Start(ctx context.Context) error
+	GetSpikeData() map[string]*metrics.SpikeData
+}
+
+// Agent samples back-to-back latch windows and keeps the last complete
+// window of every workload next to the one in progress.
+type Agent struct {
+	config     Config
+	newSampler func(metrics.LatchConfig) (sampler, error)
+
+	mu           sync.RWMutex
+	current      sampler
+	currentStart time.Time
+	completed    map[string]*WorkloadLatch // key: namespace/workload
+}
+
+// New creates an agent whose windows are sampled by latch monitors built
+// with newMonitor.
+func New(config Config, newMonitor func(metrics.LatchConfig) (*metrics.LatchMonitor, error)) *Agent {
+	a := newAgent(config)
+	a.newSampler = func(c metrics.LatchConfig) (sampler, error) {
+		return newMonitor(c)
+	}
+	return a
+}
+
+func newAgent(config Config) *Agent {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	if config.Log == nil {
+		config.Log = logging.Component("agent")
+	}
+	return &Agent{config: config, completed: make(map[string]*WorkloadLatch)}
+}
+
+// Run samples windows until ctx is canceled.
+func (a *Agent) Run(ctx context.Context) error {
+	for {
+		mon, err := a.newSampler(metrics.LatchConfig{
+			SampleInterval: a.config.Interval,
+			Duration:       a.config.Window,
+			Namespaces:     a.config.Namespaces,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create latch monitor: %w", err)
+		}
+
+		start := time.Now()
+		a.mu.Lock()
+		a.current = mon
+		a.currentStart = start
+		a.mu.Unlock()
+
+		if err := mon.Start(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("latch window failed: %w", err)
+		}
+		a.finishWindow(mon, start, time.Now())
+	}
+}
+
+// finishWindow replaces the completed windows with the one just sampled.
+func (a *Agent) finishWindow(mon sampler, start, end time.Time) {
+	completed := make(map[string]*WorkloadLatch)
+	for key, data := range mon.GetSpikeData() {
+		completed[key] = a.workloadLatch(data, true, start, end)
+	}
+
+	a.mu.Lock()
+	a.completed = completed
+	a.mu.Unlock()
+	a.config.Log.Info("Window complete", "workloads", len(completed), "duration", end.Sub(start).Truncate(time.Second))
+}
+
+func (a *Agent) workloadLatch(data *metrics.SpikeData, complete bool, start, end time.Time) *WorkloadLatch {
+	return &WorkloadLatch{
+		Namespace:   data.Namespace,
+		Workload:    data.WorkloadName,
+		Complete:    complete,
+		WindowStart: start,
+		WindowEnd:   end,
+		Interval:    a.config.Interval,
+		Data:        data,
+	}
+}
+
+// Latch returns a workload's last complete window. With partial set, or
+// before its first window completes, the window in progress is returned.
+func (a *Agent) Latch(namespace, workload string, partial bool) (*WorkloadLatch, bool) {
+	key := namespace + "/" + workload
+
+	a.mu.RLock()
+	completed := a.completed[key]
+	current, currentStart := a.current, a.currentStart
+	a.mu.RUnlock()
+
+	if completed != nil && !partial {
+		return completed, true
+	}
+	if current != nil {
+		if data := current.GetSpikeData()[key]; data != nil {
+			return a.workloadLatch(data, false, currentStart, data.LastSeen), true
+		}
+	}
+	return completed, completed != nil
+}
+
+// Workloads lists the workloads with samples, sorted by namespace and
+// name. A workload with a complete window is reported from that window.
+func (a *Agent) Workloads() []WorkloadSummary {
+	a.mu.RLock()
+	completed := a.completed
+	current := a.current
+	a.mu.RUnlock()
+
+	byKey := make(map[string]WorkloadSummary)
+	if current != nil {
+		for key, data := range current.GetSpikeData() {
+			byKey[key] = WorkloadSummary{
+				Namespace: data.Namespace, Workload: data.WorkloadName,
+				Samples: data.SampleCount, LastSeen: data.LastSeen,
+			}
+		}
+	}
+	for key, w := range completed {
+		byKey[key] = WorkloadSummary{
+			Namespace: w.Namespace, Workload: w.Workload,
+			Samples: w.Data.SampleCount, Complete: true, LastSeen: w.Data.LastSeen,
+		}
+	}
+
+	result := make([]WorkloadSummary, 0, len(byKey))
+	for _, s := range byKey {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Workload < result[j].Workload
+	})
+	return result
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+type fakeSampler struct {
+	data map[string]*metrics.SpikeData
+}
+
+func (f *fakeSampler) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeSampler) GetSpikeData() map[string]*metrics.SpikeData {
+	return f.data
+}
+
+func spikeData(namespace, workload string, samples int, lastSeen time.Time) *metrics.SpikeData {
+	return &metrics.SpikeData{
+		Namespace:    namespace,
+		WorkloadName: workload,
+		SampleCount:  samples,
+		LastSeen:     lastSeen,
+	}
+}
+
+func TestAgent_LatchCompleteAndPartial(t *testing.T) {
+	a := newAgent(Config{Log: slog.New(slog.DiscardHandler)})
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	a.finishWindow(&fakeSampler{data: map[string]*metrics.SpikeData{
+		"prod/api": spikeData("prod", "api", 720, end),
+	}}, start, end)
+	a.current = &fakeSampler{data: map[string]*metrics.SpikeData{
+		"prod/api":    spikeData("prod", "api", 12, end.Add(time.Minute)),
+		"prod/worker": spikeData("prod", "worker", 12, end.Add(time.Minute)),
+	}}
+	a.currentStart = end
+
+	latch, ok := a.Latch("prod", "api", false)
+	require.True(t, ok)
+	assert.True(t, latch.Complete)
+	assert.Equal(t, 720, latch.Data.SampleCount)
+	assert.Equal(t, time.Hour, latch.Duration())
+	assert.Equal(t, DefaultInterval, latch.Interval)
+
+	latch, ok = a.Latch("prod", "api", true)
+	require.True(t, ok)
+	assert.False(t, latch.Complete)
+	assert.Equal(t, 12, latch.Data.SampleCount)
+	assert.Equal(t, time.Minute, latch.Duration())
+
+	// No complete window yet: the window in progress is returned
+	latch, ok = a.Latch("prod", "worker", false)
+	require.True(t, ok)
+	assert.False(t, latch.Complete)
+
+	_, ok = a.Latch("prod", "missing", false)
+	assert.False(t, ok)
+}
+
+func TestAgent_Workloads(t *testing.T) {
+	a := newAgent(Config{Log: slog.New(slog.DiscardHandler)})
+	now := time.Now()
+	a.finishWindow(&fakeSampler{data: map[string]*metrics.SpikeData{
+		"prod/api": spikeData("prod", "api", 720, now),
+	}}, now.Add(-time.Hour), now)
+	a.current = &fakeSampler{data: map[string]*metrics.SpikeData{
+		"prod/api":   spikeData("prod", "api", 3, now),
+		"dev/worker": spikeData("dev", "worker", 3, now),
+	}}
+
+	workloads := a.Workloads()
+	require.Len(t, workloads, 2)
+	assert.Equal(t, "dev", workloads[0].Namespace)
+	assert.False(t, workloads[0].Complete)
+	assert.Equal(t, "api", workloads[1].Workload)
+	assert.True(t, workloads[1].Complete)
+	assert.Equal(t, 720, workloads[1].Samples)
+}
+
+func TestAgent_RunStopsOnCancel(t *testing.T) {
+	a := newAgent(Config{Log: slog.New(slog.DiscardHandler)})
+	var got metrics.LatchConfig
+	a.newSampler = func(c metrics.LatchConfig) (sampler, error) {
+		got = c
+		return &fakeSampler{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	assert.Equal(t, DefaultInterval, got.SampleInterval)
+	assert.Equal(t, DefaultWindow, got.Duration)
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client queries an agent's API.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewClient creates a client for the agent at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Latch fetches a workload's latch window; see Agent.Latch for partial.
+func (c *Client) Latch(ctx context.Context, namespace, workload string, partial bool) (*WorkloadLatch, error) {
+	path := fmt.Sprintf("/v1/latch/%s/%s", url.PathEscape(namespace), url.PathEscape(workload))
+	if partial {
+		path += "?partial=true"
+	}
+	var latch WorkloadLatch
+	if err := c.get(ctx, path, &latch); err != nil {
+		return nil, err
+	}
+	return &latch, nil
+}
+
+// Workloads lists the workloads the agent has samples for.
+func (c *Client) Workloads(ctx context.Context) ([]WorkloadSummary, error) {
+	var workloads []WorkloadSummary
+	if err := c.get(ctx, "/v1/workloads", &workloads); err != nil {
+		return nil, err
+	}
+	return workloads, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to build agent request: %w", err)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("agent request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("agent: %s", apiErr.Error)
+		}
+		return fmt.Errorf("agent returned HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode agent response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Name is the name of every object the agent is installed as.
+const Name = "kubenow-agent"
+
+// Defaults for the agent's installation.
+const (
+	DefaultNamespace = "kubenow"
+	DefaultPort      = 8080
+	DefaultImageRepo = "ppiankov/kubenow"
+)
+
+// nonRootUID is the UID the agent container runs as.
+const nonRootUID = 65532
+
+// InstallOptions describe the agent's installation.
+type InstallOptions struct {
+	Namespace      string        // namespace the agent runs in
+	Image          string        // kubenow image
+	Interval       time.Duration // sample interval
+	Window         time.Duration // latch window
+	Port           int           // API port
+	WatchNamespace []string      // namespaces to sample (empty = all)
+}
+
+// Objects are the Kubernetes objects the agent is installed as.
+type Objects struct {
+	Namespace          *corev1.Namespace
+	ServiceAccount     *corev1.ServiceAccount
+	ClusterRole        *rbacv1.ClusterRole
+	ClusterRoleBinding *rbacv1.ClusterRoleBinding
+	Deployment         *appsv1.Deployment
+	Service            *corev1.Service
+}
+
+func labels() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       Name,
+		"app.kubernetes.io/managed-by": "kubenow",
+	}
+}
+
+// BuildObjects builds the agent's objects. One replica suffices: the
+// Metrics API serves every node's usage, so no DaemonSet is needed.
+func BuildObjects(opts InstallOptions) *Objects {
+	meta := metav1.ObjectMeta{Name: Name, Namespace: opts.Namespace, Labels: labels()}
+	clusterMeta := metav1.ObjectMeta{Name: Name, Labels: labels()}
+
+	args := []string{
+		"agent", "run",
+		"--interval", opts.Interval.String(),
+		"--window", opts.Window.String(),
+		"--listen", ":" + strconv.Itoa(opts.Port),
+	}
+	for _, ns := range opts.WatchNamespace {
+		args = append(args, "--watch-namespace", ns)
+	}
+
+	replicas := int32(1)
+	nonRoot := true
+	noEscalation := false
+	readOnlyRoot := true
+	uid := int64(nonRootUID)
+
+	return &Objects{
+		Namespace: &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: opts.Namespace},
+		},
+		ServiceAccount: &corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta,
+		},
+		ClusterRole: &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+			ObjectMeta: clusterMeta,
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods", "events"}, Verbs: []string{"get", "list"}},
+				{APIGroups: []string{"metrics.k8s.io"}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+			},
+		},
+		ClusterRoleBinding: &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: clusterMeta,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: Name},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Namespace: opts.Namespace, Name: Name},
+			},
+		},
+		Deployment: &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: meta,
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels()},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels()},
+					Spec: corev1.PodSpec{
+						ServiceAccountName: Name,
+						SecurityContext:    &corev1.PodSecurityContext{RunAsNonRoot: &nonRoot, RunAsUser: &uid},
+						Containers: []corev1.Container{{
+							Name:  "agent",
+							Image: opts.Image,
+							Args:  args,
+							Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: int32(opts.Port)}},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+									Path: "/healthz", Port: intstr.FromString("http"),
+								}},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("50m"),
+									corev1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: &noEscalation,
+								ReadOnlyRootFilesystem:   &readOnlyRoot,
+								Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+							},
+						}},
+					},
+				},
+			},
+		},
+		Service: &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: meta,
+			Spec: corev1.ServiceSpec{
+				Selector: labels(),
+				Ports: []corev1.ServicePort{{
+					Name: "http", Port: int32(opts.Port), TargetPort: intstr.FromString("http"),
+				}},
+			},
+		},
+	}
+}
+
+// Manifest renders the agent's objects as a multi-document YAML manifest.
+func Manifest(opts InstallOptions) ([]byte, error) {
+	objs := BuildObjects(opts)
+	var buf bytes.Buffer
+	for i, obj := range []any{
+		objs.Namespace, objs.ServiceAccount, objs.ClusterRole, objs.ClusterRoleBinding, objs.Deployment, objs.Service,
+	} {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render agent manifest: %w", err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// Install creates the agent's objects, or updates them when they exist,
+// and returns one line per object describing what was done. The namespace
+// is only created, never updated.
+func Install(ctx context.Context, client kubernetes.Interface, opts InstallOptions) ([]string, error) {
+	objs := BuildObjects(opts)
+	var done []string
+	record := func(kind, name string, created bool) {
+		verb := "updated"
+		if created {
+			verb = "created"
+		}
+		done = append(done, fmt.Sprintf("%s/%s %s", kind, name, verb))
+	}
+
+	_, err := client.CoreV1().Namespaces().Create(ctx, objs.Namespace, metav1.CreateOptions{})
+	switch {
+	case err == nil:
+		record("namespace", opts.Namespace, true)
+	case !apierrors.IsAlreadyExists(err):
+		return done, fmt.Errorf("failed to create namespace %s: %w", opts.Namespace, err)
+	}
+
+	sa := client.CoreV1().ServiceAccounts(opts.Namespace)
+	created, err := createOrUpdate(ctx, objs.ServiceAccount, sa.Create, sa.Get, sa.Update)
+	if err != nil {
+		return done, fmt.Errorf("failed to apply serviceaccount: %w", err)
+	}
+	record("serviceaccount", Name, created)
+
+	cr := client.RbacV1().ClusterRoles()
+	if created, err = createOrUpdate(ctx, objs.ClusterRole, cr.Create, cr.Get, cr.Update); err != nil {
+		return done, fmt.Errorf("failed to apply clusterrole: %w", err)
+	}
+	record("clusterrole", Name, created)
+
+	crb := client.RbacV1().ClusterRoleBindings()
+	if created, err = createOrUpdate(ctx, objs.ClusterRoleBinding, crb.Create, crb.Get, crb.Update); err != nil {
+		return done, fmt.Errorf("failed to apply clusterrolebinding: %w", err)
+	}
+	record("clusterrolebinding", Name, created)
+
+	deploy := client.AppsV1().Deployments(opts.Namespace)
+	if created, err = createOrUpdate(ctx, objs.Deployment, deploy.Create, deploy.Get, deploy.Update); err != nil {
+		return done, fmt.Errorf("failed to apply deployment: %w", err)
+	}
+	record("deployment", Name, created)
+
+	svc := client.CoreV1().Services(opts.Namespace)
+	existing, err := svc.Get(ctx, Name, metav1.GetOptions{})
+	if err == nil {
+		// Keep the allocated cluster IP
+		objs.Service.Spec.ClusterIP = existing.Spec.ClusterIP
+		objs.Service.Spec.ClusterIPs = existing.Spec.ClusterIPs
+	}
+	if created, err = createOrUpdate(ctx, objs.Service, svc.Create, svc.Get, svc.Update); err != nil {
+		return done, fmt.Errorf("failed to apply service: %w", err)
+	}
+	record("service", Name, created)
+
+	return done, nil
+}
+
+// createOrUpdate creates obj, or replaces the existing object of the same
+// name. Reports whether obj was created.
+func createOrUpdate[T interface {
+	metav1.Object
+	*O
+}, O any](
+	ctx context.Context, obj T,
+	create func(context.Context, T, metav1.CreateOptions) (T, error),
+	get func(context.Context, string, metav1.GetOptions) (T, error),
+	update func(context.Context, T, metav1.UpdateOptions) (T, error),
+) (bool, error) {
+	_, err := create(ctx, obj, metav1.CreateOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return false, err
+	}
+	existing, err := get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = update(ctx, obj, metav1.UpdateOptions{})
+	return false, err
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testInstallOptions() InstallOptions {
+	return InstallOptions{
+		Namespace:      DefaultNamespace,
+		Image:          "ppiankov/kubenow:1.0.0",
+		Interval:       2 * time.Second,
+		Window:         time.Hour,
+		Port:           DefaultPort,
+		WatchNamespace: []string{"prod"},
+	}
+}
+
+func TestBuildObjects(t *testing.T) {
+	objs := BuildObjects(testInstallOptions())
+
+	container := objs.Deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "ppiankov/kubenow:1.0.0", container.Image)
+	assert.Equal(t, []string{
+		"agent", "run", "--interval", "2s", "--window", "1h0m0s", "--listen", ":8080",
+		"--watch-namespace", "prod",
+	}, container.Args)
+	assert.False(t, *container.SecurityContext.AllowPrivilegeEscalation)
+	assert.True(t, *objs.Deployment.Spec.Template.Spec.SecurityContext.RunAsNonRoot)
+	assert.Equal(t, Name, objs.Deployment.Spec.Template.Spec.ServiceAccountName)
+	assert.Equal(t, DefaultNamespace, objs.ClusterRoleBinding.Subjects[0].Namespace)
+	assert.Equal(t, int32(DefaultPort), objs.Service.Spec.Ports[0].Port)
+}
+
+func TestManifest(t *testing.T) {
+	data, err := Manifest(testInstallOptions())
+	require.NoError(t, err)
+
+	docs := strings.Split(string(data), "---\n")
+	require.Len(t, docs, 6)
+	assert.Contains(t, docs[0], "kind: Namespace")
+	assert.Contains(t, docs[2], "metrics.k8s.io")
+	assert.Contains(t, docs[4], "kind: Deployment")
+	assert.Contains(t, docs[5], "kind: Service")
+}
+
+func TestInstall_CreateThenUpdate(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctx := context.Background()
+	opts := testInstallOptions()
+
+	done, err := Install(ctx, client, opts)
+	require.NoError(t, err)
+	assert.Len(t, done, 6)
+	assert.Equal(t, "namespace/kubenow created", done[0])
+
+	opts.Image = "ppiankov/kubenow:1.1.0"
+	done, err = Install(ctx, client, opts)
+	require.NoError(t, err)
+	assert.Len(t, done, 5) // the existing namespace is left alone
+	assert.Equal(t, "deployment/kubenow-agent updated", done[3])
+
+	deploy, err := client.AppsV1().Deployments(DefaultNamespace).Get(ctx, Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ppiankov/kubenow:1.1.0", deploy.Spec.Template.Spec.Containers[0].Image)
+}
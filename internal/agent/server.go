@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Handler serves the agent's API:
+//
+//	GET /healthz
+//	GET /v1/workloads
+//	GET /v1/latch/{namespace}/{workload}[?partial=true]
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /v1/workloads", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, a.Workloads())
+	})
+	mux.HandleFunc("GET /v1/latch/{namespace}/{workload}", func(w http.ResponseWriter, r *http.Request) {
+		namespace, workload := r.PathValue("namespace"), r.PathValue("workload")
+		latch, ok := a.Latch(namespace, workload, r.URL.Query().Get("partial") == "true")
+		if !ok {
+			writeJSON(w, http.StatusNotFound, apiError{
+				Error: fmt.Sprintf("no samples for %s/%s", namespace, workload),
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, latch)
+	})
+	return mux
+}
+
+// apiError is the body of an error response.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	//nolint:errcheck // the client went away; nothing to report to
+	json.NewEncoder(w).Encode(v)
+}
+
+// Serve runs the API on addr until ctx is canceled, then waits up to
+// shutdownTimeout for in-flight requests to finish.
+func (a *Agent) Serve(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("agent server failed: %w", err)
+	}
+	return a.serve(ctx, ln)
+}
+
+// shutdownTimeout bounds how long Serve drains in-flight requests.
+const shutdownTimeout = 5 * time.Second
+
+func (a *Agent) serve(ctx context.Context, ln net.Listener) error {
+	server := &http.Server{
+		Handler:           a.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// ListenAndServe returns as soon as Shutdown starts; wait for it to drain
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		//nolint:errcheck // best-effort: requests still running at the deadline are cut off
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(ln); err != http.ErrServerClosed {
+		return fmt.Errorf("agent server failed: %w", err)
+	}
+	<-drained
+	return nil
+}
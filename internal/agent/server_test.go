@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func TestServerAndClient(t *testing.T) {
+	a := newAgent(Config{Interval: 2 * time.Second, Log: slog.New(slog.DiscardHandler)})
+	now := time.Now()
+	a.current = &fakeSampler{data: map[string]*metrics.SpikeData{
+		"prod/api": spikeData("prod", "api", 30, now),
+	}}
+	a.currentStart = now.Add(-time.Minute)
+
+	srv := httptest.NewServer(a.Handler())
+	defer srv.Close()
+	client := NewClient(srv.URL + "/")
+	ctx := context.Background()
+
+	resp, err := http.Get(srv.URL + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	workloads, err := client.Workloads(ctx)
+	require.NoError(t, err)
+	require.Len(t, workloads, 1)
+	assert.Equal(t, "api", workloads[0].Workload)
+
+	latch, err := client.Latch(ctx, "prod", "api", true)
+	require.NoError(t, err)
+	assert.Equal(t, 30, latch.Data.SampleCount)
+	assert.Equal(t, 2*time.Second, latch.Interval)
+	assert.False(t, latch.Complete)
+
+	_, err = client.Latch(ctx, "prod", "missing", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no samples for prod/missing")
+}
+
+func TestServe_ShutsDownOnCancel(t *testing.T) {
+	a := newAgent(Config{Interval: 2 * time.Second, Log: slog.New(slog.DiscardHandler)})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + ln.Addr().String() + "/healthz"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- a.serve(ctx, ln) }()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(shutdownTimeout + time.Second):
+		t.Fatal("serve did not return after cancel")
+	}
+
+	// Shutdown has finished: the listener is closed
+	_, err = http.Get(url)
+	require.Error(t, err)
+}
+
+func TestServe_ListenError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+
+	a := newAgent(Config{Log: slog.New(slog.DiscardHandler)})
+	err = a.Serve(context.Background(), ln.Addr().String())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent server failed")
+}
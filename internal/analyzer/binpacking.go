@@ -3,6 +3,8 @@ package analyzer
 import (
 	"fmt"
 	"sort"
+
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // Node represents a node in the cluster with resource capacity
@@ -106,9 +108,9 @@ func (bp *BinPacker) Pack(pods []PodRequirement) *BinPackingResult {
 			if !bp.canFit(&newNode, pod) {
 				result.Feasible = false
 				result.Reasons = append(result.Reasons,
-					fmt.Sprintf("Pod %s/%s (CPU: %.2f, Memory: %.2fGi) exceeds single node capacity (CPU: %.2f, Memory: %.2fGi)",
-						pod.Namespace, pod.Name, pod.CPU, pod.Memory/(1024*1024*1024),
-						newNode.CPUAllocatable, newNode.MemAllocatable/(1024*1024*1024)))
+					fmt.Sprintf("Pod %s/%s (CPU: %s, Memory: %s) exceeds single node capacity (CPU: %s, Memory: %s)",
+						pod.Namespace, pod.Name, units.Cores(pod.CPU), units.MemoryGi(pod.Memory),
+						units.Cores(newNode.CPUAllocatable), units.MemoryGi(newNode.MemAllocatable)))
 				continue
 			}
 
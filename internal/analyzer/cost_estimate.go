@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+)
+
+// Node labels carrying the cloud instance type.
+const (
+	labelInstanceType     = "node.kubernetes.io/instance-type"
+	labelInstanceTypeBeta = "beta.kubernetes.io/instance-type"
+)
+
+// ClusterNodeTypes lists every node's instance type and allocatable capacity
+// for per-node-type pricing. Nodes without an instance-type label are skipped.
+func ClusterNodeTypes(ctx context.Context, client kubernetes.Interface) ([]cost.NodeType, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var out []cost.NodeType
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		instanceType := nodeInstanceType(n)
+		if instanceType == "" {
+			continue
+		}
+		out = append(out, cost.NodeType{
+			InstanceType: instanceType,
+			CPUCores:     n.Status.Allocatable.Cpu().AsApproximateFloat64(),
+			MemoryGi:     float64(n.Status.Allocatable.Memory().Value()) / (1024 * 1024 * 1024),
+		})
+	}
+	return out, nil
+}
+
+// nodeInstanceType returns the instance type of a node, or "" if unlabeled.
+func nodeInstanceType(n *corev1.Node) string {
+	if t := n.Labels[labelInstanceType]; t != "" {
+		return t
+	}
+	return n.Labels[labelInstanceTypeBeta]
+}
+
+// AttachCostEstimates prices every workload in result at the given rates and
+// fills the per-namespace and summary monthly waste estimates. Batch
+// workloads are priced only for the share of the window they were running.
+func AttachCostEstimates(result *RequestsSkewResult, rates cost.Rates) {
+	var totalRequestedCPU, totalRequestedMemGi, totalWastedCPU, totalWastedMemGi float64
+	byNamespace := make(map[string][]cost.WorkloadCostEstimate)
+	for i := range result.Results {
+		w := &result.Results[i]
+		share := 1.0
+		if w.ActiveFraction > 0 {
+			share = w.ActiveFraction
+		}
+		est := cost.EstimateWorkload(
+			w.RequestedCPU*share, w.P95UsedCPU*share,
+			w.RequestedMemoryGi*share, w.P95UsedMemoryGi*share,
+			rates,
+		)
+		w.CostEstimate = &est
+		// A template group's estimate is per instance; each instance counts
+		namespaces := w.instanceNamespaces()
+		for _, ns := range namespaces {
+			byNamespace[ns] = append(byNamespace[ns], est)
+		}
+		n := float64(len(namespaces))
+		totalRequestedCPU += w.RequestedCPU * share * n
+		totalRequestedMemGi += w.RequestedMemoryGi * share * n
+		totalWastedCPU += max(w.RequestedCPU-w.P95UsedCPU, 0) * share * n
+		totalWastedMemGi += max(w.RequestedMemoryGi-w.P95UsedMemoryGi, 0) * share * n
+	}
+
+	summary := cost.EstimateSummary(
+		totalWastedCPU,
+		totalWastedMemGi,
+		totalRequestedCPU,
+		totalRequestedMemGi,
+		rates,
+	)
+	result.Summary.CostEstimate = &summary
+	result.NamespaceCosts = cost.EstimateNamespaces(byNamespace)
+}
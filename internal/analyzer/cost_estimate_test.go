@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+)
+
+func costNode(name string, labels map[string]string, cpu, mem string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+			},
+		},
+	}
+}
+
+func TestClusterNodeTypes(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		costNode("a", map[string]string{labelInstanceType: "m5.xlarge"}, "4", "16Gi"),
+		costNode("b", map[string]string{labelInstanceTypeBeta: "r5.large"}, "2", "16Gi"),
+		costNode("c", nil, "8", "32Gi"),
+	)
+
+	types, err := ClusterNodeTypes(context.Background(), client)
+	require.NoError(t, err)
+	require.Len(t, types, 2)
+
+	byType := map[string]cost.NodeType{}
+	for _, nt := range types {
+		byType[nt.InstanceType] = nt
+	}
+	assert.InDelta(t, 4, byType["m5.xlarge"].CPUCores, 1e-9)
+	assert.InDelta(t, 16, byType["m5.xlarge"].MemoryGi, 1e-9)
+	assert.InDelta(t, 2, byType["r5.large"].CPUCores, 1e-9)
+}
+
+func TestAttachCostEstimates(t *testing.T) {
+	result := &RequestsSkewResult{
+		Summary: RequestsSkewSummary{TotalWastedCPU: 3, TotalWastedMemoryGi: 4},
+		Results: []WorkloadSkewAnalysis{
+			{Namespace: "prod", Workload: "api", RequestedCPU: 2, P95UsedCPU: 0.5, RequestedMemoryGi: 4, P95UsedMemoryGi: 2},
+			{Namespace: "prod", Workload: "worker", RequestedCPU: 1, P95UsedCPU: 0.5, RequestedMemoryGi: 2, P95UsedMemoryGi: 1},
+			{Namespace: "dev", Workload: "tool", RequestedCPU: 1, P95UsedCPU: 1, RequestedMemoryGi: 1, P95UsedMemoryGi: 1},
+		},
+	}
+	rates := cost.Rates{CPUPerCoreHour: 0.04, MemoryPerGiBHour: 0.005, Source: "user"}
+
+	AttachCostEstimates(result, rates)
+
+	for i := range result.Results {
+		require.NotNil(t, result.Results[i].CostEstimate, result.Results[i].Workload)
+	}
+	require.NotNil(t, result.Summary.CostEstimate)
+	assert.Equal(t, "user", result.Summary.CostEstimate.Rates.Source)
+
+	require.Len(t, result.NamespaceCosts, 2)
+	prod := result.NamespaceCosts[0]
+	assert.Equal(t, "prod", prod.Namespace)
+	assert.Equal(t, 2, prod.Workloads)
+	assert.InDelta(t,
+		result.Results[0].CostEstimate.WastedMonthly+result.Results[1].CostEstimate.WastedMonthly,
+		prod.WastedMonthly, 0.01)
+	assert.Equal(t, "dev", result.NamespaceCosts[1].Namespace)
+	assert.Zero(t, result.NamespaceCosts[1].WastedMonthly)
+}
+
+func TestAttachCostEstimates_BatchActiveFraction(t *testing.T) {
+	result := &RequestsSkewResult{
+		Results: []WorkloadSkewAnalysis{
+			{Namespace: "etl", Workload: "nightly", RequestedCPU: 4, P95UsedCPU: 1, RequestedMemoryGi: 8, P95UsedMemoryGi: 2, ActiveFraction: 0.25},
+		},
+	}
+	rates := cost.Rates{CPUPerCoreHour: 0.04, MemoryPerGiBHour: 0.005, Source: "user"}
+
+	AttachCostEstimates(result, rates)
+
+	expected := cost.EstimateWorkload(1, 0.25, 2, 0.5, rates)
+	assert.InDelta(t, expected.WastedMonthly, result.Results[0].CostEstimate.WastedMonthly, 1e-9)
+	assert.InDelta(t, expected.WastedMonthly, result.Summary.CostEstimate.TotalWastedMonthly, 0.01)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/ppiankov/kubenow/internal/metrics"
@@ -32,7 +33,7 @@ type crdWorkloadGroup struct {
 // known CRD operators (CNPG, Strimzi, RabbitMQ, etc.) that are not already discovered
 // by the standard Deployment/StatefulSet/DaemonSet loops.
 func (a *RequestsSkewAnalyzer) discoverCRDWorkloads(ctx context.Context, namespace string, knownWorkloads map[string]bool) ([]crdWorkloadGroup, error) {
-	pods, err := a.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pods, err := a.namespacePods(ctx, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +46,8 @@ func (a *RequestsSkewAnalyzer) discoverCRDWorkloads(ctx context.Context, namespa
 	}
 	groups := make(map[string]*groupState)
 
-	for i := range pods.Items {
-		pod := &pods.Items[i]
+	for i := range pods {
+		pod := &pods[i]
 		// Skip standalone pods (no ownerReferences)
 		if len(pod.OwnerReferences) == 0 {
 			continue
@@ -100,3 +101,16 @@ func (a *RequestsSkewAnalyzer) discoverCRDWorkloads(ctx context.Context, namespa
 
 	return result, nil
 }
+
+// namespacePods lists a namespace's pods, sharing the OOM index's list when
+// the analyzer has one.
+func (a *RequestsSkewAnalyzer) namespacePods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	if a.ooms != nil {
+		return a.ooms.pods(ctx, namespace)
+	}
+	list, err := a.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
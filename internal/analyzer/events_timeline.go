@@ -0,0 +1,274 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultTimelineWindow is the default lookback of an events timeline.
+const DefaultTimelineWindow = 24 * time.Hour
+
+// Events timeline entry kinds.
+const (
+	TimelineDeploy  = "deploy"
+	TimelineEvent   = "event"
+	TimelineRestart = "restart"
+)
+
+// timelineKindOrder orders entries with the same timestamp: a deploy comes
+// before the events it causes.
+var timelineKindOrder = map[string]int{TimelineDeploy: 0, TimelineEvent: 1, TimelineRestart: 2}
+
+// TimelineConfig holds configuration for an events timeline.
+type TimelineConfig struct {
+	Namespace string        // "" = all namespaces
+	Kind      string        // workload kind; "" = every workload in Namespace
+	Workload  string        // workload name, with Kind
+	Window    time.Duration // lookback (0 = DefaultTimelineWindow)
+	Now       time.Time     // zero = time.Now(); set by tests
+}
+
+// EventTimeline is the outcome of BuildEventTimeline.
+type EventTimeline struct {
+	Namespace   string          `json:"namespace,omitempty"`
+	Workload    string          `json:"workload,omitempty"` // Kind/name
+	Window      string          `json:"window"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	Summary     TimelineSummary `json:"summary"`
+	Entries     []TimelineEntry `json:"entries"` // oldest first
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// TimelineSummary counts a timeline's entries.
+type TimelineSummary struct {
+	Deploys  int `json:"deploys"`
+	Events   int `json:"events"`
+	Warnings int `json:"warnings"`
+	Restarts int `json:"restarts"`
+}
+
+// TimelineEntry is one entry of an events timeline.
+type TimelineEntry struct {
+	Time        time.Time `json:"time"`
+	Kind        string    `json:"kind"`           // deploy|event|restart
+	Type        string    `json:"type,omitempty"` // Normal|Warning for events
+	Namespace   string    `json:"namespace"`
+	Object      string    `json:"object"`             // Kind/name
+	Workload    string    `json:"workload,omitempty"` // Kind/name of the owning workload, when known
+	Reason      string    `json:"reason"`
+	Count       int32     `json:"count,omitempty"`
+	Message     string    `json:"message"`
+	AfterDeploy string    `json:"after_deploy,omitempty"` // e.g. "4m after revision 7 (api-6b8c)"
+}
+
+// BuildEventTimeline collects the events, container restarts, and rollouts
+// of a namespace or one workload in the window and orders them into a
+// timeline. Events and restarts within an hour after a rollout of their
+// workload name it, so incident reviews can see what a deploy set off.
+//
+// Events expire after the API server's event TTL (1h by default) and only
+// the last termination of each live container is visible, so older
+// entries may be missing even inside the window.
+//
+//nolint:gocyclo // sequential collect → attribute → correlate pipeline
+func BuildEventTimeline(ctx context.Context, client kubernetes.Interface, cfg TimelineConfig) (*EventTimeline, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultTimelineWindow
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	since := now.Add(-window)
+
+	events, err := client.CoreV1().Events(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	owners := newTimelineOwners(ctx, client, cfg.Namespace, pods.Items)
+
+	tl := &EventTimeline{
+		Namespace: cfg.Namespace, Window: formatDuration(window),
+		From: since, To: now, Entries: []TimelineEntry{}, GeneratedAt: now,
+	}
+	target := ""
+	if cfg.Workload != "" {
+		target = cfg.Kind + "/" + cfg.Workload
+		tl.Workload = target
+	}
+	keep := func(workload string) bool { return target == "" || workload == target }
+
+	for _, r := range owners.rollouts {
+		if workload := r.Kind + "/" + r.Workload; keep(workload) && !r.Time.Before(since) && !r.Time.After(now) {
+			tl.Entries = append(tl.Entries, TimelineEntry{
+				Time: r.Time, Kind: TimelineDeploy, Namespace: r.Namespace, Object: r.Kind + "/" + r.Workload,
+				Workload: workload, Reason: "Rollout", Message: r.Detail,
+			})
+		}
+	}
+
+	for i := range events.Items {
+		e := &events.Items[i]
+		t := eventTime(e)
+		if t.Before(since) || t.After(now) {
+			continue
+		}
+		obj := e.InvolvedObject
+		workload := owners.workload(obj.Namespace, obj.Kind, obj.Name)
+		if !keep(workload) {
+			continue
+		}
+		count := e.Count
+		if e.Series != nil && e.Series.Count > count {
+			count = e.Series.Count
+		}
+		tl.Entries = append(tl.Entries, TimelineEntry{
+			Time: t, Kind: TimelineEvent, Type: e.Type, Namespace: obj.Namespace, Object: obj.Kind + "/" + obj.Name,
+			Workload: workload, Reason: e.Reason, Count: count, Message: strings.TrimSpace(e.Message),
+		})
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		workload := owners.workload(pod.Namespace, "Pod", pod.Name)
+		if !keep(workload) {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			term := cs.LastTerminationState.Terminated
+			if term == nil || term.FinishedAt.Time.Before(since) || term.FinishedAt.Time.After(now) {
+				continue
+			}
+			reason := term.Reason
+			if reason == "" {
+				reason = "Terminated"
+			}
+			tl.Entries = append(tl.Entries, TimelineEntry{
+				Time: term.FinishedAt.Time, Kind: TimelineRestart, Namespace: pod.Namespace, Object: "Pod/" + pod.Name,
+				Workload: workload, Reason: reason, Count: cs.RestartCount,
+				Message: fmt.Sprintf("container %s exited with code %d; %d restart(s)", cs.Name, term.ExitCode, cs.RestartCount),
+			})
+		}
+	}
+
+	sort.SliceStable(tl.Entries, func(i, j int) bool {
+		a, b := &tl.Entries[i], &tl.Entries[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if timelineKindOrder[a.Kind] != timelineKindOrder[b.Kind] {
+			return timelineKindOrder[a.Kind] < timelineKindOrder[b.Kind]
+		}
+		return a.Object < b.Object
+	})
+
+	lastDeploy := make(map[string]*TimelineEntry) // namespace/workload -> latest rollout so far
+	for i := range tl.Entries {
+		entry := &tl.Entries[i]
+		key := entry.Namespace + "/" + entry.Workload
+		switch entry.Kind {
+		case TimelineDeploy:
+			lastDeploy[key] = entry
+			tl.Summary.Deploys++
+			continue
+		case TimelineEvent:
+			tl.Summary.Events++
+			if entry.Type == corev1.EventTypeWarning {
+				tl.Summary.Warnings++
+			}
+		case TimelineRestart:
+			tl.Summary.Restarts++
+		}
+		if d := lastDeploy[key]; entry.Workload != "" && d != nil && entry.Time.Sub(d.Time) <= oomDeployWindow {
+			revision, _, _ := strings.Cut(d.Message, ":")
+			entry.AfterDeploy = formatDuration(entry.Time.Sub(d.Time)) + " after " + revision
+		}
+	}
+	return tl, nil
+}
+
+// timelineOwners attributes objects to their workloads, including pods
+// and ReplicaSets that no longer exist, by their generated names.
+type timelineOwners struct {
+	rollouts    []workloadRollout
+	rsOwners    map[string]string // namespace/replicaset -> Deployment
+	controllers map[string]string // namespace/name -> StatefulSet or DaemonSet
+	pods        map[string]string // namespace/pod -> Kind/name
+	hpas        map[string]string // namespace/hpa -> Kind/name of the scale target
+}
+
+func newTimelineOwners(ctx context.Context, client kubernetes.Interface, namespace string, pods []corev1.Pod) *timelineOwners {
+	o := &timelineOwners{
+		rollouts:    workloadRollouts(ctx, client, namespace),
+		rsOwners:    make(map[string]string),
+		controllers: make(map[string]string),
+		pods:        make(map[string]string, len(pods)),
+		hpas:        make(map[string]string),
+	}
+	for _, r := range o.rollouts {
+		if r.Kind == "Deployment" {
+			o.rsOwners[r.Namespace+"/"+r.Name] = r.Workload
+		} else {
+			o.controllers[r.Namespace+"/"+r.Workload] = r.Kind
+		}
+	}
+	for i := range pods {
+		kind, name := podWorkload(&pods[i], o.rsOwners)
+		o.pods[pods[i].Namespace+"/"+pods[i].Name] = kind + "/" + name
+	}
+	if list, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range list.Items {
+			hpa := &list.Items[i]
+			ref := hpa.Spec.ScaleTargetRef
+			o.hpas[hpa.Namespace+"/"+hpa.Name] = ref.Kind + "/" + ref.Name
+		}
+	}
+	return o
+}
+
+// workload returns the Kind/name of the workload an object belongs to, or
+// "" when it cannot be attributed.
+func (o *timelineOwners) workload(namespace, kind, name string) string {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob":
+		return kind + "/" + name
+	case "ReplicaSet":
+		if dep, ok := o.rsOwners[namespace+"/"+name]; ok {
+			return "Deployment/" + dep
+		}
+		return kind + "/" + name
+	case "HorizontalPodAutoscaler":
+		return o.hpas[namespace+"/"+name]
+	case "Pod":
+		if w, ok := o.pods[namespace+"/"+name]; ok {
+			return w
+		}
+		// Deleted pod: <replicaset>-<suffix>, <statefulset>-<ordinal>, or
+		// <daemonset>-<suffix>.
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			prefix := name[:i]
+			if dep, ok := o.rsOwners[namespace+"/"+prefix]; ok {
+				return "Deployment/" + dep
+			}
+			if k, ok := o.controllers[namespace+"/"+prefix]; ok {
+				return k + "/" + prefix
+			}
+		}
+		return ""
+	default:
+		return ""
+	}
+}
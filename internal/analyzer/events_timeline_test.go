@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func timelineEvent(ns, name, kind, object, eventType, reason string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: ns},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: ns, Name: object},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " message ",
+		Count:          1,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestBuildEventTimeline(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rs := ownedReplicaSet("shop", "api-6b8c", "api")
+	rs.CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+	rs.Annotations = map[string]string{"deployment.kubernetes.io/revision": "7"}
+	rs.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: "ghcr.io/acme/api:v2"}}
+
+	controller := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "api-6b8c-aaaaa", Namespace: "shop",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-6b8c", Controller: &controller}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "app", RestartCount: 3,
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "OOMKilled", ExitCode: 137, FinishedAt: metav1.NewTime(now.Add(-110 * time.Minute)),
+			}},
+		}}},
+	}
+
+	busy := timelineEvent("shop", "e3", "Pod", "api-6b8c-aaaaa", corev1.EventTypeWarning, "BackOff", now.Add(-100*time.Minute))
+	busy.Count = 12
+
+	client := fake.NewClientset(
+		rs, pod, busy,
+		timelineEvent("shop", "e1", "Deployment", "api", corev1.EventTypeNormal, "ScalingReplicaSet", now.Add(-2*time.Hour)),
+		timelineEvent("shop", "e2", "Pod", "api-6b8c-zzzzz", corev1.EventTypeNormal, "Killing", now.Add(-119*time.Minute)),
+		timelineEvent("shop", "e4", "HorizontalPodAutoscaler", "api-hpa", corev1.EventTypeNormal, "SuccessfulRescale",
+			now.Add(-30*time.Minute)),
+		timelineEvent("shop", "e5", "Pod", "web-1", corev1.EventTypeWarning, "Unhealthy", now.Add(-10*time.Minute)),
+		timelineEvent("shop", "old", "Deployment", "api", corev1.EventTypeNormal, "ScalingReplicaSet", now.Add(-48*time.Hour)),
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-hpa", Namespace: "shop"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "api"},
+			},
+		},
+	)
+
+	tl, err := BuildEventTimeline(context.Background(), client, TimelineConfig{
+		Namespace: "shop", Kind: "Deployment", Workload: "api", Now: now,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment/api", tl.Workload)
+	assert.Equal(t, "1d", tl.Window)
+	assert.Equal(t, TimelineSummary{Deploys: 1, Events: 4, Warnings: 1, Restarts: 1}, tl.Summary)
+
+	var kinds, reasons []string
+	for _, e := range tl.Entries {
+		kinds = append(kinds, e.Kind)
+		reasons = append(reasons, e.Reason)
+		assert.Equal(t, "Deployment/api", e.Workload)
+	}
+	assert.Equal(t, []string{"deploy", "event", "event", "restart", "event", "event"}, kinds)
+	assert.Equal(t, []string{"Rollout", "ScalingReplicaSet", "Killing", "OOMKilled", "BackOff", "SuccessfulRescale"}, reasons)
+
+	deploy := tl.Entries[0]
+	assert.Equal(t, "revision 7 (api-6b8c): ghcr.io/acme/api:v2", deploy.Message)
+	assert.Empty(t, deploy.AfterDeploy)
+
+	restart := tl.Entries[3]
+	assert.Equal(t, "Pod/api-6b8c-aaaaa", restart.Object)
+	assert.Equal(t, "container app exited with code 137; 3 restart(s)", restart.Message)
+	assert.Equal(t, "10m after revision 7 (api-6b8c)", restart.AfterDeploy)
+
+	backoff := tl.Entries[4]
+	assert.Equal(t, int32(12), backoff.Count)
+	assert.Equal(t, "BackOff message", backoff.Message)
+	assert.Equal(t, "20m after revision 7 (api-6b8c)", backoff.AfterDeploy)
+
+	// More than an hour after the rollout.
+	assert.Empty(t, tl.Entries[5].AfterDeploy)
+}
+
+func TestBuildEventTimeline_Namespace(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := fake.NewClientset(
+		timelineEvent("shop", "e1", "Pod", "web-1", corev1.EventTypeWarning, "Unhealthy", now.Add(-10*time.Minute)),
+		timelineEvent("shop", "e2", "Node", "node-1", corev1.EventTypeNormal, "NodeReady", now.Add(-20*time.Minute)),
+	)
+
+	tl, err := BuildEventTimeline(context.Background(), client, TimelineConfig{Namespace: "shop", Window: time.Hour, Now: now})
+	require.NoError(t, err)
+	assert.Empty(t, tl.Workload)
+	assert.Equal(t, "1h", tl.Window)
+	require.Len(t, tl.Entries, 2)
+	assert.Equal(t, "Node/node-1", tl.Entries[0].Object)
+	assert.Empty(t, tl.Entries[0].Workload)
+	assert.Equal(t, "Pod/web-1", tl.Entries[1].Object)
+	assert.Equal(t, 1, tl.Summary.Warnings)
+}
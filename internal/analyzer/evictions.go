@@ -0,0 +1,464 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// DefaultEvictionWindow is how far back evictions are considered.
+const DefaultEvictionWindow = 7 * 24 * time.Hour
+
+// Eviction signals reported in EvictionWorkload.Signals and NodePressure.
+const (
+	EvictionSignalMemory           = "memory"
+	EvictionSignalEphemeralStorage = "ephemeral-storage" // node disk pressure or the pod's own storage limit
+	EvictionSignalPIDs             = "pids"
+	EvictionSignalPreemption       = "preemption"
+	EvictionSignalTaint            = "node-taint" // NoExecute taint, e.g. an unreachable node
+	EvictionSignalOther            = "other"
+)
+
+// Pod event reasons that record an eviction, and the signal each implies
+// when the message does not name one.
+var podEvictionReasons = map[string]string{
+	"Evicted":              EvictionSignalOther,
+	preemptedReason:        EvictionSignalPreemption,
+	"TaintManagerEviction": EvictionSignalTaint,
+}
+
+// Node event reasons that record resource pressure.
+var nodePressureReasons = map[string]string{
+	"EvictionThresholdMet":      "",
+	"NodeHasInsufficientMemory": EvictionSignalMemory,
+	"NodeHasDiskPressure":       EvictionSignalEphemeralStorage,
+	"NodeHasInsufficientPID":    EvictionSignalPIDs,
+}
+
+var (
+	// "The node was low on resource: memory."
+	lowOnResourceRe = regexp.MustCompile(`low on resource: ([a-z-]+)`)
+	// "Attempting to reclaim memory"
+	reclaimRe = regexp.MustCompile(`reclaim ([a-z-]+)`)
+	// "Container app was using 700Mi, request is 256Mi" (older kubelets) or
+	// "Container app was using 700Mi, which exceeds its request of 256Mi"
+	containerUsageRe = regexp.MustCompile(`Container (\S+) was using (\S+?), (?:request is|which exceeds its request of) ([^,.\s]+)`)
+)
+
+// EvictionsConfig holds configuration for the eviction analysis.
+type EvictionsConfig struct {
+	Namespace string        // "" = all namespaces
+	Window    time.Duration // lookback (0 = DefaultEvictionWindow)
+	Now       time.Time     // zero = time.Now(); set by tests
+}
+
+// EvictionsResult is the outcome of AnalyzeEvictions.
+type EvictionsResult struct {
+	Window          string             `json:"window"`
+	DefaultPriority int32              `json:"default_priority"`
+	Workloads       []EvictionWorkload `json:"workloads"`
+	NodePressure    []NodePressure     `json:"node_pressure"`
+	GeneratedAt     time.Time          `json:"generated_at"`
+}
+
+// EvictionWorkload is a workload with evicted or preempted pods in the window.
+type EvictionWorkload struct {
+	Namespace       string           `json:"namespace"`
+	Workload        string           `json:"workload"`
+	Kind            string           `json:"kind"`
+	Evictions       int              `json:"evictions"`
+	Repeated        bool             `json:"repeated"`
+	Signals         map[string]int   `json:"signals"` // signal -> evictions
+	Nodes           []string         `json:"nodes,omitempty"`
+	LastEviction    time.Time        `json:"last_eviction"`
+	PriorityClass   string           `json:"priority_class,omitempty"`
+	Priority        int32            `json:"priority"`
+	QOSClass        string           `json:"qos_class,omitempty"`
+	OverRequest     []ContainerUsage `json:"over_request,omitempty"`
+	Recommendations []string         `json:"recommendations,omitempty"`
+}
+
+// ContainerUsage is the highest usage above its request the kubelet
+// reported for a container when evicting it.
+type ContainerUsage struct {
+	Container    string  `json:"container"`
+	Resource     string  `json:"resource"`
+	UsedBytes    float64 `json:"used_bytes"`
+	RequestBytes float64 `json:"request_bytes"`
+}
+
+// NodePressure counts a node's resource pressure events in the window.
+type NodePressure struct {
+	Node     string    `json:"node"`
+	Signal   string    `json:"signal"`
+	Events   int       `json:"events"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// evictionState accumulates a workload's evictions.
+type evictionState struct {
+	result     EvictionWorkload
+	nodes      map[string]bool
+	usage      map[string]*ContainerUsage // container/resource -> highest usage
+	storageCap bool                       // evicted for exceeding its own ephemeral-storage limit
+}
+
+// AnalyzeEvictions aggregates evicted pods, eviction and preemption events,
+// and node pressure events in the window per workload, with each workload's
+// PriorityClass. The kubelet evicts pods using more than they request first,
+// then lower-priority pods, so each workload gets the request and
+// PriorityClass changes that would keep it from being picked.
+//
+// Evicted pods stay listed until the pod garbage collector removes them,
+// while events expire after the API server's event TTL (1h by default), so
+// older evictions of replaced pods may be missing.
+//
+//nolint:gocyclo // sequential list → aggregate → recommend pipeline
+func AnalyzeEvictions(ctx context.Context, client kubernetes.Interface, cfg EvictionsConfig) (*EvictionsResult, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultEvictionWindow
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	since := now.Add(-window)
+
+	classes, err := client.SchedulingV1().PriorityClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list priority classes: %w", err)
+	}
+	result := &EvictionsResult{
+		Window:       window.String(),
+		Workloads:    []EvictionWorkload{},
+		NodePressure: []NodePressure{},
+		GeneratedAt:  now.UTC(),
+	}
+	classValues := make(map[string]int32, len(classes.Items))
+	for i := range classes.Items {
+		pc := &classes.Items[i]
+		classValues[pc.Name] = pc.Value
+		if pc.GlobalDefault {
+			result.DefaultPriority = pc.Value
+		}
+	}
+
+	targets, err := listPriorityTargets(ctx, client, cfg.Namespace, classValues, result.DefaultPriority)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	rsOwners := replicaSetDeployments(ctx, client, cfg.Namespace)
+
+	states := make(map[string]*evictionState)
+	state := func(namespace, kind, name string) *evictionState {
+		key := namespace + "/" + kind + "/" + name
+		st := states[key]
+		if st == nil {
+			st = &evictionState{
+				result: EvictionWorkload{Namespace: namespace, Workload: name, Kind: kind, Signals: make(map[string]int)},
+				nodes:  make(map[string]bool),
+				usage:  make(map[string]*ContainerUsage),
+			}
+			st.result.Priority = result.DefaultPriority
+			for _, t := range targets {
+				if t.namespace == namespace && t.kind == kind && t.name == name {
+					st.result.PriorityClass, st.result.Priority = t.class, t.priority
+				}
+			}
+			states[key] = st
+		}
+		return st
+	}
+
+	// Evicted pods that are still listed
+	counted := make(map[string]bool)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodFailed || pod.Status.Reason != "Evicted" {
+			continue
+		}
+		at := podEvictionTime(pod)
+		if at.Before(since) {
+			continue
+		}
+		counted[pod.Namespace+"/"+pod.Name] = true
+		kind, name := podWorkload(pod, rsOwners)
+		st := state(pod.Namespace, kind, name)
+		if kind == "Pod" {
+			st.result.PriorityClass = pod.Spec.PriorityClassName
+			if pod.Spec.Priority != nil {
+				st.result.Priority = *pod.Spec.Priority
+			}
+		}
+		st.result.QOSClass = string(pod.Status.QOSClass)
+		st.add(evictionSignal(pod.Status.Message, EvictionSignalOther), 1, at, pod.Spec.NodeName, pod.Status.Message)
+	}
+
+	// Eviction and preemption events of pods that are gone
+	events, err := client.CoreV1().Events(cfg.Namespace).List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	for i := range events.Items {
+		e := &events.Items[i]
+		fallback, ok := podEvictionReasons[e.Reason]
+		// Field selectors are not honoured by every client (e.g. fakes).
+		if !ok || e.InvolvedObject.Kind != "Pod" || counted[e.InvolvedObject.Namespace+"/"+e.InvolvedObject.Name] {
+			continue
+		}
+		at := eventTime(e)
+		if at.Before(since) {
+			continue
+		}
+		name := matchWorkloadForPod(e.InvolvedObject.Namespace, e.InvolvedObject.Name, targets)
+		if name == "" {
+			continue
+		}
+		kind := ""
+		for _, t := range targets {
+			if t.namespace == e.InvolvedObject.Namespace && t.name == name {
+				kind = t.kind
+			}
+		}
+		count := int(e.Count)
+		if count == 0 {
+			count = 1
+		}
+		signal := fallback
+		if e.Reason == "Evicted" {
+			signal = evictionSignal(e.Message, fallback)
+		}
+		state(e.InvolvedObject.Namespace, kind, name).add(signal, count, at, e.Source.Host, e.Message)
+	}
+
+	result.NodePressure = nodePressureEvents(ctx, client, since)
+
+	for _, st := range states {
+		result.Workloads = append(result.Workloads, st.finish(classValues))
+	}
+	sort.SliceStable(result.Workloads, func(i, j int) bool {
+		a, b := &result.Workloads[i], &result.Workloads[j]
+		if a.Evictions != b.Evictions {
+			return a.Evictions > b.Evictions
+		}
+		if !a.LastEviction.Equal(b.LastEviction) {
+			return a.LastEviction.After(b.LastEviction)
+		}
+		return a.Namespace+"/"+a.Workload < b.Namespace+"/"+b.Workload
+	})
+	return result, nil
+}
+
+// add records count evictions by signal, parsing the kubelet's message for
+// containers that used more than they requested.
+func (st *evictionState) add(signal string, count int, at time.Time, node, message string) {
+	st.result.Evictions += count
+	st.result.Signals[signal] += count
+	if at.After(st.result.LastEviction) {
+		st.result.LastEviction = at
+	}
+	if node != "" {
+		st.nodes[node] = true
+	}
+	if strings.Contains(message, "exceeds the total limit") || strings.Contains(message, "exceeds the limit") {
+		st.storageCap = true
+	}
+
+	for _, m := range containerUsageRe.FindAllStringSubmatch(message, -1) {
+		used, err := resource.ParseQuantity(m[2])
+		if err != nil {
+			continue
+		}
+		request, err := resource.ParseQuantity(m[3])
+		if err != nil {
+			continue
+		}
+		key := m[1] + "/" + signal
+		u := st.usage[key]
+		if u == nil {
+			u = &ContainerUsage{Container: m[1], Resource: signal}
+			st.usage[key] = u
+		}
+		if v := float64(used.Value()); v > u.UsedBytes {
+			u.UsedBytes = v
+			u.RequestBytes = float64(request.Value())
+		}
+	}
+}
+
+// finish sorts the collected details and derives the recommendations.
+//
+//nolint:gocyclo // one independent check per recommendation
+func (st *evictionState) finish(classValues map[string]int32) EvictionWorkload {
+	w := st.result
+	w.Repeated = w.Evictions > 1
+	for node := range st.nodes {
+		w.Nodes = append(w.Nodes, node)
+	}
+	sort.Strings(w.Nodes)
+	for _, u := range st.usage {
+		w.OverRequest = append(w.OverRequest, *u)
+	}
+	sort.Slice(w.OverRequest, func(i, j int) bool {
+		return w.OverRequest[i].Container+w.OverRequest[i].Resource < w.OverRequest[j].Container+w.OverRequest[j].Resource
+	})
+
+	for _, u := range w.OverRequest {
+		w.Recommendations = append(w.Recommendations, fmt.Sprintf(
+			"raise %s requests of %s from %s to at least %s, its usage when evicted: pods using more than they request are evicted first",
+			u.Resource, u.Container, units.Memory(u.RequestBytes), units.Memory(u.UsedBytes)))
+	}
+	if w.QOSClass == string(corev1.PodQOSBestEffort) {
+		w.Recommendations = append(w.Recommendations,
+			"set CPU and memory requests: BestEffort pods are the first eviction candidates under node pressure")
+	}
+	if st.storageCap {
+		w.Recommendations = append(w.Recommendations,
+			"raise the ephemeral-storage limit or move scratch data to a volume: the pod exceeded its own storage limit")
+	}
+	if w.Signals[EvictionSignalPIDs] > 0 {
+		w.Recommendations = append(w.Recommendations, "look for a process or thread leak: the node ran out of PIDs")
+	}
+	if w.Signals[EvictionSignalTaint] > 0 {
+		w.Recommendations = append(w.Recommendations,
+			"spread replicas across nodes (topologySpreadConstraints): taint evictions follow node failures")
+	}
+	pressure := w.Evictions - w.Signals[EvictionSignalTaint]
+	if pressure > 0 {
+		if class, value := higherPriorityClass(classValues, w.Priority); class != "" {
+			w.Recommendations = append(w.Recommendations, fmt.Sprintf(
+				"set priorityClassName: %s (priority %d > %d) so lower-priority pods are evicted or preempted first",
+				class, value, w.Priority))
+		} else if w.Signals[EvictionSignalPreemption] > 0 {
+			w.Recommendations = append(w.Recommendations, fmt.Sprintf(
+				"create a PriorityClass above %d for this workload: it is preempted by higher-priority pods", w.Priority))
+		}
+	}
+	return w
+}
+
+// higherPriorityClass returns the lowest-valued non-system PriorityClass
+// above priority: the smallest step that outranks the current class.
+func higherPriorityClass(classValues map[string]int32, priority int32) (string, int32) {
+	best, bestValue := "", int32(0)
+	for name, value := range classValues {
+		if strings.HasPrefix(name, "system-") || value <= priority {
+			continue
+		}
+		if best == "" || value < bestValue || (value == bestValue && name < best) {
+			best, bestValue = name, value
+		}
+	}
+	return best, bestValue
+}
+
+// evictionSignal extracts the resource the kubelet evicted for from its
+// eviction message.
+func evictionSignal(message, fallback string) string {
+	if m := lowOnResourceRe.FindStringSubmatch(message); m != nil {
+		return normalizeEvictionSignal(m[1])
+	}
+	if strings.Contains(message, "ephemeral local storage") || strings.Contains(message, "EmptyDir") {
+		return EvictionSignalEphemeralStorage
+	}
+	return fallback
+}
+
+// normalizeEvictionSignal maps kubelet resource names to eviction signals.
+func normalizeEvictionSignal(name string) string {
+	switch name {
+	case "memory":
+		return EvictionSignalMemory
+	case "ephemeral-storage", "nodefs", "imagefs":
+		return EvictionSignalEphemeralStorage
+	case "pids":
+		return EvictionSignalPIDs
+	default:
+		return EvictionSignalOther
+	}
+}
+
+// podEvictionTime returns when an evicted pod was evicted: its
+// DisruptionTarget condition, or failing that its Ready condition's last
+// transition.
+func podEvictionTime(pod *corev1.Pod) time.Time {
+	var ready time.Time
+	for _, c := range pod.Status.Conditions {
+		switch c.Type {
+		case corev1.DisruptionTarget:
+			return c.LastTransitionTime.Time
+		case corev1.PodReady:
+			ready = c.LastTransitionTime.Time
+		}
+	}
+	if !ready.IsZero() {
+		return ready
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// nodePressureEvents counts resource pressure events per node and signal in
+// the window, most events first.
+func nodePressureEvents(ctx context.Context, client kubernetes.Interface, since time.Time) []NodePressure {
+	out := []NodePressure{}
+	list, err := client.CoreV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=Node"})
+	if err != nil {
+		return out
+	}
+	byKey := make(map[string]*NodePressure)
+	for i := range list.Items {
+		e := &list.Items[i]
+		signal, ok := nodePressureReasons[e.Reason]
+		if !ok || e.InvolvedObject.Kind != "Node" {
+			continue
+		}
+		at := eventTime(e)
+		if at.Before(since) {
+			continue
+		}
+		if signal == "" {
+			signal = EvictionSignalOther
+			if m := reclaimRe.FindStringSubmatch(e.Message); m != nil {
+				signal = normalizeEvictionSignal(m[1])
+			}
+		}
+		key := e.InvolvedObject.Name + "/" + signal
+		p := byKey[key]
+		if p == nil {
+			p = &NodePressure{Node: e.InvolvedObject.Name, Signal: signal}
+			byKey[key] = p
+		}
+		p.Events += max(int(e.Count), 1)
+		if at.After(p.LastSeen) {
+			p.LastSeen = at
+		}
+	}
+	for _, p := range byKey {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Events != out[j].Events {
+			return out[i].Events > out[j].Events
+		}
+		return out[i].Node+out[i].Signal < out[j].Node+out[j].Signal
+	})
+	return out
+}
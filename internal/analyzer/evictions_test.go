@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func evictedPod(ns, name, rs, message string, at time.Time) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: ns,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: rs, Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase:    corev1.PodFailed,
+			Reason:   "Evicted",
+			Message:  message,
+			QOSClass: corev1.PodQOSBurstable,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(at)},
+			},
+		},
+	}
+}
+
+func ownedReplicaSet(ns, name, deployment string) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: name, Namespace: ns,
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: deployment, Controller: &controller}},
+	}}
+}
+
+func TestAnalyzeEvictions(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	memMsg := "The node was low on resource: memory. Threshold quantity: 100Mi, available: 80Mi. " +
+		"Container app was using 700Mi, which exceeds its request of 256Mi. "
+	client := fake.NewClientset(
+		&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "system-cluster-critical"}, Value: 2000000000},
+		&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "business-critical"}, Value: 100000},
+		&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: 10000},
+		priorityDeployment("shop", "cart", ""),
+		priorityDeployment("batch", "etl", ""),
+		ownedReplicaSet("shop", "cart-7d9f", "cart"),
+		evictedPod("shop", "cart-7d9f-aaaaa", "cart-7d9f", memMsg, now.Add(-time.Hour)),
+		evictedPod("shop", "cart-7d9f-bbbbb", "cart-7d9f",
+			"The node was low on resource: memory. Container app was using 600Mi, request is 256Mi.", now.Add(-2*time.Hour)),
+		evictedPod("shop", "cart-7d9f-old00", "cart-7d9f", memMsg, now.Add(-30*24*time.Hour)),
+		preemptionEvent("batch", "etl-5c6d7-xyz12", now.Add(-time.Hour)),
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "node-1.pressure", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			Reason:         "EvictionThresholdMet",
+			Message:        "Attempting to reclaim memory",
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Hour)),
+		},
+	)
+
+	result, err := AnalyzeEvictions(context.Background(), client, EvictionsConfig{Now: now})
+	require.NoError(t, err)
+	require.Len(t, result.Workloads, 2)
+
+	// Tied on evictions and last eviction: sorted by namespace/workload
+	etl, cart := result.Workloads[0], result.Workloads[1]
+	assert.Equal(t, "cart", cart.Workload)
+	assert.Equal(t, "Deployment", cart.Kind)
+	assert.Equal(t, 2, cart.Evictions)
+	assert.True(t, cart.Repeated)
+	assert.Equal(t, map[string]int{EvictionSignalMemory: 2}, cart.Signals)
+	assert.Equal(t, []string{"node-1"}, cart.Nodes)
+	require.Len(t, cart.OverRequest, 1)
+	assert.Equal(t, float64(700*1024*1024), cart.OverRequest[0].UsedBytes)
+	assert.Equal(t, float64(256*1024*1024), cart.OverRequest[0].RequestBytes)
+	require.Len(t, cart.Recommendations, 2)
+	assert.Contains(t, cart.Recommendations[0], "raise memory requests of app")
+	assert.Contains(t, cart.Recommendations[1], "priorityClassName: high")
+
+	assert.Equal(t, "etl", etl.Workload)
+	assert.Equal(t, 2, etl.Evictions) // the event's count
+	assert.Equal(t, map[string]int{EvictionSignalPreemption: 2}, etl.Signals)
+	assert.Contains(t, etl.Recommendations, "set priorityClassName: high (priority 10000 > 0) so lower-priority pods are evicted or preempted first")
+
+	require.Len(t, result.NodePressure, 1)
+	assert.Equal(t, NodePressure{Node: "node-1", Signal: EvictionSignalMemory, Events: 3, LastSeen: now.Add(-time.Hour)},
+		result.NodePressure[0])
+}
+
+func TestEvictionSignal(t *testing.T) {
+	assert.Equal(t, EvictionSignalEphemeralStorage, evictionSignal("The node was low on resource: ephemeral-storage.", EvictionSignalOther))
+	assert.Equal(t, EvictionSignalEphemeralStorage, evictionSignal("The node was low on resource: nodefs.", EvictionSignalOther))
+	assert.Equal(t, EvictionSignalEphemeralStorage,
+		evictionSignal("Pod ephemeral local storage usage exceeds the total limit of containers 1Gi.", EvictionSignalOther))
+	assert.Equal(t, EvictionSignalPIDs, evictionSignal("The node was low on resource: pids.", EvictionSignalOther))
+	assert.Equal(t, EvictionSignalTaint, evictionSignal("Marking for deletion Pod shop/cart-1", EvictionSignalTaint))
+}
+
+func TestHigherPriorityClass(t *testing.T) {
+	classes := map[string]int32{"system-node-critical": 2000001000, "high": 10000, "medium": 1000}
+	class, value := higherPriorityClass(classes, 0)
+	assert.Equal(t, "medium", class)
+	assert.Equal(t, int32(1000), value)
+
+	class, _ = higherPriorityClass(classes, 10000)
+	assert.Empty(t, class)
+}
@@ -0,0 +1,500 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// HPA skew analysis defaults.
+const (
+	DefaultHPASkewWindow    = 7 * 24 * time.Hour
+	DefaultHPASkewStep      = 5 * time.Minute
+	DefaultHPAFlapWindow    = 30 * time.Minute // opposite scale events closer than this are a flap
+	DefaultHPAFlapThreshold = 3                // flaps over the window before an HPA is reported
+
+	// hpaUnreachableRatio is the share of the target that peak utilization
+	// must reach; below it the HPA cannot scale out at current requests.
+	hpaUnreachableRatio = 0.5
+	// hpaSaturatedPercent is the share of samples at maxReplicas, with
+	// utilization above target, from which maxReplicas is too low.
+	hpaSaturatedPercent = 10
+	// hpaMinSamples leaves scaling behaviour unjudged below one hour of
+	// replica samples at the default step.
+	hpaMinSamples = 12
+	// hpaCPURounding rounds recommended CPU requests up to 10 millicores.
+	hpaCPURounding = 0.01
+	// hpaMemoryRounding rounds recommended memory requests up to whole mebibytes.
+	hpaMemoryRounding = 1024 * 1024
+)
+
+// HPA finding types.
+const (
+	HPAFindingFixedReplicas     = "fixed-replicas"     // minReplicas == maxReplicas
+	HPAFindingInactive          = "inactive"           // ScalingActive condition is False
+	HPAFindingMissingRequest    = "missing-request"    // utilization target on a container without a request
+	HPAFindingNeverScales       = "never-scales"       // no replica change over the window
+	HPAFindingFlapping          = "flapping"           // repeated scale reversals
+	HPAFindingTargetUnreachable = "target-unreachable" // requests too high for usage to reach the target
+	HPAFindingSaturated         = "saturated"          // pinned at maxReplicas above target
+	HPAFindingMemoryPinned      = "memory-pinned"      // memory target keeps replicas above minReplicas
+)
+
+// HPASkewConfig holds configuration for the HPA alignment analysis.
+type HPASkewConfig struct {
+	Namespace     string        // "" = all namespaces
+	Window        time.Duration // lookback (0 = DefaultHPASkewWindow)
+	Step          time.Duration // sample resolution (0 = DefaultHPASkewStep)
+	FlapWindow    time.Duration // 0 = DefaultHPAFlapWindow
+	FlapThreshold int           // 0 = DefaultHPAFlapThreshold
+	All           bool          // keep HPAs without findings
+	Now           time.Time     // zero = time.Now(); set by tests
+}
+
+// HPASkewResult is the outcome of AnalyzeHPASkew.
+type HPASkewResult struct {
+	Window      string           `json:"window"`
+	Analyzed    int              `json:"analyzed"`
+	Healthy     int              `json:"healthy"`
+	HPAs        []HPASkewEntry   `json:"hpas"` // most findings first
+	GeneratedAt time.Time        `json:"generated_at"`
+	Queries     *querylog.Report `json:"queries,omitempty"` // --include-queries
+}
+
+// HPASkewEntry is one HorizontalPodAutoscaler compared with the usage and
+// replica history of its target.
+type HPASkewEntry struct {
+	Namespace   string            `json:"namespace"`
+	Name        string            `json:"name"`
+	TargetKind  string            `json:"target_kind"`
+	TargetName  string            `json:"target_name"`
+	MinReplicas int32             `json:"min_replicas"`
+	MaxReplicas int32             `json:"max_replicas"`
+	Targets     []HPAMetricTarget `json:"targets"`
+	Replicas    *HPAReplicaStats  `json:"replicas,omitempty"` // nil without enough kube-state-metrics samples
+	Findings    []HPAFinding      `json:"findings"`
+}
+
+// HPAMetricTarget is one metric of an HPA. Only resource utilization
+// targets (cpu, memory) are compared with usage.
+type HPAMetricTarget struct {
+	Metric            string  `json:"metric"` // cpu, memory, or the metric source type
+	TargetUtilization int32   `json:"target_utilization,omitempty"`
+	RequestPerPod     float64 `json:"request_per_pod,omitempty"`    // cores or bytes
+	PeakUsagePerPod   float64 `json:"peak_usage_per_pod,omitempty"` // cores or bytes
+	UtilizationP50    float64 `json:"utilization_p50,omitempty"`    // percent of requests
+	UtilizationP95    float64 `json:"utilization_p95,omitempty"`
+	UtilizationPeak   float64 `json:"utilization_peak,omitempty"`
+	Evaluated         bool    `json:"evaluated"`
+}
+
+// HPAReplicaStats summarizes an HPA's replica history.
+type HPAReplicaStats struct {
+	Samples      int     `json:"samples"`
+	Min          int     `json:"min"`
+	Max          int     `json:"max"`
+	ScaleEvents  int     `json:"scale_events"`
+	Flaps        int     `json:"flaps"`
+	AtMinPercent float64 `json:"at_min_percent"`
+	AtMaxPercent float64 `json:"at_max_percent"`
+}
+
+// HPAFinding is one problem with an HPA.
+type HPAFinding struct {
+	Type           string `json:"type"`
+	Message        string `json:"message"`
+	Recommendation string `json:"recommendation,omitempty"`
+}
+
+// hpaUtilization holds a target's utilization samples, overall and at the
+// replica bounds.
+type hpaUtilization struct {
+	all       []float64
+	peakUsage float64
+	overAtMax bool // utilization above target while at maxReplicas
+}
+
+// AnalyzeHPASkew compares each HPA's target utilization and replica bounds
+// with its scaling history (kube_horizontalpodautoscaler_status_current_replicas)
+// and its target's usage, and reports HPAs that never scale, flap, sit at
+// maxReplicas, or have targets that usage cannot reach at current requests.
+// CPU utilization is the workload's usage divided by replicas; memory uses
+// the hottest replica, so memory findings are an upper bound.
+func AnalyzeHPASkew(
+	ctx context.Context, client kubernetes.Interface, provider metrics.MetricsProvider, cfg HPASkewConfig,
+) (*HPASkewResult, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultHPASkewWindow
+	}
+	step := cfg.Step
+	if step <= 0 {
+		step = DefaultHPASkewStep
+	}
+	if cfg.FlapWindow <= 0 {
+		cfg.FlapWindow = DefaultHPAFlapWindow
+	}
+	if cfg.FlapThreshold <= 0 {
+		cfg.FlapThreshold = DefaultHPAFlapThreshold
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	start := now.Add(-window)
+
+	hpas, err := client.AutoscalingV2().HorizontalPodAutoscalers(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HPAs: %w", err)
+	}
+
+	result := &HPASkewResult{
+		Window:      window.String(),
+		HPAs:        []HPASkewEntry{},
+		GeneratedAt: now.UTC(),
+	}
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		entry, err := analyzeHPA(ctx, client, provider, hpa, start, now, step, cfg)
+		if err != nil {
+			return nil, err
+		}
+		result.Analyzed++
+		if len(entry.Findings) == 0 {
+			result.Healthy++
+			if !cfg.All {
+				continue
+			}
+		}
+		result.HPAs = append(result.HPAs, *entry)
+	}
+	sort.Slice(result.HPAs, func(i, j int) bool {
+		a, b := &result.HPAs[i], &result.HPAs[j]
+		if len(a.Findings) != len(b.Findings) {
+			return len(a.Findings) > len(b.Findings)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return result, nil
+}
+
+func analyzeHPA(
+	ctx context.Context, client kubernetes.Interface, provider metrics.MetricsProvider,
+	hpa *autoscalingv2.HorizontalPodAutoscaler, start, end time.Time, step time.Duration, cfg HPASkewConfig,
+) (*HPASkewEntry, error) {
+	ref := hpa.Spec.ScaleTargetRef
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	entry := &HPASkewEntry{
+		Namespace:   hpa.Namespace,
+		Name:        hpa.Name,
+		TargetKind:  ref.Kind,
+		TargetName:  ref.Name,
+		MinReplicas: minReplicas,
+		MaxReplicas: hpa.Spec.MaxReplicas,
+		Findings:    []HPAFinding{},
+	}
+
+	if minReplicas == hpa.Spec.MaxReplicas {
+		entry.addFinding(HPAFindingFixedReplicas,
+			fmt.Sprintf("minReplicas equals maxReplicas (%d): the HPA cannot scale", minReplicas),
+			"widen the replica range or replace the HPA with a fixed replica count")
+	}
+	for _, c := range hpa.Status.Conditions {
+		if c.Type == autoscalingv2.ScalingActive && c.Status == corev1.ConditionFalse {
+			entry.addFinding(HPAFindingInactive, fmt.Sprintf("scaling is inactive (%s): %s", c.Reason, c.Message),
+				"fix the metric source the HPA reads; until then it holds the current replica count")
+		}
+	}
+
+	template := hpaPodTemplate(ctx, client, hpa.Namespace, ref.Kind, ref.Name)
+	for _, m := range hpa.Spec.Metrics {
+		target := HPAMetricTarget{Metric: string(m.Type)}
+		if m.Type == autoscalingv2.ResourceMetricSourceType && m.Resource != nil {
+			target.Metric = string(m.Resource.Name)
+			if m.Resource.Target.Type == autoscalingv2.UtilizationMetricType && m.Resource.Target.AverageUtilization != nil {
+				target.TargetUtilization = *m.Resource.Target.AverageUtilization
+			}
+		}
+		if target.TargetUtilization > 0 && template != nil {
+			request, missing := podRequest(template, m.Resource.Name)
+			target.RequestPerPod = request
+			for _, name := range missing {
+				entry.addFinding(HPAFindingMissingRequest,
+					fmt.Sprintf("container %s has no %s request: the HPA cannot compute %s utilization", name, target.Metric, target.Metric),
+					fmt.Sprintf("set a %s request on every container of %s/%s", target.Metric, ref.Kind, ref.Name))
+			}
+		}
+		entry.Targets = append(entry.Targets, target)
+	}
+
+	replicas, err := provider.GetHPAReplicaSeries(ctx, hpa.Namespace, hpa.Name, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	if len(replicas) < hpaMinSamples {
+		return entry, nil
+	}
+	entry.Replicas = replicaStats(replicas, minReplicas, hpa.Spec.MaxReplicas, cfg.FlapWindow)
+
+	for i := range entry.Targets {
+		t := &entry.Targets[i]
+		if t.TargetUtilization == 0 || t.RequestPerPod == 0 {
+			continue
+		}
+		util, err := targetUtilization(ctx, provider, entry, t, replicas, start, end, step)
+		if err != nil {
+			return nil, err
+		}
+		if util == nil {
+			continue
+		}
+		sorted := append([]float64(nil), util.all...)
+		sort.Float64s(sorted)
+		t.Evaluated = true
+		t.UtilizationP50 = quantileSorted(sorted, 0.5)
+		t.UtilizationP95 = quantileSorted(sorted, 0.95)
+		t.UtilizationPeak = sorted[len(sorted)-1]
+		t.PeakUsagePerPod = util.peakUsage
+		entry.judgeTarget(t, util)
+	}
+	entry.judgeReplicas(cfg.FlapThreshold, cfg.FlapWindow)
+	return entry, nil
+}
+
+func (e *HPASkewEntry) addFinding(kind, message, recommendation string) {
+	e.Findings = append(e.Findings, HPAFinding{Type: kind, Message: message, Recommendation: recommendation})
+}
+
+func (e *HPASkewEntry) hasFinding(kind string) bool {
+	for _, f := range e.Findings {
+		if f.Type == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// judgeTarget reports a target that usage cannot reach, a maxReplicas that
+// is too low, and a memory target that never lets replicas return to
+// minReplicas.
+func (e *HPASkewEntry) judgeTarget(t *HPAMetricTarget, util *hpaUtilization) {
+	target := float64(t.TargetUtilization)
+	stats := e.Replicas
+
+	if stats.Max == int(e.MinReplicas) && e.MinReplicas < e.MaxReplicas && t.UtilizationPeak < target*hpaUnreachableRatio {
+		e.addFinding(HPAFindingTargetUnreachable,
+			fmt.Sprintf("peak %s utilization %.0f%% of requests never approaches the %d%% target: replicas stayed at minReplicas (%d)",
+				t.Metric, t.UtilizationPeak, t.TargetUtilization, e.MinReplicas),
+			fmt.Sprintf("lower the %s request to %s per pod so peak usage reaches the target, or lower the target",
+				t.Metric, formatHPAQuantity(t.Metric, recommendHPARequest(t))))
+	}
+	if util.overAtMax && stats.AtMaxPercent >= hpaSaturatedPercent && !e.hasFinding(HPAFindingSaturated) {
+		e.addFinding(HPAFindingSaturated,
+			fmt.Sprintf("at maxReplicas (%d) for %.0f%% of the window with %s utilization above the %d%% target",
+				e.MaxReplicas, stats.AtMaxPercent, t.Metric, t.TargetUtilization),
+			"raise maxReplicas, or raise requests if each pod is undersized")
+	}
+	if t.Metric == string(corev1.ResourceMemory) && stats.Min > int(e.MinReplicas) && t.UtilizationP50 >= target {
+		e.addFinding(HPAFindingMemoryPinned,
+			fmt.Sprintf("memory utilization (P50 %.0f%%) stays above the %d%% target: replicas never dropped below %d (minReplicas %d)",
+				t.UtilizationP50, t.TargetUtilization, stats.Min, e.MinReplicas),
+			"memory rarely falls as replicas are added; scale on CPU or a request-rate metric, or raise the memory target")
+	}
+}
+
+// judgeReplicas reports flapping, and an HPA that never scaled for reasons
+// no other finding explains.
+func (e *HPASkewEntry) judgeReplicas(flapThreshold int, flapWindow time.Duration) {
+	stats := e.Replicas
+	if stats.Flaps >= flapThreshold {
+		e.addFinding(HPAFindingFlapping,
+			fmt.Sprintf("%d scale reversals within %s of the previous change (%d scale events)", stats.Flaps, flapWindow, stats.ScaleEvents),
+			"set behavior.scaleDown.stabilizationWindowSeconds (e.g. 300) or leave more headroom between typical utilization and the target")
+	}
+	if stats.ScaleEvents > 0 || e.MinReplicas == e.MaxReplicas ||
+		e.hasFinding(HPAFindingTargetUnreachable) || e.hasFinding(HPAFindingSaturated) || e.hasFinding(HPAFindingMemoryPinned) {
+		return
+	}
+	e.addFinding(HPAFindingNeverScales,
+		fmt.Sprintf("replicas stayed at %d for the whole window (range %d-%d)", stats.Min, e.MinReplicas, e.MaxReplicas),
+		"if load is steady, replace the HPA with a fixed replica count; otherwise check that its metrics reflect load")
+}
+
+// replicaStats counts scale events and flaps: a change in the opposite
+// direction of the previous one within flapWindow.
+func replicaStats(values []model.SamplePair, minReplicas, maxReplicas int32, flapWindow time.Duration) *HPAReplicaStats {
+	stats := &HPAReplicaStats{Samples: len(values), Min: math.MaxInt, Max: 0}
+	var atMin, atMax int
+	var lastChange model.Time
+	lastDirection := 0
+	for i, v := range values {
+		n := int(v.Value)
+		stats.Min = min(stats.Min, n)
+		stats.Max = max(stats.Max, n)
+		if n == int(minReplicas) {
+			atMin++
+		}
+		if n == int(maxReplicas) {
+			atMax++
+		}
+		if i == 0 {
+			continue
+		}
+		prev := int(values[i-1].Value)
+		if n == prev {
+			continue
+		}
+		stats.ScaleEvents++
+		direction := 1
+		if n < prev {
+			direction = -1
+		}
+		if lastDirection != 0 && direction != lastDirection && v.Timestamp.Sub(lastChange) <= flapWindow {
+			stats.Flaps++
+		}
+		lastDirection, lastChange = direction, v.Timestamp
+	}
+	stats.AtMinPercent = float64(atMin) / float64(len(values)) * 100
+	stats.AtMaxPercent = float64(atMax) / float64(len(values)) * 100
+	return stats
+}
+
+// targetUtilization returns per-pod utilization of a resource target at
+// each step that has both usage and replica samples, or nil without usage.
+func targetUtilization(
+	ctx context.Context, provider metrics.MetricsProvider, e *HPASkewEntry, t *HPAMetricTarget,
+	replicas []model.SamplePair, start, end time.Time, step time.Duration,
+) (*hpaUtilization, error) {
+	replicasAt := make(map[model.Time]float64, len(replicas))
+	for _, v := range replicas {
+		replicasAt[v.Timestamp] = float64(v.Value)
+	}
+
+	// usage per pod at each step
+	perPod := map[model.Time]float64{}
+	switch t.Metric {
+	case string(corev1.ResourceCPU):
+		series, err := provider.GetWorkloadCPUSeries(ctx, e.Namespace, e.TargetName, e.TargetKind, start, end, step)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range series {
+			if n := replicasAt[v.Timestamp]; n > 0 {
+				perPod[v.Timestamp] = float64(v.Value) / n
+			}
+		}
+	case string(corev1.ResourceMemory):
+		series, err := provider.GetWorkloadMemorySeries(ctx, e.Namespace, e.TargetName, e.TargetKind, start, end, step)
+		if err != nil {
+			return nil, err
+		}
+		for _, container := range series {
+			for _, v := range container {
+				if replicasAt[v.Timestamp] > 0 {
+					perPod[v.Timestamp] += float64(v.Value)
+				}
+			}
+		}
+	default:
+		return nil, nil
+	}
+	if len(perPod) == 0 {
+		return nil, nil
+	}
+
+	util := &hpaUtilization{all: make([]float64, 0, len(perPod))}
+	for ts, usage := range perPod {
+		pct := usage / t.RequestPerPod * 100
+		util.all = append(util.all, pct)
+		util.peakUsage = math.Max(util.peakUsage, usage)
+		if int32(replicasAt[ts]) == e.MaxReplicas && pct > float64(t.TargetUtilization) {
+			util.overAtMax = true
+		}
+	}
+	return util, nil
+}
+
+// recommendHPARequest sizes a per-pod request at which peak usage reaches
+// the target utilization.
+func recommendHPARequest(t *HPAMetricTarget) float64 {
+	request := t.PeakUsagePerPod / (float64(t.TargetUtilization) / 100)
+	rounding := float64(hpaCPURounding)
+	if t.Metric == string(corev1.ResourceMemory) {
+		rounding = hpaMemoryRounding
+	}
+	return math.Max(rounding, math.Ceil(request/rounding-1e-9)*rounding)
+}
+
+func formatHPAQuantity(metric string, v float64) string {
+	if metric == string(corev1.ResourceMemory) {
+		return units.Memory(v)
+	}
+	return units.Cores(v) + " cores"
+}
+
+// podRequest sums a pod template's requests for a resource and names the
+// containers without one.
+func podRequest(template *corev1.PodTemplateSpec, resourceName corev1.ResourceName) (total float64, missing []string) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		q, ok := c.Resources.Requests[resourceName]
+		if !ok || q.IsZero() {
+			missing = append(missing, c.Name)
+			continue
+		}
+		total += q.AsApproximateFloat64()
+	}
+	return total, missing
+}
+
+// hpaPodTemplate returns the pod template of an HPA's scale target, or nil
+// for kinds it cannot read (custom resources) or a missing target.
+func hpaPodTemplate(ctx context.Context, client kubernetes.Interface, namespace, kind, name string) *corev1.PodTemplateSpec {
+	switch kind {
+	case "Deployment":
+		if d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return &d.Spec.Template
+		}
+	case "StatefulSet":
+		if s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return &s.Spec.Template
+		}
+	case "ReplicaSet":
+		if r, err := client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return &r.Spec.Template
+		}
+	}
+	return nil
+}
+
+// quantileSorted interpolates the q quantile of sorted values.
+func quantileSorted(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := q * float64(len(sorted)-1)
+	lower := int(idx)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := idx - float64(lower)
+	return sorted[lower]*(1-frac) + sorted[lower+1]*frac
+}
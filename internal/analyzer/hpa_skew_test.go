@@ -0,0 +1,170 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func hpaDeployment(name, cpuRequest string) *appsv1.Deployment {
+	requests := corev1.ResourceList{}
+	if cpuRequest != "" {
+		requests[corev1.ResourceCPU] = resource.MustParse(cpuRequest)
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Requests: requests}}},
+		}}},
+	}
+}
+
+func cpuHPA(name string, minReplicas, maxReplicas, target int32) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: name},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    maxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &target},
+				},
+			}},
+		},
+	}
+}
+
+// hpaSeries builds one sample per 5 minutes from now-len*5m.
+func hpaSeries(now time.Time, values ...float64) []model.SamplePair {
+	out := make([]model.SamplePair, len(values))
+	for i, v := range values {
+		ts := now.Add(-time.Duration(len(values)-i) * DefaultHPASkewStep)
+		out[i] = model.SamplePair{Timestamp: model.TimeFromUnixNano(ts.UnixNano()), Value: model.SampleValue(v)}
+	}
+	return out
+}
+
+func repeat(v float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
+
+func findingTypes(e HPASkewEntry) []string {
+	var types []string
+	for _, f := range e.Findings {
+		types = append(types, f.Type)
+	}
+	return types
+}
+
+func TestAnalyzeHPASkew(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := fake.NewClientset(
+		hpaDeployment("api", "500m"), cpuHPA("api", 2, 10, 70),
+		hpaDeployment("web", "1"), cpuHPA("web", 2, 10, 70),
+		hpaDeployment("worker", "1"), cpuHPA("worker", 1, 5, 60),
+		hpaDeployment("fixed", "1"), cpuHPA("fixed", 3, 3, 70),
+		hpaDeployment("nocpu", ""), cpuHPA("nocpu", 1, 4, 70),
+		hpaDeployment("healthy", "1"), cpuHPA("healthy", 2, 6, 70),
+	)
+
+	provider := metrics.NewMockMetrics()
+	// api: 0.2 cores over 2 pods = 20% of a 500m request, target 70%.
+	provider.HPAReplicas["prod/api"] = hpaSeries(now, repeat(2, 24)...)
+	provider.CPUSeries["prod/api"] = hpaSeries(now, repeat(0.2, 24)...)
+	// web: 3 -> 4 -> 3 every 5 minutes.
+	webReplicas := make([]float64, 24)
+	for i := range webReplicas {
+		webReplicas[i] = float64(3 + i%2)
+	}
+	provider.HPAReplicas["prod/web"] = hpaSeries(now, webReplicas...)
+	provider.CPUSeries["prod/web"] = hpaSeries(now, repeat(2.5, 24)...)
+	// worker: at maxReplicas with 90% utilization.
+	provider.HPAReplicas["prod/worker"] = hpaSeries(now, repeat(5, 24)...)
+	provider.CPUSeries["prod/worker"] = hpaSeries(now, repeat(4.5, 24)...)
+	// healthy: scales 2 -> 3 and back hours apart, near target.
+	healthy := append(append(repeat(2, 12), repeat(3, 12)...), repeat(2, 12)...)
+	provider.HPAReplicas["prod/healthy"] = hpaSeries(now, healthy...)
+	provider.CPUSeries["prod/healthy"] = hpaSeries(now, repeat(1.3, 36)...)
+
+	result, err := AnalyzeHPASkew(context.Background(), client, provider, HPASkewConfig{Namespace: "prod", Now: now})
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.Analyzed)
+	assert.Equal(t, 1, result.Healthy)
+	require.Len(t, result.HPAs, 5)
+
+	byName := map[string]HPASkewEntry{}
+	for _, e := range result.HPAs {
+		byName[e.Name] = e
+	}
+
+	api := byName["api"]
+	assert.Equal(t, []string{HPAFindingTargetUnreachable}, findingTypes(api))
+	require.Len(t, api.Targets, 1)
+	assert.True(t, api.Targets[0].Evaluated)
+	assert.InDelta(t, 20.0, api.Targets[0].UtilizationPeak, 0.001)
+	assert.InDelta(t, 0.1, api.Targets[0].PeakUsagePerPod, 0.001)
+	assert.Contains(t, api.Findings[0].Recommendation, "lower the cpu request to 0.15 cores per pod")
+
+	web := byName["web"]
+	assert.Equal(t, []string{HPAFindingFlapping}, findingTypes(web))
+	assert.Equal(t, 23, web.Replicas.ScaleEvents)
+	assert.Equal(t, 22, web.Replicas.Flaps)
+
+	worker := byName["worker"]
+	assert.Equal(t, []string{HPAFindingSaturated}, findingTypes(worker), "saturation explains the missing scale events")
+	assert.InDelta(t, 100.0, worker.Replicas.AtMaxPercent, 0.001)
+
+	assert.Equal(t, []string{HPAFindingFixedReplicas}, findingTypes(byName["fixed"]))
+	assert.Nil(t, byName["fixed"].Replicas, "no replica samples")
+
+	nocpu := byName["nocpu"]
+	assert.Equal(t, []string{HPAFindingMissingRequest}, findingTypes(nocpu))
+	assert.Contains(t, nocpu.Findings[0].Message, "container app has no cpu request")
+
+	all, err := AnalyzeHPASkew(context.Background(), client, provider, HPASkewConfig{Namespace: "prod", Now: now, All: true})
+	require.NoError(t, err)
+	require.Len(t, all.HPAs, 6)
+	assert.Equal(t, "healthy", all.HPAs[5].Name, "HPAs without findings sort last")
+}
+
+func TestReplicaStats(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		values []float64
+		events int
+		flaps  int
+	}{
+		{"steady", repeat(3, 12), 0, 0},
+		{"scale out and in hours apart", append(append(repeat(2, 12), repeat(4, 12)...), repeat(2, 12)...), 2, 0},
+		{"reversal within the flap window", []float64{2, 2, 3, 3, 2, 2}, 2, 1},
+		{"same direction is not a flap", []float64{2, 3, 4, 5}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := replicaStats(hpaSeries(now, tt.values...), 2, 5, DefaultHPAFlapWindow)
+			assert.Equal(t, tt.events, stats.ScaleEvents)
+			assert.Equal(t, tt.flaps, stats.Flaps)
+			assert.Equal(t, len(tt.values), stats.Samples)
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Image pull failure classes reported in PulledImage.Class.
+const (
+	PullClassAuth        = "auth"         // the registry denied access
+	PullClassNotFound    = "not-found"    // the repository or tag does not exist
+	PullClassRateLimit   = "rate-limit"   // the registry throttled pulls
+	PullClassNetwork     = "network"      // nodes cannot reach the registry
+	PullClassInvalidName = "invalid-name" // the image reference does not parse
+	PullClassUnknown     = "unknown"
+)
+
+// dockerHubRegistry is the registry of image references without a host.
+const dockerHubRegistry = "docker.io"
+
+// ImagePullConfig holds configuration for the image pull analysis.
+type ImagePullConfig struct {
+	Namespace    string        // "" = all namespaces
+	Probe        bool          // probe each failing registry from the CLI
+	ProbeTimeout time.Duration // per request (0 = DefaultProbeTimeout)
+	HTTPClient   *http.Client  // nil = a client with ProbeTimeout; set by tests
+}
+
+// ImagePullResult is the outcome of AnalyzeImagePulls.
+type ImagePullResult struct {
+	Pods       int             `json:"pods"`
+	Registries []RegistryPulls `json:"registries"`
+}
+
+// RegistryPulls groups the failing images of one registry.
+type RegistryPulls struct {
+	Registry string         `json:"registry"`
+	Pods     int            `json:"pods"`
+	Classes  map[string]int `json:"classes"` // class -> images
+	Images   []PulledImage  `json:"images"`
+	Probe    *RegistryProbe `json:"probe,omitempty"`
+}
+
+// PulledImage is an image that fails to pull, with the pods waiting on it.
+type PulledImage struct {
+	Image       string      `json:"image"`
+	Repository  string      `json:"repository"`
+	Tag         string      `json:"tag,omitempty"`
+	Digest      string      `json:"digest,omitempty"`
+	Class       string      `json:"class"`
+	Pods        int         `json:"pods"`
+	Workloads   []string    `json:"workloads"` // namespace/Kind/name
+	PullSecrets []string    `json:"pull_secrets,omitempty"`
+	Message     string      `json:"message,omitempty"`
+	Fix         string      `json:"fix"`
+	Probe       *ImageProbe `json:"probe,omitempty"`
+}
+
+// imageRef is a parsed image reference.
+type imageRef struct {
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+// AnalyzeImagePulls groups containers stuck in ErrImagePull,
+// ImagePullBackOff, or InvalidImageName by registry and image, classifies
+// each failure from the kubelet's pull error as auth, not-found,
+// rate-limit, or network, and suggests a fix. The waiting message of a
+// container in back-off only says it is backing off, so the pod's latest
+// "Failed to pull image" event supplies the cause when there is one. With
+// Probe set, each failing registry is also probed from the CLI.
+//
+//nolint:gocyclo // sequential list → classify → group pipeline
+func AnalyzeImagePulls(ctx context.Context, client kubernetes.Interface, cfg ImagePullConfig) (*ImagePullResult, error) {
+	pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	rsOwners := replicaSetDeployments(ctx, client, cfg.Namespace)
+	pullErrors := pullErrorEvents(ctx, client, cfg.Namespace)
+	saSecrets := serviceAccountPullSecrets(ctx, client, cfg.Namespace)
+
+	result := &ImagePullResult{Registries: []RegistryPulls{}}
+	registries := make(map[string]*RegistryPulls)
+	images := make(map[string]*PulledImage)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		failing := false
+		for _, cs := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+			waiting := cs.State.Waiting
+			if waiting == nil || !isImagePullReason(waiting.Reason) {
+				continue
+			}
+			failing = true
+			image := containerImage(pod, cs.Name, cs.Image)
+			ref, ok := parseImageRef(image)
+
+			message := waiting.Message
+			if msg := pullErrors[pod.Namespace+"/"+pod.Name]; msg != "" && strings.HasPrefix(strings.ToLower(message), "back-off") {
+				message = msg
+			}
+			class := classifyPullError(message)
+			if !ok || waiting.Reason == "InvalidImageName" {
+				class = PullClassInvalidName
+				ref = imageRef{registry: "invalid", repository: image}
+			}
+
+			reg := registries[ref.registry]
+			if reg == nil {
+				reg = &RegistryPulls{Registry: ref.registry, Classes: map[string]int{}}
+				registries[ref.registry] = reg
+			}
+			img := images[image]
+			if img == nil {
+				img = &PulledImage{
+					Image: image, Repository: ref.repository, Tag: ref.tag, Digest: ref.digest,
+					Class: class, Message: firstLine(message),
+				}
+				images[image] = img
+				reg.Images = append(reg.Images, *img)
+			}
+			img.Pods++
+			kind, name := podWorkload(pod, rsOwners)
+			if workload := pod.Namespace + "/" + kind + "/" + name; !slices.Contains(img.Workloads, workload) {
+				img.Workloads = append(img.Workloads, workload)
+			}
+			for _, s := range podPullSecrets(pod, saSecrets) {
+				if !slices.Contains(img.PullSecrets, s) {
+					img.PullSecrets = append(img.PullSecrets, s)
+				}
+			}
+		}
+		if failing {
+			result.Pods++
+		}
+	}
+
+	for _, reg := range registries {
+		for i := range reg.Images {
+			img := images[reg.Images[i].Image]
+			sort.Strings(img.Workloads)
+			sort.Strings(img.PullSecrets)
+			img.Fix = pullFix(img, reg.Registry)
+			reg.Images[i] = *img
+			reg.Classes[img.Class]++
+			reg.Pods += img.Pods
+		}
+		sort.Slice(reg.Images, func(i, j int) bool {
+			if reg.Images[i].Pods != reg.Images[j].Pods {
+				return reg.Images[i].Pods > reg.Images[j].Pods
+			}
+			return reg.Images[i].Image < reg.Images[j].Image
+		})
+		result.Registries = append(result.Registries, *reg)
+	}
+	sort.Slice(result.Registries, func(i, j int) bool {
+		a, b := &result.Registries[i], &result.Registries[j]
+		if a.Pods != b.Pods {
+			return a.Pods > b.Pods
+		}
+		return a.Registry < b.Registry
+	})
+
+	if cfg.Probe {
+		prober := newRegistryProber(cfg.HTTPClient, cfg.ProbeTimeout)
+		for i := range result.Registries {
+			reg := &result.Registries[i]
+			if reg.Registry == "invalid" {
+				continue
+			}
+			reg.Probe = prober.probe(ctx, reg.Registry, reg.Images)
+			applyProbe(reg)
+		}
+	}
+	return result, nil
+}
+
+func isImagePullReason(reason string) bool {
+	return reason == "ErrImagePull" || reason == "ImagePullBackOff" || reason == "InvalidImageName"
+}
+
+// containerImage returns the image of a container as written in the pod
+// spec; the status may hold a resolved reference instead.
+func containerImage(pod *corev1.Pod, container, fallback string) string {
+	for _, c := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+		if c.Name == container {
+			return c.Image
+		}
+	}
+	return fallback
+}
+
+// parseImageRef splits an image reference into registry, repository, tag,
+// and digest, applying Docker Hub's defaults to references without a host.
+func parseImageRef(image string) (imageRef, bool) {
+	if image == "" || strings.ContainsAny(image, " \t\r\n") {
+		return imageRef{}, false
+	}
+	var ref imageRef
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.digest = name[:i], name[i+1:]
+		if !strings.Contains(ref.digest, ":") {
+			return imageRef{}, false
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.tag = name[:i], name[i+1:]
+		if ref.tag == "" {
+			return imageRef{}, false
+		}
+	}
+	first, rest, hasSlash := strings.Cut(name, "/")
+	if hasSlash && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.registry, ref.repository = first, rest
+	} else {
+		ref.registry, ref.repository = dockerHubRegistry, name
+		if !hasSlash {
+			ref.repository = "library/" + name
+		}
+	}
+	if ref.repository == "" || ref.repository != strings.ToLower(ref.repository) {
+		return imageRef{}, false
+	}
+	if ref.tag == "" && ref.digest == "" {
+		ref.tag = "latest"
+	}
+	return ref, true
+}
+
+// classifyPullError classifies a kubelet or container runtime pull error.
+func classifyPullError(message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "toomanyrequests"), strings.Contains(lower, "rate limit"),
+		strings.Contains(lower, "429 too many requests"):
+		return PullClassRateLimit
+	case strings.Contains(lower, "unauthorized"), strings.Contains(lower, "authentication required"),
+		strings.Contains(lower, "pull access denied"), strings.Contains(lower, "403 forbidden"),
+		strings.Contains(lower, "denied:"), strings.Contains(lower, "no basic auth credentials"):
+		return PullClassAuth
+	case strings.Contains(lower, "manifest unknown"), strings.Contains(lower, "not found"),
+		strings.Contains(lower, "name unknown"):
+		return PullClassNotFound
+	case strings.Contains(lower, "no such host"), strings.Contains(lower, "i/o timeout"),
+		strings.Contains(lower, "connection refused"), strings.Contains(lower, "network is unreachable"),
+		strings.Contains(lower, "tls handshake timeout"), strings.Contains(lower, "x509:"),
+		strings.Contains(lower, "context deadline exceeded"), strings.Contains(lower, "connection reset"):
+		return PullClassNetwork
+	case strings.Contains(lower, "invalid reference format"):
+		return PullClassInvalidName
+	default:
+		return PullClassUnknown
+	}
+}
+
+// pullFix suggests the fix for an image's pull failure.
+func pullFix(img *PulledImage, registry string) string {
+	switch img.Class {
+	case PullClassAuth:
+		if len(img.PullSecrets) == 0 {
+			return fmt.Sprintf("add an imagePullSecret with credentials for %s to the pod or its ServiceAccount", registry)
+		}
+		return fmt.Sprintf("imagePullSecrets %s are set: check they hold valid, unexpired credentials for %s",
+			strings.Join(img.PullSecrets, ", "), registry)
+	case PullClassNotFound:
+		return fmt.Sprintf("check the repository %s and tag %s for typos, and that the image was pushed", img.Repository, imageVersion(img))
+	case PullClassRateLimit:
+		return fmt.Sprintf("authenticate pulls from %s with an imagePullSecret, or pull through a mirror or cache", registry)
+	case PullClassNetwork:
+		return fmt.Sprintf("nodes cannot reach %s: check node DNS, egress firewall or proxy, and private endpoints", registry)
+	case PullClassInvalidName:
+		return "fix the image reference: it does not parse (uppercase letters, spaces, or an empty tag)"
+	default:
+		return "inspect the pod's events: kubectl describe pod"
+	}
+}
+
+func imageVersion(img *PulledImage) string {
+	if img.Digest != "" {
+		return img.Digest
+	}
+	return img.Tag
+}
+
+// pullErrorEvents returns the latest pull error message of each pod,
+// keyed namespace/pod.
+func pullErrorEvents(ctx context.Context, client kubernetes.Interface, namespace string) map[string]string {
+	out := make(map[string]string)
+	list, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "reason=Failed"})
+	if err != nil {
+		return out
+	}
+	latest := make(map[string]time.Time)
+	for i := range list.Items {
+		e := &list.Items[i]
+		// Field selectors are not honoured by every client (e.g. fakes).
+		if e.Reason != "Failed" || e.InvolvedObject.Kind != "Pod" || !strings.Contains(e.Message, "Failed to pull image") {
+			continue
+		}
+		key := e.InvolvedObject.Namespace + "/" + e.InvolvedObject.Name
+		if t := eventTime(e); !t.Before(latest[key]) {
+			latest[key] = t
+			out[key] = e.Message
+		}
+	}
+	return out
+}
+
+// serviceAccountPullSecrets returns the imagePullSecrets of each
+// ServiceAccount, keyed namespace/name. Best-effort: without access, only
+// the pods' own secrets are known.
+func serviceAccountPullSecrets(ctx context.Context, client kubernetes.Interface, namespace string) map[string][]string {
+	out := make(map[string][]string)
+	list, err := client.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return out
+	}
+	for i := range list.Items {
+		sa := &list.Items[i]
+		for _, s := range sa.ImagePullSecrets {
+			out[sa.Namespace+"/"+sa.Name] = append(out[sa.Namespace+"/"+sa.Name], s.Name)
+		}
+	}
+	return out
+}
+
+// podPullSecrets lists the pod's imagePullSecrets and its ServiceAccount's.
+func podPullSecrets(pod *corev1.Pod, saSecrets map[string][]string) []string {
+	var out []string
+	for _, s := range pod.Spec.ImagePullSecrets {
+		out = append(out, s.Name)
+	}
+	sa := pod.Spec.ServiceAccountName
+	if sa == "" {
+		sa = "default"
+	}
+	return append(out, saSecrets[pod.Namespace+"/"+sa]...)
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func pullFailingPod(ns, name, rs, image, reason, message string) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: ns,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: rs, Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "app", Image: image,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: message}},
+			}},
+		},
+	}
+}
+
+func pullFailedEvent(ns, pod, message string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: pod + ".failed", Namespace: ns},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: ns, Name: pod},
+		Reason:         "Failed",
+		Message:        message,
+	}
+}
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		image string
+		want  imageRef
+		ok    bool
+	}{
+		{"nginx", imageRef{registry: "docker.io", repository: "library/nginx", tag: "latest"}, true},
+		{"bitnami/redis:7.2", imageRef{registry: "docker.io", repository: "bitnami/redis", tag: "7.2"}, true},
+		{"ghcr.io/acme/api:v1.4.0", imageRef{registry: "ghcr.io", repository: "acme/api", tag: "v1.4.0"}, true},
+		{"localhost:5000/app", imageRef{registry: "localhost:5000", repository: "app", tag: "latest"}, true},
+		{"quay.io/acme/api@sha256:abc", imageRef{registry: "quay.io", repository: "acme/api", digest: "sha256:abc"}, true},
+		{"Acme/API:v1", imageRef{}, false},
+		{"nginx:", imageRef{}, false},
+		{"nginx 1.25", imageRef{}, false},
+		{"", imageRef{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, ok := parseImageRef(tt.image)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClassifyPullError(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{`Failed to pull image "ghcr.io/acme/api:v1": failed to authorize: 401 Unauthorized`, PullClassAuth},
+		{`Failed to pull image "acme/private": pull access denied, repository does not exist or may require authorization`, PullClassAuth},
+		{`Failed to pull image "ghcr.io/acme/api:v9": ghcr.io/acme/api:v9: not found`, PullClassNotFound},
+		{`Failed to pull image "nginx": 429 Too Many Requests - toomanyrequests: You have reached your pull rate limit`, PullClassRateLimit},
+		{`Failed to pull image "reg.internal/app": dial tcp: lookup reg.internal on 10.0.0.10:53: no such host`, PullClassNetwork},
+		{`Failed to pull image "nginx": something unexpected`, PullClassUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyPullError(tt.message), tt.message)
+	}
+}
+
+func TestAnalyzeImagePulls(t *testing.T) {
+	client := fake.NewClientset(
+		ownedReplicaSet("shop", "api-6b8c", "api"),
+		ownedReplicaSet("shop", "web-5f7d", "web"),
+		pullFailingPod("shop", "api-6b8c-aaaaa", "api-6b8c", "ghcr.io/acme/api:v1.4.0", "ImagePullBackOff",
+			`Back-off pulling image "ghcr.io/acme/api:v1.4.0"`),
+		pullFailingPod("shop", "api-6b8c-bbbbb", "api-6b8c", "ghcr.io/acme/api:v1.4.0", "ErrImagePull",
+			`failed to authorize: 401 Unauthorized`),
+		pullFailingPod("shop", "web-5f7d-aaaaa", "web-5f7d", "nginx:1.255", "ErrImagePull",
+			`docker.io/library/nginx:1.255: not found`),
+		pullFailingPod("shop", "bad-00000", "bad", "Acme/Web", "InvalidImageName", `invalid reference format`),
+		pullFailedEvent("shop", "api-6b8c-aaaaa", `Failed to pull image "ghcr.io/acme/api:v1.4.0": 403 Forbidden`),
+		&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "shop"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "ghcr-creds"}},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "shop"}},
+	)
+
+	result, err := AnalyzeImagePulls(context.Background(), client, ImagePullConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Pods)
+	require.Len(t, result.Registries, 3)
+
+	ghcr := result.Registries[0]
+	assert.Equal(t, "ghcr.io", ghcr.Registry)
+	assert.Equal(t, 2, ghcr.Pods)
+	assert.Equal(t, map[string]int{PullClassAuth: 1}, ghcr.Classes)
+	require.Len(t, ghcr.Images, 1)
+	img := ghcr.Images[0]
+	assert.Equal(t, "acme/api", img.Repository)
+	assert.Equal(t, PullClassAuth, img.Class)
+	assert.Equal(t, []string{"shop/Deployment/api"}, img.Workloads)
+	assert.Equal(t, []string{"ghcr-creds"}, img.PullSecrets)
+	assert.Contains(t, img.Fix, "ghcr-creds are set")
+	assert.Nil(t, ghcr.Probe)
+
+	hub := result.Registries[1]
+	assert.Equal(t, "docker.io", hub.Registry)
+	require.Len(t, hub.Images, 1)
+	assert.Equal(t, PullClassNotFound, hub.Images[0].Class)
+	assert.Equal(t, "library/nginx", hub.Images[0].Repository)
+	assert.Equal(t, "1.255", hub.Images[0].Tag)
+
+	invalid := result.Registries[2]
+	assert.Equal(t, "invalid", invalid.Registry)
+	assert.Equal(t, PullClassInvalidName, invalid.Images[0].Class)
+}
+
+func TestAnalyzeImagePulls_NoFailures(t *testing.T) {
+	client := fake.NewClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "shop"}})
+	result, err := AnalyzeImagePulls(context.Background(), client, ImagePullConfig{})
+	require.NoError(t, err)
+	assert.Zero(t, result.Pods)
+	assert.Empty(t, result.Registries)
+}
+
+// fakeRegistry serves /v2/, a token endpoint, and the tag list of acme/api.
+func fakeRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Regexp(t, `^repository:acme/[a-z]+:pull$`, r.URL.Query().Get("scope"))
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "anon"})
+		case r.Header.Get("Authorization") != "Bearer anon":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/acme/api/tags/list":
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "acme/api", "tags": []string{"v1.3.0", "v1.4.0", "latest"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAnalyzeImagePulls_Probe(t *testing.T) {
+	srv := fakeRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	client := fake.NewClientset(
+		pullFailingPod("shop", "api-1", "api-1", host+"/acme/api:v1.4.1", "ErrImagePull", "manifest unknown"),
+		pullFailingPod("shop", "api-2", "api-2", host+"/acme/api:v1.4.0", "ErrImagePull", "manifest unknown"),
+		pullFailingPod("shop", "gone-1", "gone-1", host+"/acme/gone:v1", "ErrImagePull", "manifest unknown"),
+		pullFailingPod("shop", "net-1", "net-1", host+"/acme/net:v1", "ErrImagePull", "dial tcp: i/o timeout"),
+	)
+
+	result, err := AnalyzeImagePulls(context.Background(), client, ImagePullConfig{Probe: true, HTTPClient: srv.Client()})
+	require.NoError(t, err)
+	require.Len(t, result.Registries, 1)
+	reg := result.Registries[0]
+	require.NotNil(t, reg.Probe)
+	assert.True(t, reg.Probe.Reachable)
+	assert.Equal(t, http.StatusUnauthorized, reg.Probe.Status)
+	assert.Equal(t, "bearer", reg.Probe.Auth)
+
+	fixes := make(map[string]string)
+	for _, img := range reg.Images {
+		fixes[img.Repository+":"+img.Tag] = img.Fix
+	}
+	assert.Contains(t, fixes["acme/api:v1.4.1"], "did you mean v1.4.0?")
+	assert.Contains(t, fixes["acme/api:v1.4.0"], "tag v1.4.0 exists")
+	assert.Contains(t, fixes["acme/gone:v1"], "repository acme/gone does not exist")
+	assert.Contains(t, fixes["acme/net:v1"], "answers from here (HTTP 401) but not from the nodes")
+}
+
+func TestAnalyzeImagePulls_ProbeUnreachable(t *testing.T) {
+	srv := fakeRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	httpClient := srv.Client()
+	srv.Close()
+
+	client := fake.NewClientset(
+		pullFailingPod("shop", "net-1", "net-1", host+"/acme/net:v1", "ErrImagePull", "dial tcp: i/o timeout"),
+	)
+	result, err := AnalyzeImagePulls(context.Background(), client, ImagePullConfig{Probe: true, HTTPClient: httpClient})
+	require.NoError(t, err)
+	reg := result.Registries[0]
+	assert.False(t, reg.Probe.Reachable)
+	assert.NotEmpty(t, reg.Probe.Error)
+	assert.Contains(t, reg.Images[0].Fix, "unreachable from here too")
+}
+
+func TestParseChallenge(t *testing.T) {
+	params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="a,b"`)
+	assert.Equal(t, map[string]string{
+		"realm": "https://auth.docker.io/token", "service": "registry.docker.io", "scope": "a,b",
+	}, params)
+}
+
+func TestClosestTag(t *testing.T) {
+	tags := []string{"1.25.3", "1.25.4", "1.26.0", "stable"}
+	assert.Equal(t, "1.25.3", closestTag("1.25.33", tags))
+	assert.Equal(t, "stable", closestTag("stabel", tags))
+	assert.Empty(t, closestTag("nightly-2026", tags))
+}
@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// Causes reported in WorkloadWithoutMetrics.Cause.
+const (
+	NoMetricsCauseNotScraped    = "not-scraped"     // pods run, but Prometheus has no container metrics for them
+	NoMetricsCauseTooNew        = "too-new"         // younger than --min-runtime-days, or than the first scrapes
+	NoMetricsCauseCrashLooping  = "crash-looping"   // a container is in CrashLoopBackOff
+	NoMetricsCauseNoRunningPods = "no-running-pods" // nothing is running to measure
+	NoMetricsCauseUnknown       = "unknown"         // pods could not be listed
+)
+
+// NoMetricsRemediation is the next step for each cause, without workload
+// names so it survives obfuscation.
+var NoMetricsRemediation = map[string]string{
+	NoMetricsCauseNotScraped: "configure cAdvisor scraping (kubelet ServiceMonitor) for its nodes, " +
+		"or measure it with kubenow pro-monitor latch",
+	NoMetricsCauseTooNew:        "rerun once it has run for --min-runtime-days",
+	NoMetricsCauseCrashLooping:  "fix the crash loop first (kubectl logs --previous): a crashing container's usage is not representative",
+	NoMetricsCauseNoRunningPods: "scale it up or check why its pods are not running: there is nothing to measure",
+	NoMetricsCauseUnknown:       "grant list on pods to classify it",
+}
+
+// NoMetricsCauseOrder lists causes from most to least actionable, for
+// summaries.
+var NoMetricsCauseOrder = []string{
+	NoMetricsCauseCrashLooping, NoMetricsCauseNoRunningPods, NoMetricsCauseTooNew, NoMetricsCauseNotScraped, NoMetricsCauseUnknown,
+}
+
+// noMetricsScrapeGrace is how long a workload may take to show up in
+// Prometheus after it is created.
+const noMetricsScrapeGrace = 15 * time.Minute
+
+// classifyWorkloadsWithoutMetrics sets the cause, detail, and remediation
+// of every workload without metrics from the state of its pods, and counts
+// them by cause in the summary. Pods are listed once per namespace, shared
+// with the other per-namespace passes. Template instances take the cause and
+// diagnosis of their representative.
+func (a *RequestsSkewAnalyzer) classifyWorkloadsWithoutMetrics(ctx context.Context, result *RequestsSkewResult) {
+	minAge := max(time.Duration(a.config.MinRuntimeDays)*24*time.Hour, noMetricsScrapeGrace)
+	now := time.Now()
+
+	type classification struct{ cause, diagnosis string }
+	representatives := make(map[workloadKey]classification)
+	for i := range result.WorkloadsWithoutMetrics {
+		w := &result.WorkloadsWithoutMetrics[i]
+		if w.template != nil {
+			continue
+		}
+		pods, err := a.namespacePods(ctx, w.Namespace)
+		if err != nil {
+			w.Cause = NoMetricsCauseUnknown
+			w.Diagnosis = fmt.Sprintf("unable to query pods: %v", err)
+		} else {
+			classifyWorkloadWithoutMetrics(w, workloadPods(pods, w.Type, w.Workload), minAge, now)
+		}
+		representatives[workloadKey{namespace: w.Namespace, kind: w.Type, name: w.Workload}] = classification{w.Cause, w.Diagnosis}
+	}
+	for i := range result.WorkloadsWithoutMetrics {
+		w := &result.WorkloadsWithoutMetrics[i]
+		if w.template == nil {
+			continue
+		}
+		rep, ok := representatives[*w.template]
+		if !ok {
+			w.Cause = NoMetricsCauseNotScraped
+			continue
+		}
+		w.Cause = rep.cause
+		w.Diagnosis = fmt.Sprintf("%s (same template as %s/%s)", rep.diagnosis, w.template.namespace, w.template.name)
+	}
+
+	result.Summary.WithoutMetricsByCause = nil
+	for i := range result.WorkloadsWithoutMetrics {
+		w := &result.WorkloadsWithoutMetrics[i]
+		w.Remediation = NoMetricsRemediation[w.Cause]
+		if result.Summary.WithoutMetricsByCause == nil {
+			result.Summary.WithoutMetricsByCause = make(map[string]int)
+		}
+		result.Summary.WithoutMetricsByCause[w.Cause]++
+	}
+}
+
+// classifyWorkloadWithoutMetrics picks the cause from the workload's pods:
+// pod trouble first, since it holds whatever else is true, then an
+// unscraped namespace, then age.
+func classifyWorkloadWithoutMetrics(w *WorkloadWithoutMetrics, pods []corev1.Pod, minAge time.Duration, now time.Time) {
+	running, crashing := 0, 0
+	phases := make(map[corev1.PodPhase]int)
+	for i := range pods {
+		pod := &pods[i]
+		phases[pod.Status.Phase]++
+		if pod.Status.Phase == corev1.PodRunning {
+			running++
+		}
+		if podCrashLooping(pod) {
+			crashing++
+		}
+	}
+
+	switch {
+	case crashing > 0:
+		w.Cause = NoMetricsCauseCrashLooping
+		w.Diagnosis = fmt.Sprintf("%d of %d pod(s) in CrashLoopBackOff", crashing, len(pods))
+	case running == 0:
+		w.Cause = NoMetricsCauseNoRunningPods
+		switch {
+		case len(pods) == 0 && metrics.IsBatchKind(w.Type):
+			w.Diagnosis = "no pods: no run in progress"
+		case len(pods) == 0:
+			w.Diagnosis = "no pods"
+		default:
+			w.Diagnosis = "no running pods (" + formatPhases(phases) + ")"
+		}
+	case w.unscrapedNamespace:
+		w.Cause = NoMetricsCauseNotScraped
+		w.Diagnosis = "no Prometheus container metrics for this namespace"
+	case !w.created.IsZero() && now.Sub(w.created) < minAge:
+		w.Cause = NoMetricsCauseTooNew
+		w.Diagnosis = fmt.Sprintf("created %s ago, needs %s of history", formatDuration(now.Sub(w.created)), formatDuration(minAge))
+	default:
+		w.Cause = NoMetricsCauseNotScraped
+		w.Diagnosis = "pods running but not in Prometheus: check ServiceMonitor/PodMonitor"
+	}
+}
+
+// podCrashLooping reports whether any of the pod's containers, init
+// containers included, is waiting in CrashLoopBackOff.
+func podCrashLooping(pod *corev1.Pod) bool {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := range statuses {
+			if waiting := statuses[i].State.Waiting; waiting != nil && waiting.Reason == "CrashLoopBackOff" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// workloadPods returns the pods of a workload: through owner references for
+// built-in kinds, each Job of a CronJob being named after it, and through
+// operator labels for CRD-managed workloads.
+func workloadPods(pods []corev1.Pod, kind, name string) []corev1.Pod {
+	var matched []corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		ownerKind, owner := podTemplateWorkload(pod)
+		var match bool
+		switch {
+		case kind == "CronJob":
+			match = ownerKind == "Job" && strings.HasPrefix(owner, name+"-")
+		case ownerKind == kind:
+			match = owner == name
+		default:
+			resolved, operatorType := metrics.ResolveWorkloadIdentity(pod.Name, pod.Labels)
+			match = operatorType == kind && resolved == name
+		}
+		if match {
+			matched = append(matched, *pod)
+		}
+	}
+	return matched
+}
+
+// formatPhases renders pod counts per phase, e.g. "2 Pending, 1 Failed".
+func formatPhases(phases map[corev1.PodPhase]int) string {
+	names := make([]string, 0, len(phases))
+	for phase := range phases {
+		names = append(names, string(phase))
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, phase := range names {
+		label := phase
+		if label == "" {
+			label = "Unknown"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", phases[corev1.PodPhase(phase)], label))
+	}
+	return strings.Join(parts, ", ")
+}
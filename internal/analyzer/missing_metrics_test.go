@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// ownedPod is a pod of the named controller in namespace "prod".
+func ownedPod(name, ownerKind, owner string, phase corev1.PodPhase, waiting string) *corev1.Pod {
+	isController := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "prod",
+			OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: owner, Controller: &isController}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	if waiting != "" {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waiting}},
+		}}
+	}
+	return pod
+}
+
+func TestClassifyWorkloadsWithoutMetrics(t *testing.T) {
+	client := fake.NewClientset(
+		ownedPod("db-0", "StatefulSet", "db", corev1.PodRunning, "CrashLoopBackOff"),
+		ownedPod("db-1", "StatefulSet", "db", corev1.PodRunning, ""),
+		ownedPod("queue-0", "StatefulSet", "queue", corev1.PodPending, ""),
+		ownedPod("api-0", "StatefulSet", "api", corev1.PodRunning, ""),
+		ownedPod("fresh-0", "StatefulSet", "fresh", corev1.PodRunning, ""),
+		ownedPod("nightly-29000000-x", "Job", "nightly-29000000", corev1.PodRunning, ""),
+	)
+	a := NewRequestsSkewAnalyzer(client, metrics.NewMockMetrics(), &RequestsSkewConfig{Silent: true, MinRuntimeDays: 7})
+
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	rep := workloadKey{namespace: "prod", kind: "StatefulSet", name: "db"}
+	orphan := workloadKey{namespace: "prod", kind: "StatefulSet", name: "orphan"}
+	result := &RequestsSkewResult{WorkloadsWithoutMetrics: []WorkloadWithoutMetrics{
+		{Namespace: "prod", Workload: "db", Type: "StatefulSet", created: old},
+		{Namespace: "prod", Workload: "queue", Type: "StatefulSet", created: old},
+		{Namespace: "prod", Workload: "api", Type: "StatefulSet", created: old},
+		{Namespace: "prod", Workload: "fresh", Type: "StatefulSet", created: time.Now().Add(-2 * time.Hour)},
+		{Namespace: "prod", Workload: "nightly", Type: "CronJob", created: old},
+		{Namespace: "prod", Workload: "gone", Type: "Deployment", created: old},
+		{Namespace: "prod", Workload: "fresh-unscraped", Type: "Deployment", unscrapedNamespace: true},
+		{Namespace: "staging", Workload: "db", Type: "StatefulSet", Diagnosis: "not analyzed: same template as prod/db", template: &rep},
+		{Namespace: "staging", Workload: "orphan", Type: "StatefulSet", Diagnosis: "not analyzed: same template as prod/orphan", template: &orphan},
+	}}
+	a.classifyWorkloadsWithoutMetrics(context.Background(), result)
+
+	want := []struct{ cause, diagnosis string }{
+		{NoMetricsCauseCrashLooping, "1 of 2 pod(s) in CrashLoopBackOff"},
+		{NoMetricsCauseNoRunningPods, "no running pods (1 Pending)"},
+		{NoMetricsCauseNotScraped, "pods running but not in Prometheus: check ServiceMonitor/PodMonitor"},
+		{NoMetricsCauseTooNew, "created 2h ago, needs 7d of history"},
+		{NoMetricsCauseNotScraped, "pods running but not in Prometheus: check ServiceMonitor/PodMonitor"},
+		{NoMetricsCauseNoRunningPods, "no pods"},
+		{NoMetricsCauseNoRunningPods, "no pods"},
+		{NoMetricsCauseCrashLooping, "1 of 2 pod(s) in CrashLoopBackOff (same template as prod/db)"},
+		{NoMetricsCauseNotScraped, "not analyzed: same template as prod/orphan"},
+	}
+	require.Len(t, result.WorkloadsWithoutMetrics, len(want))
+	for i, w := range result.WorkloadsWithoutMetrics {
+		assert.Equal(t, want[i].cause, w.Cause, w.Workload)
+		assert.Equal(t, want[i].diagnosis, w.Diagnosis, w.Workload)
+		assert.Equal(t, NoMetricsRemediation[w.Cause], w.Remediation, w.Workload)
+	}
+	assert.Equal(t, map[string]int{
+		NoMetricsCauseCrashLooping:  2,
+		NoMetricsCauseNoRunningPods: 3,
+		NoMetricsCauseNotScraped:    3,
+		NoMetricsCauseTooNew:        1,
+	}, result.Summary.WithoutMetricsByCause)
+}
+
+func TestClassifyWorkloadWithoutMetrics_UnscrapedNamespace(t *testing.T) {
+	w := &WorkloadWithoutMetrics{Type: "Deployment", created: time.Now(), unscrapedNamespace: true}
+	pods := []corev1.Pod{*ownedPod("web-0", "StatefulSet", "web", corev1.PodRunning, "")}
+	classifyWorkloadWithoutMetrics(w, pods, 7*24*time.Hour, time.Now())
+	assert.Equal(t, NoMetricsCauseNotScraped, w.Cause, "an unscraped namespace outweighs age")
+	assert.Equal(t, "no Prometheus container metrics for this namespace", w.Diagnosis)
+}
+
+func TestNoMetricsCauses_HaveRemediation(t *testing.T) {
+	for _, cause := range NoMetricsCauseOrder {
+		assert.NotEmpty(t, NoMetricsRemediation[cause], cause)
+	}
+	assert.Len(t, NoMetricsRemediation, len(NoMetricsCauseOrder))
+}
@@ -3,14 +3,16 @@ package analyzer
 import (
 	"context"
 	"fmt"
-	"os"
+	"log/slog"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
 )
 
 const (
@@ -35,19 +37,25 @@ type NodeFootprintConfig struct {
 	Silent     bool          // Suppress progress output
 }
 
-// logProgress prints progress messages unless silent mode is enabled
-func (a *NodeFootprintAnalyzer) logProgress(format string, args ...interface{}) {
+// logProgress logs progress messages unless silent mode is enabled
+func (a *NodeFootprintAnalyzer) logProgress(msg string, args ...any) {
 	if !a.config.Silent {
-		fmt.Fprintf(os.Stderr, format, args...)
+		slog.Info(msg, args...)
 	}
 }
 
+// logWarning logs a warning; silent mode does not hide it
+func (a *NodeFootprintAnalyzer) logWarning(msg string, args ...any) {
+	slog.Warn(msg, args...)
+}
+
 // NodeFootprintResult contains the analysis results
 type NodeFootprintResult struct {
 	Metadata         NodeFootprintMetadata `json:"metadata"`
 	CurrentTopology  CurrentTopology       `json:"current_topology"`
 	WorkloadEnvelope WorkloadEnvelope      `json:"workload_envelope"`
 	Scenarios        []NodeScenario        `json:"scenarios"`
+	Queries          *querylog.Report      `json:"queries,omitempty"` // --include-queries
 }
 
 // NodeFootprintMetadata contains metadata about the analysis
@@ -135,24 +143,24 @@ func (a *NodeFootprintAnalyzer) Analyze(ctx context.Context) (*NodeFootprintResu
 	}
 
 	// Get current topology
-	a.logProgress("[kubenow] Analyzing current cluster topology...\n")
+	a.logProgress("Analyzing current cluster topology...")
 	currentTopology, err := a.getCurrentTopology(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current topology: %w", err)
 	}
 	result.CurrentTopology = *currentTopology
-	a.logProgress("[kubenow] Current: %d x %s nodes\n", currentTopology.NodeCount, currentTopology.NodeType)
+	a.logProgress("Current topology", "nodes", currentTopology.NodeCount, "node_type", currentTopology.NodeType)
 
 	// Get workload envelope
-	a.logProgress("[kubenow] Calculating workload envelope (%s percentile)...\n", a.config.Percentile)
+	a.logProgress("Calculating workload envelope...", "percentile", a.config.Percentile)
 	envelope, podRequirements, err := a.getWorkloadEnvelope(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workload envelope: %w", err)
 	}
 	result.WorkloadEnvelope = *envelope
 	result.Metadata.WorkloadCount = envelope.PodCount
-	a.logProgress("[kubenow] Found %d pods requiring %.2f CPU cores, %.2f GiB memory\n",
-		envelope.PodCount, envelope.TotalCPURequired, envelope.TotalMemoryRequired/(1024*1024*1024))
+	a.logProgress("Found workload envelope", "pods", envelope.PodCount,
+		"cpu_cores", envelope.TotalCPURequired, "memory_gib", envelope.TotalMemoryRequired/(1024*1024*1024))
 
 	// Add current topology as first scenario
 	currentScenario := NodeScenario{
@@ -167,20 +175,22 @@ func (a *NodeFootprintAnalyzer) Analyze(ctx context.Context) (*NodeFootprintResu
 	result.Scenarios = append(result.Scenarios, currentScenario)
 
 	// Simulate alternative topologies
-	a.logProgress("[kubenow] Simulating alternative topologies...\n")
+	a.logProgress("Simulating alternative topologies...")
 	nodeTemplates := GetNodeTemplates()
 	for i, nodeType := range a.config.NodeTypes {
 		template, exists := nodeTemplates[nodeType]
 		if !exists {
-			a.logProgress("[kubenow] Warning: unknown node type %s, skipping\n", nodeType)
+			a.logWarning("Unknown node type, skipping", "node_type", nodeType)
 			continue
 		}
 
-		a.logProgress("[kubenow] [%d/%d] Testing %s configuration...\n", i+1, len(a.config.NodeTypes), nodeType)
+		if !a.config.Silent {
+			logging.Progress(slog.Default(), "Testing configuration...", i+1, len(a.config.NodeTypes), "node_type", nodeType)
+		}
 		scenario := a.simulateTopology(i+1, template, podRequirements, currentTopology.NodeCount, envelope)
 		result.Scenarios = append(result.Scenarios, scenario)
 	}
-	a.logProgress("[kubenow] Analysis complete!\n\n")
+	a.logProgress("Analysis complete!")
 
 	return result, nil
 }
@@ -0,0 +1,482 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+)
+
+// Node-skew defaults.
+const (
+	DefaultNodeSkewWindow    = 7 * 24 * time.Hour
+	DefaultNodeSkewQuantile  = 0.95
+	DefaultRightSizingMargin = 0.15 // headroom added to each pod's usage
+	DefaultTargetUtilization = 0.85 // share of allocatable the packing may fill
+
+	// Right-sized requests never drop below these floors.
+	minPodCPU   = 0.01        // 10m
+	minPodMemGi = 16.0 / 1024 // 16Mi
+)
+
+// NodeSkewConfig holds configuration for node-skew analysis.
+type NodeSkewConfig struct {
+	Window            time.Duration // usage history (0 = DefaultNodeSkewWindow)
+	Quantile          float64       // usage quantile per node and pod (0 = DefaultNodeSkewQuantile)
+	Margin            float64       // headroom over pod usage for right-sized requests, e.g. DefaultRightSizingMargin
+	TargetUtilization float64       // max share of allocatable filled by the packing (0 = DefaultTargetUtilization)
+	NodeSelector      string        // label selector limiting the nodes analyzed
+	Rates             cost.Rates    // fallback pricing for nodes without a known instance type
+	Silent            bool
+}
+
+// NodeSkewResult is the outcome of AnalyzeNodeSkew.
+type NodeSkewResult struct {
+	Metadata      NodeSkewMetadata  `json:"metadata"`
+	Summary       NodeSkewSummary   `json:"summary"`
+	Nodes         []NodeSkew        `json:"nodes"`
+	Consolidation NodeConsolidation `json:"consolidation"`
+	Queries       *querylog.Report  `json:"queries,omitempty"` // --include-queries
+}
+
+// NodeSkewMetadata contains metadata about the analysis.
+type NodeSkewMetadata struct {
+	Window            string    `json:"window"`
+	Quantile          float64   `json:"quantile"`
+	Margin            float64   `json:"margin"`
+	TargetUtilization float64   `json:"target_utilization"`
+	GeneratedAt       time.Time `json:"generated_at"`
+}
+
+// NodeSkew compares one node's allocatable capacity with the requests
+// scheduled on it and the usage observed on it.
+type NodeSkew struct {
+	Node                string  `json:"node"`
+	InstanceType        string  `json:"instance_type,omitempty"`
+	Schedulable         bool    `json:"schedulable"` // not cordoned and without NoSchedule taints
+	Pods                int     `json:"pods"`
+	AllocatableCPU      float64 `json:"allocatable_cpu"`
+	RequestedCPU        float64 `json:"requested_cpu"`
+	UsedCPU             float64 `json:"used_cpu"` // at the configured quantile
+	AvgUsedCPU          float64 `json:"avg_used_cpu"`
+	AllocatableMemoryGi float64 `json:"allocatable_memory_gi"`
+	RequestedMemoryGi   float64 `json:"requested_memory_gi"`
+	UsedMemoryGi        float64 `json:"used_memory_gi"`
+	AvgUsedMemoryGi     float64 `json:"avg_used_memory_gi"`
+	CPURequestedPercent float64 `json:"cpu_requested_percent"`
+	CPUUsedPercent      float64 `json:"cpu_used_percent"`
+	MemRequestedPercent float64 `json:"memory_requested_percent"`
+	MemUsedPercent      float64 `json:"memory_used_percent"`
+	SkewCPU             float64 `json:"skew_cpu"`    // requested / used
+	SkewMemory          float64 `json:"skew_memory"` // requested / used
+	HasMetrics          bool    `json:"has_metrics"`
+	MonthlyCost         float64 `json:"monthly_cost"`
+}
+
+// NodeSkewSummary totals the analyzed nodes.
+type NodeSkewSummary struct {
+	Nodes               int     `json:"nodes"`
+	NodesWithoutMetrics int     `json:"nodes_without_metrics,omitempty"`
+	AllocatableCPU      float64 `json:"allocatable_cpu"`
+	RequestedCPU        float64 `json:"requested_cpu"`
+	UsedCPU             float64 `json:"used_cpu"`
+	AllocatableMemoryGi float64 `json:"allocatable_memory_gi"`
+	RequestedMemoryGi   float64 `json:"requested_memory_gi"`
+	UsedMemoryGi        float64 `json:"used_memory_gi"`
+	CPURequestedPercent float64 `json:"cpu_requested_percent"`
+	CPUUsedPercent      float64 `json:"cpu_used_percent"`
+	MemRequestedPercent float64 `json:"memory_requested_percent"`
+	MemUsedPercent      float64 `json:"memory_used_percent"`
+}
+
+// NodeConsolidation estimates how many schedulable nodes the movable pods
+// need with today's requests and with right-sized requests.
+type NodeConsolidation struct {
+	CandidateNodes        int      `json:"candidate_nodes"`
+	ExcludedNodes         []string `json:"excluded_nodes,omitempty"` // cordoned or NoSchedule-tainted
+	NodesNeededCurrent    int      `json:"nodes_needed_current"`
+	NodesNeededRightSized int      `json:"nodes_needed_right_sized"`
+	RemovableCurrent      int      `json:"removable_current"`     // freed by repacking alone
+	RemovableRightSized   int      `json:"removable_right_sized"` // freed by right-sizing and repacking
+	RemovableNodes        []string `json:"removable_nodes,omitempty"`
+	RightSizedCPU         float64  `json:"right_sized_cpu"`
+	RightSizedMemoryGi    float64  `json:"right_sized_memory_gi"`
+	PodsWithoutMetrics    int      `json:"pods_without_metrics,omitempty"` // packed at their current requests
+	UnplacedPods          []string `json:"unplaced_pods,omitempty"`        // larger than any candidate node
+	MonthlySavings        float64  `json:"monthly_savings"`
+}
+
+// nodeSlot is a candidate node during packing.
+type nodeSlot struct {
+	name               string
+	cpu, memGi         float64 // capacity left for movable pods
+	freeCPU, freeMemGi float64
+	used               bool
+}
+
+// packPod is a movable pod's current and right-sized footprint.
+type packPod struct {
+	ref                  string
+	cpu, memGi           float64
+	rightCPU, rightMemGi float64
+}
+
+// AnalyzeNodeSkew compares allocatable, requested, and used resources per
+// node, then estimates how many nodes the workload would need if pods were
+// repacked at their current requests and at right-sized requests (their
+// usage quantile plus a margin). DaemonSet and static pods stay on their
+// node; affinity, taints tolerated by pods, PDBs, and topology spread are
+// not modeled, so the estimate is an upper bound on removable nodes.
+func AnalyzeNodeSkew(
+	ctx context.Context, client kubernetes.Interface, provider metrics.MetricsProvider, cfg NodeSkewConfig,
+) (*NodeSkewResult, error) {
+	cfg = withNodeSkewDefaults(cfg)
+
+	nodeList, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: cfg.NodeSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if len(nodeList.Items) == 0 {
+		return nil, fmt.Errorf("no nodes found")
+	}
+	podList, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	if !cfg.Silent {
+		slog.Info("Querying node and pod usage...", "window", formatDuration(cfg.Window), "quantile", cfg.Quantile)
+	}
+	nodeUsage, err := provider.GetNodeResourceUsage(ctx, cfg.Window, cfg.Quantile)
+	if err != nil {
+		return nil, err
+	}
+	podUsage, err := provider.GetPodUsageQuantiles(ctx, cfg.Window, cfg.Quantile)
+	if err != nil {
+		return nil, err
+	}
+
+	podsByNode := scheduledPodsByNode(podList.Items)
+
+	result := &NodeSkewResult{
+		Metadata: NodeSkewMetadata{
+			Window:            formatDuration(cfg.Window),
+			Quantile:          cfg.Quantile,
+			Margin:            cfg.Margin,
+			TargetUtilization: cfg.TargetUtilization,
+			GeneratedAt:       time.Now().UTC(),
+		},
+		Nodes: make([]NodeSkew, 0, len(nodeList.Items)),
+	}
+
+	nodeCost := map[string]float64{}
+	for i := range nodeList.Items {
+		n := &nodeList.Items[i]
+		ns := nodeSkewOf(n, podsByNode[n.Name], nodeUsage[n.Name], cfg.Rates)
+		result.Nodes = append(result.Nodes, ns)
+		nodeCost[n.Name] = ns.MonthlyCost
+	}
+	result.Summary = summarizeNodeSkew(result.Nodes)
+
+	slotsCurrent, slotsRight, movable := packingInput(result, podsByNode, podUsage, cfg)
+	if !cfg.Silent {
+		slog.Info("Simulating repacking...", "pods", len(movable), "nodes", result.Consolidation.CandidateNodes)
+	}
+	estimateConsolidation(&result.Consolidation, slotsCurrent, slotsRight, movable, nodeCost)
+
+	sort.SliceStable(result.Nodes, func(i, j int) bool {
+		a, b := result.Nodes[i], result.Nodes[j]
+		return a.RequestedCPU-a.UsedCPU > b.RequestedCPU-b.UsedCPU
+	})
+	return result, nil
+}
+
+// packingInput splits each schedulable node into the capacity left for
+// movable pods (target utilization minus pinned pods), once at current
+// requests and once right-sized, and collects the movable pods. Cordoned
+// and NoSchedule-tainted nodes are excluded together with their pods.
+func packingInput(
+	result *NodeSkewResult, podsByNode map[string][]*corev1.Pod, podUsage map[string]metrics.PodUsageQuantile, cfg NodeSkewConfig,
+) (slotsCurrent, slotsRight []nodeSlot, movable []packPod) {
+	c := &result.Consolidation
+	for i := range result.Nodes {
+		ns := &result.Nodes[i]
+		if !ns.Schedulable {
+			c.ExcludedNodes = append(c.ExcludedNodes, ns.Node)
+			continue
+		}
+		c.CandidateNodes++
+
+		slotCurrent := nodeSlot{
+			name:  ns.Node,
+			cpu:   ns.AllocatableCPU * cfg.TargetUtilization,
+			memGi: ns.AllocatableMemoryGi * cfg.TargetUtilization,
+		}
+		slotRight := slotCurrent
+		for _, p := range podsByNode[ns.Node] {
+			pp, hasUsage := rightSizePod(p, podUsage, cfg.Margin)
+			if !hasUsage {
+				c.PodsWithoutMetrics++
+			}
+			c.RightSizedCPU += pp.rightCPU
+			c.RightSizedMemoryGi += pp.rightMemGi
+			if pinnedToNode(p) {
+				slotCurrent.cpu -= pp.cpu
+				slotCurrent.memGi -= pp.memGi
+				slotRight.cpu -= pp.rightCPU
+				slotRight.memGi -= pp.rightMemGi
+				continue
+			}
+			movable = append(movable, pp)
+		}
+		slotsCurrent = append(slotsCurrent, slotCurrent)
+		slotsRight = append(slotsRight, slotRight)
+	}
+	return slotsCurrent, slotsRight, movable
+}
+
+// estimateConsolidation packs the movable pods at current and right-sized
+// requests. Removable nodes and savings are only reported when every pod
+// found a place.
+func estimateConsolidation(
+	c *NodeConsolidation, slotsCurrent, slotsRight []nodeSlot, movable []packPod, nodeCost map[string]float64,
+) {
+	usedCurrent, _ := packNodes(slotsCurrent, movable, false)
+	usedRight, unplaced := packNodes(slotsRight, movable, true)
+	c.NodesNeededCurrent = countUsed(usedCurrent)
+	c.NodesNeededRightSized = countUsed(usedRight)
+	c.UnplacedPods = unplaced
+	if len(unplaced) == 0 {
+		c.RemovableCurrent = c.CandidateNodes - c.NodesNeededCurrent
+		c.RemovableRightSized = c.CandidateNodes - c.NodesNeededRightSized
+		for _, s := range usedRight {
+			if !s.used {
+				c.RemovableNodes = append(c.RemovableNodes, s.name)
+				c.MonthlySavings += nodeCost[s.name]
+			}
+		}
+		sort.Strings(c.RemovableNodes)
+	}
+	c.RightSizedCPU = round2(c.RightSizedCPU)
+	c.RightSizedMemoryGi = round2(c.RightSizedMemoryGi)
+	c.MonthlySavings = round2(c.MonthlySavings)
+}
+
+// scheduledPodsByNode groups pods that hold resources on a node, skipping
+// pending and completed pods.
+func scheduledPodsByNode(pods []corev1.Pod) map[string][]*corev1.Pod {
+	byNode := map[string][]*corev1.Pod{}
+	for i := range pods {
+		p := &pods[i]
+		if p.Spec.NodeName == "" || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		byNode[p.Spec.NodeName] = append(byNode[p.Spec.NodeName], p)
+	}
+	return byNode
+}
+
+func withNodeSkewDefaults(cfg NodeSkewConfig) NodeSkewConfig {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultNodeSkewWindow
+	}
+	if cfg.Quantile <= 0 || cfg.Quantile > 1 {
+		cfg.Quantile = DefaultNodeSkewQuantile
+	}
+	if cfg.Margin < 0 {
+		cfg.Margin = DefaultRightSizingMargin
+	}
+	if cfg.TargetUtilization <= 0 || cfg.TargetUtilization > 1 {
+		cfg.TargetUtilization = DefaultTargetUtilization
+	}
+	if cfg.Rates == (cost.Rates{}) {
+		cfg.Rates = cost.DefaultRates()
+	}
+	return cfg
+}
+
+func nodeSkewOf(n *corev1.Node, pods []*corev1.Pod, usage *metrics.NodeUsage, fallback cost.Rates) NodeSkew {
+	ns := NodeSkew{
+		Node:                n.Name,
+		InstanceType:        nodeInstanceType(n),
+		Schedulable:         nodeSchedulable(n),
+		Pods:                len(pods),
+		AllocatableCPU:      n.Status.Allocatable.Cpu().AsApproximateFloat64(),
+		AllocatableMemoryGi: float64(n.Status.Allocatable.Memory().Value()) / (1024 * 1024 * 1024),
+	}
+	for _, p := range pods {
+		cpu, memGi := podSpecRequests(&p.Spec)
+		ns.RequestedCPU += cpu
+		ns.RequestedMemoryGi += memGi
+	}
+	if usage != nil {
+		ns.HasMetrics = true
+		ns.UsedCPU = usage.CPUQuantile
+		ns.AvgUsedCPU = usage.CPUAvg
+		ns.UsedMemoryGi = usage.MemoryQuantile / (1024 * 1024 * 1024)
+		ns.AvgUsedMemoryGi = usage.MemoryAvg / (1024 * 1024 * 1024)
+	}
+	ns.CPURequestedPercent = percentOf(ns.RequestedCPU, ns.AllocatableCPU)
+	ns.CPUUsedPercent = percentOf(ns.UsedCPU, ns.AllocatableCPU)
+	ns.MemRequestedPercent = percentOf(ns.RequestedMemoryGi, ns.AllocatableMemoryGi)
+	ns.MemUsedPercent = percentOf(ns.UsedMemoryGi, ns.AllocatableMemoryGi)
+	if ns.UsedCPU > 0 {
+		ns.SkewCPU = round2(ns.RequestedCPU / ns.UsedCPU)
+	}
+	if ns.UsedMemoryGi > 0 {
+		ns.SkewMemory = round2(ns.RequestedMemoryGi / ns.UsedMemoryGi)
+	}
+
+	rates := fallback
+	if r, ok := cost.LookupRates(ns.InstanceType); ok {
+		rates = r
+	}
+	ns.MonthlyCost = cost.MonthlyCost(ns.AllocatableCPU, ns.AllocatableMemoryGi, rates)
+	return ns
+}
+
+func summarizeNodeSkew(nodes []NodeSkew) NodeSkewSummary {
+	s := NodeSkewSummary{Nodes: len(nodes)}
+	for i := range nodes {
+		n := &nodes[i]
+		if !n.HasMetrics {
+			s.NodesWithoutMetrics++
+		}
+		s.AllocatableCPU += n.AllocatableCPU
+		s.RequestedCPU += n.RequestedCPU
+		s.UsedCPU += n.UsedCPU
+		s.AllocatableMemoryGi += n.AllocatableMemoryGi
+		s.RequestedMemoryGi += n.RequestedMemoryGi
+		s.UsedMemoryGi += n.UsedMemoryGi
+	}
+	s.CPURequestedPercent = percentOf(s.RequestedCPU, s.AllocatableCPU)
+	s.CPUUsedPercent = percentOf(s.UsedCPU, s.AllocatableCPU)
+	s.MemRequestedPercent = percentOf(s.RequestedMemoryGi, s.AllocatableMemoryGi)
+	s.MemUsedPercent = percentOf(s.UsedMemoryGi, s.AllocatableMemoryGi)
+	return s
+}
+
+// nodeSchedulable reports whether new pods can land on a node: it is not
+// cordoned and has no NoSchedule or NoExecute taints.
+func nodeSchedulable(n *corev1.Node) bool {
+	if n.Spec.Unschedulable {
+		return false
+	}
+	for _, t := range n.Spec.Taints {
+		if t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute {
+			return false
+		}
+	}
+	return true
+}
+
+// pinnedToNode reports whether a pod cannot move to another node: DaemonSet
+// pods and static (mirror) pods.
+func pinnedToNode(p *corev1.Pod) bool {
+	if owner := metav1.GetControllerOf(p); owner != nil {
+		return owner.Kind == workloadTypeDaemonSet || owner.Kind == "Node"
+	}
+	_, mirror := p.Annotations[corev1.MirrorPodAnnotationKey]
+	return mirror
+}
+
+// rightSizePod returns a pod's current requests and its right-sized
+// footprint: usage plus margin, floored, or the current requests when the
+// pod has no usage data.
+func rightSizePod(p *corev1.Pod, usage map[string]metrics.PodUsageQuantile, margin float64) (packPod, bool) {
+	cpu, memGi := podSpecRequests(&p.Spec)
+	pp := packPod{ref: p.Namespace + "/" + p.Name, cpu: cpu, memGi: memGi, rightCPU: cpu, rightMemGi: memGi}
+	u, ok := usage[pp.ref]
+	if !ok {
+		return pp, false
+	}
+	pp.rightCPU = math.Max(u.CPU*(1+margin), minPodCPU)
+	pp.rightMemGi = math.Max(u.Memory/(1024*1024*1024)*(1+margin), minPodMemGi)
+	return pp, true
+}
+
+// packNodes places pods first-fit decreasing onto nodes taken largest
+// first, opening a node only when no open node has room. It returns the
+// slots with their used flag set and the pods that fit on no node.
+func packNodes(slots []nodeSlot, pods []packPod, rightSized bool) ([]nodeSlot, []string) {
+	out := make([]nodeSlot, len(slots))
+	copy(out, slots)
+	for i := range out {
+		out[i].freeCPU, out[i].freeMemGi = out[i].cpu, out[i].memGi
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].cpu+out[i].memGi > out[j].cpu+out[j].memGi
+	})
+
+	size := func(p packPod) (float64, float64) {
+		if rightSized {
+			return p.rightCPU, p.rightMemGi
+		}
+		return p.cpu, p.memGi
+	}
+	sorted := make([]packPod, len(pods))
+	copy(sorted, pods)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ci, mi := size(sorted[i])
+		cj, mj := size(sorted[j])
+		return ci+mi > cj+mj
+	})
+
+	var unplaced []string
+	for _, p := range sorted {
+		cpu, memGi := size(p)
+		placed := false
+		// Open nodes first, then the next unopened node with room
+		for _, wantUsed := range []bool{true, false} {
+			for i := range out {
+				s := &out[i]
+				if s.used != wantUsed || cpu > s.freeCPU || memGi > s.freeMemGi {
+					continue
+				}
+				s.used = true
+				s.freeCPU -= cpu
+				s.freeMemGi -= memGi
+				placed = true
+				break
+			}
+			if placed {
+				break
+			}
+		}
+		if !placed {
+			unplaced = append(unplaced, p.ref)
+		}
+	}
+	return out, unplaced
+}
+
+func countUsed(slots []nodeSlot) int {
+	n := 0
+	for _, s := range slots {
+		if s.used {
+			n++
+		}
+	}
+	return n
+}
+
+func percentOf(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(part/whole*1000) / 10
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+const gib = 1024 * 1024 * 1024
+
+func skewNode(name string, cordoned bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: cordoned},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("16Gi"),
+		}},
+	}
+}
+
+func skewPod(name, node, ownerKind, cpu, mem string) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if ownerKind != "" {
+		controller := true
+		p.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: "owner", Controller: &controller}}
+	}
+	return p
+}
+
+func TestAnalyzeNodeSkew(t *testing.T) {
+	objects := []runtime.Object{skewNode("a", false), skewNode("b", false), skewNode("c", false), skewNode("d", true)}
+	mock := metrics.NewMockMetrics()
+	for _, n := range []string{"a", "b", "c"} {
+		objects = append(objects,
+			skewPod("ds-"+n, n, workloadTypeDaemonSet, "100m", "128Mi"),
+			skewPod("app1-"+n, n, "ReplicaSet", "1500m", "4Gi"),
+			skewPod("app2-"+n, n, "ReplicaSet", "1500m", "4Gi"),
+		)
+		mock.NodeUsages[n] = &metrics.NodeUsage{Node: n, CPUQuantile: 0.5, CPUAvg: 0.4, MemoryQuantile: 3 * gib, MemoryAvg: 2 * gib}
+		mock.PodQuantiles["default/ds-"+n] = metrics.PodUsageQuantile{CPU: 0.05, Memory: 0.1 * gib}
+		mock.PodQuantiles["default/app1-"+n] = metrics.PodUsageQuantile{CPU: 0.2, Memory: gib}
+		mock.PodQuantiles["default/app2-"+n] = metrics.PodUsageQuantile{CPU: 0.2, Memory: gib}
+	}
+	delete(mock.PodQuantiles, "default/app2-c")
+	done := skewPod("job-a", "a", "Job", "2", "8Gi")
+	done.Status.Phase = corev1.PodSucceeded
+	objects = append(objects, done, skewPod("app-d", "d", "ReplicaSet", "1", "1Gi"))
+
+	result, err := AnalyzeNodeSkew(context.Background(), fake.NewClientset(objects...), mock, NodeSkewConfig{Silent: true})
+	require.NoError(t, err)
+
+	require.Len(t, result.Nodes, 4)
+	assert.Equal(t, 4, result.Summary.Nodes)
+	assert.Equal(t, 1, result.Summary.NodesWithoutMetrics)
+	assert.InDelta(t, 10.3, result.Summary.RequestedCPU, 0.001)
+
+	var a NodeSkew
+	for _, n := range result.Nodes {
+		if n.Node == "a" {
+			a = n
+		}
+	}
+	assert.Equal(t, 3, a.Pods) // completed job pod holds no resources
+	assert.InDelta(t, 3.1, a.RequestedCPU, 0.001)
+	assert.InDelta(t, 77.5, a.CPURequestedPercent, 0.001)
+	assert.InDelta(t, 12.5, a.CPUUsedPercent, 0.001)
+	assert.InDelta(t, 6.2, a.SkewCPU, 0.001)
+	assert.True(t, a.Schedulable)
+
+	c := result.Consolidation
+	assert.Equal(t, 3, c.CandidateNodes)
+	assert.Equal(t, []string{"d"}, c.ExcludedNodes)
+	assert.Equal(t, 3, c.NodesNeededCurrent) // two 1.5-core pods per node at 85%
+	assert.Equal(t, 1, c.NodesNeededRightSized)
+	assert.Equal(t, 0, c.RemovableCurrent)
+	assert.Equal(t, 2, c.RemovableRightSized)
+	assert.Len(t, c.RemovableNodes, 2)
+	assert.Equal(t, 1, c.PodsWithoutMetrics)
+	assert.Empty(t, c.UnplacedPods)
+	assert.InDelta(t, 2*cost.MonthlyCost(4, 16, cost.DefaultRates()), c.MonthlySavings, 0.01)
+}
+
+func TestAnalyzeNodeSkew_NoNodes(t *testing.T) {
+	_, err := AnalyzeNodeSkew(context.Background(), fake.NewClientset(), metrics.NewMockMetrics(), NodeSkewConfig{Silent: true})
+	assert.ErrorContains(t, err, "no nodes found")
+}
+
+func TestPackNodes(t *testing.T) {
+	slots := []nodeSlot{{name: "small", cpu: 1, memGi: 4}, {name: "big", cpu: 4, memGi: 16}}
+	tests := []struct {
+		name         string
+		pods         []packPod
+		wantUsed     []string
+		wantUnplaced []string
+	}{
+		{
+			name:     "largest node opened first",
+			pods:     []packPod{{ref: "p1", cpu: 1, memGi: 2}, {ref: "p2", cpu: 2, memGi: 2}},
+			wantUsed: []string{"big"},
+		},
+		{
+			name:     "second node opened when the first is full",
+			pods:     []packPod{{ref: "p1", cpu: 3, memGi: 2}, {ref: "p2", cpu: 1, memGi: 2}, {ref: "p3", cpu: 1, memGi: 1}},
+			wantUsed: []string{"big", "small"},
+		},
+		{
+			name:         "pod larger than any node",
+			pods:         []packPod{{ref: "huge", cpu: 8, memGi: 2}},
+			wantUnplaced: []string{"huge"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, unplaced := packNodes(slots, tt.pods, false)
+			var used []string
+			for _, s := range out {
+				if s.used {
+					used = append(used, s.name)
+				}
+			}
+			assert.Equal(t, tt.wantUsed, used)
+			assert.Equal(t, tt.wantUnplaced, unplaced)
+		})
+	}
+}
+
+func TestPinnedToNode(t *testing.T) {
+	static := skewPod("etcd", "a", "", "100m", "64Mi")
+	static.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "x"}
+
+	assert.True(t, pinnedToNode(skewPod("ds", "a", workloadTypeDaemonSet, "100m", "64Mi")))
+	assert.True(t, pinnedToNode(skewPod("kube-proxy", "a", "Node", "100m", "64Mi")))
+	assert.True(t, pinnedToNode(static))
+	assert.False(t, pinnedToNode(skewPod("web", "a", "ReplicaSet", "100m", "64Mi")))
+	assert.False(t, pinnedToNode(skewPod("bare", "a", "", "100m", "64Mi")))
+}
+
+func TestNodeSchedulable(t *testing.T) {
+	tainted := skewNode("t", false)
+	tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}}
+	preferred := skewNode("p", false)
+	preferred.Spec.Taints = []corev1.Taint{{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule}}
+
+	assert.True(t, nodeSchedulable(skewNode("n", false)))
+	assert.True(t, nodeSchedulable(preferred))
+	assert.False(t, nodeSchedulable(skewNode("c", true)))
+	assert.False(t, nodeSchedulable(tainted))
+}
@@ -0,0 +1,633 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// OOM analysis defaults.
+const (
+	DefaultOOMWindow = 7 * 24 * time.Hour
+	DefaultOOMMargin = 0.25 // headroom over observed demand for the recommended limit
+
+	// oomAtLimitRatio is the share of the limit the working set must have
+	// reached before a kill for the container's own limit to be the cause.
+	oomAtLimitRatio = 0.9
+	// oomCorrelationWindow matches node-level OOM events to container kills.
+	oomCorrelationWindow = 2 * time.Minute
+	// oomDeployWindow is how soon after a rollout a kill counts as following it.
+	oomDeployWindow = time.Hour
+	// oomMaxSeriesPoints bounds the working-set range query resolution.
+	oomMaxSeriesPoints = 500
+	// oomLimitRounding rounds recommended limits up to whole mebibytes.
+	oomLimitRounding = 1024 * 1024
+)
+
+// OOM causes reported in OOMWorkload.Cause.
+const (
+	OOMCauseLimit        = "limit"         // the container reached its own memory limit
+	OOMCauseNodePressure = "node-pressure" // the node ran out of memory; the container was below its limit
+)
+
+// OOM timeline event kinds.
+const (
+	OOMEventKill    = "oom-kill"
+	OOMEventKernel  = "kernel-oom"
+	OOMEventRestart = "restart"
+	OOMEventDeploy  = "deploy"
+)
+
+// oomEventOrder orders timeline events with the same timestamp: a restart
+// follows the kill it recovers from.
+var oomEventOrder = map[string]int{OOMEventDeploy: 0, OOMEventKill: 1, OOMEventKernel: 2, OOMEventRestart: 3}
+
+// Node events recorded for kernel OOM kills: kubelet's SystemOOM and
+// node-problem-detector's OOMKilling.
+var kernelOOMReasons = map[string]bool{"SystemOOM": true, "OOMKilling": true}
+
+// OOMConfig holds configuration for the OOMKill analysis.
+type OOMConfig struct {
+	Namespace string        // "" = all namespaces
+	Window    time.Duration // lookback (0 = DefaultOOMWindow)
+	Margin    float64       // headroom over demand for the recommended limit (0 = DefaultOOMMargin)
+	Now       time.Time     // zero = time.Now(); set by tests
+}
+
+// OOMResult is the outcome of AnalyzeOOMKills.
+type OOMResult struct {
+	Window      string           `json:"window"`
+	HasMetrics  bool             `json:"has_metrics"` // working-set history from Prometheus
+	Workloads   []OOMWorkload    `json:"workloads"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Queries     *querylog.Report `json:"queries,omitempty"` // --include-queries
+}
+
+// OOMWorkload is a workload with OOM-killed containers in the window.
+type OOMWorkload struct {
+	Namespace  string         `json:"namespace"`
+	Workload   string         `json:"workload"`
+	Kind       string         `json:"kind"`
+	Kills      int            `json:"kills"`
+	LastKill   time.Time      `json:"last_kill"`
+	Cause      string         `json:"cause"`
+	Containers []OOMContainer `json:"containers"`
+	Timeline   []OOMEvent     `json:"timeline"` // oldest first
+	Findings   []string       `json:"findings,omitempty"`
+}
+
+// OOMContainer summarizes one container of an OOM-killed workload.
+type OOMContainer struct {
+	Name                  string  `json:"name"`
+	Kills                 int     `json:"kills"`
+	Restarts              int32   `json:"restarts"`
+	RequestBytes          float64 `json:"request_bytes,omitempty"`
+	LimitBytes            float64 `json:"limit_bytes,omitempty"` // 0 = unlimited
+	PeakWorkingSetBytes   float64 `json:"peak_working_set_bytes,omitempty"`
+	WorkingSetAtKillBytes float64 `json:"working_set_at_kill_bytes,omitempty"` // highest sample before a kill
+	RecommendedLimitBytes float64 `json:"recommended_limit_bytes,omitempty"`
+}
+
+// OOMEvent is one entry of a workload's OOM timeline.
+type OOMEvent struct {
+	Time            time.Time `json:"time"`
+	Kind            string    `json:"kind"` // oom-kill|kernel-oom|restart|deploy
+	Pod             string    `json:"pod,omitempty"`
+	Container       string    `json:"container,omitempty"`
+	Node            string    `json:"node,omitempty"`
+	WorkingSetBytes float64   `json:"working_set_bytes,omitempty"`
+	Detail          string    `json:"detail"`
+}
+
+// oomWorkloadState accumulates a workload's kills while pods are scanned.
+type oomWorkloadState struct {
+	result     OOMWorkload
+	containers map[string]*OOMContainer
+	kills      []OOMEvent
+	nodeKills  map[string][]time.Time // node -> kill times on that node
+}
+
+// AnalyzeOOMKills builds per-workload OOM timelines from container
+// termination states, kernel OOM node events, restarts, and rollouts in the
+// window, and recommends a memory limit per container. With a metrics
+// provider, the working set before each kill and the window's peak refine
+// the cause and the recommendation; provider may be nil.
+//
+// Only the last termination of each live container is visible in its
+// status, so kills of replaced pods and earlier kills of a restarted
+// container are not counted.
+func AnalyzeOOMKills(
+	ctx context.Context, client kubernetes.Interface, provider metrics.MetricsProvider, cfg OOMConfig,
+) (*OOMResult, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultOOMWindow
+	}
+	margin := cfg.Margin
+	if margin <= 0 {
+		margin = DefaultOOMMargin
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	since := now.Add(-window)
+
+	pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	rsOwners := replicaSetDeployments(ctx, client, cfg.Namespace)
+
+	states := make(map[string]*oomWorkloadState)
+	var order []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		kind, name := podWorkload(pod, rsOwners)
+		key := pod.Namespace + "/" + kind + "/" + name
+		st := states[key]
+		if st == nil {
+			st = &oomWorkloadState{
+				result:     OOMWorkload{Namespace: pod.Namespace, Workload: name, Kind: kind},
+				containers: make(map[string]*OOMContainer),
+				nodeKills:  make(map[string][]time.Time),
+			}
+		}
+		if !st.addPod(pod, since) {
+			continue
+		}
+		if states[key] == nil {
+			states[key] = st
+			order = append(order, key)
+		}
+	}
+
+	result := &OOMResult{Window: window.String(), HasMetrics: provider != nil, Workloads: []OOMWorkload{}, GeneratedAt: now.UTC()}
+	if len(order) == 0 {
+		return result, nil
+	}
+
+	kernelEvents := kernelOOMEvents(ctx, client, since)
+	step := max(window/oomMaxSeriesPoints, time.Minute)
+	for _, key := range order {
+		st := states[key]
+		st.addKernelEvents(kernelEvents)
+		st.addDeploys(ctx, client, since)
+
+		var series map[string][]model.SamplePair
+		if provider != nil {
+			w := &st.result
+			if series, err = provider.GetWorkloadMemorySeries(ctx, w.Namespace, w.Workload, w.Kind, since, now, step); err != nil {
+				return nil, err
+			}
+		}
+		st.finish(series, step, margin)
+		result.Workloads = append(result.Workloads, st.result)
+	}
+
+	sort.SliceStable(result.Workloads, func(i, j int) bool {
+		a, b := &result.Workloads[i], &result.Workloads[j]
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		return a.LastKill.After(b.LastKill)
+	})
+	return result, nil
+}
+
+// addPod records the pod's containers and their OOM kills and restarts in
+// the window. It returns false when the pod has no OOM kill.
+func (st *oomWorkloadState) addPod(pod *corev1.Pod, since time.Time) bool {
+	specs := make(map[string]*corev1.Container, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		specs[pod.Spec.Containers[i].Name] = &pod.Spec.Containers[i]
+	}
+
+	killed := false
+	for i := range pod.Status.ContainerStatuses {
+		cs := &pod.Status.ContainerStatuses[i]
+		var kills []time.Time
+		for _, term := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+			if term != nil && term.Reason == "OOMKilled" && !term.FinishedAt.Time.Before(since) {
+				kills = append(kills, term.FinishedAt.Time)
+			}
+		}
+		if len(kills) == 0 {
+			continue
+		}
+		killed = true
+
+		c := st.containers[cs.Name]
+		if c == nil {
+			c = &OOMContainer{Name: cs.Name}
+			if spec := specs[cs.Name]; spec != nil {
+				if q, ok := spec.Resources.Requests[corev1.ResourceMemory]; ok {
+					c.RequestBytes = float64(q.Value())
+				}
+				if q, ok := spec.Resources.Limits[corev1.ResourceMemory]; ok {
+					c.LimitBytes = float64(q.Value())
+				}
+			}
+			st.containers[cs.Name] = c
+		}
+		c.Kills += len(kills)
+		c.Restarts += cs.RestartCount
+
+		for _, t := range kills {
+			detail := "OOMKilled"
+			if c.LimitBytes > 0 {
+				detail += " (limit " + units.Memory(c.LimitBytes) + ")"
+			}
+			st.kills = append(st.kills, OOMEvent{
+				Time: t, Kind: OOMEventKill, Pod: pod.Name, Container: cs.Name, Node: pod.Spec.NodeName, Detail: detail,
+			})
+			if pod.Spec.NodeName != "" {
+				st.nodeKills[pod.Spec.NodeName] = append(st.nodeKills[pod.Spec.NodeName], t)
+			}
+		}
+		if running := cs.State.Running; running != nil && cs.RestartCount > 0 && !running.StartedAt.Time.Before(since) {
+			st.result.Timeline = append(st.result.Timeline, OOMEvent{
+				Time: running.StartedAt.Time, Kind: OOMEventRestart, Pod: pod.Name, Container: cs.Name,
+				Detail: "restarted (restart #" + strconv.Itoa(int(cs.RestartCount)) + ")",
+			})
+		}
+	}
+	return killed
+}
+
+// podOOMKills counts the pod's container terminations since the given time
+// that were OOM kills.
+func podOOMKills(pod *corev1.Pod, since time.Time) int {
+	kills := 0
+	for i := range pod.Status.ContainerStatuses {
+		cs := &pod.Status.ContainerStatuses[i]
+		for _, term := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+			if term != nil && term.Reason == "OOMKilled" && !term.FinishedAt.Time.Before(since) {
+				kills++
+			}
+		}
+	}
+	return kills
+}
+
+// oomIndex counts the OOM kills visible in pod statuses per workload, so
+// other analyses can cross-check a recommendation without listing pods for
+// every workload. Each namespace's pods are listed once, on first use, and
+// the list is shared with other per-namespace passes through pods.
+type oomIndex struct {
+	client kubernetes.Interface
+	since  time.Time
+
+	mu    sync.Mutex
+	lists map[string]*namespacePods
+}
+
+type namespacePods struct {
+	pods  []corev1.Pod
+	err   error
+	kills map[string]int // "Kind/name" -> kills
+}
+
+func newOOMIndex(client kubernetes.Interface, since time.Time) *oomIndex {
+	return &oomIndex{client: client, since: since, lists: make(map[string]*namespacePods)}
+}
+
+// namespace lists the namespace's pods on first use and indexes their kills.
+func (x *oomIndex) namespace(ctx context.Context, namespace string) *namespacePods {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if np, ok := x.lists[namespace]; ok {
+		return np
+	}
+	np := &namespacePods{kills: make(map[string]int)}
+	if list, err := x.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		np.err = err
+	} else {
+		np.pods = list.Items
+	}
+	for i := range np.pods {
+		pod := &np.pods[i]
+		if n := podOOMKills(pod, x.since); n > 0 {
+			k, w := podTemplateWorkload(pod)
+			np.kills[k+"/"+w] += n
+		}
+	}
+	x.lists[namespace] = np
+	return np
+}
+
+// pods returns the namespace's pods, listing them on first use.
+func (x *oomIndex) pods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	np := x.namespace(ctx, namespace)
+	return np.pods, np.err
+}
+
+// count returns the workload's OOM kills since the index's start. Listing
+// errors count as no kills.
+func (x *oomIndex) count(ctx context.Context, namespace, kind, name string) int {
+	if x == nil || x.client == nil {
+		return 0
+	}
+	return x.namespace(ctx, namespace).kills[kind+"/"+name]
+}
+
+// podTemplateWorkload is podWorkload without a ReplicaSet lookup: a
+// Deployment's ReplicaSet is named after it plus the pod-template-hash.
+func podTemplateWorkload(pod *corev1.Pod) (kind, name string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; owner.Kind == "ReplicaSet" && hash != "" &&
+		strings.HasSuffix(owner.Name, "-"+hash) {
+		return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+	}
+	return owner.Kind, owner.Name
+}
+
+// addKernelEvents adds node OOM events close to one of the workload's kills
+// on the same node.
+func (st *oomWorkloadState) addKernelEvents(events []corev1.Event) {
+	for i := range events {
+		e := &events[i]
+		t := eventTime(e)
+		for _, kill := range st.nodeKills[e.InvolvedObject.Name] {
+			if d := t.Sub(kill); d >= -oomCorrelationWindow && d <= oomCorrelationWindow {
+				st.result.Timeline = append(st.result.Timeline, OOMEvent{
+					Time: t, Kind: OOMEventKernel, Node: e.InvolvedObject.Name, Detail: e.Reason + ": " + strings.TrimSpace(e.Message),
+				})
+				break
+			}
+		}
+	}
+}
+
+// addDeploys adds the workload's rollouts in the window.
+func (st *oomWorkloadState) addDeploys(ctx context.Context, client kubernetes.Interface, since time.Time) {
+	w := &st.result
+	for _, r := range workloadRollouts(ctx, client, w.Namespace) {
+		if r.Kind == w.Kind && r.Workload == w.Workload && !r.Time.Before(since) {
+			w.Timeline = append(w.Timeline, OOMEvent{Time: r.Time, Kind: OOMEventDeploy, Detail: r.Detail})
+		}
+	}
+}
+
+// workloadRollout is one revision of a workload.
+type workloadRollout struct {
+	Namespace string
+	Kind      string
+	Workload  string
+	Name      string // ReplicaSet or ControllerRevision
+	Time      time.Time
+	Detail    string
+}
+
+// workloadRollouts lists the revisions of the namespace's workloads:
+// Deployment ReplicaSets and StatefulSet/DaemonSet ControllerRevisions.
+// Listing errors leave them out.
+func workloadRollouts(ctx context.Context, client kubernetes.Interface, namespace string) []workloadRollout {
+	var out []workloadRollout
+	if list, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range list.Items {
+			rs := &list.Items[i]
+			owner := metav1.GetControllerOf(rs)
+			if owner == nil || owner.Kind != "Deployment" {
+				continue
+			}
+			detail := "rollout to ReplicaSet " + rs.Name
+			if rev := rs.Annotations["deployment.kubernetes.io/revision"]; rev != "" {
+				detail = "revision " + rev + " (" + rs.Name + ")"
+			}
+			if images := containerImages(rs.Spec.Template.Spec.Containers); len(images) > 0 {
+				detail += ": " + strings.Join(images, ", ")
+			}
+			out = append(out, workloadRollout{
+				Namespace: rs.Namespace, Kind: owner.Kind, Workload: owner.Name, Name: rs.Name,
+				Time: rs.CreationTimestamp.Time, Detail: detail,
+			})
+		}
+	}
+	if list, err := client.AppsV1().ControllerRevisions(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range list.Items {
+			cr := &list.Items[i]
+			owner := metav1.GetControllerOf(cr)
+			if owner == nil || owner.Kind != "StatefulSet" && owner.Kind != "DaemonSet" {
+				continue
+			}
+			out = append(out, workloadRollout{
+				Namespace: cr.Namespace, Kind: owner.Kind, Workload: owner.Name, Name: cr.Name,
+				Time:   cr.CreationTimestamp.Time,
+				Detail: "revision " + strconv.FormatInt(cr.Revision, 10) + " (" + cr.Name + ")",
+			})
+		}
+	}
+	return out
+}
+
+// finish attaches working-set samples to the kills, classifies the cause,
+// recommends limits, and sorts the timeline.
+func (st *oomWorkloadState) finish(series map[string][]model.SamplePair, step time.Duration, margin float64) {
+	w := &st.result
+	belowLimit := 0
+	for i := range st.kills {
+		k := &st.kills[i]
+		c := st.containers[k.Container]
+		k.WorkingSetBytes = workingSetAt(series[k.Container], k.Time, step)
+		c.WorkingSetAtKillBytes = math.Max(c.WorkingSetAtKillBytes, k.WorkingSetBytes)
+		if k.WorkingSetBytes > 0 && c.LimitBytes > 0 {
+			pct := k.WorkingSetBytes / c.LimitBytes * 100
+			k.Detail += fmt.Sprintf(", working set %s (%.0f%% of limit)", units.Memory(k.WorkingSetBytes), pct)
+			if pct < oomAtLimitRatio*100 {
+				belowLimit++
+			}
+		}
+		w.Kills++
+		if k.Time.After(w.LastKill) {
+			w.LastKill = k.Time
+		}
+	}
+	w.Timeline = append(w.Timeline, st.kills...)
+	sort.SliceStable(w.Timeline, func(i, j int) bool {
+		a, b := &w.Timeline[i], &w.Timeline[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		return oomEventOrder[a.Kind] < oomEventOrder[b.Kind]
+	})
+
+	kernel := false
+	for i := range w.Timeline {
+		kernel = kernel || w.Timeline[i].Kind == OOMEventKernel
+	}
+	w.Cause = OOMCauseLimit
+	if kernel && (belowLimit > 0 || !st.anyLimit()) {
+		w.Cause = OOMCauseNodePressure
+		w.Findings = append(w.Findings,
+			"node-level OOM events coincide with the kills while containers were below their limits: the node is overcommitted, "+
+				"raise memory requests or move the workload rather than only raising limits")
+	}
+	if finding := deployFinding(w.Timeline); finding != "" {
+		w.Findings = append(w.Findings, finding)
+	}
+
+	names := make([]string, 0, len(st.containers))
+	for name := range st.containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := st.containers[name]
+		for _, p := range series[name] {
+			c.PeakWorkingSetBytes = math.Max(c.PeakWorkingSetBytes, float64(p.Value))
+		}
+		c.RecommendedLimitBytes = recommendOOMLimit(c, margin)
+		if c.LimitBytes == 0 {
+			w.Findings = append(w.Findings, "container "+name+" has no memory limit: kills come from node memory pressure")
+		}
+		w.Containers = append(w.Containers, *c)
+	}
+}
+
+func (st *oomWorkloadState) anyLimit() bool {
+	for _, c := range st.containers {
+		if c.LimitBytes > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// recommendOOMLimit sizes a limit from the observed demand plus margin. A
+// container killed at its limit needed at least the limit, since the
+// working set cannot be observed above it; a limit is never lowered, as
+// samples can miss the spike behind a kill. Returns 0 without any signal.
+func recommendOOMLimit(c *OOMContainer, margin float64) float64 {
+	demand := math.Max(c.PeakWorkingSetBytes, c.WorkingSetAtKillBytes)
+	atLimit := c.LimitBytes > 0 && (c.WorkingSetAtKillBytes == 0 || c.WorkingSetAtKillBytes >= c.LimitBytes*oomAtLimitRatio)
+	if atLimit {
+		demand = math.Max(demand, c.LimitBytes)
+	}
+	if demand <= 0 {
+		return 0
+	}
+	return math.Max(math.Ceil(demand*(1+margin)/oomLimitRounding)*oomLimitRounding, c.LimitBytes)
+}
+
+// deployFinding reports kills that started within oomDeployWindow after a
+// rollout, pointing at a regression in the new revision.
+func deployFinding(timeline []OOMEvent) string {
+	var lastDeploy *OOMEvent
+	for i := range timeline {
+		e := &timeline[i]
+		switch e.Kind {
+		case OOMEventDeploy:
+			lastDeploy = e
+		case OOMEventKill:
+			if lastDeploy != nil && e.Time.Sub(lastDeploy.Time) <= oomDeployWindow {
+				return fmt.Sprintf("first kill %s after %s: check the new revision for a memory regression",
+					e.Time.Sub(lastDeploy.Time).Round(time.Second), lastDeploy.Detail)
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// workingSetAt returns the highest working-set sample around t. Each
+// sample is the maximum of the step ending at its timestamp, so the samples
+// in [t-step, t+step) cover the step of the kill and the one before it.
+func workingSetAt(samples []model.SamplePair, t time.Time, step time.Duration) float64 {
+	var value float64
+	for _, p := range samples {
+		ts := p.Timestamp.Time()
+		if !ts.Before(t.Add(-step)) && ts.Before(t.Add(step)) {
+			value = math.Max(value, float64(p.Value))
+		}
+	}
+	return value
+}
+
+// kernelOOMEvents lists node OOM events in the window. Listing errors
+// leave them out.
+func kernelOOMEvents(ctx context.Context, client kubernetes.Interface, since time.Time) []corev1.Event {
+	list, err := client.CoreV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=Node"})
+	if err != nil {
+		return nil
+	}
+	var events []corev1.Event
+	for i := range list.Items {
+		e := &list.Items[i]
+		if e.InvolvedObject.Kind == "Node" && kernelOOMReasons[e.Reason] && !eventTime(e).Before(since) {
+			events = append(events, *e)
+		}
+	}
+	return events
+}
+
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// replicaSetDeployments maps "namespace/replicaset" to the owning
+// Deployment. Listing errors leave pods attributed to their ReplicaSet.
+func replicaSetDeployments(ctx context.Context, client kubernetes.Interface, namespace string) map[string]string {
+	list, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	owners := make(map[string]string, len(list.Items))
+	for i := range list.Items {
+		rs := &list.Items[i]
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.Kind == "Deployment" {
+			owners[rs.Namespace+"/"+rs.Name] = owner.Name
+		}
+	}
+	return owners
+}
+
+// podWorkload resolves the workload controlling a pod through its owner
+// references; pods without a controller are their own workload.
+func podWorkload(pod *corev1.Pod, rsOwners map[string]string) (kind, name string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		if dep, ok := rsOwners[pod.Namespace+"/"+owner.Name]; ok {
+			return "Deployment", dep
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+// containerImages lists the images of a pod template's containers.
+func containerImages(containers []corev1.Container) []string {
+	images := make([]string, 0, len(containers))
+	for i := range containers {
+		images = append(images, containers[i].Image)
+	}
+	return images
+}
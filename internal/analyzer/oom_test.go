@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+const mib = 1024 * 1024
+
+func oomPod(name, owner, ownerKind, node, limit string, killedAt, startedAt time.Time, restarts int32) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec: corev1.PodSpec{
+			NodeName:   node,
+			Containers: []corev1.Container{{Name: "app"}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "app",
+			RestartCount: restarts,
+			State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+		}}},
+	}
+	if owner != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: owner, Controller: &controller}}
+	}
+	if limit != "" {
+		pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(limit)}
+	}
+	if !killedAt.IsZero() {
+		pod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+			Reason: "OOMKilled", ExitCode: 137, FinishedAt: metav1.NewTime(killedAt),
+		}
+	}
+	return pod
+}
+
+func oomReplicaSet(name, deployment, revision string, created time.Time) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "prod", CreationTimestamp: metav1.NewTime(created),
+			Annotations:     map[string]string{"deployment.kubernetes.io/revision": revision},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: deployment, Controller: &controller}},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "api:" + revision}},
+		}}},
+	}
+}
+
+func TestAnalyzeOOMKills(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	deployed := now.Add(-3 * time.Hour)
+	kill := deployed.Add(20 * time.Minute)
+
+	client := fake.NewClientset(
+		oomReplicaSet("api-7d9f8b6c4", "api", "5", deployed),
+		oomReplicaSet("api-5c6d7e8f9", "api", "4", now.Add(-30*24*time.Hour)),
+		oomPod("api-7d9f8b6c4-abcde", "api-7d9f8b6c4", "ReplicaSet", "node-1", "512Mi", kill, kill.Add(10*time.Second), 3),
+		oomPod("api-7d9f8b6c4-fghij", "api-7d9f8b6c4", "ReplicaSet", "node-2", "512Mi", time.Time{}, deployed, 0),
+		oomPod("cache-0", "cache", "StatefulSet", "node-1", "", now.Add(-time.Hour), now.Add(-time.Hour), 1),
+		oomPod("old-0", "old", "StatefulSet", "node-1", "1Gi", now.Add(-30*24*time.Hour), now, 1),
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "node-1.oom", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			Reason:         "SystemOOM",
+			Message:        "System OOM encountered, victim process: redis-server, pid: 4242",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Hour + 30*time.Second)),
+		},
+	)
+
+	provider := metrics.NewMockMetrics()
+	step := DefaultOOMWindow / oomMaxSeriesPoints
+	provider.MemorySeries["prod/api"] = map[string][]model.SamplePair{"app": {
+		{Timestamp: model.TimeFromUnixNano(kill.Add(-step).UnixNano()), Value: 400 * mib},
+		{Timestamp: model.TimeFromUnixNano(kill.UnixNano()), Value: 510 * mib},
+		{Timestamp: model.TimeFromUnixNano(kill.Add(step).UnixNano()), Value: 300 * mib},
+	}}
+
+	result, err := AnalyzeOOMKills(context.Background(), client, provider, OOMConfig{Namespace: "prod", Now: now})
+	require.NoError(t, err)
+	assert.True(t, result.HasMetrics)
+	require.Len(t, result.Workloads, 2, "old-0 was killed outside the window")
+
+	cache, api := result.Workloads[0], result.Workloads[1]
+	assert.Equal(t, "api", api.Workload)
+	assert.Equal(t, "Deployment", api.Kind)
+	assert.Equal(t, 1, api.Kills)
+	assert.Equal(t, OOMCauseLimit, api.Cause)
+	require.Len(t, api.Containers, 1)
+	c := api.Containers[0]
+	assert.Equal(t, float64(512*mib), c.LimitBytes)
+	assert.Equal(t, float64(510*mib), c.WorkingSetAtKillBytes)
+	assert.Equal(t, float64(510*mib), c.PeakWorkingSetBytes)
+	assert.Equal(t, float64(640*mib), c.RecommendedLimitBytes, "killed at the limit: 512Mi + 25%")
+
+	kinds := make([]string, 0, len(api.Timeline))
+	for _, e := range api.Timeline {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Equal(t, []string{OOMEventDeploy, OOMEventKill, OOMEventRestart}, kinds)
+	assert.Contains(t, api.Timeline[0].Detail, "revision 5 (api-7d9f8b6c4): api:5")
+	assert.Contains(t, api.Timeline[1].Detail, "working set 510.00Mi (100% of limit)")
+	require.Len(t, api.Findings, 1)
+	assert.Contains(t, api.Findings[0], "first kill 20m0s after revision 5")
+
+	assert.Equal(t, "cache", cache.Workload)
+	assert.Equal(t, OOMCauseNodePressure, cache.Cause)
+	require.Len(t, cache.Timeline, 3)
+	assert.Equal(t, OOMEventKernel, cache.Timeline[2].Kind)
+	assert.Equal(t, "node-1", cache.Timeline[2].Node)
+	assert.Contains(t, cache.Timeline[2].Detail, "SystemOOM: System OOM encountered")
+	assert.Zero(t, cache.Containers[0].RecommendedLimitBytes, "no limit and no metrics: nothing to size from")
+	assert.Contains(t, cache.Findings[len(cache.Findings)-1], "has no memory limit")
+}
+
+func TestAnalyzeOOMKills_WithoutMetrics(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := fake.NewClientset(
+		oomPod("worker", "", "", "node-1", "256Mi", now.Add(-time.Hour), now.Add(-time.Hour), 1),
+		oomPod("healthy", "", "", "node-1", "256Mi", time.Time{}, now, 0),
+	)
+
+	result, err := AnalyzeOOMKills(context.Background(), client, nil, OOMConfig{Now: now, Margin: 0.5})
+	require.NoError(t, err)
+	assert.False(t, result.HasMetrics)
+	require.Len(t, result.Workloads, 1)
+	w := result.Workloads[0]
+	assert.Equal(t, "Pod", w.Kind)
+	assert.Equal(t, OOMCauseLimit, w.Cause)
+	assert.Equal(t, float64(384*mib), w.Containers[0].RecommendedLimitBytes)
+}
+
+func TestRecommendOOMLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		c    OOMContainer
+		want float64
+	}{
+		{"killed at limit", OOMContainer{LimitBytes: 512 * mib, WorkingSetAtKillBytes: 500 * mib}, 640 * mib},
+		{
+			"peak above kill sample",
+			OOMContainer{LimitBytes: 512 * mib, WorkingSetAtKillBytes: 480 * mib, PeakWorkingSetBytes: 600 * mib},
+			750 * mib,
+		},
+		{"killed well below limit keeps the limit", OOMContainer{LimitBytes: 1024 * mib, WorkingSetAtKillBytes: 200 * mib}, 1024 * mib},
+		{"rounded up to MiB", OOMContainer{PeakWorkingSetBytes: 100*mib + 1}, 126 * mib},
+		{"no signal", OOMContainer{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, recommendOOMLimit(&tt.c, DefaultOOMMargin))
+		})
+	}
+}
@@ -0,0 +1,595 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// Orphan analysis defaults.
+const (
+	DefaultFailedJobAge = 7 * 24 * time.Hour
+
+	// orphanMinAge keeps objects created moments ago, e.g. mid-rollout, out
+	// of the report.
+	orphanMinAge = 24 * time.Hour
+)
+
+// Orphan categories reported in OrphanResource.Category.
+const (
+	OrphanScaledDownReplicaSet = "scaled-down-replicaset"
+	OrphanServiceNoEndpoints   = "service-without-endpoints"
+	OrphanConfigMap            = "unreferenced-configmap"
+	OrphanSecret               = "unreferenced-secret"
+	OrphanFailedJob            = "failed-job"
+	OrphanExpiredNamespace     = "expired-namespace"
+)
+
+// Namespace TTL annotations, as used by kube-janitor: a duration from
+// creation ("7d", "24h") or an absolute expiry time.
+const (
+	namespaceTTLAnnotation     = "janitor/ttl"
+	namespaceExpiresAnnotation = "janitor/expires"
+)
+
+// systemNamespaces hold ConfigMaps and Secrets read through the API by
+// control-plane components, so they are never reported as unreferenced.
+var systemNamespaces = map[string]bool{"kube-system": true, "kube-public": true, "kube-node-lease": true}
+
+// OrphansConfig holds configuration for the orphaned-resource analysis.
+type OrphansConfig struct {
+	Namespace    string        // "" = all namespaces
+	FailedJobAge time.Duration // failed Jobs older than this are reported (0 = DefaultFailedJobAge)
+	Rates        cost.Rates    // pricing for the reclaimed requests
+	Now          time.Time     // zero = time.Now(); set by tests
+}
+
+// OrphansResult is the outcome of FindOrphans.
+type OrphansResult struct {
+	FailedJobAge string           `json:"failed_job_age"`
+	Summary      OrphansSummary   `json:"summary"`
+	Resources    []OrphanResource `json:"resources"`
+	Warnings     []string         `json:"warnings,omitempty"` // checks skipped for lack of access
+	GeneratedAt  time.Time        `json:"generated_at"`
+}
+
+// OrphansSummary totals what the cleanup plan would reclaim.
+type OrphansSummary struct {
+	Resources      int            `json:"resources"`
+	ByCategory     map[string]int `json:"by_category"`
+	CPU            float64        `json:"cpu"`       // requested cores of pods that would be removed
+	MemoryGi       float64        `json:"memory_gi"` // requested memory of pods that would be removed
+	Pods           int            `json:"pods"`
+	DataBytes      int64          `json:"data_bytes"` // ConfigMap and Secret payloads stored in etcd
+	MonthlySavings float64        `json:"monthly_savings"`
+}
+
+// OrphanResource is one cleanup candidate with the command to remove it.
+type OrphanResource struct {
+	Category  string    `json:"category"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	Pods      int       `json:"pods,omitempty"` // pods removed with it
+	CPU       float64   `json:"cpu,omitempty"`
+	MemoryGi  float64   `json:"memory_gi,omitempty"`
+	DataBytes int64     `json:"data_bytes,omitempty"`
+	Command   string    `json:"command"`
+}
+
+// FindOrphans looks for resources that can be cleaned up: namespaces past
+// their TTL annotation, ReplicaSets scaled to zero that still run pods,
+// selector Services that match no pods, ConfigMaps and Secrets no pod,
+// workload template, ServiceAccount, or Ingress references, and Jobs that
+// failed longer ago than FailedJobAge. Resources in expired namespaces are
+// only reported with their namespace.
+//
+//nolint:gocyclo // one pass per category over the listed objects
+func FindOrphans(ctx context.Context, client kubernetes.Interface, cfg OrphansConfig) (*OrphansResult, error) {
+	jobAge := cfg.FailedJobAge
+	if jobAge <= 0 {
+		jobAge = DefaultFailedJobAge
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	result := &OrphansResult{FailedJobAge: jobAge.String(), Resources: []OrphanResource{}, GeneratedAt: now.UTC()}
+
+	expired, err := expiredNamespaces(ctx, client, cfg.Namespace, now)
+	if err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	for _, ns := range expired {
+		o := ns
+		for i := range pods.Items {
+			if p := &pods.Items[i]; p.Namespace == ns.Name && podActive(p) {
+				o.addPod(p)
+			}
+		}
+		result.Resources = append(result.Resources, o)
+	}
+	skip := make(map[string]bool, len(expired))
+	for i := range expired {
+		skip[expired[i].Name] = true
+	}
+
+	if rs, err := scaledDownReplicaSets(ctx, client, cfg.Namespace, pods.Items, skip); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	} else {
+		result.Resources = append(result.Resources, rs...)
+	}
+	if svcs, err := servicesWithoutPods(ctx, client, cfg.Namespace, pods.Items, skip, now); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	} else {
+		result.Resources = append(result.Resources, svcs...)
+	}
+	if data, err := unreferencedData(ctx, client, cfg.Namespace, pods.Items, skip, now); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	} else {
+		result.Resources = append(result.Resources, data...)
+	}
+	if jobs, err := failedJobs(ctx, client, cfg.Namespace, pods.Items, skip, now.Add(-jobAge)); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	} else {
+		result.Resources = append(result.Resources, jobs...)
+	}
+
+	sort.SliceStable(result.Resources, func(i, j int) bool {
+		a, b := &result.Resources[i], &result.Resources[j]
+		if a.Category != b.Category {
+			return orphanCategoryOrder[a.Category] < orphanCategoryOrder[b.Category]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	result.Summary = summarizeOrphans(result.Resources, cfg.Rates)
+	return result, nil
+}
+
+// orphanCategoryOrder lists the categories by what they reclaim: running
+// pods first, then objects.
+var orphanCategoryOrder = map[string]int{
+	OrphanExpiredNamespace:     0,
+	OrphanScaledDownReplicaSet: 1,
+	OrphanFailedJob:            2,
+	OrphanServiceNoEndpoints:   3,
+	OrphanConfigMap:            4,
+	OrphanSecret:               5,
+}
+
+func summarizeOrphans(resources []OrphanResource, rates cost.Rates) OrphansSummary {
+	s := OrphansSummary{Resources: len(resources), ByCategory: map[string]int{}}
+	for i := range resources {
+		r := &resources[i]
+		s.ByCategory[r.Category]++
+		s.CPU += r.CPU
+		s.MemoryGi += r.MemoryGi
+		s.Pods += r.Pods
+		s.DataBytes += r.DataBytes
+	}
+	s.MonthlySavings = cost.MonthlyCost(s.CPU, s.MemoryGi, rates)
+	return s
+}
+
+func (o *OrphanResource) addPod(p *corev1.Pod) {
+	cpu, memGi := podSpecRequests(&p.Spec)
+	o.Pods++
+	o.CPU += cpu
+	o.MemoryGi += memGi
+}
+
+// podActive reports whether a pod holds its requests: scheduled, not
+// finished, and not being deleted.
+func podActive(p *corev1.Pod) bool {
+	return p.DeletionTimestamp == nil && p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed
+}
+
+// expiredNamespaces returns the namespaces whose TTL annotation has passed.
+func expiredNamespaces(ctx context.Context, client kubernetes.Interface, namespace string, now time.Time) ([]OrphanResource, error) {
+	var items []corev1.Namespace
+	if namespace != "" {
+		ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("skipped namespace TTL check: %w", err)
+		}
+		items = []corev1.Namespace{*ns}
+	} else {
+		list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("skipped namespace TTL check: %w", err)
+		}
+		items = list.Items
+	}
+
+	var out []OrphanResource
+	for i := range items {
+		ns := &items[i]
+		expiry, source := namespaceExpiry(ns)
+		if expiry.IsZero() || now.Before(expiry) || systemNamespaces[ns.Name] {
+			continue
+		}
+		out = append(out, OrphanResource{
+			Category:  OrphanExpiredNamespace,
+			Kind:      "Namespace",
+			Name:      ns.Name,
+			Reason:    fmt.Sprintf("%s expired %s ago", source, now.Sub(expiry).Round(time.Minute)),
+			CreatedAt: ns.CreationTimestamp.UTC(),
+			Command:   "kubectl delete namespace " + ns.Name,
+		})
+	}
+	return out, nil
+}
+
+// namespaceExpiry reads the TTL annotations; an unparsable value is ignored.
+func namespaceExpiry(ns *corev1.Namespace) (expiry time.Time, source string) {
+	if v := ns.Annotations[namespaceExpiresAnnotation]; v != "" {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, namespaceExpiresAnnotation + "=" + v
+			}
+		}
+	}
+	if v := ns.Annotations[namespaceTTLAnnotation]; v != "" && v != "forever" {
+		if ttl, err := metrics.ParseDuration(v); err == nil {
+			return ns.CreationTimestamp.Add(ttl), namespaceTTLAnnotation + "=" + v
+		}
+	}
+	return time.Time{}, ""
+}
+
+// scaledDownReplicaSets finds ReplicaSets with zero desired replicas whose
+// pods are still running, e.g. left behind by an interrupted rollout.
+func scaledDownReplicaSets(
+	ctx context.Context, client kubernetes.Interface, namespace string, pods []corev1.Pod, skip map[string]bool,
+) ([]OrphanResource, error) {
+	list, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("skipped ReplicaSet check: %w", err)
+	}
+	owned := make(map[string][]*corev1.Pod)
+	for i := range pods {
+		p := &pods[i]
+		if owner := metav1.GetControllerOf(p); owner != nil && owner.Kind == "ReplicaSet" && podActive(p) && p.Status.Phase == corev1.PodRunning {
+			owned[string(owner.UID)] = append(owned[string(owner.UID)], p)
+		}
+	}
+
+	var out []OrphanResource
+	for i := range list.Items {
+		rs := &list.Items[i]
+		if skip[rs.Namespace] || rs.Spec.Replicas == nil || *rs.Spec.Replicas != 0 || len(owned[string(rs.UID)]) == 0 {
+			continue
+		}
+		o := OrphanResource{
+			Category:  OrphanScaledDownReplicaSet,
+			Kind:      "ReplicaSet",
+			Namespace: rs.Namespace,
+			Name:      rs.Name,
+			CreatedAt: rs.CreationTimestamp.UTC(),
+			Command:   fmt.Sprintf("kubectl delete replicaset -n %s %s", rs.Namespace, rs.Name),
+		}
+		for _, p := range owned[string(rs.UID)] {
+			o.addPod(p)
+		}
+		o.Reason = fmt.Sprintf("scaled to 0 replicas but %d pod(s) still running", o.Pods)
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// servicesWithoutPods finds selector Services whose selector matches no
+// pods, so they never get endpoints.
+func servicesWithoutPods(
+	ctx context.Context, client kubernetes.Interface, namespace string, pods []corev1.Pod, skip map[string]bool, now time.Time,
+) ([]OrphanResource, error) {
+	list, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("skipped Service check: %w", err)
+	}
+	var out []OrphanResource
+	for i := range list.Items {
+		svc := &list.Items[i]
+		if skip[svc.Namespace] || len(svc.Spec.Selector) == 0 || svc.Spec.Type == corev1.ServiceTypeExternalName ||
+			now.Sub(svc.CreationTimestamp.Time) < orphanMinAge {
+			continue
+		}
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		matched := false
+		for j := range pods {
+			if pods[j].Namespace == svc.Namespace && selector.Matches(labels.Set(pods[j].Labels)) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		out = append(out, OrphanResource{
+			Category:  OrphanServiceNoEndpoints,
+			Kind:      "Service",
+			Namespace: svc.Namespace,
+			Name:      svc.Name,
+			Reason:    "selector " + selector.String() + " matches no pods",
+			CreatedAt: svc.CreationTimestamp.UTC(),
+			Command:   fmt.Sprintf("kubectl delete service -n %s %s", svc.Namespace, svc.Name),
+		})
+	}
+	return out, nil
+}
+
+// unreferencedData finds ConfigMaps and Secrets that nothing references.
+// References come from pods, the pod templates of every workload kind (so a
+// Deployment scaled to zero keeps its config), ServiceAccounts, and Ingress
+// TLS. Owned objects, service-account tokens, Helm release records, and
+// system namespaces are left out. A failed reference listing skips the
+// check rather than reporting everything as unreferenced.
+func unreferencedData(
+	ctx context.Context, client kubernetes.Interface, namespace string, pods []corev1.Pod, skip map[string]bool, now time.Time,
+) ([]OrphanResource, error) {
+	refs, err := dataReferences(ctx, client, namespace, pods)
+	if err != nil {
+		return nil, fmt.Errorf("skipped ConfigMap/Secret check: %w", err)
+	}
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("skipped ConfigMap/Secret check: %w", err)
+	}
+	secrets, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("skipped ConfigMap/Secret check: %w", err)
+	}
+
+	candidate := func(meta *metav1.ObjectMeta, kind string) bool {
+		return !skip[meta.Namespace] && !systemNamespaces[meta.Namespace] && len(meta.OwnerReferences) == 0 &&
+			now.Sub(meta.CreationTimestamp.Time) >= orphanMinAge && !refs[kind+"/"+meta.Namespace+"/"+meta.Name]
+	}
+
+	var out []OrphanResource
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		if cm.Name == "kube-root-ca.crt" || !candidate(&cm.ObjectMeta, "ConfigMap") {
+			continue
+		}
+		var size int64
+		for _, v := range cm.Data {
+			size += int64(len(v))
+		}
+		for _, v := range cm.BinaryData {
+			size += int64(len(v))
+		}
+		out = append(out, OrphanResource{
+			Category: OrphanConfigMap, Kind: "ConfigMap", Namespace: cm.Namespace, Name: cm.Name,
+			Reason:    "not referenced by any pod, workload template, or Ingress",
+			CreatedAt: cm.CreationTimestamp.UTC(), DataBytes: size,
+			Command: fmt.Sprintf("kubectl delete configmap -n %s %s", cm.Namespace, cm.Name),
+		})
+	}
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		if s.Type == corev1.SecretTypeServiceAccountToken || s.Type == "helm.sh/release.v1" || !candidate(&s.ObjectMeta, "Secret") {
+			continue
+		}
+		var size int64
+		for _, v := range s.Data {
+			size += int64(len(v))
+		}
+		out = append(out, OrphanResource{
+			Category: OrphanSecret, Kind: "Secret", Namespace: s.Namespace, Name: s.Name,
+			Reason:    "not referenced by any pod, workload template, ServiceAccount, or Ingress",
+			CreatedAt: s.CreationTimestamp.UTC(), DataBytes: size,
+			Command: fmt.Sprintf("kubectl delete secret -n %s %s", s.Namespace, s.Name),
+		})
+	}
+	return out, nil
+}
+
+// dataReferences returns "ConfigMap/<ns>/<name>" and "Secret/<ns>/<name>"
+// keys of every referenced object.
+func dataReferences(ctx context.Context, client kubernetes.Interface, namespace string, pods []corev1.Pod) (map[string]bool, error) {
+	refs := make(map[string]bool)
+	addSpec := func(ns string, spec *corev1.PodSpec) {
+		for _, key := range podSpecDataRefs(spec) {
+			refs[strings.Replace(key, "/", "/"+ns+"/", 1)] = true
+		}
+	}
+	for i := range pods {
+		addSpec(pods[i].Namespace, &pods[i].Spec)
+	}
+
+	apps := client.AppsV1()
+	deps, err := apps.Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range deps.Items {
+		addSpec(deps.Items[i].Namespace, &deps.Items[i].Spec.Template.Spec)
+	}
+	rss, err := apps.ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range rss.Items {
+		addSpec(rss.Items[i].Namespace, &rss.Items[i].Spec.Template.Spec)
+	}
+	sts, err := apps.StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range sts.Items {
+		addSpec(sts.Items[i].Namespace, &sts.Items[i].Spec.Template.Spec)
+	}
+	dss, err := apps.DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range dss.Items {
+		addSpec(dss.Items[i].Namespace, &dss.Items[i].Spec.Template.Spec)
+	}
+	jobs, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs.Items {
+		addSpec(jobs.Items[i].Namespace, &jobs.Items[i].Spec.Template.Spec)
+	}
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range cronJobs.Items {
+		addSpec(cronJobs.Items[i].Namespace, &cronJobs.Items[i].Spec.JobTemplate.Spec.Template.Spec)
+	}
+
+	sas, err := client.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range sas.Items {
+		sa := &sas.Items[i]
+		for _, s := range sa.Secrets {
+			refs["Secret/"+sa.Namespace+"/"+s.Name] = true
+		}
+		for _, s := range sa.ImagePullSecrets {
+			refs["Secret/"+sa.Namespace+"/"+s.Name] = true
+		}
+	}
+	ingresses, err := client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range ingresses.Items {
+		ing := &ingresses.Items[i]
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName != "" {
+				refs["Secret/"+ing.Namespace+"/"+tls.SecretName] = true
+			}
+		}
+	}
+	return refs, nil
+}
+
+// podSpecDataRefs returns "ConfigMap/<name>" and "Secret/<name>" keys for
+// the volumes, projected sources, env, envFrom, and image pull secrets of a
+// pod spec.
+func podSpecDataRefs(spec *corev1.PodSpec) []string {
+	var refs []string
+	for i := range spec.Volumes {
+		v := &spec.Volumes[i]
+		if v.ConfigMap != nil {
+			refs = append(refs, "ConfigMap/"+v.ConfigMap.Name)
+		}
+		if v.Secret != nil {
+			refs = append(refs, "Secret/"+v.Secret.SecretName)
+		}
+		if v.Projected != nil {
+			for _, src := range v.Projected.Sources {
+				if src.ConfigMap != nil {
+					refs = append(refs, "ConfigMap/"+src.ConfigMap.Name)
+				}
+				if src.Secret != nil {
+					refs = append(refs, "Secret/"+src.Secret.Name)
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for i := range containers {
+		c := &containers[i]
+		for _, from := range c.EnvFrom {
+			if from.ConfigMapRef != nil {
+				refs = append(refs, "ConfigMap/"+from.ConfigMapRef.Name)
+			}
+			if from.SecretRef != nil {
+				refs = append(refs, "Secret/"+from.SecretRef.Name)
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				refs = append(refs, "ConfigMap/"+env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				refs = append(refs, "Secret/"+env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	for _, s := range spec.ImagePullSecrets {
+		refs = append(refs, "Secret/"+s.Name)
+	}
+	return refs
+}
+
+// failedJobs finds Jobs that failed before the cutoff and still exist
+// with their pods.
+func failedJobs(
+	ctx context.Context, client kubernetes.Interface, namespace string, pods []corev1.Pod, skip map[string]bool, cutoff time.Time,
+) ([]OrphanResource, error) {
+	list, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("skipped Job check: %w", err)
+	}
+	podsByJob := make(map[string]int)
+	for i := range pods {
+		if owner := metav1.GetControllerOf(&pods[i]); owner != nil && owner.Kind == "Job" {
+			podsByJob[string(owner.UID)]++
+		}
+	}
+
+	var out []OrphanResource
+	for i := range list.Items {
+		job := &list.Items[i]
+		failedAt, reason := jobFailure(job)
+		if skip[job.Namespace] || failedAt.IsZero() || failedAt.After(cutoff) {
+			continue
+		}
+		msg := "failed " + failedAt.UTC().Format(time.RFC3339)
+		if reason != "" {
+			msg += " (" + reason + ")"
+		}
+		out = append(out, OrphanResource{
+			Category:  OrphanFailedJob,
+			Kind:      "Job",
+			Namespace: job.Namespace,
+			Name:      job.Name,
+			Reason:    msg,
+			CreatedAt: job.CreationTimestamp.UTC(),
+			Pods:      podsByJob[string(job.UID)],
+			Command:   fmt.Sprintf("kubectl delete job -n %s %s", job.Namespace, job.Name),
+		})
+	}
+	return out, nil
+}
+
+// jobFailure returns when and why a Job failed, or a zero time.
+func jobFailure(job *batchv1.Job) (time.Time, string) {
+	for i := range job.Status.Conditions {
+		c := &job.Status.Conditions[i]
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return c.LastTransitionTime.Time, c.Reason
+		}
+	}
+	return time.Time{}, ""
+}
@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+)
+
+func orphanMeta(ns, name string, created time.Time) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: ns, UID: types.UID(ns + "/" + name), CreationTimestamp: metav1.NewTime(created)}
+}
+
+func orphanPod(ns, name, ownerKind, owner string, labels map[string]string, spec corev1.PodSpec) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: orphanMeta(ns, name, time.Time{}), Spec: spec, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+	pod.Labels = labels
+	if owner != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: owner, UID: types.UID(ns + "/" + owner), Controller: &controller}}
+	}
+	return pod
+}
+
+func requestsSpec(cpu, mem string) corev1.PodSpec {
+	return corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)},
+	}}}}
+}
+
+func TestFindOrphans(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-30 * 24 * time.Hour)
+	zero := int32(0)
+
+	usedSpec := requestsSpec("500m", "1Gi")
+	usedSpec.Volumes = []corev1.Volume{{Name: "cfg", VolumeSource: corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "api-config"}},
+	}}}
+	usedSpec.Containers[0].Env = []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "api-token"}, Key: "token"},
+	}}}
+	scaledDown := &appsv1.Deployment{ObjectMeta: orphanMeta("prod", "batch", old)}
+	scaledDown.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", EnvFrom: []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "batch-env"}},
+	}}}}
+
+	client := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: orphanMeta("", "prod", old)},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "preview-42", CreationTimestamp: metav1.NewTime(now.Add(-50 * time.Hour)),
+			Annotations: map[string]string{"janitor/ttl": "2d"},
+		}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "preview-43", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+			Annotations: map[string]string{"janitor/expires": "2026-03-02"},
+		}},
+
+		&appsv1.ReplicaSet{ObjectMeta: orphanMeta("prod", "api-old", old), Spec: appsv1.ReplicaSetSpec{Replicas: &zero}},
+		orphanPod("prod", "api-old-abcde", "ReplicaSet", "api-old", map[string]string{"app": "api"}, usedSpec),
+		orphanPod("prod", "api-old-fghij", "ReplicaSet", "api-old", map[string]string{"app": "api"}, usedSpec),
+		orphanPod("preview-42", "web", "", "", nil, requestsSpec("1", "2Gi")),
+		scaledDown,
+
+		&corev1.Service{ObjectMeta: orphanMeta("prod", "api", old), Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "api"}}},
+		&corev1.Service{ObjectMeta: orphanMeta("prod", "legacy", old), Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "legacy"}}},
+		&corev1.Service{
+			ObjectMeta: orphanMeta("prod", "new", now.Add(-time.Minute)), Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "new"}},
+		},
+		&corev1.Service{ObjectMeta: orphanMeta("prod", "external", old)},
+		&corev1.Service{ObjectMeta: orphanMeta("preview-42", "web", old), Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "x"}}},
+
+		&corev1.ConfigMap{ObjectMeta: orphanMeta("prod", "api-config", old), Data: map[string]string{"a": "1"}},
+		&corev1.ConfigMap{ObjectMeta: orphanMeta("prod", "batch-env", old)},
+		&corev1.ConfigMap{ObjectMeta: orphanMeta("prod", "stale", old), Data: map[string]string{"config.yaml": "0123456789"}},
+		&corev1.ConfigMap{ObjectMeta: orphanMeta("prod", "kube-root-ca.crt", old)},
+		&corev1.ConfigMap{ObjectMeta: orphanMeta("kube-system", "kubeadm-config", old)},
+		&corev1.Secret{ObjectMeta: orphanMeta("prod", "api-token", old)},
+		&corev1.Secret{ObjectMeta: orphanMeta("prod", "tls", old)},
+		&corev1.Secret{ObjectMeta: orphanMeta("prod", "sh.helm.release.v1.api.v3", old), Type: "helm.sh/release.v1"},
+		&corev1.Secret{ObjectMeta: orphanMeta("prod", "old-creds", old), Data: map[string][]byte{"password": []byte("hunter2")}},
+		&networkingv1.Ingress{ObjectMeta: orphanMeta("prod", "api", old), Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{SecretName: "tls"}},
+		}},
+
+		&batchv1.Job{ObjectMeta: orphanMeta("prod", "migrate", old), Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+			Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+			LastTransitionTime: metav1.NewTime(now.Add(-10 * 24 * time.Hour)),
+		}}}},
+		&batchv1.Job{ObjectMeta: orphanMeta("prod", "recent", old), Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+			Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
+		}}}},
+	)
+
+	rates := cost.Rates{CPUPerCoreHour: 0.04, MemoryPerGiBHour: 0.005}
+	result, err := FindOrphans(context.Background(), client, OrphansConfig{Rates: rates, Now: now})
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+
+	type item struct{ category, name string }
+	got := make([]item, 0, len(result.Resources))
+	for _, r := range result.Resources {
+		got = append(got, item{r.Category, r.Namespace + "/" + r.Name})
+	}
+	assert.Equal(t, []item{
+		{OrphanExpiredNamespace, "/preview-42"},
+		{OrphanScaledDownReplicaSet, "prod/api-old"},
+		{OrphanFailedJob, "prod/migrate"},
+		{OrphanServiceNoEndpoints, "prod/legacy"},
+		{OrphanConfigMap, "prod/stale"},
+		{OrphanSecret, "prod/old-creds"},
+	}, got)
+
+	ns := result.Resources[0]
+	assert.Equal(t, "janitor/ttl=2d expired 2h0m0s ago", ns.Reason)
+	assert.Equal(t, 1, ns.Pods)
+	assert.Equal(t, "kubectl delete namespace preview-42", ns.Command)
+
+	rs := result.Resources[1]
+	assert.Equal(t, 2, rs.Pods)
+	assert.InDelta(t, 1.0, rs.CPU, 0.001)
+	assert.InDelta(t, 2.0, rs.MemoryGi, 0.001)
+	assert.Equal(t, "scaled to 0 replicas but 2 pod(s) still running", rs.Reason)
+	assert.Equal(t, "kubectl delete replicaset -n prod api-old", rs.Command)
+
+	assert.Contains(t, result.Resources[2].Reason, "BackoffLimitExceeded")
+	assert.Equal(t, "selector app=legacy matches no pods", result.Resources[3].Reason)
+	assert.Equal(t, int64(10), result.Resources[4].DataBytes)
+	assert.Equal(t, "kubectl delete secret -n prod old-creds", result.Resources[5].Command)
+
+	s := result.Summary
+	assert.Equal(t, 6, s.Resources)
+	assert.Equal(t, 1, s.ByCategory[OrphanConfigMap])
+	assert.Equal(t, 3, s.Pods)
+	assert.InDelta(t, 2.0, s.CPU, 0.001)
+	assert.InDelta(t, 4.0, s.MemoryGi, 0.001)
+	assert.Equal(t, int64(17), s.DataBytes)
+	assert.InDelta(t, cost.MonthlyCost(2, 4, rates), s.MonthlySavings, 0.001)
+}
+
+func TestFindOrphans_Namespace(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-30 * 24 * time.Hour)
+	client := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: orphanMeta("", "prod", old)},
+		&corev1.ConfigMap{ObjectMeta: orphanMeta("prod", "stale", old)},
+		&corev1.ConfigMap{ObjectMeta: orphanMeta("dev", "stale", old)},
+	)
+
+	result, err := FindOrphans(context.Background(), client, OrphansConfig{Namespace: "prod", Now: now})
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "prod", result.Resources[0].Namespace)
+	assert.Equal(t, DefaultFailedJobAge.String(), result.FailedJobAge)
+}
+
+func TestNamespaceExpiry(t *testing.T) {
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Time
+	}{
+		{"ttl", map[string]string{"janitor/ttl": "36h"}, created.Add(36 * time.Hour)},
+		{"expires date", map[string]string{"janitor/expires": "2026-03-05"}, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{
+			"expires wins", map[string]string{"janitor/ttl": "1d", "janitor/expires": "2026-03-05T10:00:00Z"},
+			time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC),
+		},
+		{"forever", map[string]string{"janitor/ttl": "forever"}, time.Time{}},
+		{"unparsable", map[string]string{"janitor/ttl": "soon"}, time.Time{}},
+		{"none", nil, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created), Annotations: tt.annotations}}
+			got, _ := namespaceExpiry(ns)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
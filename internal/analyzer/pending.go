@@ -0,0 +1,746 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// Fixes reported in PendingFix.Kind, from the smallest change up.
+const (
+	PendingFixFitsNow        = "fits-now"        // a node has room already; the scheduler has not retried yet
+	PendingFixReduceRequests = "reduce-requests" // a small request reduction fits an existing node
+	PendingFixScaleNodePool  = "scale-node-pool" // a new node of an eligible pool would fit the pod
+	PendingFixNewNodeGroup   = "new-node-group"  // no eligible node is large enough, even empty
+	PendingFixConstraints    = "constraints"     // no node passes the pod's taints, affinity, or volume constraints
+)
+
+// maxPendingRequestReduction is the largest request cut, as a share of the
+// request, still suggested instead of adding a node.
+const maxPendingRequestReduction = 0.25
+
+// Labels naming a node's pool, most specific first; nodes without any of
+// them are grouped by instance type.
+var pendingPoolLabels = []string{
+	"karpenter.sh/nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"cloud.google.com/gke-nodepool",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+	"node.kubernetes.io/instance-type",
+}
+
+// PendingConfig holds configuration for the pending pod analysis.
+type PendingConfig struct {
+	Namespace string    // "" = all namespaces
+	Now       time.Time // zero = time.Now(); set by tests
+}
+
+// PendingResult is the outcome of AnalyzePending.
+type PendingResult struct {
+	Nodes       int               `json:"nodes"`
+	Workloads   []PendingWorkload `json:"workloads"`
+	Summary     map[string]int    `json:"summary"` // fix kind -> workloads
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// PendingWorkload is a workload with unschedulable pods. Its pods share a
+// template, so the first pending pod stands for all of them.
+type PendingWorkload struct {
+	Namespace          string          `json:"namespace"`
+	Workload           string          `json:"workload"`
+	Kind               string          `json:"kind"`
+	Pods               int             `json:"pods"`
+	PendingSince       time.Time       `json:"pending_since"`
+	Age                string          `json:"age"`
+	CPURequest         float64         `json:"cpu_request"`          // cores per pod
+	MemoryRequestBytes float64         `json:"memory_request_bytes"` // per pod
+	SchedulerMessage   string          `json:"scheduler_message,omitempty"`
+	Reasons            []PendingReason `json:"reasons"`
+	Fix                PendingFix      `json:"fix"`
+}
+
+// PendingReason counts the nodes that reject a pod for one reason.
+type PendingReason struct {
+	Reason string `json:"reason"`
+	Nodes  int    `json:"nodes"`
+}
+
+// PendingFix is the smallest change that would let a workload's pods
+// schedule.
+type PendingFix struct {
+	Kind               string  `json:"kind"`
+	Detail             string  `json:"detail"`
+	Node               string  `json:"node,omitempty"`
+	Pool               string  `json:"pool,omitempty"`
+	CPURequest         float64 `json:"cpu_request,omitempty"`          // suggested, reduce-requests only
+	MemoryRequestBytes float64 `json:"memory_request_bytes,omitempty"` // suggested, reduce-requests only
+}
+
+// pendingNode is a node with the requests already scheduled on it.
+type pendingNode struct {
+	node        *corev1.Node
+	pool        string
+	allocCPU    float64
+	allocMemory float64
+	allocPods   int64
+	usedCPU     float64
+	usedMemory  float64
+	usedPods    int64
+	notReady    bool
+}
+
+// pendingCluster is the cluster state pending pods are checked against.
+type pendingCluster struct {
+	nodes        []*pendingNode
+	nodeByName   map[string]*pendingNode
+	scheduled    []*corev1.Pod
+	pvcs         map[string]*corev1.PersistentVolumeClaim // namespace/name
+	pvs          map[string]*corev1.PersistentVolume
+	lateBinding  map[string]bool // storage classes that bind on first consumer
+	defaultClass string
+}
+
+// nodeCheck is one node's verdict on a pod.
+type nodeCheck struct {
+	node        *pendingNode
+	constraints []string // taint, affinity, volume, and node state reasons
+	resources   []string // insufficient cpu, memory, or pods
+	shortCPU    float64
+	shortMemory float64
+	shortPods   bool
+}
+
+// AnalyzePending explains why pods are unschedulable. Each pending pod is
+// checked against every node the way the scheduler's filters do: node state,
+// taints, node selector and required node affinity, required pod
+// (anti-)affinity, volume node affinity, and free CPU, memory, and pod slots.
+// The smallest change that would let it schedule is, in order: nothing (a
+// node has room now), a request reduction of at most 25% that fits an
+// existing node, one more node in an eligible pool, a node group with
+// larger nodes, or relaxing the constraints no node passes.
+//
+//nolint:gocyclo // sequential list → index → check pipeline
+func AnalyzePending(ctx context.Context, client kubernetes.Interface, cfg PendingConfig) (*PendingResult, error) {
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	// All pods: nodes' free capacity and pod affinity span namespaces
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	cluster := newPendingCluster(ctx, client, nodes.Items, pods.Items)
+	rsOwners := replicaSetDeployments(ctx, client, cfg.Namespace)
+
+	result := &PendingResult{
+		Nodes:       len(nodes.Items),
+		Workloads:   []PendingWorkload{},
+		Summary:     map[string]int{},
+		GeneratedAt: now.UTC(),
+	}
+	byKey := make(map[string]int)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if cfg.Namespace != "" && pod.Namespace != cfg.Namespace {
+			continue
+		}
+		message, since, ok := unschedulable(pod)
+		if !ok {
+			continue
+		}
+		kind, name := podWorkload(pod, rsOwners)
+		key := pod.Namespace + "/" + kind + "/" + name
+		if idx, seen := byKey[key]; seen {
+			w := &result.Workloads[idx]
+			w.Pods++
+			if since.Before(w.PendingSince) {
+				w.PendingSince = since
+				w.Age = formatDuration(now.Sub(since))
+			}
+			continue
+		}
+
+		cpu, memory := pendingPodRequests(pod)
+		w := PendingWorkload{
+			Namespace:          pod.Namespace,
+			Workload:           name,
+			Kind:               kind,
+			Pods:               1,
+			PendingSince:       since,
+			Age:                formatDuration(now.Sub(since)),
+			CPURequest:         cpu,
+			MemoryRequestBytes: memory,
+			SchedulerMessage:   message,
+		}
+		checks := make([]nodeCheck, 0, len(cluster.nodes))
+		for _, n := range cluster.nodes {
+			checks = append(checks, cluster.check(pod, n, cpu, memory))
+		}
+		w.Reasons = pendingReasons(checks)
+		w.Fix = pendingFix(checks, cpu, memory)
+		byKey[key] = len(result.Workloads)
+		result.Workloads = append(result.Workloads, w)
+	}
+
+	for i := range result.Workloads {
+		result.Summary[result.Workloads[i].Fix.Kind]++
+	}
+	sort.SliceStable(result.Workloads, func(i, j int) bool {
+		a, b := &result.Workloads[i], &result.Workloads[j]
+		if !a.PendingSince.Equal(b.PendingSince) {
+			return a.PendingSince.Before(b.PendingSince)
+		}
+		return a.Namespace+"/"+a.Workload < b.Namespace+"/"+b.Workload
+	})
+	return result, nil
+}
+
+// unschedulable reports whether the scheduler marked the pod unschedulable,
+// with its message and since when.
+func unschedulable(pod *corev1.Pod) (message string, since time.Time, ok bool) {
+	if pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName != "" {
+		return "", time.Time{}, false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			since = c.LastTransitionTime.Time
+			if since.IsZero() {
+				since = pod.CreationTimestamp.Time
+			}
+			return c.Message, since, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+func newPendingCluster(
+	ctx context.Context, client kubernetes.Interface, nodes []corev1.Node, pods []corev1.Pod,
+) *pendingCluster {
+	c := &pendingCluster{
+		nodeByName:  make(map[string]*pendingNode, len(nodes)),
+		pvcs:        make(map[string]*corev1.PersistentVolumeClaim),
+		pvs:         make(map[string]*corev1.PersistentVolume),
+		lateBinding: make(map[string]bool),
+	}
+	for i := range nodes {
+		n := &nodes[i]
+		pn := &pendingNode{
+			node:        n,
+			pool:        pendingNodePool(n),
+			allocCPU:    n.Status.Allocatable.Cpu().AsApproximateFloat64(),
+			allocMemory: float64(n.Status.Allocatable.Memory().Value()),
+			allocPods:   n.Status.Allocatable.Pods().Value(),
+			notReady:    true,
+		}
+		for _, cond := range n.Status.Conditions {
+			if cond.Type == corev1.NodeReady {
+				pn.notReady = cond.Status != corev1.ConditionTrue
+			}
+		}
+		c.nodes = append(c.nodes, pn)
+		c.nodeByName[n.Name] = pn
+	}
+	sort.Slice(c.nodes, func(i, j int) bool { return c.nodes[i].node.Name < c.nodes[j].node.Name })
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		c.scheduled = append(c.scheduled, pod)
+		n := c.nodeByName[pod.Spec.NodeName]
+		if n == nil {
+			continue
+		}
+		cpu, memory := pendingPodRequests(pod)
+		n.usedCPU += cpu
+		n.usedMemory += memory
+		n.usedPods++
+	}
+
+	// Volumes are best-effort: without access, volume constraints are not checked
+	if list, err := client.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range list.Items {
+			pvc := &list.Items[i]
+			c.pvcs[pvc.Namespace+"/"+pvc.Name] = pvc
+		}
+	}
+	if list, err := client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range list.Items {
+			c.pvs[list.Items[i].Name] = &list.Items[i]
+		}
+	}
+	if list, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range list.Items {
+			sc := &list.Items[i]
+			if sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+				c.lateBinding[sc.Name] = true
+			}
+			if sc.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+				c.defaultClass = sc.Name
+			}
+		}
+	}
+	return c
+}
+
+// check runs the scheduler's filters for pod on one node.
+func (c *pendingCluster) check(pod *corev1.Pod, n *pendingNode, cpu, memory float64) nodeCheck {
+	chk := nodeCheck{node: n}
+	node := n.node
+
+	if node.Spec.Unschedulable {
+		chk.constraints = append(chk.constraints, "node cordoned")
+	}
+	if n.notReady {
+		chk.constraints = append(chk.constraints, "node not ready")
+	}
+	for i := range node.Spec.Taints {
+		t := &node.Spec.Taints[i]
+		if t.Effect == corev1.TaintEffectPreferNoSchedule || tolerated(pod.Spec.Tolerations, t) {
+			continue
+		}
+		chk.constraints = append(chk.constraints, "untolerated taint "+formatTaint(t))
+	}
+	if !matchesNodeSelector(pod, node) {
+		chk.constraints = append(chk.constraints, "node selector/affinity mismatch")
+	}
+	chk.constraints = append(chk.constraints, c.podAffinityConflicts(pod, node)...)
+	chk.constraints = append(chk.constraints, c.volumeConflicts(pod, node)...)
+
+	if free := n.allocCPU - n.usedCPU; cpu > 0 && cpu > free {
+		chk.resources = append(chk.resources, "Insufficient cpu")
+		chk.shortCPU = cpu - free
+	}
+	if free := n.allocMemory - n.usedMemory; memory > 0 && memory > free {
+		chk.resources = append(chk.resources, "Insufficient memory")
+		chk.shortMemory = memory - free
+	}
+	if n.allocPods > 0 && n.usedPods >= n.allocPods {
+		chk.resources = append(chk.resources, "Too many pods")
+		chk.shortPods = true
+	}
+	return chk
+}
+
+// tolerated reports whether any toleration tolerates the taint.
+func tolerated(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		t := &tolerations[i]
+		if t.Effect != "" && t.Effect != taint.Effect {
+			continue
+		}
+		if t.Key == "" && t.Operator == corev1.TolerationOpExists {
+			return true
+		}
+		if t.Key != taint.Key {
+			continue
+		}
+		if t.Operator == corev1.TolerationOpExists || t.Value == taint.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatTaint(t *corev1.Taint) string {
+	if t.Value == "" {
+		return t.Key + ":" + string(t.Effect)
+	}
+	return t.Key + "=" + t.Value + ":" + string(t.Effect)
+}
+
+// matchesNodeSelector checks the pod's nodeSelector and required node
+// affinity against the node.
+func matchesNodeSelector(pod *corev1.Pod, node *corev1.Node) bool {
+	for k, v := range pod.Spec.NodeSelector {
+		if node.Labels[k] != v {
+			return false
+		}
+	}
+	aff := pod.Spec.Affinity
+	if aff == nil || aff.NodeAffinity == nil || aff.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	return matchNodeSelectorTerms(aff.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, node)
+}
+
+// matchNodeSelectorTerms reports whether the node matches any of the terms
+// (the terms are ORed, their requirements ANDed).
+func matchNodeSelectorTerms(terms []corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	for i := range terms {
+		term := &terms[i]
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		ok := true
+		for _, req := range term.MatchExpressions {
+			value, exists := node.Labels[req.Key]
+			ok = ok && matchNodeRequirement(req, value, exists)
+		}
+		for _, req := range term.MatchFields {
+			ok = ok && req.Key == metav1.ObjectNameField && matchNodeRequirement(req, node.Name, true)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchNodeRequirement(req corev1.NodeSelectorRequirement, value string, exists bool) bool {
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		return exists && slices.Contains(req.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !exists || !slices.Contains(req.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !exists
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !exists || len(req.Values) != 1 {
+			return false
+		}
+		have, err1 := strconv.ParseInt(value, 10, 64)
+		want, err2 := strconv.ParseInt(req.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if req.Operator == corev1.NodeSelectorOpGt {
+			return have > want
+		}
+		return have < want
+	default:
+		return false
+	}
+}
+
+// podAffinityConflicts checks the pod's required pod affinity and
+// anti-affinity terms on the node's topology domain.
+func (c *pendingCluster) podAffinityConflicts(pod *corev1.Pod, node *corev1.Node) []string {
+	aff := pod.Spec.Affinity
+	if aff == nil {
+		return nil
+	}
+	var out []string
+	if aff.PodAntiAffinity != nil {
+		for i := range aff.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			term := &aff.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i]
+			if domain, ok := node.Labels[term.TopologyKey]; ok && c.domainHasMatch(pod, term, domain) {
+				out = append(out, "pod anti-affinity conflict ("+term.TopologyKey+")")
+			}
+		}
+	}
+	if aff.PodAffinity != nil {
+		for i := range aff.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			term := &aff.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i]
+			domain, ok := node.Labels[term.TopologyKey]
+			if ok && c.domainHasMatch(pod, term, domain) {
+				continue
+			}
+			// The first pod of a self-affine group may go anywhere
+			if !c.anyMatch(pod, term) && termSelects(pod, term, pod) {
+				continue
+			}
+			out = append(out, "pod affinity unmet ("+term.TopologyKey+")")
+		}
+	}
+	return out
+}
+
+// domainHasMatch reports whether a pod selected by term runs in the
+// topology domain.
+func (c *pendingCluster) domainHasMatch(pod *corev1.Pod, term *corev1.PodAffinityTerm, domain string) bool {
+	for _, other := range c.scheduled {
+		n := c.nodeByName[other.Spec.NodeName]
+		if n == nil || n.node.Labels[term.TopologyKey] != domain {
+			continue
+		}
+		if termSelects(pod, term, other) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *pendingCluster) anyMatch(pod *corev1.Pod, term *corev1.PodAffinityTerm) bool {
+	for _, other := range c.scheduled {
+		if termSelects(pod, term, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// termSelects reports whether the affinity term of pod selects other. A
+// namespace selector is treated as selecting all namespaces.
+func termSelects(pod *corev1.Pod, term *corev1.PodAffinityTerm, other *corev1.Pod) bool {
+	if term.NamespaceSelector == nil {
+		namespaces := term.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{pod.Namespace}
+		}
+		if !slices.Contains(namespaces, other.Namespace) {
+			return false
+		}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil || term.LabelSelector == nil {
+		return false
+	}
+	return selector.Matches(labels.Set(other.Labels))
+}
+
+// volumeConflicts checks the pod's claims: unbound claims that bind
+// immediately, and bound volumes whose node affinity excludes the node
+// (typically a zonal disk in another zone).
+func (c *pendingCluster) volumeConflicts(pod *corev1.Pod, node *corev1.Node) []string {
+	var out []string
+	for i := range pod.Spec.Volumes {
+		src := pod.Spec.Volumes[i].PersistentVolumeClaim
+		if src == nil {
+			continue
+		}
+		pvc := c.pvcs[pod.Namespace+"/"+src.ClaimName]
+		if pvc == nil {
+			if len(c.pvcs) > 0 {
+				out = append(out, "persistentvolumeclaim "+src.ClaimName+" not found")
+			}
+			continue
+		}
+		if pvc.Spec.VolumeName == "" {
+			class := c.defaultClass
+			if pvc.Spec.StorageClassName != nil {
+				class = *pvc.Spec.StorageClassName
+			}
+			if !c.lateBinding[class] {
+				out = append(out, "persistentvolumeclaim "+src.ClaimName+" unbound")
+			}
+			continue
+		}
+		pv := c.pvs[pvc.Spec.VolumeName]
+		if pv == nil || pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+			continue
+		}
+		if !matchNodeSelectorTerms(pv.Spec.NodeAffinity.Required.NodeSelectorTerms, node) {
+			reason := "volume node affinity conflict (" + pv.Name
+			if zone := volumeZone(pv); zone != "" {
+				reason += " in " + zone
+			}
+			out = append(out, reason+")")
+		}
+	}
+	return out
+}
+
+// volumeZone returns the zone a volume's node affinity requires, if any.
+func volumeZone(pv *corev1.PersistentVolume) string {
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, req := range term.MatchExpressions {
+			if strings.HasSuffix(req.Key, "/zone") && req.Operator == corev1.NodeSelectorOpIn && len(req.Values) > 0 {
+				return strings.Join(req.Values, ",")
+			}
+		}
+	}
+	return ""
+}
+
+// pendingReasons counts the nodes rejecting the pod for each reason, most
+// nodes first.
+func pendingReasons(checks []nodeCheck) []PendingReason {
+	counts := make(map[string]int)
+	for i := range checks {
+		for _, r := range checks[i].constraints {
+			counts[r]++
+		}
+		for _, r := range checks[i].resources {
+			counts[r]++
+		}
+	}
+	out := make([]PendingReason, 0, len(counts))
+	for r, n := range counts {
+		out = append(out, PendingReason{Reason: r, Nodes: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Nodes != out[j].Nodes {
+			return out[i].Nodes > out[j].Nodes
+		}
+		return out[i].Reason < out[j].Reason
+	})
+	return out
+}
+
+// pendingFix picks the smallest change that lets the pod schedule.
+//
+//nolint:gocyclo // one branch per fix, smallest first
+func pendingFix(checks []nodeCheck, cpu, memory float64) PendingFix {
+	var eligible []*nodeCheck
+	for i := range checks {
+		if len(checks[i].constraints) == 0 {
+			eligible = append(eligible, &checks[i])
+		}
+	}
+	if len(eligible) == 0 {
+		return constraintsFix(checks)
+	}
+
+	for _, chk := range eligible {
+		if len(chk.resources) == 0 {
+			return PendingFix{
+				Kind:   PendingFixFitsNow,
+				Node:   chk.node.node.Name,
+				Detail: "fits on node " + chk.node.node.Name + " now; the scheduler will retry, or a preemption is in progress",
+			}
+		}
+	}
+
+	// Smallest request cut that fits a node with a free pod slot
+	var best *nodeCheck
+	bestCut := math.Inf(1)
+	for _, chk := range eligible {
+		if chk.shortPods {
+			continue
+		}
+		cut := 0.0
+		if chk.shortCPU > 0 {
+			cut = max(cut, chk.shortCPU/cpu)
+		}
+		if chk.shortMemory > 0 {
+			cut = max(cut, chk.shortMemory/memory)
+		}
+		if cut < bestCut {
+			best, bestCut = chk, cut
+		}
+	}
+	if best != nil && bestCut <= maxPendingRequestReduction {
+		fix := PendingFix{Kind: PendingFixReduceRequests, Node: best.node.node.Name}
+		var parts []string
+		if best.shortCPU > 0 {
+			// Whole millicores; the epsilon absorbs float error in the subtraction
+			fix.CPURequest = math.Floor((cpu-best.shortCPU)*1000+1e-6) / 1000
+			parts = append(parts, fmt.Sprintf("CPU request from %s to %s", units.Cores(cpu), units.Cores(fix.CPURequest)))
+		}
+		if best.shortMemory > 0 {
+			fix.MemoryRequestBytes = math.Floor((memory-best.shortMemory)/(1024*1024)) * 1024 * 1024
+			parts = append(parts, fmt.Sprintf("memory request from %s to %s",
+				units.Memory(memory), units.Memory(fix.MemoryRequestBytes)))
+		}
+		fix.Detail = fmt.Sprintf("reduce %s (-%.0f%%) to fit node %s",
+			strings.Join(parts, " and "), bestCut*100, fix.Node)
+		return fix
+	}
+
+	// One more node of an eligible pool, if an empty one fits the pod
+	pools := make(map[string]int)
+	var poolNames []string
+	for _, chk := range eligible {
+		n := chk.node
+		if n.allocCPU < cpu || n.allocMemory < memory {
+			continue
+		}
+		if pools[n.pool] == 0 {
+			poolNames = append(poolNames, n.pool)
+		}
+		pools[n.pool]++
+	}
+	if len(poolNames) > 0 {
+		sort.Slice(poolNames, func(i, j int) bool {
+			if pools[poolNames[i]] != pools[poolNames[j]] {
+				return pools[poolNames[i]] > pools[poolNames[j]]
+			}
+			return poolNames[i] < poolNames[j]
+		})
+		pool := poolNames[0]
+		return PendingFix{
+			Kind:   PendingFixScaleNodePool,
+			Pool:   pool,
+			Detail: fmt.Sprintf("add a node to pool %s: its nodes fit the pod but are full", pool),
+		}
+	}
+
+	largestCPU, largestMemory := 0.0, 0.0
+	for _, chk := range eligible {
+		largestCPU = max(largestCPU, chk.node.allocCPU)
+		largestMemory = max(largestMemory, chk.node.allocMemory)
+	}
+	return PendingFix{
+		Kind: PendingFixNewNodeGroup,
+		Detail: fmt.Sprintf("no eligible node is large enough even when empty (largest allocatable %s CPU, %s memory;"+
+			" pod requests %s CPU, %s memory): add a node group with larger nodes or reduce requests",
+			units.Cores(largestCPU), units.Memory(largestMemory), units.Cores(cpu), units.Memory(memory)),
+	}
+}
+
+// constraintsFix names the constraint rejecting the most nodes when no node
+// passes the pod's constraints.
+func constraintsFix(checks []nodeCheck) PendingFix {
+	if len(checks) == 0 {
+		return PendingFix{Kind: PendingFixConstraints, Detail: "the cluster has no nodes"}
+	}
+	counts := make(map[string]int)
+	for i := range checks {
+		for _, r := range checks[i].constraints {
+			counts[r]++
+		}
+	}
+	top := ""
+	for r, n := range counts {
+		if top == "" || n > counts[top] || (n == counts[top] && r < top) {
+			top = r
+		}
+	}
+	return PendingFix{
+		Kind: PendingFixConstraints,
+		Detail: fmt.Sprintf("no node passes the pod's constraints; the most common is %q (%d of %d nodes):"+
+			" relax it or add nodes that satisfy it", top, counts[top], len(checks)),
+	}
+}
+
+// pendingPodRequests returns a pod's effective CPU (cores) and memory
+// (bytes) requests: the larger of its containers' sum and its largest init
+// container.
+func pendingPodRequests(pod *corev1.Pod) (cpu, memory float64) {
+	for i := range pod.Spec.Containers {
+		r := pod.Spec.Containers[i].Resources.Requests
+		cpu += r.Cpu().AsApproximateFloat64()
+		memory += float64(r.Memory().Value())
+	}
+	for i := range pod.Spec.InitContainers {
+		r := pod.Spec.InitContainers[i].Resources.Requests
+		cpu = max(cpu, r.Cpu().AsApproximateFloat64())
+		memory = max(memory, float64(r.Memory().Value()))
+	}
+	return cpu, memory
+}
+
+// pendingNodePool returns a node's pool, or its instance type when it has
+// no pool label.
+func pendingNodePool(n *corev1.Node) string {
+	for _, l := range pendingPoolLabels {
+		if v := n.Labels[l]; v != "" {
+			return v
+		}
+	}
+	return "default"
+}
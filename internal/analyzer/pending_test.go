@@ -0,0 +1,172 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func pendingTestNode(name, pool, cpu, memory string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			"karpenter.sh/nodepool":       pool,
+			"topology.kubernetes.io/zone": "zone-a",
+			"kubernetes.io/hostname":      name,
+		}},
+		Spec: corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func requestingPod(ns, name, node, cpu, memory string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"app": name}},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if node == "" {
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+			Message: "0/2 nodes are available", LastTransitionTime: metav1.NewTime(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)),
+		}}
+	}
+	return pod
+}
+
+func analyzePendingWorkload(t *testing.T, pending *corev1.Pod, objects ...any) PendingWorkload {
+	t.Helper()
+	client := fake.NewClientset()
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *corev1.Node:
+			_, err := client.CoreV1().Nodes().Create(context.Background(), o, metav1.CreateOptions{})
+			require.NoError(t, err)
+		case *corev1.Pod:
+			_, err := client.CoreV1().Pods(o.Namespace).Create(context.Background(), o, metav1.CreateOptions{})
+			require.NoError(t, err)
+		}
+	}
+	_, err := client.CoreV1().Pods(pending.Namespace).Create(context.Background(), pending, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	result, err := AnalyzePending(context.Background(), client, PendingConfig{Now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	require.Len(t, result.Workloads, 1)
+	assert.Equal(t, 1, result.Summary[result.Workloads[0].Fix.Kind])
+	return result.Workloads[0]
+}
+
+func TestAnalyzePending_ReduceRequests(t *testing.T) {
+	w := analyzePendingWorkload(t,
+		requestingPod("shop", "api", "", "1", "1Gi"),
+		pendingTestNode("node-1", "general", "4", "8Gi"),
+		pendingTestNode("node-2", "general", "4", "8Gi"),
+		requestingPod("shop", "busy-1", "node-1", "3200m", "1Gi"),
+		requestingPod("shop", "busy-2", "node-2", "3500m", "1Gi"),
+	)
+
+	assert.Equal(t, "2h", w.Age)
+	assert.Equal(t, []PendingReason{{Reason: "Insufficient cpu", Nodes: 2}}, w.Reasons)
+	assert.Equal(t, PendingFixReduceRequests, w.Fix.Kind)
+	assert.Equal(t, "node-1", w.Fix.Node)
+	assert.InDelta(t, 0.8, w.Fix.CPURequest, 0.001)
+	assert.Zero(t, w.Fix.MemoryRequestBytes)
+}
+
+func TestAnalyzePending_ScaleNodePool(t *testing.T) {
+	w := analyzePendingWorkload(t,
+		requestingPod("shop", "api", "", "2", "1Gi"),
+		pendingTestNode("node-1", "general", "4", "8Gi"),
+		requestingPod("shop", "busy-1", "node-1", "3500m", "1Gi"),
+	)
+
+	assert.Equal(t, PendingFixScaleNodePool, w.Fix.Kind)
+	assert.Equal(t, "general", w.Fix.Pool)
+}
+
+func TestAnalyzePending_NewNodeGroup(t *testing.T) {
+	w := analyzePendingWorkload(t,
+		requestingPod("shop", "api", "", "8", "1Gi"),
+		pendingTestNode("node-1", "general", "4", "8Gi"),
+	)
+
+	assert.Equal(t, PendingFixNewNodeGroup, w.Fix.Kind)
+}
+
+func TestAnalyzePending_Constraints(t *testing.T) {
+	pending := requestingPod("shop", "api", "", "100m", "128Mi")
+	pending.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+	w := analyzePendingWorkload(t, pending,
+		pendingTestNode("node-1", "general", "4", "8Gi"),
+		pendingTestNode("gpu-1", "gpu", "4", "8Gi", corev1.Taint{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}),
+	)
+
+	assert.Equal(t, []PendingReason{
+		{Reason: "node selector/affinity mismatch", Nodes: 2},
+		{Reason: "untolerated taint nvidia.com/gpu:NoSchedule", Nodes: 1},
+	}, w.Reasons)
+	assert.Equal(t, PendingFixConstraints, w.Fix.Kind)
+	assert.Contains(t, w.Fix.Detail, `"node selector/affinity mismatch" (2 of 2 nodes)`)
+}
+
+func TestAnalyzePending_PodAntiAffinity(t *testing.T) {
+	pending := requestingPod("shop", "api", "", "100m", "128Mi")
+	pending.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api-replica"}},
+			TopologyKey:   "kubernetes.io/hostname",
+		}},
+	}}
+	replica := requestingPod("shop", "api-replica", "node-1", "100m", "128Mi")
+	w := analyzePendingWorkload(t, pending,
+		pendingTestNode("node-1", "general", "4", "8Gi"),
+		pendingTestNode("node-2", "general", "4", "8Gi"),
+		replica,
+	)
+
+	assert.Equal(t, []PendingReason{{Reason: "pod anti-affinity conflict (kubernetes.io/hostname)", Nodes: 1}}, w.Reasons)
+	assert.Equal(t, PendingFixFitsNow, w.Fix.Kind)
+	assert.Equal(t, "node-2", w.Fix.Node)
+}
+
+func TestTolerated(t *testing.T) {
+	taint := &corev1.Taint{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule}
+	assert.True(t, tolerated([]corev1.Toleration{{Key: "dedicated", Value: "batch"}}, taint))
+	assert.True(t, tolerated([]corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}, taint))
+	assert.True(t, tolerated([]corev1.Toleration{{Operator: corev1.TolerationOpExists}}, taint))
+	assert.False(t, tolerated([]corev1.Toleration{{Key: "dedicated", Value: "web"}}, taint))
+	assert.False(t, tolerated([]corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists,
+		Effect: corev1.TaintEffectNoExecute}}, taint))
+}
+
+func TestMatchNodeSelectorTerms_VolumeZone(t *testing.T) {
+	node := pendingTestNode("node-1", "general", "4", "8Gi")
+	terms := []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-b"}},
+	}}}
+	assert.False(t, matchNodeSelectorTerms(terms, node))
+
+	terms[0].MatchExpressions[0].Values = []string{"zone-a", "zone-b"}
+	assert.True(t, matchNodeSelectorTerms(terms, node))
+}
@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// WorkloadKindOperator selects CRD-managed workloads (CNPG, Strimzi,
+// RabbitMQ, ...) in RequestsSkewConfig.WorkloadKinds.
+const WorkloadKindOperator = "Operator"
+
+// RequestsSkewKinds are the workload kinds requests-skew analyzes, in
+// display order.
+var RequestsSkewKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "CronJob", "Job", WorkloadKindOperator}
+
+// Query cost of a requests-skew run: usage and safety queries per workload,
+// and the Prometheus data check per namespace. Workloads without metrics
+// stop after the usage queries, so the per-workload count is an upper bound.
+const (
+	QueriesPerWorkload  = 14
+	QueriesPerNamespace = 1
+)
+
+// NamespacePlan is a namespace's pod count and workloads per kind, for
+// choosing what to analyze before running the queries.
+type NamespacePlan struct {
+	Namespace string
+	Pods      int
+	Workloads map[string]int // kind (RequestsSkewKinds) -> count
+}
+
+// EstimateQueries returns the Prometheus queries a run over the given
+// namespaces and workloads makes at most.
+func EstimateQueries(namespaces, workloads int) int {
+	return namespaces*QueriesPerNamespace + workloads*QueriesPerWorkload
+}
+
+// kindSelected reports whether the configured kinds include kind; no kinds
+// selects all of them.
+func (a *RequestsSkewAnalyzer) kindSelected(kind string) bool {
+	return len(a.config.WorkloadKinds) == 0 || slices.Contains(a.config.WorkloadKinds, kind)
+}
+
+// PlanNamespaces lists the namespaces the configured filters select with
+// their pod and workload counts, without querying Prometheus. Each kind is
+// listed once for the whole cluster (or the configured namespace).
+func (a *RequestsSkewAnalyzer) PlanNamespaces(ctx context.Context) ([]NamespacePlan, error) {
+	namespaces, err := a.getFilteredNamespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespaces: %w", err)
+	}
+	plans := make(map[string]*NamespacePlan, len(namespaces))
+	for _, ns := range namespaces {
+		plans[ns] = &NamespacePlan{Namespace: ns, Workloads: make(map[string]int)}
+	}
+	add := func(namespace, kind string) {
+		if p, ok := plans[namespace]; ok {
+			p.Workloads[kind]++
+		}
+	}
+
+	scope := a.config.Namespace
+	deployments, err := a.kubeClient.AppsV1().Deployments(scope).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		add(deployments.Items[i].Namespace, "Deployment")
+	}
+	statefulsets, err := a.kubeClient.AppsV1().StatefulSets(scope).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulsets.Items {
+		add(statefulsets.Items[i].Namespace, "StatefulSet")
+	}
+	daemonsets, err := a.kubeClient.AppsV1().DaemonSets(scope).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonsets.Items {
+		add(daemonsets.Items[i].Namespace, "DaemonSet")
+	}
+	cronjobs, err := a.kubeClient.BatchV1().CronJobs(scope).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronjobs.Items {
+		add(cronjobs.Items[i].Namespace, "CronJob")
+	}
+	jobs, err := a.kubeClient.BatchV1().Jobs(scope).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		// Jobs created by a CronJob are analyzed as part of it
+		if owner := metav1.GetControllerOf(&jobs.Items[i]); owner == nil || owner.Kind != "CronJob" {
+			add(jobs.Items[i].Namespace, "Job")
+		}
+	}
+
+	pods, err := a.kubeClient.CoreV1().Pods(scope).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	operated := make(map[string]bool) // "namespace/name" of CRD-managed workloads
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		p, ok := plans[pod.Namespace]
+		if !ok {
+			continue
+		}
+		p.Pods++
+		if len(pod.OwnerReferences) == 0 || standardOwnerKinds[pod.OwnerReferences[0].Kind] {
+			continue
+		}
+		if name, operatorType := metrics.ResolveWorkloadIdentity(pod.Name, pod.Labels); operatorType != "" && !operated[pod.Namespace+"/"+name] {
+			operated[pod.Namespace+"/"+name] = true
+			add(pod.Namespace, WorkloadKindOperator)
+		}
+	}
+
+	result := make([]NamespacePlan, 0, len(plans))
+	for _, p := range plans {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Namespace < result[j].Namespace })
+	return result, nil
+}
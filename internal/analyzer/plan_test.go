@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/synthetic"
+)
+
+// Planning lists each kind once for the whole cluster, however many
+// namespaces there are.
+func TestPlanNamespaces(t *testing.T) {
+	cfg := synthetic.Small
+	c := synthetic.Generate(cfg)
+	client := c.Clientset()
+	a := NewRequestsSkewAnalyzer(client, c.Metrics, &RequestsSkewConfig{Silent: true})
+
+	plans, err := a.PlanNamespaces(context.Background())
+	require.NoError(t, err)
+	require.Len(t, plans, cfg.Namespaces)
+	pods, workloads := 0, 0
+	for i, p := range plans {
+		if i > 0 {
+			assert.Less(t, plans[i-1].Namespace, p.Namespace, "sorted by name")
+		}
+		assert.Equal(t, cfg.DeploymentsPerNamespace, p.Workloads["Deployment"])
+		assert.Equal(t, cfg.StatefulSetsPerNamespace, p.Workloads["StatefulSet"])
+		pods += p.Pods
+		for _, n := range p.Workloads {
+			workloads += n
+		}
+	}
+	assert.Equal(t, c.Pods, pods)
+	assert.Equal(t, c.Workloads, workloads)
+	assert.Len(t, client.Actions(), 7) // namespaces, five kinds, pods
+}
+
+func TestPlanNamespaces_Filtered(t *testing.T) {
+	c := synthetic.Generate(synthetic.Small)
+	client := c.Clientset()
+	plans, err := NewRequestsSkewAnalyzer(client, c.Metrics, &RequestsSkewConfig{Silent: true}).PlanNamespaces(context.Background())
+	require.NoError(t, err)
+	first := plans[0].Namespace
+
+	a := NewRequestsSkewAnalyzer(client, c.Metrics, &RequestsSkewConfig{Silent: true, NamespaceInclude: first})
+	plans, err = a.PlanNamespaces(context.Background())
+	require.NoError(t, err)
+	require.Len(t, plans, 1)
+	assert.Equal(t, first, plans[0].Namespace)
+}
+
+func TestAnalyze_WorkloadKinds(t *testing.T) {
+	cfg := synthetic.Small
+	c := synthetic.Generate(cfg)
+	a := NewRequestsSkewAnalyzer(c.Clientset(), c.Metrics, &RequestsSkewConfig{Silent: true, WorkloadKinds: []string{"StatefulSet"}})
+	result, err := a.Analyze(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Namespaces*cfg.StatefulSetsPerNamespace, result.Summary.TotalWorkloads)
+	for i := range result.Results {
+		assert.Equal(t, "StatefulSet", result.Results[i].Type)
+	}
+}
+
+func TestEstimateQueries(t *testing.T) {
+	assert.Equal(t, 3*QueriesPerNamespace+10*QueriesPerWorkload, EstimateQueries(3, 10))
+	assert.Zero(t, EstimateQueries(0, 0))
+}
@@ -0,0 +1,331 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultPreemptionWindow is how far back preemption events are considered.
+const DefaultPreemptionWindow = 24 * time.Hour
+
+// preemptedReason is the event reason the scheduler records on preemption victims.
+const preemptedReason = "Preempted"
+
+// PriorityRiskConfig holds configuration for the priority/preemption analysis.
+type PriorityRiskConfig struct {
+	Namespace string        // "" = all namespaces
+	Window    time.Duration // preemption event lookback (0 = DefaultPreemptionWindow)
+	Now       time.Time     // zero = time.Now(); set by tests
+}
+
+// PriorityWorkload is a workload whose scheduling priority puts it at risk of
+// preemption or node-pressure eviction.
+type PriorityWorkload struct {
+	Namespace              string `json:"namespace"`
+	Workload               string `json:"workload"`
+	Kind                   string `json:"kind"`
+	PriorityClass          string `json:"priority_class,omitempty"` // "" = cluster default
+	Priority               int32  `json:"priority"`
+	CriticalNamespace      bool   `json:"critical_namespace"`
+	Preemptions            int    `json:"preemptions"`
+	SuggestedPriorityClass string `json:"suggested_priority_class,omitempty"`
+	Reason                 string `json:"reason"`
+}
+
+// PreemptionEvent is a recent scheduler preemption of a pod.
+type PreemptionEvent struct {
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Workload  string    `json:"workload,omitempty"` // best-effort match from the pod name
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// PriorityRiskResult is the outcome of AnalyzePriorityRisk.
+type PriorityRiskResult struct {
+	DefaultPriority    int32              `json:"default_priority"`
+	DefaultClass       string             `json:"default_class,omitempty"` // globalDefault PriorityClass, if any
+	CriticalNamespaces []string           `json:"critical_namespaces"`
+	AtRisk             []PriorityWorkload `json:"at_risk"`
+	Preemptions        []PreemptionEvent  `json:"preemptions"`
+	Window             string             `json:"window"`
+}
+
+// priorityTarget is a workload with its resolved scheduling priority.
+type priorityTarget struct {
+	namespace string
+	name      string
+	kind      string
+	class     string
+	priority  int32
+}
+
+// AnalyzePriorityRisk reports workloads running at the cluster default priority
+// in namespaces that also host higher-priority (critical) services, plus recent
+// preemption events. Default-priority pods are the first preemption victims
+// when a critical pod needs room, so each at-risk workload gets a suggested
+// priorityClassName taken from its critical neighbours where possible.
+//
+//nolint:gocyclo // sequential list → classify → match pipeline
+func AnalyzePriorityRisk(ctx context.Context, client kubernetes.Interface, cfg PriorityRiskConfig) (*PriorityRiskResult, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultPreemptionWindow
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	classes, err := client.SchedulingV1().PriorityClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list priority classes: %w", err)
+	}
+
+	result := &PriorityRiskResult{
+		CriticalNamespaces: []string{},
+		AtRisk:             []PriorityWorkload{},
+		Preemptions:        []PreemptionEvent{},
+		Window:             window.String(),
+	}
+	classValues := make(map[string]int32, len(classes.Items))
+	for i := range classes.Items {
+		pc := &classes.Items[i]
+		classValues[pc.Name] = pc.Value
+		if pc.GlobalDefault {
+			result.DefaultPriority = pc.Value
+			result.DefaultClass = pc.Name
+		}
+	}
+
+	targets, err := listPriorityTargets(ctx, client, cfg.Namespace, classValues, result.DefaultPriority)
+	if err != nil {
+		return nil, err
+	}
+
+	// A namespace is critical when any of its workloads outranks the default.
+	// The suggestion is the lowest non-system class those workloads use.
+	critical := make(map[string]bool)
+	suggestion := make(map[string]string)
+	for _, t := range targets {
+		if t.priority <= result.DefaultPriority {
+			continue
+		}
+		critical[t.namespace] = true
+		if t.class == "" || strings.HasPrefix(t.class, "system-") {
+			continue
+		}
+		if cur, ok := suggestion[t.namespace]; !ok || classValues[t.class] < classValues[cur] {
+			suggestion[t.namespace] = t.class
+		}
+	}
+	for ns := range critical {
+		result.CriticalNamespaces = append(result.CriticalNamespaces, ns)
+	}
+	sort.Strings(result.CriticalNamespaces)
+
+	events, err := listPreemptionEvents(ctx, client, cfg.Namespace, now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	preemptions := make(map[string]int)
+	for i := range events {
+		e := &events[i]
+		e.Workload = matchWorkloadForPod(e.Namespace, e.Pod, targets)
+		if e.Workload != "" {
+			preemptions[e.Namespace+"/"+e.Workload] += int(e.Count)
+		}
+	}
+	result.Preemptions = events
+
+	for _, t := range targets {
+		n := preemptions[t.namespace+"/"+t.name]
+		atDefault := t.priority <= result.DefaultPriority
+		if !(atDefault && critical[t.namespace]) && n == 0 {
+			continue
+		}
+
+		var reasons []string
+		if atDefault && critical[t.namespace] {
+			reasons = append(reasons, "default priority in a namespace hosting higher-priority workloads")
+		}
+		if n > 0 {
+			reasons = append(reasons, fmt.Sprintf("preempted %d time(s) in the last %s", n, window))
+		}
+
+		w := PriorityWorkload{
+			Namespace:         t.namespace,
+			Workload:          t.name,
+			Kind:              t.kind,
+			PriorityClass:     t.class,
+			Priority:          t.priority,
+			CriticalNamespace: critical[t.namespace],
+			Preemptions:       n,
+			Reason:            strings.Join(reasons, "; "),
+		}
+		if atDefault {
+			w.SuggestedPriorityClass = suggestion[t.namespace]
+		}
+		result.AtRisk = append(result.AtRisk, w)
+	}
+
+	return result, nil
+}
+
+// ApplyPriorityNotes appends preemption-risk notes to requests-skew results
+// whose recommendation would lower requests. Lower requests make a
+// default-priority pod cheaper to preempt and, once usage exceeds requests,
+// an earlier node-pressure eviction candidate. Returns the number of notes added.
+func ApplyPriorityNotes(result *RequestsSkewResult, risk *PriorityRiskResult) int {
+	if result == nil || risk == nil || len(risk.AtRisk) == 0 {
+		return 0
+	}
+
+	byKey := make(map[string]*PriorityWorkload, len(risk.AtRisk))
+	for i := range risk.AtRisk {
+		w := &risk.AtRisk[i]
+		byKey[w.Namespace+"/"+w.Workload] = w
+	}
+
+	added := 0
+	for i := range result.Results {
+		r := &result.Results[i]
+		w, ok := byKey[r.Namespace+"/"+r.Workload]
+		if !ok || (r.SkewCPU <= 1 && r.SkewMemory <= 1) {
+			continue
+		}
+
+		note := fmt.Sprintf("preemption risk: %s; lowering requests makes it a likelier preemption/eviction victim", w.Reason)
+		if w.SuggestedPriorityClass != "" {
+			note += fmt.Sprintf(" (consider priorityClassName: %s)", w.SuggestedPriorityClass)
+		}
+		if r.Note == "" {
+			r.Note = note
+		} else {
+			r.Note += "; " + note
+		}
+		added++
+	}
+	return added
+}
+
+func listPriorityTargets(
+	ctx context.Context, client kubernetes.Interface, namespace string, classValues map[string]int32, defaultPriority int32,
+) ([]priorityTarget, error) {
+	resolve := func(ns, name, kind string, spec *corev1.PodSpec) priorityTarget {
+		t := priorityTarget{namespace: ns, name: name, kind: kind, class: spec.PriorityClassName, priority: defaultPriority}
+		if v, ok := classValues[spec.PriorityClassName]; ok {
+			t.priority = v
+		} else if spec.Priority != nil {
+			t.priority = *spec.Priority
+		}
+		return t
+	}
+
+	var targets []priorityTarget
+
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		targets = append(targets, resolve(d.Namespace, d.Name, "Deployment", &d.Spec.Template.Spec))
+	}
+
+	statefulsets, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulsets.Items {
+		s := &statefulsets.Items[i]
+		targets = append(targets, resolve(s.Namespace, s.Name, "StatefulSet", &s.Spec.Template.Spec))
+	}
+
+	daemonsets, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonsets.Items {
+		d := &daemonsets.Items[i]
+		targets = append(targets, resolve(d.Namespace, d.Name, "DaemonSet", &d.Spec.Template.Spec))
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].namespace != targets[j].namespace {
+			return targets[i].namespace < targets[j].namespace
+		}
+		return targets[i].name < targets[j].name
+	})
+	return targets, nil
+}
+
+// listPreemptionEvents returns pod preemption events seen since the cutoff,
+// newest first.
+func listPreemptionEvents(ctx context.Context, client kubernetes.Interface, namespace string, since time.Time) ([]PreemptionEvent, error) {
+	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "reason=" + preemptedReason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	out := []PreemptionEvent{}
+	for i := range events.Items {
+		e := &events.Items[i]
+		// Field selectors are not honoured by every client (e.g. fakes).
+		if e.Reason != preemptedReason || e.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		last := eventLastSeen(e)
+		if last.Before(since) {
+			continue
+		}
+		count := e.Count
+		if count == 0 {
+			count = 1
+		}
+		out = append(out, PreemptionEvent{
+			Namespace: e.InvolvedObject.Namespace,
+			Pod:       e.InvolvedObject.Name,
+			Message:   e.Message,
+			Count:     count,
+			LastSeen:  last,
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].LastSeen.After(out[j].LastSeen) })
+	return out, nil
+}
+
+func eventLastSeen(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// matchWorkloadForPod maps a (possibly deleted) pod to its workload by name
+// prefix: Deployment, StatefulSet and DaemonSet pods are all named
+// "<workload>-<suffix>". The longest matching workload name wins.
+func matchWorkloadForPod(namespace, pod string, targets []priorityTarget) string {
+	best := ""
+	for _, t := range targets {
+		if t.namespace == namespace && strings.HasPrefix(pod, t.name+"-") && len(t.name) > len(best) {
+			best = t.name
+		}
+	}
+	return best
+}
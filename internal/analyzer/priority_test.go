@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func priorityDeployment(ns, name, class string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{PriorityClassName: class},
+			},
+		},
+	}
+}
+
+func preemptionEvent(ns, pod string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: pod + ".preempted", Namespace: ns},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: ns, Name: pod},
+		Reason:         "Preempted",
+		Message:        "Preempted by pod payments/api-7d9f-abcde on node node-1",
+		Count:          2,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestAnalyzePriorityRisk(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := fake.NewClientset(
+		&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "business-critical"}, Value: 100000},
+		&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: 10000},
+		priorityDeployment("payments", "api", "business-critical"),
+		priorityDeployment("payments", "ledger", "high"),
+		priorityDeployment("payments", "report-worker", ""),
+		priorityDeployment("batch", "etl", ""),
+		preemptionEvent("batch", "etl-5c6d7-xyz12", now.Add(-time.Hour)),
+		preemptionEvent("batch", "etl-5c6d7-old00", now.Add(-48*time.Hour)),
+	)
+
+	result, err := AnalyzePriorityRisk(context.Background(), client, PriorityRiskConfig{Now: now})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"payments"}, result.CriticalNamespaces)
+	require.Len(t, result.Preemptions, 1)
+	assert.Equal(t, "etl", result.Preemptions[0].Workload)
+
+	require.Len(t, result.AtRisk, 2)
+	etl, worker := result.AtRisk[0], result.AtRisk[1]
+
+	assert.Equal(t, "etl", etl.Workload)
+	assert.Equal(t, 2, etl.Preemptions)
+	assert.False(t, etl.CriticalNamespace)
+	assert.Empty(t, etl.SuggestedPriorityClass)
+
+	assert.Equal(t, "report-worker", worker.Workload)
+	assert.True(t, worker.CriticalNamespace)
+	assert.Equal(t, "high", worker.SuggestedPriorityClass) // lowest class used by critical neighbours
+}
+
+func TestAnalyzePriorityRisk_GlobalDefault(t *testing.T) {
+	client := fake.NewClientset(
+		&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, Value: 1000, GlobalDefault: true},
+		priorityDeployment("shop", "web", "standard"),
+		priorityDeployment("shop", "cart", ""),
+	)
+
+	result, err := AnalyzePriorityRisk(context.Background(), client, PriorityRiskConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1000), result.DefaultPriority)
+	assert.Equal(t, "standard", result.DefaultClass)
+	assert.Empty(t, result.CriticalNamespaces)
+	assert.Empty(t, result.AtRisk)
+}
+
+func TestApplyPriorityNotes(t *testing.T) {
+	risk := &PriorityRiskResult{AtRisk: []PriorityWorkload{{
+		Namespace:              "payments",
+		Workload:               "report-worker",
+		Reason:                 "default priority in a namespace hosting higher-priority workloads",
+		SuggestedPriorityClass: "high",
+	}}}
+	result := &RequestsSkewResult{Results: []WorkloadSkewAnalysis{
+		{Namespace: "payments", Workload: "report-worker", SkewCPU: 4},
+		{Namespace: "payments", Workload: "report-worker-tight", SkewCPU: 4},
+		{Namespace: "payments", Workload: "api", SkewCPU: 0.8},
+	}}
+
+	assert.Equal(t, 1, ApplyPriorityNotes(result, risk))
+	assert.Contains(t, result.Results[0].Note, "priorityClassName: high")
+	assert.Empty(t, result.Results[1].Note)
+	assert.Equal(t, 0, ApplyPriorityNotes(nil, risk))
+}
+
+func TestMatchWorkloadForPod(t *testing.T) {
+	targets := []priorityTarget{
+		{namespace: "a", name: "api"},
+		{namespace: "a", name: "api-gateway"},
+		{namespace: "b", name: "db"},
+	}
+	assert.Equal(t, "api-gateway", matchWorkloadForPod("a", "api-gateway-6f7-abc", targets))
+	assert.Equal(t, "api", matchWorkloadForPod("a", "api-6f7-abc", targets))
+	assert.Equal(t, "", matchWorkloadForPod("a", "db-0", targets))
+}
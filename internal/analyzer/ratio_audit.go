@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/policy"
+)
+
+// Ratio audit rule names, matching the policy file keys.
+const (
+	RatioRuleMaxCPU    = "max_cpu_limit_ratio"
+	RatioRuleMaxMemory = "max_memory_limit_ratio"
+	RatioRuleForbidCPU = "forbid_cpu_limits"
+)
+
+// RatioViolation is a container whose limit/request ratio breaks the policy mandate.
+type RatioViolation struct {
+	Namespace string  `json:"namespace"`
+	Workload  string  `json:"workload"`
+	Kind      string  `json:"kind"`
+	Container string  `json:"container"`
+	Resource  string  `json:"resource"` // cpu|memory
+	Rule      string  `json:"rule"`
+	Request   string  `json:"request,omitempty"`
+	Limit     string  `json:"limit"`
+	Ratio     float64 `json:"ratio,omitempty"`
+	MaxRatio  float64 `json:"max_ratio,omitempty"`
+	Message   string  `json:"message"`
+}
+
+// Recommendation returns a remediation that satisfies the mandated ratio.
+func (v *RatioViolation) Recommendation() string {
+	if v.Rule == RatioRuleForbidCPU {
+		return fmt.Sprintf("remove the CPU limit from container %q", v.Container)
+	}
+	return fmt.Sprintf("set %s limit <= %.2gx request (%s) or raise the request", v.Resource, v.MaxRatio, v.Request)
+}
+
+// CheckContainerRatios evaluates one container against the ratio policy.
+// Containers without a limit never violate a ratio rule; a limit without a
+// request is skipped because Kubernetes defaults the request to the limit.
+func CheckContainerRatios(cfg policy.RatioConfig, c *corev1.Container) []RatioViolation {
+	var out []RatioViolation
+
+	cpuLimit, hasCPULimit := c.Resources.Limits[corev1.ResourceCPU]
+	if cfg.ForbidCPULimits && hasCPULimit && !cpuLimit.IsZero() {
+		out = append(out, RatioViolation{
+			Container: c.Name,
+			Resource:  "cpu",
+			Rule:      RatioRuleForbidCPU,
+			Limit:     cpuLimit.String(),
+			Message:   fmt.Sprintf("container %q sets a CPU limit (%s); policy forbids CPU limits", c.Name, cpuLimit.String()),
+		})
+	}
+
+	checks := []struct {
+		resource corev1.ResourceName
+		maxRatio float64
+		rule     string
+	}{
+		{corev1.ResourceCPU, cfg.MaxCPULimitRatio, RatioRuleMaxCPU},
+		{corev1.ResourceMemory, cfg.MaxMemoryLimitRatio, RatioRuleMaxMemory},
+	}
+	for _, chk := range checks {
+		if chk.maxRatio <= 0 {
+			continue
+		}
+		limit, hasLimit := c.Resources.Limits[chk.resource]
+		request, hasRequest := c.Resources.Requests[chk.resource]
+		if !hasLimit || !hasRequest || request.IsZero() {
+			continue
+		}
+		ratio := limit.AsApproximateFloat64() / request.AsApproximateFloat64()
+		if ratio <= chk.maxRatio {
+			continue
+		}
+		out = append(out, RatioViolation{
+			Container: c.Name,
+			Resource:  string(chk.resource),
+			Rule:      chk.rule,
+			Request:   request.String(),
+			Limit:     limit.String(),
+			Ratio:     ratio,
+			MaxRatio:  chk.maxRatio,
+			Message: fmt.Sprintf("container %q %s limit/request ratio %.1fx (%s/%s) exceeds policy max %.1fx",
+				c.Name, chk.resource, ratio, limit.String(), request.String(), chk.maxRatio),
+		})
+	}
+
+	return out
+}
+
+// CheckPodSpecRatios evaluates all containers of a pod template.
+func CheckPodSpecRatios(cfg policy.RatioConfig, namespace, kind, workload string, spec *corev1.PodSpec) []RatioViolation {
+	var out []RatioViolation
+	for i := range spec.Containers {
+		for _, v := range CheckContainerRatios(cfg, &spec.Containers[i]) {
+			v.Namespace = namespace
+			v.Kind = kind
+			v.Workload = workload
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// AuditResourceRatios checks every Deployment, StatefulSet and DaemonSet in the
+// namespace ("" = all namespaces) against the ratio policy.
+func AuditResourceRatios(ctx context.Context, client kubernetes.Interface, namespace string, cfg policy.RatioConfig) ([]RatioViolation, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	var out []RatioViolation
+
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		out = append(out, CheckPodSpecRatios(cfg, d.Namespace, "Deployment", d.Name, &d.Spec.Template.Spec)...)
+	}
+
+	statefulsets, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulsets.Items {
+		s := &statefulsets.Items[i]
+		out = append(out, CheckPodSpecRatios(cfg, s.Namespace, "StatefulSet", s.Name, &s.Spec.Template.Spec)...)
+	}
+
+	daemonsets, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonsets.Items {
+		d := &daemonsets.Items[i]
+		out = append(out, CheckPodSpecRatios(cfg, d.Namespace, "DaemonSet", d.Name, &d.Spec.Template.Spec)...)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Workload < out[j].Workload
+	})
+	return out, nil
+}
+
+// ApplyRatioNotes appends ratio-policy notes to requests-skew results, using
+// the workload-level request and limit totals. Returns the number of
+// workloads that violate the policy.
+func ApplyRatioNotes(result *RequestsSkewResult, cfg policy.RatioConfig) int {
+	if result == nil || !cfg.Enabled() {
+		return 0
+	}
+
+	violating := 0
+	for i := range result.Results {
+		w := &result.Results[i]
+		var notes []string
+		if cfg.ForbidCPULimits && w.LimitCPU > 0 {
+			notes = append(notes, "policy forbids CPU limits")
+		}
+		if cfg.MaxCPULimitRatio > 0 && w.LimitCPU > 0 && w.RequestedCPU > 0 {
+			if ratio := w.LimitCPU / w.RequestedCPU; ratio > cfg.MaxCPULimitRatio {
+				notes = append(notes, fmt.Sprintf("CPU limit/request %.1fx exceeds policy max %.1fx", ratio, cfg.MaxCPULimitRatio))
+			}
+		}
+		if cfg.MaxMemoryLimitRatio > 0 && w.LimitMemoryGi > 0 && w.RequestedMemoryGi > 0 {
+			if ratio := w.LimitMemoryGi / w.RequestedMemoryGi; ratio > cfg.MaxMemoryLimitRatio {
+				notes = append(notes, fmt.Sprintf("memory limit/request %.1fx exceeds policy max %.1fx", ratio, cfg.MaxMemoryLimitRatio))
+			}
+		}
+		if len(notes) == 0 {
+			continue
+		}
+		violating++
+		ratioNote := "ratio policy: " + strings.Join(notes, "; ")
+		if w.Note == "" {
+			w.Note = ratioNote
+		} else {
+			w.Note += "; " + ratioNote
+		}
+	}
+	return violating
+}
@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/policy"
+)
+
+func ratioContainer(name, cpuReq, cpuLim, memReq, memLim string) corev1.Container {
+	c := corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{},
+			Limits:   corev1.ResourceList{},
+		},
+	}
+	set := func(list corev1.ResourceList, name corev1.ResourceName, v string) {
+		if v != "" {
+			list[name] = resource.MustParse(v)
+		}
+	}
+	set(c.Resources.Requests, corev1.ResourceCPU, cpuReq)
+	set(c.Resources.Limits, corev1.ResourceCPU, cpuLim)
+	set(c.Resources.Requests, corev1.ResourceMemory, memReq)
+	set(c.Resources.Limits, corev1.ResourceMemory, memLim)
+	return c
+}
+
+func TestCheckContainerRatios(t *testing.T) {
+	cfg := policy.RatioConfig{MaxCPULimitRatio: 2, MaxMemoryLimitRatio: 1}
+
+	tests := []struct {
+		name      string
+		container corev1.Container
+		wantRules []string
+	}{
+		{"within ratio", ratioContainer("app", "500m", "1", "256Mi", "256Mi"), nil},
+		{"cpu over ratio", ratioContainer("app", "250m", "1", "256Mi", "256Mi"), []string{RatioRuleMaxCPU}},
+		{"memory over ratio", ratioContainer("app", "500m", "1", "256Mi", "512Mi"), []string{RatioRuleMaxMemory}},
+		{"no limits", ratioContainer("app", "100m", "", "128Mi", ""), nil},
+		{"limit without request", ratioContainer("app", "", "4", "", "1Gi"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, v := range CheckContainerRatios(cfg, &tt.container) {
+				rules = append(rules, v.Rule)
+			}
+			assert.Equal(t, tt.wantRules, rules)
+		})
+	}
+}
+
+func TestCheckContainerRatios_ForbidCPULimits(t *testing.T) {
+	c := ratioContainer("app", "100m", "200m", "", "")
+	violations := CheckContainerRatios(policy.RatioConfig{ForbidCPULimits: true}, &c)
+	require.Len(t, violations, 1)
+	assert.Equal(t, RatioRuleForbidCPU, violations[0].Rule)
+	assert.Contains(t, violations[0].Recommendation(), "remove the CPU limit")
+}
+
+func TestRatioViolation_Message(t *testing.T) {
+	c := ratioContainer("app", "250m", "1", "", "")
+	violations := CheckContainerRatios(policy.RatioConfig{MaxCPULimitRatio: 2}, &c)
+	require.Len(t, violations, 1)
+	v := violations[0]
+	assert.InDelta(t, 4.0, v.Ratio, 1e-9)
+	assert.Contains(t, v.Message, "ratio 4.0x")
+	assert.Equal(t, "set cpu limit <= 2x request (250m) or raise the request", v.Recommendation())
+}
+
+func TestAuditResourceRatios(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{ratioContainer("api", "100m", "1", "128Mi", "128Mi")},
+		}}},
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+		Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{ratioContainer("db", "1", "1", "1Gi", "1Gi")},
+		}}},
+	}
+	client := fake.NewSimpleClientset(dep, sts)
+
+	violations, err := AuditResourceRatios(context.Background(), client, "", policy.RatioConfig{MaxCPULimitRatio: 2})
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "prod", violations[0].Namespace)
+	assert.Equal(t, "Deployment", violations[0].Kind)
+	assert.Equal(t, "api", violations[0].Workload)
+
+	none, err := AuditResourceRatios(context.Background(), client, "", policy.RatioConfig{})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestApplyRatioNotes(t *testing.T) {
+	result := &RequestsSkewResult{Results: []WorkloadSkewAnalysis{
+		{Workload: "ok", RequestedCPU: 1, LimitCPU: 2},
+		{Workload: "wide", RequestedCPU: 0.5, LimitCPU: 4, Note: "existing"},
+		{Workload: "mem", RequestedMemoryGi: 1, LimitMemoryGi: 3},
+	}}
+
+	n := ApplyRatioNotes(result, policy.RatioConfig{MaxCPULimitRatio: 2, MaxMemoryLimitRatio: 2})
+	assert.Equal(t, 2, n)
+	assert.Empty(t, result.Results[0].Note)
+	assert.Equal(t, "existing; ratio policy: CPU limit/request 8.0x exceeds policy max 2.0x", result.Results[1].Note)
+	assert.Contains(t, result.Results[2].Note, "memory limit/request 3.0x")
+}
@@ -0,0 +1,254 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// DefaultProbeTimeout bounds each registry probe request.
+const DefaultProbeTimeout = 5 * time.Second
+
+// dockerHubEndpoint serves the registry API for docker.io references.
+const dockerHubEndpoint = "registry-1.docker.io"
+
+// maxProbeBody caps the response bodies read while probing.
+const maxProbeBody = 4 << 20
+
+// RegistryProbe is what the registry's /v2/ endpoint answered from where
+// the CLI runs. Reachable means it answered at all: 401 is the expected
+// answer of a registry that requires a token.
+type RegistryProbe struct {
+	Endpoint  string `json:"endpoint"`
+	Reachable bool   `json:"reachable"`
+	Status    int    `json:"status,omitempty"`
+	LatencyMS int64  `json:"latency_ms,omitempty"`
+	Auth      string `json:"auth,omitempty"` // bearer, basic, or none
+	Error     string `json:"error,omitempty"`
+}
+
+// ImageProbe is the registry's tag list for a not-found image, fetched
+// anonymously.
+type ImageProbe struct {
+	Status     int    `json:"status,omitempty"`
+	Tags       int    `json:"tags"`
+	TagExists  bool   `json:"tag_exists"`
+	ClosestTag string `json:"closest_tag,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type registryProber struct {
+	client *http.Client
+}
+
+func newRegistryProber(client *http.Client, timeout time.Duration) *registryProber {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	return &registryProber{client: client}
+}
+
+// probe checks the registry's /v2/ endpoint and lists the tags of its
+// not-found images. Results are stored on the images in place.
+func (p *registryProber) probe(ctx context.Context, registry string, images []PulledImage) *RegistryProbe {
+	endpoint := registry
+	if registry == dockerHubRegistry {
+		endpoint = dockerHubEndpoint
+	}
+	result := &RegistryProbe{Endpoint: "https://" + endpoint + "/v2/"}
+
+	start := time.Now()
+	resp, err := p.get(ctx, result.Endpoint, "")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	drainClose(resp)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	result.Status = resp.StatusCode
+	result.Reachable = resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusUnauthorized
+	challenge := resp.Header.Get("WWW-Authenticate")
+	switch {
+	case strings.HasPrefix(strings.ToLower(challenge), "bearer "):
+		result.Auth = "bearer"
+	case strings.HasPrefix(strings.ToLower(challenge), "basic"):
+		result.Auth = "basic"
+	case resp.StatusCode == http.StatusOK:
+		result.Auth = "none"
+	}
+	if !result.Reachable {
+		return result
+	}
+
+	for i := range images {
+		img := &images[i]
+		if img.Class != PullClassNotFound || img.Tag == "" || img.Digest != "" {
+			continue
+		}
+		img.Probe = p.listTags(ctx, endpoint, img, result.Auth, challenge)
+	}
+	return result
+}
+
+// listTags fetches the image's tag list, with an anonymous pull token when
+// the registry issues bearer tokens.
+func (p *registryProber) listTags(ctx context.Context, endpoint string, img *PulledImage, auth, challenge string) *ImageProbe {
+	out := &ImageProbe{}
+	var token string
+	if auth == "bearer" {
+		var err error
+		if token, err = p.anonymousToken(ctx, challenge, img.Repository); err != nil {
+			out.Error = err.Error()
+			return out
+		}
+	}
+
+	resp, err := p.get(ctx, "https://"+endpoint+"/v2/"+img.Repository+"/tags/list", token)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	defer drainClose(resp)
+	out.Status = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return out
+	}
+	var list struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxProbeBody)).Decode(&list); err != nil {
+		out.Error = fmt.Sprintf("failed to decode tag list: %v", err)
+		return out
+	}
+	out.Tags = len(list.Tags)
+	out.TagExists = slices.Contains(list.Tags, img.Tag)
+	if !out.TagExists {
+		out.ClosestTag = closestTag(img.Tag, list.Tags)
+	}
+	return out
+}
+
+// anonymousToken requests a pull token for repository from the realm of a
+// Bearer challenge, without credentials.
+func (p *registryProber) anonymousToken(ctx context.Context, challenge, repository string) (string, error) {
+	params := parseChallenge(challenge)
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	q := realm.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	q.Set("scope", "repository:"+repository+":pull")
+	realm.RawQuery = q.Encode()
+
+	resp, err := p.get(ctx, realm.String(), "")
+	if err != nil {
+		return "", err
+	}
+	defer drainClose(resp)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request returned HTTP %d", resp.StatusCode)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxProbeBody)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+func (p *registryProber) get(ctx context.Context, target, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return p.client.Do(req)
+}
+
+func drainClose(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxProbeBody))
+	_ = resp.Body.Close()
+}
+
+// parseChallenge parses the parameters of a WWW-Authenticate challenge,
+// e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io".
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	_, rest, ok := strings.Cut(challenge, " ")
+	if !ok {
+		return params
+	}
+	for rest != "" {
+		var key, value string
+		key, rest, ok = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return params
+}
+
+// closestTag returns the tag nearest to tag by edit distance, or "" when
+// none is close enough to be a plausible typo.
+func closestTag(tag string, tags []string) string {
+	best, bestDist := "", len(tag)/2+1
+	for _, t := range tags {
+		if d := util.EditDistance(tag, t); d < bestDist || d == bestDist && best != "" && t < best {
+			best, bestDist = t, d
+		}
+	}
+	return best
+}
+
+// applyProbe refines each image's fix with what the probe found.
+func applyProbe(reg *RegistryPulls) {
+	probe := reg.Probe
+	for i := range reg.Images {
+		img := &reg.Images[i]
+		switch {
+		case img.Class == PullClassNetwork && probe.Reachable:
+			img.Fix = fmt.Sprintf("%s answers from here (HTTP %d) but not from the nodes: check node DNS, egress firewall or proxy, "+
+				"and private endpoints", reg.Registry, probe.Status)
+		case img.Class == PullClassNetwork:
+			img.Fix = fmt.Sprintf("%s is unreachable from here too (%s): check the registry host name and that the registry is up",
+				reg.Registry, probe.Error)
+		case img.Probe == nil:
+		case img.Probe.Status == http.StatusNotFound:
+			img.Fix = fmt.Sprintf("repository %s does not exist on %s: check the image name for typos", img.Repository, reg.Registry)
+		case img.Probe.TagExists:
+			img.Fix = fmt.Sprintf("tag %s exists: check it has a manifest for the nodes' OS and architecture", img.Tag)
+		case img.Probe.ClosestTag != "":
+			img.Fix = fmt.Sprintf("tag %s does not exist in %s: did you mean %s?", img.Tag, img.Repository, img.Probe.ClosestTag)
+		case img.Probe.Tags > 0:
+			img.Fix = fmt.Sprintf("tag %s does not exist in %s (%d tags, none close): check the tag was pushed",
+				img.Tag, img.Repository, img.Probe.Tags)
+		}
+	}
+}
@@ -3,22 +3,28 @@ package analyzer
 import (
 	"context"
 	"fmt"
-	"os"
+	"log/slog"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/models"
+	"github.com/ppiankov/kubenow/internal/owners"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // RequestsSkewAnalyzer analyzes resource request vs usage skew
@@ -26,22 +32,43 @@ type RequestsSkewAnalyzer struct {
 	kubeClient      kubernetes.Interface
 	metricsProvider metrics.MetricsProvider
 	config          RequestsSkewConfig
+	templateGroups  map[workloadKey]*templateGroup // set with GroupTemplates
+	namespaceLabels map[string]map[string]string   // set with GroupBy team
+	ooms            *oomIndex                      // OOM kills in pod statuses over the window
+
+	batchMu      sync.Mutex
+	usageBatches map[string]*metrics.WorkloadUsageBatch // namespaces being analyzed with BatchQueries
 }
 
 type namespaceWorkload struct {
 	name         string
 	creationTime time.Time
+	labels       map[string]string
+	annotations  map[string]string
 }
 
-// logProgress prints progress messages unless silent mode is enabled
-func (a *RequestsSkewAnalyzer) logProgress(format string, args ...interface{}) {
+// logProgress logs progress messages unless silent mode is enabled
+func (a *RequestsSkewAnalyzer) logProgress(msg string, args ...any) {
 	if !a.config.Silent {
-		if _, err := fmt.Fprintf(os.Stderr, format, args...); err != nil {
-			return
-		}
+		slog.Info(msg, args...)
+	}
+}
+
+// logStep logs step of total as a progress event unless silent mode is enabled
+func (a *RequestsSkewAnalyzer) logStep(step, total int, msg string, args ...any) {
+	if !a.config.Silent {
+		logging.Progress(slog.Default(), msg, step, total, args...)
 	}
 }
 
+// logWarning logs a warning; silent mode does not hide it
+func (a *RequestsSkewAnalyzer) logWarning(msg string, args ...any) {
+	slog.Warn(msg, args...)
+}
+
+// MaxAnalysisConcurrency caps RequestsSkewConfig.Concurrency.
+const MaxAnalysisConcurrency = 50
+
 // RequestsSkewConfig holds configuration for requests-skew analysis
 type RequestsSkewConfig struct {
 	Window            time.Duration // Time window for analysis (e.g., 30d)
@@ -55,25 +82,43 @@ type RequestsSkewConfig struct {
 	SortBy            string        // Sort by: impact|skew|cpu|memory|name (default: impact)
 	Silent            bool          // Suppress progress output
 	Workers           int           // Max concurrent workload queries (0 = sequential)
+	Concurrency       int           // Namespaces analyzed in parallel (0 = sequential, max MaxAnalysisConcurrency)
+	BatchQueries      bool          // Fetch a namespace's workload usage in grouped-by-pod queries when the provider supports it
+	CostRates         *cost.Rates   // Pricing for monthly waste estimates (nil = no cost estimates)
+	GroupTemplates    bool          // Analyze workloads sharing a template across namespaces once
+	WorkloadKinds     []string      // Kinds to analyze, from RequestsSkewKinds (nil = all)
+	GroupBy           string        // Roll results up by GroupByOptions ("" = no rollup)
+	TeamLabel         string        // Workload or namespace label naming the team (default: team)
+	Owners            *owners.File  // Team owners by namespace or label selector (nil = no owners)
 }
 
 // RequestsSkewResult contains the analysis results
 type RequestsSkewResult struct {
-	Metadata                RequestsSkewMetadata     `json:"metadata"`
-	Summary                 RequestsSkewSummary      `json:"summary"`
-	Results                 []WorkloadSkewAnalysis   `json:"results"`
-	WorkloadsWithoutMetrics []WorkloadWithoutMetrics `json:"workloads_without_metrics,omitempty"`
-	NamespaceMetrics        []NamespaceMetricsStatus `json:"namespace_metrics,omitempty"`
-	NamespaceQuotas         []NamespaceQuotaInfo     `json:"namespace_quotas,omitempty"`
-	SpikeData               map[string]interface{}   `json:"spike_data,omitempty"` // Real-time spike monitoring data (if enabled)
+	Metadata                RequestsSkewMetadata         `json:"metadata"`
+	Summary                 RequestsSkewSummary          `json:"summary"`
+	Results                 []WorkloadSkewAnalysis       `json:"results"`
+	WorkloadsWithoutMetrics []WorkloadWithoutMetrics     `json:"workloads_without_metrics,omitempty"`
+	NamespaceMetrics        []NamespaceMetricsStatus     `json:"namespace_metrics,omitempty"`
+	NamespaceQuotas         []NamespaceQuotaInfo         `json:"namespace_quotas,omitempty"`
+	NamespaceCosts          []cost.NamespaceCostEstimate `json:"namespace_costs,omitempty"` // Monthly waste per namespace (all analyzed workloads)
+	SpikeData               map[string]interface{}       `json:"spike_data,omitempty"`      // Real-time spike monitoring data (if enabled)
+	Queries                 *querylog.Report             `json:"queries,omitempty"`         // Executed PromQL and API requests (--include-queries)
+	Groups                  []WorkloadGroup              `json:"groups,omitempty"`          // Rollup per release, namespace, or team (all analyzed workloads)
 }
 
 // WorkloadWithoutMetrics represents a workload found in K8s but missing from Prometheus
 type WorkloadWithoutMetrics struct {
-	Namespace string `json:"namespace"`
-	Workload  string `json:"workload"`
-	Type      string `json:"type"`      // Deployment, StatefulSet, etc.
-	Diagnosis string `json:"diagnosis"` // Why metrics are missing
+	Namespace   string `json:"namespace"`
+	Workload    string `json:"workload"`
+	Type        string `json:"type"`           // Deployment, StatefulSet, etc.
+	Cause       string `json:"cause"`          // not-scraped|too-new|crash-looping|no-running-pods|unknown
+	Diagnosis   string `json:"diagnosis"`      // Why metrics are missing, in a few words
+	Remediation string `json:"remediation"`    // Next step for the cause
+	Team        string `json:"team,omitempty"` // Owning team from the owners file
+
+	created            time.Time    // zero when unknown
+	unscrapedNamespace bool         // the namespace has no Prometheus container metrics at all
+	template           *workloadKey // representative of the template group, for instances not analyzed
 }
 
 // NamespaceMetricsStatus tracks whether Prometheus has data for a namespace
@@ -130,6 +175,7 @@ type RequestsSkewMetadata struct {
 	GeneratedAt    time.Time `json:"generated_at"`
 	PrometheusURL  string    `json:"prometheus_url"`
 	Cluster        string    `json:"cluster"`
+	GroupBy        string    `json:"group_by,omitempty"`
 }
 
 // RequestsSkewSummary contains summary statistics
@@ -144,7 +190,10 @@ type RequestsSkewSummary struct {
 	TotalWastedLimitCPU      float64 `json:"total_wasted_limit_cpu"`
 	TotalWastedLimitMemoryGi float64 `json:"total_wasted_limit_memory_gi"`
 
-	// Cost estimation (populated when --cost-cpu or --cost-memory flags are used)
+	// Workloads without metrics per cause (see NoMetricsCause*)
+	WithoutMetricsByCause map[string]int `json:"without_metrics_by_cause,omitempty"`
+
+	// Cost estimation (populated when cost rates are configured)
 	CostEstimate *cost.SummaryCostEstimate `json:"cost_estimate,omitempty"`
 }
 
@@ -175,6 +224,12 @@ type WorkloadSkewAnalysis struct {
 	Runtime           string  `json:"runtime"`
 	Note              string  `json:"note"`
 
+	// Batch workloads (Job, CronJob): usage and requests cover only the time
+	// with Running pods
+	Runs           int     `json:"runs,omitempty"`
+	ActiveTime     string  `json:"active_time,omitempty"`
+	ActiveFraction float64 `json:"active_fraction,omitempty"` // share of the window with Running pods
+
 	// Safety analysis
 	Safety *models.SafetyAnalysis `json:"safety,omitempty"`
 
@@ -182,8 +237,20 @@ type WorkloadSkewAnalysis struct {
 	UsingDefaultRequests bool   `json:"using_default_requests,omitempty"` // True if using LimitRange defaults
 	QuotaContext         string `json:"quota_context,omitempty"`          // E.g., "Namespace has quota: 50% utilized"
 
-	// Cost estimation (populated when --cost-cpu or --cost-memory flags are used)
+	// Cost estimation (populated when cost rates are configured)
 	CostEstimate *cost.WorkloadCostEstimate `json:"cost_estimate,omitempty"`
+
+	// Template grouping: the workload was analyzed on behalf of every
+	// instance of its template (chart or images) across namespaces
+	Template  string   `json:"template,omitempty"`
+	Instances []string `json:"instances,omitempty"` // namespace/name, this workload first
+
+	// Rollup key under --group-by release ("<namespace>/<release>") or team
+	Group string `json:"group,omitempty"`
+	chart string // helm.sh/chart of the release
+
+	// Owning team from the owners file
+	Team string `json:"team,omitempty"`
 }
 
 // NewRequestsSkewAnalyzer creates a new requests-skew analyzer
@@ -202,11 +269,16 @@ func NewRequestsSkewAnalyzer(kubeClient kubernetes.Interface, metricsProvider me
 	if config.MinRuntimeDays == 0 {
 		config.MinRuntimeDays = 7 // Default 7 days
 	}
+	if config.TeamLabel == "" {
+		config.TeamLabel = DefaultTeamLabel
+	}
 
 	return &RequestsSkewAnalyzer{
 		kubeClient:      kubeClient,
 		metricsProvider: metricsProvider,
 		config:          *config,
+		ooms:            newOOMIndex(kubeClient, time.Now().Add(-config.Window)),
+		usageBatches:    make(map[string]*metrics.WorkloadUsageBatch),
 	}
 }
 
@@ -219,82 +291,116 @@ func (a *RequestsSkewAnalyzer) Analyze(ctx context.Context) (*RequestsSkewResult
 			Window:         formatDuration(a.config.Window),
 			MinRuntimeDays: a.config.MinRuntimeDays,
 			GeneratedAt:    time.Now(),
+			GroupBy:        a.config.GroupBy,
 		},
 		Results:                 make([]WorkloadSkewAnalysis, 0),
 		WorkloadsWithoutMetrics: make([]WorkloadWithoutMetrics, 0),
 	}
 
 	// Get all namespaces
-	a.logProgress("[kubenow] Discovering namespaces...\n")
+	a.logProgress("Discovering namespaces...")
 	namespaces, err := a.getFilteredNamespaces(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get namespaces: %w", err)
 	}
-	a.logProgress("[kubenow] Found %d namespaces to analyze\n", len(namespaces))
+	a.logProgress("Found namespaces to analyze", "namespaces", len(namespaces))
 
 	// Fetch quota/limitrange info for namespaces
-	a.logProgress("[kubenow] Fetching ResourceQuotas and LimitRanges...\n")
-	quotaMap := make(map[string]*NamespaceQuotaInfo)
-	for _, ns := range namespaces {
+	a.logProgress("Fetching ResourceQuotas and LimitRanges...")
+	quotaInfos := make([]*NamespaceQuotaInfo, len(namespaces))
+	a.forEachNamespace(namespaces, func(i int, ns string) {
 		quotaInfo, err := a.getNamespaceQuotaInfo(ctx, ns)
 		if err != nil {
-			a.logProgress("[kubenow] Warning: failed to get quota info for namespace %s: %v\n", ns, err)
-			continue
+			a.logWarning("Failed to get quota info", "namespace", ns, "error", err)
+			return
 		}
+		quotaInfos[i] = quotaInfo
+	})
+	quotaMap := make(map[string]*NamespaceQuotaInfo)
+	for i, quotaInfo := range quotaInfos {
 		if quotaInfo != nil {
-			quotaMap[ns] = quotaInfo
+			quotaMap[namespaces[i]] = quotaInfo
 			result.NamespaceQuotas = append(result.NamespaceQuotas, *quotaInfo)
 		}
 	}
 
 	// Check per-namespace Prometheus data availability before analyzing workloads
-	a.logProgress("[kubenow] Checking Prometheus data availability per namespace...\n")
-	nsHasMetrics := make(map[string]bool, len(namespaces))
-	for _, ns := range namespaces {
+	a.logProgress("Checking Prometheus data availability per namespace...")
+	metricsStatus := make([]NamespaceMetricsStatus, len(namespaces))
+	checkFailed := make([]bool, len(namespaces))
+	a.forEachNamespace(namespaces, func(i int, ns string) {
 		hasMetrics, seriesCount, err := a.metricsProvider.HasNamespaceMetrics(ctx, ns)
 		if err != nil {
-			a.logProgress("[kubenow] Warning: failed to check metrics for namespace %s: %v\n", ns, err)
-			nsHasMetrics[ns] = true // assume yes on error, let per-workload check decide
-		} else {
-			nsHasMetrics[ns] = hasMetrics
+			a.logWarning("Failed to check metrics", "namespace", ns, "error", err)
+			checkFailed[i] = true
 		}
-		result.NamespaceMetrics = append(result.NamespaceMetrics, NamespaceMetricsStatus{
+		metricsStatus[i] = NamespaceMetricsStatus{
 			Namespace:   ns,
 			HasMetrics:  hasMetrics,
 			SeriesCount: seriesCount,
-		})
+		}
 		if !hasMetrics {
-			a.logProgress("[kubenow]   %s: no Prometheus metrics (0 container_cpu series)\n", ns)
+			a.logProgress("No Prometheus metrics (0 container_cpu series)", "namespace", ns)
 		}
+	})
+	nsHasMetrics := make(map[string]bool, len(namespaces))
+	for i, status := range metricsStatus {
+		// Assume metrics when the check failed, let per-workload check decide
+		nsHasMetrics[status.Namespace] = status.HasMetrics || checkFailed[i]
 	}
+	result.NamespaceMetrics = append(result.NamespaceMetrics, metricsStatus...)
 
-	// Analyze each namespace
-	for i, ns := range namespaces {
-		a.logProgress("[kubenow] [%d/%d] Analyzing namespace: %s\n", i+1, len(namespaces), ns)
+	if a.config.GroupTemplates {
+		withMetrics := make([]string, 0, len(namespaces))
+		for _, ns := range namespaces {
+			if nsHasMetrics[ns] {
+				withMetrics = append(withMetrics, ns)
+			}
+		}
+		a.logProgress("Grouping workloads by template across namespaces...")
+		a.templateGroups = a.buildTemplateGroups(ctx, withMetrics)
+		if skipped := len(a.templateGroups) - countTemplateGroups(a.templateGroups); skipped > 0 {
+			a.logProgress("Found templates deployed in several namespaces; skipping duplicate workloads",
+				"templates", countTemplateGroups(a.templateGroups), "skipped", skipped)
+		}
+	}
+
+	if a.config.GroupBy == GroupByTeam {
+		a.namespaceLabels = a.loadNamespaceLabels(ctx)
+	}
+
+	// Analyze each namespace, several at once with --analysis-concurrency
+	type namespaceResult struct {
+		workloads []WorkloadSkewAnalysis
+		noMetrics []WorkloadWithoutMetrics
+	}
+	nsResults := make([]namespaceResult, len(namespaces))
+	a.forEachNamespace(namespaces, func(i int, ns string) {
+		a.logStep(i+1, len(namespaces), "Analyzing namespace", "namespace", ns)
 
 		// If namespace has no Prometheus data, skip per-workload queries and
 		// record all workloads as missing metrics with a clear reason
 		if !nsHasMetrics[ns] {
-			noMetrics, err := a.listNamespaceWorkloads(ctx, ns, "no Prometheus container metrics for this namespace")
+			noMetrics, err := a.listNamespaceWorkloads(ctx, ns)
 			if err != nil {
-				a.logProgress("[kubenow] Warning: failed to list workloads in %s: %v\n", ns, err)
-				continue
+				a.logWarning("Failed to list workloads", "namespace", ns, "error", err)
+				return
 			}
-			a.logProgress("[kubenow]   → Skipped %d workloads (namespace has no Prometheus data)\n", len(noMetrics))
-			result.WorkloadsWithoutMetrics = append(result.WorkloadsWithoutMetrics, noMetrics...)
-			continue
+			a.logProgress("Skipped workloads (namespace has no Prometheus data)", "namespace", ns, "workloads", len(noMetrics))
+			nsResults[i].noMetrics = noMetrics
+			return
 		}
 
 		workloads, noMetrics, err := a.analyzeNamespace(ctx, ns)
 		if err != nil {
-			a.logProgress("[kubenow] Warning: failed to analyze namespace %s: %v\n", ns, err)
-			continue
+			a.logWarning("Failed to analyze namespace", "namespace", ns, "error", err)
+			return
 		}
 		if len(workloads) > 0 {
-			a.logProgress("[kubenow]   → Found %d workloads with metrics\n", len(workloads))
+			a.logProgress("Found workloads with metrics", "namespace", ns, "workloads", len(workloads))
 		}
 		if len(noMetrics) > 0 {
-			a.logProgress("[kubenow]   → Found %d workloads WITHOUT metrics\n", len(noMetrics))
+			a.logProgress("Found workloads WITHOUT metrics", "namespace", ns, "workloads", len(noMetrics))
 		}
 
 		// Add quota context to workloads
@@ -303,24 +409,44 @@ func (a *RequestsSkewAnalyzer) Analyze(ctx context.Context) (*RequestsSkewResult
 				a.enrichWorkloadWithQuotaContext(&workloads[i], quotaInfo)
 			}
 		}
+		nsResults[i] = namespaceResult{workloads: workloads, noMetrics: noMetrics}
+	})
+	for _, r := range nsResults {
+		result.Results = append(result.Results, r.workloads...)
+		result.WorkloadsWithoutMetrics = append(result.WorkloadsWithoutMetrics, r.noMetrics...)
+	}
+
+	if a.config.GroupTemplates {
+		a.attachTemplateGroups(result)
+	}
 
-		result.Results = append(result.Results, workloads...)
-		result.WorkloadsWithoutMetrics = append(result.WorkloadsWithoutMetrics, noMetrics...)
+	if a.config.Owners != nil {
+		a.assignNamespaceOwners(result)
 	}
 
 	// Calculate potential quota savings
-	a.logProgress("[kubenow] Calculating potential quota savings...\n")
+	a.logProgress("Calculating potential quota savings...")
 	a.calculateQuotaSavings(result)
 
-	// Diagnose why workloads don't have metrics (sample up to 5)
+	// Classify why workloads don't have metrics
 	if len(result.WorkloadsWithoutMetrics) > 0 {
-		a.logProgress("[kubenow] Diagnosing why workloads lack metrics (sampling up to 5)...\n")
-		a.diagnoseWorkloadsWithoutMetrics(ctx, result)
+		a.logProgress("Classifying why workloads lack metrics...", "workloads", len(result.WorkloadsWithoutMetrics))
+		a.classifyWorkloadsWithoutMetrics(ctx, result)
 	}
 
 	// Calculate summary statistics
-	a.logProgress("[kubenow] Calculating summary statistics...\n")
-	a.calculateSummary(result)
+	a.logProgress("Calculating summary statistics...")
+	calculateSummary(result)
+
+	// Estimate monthly waste before the top-N cut so namespace totals cover every workload
+	if a.config.CostRates != nil {
+		AttachCostEstimates(result, *a.config.CostRates)
+	}
+
+	// Roll up before the top-N cut so groups cover every workload
+	if a.config.GroupBy != "" {
+		result.Groups = rollupWorkloads(result, a.config.GroupBy)
+	}
 
 	// Sort results based on configured option
 	a.sortResults(result)
@@ -333,6 +459,36 @@ func (a *RequestsSkewAnalyzer) Analyze(ctx context.Context) (*RequestsSkewResult
 	return result, nil
 }
 
+// forEachNamespace calls fn for every namespace on up to
+// config.Concurrency goroutines (sequentially when it is 0 or 1). fn stores
+// its result at index i, so results keep the namespace order.
+func (a *RequestsSkewAnalyzer) forEachNamespace(namespaces []string, fn func(i int, ns string)) {
+	workers := min(a.config.Concurrency, MaxAnalysisConcurrency, len(namespaces))
+	if workers <= 1 {
+		for i, ns := range namespaces {
+			fn(i, ns)
+		}
+		return
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i, namespaces[i])
+			}
+		}()
+	}
+	for i := range namespaces {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
 // getFilteredNamespaces retrieves namespaces matching the filter
 func (a *RequestsSkewAnalyzer) getFilteredNamespaces(ctx context.Context) ([]string, error) {
 	// If a specific namespace is provided, use only that one
@@ -509,7 +665,9 @@ func (a *RequestsSkewAnalyzer) calculateQuotaSavings(result *RequestsSkewResult)
 	workloadsByNs := make(map[string][]WorkloadSkewAnalysis)
 	for i := range result.Results {
 		workload := &result.Results[i]
-		workloadsByNs[workload.Namespace] = append(workloadsByNs[workload.Namespace], *workload)
+		for _, ns := range workload.instanceNamespaces() {
+			workloadsByNs[ns] = append(workloadsByNs[ns], *workload)
+		}
 	}
 
 	// Calculate savings for each namespace with quotas
@@ -551,66 +709,9 @@ func (a *RequestsSkewAnalyzer) calculateQuotaSavings(result *RequestsSkewResult)
 	}
 }
 
-// diagnoseWorkloadsWithoutMetrics samples workloads to understand why they lack Prometheus metrics
-func (a *RequestsSkewAnalyzer) diagnoseWorkloadsWithoutMetrics(ctx context.Context, result *RequestsSkewResult) {
-	sampleSize := 5
-	if len(result.WorkloadsWithoutMetrics) < sampleSize {
-		sampleSize = len(result.WorkloadsWithoutMetrics)
-	}
-
-	for i := 0; i < sampleSize; i++ {
-		w := &result.WorkloadsWithoutMetrics[i]
-
-		// Skip if already diagnosed (e.g., namespace-level check)
-		if w.Diagnosis != "" {
-			continue
-		}
-
-		// Get pods for this workload
-		labelSelector := fmt.Sprintf("app=%s", w.Workload)
-		pods, err := a.kubeClient.CoreV1().Pods(w.Namespace).List(ctx, metav1.ListOptions{
-			LabelSelector: labelSelector,
-			Limit:         1,
-		})
-
-		if err != nil || len(pods.Items) == 0 {
-			labelSelector = fmt.Sprintf("app.kubernetes.io/name=%s", w.Workload)
-			pods, err = a.kubeClient.CoreV1().Pods(w.Namespace).List(ctx, metav1.ListOptions{
-				LabelSelector: labelSelector,
-				Limit:         1,
-			})
-		}
-
-		switch {
-		case err != nil:
-			w.Diagnosis = fmt.Sprintf("unable to query pods: %v", err)
-		case len(pods.Items) == 0:
-			w.Diagnosis = "no pods found"
-		default:
-			pod := pods.Items[0]
-			if pod.Status.Phase != "Running" {
-				w.Diagnosis = fmt.Sprintf("pod not running (phase: %s)", pod.Status.Phase)
-			} else {
-				hasAppLabel := false
-				for key := range pod.Labels {
-					if key == "app" || key == "app.kubernetes.io/name" {
-						hasAppLabel = true
-						break
-					}
-				}
-				if !hasAppLabel {
-					w.Diagnosis = "pod missing standard app labels"
-				} else {
-					w.Diagnosis = "pod running but no Prometheus metrics — check ServiceMonitor/PodMonitor"
-				}
-			}
-		}
-	}
-}
-
 // listNamespaceWorkloads lists all workloads in a namespace without querying Prometheus.
 // Used when the namespace has no Prometheus data at all.
-func (a *RequestsSkewAnalyzer) listNamespaceWorkloads(ctx context.Context, namespace, diagnosis string) ([]WorkloadWithoutMetrics, error) {
+func (a *RequestsSkewAnalyzer) listNamespaceWorkloads(ctx context.Context, namespace string) ([]WorkloadWithoutMetrics, error) {
 	var result []WorkloadWithoutMetrics
 
 	deployments, err := a.kubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
@@ -620,7 +721,8 @@ func (a *RequestsSkewAnalyzer) listNamespaceWorkloads(ctx context.Context, names
 	for i := range deployments.Items {
 		d := &deployments.Items[i]
 		result = append(result, WorkloadWithoutMetrics{
-			Namespace: namespace, Workload: d.Name, Type: "Deployment", Diagnosis: diagnosis,
+			Namespace: namespace, Workload: d.Name, Type: "Deployment",
+			created: d.CreationTimestamp.Time, unscrapedNamespace: true,
 		})
 	}
 
@@ -631,7 +733,8 @@ func (a *RequestsSkewAnalyzer) listNamespaceWorkloads(ctx context.Context, names
 	for i := range statefulsets.Items {
 		s := &statefulsets.Items[i]
 		result = append(result, WorkloadWithoutMetrics{
-			Namespace: namespace, Workload: s.Name, Type: "StatefulSet", Diagnosis: diagnosis,
+			Namespace: namespace, Workload: s.Name, Type: "StatefulSet",
+			created: s.CreationTimestamp.Time, unscrapedNamespace: true,
 		})
 	}
 
@@ -642,30 +745,57 @@ func (a *RequestsSkewAnalyzer) listNamespaceWorkloads(ctx context.Context, names
 	for i := range daemonsets.Items {
 		d := &daemonsets.Items[i]
 		result = append(result, WorkloadWithoutMetrics{
-			Namespace: namespace, Workload: d.Name, Type: "DaemonSet", Diagnosis: diagnosis,
+			Namespace: namespace, Workload: d.Name, Type: "DaemonSet",
+			created: d.CreationTimestamp.Time, unscrapedNamespace: true,
 		})
 	}
 
+	for _, kind := range []string{"CronJob", "Job"} {
+		targets, err := a.listWorkloadTargets(ctx, namespace, kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range targets {
+			result = append(result, WorkloadWithoutMetrics{
+				Namespace: namespace, Workload: t.name, Type: kind,
+				created: t.creationTime, unscrapedNamespace: true,
+			})
+		}
+	}
+
 	// Discover CRD-managed workloads
 	knownWorkloads := make(map[string]bool)
 	for _, w := range result {
 		knownWorkloads[w.Workload] = true
 	}
 	crdGroups, err := a.discoverCRDWorkloads(ctx, namespace, knownWorkloads)
-	if err != nil {
-		return result, nil // non-fatal, return what we have
-	}
-	for _, g := range crdGroups {
-		result = append(result, WorkloadWithoutMetrics{
-			Namespace: namespace, Workload: g.workloadName, Type: g.displayType, Diagnosis: diagnosis,
-		})
+	if err == nil && a.kindSelected(WorkloadKindOperator) { // discovery errors are non-fatal
+		for _, g := range crdGroups {
+			result = append(result, WorkloadWithoutMetrics{
+				Namespace: namespace, Workload: g.workloadName, Type: g.displayType,
+				created: g.creationTime, unscrapedNamespace: true,
+			})
+		}
 	}
 
-	return result, nil
+	// Keep the selected kinds; all of them were listed so that CRD
+	// discovery skips every known workload
+	selected := result[:0]
+	for i := range result {
+		if kind := result[i].Type; slices.Contains(RequestsSkewKinds, kind) && !a.kindSelected(kind) {
+			continue
+		}
+		selected = append(selected, result[i])
+	}
+	return selected, nil
 }
 
 // analyzeNamespace analyzes all workloads in a namespace
 func (a *RequestsSkewAnalyzer) analyzeNamespace(ctx context.Context, namespace string) ([]WorkloadSkewAnalysis, []WorkloadWithoutMetrics, error) {
+	if a.loadUsageBatch(ctx, namespace) {
+		defer a.dropUsageBatch(namespace)
+	}
+
 	workloads := make([]WorkloadSkewAnalysis, 0)
 	noMetrics := make([]WorkloadWithoutMetrics, 0)
 
@@ -691,10 +821,25 @@ func (a *RequestsSkewAnalyzer) analyzeNamespace(ctx context.Context, namespace s
 				return a.listWorkloadTargets(ctx, namespace, "DaemonSet")
 			},
 		},
+		{
+			kind: "CronJob",
+			list: func(ctx context.Context, namespace string) ([]namespaceWorkload, error) {
+				return a.listWorkloadTargets(ctx, namespace, "CronJob")
+			},
+		},
+		{
+			kind: "Job",
+			list: func(ctx context.Context, namespace string) ([]namespaceWorkload, error) {
+				return a.listWorkloadTargets(ctx, namespace, "Job")
+			},
+		},
 	}
 
 	for i := range workloadKinds {
 		workloadKind := workloadKinds[i]
+		if !a.kindSelected(workloadKind.kind) {
+			continue
+		}
 		analyzedWorkloads, analyzedNoMetrics, err := a.analyzeWorkloadKind(
 			ctx,
 			namespace,
@@ -717,9 +862,12 @@ func (a *RequestsSkewAnalyzer) analyzeNamespace(ctx context.Context, namespace s
 		knownWorkloads[noMetrics[i].Workload] = true
 	}
 
+	if !a.kindSelected(WorkloadKindOperator) {
+		return workloads, noMetrics, nil
+	}
 	crdGroups, err := a.discoverCRDWorkloads(ctx, namespace, knownWorkloads)
 	if err != nil {
-		a.logProgress("[kubenow]   Warning: CRD discovery failed in %s: %v\n", namespace, err)
+		a.logWarning("CRD discovery failed", "namespace", namespace, "error", err)
 	}
 	for _, g := range crdGroups {
 		analysis, hasMetrics, err := a.analyzeWorkload(ctx, namespace, g.workloadName, g.promqlType, g.creationTime)
@@ -731,6 +879,7 @@ func (a *RequestsSkewAnalyzer) analyzeNamespace(ctx context.Context, namespace s
 				Namespace: namespace,
 				Workload:  g.workloadName,
 				Type:      g.displayType,
+				created:   g.creationTime,
 			})
 		} else if analysis != nil {
 			analysis.Type = g.displayType
@@ -741,6 +890,46 @@ func (a *RequestsSkewAnalyzer) analyzeNamespace(ctx context.Context, namespace s
 	return workloads, noMetrics, nil
 }
 
+// loadUsageBatch fetches the usage data of all workloads in a namespace in
+// grouped queries (BatchQueries), so analyzeWorkload joins them client-side
+// instead of querying each workload. On failure the workloads are queried
+// one by one.
+func (a *RequestsSkewAnalyzer) loadUsageBatch(ctx context.Context, namespace string) bool {
+	provider, ok := a.metricsProvider.(metrics.BatchUsageProvider)
+	if !a.config.BatchQueries || !ok {
+		return false
+	}
+	batch, err := provider.NamespaceWorkloadUsage(ctx, namespace, a.config.Window)
+	if err != nil {
+		a.logWarning("Batched usage queries failed, querying workloads one by one", "namespace", namespace, "error", err)
+		return false
+	}
+	a.batchMu.Lock()
+	defer a.batchMu.Unlock()
+	a.usageBatches[namespace] = batch
+	return true
+}
+
+func (a *RequestsSkewAnalyzer) dropUsageBatch(namespace string) {
+	a.batchMu.Lock()
+	defer a.batchMu.Unlock()
+	delete(a.usageBatches, namespace)
+}
+
+// workloadUsage returns a workload's usage from its namespace's batch when
+// one is loaded and covers the kind, and from the provider otherwise.
+func (a *RequestsSkewAnalyzer) workloadUsage(ctx context.Context, namespace, workloadName, workloadType string) (*metrics.WorkloadUsage, error) {
+	a.batchMu.Lock()
+	batch := a.usageBatches[namespace]
+	a.batchMu.Unlock()
+	if batch != nil {
+		if usage, ok := batch.Usage(workloadName, workloadType); ok {
+			return usage, nil
+		}
+	}
+	return a.metricsProvider.GetWorkloadResourceUsage(ctx, namespace, workloadName, workloadType, a.config.Window)
+}
+
 func shouldIncludeNamespace(name string, excludePatterns, includePatterns []string, namespaceRegex *regexp.Regexp) bool {
 	if namespaceRegex != nil && !namespaceRegex.MatchString(name) {
 		return false
@@ -827,6 +1016,7 @@ func (a *RequestsSkewAnalyzer) analyzeWorkloadKind(
 	if err != nil {
 		return nil, nil, err
 	}
+	targets = a.skipGroupedInstances(namespace, kind, targets)
 
 	if a.config.Workers > 1 && len(targets) > 1 {
 		return a.analyzeWorkloadKindConcurrent(ctx, namespace, kind, targets)
@@ -853,10 +1043,13 @@ func (a *RequestsSkewAnalyzer) analyzeWorkloadKindSequential(
 				Namespace: namespace,
 				Workload:  target.name,
 				Type:      kind,
+				Team:      a.config.Owners.Team(namespace, target.labels),
+				created:   target.creationTime,
 			})
 			continue
 		}
 		if analysis != nil {
+			a.assignGroup(analysis, target)
 			workloads = append(workloads, *analysis)
 		}
 	}
@@ -899,11 +1092,14 @@ func (a *RequestsSkewAnalyzer) analyzeWorkloadKindConcurrent(
 							Namespace: namespace,
 							Workload:  target.name,
 							Type:      kind,
+							Team:      a.config.Owners.Team(namespace, target.labels),
+							created:   target.creationTime,
 						},
 					}
 					continue
 				}
 				if analysis != nil {
+					a.assignGroup(analysis, target)
 					results[idx] = workloadResult{analysis: analysis}
 				}
 			}
@@ -942,8 +1138,7 @@ func (a *RequestsSkewAnalyzer) listWorkloadTargets(
 		}
 		return buildNamespaceWorkloadList(
 			deployments.Items,
-			func(item appsv1.Deployment) string { return item.Name },
-			func(item appsv1.Deployment) time.Time { return item.CreationTimestamp.Time },
+			func(item *appsv1.Deployment) *metav1.ObjectMeta { return &item.ObjectMeta },
 		), nil
 	case "StatefulSet":
 		statefulsets, err := a.kubeClient.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
@@ -952,8 +1147,7 @@ func (a *RequestsSkewAnalyzer) listWorkloadTargets(
 		}
 		return buildNamespaceWorkloadList(
 			statefulsets.Items,
-			func(item appsv1.StatefulSet) string { return item.Name },
-			func(item appsv1.StatefulSet) time.Time { return item.CreationTimestamp.Time },
+			func(item *appsv1.StatefulSet) *metav1.ObjectMeta { return &item.ObjectMeta },
 		), nil
 	case "DaemonSet":
 		daemonsets, err := a.kubeClient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
@@ -962,25 +1156,47 @@ func (a *RequestsSkewAnalyzer) listWorkloadTargets(
 		}
 		return buildNamespaceWorkloadList(
 			daemonsets.Items,
-			func(item appsv1.DaemonSet) string { return item.Name },
-			func(item appsv1.DaemonSet) time.Time { return item.CreationTimestamp.Time },
+			func(item *appsv1.DaemonSet) *metav1.ObjectMeta { return &item.ObjectMeta },
+		), nil
+	case "CronJob":
+		cronjobs, err := a.kubeClient.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+		}
+		return buildNamespaceWorkloadList(
+			cronjobs.Items,
+			func(item *batchv1.CronJob) *metav1.ObjectMeta { return &item.ObjectMeta },
+		), nil
+	case "Job":
+		jobs, err := a.kubeClient.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs: %w", err)
+		}
+		// Jobs created by a CronJob are analyzed as part of it
+		standalone := make([]batchv1.Job, 0, len(jobs.Items))
+		for i := range jobs.Items {
+			if owner := metav1.GetControllerOf(&jobs.Items[i]); owner == nil || owner.Kind != "CronJob" {
+				standalone = append(standalone, jobs.Items[i])
+			}
+		}
+		return buildNamespaceWorkloadList(
+			standalone,
+			func(item *batchv1.Job) *metav1.ObjectMeta { return &item.ObjectMeta },
 		), nil
 	default:
 		return nil, fmt.Errorf("unsupported workload kind: %s", kind)
 	}
 }
 
-func buildNamespaceWorkloadList[T any](
-	items []T,
-	name func(T) string,
-	creationTime func(T) time.Time,
-) []namespaceWorkload {
+func buildNamespaceWorkloadList[T any](items []T, objectMeta func(*T) *metav1.ObjectMeta) []namespaceWorkload {
 	result := make([]namespaceWorkload, 0, len(items))
 	for i := range items {
-		item := items[i]
+		meta := objectMeta(&items[i])
 		result = append(result, namespaceWorkload{
-			name:         name(item),
-			creationTime: creationTime(item),
+			name:         meta.Name,
+			creationTime: meta.CreationTimestamp.Time,
+			labels:       meta.Labels,
+			annotations:  meta.Annotations,
 		})
 	}
 	return result
@@ -992,7 +1208,7 @@ func buildNamespaceWorkloadList[T any](
 // - hasMetrics is false if workload exists but has no Prometheus metrics
 func (a *RequestsSkewAnalyzer) analyzeWorkload(ctx context.Context, namespace, workloadName, workloadType string, creationTime time.Time) (*WorkloadSkewAnalysis, bool, error) {
 	// Get workload metrics
-	usage, err := a.metricsProvider.GetWorkloadResourceUsage(ctx, namespace, workloadName, workloadType, a.config.Window)
+	usage, err := a.workloadUsage(ctx, namespace, workloadName, workloadType)
 	if err != nil {
 		return nil, true, fmt.Errorf("failed to get workload usage: %w", err)
 	}
@@ -1037,14 +1253,27 @@ func (a *RequestsSkewAnalyzer) analyzeWorkload(ctx context.Context, namespace, w
 	// Fetch safety data
 	safety := a.fetchSafetyData(ctx, namespace, workloadName, workloadType, usage)
 
-	// Generate recommendation note
-	note := generateRecommendation(usage.CPURequested, usage.CPUP95, usage.MemoryRequested, usage.MemoryP95, usage.CPULimit, usage.MemoryLimit)
+	// Generate recommendation note; memory reductions are held back when
+	// the workload was OOM-killed anywhere in the window
+	oomKills := 0
+	if safety != nil {
+		oomKills = safety.OOMKills
+	}
+	note := generateRecommendation(usage.CPURequested, usage.CPUP95, usage.MemoryRequested, usage.MemoryP95, usage.CPULimit, usage.MemoryLimit, oomKills)
 
 	// Override note if safety issues detected
 	if safety != nil && safety.Rating != models.SafetyRatingSafe {
 		note = fmt.Sprintf("%s (Safety: %s)", note, safety.Rating)
 	}
 
+	var activeTime string
+	var activeFraction float64
+	if usage.Runs > 0 {
+		activeTime = formatDuration(usage.ActiveTime)
+		activeFraction = min(usage.ActiveTime.Hours()/a.config.Window.Hours(), 1)
+		note = fmt.Sprintf("%s [%d runs, %s running]", note, usage.Runs, activeTime)
+	}
+
 	return &WorkloadSkewAnalysis{
 		Namespace:         namespace,
 		Workload:          workloadName,
@@ -1070,20 +1299,31 @@ func (a *RequestsSkewAnalyzer) analyzeWorkload(ctx context.Context, namespace, w
 		ImpactScore:       impactScore,
 		Runtime:           fmt.Sprintf("%dd", runtimeDays),
 		Note:              note,
+		Runs:              usage.Runs,
+		ActiveTime:        activeTime,
+		ActiveFraction:    activeFraction,
 		Safety:            safety,
 	}, true, nil
 }
 
 // fetchSafetyData retrieves safety-related metrics for a workload
 func (a *RequestsSkewAnalyzer) fetchSafetyData(ctx context.Context, namespace, workloadName, workloadType string, usage *metrics.WorkloadUsage) *models.SafetyAnalysis {
+	// OOM kills still visible in pod statuses count even without Prometheus
+	podOOMKills := a.ooms.count(ctx, namespace, workloadType, workloadName)
+
 	// Type assert to get Prometheus client for safety data
 	promClient, ok := a.metricsProvider.(*metrics.PrometheusClient)
 	if !ok {
 		// Safety data not available for non-Prometheus providers
-		return &models.SafetyAnalysis{
+		safety := &models.SafetyAnalysis{
 			Rating:  models.SafetyRatingUnknown,
 			Reasons: []string{"Safety data unavailable (non-Prometheus provider)"},
 		}
+		if podOOMKills > 0 {
+			safety.OOMKills = podOOMKills
+			safety.DetermineRating(usage.CPUP99, usage.MemoryP99, usage.CPURequested, usage.MemoryRequested)
+		}
+		return safety
 	}
 
 	// Fetch safety data from Prometheus
@@ -1097,6 +1337,7 @@ func (a *RequestsSkewAnalyzer) fetchSafetyData(ctx context.Context, namespace, w
 
 	// Build safety analysis
 	safety := &models.SafetyAnalysis{
+		OOMKills:            max(int(safetyData["oom_kills"]), podOOMKills),
 		Restarts:            int(safetyData["restarts"]),
 		CPUThrottledSeconds: safetyData["cpu_throttled_seconds"],
 		CPUThrottledPercent: safetyData["cpu_throttled_percent"],
@@ -1157,11 +1398,16 @@ func (a *RequestsSkewAnalyzer) detectWorkloadPattern(ctx context.Context, namesp
 }
 
 // calculateSummary calculates summary statistics
-func (a *RequestsSkewAnalyzer) calculateSummary(result *RequestsSkewResult) {
-	result.Summary.TotalWorkloads = len(result.Results)
-	result.Summary.AnalyzedWorkloads = len(result.Results)
+func calculateSummary(result *RequestsSkewResult) {
+	// Grouped results count once per instance of their template
+	instances := 0
+	for i := range result.Results {
+		instances += len(result.Results[i].instanceNamespaces())
+	}
+	result.Summary.TotalWorkloads = instances
+	result.Summary.AnalyzedWorkloads = instances
 
-	if len(result.Results) == 0 {
+	if instances == 0 {
 		return
 	}
 
@@ -1174,37 +1420,46 @@ func (a *RequestsSkewAnalyzer) calculateSummary(result *RequestsSkewResult) {
 
 	for i := range result.Results {
 		w := &result.Results[i]
-		totalCPUSkew += w.SkewCPU
-		totalMemSkew += w.SkewMemory
+		n := float64(len(w.instanceNamespaces()))
+		totalCPUSkew += w.SkewCPU * n
+		totalMemSkew += w.SkewMemory * n
 
 		// Wasted requests = requested - p95
 		if w.RequestedCPU > w.P95UsedCPU {
-			totalWastedCPU += (w.RequestedCPU - w.P95UsedCPU)
+			totalWastedCPU += (w.RequestedCPU - w.P95UsedCPU) * n
 		}
 		if w.RequestedMemoryGi > w.P95UsedMemoryGi {
-			totalWastedMem += (w.RequestedMemoryGi - w.P95UsedMemoryGi)
+			totalWastedMem += (w.RequestedMemoryGi - w.P95UsedMemoryGi) * n
 		}
 
 		// Wasted limits = limit - p95
 		if w.LimitCPU > 0 && w.LimitCPU > w.P95UsedCPU {
-			totalWastedLimitCPU += (w.LimitCPU - w.P95UsedCPU)
+			totalWastedLimitCPU += (w.LimitCPU - w.P95UsedCPU) * n
 		}
 		if w.LimitMemoryGi > 0 && w.LimitMemoryGi > w.P95UsedMemoryGi {
-			totalWastedLimitMem += (w.LimitMemoryGi - w.P95UsedMemoryGi)
+			totalWastedLimitMem += (w.LimitMemoryGi - w.P95UsedMemoryGi) * n
 		}
 	}
 
-	result.Summary.AvgSkewCPU = totalCPUSkew / float64(len(result.Results))
-	result.Summary.AvgSkewMemory = totalMemSkew / float64(len(result.Results))
+	result.Summary.AvgSkewCPU = totalCPUSkew / float64(instances)
+	result.Summary.AvgSkewMemory = totalMemSkew / float64(instances)
 	result.Summary.TotalWastedCPU = totalWastedCPU
 	result.Summary.TotalWastedMemoryGi = totalWastedMem
 	result.Summary.TotalWastedLimitCPU = totalWastedLimitCPU
 	result.Summary.TotalWastedLimitMemoryGi = totalWastedLimitMem
 }
 
+// SortOptions are the orders SortWorkloads accepts.
+var SortOptions = []string{"impact", "skew", "cpu", "memory", "name"}
+
 // sortResults sorts workload results based on configured sort option
 func (a *RequestsSkewAnalyzer) sortResults(result *RequestsSkewResult) {
-	sortBy := a.config.SortBy
+	SortWorkloads(result.Results, a.config.SortBy)
+}
+
+// SortWorkloads sorts workload results by one of SortOptions; "" sorts by
+// impact and unknown options leave the order unchanged.
+func SortWorkloads(results []WorkloadSkewAnalysis, sortBy string) {
 	if sortBy == "" {
 		sortBy = "impact" // Default
 	}
@@ -1212,57 +1467,66 @@ func (a *RequestsSkewAnalyzer) sortResults(result *RequestsSkewResult) {
 	switch sortBy {
 	case "impact":
 		// Sort by impact score (descending - highest impact first)
-		sort.Slice(result.Results, func(i, j int) bool {
-			return result.Results[i].ImpactScore > result.Results[j].ImpactScore
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].ImpactScore > results[j].ImpactScore
 		})
 	case "skew":
 		// Sort by CPU skew ratio (descending - highest skew first)
-		sort.Slice(result.Results, func(i, j int) bool {
-			return result.Results[i].SkewCPU > result.Results[j].SkewCPU
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].SkewCPU > results[j].SkewCPU
 		})
 	case "cpu":
 		// Sort by wasted CPU (descending - most wasted first)
-		sort.Slice(result.Results, func(i, j int) bool {
-			wastedI := result.Results[i].RequestedCPU - result.Results[i].P95UsedCPU
-			wastedJ := result.Results[j].RequestedCPU - result.Results[j].P95UsedCPU
+		sort.Slice(results, func(i, j int) bool {
+			wastedI := results[i].RequestedCPU - results[i].P95UsedCPU
+			wastedJ := results[j].RequestedCPU - results[j].P95UsedCPU
 			return wastedI > wastedJ
 		})
 	case "memory":
 		// Sort by wasted memory (descending - most wasted first)
-		sort.Slice(result.Results, func(i, j int) bool {
-			wastedI := result.Results[i].RequestedMemoryGi - result.Results[i].P95UsedMemoryGi
-			wastedJ := result.Results[j].RequestedMemoryGi - result.Results[j].P95UsedMemoryGi
+		sort.Slice(results, func(i, j int) bool {
+			wastedI := results[i].RequestedMemoryGi - results[i].P95UsedMemoryGi
+			wastedJ := results[j].RequestedMemoryGi - results[j].P95UsedMemoryGi
 			return wastedI > wastedJ
 		})
 	case "name":
 		// Sort alphabetically by namespace/workload (ascending)
-		sort.Slice(result.Results, func(i, j int) bool {
-			nameI := fmt.Sprintf("%s/%s", result.Results[i].Namespace, result.Results[i].Workload)
-			nameJ := fmt.Sprintf("%s/%s", result.Results[j].Namespace, result.Results[j].Workload)
+		sort.Slice(results, func(i, j int) bool {
+			nameI := fmt.Sprintf("%s/%s", results[i].Namespace, results[i].Workload)
+			nameJ := fmt.Sprintf("%s/%s", results[j].Namespace, results[j].Workload)
 			return nameI < nameJ
 		})
 	}
 }
 
-// generateRecommendation generates a recommendation note
-func generateRecommendation(cpuReq, cpuP95, memReq, memP95, cpuLimit, memLimit float64) string {
+// generateRecommendation generates a recommendation note. With OOM kills
+// in the window, memory is never suggested for reduction.
+func generateRecommendation(cpuReq, cpuP95, memReq, memP95, cpuLimit, memLimit float64, oomKills int) string {
 	// Add 50% headroom to p95 for safety
 	recommendedCPU := cpuP95 * 1.5
 	recommendedMem := memP95 * 1.5
 
 	parts := make([]string, 0, 2)
 
-	if cpuReq > recommendedCPU*2 || memReq > recommendedMem*2 {
-		parts = append(parts, fmt.Sprintf("Consider reducing CPU request to %.2f cores and memory to %.2fGi (p95 + 50%% headroom)",
-			recommendedCPU, recommendedMem/(1024*1024*1024)))
+	reduceCPU := cpuReq > recommendedCPU*2
+	reduceMem := memReq > recommendedMem*2
+	switch {
+	case oomKills > 0 && reduceCPU:
+		parts = append(parts, fmt.Sprintf("Consider reducing CPU request to %s cores (p95 + 50%% headroom); memory reduction blocked: %d OOMKill(s) in window",
+			units.Cores(recommendedCPU), oomKills))
+	case oomKills > 0 && reduceMem:
+		parts = append(parts, fmt.Sprintf("Memory reduction blocked: %d OOMKill(s) in window", oomKills))
+	case reduceCPU || reduceMem:
+		parts = append(parts, fmt.Sprintf("Consider reducing CPU request to %s cores and memory to %s (p95 + 50%% headroom)",
+			units.Cores(recommendedCPU), units.MemoryGi(recommendedMem)))
 	}
 
 	// Flag over-provisioned limits (limit > 3x P95)
 	if cpuLimit > 0 && cpuP95 > 0 && cpuLimit > cpuP95*3 {
-		parts = append(parts, fmt.Sprintf("CPU limit %.2f is %.0fx P95 usage", cpuLimit, cpuLimit/cpuP95))
+		parts = append(parts, fmt.Sprintf("CPU limit %s is %.0fx P95 usage", units.Cores(cpuLimit), cpuLimit/cpuP95))
 	}
-	if memLimit > 0 && memP95 > 0 && memLimit > memP95*3 {
-		parts = append(parts, fmt.Sprintf("Memory limit %.2fGi is %.0fx P95 usage", memLimit/(1024*1024*1024), memLimit/memP95))
+	if oomKills == 0 && memLimit > 0 && memP95 > 0 && memLimit > memP95*3 {
+		parts = append(parts, fmt.Sprintf("Memory limit %s is %.0fx P95 usage", units.MemoryGi(memLimit), memLimit/memP95))
 	}
 
 	if len(parts) == 0 {
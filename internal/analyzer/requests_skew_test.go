@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/models"
+)
+
+func TestListWorkloadTargets_Batch(t *testing.T) {
+	isController := true
+	client := fake.NewClientset(
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "etl"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "nightly-29000000", Namespace: "etl",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "nightly", Controller: &isController}},
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "etl"}},
+	)
+	a := NewRequestsSkewAnalyzer(client, metrics.NewMockMetrics(), nil)
+
+	cronjobs, err := a.listWorkloadTargets(context.Background(), "etl", "CronJob")
+	require.NoError(t, err)
+	require.Len(t, cronjobs, 1)
+	assert.Equal(t, "nightly", cronjobs[0].name)
+
+	jobs, err := a.listWorkloadTargets(context.Background(), "etl", "Job")
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "migrate", jobs[0].name)
+}
+
+// oomKilledDeploymentPod is a pod of Deployment "api" (through ReplicaSet
+// "api-7d9f") whose container was OOM-killed at killedAt.
+func oomKilledDeploymentPod(killedAt time.Time) *corev1.Pod {
+	isController := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "api-7d9f-x1", Namespace: "prod",
+			Labels:          map[string]string{"pod-template-hash": "7d9f"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-7d9f", Controller: &isController}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "api",
+			RestartCount: 1,
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "OOMKilled", FinishedAt: metav1.NewTime(killedAt),
+			}},
+		}}},
+	}
+}
+
+func TestAnalyzeWorkload_OOMBlocksMemoryReduction(t *testing.T) {
+	created := time.Now().Add(-60 * 24 * time.Hour)
+
+	// The kill is outside any short sampling session but inside the window.
+	client := fake.NewClientset(oomKilledDeploymentPod(time.Now().Add(-10 * 24 * time.Hour)))
+	a := NewRequestsSkewAnalyzer(client, metrics.NewMockMetrics(), &RequestsSkewConfig{Silent: true})
+
+	analysis, hasMetrics, err := a.analyzeWorkload(context.Background(), "prod", "api", "Deployment", created)
+	require.NoError(t, err)
+	require.True(t, hasMetrics)
+	require.NotNil(t, analysis.Safety)
+	assert.Equal(t, 1, analysis.Safety.OOMKills)
+	assert.Equal(t, models.SafetyRatingUnsafe, analysis.Safety.Rating)
+	assert.Contains(t, analysis.Note, "Memory reduction blocked: 1 OOMKill(s) in window")
+	assert.NotContains(t, analysis.Note, "and memory to")
+	assert.NotContains(t, analysis.Note, "Memory limit")
+
+	// A kill older than the window no longer blocks the reduction.
+	client = fake.NewClientset(oomKilledDeploymentPod(time.Now().Add(-45 * 24 * time.Hour)))
+	a = NewRequestsSkewAnalyzer(client, metrics.NewMockMetrics(), &RequestsSkewConfig{Silent: true})
+	analysis, _, err = a.analyzeWorkload(context.Background(), "prod", "api", "Deployment", created)
+	require.NoError(t, err)
+	assert.Equal(t, 0, analysis.Safety.OOMKills)
+	assert.Contains(t, analysis.Note, "and memory to")
+}
+
+func TestGenerateRecommendation_OOMKills(t *testing.T) {
+	const gi = 1024 * 1024 * 1024
+
+	// CPU and memory both over-provisioned
+	note := generateRecommendation(4, 0.5, 8*gi, 1*gi, 0, 16*gi, 0)
+	assert.Contains(t, note, "memory to 1.50Gi")
+	assert.Contains(t, note, "Memory limit 16.00Gi is 16x P95 usage")
+
+	note = generateRecommendation(4, 0.5, 8*gi, 1*gi, 0, 16*gi, 2)
+	assert.NotContains(t, note, "memory to")
+	assert.Contains(t, note, "Consider reducing CPU request to 0.75 cores")
+	assert.Contains(t, note, "memory reduction blocked: 2 OOMKill(s) in window")
+	assert.NotContains(t, note, "Memory limit", "no memory limit cut after OOM kills")
+
+	// Only memory over-provisioned
+	note = generateRecommendation(1, 0.5, 8*gi, 1*gi, 0, 0, 1)
+	assert.Equal(t, "Memory reduction blocked: 1 OOMKill(s) in window", note)
+}
+
+func TestAnalyze_ParallelNamespacesKeepOrder(t *testing.T) {
+	objects := make([]runtime.Object, 0)
+	for _, ns := range []string{"alpha", "bravo", "charlie", "delta", "echo"} {
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name: "api", Namespace: ns, CreationTimestamp: metav1.NewTime(time.Now().Add(-30 * 24 * time.Hour)),
+			}},
+		)
+	}
+
+	analyze := func(concurrency int) *RequestsSkewResult {
+		a := NewRequestsSkewAnalyzer(fake.NewClientset(objects...), metrics.NewMockMetrics(),
+			&RequestsSkewConfig{Silent: true, Top: 100, SortBy: "name", Concurrency: concurrency})
+		result, err := a.Analyze(context.Background())
+		require.NoError(t, err)
+		return result
+	}
+
+	sequential := analyze(1)
+	parallel := analyze(4)
+	require.Len(t, parallel.NamespaceMetrics, 5)
+	for i, ns := range []string{"alpha", "bravo", "charlie", "delta", "echo"} {
+		assert.Equal(t, ns, parallel.NamespaceMetrics[i].Namespace)
+	}
+	assert.Equal(t, sequential.NamespaceMetrics, parallel.NamespaceMetrics)
+	assert.Equal(t, sequential.Results, parallel.Results)
+	assert.Equal(t, sequential.WorkloadsWithoutMetrics, parallel.WorkloadsWithoutMetrics)
+	assert.Equal(t, 5, len(sequential.Results)+len(sequential.WorkloadsWithoutMetrics))
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/synthetic"
+)
+
+var scaleSizes = []struct {
+	name string
+	cfg  synthetic.Config
+}{
+	{"small", synthetic.Small},
+	{"medium", synthetic.Medium},
+	{"large", synthetic.Large},
+}
+
+// requests-skew lists each resource once per namespace and never per
+// workload: one namespace list, then quotas, limit ranges, five workload
+// kinds, and pods (CRD discovery) per namespace.
+func TestRequestsSkew_APICallBudget(t *testing.T) {
+	for _, tt := range scaleSizes[:2] {
+		t.Run(tt.name, func(t *testing.T) {
+			c := synthetic.Generate(tt.cfg)
+			client := c.Clientset()
+			a := NewRequestsSkewAnalyzer(client, c.Metrics, &RequestsSkewConfig{Silent: true})
+			result, err := a.Analyze(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, c.Workloads, result.Summary.TotalWorkloads)
+			assert.Empty(t, result.WorkloadsWithoutMetrics)
+
+			namespaces := tt.cfg.Namespaces
+			counts := synthetic.CountActions(client)
+			assert.Equal(t, 1, counts["list namespaces"])
+			assert.Equal(t, namespaces, counts["list pods"])
+			assert.Equal(t, namespaces, counts["list deployments"])
+			assert.Len(t, client.Actions(), 1+8*namespaces)
+		})
+	}
+}
+
+func TestAnalyzeNodeSkew_APICallBudget(t *testing.T) {
+	c := synthetic.Generate(synthetic.Medium)
+	client := c.Clientset()
+	result, err := AnalyzeNodeSkew(context.Background(), client, c.Metrics, NodeSkewConfig{Silent: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Nodes, synthetic.Medium.Nodes)
+	assert.Len(t, client.Actions(), 2) // one node list, one pod list
+}
+
+func BenchmarkRequestsSkewAnalyze(b *testing.B) {
+	for _, bb := range scaleSizes {
+		b.Run(bb.name, func(b *testing.B) {
+			c := synthetic.Generate(bb.cfg)
+			client := c.Clientset()
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				a := NewRequestsSkewAnalyzer(client, c.Metrics, &RequestsSkewConfig{Silent: true})
+				if _, err := a.Analyze(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkAnalyzeNodeSkew(b *testing.B) {
+	for _, bb := range scaleSizes {
+		b.Run(bb.name, func(b *testing.B) {
+			c := synthetic.Generate(bb.cfg)
+			client := c.Clientset()
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := AnalyzeNodeSkew(ctx, client, c.Metrics, NodeSkewConfig{Silent: true}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// This file renders schedule-savings plans as CronJob or KEDA manifests.
+
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// scalerName names the ServiceAccount, Role, and RoleBinding the scaling
+	// CronJobs run as.
+	scalerName = "kubenow-scaler"
+	// scalerImage runs `kubectl scale`; its entrypoint is kubectl.
+	scalerImage = "registry.k8s.io/kubectl:v1.35.0"
+	// maxCronJobName leaves room for the 11-character suffix the CronJob
+	// controller appends to Job names.
+	maxCronJobName = 52
+)
+
+var managedByLabels = map[string]string{"app.kubernetes.io/managed-by": "kubenow"}
+
+type manifestMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type manifest struct {
+	APIVersion string       `yaml:"apiVersion"`
+	Kind       string       `yaml:"kind"`
+	Metadata   manifestMeta `yaml:"metadata"`
+	Spec       any          `yaml:"spec,omitempty"`
+	Rules      any          `yaml:"rules,omitempty"`
+	RoleRef    any          `yaml:"roleRef,omitempty"`
+	Subjects   any          `yaml:"subjects,omitempty"`
+}
+
+// manifestWriter joins YAML documents, each with optional comment lines.
+type manifestWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (w *manifestWriter) add(m manifest, comments ...string) {
+	if w.err != nil {
+		return
+	}
+	var doc bytes.Buffer
+	enc := yaml.NewEncoder(&doc)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		w.err = fmt.Errorf("failed to render %s %s: %w", m.Kind, m.Metadata.Name, err)
+		return
+	}
+	w.buf.WriteString("---\n")
+	for _, c := range comments {
+		w.buf.WriteString("# " + c + "\n")
+	}
+	w.buf.Write(doc.Bytes())
+}
+
+// RenderCronJobManifests renders each plan as a pair of CronJobs per schedule
+// that run `kubectl scale` to zero and back to the current replica count,
+// plus the ServiceAccount and Role they need in each namespace.
+func RenderCronJobManifests(result *ScheduleSavingsResult) ([]byte, error) {
+	w := &manifestWriter{}
+	seen := map[string]bool{}
+	for i := range result.Plans {
+		p := &result.Plans[i]
+		if !seen[p.Namespace] {
+			seen[p.Namespace] = true
+			addScalerRBAC(w, p.Namespace)
+		}
+		comments := planComments(p)
+		if p.HasHPA {
+			comments = append(comments, "NOTE: an HPA targets this workload; scaling to 0 requires pausing it (or HPAScaleToZero)")
+		}
+		for j, schedule := range p.ScaleDownCron {
+			w.add(scaleCronJob(p, cronJobName(p.Workload, "sleep", j, len(p.ScaleDownCron)), schedule, 0, result.Timezone), comments...)
+			comments = nil
+		}
+		for j, schedule := range p.ScaleUpCron {
+			w.add(scaleCronJob(p, cronJobName(p.Workload, "wake", j, len(p.ScaleUpCron)), schedule, p.Replicas, result.Timezone))
+		}
+	}
+	return w.buf.Bytes(), w.err
+}
+
+func addScalerRBAC(w *manifestWriter, namespace string) {
+	meta := manifestMeta{Name: scalerName, Namespace: namespace, Labels: managedByLabels}
+	w.add(manifest{APIVersion: "v1", Kind: "ServiceAccount", Metadata: meta})
+	w.add(manifest{
+		APIVersion: "rbac.authorization.k8s.io/v1",
+		Kind:       "Role",
+		Metadata:   meta,
+		Rules: []map[string]any{{
+			"apiGroups": []string{"apps"},
+			"resources": []string{"deployments", "statefulsets", "deployments/scale", "statefulsets/scale"},
+			"verbs":     []string{"get", "patch", "update"},
+		}},
+	})
+	w.add(manifest{
+		APIVersion: "rbac.authorization.k8s.io/v1",
+		Kind:       "RoleBinding",
+		Metadata:   meta,
+		RoleRef:    map[string]string{"apiGroup": "rbac.authorization.k8s.io", "kind": "Role", "name": scalerName},
+		Subjects:   []map[string]string{{"kind": "ServiceAccount", "name": scalerName, "namespace": namespace}},
+	})
+}
+
+func scaleCronJob(p *WorkloadSchedulePlan, name, schedule string, replicas int32, timezone string) manifest {
+	return manifest{
+		APIVersion: "batch/v1",
+		Kind:       "CronJob",
+		Metadata:   manifestMeta{Name: name, Namespace: p.Namespace, Labels: managedByLabels},
+		Spec: map[string]any{
+			"schedule":                   schedule,
+			"timeZone":                   timezone,
+			"concurrencyPolicy":          "Forbid",
+			"successfulJobsHistoryLimit": 1,
+			"failedJobsHistoryLimit":     3,
+			"jobTemplate": map[string]any{"spec": map[string]any{
+				"backoffLimit": 2,
+				"template": map[string]any{"spec": map[string]any{
+					"serviceAccountName": scalerName,
+					"restartPolicy":      "OnFailure",
+					"containers": []map[string]any{{
+						"name":  "scale",
+						"image": scalerImage,
+						"args": []string{
+							"scale", scaleResource(p.Kind) + "/" + p.Workload,
+							fmt.Sprintf("--replicas=%d", replicas),
+						},
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+// cronJobName builds "<workload>-<action>[-<n>]", truncating the workload
+// part to fit the CronJob name limit.
+func cronJobName(workload, action string, index, count int) string {
+	suffix := "-" + action
+	if count > 1 {
+		suffix += fmt.Sprintf("-%d", index+1)
+	}
+	if len(workload)+len(suffix) > maxCronJobName {
+		workload = workload[:maxCronJobName-len(suffix)]
+	}
+	return workload + suffix
+}
+
+// scaleResource returns the kubectl resource name of a workload kind.
+func scaleResource(kind string) string {
+	if kind == "StatefulSet" {
+		return "statefulset"
+	}
+	return "deployment"
+}
+
+// RenderKEDAManifests renders each plan as a KEDA ScaledObject with
+// minReplicaCount 0 and one cron trigger per recurring on-window, so the
+// workload runs at its current replica count only outside its off windows.
+func RenderKEDAManifests(result *ScheduleSavingsResult) ([]byte, error) {
+	w := &manifestWriter{}
+	for i := range result.Plans {
+		p := &result.Plans[i]
+		comments := planComments(p)
+		if p.HasHPA {
+			comments = append(comments, "NOTE: delete the existing HPA first; KEDA manages its own")
+		}
+
+		var triggers []map[string]any
+		for _, on := range onWindowSchedules(p.off) {
+			triggers = append(triggers, map[string]any{
+				"type": "cron",
+				"metadata": map[string]string{
+					"timezone":        result.Timezone,
+					"start":           on.start,
+					"end":             on.end,
+					"desiredReplicas": fmt.Sprintf("%d", p.Replicas),
+				},
+			})
+		}
+		w.add(manifest{
+			APIVersion: "keda.sh/v1alpha1",
+			Kind:       "ScaledObject",
+			Metadata:   manifestMeta{Name: p.Workload, Namespace: p.Namespace, Labels: managedByLabels},
+			Spec: map[string]any{
+				"scaleTargetRef":  map[string]string{"apiVersion": "apps/v1", "kind": p.Kind, "name": p.Workload},
+				"minReplicaCount": 0,
+				"maxReplicaCount": p.Replicas,
+				"triggers":        triggers,
+			},
+		}, comments...)
+	}
+	return w.buf.Bytes(), w.err
+}
+
+func planComments(p *WorkloadSchedulePlan) []string {
+	comments := []string{fmt.Sprintf("%s/%s/%s: idle %dh/week, projected savings $%.2f/month",
+		p.Namespace, p.Kind, p.Workload, p.IdleHoursPerWeek, p.MonthlySavings)}
+	for _, ow := range p.OffWindows {
+		comments = append(comments, fmt.Sprintf("  off %s -> %s (%dh)", ow.Start, ow.End, ow.Hours))
+	}
+	return comments
+}
+
+// cronWindow is a recurring window given by its start and end cron schedules.
+type cronWindow struct {
+	start, end string
+}
+
+// onWindowSchedules groups the hours outside the off mask into windows that
+// share a start hour and length, e.g. 07:00-20:00 on weekdays becomes start
+// "0 7 * * 1-5", end "0 20 * * 1-5".
+func onWindowSchedules(off weekMask) []cronWindow {
+	type shape struct{ hour, length int }
+	groups := map[shape][]weekRun{}
+	for _, r := range maskRuns(invertMask(off)) {
+		key := shape{r.start % 24, r.length}
+		groups[key] = append(groups[key], r)
+	}
+	keys := make([]shape, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].hour != keys[j].hour {
+			return keys[i].hour < keys[j].hour
+		}
+		return keys[i].length < keys[j].length
+	})
+
+	windows := make([]cronWindow, 0, len(keys))
+	for _, k := range keys {
+		var starts, ends []int
+		for _, r := range groups[k] {
+			starts = append(starts, r.start)
+			ends = append(ends, (r.start+r.length)%hoursPerWeek)
+		}
+		windows = append(windows, cronWindow{start: cronSchedules(starts)[0], end: cronSchedules(ends)[0]})
+	}
+	return windows
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+)
+
+// weekdayOfficePlan returns a plan that is off outside 07:00-20:00 Monday to Friday.
+func weekdayOfficePlan() *ScheduleSavingsResult {
+	var off weekMask
+	for h := range off {
+		day, hour := h/24, h%24
+		off[h] = day == 0 || day == 6 || hour < 7 || hour >= 20
+	}
+	plan := buildSchedulePlan(scheduleTarget{namespace: "team-dev", name: "web", kind: "Deployment", replicas: 2, cpu: 1, memGi: 2}, off, cost.DefaultRates())
+	plan.HasHPA = true
+	return &ScheduleSavingsResult{Timezone: "Europe/Berlin", Plans: []WorkloadSchedulePlan{*plan}}
+}
+
+func decodeManifests(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var docs []map[string]any
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		var doc map[string]any
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func TestRenderCronJobManifests(t *testing.T) {
+	data, err := RenderCronJobManifests(weekdayOfficePlan())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# team-dev/Deployment/web: idle")
+	assert.Contains(t, string(data), "HPAScaleToZero")
+
+	docs := decodeManifests(t, data)
+	require.Len(t, docs, 5)
+	kinds := make([]string, 0, len(docs))
+	for _, d := range docs {
+		kinds = append(kinds, d["kind"].(string))
+	}
+	assert.Equal(t, []string{"ServiceAccount", "Role", "RoleBinding", "CronJob", "CronJob"}, kinds)
+
+	sleep := docs[3]
+	assert.Equal(t, "web-sleep", sleep["metadata"].(map[string]any)["name"])
+	spec := sleep["spec"].(map[string]any)
+	assert.Equal(t, "0 20 * * 1-5", spec["schedule"])
+	assert.Equal(t, "Europe/Berlin", spec["timeZone"])
+	assert.Contains(t, string(data), "--replicas=0")
+
+	wake := docs[4]["spec"].(map[string]any)
+	assert.Equal(t, "0 7 * * 1-5", wake["schedule"])
+	assert.Contains(t, string(data), "--replicas=2")
+}
+
+func TestRenderKEDAManifests(t *testing.T) {
+	data, err := RenderKEDAManifests(weekdayOfficePlan())
+	require.NoError(t, err)
+
+	docs := decodeManifests(t, data)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "ScaledObject", docs[0]["kind"])
+
+	spec := docs[0]["spec"].(map[string]any)
+	assert.Equal(t, 0, spec["minReplicaCount"])
+	assert.Equal(t, 2, spec["maxReplicaCount"])
+	triggers := spec["triggers"].([]any)
+	require.Len(t, triggers, 1)
+	meta := triggers[0].(map[string]any)["metadata"].(map[string]any)
+	assert.Equal(t, "0 7 * * 1-5", meta["start"])
+	assert.Equal(t, "0 20 * * 1-5", meta["end"])
+	assert.Equal(t, "2", meta["desiredReplicas"])
+	assert.Equal(t, "Europe/Berlin", meta["timezone"])
+}
+
+func TestOnWindowSchedules_IrregularWeek(t *testing.T) {
+	var off weekMask
+	for h := range off {
+		off[h] = true
+	}
+	for h := 24 + 9; h < 24+17; h++ { // Monday 09:00-17:00
+		off[h] = false
+	}
+	for h := 3*24 + 9; h < 3*24+12; h++ { // Wednesday 09:00-12:00
+		off[h] = false
+	}
+
+	assert.Equal(t, []cronWindow{
+		{start: "0 9 * * 3", end: "0 12 * * 3"},
+		{start: "0 9 * * 1", end: "0 17 * * 1"},
+	}, onWindowSchedules(off))
+}
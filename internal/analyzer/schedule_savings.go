@@ -0,0 +1,424 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+)
+
+// Schedule-savings defaults.
+const (
+	// DefaultNonProdNamespaces selects the namespaces analyzed when neither a
+	// namespace nor include patterns are given.
+	DefaultNonProdNamespaces   = "*dev*,*test*,*staging*,*stage*,*qa*,*sandbox*,*preview*"
+	DefaultIdleCPUCores        = 0.01 // 10m peak CPU across all replicas
+	DefaultIdleRequestFraction = 0.05 // or 5% of requested CPU, whichever is higher
+	DefaultMinIdleHours        = 4
+	DefaultProfileWindow       = 14 * 24 * time.Hour
+
+	hoursPerWeek = 7 * 24
+)
+
+// weekMask marks hours of the week, indexed Sunday 00:00 = 0 to match cron.
+type weekMask [hoursPerWeek]bool
+
+// ScheduleSavingsConfig holds configuration for the idle-hours analysis.
+type ScheduleSavingsConfig struct {
+	Namespace           string         // single namespace ("" = match NamespaceInclude)
+	NamespaceInclude    string         // comma-separated patterns ("" = DefaultNonProdNamespaces)
+	Window              time.Duration  // usage history; at least one week for a full profile
+	IdleCPUCores        float64        // 0 = DefaultIdleCPUCores
+	IdleRequestFraction float64        // 0 = DefaultIdleRequestFraction
+	MinIdleHours        int            // shortest idle stretch worth scheduling (0 = DefaultMinIdleHours)
+	Location            *time.Location // timezone of the profile and cron schedules (nil = UTC)
+	Rates               cost.Rates     // pricing for projected savings
+	Now                 time.Time      // zero = time.Now(); set by tests
+	Silent              bool
+}
+
+// OffWindow is a recurring weekly period in which a workload can be scaled to zero.
+type OffWindow struct {
+	Start string `json:"start"` // e.g. "Fri 20:00"
+	End   string `json:"end"`   // e.g. "Mon 07:00"
+	Hours int    `json:"hours"`
+}
+
+// WorkloadSchedulePlan is the scale-to-zero schedule proposed for one workload.
+type WorkloadSchedulePlan struct {
+	Namespace         string      `json:"namespace"`
+	Workload          string      `json:"workload"`
+	Kind              string      `json:"kind"`
+	Replicas          int32       `json:"replicas"`
+	RequestedCPU      float64     `json:"requested_cpu"`       // cores, all replicas
+	RequestedMemoryGi float64     `json:"requested_memory_gi"` // all replicas
+	IdleHoursPerWeek  int         `json:"idle_hours_per_week"`
+	OffWindows        []OffWindow `json:"off_windows"`
+	ScaleDownCron     []string    `json:"scale_down_cron"`
+	ScaleUpCron       []string    `json:"scale_up_cron"`
+	MonthlySavings    float64     `json:"monthly_savings"`
+	HasHPA            bool        `json:"has_hpa,omitempty"`
+
+	off weekMask
+}
+
+// ScheduleSavingsResult is the outcome of AnalyzeScheduleSavings.
+type ScheduleSavingsResult struct {
+	Window              string                 `json:"window"`
+	Timezone            string                 `json:"timezone"`
+	GeneratedAt         time.Time              `json:"generated_at"`
+	Namespaces          []string               `json:"namespaces"`
+	Rates               cost.Rates             `json:"rates"`
+	Plans               []WorkloadSchedulePlan `json:"plans"`
+	AlwaysIdle          []string               `json:"always_idle,omitempty"` // namespace/kind/name idle in every observed hour
+	NoData              []string               `json:"no_data,omitempty"`     // namespace/kind/name without usage series
+	TotalMonthlySavings float64                `json:"total_monthly_savings"`
+	Queries             *querylog.Report       `json:"queries,omitempty"` // --include-queries
+}
+
+// scheduleTarget is a scalable workload with its total requests.
+type scheduleTarget struct {
+	namespace, name, kind string
+	replicas              int32
+	cpu, memGi            float64
+}
+
+// AnalyzeScheduleSavings builds an hour-of-week CPU profile for every
+// Deployment and StatefulSet in non-production namespaces and proposes
+// scale-to-zero windows for the hours in which the workload was idle in
+// every observed week. Each hour's value is the peak usage seen in it, so a
+// single busy evening keeps that hour scheduled.
+func AnalyzeScheduleSavings(
+	ctx context.Context, client kubernetes.Interface, provider metrics.MetricsProvider, cfg ScheduleSavingsConfig,
+) (*ScheduleSavingsResult, error) {
+	cfg = withScheduleDefaults(cfg)
+
+	namespaces, err := scheduleNamespaces(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScheduleSavingsResult{
+		Window:      formatDuration(cfg.Window),
+		Timezone:    cfg.Location.String(),
+		GeneratedAt: cfg.Now.UTC(),
+		Namespaces:  namespaces,
+		Rates:       cfg.Rates,
+		Plans:       []WorkloadSchedulePlan{},
+	}
+
+	start := cfg.Now.Add(-cfg.Window)
+	for _, ns := range namespaces {
+		targets, err := listScheduleTargets(ctx, client, ns)
+		if err != nil {
+			return nil, err
+		}
+		autoscaled := hpaTargets(ctx, client, ns)
+
+		for _, t := range targets {
+			ref := t.namespace + "/" + t.kind + "/" + t.name
+			if !cfg.Silent {
+				slog.Info("Profiling workload...", "workload", ref)
+			}
+			series, err := provider.GetWorkloadCPUSeries(ctx, t.namespace, t.name, t.kind, start, cfg.Now, time.Hour)
+			if err != nil || len(series) == 0 {
+				result.NoData = append(result.NoData, ref)
+				continue
+			}
+
+			peak, covered := weeklyProfile(series, cfg.Location)
+			threshold := max(cfg.IdleCPUCores, cfg.IdleRequestFraction*t.cpu)
+			off, always := offHours(peak, covered, threshold, cfg.MinIdleHours)
+			if always {
+				result.AlwaysIdle = append(result.AlwaysIdle, ref)
+				continue
+			}
+			plan := buildSchedulePlan(t, off, cfg.Rates)
+			if plan == nil {
+				continue
+			}
+			plan.HasHPA = autoscaled[t.kind+"/"+t.name]
+			result.Plans = append(result.Plans, *plan)
+			result.TotalMonthlySavings += plan.MonthlySavings
+		}
+	}
+
+	sort.SliceStable(result.Plans, func(i, j int) bool {
+		return result.Plans[i].MonthlySavings > result.Plans[j].MonthlySavings
+	})
+	result.TotalMonthlySavings = float64(int64(result.TotalMonthlySavings*100+0.5)) / 100
+	return result, nil
+}
+
+func withScheduleDefaults(cfg ScheduleSavingsConfig) ScheduleSavingsConfig {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultProfileWindow
+	}
+	if cfg.IdleCPUCores <= 0 {
+		cfg.IdleCPUCores = DefaultIdleCPUCores
+	}
+	if cfg.IdleRequestFraction <= 0 {
+		cfg.IdleRequestFraction = DefaultIdleRequestFraction
+	}
+	if cfg.MinIdleHours <= 0 {
+		cfg.MinIdleHours = DefaultMinIdleHours
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	if cfg.Now.IsZero() {
+		cfg.Now = time.Now()
+	}
+	return cfg
+}
+
+// scheduleNamespaces returns the namespaces to analyze, sorted.
+func scheduleNamespaces(ctx context.Context, client kubernetes.Interface, cfg ScheduleSavingsConfig) ([]string, error) {
+	if cfg.Namespace != "" {
+		return []string{cfg.Namespace}, nil
+	}
+	include := cfg.NamespaceInclude
+	if include == "" {
+		include = DefaultNonProdNamespaces
+	}
+	patterns := parseCommaSeparated(include)
+
+	list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	var namespaces []string
+	for i := range list.Items {
+		if name := list.Items[i].Name; matchesAnyPattern(name, patterns) {
+			namespaces = append(namespaces, name)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// listScheduleTargets returns the Deployments and StatefulSets of a namespace
+// that currently run at least one replica.
+func listScheduleTargets(ctx context.Context, client kubernetes.Interface, namespace string) ([]scheduleTarget, error) {
+	deps, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in %s: %w", namespace, err)
+	}
+	sts, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets in %s: %w", namespace, err)
+	}
+
+	var targets []scheduleTarget
+	add := func(name, kind string, replicas *int32, spec *corev1.PodSpec) {
+		n := int32(1)
+		if replicas != nil {
+			n = *replicas
+		}
+		if n == 0 {
+			return
+		}
+		cpu, memGi := podSpecRequests(spec)
+		targets = append(targets, scheduleTarget{
+			namespace: namespace, name: name, kind: kind, replicas: n,
+			cpu: cpu * float64(n), memGi: memGi * float64(n),
+		})
+	}
+	for i := range deps.Items {
+		d := &deps.Items[i]
+		add(d.Name, "Deployment", d.Spec.Replicas, &d.Spec.Template.Spec)
+	}
+	for i := range sts.Items {
+		s := &sts.Items[i]
+		add(s.Name, "StatefulSet", s.Spec.Replicas, &s.Spec.Template.Spec)
+	}
+	return targets, nil
+}
+
+// podSpecRequests sums container CPU (cores) and memory (GiB) requests.
+func podSpecRequests(spec *corev1.PodSpec) (cpu, memGi float64) {
+	for i := range spec.Containers {
+		req := spec.Containers[i].Resources.Requests
+		cpu += req.Cpu().AsApproximateFloat64()
+		memGi += float64(req.Memory().Value()) / (1024 * 1024 * 1024)
+	}
+	return cpu, memGi
+}
+
+// hpaTargets returns "Kind/name" keys of workloads scaled by an HPA.
+func hpaTargets(ctx context.Context, client kubernetes.Interface, namespace string) map[string]bool {
+	targets := map[string]bool{}
+	hpas, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return targets
+	}
+	for i := range hpas.Items {
+		ref := hpas.Items[i].Spec.ScaleTargetRef
+		targets[ref.Kind+"/"+ref.Name] = true
+	}
+	return targets
+}
+
+// weeklyProfile folds a usage series into hours of the week, keeping the peak
+// of each hour. covered marks the hours that had at least one sample.
+func weeklyProfile(series []model.SamplePair, loc *time.Location) (peak [hoursPerWeek]float64, covered weekMask) {
+	for _, s := range series {
+		t := s.Timestamp.Time().In(loc)
+		h := int(t.Weekday())*24 + t.Hour()
+		v := float64(s.Value)
+		if !covered[h] || v > peak[h] {
+			peak[h] = v
+		}
+		covered[h] = true
+	}
+	return peak, covered
+}
+
+// offHours marks the idle hours that belong to an idle stretch of at least
+// minHours (wrapping around the week). Hours without samples count as busy.
+// always reports a workload that was idle in every hour of the week.
+func offHours(peak [hoursPerWeek]float64, covered weekMask, threshold float64, minHours int) (off weekMask, always bool) {
+	var idle weekMask
+	idleCount := 0
+	for h := range idle {
+		idle[h] = covered[h] && peak[h] <= threshold
+		if idle[h] {
+			idleCount++
+		}
+	}
+	if idleCount == hoursPerWeek {
+		return off, true
+	}
+
+	for _, r := range maskRuns(idle) {
+		if r.length >= minHours {
+			for i := 0; i < r.length; i++ {
+				off[(r.start+i)%hoursPerWeek] = true
+			}
+		}
+	}
+	return off, false
+}
+
+// weekRun is a stretch of consecutive hours of the week.
+type weekRun struct {
+	start, length int
+}
+
+// maskRuns returns the runs of set hours, joining a run that wraps from
+// Saturday night into Sunday. The mask must have at least one unset hour.
+func maskRuns(mask weekMask) []weekRun {
+	origin := 0
+	for mask[origin] {
+		origin++
+	}
+	var runs []weekRun
+	for i := 1; i <= hoursPerWeek; i++ {
+		h := (origin + i) % hoursPerWeek
+		switch {
+		case mask[h] && (len(runs) == 0 || !mask[(h+hoursPerWeek-1)%hoursPerWeek]):
+			runs = append(runs, weekRun{start: h, length: 1})
+		case mask[h]:
+			runs[len(runs)-1].length++
+		}
+	}
+	return runs
+}
+
+func invertMask(mask weekMask) weekMask {
+	var inv weekMask
+	for h := range mask {
+		inv[h] = !mask[h]
+	}
+	return inv
+}
+
+func buildSchedulePlan(t scheduleTarget, off weekMask, rates cost.Rates) *WorkloadSchedulePlan {
+	runs := maskRuns(off)
+	if len(runs) == 0 {
+		return nil
+	}
+	plan := &WorkloadSchedulePlan{
+		Namespace:         t.namespace,
+		Workload:          t.name,
+		Kind:              t.kind,
+		Replicas:          t.replicas,
+		RequestedCPU:      t.cpu,
+		RequestedMemoryGi: t.memGi,
+		off:               off,
+	}
+	var downs, ups []int
+	for _, r := range runs {
+		end := (r.start + r.length) % hoursPerWeek
+		plan.IdleHoursPerWeek += r.length
+		plan.OffWindows = append(plan.OffWindows, OffWindow{Start: weekHourLabel(r.start), End: weekHourLabel(end), Hours: r.length})
+		downs = append(downs, r.start)
+		ups = append(ups, end)
+	}
+	plan.ScaleDownCron = cronSchedules(downs)
+	plan.ScaleUpCron = cronSchedules(ups)
+	plan.MonthlySavings = cost.EstimateIdleSavings(t.cpu, t.memGi, float64(plan.IdleHoursPerWeek)/hoursPerWeek, rates)
+	return plan
+}
+
+var weekdayNames = [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+func weekHourLabel(h int) string {
+	return fmt.Sprintf("%s %02d:00", weekdayNames[h/24], h%24)
+}
+
+// cronSchedules groups hours of the week by hour of day into cron schedules,
+// e.g. 20:00 on Monday to Friday becomes "0 20 * * 1-5".
+func cronSchedules(weekHours []int) []string {
+	days := map[int][]int{}
+	for _, h := range weekHours {
+		days[h%24] = append(days[h%24], h/24)
+	}
+	hours := make([]int, 0, len(days))
+	for hour := range days {
+		hours = append(hours, hour)
+	}
+	sort.Ints(hours)
+
+	schedules := make([]string, 0, len(hours))
+	for _, hour := range hours {
+		schedules = append(schedules, fmt.Sprintf("0 %d * * %s", hour, cronDays(days[hour])))
+	}
+	return schedules
+}
+
+// cronDays formats days of the week (0 = Sunday) with ranges, e.g. "1-5" or "0,6".
+func cronDays(days []int) string {
+	sort.Ints(days)
+	if len(days) == 7 {
+		return "*"
+	}
+	var parts []string
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == days[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, fmt.Sprintf("%d-%d", days[i], days[j]))
+		case j > i:
+			parts = append(parts, strconv.Itoa(days[i]), strconv.Itoa(days[j]))
+		default:
+			parts = append(parts, strconv.Itoa(days[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func scheduleDeployment(ns, name string, replicas int32, cpu, mem string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(mem),
+				}},
+			}}}},
+		},
+	}
+}
+
+// officeHoursSeries returns hourly samples that are busy on weekdays from
+// 07:00 to 20:00 and near zero otherwise.
+func officeHoursSeries(start time.Time, hours int) []model.SamplePair {
+	series := make([]model.SamplePair, 0, hours)
+	for i := 0; i < hours; i++ {
+		t := start.Add(time.Duration(i) * time.Hour)
+		v := 0.001
+		if wd := t.Weekday(); wd >= time.Monday && wd <= time.Friday && t.Hour() >= 7 && t.Hour() < 20 {
+			v = 0.5
+		}
+		series = append(series, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: model.SampleValue(v)})
+	}
+	return series
+}
+
+func flatSeries(start time.Time, hours int, v float64) []model.SamplePair {
+	series := make([]model.SamplePair, 0, hours)
+	for i := 0; i < hours; i++ {
+		t := start.Add(time.Duration(i) * time.Hour)
+		series = append(series, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: model.SampleValue(v)})
+	}
+	return series
+}
+
+func TestAnalyzeScheduleSavings(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) // Monday
+	start := now.Add(-DefaultProfileWindow)
+	hours := int(DefaultProfileWindow / time.Hour)
+
+	client := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-dev"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		scheduleDeployment("team-dev", "web", 2, "500m", "1Gi"),
+		scheduleDeployment("team-dev", "forgotten", 1, "250m", "512Mi"),
+		scheduleDeployment("team-dev", "busy", 1, "250m", "512Mi"),
+		scheduleDeployment("team-dev", "unscraped", 1, "250m", "512Mi"),
+		scheduleDeployment("team-dev", "stopped", 0, "250m", "512Mi"),
+		scheduleDeployment("prod", "web", 3, "1", "2Gi"),
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-dev"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+				MaxReplicas:    4,
+			},
+		},
+	)
+	mock := metrics.NewMockMetrics()
+	mock.AddCPUSeries("team-dev", "web", officeHoursSeries(start, hours))
+	mock.AddCPUSeries("team-dev", "forgotten", flatSeries(start, hours, 0))
+	mock.AddCPUSeries("team-dev", "busy", flatSeries(start, hours, 0.2))
+	mock.AddCPUSeries("prod", "web", officeHoursSeries(start, hours))
+
+	rates := cost.DefaultRates()
+	result, err := AnalyzeScheduleSavings(context.Background(), client, mock, ScheduleSavingsConfig{Now: now, Rates: rates, Silent: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"team-dev"}, result.Namespaces)
+	assert.Equal(t, "UTC", result.Timezone)
+	assert.Equal(t, []string{"team-dev/Deployment/forgotten"}, result.AlwaysIdle)
+	assert.Equal(t, []string{"team-dev/Deployment/unscraped"}, result.NoData)
+
+	require.Len(t, result.Plans, 1)
+	plan := result.Plans[0]
+	assert.Equal(t, "web", plan.Workload)
+	assert.Equal(t, int32(2), plan.Replicas)
+	assert.InDelta(t, 1.0, plan.RequestedCPU, 0.001)
+	assert.InDelta(t, 2.0, plan.RequestedMemoryGi, 0.001)
+	assert.True(t, plan.HasHPA)
+	assert.Equal(t, 44+59, plan.IdleHoursPerWeek)
+	assert.Equal(t, []string{"0 20 * * 1-5"}, plan.ScaleDownCron)
+	assert.Equal(t, []string{"0 7 * * 1-5"}, plan.ScaleUpCron)
+	assert.Contains(t, plan.OffWindows, OffWindow{Start: "Fri 20:00", End: "Mon 07:00", Hours: 59})
+	assert.Contains(t, plan.OffWindows, OffWindow{Start: "Mon 20:00", End: "Tue 07:00", Hours: 11})
+
+	expected := cost.EstimateIdleSavings(1, 2, 103.0/168, rates)
+	assert.InDelta(t, expected, plan.MonthlySavings, 0.001)
+	assert.InDelta(t, expected, result.TotalMonthlySavings, 0.001)
+}
+
+func TestAnalyzeScheduleSavings_Timezone(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	start := now.Add(-DefaultProfileWindow)
+	hours := int(DefaultProfileWindow / time.Hour)
+	loc := time.FixedZone("UTC+2", 2*3600)
+
+	client := fake.NewClientset(scheduleDeployment("qa", "api", 1, "500m", "1Gi"))
+	mock := metrics.NewMockMetrics()
+	mock.AddCPUSeries("qa", "api", officeHoursSeries(start, hours)) // office hours in UTC
+
+	result, err := AnalyzeScheduleSavings(context.Background(), client, mock, ScheduleSavingsConfig{
+		Namespace: "qa", Location: loc, Now: now, Rates: cost.DefaultRates(), Silent: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Plans, 1)
+	assert.Equal(t, []string{"0 22 * * 1-5"}, result.Plans[0].ScaleDownCron)
+	assert.Equal(t, []string{"0 9 * * 1-5"}, result.Plans[0].ScaleUpCron)
+}
+
+func TestOffHours_MinIdleHours(t *testing.T) {
+	var peak [hoursPerWeek]float64
+	var covered weekMask
+	for h := range peak {
+		covered[h] = true
+		peak[h] = 1
+	}
+	for h := 2; h < 4; h++ { // 2h dip early on Sunday
+		peak[h] = 0
+	}
+	for h := 100; h < 110; h++ {
+		peak[h] = 0
+	}
+
+	off, always := offHours(peak, covered, 0.01, 4)
+	assert.False(t, always)
+	assert.False(t, off[2])
+	assert.True(t, off[100])
+	assert.True(t, off[109])
+	assert.False(t, off[110])
+}
+
+func TestOffHours_UncoveredHoursAreBusy(t *testing.T) {
+	var peak [hoursPerWeek]float64
+	var covered weekMask
+	for h := 0; h < 24; h++ {
+		covered[h] = true
+	}
+
+	off, always := offHours(peak, covered, 0.01, 4)
+	assert.False(t, always)
+	assert.Equal(t, []weekRun{{start: 0, length: 24}}, maskRuns(off))
+}
+
+func TestMaskRuns_WrapsAroundWeek(t *testing.T) {
+	var mask weekMask
+	for h := hoursPerWeek - 4; h < hoursPerWeek; h++ {
+		mask[h] = true
+	}
+	for h := 0; h < 6; h++ {
+		mask[h] = true
+	}
+	mask[50] = true
+
+	assert.Equal(t, []weekRun{{start: 50, length: 1}, {start: hoursPerWeek - 4, length: 10}}, maskRuns(mask))
+}
+
+func TestCronDays(t *testing.T) {
+	tests := []struct {
+		days []int
+		want string
+	}{
+		{[]int{1, 2, 3, 4, 5}, "1-5"},
+		{[]int{6, 0}, "0,6"},
+		{[]int{0, 1, 2, 3, 4, 5, 6}, "*"},
+		{[]int{1, 2, 4}, "1,2,4"},
+		{[]int{0, 2, 3, 4, 6}, "0,2-4,6"},
+		{[]int{3}, "3"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, cronDays(tt.days), "days %v", tt.days)
+	}
+}
+
+func TestCronJobName(t *testing.T) {
+	assert.Equal(t, "web-sleep", cronJobName("web", "sleep", 0, 1))
+	assert.Equal(t, "web-wake-2", cronJobName("web", "wake", 1, 2))
+
+	long := cronJobName("a-very-long-deployment-name-that-exceeds-the-cronjob-limit", "sleep", 0, 1)
+	assert.Len(t, long, maxCronJobName)
+	assert.Equal(t, "-sleep", long[len(long)-6:])
+}
@@ -0,0 +1,404 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// Storage skew analysis defaults.
+const (
+	DefaultStorageSkewWindow = 7 * 24 * time.Hour
+	DefaultStorageFullRatio  = 0.85 // used share of capacity (or limit) at which storage is nearly full
+	DefaultStorageMargin     = 0.25 // headroom over peak usage for recommended sizes
+
+	// storageMinSavings is the share of a request a smaller size must give
+	// back for the volume or container to count as over-provisioned.
+	storageMinSavings = 0.2
+	// storageVolumeRounding rounds recommended volume sizes up to 1Gi.
+	storageVolumeRounding = 1 << 30
+	// storageEphemeralRounding rounds recommended ephemeral-storage requests up to 1Mi.
+	storageEphemeralRounding = 1 << 20
+)
+
+// StorageSkewConfig holds configuration for the storage skew analysis.
+type StorageSkewConfig struct {
+	Namespace string        // "" = all namespaces
+	Window    time.Duration // lookback (0 = DefaultStorageSkewWindow)
+	FullRatio float64       // nearly-full threshold (0 = DefaultStorageFullRatio)
+	Margin    float64       // headroom for recommended sizes (0 = DefaultStorageMargin)
+	Top       int           // keep the N most over-provisioned volumes and containers (0 = all)
+	Now       time.Time     // zero = time.Now(); set by tests
+}
+
+// StorageSkewResult is the outcome of AnalyzeStorageSkew.
+type StorageSkewResult struct {
+	Window        string                 `json:"window"`
+	FullThreshold float64                `json:"full_threshold_percent"`
+	Summary       StorageSkewSummary     `json:"summary"`
+	Volumes       []VolumeSkew           `json:"volumes"`   // nearly full first, then most over-provisioned
+	Ephemeral     []EphemeralStorageSkew `json:"ephemeral"` // nearly full first, then most over-provisioned
+	Unmeasured    []UnmeasuredVolume     `json:"unmeasured_volumes,omitempty"`
+	GeneratedAt   time.Time              `json:"generated_at"`
+	Queries       *querylog.Report       `json:"queries,omitempty"` // --include-queries
+}
+
+// StorageSkewSummary totals the analysis over every measured volume and
+// container, before the top-N cut.
+type StorageSkewSummary struct {
+	Volumes                     int     `json:"volumes"`
+	RequestedVolumeBytes        float64 `json:"requested_volume_bytes"`
+	OverProvisionedVolumeBytes  float64 `json:"over_provisioned_volume_bytes"`
+	NearlyFullVolumes           int     `json:"nearly_full_volumes"`
+	Containers                  int     `json:"containers"`
+	OverProvisionedStorageBytes float64 `json:"over_provisioned_ephemeral_bytes"`
+	NearlyFullContainers        int     `json:"nearly_full_containers"`
+}
+
+// VolumeSkew compares a PersistentVolumeClaim's request with the usage the
+// kubelet reports for its volume.
+type VolumeSkew struct {
+	Namespace            string  `json:"namespace"`
+	PVC                  string  `json:"pvc"`
+	StorageClass         string  `json:"storage_class,omitempty"`
+	MountedBy            string  `json:"mounted_by,omitempty"` // Kind/name of the workload mounting it
+	RequestedBytes       float64 `json:"requested_bytes"`
+	CapacityBytes        float64 `json:"capacity_bytes"` // filesystem size
+	UsedBytes            float64 `json:"used_bytes"`
+	PeakUsedBytes        float64 `json:"peak_used_bytes"`
+	UsedPercent          float64 `json:"used_percent"` // used / capacity
+	OverProvisionedBytes float64 `json:"over_provisioned_bytes"`
+	RecommendedBytes     float64 `json:"recommended_bytes"`
+	NearlyFull           bool    `json:"nearly_full"`
+	Recommendation       string  `json:"recommendation"`
+}
+
+// EphemeralStorageSkew compares a workload container's ephemeral-storage
+// request and limit with its usage, aggregated over its replicas.
+type EphemeralStorageSkew struct {
+	Namespace            string  `json:"namespace"`
+	Workload             string  `json:"workload"`
+	Kind                 string  `json:"kind"`
+	Container            string  `json:"container"`
+	Pods                 int     `json:"pods"`
+	RequestBytes         float64 `json:"request_bytes"`   // per replica
+	LimitBytes           float64 `json:"limit_bytes"`     // per replica, 0 = none
+	PeakUsedBytes        float64 `json:"peak_used_bytes"` // hottest replica
+	OverProvisionedBytes float64 `json:"over_provisioned_bytes"`
+	RecommendedBytes     float64 `json:"recommended_request_bytes"`
+	NearlyFull           bool    `json:"nearly_full"` // usage close to the limit: eviction risk
+	Recommendation       string  `json:"recommendation"`
+}
+
+// UnmeasuredVolume is a PersistentVolumeClaim without kubelet volume stats.
+type UnmeasuredVolume struct {
+	Namespace      string  `json:"namespace"`
+	PVC            string  `json:"pvc"`
+	Phase          string  `json:"phase"`
+	RequestedBytes float64 `json:"requested_bytes"`
+}
+
+// ephemeralKey identifies a container of a workload.
+type ephemeralKey struct {
+	namespace, kind, workload, container string
+}
+
+// AnalyzeStorageSkew compares PersistentVolumeClaim requests with
+// kubelet_volume_stats_used_bytes and container ephemeral-storage requests
+// with container_fs_usage_bytes over the window. Volumes and containers
+// whose peak usage plus margin fits in 80% or less of their request are
+// over-provisioned and ranked by the bytes they could give back; those at
+// or above the full ratio of their capacity (volumes) or limit
+// (containers) are flagged nearly full and listed first, regardless of
+// Top.
+func AnalyzeStorageSkew(
+	ctx context.Context, client kubernetes.Interface, provider metrics.MetricsProvider, cfg StorageSkewConfig,
+) (*StorageSkewResult, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultStorageSkewWindow
+	}
+	fullRatio := cfg.FullRatio
+	if fullRatio <= 0 {
+		fullRatio = DefaultStorageFullRatio
+	}
+	margin := cfg.Margin
+	if margin <= 0 {
+		margin = DefaultStorageMargin
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	volumeUsage, err := provider.GetVolumeUsage(ctx, cfg.Namespace, window)
+	if err != nil {
+		return nil, err
+	}
+	containerUsage, err := provider.GetContainerEphemeralStorage(ctx, cfg.Namespace, window)
+	if err != nil {
+		return nil, err
+	}
+	pvcs, err := client.CoreV1().PersistentVolumeClaims(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+	pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	rsOwners := replicaSetDeployments(ctx, client, cfg.Namespace)
+
+	result := &StorageSkewResult{
+		Window:        window.String(),
+		FullThreshold: fullRatio * 100,
+		Volumes:       []VolumeSkew{},
+		Ephemeral:     []EphemeralStorageSkew{},
+		GeneratedAt:   now.UTC(),
+	}
+	analyzeVolumes(ctx, client, result, volumeUsage, pvcs.Items, pods.Items, rsOwners, fullRatio, margin)
+	analyzeEphemeralStorage(result, containerUsage, pods.Items, rsOwners, fullRatio, margin)
+
+	result.Volumes = rankStorage(result.Volumes, cfg.Top,
+		func(v *VolumeSkew) bool { return v.NearlyFull },
+		func(v *VolumeSkew) float64 { return v.UsedPercent },
+		func(v *VolumeSkew) float64 { return v.OverProvisionedBytes },
+		func(v *VolumeSkew) string { return v.Namespace + "/" + v.PVC })
+	result.Ephemeral = rankStorage(result.Ephemeral, cfg.Top,
+		func(c *EphemeralStorageSkew) bool { return c.NearlyFull },
+		func(c *EphemeralStorageSkew) float64 { return usedShare(c.PeakUsedBytes, c.LimitBytes) },
+		func(c *EphemeralStorageSkew) float64 { return c.OverProvisionedBytes },
+		func(c *EphemeralStorageSkew) string { return c.Namespace + "/" + c.Workload + "/" + c.Container })
+	return result, nil
+}
+
+// analyzeVolumes matches volume stats with their claims. Claims without
+// stats are listed as unmeasured; stats of deleted claims are left out.
+func analyzeVolumes(
+	ctx context.Context, client kubernetes.Interface, result *StorageSkewResult, usage []metrics.VolumeUsage,
+	pvcs []corev1.PersistentVolumeClaim, pods []corev1.Pod, rsOwners map[string]string, fullRatio, margin float64,
+) {
+	usageByPVC := make(map[string]*metrics.VolumeUsage, len(usage))
+	for i := range usage {
+		usageByPVC[usage[i].Namespace+"/"+usage[i].PVC] = &usage[i]
+	}
+	mountedBy := make(map[string]string)
+	for i := range pods {
+		pod := &pods[i]
+		for j := range pod.Spec.Volumes {
+			if claim := pod.Spec.Volumes[j].PersistentVolumeClaim; claim != nil {
+				kind, name := podWorkload(pod, rsOwners)
+				mountedBy[pod.Namespace+"/"+claim.ClaimName] = kind + "/" + name
+			}
+		}
+	}
+	expandable := storageClassExpansion(ctx, client)
+
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		var requested float64
+		if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			requested = q.AsApproximateFloat64()
+		}
+		key := pvc.Namespace + "/" + pvc.Name
+		u := usageByPVC[key]
+		if u == nil {
+			result.Unmeasured = append(result.Unmeasured, UnmeasuredVolume{
+				Namespace: pvc.Namespace, PVC: pvc.Name, Phase: string(pvc.Status.Phase), RequestedBytes: requested,
+			})
+			continue
+		}
+
+		v := VolumeSkew{
+			Namespace:      pvc.Namespace,
+			PVC:            pvc.Name,
+			MountedBy:      mountedBy[key],
+			RequestedBytes: requested,
+			CapacityBytes:  u.Capacity,
+			UsedBytes:      u.Used,
+			PeakUsedBytes:  u.PeakUsed,
+			UsedPercent:    usedShare(u.Used, u.Capacity) * 100,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			v.StorageClass = *pvc.Spec.StorageClassName
+		}
+		v.RecommendedBytes = roundUp(v.PeakUsedBytes*(1+margin), storageVolumeRounding)
+		v.NearlyFull = v.UsedBytes >= v.CapacityBytes*fullRatio
+
+		result.Summary.Volumes++
+		result.Summary.RequestedVolumeBytes += requested
+		switch {
+		case v.NearlyFull:
+			result.Summary.NearlyFullVolumes++
+			v.RecommendedBytes = math.Max(v.RecommendedBytes, roundUp(requested+1, storageVolumeRounding))
+			v.Recommendation = fmt.Sprintf("%.0f%% full: expand to %s", v.UsedPercent, units.MemoryGi(v.RecommendedBytes))
+			if allowed, ok := expandable[v.StorageClass]; ok && !allowed {
+				v.Recommendation += fmt.Sprintf(" (storage class %s does not allow expansion: migrate to a larger volume)", v.StorageClass)
+			}
+		case v.RecommendedBytes <= requested*(1-storageMinSavings):
+			v.OverProvisionedBytes = requested - v.RecommendedBytes
+			result.Summary.OverProvisionedVolumeBytes += v.OverProvisionedBytes
+			v.Recommendation = fmt.Sprintf(
+				"peak usage is %.0f%% of the request: a %s volume is enough (claims cannot shrink in place: migrate the data)",
+				usedShare(v.PeakUsedBytes, requested)*100, units.MemoryGi(v.RecommendedBytes))
+		default:
+			continue
+		}
+		result.Volumes = append(result.Volumes, v)
+	}
+}
+
+// analyzeEphemeralStorage aggregates container usage per workload
+// container. Containers without an ephemeral-storage request or limit have
+// nothing to compare with and are left out, as are series of pods that no
+// longer exist.
+func analyzeEphemeralStorage(
+	result *StorageSkewResult, usage []metrics.ContainerStorage, pods []corev1.Pod, rsOwners map[string]string,
+	fullRatio, margin float64,
+) {
+	podsByName := make(map[string]*corev1.Pod, len(pods))
+	for i := range pods {
+		podsByName[pods[i].Namespace+"/"+pods[i].Name] = &pods[i]
+	}
+
+	containers := make(map[ephemeralKey]*EphemeralStorageSkew)
+	var order []ephemeralKey
+	for _, s := range usage {
+		pod := podsByName[s.Namespace+"/"+s.Pod]
+		if pod == nil {
+			continue
+		}
+		request, limit := containerEphemeralStorage(pod, s.Container)
+		if request == 0 && limit == 0 {
+			continue
+		}
+		kind, name := podWorkload(pod, rsOwners)
+		key := ephemeralKey{namespace: s.Namespace, kind: kind, workload: name, container: s.Container}
+		c := containers[key]
+		if c == nil {
+			c = &EphemeralStorageSkew{
+				Namespace: s.Namespace, Workload: name, Kind: kind, Container: s.Container,
+				RequestBytes: request, LimitBytes: limit,
+			}
+			containers[key] = c
+			order = append(order, key)
+		}
+		c.Pods++
+		c.PeakUsedBytes = math.Max(c.PeakUsedBytes, s.PeakUsed)
+		c.OverProvisionedBytes += math.Max(0, request-s.PeakUsed)
+	}
+
+	for _, key := range order {
+		c := containers[key]
+		c.RecommendedBytes = roundUp(c.PeakUsedBytes*(1+margin), storageEphemeralRounding)
+		c.NearlyFull = c.LimitBytes > 0 && c.PeakUsedBytes >= c.LimitBytes*fullRatio
+
+		result.Summary.Containers++
+		switch {
+		case c.NearlyFull:
+			result.Summary.NearlyFullContainers++
+			c.OverProvisionedBytes = 0
+			c.Recommendation = fmt.Sprintf(
+				"peak usage is %.0f%% of the limit: the kubelet evicts the pod when it is exceeded; raise the limit above %s or clean up logs and scratch files",
+				usedShare(c.PeakUsedBytes, c.LimitBytes)*100, units.Memory(c.RecommendedBytes))
+		case c.RecommendedBytes <= c.RequestBytes*(1-storageMinSavings):
+			result.Summary.OverProvisionedStorageBytes += c.OverProvisionedBytes
+			c.Recommendation = fmt.Sprintf("peak usage is %.0f%% of the request: lower the ephemeral-storage request to %s",
+				usedShare(c.PeakUsedBytes, c.RequestBytes)*100, units.Memory(c.RecommendedBytes))
+		default:
+			continue
+		}
+		result.Ephemeral = append(result.Ephemeral, *c)
+	}
+}
+
+// rankStorage sorts nearly-full entries first (fullest first), then the
+// rest by over-provisioned bytes, and keeps all nearly-full entries plus
+// the top most over-provisioned ones (0 = all).
+func rankStorage[T any](
+	items []T, top int, nearlyFull func(*T) bool, fullness, overProvisioned func(*T) float64, name func(*T) string,
+) []T {
+	sort.Slice(items, func(i, j int) bool {
+		a, b := &items[i], &items[j]
+		if nearlyFull(a) != nearlyFull(b) {
+			return nearlyFull(a)
+		}
+		if nearlyFull(a) && fullness(a) != fullness(b) {
+			return fullness(a) > fullness(b)
+		}
+		if overProvisioned(a) != overProvisioned(b) {
+			return overProvisioned(a) > overProvisioned(b)
+		}
+		return name(a) < name(b)
+	})
+	if top <= 0 {
+		return items
+	}
+	full := 0
+	for i := range items {
+		if nearlyFull(&items[i]) {
+			full++
+		}
+	}
+	if len(items) > full+top {
+		items = items[:full+top]
+	}
+	return items
+}
+
+// storageClassExpansion maps storage class names to allowVolumeExpansion.
+// Classes are cluster-scoped; when they cannot be listed the map is empty
+// and expansion is not checked.
+func storageClassExpansion(ctx context.Context, client kubernetes.Interface) map[string]bool {
+	list, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	expandable := make(map[string]bool, len(list.Items))
+	for i := range list.Items {
+		sc := &list.Items[i]
+		expandable[sc.Name] = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+	}
+	return expandable
+}
+
+// containerEphemeralStorage returns a pod container's ephemeral-storage
+// request and limit in bytes.
+func containerEphemeralStorage(pod *corev1.Pod, name string) (request, limit float64) {
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Name != name {
+			continue
+		}
+		if q, ok := c.Resources.Requests[corev1.ResourceEphemeralStorage]; ok {
+			request = q.AsApproximateFloat64()
+		}
+		if q, ok := c.Resources.Limits[corev1.ResourceEphemeralStorage]; ok {
+			limit = q.AsApproximateFloat64()
+		}
+	}
+	return request, limit
+}
+
+// roundUp rounds bytes up to a multiple of step, with at least one step.
+func roundUp(bytes, step float64) float64 {
+	return math.Max(1, math.Ceil(bytes/step-1e-9)) * step
+}
+
+// usedShare returns used / total, 0 when total is 0.
+func usedShare(used, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return used / total
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func storagePVC(name, size, class string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &class,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+}
+
+func ephemeralPod(name, owner, claim, request, limit string) *corev1.Pod {
+	resources := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	if request != "" {
+		resources.Requests[corev1.ResourceEphemeralStorage] = resource.MustParse(request)
+	}
+	if limit != "" {
+		resources.Limits[corev1.ResourceEphemeralStorage] = resource.MustParse(limit)
+	}
+	pod := throttledPod(name, owner, "StatefulSet", "1")
+	pod.Spec.Containers[0].Resources = resources
+	if claim != "" {
+		pod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+		}}}
+	}
+	return pod
+}
+
+func TestAnalyzeStorageSkew(t *testing.T) {
+	noExpansion := false
+	client := fake.NewClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, AllowVolumeExpansion: &noExpansion},
+		storagePVC("data-db-0", "100Gi", "fast"),
+		storagePVC("data-queue-0", "10Gi", "standard"),
+		storagePVC("data-cache-0", "10Gi", "fast"),
+		storagePVC("data-old-0", "50Gi", "fast"),
+		ephemeralPod("db-0", "db", "data-db-0", "10Gi", ""),
+		ephemeralPod("db-1", "db", "", "10Gi", ""),
+		ephemeralPod("queue-0", "queue", "data-queue-0", "100Mi", "1Gi"),
+		ephemeralPod("cache-0", "cache", "data-cache-0", "", ""),
+	)
+	provider := metrics.NewMockMetrics()
+	provider.Volumes = []metrics.VolumeUsage{
+		// db: 12Gi peak of 100Gi requested.
+		{Namespace: "prod", PVC: "data-db-0", Capacity: 98 * gib, Used: 10 * gib, PeakUsed: 12 * gib},
+		// queue: 9.5Gi of 10Gi.
+		{Namespace: "prod", PVC: "data-queue-0", Capacity: 10 * gib, Used: 9.5 * gib, PeakUsed: 9.6 * gib},
+		// cache: 7Gi of 10Gi, neither full nor worth shrinking.
+		{Namespace: "prod", PVC: "data-cache-0", Capacity: 10 * gib, Used: 7 * gib, PeakUsed: 7 * gib},
+		{Namespace: "prod", PVC: "deleted", Capacity: 10 * gib},
+	}
+	provider.Ephemeral = []metrics.ContainerStorage{
+		{Namespace: "prod", Pod: "db-0", Container: "app", PeakUsed: 1 * gib},
+		{Namespace: "prod", Pod: "db-1", Container: "app", PeakUsed: 2 * gib},
+		{Namespace: "prod", Pod: "queue-0", Container: "app", PeakUsed: 0.9 * gib},
+		{Namespace: "prod", Pod: "cache-0", Container: "app", PeakUsed: 5 * gib},
+	}
+
+	result, err := AnalyzeStorageSkew(context.Background(), client, provider, StorageSkewConfig{Namespace: "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, 85.0, result.FullThreshold)
+
+	require.Len(t, result.Volumes, 2, "cache is neither over-provisioned nor nearly full")
+	queue := result.Volumes[0]
+	assert.Equal(t, "data-queue-0", queue.PVC)
+	assert.True(t, queue.NearlyFull)
+	assert.Equal(t, "StatefulSet/queue", queue.MountedBy)
+	assert.InDelta(t, 95.0, queue.UsedPercent, 0.001)
+	assert.InDelta(t, 12*gib, queue.RecommendedBytes, 1)
+	assert.Contains(t, queue.Recommendation, "storage class standard does not allow expansion")
+
+	db := result.Volumes[1]
+	assert.Equal(t, "data-db-0", db.PVC)
+	assert.False(t, db.NearlyFull)
+	assert.InDelta(t, 15*gib, db.RecommendedBytes, 1)
+	assert.InDelta(t, 85*gib, db.OverProvisionedBytes, 1)
+	assert.Contains(t, db.Recommendation, "peak usage is 12% of the request")
+
+	require.Len(t, result.Unmeasured, 1)
+	assert.Equal(t, "data-old-0", result.Unmeasured[0].PVC)
+	assert.Equal(t, 3, result.Summary.Volumes)
+	assert.Equal(t, 1, result.Summary.NearlyFullVolumes)
+
+	require.Len(t, result.Ephemeral, 2, "cache has no ephemeral-storage request or limit")
+	assert.Equal(t, "queue", result.Ephemeral[0].Workload)
+	assert.True(t, result.Ephemeral[0].NearlyFull)
+	assert.Contains(t, result.Ephemeral[0].Recommendation, "the kubelet evicts the pod")
+	dbStorage := result.Ephemeral[1]
+	assert.Equal(t, 2, dbStorage.Pods)
+	assert.InDelta(t, 2*gib, dbStorage.PeakUsedBytes, 1)
+	assert.InDelta(t, 17*gib, dbStorage.OverProvisionedBytes, 1)
+	assert.InDelta(t, 2.5*gib, dbStorage.RecommendedBytes, 1)
+	assert.Equal(t, 2, result.Summary.Containers)
+
+	top, err := AnalyzeStorageSkew(context.Background(), client, provider, StorageSkewConfig{Top: 1, FullRatio: 0.99})
+	require.NoError(t, err)
+	require.Len(t, top.Volumes, 1)
+	assert.Equal(t, "data-db-0", top.Volumes[0].PVC)
+	assert.Len(t, top.Ephemeral, 1)
+}
+
+func TestAnalyzeStorageSkew_ProviderError(t *testing.T) {
+	provider := metrics.NewMockMetrics()
+	provider.QueryInstantError = assert.AnError
+	_, err := AnalyzeStorageSkew(context.Background(), fake.NewClientset(), provider, StorageSkewConfig{})
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,252 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// labelHelmChart is the chart label Helm charts conventionally set on the
+// objects they render (e.g. "ingress-nginx-4.10.0").
+const labelHelmChart = "helm.sh/chart"
+
+// templateGroupKinds are the workload kinds grouped by template. Batch and
+// CRD-managed workloads are always analyzed one by one.
+var templateGroupKinds = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// workloadKey identifies a workload across namespaces.
+type workloadKey struct {
+	namespace, kind, name string
+}
+
+func (k workloadKey) String() string {
+	return k.namespace + "/" + k.name
+}
+
+// templateGroup is a set of workloads rendered from the same template — the
+// same chart, or the same images, with identical requests and limits — in
+// more than one namespace. Only the representative is queried; its
+// recommendation stands for every instance.
+type templateGroup struct {
+	template       string // chart, or the first container image
+	representative workloadKey
+	instances      []workloadKey // representative first, then by namespace
+}
+
+// templatedWorkload is a workload with the pod template it is grouped by.
+type templatedWorkload struct {
+	key          workloadKey
+	labels       map[string]string
+	creationTime time.Time
+	spec         *corev1.PodSpec
+}
+
+// buildTemplateGroups lists the groupable workloads of the given namespaces
+// and groups those sharing a template. The representative is the oldest
+// instance, which has the longest usage history. A namespace that cannot be
+// listed contributes nothing.
+func (a *RequestsSkewAnalyzer) buildTemplateGroups(ctx context.Context, namespaces []string) map[workloadKey]*templateGroup {
+	byFingerprint := map[string][]templatedWorkload{}
+	for _, ns := range namespaces {
+		for _, kind := range templateGroupKinds {
+			workloads, err := a.listTemplatedWorkloads(ctx, ns, kind)
+			if err != nil {
+				a.logWarning("Failed to list workloads for template grouping", "kind", kind, "namespace", ns, "error", err)
+				continue
+			}
+			for _, w := range workloads {
+				fp := templateFingerprint(w)
+				byFingerprint[fp] = append(byFingerprint[fp], w)
+			}
+		}
+	}
+
+	groups := map[workloadKey]*templateGroup{}
+	for _, members := range byFingerprint {
+		if !spansNamespaces(members) {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if !members[i].creationTime.Equal(members[j].creationTime) {
+				return members[i].creationTime.Before(members[j].creationTime)
+			}
+			return members[i].key.namespace < members[j].key.namespace
+		})
+		g := &templateGroup{template: templateName(members[0]), representative: members[0].key}
+		for _, m := range members {
+			g.instances = append(g.instances, m.key)
+			groups[m.key] = g
+		}
+		rest := g.instances[1:]
+		sort.Slice(rest, func(i, j int) bool { return rest[i].String() < rest[j].String() })
+	}
+	return groups
+}
+
+func (a *RequestsSkewAnalyzer) listTemplatedWorkloads(ctx context.Context, namespace, kind string) ([]templatedWorkload, error) {
+	var out []templatedWorkload
+	add := func(meta *metav1.ObjectMeta, spec *corev1.PodSpec) {
+		out = append(out, templatedWorkload{
+			key:          workloadKey{namespace: namespace, kind: kind, name: meta.Name},
+			labels:       meta.Labels,
+			creationTime: meta.CreationTimestamp.Time,
+			spec:         spec,
+		})
+	}
+	switch kind {
+	case "Deployment":
+		list, err := a.kubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			add(&list.Items[i].ObjectMeta, &list.Items[i].Spec.Template.Spec)
+		}
+	case "StatefulSet":
+		list, err := a.kubeClient.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			add(&list.Items[i].ObjectMeta, &list.Items[i].Spec.Template.Spec)
+		}
+	case "DaemonSet":
+		list, err := a.kubeClient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			add(&list.Items[i].ObjectMeta, &list.Items[i].Spec.Template.Spec)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported workload kind: %s", kind)
+	}
+	return out, nil
+}
+
+// templateFingerprint identifies a workload's template: its kind, chart
+// label, and every container's name, image, requests, and limits. Workloads
+// from the same chart with different resource values are different
+// templates, since they need different recommendations.
+func templateFingerprint(w templatedWorkload) string {
+	var b strings.Builder
+	b.WriteString(w.key.kind)
+	b.WriteString("|")
+	b.WriteString(w.labels[labelHelmChart])
+	for i := range w.spec.Containers {
+		c := &w.spec.Containers[i]
+		fmt.Fprintf(&b, "|%s=%s", c.Name, c.Image)
+		for _, list := range []corev1.ResourceList{c.Resources.Requests, c.Resources.Limits} {
+			cpu, mem := list[corev1.ResourceCPU], list[corev1.ResourceMemory]
+			fmt.Fprintf(&b, ";%s,%s", cpu.String(), mem.String())
+		}
+	}
+	return b.String()
+}
+
+// templateName names a template by its chart, or its first image.
+func templateName(w templatedWorkload) string {
+	if chart := w.labels[labelHelmChart]; chart != "" {
+		return chart
+	}
+	if len(w.spec.Containers) > 0 {
+		return w.spec.Containers[0].Image
+	}
+	return w.key.name
+}
+
+func spansNamespaces(members []templatedWorkload) bool {
+	for _, m := range members[1:] {
+		if m.key.namespace != members[0].key.namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// skipGroupedInstances drops the instances that a template group's
+// representative stands for.
+func (a *RequestsSkewAnalyzer) skipGroupedInstances(namespace, kind string, targets []namespaceWorkload) []namespaceWorkload {
+	if len(a.templateGroups) == 0 {
+		return targets
+	}
+	kept := targets[:0:0]
+	for _, t := range targets {
+		key := workloadKey{namespace: namespace, kind: kind, name: t.name}
+		if g, ok := a.templateGroups[key]; ok && g.representative != key {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept
+}
+
+// attachTemplateGroups lists the instances on each representative's result
+// and weights its impact by their number. Instances of a representative
+// without usable metrics are reported without metrics as well.
+func (a *RequestsSkewAnalyzer) attachTemplateGroups(result *RequestsSkewResult) {
+	analyzed := map[workloadKey]bool{}
+	for i := range result.Results {
+		w := &result.Results[i]
+		key := workloadKey{namespace: w.Namespace, kind: w.Type, name: w.Workload}
+		g, ok := a.templateGroups[key]
+		if !ok || g.representative != key {
+			continue
+		}
+		analyzed[key] = true
+		w.Template = g.template
+		w.Instances = make([]string, len(g.instances))
+		for j, inst := range g.instances {
+			w.Instances[j] = inst.String()
+		}
+		w.ImpactScore *= float64(len(g.instances))
+	}
+
+	var missing []*templateGroup
+	seen := map[*templateGroup]bool{}
+	for _, g := range a.templateGroups {
+		if !seen[g] && !analyzed[g.representative] {
+			missing = append(missing, g)
+		}
+		seen[g] = true
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].representative.String() < missing[j].representative.String() })
+	for _, g := range missing {
+		for _, inst := range g.instances[1:] {
+			result.WorkloadsWithoutMetrics = append(result.WorkloadsWithoutMetrics, WorkloadWithoutMetrics{
+				Namespace: inst.namespace,
+				Workload:  inst.name,
+				Type:      inst.kind,
+				Diagnosis: fmt.Sprintf("not analyzed: same template as %s, which has no usable metrics", g.representative),
+				template:  &g.representative,
+			})
+		}
+	}
+}
+
+// instanceNamespaces returns the namespace of every workload a result
+// stands for: its own, or one per instance of its template group.
+func (w *WorkloadSkewAnalysis) instanceNamespaces() []string {
+	if len(w.Instances) == 0 {
+		return []string{w.Namespace}
+	}
+	namespaces := make([]string, len(w.Instances))
+	for i, inst := range w.Instances {
+		namespaces[i], _, _ = strings.Cut(inst, "/")
+	}
+	return namespaces
+}
+
+// countTemplateGroups counts distinct groups in a group index.
+func countTemplateGroups(groups map[workloadKey]*templateGroup) int {
+	distinct := map[*templateGroup]bool{}
+	for _, g := range groups {
+		distinct[g] = true
+	}
+	return len(distinct)
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func chartDeployment(namespace, name, chart, cpu string, age time.Duration) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			Labels:            map[string]string{labelHelmChart: chart},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "app",
+				Image: "registry.example.com/api:1.2.3",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				}},
+			}},
+		}}},
+	}
+}
+
+func TestRequestsSkew_GroupTemplates(t *testing.T) {
+	day := 24 * time.Hour
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-c"}},
+		chartDeployment("tenant-b", "api", "api-1.0.0", "4", 90*day), // oldest: representative
+		chartDeployment("tenant-a", "api", "api-1.0.0", "4", 30*day),
+		chartDeployment("tenant-c", "api", "api-1.0.0", "4", 30*day),
+		// Same chart, different requests: a template of its own.
+		chartDeployment("tenant-c", "api-large", "api-1.0.0", "8", 30*day),
+	}
+	client := fake.NewClientset(objects...)
+
+	provider := metrics.NewMockMetrics()
+	for _, key := range []string{"tenant-a/api", "tenant-b/api", "tenant-c/api", "tenant-c/api-large"} {
+		provider.WorkloadUsages[key] = &metrics.WorkloadUsage{
+			CPURequested: 4, CPUAvg: 0.5, CPUP95: 1,
+			MemoryRequested: 4 << 30, MemoryAvg: 1 << 30, MemoryP95: 2 << 30,
+		}
+	}
+
+	a := NewRequestsSkewAnalyzer(client, provider, &RequestsSkewConfig{Silent: true, Top: 100, GroupTemplates: true})
+	result, err := a.Analyze(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Results, 2)
+
+	byName := map[string]WorkloadSkewAnalysis{}
+	for _, w := range result.Results {
+		byName[w.Workload] = w
+	}
+	api := byName["api"]
+	assert.Equal(t, "tenant-b", api.Namespace)
+	assert.Equal(t, "api-1.0.0", api.Template)
+	assert.Equal(t, []string{"tenant-b/api", "tenant-a/api", "tenant-c/api"}, api.Instances)
+	assert.Empty(t, byName["api-large"].Instances, "different requests are a different template")
+
+	assert.Equal(t, 4, result.Summary.TotalWorkloads)
+	assert.Equal(t, 4, result.Summary.AnalyzedWorkloads)
+
+	ungrouped, err := NewRequestsSkewAnalyzer(client, provider, &RequestsSkewConfig{Silent: true, Top: 100}).
+		Analyze(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ungrouped.Results, 4)
+	assert.InDelta(t, ungrouped.Summary.TotalWastedCPU, result.Summary.TotalWastedCPU, 0.001)
+	assert.InDelta(t, ungrouped.Summary.TotalWastedMemoryGi, result.Summary.TotalWastedMemoryGi, 0.001)
+}
+
+func TestBuildTemplateGroups_SameNamespaceIsNotAGroup(t *testing.T) {
+	client := fake.NewClientset(
+		chartDeployment("tenant-a", "api", "api-1.0.0", "1", time.Hour),
+		chartDeployment("tenant-a", "api-canary", "api-1.0.0", "1", time.Hour),
+	)
+	a := NewRequestsSkewAnalyzer(client, metrics.NewMockMetrics(), &RequestsSkewConfig{Silent: true})
+	assert.Empty(t, a.buildTemplateGroups(context.Background(), []string{"tenant-a"}))
+}
@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// thresholdMetrics are the requests-skew summary values a Threshold can
+// compare, by name. They cover every analyzed workload, not just the top N.
+var thresholdMetrics = map[string]func(*RequestsSkewResult) float64{
+	"avg-skew-cpu":        func(r *RequestsSkewResult) float64 { return r.Summary.AvgSkewCPU },
+	"avg-skew-memory":     func(r *RequestsSkewResult) float64 { return r.Summary.AvgSkewMemory },
+	"wasted-cpu":          func(r *RequestsSkewResult) float64 { return r.Summary.TotalWastedCPU },
+	"wasted-memory":       func(r *RequestsSkewResult) float64 { return r.Summary.TotalWastedMemoryGi },
+	"wasted-limit-cpu":    func(r *RequestsSkewResult) float64 { return r.Summary.TotalWastedLimitCPU },
+	"wasted-limit-memory": func(r *RequestsSkewResult) float64 { return r.Summary.TotalWastedLimitMemoryGi },
+	"without-metrics":     func(r *RequestsSkewResult) float64 { return float64(len(r.WorkloadsWithoutMetrics)) },
+	"monthly-waste": func(r *RequestsSkewResult) float64 {
+		if r.Summary.CostEstimate == nil {
+			return 0
+		}
+		return r.Summary.CostEstimate.TotalWastedMonthly
+	},
+}
+
+// ThresholdMetricNames returns the metric names thresholds accept, sorted.
+func ThresholdMetricNames() []string {
+	names := make([]string, 0, len(thresholdMetrics))
+	for name := range thresholdMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// thresholdOps are the comparisons a threshold can make, longest first so
+// ">=" is not read as ">".
+var thresholdOps = []string{">=", "<=", ">", "<"}
+
+// Threshold is a --fail-on condition on a summary metric, e.g.
+// "avg-skew-cpu>5": the run fails when the metric compares true.
+type Threshold struct {
+	Metric string
+	Op     string
+	Value  float64
+}
+
+// String renders the threshold as it is written on the command line.
+func (t Threshold) String() string {
+	return t.Metric + t.Op + strconv.FormatFloat(t.Value, 'f', -1, 64)
+}
+
+// ParseThreshold parses "<metric><op><number>" with op one of > >= < <=.
+func ParseThreshold(s string) (Threshold, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	for _, op := range thresholdOps {
+		metric, value, found := strings.Cut(s, op)
+		if !found {
+			continue
+		}
+		if _, ok := thresholdMetrics[metric]; !ok {
+			return Threshold{}, fmt.Errorf("unknown threshold metric %q (supported: %s)", metric, strings.Join(ThresholdMetricNames(), ", "))
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: %q is not a number", s, value)
+		}
+		return Threshold{Metric: metric, Op: op, Value: v}, nil
+	}
+	return Threshold{}, fmt.Errorf("invalid threshold %q: expected <metric><op><number> with op one of > >= < <=", s)
+}
+
+// ThresholdCheck is the outcome of one threshold against one result.
+type ThresholdCheck struct {
+	Threshold string  `json:"threshold"`
+	Context   string  `json:"context,omitempty"` // kubeconfig context, for multi-cluster runs
+	Actual    float64 `json:"actual"`
+	Failed    bool    `json:"failed"`
+}
+
+// Evaluate compares the threshold against a result's summary.
+func (t Threshold) Evaluate(result *RequestsSkewResult) ThresholdCheck {
+	actual := thresholdMetrics[t.Metric](result)
+	var failed bool
+	switch t.Op {
+	case ">":
+		failed = actual > t.Value
+	case ">=":
+		failed = actual >= t.Value
+	case "<":
+		failed = actual < t.Value
+	case "<=":
+		failed = actual <= t.Value
+	}
+	return ThresholdCheck{Threshold: t.String(), Actual: actual, Failed: failed}
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Threshold
+	}{
+		{"avg-skew-cpu>5", Threshold{Metric: "avg-skew-cpu", Op: ">", Value: 5}},
+		{"wasted-cpu >= 50.5", Threshold{Metric: "wasted-cpu", Op: ">=", Value: 50.5}},
+		{"without-metrics<=0", Threshold{Metric: "without-metrics", Op: "<=", Value: 0}},
+		{"avg-skew-memory<1", Threshold{Metric: "avg-skew-memory", Op: "<", Value: 1}},
+	}
+	for _, tt := range tests {
+		got, err := ParseThreshold(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, bad := range []string{"avg-skew-cpu", "skew>5", "wasted-cpu>lots", "wasted-cpu=5"} {
+		_, err := ParseThreshold(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestThresholdEvaluate(t *testing.T) {
+	result := &RequestsSkewResult{
+		Summary:                 RequestsSkewSummary{AvgSkewCPU: 6.5, TotalWastedCPU: 50},
+		WorkloadsWithoutMetrics: []WorkloadWithoutMetrics{{}, {}},
+	}
+
+	check := Threshold{Metric: "avg-skew-cpu", Op: ">", Value: 5}.Evaluate(result)
+	assert.Equal(t, ThresholdCheck{Threshold: "avg-skew-cpu>5", Actual: 6.5, Failed: true}, check)
+
+	assert.False(t, Threshold{Metric: "wasted-cpu", Op: ">", Value: 50}.Evaluate(result).Failed)
+	assert.True(t, Threshold{Metric: "wasted-cpu", Op: ">=", Value: 50}.Evaluate(result).Failed)
+	assert.True(t, Threshold{Metric: "without-metrics", Op: ">", Value: 1}.Evaluate(result).Failed)
+	assert.Zero(t, Threshold{Metric: "monthly-waste", Op: ">", Value: 0}.Evaluate(result).Actual, "no cost estimate")
+}
@@ -0,0 +1,232 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// Throttling analysis defaults.
+const (
+	DefaultThrottlingWindow    = 7 * 24 * time.Hour
+	DefaultThrottlingThreshold = 0.10 // share of CFS periods throttled; matches the requests-skew safety check
+	DefaultThrottlingMargin    = 0.25 // headroom over demand for the recommended limit
+	DefaultThrottlingQuantile  = 0.99
+
+	// throttlingSustainedRatio is the share of the limit the usage quantile
+	// must reach for throttling to be sustained demand rather than bursts.
+	throttlingSustainedRatio = 0.9
+	// throttlingMinPeriods ignores containers that ran for under ~10 seconds
+	// of CFS periods (100ms each) in the window.
+	throttlingMinPeriods = 100
+	// throttlingLimitRounding rounds recommended limits up to 10 millicores.
+	throttlingLimitRounding = 0.01
+)
+
+// ThrottlingConfig holds configuration for the CPU throttling analysis.
+type ThrottlingConfig struct {
+	Namespace string        // "" = all namespaces
+	Window    time.Duration // lookback (0 = DefaultThrottlingWindow)
+	Threshold float64       // minimum throttled share of periods to report (0 = DefaultThrottlingThreshold)
+	Margin    float64       // headroom for the recommended limit (0 = DefaultThrottlingMargin)
+	Quantile  float64       // usage quantile compared with the limit (0 = DefaultThrottlingQuantile)
+	Top       int           // keep the N worst workloads (0 = all)
+	Now       time.Time     // zero = time.Now(); set by tests
+}
+
+// ThrottlingResult is the outcome of AnalyzeThrottling.
+type ThrottlingResult struct {
+	Window      string              `json:"window"`
+	Threshold   float64             `json:"threshold_percent"`
+	Quantile    float64             `json:"quantile"`
+	Workloads   []ThrottledWorkload `json:"workloads"` // worst first
+	GeneratedAt time.Time           `json:"generated_at"`
+	Queries     *querylog.Report    `json:"queries,omitempty"` // --include-queries
+}
+
+// ThrottledWorkload is a workload with at least one container throttled
+// above the threshold.
+type ThrottledWorkload struct {
+	Namespace        string               `json:"namespace"`
+	Workload         string               `json:"workload"`
+	Kind             string               `json:"kind"`
+	ThrottledPercent float64              `json:"throttled_percent"` // worst container
+	Containers       []ThrottledContainer `json:"containers"`
+}
+
+// ThrottledContainer is one container of a throttled workload, aggregated
+// over its replicas.
+type ThrottledContainer struct {
+	Name             string  `json:"name"`
+	Pods             int     `json:"pods"`
+	ThrottledPercent float64 `json:"throttled_percent"` // throttled periods / periods
+	ThrottledPeriods float64 `json:"throttled_periods"`
+	Periods          float64 `json:"periods"`
+	CPURequest       float64 `json:"cpu_request,omitempty"` // cores
+	CPULimit         float64 `json:"cpu_limit"`             // cores
+	CPUUsage         float64 `json:"cpu_usage"`             // usage quantile of the hottest replica, cores
+	RecommendedLimit float64 `json:"recommended_limit"`     // cores
+	Sustained        bool    `json:"sustained"`             // usage reaches the limit, not just short bursts
+	Recommendation   string  `json:"recommendation"`
+}
+
+// throttledKey identifies a container of a workload.
+type throttledKey struct {
+	namespace, kind, workload, container string
+}
+
+// AnalyzeThrottling reports containers whose share of throttled CFS periods
+// (container_cpu_cfs_throttled_periods_total / container_cpu_cfs_periods_total)
+// exceeds the threshold, grouped by workload, and recommends a CPU limit for
+// each. Series of pods that no longer exist are left out, since their
+// workload cannot be resolved.
+func AnalyzeThrottling(
+	ctx context.Context, client kubernetes.Interface, provider metrics.MetricsProvider, cfg ThrottlingConfig,
+) (*ThrottlingResult, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultThrottlingWindow
+	}
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThrottlingThreshold
+	}
+	margin := cfg.Margin
+	if margin <= 0 {
+		margin = DefaultThrottlingMargin
+	}
+	quantile := cfg.Quantile
+	if quantile <= 0 {
+		quantile = DefaultThrottlingQuantile
+	}
+	now := cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	series, err := provider.GetContainerThrottling(ctx, cfg.Namespace, window, quantile)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	podsByName := make(map[string]*corev1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		podsByName[pods.Items[i].Namespace+"/"+pods.Items[i].Name] = &pods.Items[i]
+	}
+	rsOwners := replicaSetDeployments(ctx, client, cfg.Namespace)
+
+	containers := make(map[throttledKey]*ThrottledContainer)
+	for _, s := range series {
+		pod := podsByName[s.Namespace+"/"+s.Pod]
+		if pod == nil {
+			continue
+		}
+		kind, name := podWorkload(pod, rsOwners)
+		key := throttledKey{namespace: s.Namespace, kind: kind, workload: name, container: s.Container}
+		c := containers[key]
+		if c == nil {
+			c = &ThrottledContainer{Name: s.Container}
+			c.CPURequest, c.CPULimit = containerCPU(pod, s.Container)
+			containers[key] = c
+		}
+		c.Pods++
+		c.Periods += s.Periods
+		c.ThrottledPeriods += s.ThrottledPeriods
+		c.CPUUsage = math.Max(c.CPUUsage, s.CPUQuantile)
+	}
+
+	workloads := make(map[throttledKey]*ThrottledWorkload)
+	for key, c := range containers {
+		if c.Periods < throttlingMinPeriods || c.ThrottledPeriods/c.Periods < threshold {
+			continue
+		}
+		c.ThrottledPercent = c.ThrottledPeriods / c.Periods * 100
+		recommendThrottlingLimit(c, margin)
+
+		wkey := throttledKey{namespace: key.namespace, kind: key.kind, workload: key.workload}
+		w := workloads[wkey]
+		if w == nil {
+			w = &ThrottledWorkload{Namespace: key.namespace, Workload: key.workload, Kind: key.kind}
+			workloads[wkey] = w
+		}
+		w.Containers = append(w.Containers, *c)
+		w.ThrottledPercent = math.Max(w.ThrottledPercent, c.ThrottledPercent)
+	}
+
+	result := &ThrottlingResult{
+		Window:      window.String(),
+		Threshold:   threshold * 100,
+		Quantile:    quantile,
+		Workloads:   make([]ThrottledWorkload, 0, len(workloads)),
+		GeneratedAt: now.UTC(),
+	}
+	for _, w := range workloads {
+		sort.Slice(w.Containers, func(i, j int) bool { return w.Containers[i].ThrottledPercent > w.Containers[j].ThrottledPercent })
+		result.Workloads = append(result.Workloads, *w)
+	}
+	sort.Slice(result.Workloads, func(i, j int) bool {
+		a, b := &result.Workloads[i], &result.Workloads[j]
+		if a.ThrottledPercent != b.ThrottledPercent {
+			return a.ThrottledPercent > b.ThrottledPercent
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Workload < b.Workload
+	})
+	if cfg.Top > 0 && len(result.Workloads) > cfg.Top {
+		result.Workloads = result.Workloads[:cfg.Top]
+	}
+	return result, nil
+}
+
+// recommendThrottlingLimit sizes a CPU limit from the larger of the limit
+// and the usage quantile plus margin. Usage far below a throttled limit
+// means bursts shorter than the scrape interval, for which removing the
+// limit and keeping the request is the alternative.
+func recommendThrottlingLimit(c *ThrottledContainer, margin float64) {
+	demand := math.Max(c.CPULimit, c.CPUUsage)
+	c.RecommendedLimit = math.Ceil(demand*(1+margin)/throttlingLimitRounding-1e-9) * throttlingLimitRounding
+	c.Sustained = c.CPULimit > 0 && c.CPUUsage >= c.CPULimit*throttlingSustainedRatio
+
+	switch {
+	case c.CPULimit == 0:
+		c.Recommendation = fmt.Sprintf("set a CPU limit of at least %s cores or check for a LimitRange default", units.Cores(c.RecommendedLimit))
+	case c.Sustained:
+		c.Recommendation = fmt.Sprintf("usage reaches the limit: raise the CPU limit to %s cores", units.Cores(c.RecommendedLimit))
+	default:
+		c.Recommendation = fmt.Sprintf(
+			"throttled in bursts while usage is %.0f%% of the limit: raise the CPU limit to %s cores, or remove it and keep the request",
+			c.CPUUsage/c.CPULimit*100, units.Cores(c.RecommendedLimit))
+	}
+}
+
+// containerCPU returns a pod container's CPU request and limit in cores.
+func containerCPU(pod *corev1.Pod, name string) (request, limit float64) {
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Name != name {
+			continue
+		}
+		if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+			request = q.AsApproximateFloat64()
+		}
+		if q, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+			limit = q.AsApproximateFloat64()
+		}
+	}
+	return request, limit
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func throttledPod(name, owner, ownerKind, cpuLimit string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpuLimit)},
+		}}}},
+	}
+	if owner != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: owner, Controller: &controller}}
+	}
+	return pod
+}
+
+func TestAnalyzeThrottling(t *testing.T) {
+	client := fake.NewClientset(
+		oomReplicaSet("api-7d9f8b6c4", "api", "1", time.Time{}),
+		throttledPod("api-7d9f8b6c4-abcde", "api-7d9f8b6c4", "ReplicaSet", "500m"),
+		throttledPod("api-7d9f8b6c4-fghij", "api-7d9f8b6c4", "ReplicaSet", "500m"),
+		throttledPod("worker-0", "worker", "StatefulSet", "1"),
+		throttledPod("calm-0", "calm", "StatefulSet", "1"),
+		throttledPod("short-0", "short", "StatefulSet", "1"),
+	)
+	provider := metrics.NewMockMetrics()
+	provider.Throttling = []metrics.ContainerThrottling{
+		// api: 40% and 20% of periods throttled, usage at the limit.
+		{Namespace: "prod", Pod: "api-7d9f8b6c4-abcde", Container: "app", Periods: 1000, ThrottledPeriods: 400, CPUQuantile: 0.49},
+		{Namespace: "prod", Pod: "api-7d9f8b6c4-fghij", Container: "app", Periods: 1000, ThrottledPeriods: 200, CPUQuantile: 0.3},
+		// worker: bursty, 15% throttled with usage far below the limit.
+		{Namespace: "prod", Pod: "worker-0", Container: "app", Periods: 2000, ThrottledPeriods: 300, CPUQuantile: 0.2},
+		{Namespace: "prod", Pod: "calm-0", Container: "app", Periods: 2000, ThrottledPeriods: 20, CPUQuantile: 0.9},
+		{Namespace: "prod", Pod: "short-0", Container: "app", Periods: 50, ThrottledPeriods: 50},
+		{Namespace: "prod", Pod: "deleted-0", Container: "app", Periods: 2000, ThrottledPeriods: 2000},
+	}
+
+	result, err := AnalyzeThrottling(context.Background(), client, provider, ThrottlingConfig{Namespace: "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, result.Threshold)
+	require.Len(t, result.Workloads, 2, "calm is under the threshold, short has too few periods, deleted-0 is gone")
+
+	api := result.Workloads[0]
+	assert.Equal(t, "Deployment", api.Kind)
+	assert.Equal(t, "api", api.Workload)
+	assert.InDelta(t, 30.0, api.ThrottledPercent, 0.001)
+	require.Len(t, api.Containers, 1)
+	c := api.Containers[0]
+	assert.Equal(t, 2, c.Pods)
+	assert.InDelta(t, 0.25, c.CPURequest, 0.001)
+	assert.InDelta(t, 0.5, c.CPULimit, 0.001)
+	assert.InDelta(t, 0.49, c.CPUUsage, 0.001)
+	assert.True(t, c.Sustained)
+	assert.InDelta(t, 0.63, c.RecommendedLimit, 0.001)
+	assert.Equal(t, "usage reaches the limit: raise the CPU limit to 0.63 cores", c.Recommendation)
+
+	worker := result.Workloads[1]
+	assert.Equal(t, "StatefulSet", worker.Kind)
+	assert.InDelta(t, 15.0, worker.ThrottledPercent, 0.001)
+	assert.False(t, worker.Containers[0].Sustained)
+	assert.Contains(t, worker.Containers[0].Recommendation, "throttled in bursts while usage is 20% of the limit")
+
+	top, err := AnalyzeThrottling(context.Background(), client, provider, ThrottlingConfig{Threshold: 0.005, Top: 1})
+	require.NoError(t, err)
+	require.Len(t, top.Workloads, 1)
+	assert.Equal(t, "api", top.Workloads[0].Workload)
+}
+
+func TestRecommendThrottlingLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		c         ThrottledContainer
+		want      float64
+		sustained bool
+	}{
+		{"at the limit", ThrottledContainer{CPULimit: 1, CPUUsage: 0.95}, 1.25, true},
+		{"usage above the limit quantile", ThrottledContainer{CPULimit: 0.2, CPUUsage: 0.3}, 0.38, true},
+		{"bursts", ThrottledContainer{CPULimit: 2, CPUUsage: 0.5}, 2.5, false},
+		{"no limit in spec", ThrottledContainer{CPUUsage: 0.4}, 0.5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommendThrottlingLimit(&tt.c, DefaultThrottlingMargin)
+			assert.InDelta(t, tt.want, tt.c.RecommendedLimit, 1e-9)
+			assert.Equal(t, tt.sustained, tt.c.Sustained)
+			assert.NotEmpty(t, tt.c.Recommendation)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/ppiankov/kubenow/internal/owners"
+)
+
+// assignNamespaceOwners gives workloads analyzed without their labels
+// (custom resources, namespaces without metrics) the team owning their
+// namespace, and rolls them up by it under --group-by team.
+func (a *RequestsSkewAnalyzer) assignNamespaceOwners(result *RequestsSkewResult) {
+	for i := range result.Results {
+		w := &result.Results[i]
+		if w.Team == "" {
+			w.Team = a.config.Owners.Team(w.Namespace, nil)
+		}
+		if a.config.GroupBy == GroupByTeam && w.Group == "" {
+			w.Group = w.Team
+		}
+	}
+	for i := range result.WorkloadsWithoutMetrics {
+		w := &result.WorkloadsWithoutMetrics[i]
+		if w.Team == "" {
+			w.Team = a.config.Owners.Team(w.Namespace, nil)
+		}
+	}
+}
+
+// Teams lists the owning teams of the reported workloads, sorted, with
+// owners.Unowned last when some workloads have no team.
+func (r *RequestsSkewResult) Teams() []string {
+	seen := map[string]bool{}
+	for i := range r.Results {
+		seen[teamOrUnowned(r.Results[i].Team)] = true
+	}
+	for i := range r.WorkloadsWithoutMetrics {
+		seen[teamOrUnowned(r.WorkloadsWithoutMetrics[i].Team)] = true
+	}
+	unowned := seen[owners.Unowned]
+	delete(seen, owners.Unowned)
+
+	teams := make([]string, 0, len(seen)+1)
+	for team := range seen {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	if unowned {
+		teams = append(teams, owners.Unowned)
+	}
+	return teams
+}
+
+// ForTeam returns the report restricted to the workloads of one team, as
+// named by Teams, with the summary, cost estimates, and rollups recomputed
+// over them. Namespace sections keep the namespaces the team has workloads
+// in; spike data is left out.
+func (r *RequestsSkewResult) ForTeam(team string) *RequestsSkewResult {
+	return r.subset(
+		func(w *WorkloadSkewAnalysis) bool { return teamOrUnowned(w.Team) == team },
+		func(w *WorkloadWithoutMetrics) bool { return teamOrUnowned(w.Team) == team },
+	)
+}
+
+// Subset returns the report restricted to the analyzed workloads keep
+// accepts, recomputed like ForTeam. Workloads without metrics are left out.
+func (r *RequestsSkewResult) Subset(keep func(w *WorkloadSkewAnalysis) bool) *RequestsSkewResult {
+	return r.subset(keep, func(*WorkloadWithoutMetrics) bool { return false })
+}
+
+func (r *RequestsSkewResult) subset(
+	keep func(w *WorkloadSkewAnalysis) bool, keepWithout func(w *WorkloadWithoutMetrics) bool,
+) *RequestsSkewResult {
+	out := &RequestsSkewResult{
+		Metadata: r.Metadata,
+		Queries:  r.Queries,
+	}
+	out.Summary.SkippedWorkloads = r.Summary.SkippedWorkloads
+
+	namespaces := map[string]bool{}
+	for i := range r.Results {
+		w := &r.Results[i]
+		if !keep(w) {
+			continue
+		}
+		out.Results = append(out.Results, *w)
+		for _, ns := range w.instanceNamespaces() {
+			namespaces[ns] = true
+		}
+	}
+	for i := range r.WorkloadsWithoutMetrics {
+		w := &r.WorkloadsWithoutMetrics[i]
+		if !keepWithout(w) {
+			continue
+		}
+		out.WorkloadsWithoutMetrics = append(out.WorkloadsWithoutMetrics, *w)
+		if out.Summary.WithoutMetricsByCause == nil {
+			out.Summary.WithoutMetricsByCause = make(map[string]int)
+		}
+		out.Summary.WithoutMetricsByCause[w.Cause]++
+		namespaces[w.Namespace] = true
+	}
+
+	for i := range r.NamespaceMetrics {
+		if namespaces[r.NamespaceMetrics[i].Namespace] {
+			out.NamespaceMetrics = append(out.NamespaceMetrics, r.NamespaceMetrics[i])
+		}
+	}
+	for i := range r.NamespaceQuotas {
+		if namespaces[r.NamespaceQuotas[i].Namespace] {
+			out.NamespaceQuotas = append(out.NamespaceQuotas, r.NamespaceQuotas[i])
+		}
+	}
+
+	calculateSummary(out)
+	if r.Summary.CostEstimate != nil {
+		AttachCostEstimates(out, r.Summary.CostEstimate.Rates)
+	}
+	if r.Metadata.GroupBy != "" {
+		out.Groups = rollupWorkloads(out, r.Metadata.GroupBy)
+	}
+	return out
+}
+
+// HasTeams reports whether any workload was assigned an owning team.
+func (r *RequestsSkewResult) HasTeams() bool {
+	return slices.ContainsFunc(r.Results, func(w WorkloadSkewAnalysis) bool { return w.Team != "" }) ||
+		slices.ContainsFunc(r.WorkloadsWithoutMetrics, func(w WorkloadWithoutMetrics) bool { return w.Team != "" })
+}
+
+func teamOrUnowned(team string) string {
+	if team == "" {
+		return owners.Unowned
+	}
+	return team
+}
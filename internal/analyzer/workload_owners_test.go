@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/owners"
+)
+
+func loadOwners(t *testing.T, content string) *owners.File {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "owners.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	f, err := owners.Load(file)
+	require.NoError(t, err)
+	return f
+}
+
+func TestRequestsSkew_OwnersGroupByTeam(t *testing.T) {
+	f := loadOwners(t, `
+owners:
+  - team: streaming
+    selector: team=streaming
+  - team: web-team
+    namespaces: [web]
+`)
+	result := rollupFixture(t, &RequestsSkewConfig{GroupBy: GroupByTeam, Owners: f})
+	names := map[string]int{}
+	for _, g := range result.Groups {
+		names[g.Name] = g.Workloads
+	}
+	// Owners win; workloads no rule matches fall back to the team label
+	assert.Equal(t, map[string]int{"streaming": 1, "web-team": 1, "platform": 1}, names)
+}
+
+func TestRequestsSkewResult_ForTeam(t *testing.T) {
+	result := &RequestsSkewResult{
+		Metadata: RequestsSkewMetadata{GroupBy: GroupByNamespace},
+		Results: []WorkloadSkewAnalysis{
+			{Namespace: "web", Workload: "api", RequestedCPU: 4, P95UsedCPU: 1, SkewCPU: 4, Team: "web"},
+			{Namespace: "data", Workload: "etl", RequestedCPU: 2, P95UsedCPU: 1, SkewCPU: 2},
+		},
+		WorkloadsWithoutMetrics: []WorkloadWithoutMetrics{
+			{Namespace: "web", Workload: "cron", Cause: NoMetricsCauseNotScraped, Team: "web"},
+		},
+		NamespaceMetrics: []NamespaceMetricsStatus{{Namespace: "web", HasMetrics: true}, {Namespace: "data", HasMetrics: true}},
+	}
+	AttachCostEstimates(result, cost.Rates{CPUPerCoreHour: 0.03})
+	assert.Equal(t, []string{"web", owners.Unowned}, result.Teams())
+
+	web := result.ForTeam("web")
+	require.Len(t, web.Results, 1)
+	assert.Equal(t, "api", web.Results[0].Workload)
+	assert.Len(t, web.WorkloadsWithoutMetrics, 1)
+	assert.Equal(t, map[string]int{NoMetricsCauseNotScraped: 1}, web.Summary.WithoutMetricsByCause)
+	assert.Equal(t, []NamespaceMetricsStatus{{Namespace: "web", HasMetrics: true}}, web.NamespaceMetrics)
+	assert.Equal(t, 1, web.Summary.AnalyzedWorkloads)
+	assert.InDelta(t, 3.0, web.Summary.TotalWastedCPU, 0.001)
+	require.NotNil(t, web.Summary.CostEstimate)
+	require.Len(t, web.Groups, 1)
+	assert.Equal(t, "web", web.Groups[0].Name)
+
+	unowned := result.ForTeam(owners.Unowned)
+	require.Len(t, unowned.Results, 1)
+	assert.Equal(t, "etl", unowned.Results[0].Workload)
+	assert.Empty(t, unowned.WorkloadsWithoutMetrics)
+}
+
+func TestRequestsSkewResult_Subset(t *testing.T) {
+	result := &RequestsSkewResult{
+		Results: []WorkloadSkewAnalysis{
+			{Namespace: "web", Workload: "api", RequestedCPU: 4, P95UsedCPU: 1, SkewCPU: 4},
+			{Namespace: "data", Workload: "etl", RequestedCPU: 2, P95UsedCPU: 1, SkewCPU: 2},
+		},
+		WorkloadsWithoutMetrics: []WorkloadWithoutMetrics{{Namespace: "data", Workload: "cron", Cause: NoMetricsCauseNotScraped}},
+		NamespaceQuotas:         []NamespaceQuotaInfo{{Namespace: "web", HasResourceQuota: true}, {Namespace: "data"}},
+	}
+	data := result.Subset(func(w *WorkloadSkewAnalysis) bool { return w.Namespace == "data" })
+	require.Len(t, data.Results, 1)
+	assert.Equal(t, "etl", data.Results[0].Workload)
+	assert.Empty(t, data.WorkloadsWithoutMetrics)
+	assert.Equal(t, []NamespaceQuotaInfo{{Namespace: "data"}}, data.NamespaceQuotas)
+	assert.InDelta(t, 1.0, data.Summary.TotalWastedCPU, 0.001)
+}
@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Rollup dimensions for --group-by.
+const (
+	GroupByRelease   = "release"
+	GroupByNamespace = "namespace"
+	GroupByTeam      = "team"
+)
+
+// GroupByOptions lists the supported rollup dimensions.
+var GroupByOptions = []string{GroupByRelease, GroupByNamespace, GroupByTeam}
+
+// DefaultTeamLabel is the label read for --group-by team.
+const DefaultTeamLabel = "team"
+
+// GroupUnassigned names the group of workloads without a release or team.
+const GroupUnassigned = "(none)"
+
+const (
+	// annotationHelmRelease is set by Helm 3 on every object it manages.
+	annotationHelmRelease = "meta.helm.sh/release-name"
+	// labelAppInstance is the release name by Kubernetes label convention.
+	labelAppInstance = "app.kubernetes.io/instance"
+)
+
+// WorkloadGroup totals the analyzed workloads sharing a release, namespace,
+// or team.
+type WorkloadGroup struct {
+	Name              string  `json:"name"`            // "<namespace>/<release>", namespace, or team
+	Chart             string  `json:"chart,omitempty"` // releases only
+	Workloads         int     `json:"workloads"`
+	RequestedCPU      float64 `json:"requested_cpu"`
+	P95UsedCPU        float64 `json:"p95_used_cpu"`
+	WastedCPU         float64 `json:"wasted_cpu"`
+	RequestedMemoryGi float64 `json:"requested_memory_gi"`
+	WastedMemoryGi    float64 `json:"wasted_memory_gi"`
+	AvgSkewCPU        float64 `json:"avg_skew_cpu"`
+	WastedMonthly     float64 `json:"wasted_monthly,omitempty"` // with cost estimates
+}
+
+// assignGroup records the owning team of a workload and the release or team
+// it rolls up into. With an owners file, --group-by team rolls up by owner.
+func (a *RequestsSkewAnalyzer) assignGroup(w *WorkloadSkewAnalysis, target *namespaceWorkload) {
+	w.Team = a.config.Owners.Team(w.Namespace, target.labels)
+	switch a.config.GroupBy {
+	case GroupByRelease:
+		if release := helmRelease(target); release != "" {
+			w.Group = w.Namespace + "/" + release
+			w.chart = target.labels[labelHelmChart]
+		}
+	case GroupByTeam:
+		w.Group = w.Team
+		if w.Group == "" {
+			w.Group = target.labels[a.config.TeamLabel]
+		}
+		if w.Group == "" {
+			w.Group = a.namespaceLabels[w.Namespace][a.config.TeamLabel]
+		}
+	}
+}
+
+// helmRelease returns the Helm release that deployed a workload, by the
+// annotation Helm sets, or the app.kubernetes.io/instance label.
+func helmRelease(target *namespaceWorkload) string {
+	if release := target.annotations[annotationHelmRelease]; release != "" {
+		return release
+	}
+	return target.labels[labelAppInstance]
+}
+
+// loadNamespaceLabels returns the labels of every namespace, so workloads
+// without a team label inherit their namespace's. A failed list is reported
+// and leaves only workload labels.
+func (a *RequestsSkewAnalyzer) loadNamespaceLabels(ctx context.Context) map[string]map[string]string {
+	list, err := a.kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		a.logWarning("Failed to list namespace labels for --group-by team", "error", err)
+		return nil
+	}
+	labels := make(map[string]map[string]string, len(list.Items))
+	for i := range list.Items {
+		labels[list.Items[i].Name] = list.Items[i].Labels
+	}
+	return labels
+}
+
+// rollupWorkloads totals the results per group, sorted by wasted CPU. A
+// template group's representative counts once per instance, in the
+// instance's namespace.
+func rollupWorkloads(result *RequestsSkewResult, groupBy string) []WorkloadGroup {
+	byName := map[string]*WorkloadGroup{}
+	for i := range result.Results {
+		w := &result.Results[i]
+		for _, ns := range w.instanceNamespaces() {
+			name := rollupName(w, ns, groupBy)
+			g, ok := byName[name]
+			if !ok {
+				g = &WorkloadGroup{Name: name}
+				byName[name] = g
+			}
+			if g.Chart == "" && groupBy == GroupByRelease {
+				g.Chart = w.chart
+			}
+			g.Workloads++
+			g.RequestedCPU += w.RequestedCPU
+			g.P95UsedCPU += w.P95UsedCPU
+			g.WastedCPU += max(w.RequestedCPU-w.P95UsedCPU, 0)
+			g.RequestedMemoryGi += w.RequestedMemoryGi
+			g.WastedMemoryGi += max(w.RequestedMemoryGi-w.P95UsedMemoryGi, 0)
+			g.AvgSkewCPU += w.SkewCPU
+			if w.CostEstimate != nil {
+				g.WastedMonthly += w.CostEstimate.WastedMonthly
+			}
+		}
+	}
+
+	groups := make([]WorkloadGroup, 0, len(byName))
+	for _, g := range byName {
+		g.AvgSkewCPU /= float64(g.Workloads)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].WastedCPU != groups[j].WastedCPU {
+			return groups[i].WastedCPU > groups[j].WastedCPU
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+func rollupName(w *WorkloadSkewAnalysis, namespace, groupBy string) string {
+	switch {
+	case groupBy == GroupByNamespace:
+		return namespace
+	case w.Group == "":
+		return GroupUnassigned
+	case groupBy == GroupByRelease:
+		// Template instances share the representative's release name
+		_, release, _ := strings.Cut(w.Group, "/")
+		return namespace + "/" + release
+	default:
+		return w.Group
+	}
+}
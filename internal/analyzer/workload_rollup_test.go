@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func rollupFixture(t *testing.T, config *RequestsSkewConfig) *RequestsSkewResult {
+	t.Helper()
+	day := 24 * time.Hour
+	kafka := chartDeployment("data", "kafka-broker", "kafka-26.0.0", "4", 30*day)
+	kafka.Annotations = map[string]string{annotationHelmRelease: "kafka"}
+	zk := chartDeployment("data", "kafka-zookeeper", "kafka-26.0.0", "4", 30*day)
+	zk.Labels[labelAppInstance] = "kafka"
+	zk.Labels["team"] = "streaming"
+	api := chartDeployment("web", "api", "", "4", 30*day)
+
+	client := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: map[string]string{"team": "platform"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+		kafka, zk, api,
+	)
+	provider := metrics.NewMockMetrics()
+	for _, key := range []string{"data/kafka-broker", "data/kafka-zookeeper", "web/api"} {
+		provider.WorkloadUsages[key] = &metrics.WorkloadUsage{
+			CPURequested: 4, CPUAvg: 0.5, CPUP95: 1,
+			MemoryRequested: 4 << 30, MemoryAvg: 1 << 30, MemoryP95: 2 << 30,
+		}
+	}
+
+	config.Silent, config.Top = true, 1
+	result, err := NewRequestsSkewAnalyzer(client, provider, config).Analyze(context.Background())
+	require.NoError(t, err)
+	return result
+}
+
+func TestRequestsSkew_GroupByRelease(t *testing.T) {
+	result := rollupFixture(t, &RequestsSkewConfig{GroupBy: GroupByRelease})
+	assert.Equal(t, GroupByRelease, result.Metadata.GroupBy)
+	require.Len(t, result.Results, 1, "top-N applies to results only")
+	require.Len(t, result.Groups, 2, "groups cover every analyzed workload")
+
+	assert.Equal(t, "data/kafka", result.Groups[0].Name)
+	assert.Equal(t, "kafka-26.0.0", result.Groups[0].Chart)
+	assert.Equal(t, 2, result.Groups[0].Workloads)
+	assert.InDelta(t, 6.0, result.Groups[0].WastedCPU, 0.001)
+	assert.InDelta(t, 4.0, result.Groups[0].WastedMemoryGi, 0.001)
+	assert.InDelta(t, 8.0, result.Groups[0].AvgSkewCPU, 0.001)
+
+	assert.Equal(t, GroupUnassigned, result.Groups[1].Name)
+	assert.Equal(t, 1, result.Groups[1].Workloads)
+}
+
+func TestRequestsSkew_GroupByTeam(t *testing.T) {
+	result := rollupFixture(t, &RequestsSkewConfig{GroupBy: GroupByTeam})
+	names := map[string]int{}
+	for _, g := range result.Groups {
+		names[g.Name] = g.Workloads
+	}
+	// The workload label wins over the namespace label
+	assert.Equal(t, map[string]int{"streaming": 1, "platform": 1, GroupUnassigned: 1}, names)
+}
+
+func TestRequestsSkew_NoGroupBy(t *testing.T) {
+	result := rollupFixture(t, &RequestsSkewConfig{})
+	assert.Empty(t, result.Groups)
+	assert.Empty(t, result.Results[0].Group)
+}
+
+func TestRollupWorkloads_Namespace(t *testing.T) {
+	result := &RequestsSkewResult{Results: []WorkloadSkewAnalysis{
+		{Namespace: "a", RequestedCPU: 2, P95UsedCPU: 1, SkewCPU: 2, Instances: []string{"a/api", "b/api"}},
+		{Namespace: "b", RequestedCPU: 1, P95UsedCPU: 2, SkewCPU: 0.5},
+	}}
+	groups := rollupWorkloads(result, GroupByNamespace)
+	require.Len(t, groups, 2)
+	// Equal waste: ordered by name; the template instance counts in its own namespace
+	assert.Equal(t, WorkloadGroup{Name: "a", Workloads: 1, RequestedCPU: 2, P95UsedCPU: 1, WastedCPU: 1, AvgSkewCPU: 2}, groups[0])
+	assert.Equal(t, WorkloadGroup{Name: "b", Workloads: 2, RequestedCPU: 3, P95UsedCPU: 3, WastedCPU: 1, AvgSkewCPU: 1.25}, groups[1])
+}
@@ -41,6 +41,8 @@ type BundleRecommendation struct {
 	Safety     string          `json:"safety"`
 	Confidence string          `json:"confidence"`
 	Evidence   *BundleEvidence `json:"evidence,omitempty"`
+	ComputedAt time.Time       `json:"computed_at,omitzero"` // when the applied values were computed
+	ValidUntil time.Time       `json:"valid_until,omitzero"` // end of the trend-derived validity window
 }
 
 // BundleEvidence holds the latch evidence summary.
@@ -101,6 +103,8 @@ type DecisionJSON struct {
 	Changes        []BundleChange `json:"changes"`
 	AppliedAt      string         `json:"applied_at,omitempty"`
 	Error          string         `json:"error,omitempty"`
+
+	Rollbacks []RollbackRecord `json:"rollbacks,omitempty"`
 }
 
 // DecisionRec is the recommendation section of decision.json.
@@ -108,6 +112,8 @@ type DecisionRec struct {
 	Safety     string          `json:"safety"`
 	Confidence string          `json:"confidence"`
 	Evidence   *BundleEvidence `json:"evidence,omitempty"`
+	ComputedAt string          `json:"computed_at,omitempty"` // RFC3339
+	ValidUntil string          `json:"valid_until,omitempty"` // RFC3339
 }
 
 // DecisionLatch is the latch section of decision.json.
@@ -259,6 +265,8 @@ func buildDecisionJSON(cfg *BundleConfig, status string) *DecisionJSON {
 			Safety:     cfg.Recommendation.Safety,
 			Confidence: cfg.Recommendation.Confidence,
 			Evidence:   cfg.Recommendation.Evidence,
+			ComputedAt: formatOptionalTime(cfg.Recommendation.ComputedAt),
+			ValidUntil: formatOptionalTime(cfg.Recommendation.ValidUntil),
 		},
 		Latch: DecisionLatch{
 			Duration:       cfg.Latch.Duration.String(),
@@ -270,6 +278,14 @@ func buildDecisionJSON(cfg *BundleConfig, status string) *DecisionJSON {
 	}
 }
 
+// formatOptionalTime formats t as RFC3339 UTC, or "" when unset.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 // stripVolatileFields removes ephemeral fields that change between reads.
 func stripVolatileFields(obj map[string]interface{}) {
 	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
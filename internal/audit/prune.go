@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/storage"
+)
+
+// PruneBundles removes audit bundle directories under auditPath that the
+// retention expires as of now, judged by the timestamp in the directory
+// name. It returns the pruned directories, newest first; with dryRun set
+// nothing is removed. Entries that are not bundles are left alone.
+func PruneBundles(auditPath string, r storage.Retention, now time.Time, dryRun bool) ([]string, error) {
+	entries, err := os.ReadDir(auditPath)
+	if err != nil {
+		return nil, fmt.Errorf("read audit directory %q: %w", auditPath, err)
+	}
+
+	var bundles []storage.Object
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ts, err := parseBundleTimestamp(entry.Name())
+		if err != nil {
+			continue
+		}
+		bundles = append(bundles, storage.Object{Key: entry.Name(), ModTime: ts})
+	}
+
+	var pruned []string
+	for _, b := range storage.Expired(bundles, r, now) {
+		dir := filepath.Join(auditPath, b.Key)
+		if !dryRun {
+			if err := os.RemoveAll(dir); err != nil {
+				return pruned, fmt.Errorf("remove audit bundle %q: %w", dir, err)
+			}
+		}
+		pruned = append(pruned, dir)
+	}
+	return pruned, nil
+}
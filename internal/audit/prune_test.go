@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/storage"
+)
+
+func TestPruneBundles(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"20260101T000000Z__default__deployment__old",
+		"20260201T000000Z__default__deployment__older-but-kept",
+		"20260301T000000Z__default__deployment__recent",
+		"not-a-bundle",
+	}
+	for _, n := range names {
+		if err := os.MkdirAll(filepath.Join(dir, n), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	r := storage.Retention{MaxAge: 30 * 24 * time.Hour, KeepLast: 2}
+
+	pruned, err := PruneBundles(dir, r, now, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(pruned) != 1 || filepath.Base(pruned[0]) != names[0] {
+		t.Fatalf("dry run pruned %v, want only %s", pruned, names[0])
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[0])); err != nil {
+		t.Fatalf("dry run removed a bundle: %v", err)
+	}
+
+	if _, err := PruneBundles(dir, r, now, false); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries after prune, want 3", len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[0])); !os.IsNotExist(err) {
+		t.Errorf("expired bundle still present")
+	}
+}
@@ -3,6 +3,7 @@ package audit
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
@@ -84,13 +85,13 @@ func CheckAndIncrement(cfg RateLimitConfig, workloadUID, workloadRef, user strin
 	// If we skipped checks (max=0), still record the entry in global
 	if cfg.MaxGlobal == 0 {
 		if err := recordEntry(globalPath, cfg.Window, entry); err != nil {
-			fmt.Fprintf(os.Stderr, "[kubenow] warning: failed to record global rate entry: %v\n", err)
+			slog.Warn("Failed to record global rate entry", "error", err)
 		}
 	}
 	if cfg.MaxPerWorkload == 0 && workloadUID != "" {
 		wlPath := filepath.Join(rateLimitDir, workloadUID+".json")
 		if err := recordEntry(wlPath, cfg.Window, entry); err != nil {
-			fmt.Fprintf(os.Stderr, "[kubenow] warning: failed to record workload rate entry: %v\n", err)
+			slog.Warn("Failed to record workload rate entry", "error", err)
 		}
 	}
 
@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rollback record statuses.
+const (
+	RollbackStatusRolledBack = "rolled-back"
+	RollbackStatusRefused    = "refused"
+	RollbackStatusFailed     = "failed"
+)
+
+// RollbackRecord is one rollback attempt of a bundle's apply, appended to
+// the rollbacks list in decision.json.
+type RollbackRecord struct {
+	Timestamp  string         `json:"timestamp"`
+	Status     string         `json:"status"`
+	Identity   *Identity      `json:"identity"`
+	Version    string         `json:"version"`
+	Forced     bool           `json:"forced,omitempty"`
+	Changes    []BundleChange `json:"changes,omitempty"`     // restored fields: live value → before value
+	Mismatches []BundleChange `json:"mismatches,omitempty"`  // read-back values that differ from before.yaml
+	ObjectFile string         `json:"object_file,omitempty"` // read-back object, relative to the bundle
+	Error      string         `json:"error,omitempty"`
+}
+
+// ResolveBundleDir finds a bundle from a directory path, or from a bundle
+// ID under auditPath: its directory name or a unique prefix of it, such as
+// the timestamp.
+func ResolveBundleDir(auditPath, ref string) (string, error) {
+	if isBundleDir(ref) {
+		return ref, nil
+	}
+	if auditPath == "" {
+		return "", fmt.Errorf("bundle %q not found: pass a bundle directory, or an ID with the audit path", ref)
+	}
+	if dir := filepath.Join(auditPath, ref); isBundleDir(dir) {
+		return dir, nil
+	}
+
+	entries, err := os.ReadDir(auditPath)
+	if err != nil {
+		return "", fmt.Errorf("read audit directory %q: %w", auditPath, err)
+	}
+	var matches []string
+	for _, e := range entries {
+		if e.IsDir() && strings.HasPrefix(e.Name(), ref) && isBundleDir(filepath.Join(auditPath, e.Name())) {
+			matches = append(matches, e.Name())
+		}
+	}
+	sort.Strings(matches)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no audit bundle %q under %s", ref, auditPath)
+	case 1:
+		return filepath.Join(auditPath, matches[0]), nil
+	default:
+		return "", fmt.Errorf("bundle ID %q is ambiguous: %s", ref, strings.Join(matches, ", "))
+	}
+}
+
+func isBundleDir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "decision.json"))
+	return err == nil && !info.IsDir()
+}
+
+// ReadDecision parses a bundle's decision.json.
+func ReadDecision(dir string) (*DecisionJSON, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "decision.json"))
+	if err != nil {
+		return nil, fmt.Errorf("read decision.json: %w", err)
+	}
+	var decision DecisionJSON
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return nil, fmt.Errorf("malformed decision.json: %w", err)
+	}
+	return &decision, nil
+}
+
+// ReadObject parses a recorded object (before.yaml or after.yaml).
+func ReadObject(dir, name string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+	return obj, nil
+}
+
+// AppendRollback writes the read-back object (if any) to the bundle as
+// rollback-<timestamp>.yaml and appends the record to decision.json.
+func AppendRollback(dir string, rec RollbackRecord, object map[string]interface{}, at time.Time) error {
+	if object != nil {
+		obj, err := deepCopyMap(object)
+		if err != nil {
+			return fmt.Errorf("copy rollback object: %w", err)
+		}
+		stripVolatileFields(obj)
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("marshal rollback YAML: %w", err)
+		}
+		rec.ObjectFile = fmt.Sprintf("rollback-%s.yaml", at.UTC().Format(bundleTimestampLayout))
+		if err := os.WriteFile(filepath.Join(dir, rec.ObjectFile), data, 0o600); err != nil {
+			return fmt.Errorf("write %s: %w", rec.ObjectFile, err)
+		}
+	}
+	rec.Timestamp = at.UTC().Format(time.RFC3339)
+
+	decision, err := ReadDecision(dir)
+	if err != nil {
+		return err
+	}
+	decision.Rollbacks = append(decision.Rollbacks, rec)
+	data, err := json.MarshalIndent(decision, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal updated decision.json: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, "decision.json"), data, 0o600)
+}
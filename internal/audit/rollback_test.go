@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAppliedBundle(t *testing.T, auditPath string, ts time.Time, name string) *AuditBundle {
+	t.Helper()
+	bundle, err := CreateBundle(&BundleConfig{
+		AuditPath: auditPath,
+		Timestamp: ts,
+		Workload:  BundleWorkload{Kind: "Deployment", Name: name, Namespace: "default"},
+		BeforeObject: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		},
+		Version: "0.2.0",
+	})
+	require.NoError(t, err)
+	after := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+	}
+	require.NoError(t, FinalizeBundle(bundle, after, "applied", ts.Add(5*time.Second), nil))
+	return bundle
+}
+
+func TestResolveBundleDir(t *testing.T) {
+	auditPath := t.TempDir()
+	first := writeAppliedBundle(t, auditPath, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), "api")
+	writeAppliedBundle(t, auditPath, time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC), "api")
+	require.NoError(t, os.Mkdir(filepath.Join(auditPath, "20260301T140000Z__not-a-bundle"), 0o755))
+
+	t.Run("directory path", func(t *testing.T) {
+		dir, err := ResolveBundleDir("", first.Dir)
+		require.NoError(t, err)
+		assert.Equal(t, first.Dir, dir)
+	})
+
+	t.Run("directory name", func(t *testing.T) {
+		dir, err := ResolveBundleDir(auditPath, filepath.Base(first.Dir))
+		require.NoError(t, err)
+		assert.Equal(t, first.Dir, dir)
+	})
+
+	t.Run("unique prefix", func(t *testing.T) {
+		dir, err := ResolveBundleDir(auditPath, "20260301T12")
+		require.NoError(t, err)
+		assert.Equal(t, first.Dir, dir)
+	})
+
+	t.Run("ambiguous prefix", func(t *testing.T) {
+		_, err := ResolveBundleDir(auditPath, "20260301T1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ambiguous")
+	})
+
+	t.Run("directories without decision.json are skipped", func(t *testing.T) {
+		_, err := ResolveBundleDir(auditPath, "20260301T14")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no audit bundle")
+	})
+
+	t.Run("ID without audit path", func(t *testing.T) {
+		_, err := ResolveBundleDir("", "20260301T12")
+		require.Error(t, err)
+	})
+}
+
+func TestReadObject(t *testing.T) {
+	bundle := writeAppliedBundle(t, t.TempDir(), time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), "api")
+
+	obj, err := ReadObject(bundle.Dir, "before.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", obj["kind"])
+
+	_, err = ReadObject(bundle.Dir, "missing.yaml")
+	require.Error(t, err)
+}
+
+func TestAppendRollback(t *testing.T) {
+	bundle := writeAppliedBundle(t, t.TempDir(), time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), "api")
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	readBack := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "api",
+			"namespace":       "default",
+			"resourceVersion": "99",
+			"managedFields":   []interface{}{"kubenow"},
+		},
+		"status": map[string]interface{}{"replicas": float64(2)},
+	}
+	rec := RollbackRecord{
+		Status:  RollbackStatusRolledBack,
+		Version: "0.3.0",
+		Changes: []BundleChange{{Field: "spec.template.spec.containers[api].resources.requests.cpu", Before: "150m", After: "100m"}},
+	}
+	require.NoError(t, AppendRollback(bundle.Dir, rec, readBack, at))
+	require.NoError(t, AppendRollback(bundle.Dir, RollbackRecord{Status: RollbackStatusRefused, Error: "changed"}, nil, at.Add(time.Hour)))
+
+	decision, err := ReadDecision(bundle.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, "applied", decision.Status, "the original decision is kept")
+	require.Len(t, decision.Rollbacks, 2)
+
+	first := decision.Rollbacks[0]
+	assert.Equal(t, RollbackStatusRolledBack, first.Status)
+	assert.Equal(t, "2026-03-02T09:00:00Z", first.Timestamp)
+	assert.Equal(t, "rollback-20260302T090000Z.yaml", first.ObjectFile)
+	assert.Len(t, first.Changes, 1)
+
+	second := decision.Rollbacks[1]
+	assert.Equal(t, RollbackStatusRefused, second.Status)
+	assert.Empty(t, second.ObjectFile)
+	assert.Equal(t, "changed", second.Error)
+
+	obj, err := ReadObject(bundle.Dir, first.ObjectFile)
+	require.NoError(t, err)
+	meta := obj["metadata"].(map[string]interface{})
+	assert.NotContains(t, meta, "resourceVersion")
+	assert.NotContains(t, meta, "managedFields")
+	assert.NotContains(t, obj, "status")
+	assert.Contains(t, readBack["metadata"], "resourceVersion", "the caller's object is not modified")
+}
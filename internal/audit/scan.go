@@ -8,6 +8,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/ppiankov/kubenow/internal/logging"
 )
 
 // ScannedBundle pairs a parsed decision record with its directory path.
@@ -47,7 +49,7 @@ func ScanBundles(cfg ScanConfig) ([]ScannedBundle, error) {
 		// Parse timestamp from directory name
 		ts, tsErr := parseBundleTimestamp(dirName)
 		if tsErr != nil {
-			fmt.Fprintf(os.Stderr, "[scan] skipping %q: %v\n", dirName, tsErr)
+			logging.Component("scan").Warn("Skipping bundle", "bundle", dirName, "error", tsErr)
 			continue
 		}
 
@@ -63,13 +65,13 @@ func ScanBundles(cfg ScanConfig) ([]ScannedBundle, error) {
 		decisionPath := filepath.Join(dirPath, "decision.json")
 		data, readErr := os.ReadFile(decisionPath)
 		if readErr != nil {
-			fmt.Fprintf(os.Stderr, "[scan] skipping %q: %v\n", dirName, readErr)
+			logging.Component("scan").Warn("Skipping bundle", "bundle", dirName, "error", readErr)
 			continue
 		}
 
 		var decision DecisionJSON
 		if jsonErr := json.Unmarshal(data, &decision); jsonErr != nil {
-			fmt.Fprintf(os.Stderr, "[scan] skipping %q: malformed decision.json: %v\n", dirName, jsonErr)
+			logging.Component("scan").Warn("Skipping bundle: malformed decision.json", "bundle", dirName, "error", jsonErr)
 			continue
 		}
 
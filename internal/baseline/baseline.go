@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/cleanup"
 )
 
 // Baseline represents a saved snapshot of analysis results
@@ -67,7 +68,7 @@ func SaveBaseline(result *analyzer.RequestsSkewResult, filepath, version string)
 		return fmt.Errorf("failed to marshal baseline: %w", err)
 	}
 
-	if err := os.WriteFile(filepath, data, 0o644); err != nil {
+	if err := cleanup.WriteFile(filepath, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write baseline file: %w", err)
 	}
 
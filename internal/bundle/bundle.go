@@ -0,0 +1,231 @@
+// Package bundle packages incident evidence (snapshot, events, logs,
+// manifests, and analysis reports) into one tar.gz archive with an
+// index.html, for attaching to tickets and sharing with vendors.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"time"
+)
+
+// Index sections, in the order the index lists them.
+const (
+	SectionReport    = "Report"
+	SectionSnapshot  = "Snapshot"
+	SectionEvents    = "Events"
+	SectionManifests = "Manifests"
+	SectionLogs      = "Logs"
+)
+
+var sectionOrder = []string{SectionReport, SectionSnapshot, SectionEvents, SectionManifests, SectionLogs}
+
+// Meta describes a bundle on its index page.
+type Meta struct {
+	Cluster        string
+	Namespace      string // "" = all namespaces
+	KubenowVersion string
+	GeneratedAt    time.Time
+	Redaction      string   // what was masked, e.g. "3 tokens, 1 email"
+	Summary        []string // highlights shown above the file list
+	Errors         []string // evidence that could not be collected
+}
+
+// File is one archived file, as listed on the index page.
+type File struct {
+	Section string
+	Path    string // relative to the bundle root
+	Title   string
+	Size    int
+}
+
+// Writer streams files into a tar.gz archive under a single root
+// directory and writes index.html on Close.
+type Writer struct {
+	gz    *gzip.Writer
+	tw    *tar.Writer
+	root  string
+	meta  Meta
+	files []File
+}
+
+// RootName is the bundle's root directory, e.g.
+// kubenow-bundle-shop-20260301-120000.
+func RootName(namespace string, t time.Time) string {
+	if namespace == "" {
+		namespace = "all"
+	}
+	return "kubenow-bundle-" + namespace + "-" + t.UTC().Format("20060102-150405")
+}
+
+// NewWriter starts a bundle written to w.
+func NewWriter(w io.Writer, meta Meta) *Writer {
+	if meta.GeneratedAt.IsZero() {
+		meta.GeneratedAt = time.Now()
+	}
+	gz := gzip.NewWriter(w)
+	return &Writer{gz: gz, tw: tar.NewWriter(gz), root: RootName(meta.Namespace, meta.GeneratedAt), meta: meta}
+}
+
+// Add archives data at name, relative to the bundle root, and lists it
+// in section of the index.
+func (w *Writer) Add(section, name, title string, data []byte) error {
+	if err := w.write(name, data); err != nil {
+		return err
+	}
+	w.files = append(w.files, File{Section: section, Path: name, Title: title, Size: len(data)})
+	return nil
+}
+
+// AddJSON archives v as indented JSON.
+func (w *Writer) AddJSON(section, name, title string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return w.Add(section, name, title, append(data, '\n'))
+}
+
+// Files lists the archived files in the order they were added.
+func (w *Writer) Files() []File {
+	return w.files
+}
+
+// Close writes index.html and finishes the archive. It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	var index bytes.Buffer
+	if err := indexTemplate.Execute(&index, w.indexData()); err != nil {
+		return fmt.Errorf("failed to render bundle index: %w", err)
+	}
+	if err := w.write("index.html", index.Bytes()); err != nil {
+		return err
+	}
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle archive: %w", err)
+	}
+	if err := w.gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle compression: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) write(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    path.Join(w.root, name),
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: w.meta.GeneratedAt,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	return nil
+}
+
+type indexSection struct {
+	Name  string
+	Files []File
+}
+
+func (w *Writer) indexData() any {
+	var sections []indexSection
+	for _, name := range sectionOrder {
+		s := indexSection{Name: name}
+		for _, f := range w.files {
+			if f.Section == name {
+				s.Files = append(s.Files, f)
+			}
+		}
+		if len(s.Files) > 0 {
+			sections = append(sections, s)
+		}
+	}
+	return struct {
+		Meta     Meta
+		Sections []indexSection
+	}{w.meta, sections}
+}
+
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"size": formatSize,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>kubenow incident bundle{{with .Meta.Namespace}} - {{.}}{{end}}</title>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; max-width: 1200px; margin: 40px auto; padding: 20px; }
+        h1 { color: #1976d2; }
+        .metadata { background: #f5f5f5; padding: 15px; border-left: 4px solid #1976d2; margin-bottom: 20px; }
+        .errors { background: #fff3e0; padding: 15px; border-left: 4px solid #ef6c00; margin-bottom: 20px; }
+        table { border-collapse: collapse; width: 100%; margin-bottom: 24px; font-size: 14px; }
+        th, td { border: 1px solid #e0e0e0; padding: 6px 10px; text-align: left; }
+        th { background: #fafafa; }
+        td.num { text-align: right; font-variant-numeric: tabular-nums; }
+    </style>
+</head>
+<body>
+    <h1>kubenow incident bundle</h1>
+    <div class="metadata">
+        <p><strong>Generated:</strong> {{.Meta.GeneratedAt.UTC.Format "2006-01-02 15:04:05 UTC"}}</p>
+        {{- with .Meta.Cluster}}
+        <p><strong>Cluster:</strong> {{.}}</p>
+        {{- end}}
+        <p><strong>Namespace:</strong> {{with .Meta.Namespace}}{{.}}{{else}}all namespaces{{end}}</p>
+        <p><strong>Redaction:</strong> {{with .Meta.Redaction}}{{.}}{{else}}nothing masked{{end}}</p>
+        <p><strong>Version:</strong> {{.Meta.KubenowVersion}}</p>
+    </div>
+    {{- with .Meta.Summary}}
+    <ul>
+        {{- range .}}
+        <li>{{.}}</li>
+        {{- end}}
+    </ul>
+    {{- end}}
+    {{- with .Meta.Errors}}
+    <div class="errors">
+        <p><strong>Not collected:</strong></p>
+        <ul>
+            {{- range .}}
+            <li>{{.}}</li>
+            {{- end}}
+        </ul>
+    </div>
+    {{- end}}
+    {{- range .Sections}}
+    <h2>{{.Name}}</h2>
+    <table>
+        <thead><tr><th>File</th><th>Contents</th><th>Size</th></tr></thead>
+        <tbody>
+        {{- range .Files}}
+            <tr><td><a href="{{.Path}}">{{.Path}}</a></td><td>{{.Title}}</td><td class="num">{{size .Size}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    {{- end}}
+    <hr>
+    <p><em>Generated by <a href="https://github.com/ppiankov/kubenow">kubenow</a></em></p>
+</body>
+</html>
+`))
+
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,75 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBundle returns the archive's files by name.
+func readBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(body)
+	}
+	return files
+}
+
+func TestWriter(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Meta{
+		Cluster: "prod", Namespace: "shop", KubenowVersion: "1.0.0", GeneratedAt: at,
+		Redaction: "2 tokens", Summary: []string{"3 problem pods"}, Errors: []string{"pod shop/gone: not found"},
+	})
+	require.NoError(t, w.Add(SectionLogs, "logs/shop/api-1/app.log", "container app", []byte("hello\n")))
+	require.NoError(t, w.AddJSON(SectionSnapshot, "snapshot.json", "cluster snapshot", map[string]int{"pods": 3}))
+	require.NoError(t, w.Add(SectionReport, "report.html", "<LLM> report", []byte("<html></html>")))
+	require.NoError(t, w.Close())
+	assert.Len(t, w.Files(), 3)
+
+	files := readBundle(t, buf.Bytes())
+	root := "kubenow-bundle-shop-20260301-120000/"
+	assert.Equal(t, "hello\n", files[root+"logs/shop/api-1/app.log"])
+	assert.JSONEq(t, `{"pods": 3}`, files[root+"snapshot.json"])
+
+	index := files[root+"index.html"]
+	require.NotEmpty(t, index)
+	assert.Contains(t, index, "<strong>Cluster:</strong> prod")
+	assert.Contains(t, index, "<strong>Redaction:</strong> 2 tokens")
+	assert.Contains(t, index, "<li>3 problem pods</li>")
+	assert.Contains(t, index, "<li>pod shop/gone: not found</li>")
+	assert.Contains(t, index, `<a href="logs/shop/api-1/app.log">`)
+	assert.Contains(t, index, "&lt;LLM&gt; report")
+	// Sections follow sectionOrder, not insertion order.
+	assert.Less(t, bytes.Index([]byte(index), []byte("<h2>Report</h2>")), bytes.Index([]byte(index), []byte("<h2>Logs</h2>")))
+}
+
+func TestRootName(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "kubenow-bundle-all-20260301-120000", RootName("", at))
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "1.5 KiB", formatSize(1536))
+	assert.Equal(t, "2.0 MiB", formatSize(2<<20))
+}
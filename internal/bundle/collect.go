@@ -0,0 +1,188 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTailLines is how many log lines are kept per container.
+const DefaultTailLines = 500
+
+// lastAppliedAnnotation holds a copy of the applied manifest; it is
+// dropped because it repeats the spec, including any literal env values.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// PodRef names a pod whose evidence is collected.
+type PodRef struct {
+	Namespace string
+	Name      string
+}
+
+// CollectOptions controls evidence collection.
+type CollectOptions struct {
+	TailLines int64               // per container (0 = DefaultTailLines)
+	Redact    func(string) string // masks secrets in logs and manifests; nil = unchanged
+}
+
+// Evidence is the collected logs and manifests, as files relative to the
+// bundle root.
+type Evidence struct {
+	Manifests []File
+	Logs      []File
+	Data      map[string][]byte // path -> contents
+	Errors    []string
+}
+
+// Collect fetches the manifests of the pods and their owning workloads
+// (ReplicaSet, Deployment, StatefulSet, DaemonSet, Job, CronJob) and the
+// current and, after a restart, previous logs of each container. It is
+// best-effort: what cannot be fetched is listed in Evidence.Errors.
+// Secrets are never read; managedFields and the last-applied annotation
+// are stripped from manifests.
+func Collect(ctx context.Context, client kubernetes.Interface, pods []PodRef, opts CollectOptions) *Evidence {
+	tail := opts.TailLines
+	if tail <= 0 {
+		tail = DefaultTailLines
+	}
+	redact := opts.Redact
+	if redact == nil {
+		redact = func(s string) string { return s }
+	}
+	ev := &Evidence{Data: make(map[string][]byte)}
+	seen := make(map[string]bool)
+	addManifest := func(obj runtime.Object, meta metav1.Object, kind string) {
+		name := path.Join("manifests", meta.GetNamespace(), strings.ToLower(kind)+"-"+meta.GetName()+".yaml")
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		data, err := manifestYAML(obj, meta, kind)
+		if err != nil {
+			ev.Errors = append(ev.Errors, err.Error())
+			return
+		}
+		ev.Data[name] = []byte(redact(string(data)))
+		ev.Manifests = append(ev.Manifests, File{Section: SectionManifests, Path: name, Title: kind + " " + meta.GetName()})
+	}
+
+	for _, ref := range pods {
+		pod, err := client.CoreV1().Pods(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			ev.Errors = append(ev.Errors, fmt.Sprintf("pod %s/%s: %v", ref.Namespace, ref.Name, err))
+			continue
+		}
+		addManifest(pod, pod, "Pod")
+		collectOwners(ctx, client, pod, addManifest, ev)
+
+		restarted := make(map[string]bool)
+		for _, cs := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+			restarted[cs.Name] = cs.RestartCount > 0
+		}
+		for _, c := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			collectLog(ctx, client, pod, c.Name, false, tail, redact, ev)
+			if restarted[c.Name] {
+				collectLog(ctx, client, pod, c.Name, true, tail, redact, ev)
+			}
+		}
+	}
+	return ev
+}
+
+// collectOwners adds the manifests of the pod's controller chain.
+func collectOwners(
+	ctx context.Context, client kubernetes.Interface, pod *corev1.Pod,
+	add func(runtime.Object, metav1.Object, string), ev *Evidence,
+) {
+	ns := pod.Namespace
+	owner := metav1.GetControllerOf(pod)
+	for owner != nil {
+		var (
+			obj  runtime.Object
+			meta metav1.Object
+			err  error
+		)
+		switch owner.Kind {
+		case "ReplicaSet":
+			rs, getErr := client.AppsV1().ReplicaSets(ns).Get(ctx, owner.Name, metav1.GetOptions{})
+			obj, meta, err = rs, rs, getErr
+		case "Deployment":
+			dep, getErr := client.AppsV1().Deployments(ns).Get(ctx, owner.Name, metav1.GetOptions{})
+			obj, meta, err = dep, dep, getErr
+		case "StatefulSet":
+			sts, getErr := client.AppsV1().StatefulSets(ns).Get(ctx, owner.Name, metav1.GetOptions{})
+			obj, meta, err = sts, sts, getErr
+		case "DaemonSet":
+			ds, getErr := client.AppsV1().DaemonSets(ns).Get(ctx, owner.Name, metav1.GetOptions{})
+			obj, meta, err = ds, ds, getErr
+		case "Job":
+			job, getErr := client.BatchV1().Jobs(ns).Get(ctx, owner.Name, metav1.GetOptions{})
+			obj, meta, err = job, job, getErr
+		case "CronJob":
+			cj, getErr := client.BatchV1().CronJobs(ns).Get(ctx, owner.Name, metav1.GetOptions{})
+			obj, meta, err = cj, cj, getErr
+		default:
+			return
+		}
+		if err != nil {
+			ev.Errors = append(ev.Errors, fmt.Sprintf("%s %s/%s: %v", owner.Kind, ns, owner.Name, err))
+			return
+		}
+		add(obj, meta, owner.Kind)
+		owner = metav1.GetControllerOf(meta)
+	}
+}
+
+// manifestYAML renders obj without server-managed noise.
+func manifestYAML(obj runtime.Object, meta metav1.Object, kind string) ([]byte, error) {
+	meta.SetManagedFields(nil)
+	if annotations := meta.GetAnnotations(); annotations[lastAppliedAnnotation] != "" {
+		delete(annotations, lastAppliedAnnotation)
+		meta.SetAnnotations(annotations)
+	}
+	group := "apps/v1"
+	switch kind {
+	case "Pod":
+		group = "v1"
+	case "Job", "CronJob":
+		group = "batch/v1"
+	}
+	obj.GetObjectKind().SetGroupVersionKind(schema.FromAPIVersionAndKind(group, kind))
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s/%s: failed to render manifest: %w", kind, meta.GetNamespace(), meta.GetName(), err)
+	}
+	return data, nil
+}
+
+func collectLog(
+	ctx context.Context, client kubernetes.Interface, pod *corev1.Pod, container string, previous bool,
+	tail int64, redact func(string) string, ev *Evidence,
+) {
+	name := path.Join("logs", pod.Namespace, pod.Name, container+".log")
+	title := "container " + container
+	if previous {
+		name = path.Join("logs", pod.Namespace, pod.Name, container+".previous.log")
+		title += ", before the last restart"
+	}
+	raw, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tail,
+		Previous:  previous,
+	}).DoRaw(ctx)
+	if err != nil {
+		ev.Errors = append(ev.Errors, fmt.Sprintf("%s: %v", name, err))
+		return
+	}
+	ev.Data[name] = []byte(redact(string(raw)))
+	ev.Logs = append(ev.Logs, File{Section: SectionLogs, Path: name, Title: title})
+}
@@ -0,0 +1,78 @@
+package bundle
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollect(t *testing.T) {
+	controller := true
+	client := fake.NewClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name: "api", Namespace: "shop",
+			Annotations:   map[string]string{lastAppliedAnnotation: `{"spec":{}}`, "team": "payments"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "api-6b8c", Namespace: "shop",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "api", Controller: &controller}},
+		}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "api-6b8c-aaaaa", Namespace: "shop",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-6b8c", Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Env: []corev1.EnvVar{{Name: "TOKEN", Value: "secret-value"}}},
+				{Name: "sidecar"},
+			}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", RestartCount: 2},
+				{Name: "sidecar"},
+			}},
+		},
+	)
+
+	ev := Collect(context.Background(), client, []PodRef{{Namespace: "shop", Name: "api-6b8c-aaaaa"}, {Namespace: "shop", Name: "gone"}},
+		CollectOptions{Redact: func(s string) string { return strings.ReplaceAll(s, "secret-value", "[REDACTED]") }})
+
+	var manifests, logs []string
+	for _, f := range ev.Manifests {
+		manifests = append(manifests, f.Path)
+	}
+	for _, f := range ev.Logs {
+		logs = append(logs, f.Path)
+	}
+	assert.Equal(t, []string{
+		"manifests/shop/pod-api-6b8c-aaaaa.yaml",
+		"manifests/shop/replicaset-api-6b8c.yaml",
+		"manifests/shop/deployment-api.yaml",
+	}, manifests)
+	assert.Equal(t, []string{
+		"logs/shop/api-6b8c-aaaaa/app.log",
+		"logs/shop/api-6b8c-aaaaa/app.previous.log",
+		"logs/shop/api-6b8c-aaaaa/sidecar.log",
+	}, logs)
+
+	pod := string(ev.Data["manifests/shop/pod-api-6b8c-aaaaa.yaml"])
+	assert.Contains(t, pod, "apiVersion: v1\nkind: Pod\n")
+	assert.Contains(t, pod, "[REDACTED]")
+	assert.NotContains(t, pod, "secret-value")
+
+	dep := string(ev.Data["manifests/shop/deployment-api.yaml"])
+	assert.Contains(t, dep, "apiVersion: apps/v1\nkind: Deployment\n")
+	assert.Contains(t, dep, "team: payments")
+	assert.NotContains(t, dep, "last-applied-configuration")
+	assert.NotContains(t, dep, "managedFields")
+
+	require.Len(t, ev.Errors, 1)
+	assert.Contains(t, ev.Errors[0], "pod shop/gone")
+}
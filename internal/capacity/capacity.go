@@ -0,0 +1,372 @@
+// Package capacity condenses a requests-skew report and the cluster's node
+// pools into the input of the capacity-planning prompt: totals, headroom,
+// per-namespace rollups, and the workloads that matter most, instead of raw
+// pods.
+package capacity
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/models"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+const (
+	maxNamespaces       = 20
+	maxOverProvisioned  = 25
+	maxUnderProvisioned = 15
+	maxNodePools        = 20
+)
+
+// poolLabels name a node's pool, most specific first. Nodes without any of
+// them are grouped by instance type.
+var poolLabels = []string{
+	"karpenter.sh/nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"cloud.google.com/gke-nodepool",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+}
+
+var instanceTypeLabels = []string{
+	"node.kubernetes.io/instance-type",
+	"beta.kubernetes.io/instance-type",
+}
+
+// Input is what the capacity prompt sees.
+type Input struct {
+	Cluster          string           `json:"cluster,omitempty"`
+	Window           string           `json:"window"`
+	AnalyzedAt       time.Time        `json:"analyzedAt"`
+	Totals           Totals           `json:"totals"`
+	Headroom         *Headroom        `json:"headroom,omitempty"` // nil without node pools
+	NodePools        []NodePool       `json:"nodePools,omitempty"`
+	Namespaces       []NamespaceUsage `json:"namespaces,omitempty"`
+	OverProvisioned  []WorkloadUsage  `json:"overProvisioned,omitempty"`
+	UnderProvisioned []WorkloadUsage  `json:"underProvisioned,omitempty"`
+}
+
+// Totals sums the analyzed workloads. Usage is the p95 over the window.
+type Totals struct {
+	Workloads         int      `json:"workloads"`
+	WithoutMetrics    int      `json:"withoutMetrics,omitempty"`
+	RequestedCPU      float64  `json:"requestedCpu"`
+	UsedCPU           float64  `json:"p95Cpu"`
+	RequestedMemoryGi float64  `json:"requestedMemoryGi"`
+	UsedMemoryGi      float64  `json:"p95MemoryGi"`
+	WastedCPU         float64  `json:"wastedCpu"`
+	WastedMemoryGi    float64  `json:"wastedMemoryGi"`
+	AvgSkewCPU        float64  `json:"avgSkewCpu"`
+	AvgSkewMemory     float64  `json:"avgSkewMemory"`
+	MonthlyCost       *float64 `json:"monthlyCostUsd,omitempty"`
+	MonthlyWaste      *float64 `json:"monthlyWasteUsd,omitempty"`
+}
+
+// Headroom compares the node pools' allocatable capacity with what pods
+// request and what the analyzed workloads use at p95.
+type Headroom struct {
+	AllocatableCPU         float64 `json:"allocatableCpu"`
+	AllocatableMemoryGi    float64 `json:"allocatableMemoryGi"`
+	RequestedCPUPercent    int     `json:"requestedCpuPercent"`
+	RequestedMemoryPercent int     `json:"requestedMemoryPercent"`
+	UsedCPUPercent         int     `json:"p95CpuPercent"`
+	UsedMemoryPercent      int     `json:"p95MemoryPercent"`
+}
+
+// NodePool is a group of nodes sharing a pool label or instance type.
+type NodePool struct {
+	Name                   string  `json:"name"`
+	InstanceType           string  `json:"instanceType,omitempty"`
+	Nodes                  int     `json:"nodes"`
+	AllocatableCPU         float64 `json:"allocatableCpu"`
+	AllocatableMemoryGi    float64 `json:"allocatableMemoryGi"`
+	RequestedCPU           float64 `json:"requestedCpu"`
+	RequestedMemoryGi      float64 `json:"requestedMemoryGi"`
+	RequestedCPUPercent    int     `json:"requestedCpuPercent"`
+	RequestedMemoryPercent int     `json:"requestedMemoryPercent"`
+}
+
+// NamespaceUsage rolls up a namespace's analyzed workloads.
+type NamespaceUsage struct {
+	Namespace         string   `json:"namespace"`
+	Workloads         int      `json:"workloads"`
+	RequestedCPU      float64  `json:"requestedCpu"`
+	UsedCPU           float64  `json:"p95Cpu"`
+	RequestedMemoryGi float64  `json:"requestedMemoryGi"`
+	UsedMemoryGi      float64  `json:"p95MemoryGi"`
+	MonthlyWaste      *float64 `json:"monthlyWasteUsd,omitempty"`
+}
+
+// WorkloadUsage is one workload's requests against its p95 usage.
+type WorkloadUsage struct {
+	Namespace         string  `json:"namespace"`
+	Workload          string  `json:"workload"` // "Kind/name"
+	RequestedCPU      float64 `json:"requestedCpu"`
+	UsedCPU           float64 `json:"p95Cpu"`
+	RequestedMemoryGi float64 `json:"requestedMemoryGi"`
+	UsedMemoryGi      float64 `json:"p95MemoryGi"`
+	SkewCPU           float64 `json:"skewCpu"`
+	SkewMemory        float64 `json:"skewMemory"`
+	Safety            string  `json:"safety,omitempty"` // SAFE|CAUTION|RISKY|UNSAFE
+	OOMKills          int     `json:"oomKills,omitempty"`
+}
+
+// LoadSkewReport reads a requests-skew JSON report
+// (kubenow analyze requests-skew --output json).
+func LoadSkewReport(path string) (*analyzer.RequestsSkewResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skew report: %w", err)
+	}
+	var r analyzer.RequestsSkewResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse skew report %s: %w", path, err)
+	}
+	if r.Metadata.GeneratedAt.IsZero() && len(r.Results) == 0 {
+		return nil, fmt.Errorf("%s is not a requests-skew JSON report", path)
+	}
+	return &r, nil
+}
+
+// Build condenses a skew report and the cluster's node pools (nil when the
+// cluster was not read) into the capacity prompt input.
+func Build(skew *analyzer.RequestsSkewResult, pools []NodePool) *Input {
+	in := &Input{
+		Cluster:    skew.Metadata.Cluster,
+		Window:     skew.Metadata.Window,
+		AnalyzedAt: skew.Metadata.GeneratedAt,
+		NodePools:  pools,
+	}
+
+	in.Totals = Totals{
+		Workloads:      skew.Summary.AnalyzedWorkloads,
+		WithoutMetrics: len(skew.WorkloadsWithoutMetrics),
+		WastedCPU:      round(skew.Summary.TotalWastedCPU),
+		WastedMemoryGi: round(skew.Summary.TotalWastedMemoryGi),
+		AvgSkewCPU:     round(skew.Summary.AvgSkewCPU),
+		AvgSkewMemory:  round(skew.Summary.AvgSkewMemory),
+	}
+	if c := skew.Summary.CostEstimate; c != nil {
+		in.Totals.MonthlyCost = ptr(round(c.TotalCurrentMonthly))
+		in.Totals.MonthlyWaste = ptr(round(c.TotalWastedMonthly))
+	}
+
+	namespaces := map[string]*NamespaceUsage{}
+	var over, under []WorkloadUsage
+	for i := range skew.Results {
+		w := &skew.Results[i]
+		in.Totals.RequestedCPU += w.RequestedCPU
+		in.Totals.UsedCPU += w.P95UsedCPU
+		in.Totals.RequestedMemoryGi += w.RequestedMemoryGi
+		in.Totals.UsedMemoryGi += w.P95UsedMemoryGi
+
+		ns := namespaces[w.Namespace]
+		if ns == nil {
+			ns = &NamespaceUsage{Namespace: w.Namespace}
+			namespaces[w.Namespace] = ns
+		}
+		ns.Workloads++
+		ns.RequestedCPU += w.RequestedCPU
+		ns.UsedCPU += w.P95UsedCPU
+		ns.RequestedMemoryGi += w.RequestedMemoryGi
+		ns.UsedMemoryGi += w.P95UsedMemoryGi
+
+		u := workloadUsage(w)
+		if underProvisioned(w) {
+			under = append(under, u)
+		} else if w.SkewCPU > 1 || w.SkewMemory > 1 {
+			over = append(over, u)
+		}
+	}
+	in.Totals.RequestedCPU = round(in.Totals.RequestedCPU)
+	in.Totals.UsedCPU = round(in.Totals.UsedCPU)
+	in.Totals.RequestedMemoryGi = round(in.Totals.RequestedMemoryGi)
+	in.Totals.UsedMemoryGi = round(in.Totals.UsedMemoryGi)
+
+	for _, c := range skew.NamespaceCosts {
+		if ns := namespaces[c.Namespace]; ns != nil {
+			ns.MonthlyWaste = ptr(round(c.WastedMonthly))
+		}
+	}
+	for _, ns := range namespaces {
+		ns.RequestedCPU, ns.UsedCPU = round(ns.RequestedCPU), round(ns.UsedCPU)
+		ns.RequestedMemoryGi, ns.UsedMemoryGi = round(ns.RequestedMemoryGi), round(ns.UsedMemoryGi)
+		in.Namespaces = append(in.Namespaces, *ns)
+	}
+	// Most idle CPU first
+	sort.Slice(in.Namespaces, func(i, j int) bool {
+		a, b := in.Namespaces[i], in.Namespaces[j]
+		if idleA, idleB := a.RequestedCPU-a.UsedCPU, b.RequestedCPU-b.UsedCPU; idleA != idleB {
+			return idleA > idleB
+		}
+		return a.Namespace < b.Namespace
+	})
+	in.Namespaces = capped(in.Namespaces, maxNamespaces)
+
+	sort.SliceStable(over, func(i, j int) bool {
+		return over[i].RequestedCPU-over[i].UsedCPU > over[j].RequestedCPU-over[j].UsedCPU
+	})
+	in.OverProvisioned = capped(over, maxOverProvisioned)
+	in.UnderProvisioned = capped(under, maxUnderProvisioned)
+
+	if len(pools) > 0 {
+		in.Headroom = headroom(pools, in.Totals)
+	}
+	return in
+}
+
+// underProvisioned reports whether a workload uses more than it requests at
+// p95, or is unsafe to shrink.
+func underProvisioned(w *analyzer.WorkloadSkewAnalysis) bool {
+	if w.RequestedCPU > 0 && w.P95UsedCPU > w.RequestedCPU {
+		return true
+	}
+	if w.RequestedMemoryGi > 0 && w.P95UsedMemoryGi > w.RequestedMemoryGi {
+		return true
+	}
+	return w.Safety != nil && (w.Safety.Rating == models.SafetyRatingRisky || w.Safety.Rating == models.SafetyRatingUnsafe)
+}
+
+func workloadUsage(w *analyzer.WorkloadSkewAnalysis) WorkloadUsage {
+	u := WorkloadUsage{
+		Namespace:         w.Namespace,
+		Workload:          w.Type + "/" + w.Workload,
+		RequestedCPU:      round(w.RequestedCPU),
+		UsedCPU:           round(w.P95UsedCPU),
+		RequestedMemoryGi: round(w.RequestedMemoryGi),
+		UsedMemoryGi:      round(w.P95UsedMemoryGi),
+		SkewCPU:           round(w.SkewCPU),
+		SkewMemory:        round(w.SkewMemory),
+	}
+	if w.Safety != nil {
+		u.Safety = string(w.Safety.Rating)
+		u.OOMKills = w.Safety.OOMKills
+	}
+	return u
+}
+
+// headroom compares the pools' capacity with their pods' requests and the
+// analyzed workloads' p95 usage. Workloads the skew report did not analyze
+// count toward requests but not usage.
+func headroom(pools []NodePool, totals Totals) *Headroom {
+	h := &Headroom{}
+	var requestedCPU, requestedMemory float64
+	for _, p := range pools {
+		h.AllocatableCPU += p.AllocatableCPU
+		h.AllocatableMemoryGi += p.AllocatableMemoryGi
+		requestedCPU += p.RequestedCPU
+		requestedMemory += p.RequestedMemoryGi
+	}
+	h.RequestedCPUPercent = percent(requestedCPU, h.AllocatableCPU)
+	h.RequestedMemoryPercent = percent(requestedMemory, h.AllocatableMemoryGi)
+	h.UsedCPUPercent = percent(totals.UsedCPU, h.AllocatableCPU)
+	h.UsedMemoryPercent = percent(totals.UsedMemoryGi, h.AllocatableMemoryGi)
+	h.AllocatableCPU, h.AllocatableMemoryGi = round(h.AllocatableCPU), round(h.AllocatableMemoryGi)
+	return h
+}
+
+// NodePools groups nodes by pool label, or by instance type when they have
+// none, and sums the requests of the pods scheduled on them.
+func NodePools(nodes []corev1.Node, pods []corev1.Pod) []NodePool {
+	poolOf := make(map[string]string, len(nodes))
+	pools := map[string]*NodePool{}
+	for i := range nodes {
+		n := &nodes[i]
+		name, instanceType := nodePool(n)
+		poolOf[n.Name] = name
+		p := pools[name]
+		if p == nil {
+			p = &NodePool{Name: name, InstanceType: instanceType}
+			pools[name] = p
+		} else if p.InstanceType != instanceType {
+			p.InstanceType = "mixed"
+		}
+		p.Nodes++
+		p.AllocatableCPU += float64(n.Status.Allocatable.Cpu().MilliValue()) / 1000
+		p.AllocatableMemoryGi += gi(n.Status.Allocatable.Memory())
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		p := pools[poolOf[pod.Spec.NodeName]]
+		if p == nil {
+			continue
+		}
+		cpu, memory := snapshot.PodRequests(pod)
+		p.RequestedCPU += float64(cpu.MilliValue()) / 1000
+		p.RequestedMemoryGi += gi(&memory)
+	}
+
+	out := make([]NodePool, 0, len(pools))
+	for _, p := range pools {
+		p.RequestedCPUPercent = percent(p.RequestedCPU, p.AllocatableCPU)
+		p.RequestedMemoryPercent = percent(p.RequestedMemoryGi, p.AllocatableMemoryGi)
+		p.AllocatableCPU, p.AllocatableMemoryGi = round(p.AllocatableCPU), round(p.AllocatableMemoryGi)
+		p.RequestedCPU, p.RequestedMemoryGi = round(p.RequestedCPU), round(p.RequestedMemoryGi)
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Nodes != out[j].Nodes {
+			return out[i].Nodes > out[j].Nodes
+		}
+		return out[i].Name < out[j].Name
+	})
+	return capped(out, maxNodePools)
+}
+
+// nodePool returns the pool and instance type of a node.
+func nodePool(n *corev1.Node) (pool, instanceType string) {
+	for _, l := range instanceTypeLabels {
+		if v := n.Labels[l]; v != "" {
+			instanceType = v
+			break
+		}
+	}
+	for _, l := range poolLabels {
+		if v := n.Labels[l]; v != "" {
+			return v, instanceType
+		}
+	}
+	if instanceType != "" {
+		return instanceType, instanceType
+	}
+	return "default", ""
+}
+
+func gi(q *resource.Quantity) float64 {
+	return float64(q.Value()) / (1 << 30)
+}
+
+func percent(part, whole float64) int {
+	if whole <= 0 {
+		return 0
+	}
+	return int(math.Round(part / whole * 100))
+}
+
+// round keeps two decimals, enough for cores and GiB.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func capped[T any](s []T, n int) []T {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,137 @@
+package capacity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/models"
+)
+
+func testSkew() *analyzer.RequestsSkewResult {
+	return &analyzer.RequestsSkewResult{
+		Metadata: analyzer.RequestsSkewMetadata{Window: "30d", Cluster: "prod", GeneratedAt: time.Unix(100, 0).UTC()},
+		Summary: analyzer.RequestsSkewSummary{
+			AnalyzedWorkloads: 3,
+			TotalWastedCPU:    7.5,
+			CostEstimate:      &cost.SummaryCostEstimate{TotalCurrentMonthly: 1000, TotalWastedMonthly: 400},
+		},
+		Results: []analyzer.WorkloadSkewAnalysis{
+			{Namespace: "shop", Workload: "api", Type: "Deployment", RequestedCPU: 8, P95UsedCPU: 1, SkewCPU: 8,
+				RequestedMemoryGi: 16, P95UsedMemoryGi: 4, SkewMemory: 4},
+			{Namespace: "shop", Workload: "worker", Type: "Deployment", RequestedCPU: 1, P95UsedCPU: 1.5, SkewCPU: 0.67,
+				RequestedMemoryGi: 2, P95UsedMemoryGi: 1, SkewMemory: 2},
+			{Namespace: "db", Workload: "pg", Type: "StatefulSet", RequestedCPU: 2, P95UsedCPU: 0.5, SkewCPU: 4,
+				RequestedMemoryGi: 8, P95UsedMemoryGi: 7, SkewMemory: 1.14,
+				Safety: &models.SafetyAnalysis{Rating: models.SafetyRatingUnsafe, OOMKills: 2}},
+		},
+		NamespaceCosts: []cost.NamespaceCostEstimate{{Namespace: "shop", WastedMonthly: 350}},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	in := Build(testSkew(), nil)
+
+	assert.Equal(t, "prod", in.Cluster)
+	assert.Equal(t, "30d", in.Window)
+	assert.Equal(t, 3, in.Totals.Workloads)
+	assert.Equal(t, 11.0, in.Totals.RequestedCPU)
+	assert.Equal(t, 3.0, in.Totals.UsedCPU)
+	require.NotNil(t, in.Totals.MonthlyWaste)
+	assert.Equal(t, 400.0, *in.Totals.MonthlyWaste)
+	assert.Nil(t, in.Headroom, "no node pools, no headroom")
+
+	require.Len(t, in.Namespaces, 2)
+	assert.Equal(t, "shop", in.Namespaces[0].Namespace, "most idle CPU first")
+	assert.Equal(t, 2, in.Namespaces[0].Workloads)
+	require.NotNil(t, in.Namespaces[0].MonthlyWaste)
+	assert.Nil(t, in.Namespaces[1].MonthlyWaste)
+
+	require.Len(t, in.OverProvisioned, 1)
+	assert.Equal(t, "Deployment/api", in.OverProvisioned[0].Workload)
+
+	require.Len(t, in.UnderProvisioned, 2, "usage above requests and unsafe workloads")
+	assert.Equal(t, "Deployment/worker", in.UnderProvisioned[0].Workload)
+	assert.Equal(t, "UNSAFE", in.UnderProvisioned[1].Safety)
+	assert.Equal(t, 2, in.UnderProvisioned[1].OOMKills)
+}
+
+func testNode(name string, labels map[string]string, cpu, memory string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}},
+	}
+}
+
+func testPod(node string, phase corev1.PodPhase, cpu, memory string) corev1.Pod {
+	return corev1.Pod{
+		Spec: corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestNodePools(t *testing.T) {
+	nodes := []corev1.Node{
+		testNode("a", map[string]string{"eks.amazonaws.com/nodegroup": "general", "node.kubernetes.io/instance-type": "m5.xlarge"}, "4", "16Gi"),
+		testNode("b", map[string]string{"eks.amazonaws.com/nodegroup": "general", "node.kubernetes.io/instance-type": "m5.xlarge"}, "4", "16Gi"),
+		testNode("c", map[string]string{"node.kubernetes.io/instance-type": "r5.large"}, "2", "16Gi"),
+		testNode("d", nil, "1", "2Gi"),
+	}
+	pods := []corev1.Pod{
+		testPod("a", corev1.PodRunning, "2", "4Gi"),
+		testPod("b", corev1.PodRunning, "2", "4Gi"),
+		testPod("b", corev1.PodSucceeded, "4", "8Gi"),
+		testPod("", corev1.PodPending, "4", "8Gi"),
+	}
+
+	pools := NodePools(nodes, pods)
+	require.Len(t, pools, 3)
+	assert.Equal(t, NodePool{
+		Name: "general", InstanceType: "m5.xlarge", Nodes: 2,
+		AllocatableCPU: 8, AllocatableMemoryGi: 32, RequestedCPU: 4, RequestedMemoryGi: 8,
+		RequestedCPUPercent: 50, RequestedMemoryPercent: 25,
+	}, pools[0])
+	assert.Equal(t, "default", pools[1].Name)
+	assert.Equal(t, "r5.large", pools[2].Name)
+	assert.Equal(t, "r5.large", pools[2].InstanceType)
+
+	in := Build(testSkew(), pools)
+	require.NotNil(t, in.Headroom)
+	assert.Equal(t, 11.0, in.Headroom.AllocatableCPU)
+	assert.Equal(t, 36, in.Headroom.RequestedCPUPercent)
+	assert.Equal(t, 27, in.Headroom.UsedCPUPercent)
+}
+
+func TestLoadSkewReport(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "skew.json")
+	require.NoError(t, os.WriteFile(good, []byte(`{"metadata":{"window":"7d","generated_at":"2026-01-01T00:00:00Z"},"results":[]}`), 0o600))
+	r, err := LoadSkewReport(good)
+	require.NoError(t, err)
+	assert.Equal(t, "7d", r.Metadata.Window)
+
+	other := filepath.Join(dir, "snapshot.json")
+	require.NoError(t, os.WriteFile(other, []byte(`{"schemaVersion":1}`), 0o600))
+	_, err = LoadSkewReport(other)
+	assert.Error(t, err)
+
+	_, err = LoadSkewReport(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
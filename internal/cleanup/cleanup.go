@@ -0,0 +1,135 @@
+// Package cleanup releases what a command leaves behind when it is cut
+// short — port-forwards, partially written exports, temporary files — so an
+// interrupted run leaves the machine and the cluster clean.
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// ExitInterrupted is the exit code after SIGINT or SIGTERM (128 + SIGINT).
+const ExitInterrupted = 130
+
+type entry struct {
+	id   int
+	name string
+	fn   func() error
+}
+
+var (
+	mu       sync.Mutex
+	entries  []entry
+	nextID   int
+	graceful int // commands currently stopping on interrupt by themselves
+
+	// Replaced in tests.
+	stderr io.Writer = os.Stderr
+	exit             = os.Exit
+)
+
+// Register adds fn to the functions run by Run, newest first. Call the
+// returned release once the resource is released normally; it is safe to
+// call more than once and after Run.
+func Register(name string, fn func() error) (release func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	nextID++
+	id := nextID
+	entries = append(entries, entry{id: id, name: name, fn: fn})
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for i := range entries {
+			if entries[i].id == id {
+				entries = append(entries[:i], entries[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Run calls every registered function once, newest first, and reports
+// failures on stderr. Functions run without the registry lock held, so they
+// may call their own release.
+func Run() {
+	mu.Lock()
+	pending := entries
+	entries = nil
+	mu.Unlock()
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		if err := pending[i].fn(); err != nil {
+			warnf("[kubenow] Warning: cleanup of %s failed: %v\n", pending[i].name, err)
+		}
+	}
+}
+
+// Graceful tells the signal handler that the running command stops on
+// SIGINT by itself (for example, to save collected samples), so the first
+// signal is left to it. A second signal still cleans up and exits. Call the
+// returned function when the command no longer handles signals.
+func Graceful() (done func()) {
+	mu.Lock()
+	graceful++
+	mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			graceful--
+			mu.Unlock()
+		})
+	}
+}
+
+// HandleSignals returns a context canceled on SIGINT or SIGTERM. On a
+// signal the context is canceled, Run is called, and the process exits with
+// ExitInterrupted — unless a command called Graceful, in which case only a
+// second signal does so. stop unregisters the handler.
+func HandleSignals(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handle(sigCh, cancel)
+	}()
+	return ctx, func() {
+		signal.Stop(sigCh)
+		close(sigCh)
+		<-done
+		cancel()
+	}
+}
+
+func handle(sigCh <-chan os.Signal, cancel context.CancelFunc) {
+	received := 0
+	for sig := range sigCh {
+		received++
+		mu.Lock()
+		deferred := graceful > 0 && received == 1
+		mu.Unlock()
+		if deferred {
+			continue
+		}
+		warnf("\n[kubenow] Received %v — cleaning up\n", sig)
+		cancel()
+		Run()
+		exit(ExitInterrupted)
+		return
+	}
+}
+
+// warnf writes to stderr. Failing to report is not worth failing a cleanup.
+func warnf(format string, args ...any) {
+	if _, err := fmt.Fprintf(stderr, format, args...); err != nil {
+		return
+	}
+}
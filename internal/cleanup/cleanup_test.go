@@ -0,0 +1,130 @@
+package cleanup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubOutput(t *testing.T) (*bytes.Buffer, *[]int) {
+	t.Helper()
+	var out bytes.Buffer
+	var codes []int
+	prevStderr, prevExit := stderr, exit
+	stderr, exit = &out, func(code int) { codes = append(codes, code) }
+	t.Cleanup(func() {
+		stderr, exit = prevStderr, prevExit
+		Run()
+	})
+	return &out, &codes
+}
+
+func TestRegisterRun(t *testing.T) {
+	out, _ := stubOutput(t)
+
+	var order []string
+	Register("first", func() error { order = append(order, "first"); return nil })
+	release := Register("released", func() error { order = append(order, "released"); return nil })
+	var releaseSelf func()
+	releaseSelf = Register("self", func() error {
+		order = append(order, "self")
+		releaseSelf() // a cleanup may release itself without deadlocking
+		return errors.New("connection reset")
+	})
+	release()
+	release()
+
+	Run()
+	assert.Equal(t, []string{"self", "first"}, order)
+	assert.Contains(t, out.String(), "cleanup of self failed: connection reset")
+
+	Run()
+	assert.Len(t, order, 2, "functions run once")
+}
+
+func TestWriteFile(t *testing.T) {
+	stubOutput(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.json")
+
+	require.NoError(t, WriteFile(path, []byte(`{"ok":true}`), 0o600))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file left behind")
+}
+
+func TestCreate_InterruptedWrite(t *testing.T) {
+	out, _ := stubOutput(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(path, []byte("previous"), 0o600))
+
+	f, err := Create(path, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString("partial")
+	require.NoError(t, err)
+
+	Run() // interrupted before Commit
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(data))
+
+	// Run already closed and removed the file: nothing left to report
+	f.Discard()
+	assert.Empty(t, out.String())
+	assert.Error(t, f.Commit())
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name     string
+		graceful bool
+		signals  int
+		exits    []int
+	}{
+		{"first signal exits", false, 1, []int{ExitInterrupted}},
+		{"graceful command keeps the first signal", true, 1, nil},
+		{"second signal exits a graceful command", true, 2, []int{ExitInterrupted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, codes := stubOutput(t)
+			if tt.graceful {
+				done := Graceful()
+				defer done()
+			}
+			cleaned := false
+			Register("port-forward", func() error { cleaned = true; return nil })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			sigCh := make(chan os.Signal, tt.signals)
+			for i := 0; i < tt.signals; i++ {
+				sigCh <- syscall.SIGINT
+			}
+			close(sigCh)
+			handle(sigCh, cancel)
+
+			assert.Equal(t, tt.exits, *codes)
+			assert.Equal(t, tt.exits != nil, cleaned)
+			assert.Equal(t, tt.exits != nil, ctx.Err() != nil)
+		})
+	}
+}
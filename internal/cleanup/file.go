@@ -0,0 +1,101 @@
+package cleanup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File is written under a temporary name next to its destination (a
+// hidden ".tmp-" file, which storage listings skip) and
+// renamed into place by Commit, so readers never see a partial file. Until
+// then the temporary file is removed by Discard or, on interrupt, by Run.
+type File struct {
+	*os.File
+	path    string
+	perm    os.FileMode
+	release func()
+	done    bool
+}
+
+// Create starts writing path. Finish with Commit, or Discard on failure;
+// Discard after Commit is a no-op, so it can be deferred.
+func Create(path string, perm os.FileMode) (*File, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return nil, err
+	}
+	return &File{
+		File: f,
+		path: path,
+		perm: perm,
+		release: Register("partial file "+path, func() error {
+			return removeTemp(f)
+		}),
+	}, nil
+}
+
+// Commit closes the file and renames it to its destination.
+func (f *File) Commit() error {
+	if f.done {
+		return fmt.Errorf("%s is already closed", f.path)
+	}
+	f.done = true
+	defer f.release()
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		return errors.Join(err, removeIfExists(name))
+	}
+	if err := os.Chmod(name, f.perm); err != nil {
+		return errors.Join(err, removeIfExists(name))
+	}
+	if err := os.Rename(name, f.path); err != nil {
+		return errors.Join(err, removeIfExists(name))
+	}
+	return nil
+}
+
+// Discard closes and removes the temporary file, leaving the destination
+// untouched. A temporary file left behind is reported on stderr.
+func (f *File) Discard() {
+	if f.done {
+		return
+	}
+	f.done = true
+	defer f.release()
+	if err := removeTemp(f.File); err != nil {
+		warnf("[kubenow] Warning: could not remove partial file of %s: %v\n", f.path, err)
+	}
+}
+
+// WriteFile is os.WriteFile through Create and Commit: an interrupted or
+// failed write leaves any existing file at path as it was.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := Create(path, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Discard()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Commit()
+}
+
+// removeTemp closes and removes a temporary file. Closing it twice, as
+// Run after Commit or Discard may, is not an error.
+func removeTemp(f *os.File) error {
+	var closeErr error
+	if err := f.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		closeErr = err
+	}
+	return errors.Join(closeErr, removeIfExists(f.Name()))
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ppiankov/kubenow/internal/agent"
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promonitor"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var agentConfig struct {
+	// install and fetch
+	agentNamespace string
+	port           int
+
+	// install
+	image string
+	print bool
+
+	// install and run
+	interval       time.Duration
+	window         time.Duration
+	watchNamespace []string
+
+	// run
+	listen string
+
+	// fetch
+	agentURL  string
+	localPort string
+	partial   bool
+}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "In-cluster agent that latches workloads continuously",
+	Long: `The kubenow agent runs in the cluster and samples the Metrics API at
+1-5s resolution without interruption, so long latches no longer need a
+local session. It keeps the last complete window of every workload next to
+the window in progress and serves them over HTTP; 'agent fetch' saves one
+as a latch result for 'pro-monitor analyze' and 'pro-monitor export'.
+
+The agent is the only part of kubenow that installs anything into the
+cluster, and only when 'agent install' is run.`,
+}
+
+var agentInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Deploy the agent into the cluster",
+	Long: `Deploy the agent: a namespace, a ServiceAccount with read-only access to
+pods, events, and pod metrics, a one-replica Deployment, and a Service.
+One replica covers the whole cluster, since the Metrics API serves every
+node's usage. Existing objects are updated in place.
+
+Examples:
+  # Install with 5s samples over 1h windows
+  kubenow agent install
+
+  # 1s samples of two namespaces over 24h windows
+  kubenow agent install --interval 1s --window 24h --watch-namespace prod --watch-namespace payments
+
+  # Review or GitOps the manifest instead of applying it
+  kubenow agent install --print > kubenow-agent.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runAgentInstall,
+}
+
+var agentRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the agent (the command the agent's Deployment runs)",
+	Long: `Sample back-to-back latch windows and serve them over HTTP:
+
+  GET /healthz
+  GET /v1/workloads
+  GET /v1/latch/{namespace}/{workload}[?partial=true]
+
+Uses the in-cluster config when no kubeconfig is found.`,
+	Args: cobra.NoArgs,
+	RunE: runAgentRun,
+}
+
+var agentFetchCmd = &cobra.Command{
+	Use:   "fetch <kind>/<name>",
+	Short: "Save a workload's latch window from the agent",
+	Long: `Fetch a workload's last complete window from the agent and save it to
+~/.kubenow/latch/ like 'pro-monitor collect' does, for 'pro-monitor analyze'
+or 'pro-monitor export'. Before the first window completes, or with
+--partial, the window in progress is fetched instead.
+
+Without --agent-url the agent's Service is port-forwarded.
+
+Examples:
+  # Save the last complete window and review it
+  kubenow agent fetch deployment/payment-api -n prod
+  kubenow pro-monitor analyze deployment/payment-api -n prod
+
+  # Use the window in progress
+  kubenow agent fetch deployment/payment-api -n prod --partial
+
+  # Query an agent reachable directly
+  kubenow agent fetch deployment/payment-api -n prod --agent-url http://kubenow-agent.kubenow:8080`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentFetch,
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.AddCommand(agentInstallCmd, agentRunCmd, agentFetchCmd)
+
+	f := agentInstallCmd.Flags()
+	f.StringVar(&agentConfig.agentNamespace, "agent-namespace", agent.DefaultNamespace, "namespace to install the agent into")
+	f.StringVar(&agentConfig.image, "image", "", "agent image (default: ppiankov/kubenow at this binary's version)")
+	f.IntVar(&agentConfig.port, "port", agent.DefaultPort, "agent API port")
+	f.BoolVar(&agentConfig.print, "print", false, "print the manifest instead of applying it")
+	f.DurationVar(&agentConfig.interval, "interval", agent.DefaultInterval, "sample interval (1s-5s)")
+	f.DurationVar(&agentConfig.window, "window", agent.DefaultWindow, "length of one latch window")
+	f.StringSliceVar(&agentConfig.watchNamespace, "watch-namespace", nil, "namespace to sample (repeatable; default: all but kube-system)")
+
+	f = agentRunCmd.Flags()
+	f.DurationVar(&agentConfig.interval, "interval", agent.DefaultInterval, "sample interval (1s-5s)")
+	f.DurationVar(&agentConfig.window, "window", agent.DefaultWindow, "length of one latch window")
+	f.StringSliceVar(&agentConfig.watchNamespace, "watch-namespace", nil, "namespace to sample (repeatable; default: all but kube-system)")
+	f.StringVar(&agentConfig.listen, "listen", ":"+strconv.Itoa(agent.DefaultPort), "API listen address")
+
+	f = agentFetchCmd.Flags()
+	f.StringVar(&agentConfig.agentURL, "agent-url", "", "agent API URL (default: port-forward the agent's Service)")
+	f.StringVar(&agentConfig.agentNamespace, "agent-namespace", agent.DefaultNamespace, "namespace the agent is installed in")
+	f.IntVar(&agentConfig.port, "port", agent.DefaultPort, "agent API port the agent was installed with")
+	f.StringVar(&agentConfig.localPort, "local-port", "18080", "local port for the port-forward")
+	f.BoolVar(&agentConfig.partial, "partial", false, "fetch the window in progress instead of the last complete one")
+}
+
+func validateAgentSampling() error {
+	if agentConfig.interval < time.Second || agentConfig.interval > 5*time.Second {
+		return fmt.Errorf("--interval must be between 1s and 5s, got %s", agentConfig.interval)
+	}
+	if agentConfig.window < time.Minute {
+		return fmt.Errorf("--window must be at least 1m, got %s", agentConfig.window)
+	}
+	return nil
+}
+
+func runAgentInstall(cmd *cobra.Command, _ []string) error {
+	if err := validateAgentSampling(); err != nil {
+		return err
+	}
+
+	image := agentConfig.image
+	if image == "" {
+		tag := version
+		if tag == "dev" {
+			tag = "latest"
+		}
+		image = agent.DefaultImageRepo + ":" + tag
+	}
+	opts := agent.InstallOptions{
+		Namespace:      agentConfig.agentNamespace,
+		Image:          image,
+		Interval:       agentConfig.interval,
+		Window:         agentConfig.window,
+		Port:           agentConfig.port,
+		WatchNamespace: agentConfig.watchNamespace,
+	}
+
+	if agentConfig.print {
+		manifest, err := agent.Manifest(opts)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(manifest)
+		return err
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	done, err := agent.Install(cmd.Context(), kubeClient, opts)
+	log := logging.Component("agent")
+	for _, line := range done {
+		log.Info("Installed", "object", line)
+	}
+	if err != nil {
+		return err
+	}
+	printfOut("Agent installed in namespace %s (%s samples, %s windows).\n", opts.Namespace, opts.Interval, opts.Window)
+	printfOut("Fetch a workload's latch with: kubenow agent fetch <kind>/<name> -n <namespace>\n")
+	return nil
+}
+
+func runAgentRun(cmd *cobra.Command, _ []string) error {
+	if err := validateAgentSampling(); err != nil {
+		return err
+	}
+
+	opts := GetKubeOpts()
+	kubeClient, err := util.BuildKubeClientWithOpts(opts)
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	a := agent.New(agent.Config{
+		Interval:   agentConfig.interval,
+		Window:     agentConfig.window,
+		Namespaces: agentConfig.watchNamespace,
+	}, func(c metrics.LatchConfig) (*metrics.LatchMonitor, error) {
+		return metrics.NewLatchMonitor(kubeClient, c, opts)
+	})
+
+	// The first SIGINT or SIGTERM stops sampling and lets the server drain
+	// instead of exiting right away
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	defer cleanup.Graceful()()
+
+	logging.Component("agent").Info("Sampling", "interval", agentConfig.interval, "window", agentConfig.window,
+		"listen", agentConfig.listen)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error { return a.Run(gctx) })
+	g.Go(func() error { return a.Serve(gctx, agentConfig.listen) })
+	return g.Wait()
+}
+
+func runAgentFetch(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	ref, err := promonitor.ParseWorkloadRef(args[0])
+	if err != nil {
+		return err
+	}
+	if ref.Kind == "Pod" {
+		return fmt.Errorf("the agent samples workloads, not single pods")
+	}
+	ns := GetNamespace()
+	if ns == "" {
+		ns = "default"
+	}
+	ref.Namespace = ns
+
+	agentURL := agentConfig.agentURL
+	if agentURL == "" {
+		pf, pfErr := util.NewPortForward(GetKubeOpts(), agent.Name, agentConfig.agentNamespace,
+			agentConfig.localPort, strconv.Itoa(agentConfig.port), 0)
+		if pfErr != nil {
+			return fmt.Errorf("failed to create port-forward: %w", pfErr)
+		}
+		if pfErr = pf.Start(); pfErr != nil {
+			return fmt.Errorf("failed to port-forward the agent (installed with 'kubenow agent install'?): %w", pfErr)
+		}
+		defer func() {
+			if stopErr := pf.Stop(); stopErr != nil {
+				logging.Component("agent").Warn("Failed to stop port-forward", "error", stopErr)
+			}
+		}()
+		agentURL = "http://localhost:" + agentConfig.localPort
+	}
+
+	latch, err := agent.NewClient(agentURL).Latch(ctx, ref.Namespace, ref.Name, agentConfig.partial)
+	if err != nil {
+		return err
+	}
+
+	result := promonitor.BuildLatchResult(*ref, latch.Data, latch.Duration(), latch.Interval)
+	if err := promonitor.SaveLatch(result); err != nil {
+		return fmt.Errorf("failed to save latch data: %w", err)
+	}
+
+	log := logging.Component("agent")
+	log.Info("Fetched latch", "workload", ref.String(), "samples", latch.Data.SampleCount,
+		"duration", latch.Duration().Truncate(time.Second), "complete", latch.Complete,
+		"started", latch.WindowStart.Format(time.RFC3339))
+	if !result.Valid {
+		log.Warn("Latch data is invalid", "reason", result.Reason)
+	}
+	log.Info("Latch saved", "path", promonitor.LatchFilePath(*ref))
+	printfOut("Review with: kubenow pro-monitor analyze %s -n %s\n", args[0], ref.Namespace)
+	return nil
+}
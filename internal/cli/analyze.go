@@ -17,6 +17,11 @@ metrics from Prometheus and reproducible calculations.
 Available analysis types:
   - requests-skew: Identify over-provisioned resource requests
   - node-footprint: Simulate alternative cluster topologies
+  - schedule-savings: Plan scale-to-zero schedules for idle non-prod workloads
+  - oom: Explain OOMKills per workload and recommend memory limits
+  - orphans: Find orphaned resources and expired namespaces and plan their cleanup
+  - throttling: Find CPU-throttled containers and recommend CPU limits
+  - hpa-skew: Find HPAs that never scale, flap, or have targets usage cannot reach
 
 Examples:
   # Find over-provisioned resources
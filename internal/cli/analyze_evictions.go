@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var evictionsConfig struct {
+	window     string
+	output     string
+	exportFile string
+}
+
+var evictionsCmd = &cobra.Command{
+	Use:   "evictions",
+	Short: "Find repeatedly evicted workloads and what would prevent it",
+	Long: `Aggregate evicted pods, eviction and preemption events, node pressure
+events, and PriorityClass configuration over the window, per workload.
+No LLM is involved.
+
+Each workload shows how often it was evicted, by which signal (memory,
+ephemeral-storage, pids, preemption, node-taint), on which nodes, and its
+PriorityClass and QoS class. Under node pressure the kubelet evicts pods
+using more than they request first, then lower-priority pods, so the
+recommendations are the request and priorityClassName changes that would
+keep the workload from being picked: requests raised to the usage the
+kubelet reported at eviction, and the lowest PriorityClass above the
+workload's current priority.
+
+Evicted pods stay listed until the pod garbage collector removes them, but
+events expire after the API server's event TTL (1h by default), so
+evictions of pods that are gone may be missing.
+
+Examples:
+  # Evictions in the last week, cluster-wide
+  kubenow analyze evictions
+
+  # One namespace, last 24 hours, as JSON
+  kubenow analyze evictions -n shop --window 24h --output json`,
+	RunE: runEvictions,
+}
+
+func init() {
+	analyzeCmd.AddCommand(evictionsCmd)
+	evictionsCmd.Flags().StringVar(&evictionsConfig.window, "window", "7d", "Lookback for evictions and pressure events (e.g., 24h, 7d)")
+	evictionsCmd.Flags().StringVar(&evictionsConfig.output, "output", "table", "Output format: table|json")
+	evictionsCmd.Flags().StringVarP(&evictionsConfig.exportFile, "export-file", "o", "", "write to file instead of stdout")
+}
+
+func runEvictions(_ *cobra.Command, _ []string) error {
+	if evictionsConfig.output != "table" && evictionsConfig.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", evictionsConfig.output)
+	}
+
+	window, err := metrics.ParseDuration(evictionsConfig.window)
+	if err != nil {
+		return fmt.Errorf("invalid --window: %w", err)
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	result, err := analyzer.AnalyzeEvictions(context.Background(), kubeClient, analyzer.EvictionsConfig{
+		Namespace: GetNamespace(),
+		Window:    window,
+	})
+	if err != nil {
+		return fmt.Errorf("eviction analysis failed: %w", err)
+	}
+
+	if evictionsConfig.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(evictionsConfig.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(evictionsConfig.exportFile, renderEvictionsTable(result))
+}
+
+func renderEvictionsTable(r *analyzer.EvictionsResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== Evictions (last %s) ===\n\n", r.Window)
+
+	if len(r.Workloads) == 0 {
+		b.WriteString("No evicted or preempted workloads.\n")
+	} else {
+		table := tablewriter.NewWriter(&b)
+		table.Header([]string{"Namespace", "Workload", "Kind", "Evictions", "Signals", "Priority", "QoS", "Last Eviction"})
+		for i := range r.Workloads {
+			w := &r.Workloads[i]
+			priority := strconv.Itoa(int(w.Priority))
+			if w.PriorityClass != "" {
+				priority = w.PriorityClass + " (" + priority + ")"
+			}
+			qos := w.QOSClass
+			if qos == "" {
+				qos = "—"
+			}
+			appendTableRowBestEffort(table, []string{
+				w.Namespace, w.Workload, w.Kind, strconv.Itoa(w.Evictions), formatEvictionSignals(w.Signals),
+				priority, qos, w.LastEviction.Format(time.RFC3339),
+			})
+		}
+		renderTableBestEffort(table)
+
+		for i := range r.Workloads {
+			w := &r.Workloads[i]
+			if len(w.Recommendations) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n%s/%s (%s):\n", w.Namespace, w.Workload, w.Kind)
+			if len(w.Nodes) > 0 {
+				fmt.Fprintf(&b, "  evicted on %s\n", strings.Join(w.Nodes, ", "))
+			}
+			for _, rec := range w.Recommendations {
+				fmt.Fprintf(&b, "  -> %s\n", rec)
+			}
+		}
+	}
+
+	if len(r.NodePressure) > 0 {
+		b.WriteString("\nNode pressure events:\n")
+		for i := range r.NodePressure {
+			p := &r.NodePressure[i]
+			fmt.Fprintf(&b, "  %s  %s: %s pressure (x%d)\n", p.LastSeen.Format(time.RFC3339), p.Node, p.Signal, p.Events)
+		}
+	}
+	return b.String()
+}
+
+// formatEvictionSignals renders signal counts as "memory x3, preemption x1",
+// most frequent first.
+func formatEvictionSignals(signals map[string]int) string {
+	names := make([]string, 0, len(signals))
+	for name := range signals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if signals[names[i]] != signals[names[j]] {
+			return signals[names[i]] > signals[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s x%d", name, signals[name])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var hpaSkewConfig struct {
+	prometheusURL          string
+	window                 string
+	step                   string
+	flapWindow             string
+	flapThreshold          int
+	all                    bool
+	output                 string
+	exportFile             string
+	includeQueries         bool
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	silent                 bool
+}
+
+var hpaSkewCmd = &cobra.Command{
+	Use:   "hpa-skew",
+	Short: "Find HPAs that never scale, flap, or have targets usage cannot reach",
+	Long: `Compare every HorizontalPodAutoscaler's target utilization and replica range
+with its replica history (kube_horizontalpodautoscaler_status_current_replicas
+from kube-state-metrics) and its target's observed usage.
+
+Findings:
+  target-unreachable  Replicas stayed at minReplicas and peak utilization never
+                      reached half the target: requests are too high for the
+                      HPA to ever scale out. Suggests a request at which peak
+                      usage reaches the target.
+  saturated           At maxReplicas for 10%+ of the window with utilization
+                      above the target.
+  flapping            --flap-threshold or more scale reversals within
+                      --flap-window of the previous change.
+  never-scales        No replica change over the window, for no reason above.
+  memory-pinned       A memory target keeps replicas above minReplicas because
+                      memory does not fall as replicas are added.
+  fixed-replicas      minReplicas equals maxReplicas.
+  missing-request     A container lacks the request a utilization target needs.
+  inactive            The HPA reports ScalingActive=False.
+
+CPU utilization is the workload's usage divided by its replicas, as the HPA
+computes it. Memory uses the hottest replica, so memory findings are an upper
+bound. Only cpu and memory utilization targets are compared with usage;
+custom and external metrics count toward scaling behaviour only.
+
+Examples:
+  # HPAs with findings over the last week
+  kubenow analyze hpa-skew --prometheus-url http://localhost:9090
+
+  # One namespace, including healthy HPAs
+  kubenow analyze hpa-skew --prometheus-url http://localhost:9090 -n payments --all
+
+  # JSON export
+  kubenow analyze hpa-skew --prometheus-url http://localhost:9090 --output json --export-file hpa.json`,
+	RunE: runHPASkew,
+}
+
+func init() {
+	analyzeCmd.AddCommand(hpaSkewCmd)
+
+	f := hpaSkewCmd.Flags()
+	f.StringVar(&hpaSkewConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint (e.g., http://prometheus:9090)")
+	f.StringVar(&hpaSkewConfig.window, "window", "7d", "Time window to analyze")
+	f.StringVar(&hpaSkewConfig.step, "step", analyzer.DefaultHPASkewStep.String(), "Resolution of replica and usage samples")
+	f.StringVar(&hpaSkewConfig.flapWindow, "flap-window", analyzer.DefaultHPAFlapWindow.String(),
+		"A scale change reversing the previous one within this time is a flap")
+	f.IntVar(&hpaSkewConfig.flapThreshold, "flap-threshold", analyzer.DefaultHPAFlapThreshold, "Report HPAs with at least this many flaps")
+	f.BoolVar(&hpaSkewConfig.all, "all", false, "Include HPAs without findings")
+	f.StringVar(&hpaSkewConfig.output, "output", "table", "Output format: table|json")
+	f.StringVar(&hpaSkewConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+	addIncludeQueriesFlag(f, &hpaSkewConfig.includeQueries)
+	f.StringVar(&hpaSkewConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	f.StringVar(&hpaSkewConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	f.StringVar(&hpaSkewConfig.metricsTenant, "metrics-tenant", "", "Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	f.StringVar(&hpaSkewConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
+	f.BoolVar(&hpaSkewConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
+}
+
+func runHPASkew(_ *cobra.Command, _ []string) error {
+	cfg := &hpaSkewConfig
+	if err := validateHPASkewFlags(); err != nil {
+		return err
+	}
+
+	window, err := metrics.ParseDuration(cfg.window)
+	if err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+	step, err := time.ParseDuration(cfg.step)
+	if err != nil || step <= 0 {
+		return fmt.Errorf("invalid --step %q", cfg.step)
+	}
+	flapWindow, err := time.ParseDuration(cfg.flapWindow)
+	if err != nil || flapWindow <= 0 {
+		return fmt.Errorf("invalid --flap-window %q", cfg.flapWindow)
+	}
+	timeout, err := time.ParseDuration(cfg.prometheusTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	queryLog := newQueryLog(cfg.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	promConfig := metrics.Config{
+		PrometheusURL: cfg.prometheusURL,
+		Timeout:       timeout,
+		Backend:       cfg.metricsBackend,
+		TenantID:      cfg.metricsTenant,
+		QueryLog:      queryLog,
+	}
+	promConfig.QueryOptions, err = resolveClusterLabel(cfg.prometheusClusterLabel, promConfig, kubeClient, timeout, cfg.silent)
+	if err != nil {
+		return err
+	}
+	metricsProvider, err := metrics.NewProvider(promConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics provider: %w", err)
+	}
+
+	healthCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err = metricsProvider.Health(healthCtx); err != nil {
+		return fmt.Errorf("prometheus health check failed: %w", err)
+	}
+
+	result, err := analyzer.AnalyzeHPASkew(context.Background(), kubeClient, metricsProvider, analyzer.HPASkewConfig{
+		Namespace:     GetNamespace(),
+		Window:        window,
+		Step:          step,
+		FlapWindow:    flapWindow,
+		FlapThreshold: cfg.flapThreshold,
+		All:           cfg.all,
+	})
+	if err != nil {
+		return fmt.Errorf("HPA analysis failed: %w", err)
+	}
+	result.Queries = queryLog.Report(cfg.prometheusURL)
+
+	if cfg.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(cfg.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(cfg.exportFile, renderHPASkewTable(result)+querylog.Render(result.Queries))
+}
+
+func validateHPASkewFlags() error {
+	cfg := &hpaSkewConfig
+	if cfg.prometheusURL == "" {
+		return fmt.Errorf("--prometheus-url is required")
+	}
+	if cfg.output != "table" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", cfg.output)
+	}
+	if cfg.flapThreshold < 1 {
+		return fmt.Errorf("--flap-threshold must be at least 1")
+	}
+	return nil
+}
+
+func renderHPASkewTable(r *analyzer.HPASkewResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== HPA Alignment (last %s, %d HPAs, %d healthy) ===\n\n", r.Window, r.Analyzed, r.Healthy)
+	if len(r.HPAs) == 0 {
+		b.WriteString("No HPA findings.\n")
+		return b.String()
+	}
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Namespace", "HPA", "Target", "Range", "Observed", "Events", "Flaps", "Utilization", "Findings"})
+	for i := range r.HPAs {
+		e := &r.HPAs[i]
+		observed, events, flaps := "-", "-", "-"
+		if e.Replicas != nil {
+			observed = fmt.Sprintf("%d-%d", e.Replicas.Min, e.Replicas.Max)
+			events = strconv.Itoa(e.Replicas.ScaleEvents)
+			flaps = strconv.Itoa(e.Replicas.Flaps)
+		}
+		var utilization []string
+		for _, t := range e.Targets {
+			switch {
+			case t.Evaluated:
+				utilization = append(utilization, fmt.Sprintf("%s %.0f%% peak / %d%%", t.Metric, t.UtilizationPeak, t.TargetUtilization))
+			case t.TargetUtilization > 0:
+				utilization = append(utilization, fmt.Sprintf("%s ? / %d%%", t.Metric, t.TargetUtilization))
+			default:
+				utilization = append(utilization, t.Metric)
+			}
+		}
+		types := make([]string, 0, len(e.Findings))
+		for _, f := range e.Findings {
+			types = append(types, f.Type)
+		}
+		if len(types) == 0 {
+			types = append(types, "ok")
+		}
+		appendTableRowBestEffort(table, []string{
+			e.Namespace, e.Name, e.TargetKind + "/" + e.TargetName, fmt.Sprintf("%d-%d", e.MinReplicas, e.MaxReplicas),
+			observed, events, flaps, strings.Join(utilization, ", "), strings.Join(types, ", "),
+		})
+	}
+	renderTableBestEffort(table)
+
+	b.WriteString("\n")
+	for i := range r.HPAs {
+		e := &r.HPAs[i]
+		for _, f := range e.Findings {
+			fmt.Fprintf(&b, "%s/%s [%s]: %s\n", e.Namespace, e.Name, f.Type, f.Message)
+			if f.Recommendation != "" {
+				fmt.Fprintf(&b, "  fix: %s\n", f.Recommendation)
+			}
+		}
+	}
+	return b.String()
+}
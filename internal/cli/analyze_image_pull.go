@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var imagePullConfig struct {
+	probe        bool
+	probeTimeout time.Duration
+	output       string
+	exportFile   string
+}
+
+var imagePullCmd = &cobra.Command{
+	Use:   "image-pull",
+	Short: "Group image pull failures by registry and explain each one",
+	Long: `Find containers stuck in ErrImagePull, ImagePullBackOff, or
+InvalidImageName, group them by registry and image, and classify each
+failure from the kubelet's pull error. No LLM is involved.
+
+  auth          the registry denied access: imagePullSecret missing or stale
+  not-found     the repository or tag does not exist: usually a tag typo
+  rate-limit    the registry throttled pulls (Docker Hub's anonymous limit)
+  network       nodes cannot resolve or reach the registry
+  invalid-name  the image reference does not parse
+
+Pods in back-off only say they are backing off, so the cause comes from
+the pod's latest "Failed to pull image" event; once that event expires the
+failure is classified as unknown. Pull secrets are read from the pod and
+its ServiceAccount.
+
+With --probe, each failing registry's /v2/ endpoint is requested from
+where kubenow runs, without credentials. A registry that answers here but
+not from the nodes points at node DNS or egress; one that does not answer
+here either points at the host name or the registry itself. For not-found
+images the tag list is fetched with an anonymous token, when the registry
+allows it, and the closest existing tag is suggested. Probing sends
+requests to the registries outside the cluster; it is off by default.
+
+Examples:
+  # Image pull failures, cluster-wide
+  kubenow analyze image-pull
+
+  # Probe the registries and suggest tags
+  kubenow analyze image-pull -n shop --probe
+
+  # As JSON
+  kubenow analyze image-pull --output json`,
+	RunE: runImagePull,
+}
+
+func init() {
+	analyzeCmd.AddCommand(imagePullCmd)
+	imagePullCmd.Flags().BoolVar(&imagePullConfig.probe, "probe", false, "Probe each failing registry from this machine")
+	imagePullCmd.Flags().DurationVar(&imagePullConfig.probeTimeout, "probe-timeout", analyzer.DefaultProbeTimeout,
+		"Timeout per registry probe request")
+	imagePullCmd.Flags().StringVar(&imagePullConfig.output, "output", "table", "Output format: table|json")
+	imagePullCmd.Flags().StringVarP(&imagePullConfig.exportFile, "export-file", "o", "", "write to file instead of stdout")
+}
+
+func runImagePull(_ *cobra.Command, _ []string) error {
+	if imagePullConfig.output != "table" && imagePullConfig.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", imagePullConfig.output)
+	}
+	if imagePullConfig.probeTimeout <= 0 {
+		return fmt.Errorf("invalid --probe-timeout %s: must be positive", imagePullConfig.probeTimeout)
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	result, err := analyzer.AnalyzeImagePulls(context.Background(), kubeClient, analyzer.ImagePullConfig{
+		Namespace:    GetNamespace(),
+		Probe:        imagePullConfig.probe,
+		ProbeTimeout: imagePullConfig.probeTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("image pull analysis failed: %w", err)
+	}
+
+	if imagePullConfig.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(imagePullConfig.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(imagePullConfig.exportFile, renderImagePullTable(result))
+}
+
+// pullClassOrder lists failure classes, most actionable first.
+var pullClassOrder = []string{
+	analyzer.PullClassAuth,
+	analyzer.PullClassNotFound,
+	analyzer.PullClassRateLimit,
+	analyzer.PullClassNetwork,
+	analyzer.PullClassInvalidName,
+	analyzer.PullClassUnknown,
+}
+
+func renderImagePullTable(r *analyzer.ImagePullResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== Image pull failures (%d pods) ===\n\n", r.Pods)
+	if len(r.Registries) == 0 {
+		b.WriteString("No image pull failures.\n")
+		return b.String()
+	}
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Registry", "Pods", "Images", "Classes", "Probe"})
+	for i := range r.Registries {
+		reg := &r.Registries[i]
+		var classes []string
+		for _, class := range pullClassOrder {
+			if n := reg.Classes[class]; n > 0 {
+				classes = append(classes, fmt.Sprintf("%s %d", class, n))
+			}
+		}
+		appendTableRowBestEffort(table, []string{
+			reg.Registry, strconv.Itoa(reg.Pods), strconv.Itoa(len(reg.Images)), strings.Join(classes, ", "),
+			formatRegistryProbe(reg.Probe),
+		})
+	}
+	renderTableBestEffort(table)
+
+	for i := range r.Registries {
+		reg := &r.Registries[i]
+		for j := range reg.Images {
+			img := &reg.Images[j]
+			fmt.Fprintf(&b, "\n%s [%s], %d pod(s):\n", img.Image, img.Class, img.Pods)
+			fmt.Fprintf(&b, "  workloads: %s\n", strings.Join(img.Workloads, ", "))
+			if img.Message != "" {
+				fmt.Fprintf(&b, "  error: %s\n", img.Message)
+			}
+			fmt.Fprintf(&b, "  -> %s\n", img.Fix)
+		}
+	}
+	return b.String()
+}
+
+func formatRegistryProbe(p *analyzer.RegistryProbe) string {
+	switch {
+	case p == nil:
+		return "—"
+	case p.Reachable:
+		return fmt.Sprintf("reachable (HTTP %d, %dms)", p.Status, p.LatencyMS)
+	case p.Status != 0:
+		return fmt.Sprintf("HTTP %d", p.Status)
+	default:
+		return "unreachable"
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
 	"time"
@@ -12,20 +13,27 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/cleanup"
 	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
 	"github.com/ppiankov/kubenow/internal/util"
 )
 
 var nodeFootprintConfig struct {
-	prometheusURL     string
-	autoDetect        bool
-	window            string
-	percentile        string
-	nodeTypes         string
-	output            string
-	exportFile        string
-	prometheusTimeout string
-	silent            bool
+	prometheusURL          string
+	autoDetect             bool
+	window                 string
+	percentile             string
+	nodeTypes              string
+	output                 string
+	exportFile             string
+	includeQueries         bool
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	silent                 bool
 }
 
 var nodeFootprintCmd = &cobra.Command{
@@ -75,7 +83,12 @@ func init() {
 	nodeFootprintCmd.Flags().StringVar(&nodeFootprintConfig.nodeTypes, "node-types", "", "Comma-separated node types to simulate (e.g., 'c5.xlarge,c5.2xlarge')")
 	nodeFootprintCmd.Flags().StringVar(&nodeFootprintConfig.output, "output", "table", "Output format: table|json")
 	nodeFootprintCmd.Flags().StringVar(&nodeFootprintConfig.exportFile, "export-file", "", "Save to file (optional)")
+	addIncludeQueriesFlag(nodeFootprintCmd.Flags(), &nodeFootprintConfig.includeQueries)
 	nodeFootprintCmd.Flags().StringVar(&nodeFootprintConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	nodeFootprintCmd.Flags().StringVar(&nodeFootprintConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	nodeFootprintCmd.Flags().StringVar(&nodeFootprintConfig.metricsTenant, "metrics-tenant", "", "Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	nodeFootprintCmd.Flags().StringVar(&nodeFootprintConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
 
 	// CI/CD flags
 	nodeFootprintCmd.Flags().BoolVar(&nodeFootprintConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
@@ -121,28 +134,33 @@ func runNodeFootprint(_ *cobra.Command, _ []string) error {
 	}
 
 	// Build Kubernetes client
-	if IsVerbose() {
-		stderrln("[kubenow] Building Kubernetes client...")
-	}
+	slog.Debug("Building Kubernetes client...")
 
-	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	queryLog := newQueryLog(nodeFootprintConfig.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
 	if err != nil {
 		return fmt.Errorf("failed to build Kubernetes client: %w", err)
 	}
 
 	// Create Prometheus client
-	if IsVerbose() {
-		stderrf("[kubenow] Connecting to Prometheus: %s\n", nodeFootprintConfig.prometheusURL)
-	}
+	slog.Debug("Connecting to Prometheus", "url", nodeFootprintConfig.prometheusURL)
 
 	promConfig := metrics.Config{
 		PrometheusURL: nodeFootprintConfig.prometheusURL,
 		Timeout:       timeout,
+		Backend:       nodeFootprintConfig.metricsBackend,
+		TenantID:      nodeFootprintConfig.metricsTenant,
+		QueryLog:      queryLog,
+	}
+
+	promConfig.QueryOptions, err = resolveClusterLabel(nodeFootprintConfig.prometheusClusterLabel, promConfig, kubeClient, timeout, nodeFootprintConfig.silent)
+	if err != nil {
+		return err
 	}
 
-	metricsProvider, err := metrics.NewPrometheusClient(promConfig)
+	metricsProvider, err := metrics.NewProvider(promConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Prometheus client: %w", err)
+		return fmt.Errorf("failed to create metrics provider: %w", err)
 	}
 
 	// Health check — use timeout to prevent unbounded calls
@@ -152,9 +170,7 @@ func runNodeFootprint(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("prometheus health check failed: %w", err)
 	}
 
-	if IsVerbose() {
-		stderrln("[kubenow] Analyzing cluster node footprint...")
-	}
+	slog.Debug("Analyzing cluster node footprint...")
 
 	// Create analyzer
 	analyzerConfig := analyzer.NodeFootprintConfig{
@@ -171,6 +187,7 @@ func runNodeFootprint(_ *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
+	result.Queries = queryLog.Report(nodeFootprintConfig.prometheusURL)
 
 	// Output results
 	if nodeFootprintConfig.output == "json" {
@@ -188,10 +205,10 @@ func outputNodeFootprintJSON(result *analyzer.NodeFootprintResult, exportFile st
 
 	// Export to file if specified
 	if exportFile != "" {
-		if err := os.WriteFile(exportFile, data, 0o600); err != nil {
+		if err := cleanup.WriteFile(exportFile, data, 0o600); err != nil {
 			return fmt.Errorf("failed to write file: %w", err)
 		}
-		stderrf("[kubenow] Report saved to: %s\n", exportFile)
+		slog.Info("Report saved", "path", exportFile)
 		return nil
 	}
 
@@ -249,8 +266,8 @@ func outputNodeFootprintTable(result *analyzer.NodeFootprintResult, _ string) er
 	fmt.Printf("  Avg Memory Utilization: %.0f%%\n\n", result.CurrentTopology.AvgMemUtilization)
 
 	fmt.Printf("Workload Envelope (%s):\n", result.Metadata.Percentile)
-	fmt.Printf("  Total CPU Required: %.2f cores\n", result.WorkloadEnvelope.TotalCPURequired)
-	fmt.Printf("  Total Memory Required: %.2fGi\n", result.WorkloadEnvelope.TotalMemoryRequired/(1024*1024*1024))
+	fmt.Printf("  Total CPU Required: %s cores\n", units.Cores(result.WorkloadEnvelope.TotalCPURequired))
+	fmt.Printf("  Total Memory Required: %s\n", units.MemoryGi(result.WorkloadEnvelope.TotalMemoryRequired))
 	fmt.Printf("  Pod Count: %d\n\n", result.WorkloadEnvelope.PodCount)
 
 	fmt.Println("Scenarios:")
@@ -293,6 +310,7 @@ func outputNodeFootprintTable(result *analyzer.NodeFootprintResult, _ string) er
 	// Print philosophy reminder
 	fmt.Printf("\nNote: These scenarios show what would have been sufficient based on historical %s usage.\n", result.Metadata.Percentile)
 	fmt.Println("Always validate with your specific requirements and add safety margins for production.")
+	fmt.Print(querylog.Render(result.Queries))
 
 	return nil
 }
@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var nodeSkewConfig struct {
+	prometheusURL          string
+	window                 string
+	percentile             string
+	margin                 float64
+	targetUtilization      float64
+	nodeSelector           string
+	output                 string
+	exportFile             string
+	includeQueries         bool
+	costCPU                float64
+	costMemory             float64
+	instanceType           string
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	silent                 bool
+}
+
+// nodeSkewQuantiles maps --percentile values to usage quantiles.
+var nodeSkewQuantiles = map[string]float64{"p50": 0.5, "p90": 0.9, "p95": 0.95, "p99": 0.99}
+
+var nodeSkewCmd = &cobra.Command{
+	Use:   "node-skew",
+	Short: "Compare node allocatable, requested, and used resources and estimate consolidation",
+	Long: `Show, for every node, how much of its allocatable CPU and memory is requested
+by the pods scheduled on it and how much is actually used, then estimate how
+many nodes the workload would need after right-sizing.
+
+The consolidation estimate repacks movable pods (everything except DaemonSet
+and static pods) first-fit onto the largest schedulable nodes, filling each to
+--target-utilization of its allocatable. It runs twice: once with today's
+requests, showing what fragmentation costs, and once with right-sized requests
+(--percentile usage plus --margin). Pods without usage data keep their current
+requests. Cordoned and NoSchedule-tainted nodes are left out.
+
+The estimate ignores affinity, tolerations, PodDisruptionBudgets, and topology
+spread constraints, so treat removable nodes as an upper bound.
+
+Examples:
+  # Node skew and consolidation estimate over the last week
+  kubenow analyze node-skew --prometheus-url http://localhost:9090
+
+  # A single node pool, packing no tighter than 75%
+  kubenow analyze node-skew --prometheus-url http://localhost:9090 \
+    --node-selector node.kubernetes.io/instance-type=m5.2xlarge --target-utilization 0.75
+
+  # JSON export
+  kubenow analyze node-skew --prometheus-url http://localhost:9090 --output json --export-file node-skew.json`,
+	RunE: runNodeSkew,
+}
+
+func init() {
+	analyzeCmd.AddCommand(nodeSkewCmd)
+
+	f := nodeSkewCmd.Flags()
+	f.StringVar(&nodeSkewConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint (e.g., http://prometheus:9090)")
+	f.StringVar(&nodeSkewConfig.window, "window", "7d", "Usage history to analyze")
+	f.StringVar(&nodeSkewConfig.percentile, "percentile", "p95", "Usage percentile: p50, p90, p95, p99")
+	f.Float64Var(&nodeSkewConfig.margin, "margin", analyzer.DefaultRightSizingMargin,
+		"Headroom added to pod usage for right-sized requests (0.15 = 15%)")
+	f.Float64Var(&nodeSkewConfig.targetUtilization, "target-utilization", analyzer.DefaultTargetUtilization,
+		"Share of node allocatable the consolidation estimate may fill (0-1)")
+	f.StringVar(&nodeSkewConfig.nodeSelector, "node-selector", "",
+		"Label selector limiting the nodes analyzed (e.g., karpenter.sh/nodepool=default)")
+	f.StringVar(&nodeSkewConfig.output, "output", "table", "Output format: table|json")
+	f.StringVar(&nodeSkewConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+	addIncludeQueriesFlag(f, &nodeSkewConfig.includeQueries)
+	f.Float64Var(&nodeSkewConfig.costCPU, "cost-per-cpu-hour", 0, "Cost per CPU core per hour in dollars (overrides instance-type lookup)")
+	f.Float64Var(&nodeSkewConfig.costMemory, "cost-per-gib-hour", 0,
+		"Cost per GiB memory per hour in dollars (overrides instance-type lookup)")
+	f.StringVar(&nodeSkewConfig.instanceType, "instance-type", instanceTypeAuto,
+		"Cloud instance type for pricing nodes without a known type, or 'auto' to blend the cluster's node types")
+	f.StringVar(&nodeSkewConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	f.StringVar(&nodeSkewConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	f.StringVar(&nodeSkewConfig.metricsTenant, "metrics-tenant", "", "Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	f.StringVar(&nodeSkewConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
+	f.BoolVar(&nodeSkewConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
+}
+
+func runNodeSkew(_ *cobra.Command, _ []string) error {
+	cfg := &nodeSkewConfig
+	if err := validateNodeSkewFlags(); err != nil {
+		return err
+	}
+
+	window, err := metrics.ParseDuration(cfg.window)
+	if err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+	timeout, err := time.ParseDuration(cfg.prometheusTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	queryLog := newQueryLog(cfg.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	promConfig := metrics.Config{
+		PrometheusURL: cfg.prometheusURL,
+		Timeout:       timeout,
+		Backend:       cfg.metricsBackend,
+		TenantID:      cfg.metricsTenant,
+		QueryLog:      queryLog,
+	}
+	promConfig.QueryOptions, err = resolveClusterLabel(cfg.prometheusClusterLabel, promConfig, kubeClient, timeout, cfg.silent)
+	if err != nil {
+		return err
+	}
+	metricsProvider, err := metrics.NewProvider(promConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics provider: %w", err)
+	}
+
+	healthCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err = metricsProvider.Health(healthCtx); err != nil {
+		return fmt.Errorf("prometheus health check failed: %w", err)
+	}
+
+	ctx := context.Background()
+	result, err := analyzer.AnalyzeNodeSkew(ctx, kubeClient, metricsProvider, analyzer.NodeSkewConfig{
+		Window:            window,
+		Quantile:          nodeSkewQuantiles[cfg.percentile],
+		Margin:            cfg.margin,
+		TargetUtilization: cfg.targetUtilization,
+		NodeSelector:      cfg.nodeSelector,
+		Rates:             clusterCostRates(ctx, kubeClient, cfg.instanceType, cfg.costCPU, cfg.costMemory, cfg.silent),
+		Silent:            cfg.silent,
+	})
+	if err != nil {
+		return fmt.Errorf("node-skew analysis failed: %w", err)
+	}
+	result.Queries = queryLog.Report(cfg.prometheusURL)
+
+	if cfg.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(cfg.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(cfg.exportFile, renderNodeSkewTable(result, cfg.percentile)+querylog.Render(result.Queries))
+}
+
+func validateNodeSkewFlags() error {
+	cfg := &nodeSkewConfig
+	if cfg.prometheusURL == "" {
+		return fmt.Errorf("--prometheus-url is required")
+	}
+	if cfg.output != "table" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", cfg.output)
+	}
+	if _, ok := nodeSkewQuantiles[cfg.percentile]; !ok {
+		return fmt.Errorf("--percentile must be one of: p50, p90, p95, p99")
+	}
+	if cfg.margin < 0 {
+		return fmt.Errorf("--margin must not be negative")
+	}
+	if cfg.targetUtilization <= 0 || cfg.targetUtilization > 1 {
+		return fmt.Errorf("--target-utilization must be between 0 and 1")
+	}
+	return nil
+}
+
+func renderNodeSkewTable(r *analyzer.NodeSkewResult, percentile string) string {
+	var b strings.Builder
+	s := r.Summary
+
+	fmt.Fprintf(&b, "\n=== Node Skew (window %s, %s usage) ===\n\n", r.Metadata.Window, percentile)
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{
+		"Node", "Type", "Pods", "CPU Alloc", "CPU Req", "CPU Used", "Skew", "Mem Alloc", "Mem Req", "Mem Used", "Skew", "Cost",
+	})
+	for i := range r.Nodes {
+		n := &r.Nodes[i]
+		name := n.Node
+		if !n.Schedulable {
+			name += " (unschedulable)"
+		}
+		share := func(v string, pct float64) string {
+			return fmt.Sprintf("%s (%.0f%%)", v, pct)
+		}
+		usedCPU, usedMem := "-", "-"
+		if n.HasMetrics {
+			usedCPU = share(units.Cores(n.UsedCPU), n.CPUUsedPercent)
+			usedMem = share(units.MemoryGi(n.UsedMemoryGi*units.Gi), n.MemUsedPercent)
+		}
+		appendTableRowBestEffort(table, []string{
+			name, n.InstanceType, strconv.Itoa(n.Pods),
+			units.Cores(n.AllocatableCPU), share(units.Cores(n.RequestedCPU), n.CPURequestedPercent),
+			usedCPU, formatSkew(n.SkewCPU),
+			units.MemoryGi(n.AllocatableMemoryGi * units.Gi), share(units.MemoryGi(n.RequestedMemoryGi*units.Gi), n.MemRequestedPercent),
+			usedMem, formatSkew(n.SkewMemory),
+			formatMonthlyCost(n.MonthlyCost),
+		})
+	}
+	renderTableBestEffort(table)
+
+	fmt.Fprintf(&b, "\nCluster: CPU %.0f%% requested / %.0f%% used, memory %.0f%% requested / %.0f%% used of allocatable\n",
+		s.CPURequestedPercent, s.CPUUsedPercent, s.MemRequestedPercent, s.MemUsedPercent)
+	if s.NodesWithoutMetrics > 0 {
+		fmt.Fprintf(&b, "%d node(s) have no usage data in the window\n", s.NodesWithoutMetrics)
+	}
+
+	c := r.Consolidation
+	fmt.Fprintf(&b, "\n=== Consolidation Estimate (%d schedulable nodes, packed to %.0f%%) ===\n\n",
+		c.CandidateNodes, r.Metadata.TargetUtilization*100)
+	if len(c.ExcludedNodes) > 0 {
+		fmt.Fprintf(&b, "  Excluded (cordoned or tainted): %s\n", strings.Join(c.ExcludedNodes, ", "))
+	}
+	if len(c.UnplacedPods) > 0 {
+		fmt.Fprintf(&b, "  %d pod(s) fit on no candidate node, so no nodes are counted as removable: %s\n",
+			len(c.UnplacedPods), strings.Join(c.UnplacedPods, ", "))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "  Current requests:    %d node(s) needed, %d removable by repacking alone\n", c.NodesNeededCurrent, c.RemovableCurrent)
+	fmt.Fprintf(&b, "  Right-sized (+%.0f%%): %d node(s) needed, %d removable\n",
+		r.Metadata.Margin*100, c.NodesNeededRightSized, c.RemovableRightSized)
+	if len(c.RemovableNodes) > 0 {
+		fmt.Fprintf(&b, "  Removable nodes: %s\n", strings.Join(c.RemovableNodes, ", "))
+		fmt.Fprintf(&b, "  Estimated savings: %s\n", formatMonthlyCost(c.MonthlySavings))
+	}
+	if c.PodsWithoutMetrics > 0 {
+		fmt.Fprintf(&b, "  %d pod(s) without usage data were packed at their current requests\n", c.PodsWithoutMetrics)
+	}
+	b.WriteString("\nNote: affinity, tolerations, PDBs, and topology spread are not modeled; removable nodes are an upper bound.\n")
+	return b.String()
+}
+
+func formatSkew(skew float64) string {
+	if skew <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fx", skew)
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var oomConfig struct {
+	prometheusURL          string
+	window                 string
+	margin                 float64
+	output                 string
+	exportFile             string
+	includeQueries         bool
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	silent                 bool
+}
+
+var oomCmd = &cobra.Command{
+	Use:   "oom",
+	Short: "Explain OOMKills per workload and recommend memory limits",
+	Long: `Build a timeline of each OOM-killed workload from container termination
+states, kernel OOM node events (kubelet SystemOOM, node-problem-detector
+OOMKilling), restarts, and rollouts in the window, then classify the cause
+and recommend a memory limit per container. No LLM is involved.
+
+A container whose working set reached its limit before the kill needs a
+larger limit: the recommendation is the limit (or the window's peak working
+set, if higher) plus --margin. When node-level OOM events coincide with kills
+of containers below their limit, the node is overcommitted and the cause is
+reported as node-pressure. Kills within an hour of a rollout are flagged as a
+possible regression in the new revision.
+
+With --prometheus-url, container_memory_working_set_bytes history adds the
+working set before each kill and the window's peak. Without it, recommendations
+are based on the current limits only.
+
+Only the last termination of each live container is visible in pod status, so
+kills of pods that were since replaced are not counted.
+
+Examples:
+  # OOMKills in the last week, cluster-wide
+  kubenow analyze oom --prometheus-url http://localhost:9090
+
+  # One namespace from pod status and events only
+  kubenow analyze oom -n payments --window 24h
+
+  # JSON export
+  kubenow analyze oom --prometheus-url http://localhost:9090 --output json --export-file oom.json`,
+	RunE: runOOM,
+}
+
+func init() {
+	analyzeCmd.AddCommand(oomCmd)
+
+	f := oomCmd.Flags()
+	f.StringVar(&oomConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint for working-set history (optional)")
+	f.StringVar(&oomConfig.window, "window", "7d", "Lookback for kills, events, and rollouts")
+	f.Float64Var(&oomConfig.margin, "margin", analyzer.DefaultOOMMargin,
+		"Headroom added to observed demand for the recommended limit (0.25 = 25%)")
+	f.StringVar(&oomConfig.output, "output", "table", "Output format: table|json")
+	f.StringVar(&oomConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+	addIncludeQueriesFlag(f, &oomConfig.includeQueries)
+	f.StringVar(&oomConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	f.StringVar(&oomConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	f.StringVar(&oomConfig.metricsTenant, "metrics-tenant", "", "Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	f.StringVar(&oomConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
+	f.BoolVar(&oomConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
+}
+
+func runOOM(_ *cobra.Command, _ []string) error {
+	cfg := &oomConfig
+	if cfg.output != "table" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", cfg.output)
+	}
+	if cfg.margin < 0 {
+		return fmt.Errorf("--margin must not be negative")
+	}
+
+	window, err := metrics.ParseDuration(cfg.window)
+	if err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+	timeout, err := time.ParseDuration(cfg.prometheusTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	queryLog := newQueryLog(cfg.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	var provider metrics.MetricsProvider
+	if cfg.prometheusURL != "" {
+		promConfig := metrics.Config{
+			PrometheusURL: cfg.prometheusURL,
+			Timeout:       timeout,
+			Backend:       cfg.metricsBackend,
+			TenantID:      cfg.metricsTenant,
+			QueryLog:      queryLog,
+		}
+		promConfig.QueryOptions, err = resolveClusterLabel(cfg.prometheusClusterLabel, promConfig, kubeClient, timeout, cfg.silent)
+		if err != nil {
+			return err
+		}
+		if provider, err = metrics.NewProvider(promConfig); err != nil {
+			return fmt.Errorf("failed to create metrics provider: %w", err)
+		}
+		healthCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err = provider.Health(healthCtx); err != nil {
+			return fmt.Errorf("prometheus health check failed: %w", err)
+		}
+	} else if !cfg.silent {
+		slog.Info("No --prometheus-url: recommendations use current limits only")
+	}
+
+	result, err := analyzer.AnalyzeOOMKills(context.Background(), kubeClient, provider, analyzer.OOMConfig{
+		Namespace: GetNamespace(),
+		Window:    window,
+		Margin:    cfg.margin,
+	})
+	if err != nil {
+		return fmt.Errorf("oom analysis failed: %w", err)
+	}
+	result.Queries = queryLog.Report(cfg.prometheusURL)
+
+	if cfg.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(cfg.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(cfg.exportFile, renderOOMTable(result)+querylog.Render(result.Queries))
+}
+
+func renderOOMTable(r *analyzer.OOMResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== OOMKills (last %s) ===\n\n", r.Window)
+	if len(r.Workloads) == 0 {
+		b.WriteString("No OOM-killed containers.\n")
+		return b.String()
+	}
+
+	memory := func(v float64) string {
+		if v <= 0 {
+			return "-"
+		}
+		return units.Memory(v)
+	}
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Namespace", "Workload", "Container", "Kills", "Last Kill", "Cause", "Limit", "Peak", "At Kill", "Recommended"})
+	for i := range r.Workloads {
+		w := &r.Workloads[i]
+		for j := range w.Containers {
+			c := &w.Containers[j]
+			limit := memory(c.LimitBytes)
+			if c.LimitBytes == 0 {
+				limit = "none"
+			}
+			appendTableRowBestEffort(table, []string{
+				w.Namespace, w.Kind + "/" + w.Workload, c.Name, strconv.Itoa(c.Kills), w.LastKill.Format(time.RFC3339), w.Cause,
+				limit, memory(c.PeakWorkingSetBytes), memory(c.WorkingSetAtKillBytes), memory(c.RecommendedLimitBytes),
+			})
+		}
+	}
+	renderTableBestEffort(table)
+
+	for i := range r.Workloads {
+		w := &r.Workloads[i]
+		fmt.Fprintf(&b, "\n%s/%s (%s): %d kill(s), cause %s\n", w.Namespace, w.Workload, w.Kind, w.Kills, w.Cause)
+		for j := range w.Timeline {
+			e := &w.Timeline[j]
+			where := e.Pod
+			if e.Container != "" {
+				where += "/" + e.Container
+			}
+			if where == "" {
+				where = e.Node
+			}
+			if where != "" {
+				where += ": "
+			}
+			fmt.Fprintf(&b, "  %s  %-10s  %s%s\n", e.Time.Format(time.RFC3339), e.Kind, where, e.Detail)
+		}
+		for _, finding := range w.Findings {
+			fmt.Fprintf(&b, "  -> %s\n", finding)
+		}
+	}
+	if !r.HasMetrics {
+		b.WriteString("\nNote: without --prometheus-url, peak and pre-kill working sets are unknown;" +
+			" recommendations start from the current limit.\n")
+	}
+	return b.String()
+}
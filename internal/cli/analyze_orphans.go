@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/units"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var orphansConfig struct {
+	jobAge       string
+	output       string
+	exportFile   string
+	costCPU      float64
+	costMemory   float64
+	instanceType string
+	silent       bool
+}
+
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Find orphaned resources and expired namespaces and plan their cleanup",
+	Long: `Find resources that can be removed and list the command that removes each,
+with the CPU, memory, and cost their deletion would reclaim:
+
+  - namespaces past their kube-janitor TTL (janitor/ttl or janitor/expires annotation)
+  - ReplicaSets scaled to zero replicas whose pods are still running
+  - failed Jobs older than --job-age
+  - Services whose selector matches no pods, so they have no endpoints
+  - ConfigMaps and Secrets not referenced by any pod, workload template,
+    ServiceAccount, or Ingress
+
+Objects younger than a day, owned objects, service-account tokens, Helm
+release records, and ConfigMaps and Secrets in kube-system, kube-public, and
+kube-node-lease are never reported. Resources inside an expired namespace are
+only listed with the namespace. No Prometheus needed.
+
+kubenow deletes nothing: review the plan and run the commands yourself.
+Objects read by applications through the API (leader-election ConfigMaps,
+operator state) are not visible as references and may show up as unreferenced.
+
+Examples:
+  # Cluster-wide cleanup plan
+  kubenow analyze orphans
+
+  # One namespace, failed Jobs older than a day
+  kubenow analyze orphans -n ci --job-age 1d
+
+  # JSON export
+  kubenow analyze orphans --output json --export-file orphans.json`,
+	RunE: runOrphans,
+}
+
+func init() {
+	analyzeCmd.AddCommand(orphansCmd)
+
+	f := orphansCmd.Flags()
+	f.StringVar(&orphansConfig.jobAge, "job-age", "7d", "Report failed Jobs that failed longer ago than this")
+	f.StringVar(&orphansConfig.output, "output", "table", "Output format: table|json")
+	f.StringVar(&orphansConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+	f.Float64Var(&orphansConfig.costCPU, "cost-per-cpu-hour", 0, "Cost per CPU core per hour in dollars (overrides instance-type lookup)")
+	f.Float64Var(&orphansConfig.costMemory, "cost-per-gib-hour", 0,
+		"Cost per GiB memory per hour in dollars (overrides instance-type lookup)")
+	f.StringVar(&orphansConfig.instanceType, "instance-type", instanceTypeAuto,
+		"Cloud instance type for pricing, or 'auto' to blend the cluster's node types")
+	f.BoolVar(&orphansConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
+}
+
+func runOrphans(_ *cobra.Command, _ []string) error {
+	cfg := &orphansConfig
+	if cfg.output != "table" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", cfg.output)
+	}
+	jobAge, err := metrics.ParseDuration(cfg.jobAge)
+	if err != nil {
+		return fmt.Errorf("invalid job age: %w", err)
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	ctx := context.Background()
+	result, err := analyzer.FindOrphans(ctx, kubeClient, analyzer.OrphansConfig{
+		Namespace:    GetNamespace(),
+		FailedJobAge: jobAge,
+		Rates:        clusterCostRates(ctx, kubeClient, cfg.instanceType, cfg.costCPU, cfg.costMemory, cfg.silent),
+	})
+	if err != nil {
+		return fmt.Errorf("orphans analysis failed: %w", err)
+	}
+	if !cfg.silent {
+		for _, w := range result.Warnings {
+			slog.Warn("Orphan scan incomplete", "reason", w)
+		}
+	}
+
+	if cfg.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(cfg.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(cfg.exportFile, renderOrphansTable(result))
+}
+
+func renderOrphansTable(r *analyzer.OrphansResult) string {
+	var b strings.Builder
+	b.WriteString("\n=== Orphaned Resources ===\n\n")
+	if len(r.Resources) == 0 {
+		b.WriteString("Nothing to clean up.\n")
+		return b.String()
+	}
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Category", "Namespace", "Name", "Age", "Pods", "CPU", "Memory", "Data", "Reason"})
+	for i := range r.Resources {
+		o := &r.Resources[i]
+		pods, cpu, mem, data := "-", "-", "-", "-"
+		if o.Pods > 0 {
+			pods = strconv.Itoa(o.Pods)
+		}
+		if o.CPU > 0 {
+			cpu = units.Cores(o.CPU)
+		}
+		if o.MemoryGi > 0 {
+			mem = units.Memory(o.MemoryGi * units.Gi)
+		}
+		if o.DataBytes > 0 {
+			data = units.Memory(float64(o.DataBytes))
+		}
+		appendTableRowBestEffort(table, []string{
+			o.Category, o.Namespace, o.Name, formatOrphanAge(r.GeneratedAt.Sub(o.CreatedAt)), pods, cpu, mem, data, o.Reason,
+		})
+	}
+	renderTableBestEffort(table)
+
+	s := &r.Summary
+	fmt.Fprintf(&b, "\n%d resource(s) to clean up", s.Resources)
+	if s.Pods > 0 {
+		fmt.Fprintf(&b, ", reclaiming %d pod(s), %s CPU, %s memory (%s)",
+			s.Pods, units.Cores(s.CPU), units.Memory(s.MemoryGi*units.Gi), formatMonthlyCost(s.MonthlySavings))
+	}
+	if s.DataBytes > 0 {
+		fmt.Fprintf(&b, ", %s of ConfigMap/Secret data", units.Memory(float64(s.DataBytes)))
+	}
+	b.WriteString("\n\nCleanup plan (review before running):\n")
+	for i := range r.Resources {
+		fmt.Fprintf(&b, "  %s\n", r.Resources[i].Command)
+	}
+	return b.String()
+}
+
+// formatOrphanAge renders an object age in days, or hours below a day.
+func formatOrphanAge(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/units"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var pendingConfig struct {
+	output     string
+	exportFile string
+}
+
+var pendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "Explain why pods are unschedulable and what would let them schedule",
+	Long: `Check every unschedulable pod against every node the way the scheduler's
+filters do, and explain why each node rejects it: insufficient CPU, memory,
+or pod slots, untolerated taints, node selector or affinity mismatches,
+pod (anti-)affinity conflicts, unbound claims, and volume zone constraints.
+No LLM is involved.
+
+Pods are grouped by workload. Each workload gets the smallest change that
+would let its pods schedule, in order:
+
+  fits-now          a node has room already; the scheduler has not retried
+  reduce-requests   a request cut of at most 25% fits an existing node
+  scale-node-pool   one more node of an eligible pool would fit the pod
+  new-node-group    no eligible node is large enough, even when empty
+  constraints       no node passes the pod's constraints
+
+Needs cluster-wide read access to nodes and pods, since free capacity
+depends on every pod scheduled on a node.
+
+Examples:
+  # All unschedulable pods
+  kubenow analyze pending
+
+  # One namespace, as JSON
+  kubenow analyze pending -n shop --output json`,
+	RunE: runPending,
+}
+
+func init() {
+	analyzeCmd.AddCommand(pendingCmd)
+	pendingCmd.Flags().StringVar(&pendingConfig.output, "output", "table", "Output format: table|json")
+	pendingCmd.Flags().StringVarP(&pendingConfig.exportFile, "export-file", "o", "", "write to file instead of stdout")
+}
+
+func runPending(_ *cobra.Command, _ []string) error {
+	if pendingConfig.output != "table" && pendingConfig.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", pendingConfig.output)
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	result, err := analyzer.AnalyzePending(context.Background(), kubeClient, analyzer.PendingConfig{
+		Namespace: GetNamespace(),
+	})
+	if err != nil {
+		return fmt.Errorf("pending analysis failed: %w", err)
+	}
+
+	if pendingConfig.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(pendingConfig.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(pendingConfig.exportFile, renderPendingTable(result))
+}
+
+// pendingFixOrder lists fix kinds from the smallest change up.
+var pendingFixOrder = []string{
+	analyzer.PendingFixFitsNow,
+	analyzer.PendingFixReduceRequests,
+	analyzer.PendingFixScaleNodePool,
+	analyzer.PendingFixNewNodeGroup,
+	analyzer.PendingFixConstraints,
+}
+
+func renderPendingTable(r *analyzer.PendingResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== Unschedulable pods (%d nodes) ===\n\n", r.Nodes)
+	if len(r.Workloads) == 0 {
+		b.WriteString("No unschedulable pods.\n")
+		return b.String()
+	}
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Namespace", "Workload", "Kind", "Pods", "Age", "CPU", "Memory", "Fix"})
+	for i := range r.Workloads {
+		w := &r.Workloads[i]
+		appendTableRowBestEffort(table, []string{
+			w.Namespace, w.Workload, w.Kind, strconv.Itoa(w.Pods), w.Age,
+			units.Cores(w.CPURequest), units.Memory(w.MemoryRequestBytes), w.Fix.Kind,
+		})
+	}
+	renderTableBestEffort(table)
+
+	for i := range r.Workloads {
+		w := &r.Workloads[i]
+		fmt.Fprintf(&b, "\n%s/%s (%s), %d pod(s) pending for %s:\n", w.Namespace, w.Workload, w.Kind, w.Pods, w.Age)
+		if w.SchedulerMessage != "" {
+			fmt.Fprintf(&b, "  scheduler: %s\n", w.SchedulerMessage)
+		}
+		for _, reason := range w.Reasons {
+			fmt.Fprintf(&b, "  %d node(s): %s\n", reason.Nodes, reason.Reason)
+		}
+		fmt.Fprintf(&b, "  -> %s\n", w.Fix.Detail)
+	}
+
+	var parts []string
+	for _, kind := range pendingFixOrder {
+		if n := r.Summary[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", kind, n))
+		}
+	}
+	fmt.Fprintf(&b, "\nSmallest fix per workload: %s\n", strings.Join(parts, ", "))
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var priorityConfig struct {
+	window     string
+	output     string
+	exportFile string
+}
+
+var priorityCmd = &cobra.Command{
+	Use:   "priority",
+	Short: "Find workloads at risk of preemption due to pod priority",
+	Long: `Report workloads running at the cluster default priority in namespaces
+that also host higher-priority (critical) services, and recent scheduler
+preemption events.
+
+Default-priority pods are the first victims when a critical pod needs room.
+Lowering their requests (as requests-skew recommends for over-provisioned
+workloads) makes them cheaper to preempt and, once usage exceeds requests,
+earlier node-pressure eviction candidates. Each at-risk workload gets a
+suggested priorityClassName taken from its critical neighbours.
+
+Examples:
+  # Cluster-wide priority risk report
+  kubenow analyze priority
+
+  # One namespace, preemptions from the last 7 days, as JSON
+  kubenow analyze priority -n payments --window 168h --output json`,
+	RunE: runPriority,
+}
+
+func init() {
+	analyzeCmd.AddCommand(priorityCmd)
+	priorityCmd.Flags().StringVar(&priorityConfig.window, "window", analyzer.DefaultPreemptionWindow.String(), "Lookback for preemption events (e.g., 24h, 168h)")
+	priorityCmd.Flags().StringVar(&priorityConfig.output, "output", "table", "Output format: table|json")
+	priorityCmd.Flags().StringVarP(&priorityConfig.exportFile, "export-file", "o", "", "write to file instead of stdout")
+}
+
+func runPriority(_ *cobra.Command, _ []string) error {
+	if priorityConfig.output != "table" && priorityConfig.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", priorityConfig.output)
+	}
+
+	window, err := time.ParseDuration(priorityConfig.window)
+	if err != nil {
+		return fmt.Errorf("invalid --window: %w", err)
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	result, err := analyzer.AnalyzePriorityRisk(context.Background(), kubeClient, analyzer.PriorityRiskConfig{
+		Namespace: GetNamespace(),
+		Window:    window,
+	})
+	if err != nil {
+		return fmt.Errorf("priority analysis failed: %w", err)
+	}
+
+	if priorityConfig.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(priorityConfig.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(priorityConfig.exportFile, renderPriorityTable(result))
+}
+
+func renderPriorityTable(r *analyzer.PriorityRiskResult) string {
+	var b strings.Builder
+
+	defaultClass := r.DefaultClass
+	if defaultClass == "" {
+		defaultClass = "none"
+	}
+	fmt.Fprintf(&b, "\nDefault priority: %d (globalDefault class: %s)\n", r.DefaultPriority, defaultClass)
+	if len(r.CriticalNamespaces) > 0 {
+		fmt.Fprintf(&b, "Namespaces hosting higher-priority workloads: %s\n", strings.Join(r.CriticalNamespaces, ", "))
+	}
+	b.WriteString("\n")
+
+	if len(r.AtRisk) == 0 {
+		b.WriteString("No workloads at preemption risk.\n")
+	} else {
+		table := tablewriter.NewWriter(&b)
+		table.Header([]string{"Namespace", "Workload", "Kind", "Priority", "Preemptions", "Suggested Class", "Reason"})
+		for i := range r.AtRisk {
+			w := &r.AtRisk[i]
+			suggested := w.SuggestedPriorityClass
+			if suggested == "" {
+				suggested = "—"
+			}
+			appendTableRowBestEffort(table, []string{
+				w.Namespace, w.Workload, w.Kind, strconv.Itoa(int(w.Priority)), strconv.Itoa(w.Preemptions), suggested, w.Reason,
+			})
+		}
+		renderTableBestEffort(table)
+	}
+
+	if len(r.Preemptions) > 0 {
+		fmt.Fprintf(&b, "\nPreemption events (last %s):\n", r.Window)
+		for i := range r.Preemptions {
+			e := &r.Preemptions[i]
+			fmt.Fprintf(&b, "  %s  %s/%s (x%d): %s\n", e.LastSeen.Format(time.RFC3339), e.Namespace, e.Pod, e.Count, e.Message)
+		}
+	}
+	return b.String()
+}
@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
@@ -15,33 +18,46 @@ import (
 
 	"github.com/ppiankov/kubenow/internal/analyzer"
 	"github.com/ppiankov/kubenow/internal/baseline"
+	"github.com/ppiankov/kubenow/internal/cleanup"
 	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/export"
+	"github.com/ppiankov/kubenow/internal/issues"
+	"github.com/ppiankov/kubenow/internal/keymap"
 	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/output"
+	"github.com/ppiankov/kubenow/internal/owners"
+	"github.com/ppiankov/kubenow/internal/picker"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/skewbrowser"
 	"github.com/ppiankov/kubenow/internal/trend"
+	"github.com/ppiankov/kubenow/internal/units"
 	"github.com/ppiankov/kubenow/internal/util"
 )
 
 var requestsSkewConfig struct {
-	prometheusURL       string
-	autoDetect          bool
-	window              string
-	top                 int
-	namespaceRegex      string
-	namespaceInclude    string
-	namespaceExclude    string
-	minRuntimeDays      int
-	output              string
-	exportFile          string
-	exportFormat        string
-	prometheusTimeout   string
-	watchForSpikes      bool
-	spikeDuration       string
-	spikeInterval       string
-	showRecommendations bool
-	safetyFactor        float64
-	silent              bool
-	sortBy              string
+	prometheusURL          string
+	autoDetect             bool
+	window                 string
+	top                    int
+	namespaceRegex         string
+	namespaceInclude       string
+	namespaceExclude       string
+	minRuntimeDays         int
+	output                 string
+	exportFile             string
+	exportFormat           string
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	watchForSpikes         bool
+	spikeDuration          string
+	spikeInterval          string
+	showRecommendations    bool
+	safetyFactor           float64
+	memorySafetyFactor     float64
+	silent                 bool
+	sortBy                 string
 	// Port-forward options
 	k8sService         string
 	k8sNamespace       string
@@ -50,8 +66,14 @@ var requestsSkewConfig struct {
 	portforwardTimeout string
 	// Security options
 	obfuscate bool
+	// Evidence options
+	includeQueries bool
+	// Findings endpoint
+	metricsPort     int
+	metricsInterval string
 	// CI/CD options
-	failOn string
+	failOn      string
+	verdictFile string
 	// Cost estimation options
 	costCPU      float64
 	costMemory   float64
@@ -62,7 +84,35 @@ var requestsSkewConfig struct {
 	// Trend tracking
 	trackTrends bool
 	// Concurrency
-	workers int
+	workers        int
+	concurrency    int
+	queryRate      float64
+	batchQueries   bool
+	groupTemplates bool
+	// Rollups
+	groupBy    string
+	teamLabel  string
+	ownersFile string
+	// Admin policy (resource_ratios notes)
+	policyFile string
+	// Scope selection
+	interactive   bool
+	workloadKinds string
+	// Results browser
+	browse bool
+	// Multi-cluster
+	contexts    string
+	allContexts bool
+	// Issue tracker
+	createIssues  string
+	githubRepo    string
+	githubToken   string
+	githubURL     string
+	jiraURL       string
+	jiraProject   string
+	jiraIssueType string
+	jiraUser      string
+	jiraToken     string
 }
 
 // spikeWorkload holds spike data with calculated ratios
@@ -104,7 +154,17 @@ Examples:
 
   # Use native port-forward to in-cluster Prometheus
   kubenow analyze requests-skew --k8s-service prometheus-operated \
-    --k8s-namespace monitoring`,
+    --k8s-namespace monitoring
+
+  # Pick namespaces and workload kinds from a list, with a query estimate
+  kubenow analyze requests-skew --prometheus-url http://localhost:9090 --interactive
+
+  # Browse all results: expand, filter, sort, and export a selection
+  kubenow analyze requests-skew --prometheus-url http://localhost:9090 --top 0 --browse
+
+  # Several clusters behind one Thanos, with a cross-cluster summary
+  kubenow analyze requests-skew --contexts staging,prod-eu,prod-us \
+    --prometheus-url http://thanos:9090 --prometheus-cluster-label auto`,
 	RunE: runRequestsSkew,
 }
 
@@ -112,44 +172,72 @@ func init() {
 	analyzeCmd.AddCommand(requestsSkewCmd)
 
 	// Required flags
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint (e.g., http://prometheus:9090)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.prometheusURL, "prometheus-url", "",
+		"Prometheus endpoint (e.g., http://prometheus:9090)")
 	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.autoDetect, "auto-detect-prometheus", false, "Auto-discover Prometheus in cluster")
 
 	// Optional flags
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.window, "window", "30d", "Time window for analysis (e.g., 7d, 24h, 30d)")
 	requestsSkewCmd.Flags().IntVar(&requestsSkewConfig.top, "top", 10, "Top N results (0 = all)")
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.namespaceRegex, "namespace-regex", ".*", "Namespace filter regex")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.namespaceInclude, "namespace-include", "", "Include only these namespaces (comma-separated patterns)")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.namespaceExclude, "namespace-exclude", "", "Exclude these namespaces (comma-separated patterns)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.namespaceInclude, "namespace-include", "",
+		"Include only these namespaces (comma-separated patterns)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.namespaceExclude, "namespace-exclude", "",
+		"Exclude these namespaces (comma-separated patterns)")
 	requestsSkewCmd.Flags().IntVar(&requestsSkewConfig.minRuntimeDays, "min-runtime-days", 7, "Ignore workloads younger than N days")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.output, "output", "table", "Output format: table|json")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.output, "output", "table", "Output format: table|json|sarif|junit|html")
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.exportFile, "export-file", "", "Save to file (optional)")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.exportFormat, "export-format", "json", "Export file format: json|table")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.exportFormat, "export-format", "json",
+		"Export file format: json|table|html|sarif|junit")
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.sortBy, "sort-by", "impact", "Sort results by: impact|skew|cpu|memory|name")
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.metricsTenant, "metrics-tenant", "",
+		"Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
 
 	// Spike monitoring flags (experimental)
 	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.watchForSpikes, "watch-for-spikes", false, "Enable real-time spike monitoring (experimental)")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.spikeDuration, "spike-duration", "15m", "How long to monitor for spikes (e.g., 15m, 1h, 24h)")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.spikeInterval, "spike-interval", "5s", "Sampling interval for spike detection (e.g., 1s, 5s)")
-	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.showRecommendations, "show-recommendations", false, "Show calculated CPU request recommendations based on spike data")
-	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.safetyFactor, "safety-factor", 0.0, "Override safety factor for recommendations (default: auto-select based on spike ratio)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.spikeDuration, "spike-duration", "15m",
+		"How long to monitor for spikes (e.g., 15m, 1h, 24h)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.spikeInterval, "spike-interval", "5s",
+		"Sampling interval for spike detection (e.g., 1s, 5s)")
+	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.showRecommendations, "show-recommendations", false,
+		"Show calculated CPU and memory recommendations based on spike data")
+	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.safetyFactor, "safety-factor", 0.0,
+		"Override CPU safety factor for recommendations (default: auto-select based on spike ratio)")
+	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.memorySafetyFactor, "memory-safety-factor", 0.0,
+		"Override memory safety factor for recommendations (default: auto-select from memory variance and OOMKills)")
 
 	// CI/CD flags
 	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
 
 	// Kubernetes port-forward flags
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.k8sService, "k8s-service", "", "Kubernetes service name for port-forward (e.g., 'prometheus-operated')")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.k8sService, "k8s-service", "",
+		"Kubernetes service name for port-forward (e.g., 'prometheus-operated')")
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.k8sNamespace, "k8s-namespace", "monitoring", "Kubernetes namespace for service")
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.k8sLocalPort, "k8s-local-port", "9090", "Local port for port-forward")
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.k8sRemotePort, "k8s-remote-port", "9090", "Remote port for port-forward")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.portforwardTimeout, "portforward-timeout", "30s", "Timeout for port-forward readiness (e.g., 30s, 1m)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.portforwardTimeout, "portforward-timeout", "30s",
+		"Timeout for port-forward readiness (e.g., 30s, 1m)")
 
 	// Security/privacy flags
 	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.obfuscate, "obfuscate", false, "Obfuscate sensitive names (namespaces, pods, services, nodes)")
+	addIncludeQueriesFlag(requestsSkewCmd.Flags(), &requestsSkewConfig.includeQueries)
+
+	// Findings endpoint flags
+	requestsSkewCmd.Flags().IntVar(&requestsSkewConfig.metricsPort, "metrics-port", 0,
+		"Keep running and serve skew and waste gauges on this port's /metrics, re-analyzing every --metrics-interval (0 = disabled)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.metricsInterval, "metrics-interval", "1h",
+		"Re-analysis interval with --metrics-port (e.g., 30m, 6h)")
 
 	// CI/CD flags
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.failOn, "fail-on", "", "Exit with code 1 if problems at or above severity found (fatal|critical|warning)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.failOn, "fail-on", "",
+		"Exit with code 1 if problems at or above severity are found (fatal|critical|warning) or a summary threshold is crossed "+
+			"(comma-separated, e.g. avg-skew-cpu>5,wasted-cpu>50; metrics: "+strings.Join(analyzer.ThresholdMetricNames(), ", ")+")")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.verdictFile, "verdict-file", "",
+		"Also write the --fail-on threshold verdict JSON to this file")
 
 	// Baseline/drift flags
 	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.saveBaseline, "save-baseline", "", "Save analysis results as baseline to file")
@@ -160,23 +248,143 @@ func init() {
 
 	// Concurrency
 	requestsSkewCmd.Flags().IntVar(&requestsSkewConfig.workers, "workers", 1, "Max concurrent workload queries (1 = sequential, max 20)")
+	requestsSkewCmd.Flags().IntVar(&requestsSkewConfig.concurrency, "analysis-concurrency", 1,
+		fmt.Sprintf("Namespaces analyzed in parallel (1 = sequential, max %d)", analyzer.MaxAnalysisConcurrency))
+	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.queryRate, "max-query-rate", 0,
+		"Max Prometheus queries per second across all namespace and workload workers (0 = unlimited)")
+	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.batchQueries, "batch-queries", true,
+		"Fetch CPU and memory usage, requests, and limits for all workloads of a namespace in a few grouped-by-pod queries")
+	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.groupTemplates, "group-templates", false,
+		"Analyze workloads deployed from the same chart or images in several namespaces once, and report one recommendation per template")
+
+	// Rollup flags
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.groupBy, "group-by", "",
+		"Roll skew and waste up per "+strings.Join(analyzer.GroupByOptions, "|")+" across all analyzed workloads")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.teamLabel, "team-label", analyzer.DefaultTeamLabel,
+		"Workload label (or, if absent, namespace label) naming the team for --group-by team")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.ownersFile, "owners-file", "",
+		"YAML file mapping namespaces and label selectors to owning teams; with "+teamPlaceholder+" in --export-file, writes one report per team")
+
+	// Scope selection
+	requestsSkewCmd.Flags().BoolVarP(&requestsSkewConfig.interactive, "interactive", "i", false,
+		"Pick namespaces (with pod counts) and workload kinds interactively, and review the query estimate before running")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.workloadKinds, "workload-kinds", "",
+		"Analyze only these workload kinds (comma-separated: "+strings.Join(analyzer.RequestsSkewKinds, ",")+"; default all)")
+
+	// Results browser
+	requestsSkewCmd.Flags().BoolVar(&requestsSkewConfig.browse, "browse", false,
+		"Browse the results in a terminal UI (scroll, expand details, filter by namespace, sort, export a selection) instead of printing them")
+
+	// Multi-cluster flags
+	addContextsFlags(requestsSkewCmd, &requestsSkewConfig.contexts, &requestsSkewConfig.allContexts)
+
+	// Issue tracker flags
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.createIssues, "create-issues", "",
+		"File one issue per team (with --owners-file) or namespace listing its top over-provisioned workloads: github|jira; "+
+			"re-runs update the same issues")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.githubRepo, "github-repo", "",
+		"GitHub repository (owner/name) for --create-issues github")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.githubToken, "github-token", "",
+		"GitHub token for --create-issues github (default: $GITHUB_TOKEN)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.githubURL, "github-url", issues.DefaultGitHubURL,
+		"GitHub API URL (GitHub Enterprise Server: https://<host>/api/v3)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.jiraURL, "jira-url", "",
+		"Jira base URL for --create-issues jira (e.g., https://example.atlassian.net)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.jiraProject, "jira-project", "", "Jira project key for --create-issues jira")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.jiraIssueType, "jira-issue-type", issues.DefaultJiraIssueType,
+		"Jira issue type for --create-issues jira")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.jiraUser, "jira-user", "",
+		"Jira account email for API token auth (default: $JIRA_USER; empty sends the token as a bearer token)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.jiraToken, "jira-token", "",
+		"Jira API token or personal access token (default: $JIRA_API_TOKEN)")
+
+	// Policy flags
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.policyFile, "policy", "", "path to admin policy file (adds resource_ratios notes)")
 
 	// Cost estimation flags
-	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.costCPU, "cost-cpu", 0, "Cost per CPU core per hour in dollars (overrides instance-type lookup)")
-	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.costMemory, "cost-memory", 0, "Cost per GiB memory per hour in dollars (overrides instance-type lookup)")
-	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.instanceType, "instance-type", "", "Node instance type for pricing lookup (e.g., m5.xlarge, n2-standard-4)")
+	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.costCPU, "cost-per-cpu-hour", 0, "Cost per CPU core per hour in dollars (overrides instance-type lookup)")
+	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.costMemory, "cost-per-gib-hour", 0, "Cost per GiB memory per hour in dollars (overrides instance-type lookup)")
+	requestsSkewCmd.Flags().StringVar(&requestsSkewConfig.instanceType, "instance-type", "",
+		"Node instance type for pricing lookup (e.g., m5.xlarge, n2-standard-4), or 'auto' to price by each node's instance-type label")
+	// Deprecated aliases, kept for existing scripts
+	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.costCPU, "cost-cpu", 0, "Cost per CPU core per hour in dollars")
+	requestsSkewCmd.Flags().Float64Var(&requestsSkewConfig.costMemory, "cost-memory", 0, "Cost per GiB memory per hour in dollars")
+	mustMarkFlagDeprecated(requestsSkewCmd, "cost-cpu", "use --cost-per-cpu-hour")
+	mustMarkFlagDeprecated(requestsSkewCmd, "cost-memory", "use --cost-per-gib-hour")
 }
 
 func runRequestsSkew(_ *cobra.Command, _ []string) error {
 	// Silent mode is passed via config to the analyzer (no global state)
 
+	// The query log carries real namespace and workload names
+	if requestsSkewConfig.includeQueries && requestsSkewConfig.obfuscate {
+		return fmt.Errorf("--include-queries cannot be combined with --obfuscate")
+	}
+
+	failOn, err := parseRequestsSkewFailOn(requestsSkewConfig.failOn)
+	if err != nil {
+		return err
+	}
+	if requestsSkewConfig.verdictFile != "" && len(failOn.thresholds) == 0 {
+		return fmt.Errorf("--verdict-file requires --fail-on thresholds")
+	}
+
+	if requestsSkewConfig.groupBy != "" && !slices.Contains(analyzer.GroupByOptions, requestsSkewConfig.groupBy) {
+		return fmt.Errorf("invalid --group-by %q (must be: %s)", requestsSkewConfig.groupBy, strings.Join(analyzer.GroupByOptions, "|"))
+	}
+
+	teamOwners, err := loadOwners(requestsSkewConfig.ownersFile)
+	if err != nil {
+		return err
+	}
+	perTeam := strings.Contains(requestsSkewConfig.exportFile, teamPlaceholder)
+	if perTeam && teamOwners == nil {
+		return fmt.Errorf("%s in --export-file requires --owners-file", teamPlaceholder)
+	}
+
+	if err := validateIssueFlags(); err != nil {
+		return err
+	}
+	tracker, err := newIssueTracker()
+	if err != nil {
+		return err
+	}
+
+	var metricsInterval time.Duration
+	if requestsSkewConfig.metricsPort > 0 {
+		if requestsSkewConfig.failOn != "" || requestsSkewConfig.compareBaseline != "" || requestsSkewConfig.includeQueries {
+			return fmt.Errorf("--metrics-port cannot be combined with --fail-on, --compare-baseline, or --include-queries")
+		}
+		if requestsSkewConfig.contexts != "" || requestsSkewConfig.allContexts {
+			return fmt.Errorf("--metrics-port cannot be combined with --contexts or --all-contexts")
+		}
+		if metricsInterval, err = time.ParseDuration(requestsSkewConfig.metricsInterval); err != nil || metricsInterval <= 0 {
+			return fmt.Errorf("invalid --metrics-interval %q: must be a positive duration", requestsSkewConfig.metricsInterval)
+		}
+	}
+
+	var browseKeys *keymap.Map
+	if requestsSkewConfig.browse {
+		if err := validateRequestsSkewBrowse(perTeam); err != nil {
+			return err
+		}
+		if browseKeys, err = skewbrowser.NewKeyMap(GetKeyBindings("requests-skew")); err != nil {
+			return fmt.Errorf("invalid keybindings.requests-skew in config: %w", err)
+		}
+	}
+
+	contexts, err := resolveContexts(requestsSkewConfig.contexts, requestsSkewConfig.allContexts)
+	if err != nil {
+		return err
+	}
+	if contexts != nil {
+		return runRequestsSkewContexts(contexts, teamOwners, failOn)
+	}
+
 	// Setup kubectl port-forward if k8s-service is specified
 	var portForward *util.PortForward
 	if requestsSkewConfig.k8sService != "" {
-		if IsVerbose() {
-			stderrf("[kubenow] Setting up native port-forward to %s/%s...\n",
-				requestsSkewConfig.k8sNamespace, requestsSkewConfig.k8sService)
-		}
+		slog.Debug("Setting up native port-forward...", "service", requestsSkewConfig.k8sNamespace+"/"+requestsSkewConfig.k8sService)
 
 		pfTimeout, err := time.ParseDuration(requestsSkewConfig.portforwardTimeout)
 		if err != nil {
@@ -184,6 +392,7 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 		}
 
 		portForward, err = util.NewPortForward(
+			GetKubeOpts(),
 			requestsSkewConfig.k8sService,
 			requestsSkewConfig.k8sNamespace,
 			requestsSkewConfig.k8sLocalPort,
@@ -201,16 +410,14 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 		// Stop port-forward on exit
 		defer func() {
 			if err := portForward.Stop(); err != nil {
-				stderrf("[kubenow] Warning: failed to stop port-forward: %v\n", err)
+				slog.Warn("Failed to stop port-forward", "error", err)
 			}
 		}()
 
 		// Use localhost URL if port-forward is active
 		if requestsSkewConfig.prometheusURL == "" {
 			requestsSkewConfig.prometheusURL = fmt.Sprintf("http://localhost:%s", requestsSkewConfig.k8sLocalPort)
-			if IsVerbose() {
-				stderrf("[kubenow] Using port-forward URL: %s\n", requestsSkewConfig.prometheusURL)
-			}
+			slog.Debug("Using port-forward URL", "url", requestsSkewConfig.prometheusURL)
 		}
 	}
 
@@ -220,9 +427,7 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 			return fmt.Errorf("either --prometheus-url, --k8s-service, or --auto-detect-prometheus is required")
 		}
 
-		if IsVerbose() {
-			stderrln("[kubenow] Auto-detecting Prometheus in cluster...")
-		}
+		slog.Debug("Auto-detecting Prometheus in cluster...")
 
 		detectClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
 		if err != nil {
@@ -238,15 +443,33 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 		}
 
 		requestsSkewConfig.prometheusURL = detectedURL
-		stderrf("[kubenow] Discovered Prometheus at %s\n", detectedURL)
+		slog.Info("Discovered Prometheus", "url", detectedURL)
 	}
 
-	if requestsSkewConfig.output != "table" && requestsSkewConfig.output != "json" && requestsSkewConfig.output != "sarif" {
-		return fmt.Errorf("--output must be 'table', 'json', or 'sarif'")
+	switch requestsSkewConfig.output {
+	case "table", "json", "sarif", "junit", "html":
+	default:
+		return fmt.Errorf("--output must be 'table', 'json', 'sarif', 'junit', or 'html'")
 	}
 
-	if requestsSkewConfig.exportFormat != "table" && requestsSkewConfig.exportFormat != "json" {
-		return fmt.Errorf("--export-format must be 'table' or 'json'")
+	switch requestsSkewConfig.exportFormat {
+	case "table", "json", "html", "sarif", "junit":
+	default:
+		return fmt.Errorf("--export-format must be 'table', 'json', 'html', 'sarif', or 'junit'")
+	}
+
+	workloadKinds, err := parseWorkloadKinds(requestsSkewConfig.workloadKinds)
+	if err != nil {
+		return err
+	}
+	var pickerKeys *keymap.Map
+	if requestsSkewConfig.interactive {
+		if !stdinIsTerminal() {
+			return fmt.Errorf("--interactive needs a terminal")
+		}
+		if pickerKeys, err = picker.NewKeyMap(GetKeyBindings("picker")); err != nil {
+			return fmt.Errorf("invalid keybindings.picker in config: %w", err)
+		}
 	}
 
 	// Parse window duration
@@ -262,101 +485,62 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 	}
 
 	// Build Kubernetes client
-	if IsVerbose() {
-		stderrln("[kubenow] Building Kubernetes client...")
-	}
+	slog.Debug("Building Kubernetes client...")
 
-	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	queryLog := newQueryLog(requestsSkewConfig.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
 	if err != nil {
 		return fmt.Errorf("failed to build Kubernetes client: %w", err)
 	}
 
 	// Create Prometheus client
-	if IsVerbose() {
-		stderrf("[kubenow] Connecting to Prometheus: %s\n", requestsSkewConfig.prometheusURL)
-	}
-
-	promConfig := metrics.Config{
-		PrometheusURL: requestsSkewConfig.prometheusURL,
-		Timeout:       timeout,
-	}
+	slog.Debug("Connecting to Prometheus", "url", requestsSkewConfig.prometheusURL)
 
-	metricsProvider, err := metrics.NewPrometheusClient(promConfig)
+	metricsProvider, err := newRequestsSkewProvider(requestsSkewConfig.prometheusURL, timeout, kubeClient, queryLog)
 	if err != nil {
-		return fmt.Errorf("failed to create Prometheus client: %w", err)
+		return err
 	}
 
 	// Health check — use timeout to prevent unbounded calls
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
+	healthStart := time.Now()
 	if err = metricsProvider.Health(ctx); err != nil {
 		return fmt.Errorf("prometheus health check failed: %w", err)
 	}
+	queryLatency := time.Since(healthStart)
 
-	// Discover available metrics
-	if !requestsSkewConfig.silent {
-		stderrln("[kubenow] Discovering available Prometheus metrics...")
+	if err := checkRequestsSkewMetrics(ctx, metricsProvider); err != nil {
+		return err
 	}
 
-	discovery := metrics.NewMetricDiscovery(metricsProvider.GetAPI())
-	availableMetrics, err := discovery.DiscoverMetrics(ctx)
-	if err != nil {
-		return fmt.Errorf("metric discovery failed: %w", err)
-	}
-
-	// Validate that required metrics exist
-	if err = availableMetrics.ValidateMetrics(); err != nil {
-		stderrf("\n⚠️  Metric Discovery Failed:\n")
-		stderrf("════════════════════════\n\n")
-		stderrf("%s\n\n", err.Error())
-		stderrf("Available metrics in Prometheus:\n")
-		if len(availableMetrics.AllCPU) > 0 {
-			stderrf("  CPU-related: %v\n", availableMetrics.AllCPU)
-		} else {
-			stderrf("  CPU-related: (none found)\n")
-		}
-		if len(availableMetrics.AllMemory) > 0 {
-			stderrf("  Memory-related: %v\n", availableMetrics.AllMemory)
-		} else {
-			stderrf("  Memory-related: (none found)\n")
-		}
-		stderrf("\nPossible causes:\n")
-		stderrf("  • cAdvisor metrics not being scraped\n")
-		stderrf("  • ServiceMonitor/PodMonitor not configured\n")
-		stderrf("  • Prometheus scrape config missing container metrics\n")
-		stderrf("\nSee README troubleshooting section for details.\n")
-		return fmt.Errorf("required metrics not available in Prometheus")
-	}
-
-	if !requestsSkewConfig.silent {
-		stderrf("[kubenow] Using metrics: CPU=%s, Memory=%s\n",
-			availableMetrics.CPUMetric, availableMetrics.MemoryMetric)
-	}
-
-	if IsVerbose() {
-		stderrln("[kubenow] Analyzing resource requests vs usage...")
-	}
+	slog.Debug("Analyzing resource requests vs usage...")
 
 	// Validate sort-by option
-	validSortOptions := map[string]bool{
-		"impact": true, "skew": true, "cpu": true, "memory": true, "name": true,
-	}
-	if !validSortOptions[requestsSkewConfig.sortBy] {
-		return fmt.Errorf("invalid --sort-by option: %s (must be: impact|skew|cpu|memory|name)", requestsSkewConfig.sortBy)
+	if !slices.Contains(analyzer.SortOptions, requestsSkewConfig.sortBy) {
+		return fmt.Errorf("invalid --sort-by option: %s (must be: %s)", requestsSkewConfig.sortBy, strings.Join(analyzer.SortOptions, "|"))
 	}
 
 	// Create analyzer
-	analyzerConfig := analyzer.RequestsSkewConfig{
-		Window:           window,
-		Top:              requestsSkewConfig.top,
-		Namespace:        GetNamespace(), // Use global --namespace flag if provided
-		NamespaceRegex:   requestsSkewConfig.namespaceRegex,
-		NamespaceInclude: requestsSkewConfig.namespaceInclude,
-		NamespaceExclude: requestsSkewConfig.namespaceExclude,
-		MinRuntimeDays:   requestsSkewConfig.minRuntimeDays,
-		SortBy:           requestsSkewConfig.sortBy,
-		Silent:           requestsSkewConfig.silent,
-		Workers:          requestsSkewConfig.workers,
+	analyzerConfig := newRequestsSkewAnalyzerConfig(window, workloadKinds, resolveCostRates(ctx, kubeClient), teamOwners)
+	if perTeam || tracker != nil {
+		// Each team's report and each issue gets its own top N, cut below
+		analyzerConfig.Top = 0
+	}
+
+	if requestsSkewConfig.interactive {
+		ok, err := pickRequestsSkewScope(kubeClient, metricsProvider, &analyzerConfig, pickerKeys, queryLatency)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			slog.Info("Cancelled")
+			return nil
+		}
+		// The picker may have outlasted the timeout; restart it for the run
+		runCtx, runCancel := context.WithTimeout(context.Background(), timeout)
+		defer runCancel()
+		ctx = runCtx
 	}
 
 	skewAnalyzer := analyzer.NewRequestsSkewAnalyzer(kubeClient, metricsProvider, &analyzerConfig)
@@ -366,13 +550,33 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
+	result.Metadata.Cluster = extractClusterName(GetKubeconfig())
+	result.Metadata.PrometheusURL = requestsSkewConfig.prometheusURL
+
+	// Annotate workloads that break the limit/request ratio policy
+	ratios, err := loadRatioPolicy(requestsSkewConfig.policyFile)
+	if err != nil {
+		slog.Warn("Skipping ratio policy notes", "error", err)
+	}
+	if n := analyzer.ApplyRatioNotes(result, ratios); n > 0 {
+		slog.Info("Workloads violate the limit/request ratio policy (see note field)", "workloads", n)
+	}
+
+	// Flag over-provisioned workloads whose lower requests would raise preemption risk
+	priorityRisk, err := analyzer.AnalyzePriorityRisk(ctx, kubeClient, analyzer.PriorityRiskConfig{})
+	if err != nil {
+		slog.Warn("Skipping preemption risk notes", "error", err)
+	} else if n := analyzer.ApplyPriorityNotes(result, priorityRisk); n > 0 {
+		slog.Info("Workloads at default priority would become likelier preemption victims (see note field, analyze priority)",
+			"workloads", n)
+	}
 
 	// Run spike monitoring if requested
 	var spikeData map[string]*metrics.SpikeData
 	if requestsSkewConfig.watchForSpikes {
 		spikeData, err = runSpikeMonitoring(ctx, kubeClient)
 		if err != nil {
-			stderrf("[kubenow] Warning: Spike monitoring failed: %v\n", err)
+			slog.Warn("Spike monitoring failed", "error", err)
 			// Continue with analysis results even if spike monitoring fails
 		}
 
@@ -385,9 +589,19 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 		}
 	}
 
-	// Compute cost estimates if any cost flag is provided
-	if requestsSkewConfig.costCPU > 0 || requestsSkewConfig.costMemory > 0 || requestsSkewConfig.instanceType != "" {
-		attachCostEstimates(result)
+	// Attach the query log last so it covers the preemption and spike requests
+	result.Queries = queryLog.Report(requestsSkewConfig.prometheusURL)
+
+	var teamResults []*analyzer.RequestsSkewResult
+	if perTeam {
+		teamResults = splitRequestsSkewByTeam(result, requestsSkewConfig.top)
+	}
+	var skewIssues []*issues.Issue
+	if tracker != nil {
+		skewIssues = requestsSkewIssues(result, teamOwners != nil, requestsSkewConfig.top)
+	}
+	if perTeam || tracker != nil {
+		truncateResults(result, requestsSkewConfig.top)
 	}
 
 	// Save trend snapshot if requested (before obfuscation to capture real names)
@@ -404,6 +618,9 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 		if spikeData != nil {
 			obfuscateSpikeData(spikeData, obfuscator)
 		}
+		for _, teamResult := range teamResults {
+			obfuscateResults(teamResult, obfuscator)
+		}
 	}
 
 	// Save baseline if requested
@@ -411,7 +628,7 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 		if err := baseline.SaveBaseline(result, requestsSkewConfig.saveBaseline, version); err != nil {
 			return fmt.Errorf("failed to save baseline: %w", err)
 		}
-		stderrf("[kubenow] Baseline saved to: %s\n", requestsSkewConfig.saveBaseline)
+		slog.Info("Baseline saved", "path", requestsSkewConfig.saveBaseline)
 	}
 
 	// Compare to baseline if requested
@@ -430,15 +647,36 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
-	// Output results
+	// Output results; per-team reports go to their own files
+	exportFile := requestsSkewConfig.exportFile
+	if perTeam {
+		exportFile = ""
+	}
 	var outputErr error
-	switch requestsSkewConfig.output {
-	case "json":
-		outputErr = outputRequestsSkewJSON(result, requestsSkewConfig.exportFile)
-	case "sarif":
-		outputErr = outputRequestsSkewSARIF(result, requestsSkewConfig.exportFile)
-	default:
-		outputErr = outputRequestsSkewTable(result, spikeData, requestsSkewConfig.exportFile, requestsSkewConfig.exportFormat)
+	if requestsSkewConfig.browse {
+		outputErr = browseRequestsSkew(result, browseKeys)
+	} else {
+		outputErr = outputRequestsSkew(result, spikeData, exportFile)
+	}
+	if perTeam && outputErr == nil {
+		outputErr = exportRequestsSkewPerTeam(teamResults, requestsSkewConfig.exportFile)
+	}
+	if tracker != nil && outputErr == nil {
+		outputErr = fileRequestsSkewIssues(tracker, skewIssues)
+	}
+
+	if requestsSkewConfig.metricsPort > 0 && outputErr == nil {
+		return serveRequestsSkewMetrics(result, func(ctx context.Context) (*analyzer.RequestsSkewResult, error) {
+			next, err := skewAnalyzer.Analyze(ctx)
+			if err != nil {
+				return nil, err
+			}
+			truncateResults(next, requestsSkewConfig.top)
+			if obfuscator.IsEnabled() {
+				obfuscateResults(next, obfuscator)
+			}
+			return next, nil
+		}, timeout, metricsInterval)
 	}
 
 	// Check fail-on conditions for CI/CD
@@ -447,42 +685,141 @@ func runRequestsSkew(_ *cobra.Command, _ []string) error {
 
 		// Check for OOMKills in spike data (always critical)
 		for _, data := range spikeData {
-			if data.OOMKills > 0 {
+			if failOn.severity != "" && data.OOMKills > 0 {
 				shouldFail = true
-				stderrf("\n❌ Found OOMKills in spike monitoring data (--fail-on active)\n")
+				slog.Error("Found OOMKills in spike monitoring data (--fail-on active)")
 				break
 			}
 		}
 
 		// Check for UNSAFE safety ratings
-		if requestsSkewConfig.failOn == "unsafe" || requestsSkewConfig.failOn == "critical" || requestsSkewConfig.failOn == "warning" {
-			for i := range result.Results {
-				workload := &result.Results[i]
-				if workload.Safety != nil && workload.Safety.Rating == "UNSAFE" {
-					shouldFail = true
-					stderrf("\n❌ Found UNSAFE workloads (--fail-on active)\n")
-					break
-				}
+		if failOn.failsOnUnsafe() && hasUnsafeWorkload(result) {
+			shouldFail = true
+			slog.Error("Found UNSAFE workloads (--fail-on active)")
+		}
+
+		// Check summary thresholds and emit the verdict
+		if len(failOn.thresholds) > 0 {
+			checks := make([]analyzer.ThresholdCheck, 0, len(failOn.thresholds))
+			for _, t := range failOn.thresholds {
+				checks = append(checks, t.Evaluate(result))
 			}
+			failed, err := emitRequestsSkewVerdict(checks)
+			if err != nil {
+				return err
+			}
+			shouldFail = shouldFail || failed
 		}
 
 		if shouldFail {
-			util.Exit(1) // Custom exit code for CI/CD
+			util.Exit(util.ExitPolicyFail)
 		}
 	}
 
 	return outputErr
 }
 
+// newRequestsSkewAnalyzerConfig maps the command flags to the analyzer
+// configuration.
+func newRequestsSkewAnalyzerConfig(
+	window time.Duration, workloadKinds []string, costRates *cost.Rates, teamOwners *owners.File,
+) analyzer.RequestsSkewConfig {
+	return analyzer.RequestsSkewConfig{
+		Window:           window,
+		Top:              requestsSkewConfig.top,
+		Namespace:        GetNamespace(), // Use global --namespace flag if provided
+		NamespaceRegex:   requestsSkewConfig.namespaceRegex,
+		NamespaceInclude: requestsSkewConfig.namespaceInclude,
+		NamespaceExclude: requestsSkewConfig.namespaceExclude,
+		MinRuntimeDays:   requestsSkewConfig.minRuntimeDays,
+		SortBy:           requestsSkewConfig.sortBy,
+		Silent:           requestsSkewConfig.silent,
+		Workers:          requestsSkewConfig.workers,
+		Concurrency:      requestsSkewConfig.concurrency,
+		BatchQueries:     requestsSkewConfig.batchQueries,
+		GroupTemplates:   requestsSkewConfig.groupTemplates,
+		GroupBy:          requestsSkewConfig.groupBy,
+		TeamLabel:        requestsSkewConfig.teamLabel,
+		Owners:           teamOwners,
+		CostRates:        costRates,
+		WorkloadKinds:    workloadKinds,
+	}
+}
+
+// newRequestsSkewProvider creates the metrics provider for one cluster,
+// scoped by --prometheus-cluster-label when set. Queries are recorded into
+// queryLog, which may be nil.
+func newRequestsSkewProvider(
+	prometheusURL string, timeout time.Duration, kubeClient kubernetes.Interface, queryLog *querylog.Log,
+) (metrics.MetricsProvider, error) {
+	promConfig := metrics.Config{
+		PrometheusURL: prometheusURL,
+		Timeout:       timeout,
+		Backend:       requestsSkewConfig.metricsBackend,
+		TenantID:      requestsSkewConfig.metricsTenant,
+		QueryLog:      queryLog,
+		QueryRate:     requestsSkewConfig.queryRate,
+	}
+
+	var err error
+	promConfig.QueryOptions, err = resolveClusterLabel(requestsSkewConfig.prometheusClusterLabel, promConfig, kubeClient, timeout, requestsSkewConfig.silent)
+	if err != nil {
+		return nil, err
+	}
+
+	metricsProvider, err := metrics.NewProvider(promConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics provider: %w", err)
+	}
+	return metricsProvider, nil
+}
+
+// checkRequestsSkewMetrics verifies that the CPU and memory metrics the
+// analysis needs exist, explaining likely causes when they do not. Only
+// Prometheus-API backends support discovery; others pass unchecked.
+func checkRequestsSkewMetrics(ctx context.Context, metricsProvider metrics.MetricsProvider) error {
+	apiProvider, ok := metricsProvider.(metrics.APIProvider)
+	if !ok {
+		return nil
+	}
+	slog.Info("Discovering available Prometheus metrics...")
+
+	discovery := metrics.NewMetricDiscovery(apiProvider.GetAPI())
+	availableMetrics, err := discovery.DiscoverMetrics(ctx)
+	if err != nil {
+		return fmt.Errorf("metric discovery failed: %w", err)
+	}
+
+	// Validate that required metrics exist
+	if err = availableMetrics.ValidateMetrics(); err != nil {
+		// Possible causes: cAdvisor metrics not scraped, no ServiceMonitor or
+		// PodMonitor, or a scrape config without container metrics.
+		slog.Error("Metric discovery failed; check that cAdvisor container metrics are scraped (see README troubleshooting)",
+			"error", err, "cpu_metrics", strings.Join(availableMetrics.AllCPU, ","),
+			"memory_metrics", strings.Join(availableMetrics.AllMemory, ","))
+		return fmt.Errorf("required metrics not available in Prometheus")
+	}
+
+	slog.Info("Using metrics", "cpu_metric", availableMetrics.CPUMetric, "memory_metric", availableMetrics.MemoryMetric)
+	return nil
+}
+
 // obfuscateResults applies obfuscation to analysis results
 func obfuscateResults(result *analyzer.RequestsSkewResult, obf *util.Obfuscator) {
+	result.Metadata.Cluster = obf.Cluster(result.Metadata.Cluster)
+	result.Metadata.PrometheusURL = ""
 	for i := range result.Results {
 		result.Results[i].Namespace = obf.Namespace(result.Results[i].Namespace)
 		result.Results[i].Workload = obf.Workload(result.Results[i].Workload)
+		for j, inst := range result.Results[i].Instances {
+			ns, name, _ := strings.Cut(inst, "/")
+			result.Results[i].Instances[j] = obf.Namespace(ns) + "/" + obf.Workload(name)
+		}
 	}
 	for i := range result.WorkloadsWithoutMetrics {
 		result.WorkloadsWithoutMetrics[i].Namespace = obf.Namespace(result.WorkloadsWithoutMetrics[i].Namespace)
 		result.WorkloadsWithoutMetrics[i].Workload = obf.Workload(result.WorkloadsWithoutMetrics[i].Workload)
+		result.WorkloadsWithoutMetrics[i].Team = obfuscateTeam(result.WorkloadsWithoutMetrics[i].Team, obf)
 	}
 	for i := range result.NamespaceMetrics {
 		result.NamespaceMetrics[i].Namespace = obf.Namespace(result.NamespaceMetrics[i].Namespace)
@@ -490,6 +827,41 @@ func obfuscateResults(result *analyzer.RequestsSkewResult, obf *util.Obfuscator)
 	for i := range result.NamespaceQuotas {
 		result.NamespaceQuotas[i].Namespace = obf.Namespace(result.NamespaceQuotas[i].Namespace)
 	}
+	for i := range result.NamespaceCosts {
+		result.NamespaceCosts[i].Namespace = obf.Namespace(result.NamespaceCosts[i].Namespace)
+	}
+	for i := range result.Groups {
+		result.Groups[i].Name = obfuscateGroupName(result.Groups[i].Name, result.Metadata.GroupBy, obf)
+	}
+	for i := range result.Results {
+		result.Results[i].Group = obfuscateGroupName(result.Results[i].Group, result.Metadata.GroupBy, obf)
+		result.Results[i].Team = obfuscateTeam(result.Results[i].Team, obf)
+	}
+}
+
+// obfuscateTeam obfuscates an owning team; unowned stays empty.
+func obfuscateTeam(team string, obf *util.Obfuscator) string {
+	if team == "" {
+		return ""
+	}
+	return obf.Team(team)
+}
+
+// obfuscateGroupName obfuscates a --group-by group: a namespace, a
+// "<namespace>/<release>", or a team.
+func obfuscateGroupName(name, groupBy string, obf *util.Obfuscator) string {
+	if name == analyzer.GroupUnassigned {
+		return name
+	}
+	switch groupBy {
+	case analyzer.GroupByNamespace:
+		return obf.Namespace(name)
+	case analyzer.GroupByRelease:
+		ns, release, _ := strings.Cut(name, "/")
+		return obf.Namespace(ns) + "/" + obf.Release(release)
+	default:
+		return obf.Team(name)
+	}
 }
 
 // obfuscateSpikeData applies obfuscation to spike monitoring data
@@ -524,9 +896,8 @@ func runSpikeMonitoring(ctx context.Context, kubeClient *kubernetes.Clientset) (
 		return nil, fmt.Errorf("invalid spike-interval: %w", err)
 	}
 
-	stderrf("\n[kubenow] Starting real-time spike monitoring...\n")
-	stderrf("[kubenow] Duration: %s | Interval: %s\n", duration, interval)
-	stderrf("[kubenow] This will sample Kubernetes Metrics API at high frequency to catch sub-scrape-interval spikes.\n\n")
+	slog.Info("Starting real-time spike monitoring: sampling the Kubernetes Metrics API to catch sub-scrape-interval spikes...",
+		"duration", duration, "interval", interval)
 
 	// Create latch monitor
 	latchConfig := metrics.LatchConfig{
@@ -548,11 +919,28 @@ func runSpikeMonitoring(ctx context.Context, kubeClient *kubernetes.Clientset) (
 	// Get collected data
 	spikeData := monitor.GetSpikeData()
 
-	stderrf("\n[kubenow] Spike monitoring complete. Captured %d workloads.\n\n", len(spikeData))
+	slog.Info("Spike monitoring complete", "workloads", len(spikeData))
 
 	return spikeData, nil
 }
 
+// outputRequestsSkew writes the report in the --output format, to
+// exportFile when set.
+func outputRequestsSkew(result *analyzer.RequestsSkewResult, spikeData map[string]*metrics.SpikeData, exportFile string) error {
+	switch requestsSkewConfig.output {
+	case "json":
+		return outputRequestsSkewJSON(result, exportFile)
+	case "sarif":
+		return outputRequestsSkewSARIF(result, exportFile)
+	case "junit":
+		return outputRequestsSkewJUnit(result, exportFile)
+	case "html":
+		return outputRequestsSkewHTML(result, exportFile)
+	default:
+		return outputRequestsSkewTable(result, spikeData, exportFile, requestsSkewConfig.exportFormat)
+	}
+}
+
 func outputRequestsSkewJSON(result *analyzer.RequestsSkewResult, exportFile string) error {
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
@@ -561,10 +949,10 @@ func outputRequestsSkewJSON(result *analyzer.RequestsSkewResult, exportFile stri
 
 	// Export to file if specified
 	if exportFile != "" {
-		if err := os.WriteFile(exportFile, data, 0o600); err != nil {
+		if err := cleanup.WriteFile(exportFile, data, 0o600); err != nil {
 			return fmt.Errorf("failed to write file: %w", err)
 		}
-		stderrf("[kubenow] Report saved to: %s\n", exportFile)
+		slog.Info("Report saved", "path", exportFile)
 		return nil
 	}
 
@@ -581,11 +969,11 @@ func outputRequestsSkewSARIF(result *analyzer.RequestsSkewResult, exportFile str
 
 	// Export to file if specified
 	if exportFile != "" {
-		if err := os.WriteFile(exportFile, data, 0o600); err != nil {
+		if err := cleanup.WriteFile(exportFile, data, 0o600); err != nil {
 			return fmt.Errorf("failed to write file: %w", err)
 		}
-		stderrf("[kubenow] SARIF report saved to: %s\n", exportFile)
-		stderrf("[kubenow] Upload to GitHub: gh api repos/{owner}/{repo}/code-scanning/sarifs -F sarif=@%s\n", exportFile)
+		slog.Info("SARIF report saved", "path", exportFile,
+			"upload", "gh api repos/{owner}/{repo}/code-scanning/sarifs -F sarif=@"+exportFile)
 		return nil
 	}
 
@@ -594,6 +982,66 @@ func outputRequestsSkewSARIF(result *analyzer.RequestsSkewResult, exportFile str
 	return nil
 }
 
+// outputRequestsSkewJUnit writes the report as JUnit XML, with the --fail-on
+// thresholds as test cases next to the workloads.
+func outputRequestsSkewJUnit(result *analyzer.RequestsSkewResult, exportFile string) error {
+	var checks []analyzer.ThresholdCheck
+	if failOn, err := parseRequestsSkewFailOn(requestsSkewConfig.failOn); err == nil {
+		for _, t := range failOn.thresholds {
+			checks = append(checks, t.Evaluate(result))
+		}
+	}
+	data, err := output.GenerateJUnitFromRequestsSkew(result, checks, version)
+	if err != nil {
+		return fmt.Errorf("failed to generate JUnit XML: %w", err)
+	}
+
+	if exportFile != "" {
+		if err := cleanup.WriteFile(exportFile, data, 0o600); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		slog.Info("JUnit report saved", "path", exportFile)
+		return nil
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+func outputRequestsSkewHTML(result *analyzer.RequestsSkewResult, exportFile string) error {
+	data, err := renderRequestsSkewHTML(result)
+	if err != nil {
+		return err
+	}
+
+	// Export to file if specified
+	if exportFile != "" {
+		if err := cleanup.WriteFile(exportFile, data, 0o600); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		slog.Info("HTML report saved", "path", exportFile)
+		return nil
+	}
+
+	// Print to stdout
+	printOut(string(data))
+	return nil
+}
+
+func renderRequestsSkewHTML(result *analyzer.RequestsSkewResult) ([]byte, error) {
+	var buf bytes.Buffer
+	metadata := export.ExportMetadata{
+		GeneratedAt:    result.Metadata.GeneratedAt,
+		KubenowVersion: version,
+		ClusterName:    result.Metadata.Cluster,
+		Mode:           "requests-skew",
+	}
+	if err := export.ExportRequestsSkewHTML(result, &metadata, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[string]*metrics.SpikeData, exportFile, exportFormat string) error {
 	// If export file is specified, save to file in requested format
 	if exportFile != "" {
@@ -603,16 +1051,28 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 			if err != nil {
 				return fmt.Errorf("failed to marshal JSON for export: %w", err)
 			}
-			if err := os.WriteFile(exportFile, data, 0o600); err != nil {
+			if err := cleanup.WriteFile(exportFile, data, 0o600); err != nil {
 				return fmt.Errorf("failed to write export file: %w", err)
 			}
-			stderrf("[kubenow] Full results exported to: %s (JSON format)\n", exportFile)
+			slog.Info("Full results exported (JSON format)", "path", exportFile)
+		case "html":
+			if err := outputRequestsSkewHTML(result, exportFile); err != nil {
+				return fmt.Errorf("failed to export HTML: %w", err)
+			}
+		case "sarif":
+			if err := outputRequestsSkewSARIF(result, exportFile); err != nil {
+				return err
+			}
+		case "junit":
+			if err := outputRequestsSkewJUnit(result, exportFile); err != nil {
+				return err
+			}
 		case "table":
 			// Defer the table export until after we render it
 			// We'll capture the table output and save it
 			defer func() {
 				if err := exportTableToFile(result, spikeData, exportFile); err != nil {
-					stderrf("[kubenow] Warning: failed to export table: %v\n", err)
+					slog.Warn("Failed to export table", "error", err)
 				}
 			}()
 		}
@@ -620,11 +1080,15 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 
 	// Create table — add cost column if cost estimates are present
 	hasCost := result.Summary.CostEstimate != nil
+	hasTeams := result.HasTeams()
 	table := tablewriter.NewWriter(os.Stdout)
 	header := []string{"Namespace", "Workload", "Req CPU", "Lim CPU", "P99 CPU", "Skew", "Lim Skew", "Safety", "Impact"}
 	if hasCost {
 		header = append(header, "Est.Waste")
 	}
+	if hasTeams {
+		header = append(header, "Team")
+	}
 	table.Header(header)
 
 	for i := range result.Results {
@@ -647,7 +1111,7 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 
 		limCPU := "-"
 		if w.LimitCPU > 0 {
-			limCPU = fmt.Sprintf("%.2f", w.LimitCPU)
+			limCPU = units.Cores(w.LimitCPU)
 		}
 
 		limSkew := "-"
@@ -657,10 +1121,10 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 
 		row := []string{
 			w.Namespace,
-			w.Workload,
-			fmt.Sprintf("%.2f", w.RequestedCPU),
+			workloadLabel(w),
+			units.Cores(w.RequestedCPU),
 			limCPU,
-			fmt.Sprintf("%.2f", w.P99UsedCPU),
+			units.Cores(w.P99UsedCPU),
 			fmt.Sprintf("%.1fx", w.SkewCPU),
 			limSkew,
 			safetyLabel,
@@ -671,6 +1135,9 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 		} else if hasCost {
 			row = append(row, "-")
 		}
+		if hasTeams {
+			row = append(row, teamLabel(w.Team))
+		}
 		if err := table.Append(row); err != nil {
 			return fmt.Errorf("failed to append requests-skew row: %w", err)
 		}
@@ -718,11 +1185,11 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 	fmt.Printf("\nSummary:\n")
 	fmt.Printf("  Average CPU Skew: %.2fx\n", result.Summary.AvgSkewCPU)
 	fmt.Printf("  Average Memory Skew: %.2fx\n", result.Summary.AvgSkewMemory)
-	fmt.Printf("  Total Wasted CPU (requests): %.2f cores\n", result.Summary.TotalWastedCPU)
-	fmt.Printf("  Total Wasted Memory (requests): %.2fGi\n", result.Summary.TotalWastedMemoryGi)
+	fmt.Printf("  Total Wasted CPU (requests): %s cores\n", units.Cores(result.Summary.TotalWastedCPU))
+	fmt.Printf("  Total Wasted Memory (requests): %s\n", units.MemoryGi(result.Summary.TotalWastedMemoryGi*units.Gi))
 	if result.Summary.TotalWastedLimitCPU > 0 || result.Summary.TotalWastedLimitMemoryGi > 0 {
-		fmt.Printf("  Total Wasted CPU (limits): %.2f cores\n", result.Summary.TotalWastedLimitCPU)
-		fmt.Printf("  Total Wasted Memory (limits): %.2fGi\n", result.Summary.TotalWastedLimitMemoryGi)
+		fmt.Printf("  Total Wasted CPU (limits): %s cores\n", units.Cores(result.Summary.TotalWastedLimitCPU))
+		fmt.Printf("  Total Wasted Memory (limits): %s\n", units.MemoryGi(result.Summary.TotalWastedLimitMemoryGi*units.Gi))
 	}
 	if result.Summary.CostEstimate != nil {
 		ce := result.Summary.CostEstimate
@@ -732,6 +1199,13 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 			ce.Rates.MemoryPerGiBHour,
 			ce.Rates.Source)
 	}
+	printNamespaceCosts(result)
+
+	// Print the release/namespace/team rollup (--group-by)
+	fmt.Print(renderWorkloadGroups(result))
+
+	// Print template groups (--group-templates)
+	printTemplateGroups(result)
 
 	// Print safety warnings
 	printSafetyWarnings(result)
@@ -747,9 +1221,113 @@ func outputRequestsSkewTable(result *analyzer.RequestsSkewResult, spikeData map[
 		printSpikeMonitoringResults(spikeData)
 	}
 
+	fmt.Print(querylog.Render(result.Queries))
+
 	return nil
 }
 
+// workloadLabel names a result in table output, with the number of
+// instances it stands for when grouped by template.
+func workloadLabel(w *analyzer.WorkloadSkewAnalysis) string {
+	if len(w.Instances) > 1 {
+		return fmt.Sprintf("%s (×%d)", w.Workload, len(w.Instances))
+	}
+	return w.Workload
+}
+
+// teamLabel names the owning team of a result in table output.
+func teamLabel(team string) string {
+	if team == "" {
+		return owners.Unowned
+	}
+	return team
+}
+
+// printTemplateGroups lists the instances each grouped recommendation applies to.
+func printTemplateGroups(result *analyzer.RequestsSkewResult) {
+	header := false
+	for i := range result.Results {
+		w := &result.Results[i]
+		if len(w.Instances) < 2 {
+			continue
+		}
+		if !header {
+			fmt.Printf("\nTemplate groups (one recommendation per template, from the oldest instance):\n")
+			header = true
+		}
+		fmt.Printf("  %s (%s, %d instances): %s\n", w.Template, w.Type, len(w.Instances), strings.Join(w.Instances, ", "))
+	}
+}
+
+// renderWorkloadGroups formats the --group-by rollup as a table, with the
+// group wasting the most CPU called out first.
+func renderWorkloadGroups(result *analyzer.RequestsSkewResult) string {
+	if len(result.Groups) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\nWaste by %s (all analyzed workloads):\n", result.Metadata.GroupBy)
+	if top := result.Groups[0]; top.WastedCPU > 0 && top.Name != analyzer.GroupUnassigned {
+		fmt.Fprintf(&buf, "  %s %s wastes %s cores across %d workload(s)\n\n",
+			top.Name, result.Metadata.GroupBy, units.Cores(top.WastedCPU), top.Workloads)
+	}
+
+	hasCost := result.Summary.CostEstimate != nil
+	table := tablewriter.NewWriter(&buf)
+	header := []string{strings.ToUpper(result.Metadata.GroupBy[:1]) + result.Metadata.GroupBy[1:], "Workloads", "Req CPU", "Wasted CPU", "Wasted Mem", "Avg Skew"}
+	if result.Metadata.GroupBy == analyzer.GroupByRelease {
+		header = append(header, "Chart")
+	}
+	if hasCost {
+		header = append(header, "Est.Waste")
+	}
+	table.Header(header)
+	for i := range result.Groups {
+		g := &result.Groups[i]
+		row := []string{
+			g.Name,
+			fmt.Sprintf("%d", g.Workloads),
+			units.Cores(g.RequestedCPU),
+			units.Cores(g.WastedCPU),
+			units.MemoryGi(g.WastedMemoryGi * units.Gi),
+			fmt.Sprintf("%.1fx", g.AvgSkewCPU),
+		}
+		if result.Metadata.GroupBy == analyzer.GroupByRelease {
+			row = append(row, g.Chart)
+		}
+		if hasCost {
+			row = append(row, formatMonthlyCost(g.WastedMonthly))
+		}
+		appendTableRowBestEffort(table, row)
+	}
+	renderTableBestEffort(table)
+	return buf.String()
+}
+
+// maxNamespaceCostRows caps the per-namespace waste list in table output.
+const maxNamespaceCostRows = 10
+
+// printNamespaceCosts lists the namespaces with the highest estimated monthly waste.
+func printNamespaceCosts(result *analyzer.RequestsSkewResult) {
+	if len(result.NamespaceCosts) == 0 {
+		return
+	}
+
+	fmt.Printf("\nEstimated monthly waste by namespace:\n")
+	for i, nc := range result.NamespaceCosts {
+		if i == maxNamespaceCostRows {
+			fmt.Printf("  ... and %d more (see --output json)\n", len(result.NamespaceCosts)-maxNamespaceCostRows)
+			break
+		}
+		fmt.Printf("  %-30s %10s of %s (%.1f%%, %d workloads)\n",
+			nc.Namespace,
+			formatMonthlyCost(nc.WastedMonthly),
+			formatMonthlyCost(nc.CurrentMonthlyCost),
+			nc.SavingsPercent,
+			nc.Workloads)
+	}
+}
+
 func printSafetyWarnings(result *analyzer.RequestsSkewResult) {
 	// Collect workloads with safety issues
 	var unsafe, risky, caution []string
@@ -829,55 +1407,77 @@ func printSafetyWarnings(result *analyzer.RequestsSkewResult) {
 	}
 }
 
+// maxNoMetricsRows caps the workloads-without-metrics table in table output.
+const maxNoMetricsRows = 20
+
+// printWorkloadsWithoutMetricsWarning prints the workloads without metrics
+// as a table with their cause, most actionable causes first, and the next
+// step for each cause present.
 func printWorkloadsWithoutMetricsWarning(result *analyzer.RequestsSkewResult) {
 	if len(result.WorkloadsWithoutMetrics) == 0 {
 		return
 	}
 
-	fmt.Printf("\n⚠️  Workloads Without Prometheus Metrics:\n")
-	fmt.Printf("══════════════════════════════════════════\n\n")
-
-	fmt.Printf("requests-skew compares Prometheus historical data against resource requests.\n")
-	fmt.Printf("The following %d workload(s) have no Prometheus metrics and cannot be analyzed here.\n\n", len(result.WorkloadsWithoutMetrics))
+	fmt.Printf("\n⚠️  Workloads Without Prometheus Metrics (%d):\n", len(result.WorkloadsWithoutMetrics))
+	var counts []string
+	for _, cause := range analyzer.NoMetricsCauseOrder {
+		if n := result.Summary.WithoutMetricsByCause[cause]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", cause, n))
+		}
+	}
+	if len(counts) > 0 {
+		fmt.Printf("  %s\n", strings.Join(counts, ", "))
+	}
+	fmt.Println()
 
 	// Show per-namespace Prometheus data status
 	printNamespaceMetricsStatus(result)
 
-	// Group workloads by namespace
-	byNamespace := make(map[string][]analyzer.WorkloadWithoutMetrics)
-	for _, w := range result.WorkloadsWithoutMetrics {
-		byNamespace[w.Namespace] = append(byNamespace[w.Namespace], w)
+	rank := make(map[string]int, len(analyzer.NoMetricsCauseOrder))
+	for i, cause := range analyzer.NoMetricsCauseOrder {
+		rank[cause] = i
 	}
+	sorted := make([]analyzer.WorkloadWithoutMetrics, len(result.WorkloadsWithoutMetrics))
+	copy(sorted, result.WorkloadsWithoutMetrics)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := &sorted[i], &sorted[j]
+		if rank[a.Cause] != rank[b.Cause] {
+			return rank[a.Cause] < rank[b.Cause]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Workload < b.Workload
+	})
 
-	// Sort namespace keys for deterministic output
-	nsKeys := make([]string, 0, len(byNamespace))
-	for ns := range byNamespace {
-		nsKeys = append(nsKeys, ns)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Namespace", "Workload", "Type", "Cause", "Detail"})
+	for i := range sorted {
+		if i == maxNoMetricsRows {
+			break
+		}
+		w := &sorted[i]
+		appendTableRowBestEffort(table, []string{w.Namespace, w.Workload, w.Type, w.Cause, w.Diagnosis})
+	}
+	renderTableBestEffort(table)
+	if len(sorted) > maxNoMetricsRows {
+		fmt.Printf("  ... and %d more (see --output json)\n", len(sorted)-maxNoMetricsRows)
 	}
-	sort.Strings(nsKeys)
 
-	// Print workloads grouped by namespace with per-workload latch commands
-	fmt.Printf("Use pro-monitor latch to analyze these workloads via Kubernetes Metrics API:\n\n")
-	for _, ns := range nsKeys {
-		workloads := byNamespace[ns]
-		fmt.Printf("  Namespace: %s\n", ns)
-		for _, w := range workloads {
-			diag := ""
-			if w.Diagnosis != "" {
-				diag = fmt.Sprintf(" — %s", w.Diagnosis)
-			}
-			kindLower := kindToArg(w.Type)
-			fmt.Printf("    • %s/%s%s\n", w.Type, w.Workload, diag)
-			fmt.Printf("      kubenow pro-monitor latch %s/%s -n %s --duration 5m\n", kindLower, w.Workload, ns)
+	fmt.Printf("\nNext steps:\n")
+	for _, cause := range analyzer.NoMetricsCauseOrder {
+		if result.Summary.WithoutMetricsByCause[cause] > 0 {
+			fmt.Printf("  %-16s %s\n", cause, analyzer.NoMetricsRemediation[cause])
 		}
-		fmt.Println()
 	}
-
-	fmt.Printf("Why use pro-monitor latch?\n")
-	fmt.Printf("  • Works without Prometheus — uses Kubernetes Metrics API directly\n")
-	fmt.Printf("  • Captures real-time usage with sub-second sampling\n")
-	fmt.Printf("  • Produces resource recommendations with safety analysis\n")
-	fmt.Printf("  • Supports any workload type including CRD-managed (CNPG, Strimzi, etc.)\n\n")
+	for i := range sorted {
+		w := &sorted[i]
+		if w.Cause == analyzer.NoMetricsCauseNotScraped && !metrics.IsBatchKind(w.Type) {
+			fmt.Printf("  e.g. kubenow pro-monitor latch %s/%s -n %s --duration 5m\n", kindToArg(w.Type), w.Workload, w.Namespace)
+			break
+		}
+	}
+	fmt.Println()
 }
 
 // printNamespaceMetricsStatus shows which namespaces have/lack Prometheus data
@@ -980,10 +1580,13 @@ func printSpikeMonitoringResults(spikeData map[string]*metrics.SpikeData) {
 
 	// Create table for spike data
 	table := tablewriter.NewWriter(os.Stdout)
+	// Spikes are often fractions of a core, so show a decimal more
+	spikeCPU := units.Current().WithPrecision(3)
 
 	// Add recommendations column if requested
 	if requestsSkewConfig.showRecommendations {
-		table.Header([]string{"Namespace/Workload", "Avg CPU", "Max CPU", "Spike Ratio", "Recommended CPU", "Safety Factor"})
+		table.Header([]string{"Namespace/Workload", "Avg CPU", "Max CPU", "Spike Ratio", "Recommended CPU", "CPU Factor",
+			"Max Mem", "Rec Mem Req", "Rec Mem Lim", "Mem Factor"})
 	} else {
 		table.Header([]string{"Namespace/Workload", "Avg CPU", "Max CPU", "Spike Ratio", "Spike Count", "Samples"})
 	}
@@ -993,35 +1596,44 @@ func printSpikeMonitoringResults(spikeData map[string]*metrics.SpikeData) {
 			// Calculate safety factor based on spike ratio
 			safetyFactor := requestsSkewConfig.safetyFactor
 			if safetyFactor == 0.0 {
-				// Auto-select based on spike ratio
-				switch {
-				case sw.spikeRatio >= 20.0:
-					safetyFactor = 2.5
-				case sw.spikeRatio >= 10.0:
-					safetyFactor = 2.0
-				case sw.spikeRatio >= 5.0:
-					safetyFactor = 1.5
-				default:
-					safetyFactor = 1.2
-				}
+				safetyFactor = metrics.SpikeCPUSafetyFactor(sw.spikeRatio)
 			}
 
 			// Calculate recommended CPU
 			recommendedCPU := sw.data.MaxCPU * safetyFactor
 
+			// Memory uses its own factor: variability plus OOMKill signals
+			maxMem, memReq, memLim, memFactor := "-", "-", "-", "-"
+			if memRec := metrics.RecommendSpikeMemory(sw.data, requestsSkewConfig.memorySafetyFactor); memRec != nil {
+				maxMem = units.Memory(sw.data.MaxMemory)
+				memReq = units.Memory(memRec.RequestBytes)
+				memLim = units.Memory(memRec.LimitBytes)
+				memFactor = fmt.Sprintf("%.2fx", memRec.SafetyFactor)
+				if memRec.OOMAdjusted {
+					memFactor += fmt.Sprintf(" (%d OOM)", sw.data.OOMKills)
+				}
+				if memRec.LeakSuspected {
+					memFactor += " (leak?)"
+				}
+			}
+
 			appendTableRowBestEffort(table, []string{
 				sw.key,
-				fmt.Sprintf("%.3f", sw.data.AvgCPU),
-				fmt.Sprintf("%.3f", sw.data.MaxCPU),
+				spikeCPU.Cores(sw.data.AvgCPU),
+				spikeCPU.Cores(sw.data.MaxCPU),
 				fmt.Sprintf("%.1fx", sw.spikeRatio),
-				fmt.Sprintf("%.2f cores", recommendedCPU),
+				units.Cores(recommendedCPU) + " cores",
 				fmt.Sprintf("%.1fx", safetyFactor),
+				maxMem,
+				memReq,
+				memLim,
+				memFactor,
 			})
 		} else {
 			appendTableRowBestEffort(table, []string{
 				sw.key,
-				fmt.Sprintf("%.3f", sw.data.AvgCPU),
-				fmt.Sprintf("%.3f", sw.data.MaxCPU),
+				spikeCPU.Cores(sw.data.AvgCPU),
+				spikeCPU.Cores(sw.data.MaxCPU),
 				fmt.Sprintf("%.1fx", sw.spikeRatio),
 				fmt.Sprintf("%d", sw.data.SpikeCount),
 				fmt.Sprintf("%d", sw.data.SampleCount),
@@ -1043,9 +1655,16 @@ func printSpikeMonitoringResults(spikeData map[string]*metrics.SpikeData) {
 		fmt.Printf("  • Spike 10-20x: 2.0x (high bursts, e.g., batch jobs)\n")
 		fmt.Printf("  • Spike 5-10x: 1.5x (moderate bursts, e.g., APIs)\n")
 		fmt.Printf("  • Spike 2-5x: 1.2x (low bursts, e.g., background workers)\n\n")
+		fmt.Printf("Memory Request = Max Observed Memory × Memory Safety Factor\n")
+		fmt.Printf("  • Max/avg memory ≥2x: 1.5x, ≥1.5x: 1.3x, otherwise 1.15x\n")
+		fmt.Printf("  • +0.25x per OOMKill observed during monitoring (capped at 2.0x)\n")
+		fmt.Printf("  • Memory Limit = Request × 1.2 (× 1.5 when OOMKills were observed)\n")
+		fmt.Printf("  • Override with --memory-safety-factor\n\n")
 		fmt.Printf("Apply with kubectl:\n")
 		fmt.Printf("  kubectl patch deployment <name> -n <namespace> --type=json -p='[\n")
-		fmt.Printf("    {\"op\": \"replace\", \"path\": \"/spec/template/spec/containers/0/resources/requests/cpu\", \"value\": \"<recommended>m\"}\n")
+		fmt.Printf("    {\"op\": \"replace\", \"path\": \"/spec/template/spec/containers/0/resources/requests/cpu\", \"value\": \"<recommended>m\"},\n")
+		fmt.Printf("    {\"op\": \"replace\", \"path\": \"/spec/template/spec/containers/0/resources/requests/memory\", \"value\": \"<rec-mem-req>\"},\n")
+		fmt.Printf("    {\"op\": \"replace\", \"path\": \"/spec/template/spec/containers/0/resources/limits/memory\", \"value\": \"<rec-mem-lim>\"}\n")
 		fmt.Printf("  ]'\n\n")
 		fmt.Printf("See SPIKE-ANALYSIS.md for comprehensive guidance.\n\n")
 	} else {
@@ -1054,7 +1673,7 @@ func printSpikeMonitoringResults(spikeData map[string]*metrics.SpikeData) {
 		fmt.Printf("  • High spike ratios suggest sub-second bursts (common in RAG, AI inference, etc.)\n")
 		fmt.Printf("  • Consider these spikes when sizing resource requests\n\n")
 		fmt.Printf("💡 Want calculated recommendations? Use: --show-recommendations\n")
-		fmt.Printf("   This adds a 'Recommended CPU' column with safety-factor-adjusted values.\n")
+		fmt.Printf("   This adds recommended CPU and memory columns with safety-factor-adjusted values.\n")
 		fmt.Printf("   See SPIKE-ANALYSIS.md for detailed interpretation guidance.\n\n")
 	}
 }
@@ -1085,8 +1704,8 @@ func printQuotaInformation(result *analyzer.RequestsSkewResult) {
 			if quota.PotentialQuotaSavings != nil {
 				fmt.Printf("  Potential Quota Savings (if requests reduced to p95):\n")
 				if quota.PotentialQuotaSavings.CPUSavings > 0 {
-					fmt.Printf("    CPU:    %.2f cores (%.1f%% of quota)\n",
-						quota.PotentialQuotaSavings.CPUSavings,
+					fmt.Printf("    CPU:    %s cores (%.1f%% of quota)\n",
+						units.Cores(quota.PotentialQuotaSavings.CPUSavings),
 						quota.PotentialQuotaSavings.CPUPercent)
 				}
 				if quota.PotentialQuotaSavings.MemorySavings > 0 {
@@ -1202,6 +1821,10 @@ func printCriticalSignals(workloads []spikeWorkload) {
 			}
 		}
 
+		if c := sw.data.Crash; c != nil {
+			fmt.Printf("  Crash Pattern: %s (%d times) - %s\n", c.Class, c.Count, c.Evidence)
+		}
+
 		if len(sw.data.CriticalEvents) > 0 {
 			fmt.Printf("  Recent Events:\n")
 			// Show only last 5 events to avoid clutter
@@ -1271,12 +1894,28 @@ func getExitCodeMeaning(exitCode int) string {
 
 // exportTableToFile renders the table output and saves it to a file
 func exportTableToFile(result *analyzer.RequestsSkewResult, spikeData map[string]*metrics.SpikeData, exportFile string) error {
+	if err := cleanup.WriteFile(exportFile, renderRequestsSkewTableExport(result, spikeData), 0o600); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	slog.Info("Table results exported", "path", exportFile)
+	return nil
+}
+
+// renderRequestsSkewTableExport renders the plain-text table report written by
+// --export-format table.
+func renderRequestsSkewTableExport(result *analyzer.RequestsSkewResult, spikeData map[string]*metrics.SpikeData) []byte {
 	// Create a bytes buffer to capture table output
 	var buf bytes.Buffer
 
 	// Create table writing to buffer
+	hasTeams := result.HasTeams()
 	table := tablewriter.NewWriter(&buf)
-	table.Header([]string{"Namespace", "Workload", "Req CPU", "Lim CPU", "P99 CPU", "Skew", "Lim Skew", "Safety", "Impact"})
+	header := []string{"Namespace", "Workload", "Req CPU", "Lim CPU", "P99 CPU", "Skew", "Lim Skew", "Safety", "Impact"}
+	if hasTeams {
+		header = append(header, "Team")
+	}
+	table.Header(header)
 
 	for i := range result.Results {
 		w := &result.Results[i]
@@ -1298,7 +1937,7 @@ func exportTableToFile(result *analyzer.RequestsSkewResult, spikeData map[string
 
 		limCPU := "-"
 		if w.LimitCPU > 0 {
-			limCPU = fmt.Sprintf("%.2f", w.LimitCPU)
+			limCPU = units.Cores(w.LimitCPU)
 		}
 
 		limSkew := "-"
@@ -1306,20 +1945,25 @@ func exportTableToFile(result *analyzer.RequestsSkewResult, spikeData map[string
 			limSkew = fmt.Sprintf("%.1fx", w.LimitSkewCPU)
 		}
 
-		appendTableRowBestEffort(table, []string{
+		row := []string{
 			w.Namespace,
-			w.Workload,
-			fmt.Sprintf("%.2f", w.RequestedCPU),
+			workloadLabel(w),
+			units.Cores(w.RequestedCPU),
 			limCPU,
-			fmt.Sprintf("%.2f", w.P99UsedCPU),
+			units.Cores(w.P99UsedCPU),
 			fmt.Sprintf("%.1fx", w.SkewCPU),
 			limSkew,
 			safetyLabel,
 			fmt.Sprintf("%.2f cores", w.ImpactScore),
-		})
+		}
+		if hasTeams {
+			row = append(row, teamLabel(w.Team))
+		}
+		appendTableRowBestEffort(table, row)
 	}
 
 	renderTableBestEffort(table)
+	buf.WriteString(renderWorkloadGroups(result))
 
 	// Add spike data if available
 	if len(spikeData) > 0 {
@@ -1345,10 +1989,11 @@ func exportTableToFile(result *analyzer.RequestsSkewResult, spikeData map[string
 		})
 
 		// Write spike data
+		spikeCPU := units.Current().WithPrecision(4)
 		for _, sw := range spikes {
 			buf.WriteString(fmt.Sprintf("Workload: %s\n", sw.key))
-			buf.WriteString(fmt.Sprintf("  Max CPU: %.4f cores (spike)\n", sw.data.MaxCPU))
-			buf.WriteString(fmt.Sprintf("  Avg CPU: %.4f cores (baseline)\n", sw.data.AvgCPU))
+			buf.WriteString(fmt.Sprintf("  Max CPU: %s cores (spike)\n", spikeCPU.Cores(sw.data.MaxCPU)))
+			buf.WriteString(fmt.Sprintf("  Avg CPU: %s cores (baseline)\n", spikeCPU.Cores(sw.data.AvgCPU)))
 			buf.WriteString(fmt.Sprintf("  Spike Ratio: %.2fx\n", sw.spikeRatio))
 			buf.WriteString(fmt.Sprintf("  Samples: %d over %s\n", sw.data.SampleCount,
 				sw.data.LastSeen.Sub(sw.data.FirstSeen).Round(time.Second)))
@@ -1403,6 +2048,10 @@ func exportTableToFile(result *analyzer.RequestsSkewResult, spikeData map[string
 				}
 			}
 
+			if c := sw.data.Crash; c != nil {
+				buf.WriteString(fmt.Sprintf("  Crash Pattern: %s (%d times) - %s\n", c.Class, c.Count, c.Evidence))
+			}
+
 			if len(sw.data.CriticalEvents) > 0 {
 				buf.WriteString("  Recent Events:\n")
 				maxEvents := 5
@@ -1419,13 +2068,8 @@ func exportTableToFile(result *analyzer.RequestsSkewResult, spikeData map[string
 		}
 	}
 
-	// Write to file
-	if err := os.WriteFile(exportFile, buf.Bytes(), 0o600); err != nil {
-		return fmt.Errorf("failed to write export file: %w", err)
-	}
-
-	stderrf("[kubenow] Table results exported to: %s\n", exportFile)
-	return nil
+	buf.WriteString(querylog.Render(result.Queries))
+	return buf.Bytes()
 }
 
 // printDriftReport prints the baseline drift report
@@ -1519,36 +2163,49 @@ func printDriftReport(report *baseline.DriftReport) {
 	fmt.Printf("💡 Use --save-baseline to update your baseline with current results\n")
 }
 
-// attachCostEstimates computes per-workload and summary cost estimates
-// and attaches them to the analysis result.
-func attachCostEstimates(result *analyzer.RequestsSkewResult) {
-	rates := cost.ResolveRates(
-		requestsSkewConfig.instanceType,
-		requestsSkewConfig.costCPU,
-		requestsSkewConfig.costMemory,
-	)
+// instanceTypeAuto makes --instance-type price each node by its own instance-type label.
+const instanceTypeAuto = "auto"
 
-	var totalRequestedCPU, totalRequestedMemGi float64
-	for i := range result.Results {
-		w := &result.Results[i]
-		est := cost.EstimateWorkload(
-			w.RequestedCPU, w.P95UsedCPU,
-			w.RequestedMemoryGi, w.P95UsedMemoryGi,
-			rates,
-		)
-		w.CostEstimate = &est
-		totalRequestedCPU += w.RequestedCPU
-		totalRequestedMemGi += w.RequestedMemoryGi
-	}
-
-	summary := cost.EstimateSummary(
-		result.Summary.TotalWastedCPU,
-		result.Summary.TotalWastedMemoryGi,
-		totalRequestedCPU,
-		totalRequestedMemGi,
-		rates,
-	)
-	result.Summary.CostEstimate = &summary
+// resolveCostRates returns the pricing for monthly waste estimates, or nil when
+// no cost flag is set. With --instance-type auto the rates are blended from
+// the cluster's node instance types, falling back to defaults if none is known.
+func resolveCostRates(ctx context.Context, kubeClient kubernetes.Interface) *cost.Rates {
+	cfg := &requestsSkewConfig
+	if cfg.costCPU <= 0 && cfg.costMemory <= 0 && cfg.instanceType == "" {
+		return nil
+	}
+	rates := clusterCostRates(ctx, kubeClient, cfg.instanceType, cfg.costCPU, cfg.costMemory, cfg.silent)
+	return &rates
+}
+
+// clusterCostRates resolves pricing from explicit rates, an instance type, or
+// (with instanceTypeAuto) the cluster's node instance types. Anything
+// unknown falls back to the default rates with a warning.
+func clusterCostRates(
+	ctx context.Context, kubeClient kubernetes.Interface, instanceType string, costCPU, costMemory float64, silent bool,
+) cost.Rates {
+	if instanceType != instanceTypeAuto || costCPU > 0 || costMemory > 0 {
+		rates := cost.ResolveRates(instanceType, costCPU, costMemory)
+		if instanceType != "" && instanceType != instanceTypeAuto && rates.Source == "default" && !silent {
+			slog.Warn("No pricing for instance type, using default rates", "instance_type", instanceType)
+		}
+		return rates
+	}
+
+	nodeTypes, err := analyzer.ClusterNodeTypes(ctx, kubeClient)
+	if err != nil {
+		if !silent {
+			slog.Warn("Node instance types unavailable, using default rates", "error", err)
+		}
+		return cost.DefaultRates()
+	}
+	if blended, ok := cost.BlendRates(nodeTypes); ok {
+		return blended
+	}
+	if !silent {
+		slog.Warn("No node has a known instance type, using default rates")
+	}
+	return cost.DefaultRates()
 }
 
 // saveTrendSnapshot persists the analysis result as a trend data point.
@@ -1584,10 +2241,10 @@ func saveTrendSnapshot(result *analyzer.RequestsSkewResult) {
 	}
 
 	if err := trend.SaveSnapshot(snap); err != nil {
-		stderrf("[kubenow] Warning: failed to save trend snapshot: %v\n", err)
+		slog.Warn("Failed to save trend snapshot", "error", err)
 		return
 	}
-	stderrln("[kubenow] Trend snapshot saved.")
+	slog.Info("Trend snapshot saved.")
 }
 
 // formatMonthlyCost renders a dollar amount as a compact monthly cost string.
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/keymap"
+	"github.com/ppiankov/kubenow/internal/output"
+	"github.com/ppiankov/kubenow/internal/skewbrowser"
+)
+
+// validateRequestsSkewBrowse rejects flags that --browse replaces or that
+// keep the command from ending when the browser closes.
+func validateRequestsSkewBrowse(perTeam bool) error {
+	switch {
+	case !stdinIsTerminal():
+		return fmt.Errorf("--browse needs a terminal")
+	case requestsSkewConfig.output != "table":
+		return fmt.Errorf("--browse cannot be combined with --output %s", requestsSkewConfig.output)
+	case requestsSkewConfig.metricsPort > 0:
+		return fmt.Errorf("--browse cannot be combined with --metrics-port")
+	case requestsSkewConfig.contexts != "" || requestsSkewConfig.allContexts:
+		return fmt.Errorf("--browse cannot be combined with --contexts or --all-contexts")
+	case perTeam:
+		return fmt.Errorf("--browse cannot be combined with %s in --export-file", teamPlaceholder)
+	}
+	return nil
+}
+
+// browseRequestsSkew opens the results browser. Exports of the selected
+// workloads go to --export-file, or to a timestamped file in the current
+// directory, in --export-format.
+func browseRequestsSkew(result *analyzer.RequestsSkewResult, keys *keymap.Map) error {
+	model := skewbrowser.NewModel(result, requestsSkewConfig.sortBy, func(subset *analyzer.RequestsSkewResult) (string, error) {
+		return exportRequestsSkewSelection(subset, requestsSkewConfig.exportFile, requestsSkewConfig.exportFormat)
+	})
+	model.SetKeyMap(keys)
+	if _, err := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(os.Stderr)).Run(); err != nil {
+		return fmt.Errorf("error running results browser: %w", err)
+	}
+	return nil
+}
+
+// exportRequestsSkewSelection writes a browser selection without printing,
+// since the browser owns the terminal.
+func exportRequestsSkewSelection(subset *analyzer.RequestsSkewResult, path, format string) (string, error) {
+	var data []byte
+	var err error
+	switch format {
+	case "html":
+		data, err = renderRequestsSkewHTML(subset)
+	case "table":
+		data = renderRequestsSkewTableExport(subset, nil)
+	case "sarif":
+		data, err = output.GenerateSARIFFromRequestsSkew(subset, version)
+	case "junit":
+		data, err = output.GenerateJUnitFromRequestsSkew(subset, nil, version)
+	default:
+		data, err = json.MarshalIndent(subset, "", "  ")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s export: %w", format, err)
+	}
+
+	if path == "" {
+		ext := map[string]string{"html": "html", "table": "txt", "sarif": "sarif", "junit": "xml"}[format]
+		if ext == "" {
+			ext = "json"
+		}
+		path = fmt.Sprintf("kubenow-requests-skew-%s.%s", time.Now().Format("20060102-150405"), ext)
+	}
+	if err := cleanup.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/owners"
+	"github.com/ppiankov/kubenow/internal/policy"
+	"github.com/ppiankov/kubenow/internal/rollup"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// contextPlaceholder in --prometheus-url is replaced by each context name,
+// for clusters that run their own Prometheus.
+const contextPlaceholder = "{context}"
+
+// requestsSkewContextRun is the outcome of analyzing one context.
+type requestsSkewContextRun struct {
+	Context string                       `json:"context"`
+	Cluster string                       `json:"cluster"`
+	Error   string                       `json:"error,omitempty"`
+	Report  *analyzer.RequestsSkewResult `json:"report,omitempty"`
+}
+
+// requestsSkewContextsReport is the JSON document of a multi-context run:
+// one report per context and the cross-cluster summary, keyed by context.
+type requestsSkewContextsReport struct {
+	Clusters []requestsSkewContextRun `json:"clusters"`
+	Summary  *rollup.Fleet            `json:"summary"`
+}
+
+// runRequestsSkewContexts analyzes each context in turn and prints one
+// section per cluster plus a cross-cluster summary. A context that fails is
+// reported and skipped; the run fails only when every context does.
+func runRequestsSkewContexts(contexts []util.KubeContext, teamOwners *owners.File, failOn requestsSkewFailOn) error {
+	cfg := &requestsSkewConfig
+	switch {
+	case cfg.interactive:
+		return fmt.Errorf("--interactive cannot be combined with --contexts or --all-contexts")
+	case cfg.k8sService != "":
+		return fmt.Errorf("--k8s-service port-forwards to a single cluster; with --contexts use --prometheus-url (%s is replaced by each context name) or --auto-detect-prometheus", contextPlaceholder)
+	case cfg.watchForSpikes:
+		return fmt.Errorf("--watch-for-spikes cannot be combined with --contexts or --all-contexts")
+	case cfg.saveBaseline != "" || cfg.compareBaseline != "":
+		return fmt.Errorf("baselines are per cluster; run --save-baseline and --compare-baseline with --context")
+	case cfg.prometheusURL == "" && !cfg.autoDetect:
+		return fmt.Errorf("either --prometheus-url or --auto-detect-prometheus is required with --contexts")
+	case cfg.output != "table" && cfg.output != "json":
+		return fmt.Errorf("--output must be 'table' or 'json' with --contexts")
+	case cfg.exportFile != "" && cfg.exportFormat != "json":
+		return fmt.Errorf("--export-format must be 'json' with --contexts")
+	case strings.Contains(cfg.exportFile, teamPlaceholder):
+		return fmt.Errorf("per-team export files (%s) cannot be combined with --contexts or --all-contexts", teamPlaceholder)
+	}
+
+	workloadKinds, err := parseWorkloadKinds(cfg.workloadKinds)
+	if err != nil {
+		return err
+	}
+	window, err := metrics.ParseDuration(cfg.window)
+	if err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+	timeout, err := time.ParseDuration(cfg.prometheusTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+	switch cfg.sortBy {
+	case "impact", "skew", "cpu", "memory", "name":
+	default:
+		return fmt.Errorf("invalid --sort-by option: %s (must be: impact|skew|cpu|memory|name)", cfg.sortBy)
+	}
+	ratios, err := loadRatioPolicy(cfg.policyFile)
+	if err != nil {
+		slog.Warn("Skipping ratio policy notes", "error", err)
+	}
+
+	runs := make([]requestsSkewContextRun, 0, len(contexts))
+	failed := 0
+	for i, c := range contexts {
+		if !cfg.silent {
+			logging.Progress(slog.Default(), "Context", i+1, len(contexts), "context", c.Name)
+		}
+		run := requestsSkewContextRun{Context: c.Name, Cluster: contextClusterName(c)}
+		run.Report, err = analyzeRequestsSkewContext(c, window, timeout, workloadKinds, ratios, teamOwners)
+		if err != nil {
+			failed++
+			run.Error = err.Error()
+			slog.Warn("Context failed", "context", c.Name, "error", err)
+		}
+		runs = append(runs, run)
+	}
+	if failed == len(runs) {
+		return fmt.Errorf("analysis failed in all %d context(s)", failed)
+	}
+
+	obfuscator := util.NewObfuscator(cfg.obfuscate)
+	reports := make([]rollup.ClusterReport, 0, len(runs))
+	for i := range runs {
+		run := &runs[i]
+		if obfuscator.IsEnabled() {
+			run.Context = obfuscator.Cluster(run.Context)
+			run.Cluster = obfuscator.Cluster(run.Cluster)
+			if run.Report != nil {
+				obfuscateResults(run.Report, obfuscator)
+			}
+		}
+		if run.Report != nil {
+			reports = append(reports, rollup.ClusterReport{Cluster: run.Context, Source: run.Context, Result: run.Report})
+		}
+	}
+	doc := &requestsSkewContextsReport{Clusters: runs, Summary: rollup.Build(reports, cfg.top)}
+
+	var outputErr error
+	if cfg.output == "json" {
+		outputErr = outputRequestsSkewContextsJSON(doc, cfg.exportFile)
+	} else {
+		outputErr = outputRequestsSkewContextsTable(doc, cfg.exportFile)
+	}
+
+	// As for one cluster, --fail-on fatal only concerns spike data
+	if failOn.failsOnUnsafe() && outputErr == nil {
+		for _, run := range runs {
+			if run.Report != nil && hasUnsafeWorkload(run.Report) {
+				slog.Error("Found UNSAFE workloads (--fail-on active)", "context", run.Context)
+				util.Exit(util.ExitPolicyFail)
+			}
+		}
+	}
+
+	// Thresholds apply to each cluster's summary
+	if len(failOn.thresholds) > 0 && outputErr == nil {
+		var checks []analyzer.ThresholdCheck
+		for _, run := range runs {
+			if run.Report == nil {
+				continue
+			}
+			for _, t := range failOn.thresholds {
+				check := t.Evaluate(run.Report)
+				check.Context = run.Context
+				checks = append(checks, check)
+			}
+		}
+		failed, err := emitRequestsSkewVerdict(checks)
+		if err != nil {
+			return err
+		}
+		if failed {
+			util.Exit(util.ExitPolicyFail)
+		}
+	}
+	return outputErr
+}
+
+// analyzeRequestsSkewContext runs the requests-skew analysis against one
+// kubeconfig context.
+func analyzeRequestsSkewContext(
+	c util.KubeContext, window, timeout time.Duration, workloadKinds []string, ratios policy.RatioConfig, teamOwners *owners.File,
+) (*analyzer.RequestsSkewResult, error) {
+	queryLog := newQueryLog(requestsSkewConfig.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(contextKubeOpts(c), queryLog))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	prometheusURL := strings.ReplaceAll(requestsSkewConfig.prometheusURL, contextPlaceholder, c.Name)
+	if prometheusURL == "" {
+		detectCtx, detectCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		prometheusURL, err = metrics.AutoDetect(detectCtx, kubeClient)
+		detectCancel()
+		if err != nil {
+			return nil, fmt.Errorf("auto-detect failed: %w", err)
+		}
+		slog.Info("Discovered Prometheus", "url", prometheusURL)
+	}
+
+	metricsProvider, err := newRequestsSkewProvider(prometheusURL, timeout, kubeClient, queryLog)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := metricsProvider.Health(ctx); err != nil {
+		return nil, fmt.Errorf("prometheus health check failed: %w", err)
+	}
+	if err := checkRequestsSkewMetrics(ctx, metricsProvider); err != nil {
+		return nil, err
+	}
+
+	analyzerConfig := newRequestsSkewAnalyzerConfig(window, workloadKinds, resolveCostRates(ctx, kubeClient), teamOwners)
+	result, err := analyzer.NewRequestsSkewAnalyzer(kubeClient, metricsProvider, &analyzerConfig).Analyze(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	result.Metadata.Cluster = contextClusterName(c)
+	result.Metadata.PrometheusURL = prometheusURL
+
+	if n := analyzer.ApplyRatioNotes(result, ratios); n > 0 && !requestsSkewConfig.silent {
+		slog.Info("Workloads violate the limit/request ratio policy (see note field)", "workloads", n)
+	}
+	if priorityRisk, err := analyzer.AnalyzePriorityRisk(ctx, kubeClient, analyzer.PriorityRiskConfig{}); err == nil {
+		analyzer.ApplyPriorityNotes(result, priorityRisk)
+	} else if !requestsSkewConfig.silent {
+		slog.Warn("Skipping preemption risk notes", "error", err)
+	}
+	result.Queries = queryLog.Report(prometheusURL)
+	return result, nil
+}
+
+func outputRequestsSkewContextsJSON(doc *requestsSkewContextsReport, exportFile string) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return writeOutputOrStdout(exportFile, string(data)+"\n")
+}
+
+// outputRequestsSkewContextsTable prints one table per context, then the
+// cross-cluster summary. --export-file saves the JSON document alongside.
+func outputRequestsSkewContextsTable(doc *requestsSkewContextsReport, exportFile string) error {
+	for i := range doc.Clusters {
+		run := &doc.Clusters[i]
+		printfOut("\n=== Context %s (cluster %s) ===\n", run.Context, run.Cluster)
+		if run.Report == nil {
+			printfOut("\nAnalysis failed: %s\n", run.Error)
+			continue
+		}
+		if err := outputRequestsSkewTable(run.Report, nil, "", "json"); err != nil {
+			return err
+		}
+	}
+
+	printOut(renderFleetTable(doc.Summary))
+	var failed []string
+	for _, run := range doc.Clusters {
+		if run.Error != "" {
+			failed = append(failed, run.Context)
+		}
+	}
+	if len(failed) > 0 {
+		printfOut("\nNot in the summary (analysis failed): %s\n", strings.Join(failed, ", "))
+	}
+
+	if exportFile != "" {
+		return outputRequestsSkewContextsJSON(doc, exportFile)
+	}
+	return nil
+}
+
+// hasUnsafeWorkload reports whether any result is rated UNSAFE.
+func hasUnsafeWorkload(result *analyzer.RequestsSkewResult) bool {
+	for i := range result.Results {
+		if s := result.Results[i].Safety; s != nil && s.Rating == "UNSAFE" {
+			return true
+		}
+	}
+	return false
+}
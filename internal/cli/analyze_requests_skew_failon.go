@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// failOnSeverities are the --fail-on values that gate on spike OOMKills
+// (all of them) and UNSAFE workloads (all but fatal).
+var failOnSeverities = []string{"fatal", "critical", "warning", "unsafe"}
+
+// requestsSkewFailOn is a parsed --fail-on: at most one severity plus any
+// number of summary thresholds.
+type requestsSkewFailOn struct {
+	severity   string
+	thresholds []analyzer.Threshold
+}
+
+// failsOnUnsafe reports whether UNSAFE workloads fail the run.
+func (f requestsSkewFailOn) failsOnUnsafe() bool {
+	return f.severity != "" && f.severity != "fatal"
+}
+
+// parseRequestsSkewFailOn parses a comma-separated --fail-on, e.g.
+// "critical,avg-skew-cpu>5,wasted-cpu>50".
+func parseRequestsSkewFailOn(spec string) (requestsSkewFailOn, error) {
+	var f requestsSkewFailOn
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			continue
+		case slices.Contains(failOnSeverities, part):
+			if f.severity != "" {
+				return f, fmt.Errorf("--fail-on: only one severity allowed, got %s and %s", f.severity, part)
+			}
+			f.severity = part
+		default:
+			t, err := analyzer.ParseThreshold(part)
+			if err != nil {
+				return f, fmt.Errorf("--fail-on: %w (or a severity: %s)", err, strings.Join(failOnSeverities, "|"))
+			}
+			f.thresholds = append(f.thresholds, t)
+		}
+	}
+	return f, nil
+}
+
+// requestsSkewVerdict is the compact, machine-readable outcome of the
+// --fail-on thresholds for CI pipelines.
+type requestsSkewVerdict struct {
+	Verdict  string                    `json:"verdict"` // pass|fail
+	ExitCode int                       `json:"exit_code"`
+	Failed   []string                  `json:"failed,omitempty"`
+	Checks   []analyzer.ThresholdCheck `json:"checks"`
+}
+
+// emitRequestsSkewVerdict writes the verdict of the threshold checks as one
+// JSON line to stderr (stdout carries the report) and to --verdict-file
+// when set, and reports whether a threshold failed.
+func emitRequestsSkewVerdict(checks []analyzer.ThresholdCheck) (bool, error) {
+	verdict := requestsSkewVerdict{Verdict: "pass", ExitCode: util.ExitOK, Checks: checks}
+	for _, c := range checks {
+		if c.Failed {
+			name := c.Threshold
+			if c.Context != "" {
+				name = c.Context + ":" + name
+			}
+			verdict.Failed = append(verdict.Failed, name)
+		}
+	}
+	if len(verdict.Failed) > 0 {
+		verdict.Verdict = "fail"
+		verdict.ExitCode = util.ExitPolicyFail
+	}
+
+	data, err := json.Marshal(verdict)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal verdict: %w", err)
+	}
+	stderrf("%s\n", data)
+	if requestsSkewConfig.verdictFile != "" {
+		if err := cleanup.WriteFile(requestsSkewConfig.verdictFile, append(data, '\n'), 0o600); err != nil {
+			return false, fmt.Errorf("failed to write verdict file: %w", err)
+		}
+	}
+	return len(verdict.Failed) > 0, nil
+}
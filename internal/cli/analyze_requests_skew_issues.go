@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/issues"
+	"github.com/ppiankov/kubenow/internal/models"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// skewIssueHeadroom is the headroom over p95 usage of recommended
+// requests, as in the requests-skew note.
+const skewIssueHeadroom = 1.5
+
+// newIssueTracker returns the tracker selected by --create-issues, or nil
+// when it is unset. Tokens default to GITHUB_TOKEN, JIRA_USER, and
+// JIRA_API_TOKEN.
+func newIssueTracker() (issues.Tracker, error) {
+	cfg := &requestsSkewConfig
+	switch cfg.createIssues {
+	case "":
+		return nil, nil
+	case issues.TrackerGitHub:
+		gh := &issues.GitHub{Repo: cfg.githubRepo, Token: cfg.githubToken, BaseURL: cfg.githubURL}
+		if gh.Token == "" {
+			gh.Token = os.Getenv("GITHUB_TOKEN")
+		}
+		if err := gh.Validate(); err != nil {
+			return nil, fmt.Errorf("--create-issues github: %w (set --github-repo and --github-token or GITHUB_TOKEN)", err)
+		}
+		return gh, nil
+	case issues.TrackerJira:
+		jira := &issues.Jira{
+			BaseURL: cfg.jiraURL, Project: cfg.jiraProject, IssueType: cfg.jiraIssueType,
+			User: cfg.jiraUser, Token: cfg.jiraToken,
+		}
+		if jira.User == "" {
+			jira.User = os.Getenv("JIRA_USER")
+		}
+		if jira.Token == "" {
+			jira.Token = os.Getenv("JIRA_API_TOKEN")
+		}
+		if err := jira.Validate(); err != nil {
+			return nil, fmt.Errorf("--create-issues jira: %w (set --jira-url, --jira-project, and --jira-token or JIRA_API_TOKEN)", err)
+		}
+		return jira, nil
+	default:
+		return nil, fmt.Errorf("invalid --create-issues %q: must be github or jira", cfg.createIssues)
+	}
+}
+
+// requestsSkewIssues builds one issue per owning team (with an owners
+// file) or namespace that has over-provisioned workloads, listing its top
+// offenders (0 = all).
+func requestsSkewIssues(result *analyzer.RequestsSkewResult, byTeam bool, top int) []*issues.Issue {
+	groupOf := func(w *analyzer.WorkloadSkewAnalysis) string { return w.Namespace }
+	scope := "namespace"
+	if byTeam {
+		groupOf = func(w *analyzer.WorkloadSkewAnalysis) string { return teamLabel(w.Team) }
+		scope = "team"
+	}
+
+	var order []string
+	groups := make(map[string][]*analyzer.WorkloadSkewAnalysis)
+	for i := range result.Results {
+		w := &result.Results[i]
+		if !skewOffender(w) {
+			continue
+		}
+		g := groupOf(w)
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], w)
+	}
+
+	out := make([]*issues.Issue, 0, len(order))
+	for _, g := range order {
+		out = append(out, skewIssue(result, scope, g, groups[g], top))
+	}
+	return out
+}
+
+// skewOffender reports whether a workload requests more than twice the
+// recommended CPU or memory and is not rated UNSAFE.
+func skewOffender(w *analyzer.WorkloadSkewAnalysis) bool {
+	if w.Safety != nil && w.Safety.Rating == models.SafetyRatingUnsafe {
+		return false
+	}
+	cpu, mem := skewRecommendation(w)
+	return w.RequestedCPU > 2*cpu || w.RequestedMemoryGi > 2*mem
+}
+
+// skewRecommendation returns the recommended CPU (cores) and memory (GiB)
+// requests: p95 plus headroom, keeping memory after OOMKills.
+func skewRecommendation(w *analyzer.WorkloadSkewAnalysis) (cpu, memGi float64) {
+	cpu = w.P95UsedCPU * skewIssueHeadroom
+	memGi = w.P95UsedMemoryGi * skewIssueHeadroom
+	if w.Safety != nil && w.Safety.OOMKills > 0 {
+		memGi = w.RequestedMemoryGi
+	}
+	return cpu, memGi
+}
+
+func skewIssue(result *analyzer.RequestsSkewResult, scope, group string, workloads []*analyzer.WorkloadSkewAnalysis, top int) *issues.Issue {
+	cluster := result.Metadata.Cluster
+	issue := &issues.Issue{
+		Key:   "requests-skew/" + cluster + "/" + scope + "/" + group,
+		Title: fmt.Sprintf("kubenow: %d over-provisioned workload(s) in %s %s", len(workloads), scope, group),
+	}
+	if cluster != "" {
+		issue.Title += " (" + cluster + ")"
+	}
+
+	var saveCPU, saveMemGi, waste float64
+	for _, w := range workloads {
+		cpu, mem := skewRecommendation(w)
+		saveCPU += max(w.RequestedCPU-cpu, 0)
+		saveMemGi += max(w.RequestedMemoryGi-mem, 0)
+		if w.CostEstimate != nil {
+			waste += w.CostEstimate.WastedMonthly
+		}
+	}
+	where := fmt.Sprintf("%s `%s`", scope, group)
+	if cluster != "" {
+		where += fmt.Sprintf(" on cluster `%s`", cluster)
+	}
+	issue.Summary = []string{
+		fmt.Sprintf("**%d workload(s)** in %s request more than twice what they used at p95 over the last %s.",
+			len(workloads), where, result.Metadata.Window),
+	}
+	savings := fmt.Sprintf("Applying the recommendations frees about %s of CPU and %s of memory requests",
+		units.Cores(saveCPU), units.MemoryGi(saveMemGi*units.Gi))
+	if waste > 0 {
+		savings += fmt.Sprintf(", about $%.2f/month", waste)
+	}
+	issue.Summary = append(issue.Summary, savings+".")
+
+	shown := workloads
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+		issue.Summary = append(issue.Summary, fmt.Sprintf("The top %d are listed below.", top))
+	}
+	issue.Header = []string{
+		"Workload", "CPU request", "CPU p95", "Recommended CPU",
+		"Memory request", "Memory p95", "Recommended memory", "Safety",
+	}
+	for _, w := range shown {
+		cpu, mem := skewRecommendation(w)
+		safety := string(models.SafetyRatingUnknown)
+		if w.Safety != nil {
+			safety = string(w.Safety.Rating)
+			if w.Safety.OOMKills > 0 {
+				safety += fmt.Sprintf(" (%d OOMKill(s))", w.Safety.OOMKills)
+			}
+		}
+		gi := func(v float64) string { return units.MemoryGi(v * units.Gi) }
+		row := []string{
+			w.Namespace + "/" + workloadLabel(w),
+			units.Cores(w.RequestedCPU), units.Cores(w.P95UsedCPU), units.Cores(cpu),
+			gi(w.RequestedMemoryGi), gi(w.P95UsedMemoryGi), gi(mem),
+			safety,
+		}
+		issue.Rows = append(issue.Rows, row)
+	}
+
+	issue.Footer = []string{
+		fmt.Sprintf("Recommended requests are p95 usage plus %.0f%% headroom; memory is kept for workloads with OOMKills in the window. "+
+			"Review the safety rating first, and confirm spiky workloads with `kubenow pro-monitor latch`.", (skewIssueHeadroom-1)*100),
+		fmt.Sprintf("Filed by `kubenow analyze requests-skew` on %s. Re-runs update this issue instead of opening a new one.",
+			result.Metadata.GeneratedAt.UTC().Format("2006-01-02 15:04 UTC")),
+	}
+	return issue
+}
+
+// fileRequestsSkewIssues creates or updates the issues and reports each.
+// All issues are attempted; the first error is returned.
+func fileRequestsSkewIssues(tracker issues.Tracker, skewIssues []*issues.Issue) error {
+	if len(skewIssues) == 0 {
+		slog.Info("No over-provisioned workloads: no issues filed")
+		return nil
+	}
+	var firstErr error
+	created, updated := 0, 0
+	for _, issue := range skewIssues {
+		res, err := tracker.Upsert(context.Background(), issue)
+		if err != nil {
+			slog.Warn("Filing issue failed", "title", issue.Title, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if res.Created {
+			created++
+			slog.Info("Created issue", "id", res.ID, "url", res.URL)
+		} else {
+			updated++
+			slog.Info("Updated issue", "id", res.ID, "url", res.URL)
+		}
+	}
+	slog.Info("Issues filed", "created", created, "updated", updated)
+	if firstErr != nil {
+		return fmt.Errorf("filing issues: %w", firstErr)
+	}
+	return nil
+}
+
+// validateIssueFlags rejects --create-issues with modes that skip the
+// report or hide names.
+func validateIssueFlags() error {
+	cfg := &requestsSkewConfig
+	if cfg.createIssues == "" {
+		return nil
+	}
+	var conflicts []string
+	for flag, set := range map[string]bool{
+		"--obfuscate":        cfg.obfuscate,
+		"--compare-baseline": cfg.compareBaseline != "",
+		"--metrics-port":     cfg.metricsPort > 0,
+		"--contexts":         cfg.contexts != "" || cfg.allContexts,
+	} {
+		if set {
+			conflicts = append(conflicts, flag)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("--create-issues cannot be combined with %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
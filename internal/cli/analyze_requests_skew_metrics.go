@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/telemetry"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// serveRequestsSkewMetrics publishes result on --metrics-port, then re-runs
+// the analysis every interval so alerting stacks see fresh findings. It
+// serves until the process is interrupted; a failed re-run keeps the
+// previous findings.
+func serveRequestsSkewMetrics(
+	result *analyzer.RequestsSkewResult,
+	analyze func(context.Context) (*analyzer.RequestsSkewResult, error),
+	timeout, interval time.Duration,
+) error {
+	findings := startMetricsServer(context.Background(), requestsSkewConfig.metricsPort).Findings()
+	publishRequestsSkewFindings(findings, result)
+	slog.Info("Re-analyzing periodically (Ctrl+C to stop)", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		next, err := analyze(ctx)
+		cancel()
+		if err != nil {
+			slog.Warn("Re-analysis failed, keeping previous findings", "error", err)
+			continue
+		}
+		publishRequestsSkewFindings(findings, next)
+		slog.Debug("Published re-analysis", "workloads", len(next.Results))
+	}
+	return nil
+}
+
+// publishRequestsSkewFindings maps a requests-skew result to the findings
+// gauges. Per-workload gauges cover the reported (--top) workloads; the
+// wasted totals cover every analyzed workload.
+func publishRequestsSkewFindings(findings *telemetry.Findings, result *analyzer.RequestsSkewResult) {
+	workloads := make([]telemetry.WorkloadSkew, 0, len(result.Results))
+	for i := range result.Results {
+		w := &result.Results[i]
+		workloads = append(workloads, telemetry.WorkloadSkew{
+			Namespace:  w.Namespace,
+			Workload:   w.Workload,
+			Kind:       w.Type,
+			SkewCPU:    w.SkewCPU,
+			SkewMemory: w.SkewMemory,
+			WastedCPU:  max(w.RequestedCPU-w.P95UsedCPU, 0),
+		})
+	}
+	findings.SetRequestsSkew(telemetry.SkewSummary{
+		AnalyzedWorkloads: result.Summary.AnalyzedWorkloads,
+		WastedCPU:         result.Summary.TotalWastedCPU,
+		WastedMemoryBytes: result.Summary.TotalWastedMemoryGi * units.Gi,
+	}, workloads)
+}
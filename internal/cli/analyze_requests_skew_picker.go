@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/keymap"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/picker"
+)
+
+// minQueryLatency floors the per-query latency of run estimates, since the
+// health check that measures it is cheaper than any range query.
+const minQueryLatency = 50 * time.Millisecond
+
+// parseWorkloadKinds validates --workload-kinds against the kinds
+// requests-skew analyzes, matching case-insensitively. Empty selects all.
+func parseWorkloadKinds(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var kinds []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		found := ""
+		for _, k := range analyzer.RequestsSkewKinds {
+			if strings.EqualFold(part, k) {
+				found = k
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("invalid --workload-kinds %q (valid: %s)", part, strings.Join(analyzer.RequestsSkewKinds, ", "))
+		}
+		kinds = append(kinds, found)
+	}
+	return kinds, nil
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// pickRequestsSkewScope lists the namespaces the filters select with their
+// pod and workload counts, lets the user check namespaces and kinds, and
+// narrows cfg to the selection. It returns false when the user quits. The
+// picker draws on stderr so stdout stays clean for --output json.
+func pickRequestsSkewScope(
+	kubeClient kubernetes.Interface, provider metrics.MetricsProvider, cfg *analyzer.RequestsSkewConfig,
+	keys *keymap.Map, queryLatency time.Duration,
+) (bool, error) {
+	if !cfg.Silent {
+		slog.Info("Listing namespaces and workloads...")
+	}
+	planCfg := *cfg
+	planCfg.WorkloadKinds = nil
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	plans, err := analyzer.NewRequestsSkewAnalyzer(kubeClient, provider, &planCfg).PlanNamespaces(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(plans) == 0 {
+		return false, fmt.Errorf("no namespaces match the namespace filters")
+	}
+
+	namespaces := make([]picker.Namespace, len(plans))
+	for i, p := range plans {
+		namespaces[i] = picker.Namespace{Name: p.Namespace, Pods: p.Pods, Workloads: p.Workloads}
+	}
+	estimate := func(sel picker.Selection) string {
+		return describeRunEstimate(len(sel.Namespaces), sel.Workloads(namespaces), cfg.Workers, cfg.Concurrency,
+			requestsSkewConfig.queryRate, queryLatency)
+	}
+
+	model := picker.NewModel(namespaces, analyzer.RequestsSkewKinds, estimate)
+	model.SetKeyMap(keys)
+	if len(cfg.WorkloadKinds) > 0 {
+		model.SetKinds(cfg.WorkloadKinds)
+	}
+	if _, err := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(os.Stderr)).Run(); err != nil {
+		return false, fmt.Errorf("error running namespace picker: %w", err)
+	}
+	sel, ok := model.Selection()
+	if !ok {
+		return false, nil
+	}
+
+	cfg.Namespace = ""
+	cfg.NamespaceInclude = strings.Join(sel.Namespaces, ",")
+	cfg.WorkloadKinds = sel.Kinds
+	if len(sel.Kinds) == len(analyzer.RequestsSkewKinds) {
+		cfg.WorkloadKinds = nil
+	}
+
+	if !cfg.Silent {
+		slog.Info("Analyzing selection", "namespaces", len(sel.Namespaces), "workloads", sel.Workloads(namespaces),
+			"estimate", estimate(sel))
+		flags := "--namespace-include " + cfg.NamespaceInclude
+		if cfg.WorkloadKinds != nil {
+			flags += " --workload-kinds " + strings.Join(cfg.WorkloadKinds, ",")
+		}
+		slog.Info("Same selection without the picker", "flags", flags)
+	}
+	return true, nil
+}
+
+// describeRunEstimate renders the query count and expected duration of a
+// run, e.g. "~1,234 queries, ~2m at 4 workers". Namespaces analyzed in
+// parallel (concurrency) shorten the run down to the query rate limit
+// (queries per second, 0 = unlimited).
+func describeRunEstimate(namespaces, workloads, workers, concurrency int, queryRate float64, queryLatency time.Duration) string {
+	queries := analyzer.EstimateQueries(namespaces, workloads)
+	workers = min(max(workers, 1), 20)
+	concurrency = min(max(concurrency, 1), analyzer.MaxAnalysisConcurrency, max(namespaces, 1))
+	perWorkload := max(queryLatency, minQueryLatency) * analyzer.QueriesPerWorkload
+	d := time.Duration(namespaces)*max(queryLatency, minQueryLatency)*analyzer.QueriesPerNamespace +
+		time.Duration((workloads+workers-1)/workers)*perWorkload
+	d /= time.Duration(concurrency)
+	if queryRate > 0 {
+		d = max(d, time.Duration(float64(queries)/queryRate*float64(time.Second)))
+	}
+	label := fmt.Sprintf("%d worker(s)", workers)
+	if concurrency > 1 {
+		label += fmt.Sprintf(" x %d namespaces", concurrency)
+	}
+	return fmt.Sprintf("~%s queries, ~%s at %s", formatThousands(queries), formatDuration(max(d, time.Second)), label)
+}
+
+// formatThousands renders n with comma thousands separators.
+func formatThousands(n int) string {
+	s := fmt.Sprintf("%d", n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
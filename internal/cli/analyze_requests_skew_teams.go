@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/owners"
+)
+
+// teamPlaceholder in --export-file is replaced by each owning team's name,
+// writing one report per team.
+const teamPlaceholder = "{team}"
+
+// loadOwners loads --owners-file; an empty path means none.
+func loadOwners(path string) (*owners.File, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return owners.Load(path)
+}
+
+// splitRequestsSkewByTeam returns one report per owning team, each cut to
+// its own top N workloads (0 = all).
+func splitRequestsSkewByTeam(result *analyzer.RequestsSkewResult, top int) []*analyzer.RequestsSkewResult {
+	teams := result.Teams()
+	split := make([]*analyzer.RequestsSkewResult, 0, len(teams))
+	for _, team := range teams {
+		teamResult := result.ForTeam(team)
+		truncateResults(teamResult, top)
+		split = append(split, teamResult)
+	}
+	return split
+}
+
+// truncateResults keeps the first top results (0 = all); results are
+// already sorted.
+func truncateResults(result *analyzer.RequestsSkewResult, top int) {
+	if top > 0 && len(result.Results) > top {
+		result.Results = result.Results[:top]
+	}
+}
+
+// exportRequestsSkewPerTeam writes each team's report to path with
+// teamPlaceholder replaced by the team name, in the --output format (or
+// --export-format for table output).
+func exportRequestsSkewPerTeam(teamResults []*analyzer.RequestsSkewResult, path string) error {
+	for _, teamResult := range teamResults {
+		// Every workload of a team's report has the same (possibly obfuscated) team
+		team := teamResult.Teams()[0]
+		file := strings.ReplaceAll(path, teamPlaceholder, teamFileName(team))
+		var err error
+		switch {
+		case requestsSkewConfig.output != "table":
+			err = outputRequestsSkew(teamResult, nil, file)
+		case requestsSkewConfig.exportFormat == "json":
+			err = outputRequestsSkewJSON(teamResult, file)
+		case requestsSkewConfig.exportFormat == "html":
+			err = outputRequestsSkewHTML(teamResult, file)
+		case requestsSkewConfig.exportFormat == "sarif":
+			err = outputRequestsSkewSARIF(teamResult, file)
+		case requestsSkewConfig.exportFormat == "junit":
+			err = outputRequestsSkewJUnit(teamResult, file)
+		default:
+			err = exportTableToFile(teamResult, nil, file)
+		}
+		if err != nil {
+			return fmt.Errorf("team %s: %w", team, err)
+		}
+	}
+	return nil
+}
+
+// teamFileName makes a team name safe for a file name: characters other
+// than letters, digits, '.', '_' and '-' become '-'.
+func teamFileName(team string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, team)
+	return strings.Trim(name, "-.")
+}
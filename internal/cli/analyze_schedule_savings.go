@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var scheduleSavingsConfig struct {
+	prometheusURL          string
+	window                 string
+	timezone               string
+	namespaceInclude       string
+	idleCPU                float64
+	idleRequestFraction    float64
+	minIdleHours           int
+	format                 string
+	exportFile             string
+	includeQueries         bool
+	costCPU                float64
+	costMemory             float64
+	instanceType           string
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	silent                 bool
+}
+
+var scheduleSavingsCmd = &cobra.Command{
+	Use:   "schedule-savings",
+	Short: "Plan scale-to-zero schedules for workloads idle at night and on weekends",
+	Long: `Build weekly usage profiles for Deployments and StatefulSets in non-production
+namespaces and find the hours in which they sat idle in every observed week.
+Each recurring idle window of at least --min-idle-hours becomes a scale-to-zero
+schedule with its projected monthly savings.
+
+An hour counts as idle when the workload's peak CPU in that hour stayed below
+--idle-cpu cores or --idle-request-fraction of its requested CPU, whichever is
+higher. Hours without data count as busy, so use a window of at least 7 days.
+
+Namespaces default to common non-production patterns (*dev*, *test*, *staging*,
+*qa*, ...). Use -n for a single namespace or --namespace-include to override.
+
+Output formats:
+  table    Human-readable plan (default)
+  json     Machine-readable plan
+  cronjob  CronJobs running kubectl scale, with the RBAC they need
+  keda     KEDA ScaledObjects with cron triggers
+
+Examples:
+  # Plan for all non-prod namespaces over the last two weeks
+  kubenow analyze schedule-savings --prometheus-url http://localhost:9090
+
+  # Schedules in local time for one namespace
+  kubenow analyze schedule-savings --prometheus-url http://localhost:9090 \
+    -n team-a-dev --timezone Europe/Berlin
+
+  # Write KEDA ScaledObjects for review
+  kubenow analyze schedule-savings --prometheus-url http://localhost:9090 \
+    --format keda --export-file scale-schedules.yaml`,
+	RunE: runScheduleSavings,
+}
+
+func init() {
+	analyzeCmd.AddCommand(scheduleSavingsCmd)
+
+	f := scheduleSavingsCmd.Flags()
+	f.StringVar(&scheduleSavingsConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint (e.g., http://prometheus:9090)")
+	f.StringVar(&scheduleSavingsConfig.window, "window", "14d", "Usage history to profile (at least 7d)")
+	f.StringVar(&scheduleSavingsConfig.timezone, "timezone", "UTC", "IANA timezone for weekly profiles and schedules (e.g., America/New_York)")
+	f.StringVar(&scheduleSavingsConfig.namespaceInclude, "namespace-include", analyzer.DefaultNonProdNamespaces,
+		"Namespaces to analyze (comma-separated patterns); ignored with -n")
+	cpuFlagVar(f, &scheduleSavingsConfig.idleCPU, "idle-cpu", analyzer.DefaultIdleCPUCores, "Peak CPU below which an hour is idle, in cores or millicores (e.g., 0.01 or 10m)")
+	f.Float64Var(&scheduleSavingsConfig.idleRequestFraction, "idle-request-fraction", analyzer.DefaultIdleRequestFraction,
+		"Fraction of requested CPU below which an hour is idle")
+	f.IntVar(&scheduleSavingsConfig.minIdleHours, "min-idle-hours", analyzer.DefaultMinIdleHours, "Shortest idle window worth scheduling")
+	f.StringVar(&scheduleSavingsConfig.format, "format", "table", "Output format: table|json|cronjob|keda")
+	f.StringVar(&scheduleSavingsConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+	addIncludeQueriesFlag(f, &scheduleSavingsConfig.includeQueries)
+	f.Float64Var(&scheduleSavingsConfig.costCPU, "cost-per-cpu-hour", 0, "Cost per CPU core per hour in dollars (overrides instance-type lookup)")
+	f.Float64Var(&scheduleSavingsConfig.costMemory, "cost-per-gib-hour", 0, "Cost per GiB memory per hour in dollars (overrides instance-type lookup)")
+	f.StringVar(&scheduleSavingsConfig.instanceType, "instance-type", instanceTypeAuto,
+		"Cloud instance type for pricing (e.g., m5.xlarge), or 'auto' to blend the cluster's node types")
+	f.StringVar(&scheduleSavingsConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	f.StringVar(&scheduleSavingsConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	f.StringVar(&scheduleSavingsConfig.metricsTenant, "metrics-tenant", "", "Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	f.StringVar(&scheduleSavingsConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
+	f.BoolVar(&scheduleSavingsConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
+}
+
+func runScheduleSavings(_ *cobra.Command, _ []string) error {
+	cfg := &scheduleSavingsConfig
+	if cfg.prometheusURL == "" {
+		return fmt.Errorf("--prometheus-url is required")
+	}
+	switch cfg.format {
+	case "table", "json", "cronjob", "keda":
+	default:
+		return fmt.Errorf("invalid --format %q: must be table, json, cronjob, or keda", cfg.format)
+	}
+
+	window, err := metrics.ParseDuration(cfg.window)
+	if err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+	if window < 7*24*time.Hour && !cfg.silent {
+		slog.Warn("--window is shorter than a week; unobserved hours count as busy", "window", cfg.window)
+	}
+	loc, err := time.LoadLocation(cfg.timezone)
+	if err != nil {
+		return fmt.Errorf("invalid --timezone: %w", err)
+	}
+	timeout, err := time.ParseDuration(cfg.prometheusTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	queryLog := newQueryLog(cfg.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	promConfig := metrics.Config{
+		PrometheusURL: cfg.prometheusURL,
+		Timeout:       timeout,
+		Backend:       cfg.metricsBackend,
+		TenantID:      cfg.metricsTenant,
+		QueryLog:      queryLog,
+	}
+	promConfig.QueryOptions, err = resolveClusterLabel(cfg.prometheusClusterLabel, promConfig, kubeClient, timeout, cfg.silent)
+	if err != nil {
+		return err
+	}
+	metricsProvider, err := metrics.NewProvider(promConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics provider: %w", err)
+	}
+
+	healthCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err = metricsProvider.Health(healthCtx); err != nil {
+		return fmt.Errorf("prometheus health check failed: %w", err)
+	}
+
+	ctx := context.Background()
+	result, err := analyzer.AnalyzeScheduleSavings(ctx, kubeClient, metricsProvider, analyzer.ScheduleSavingsConfig{
+		Namespace:           GetNamespace(),
+		NamespaceInclude:    cfg.namespaceInclude,
+		Window:              window,
+		IdleCPUCores:        cfg.idleCPU,
+		IdleRequestFraction: cfg.idleRequestFraction,
+		MinIdleHours:        cfg.minIdleHours,
+		Location:            loc,
+		Rates:               clusterCostRates(ctx, kubeClient, cfg.instanceType, cfg.costCPU, cfg.costMemory, cfg.silent),
+		Silent:              cfg.silent,
+	})
+	if err != nil {
+		return fmt.Errorf("schedule-savings analysis failed: %w", err)
+	}
+	result.Queries = queryLog.Report(cfg.prometheusURL)
+
+	return writeScheduleSavings(result, cfg.format, cfg.exportFile)
+}
+
+func writeScheduleSavings(result *analyzer.ScheduleSavingsResult, format, exportFile string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(exportFile, string(data)+"\n")
+	case "cronjob", "keda":
+		render := analyzer.RenderCronJobManifests
+		if format == "keda" {
+			render = analyzer.RenderKEDAManifests
+		}
+		data, err := render(result)
+		if err != nil {
+			return err
+		}
+		if len(result.Plans) == 0 {
+			slog.Info("No idle windows found; nothing to schedule")
+		}
+		return writeOutputOrStdout(exportFile, string(data)+yamlComment(querylog.Render(result.Queries)))
+	default:
+		return writeOutputOrStdout(exportFile, renderScheduleSavingsTable(result)+querylog.Render(result.Queries))
+	}
+}
+
+func renderScheduleSavingsTable(r *analyzer.ScheduleSavingsResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\n=== Schedule Savings (window %s, timezone %s) ===\n", r.Window, r.Timezone)
+	fmt.Fprintf(&b, "Namespaces: %s\n\n", strings.Join(r.Namespaces, ", "))
+
+	if len(r.Plans) == 0 {
+		b.WriteString("No recurring idle windows found.\n")
+	} else {
+		table := tablewriter.NewWriter(&b)
+		table.Header([]string{"Namespace", "Workload", "Replicas", "Idle h/wk", "Off Windows", "Scale Down", "Scale Up", "Savings/mo"})
+		for i := range r.Plans {
+			p := &r.Plans[i]
+			windows := make([]string, 0, len(p.OffWindows))
+			for _, w := range p.OffWindows {
+				windows = append(windows, fmt.Sprintf("%s → %s", w.Start, w.End))
+			}
+			workload := p.Kind + "/" + p.Workload
+			if p.HasHPA {
+				workload += " (HPA)"
+			}
+			appendTableRowBestEffort(table, []string{
+				p.Namespace, workload, strconv.Itoa(int(p.Replicas)), strconv.Itoa(p.IdleHoursPerWeek),
+				strings.Join(windows, "\n"), strings.Join(p.ScaleDownCron, "\n"), strings.Join(p.ScaleUpCron, "\n"),
+				fmt.Sprintf("$%.2f", p.MonthlySavings),
+			})
+		}
+		renderTableBestEffort(table)
+		fmt.Fprintf(&b, "\nProjected savings: $%.2f/month (%s pricing)\n", r.TotalMonthlySavings, r.Rates.Source)
+	}
+
+	if len(r.AlwaysIdle) > 0 {
+		b.WriteString("\nIdle in every observed hour (consider scaling to zero or removing):\n")
+		for _, ref := range r.AlwaysIdle {
+			fmt.Fprintf(&b, "  • %s\n", ref)
+		}
+	}
+	if len(r.NoData) > 0 {
+		fmt.Fprintf(&b, "\n%d workload(s) had no usage data and were skipped.\n", len(r.NoData))
+	}
+	b.WriteString("\nUse --format cronjob or --format keda to generate manifests.\n")
+	return b.String()
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var storageSkewConfig struct {
+	prometheusURL          string
+	window                 string
+	fullThreshold          float64
+	margin                 float64
+	top                    int
+	output                 string
+	exportFile             string
+	includeQueries         bool
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	silent                 bool
+}
+
+var storageSkewCmd = &cobra.Command{
+	Use:   "storage-skew",
+	Short: "Find over-provisioned and nearly-full volumes and ephemeral storage",
+	Long: `Compare PersistentVolumeClaim requests with the usage the kubelet reports for
+their volumes (kubelet_volume_stats_used_bytes), and container
+ephemeral-storage requests and limits with container_fs_usage_bytes.
+
+Volumes and containers whose peak usage over --window plus --margin fits in
+80% or less of their request are over-provisioned, ranked by the bytes they
+could give back. Volumes using --full-threshold percent of their capacity or
+more, and containers that peaked at that share of their ephemeral-storage
+limit, are nearly full and listed first. Claims cannot shrink in place;
+the recommended size is for the volume to migrate to.
+
+Claims without volume stats (not mounted, or a CSI driver that does not
+report them) are listed separately. Containers without an ephemeral-storage
+request or limit are left out.
+
+Examples:
+  # Storage skew over the last week
+  kubenow analyze storage-skew --prometheus-url http://localhost:9090
+
+  # One namespace, flag volumes from 90% full
+  kubenow analyze storage-skew --prometheus-url http://localhost:9090 -n databases --full-threshold 90
+
+  # JSON export
+  kubenow analyze storage-skew --prometheus-url http://localhost:9090 --output json --export-file storage.json`,
+	RunE: runStorageSkew,
+}
+
+func init() {
+	analyzeCmd.AddCommand(storageSkewCmd)
+
+	f := storageSkewCmd.Flags()
+	f.StringVar(&storageSkewConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint (e.g., http://prometheus:9090)")
+	f.StringVar(&storageSkewConfig.window, "window", "7d", "Time window to analyze")
+	f.Float64Var(&storageSkewConfig.fullThreshold, "full-threshold", analyzer.DefaultStorageFullRatio*100,
+		"Flag volumes (of capacity) and containers (of their limit) using at least this percentage as nearly full")
+	f.Float64Var(&storageSkewConfig.margin, "margin", analyzer.DefaultStorageMargin,
+		"Headroom added to peak usage for recommended sizes (0.25 = 25%)")
+	f.IntVar(&storageSkewConfig.top, "top", 20, "Show the N most over-provisioned volumes and containers (0 = all)")
+	f.StringVar(&storageSkewConfig.output, "output", "table", "Output format: table|json")
+	f.StringVar(&storageSkewConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+	addIncludeQueriesFlag(f, &storageSkewConfig.includeQueries)
+	f.StringVar(&storageSkewConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	f.StringVar(&storageSkewConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	f.StringVar(&storageSkewConfig.metricsTenant, "metrics-tenant", "", "Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	f.StringVar(&storageSkewConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
+	f.BoolVar(&storageSkewConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
+}
+
+func runStorageSkew(_ *cobra.Command, _ []string) error {
+	cfg := &storageSkewConfig
+	if err := validateStorageSkewFlags(); err != nil {
+		return err
+	}
+
+	window, err := metrics.ParseDuration(cfg.window)
+	if err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+	timeout, err := time.ParseDuration(cfg.prometheusTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	queryLog := newQueryLog(cfg.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	promConfig := metrics.Config{
+		PrometheusURL: cfg.prometheusURL,
+		Timeout:       timeout,
+		Backend:       cfg.metricsBackend,
+		TenantID:      cfg.metricsTenant,
+		QueryLog:      queryLog,
+	}
+	promConfig.QueryOptions, err = resolveClusterLabel(cfg.prometheusClusterLabel, promConfig, kubeClient, timeout, cfg.silent)
+	if err != nil {
+		return err
+	}
+	metricsProvider, err := metrics.NewProvider(promConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics provider: %w", err)
+	}
+
+	healthCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err = metricsProvider.Health(healthCtx); err != nil {
+		return fmt.Errorf("prometheus health check failed: %w", err)
+	}
+
+	result, err := analyzer.AnalyzeStorageSkew(context.Background(), kubeClient, metricsProvider, analyzer.StorageSkewConfig{
+		Namespace: GetNamespace(),
+		Window:    window,
+		FullRatio: cfg.fullThreshold / 100,
+		Margin:    cfg.margin,
+		Top:       cfg.top,
+	})
+	if err != nil {
+		return fmt.Errorf("storage skew analysis failed: %w", err)
+	}
+	result.Queries = queryLog.Report(cfg.prometheusURL)
+
+	if cfg.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(cfg.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(cfg.exportFile, renderStorageSkewTable(result)+querylog.Render(result.Queries))
+}
+
+func validateStorageSkewFlags() error {
+	cfg := &storageSkewConfig
+	if cfg.prometheusURL == "" {
+		return fmt.Errorf("--prometheus-url is required")
+	}
+	if cfg.output != "table" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", cfg.output)
+	}
+	if cfg.fullThreshold <= 0 || cfg.fullThreshold > 100 {
+		return fmt.Errorf("--full-threshold must be between 0 and 100")
+	}
+	if cfg.margin < 0 {
+		return fmt.Errorf("--margin must not be negative")
+	}
+	if cfg.top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+	return nil
+}
+
+func renderStorageSkewTable(r *analyzer.StorageSkewResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== Storage Skew (last %s, nearly full at %.0f%%) ===\n\n", r.Window, r.FullThreshold)
+	fmt.Fprintf(&b, "Volumes: %d measured, %s requested, %s over-provisioned, %d nearly full\n",
+		r.Summary.Volumes, units.MemoryGi(r.Summary.RequestedVolumeBytes),
+		units.MemoryGi(r.Summary.OverProvisionedVolumeBytes), r.Summary.NearlyFullVolumes)
+	fmt.Fprintf(&b, "Ephemeral storage: %d containers, %s over-provisioned, %d nearly full\n",
+		r.Summary.Containers, units.Memory(r.Summary.OverProvisionedStorageBytes), r.Summary.NearlyFullContainers)
+
+	b.WriteString("\n--- Persistent volumes ---\n\n")
+	if len(r.Volumes) == 0 {
+		b.WriteString("No over-provisioned or nearly-full volumes.\n")
+	} else {
+		table := tablewriter.NewWriter(&b)
+		table.Header([]string{"Namespace", "PVC", "Mounted By", "Requested", "Capacity", "Used", "Peak", "Used %", "Recommended", "Status"})
+		for i := range r.Volumes {
+			v := &r.Volumes[i]
+			appendTableRowBestEffort(table, []string{
+				v.Namespace, v.PVC, v.MountedBy, units.MemoryGi(v.RequestedBytes), units.MemoryGi(v.CapacityBytes),
+				units.MemoryGi(v.UsedBytes), units.MemoryGi(v.PeakUsedBytes), fmt.Sprintf("%.0f%%", v.UsedPercent),
+				units.MemoryGi(v.RecommendedBytes), storageStatus(v.NearlyFull),
+			})
+		}
+		renderTableBestEffort(table)
+	}
+
+	b.WriteString("\n--- Ephemeral storage ---\n\n")
+	if len(r.Ephemeral) == 0 {
+		b.WriteString("No over-provisioned or nearly-full containers.\n")
+	} else {
+		table := tablewriter.NewWriter(&b)
+		table.Header([]string{"Namespace", "Workload", "Container", "Pods", "Request", "Limit", "Peak", "Recommended", "Status"})
+		for i := range r.Ephemeral {
+			c := &r.Ephemeral[i]
+			limit := units.Memory(c.LimitBytes)
+			if c.LimitBytes == 0 {
+				limit = "none"
+			}
+			appendTableRowBestEffort(table, []string{
+				c.Namespace, c.Kind + "/" + c.Workload, c.Container, strconv.Itoa(c.Pods), units.Memory(c.RequestBytes),
+				limit, units.Memory(c.PeakUsedBytes), units.Memory(c.RecommendedBytes), storageStatus(c.NearlyFull),
+			})
+		}
+		renderTableBestEffort(table)
+	}
+
+	if len(r.Volumes) > 0 || len(r.Ephemeral) > 0 {
+		b.WriteString("\n")
+	}
+	for i := range r.Volumes {
+		fmt.Fprintf(&b, "%s/%s: %s\n", r.Volumes[i].Namespace, r.Volumes[i].PVC, r.Volumes[i].Recommendation)
+	}
+	for i := range r.Ephemeral {
+		c := &r.Ephemeral[i]
+		fmt.Fprintf(&b, "%s/%s %s: %s\n", c.Namespace, c.Workload, c.Container, c.Recommendation)
+	}
+
+	if len(r.Unmeasured) > 0 {
+		fmt.Fprintf(&b, "\n%d claim(s) without volume stats (not mounted, or the CSI driver does not report them):\n", len(r.Unmeasured))
+		for i := range r.Unmeasured {
+			u := &r.Unmeasured[i]
+			fmt.Fprintf(&b, "  %s/%s (%s, %s)\n", u.Namespace, u.PVC, u.Phase, units.MemoryGi(u.RequestedBytes))
+		}
+	}
+	return b.String()
+}
+
+func storageStatus(nearlyFull bool) string {
+	if nearlyFull {
+		return "NEARLY FULL"
+	}
+	return "over-provisioned"
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/units"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var throttlingConfig struct {
+	prometheusURL          string
+	window                 string
+	threshold              float64
+	percentile             string
+	margin                 float64
+	top                    int
+	output                 string
+	exportFile             string
+	includeQueries         bool
+	prometheusTimeout      string
+	metricsBackend         string
+	metricsTenant          string
+	prometheusClusterLabel string
+	silent                 bool
+}
+
+var throttlingCmd = &cobra.Command{
+	Use:   "throttling",
+	Short: "Find CPU-throttled containers and recommend CPU limits",
+	Long: `Report the share of CFS enforcement periods in which each container hit its
+CPU quota (container_cpu_cfs_throttled_periods_total divided by
+container_cpu_cfs_periods_total), grouped by workload, worst first.
+
+Containers throttled in more than --threshold percent of their periods get a
+CPU limit recommendation: the larger of the current limit and the
+--percentile usage of the hottest replica, plus --margin. When usage reaches
+the limit, demand is sustained and the limit is too low. When usage stays far
+below a throttled limit, the container bursts for less than a scrape
+interval; raising the limit or removing it and keeping the request both help.
+
+Only containers with a CPU limit have CFS periods. Series of pods that no
+longer exist are left out.
+
+Examples:
+  # Throttled workloads over the last week
+  kubenow analyze throttling --prometheus-url http://localhost:9090
+
+  # One namespace, anything throttled more than 5% of the time
+  kubenow analyze throttling --prometheus-url http://localhost:9090 -n payments --threshold 5
+
+  # JSON export
+  kubenow analyze throttling --prometheus-url http://localhost:9090 --output json --export-file throttling.json`,
+	RunE: runThrottling,
+}
+
+func init() {
+	analyzeCmd.AddCommand(throttlingCmd)
+
+	f := throttlingCmd.Flags()
+	f.StringVar(&throttlingConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint (e.g., http://prometheus:9090)")
+	f.StringVar(&throttlingConfig.window, "window", "7d", "Time window to analyze")
+	f.Float64Var(&throttlingConfig.threshold, "threshold", analyzer.DefaultThrottlingThreshold*100,
+		"Report containers throttled in more than this percentage of CFS periods")
+	f.StringVar(&throttlingConfig.percentile, "percentile", "p99", "Usage percentile compared with the limit: p50, p90, p95, p99")
+	f.Float64Var(&throttlingConfig.margin, "margin", analyzer.DefaultThrottlingMargin,
+		"Headroom added to demand for the recommended limit (0.25 = 25%)")
+	f.IntVar(&throttlingConfig.top, "top", 20, "Show the N most throttled workloads (0 = all)")
+	f.StringVar(&throttlingConfig.output, "output", "table", "Output format: table|json")
+	f.StringVar(&throttlingConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+	addIncludeQueriesFlag(f, &throttlingConfig.includeQueries)
+	f.StringVar(&throttlingConfig.prometheusTimeout, "prometheus-timeout", "30s", "Query timeout")
+	f.StringVar(&throttlingConfig.metricsBackend, "metrics-backend", metrics.BackendPrometheus,
+		"Metrics backend: prometheus|victoriametrics|thanos|mimir")
+	f.StringVar(&throttlingConfig.metricsTenant, "metrics-tenant", "", "Tenant ID for multi-tenant backends (Mimir, VictoriaMetrics cluster)")
+	f.StringVar(&throttlingConfig.prometheusClusterLabel, "prometheus-cluster-label", "", clusterLabelFlagUsage)
+	f.BoolVar(&throttlingConfig.silent, "silent", false, "Suppress progress output (for CI/CD pipelines)")
+}
+
+func runThrottling(_ *cobra.Command, _ []string) error {
+	cfg := &throttlingConfig
+	if err := validateThrottlingFlags(); err != nil {
+		return err
+	}
+
+	window, err := metrics.ParseDuration(cfg.window)
+	if err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+	timeout, err := time.ParseDuration(cfg.prometheusTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	queryLog := newQueryLog(cfg.includeQueries)
+	kubeClient, err := util.BuildKubeClientWithOpts(queryLogKubeOpts(GetKubeOpts(), queryLog))
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	promConfig := metrics.Config{
+		PrometheusURL: cfg.prometheusURL,
+		Timeout:       timeout,
+		Backend:       cfg.metricsBackend,
+		TenantID:      cfg.metricsTenant,
+		QueryLog:      queryLog,
+	}
+	promConfig.QueryOptions, err = resolveClusterLabel(cfg.prometheusClusterLabel, promConfig, kubeClient, timeout, cfg.silent)
+	if err != nil {
+		return err
+	}
+	metricsProvider, err := metrics.NewProvider(promConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics provider: %w", err)
+	}
+
+	healthCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err = metricsProvider.Health(healthCtx); err != nil {
+		return fmt.Errorf("prometheus health check failed: %w", err)
+	}
+
+	result, err := analyzer.AnalyzeThrottling(context.Background(), kubeClient, metricsProvider, analyzer.ThrottlingConfig{
+		Namespace: GetNamespace(),
+		Window:    window,
+		Threshold: cfg.threshold / 100,
+		Margin:    cfg.margin,
+		Quantile:  nodeSkewQuantiles[cfg.percentile],
+		Top:       cfg.top,
+	})
+	if err != nil {
+		return fmt.Errorf("throttling analysis failed: %w", err)
+	}
+	result.Queries = queryLog.Report(cfg.prometheusURL)
+
+	if cfg.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(cfg.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(cfg.exportFile, renderThrottlingTable(result, cfg.percentile)+querylog.Render(result.Queries))
+}
+
+func validateThrottlingFlags() error {
+	cfg := &throttlingConfig
+	if cfg.prometheusURL == "" {
+		return fmt.Errorf("--prometheus-url is required")
+	}
+	if cfg.output != "table" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", cfg.output)
+	}
+	if _, ok := nodeSkewQuantiles[cfg.percentile]; !ok {
+		return fmt.Errorf("--percentile must be one of: p50, p90, p95, p99")
+	}
+	if cfg.threshold <= 0 || cfg.threshold > 100 {
+		return fmt.Errorf("--threshold must be between 0 and 100")
+	}
+	if cfg.margin < 0 {
+		return fmt.Errorf("--margin must not be negative")
+	}
+	if cfg.top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+	return nil
+}
+
+func renderThrottlingTable(r *analyzer.ThrottlingResult, percentile string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== CPU Throttling (last %s, > %.0f%% of periods) ===\n\n", r.Window, r.Threshold)
+	if len(r.Workloads) == 0 {
+		b.WriteString("No throttled containers.\n")
+		return b.String()
+	}
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Namespace", "Workload", "Container", "Pods", "Throttled", "Request", "Limit",
+		"Usage " + strings.ToUpper(percentile), "Recommended"})
+	for i := range r.Workloads {
+		w := &r.Workloads[i]
+		for j := range w.Containers {
+			c := &w.Containers[j]
+			limit := units.Cores(c.CPULimit)
+			if c.CPULimit == 0 {
+				limit = "none"
+			}
+			appendTableRowBestEffort(table, []string{
+				w.Namespace, w.Kind + "/" + w.Workload, c.Name, strconv.Itoa(c.Pods), fmt.Sprintf("%.1f%%", c.ThrottledPercent),
+				units.Cores(c.CPURequest), limit, units.Cores(c.CPUUsage), units.Cores(c.RecommendedLimit),
+			})
+		}
+	}
+	renderTableBestEffort(table)
+
+	b.WriteString("\n")
+	for i := range r.Workloads {
+		w := &r.Workloads[i]
+		for j := range w.Containers {
+			fmt.Fprintf(&b, "%s/%s %s: %s\n", w.Namespace, w.Workload, w.Containers[j].Name, w.Containers[j].Recommendation)
+		}
+	}
+	return b.String()
+}
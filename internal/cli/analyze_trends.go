@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -45,7 +46,7 @@ func runTrends(_ *cobra.Command, _ []string) error {
 	}
 
 	if len(history) == 0 {
-		fmt.Fprintln(os.Stderr, "No trend data found. Run 'analyze requests-skew --track-trends' to start collecting snapshots.")
+		slog.Info("No trend data found. Run 'analyze requests-skew --track-trends' to start collecting snapshots.")
 		return nil
 	}
 
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ppiankov/kubenow/internal/capacity"
+	"github.com/ppiankov/kubenow/internal/export"
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/llmcache"
+	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var capacityConfig struct {
+	LLMCommandConfig
+	skewReport string
+	nodePools  bool
+}
+
+var capacityCmd = &cobra.Command{
+	Use:   "capacity",
+	Short: "Capacity-planning narrative from a requests-skew report using LLM",
+	Long: `Write an executive capacity-planning report from a requests-skew analysis.
+
+The LLM does not see pods. It gets the deterministic requests-skew result,
+condensed to totals, per-namespace rollups, the most over- and
+under-provisioned workloads, and (unless --node-pools=false) the cluster's
+node pools with allocatable capacity against pod requests. It returns
+projected headroom, consolidation opportunities, and node pool
+recommendations.
+
+Write the skew report first with
+  kubenow analyze requests-skew --prometheus-url ... --output json --export-file skew.json
+
+Examples:
+  # Capacity plan from a skew report and the current cluster's node pools
+  kubenow capacity --skew-report skew.json --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b
+
+  # Plan for expected growth, without cluster access
+  kubenow capacity --skew-report skew.json --node-pools=false --hint "2x traffic in Q4" \
+    --llm-endpoint https://api.openai.com/v1 --model gpt-4o
+
+  # Export the plan
+  kubenow capacity --skew-report skew.json --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --output capacity.md`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runCapacity()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(capacityCmd)
+	config := &capacityConfig.LLMCommandConfig
+	capacityCmd.Flags().StringVar(&capacityConfig.skewReport, "skew-report", "",
+		"requests-skew JSON report to plan from (kubenow analyze requests-skew --output json --export-file)")
+	capacityCmd.Flags().BoolVar(&capacityConfig.nodePools, "node-pools", true,
+		"Read node pools and pod requests from the current cluster for headroom and pool recommendations")
+	mustMarkFlagRequired(capacityCmd, "skew-report")
+	addLLMClientFlags(capacityCmd, config)
+	capacityCmd.Flags().StringVar(&config.ProblemHint, "hint", "", "Planning context for the report (e.g., '2x traffic in Q4', 'move batch to spot')")
+	addTemplateFlags(capacityCmd, config)
+}
+
+// runCapacity condenses the skew report, asks the LLM for the capacity
+// plan, and renders it.
+func runCapacity() error {
+	config := &capacityConfig.LLMCommandConfig
+	config.Mode = "capacity"
+	if err := validateLLMClientFlags(config, true); err != nil {
+		return err
+	}
+
+	skew, err := capacity.LoadSkewReport(capacityConfig.skewReport)
+	if err != nil {
+		return err
+	}
+	var pools []capacity.NodePool
+	if capacityConfig.nodePools {
+		pools = clusterNodePools()
+	}
+	input := capacity.Build(skew, pools)
+
+	enhancements, err := promptEnhancements(config)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal capacity input: %w", err)
+	}
+	finalPrompt, err := prompt.LoadPrompt(config.Mode, string(data), config.ProblemHint, enhancements)
+	if err != nil {
+		return fmt.Errorf("prompt error: %w", err)
+	}
+
+	llmClient := newLLMClient(config)
+	ctx, cancel := context.WithTimeout(context.Background(), llmClient.Timeout)
+	defer cancel()
+
+	meter := llm.NewMeter(config.Model, llmPrice(config))
+	llmClient.OnUsage = meter.Record
+	raw, err := completeCapacity(ctx, &llmClient, config, enhancements, input, finalPrompt)
+	if err != nil {
+		return fmt.Errorf("llm error: %w", err)
+	}
+	cost := meter.Cost()
+	if cost != nil {
+		slog.Info("LLM usage", "usage", cost.String())
+	}
+
+	clusterName := input.Cluster
+	if clusterName == "" {
+		clusterName = "unknown"
+	}
+	meta := export.ExportMetadata{ClusterName: clusterName, Mode: config.Mode, LLMCost: cost}
+	return handleOutput(raw, config.Format, config.OutputFile, &meta, nil)
+}
+
+// completeCapacity calls the LLM, reusing a cached answer for an unchanged
+// capacity input.
+func completeCapacity(
+	ctx context.Context, llmClient *llm.Client, config *LLMCommandConfig,
+	enhancements prompt.PromptEnhancements, input *capacity.Input, finalPrompt string,
+) (string, error) {
+	cache, err := newLLMCache(config)
+	if err != nil {
+		return "", err
+	}
+	if cache == nil {
+		return completeLLM(ctx, llmClient, finalPrompt, config)
+	}
+	key, err := llmcache.KeyOf(input, llmcache.NewOptions(llmClient, config.Mode, config.ProblemHint, enhancements, 0))
+	if err != nil {
+		return "", err
+	}
+	return completeWithCache(ctx, llmClient, config, cache, key, config.Mode, finalPrompt)
+}
+
+// clusterNodePools reads the current cluster's node pools. It is
+// best-effort: without cluster access the plan is written without them.
+func clusterNodePools() []capacity.NodePool {
+	clientset, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		slog.Warn("Cannot read node pools; planning from the skew report only", "error", err)
+		return nil
+	}
+	ctx := context.Background()
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("Cannot list nodes; planning from the skew report only", "error", err)
+		return nil
+	}
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("Cannot list pods; planning from the skew report only", "error", err)
+		return nil
+	}
+	pools := capacity.NodePools(nodes.Items, pods.Items)
+	slog.Debug("Read node pools", "pools", len(pools), "nodes", len(nodes.Items))
+	return pools
+}
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/bundle"
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/export"
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/llmcache"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/redact"
+	"github.com/ppiankov/kubenow/internal/result"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var bundleCollectConfig struct {
+	llm        LLMCommandConfig
+	output     string
+	window     string
+	tailLines  int64
+	reportFile string
+}
+
+var bundleCollectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "Package incident evidence into one shareable archive",
+	Long: `Collect a curated incident bundle: a tar.gz with an index.html that links
+the cluster snapshot, the events timeline, the manifests of the affected
+workloads, their container logs, and the LLM report, for attaching to
+tickets and sharing with vendors.
+
+Affected workloads are those of the snapshot's problem pods; for each, the
+pod, its ReplicaSet, and its Deployment, StatefulSet, DaemonSet, Job, or
+CronJob are exported without managedFields or the last-applied annotation.
+Logs are the last --tail-lines of every container, plus the previous run
+of containers that restarted. Secrets are never read.
+
+Logs, events, manifests, and the snapshot are masked with --redact-profile
+before they are written, like snapshots sent to an LLM. The LLM report is
+only produced with --llm-endpoint/--model (incident mode); --report adds a
+report exported earlier instead. snapshot.json can be replayed with
+'kubenow incident --from-snapshot'.
+
+Examples:
+  # Bundle one namespace
+  kubenow collect -n shop --output shop-incident.tar.gz
+
+  # With an LLM incident report
+  kubenow collect -n shop --output shop-incident.tar.gz \
+    --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b
+
+  # Attach a report exported earlier, strict redaction
+  kubenow collect -n shop --report incident.html --redact-profile strict`,
+	RunE: runBundleCollect,
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCollectCmd)
+	f := bundleCollectCmd.Flags()
+	f.StringVarP(&bundleCollectConfig.output, "output", "o", "", "Bundle file (default kubenow-bundle-<namespace>-<time>.tar.gz)")
+	f.StringVar(&bundleCollectConfig.window, "window", "24h", "Events timeline lookback (e.g., 1h, 24h)")
+	f.Int64Var(&bundleCollectConfig.tailLines, "tail-lines", bundle.DefaultTailLines, "Log lines per container in the bundle")
+	f.StringVar(&bundleCollectConfig.reportFile, "report", "", "Report file to include (e.g. from 'kubenow incident --output report.html')")
+
+	cfg := &bundleCollectConfig.llm
+	f.StringVar(&cfg.LLMEndpoint, "llm-endpoint", "", "LLM API base URL for the incident report (optional)")
+	f.StringVar(&cfg.Model, "model", "", "Model name for the incident report (optional)")
+	f.StringVar(&cfg.LLMProvider, "llm-provider", llm.ProviderOpenAI, "LLM API: openai, anthropic, or gemini")
+	f.StringVar(&cfg.APIKey, "api-key", "", "LLM API key (defaults to OPENAI_API_KEY, ANTHROPIC_API_KEY, or GEMINI_API_KEY/GOOGLE_API_KEY)")
+	f.IntVar(&cfg.MaxResponseTokens, "max-response-tokens", 0, "Cap on generated tokens (0 = provider default)")
+	f.IntVar(&cfg.TimeoutSeconds, "timeout-seconds", 60, "LLM call timeout in seconds")
+	f.BoolVar(&cfg.NoCache, "no-cache", false, "Always call the LLM instead of reusing a cached answer for an unchanged snapshot")
+	addSnapshotFlags(bundleCollectCmd, cfg)
+	addPromptFlags(bundleCollectCmd, cfg)
+}
+
+//nolint:gocyclo // sequential collect → analyze → archive pipeline
+func runBundleCollect(_ *cobra.Command, _ []string) error {
+	cfg := &bundleCollectConfig.llm
+	cfg.Mode = "incident"
+	cfg.Format = "human"
+	cfg.CacheTTL = llmcache.DefaultTTL
+	withLLM := cfg.LLMEndpoint != "" || cfg.Model != ""
+	if err := validateLLMClientFlags(cfg, withLLM); err != nil {
+		return err
+	}
+	window, err := metrics.ParseDuration(bundleCollectConfig.window)
+	if err != nil {
+		return fmt.Errorf("invalid --window: %w", err)
+	}
+	if bundleCollectConfig.tailLines <= 0 {
+		return fmt.Errorf("--tail-lines must be positive")
+	}
+	var report []byte
+	if bundleCollectConfig.reportFile != "" {
+		if report, err = os.ReadFile(bundleCollectConfig.reportFile); err != nil {
+			return fmt.Errorf("failed to read --report: %w", err)
+		}
+	}
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+	clusterName := extractClusterName(GetKubeconfig())
+	namespace := GetNamespace()
+	ctx := context.Background()
+	now := time.Now()
+
+	output := bundleCollectConfig.output
+	if output == "" {
+		output = bundle.RootName(namespace, now) + ".tar.gz"
+	}
+
+	slog.Info("Collecting cluster snapshot...")
+	filters := snapshotFilters(cfg)
+	snap, err := snapshot.BuildSnapshot(ctx, clientset, namespace, cfg.MaxPods, cfg.LogLines, cfg.MaxConcurrent, &filters)
+	if err != nil {
+		return fmt.Errorf("snapshot error: %w", err)
+	}
+
+	// The LLM analysis redacts the snapshot itself before building the prompt.
+	var analysis *llmAnalysis
+	var redaction *redact.Summary
+	if withLLM {
+		enhancements, err := promptEnhancements(cfg)
+		if err != nil {
+			return err
+		}
+		llmClient := newLLMClient(cfg)
+		if analysis, err = runLLMAnalysis(clientset, &llmClient, cfg, &filters, enhancements, clusterName, snap); err != nil {
+			return err
+		}
+		redaction = analysis.meta.Redaction
+	} else {
+		redaction = snap.Redact(redactor)
+	}
+	if redaction == nil {
+		redaction = redactor.NewSummary()
+	}
+	mask := func(s string) string { return redactor.Redact(s, redaction) }
+
+	timeline, err := analyzer.BuildEventTimeline(ctx, clientset, analyzer.TimelineConfig{Namespace: namespace, Window: window, Now: now})
+	if err != nil {
+		return err
+	}
+	for i := range timeline.Entries {
+		timeline.Entries[i].Message = mask(timeline.Entries[i].Message)
+	}
+
+	pods := make([]bundle.PodRef, 0, len(snap.ProblemPods))
+	for i := range snap.ProblemPods {
+		pods = append(pods, bundle.PodRef{Namespace: snap.ProblemPods[i].Namespace, Name: snap.ProblemPods[i].Name})
+	}
+	slog.Info("Collecting manifests and logs of problem pods", "pods", len(pods))
+	evidence := bundle.Collect(ctx, clientset, pods, bundle.CollectOptions{TailLines: bundleCollectConfig.tailLines, Redact: mask})
+
+	file, err := cleanup.Create(output, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer file.Discard()
+
+	meta := bundle.Meta{
+		Cluster: clusterName, Namespace: namespace, KubenowVersion: version, GeneratedAt: now,
+		Summary: []string{
+			fmt.Sprintf("%d problem pod(s), %d node(s) with conditions", len(snap.ProblemPods), len(snap.NodeConditions)),
+			fmt.Sprintf("%d warning event(s), %d restart(s), %d deploy(s) in the last %s",
+				timeline.Summary.Warnings, timeline.Summary.Restarts, timeline.Summary.Deploys, timeline.Window),
+		},
+		Errors: evidence.Errors,
+	}
+	if redaction.Redactions > 0 {
+		meta.Redaction = redaction.String()
+	}
+	w := bundle.NewWriter(file, meta)
+
+	if analysis != nil {
+		if err := addLLMReport(w, analysis, now); err != nil {
+			return err
+		}
+	}
+	if report != nil {
+		name := "report/" + filepath.Base(bundleCollectConfig.reportFile)
+		if err := w.Add(bundle.SectionReport, name, "report included with --report", report); err != nil {
+			return err
+		}
+	}
+
+	if err := w.AddJSON(bundle.SectionSnapshot, "snapshot.json", "cluster snapshot (replay with --from-snapshot)", snapshot.File{
+		SchemaVersion: snapshot.SchemaVersion, KubenowVersion: version, Cluster: clusterName, SavedAt: now.UTC(), Snapshot: snap,
+	}); err != nil {
+		return err
+	}
+
+	if err := w.AddJSON(bundle.SectionEvents, "events/timeline.json", "events, restarts, and deploys", timeline); err != nil {
+		return err
+	}
+	var timelineHTML bytes.Buffer
+	if err := export.ExportEventTimelineHTML(timeline, &export.ExportMetadata{
+		GeneratedAt: now, KubenowVersion: version, ClusterName: clusterName, Mode: "events-timeline",
+	}, &timelineHTML); err != nil {
+		return err
+	}
+	if err := w.Add(bundle.SectionEvents, "events/timeline.html", "events timeline", timelineHTML.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Add(bundle.SectionEvents, "events/timeline.mmd", "events timeline as a Mermaid chart",
+		[]byte(export.RenderTimelineMermaid(timeline))); err != nil {
+		return err
+	}
+
+	for _, f := range slices.Concat(evidence.Manifests, evidence.Logs) {
+		if err := w.Add(f.Section, f.Path, f.Title, evidence.Data[f.Path]); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := file.Commit(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if redaction.Redactions > 0 {
+		slog.Info("Redacted the bundle", "redacted", redaction.String(), "redactions", redaction.Redactions,
+			"profile", string(redaction.Profile))
+	}
+	for _, e := range evidence.Errors {
+		slog.Warn("Evidence not collected", "error", e)
+	}
+	slog.Info("Bundle saved", "path", output, "files", len(w.Files())+1)
+	return nil
+}
+
+// addLLMReport adds the incident report as HTML and Markdown, or the raw
+// answer when it holds no incident JSON.
+func addLLMReport(w *bundle.Writer, a *llmAnalysis, now time.Time) error {
+	jsonStr, err := llm.ExtractJSON(a.raw)
+	var ir result.IncidentResult
+	if err == nil {
+		err = json.Unmarshal([]byte(jsonStr), &ir)
+	}
+	if err != nil {
+		slog.Warn("LLM answer is not an incident report, bundling the raw answer", "error", err)
+		return w.Add(bundle.SectionReport, "report.txt", "LLM answer", []byte(a.raw))
+	}
+
+	meta := a.meta
+	meta.GeneratedAt = now.UTC()
+	meta.KubenowVersion = version
+	for _, r := range []struct {
+		name, title string
+		format      export.Format
+	}{
+		{"report.html", "LLM incident report", export.FormatHTML},
+		{"report.md", "LLM incident report (Markdown)", export.FormatMarkdown},
+	} {
+		var buf bytes.Buffer
+		exporter := export.Exporter{Format: r.format, Metadata: meta}
+		if err := exporter.Export(&ir, &buf); err != nil {
+			return fmt.Errorf("failed to export LLM report: %w", err)
+		}
+		if err := w.Add(bundle.SectionReport, r.name, r.title, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/promonitor"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// completionTimeout bounds the cluster queries behind one completion, so
+// a slow or unreachable cluster never hangs the shell.
+const completionTimeout = 5 * time.Second
+
+// namespaceFlags complete to the cluster's namespaces.
+var namespaceFlags = []string{"namespace", "k8s-namespace", "agent-namespace", "watch-namespace"}
+
+// prometheusServiceHints rank services that look like a Prometheus
+// query endpoint first in --k8s-service completion.
+var prometheusServiceHints = []string{"prometheus", "thanos", "victoria", "vmselect", "mimir", "cortex"}
+
+// workloadKinds are the kinds completed for <kind>/<name> arguments.
+var workloadKinds = []string{"deployment", "statefulset", "daemonset", "pod"}
+
+// newCompletionClient builds the client behind cluster completions.
+// Replaced in tests.
+var newCompletionClient = completionClient
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish]",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for bash, zsh, or fish.
+
+Besides commands and flags, the scripts complete names from the current
+cluster: namespaces for -n and the other namespace flags, <kind>/<name>
+workloads for pro-monitor and agent fetch, and Prometheus services (in
+--k8s-namespace) for --k8s-service. Cluster lookups use the same
+kubeconfig, context, and impersonation flags as the command being completed.
+
+Bash (requires bash-completion v2):
+  source <(kubenow completion bash)
+  # permanently:
+  kubenow completion bash > /etc/bash_completion.d/kubenow
+
+Zsh:
+  kubenow completion zsh > "${fpath[1]}/_kubenow"
+  # compinit must be enabled: echo "autoload -U compinit; compinit" >> ~/.zshrc
+
+Fish:
+  kubenow completion fish > ~/.config/fish/completions/kubenow.fish`,
+	Args:                  cobra.ExactArgs(1),
+	ValidArgs:             []string{"bash", "zsh", "fish"},
+	DisableFlagsInUseLine: true,
+	RunE:                  runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	root := cmd.Root()
+	switch args[0] {
+	case "bash":
+		return root.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return root.GenZshCompletion(os.Stdout)
+	case "fish":
+		return root.GenFishCompletion(os.Stdout, true)
+	default:
+		return fmt.Errorf("unsupported shell %q: must be bash, zsh, or fish", args[0])
+	}
+}
+
+// registerCompletions attaches the dynamic completions to every command
+// in the tree: namespace flags, --k8s-service, and <kind>/<name>
+// arguments. It runs once all commands and flags are defined.
+func registerCompletions(cmd *cobra.Command) {
+	register := func(flags *pflag.FlagSet) {
+		flags.VisitAll(func(f *pflag.Flag) {
+			var fn cobra.CompletionFunc
+			switch {
+			case slices.Contains(namespaceFlags, f.Name):
+				fn = completeNamespaces
+			case f.Name == "k8s-service":
+				fn = completePrometheusServices
+			default:
+				return
+			}
+			if _, ok := cmd.GetFlagCompletionFunc(f.Name); ok {
+				return
+			}
+			if err := cmd.RegisterFlagCompletionFunc(f.Name, fn); err != nil {
+				panic(err)
+			}
+		})
+	}
+	register(cmd.LocalNonPersistentFlags())
+	register(cmd.PersistentFlags())
+
+	if cmd.ValidArgsFunction == nil && strings.Contains(cmd.Use, "<kind>/<name>") {
+		cmd.ValidArgsFunction = completeWorkloadRefs
+	}
+	for _, sub := range cmd.Commands() {
+		registerCompletions(sub)
+	}
+}
+
+// completionClient builds a client for the command being completed,
+// applying the config file and profile as a run would.
+func completionClient(cmd *cobra.Command) (kubernetes.Interface, error) {
+	initConfig()
+	if err := applyConfig(cmd); err != nil {
+		return nil, err
+	}
+	return util.BuildKubeClientWithOpts(GetKubeOpts())
+}
+
+func completeNamespaces(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	client, err := newCompletionClient(cmd)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(list.Items))
+	for i := range list.Items {
+		names = append(names, list.Items[i].Name)
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePrometheusServices completes --k8s-service with the services
+// in --k8s-namespace, listing likely Prometheus endpoints first (or only
+// them, when there are any).
+func completePrometheusServices(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	client, err := newCompletionClient(cmd)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ns, err := cmd.Flags().GetString("k8s-namespace")
+	if err != nil || ns == "" {
+		ns = "monitoring"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	list, err := client.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var prometheus, other []string
+	for i := range list.Items {
+		name := list.Items[i].Name
+		if slices.ContainsFunc(prometheusServiceHints, func(h string) bool { return strings.Contains(name, h) }) {
+			prometheus = append(prometheus, name)
+		} else {
+			other = append(other, name)
+		}
+	}
+	if len(prometheus) > 0 {
+		return filterCompletions(prometheus, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterCompletions(other, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkloadRefs completes a <kind>/<name> argument: the kind
+// first, then the names of that kind in the command's namespace.
+func completeWorkloadRefs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	kind, prefix, ok := strings.Cut(toComplete, "/")
+	if !ok {
+		kinds := make([]string, len(workloadKinds))
+		for i, k := range workloadKinds {
+			kinds[i] = k + "/"
+		}
+		return filterCompletions(kinds, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	}
+
+	client, err := newCompletionClient(cmd)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ns := GetNamespace()
+	if ns == "" {
+		ns = "default"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	names, err := listWorkloadNames(ctx, client, kind, ns)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	refs := make([]string, 0, len(names))
+	for _, name := range filterCompletions(names, prefix) {
+		refs = append(refs, kind+"/"+name)
+	}
+	return refs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// listWorkloadNames lists the names of one workload kind (as typed,
+// including the short forms pro-monitor accepts) in a namespace.
+func listWorkloadNames(ctx context.Context, client kubernetes.Interface, kind, ns string) ([]string, error) {
+	canonical, err := promonitor.NormalizeKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	opts := metav1.ListOptions{}
+	var names []string
+	switch canonical {
+	case promonitor.KindDeployment:
+		list, err := client.AppsV1().Deployments(ns).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			names = append(names, list.Items[i].Name)
+		}
+	case promonitor.KindStatefulSet:
+		list, err := client.AppsV1().StatefulSets(ns).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			names = append(names, list.Items[i].Name)
+		}
+	case promonitor.KindDaemonSet:
+		list, err := client.AppsV1().DaemonSets(ns).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			names = append(names, list.Items[i].Name)
+		}
+	case promonitor.KindPod:
+		list, err := client.CoreV1().Pods(ns).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			names = append(names, list.Items[i].Name)
+		}
+	}
+	return names, nil
+}
+
+// filterCompletions keeps the candidates starting with prefix, sorted.
+func filterCompletions(candidates []string, prefix string) []cobra.Completion {
+	out := make([]cobra.Completion, 0, len(candidates))
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func objectMeta(ns, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Namespace: ns, Name: name}
+}
+
+// useFakeCompletionClient serves completions from a fake clientset with
+// objects for the rest of the test.
+func useFakeCompletionClient(t *testing.T, objects ...runtime.Object) {
+	t.Helper()
+	client := fake.NewClientset(objects...)
+	orig := newCompletionClient
+	newCompletionClient = func(*cobra.Command) (kubernetes.Interface, error) { return client, nil }
+	t.Cleanup(func() { newCompletionClient = orig })
+}
+
+func TestFilterCompletions(t *testing.T) {
+	candidates := []string{"payments", "api", "payment-worker", "web"}
+
+	assert.Equal(t, []cobra.Completion{"payment-worker", "payments"}, filterCompletions(candidates, "pay"))
+	assert.Equal(t, []cobra.Completion{"api", "payment-worker", "payments", "web"}, filterCompletions(candidates, ""))
+	assert.Empty(t, filterCompletions(candidates, "x"))
+}
+
+func TestListWorkloadNames(t *testing.T) {
+	client := fake.NewClientset(
+		&appsv1.Deployment{ObjectMeta: objectMeta("shop", "api")},
+		&appsv1.Deployment{ObjectMeta: objectMeta("other", "elsewhere")},
+		&appsv1.StatefulSet{ObjectMeta: objectMeta("shop", "db")},
+		&appsv1.DaemonSet{ObjectMeta: objectMeta("shop", "agent")},
+		&corev1.Pod{ObjectMeta: objectMeta("shop", "api-7f9c")},
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{"deployment", []string{"api"}},
+		{"deploy", []string{"api"}},
+		{"Deployments", []string{"api"}},
+		{"sts", []string{"db"}},
+		{"ds", []string{"agent"}},
+		{"po", []string{"api-7f9c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			names, err := listWorkloadNames(ctx, client, tt.kind, "shop")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names)
+		})
+	}
+
+	_, err := listWorkloadNames(ctx, client, "cronjob", "shop")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported workload kind")
+}
+
+func TestCompletePrometheusServices(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("k8s-namespace", "", "")
+
+	t.Run("prometheus services first", func(t *testing.T) {
+		useFakeCompletionClient(t,
+			&corev1.Service{ObjectMeta: objectMeta("monitoring", "grafana")},
+			&corev1.Service{ObjectMeta: objectMeta("monitoring", "prometheus-server")},
+			&corev1.Service{ObjectMeta: objectMeta("monitoring", "thanos-query")},
+			&corev1.Service{ObjectMeta: objectMeta("other", "prometheus-other")},
+		)
+		got, directive := completePrometheusServices(cmd, nil, "")
+		assert.Equal(t, []cobra.Completion{"prometheus-server", "thanos-query"}, got)
+		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+		got, _ = completePrometheusServices(cmd, nil, "th")
+		assert.Equal(t, []cobra.Completion{"thanos-query"}, got)
+	})
+
+	t.Run("all services without a likely endpoint", func(t *testing.T) {
+		useFakeCompletionClient(t,
+			&corev1.Service{ObjectMeta: objectMeta("metrics", "vm")},
+			&corev1.Service{ObjectMeta: objectMeta("metrics", "grafana")},
+		)
+		require.NoError(t, cmd.Flags().Set("k8s-namespace", "metrics"))
+		got, _ := completePrometheusServices(cmd, nil, "")
+		assert.Equal(t, []cobra.Completion{"grafana", "vm"}, got)
+	})
+}
+
+func TestCompleteWorkloadRefs(t *testing.T) {
+	useFakeCompletionClient(t,
+		&appsv1.Deployment{ObjectMeta: objectMeta("shop", "api")},
+		&appsv1.Deployment{ObjectMeta: objectMeta("shop", "admin")},
+		&appsv1.Deployment{ObjectMeta: objectMeta("shop", "web")},
+	)
+	origNamespace := namespace
+	namespace = "shop"
+	t.Cleanup(func() { namespace = origNamespace })
+	cmd := &cobra.Command{}
+
+	got, directive := completeWorkloadRefs(cmd, nil, "d")
+	assert.Equal(t, []cobra.Completion{"daemonset/", "deployment/"}, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoSpace|cobra.ShellCompDirectiveNoFileComp, directive)
+
+	got, directive = completeWorkloadRefs(cmd, nil, "deploy/a")
+	assert.Equal(t, []cobra.Completion{"deploy/admin", "deploy/api"}, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	got, _ = completeWorkloadRefs(cmd, nil, "cronjob/a")
+	assert.Empty(t, got)
+
+	got, _ = completeWorkloadRefs(cmd, []string{"deployment/api"}, "")
+	assert.Empty(t, got)
+}
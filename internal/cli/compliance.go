@@ -12,7 +12,8 @@ var complianceCmd = &cobra.Command{
 	Long: `Perform compliance and security analysis using LLM.
 
 This command analyzes your cluster for compliance issues, security concerns,
-and best practice violations.
+and best practice violations. When the admin policy defines resource_ratios,
+deterministic limit/request ratio violations are added to the findings.
 
 Examples:
   # Run compliance check
@@ -22,7 +23,10 @@ Examples:
   kubenow compliance --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --output compliance.html
 
   # Detailed compliance analysis
-  kubenow compliance --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --enhance-technical --enhance-priority`,
+  kubenow compliance --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --enhance-technical --enhance-priority
+
+  # Include the limit/request ratio audit from an admin policy file
+  kubenow compliance --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --policy ./policy.yaml`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		complianceConfig.Mode = "compliance"
 		if err := RunLLMCommand(cmd, &complianceConfig); err != nil {
@@ -35,4 +39,5 @@ Examples:
 func init() {
 	rootCmd.AddCommand(complianceCmd)
 	addLLMFlags(complianceCmd, &complianceConfig)
+	complianceCmd.Flags().StringVar(&complianceConfig.PolicyFile, "policy", "", "path to admin policy file (resource_ratios audit)")
 }
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// addContextsFlags registers --contexts and --all-contexts, which run a
+// command once per kubeconfig context.
+func addContextsFlags(cmd *cobra.Command, contexts *string, all *bool) {
+	cmd.Flags().StringVar(contexts, "contexts", "", "Analyze each of these kubeconfig contexts (comma-separated) and add a cross-cluster summary")
+	cmd.Flags().BoolVar(all, "all-contexts", false, "Analyze every context in the kubeconfig and add a cross-cluster summary")
+}
+
+// resolveContexts returns the contexts selected by --contexts or
+// --all-contexts, in the order given (all contexts sorted by name), or nil
+// when neither is set.
+func resolveContexts(list string, all bool) ([]util.KubeContext, error) {
+	if list == "" && !all {
+		return nil, nil
+	}
+	if list != "" && all {
+		return nil, fmt.Errorf("--contexts and --all-contexts are mutually exclusive")
+	}
+	if GetKubecontext() != "" {
+		return nil, fmt.Errorf("--context cannot be combined with --contexts or --all-contexts")
+	}
+
+	available, err := util.ListKubeContexts(GetKubeconfig())
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		if len(available) == 0 {
+			return nil, fmt.Errorf("--all-contexts: the kubeconfig has no contexts")
+		}
+		return available, nil
+	}
+
+	byName := make(map[string]util.KubeContext, len(available))
+	for _, c := range available {
+		byName[c.Name] = c
+	}
+	var selected []util.KubeContext
+	seen := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("--contexts: no context %q in the kubeconfig", name)
+		}
+		seen[name] = true
+		selected = append(selected, c)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("--contexts: no context names given")
+	}
+	return selected, nil
+}
+
+// contextKubeOpts returns the client options for one selected context.
+func contextKubeOpts(c util.KubeContext) util.KubeOpts {
+	opts := GetKubeOpts()
+	opts.Context = c.Name
+	return opts
+}
+
+// contextClusterName names a context's cluster in reports, falling back to
+// the context name.
+func contextClusterName(c util.KubeContext) string {
+	if c.Cluster != "" {
+		return c.Cluster
+	}
+	return c.Name
+}
+
+// contextOutputFile derives a per-context file name from an output path,
+// e.g. report.html -> report-prod-eu.html.
+func contextOutputFile(path, context string) string {
+	if path == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	safe := strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(context)
+	return strings.TrimSuffix(path, ext) + "-" + safe + ext
+}
@@ -23,7 +23,11 @@ Examples:
     --enhance-technical --enhance-priority --enhance-remediation
 
   # Export to HTML report
-  kubenow default --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --output report.html`,
+  kubenow default --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --output report.html
+
+  # Several clusters, one section each plus a cross-cluster summary
+  kubenow default --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b \
+    --contexts staging,prod-eu,prod-us`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		defaultConfig.Mode = "default"
 		if err := RunLLMCommand(cmd, &defaultConfig); err != nil {
@@ -36,4 +40,5 @@ Examples:
 func init() {
 	rootCmd.AddCommand(defaultCmd)
 	addLLMFlags(defaultCmd, &defaultConfig)
+	addContextsFlags(defaultCmd, &defaultConfig.Contexts, &defaultConfig.AllContexts)
 }
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/export"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promonitor"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var eventsTimelineConfig struct {
+	window     string
+	output     string
+	exportFile string
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Work with Kubernetes events",
+}
+
+var eventsTimelineCmd = &cobra.Command{
+	Use:   "timeline [<kind>/<name>]",
+	Short: "Render an ordered timeline of events, restarts, and deploys",
+	Long: `Collect the events of a namespace, or of one workload and its
+ReplicaSets, pods, and HorizontalPodAutoscaler, over the window and order
+them into a timeline together with container restarts and rollouts. Events
+and restarts within an hour after a rollout of their workload name it, so
+the timeline shows what a deploy set off. No LLM is involved.
+
+Formats:
+  table    - the timeline in the terminal
+  json     - entries with time, kind, object, reason, count, and message
+  mermaid  - a Mermaid Gantt chart, for Markdown incident reviews
+  html     - a self-contained page with the table and the Mermaid source
+
+Events expire after the API server's event TTL (1h by default) and only
+the last termination of each live container is visible, so export the
+timeline soon after an incident.
+
+Examples:
+  # Everything in a namespace over the last 24 hours
+  kubenow events timeline -n shop
+
+  # One workload over the last 6 hours, as Mermaid
+  kubenow events timeline deployment/payment-api -n prod --window 6h --output mermaid
+
+  # HTML for an incident review
+  kubenow events timeline deployment/payment-api -n prod --output html -o incident-timeline.html`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEventsTimeline,
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.AddCommand(eventsTimelineCmd)
+	eventsTimelineCmd.Flags().StringVar(&eventsTimelineConfig.window, "window", "24h", "Lookback (e.g., 1h, 24h, 7d)")
+	eventsTimelineCmd.Flags().StringVar(&eventsTimelineConfig.output, "output", "table", "Output format: table|json|mermaid|html")
+	eventsTimelineCmd.Flags().StringVarP(&eventsTimelineConfig.exportFile, "export-file", "o", "", "write to file instead of stdout")
+}
+
+func runEventsTimeline(_ *cobra.Command, args []string) error {
+	switch eventsTimelineConfig.output {
+	case "table", "json", "mermaid", "html":
+	default:
+		return fmt.Errorf("invalid --output %q: must be table, json, mermaid, or html", eventsTimelineConfig.output)
+	}
+
+	window, err := metrics.ParseDuration(eventsTimelineConfig.window)
+	if err != nil {
+		return fmt.Errorf("invalid --window: %w", err)
+	}
+
+	cfg := analyzer.TimelineConfig{Namespace: GetNamespace(), Window: window}
+	if len(args) == 1 {
+		ref, err := promonitor.ParseWorkloadRef(args[0])
+		if err != nil {
+			return err
+		}
+		if cfg.Namespace == "" {
+			cfg.Namespace = "default"
+		}
+		cfg.Kind, cfg.Workload = ref.Kind, ref.Name
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	tl, err := analyzer.BuildEventTimeline(context.Background(), kubeClient, cfg)
+	if err != nil {
+		return fmt.Errorf("events timeline failed: %w", err)
+	}
+
+	switch eventsTimelineConfig.output {
+	case "json":
+		data, err := json.MarshalIndent(tl, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(eventsTimelineConfig.exportFile, string(data)+"\n")
+	case "mermaid":
+		return writeOutputOrStdout(eventsTimelineConfig.exportFile, export.RenderTimelineMermaid(tl))
+	case "html":
+		var buf bytes.Buffer
+		metadata := export.ExportMetadata{GeneratedAt: tl.GeneratedAt, KubenowVersion: version, Mode: "events-timeline"}
+		if err := export.ExportEventTimelineHTML(tl, &metadata, &buf); err != nil {
+			return err
+		}
+		return writeOutputOrStdout(eventsTimelineConfig.exportFile, buf.String())
+	default:
+		return writeOutputOrStdout(eventsTimelineConfig.exportFile, renderEventsTimelineTable(tl))
+	}
+}
+
+func renderEventsTimelineTable(tl *analyzer.EventTimeline) string {
+	var b strings.Builder
+	scope := tl.Namespace
+	switch {
+	case tl.Workload != "":
+		scope += "/" + tl.Workload
+	case scope == "":
+		scope = "all namespaces"
+	}
+	fmt.Fprintf(&b, "\n=== Events timeline: %s (last %s) ===\n\n", scope, tl.Window)
+	if len(tl.Entries) == 0 {
+		b.WriteString("No events, restarts, or deploys in the window.\n")
+		return b.String()
+	}
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Time", "Kind", "Object", "Reason", "Count", "Message", "After Deploy"})
+	for i := range tl.Entries {
+		e := &tl.Entries[i]
+		kind := e.Kind
+		if e.Type == "Warning" {
+			kind = "warning"
+		}
+		object := e.Object
+		if tl.Namespace == "" {
+			object = e.Namespace + "/" + object
+		}
+		count := ""
+		if e.Count > 1 {
+			count = strconv.Itoa(int(e.Count))
+		}
+		appendTableRowBestEffort(table, []string{
+			e.Time.Format(time.RFC3339), kind, object, e.Reason, count, e.Message, e.AfterDeploy,
+		})
+	}
+	renderTableBestEffort(table)
+
+	s := tl.Summary
+	fmt.Fprintf(&b, "\n%d deploy(s), %d event(s) (%d warning), %d restart(s)\n", s.Deploys, s.Events, s.Warnings, s.Restarts)
+	return b.String()
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var exposureCmd = &cobra.Command{
+	Use:   "exposure",
+	Short: "Traffic topology from service mesh metrics",
+	Long: `Inspect measured traffic between workloads using service mesh
+(Linkerd) proxy metrics from Prometheus.
+
+Available subcommands:
+  - graph: Export a workload's inbound/outbound traffic as DOT or Mermaid
+  - topology: Namespace-wide service graph with success-rate health
+
+Examples:
+  # Mermaid graph of a deployment's traffic edges
+  kubenow exposure graph deployment/payment-api -n prod --prometheus-url http://prometheus:9090
+
+  # Service graph for a whole namespace
+  kubenow exposure topology -n prod --prometheus-url http://prometheus:9090`,
+}
+
+func init() {
+	rootCmd.AddCommand(exposureCmd)
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/exposure"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promonitor"
+)
+
+var exposureGraphConfig struct {
+	prometheusURL string
+	format        string
+	output        string
+}
+
+var exposureGraphCmd = &cobra.Command{
+	Use:   "graph <kind>/<name>",
+	Short: "Export a workload's traffic edges as a DOT or Mermaid graph",
+	Long: `Query Linkerd or Istio proxy metrics (auto-detected) for a workload's
+inbound and outbound traffic and emit a dependency graph with RPS and
+success-rate labels, suitable for embedding in runbooks and architecture docs.
+
+Edges with success rate below 99% are drawn orange, below 95% red.
+
+Formats:
+  mermaid  - Mermaid flowchart (renders natively in GitHub/GitLab markdown)
+  dot      - Graphviz DOT (render with: dot -Tsvg graph.dot -o graph.svg)
+
+Examples:
+  # Mermaid graph to stdout
+  kubenow exposure graph deployment/payment-api -n prod --prometheus-url http://prometheus:9090
+
+  # Graphviz DOT to a file
+  kubenow exposure graph deployment/payment-api -n prod --prometheus-url http://prometheus:9090 \
+    --format dot -o payment-api.dot`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExposureGraph,
+}
+
+func init() {
+	exposureCmd.AddCommand(exposureGraphCmd)
+	exposureGraphCmd.Flags().StringVar(&exposureGraphConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint with Linkerd or Istio proxy metrics (required)")
+	exposureGraphCmd.Flags().StringVar(&exposureGraphConfig.format, "format", "mermaid", "graph format (mermaid, dot)")
+	exposureGraphCmd.Flags().StringVarP(&exposureGraphConfig.output, "output", "o", "", "write to file instead of stdout")
+	mustMarkFlagRequired(exposureGraphCmd, "prometheus-url")
+}
+
+func runExposureGraph(_ *cobra.Command, args []string) error {
+	ref, err := promonitor.ParseWorkloadRef(args[0])
+	if err != nil {
+		return err
+	}
+
+	format := exposure.GraphFormat(exposureGraphConfig.format)
+	if format != exposure.GraphFormatDOT && format != exposure.GraphFormatMermaid {
+		return fmt.Errorf("invalid --format %q: must be mermaid or dot", exposureGraphConfig.format)
+	}
+
+	ns := GetNamespace()
+	if ns == "" {
+		ns = "default"
+	}
+
+	collector, err := newTrafficCollector(exposureGraphConfig.prometheusURL)
+	if err != nil {
+		return err
+	}
+
+	tm, err := collector.CollectTrafficMap(context.Background(), ns, ref.Name)
+	if err != nil {
+		return fmt.Errorf("failed to collect traffic map: %w", err)
+	}
+
+	out, err := exposure.RenderGraph(exposure.BuildWorkloadGraph(ns, ref.Name, tm), format)
+	if err != nil {
+		return err
+	}
+
+	return writeOutputOrStdout(exposureGraphConfig.output, out)
+}
+
+// newTrafficCollector builds an exposure collector wired to Prometheus for
+// mesh traffic queries only (no Kubernetes client needed).
+func newTrafficCollector(prometheusURL string) (*exposure.ExposureCollector, error) {
+	promClient, err := metrics.NewPrometheusClient(metrics.Config{PrometheusURL: prometheusURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Prometheus client: %w", err)
+	}
+	collector := exposure.NewExposureCollector(nil, nil)
+	collector.SetPrometheusAPI(promClient.GetAPI())
+	return collector, nil
+}
+
+// writeOutputOrStdout writes content to path, or stdout when path is empty.
+func writeOutputOrStdout(path, content string) error {
+	if path == "" {
+		printOut(content)
+		return nil
+	}
+	if err := cleanup.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	slog.Info("Graph written", "path", path)
+	return nil
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/exposure"
+)
+
+var exposureTopologyConfig struct {
+	prometheusURL string
+	output        string
+	exportFile    string
+}
+
+var exposureTopologyCmd = &cobra.Command{
+	Use:   "topology",
+	Short: "Namespace-wide service graph from mesh metrics",
+	Long: `Query Linkerd proxy metrics for all workloads in a namespace and
+produce the full service graph: traffic between workloads in the namespace,
+traffic leaving it, and traffic entering it from other namespaces.
+
+Edges with success rate below 99% are flagged DEGRADED, below 95% CRITICAL —
+a quick mesh-health overview without opening Linkerd viz.
+
+Output formats:
+  table    - Edge list sorted by RPS with health status (default)
+  json     - Nodes and edges for automation
+  mermaid  - Mermaid flowchart
+  dot      - Graphviz DOT
+
+Examples:
+  # Service graph for a namespace
+  kubenow exposure topology -n prod --prometheus-url http://prometheus:9090
+
+  # Mermaid diagram for docs
+  kubenow exposure topology -n prod --prometheus-url http://prometheus:9090 --output mermaid -o prod.mmd`,
+	RunE: runExposureTopology,
+}
+
+func init() {
+	exposureCmd.AddCommand(exposureTopologyCmd)
+	exposureTopologyCmd.Flags().StringVar(&exposureTopologyConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint with Linkerd proxy metrics (required)")
+	exposureTopologyCmd.Flags().StringVar(&exposureTopologyConfig.output, "output", "table", "output format (table, json, mermaid, dot)")
+	exposureTopologyCmd.Flags().StringVarP(&exposureTopologyConfig.exportFile, "export-file", "o", "", "write to file instead of stdout")
+	mustMarkFlagRequired(exposureTopologyCmd, "prometheus-url")
+}
+
+// topologyJSON is the JSON output of exposure topology.
+type topologyJSON struct {
+	Namespace string             `json:"namespace"`
+	Window    string             `json:"window"`
+	Nodes     []string           `json:"nodes"`
+	Edges     []topologyEdgeJSON `json:"edges"`
+}
+
+type topologyEdgeJSON struct {
+	exposure.GraphEdge
+	Status string `json:"status"`
+}
+
+func runExposureTopology(_ *cobra.Command, _ []string) error {
+	ns := GetNamespace()
+	if ns == "" {
+		return fmt.Errorf("--namespace is required for topology")
+	}
+
+	format := exposureTopologyConfig.output
+	switch format {
+	case "table", "json", string(exposure.GraphFormatMermaid), string(exposure.GraphFormatDOT):
+	default:
+		return fmt.Errorf("invalid --output %q: must be table, json, mermaid, or dot", format)
+	}
+
+	collector, err := newTrafficCollector(exposureTopologyConfig.prometheusURL)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Querying mesh traffic...", "namespace", ns)
+	g, err := collector.CollectNamespaceTopology(context.Background(), ns)
+	if err != nil {
+		return fmt.Errorf("failed to collect topology: %w", err)
+	}
+
+	switch format {
+	case "json":
+		out := topologyJSON{Namespace: ns, Window: g.Window.String(), Nodes: g.Nodes}
+		out.Edges = make([]topologyEdgeJSON, 0, len(g.Edges))
+		for _, e := range g.Edges {
+			out.Edges = append(out.Edges, topologyEdgeJSON{GraphEdge: e, Status: edgeStatus(e)})
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(exposureTopologyConfig.exportFile, string(data)+"\n")
+	case "table":
+		return writeOutputOrStdout(exposureTopologyConfig.exportFile, renderTopologyTable(ns, g))
+	default:
+		out, err := exposure.RenderGraph(g, exposure.GraphFormat(format))
+		if err != nil {
+			return err
+		}
+		return writeOutputOrStdout(exposureTopologyConfig.exportFile, out)
+	}
+}
+
+// edgeStatus classifies an edge by success rate.
+func edgeStatus(e exposure.GraphEdge) string {
+	switch {
+	case e.SuccessRate < 0:
+		return "UNKNOWN"
+	case e.Critical():
+		return "CRITICAL"
+	case e.Degraded():
+		return "DEGRADED"
+	default:
+		return "OK"
+	}
+}
+
+func renderTopologyTable(ns string, g *exposure.TrafficGraph) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nService graph for namespace %s (%s window): %d workloads, %d edges\n\n",
+		ns, g.Window, len(g.Nodes), len(g.Edges))
+
+	if len(g.Edges) == 0 {
+		b.WriteString("No mesh traffic found. Is the namespace meshed (Linkerd) and scraped by Prometheus?\n")
+		return b.String()
+	}
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"From", "To", "RPS", "Success", "Status"})
+	unhealthy := 0
+	for _, e := range g.Edges {
+		success := "—"
+		if e.SuccessRate >= 0 {
+			success = fmt.Sprintf("%.2f%%", e.SuccessRate*100)
+		}
+		status := edgeStatus(e)
+		if status == "DEGRADED" || status == "CRITICAL" {
+			unhealthy++
+		}
+		appendTableRowBestEffort(table, []string{e.From, e.To, fmt.Sprintf("%.2f", e.RPS), success, status})
+	}
+	renderTableBestEffort(table)
+
+	if unhealthy > 0 {
+		fmt.Fprintf(&b, "\n%d edge(s) below 99%% success rate\n", unhealthy)
+	}
+	return b.String()
+}
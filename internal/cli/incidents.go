@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/notify"
+)
+
+// IncidentFlags configure PagerDuty and Opsgenie incidents for fatal
+// problems in watch mode and monitor.
+type IncidentFlags struct {
+	PagerDutyRoutingKey string
+	OpsgenieAPIKey      string
+	OpsgenieURL         string
+	NotifyConfig        string
+}
+
+func addIncidentFlags(cmd *cobra.Command, f *IncidentFlags) {
+	cmd.Flags().StringVar(&f.PagerDutyRoutingKey, "pagerduty-routing-key", "",
+		"Open and resolve PagerDuty incidents for fatal problems (Events API v2 integration key)")
+	cmd.Flags().StringVar(&f.OpsgenieAPIKey, "opsgenie-api-key", "", "Open and close Opsgenie alerts for fatal problems (API integration key)")
+	cmd.Flags().StringVar(&f.OpsgenieURL, "opsgenie-url", notify.DefaultOpsgenieURL, "Opsgenie API URL (EU: https://api.eu.opsgenie.com)")
+	cmd.Flags().StringVar(&f.NotifyConfig, "notify-config", "",
+		"Notifications YAML declaring incident services (PagerDuty, Opsgenie), with keys inline or from environment variables")
+}
+
+func (f *IncidentFlags) set() bool {
+	return f.PagerDutyRoutingKey != "" || f.OpsgenieAPIKey != "" || f.NotifyConfig != ""
+}
+
+// build returns the incident services of the flags and the notifications
+// config file, or nil when none is configured.
+func (f *IncidentFlags) build(clusterName string) (*notify.Incidents, error) {
+	if !f.set() {
+		return nil, nil
+	}
+	incidents := &notify.Incidents{Cluster: clusterName}
+	if f.PagerDutyRoutingKey != "" {
+		incidents.Services = append(incidents.Services, notify.IncidentService{Provider: notify.ProviderPagerDuty, Key: f.PagerDutyRoutingKey})
+	}
+	if f.OpsgenieAPIKey != "" {
+		incidents.Services = append(incidents.Services, notify.IncidentService{
+			Provider: notify.ProviderOpsgenie, Key: f.OpsgenieAPIKey, URL: f.OpsgenieURL,
+		})
+	}
+	for i := range incidents.Services {
+		if err := incidents.Services[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid incident flags: %w", err)
+		}
+	}
+	if f.NotifyConfig != "" {
+		file, err := notify.LoadNotificationsFile(f.NotifyConfig)
+		if err != nil {
+			return nil, err
+		}
+		incidents.Services = append(incidents.Services, file.Incidents...)
+	}
+	return incidents, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// latchExporter ships the samples of a headless latch to a Prometheus
+// remote_write endpoint and/or an OpenMetrics file, so production captures
+// can be retained in a central TSDB.
+type latchExporter struct {
+	recorder    *metrics.SampleRecorder
+	writer      *metrics.RemoteWriter
+	metricsFile string
+}
+
+// newLatchExporter returns nil when neither --remote-write-url nor
+// --openmetrics-file is set.
+func newLatchExporter(remoteWriteURL, metricsFile string, labelSpecs []string) (*latchExporter, error) {
+	if remoteWriteURL == "" && metricsFile == "" {
+		if len(labelSpecs) > 0 {
+			return nil, fmt.Errorf("--export-label requires --remote-write-url or --openmetrics-file")
+		}
+		return nil, nil
+	}
+	labels, err := parseExportLabels(labelSpecs)
+	if err != nil {
+		return nil, err
+	}
+	e := &latchExporter{recorder: metrics.NewSampleRecorder(labels), metricsFile: metricsFile}
+	if remoteWriteURL != "" {
+		if e.writer, err = metrics.NewRemoteWriter(remoteWriteURL); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// parseExportLabels parses repeated key=value --export-label values.
+func parseExportLabels(specs []string) (map[string]string, error) {
+	labels := make(map[string]string, len(specs))
+	reserved := map[string]bool{"__name__": true, "namespace": true, "workload": true, "pod": true, "container": true}
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid --export-label %q: expected key=value", spec)
+		}
+		if reserved[key] {
+			return nil, fmt.Errorf("invalid --export-label %q: %s is set by kubenow", spec, key)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+func (e *latchExporter) sampleRecorder() *metrics.SampleRecorder {
+	if e == nil {
+		return nil
+	}
+	return e.recorder
+}
+
+// start pushes samples to remote_write periodically until ctx is done.
+func (e *latchExporter) start(ctx context.Context, log *slog.Logger) {
+	if e == nil || e.writer == nil {
+		return
+	}
+	go e.writer.Run(ctx, e.recorder, metrics.DefaultRemoteWriteInterval, func(err error) {
+		log.Warn("Remote write failed, retrying", "error", err)
+	})
+}
+
+// finish pushes the remaining samples and writes the OpenMetrics file.
+func (e *latchExporter) finish(ctx context.Context, log *slog.Logger) error {
+	if e == nil {
+		return nil
+	}
+	series := e.recorder.Series()
+	if e.writer != nil {
+		sent, err := e.writer.Push(ctx, series)
+		if err != nil {
+			return fmt.Errorf("failed to push samples to remote_write: %w", err)
+		}
+		log.Info("Pushed samples to remote_write", "samples", sent, "series", len(series))
+	}
+	if e.metricsFile != "" {
+		f, err := cleanup.Create(e.metricsFile, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to create OpenMetrics file: %w", err)
+		}
+		if err = metrics.WriteOpenMetrics(f, series); err != nil {
+			f.Discard()
+			return fmt.Errorf("failed to write OpenMetrics file: %w", err)
+		}
+		if err = f.Commit(); err != nil {
+			return fmt.Errorf("failed to write OpenMetrics file: %w", err)
+		}
+		log.Info("Wrote OpenMetrics file", "series", len(series), "path", e.metricsFile)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
 	"time"
@@ -11,10 +12,17 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 
+	"github.com/ppiankov/kubenow/internal/ack"
+	"github.com/ppiankov/kubenow/internal/cleanup"
 	"github.com/ppiankov/kubenow/internal/export"
 	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/llmcache"
+	"github.com/ppiankov/kubenow/internal/notify"
+	"github.com/ppiankov/kubenow/internal/owners"
 	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/redact"
 	"github.com/ppiankov/kubenow/internal/result"
 	"github.com/ppiankov/kubenow/internal/snapshot"
 	"github.com/ppiankov/kubenow/internal/util"
@@ -26,7 +34,19 @@ type LLMCommandConfig struct {
 	// Mode for prompt template selection
 	Mode string
 
-	// Required flags
+	// ModeSelection is "auto" to pick the mode from deterministic triage, or
+	// "offline" to report the triage without calling an LLM (--mode)
+	ModeSelection string
+
+	// PolicyFile overrides the admin policy path for deterministic audits (compliance only)
+	PolicyFile string
+
+	// Snapshot persistence: save the collected snapshot, or replay a saved one offline
+	SaveSnapshot string
+	FromSnapshot string
+
+	// Required flags (--llm-endpoint only for the openai provider)
+	LLMProvider string
 	LLMEndpoint string
 	Model       string
 
@@ -38,6 +58,28 @@ type LLMCommandConfig struct {
 	TimeoutSeconds int
 	MaxConcurrent  int
 	OutputFile     string
+	Stream         bool
+
+	// MaxPromptTokens caps the prompt size; 0 derives it from the model's context window
+	MaxPromptTokens int
+	// MaxResponseTokens caps the completion; 0 uses the provider default
+	MaxResponseTokens int
+
+	// StrictJSON requests JSON mode and re-prompts up to JSONRetries times
+	// on an answer that is not valid JSON
+	StrictJSON  bool
+	JSONRetries int
+
+	// Answers are cached in CacheDir for CacheTTL, keyed by the snapshot and
+	// prompt options; NoCache always calls the LLM
+	NoCache  bool
+	CacheTTL time.Duration
+	CacheDir string
+
+	// PriceInput and PriceOutput override the model's list price, in USD per
+	// million prompt and completion tokens
+	PriceInput  float64
+	PriceOutput float64
 
 	// Filters
 	IncludePods       string
@@ -46,83 +88,245 @@ type LLMCommandConfig struct {
 	ExcludeNamespaces string
 	IncludeKeywords   string
 	ExcludeKeywords   string
+	IncludeStorage    bool
 	ProblemHint       string
 
+	// RedactProfile and RedactPatterns mask secrets and PII in the snapshot
+	// before it is sent to the LLM
+	RedactProfile  string
+	RedactPatterns []string
+
+	// AckFile lists known accepted problems to report apart from the analysis
+	AckFile string
+
+	// OwnersFile maps namespaces and label selectors to owning teams
+	OwnersFile string
+
 	// Enhancements
 	EnhanceTechnical   bool
 	EnhancePriority    bool
 	EnhanceRemediation bool
 
+	// PromptDir and PromptFile replace the embedded prompt templates
+	PromptDir  string
+	PromptFile string
+
 	// Watch mode
 	WatchInterval     string
+	WatchConfig       string
 	WatchIterations   int
 	WatchAlertNewOnly bool
+	WatchState        string
+	WatchStateless    bool
+
+	// Usage growth detectors (watch mode), percent per hour
+	WatchMemoryGrowth     float64
+	WatchCPUGrowth        float64
+	WatchGrowthIterations int
+
+	// Notifications (watch mode)
+	NotifyWebhooks     []string
+	NotifySlackChannel string
+	Incidents          IncidentFlags
+
+	// Findings endpoint (watch mode)
+	MetricsPort int
+
+	// Multi-cluster: analyze each kubeconfig context (--contexts, --all-contexts)
+	Contexts    string
+	AllContexts bool
 }
 
 // RunLLMCommand executes an LLM analysis command
 func RunLLMCommand(_ *cobra.Command, config *LLMCommandConfig) error {
-	// Validate required fields
-	if config.LLMEndpoint == "" || config.Model == "" {
-		return fmt.Errorf("--llm-endpoint and --model are required")
+	// --mode offline never calls an LLM; --save-snapshot without an LLM only
+	// collects (restricted environments)
+	offline := config.ModeSelection == modeOffline
+	collectOnly := !offline && config.SaveSnapshot != "" && config.LLMEndpoint == "" && config.Model == ""
+
+	if err := validateLLMClientFlags(config, !collectOnly && !offline); err != nil {
+		return err
 	}
 
-	if config.Format != "human" && config.Format != "json" {
-		return fmt.Errorf("--format must be 'human' or 'json'")
+	if config.FromSnapshot != "" && config.SaveSnapshot != "" {
+		return fmt.Errorf("--from-snapshot and --save-snapshot are mutually exclusive")
 	}
 
-	// Build Kubernetes client
-	if IsVerbose() {
-		stderrln("[kubenow] Building Kubernetes client...")
+	watching := config.WatchInterval != "" || config.WatchConfig != ""
+	if watching && (config.FromSnapshot != "" || config.SaveSnapshot != "") {
+		return fmt.Errorf("--from-snapshot and --save-snapshot cannot be used with --watch-interval or --watch-config")
 	}
 
-	clientset, err := util.BuildKubeClientWithOpts(GetKubeOpts())
-	if err != nil {
-		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	if !watching && (len(config.NotifyWebhooks) > 0 || config.NotifySlackChannel != "") {
+		return fmt.Errorf("--notify-webhook and --notify-slack-channel require --watch-interval or --watch-config")
+	}
+	if !watching && config.Incidents.set() {
+		return fmt.Errorf("--pagerduty-routing-key, --opsgenie-api-key, and --notify-config require --watch-interval or --watch-config")
+	}
+	if !watching && config.MetricsPort > 0 {
+		return fmt.Errorf("--metrics-port requires --watch-interval or --watch-config")
 	}
 
-	// Extract cluster name
-	clusterName := extractClusterName(GetKubeconfig())
+	if config.ModeSelection != "" && config.ModeSelection != prompt.ModeAuto && !offline {
+		return fmt.Errorf("--mode must be 'auto' or 'offline' (or omitted)")
+	}
+	if offline && watching {
+		return fmt.Errorf("--mode offline cannot be used with --watch-interval or --watch-config")
+	}
 
-	// Setup filters
-	filters := snapshot.Filters{
-		IncludePods:       config.IncludePods,
-		ExcludePods:       config.ExcludePods,
-		IncludeNamespaces: config.IncludeNamespaces,
-		ExcludeNamespaces: config.ExcludeNamespaces,
-		IncludeKeywords:   config.IncludeKeywords,
-		ExcludeKeywords:   config.ExcludeKeywords,
+	if _, err := newRedactor(config); err != nil {
+		return err
+	}
+
+	contexts, err := resolveContexts(config.Contexts, config.AllContexts)
+	if err != nil {
+		return err
+	}
+	if contexts != nil && (watching || config.FromSnapshot != "" || config.SaveSnapshot != "") {
+		return fmt.Errorf("--contexts and --all-contexts cannot be combined with watch mode or snapshots")
+	}
+	if contexts != nil && offline {
+		return fmt.Errorf("--mode offline analyzes one cluster; drop --contexts/--all-contexts")
 	}
 
+	// Setup filters
+	filters := snapshotFilters(config)
+
 	// Setup enhancements
-	enhancements := prompt.PromptEnhancements{
-		Technical:   config.EnhanceTechnical,
-		Priority:    config.EnhancePriority,
-		Remediation: config.EnhanceRemediation,
+	enhancements, err := promptEnhancements(config)
+	if err != nil {
+		return err
 	}
 
 	// Setup LLM client
-	timeout := time.Duration(config.TimeoutSeconds) * time.Second
-	llmClient := llm.Client{
-		Endpoint: config.LLMEndpoint,
-		Model:    config.Model,
-		APIKey:   config.APIKey,
-		Timeout:  timeout,
+	llmClient := newLLMClient(config)
+
+	// Offline mode: replay a saved snapshot without cluster access
+	if config.FromSnapshot != "" {
+		saved, err := snapshot.Load(config.FromSnapshot)
+		if err != nil {
+			return err
+		}
+		clusterName := saved.Cluster
+		if clusterName == "" {
+			clusterName = "unknown"
+		}
+		slog.Info("Analyzing saved snapshot", "path", config.FromSnapshot, "cluster", clusterName,
+			"collected", saved.Snapshot.GeneratedAt.Format(time.RFC3339))
+		return analyzeSnapshot(nil, &llmClient, config, &filters, enhancements, clusterName, saved.Snapshot)
 	}
 
+	if contexts != nil {
+		return runLLMContexts(contexts, &llmClient, config, &filters, enhancements)
+	}
+
+	// Build Kubernetes client
+	slog.Debug("Building Kubernetes client...")
+
+	clientset, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	// Extract cluster name
+	clusterName := extractClusterName(GetKubeconfig())
+
 	// Check if watch mode is enabled
-	if config.WatchInterval != "" {
-		return runWatchMode(clientset, &llmClient, config, &filters, enhancements)
+	if watching {
+		return runWatchMode(clientset, &llmClient, config, &filters, enhancements, clusterName)
 	}
 
 	// Single execution mode
-	return runSingleExecution(clientset, &llmClient, config, &filters, enhancements, clusterName)
+	return runSingleExecution(clientset, &llmClient, config, &filters, enhancements, clusterName, collectOnly)
+}
+
+// validateLLMClientFlags checks the LLM connection, answer, and output
+// flags, defaulting --llm-endpoint to the provider's public API. requireLLM
+// is false for runs that call no LLM.
+func validateLLMClientFlags(config *LLMCommandConfig, requireLLM bool) error {
+	if err := llm.ValidateProvider(config.LLMProvider); err != nil {
+		return fmt.Errorf("invalid --llm-provider: %w", err)
+	}
+	if requireLLM {
+		if config.LLMEndpoint == "" {
+			config.LLMEndpoint = llm.DefaultEndpoint(config.LLMProvider)
+		}
+		if config.LLMEndpoint == "" || config.Model == "" {
+			return fmt.Errorf("--llm-endpoint and --model are required")
+		}
+	}
+	if config.MaxResponseTokens < 0 {
+		return fmt.Errorf("--max-response-tokens must not be negative")
+	}
+	if config.JSONRetries < 0 {
+		return fmt.Errorf("--llm-json-retries must not be negative")
+	}
+	if config.PriceInput < 0 || config.PriceOutput < 0 {
+		return fmt.Errorf("--llm-price-input and --llm-price-output must not be negative")
+	}
+	if config.CacheTTL <= 0 && !config.NoCache {
+		return fmt.Errorf("--cache-ttl must be positive (use --no-cache to disable the LLM cache)")
+	}
+	if config.Format != "human" && config.Format != "json" {
+		return fmt.Errorf("--format must be 'human' or 'json'")
+	}
+	return nil
+}
+
+// newLLMClient builds the LLM client of the connection flags.
+func newLLMClient(config *LLMCommandConfig) llm.Client {
+	return llm.Client{
+		Provider:  config.LLMProvider,
+		Endpoint:  config.LLMEndpoint,
+		Model:     config.Model,
+		APIKey:    config.APIKey,
+		MaxTokens: config.MaxResponseTokens,
+		Timeout:   time.Duration(config.TimeoutSeconds) * time.Second,
+
+		StrictJSON:  config.StrictJSON,
+		JSONRetries: config.JSONRetries,
+		OnJSONRetry: func(attempt int, parseErr error) {
+			slog.Info("LLM answer is not valid JSON; re-prompting", "attempt", attempt, "retries", config.JSONRetries, "error", parseErr)
+		},
+	}
 }
 
 // runWatchMode executes the LLM command in watch mode
-func runWatchMode(clientset *kubernetes.Clientset, llmClient *llm.Client, config *LLMCommandConfig, filters *snapshot.Filters, enhancements prompt.PromptEnhancements) error {
-	interval, err := time.ParseDuration(config.WatchInterval)
+func runWatchMode(
+	clientset *kubernetes.Clientset, llmClient *llm.Client, config *LLMCommandConfig,
+	filters *snapshot.Filters, enhancements prompt.PromptEnhancements, clusterName string,
+) error {
+	acks, err := loadAcknowledgements(config.AckFile)
+	if err != nil {
+		return err
+	}
+	teamOwners, err := loadOwners(config.OwnersFile)
+	if err != nil {
+		return err
+	}
+	redactor, err := newRedactor(config)
 	if err != nil {
-		return fmt.Errorf("invalid watch-interval: %w", err)
+		return err
+	}
+
+	var interval time.Duration
+	if config.WatchInterval != "" {
+		if interval, err = time.ParseDuration(config.WatchInterval); err != nil {
+			return fmt.Errorf("invalid watch-interval: %w", err)
+		}
+	}
+
+	var schedules []watch.Schedule
+	var sinks []notify.Sink
+	if config.WatchConfig != "" {
+		file, loadErr := watch.LoadWatchConfig(config.WatchConfig)
+		if loadErr != nil {
+			return loadErr
+		}
+		schedules, sinks = file.Schedules, file.Sinks
+	}
+	if len(schedules) == 0 && interval <= 0 {
+		return fmt.Errorf("--watch-config defines no schedules: set --watch-interval")
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -131,14 +335,12 @@ func runWatchMode(clientset *kubernetes.Clientset, llmClient *llm.Client, config
 	// Setup signal handling
 	setupSignalHandler(cancel)
 
-	if IsVerbose() {
-		stderrf("[kubenow] Starting watch mode (interval: %s)\n", interval)
-		if config.WatchIterations > 0 {
-			stderrf("[kubenow] Max iterations: %d\n", config.WatchIterations)
-		}
-		if config.WatchAlertNewOnly {
-			stderrln("[kubenow] Alert mode: New issues only")
-		}
+	if len(schedules) > 0 {
+		slog.Debug("Starting watch mode", "schedules", len(schedules), "config", config.WatchConfig,
+			"max_iterations", config.WatchIterations, "new_issues_only", config.WatchAlertNewOnly)
+	} else {
+		slog.Debug("Starting watch mode", "interval", interval,
+			"max_iterations", config.WatchIterations, "new_issues_only", config.WatchAlertNewOnly)
 	}
 
 	watchConfig := watch.Config{
@@ -151,85 +353,524 @@ func runWatchMode(clientset *kubernetes.Clientset, llmClient *llm.Client, config
 		MaxConcurrent: config.MaxConcurrent,
 		Filters:       *filters,
 		Mode:          config.Mode,
+		AutoMode:      config.ModeSelection == prompt.ModeAuto,
 		ProblemHint:   config.ProblemHint,
 		Enhancements:  enhancements,
 		LLMClient:     llmClient,
+		Acks:          acks,
+		Owners:        teamOwners,
+		Redactor:      redactor,
+
+		MaxPromptTokens: config.MaxPromptTokens,
+	}
+
+	if watchConfig.Notifier, err = buildNotifier(config, sinks); err != nil {
+		return err
+	}
+	if watchConfig.Incidents, err = config.Incidents.build(clusterName); err != nil {
+		return err
+	}
+	if err := configureWatchState(&watchConfig, config, clusterName); err != nil {
+		return err
+	}
+	if watchConfig.Cache, err = newLLMCache(config); err != nil {
+		return err
+	}
+	watchConfig.LLMUsage = llm.NewMeter(config.Model, llmPrice(config))
+	if err := configureWatchGrowth(&watchConfig, config, clientset); err != nil {
+		return err
+	}
+	if config.MetricsPort > 0 {
+		watchConfig.Findings = startMetricsServer(ctx, config.MetricsPort).Findings()
 	}
 
-	if err := watch.Run(ctx, clientset, &watchConfig); err != nil && err != context.Canceled {
+	if len(schedules) > 0 {
+		err = watch.RunSchedules(ctx, clientset, &watchConfig, schedules)
+	} else {
+		err = watch.Run(ctx, clientset, &watchConfig)
+	}
+	if err != nil && err != context.Canceled {
 		return fmt.Errorf("watch error: %w", err)
 	}
 
 	return nil
 }
 
-// runSingleExecution executes the LLM command once
-func runSingleExecution(clientset *kubernetes.Clientset, llmClient *llm.Client, config *LLMCommandConfig, filters *snapshot.Filters, enhancements prompt.PromptEnhancements, clusterName string) error {
-	if IsVerbose() {
-		stderrln("[kubenow] Collecting cluster snapshot...")
+// buildNotifier returns the webhook notifier for --notify-webhook and the
+// watch config's sinks, or nil when neither is set.
+func buildNotifier(config *LLMCommandConfig, sinks []notify.Sink) (*notify.Notifier, error) {
+	if len(config.NotifyWebhooks) == 0 && config.NotifySlackChannel != "" {
+		return nil, fmt.Errorf("--notify-slack-channel requires a Slack --notify-webhook")
+	}
+	if len(config.NotifyWebhooks) == 0 && len(sinks) == 0 {
+		return nil, nil
+	}
+	notifier := &notify.Notifier{}
+	for _, spec := range config.NotifyWebhooks {
+		target, err := notify.ParseTarget(spec, config.NotifySlackChannel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --notify-webhook: %w", err)
+		}
+		notifier.Targets = append(notifier.Targets, target)
+	}
+	for i := range sinks {
+		target, err := sinks[i].Target()
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink %d in --watch-config: %w", i+1, err)
+		}
+		notifier.Targets = append(notifier.Targets, target)
+	}
+	return notifier, nil
+}
+
+// configureWatchState points watch mode at its state database, scoped to
+// the cluster and namespace, unless --watch-stateless is set.
+func configureWatchState(watchConfig *watch.Config, config *LLMCommandConfig, clusterName string) error {
+	if config.WatchStateless {
+		return nil
+	}
+	statePath := config.WatchState
+	if statePath == "" {
+		var err error
+		if statePath, err = watch.DefaultStatePath(); err != nil {
+			return err
+		}
+	}
+	scope := GetNamespace()
+	if scope == "" {
+		scope = "*"
+	}
+	watchConfig.StatePath = statePath
+	watchConfig.StateScope = clusterName + "/" + scope
+	return nil
+}
+
+// configureWatchGrowth enables the usage growth detectors, which read the
+// Metrics API, when --watch-memory-growth or --watch-cpu-growth is set.
+func configureWatchGrowth(watchConfig *watch.Config, config *LLMCommandConfig, clientset *kubernetes.Clientset) error {
+	if config.WatchMemoryGrowth < 0 || config.WatchCPUGrowth < 0 || config.WatchGrowthIterations < 0 {
+		return fmt.Errorf("--watch-memory-growth, --watch-cpu-growth and --watch-growth-iterations must not be negative")
+	}
+	watchConfig.Growth = watch.GrowthConfig{
+		MemoryRate: config.WatchMemoryGrowth,
+		CPURate:    config.WatchCPUGrowth,
+		Iterations: config.WatchGrowthIterations,
+	}
+	if !watchConfig.Growth.Enabled() {
+		return nil
+	}
+	restConfig, err := util.BuildRestConfigWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build REST config: %w", err)
+	}
+	metricsClient, err := metricsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build metrics client: %w", err)
 	}
+	watchConfig.Usage = &watch.MetricsUsageSource{Kube: clientset, Metrics: metricsClient}
+	return nil
+}
+
+// runSingleExecution executes the LLM command once.
+// With collectOnly, the snapshot is saved and no LLM call is made.
+func runSingleExecution(
+	clientset *kubernetes.Clientset, llmClient *llm.Client, config *LLMCommandConfig,
+	filters *snapshot.Filters, enhancements prompt.PromptEnhancements, clusterName string, collectOnly bool,
+) error {
+	slog.Debug("Collecting cluster snapshot...")
 
 	snap, err := snapshot.BuildSnapshot(context.Background(), clientset, GetNamespace(), config.MaxPods, config.LogLines, config.MaxConcurrent, filters)
 	if err != nil {
 		return fmt.Errorf("snapshot error: %w", err)
 	}
 
-	snapJSON, err := json.Marshal(snap)
-	if err != nil {
-		return fmt.Errorf("snapshot marshal error: %w", err)
+	if config.SaveSnapshot != "" {
+		// Teams are resolved from pod labels, which saved snapshots do not keep
+		teamOwners, err := loadOwners(config.OwnersFile)
+		if err != nil {
+			return err
+		}
+		snap.AssignOwners(teamOwners)
+		if err := snapshot.Save(config.SaveSnapshot, snap, clusterName, version); err != nil {
+			return err
+		}
+		slog.Info("Snapshot saved", "path", config.SaveSnapshot, "problem_pods", len(snap.ProblemPods), "nodes", len(snap.NodeConditions))
+		if collectOnly {
+			return nil
+		}
 	}
 
-	// Load prompt with enhancements
-	finalPrompt, err := prompt.LoadPrompt(config.Mode, string(snapJSON), config.ProblemHint, enhancements)
+	return analyzeSnapshot(clientset, llmClient, config, filters, enhancements, clusterName, snap)
+}
+
+// analyzeSnapshot runs the LLM analysis on a live or replayed snapshot, or
+// only the deterministic triage with --mode offline. clientset is nil for
+// replayed snapshots; cluster-backed audits are skipped then.
+func analyzeSnapshot(
+	clientset *kubernetes.Clientset, llmClient *llm.Client, config *LLMCommandConfig,
+	filters *snapshot.Filters, enhancements prompt.PromptEnhancements, clusterName string, snap *snapshot.Snapshot,
+) error {
+	if config.ModeSelection == modeOffline {
+		return runOfflineTriage(config, clusterName, snap)
+	}
+	a, err := runLLMAnalysis(clientset, llmClient, config, filters, enhancements, clusterName, snap)
 	if err != nil {
-		return fmt.Errorf("prompt error: %w", err)
+		return err
+	}
+	err = handleOutput(a.raw, config.Format, config.OutputFile, &a.meta, a.policyIssues)
+	if err != nil {
+		return err
+	}
+	if config.Format == "human" && config.OutputFile == "" {
+		printAcknowledged(a.meta.Acknowledged)
+		printOwners(a.meta.Owners)
 	}
+	return nil
+}
 
-	if IsVerbose() {
-		stderrf("[kubenow] Calling LLM endpoint: %s\n", config.LLMEndpoint)
+// llmAnalysis is a completed LLM analysis of one snapshot, ready for output.
+type llmAnalysis struct {
+	raw          string
+	meta         export.ExportMetadata
+	policyIssues []result.ComplianceIssue
+}
+
+// runLLMAnalysis builds the prompt, calls the LLM, and runs the
+// deterministic audits of the mode.
+func runLLMAnalysis(
+	clientset *kubernetes.Clientset, llmClient *llm.Client, config *LLMCommandConfig,
+	filters *snapshot.Filters, enhancements prompt.PromptEnhancements, clusterName string, snap *snapshot.Snapshot,
+) (*llmAnalysis, error) {
+	p, err := buildLLMPrompt(config, enhancements, snap)
+	if err != nil {
+		return nil, err
 	}
+	mode := p.mode
+
+	slog.Debug("Calling LLM endpoint", "endpoint", config.LLMEndpoint, "provider", config.LLMProvider)
 
 	ctx, cancel := context.WithTimeout(context.Background(), llmClient.Timeout)
 	defer cancel()
 
-	raw, err := llmClient.Complete(ctx, finalPrompt)
+	meter := llm.NewMeter(config.Model, llmPrice(config))
+	metered := *llmClient
+	metered.OnUsage = meter.Record
+	raw, err := completeCached(ctx, &metered, config, enhancements, p, snap)
 	if err != nil {
-		return fmt.Errorf("llm error: %w", err)
+		return nil, fmt.Errorf("llm error: %w", err)
+	}
+	cost := meter.Cost()
+	if cost != nil {
+		slog.Info("LLM usage", "usage", cost.String())
+	}
+
+	var policyIssues []result.ComplianceIssue
+	if mode == "compliance" && clientset == nil && config.PolicyFile != "" {
+		slog.Warn("Ratio policy audit needs cluster access, skipped for saved snapshot")
+	}
+	if mode == "compliance" && clientset != nil {
+		policyIssues, err = auditRatioPolicy(clientset, config.PolicyFile)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Handle output
-	return handleOutput(raw, config.Mode, config.Format, config.OutputFile, clusterName, filters)
+	return &llmAnalysis{
+		raw: raw,
+		meta: export.ExportMetadata{
+			ClusterName:  clusterName,
+			Mode:         mode,
+			AutoMode:     p.modeReason != "",
+			ModeReason:   p.modeReason,
+			Filters:      *filters,
+			Truncation:   p.truncation,
+			Redaction:    p.redaction,
+			LLMCost:      cost,
+			Acknowledged: snap.AcknowledgedProblems,
+			Owners:       p.assignments,
+		},
+		policyIssues: policyIssues,
+	}, nil
 }
 
-// handleOutput processes the LLM output and writes to stdout or file
-func handleOutput(raw, mode, format, outputFile, clusterName string, filters *snapshot.Filters) error {
-	// Strict JSON mode: keep old behavior for stdout
-	if format == "json" && outputFile == "" {
-		jsonStr, jerr := extractJSON(raw)
-		if jerr != nil {
-			return fmt.Errorf("json parse error: %w\nRaw output:\n%s", jerr, raw)
+// llmPrompt is the prompt built for one snapshot and what shaped it.
+type llmPrompt struct {
+	text        string
+	mode        string
+	modeReason  string // set when --mode auto picked the mode
+	truncation  *prompt.Truncation
+	redaction   *redact.Summary
+	assignments []owners.Assignment
+}
+
+// buildLLMPrompt sets acknowledged problems aside, assigns owners, picks the
+// mode, redacts the snapshot, and fits the prompt to the model's budget.
+func buildLLMPrompt(config *LLMCommandConfig, enhancements prompt.PromptEnhancements, snap *snapshot.Snapshot) (*llmPrompt, error) {
+	acks, err := loadAcknowledgements(config.AckFile)
+	if err != nil {
+		return nil, err
+	}
+	if n := snap.SplitAcknowledged(acks, time.Now()); n > 0 {
+		slog.Info("Acknowledged problem pods left out of the analysis and listed separately", "pods", n)
+	}
+	teamOwners, err := loadOwners(config.OwnersFile)
+	if err != nil {
+		return nil, err
+	}
+	snap.AssignOwners(teamOwners)
+	var assignments []owners.Assignment
+	if teamOwners != nil {
+		assignments = snap.OwnerAssignments(teamOwners)
+	}
+
+	mode, modeReason := config.Mode, ""
+	if config.ModeSelection == prompt.ModeAuto {
+		mode, modeReason = prompt.SelectMode(snapshot.Triage(snap), config.Mode)
+		slog.Info("Auto-selected mode", "mode", mode, "reason", modeReason)
+	}
+
+	redactor, err := newRedactor(config)
+	if err != nil {
+		return nil, err
+	}
+	redaction := snap.Redact(redactor)
+	reportRedaction(redaction)
+
+	// Load prompt with enhancements, trimming the snapshot to the model's budget
+	budget := prompt.PromptBudget(config.Model, config.MaxPromptTokens)
+	finalPrompt, truncation, err := prompt.FitPrompt(snap, budget, mode, config.ProblemHint, enhancements)
+	if err != nil {
+		return nil, fmt.Errorf("prompt error: %w", err)
+	}
+	reportTruncation(truncation)
+
+	return &llmPrompt{
+		text:        finalPrompt,
+		mode:        mode,
+		modeReason:  modeReason,
+		truncation:  truncation,
+		redaction:   redaction,
+		assignments: assignments,
+	}, nil
+}
+
+// snapshotFilters collects the snapshot filter flags.
+func snapshotFilters(config *LLMCommandConfig) snapshot.Filters {
+	return snapshot.Filters{
+		IncludePods:       config.IncludePods,
+		ExcludePods:       config.ExcludePods,
+		IncludeNamespaces: config.IncludeNamespaces,
+		ExcludeNamespaces: config.ExcludeNamespaces,
+		IncludeKeywords:   config.IncludeKeywords,
+		ExcludeKeywords:   config.ExcludeKeywords,
+		IncludeStorage:    config.IncludeStorage,
+		IncludeSecurity:   config.Mode == "security",
+	}
+}
+
+// promptEnhancements collects the --enhance-* flags and the templates of
+// --prompt-dir and --prompt-file.
+func promptEnhancements(config *LLMCommandConfig) (prompt.PromptEnhancements, error) {
+	templates, err := prompt.LoadTemplates(config.PromptDir, config.PromptFile)
+	if err != nil {
+		return prompt.PromptEnhancements{}, err
+	}
+	return prompt.PromptEnhancements{
+		Technical:   config.EnhanceTechnical,
+		Priority:    config.EnhancePriority,
+		Remediation: config.EnhanceRemediation,
+		Templates:   templates,
+	}, nil
+}
+
+// loadAcknowledgements loads --ack-file; an empty path means none.
+func loadAcknowledgements(path string) (*ack.List, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return ack.Load(path)
+}
+
+// printAcknowledged lists known accepted problems after the human report.
+func printAcknowledged(problems []ack.Problem) {
+	if len(problems) == 0 {
+		return
+	}
+	printfOut("\nKNOWN ACCEPTED PROBLEMS (%d, not analyzed)\n", len(problems))
+	for i := range problems {
+		p := &problems[i]
+		printfOut("  %s  %s — %s\n", p.Location(), p.Type, p.Note())
+	}
+}
+
+// printOwners lists the problem pods per owning team after the human report.
+func printOwners(assignments []owners.Assignment) {
+	if len(assignments) == 0 {
+		return
+	}
+	printlnOut("\nOWNERS")
+	for i := range assignments {
+		a := &assignments[i]
+		team := a.Team
+		if a.Contact != "" {
+			team += " (" + a.Contact + ")"
 		}
+		printfOut("  %s: %s\n", team, strings.Join(a.Pods, ", "))
+	}
+}
+
+// reportTruncation tells the user what was cut from the snapshot to fit the
+// prompt budget.
+func reportTruncation(t *prompt.Truncation) {
+	if t == nil {
+		return
+	}
+	slog.Info("Snapshot trimmed to fit the prompt budget", "trimmed", t.Summary())
+	if t.OverBudget {
+		slog.Warn("Prompt still exceeds the budget; raise --max-prompt-tokens or narrow the snapshot with filters")
+	}
+}
+
+// newRedactor builds the snapshot redactor from --redact-profile and
+// --redact-pattern; nil means nothing is redacted.
+func newRedactor(config *LLMCommandConfig) (*redact.Redactor, error) {
+	profile, err := redact.ParseProfile(config.RedactProfile)
+	if err != nil {
+		return nil, err
+	}
+	return redact.New(profile, config.RedactPatterns)
+}
+
+// reportRedaction tells how many values were masked before the LLM call.
+func reportRedaction(s *redact.Summary) {
+	if s == nil || s.Redactions == 0 {
+		return
+	}
+	slog.Info("Redacted values before sending the snapshot", "redacted", s.String(), "profile", string(s.Profile))
+}
+
+// completeLLM calls the LLM. With --stream, in human format without
+// --output, the completion is streamed and its raw tokens are echoed to
+// stderr as they arrive; the full response is still parsed and rendered
+// afterwards. JSON output and file
+// export stay buffered.
+func completeLLM(ctx context.Context, llmClient *llm.Client, finalPrompt string, config *LLMCommandConfig) (string, error) {
+	if !config.Stream || config.Format != "human" || config.OutputFile != "" {
+		return llmClient.Complete(ctx, finalPrompt)
+	}
+
+	slog.Info("LLM response (streaming):")
+	raw, err := llmClient.Stream(ctx, finalPrompt, func(token string) {
+		stderrf("%s", token)
+	})
+	stderrln()
+	return raw, err
+}
 
-		var tmp any
-		if err := json.Unmarshal([]byte(jsonStr), &tmp); err != nil {
-			return fmt.Errorf("json unmarshal error: %w\nRaw JSON:\n%s", err, jsonStr)
+// completeCached returns the cached answer to the prompt when the snapshot
+// and prompt options are unchanged, and otherwise calls the LLM and caches a
+// valid JSON answer.
+func completeCached(
+	ctx context.Context, llmClient *llm.Client, config *LLMCommandConfig,
+	enhancements prompt.PromptEnhancements, p *llmPrompt, snap *snapshot.Snapshot,
+) (string, error) {
+	cache, err := newLLMCache(config)
+	if err != nil {
+		return "", err
+	}
+	if cache == nil {
+		return completeLLM(ctx, llmClient, p.text, config)
+	}
+	budget := prompt.PromptBudget(config.Model, config.MaxPromptTokens)
+	key, err := llmcache.Key(snap, llmcache.NewOptions(llmClient, p.mode, config.ProblemHint, enhancements, budget))
+	if err != nil {
+		return "", err
+	}
+	return completeWithCache(ctx, llmClient, config, cache, key, p.mode, p.text)
+}
+
+// completeWithCache returns the answer cached under key, or calls the LLM
+// and caches a valid JSON answer.
+func completeWithCache(
+	ctx context.Context, llmClient *llm.Client, config *LLMCommandConfig,
+	cache *llmcache.Cache, key, mode, finalPrompt string,
+) (string, error) {
+	raw, hit, err := cache.Complete(key, config.Model, mode, func() (string, error) {
+		return completeLLM(ctx, llmClient, finalPrompt, config)
+	})
+	if hit != nil {
+		slog.Info("Using cached LLM answer: prompt input and options unchanged (--no-cache to call the LLM)",
+			"cached", hit.CreatedAt.Local().Format(time.RFC3339))
+	}
+	return raw, err
+}
+
+// llmPrice returns the price set with --llm-price-input and
+// --llm-price-output, or nil to use the model's list price.
+func llmPrice(config *LLMCommandConfig) *llm.Price {
+	if config.PriceInput == 0 && config.PriceOutput == 0 {
+		return nil
+	}
+	return &llm.Price{Input: config.PriceInput, Output: config.PriceOutput}
+}
+
+// newLLMCache opens the answer cache of --cache-dir (default
+// ~/.kubenow/cache/llm); nil with --no-cache.
+func newLLMCache(config *LLMCommandConfig) (*llmcache.Cache, error) {
+	if config.NoCache {
+		return nil, nil
+	}
+	dir := config.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = llmcache.DefaultDir(); err != nil {
+			return nil, err
 		}
+	}
+	return llmcache.New(dir, config.CacheTTL), nil
+}
 
-		out, err := result.PrettyJSON(tmp)
+// auditRatioPolicy runs the limit/request ratio audit when the admin policy
+// mandates one. Returns nil when no ratio policy is configured.
+func auditRatioPolicy(clientset *kubernetes.Clientset, policyFile string) ([]result.ComplianceIssue, error) {
+	ratios, err := loadRatioPolicy(policyFile)
+	if err != nil {
+		return nil, err
+	}
+	if !ratios.Enabled() {
+		return nil, nil
+	}
+
+	issues, err := ratioComplianceIssues(context.Background(), clientset, GetNamespace(), ratios)
+	if err != nil {
+		return nil, fmt.Errorf("ratio audit failed: %w", err)
+	}
+	slog.Debug("Ratio policy audit", "violations", len(issues))
+	return issues, nil
+}
+
+// handleOutput processes the LLM output and writes to stdout or file. meta
+// carries the mode and what file exports record about the run. policyIssues
+// are deterministic compliance findings appended to the LLM's compliance issues.
+func handleOutput(raw, format, outputFile string, meta *export.ExportMetadata, policyIssues []result.ComplianceIssue) error {
+	mode := meta.Mode
+
+	// Strict JSON mode: keep old behavior for stdout
+	if format == "json" && outputFile == "" {
+		out, err := strictJSON(raw, policyIssues)
 		if err != nil {
-			printlnOut(jsonStr)
-			return nil
+			return err
 		}
 		printOut(out)
 		return nil
 	}
 
 	// Extract and parse JSON
-	jsonStr, jerr := extractJSON(raw)
+	jsonStr, jerr := llm.ExtractJSON(raw)
 	if jerr != nil {
 		// No JSON at all: just show raw model answer
 		if outputFile == "" {
-			stderrln("[kubenow] No JSON detected in LLM output, showing raw response")
+			slog.Warn("No JSON detected in LLM output, showing raw response")
 			printlnOut(raw)
 			return nil
 		}
@@ -241,117 +882,174 @@ func handleOutput(raw, mode, format, outputFile, clusterName string, filters *sn
 		var pr result.PodResult
 		if err := json.Unmarshal([]byte(jsonStr), &pr); err != nil {
 			if outputFile == "" {
-				stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 				printlnOut(raw)
 				return nil
 			}
 			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
 		}
 		if outputFile != "" {
-			return exportToFile(&pr, mode, outputFile, clusterName, filters)
+			return exportToFile(&pr, outputFile, meta)
 		}
 		return result.RenderPodHuman(os.Stdout, &pr)
 	case "incident":
 		var ir result.IncidentResult
 		if err := json.Unmarshal([]byte(jsonStr), &ir); err != nil {
 			if outputFile == "" {
-				stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 				printlnOut(raw)
 				return nil
 			}
 			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
 		}
 		if outputFile != "" {
-			return exportToFile(&ir, mode, outputFile, clusterName, filters)
+			return exportToFile(&ir, outputFile, meta)
 		}
 		return result.RenderIncidentHuman(os.Stdout, &ir)
 	case "teamlead":
 		var tr result.TeamleadResult
 		if err := json.Unmarshal([]byte(jsonStr), &tr); err != nil {
 			if outputFile == "" {
-				stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 				printlnOut(raw)
 				return nil
 			}
 			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
 		}
 		if outputFile != "" {
-			return exportToFile(&tr, mode, outputFile, clusterName, filters)
+			return exportToFile(&tr, outputFile, meta)
 		}
 		return result.RenderTeamleadHuman(os.Stdout, &tr)
 	case "compliance":
 		var cr result.ComplianceResult
 		if err := json.Unmarshal([]byte(jsonStr), &cr); err != nil {
 			if outputFile == "" {
-				stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 				printlnOut(raw)
 				return nil
 			}
 			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
 		}
+		cr.Issues = append(cr.Issues, policyIssues...)
 		if outputFile != "" {
-			return exportToFile(&cr, mode, outputFile, clusterName, filters)
+			return exportToFile(&cr, outputFile, meta)
 		}
 		return result.RenderComplianceHuman(os.Stdout, &cr)
 	case "chaos":
 		var ch result.ChaosResult
 		if err := json.Unmarshal([]byte(jsonStr), &ch); err != nil {
 			if outputFile == "" {
-				stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 				printlnOut(raw)
 				return nil
 			}
 			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
 		}
 		if outputFile != "" {
-			return exportToFile(&ch, mode, outputFile, clusterName, filters)
+			return exportToFile(&ch, outputFile, meta)
 		}
 		return result.RenderChaosHuman(os.Stdout, &ch)
+	case "security":
+		var sr result.SecurityResult
+		if err := json.Unmarshal([]byte(jsonStr), &sr); err != nil {
+			if outputFile == "" {
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
+				printlnOut(raw)
+				return nil
+			}
+			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
+		}
+		if outputFile != "" {
+			return exportToFile(&sr, outputFile, meta)
+		}
+		return result.RenderSecurityHuman(os.Stdout, &sr)
+	case "capacity":
+		var cr result.CapacityResult
+		if err := json.Unmarshal([]byte(jsonStr), &cr); err != nil {
+			if outputFile == "" {
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
+				printlnOut(raw)
+				return nil
+			}
+			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
+		}
+		if outputFile != "" {
+			return exportToFile(&cr, outputFile, meta)
+		}
+		return result.RenderCapacityHuman(os.Stdout, &cr)
 	default:
 		var dr result.DefaultResult
 		if err := json.Unmarshal([]byte(jsonStr), &dr); err != nil {
 			if outputFile == "" {
-				stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+				slog.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 				printlnOut(raw)
 				return nil
 			}
 			return fmt.Errorf("failed to parse %s JSON: %w", mode, err)
 		}
 		if outputFile != "" {
-			return exportToFile(&dr, mode, outputFile, clusterName, filters)
+			return exportToFile(&dr, outputFile, meta)
 		}
 		return result.RenderDefaultHuman(os.Stdout, &dr)
 	}
 }
 
-// exportToFile exports the result to a file in the specified format
-func exportToFile(parsedResult interface{}, mode, outputPath, clusterName string, filters *snapshot.Filters) error {
-	format := export.DetectFormat(outputPath)
+// strictJSON extracts the JSON document from the LLM output and
+// pretty-prints it, appending deterministic compliance findings.
+func strictJSON(raw string, policyIssues []result.ComplianceIssue) (string, error) {
+	jsonStr, jerr := llm.ExtractJSON(raw)
+	if jerr != nil {
+		return "", fmt.Errorf("json parse error: %w\nRaw output:\n%s", jerr, raw)
+	}
+
+	if len(policyIssues) > 0 {
+		var cr result.ComplianceResult
+		if err := json.Unmarshal([]byte(jsonStr), &cr); err != nil {
+			return "", fmt.Errorf("json unmarshal error: %w\nRaw JSON:\n%s", err, jsonStr)
+		}
+		cr.Issues = append(cr.Issues, policyIssues...)
+		out, err := result.PrettyJSON(cr)
+		if err != nil {
+			return "", fmt.Errorf("json marshal error: %w", err)
+		}
+		return out, nil
+	}
+
+	var tmp any
+	if err := json.Unmarshal([]byte(jsonStr), &tmp); err != nil {
+		return "", fmt.Errorf("json unmarshal error: %w\nRaw JSON:\n%s", err, jsonStr)
+	}
 
+	out, err := result.PrettyJSON(tmp)
+	if err != nil {
+		return jsonStr + "\n", nil
+	}
+	return out, nil
+}
+
+// exportToFile exports the result to a file in the format detected from its extension
+func exportToFile(parsedResult interface{}, outputPath string, meta *export.ExportMetadata) error {
 	exporter := export.Exporter{
-		Format: format,
-		Metadata: export.ExportMetadata{
-			GeneratedAt:    time.Now().UTC(),
-			KubenowVersion: version, // from root.go
-			ClusterName:    clusterName,
-			Mode:           mode,
-			Filters:        *filters,
-		},
+		Format:   export.DetectFormat(outputPath),
+		Metadata: *meta,
 	}
+	exporter.Metadata.GeneratedAt = time.Now().UTC()
+	exporter.Metadata.KubenowVersion = version // from root.go
 
-	file, err := os.Create(outputPath)
+	file, err := cleanup.Create(outputPath, 0o644)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer func() {
-		closeBestEffort(file)
-	}()
+	defer file.Discard()
 
 	if err := exporter.Export(parsedResult, file); err != nil {
 		return fmt.Errorf("failed to export: %w", err)
 	}
+	if err := file.Commit(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
 
-	stderrf("[kubenow] Report saved to: %s\n", outputPath)
+	slog.Info("Report saved", "path", outputPath)
 	return nil
 }
 
@@ -368,11 +1066,15 @@ func extractClusterName(kubeconfigPath string) string {
 		return "unknown"
 	}
 
-	if rawConfig.CurrentContext == "" {
+	contextName := rawConfig.CurrentContext
+	if override := GetKubecontext(); override != "" {
+		contextName = override
+	}
+	if contextName == "" {
 		return "unknown"
 	}
 
-	ctx, ok := rawConfig.Contexts[rawConfig.CurrentContext]
+	ctx, ok := rawConfig.Contexts[contextName]
 	if !ok {
 		return "unknown"
 	}
@@ -380,27 +1082,6 @@ func extractClusterName(kubeconfigPath string) string {
 	return ctx.Cluster
 }
 
-// extractJSON extracts a JSON object or array from noisy LLM output
-func extractJSON(s string) (string, error) {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return "", fmt.Errorf("empty LLM output")
-	}
-
-	// If starts with { or [, assume valid JSON
-	if s[0] == '{' || s[0] == '[' {
-		return s, nil
-	}
-
-	start := strings.Index(s, "{")
-	end := strings.LastIndex(s, "}")
-	if start == -1 || end == -1 || end <= start {
-		return "", fmt.Errorf("no JSON object detected in output")
-	}
-
-	return s[start : end+1], nil
-}
-
 // setupSignalHandler sets up signal handling for graceful shutdown
 func setupSignalHandler(_ context.CancelFunc) {
 	// Signal handling is already done in watch.Run, but we can add here if needed
@@ -409,20 +1090,81 @@ func setupSignalHandler(_ context.CancelFunc) {
 
 // addLLMFlags adds common LLM flags to a command
 func addLLMFlags(cmd *cobra.Command, config *LLMCommandConfig) {
-	// Required flags
-	cmd.Flags().StringVar(&config.LLMEndpoint, "llm-endpoint", "", "OpenAI-compatible endpoint (e.g., http://localhost:11434/v1)")
-	cmd.Flags().StringVar(&config.Model, "model", "", "Model name (e.g., mixtral:8x22b, gpt-4.1-mini)")
-	mustMarkFlagRequired(cmd, "llm-endpoint")
-	mustMarkFlagRequired(cmd, "model")
+	addLLMClientFlags(cmd, config)
+	cmd.Flags().IntVar(&config.MaxPromptTokens, "max-prompt-tokens", 0,
+		"Prompt token budget; larger snapshots are trimmed (0 = model context window minus response reserve)")
+	cmd.Flags().StringVar(&config.SaveSnapshot, "save-snapshot", "",
+		"Save the collected cluster snapshot to a JSON file (without --llm-endpoint/--model: collect only)")
+	cmd.Flags().StringVar(&config.FromSnapshot, "from-snapshot", "", "Analyze a saved snapshot file instead of the live cluster (no cluster access needed)")
+	cmd.Flags().StringVar(&config.ModeSelection, "mode", "",
+		"'auto' picks the prompt from triage (incident if fatal problems, otherwise default); "+
+			"'offline' reports the deterministic triage without an LLM (no --llm-endpoint/--model needed)")
+
+	addSnapshotFlags(cmd, config)
+	addPromptFlags(cmd, config)
+	addWatchFlags(cmd, config)
+}
+
+// addLLMClientFlags adds the LLM connection, answer, cache, and output flags.
+func addLLMClientFlags(cmd *cobra.Command, config *LLMCommandConfig) {
+	// Required flags (validated in RunLLMCommand: --save-snapshot may run without them)
+	cmd.Flags().StringVar(&config.LLMEndpoint, "llm-endpoint", "",
+		"LLM API base URL (e.g., http://localhost:11434/v1); required for openai, defaults to the public API for anthropic and gemini")
+	cmd.Flags().StringVar(&config.Model, "model", "", "Model name (e.g., mixtral:8x22b, gpt-4.1-mini, claude-sonnet-4-5, gemini-2.5-pro)")
 
 	// Optional flags
-	cmd.Flags().StringVar(&config.APIKey, "api-key", "", "LLM API key (optional for local models)")
+	cmd.Flags().StringVar(&config.LLMProvider, "llm-provider", llm.ProviderOpenAI,
+		"LLM API: openai (any OpenAI-compatible endpoint), anthropic (Messages API), or gemini")
+	cmd.Flags().StringVar(&config.APIKey, "api-key", "",
+		"LLM API key (optional for local models; defaults to OPENAI_API_KEY, ANTHROPIC_API_KEY, or GEMINI_API_KEY/GOOGLE_API_KEY)")
+	cmd.Flags().IntVar(&config.MaxResponseTokens, "max-response-tokens", 0,
+		fmt.Sprintf("Cap on generated tokens (0 = provider default; anthropic requires one and uses %d)", llm.DefaultAnthropicMaxTokens))
+	cmd.Flags().BoolVar(&config.StrictJSON, "llm-strict-json", false,
+		"Request JSON-mode output (openai, gemini) and re-prompt with the parse error when the answer is not valid JSON")
+	cmd.Flags().IntVar(&config.JSONRetries, "llm-json-retries", 2, "Re-prompts after an invalid JSON answer with --llm-strict-json")
+	cmd.Flags().Float64Var(&config.PriceInput, "llm-price-input", 0,
+		"Prompt token price in USD per million tokens for the cost line (0 = the model's list price; self-hosted models have none)")
+	cmd.Flags().Float64Var(&config.PriceOutput, "llm-price-output", 0,
+		"Completion token price in USD per million tokens for the cost line (0 = the model's list price)")
+	cmd.Flags().BoolVar(&config.NoCache, "no-cache", false, "Always call the LLM instead of reusing a cached answer for an unchanged snapshot")
+	cmd.Flags().DurationVar(&config.CacheTTL, "cache-ttl", llmcache.DefaultTTL, "How long a cached LLM answer is reused")
+	cmd.Flags().StringVar(&config.CacheDir, "cache-dir", "", "LLM answer cache directory (default ~/.kubenow/cache/llm)")
 	cmd.Flags().StringVar(&config.Format, "format", "human", "Output format: human|json")
+	cmd.Flags().IntVar(&config.TimeoutSeconds, "timeout-seconds", 60, "LLM call timeout in seconds")
+	cmd.Flags().StringVar(&config.OutputFile, "output", "", "Save report to file (format auto-detected: .json, .md, .html, .txt)")
+	cmd.Flags().BoolVar(&config.Stream, "stream", false,
+		"Echo the raw LLM answer to stderr as it arrives, before the rendered report (human format only; JSON and --output stay buffered)")
+}
+
+// addWatchFlags adds the watch mode, notification, and metrics flags.
+func addWatchFlags(cmd *cobra.Command, config *LLMCommandConfig) {
+	// Watch mode
+	cmd.Flags().StringVar(&config.WatchInterval, "watch-interval", "", "Enable watch mode with interval (e.g., '30s', '1m', '5m')")
+	cmd.Flags().StringVar(&config.WatchConfig, "watch-config", "",
+		"Watch schedules YAML: per-namespace-group interval, mode, and alert settings in one process")
+	cmd.Flags().IntVar(&config.WatchIterations, "watch-iterations", 0, "Max watch iterations (0 = infinite)")
+	cmd.Flags().BoolVar(&config.WatchAlertNewOnly, "watch-alert-new-only", false, "Only show new/changed issues in watch mode")
+	cmd.Flags().StringVar(&config.WatchState, "watch-state", "", "Watch state database remembering seen issues across restarts (default ~/.kubenow/watch/state.db)")
+	cmd.Flags().BoolVar(&config.WatchStateless, "watch-stateless", false, "Keep watch state in memory only")
+	cmd.Flags().Float64Var(&config.WatchMemoryGrowth, "watch-memory-growth", 0,
+		"Alert when a workload's memory grows faster than this many percent per hour (0 = off, needs metrics-server)")
+	cmd.Flags().Float64Var(&config.WatchCPUGrowth, "watch-cpu-growth", 0,
+		"Alert when a workload's CPU grows faster than this many percent per hour (0 = off, needs metrics-server)")
+	cmd.Flags().IntVar(&config.WatchGrowthIterations, "watch-growth-iterations", watch.DefaultGrowthIterations,
+		"Consecutive growing checks required before a growth alert fires")
+	cmd.Flags().StringArrayVar(&config.NotifyWebhooks, "notify-webhook", nil,
+		"Push new/changed issues to a Slack, Teams, Google Chat, or generic webhook: [severity=]URL (repeatable; default severity: high)")
+	cmd.Flags().StringVar(&config.NotifySlackChannel, "notify-slack-channel", "", "Slack channel override for Slack webhooks (e.g. '#oncall')")
+	addIncidentFlags(cmd, &config.Incidents)
+	cmd.Flags().IntVar(&config.MetricsPort, "metrics-port", 0,
+		"Serve each watch iteration's kubenow_problem_count{severity} on this port's /metrics (0 = disabled)")
+}
+
+// addSnapshotFlags adds the flags that shape the collected snapshot.
+func addSnapshotFlags(cmd *cobra.Command, config *LLMCommandConfig) {
 	cmd.Flags().IntVar(&config.MaxPods, "max-pods", 20, "Max problematic pods to include")
 	cmd.Flags().IntVar(&config.LogLines, "log-lines", 50, "Max log lines per container")
-	cmd.Flags().IntVar(&config.TimeoutSeconds, "timeout-seconds", 60, "LLM call timeout in seconds")
 	cmd.Flags().IntVar(&config.MaxConcurrent, "max-concurrent-fetches", 5, "Max concurrent log fetches")
-	cmd.Flags().StringVar(&config.OutputFile, "output", "", "Save report to file (format auto-detected: .json, .md, .html, .txt)")
 
 	// Filters
 	cmd.Flags().StringVar(&config.IncludePods, "include-pods", "", "Comma-separated pod name patterns to include (supports wildcards)")
@@ -431,17 +1173,36 @@ func addLLMFlags(cmd *cobra.Command, config *LLMCommandConfig) {
 	cmd.Flags().StringVar(&config.ExcludeNamespaces, "exclude-namespaces", "", "Comma-separated namespace patterns to exclude (supports wildcards)")
 	cmd.Flags().StringVar(&config.IncludeKeywords, "include-keywords", "", "Comma-separated keywords to search in logs/events")
 	cmd.Flags().StringVar(&config.ExcludeKeywords, "exclude-keywords", "", "Comma-separated keywords to exclude from logs/events")
+	cmd.Flags().BoolVar(&config.IncludeStorage, "include-storage", false,
+		"Include PVC binding, PV phase, StorageClass provisioner, and volume attachment state for problem pods' volumes")
+	cmd.Flags().StringVar(&config.AckFile, "ack-file", "",
+		"Acknowledgements YAML: known accepted problems are left out of the analysis and reported in their own section")
+	cmd.Flags().StringVar(&config.OwnersFile, "owners-file", "",
+		"Owners YAML mapping namespaces and label selectors to teams: problem pods are annotated with their owning team")
+}
+
+// addPromptFlags adds the flags that shape the prompt built from a snapshot.
+func addPromptFlags(cmd *cobra.Command, config *LLMCommandConfig) {
 	cmd.Flags().StringVar(&config.ProblemHint, "hint", "", "Problem hint to guide LLM analysis (e.g., 'memory leak', 'network issue')")
+	cmd.Flags().StringVar(&config.RedactProfile, "redact-profile", string(redact.ProfileStandard),
+		"Mask secrets and PII in logs and events before they reach the LLM: strict|standard|off")
+	cmd.Flags().StringArrayVar(&config.RedactPatterns, "redact-pattern", nil,
+		"Extra regular expression whose matches are masked (repeatable)")
 
 	// Enhancements
 	cmd.Flags().BoolVar(&config.EnhanceTechnical, "enhance-technical", false, "Include technical depth (stack traces, config diffs)")
 	cmd.Flags().BoolVar(&config.EnhancePriority, "enhance-priority", false, "Include priority scoring (numerical scores, SLO impact)")
 	cmd.Flags().BoolVar(&config.EnhanceRemediation, "enhance-remediation", false, "Include detailed remediation (step-by-step fixes)")
 
-	// Watch mode
-	cmd.Flags().StringVar(&config.WatchInterval, "watch-interval", "", "Enable watch mode with interval (e.g., '30s', '1m', '5m')")
-	cmd.Flags().IntVar(&config.WatchIterations, "watch-iterations", 0, "Max watch iterations (0 = infinite)")
-	cmd.Flags().BoolVar(&config.WatchAlertNewOnly, "watch-alert-new-only", false, "Only show new/changed issues in watch mode")
+	addTemplateFlags(cmd, config)
+}
+
+// addTemplateFlags adds the flags that replace the embedded prompt templates.
+func addTemplateFlags(cmd *cobra.Command, config *LLMCommandConfig) {
+	cmd.Flags().StringVar(&config.PromptDir, "prompt-dir", "",
+		"Directory of custom prompt templates named <mode>.tmpl (e.g. incident.tmpl); modes without a file keep the embedded template")
+	cmd.Flags().StringVar(&config.PromptFile, "prompt-file", "",
+		"Custom prompt template for this run, overriding --prompt-dir and the embedded template")
 }
 
 func mustMarkFlagRequired(cmd *cobra.Command, name string) {
@@ -449,3 +1210,9 @@ func mustMarkFlagRequired(cmd *cobra.Command, name string) {
 		panic(err)
 	}
 }
+
+func mustMarkFlagDeprecated(cmd *cobra.Command, name, usage string) {
+	if err := cmd.Flags().MarkDeprecated(name, usage); err != nil {
+		panic(err)
+	}
+}
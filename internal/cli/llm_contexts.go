@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// llmContextRun is the outcome of analyzing one context. Triage counts the
+// snapshot's problems deterministically, so clusters compare even when the
+// LLM answers differ in shape.
+type llmContextRun struct {
+	Context     string                 `json:"context"`
+	Cluster     string                 `json:"cluster"`
+	ProblemPods int                    `json:"problem_pods"`
+	Triage      snapshot.TriageSummary `json:"triage"`
+	Error       string                 `json:"error,omitempty"`
+	Result      json.RawMessage        `json:"result,omitempty"`
+}
+
+// llmContextsSummary totals the triage across clusters.
+type llmContextsSummary struct {
+	Clusters    int `json:"clusters"`
+	Failed      int `json:"failed"`
+	ProblemPods int `json:"problem_pods"`
+	snapshot.TriageSummary
+}
+
+// llmContextsReport is the --format json document of a multi-context run.
+type llmContextsReport struct {
+	Clusters []llmContextRun    `json:"clusters"`
+	Summary  llmContextsSummary `json:"summary"`
+}
+
+// runLLMContexts runs the LLM analysis once per context. Human output gets
+// one section per cluster and a cross-cluster summary table; JSON output to
+// stdout is a single document. --output files are written per context
+// (report.html -> report-<context>.html). A context that fails is reported
+// and skipped; the run fails only when every context does.
+func runLLMContexts(
+	contexts []util.KubeContext, llmClient *llm.Client, config *LLMCommandConfig,
+	filters *snapshot.Filters, enhancements prompt.PromptEnhancements,
+) error {
+	jsonStdout := config.Format == "json" && config.OutputFile == ""
+	report := llmContextsReport{Clusters: make([]llmContextRun, 0, len(contexts))}
+
+	for i, c := range contexts {
+		logging.Progress(slog.Default(), "Context", i+1, len(contexts), "context", c.Name)
+		run := llmContextRun{Context: c.Name, Cluster: contextClusterName(c)}
+		if !jsonStdout {
+			printfOut("\n=== Context %s (cluster %s) ===\n", run.Context, run.Cluster)
+		}
+		if err := analyzeLLMContext(c, &run, llmClient, config, filters, enhancements, jsonStdout); err != nil {
+			run.Error = err.Error()
+			report.Summary.Failed++
+			slog.Warn("Context failed", "context", c.Name, "error", err)
+			if !jsonStdout {
+				printfOut("\nAnalysis failed: %v\n", err)
+			}
+		}
+
+		report.Summary.Clusters++
+		report.Summary.ProblemPods += run.ProblemPods
+		report.Summary.Fatal += run.Triage.Fatal
+		report.Summary.Critical += run.Triage.Critical
+		report.Summary.Warning += run.Triage.Warning
+		report.Clusters = append(report.Clusters, run)
+	}
+
+	if jsonStdout {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		printlnOut(string(data))
+	} else {
+		printOut(renderLLMContextsSummary(&report))
+	}
+
+	if report.Summary.Failed == report.Summary.Clusters {
+		return fmt.Errorf("analysis failed in all %d context(s)", report.Summary.Failed)
+	}
+	return nil
+}
+
+// analyzeLLMContext collects one context's snapshot and analyzes it,
+// recording the triage in run. With jsonStdout the LLM's JSON is kept in
+// run for the combined document instead of being printed.
+func analyzeLLMContext(
+	c util.KubeContext, run *llmContextRun, llmClient *llm.Client, config *LLMCommandConfig,
+	filters *snapshot.Filters, enhancements prompt.PromptEnhancements, jsonStdout bool,
+) error {
+	clientset, err := util.BuildKubeClientWithOpts(contextKubeOpts(c))
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+	snap, err := snapshot.BuildSnapshot(context.Background(), clientset, GetNamespace(), config.MaxPods, config.LogLines, config.MaxConcurrent, filters)
+	if err != nil {
+		return fmt.Errorf("snapshot error: %w", err)
+	}
+	// Counted after the analysis, which sets acknowledged problems aside
+	defer func() {
+		run.ProblemPods = len(snap.ProblemPods)
+		run.Triage = snapshot.Triage(snap)
+	}()
+
+	if !jsonStdout {
+		contextConfig := *config
+		contextConfig.OutputFile = contextOutputFile(config.OutputFile, c.Name)
+		return analyzeSnapshot(clientset, llmClient, &contextConfig, filters, enhancements, run.Cluster, snap)
+	}
+
+	a, err := runLLMAnalysis(clientset, llmClient, config, filters, enhancements, run.Cluster, snap)
+	if err != nil {
+		return err
+	}
+	out, err := strictJSON(a.raw, a.policyIssues)
+	if err != nil {
+		return err
+	}
+	run.Result = json.RawMessage(out)
+	return nil
+}
+
+func renderLLMContextsSummary(r *llmContextsReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== Cross-Cluster Summary (%d clusters) ===\n\n", r.Summary.Clusters)
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Context", "Cluster", "Problem Pods", "Fatal", "Critical", "Warning", "Status"})
+	for i := range r.Clusters {
+		run := &r.Clusters[i]
+		status := "ok"
+		if run.Error != "" {
+			status = "failed"
+		}
+		appendTableRowBestEffort(table, []string{
+			run.Context, run.Cluster, strconv.Itoa(run.ProblemPods),
+			strconv.Itoa(run.Triage.Fatal), strconv.Itoa(run.Triage.Critical), strconv.Itoa(run.Triage.Warning), status,
+		})
+	}
+	appendTableRowBestEffort(table, []string{
+		"Total", "", strconv.Itoa(r.Summary.ProblemPods),
+		strconv.Itoa(r.Summary.Fatal), strconv.Itoa(r.Summary.Critical), strconv.Itoa(r.Summary.Warning),
+		fmt.Sprintf("%d failed", r.Summary.Failed),
+	})
+	renderTableBestEffort(table)
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/ppiankov/kubenow/internal/ack"
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/monitor"
+	"github.com/ppiankov/kubenow/internal/owners"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+// modeOffline is the --mode value that triages the snapshot with the
+// monitor's deterministic rules and never calls an LLM.
+const modeOffline = "offline"
+
+// offlineProblem is one detected problem in the offline report.
+type offlineProblem struct {
+	Severity  string            `json:"severity"`
+	Type      string            `json:"type"`
+	Namespace string            `json:"namespace,omitempty"`
+	Object    string            `json:"object"` // pod, or node for node conditions
+	Container string            `json:"container,omitempty"`
+	Team      string            `json:"team,omitempty"`
+	Message   string            `json:"message"`
+	SubReason string            `json:"subReason,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// offlineReport is the structured result of --mode offline.
+type offlineReport struct {
+	Cluster      string                 `json:"cluster"`
+	GeneratedAt  time.Time              `json:"generatedAt"`
+	ProblemPods  int                    `json:"problemPods"`
+	Triage       snapshot.TriageSummary `json:"triage"`
+	ByType       map[string]int         `json:"byType"`
+	Problems     []offlineProblem       `json:"problems"`
+	Acknowledged []ack.Problem          `json:"acknowledged,omitempty"`
+	Owners       []owners.Assignment    `json:"owners,omitempty"`
+}
+
+// runOfflineTriage reports the snapshot's problems from deterministic
+// detection only: no prompt is built and no LLM endpoint is contacted.
+func runOfflineTriage(config *LLMCommandConfig, clusterName string, snap *snapshot.Snapshot) error {
+	acks, err := loadAcknowledgements(config.AckFile)
+	if err != nil {
+		return err
+	}
+	snap.SplitAcknowledged(acks, time.Now())
+	teamOwners, err := loadOwners(config.OwnersFile)
+	if err != nil {
+		return err
+	}
+	snap.AssignOwners(teamOwners)
+
+	report := buildOfflineReport(clusterName, snap)
+	if teamOwners != nil {
+		report.Owners = snap.OwnerAssignments(teamOwners)
+	}
+
+	if config.OutputFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		if err := cleanup.WriteFile(config.OutputFile, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		slog.Info("Offline triage report saved", "path", config.OutputFile, "problems", len(report.Problems))
+		return nil
+	}
+	if config.Format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		printlnOut(string(data))
+		return nil
+	}
+	printOut(renderOfflineReport(report))
+	printAcknowledged(report.Acknowledged)
+	printOwners(report.Owners)
+	return nil
+}
+
+// buildOfflineReport runs monitor.SnapshotProblems on the snapshot.
+func buildOfflineReport(clusterName string, snap *snapshot.Snapshot) *offlineReport {
+	teams := make(map[string]string)
+	for i := range snap.ProblemPods {
+		p := &snap.ProblemPods[i]
+		teams[p.Namespace+"/"+p.Name] = p.Team
+	}
+
+	report := &offlineReport{
+		Cluster:      clusterName,
+		GeneratedAt:  snap.GeneratedAt,
+		ProblemPods:  len(snap.ProblemPods),
+		Triage:       snapshot.Triage(snap),
+		ByType:       make(map[string]int),
+		Problems:     []offlineProblem{},
+		Acknowledged: snap.AcknowledgedProblems,
+	}
+	for _, p := range monitor.SnapshotProblems(snap) {
+		report.ByType[p.Type]++
+		report.Problems = append(report.Problems, offlineProblem{
+			Severity:  string(p.Severity),
+			Type:      p.Type,
+			Namespace: p.Namespace,
+			Object:    p.PodName,
+			Container: p.ContainerName,
+			Team:      teams[p.Namespace+"/"+p.PodName],
+			Message:   p.Message,
+			SubReason: p.SubReason,
+			Details:   p.Details,
+		})
+	}
+	return report
+}
+
+func renderOfflineReport(r *offlineReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== Offline Triage (cluster %s, no LLM) ===\n\n", r.Cluster)
+	fmt.Fprintf(&b, "Problem pods: %d   Fatal: %d   Critical: %d   Warning: %d\n",
+		r.ProblemPods, r.Triage.Fatal, r.Triage.Critical, r.Triage.Warning)
+	if len(r.Problems) == 0 {
+		b.WriteString("\nNo problems detected.\n")
+		return b.String()
+	}
+
+	types := make([]string, 0, len(r.ByType))
+	for t := range r.ByType {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if r.ByType[types[i]] != r.ByType[types[j]] {
+			return r.ByType[types[i]] > r.ByType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = t + " " + strconv.Itoa(r.ByType[t])
+	}
+	fmt.Fprintf(&b, "By type: %s\n\n", strings.Join(parts, ", "))
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Severity", "Type", "Object", "Container", "Reason"})
+	for i := range r.Problems {
+		p := &r.Problems[i]
+		object := p.Object
+		if p.Namespace != "" {
+			object = p.Namespace + "/" + p.Object
+		}
+		reason := p.SubReason
+		if reason == "" {
+			reason = p.Message
+		}
+		appendTableRowBestEffort(table, []string{p.Severity, p.Type, object, p.Container, reason})
+	}
+	renderTableBestEffort(table)
+	return b.String()
+}
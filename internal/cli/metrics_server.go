@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/ppiankov/kubenow/internal/telemetry"
+)
+
+// startMetricsServer serves kubenow's telemetry and findings on port's
+// /metrics until ctx is canceled.
+func startMetricsServer(ctx context.Context, port int) *telemetry.Server {
+	srv := telemetry.NewServer(port)
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			slog.Error("Metrics server failed", "error", err)
+		}
+	}()
+	slog.Info("Metrics endpoint", "url", fmt.Sprintf("http://localhost:%d/metrics", port))
+	return srv
+}
@@ -3,13 +3,20 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
+	"log/slog"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/ppiankov/kubenow/internal/ack"
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/monitor"
+	"github.com/ppiankov/kubenow/internal/notify"
+	"github.com/ppiankov/kubenow/internal/prompt"
 	"github.com/ppiankov/kubenow/internal/telemetry"
 	"github.com/ppiankov/kubenow/internal/util"
 )
@@ -21,6 +28,17 @@ var monitorConfig struct {
 	alertSound     bool
 	noMesh         bool
 	metricsPort    int
+	prometheusURL  string
+	ackFile        string
+	incidents      IncidentFlags
+
+	// LLM explain (a key); off without --model
+	llmProvider       string
+	llmEndpoint       string
+	model             string
+	apiKey            string
+	maxResponseTokens int
+	timeoutSeconds    int
 }
 
 var monitorCmd = &cobra.Command{
@@ -34,6 +52,8 @@ This command watches for critical issues like:
   • ImagePullBackOff - Image pull failures
   • Failed pods - Container failures
   • Node issues - NotReady, DiskPressure, etc.
+  • Services with zero ready endpoints (with the backing workload)
+  • Ingress 5xx spikes (ingress-nginx metrics, requires --prometheus-url)
 
 The screen stays mostly empty when everything is healthy (attention-first design).
 A heartbeat indicator shows the monitor is actively running.
@@ -60,6 +80,18 @@ Examples:
   # Disable service mesh health monitoring
   kubenow monitor --no-mesh
 
+  # Also flag ingress routes with elevated 5xx rates
+  kubenow monitor --prometheus-url http://prometheus:9090
+
+  # List known accepted problems apart from active ones in print/export
+  kubenow monitor --ack-file ./acks.yaml
+
+  # Page on-call through PagerDuty for fatal problems (OOMKills, crash loops)
+  kubenow monitor --pagerduty-routing-key "$PD_ROUTING_KEY"
+
+  # Press 'a' on a problem to have an LLM explain it from its pod, events, and logs
+  kubenow monitor --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b
+
 Philosophy:
   • Attention-first: Screen is empty when healthy
   • No navigation: Problems auto-appear
@@ -77,20 +109,54 @@ func init() {
 	monitorCmd.Flags().BoolVar(&monitorConfig.quiet, "quiet", false, "Quiet mode: only show problems, hide stats")
 	monitorCmd.Flags().BoolVar(&monitorConfig.alertSound, "alert", false, "Terminal bell on critical problems")
 	monitorCmd.Flags().BoolVar(&monitorConfig.noMesh, "no-mesh", false, "Disable service mesh health monitoring")
-	monitorCmd.Flags().IntVar(&monitorConfig.metricsPort, "metrics-port", 0, "Expose Prometheus metrics on this port (0 = disabled)")
+	monitorCmd.Flags().StringVar(&monitorConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint for ingress 5xx detection (optional)")
+	monitorCmd.Flags().IntVar(&monitorConfig.metricsPort, "metrics-port", 0, "Expose Prometheus metrics, including kubenow_problem_count{severity}, on this port (0 = disabled)")
+	monitorCmd.Flags().StringVar(&monitorConfig.ackFile, "ack-file", "",
+		"Acknowledgements YAML: known accepted problems are listed separately in print (c) and export")
+	addIncidentFlags(monitorCmd, &monitorConfig.incidents)
+
+	// LLM explain
+	monitorCmd.Flags().StringVar(&monitorConfig.llmProvider, "llm-provider", llm.ProviderOpenAI,
+		"LLM API for the explain key: openai (any OpenAI-compatible endpoint), anthropic (Messages API), or gemini")
+	monitorCmd.Flags().StringVar(&monitorConfig.llmEndpoint, "llm-endpoint", "",
+		"LLM API base URL for the explain key (e.g., http://localhost:11434/v1); required for openai")
+	monitorCmd.Flags().StringVar(&monitorConfig.model, "model", "", "Model that explains a selected problem (a key); explain is off without it")
+	monitorCmd.Flags().StringVar(&monitorConfig.apiKey, "api-key", "",
+		"LLM API key (optional for local models; defaults to OPENAI_API_KEY, ANTHROPIC_API_KEY, or GEMINI_API_KEY/GOOGLE_API_KEY)")
+	monitorCmd.Flags().IntVar(&monitorConfig.maxResponseTokens, "max-response-tokens", 0,
+		fmt.Sprintf("Cap on generated tokens (0 = provider default; anthropic requires one and uses %d)", llm.DefaultAnthropicMaxTokens))
+	monitorCmd.Flags().IntVar(&monitorConfig.timeoutSeconds, "timeout-seconds", 60, "LLM call timeout in seconds")
 }
 
 func runMonitor(_ *cobra.Command, _ []string) error {
 	// Build Kubernetes client
-	if IsVerbose() {
-		stderrln("[kubenow] Building Kubernetes client...")
-	}
+	slog.Debug("Building Kubernetes client...")
 
 	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
 	if err != nil {
 		return fmt.Errorf("failed to build Kubernetes client: %w", err)
 	}
 
+	acks, err := loadAcknowledgements(monitorConfig.ackFile)
+	if err != nil {
+		return err
+	}
+
+	keys, err := monitor.NewKeyMap(GetKeyBindings("monitor"))
+	if err != nil {
+		return fmt.Errorf("invalid keybindings.monitor in config: %w", err)
+	}
+
+	explain, err := monitorExplainer()
+	if err != nil {
+		return err
+	}
+
+	incidents, err := monitorConfig.incidents.build(extractClusterName(GetKubeconfig()))
+	if err != nil {
+		return err
+	}
+
 	// Parse severity filter
 	var severityFilter monitor.Severity
 	if monitorConfig.severityFilter != "" {
@@ -117,18 +183,21 @@ func runMonitor(_ *cobra.Command, _ []string) error {
 
 	watcher := monitor.NewWatcher(kubeClient, config)
 
+	if monitorConfig.prometheusURL != "" {
+		promClient, err := metrics.NewPrometheusClient(metrics.Config{PrometheusURL: monitorConfig.prometheusURL})
+		if err != nil {
+			return fmt.Errorf("failed to create Prometheus client: %w", err)
+		}
+		watcher.SetPrometheusAPI(promClient.GetAPI())
+	}
+
 	// Start metrics server if requested
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	if monitorConfig.metricsPort > 0 {
-		srv := telemetry.NewServer(monitorConfig.metricsPort)
-		go func() {
-			if err := srv.Start(ctx); err != nil {
-				stderrf("[kubenow] Metrics server error: %v\n", err)
-			}
-		}()
-		stderrf("[kubenow] Metrics endpoint: http://localhost:%d/metrics\n", monitorConfig.metricsPort)
+		srv := startMetricsServer(ctx, monitorConfig.metricsPort)
+		go publishMonitorFindings(ctx, watcher, acks, srv.Findings())
 	}
 
 	// Start watching
@@ -136,9 +205,19 @@ func runMonitor(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed to start watcher: %w", err)
 	}
 
+	var incidentErrs monitorIncidentErrors
+	if incidents != nil {
+		go syncMonitorIncidents(ctx, watcher, acks, incidents, &incidentErrs)
+		defer incidentErrs.report()
+	}
+
 	// Run TUI in a loop (for print mode that returns to monitor)
 	for {
 		model := monitor.NewModel(watcher)
+		model.SetKeyMap(keys)
+		if explain != nil {
+			model.SetExplainer(monitorConfig.model, explain)
+		}
 		p := tea.NewProgram(
 			&model,
 			tea.WithAltScreen(),       // Use alternate screen buffer
@@ -153,11 +232,11 @@ func runMonitor(_ *cobra.Command, _ []string) error {
 		// Check what action was requested
 		if m, ok := finalModel.(*monitor.Model); ok {
 			if m.ExportRequested() {
-				return exportProblems(m)
+				return exportProblems(m, acks)
 			}
 			if m.PrintRequested() {
 				// Print to terminal (copyable), wait for input, then loop back
-				printProblemsToTerminal(m)
+				printProblemsToTerminal(m, acks)
 				printlnOut("\nPress Enter to return to monitor...")
 				waitForEnter()
 				continue // Restart monitor loop
@@ -171,8 +250,153 @@ func runMonitor(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func printProblemsToTerminal(m *monitor.Model) {
-	problems, events, stats := m.GetState()
+// monitorExplainer builds the LLM call behind the monitor's explain key,
+// or returns nil when no model is configured.
+func monitorExplainer() (monitor.ExplainFunc, error) {
+	if err := llm.ValidateProvider(monitorConfig.llmProvider); err != nil {
+		return nil, fmt.Errorf("invalid --llm-provider: %w", err)
+	}
+	if monitorConfig.model == "" {
+		if monitorConfig.llmEndpoint != "" {
+			return nil, fmt.Errorf("--llm-endpoint requires --model")
+		}
+		return nil, nil
+	}
+	if monitorConfig.maxResponseTokens < 0 {
+		return nil, fmt.Errorf("--max-response-tokens must not be negative")
+	}
+	endpoint := monitorConfig.llmEndpoint
+	if endpoint == "" {
+		endpoint = llm.DefaultEndpoint(monitorConfig.llmProvider)
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("--model requires --llm-endpoint for the %s provider", monitorConfig.llmProvider)
+	}
+
+	client := llm.Client{
+		Provider:  monitorConfig.llmProvider,
+		Endpoint:  endpoint,
+		Model:     monitorConfig.model,
+		APIKey:    monitorConfig.apiKey,
+		MaxTokens: monitorConfig.maxResponseTokens,
+		Timeout:   time.Duration(monitorConfig.timeoutSeconds) * time.Second,
+	}
+	return func(ctx context.Context, p *monitor.Problem, d *monitor.ProblemDetails) (string, error) {
+		return client.Complete(ctx, prompt.LoadExplainPrompt(monitor.ExplainContext(p, d, time.Now())))
+	}, nil
+}
+
+// monitorFindingsInterval is how often the monitor's problem counts are
+// copied to the findings gauges.
+const monitorFindingsInterval = 10 * time.Second
+
+// publishMonitorFindings exports the active (unacknowledged) problem counts
+// by severity until ctx is canceled.
+func publishMonitorFindings(ctx context.Context, watcher *monitor.Watcher, acks *ack.List, findings *telemetry.Findings) {
+	ticker := time.NewTicker(monitorFindingsInterval)
+	defer ticker.Stop()
+	for {
+		all, _, _ := watcher.GetState()
+		problems, _ := monitor.SplitAcknowledged(all, acks, time.Now())
+		counts := make(map[string]int)
+		for i := range problems {
+			counts[string(problems[i].Severity)]++
+		}
+		findings.SetProblemCounts(telemetry.SourceMonitor, counts)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// monitorIncidentInterval is how often fatal problems are synced to the
+// incident services.
+const monitorIncidentInterval = 10 * time.Second
+
+// syncMonitorIncidents opens an incident for each container with an active
+// fatal problem and resolves it once the monitor drops the problem, until
+// ctx is canceled. Incidents stay open when the monitor exits.
+func syncMonitorIncidents(
+	ctx context.Context, watcher *monitor.Watcher, acks *ack.List, incidents *notify.Incidents, errs *monitorIncidentErrors,
+) {
+	ticker := time.NewTicker(monitorIncidentInterval)
+	defer ticker.Stop()
+	open := make(map[string]*notify.Incident)
+	for {
+		all, _, _ := watcher.GetState()
+		problems, _ := monitor.SplitAcknowledged(all, acks, time.Now())
+		fatal := make(map[string]*notify.Incident)
+		for i := range problems {
+			p := &problems[i]
+			if p.Severity != monitor.SeverityFatal {
+				continue
+			}
+			name := p.PodName
+			if p.ContainerName != "" {
+				name += "/" + p.ContainerName
+			}
+			// Same fingerprint as watch mode, so both raise one incident
+			fp := p.Namespace + "/" + p.PodName + "/" + p.ContainerName
+			fatal[fp] = &notify.Incident{Fingerprint: fp, Namespace: p.Namespace, Name: name, IssueType: p.Type, Summary: p.Message}
+		}
+		for fp, inc := range fatal {
+			if open[fp] != nil {
+				continue
+			}
+			if err := incidents.Open(ctx, inc); err != nil {
+				errs.record(err)
+				continue
+			}
+			open[fp] = inc
+		}
+		for fp, inc := range open {
+			if fatal[fp] != nil {
+				continue
+			}
+			if err := incidents.Resolve(ctx, inc); err != nil {
+				errs.record(err)
+				continue
+			}
+			delete(open, fp)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// monitorIncidentErrors keeps incident failures out of the TUI and reports
+// them after it exits.
+type monitorIncidentErrors struct {
+	mu    sync.Mutex
+	count int
+	last  error
+}
+
+func (e *monitorIncidentErrors) record(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.count++
+	e.last = err
+}
+
+func (e *monitorIncidentErrors) report() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.count > 0 {
+		slog.Warn("Incident requests failed", "failed", e.count, "error", e.last)
+	}
+}
+
+func printProblemsToTerminal(m *monitor.Model, acks *ack.List) {
+	all, events, stats := m.GetState()
+	problems, accepted := monitor.SplitAcknowledged(all, acks, time.Now())
 
 	printlnOut("\n═══════════════════════════════════════════════════════════════")
 	printlnOut("kubenow monitor - Current State (COPYABLE)")
@@ -191,6 +415,9 @@ func printProblemsToTerminal(m *monitor.Model) {
 			if problem.ContainerName != "" {
 				printfOut("    Container: %s\n", problem.ContainerName)
 			}
+			if problem.SubReason != "" {
+				printfOut("    Reason: %s\n", problem.SubReason)
+			}
 			printfOut("    Message: %s\n", problem.Message)
 			if problem.Count > 1 {
 				printfOut("    Count: %d occurrences\n", problem.Count)
@@ -211,6 +438,13 @@ func printProblemsToTerminal(m *monitor.Model) {
 		}
 	}
 
+	if len(accepted) > 0 {
+		printfOut("\n✓ KNOWN ACCEPTED PROBLEMS (%d)\n", len(accepted))
+		for i := range accepted {
+			printfOut("  %s  %s — %s\n", accepted[i].Location(), accepted[i].Type, accepted[i].Note())
+		}
+	}
+
 	// Print recent events (last 10)
 	if len(events) > 0 {
 		printlnOut("\n📊 RECENT EVENTS (last 5m)")
@@ -247,10 +481,11 @@ func printProblemsToTerminal(m *monitor.Model) {
 	printlnOut("═══════════════════════════════════════════════════════════════")
 }
 
-func exportProblems(m *monitor.Model) error {
-	problems, _, stats := m.GetState()
+func exportProblems(m *monitor.Model, acks *ack.List) error {
+	all, _, stats := m.GetState()
+	problems, accepted := monitor.SplitAcknowledged(all, acks, time.Now())
 
-	if len(problems) == 0 {
+	if len(problems) == 0 && len(accepted) == 0 {
 		printlnOut("\n✓ No problems to export")
 		return nil
 	}
@@ -259,13 +494,11 @@ func exportProblems(m *monitor.Model) error {
 	filename := fmt.Sprintf("kubenow-problems-%s.txt", time.Now().Format("20060102-150405"))
 
 	// Open file
-	f, err := os.Create(filename)
+	f, err := cleanup.Create(filename, 0o644)
 	if err != nil {
 		return fmt.Errorf("failed to create export file: %w", err)
 	}
-	defer func() {
-		closeBestEffort(f)
-	}()
+	defer f.Discard()
 
 	var writeErr error
 	writef := func(format string, args ...any) {
@@ -278,8 +511,12 @@ func exportProblems(m *monitor.Model) error {
 	// Write header
 	writef("kubenow monitor - Problem Export\n")
 	writef("Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	writef("Cluster: %d namespaces, %d pods, %d nodes\n", countNamespaces(problems), stats.TotalPods, stats.TotalNodes)
+	writef("Cluster: %d namespaces, %d pods, %d nodes\n", countNamespaces(all), stats.TotalPods, stats.TotalNodes)
+	writef("Active problems: %d  |  Known accepted: %d\n", len(problems), len(accepted))
 	writef("═══════════════════════════════════════════════════════════════\n\n")
+	if len(problems) == 0 {
+		writef("No active problems.\n\n")
+	}
 
 	// Write problems
 	for i := range problems {
@@ -290,6 +527,9 @@ func exportProblems(m *monitor.Model) error {
 		if problem.ContainerName != "" {
 			writef("  Container: %s\n", problem.ContainerName)
 		}
+		if problem.SubReason != "" {
+			writef("  Reason: %s\n", problem.SubReason)
+		}
 		writef("  Message: %s\n", problem.Message)
 		writef("  First seen: %s ago\n", formatDuration(time.Since(problem.FirstSeen)))
 		writef("  Last seen: %s ago\n", formatDuration(time.Since(problem.LastSeen)))
@@ -311,11 +551,23 @@ func exportProblems(m *monitor.Model) error {
 		}
 	}
 
+	if len(accepted) > 0 {
+		writef("═══════════════════════════════════════════════════════════════\n")
+		writef("KNOWN ACCEPTED PROBLEMS (%d) - already triaged, not active\n\n", len(accepted))
+		for i := range accepted {
+			writef("  %s  %s\n", accepted[i].Location(), accepted[i].Type)
+			writef("    Accepted: %s\n", accepted[i].Note())
+		}
+	}
+
+	if writeErr == nil {
+		writeErr = f.Commit()
+	}
 	if writeErr != nil {
 		return fmt.Errorf("failed to write export file: %w", writeErr)
 	}
 
-	printfOut("\n✓ Exported %d problems to: %s\n", len(problems), filename)
+	printfOut("\n✓ Exported %d problems (%d known accepted) to: %s\n", len(problems), len(accepted), filename)
 	printlnOut("  You can now copy pod names and commands from this file.")
 	return nil
 }
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// kubectlPluginPrefix starts the name of every kubectl plugin binary;
+// kubectl runs kubectl-now for `kubectl now`.
+const kubectlPluginPrefix = "kubectl-"
+
+// asKubectlPlugin is true when the binary runs as a kubectl plugin.
+var asKubectlPlugin = isKubectlPlugin(os.Args[0])
+
+func isKubectlPlugin(arg0 string) bool {
+	name := strings.TrimSuffix(filepath.Base(arg0), ".exe")
+	return strings.HasPrefix(name, kubectlPluginPrefix)
+}
+
+// configureKubectlPlugin makes help and the namespace default match
+// kubectl when running as a plugin.
+func configureKubectlPlugin() {
+	if !asKubectlPlugin {
+		return
+	}
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	rootCmd.Annotations = map[string]string{
+		cobra.CommandDisplayNameAnnotation: "kubectl " + strings.TrimPrefix(name, kubectlPluginPrefix),
+	}
+	rootCmd.PersistentFlags().Lookup("namespace").Usage =
+		"kubernetes namespace to analyze (default is the current context's namespace; -A for all namespaces)"
+}
+
+// resolvePluginNamespace defaults the namespace to the current context's,
+// as kubectl does, when running as a plugin without -n or -A. Commands
+// that never reach a cluster run without a kubeconfig, so a kubeconfig
+// error leaves all namespaces selected.
+func resolvePluginNamespace() {
+	if !asKubectlPlugin || allNamespaces || GetNamespace() != "" {
+		return
+	}
+	ns, err := util.ContextNamespace(GetKubeOpts())
+	if err != nil {
+		slog.Debug("Context namespace unavailable; using all namespaces", "error", err)
+		return
+	}
+	namespace = ns
+}
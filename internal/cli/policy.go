@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/policy"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var policyLintConfig struct {
+	output string
+	strict bool
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Check admin policy files",
+	Long: `Check kubenow admin policy files before pro-monitor reads them.
+
+The policy file is resolved like pro-monitor does: the given path, else
+$KUBENOW_POLICY, else /etc/kubenow/policy.yaml.`,
+}
+
+var policyLintCmd = &cobra.Command{
+	Use:   "lint [file]",
+	Short: "Validate a policy file against its schema and for consistency",
+	Long: `Validate an admin policy file and print every problem with its line and a
+hint to fix it.
+
+Checks:
+  - YAML syntax
+  - The published schema (kubenow policy schema): known fields, types,
+    enums, ranges, duration formats, namespace names
+  - The rules pro-monitor enforces at load time (audit path and backend
+    when apply is enabled, resource ratio rules)
+  - Consistency: min_latch_duration > 0 and not above max_latch_age,
+    namespaces both denied and allowed or listed twice, apply enabled
+    with the global switch off or with unbounded deltas
+
+Exits 1 on errors, and on warnings with --strict.
+
+Examples:
+  # Lint the default policy location
+  kubenow policy lint
+
+  # Lint a file in CI, failing on warnings too
+  kubenow policy lint deploy/policy.yaml --strict
+
+  # Machine-readable diagnostics
+  kubenow policy lint deploy/policy.yaml --output json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPolicyLint,
+}
+
+var policySchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the policy file JSON Schema",
+	Long: `Print the JSON Schema that kubenow policy lint validates against, for
+editor integration (e.g. the YAML language server's yaml.schemas setting).`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		stdoutf("%s", policy.Schema)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyLintCmd)
+	policyCmd.AddCommand(policySchemaCmd)
+
+	policyLintCmd.Flags().StringVar(&policyLintConfig.output, "output", "text", "Output format: text|json")
+	policyLintCmd.Flags().BoolVar(&policyLintConfig.strict, "strict", false, "exit non-zero on warnings as well as errors")
+}
+
+// policyLintReport is the JSON output of policy lint.
+type policyLintReport struct {
+	File        string              `json:"file"`
+	Errors      int                 `json:"errors"`
+	Warnings    int                 `json:"warnings"`
+	Diagnostics []policy.Diagnostic `json:"diagnostics"`
+}
+
+func runPolicyLint(_ *cobra.Command, args []string) error {
+	cfg := policyLintConfig
+	if cfg.output != "text" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be text or json", cfg.output)
+	}
+
+	override := ""
+	if len(args) == 1 {
+		override = args[0]
+	}
+	path := policy.ResolvePath(override)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	report := policyLintReport{File: path, Diagnostics: policy.Lint(data)}
+	if report.Diagnostics == nil {
+		report.Diagnostics = []policy.Diagnostic{}
+	}
+	for _, d := range report.Diagnostics {
+		if d.Severity == policy.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	if cfg.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printPolicyLint(&report)
+	}
+
+	if report.Errors > 0 || (cfg.strict && report.Warnings > 0) {
+		util.Exit(util.ExitPolicyFail)
+	}
+	return nil
+}
+
+func printPolicyLint(r *policyLintReport) {
+	if len(r.Diagnostics) == 0 {
+		stdoutf("%s: OK\n", r.File)
+		return
+	}
+	for _, d := range r.Diagnostics {
+		loc := r.File
+		if d.Line > 0 {
+			loc = fmt.Sprintf("%s:%d:%d", r.File, d.Line, d.Column)
+		}
+		stdoutf("%s: %s: %s: %s\n", loc, d.Severity, d.Field, d.Message)
+		if d.Hint != "" {
+			stdoutf("    hint: %s\n", d.Hint)
+		}
+	}
+	stdoutf("\n%d error(s), %d warning(s)\n", r.Errors, r.Warnings)
+}
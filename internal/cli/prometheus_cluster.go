@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promql"
+)
+
+// clusterLabelFlagUsage is the shared help text for --prometheus-cluster-label.
+const clusterLabelFlagUsage = "Scope every query to one cluster on a shared Prometheus/Thanos/Mimir (key=value, key=auto, or auto)"
+
+// resolveClusterLabel parses --prometheus-cluster-label and returns the query
+// builder options that inject it. For auto, the value is detected from the
+// kube_node_info series of this cluster's nodes.
+func resolveClusterLabel(spec string, promConfig metrics.Config, kubeClient kubernetes.Interface, timeout time.Duration, silent bool) ([]promql.Option, error) {
+	label, err := metrics.ParseClusterLabel(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --prometheus-cluster-label: %w", err)
+	}
+	if !label.Auto {
+		return label.QueryOptions(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 5})
+	if err != nil {
+		return nil, fmt.Errorf("cluster label auto-detection: failed to list nodes: %w", err)
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		nodeNames = append(nodeNames, nodes.Items[i].Name)
+	}
+
+	provider, err := metrics.NewProvider(promConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics provider: %w", err)
+	}
+	label.Value, err = metrics.DetectClusterLabel(ctx, provider, label.Name, nodeNames)
+	if err != nil {
+		return nil, fmt.Errorf("cluster label auto-detection: %w", err)
+	}
+
+	if !silent {
+		if label.Value == "" {
+			slog.Info("Cluster label missing on this cluster's series; queries are not scoped", "label", label.Name)
+		} else {
+			slog.Info("Scoping Prometheus queries (auto-detected)", "label", label.String())
+		}
+	}
+	return label.QueryOptions(), nil
+}
@@ -1,7 +1,13 @@
 package cli
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promonitor"
 )
 
 var proMonitorCmd = &cobra.Command{
@@ -23,9 +29,30 @@ Available subcommands:
   - validate-policy: Validate an admin policy file`,
 }
 
-var policyPath string
+var (
+	policyPath string
+	shareTTL   string
+)
 
 func init() {
 	rootCmd.AddCommand(proMonitorCmd)
 	proMonitorCmd.PersistentFlags().StringVar(&policyPath, "policy", "", "path to admin policy file")
+	proMonitorCmd.PersistentFlags().StringVar(&shareTTL, "share-ttl", "7d", "lifetime of share links created with 's' in the TUI (S3 stores; max 7d)")
+}
+
+// parseShareTTL validates --share-ttl against the S3 presign limit.
+func parseShareTTL() (time.Duration, error) {
+	ttl, err := metrics.ParseDuration(shareTTL)
+	if err != nil || ttl <= 0 || ttl > promonitor.DefaultShareTTL {
+		return 0, fmt.Errorf("invalid --share-ttl %q: must be a duration between 1s and 7d", shareTTL)
+	}
+	return ttl, nil
+}
+
+// printShareLink repeats the TUI's share link after it exits, since long
+// presigned URLs wrap inside the TUI border and are awkward to copy there.
+func printShareLink(model *promonitor.Model) {
+	if r := model.ShareResult(); r != nil {
+		fmt.Printf("Evidence page (read-only): %s\n", r.URL)
+	}
 }
@@ -3,13 +3,14 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"github.com/ppiankov/kubenow/internal/exposure"
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/promonitor"
 	"github.com/ppiankov/kubenow/internal/util"
@@ -18,10 +19,11 @@ import (
 var pmAnalyzeConfig struct {
 	prometheusURL  string
 	acknowledgeHPA bool
+	latest         bool
 }
 
 var pmAnalyzeCmd = &cobra.Command{
-	Use:   "analyze <kind>/<name>",
+	Use:   "analyze [<kind>/<name>]",
 	Short: "Analyze previously collected latch data",
 	Long: `Analyze a workload using previously collected latch data from
 'pro-monitor collect' or a prior 'pro-monitor latch' session.
@@ -30,8 +32,10 @@ Loads persisted latch data from ~/.kubenow/latch/, connects to the cluster
 for current resource values, computes the recommendation, and launches the
 interactive TUI for export or apply.
 
-This is the counterpart to 'pro-monitor collect' — collect data headlessly
-(e.g., overnight via cron), then analyze interactively later.
+This is the counterpart to 'pro-monitor collect' and 'pro-monitor latch
+--detach' — collect data headlessly (e.g., overnight via cron), then analyze
+interactively later. --latest picks the most recently saved latch (in the
+namespace given with -n, or in any namespace) instead of naming a workload.
 
 Policy gates apply normally:
   - MaxLatchAge gates apply on stale data
@@ -41,45 +45,51 @@ Examples:
   # Analyze previously collected data
   kubenow pro-monitor analyze deployment/payment-api -n prod --policy ./policy.yaml
 
+  # Analyze the latch that finished last in prod
+  kubenow pro-monitor analyze --latest -n prod
+
   # Analyze with Linkerd traffic source measurement
   kubenow pro-monitor analyze deployment/payment-api -n prod --prometheus-url http://prometheus:9090`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	RunE: runAnalyze,
 }
 
 func init() {
 	proMonitorCmd.AddCommand(pmAnalyzeCmd)
-	pmAnalyzeCmd.Flags().StringVar(&pmAnalyzeConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint for Linkerd traffic metrics")
+	pmAnalyzeCmd.Flags().StringVar(&pmAnalyzeConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint for Linkerd or Istio traffic metrics")
 	pmAnalyzeCmd.Flags().BoolVar(&pmAnalyzeConfig.acknowledgeHPA, "acknowledge-hpa", false, "acknowledge HPA presence and allow apply despite HPA")
+	pmAnalyzeCmd.Flags().BoolVar(&pmAnalyzeConfig.latest, "latest", false,
+		"analyze the most recently saved latch instead of a named workload (limited to -n when given)")
 }
 
 func runAnalyze(_ *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	ref, err := promonitor.ParseWorkloadRef(args[0])
+	keys, err := promonitor.NewKeyMap(GetKeyBindings("pro-monitor"))
+	if err != nil {
+		return fmt.Errorf("invalid keybindings.pro-monitor in config: %w", err)
+	}
+	linkTTL, err := parseShareTTL()
 	if err != nil {
 		return err
 	}
 
-	ns := GetNamespace()
-	if ns == "" {
-		ns = "default"
-	}
-	ref.Namespace = ns
-
-	// Load persisted latch data
-	latch, err := promonitor.LoadLatch(*ref)
+	latch, err := loadAnalyzeLatch(args)
 	if err != nil {
-		return fmt.Errorf("no latch data found: %w\nRun 'kubenow pro-monitor collect %s -n %s' first", err, args[0], ns)
+		return err
+	}
+	ref := &latch.Workload
+	log := logging.Component("analyze")
+	if pmAnalyzeConfig.latest {
+		log.Info("Latest latch", "workload", ref.String(), "namespace", ref.Namespace, "saved", latch.Timestamp.Format(time.RFC3339))
 	}
 
-	fmt.Fprintf(os.Stderr, "[analyze] Loaded latch data: %d samples, duration %s\n",
-		latch.Data.SampleCount, latch.Duration.Truncate(1e9))
+	log.Info("Loaded latch data", "samples", latch.Data.SampleCount, "duration", latch.Duration.Truncate(time.Second))
 	if latch.PlannedDuration > 0 {
-		fmt.Fprintf(os.Stderr, "[analyze] Early-stopped: %s of planned %s\n", latch.Duration, latch.PlannedDuration)
+		log.Info("Early-stopped", "duration", latch.Duration, "planned", latch.PlannedDuration)
 	}
 	if !latch.Valid {
-		fmt.Fprintf(os.Stderr, "[analyze] WARNING: latch data is invalid: %s\n", latch.Reason)
+		log.Warn("Latch data is invalid", "reason", latch.Reason)
 	}
 
 	// Build K8s clients
@@ -102,9 +112,7 @@ func runAnalyze(_ *cobra.Command, args []string) error {
 	// Fetch current container resources
 	containers, err := promonitor.FetchContainerResources(ctx, kubeClient, ref)
 	if err != nil {
-		if IsVerbose() {
-			fmt.Fprintf(os.Stderr, "[analyze] Warning: could not read container resources: %v\n", err)
-		}
+		log.Debug("Could not read container resources", "error", err)
 	}
 
 	// Detect HPA
@@ -119,6 +127,7 @@ func runAnalyze(_ *cobra.Command, args []string) error {
 		Containers: containers,
 		Bounds:     bounds,
 		HPA:        hpa,
+		OOMHistory: promonitor.FetchOOMHistory(ctx, kubeClient, ref, promonitor.OOMHistoryWindow),
 	})
 
 	if latch.PlannedDuration > 0 {
@@ -129,6 +138,9 @@ func runAnalyze(_ *cobra.Command, args []string) error {
 
 	// Create analyze-mode TUI model (starts post-latch)
 	model := promonitor.NewAnalyzeModel(*ref, mode, policyMsg, hpa, rec, latch)
+	model.SetKeyMap(keys)
+	model.SetShareTTL(linkTTL)
+	model.SetVersion(version)
 	model.SetContainers(containers)
 	if bounds != nil {
 		model.SetPolicyBounds(bounds)
@@ -144,12 +156,12 @@ func runAnalyze(_ *cobra.Command, args []string) error {
 		model.SetPolicy(bounds)
 	}
 
-	// Wire exposure map (+ optional Linkerd traffic)
+	// Wire exposure map (+ optional mesh traffic)
 	exposureCollector := exposure.NewExposureCollector(kubeClient, metricsClient)
 	if pmAnalyzeConfig.prometheusURL != "" {
 		promClient, err := metrics.NewPrometheusClient(metrics.Config{PrometheusURL: pmAnalyzeConfig.prometheusURL})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[analyze] Warning: could not connect to Prometheus: %v\n", err)
+			log.Warn("Could not connect to Prometheus", "error", err)
 		} else {
 			exposureCollector.SetPrometheusAPI(promClient.GetAPI())
 		}
@@ -176,5 +188,40 @@ func runAnalyze(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("tui error: %w", err)
 	}
 
+	printShareLink(&model)
 	return nil
 }
+
+// loadAnalyzeLatch loads the latch named by args, or the latest one with
+// --latest.
+func loadAnalyzeLatch(args []string) (*promonitor.LatchResult, error) {
+	if pmAnalyzeConfig.latest {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("--latest cannot be combined with a workload")
+		}
+		latch, err := promonitor.LatestLatch(GetNamespace())
+		if err != nil {
+			return nil, fmt.Errorf("no latch data found: %w\nRun 'kubenow pro-monitor collect' or 'pro-monitor latch --detach' first", err)
+		}
+		return latch, nil
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("requires a workload (<kind>/<name>) or --latest")
+	}
+
+	ref, err := promonitor.ParseWorkloadRef(args[0])
+	if err != nil {
+		return nil, err
+	}
+	ns := GetNamespace()
+	if ns == "" {
+		ns = "default"
+	}
+	ref.Namespace = ns
+
+	latch, err := promonitor.LoadLatch(*ref)
+	if err != nil {
+		return nil, fmt.Errorf("no latch data found: %w\nRun 'kubenow pro-monitor collect %s -n %s' first", err, args[0], ns)
+	}
+	return latch, nil
+}
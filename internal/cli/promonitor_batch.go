@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promonitor"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var batchConfig struct {
+	selector     string
+	duration     string
+	interval     string
+	maxWorkloads int
+	outputDir    string
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Latch and recommend every workload matching a label selector",
+	Long: `Latch all Deployments, StatefulSets, and DaemonSets matching a label selector
+at once and emit one consolidated recommendation report. Non-interactive:
+progress goes to stderr and nothing is applied.
+
+A single sampler polls the Metrics API for every selected namespace, so the
+workloads are latched concurrently for the same window. Each latch is saved
+to the artifact store like 'pro-monitor collect', so individual workloads can
+be reviewed later with 'pro-monitor analyze' or 'pro-monitor export'.
+
+Recommendations respect the admin policy's bounds (max request/limit delta,
+minimum safety rating, limit ratios). Workloads without usable samples or
+with an UNSAFE rating are listed as skipped with the reason.
+
+Without -n, workloads are selected across all namespaces.
+
+Output:
+  - JSON report on stdout, with each workload's SSA patch inline
+  - --output-dir DIR: report.json plus one patch per workload in DIR/patches/
+
+Examples:
+  # Latch all payments workloads for 30 minutes
+  kubenow pro-monitor batch --selector app.kubernetes.io/part-of=payments --duration 30m
+
+  # Write the report and patches for review, bounded by policy
+  kubenow pro-monitor batch --selector team=checkout -n prod --policy ./policy.yaml --output-dir ./batch-out`,
+	Args: cobra.NoArgs,
+	RunE: runBatch,
+}
+
+func init() {
+	proMonitorCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringVarP(&batchConfig.selector, "selector", "l", "", "label selector for the workloads to latch (required)")
+	batchCmd.Flags().StringVar(&batchConfig.duration, "duration", "15m", "latch duration (e.g., 15m, 1h, 8h)")
+	batchCmd.Flags().StringVar(&batchConfig.interval, "interval", "5s", "sample interval (e.g., 1s, 5s)")
+	batchCmd.Flags().IntVar(&batchConfig.maxWorkloads, "max-workloads", 100, "refuse selectors matching more workloads than this")
+	batchCmd.Flags().StringVar(&batchConfig.outputDir, "output-dir", "", "write report.json and per-workload patches to this directory")
+}
+
+func runBatch(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if batchConfig.selector == "" {
+		return fmt.Errorf("--selector is required")
+	}
+	duration, err := time.ParseDuration(batchConfig.duration)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", batchConfig.duration, err)
+	}
+	interval, err := time.ParseDuration(batchConfig.interval)
+	if err != nil {
+		return fmt.Errorf("invalid interval %q: %w", batchConfig.interval, err)
+	}
+
+	opts := GetKubeOpts()
+	kubeClient, err := util.BuildKubeClientWithOpts(opts)
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+	restConfig, err := util.BuildRestConfigWithOpts(opts)
+	if err != nil {
+		return fmt.Errorf("failed to build REST config: %w", err)
+	}
+	metricsClient, err := metricsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build metrics client: %w", err)
+	}
+
+	ns := GetNamespace()
+	targets, err := promonitor.SelectWorkloads(ctx, kubeClient, ns, batchConfig.selector)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no deployments, statefulsets, or daemonsets match %q", batchConfig.selector)
+	}
+	if batchConfig.maxWorkloads > 0 && len(targets) > batchConfig.maxWorkloads {
+		return fmt.Errorf("%d workloads match %q, more than --max-workloads %d", len(targets), batchConfig.selector, batchConfig.maxWorkloads)
+	}
+
+	namespaces := promonitor.BatchNamespaces(targets)
+	err = promonitor.CheckMetricsServer(ctx, metricsClient, namespaces[0])
+	if err != nil {
+		return fmt.Errorf("metrics-server required for batch: %w", err)
+	}
+
+	log := logging.Component("batch")
+	log.Info("Workloads match selector", "selector", batchConfig.selector, "workloads", len(targets), "namespaces", len(namespaces),
+		"duration", duration, "interval", interval)
+
+	latches, err := runBatchLatch(ctx, log, kubeClient, opts, targets, namespaces, duration, interval)
+	if err != nil {
+		return err
+	}
+
+	// The mode message is reported once; with namespace overrides each
+	// namespace gets its own bounds.
+	_, policyMsg, bounds, loadedPolicy := resolveMode(policyPath, &promonitor.WorkloadRef{Namespace: namespaces[0]})
+	if loadedPolicy != nil && len(loadedPolicy.Overrides) > 0 {
+		nsBounds := map[string]*promonitor.PolicyBounds{}
+		for _, ns := range namespaces {
+			_, _, nsBounds[ns], _ = resolveMode(policyPath, &promonitor.WorkloadRef{Namespace: ns})
+		}
+		for i := range targets {
+			targets[i].Bounds = nsBounds[targets[i].Ref.Namespace]
+		}
+	}
+	entries, summary := promonitor.RecommendBatch(targets, latches, bounds)
+	report := &promonitor.BatchReport{
+		Timestamp: time.Now(),
+		Selector:  batchConfig.selector,
+		Namespace: ns,
+		Duration:  duration,
+		Interval:  interval,
+		Policy:    policyMsg,
+		Summary:   summary,
+		Workloads: entries,
+	}
+
+	log.Info("Batch complete", "recommended", summary.Recommended, "skipped", summary.Skipped,
+		"cpu_request_delta_cores", summary.CPURequestDelta, "memory_request_delta_mib", summary.MemoryRequestDelta/(1024*1024))
+
+	if batchConfig.outputDir != "" {
+		return writeBatchDirectory(log, report, batchConfig.outputDir)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch report: %w", err)
+	}
+	printOut(string(data) + "\n")
+	return nil
+}
+
+// runBatchLatch samples every target namespace with one latch monitor and
+// saves a latch result per target. SIGINT stops sampling early and keeps
+// what was collected.
+func runBatchLatch(
+	ctx context.Context, log *slog.Logger, kubeClient *kubernetes.Clientset, opts util.KubeOpts, targets []promonitor.BatchTarget,
+	namespaces []string, duration, interval time.Duration,
+) (map[promonitor.WorkloadRef]*promonitor.LatchResult, error) {
+	latchMon, err := metrics.NewLatchMonitor(kubeClient, metrics.LatchConfig{
+		SampleInterval: interval,
+		Duration:       duration,
+		Namespaces:     namespaces,
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create latch monitor: %w", err)
+	}
+
+	latchCtx, latchCancel := context.WithCancel(ctx)
+	defer latchCancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer cleanup.Graceful()()
+	startTime := time.Now()
+	var earlyStop bool
+	go func() {
+		if _, ok := <-sigCh; !ok {
+			return
+		}
+		log.Info("Received interrupt — stopping latch and building the report...")
+		earlyStop = true
+		latchMon.Stop()
+	}()
+
+	latchErr := latchMon.Start(latchCtx)
+	signal.Stop(sigCh)
+	close(sigCh)
+	if latchErr != nil && latchErr != context.Canceled {
+		return nil, fmt.Errorf("latch error: %w", latchErr)
+	}
+
+	effectiveDuration := duration
+	if earlyStop {
+		effectiveDuration = time.Since(startTime)
+	}
+	latches := make(map[promonitor.WorkloadRef]*promonitor.LatchResult, len(targets))
+	for _, t := range targets {
+		data := latchMon.GetWorkloadSpikeData(t.Ref.Namespace, t.Ref.Name)
+		result := promonitor.BuildLatchResult(t.Ref, data, effectiveDuration, interval)
+		if earlyStop {
+			result.PlannedDuration = duration
+		}
+		if err := promonitor.SaveLatch(result); err != nil {
+			log.Warn("Failed to save latch", "workload", t.Ref.FullString(), "error", err)
+		}
+		latches[t.Ref] = result
+	}
+	return latches, nil
+}
+
+// writeBatchDirectory writes report.json and one patch file per
+// recommended workload under dir/patches.
+func writeBatchDirectory(log *slog.Logger, report *promonitor.BatchReport, dir string) error {
+	patchDir := filepath.Join(dir, "patches")
+	if err := os.MkdirAll(patchDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", patchDir, err)
+	}
+
+	for i := range report.Workloads {
+		entry := &report.Workloads[i]
+		if entry.Patch == "" {
+			continue
+		}
+		name := filepath.Join("patches", promonitor.BatchPatchFilename(entry.Workload))
+		if err := cleanup.WriteFile(filepath.Join(dir, name), []byte(entry.Patch), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		entry.PatchFile = name
+		entry.Patch = ""
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch report: %w", err)
+	}
+	reportPath := filepath.Join(dir, "report.json")
+	if err := cleanup.WriteFile(reportPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", reportPath, err)
+	}
+
+	log.Info("Report and patches written", "path", dir, "patches", report.Summary.Recommended)
+	return nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,15 +12,21 @@ import (
 	"github.com/spf13/cobra"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/promonitor"
 	"github.com/ppiankov/kubenow/internal/util"
 )
 
 var collectConfig struct {
-	duration string
-	interval string
-	output   string
+	duration       string
+	interval       string
+	output         string
+	remoteWriteURL string
+	metricsFile    string
+	exportLabels   []string
+	networkSource  string
 }
 
 var collectCmd = &cobra.Command{
@@ -32,7 +39,9 @@ instead of launching the interactive TUI. Designed for background collection
 (e.g., cron jobs, CI pipelines, overnight runs).
 
 On completion (or SIGINT), saves the latch data to ~/.kubenow/latch/ for
-later analysis with 'pro-monitor analyze' or 'pro-monitor export'.
+later analysis with 'pro-monitor analyze' or 'pro-monitor export'. The
+samples are checkpointed every minute; if the process is killed, continue
+with 'pro-monitor resume'.
 
 Examples:
   # Collect 8 hours of samples overnight
@@ -42,7 +51,14 @@ Examples:
   kubenow pro-monitor collect deployment/api-server -n prod --duration 2h --interval 1s
 
   # Collect and save to a specific path
-  kubenow pro-monitor collect statefulset/postgres -n databases --duration 4h --output /tmp/latch.json`,
+  kubenow pro-monitor collect statefulset/postgres -n databases --duration 4h --output /tmp/latch.json
+
+  # Keep the raw samples in the central TSDB via remote_write
+  kubenow pro-monitor collect deployment/payment-api -n prod --duration 8h \
+    --remote-write-url http://mimir:9009/api/v1/push --export-label cluster=prod-eu
+
+  # Air-gapped: write an OpenMetrics file to backfill later with promtool
+  kubenow pro-monitor collect deployment/payment-api -n prod --duration 8h --openmetrics-file latch.om`,
 	Args: cobra.ExactArgs(1),
 	RunE: runCollect,
 }
@@ -52,6 +68,14 @@ func init() {
 	collectCmd.Flags().StringVar(&collectConfig.duration, "duration", "15m", "collection duration (e.g., 15m, 1h, 8h)")
 	collectCmd.Flags().StringVar(&collectConfig.interval, "interval", "5s", "sample interval (e.g., 1s, 5s)")
 	collectCmd.Flags().StringVar(&collectConfig.output, "output", "", "override output path (default: ~/.kubenow/latch/)")
+	collectCmd.Flags().StringVar(&collectConfig.remoteWriteURL, "remote-write-url", "",
+		"push samples to a Prometheus remote_write endpoint every minute (e.g., http://prometheus:9090/api/v1/write)")
+	collectCmd.Flags().StringVar(&collectConfig.metricsFile, "openmetrics-file", "",
+		"write timestamped samples to an OpenMetrics file (backfill with: promtool tsdb create-blocks-from openmetrics)")
+	collectCmd.Flags().StringArrayVar(&collectConfig.exportLabels, "export-label", nil,
+		"extra label on exported samples: key=value (repeatable, e.g. cluster=prod-eu)")
+	collectCmd.Flags().StringVar(&collectConfig.networkSource, "network-source", networkSourceKubelet,
+		"Network RX/TX sampling: kubelet (summary API, needs nodes/proxy) or none")
 }
 
 func runCollect(_ *cobra.Command, args []string) error {
@@ -78,8 +102,13 @@ func runCollect(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid interval %q: %w", collectConfig.interval, err)
 	}
 
-	fmt.Fprintf(os.Stderr, "[collect] Target: %s in namespace %s\n", ref.String(), ref.Namespace)
-	fmt.Fprintf(os.Stderr, "[collect] Duration: %s, Interval: %s\n", duration, interval)
+	exporter, err := newLatchExporter(collectConfig.remoteWriteURL, collectConfig.metricsFile, collectConfig.exportLabels)
+	if err != nil {
+		return err
+	}
+
+	log := logging.Component("collect")
+	log.Info("Target", "workload", ref.String(), "namespace", ref.Namespace, "duration", duration, "interval", interval)
 
 	// Build K8s clients
 	opts := GetKubeOpts()
@@ -108,16 +137,23 @@ func runCollect(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("metrics-server required for collect: %w", err)
 	}
 
-	// Create latch monitor with stderr progress
+	networkSampler, err := latchNetworkSampler(collectConfig.networkSource, kubeClient, "")
+	if err != nil {
+		return err
+	}
+
+	// Create latch monitor logging its progress
 	latchMon, err := metrics.NewLatchMonitor(kubeClient, metrics.LatchConfig{
 		SampleInterval: interval,
 		Duration:       duration,
 		Namespaces:     []string{ref.Namespace},
 		WorkloadFilter: ref.Name,
 		PodLevel:       ref.Kind == "Pod",
-		ProgressFunc: func(msg string) {
-			fmt.Fprintf(os.Stderr, "%s\n", msg)
-		},
+		Recorder:       exporter.sampleRecorder(),
+		Network:        networkSampler,
+		Checkpoint: latchCheckpointer(*ref, time.Now(), duration, interval, func(err error) {
+			log.Warn("Checkpoint failed, this collection cannot be resumed", "error", err)
+		}),
 	}, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create latch monitor: %w", err)
@@ -129,34 +165,49 @@ func runCollect(_ *cobra.Command, args []string) error {
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer cleanup.Graceful()()
 
 	startTime := time.Now()
 	var earlyStop bool
 
 	go func() {
 		<-sigCh
-		fmt.Fprintf(os.Stderr, "\n[collect] Received interrupt — stopping collection and saving data...\n")
+		log.Info("Received interrupt — stopping collection and saving data...")
 		earlyStop = true
 		latchMon.Stop()
 	}()
 
-	fmt.Fprintf(os.Stderr, "[collect] Starting collection...\n")
+	log.Info("Starting collection...")
+	exportCtx, exportCancel := context.WithCancel(ctx)
+	exporter.start(exportCtx, log)
 	latchErr := latchMon.Start(latchCtx)
+	exportCancel()
 	signal.Stop(sigCh)
 
 	if latchErr != nil && latchErr != context.Canceled {
 		return fmt.Errorf("latch error: %w", latchErr)
 	}
 
-	// Build and persist latch result
+	if err := saveHeadlessLatch(log, *ref, latchMon, duration, time.Since(startTime), interval, earlyStop); err != nil {
+		return err
+	}
+	return exporter.finish(ctx, log)
+}
+
+// saveHeadlessLatch persists the result of a headless latch that sampled
+// for actualDuration of the planned duration, drops its checkpoint, and
+// logs the outcome.
+func saveHeadlessLatch(
+	log *slog.Logger, ref promonitor.WorkloadRef, latchMon *metrics.LatchMonitor,
+	duration, actualDuration, interval time.Duration, earlyStop bool,
+) error {
 	data := latchMon.GetWorkloadSpikeData(ref.Namespace, ref.Name)
 	effectiveDuration := duration
-	actualDuration := time.Since(startTime)
 	if earlyStop {
 		effectiveDuration = actualDuration
 	}
 
-	latchResult := promonitor.BuildLatchResult(*ref, data, effectiveDuration, interval)
+	latchResult := promonitor.BuildLatchResult(ref, data, effectiveDuration, interval)
 	if earlyStop {
 		latchResult.PlannedDuration = duration
 	}
@@ -164,24 +215,25 @@ func runCollect(_ *cobra.Command, args []string) error {
 	if err := promonitor.SaveLatch(latchResult); err != nil {
 		return fmt.Errorf("failed to save latch data: %w", err)
 	}
+	if err := promonitor.DeleteLatchSession(ref); err != nil {
+		log.Warn("Could not remove latch checkpoint", "error", err)
+	}
 
 	// Report
 	sampleCount := 0
 	if data != nil {
 		sampleCount = data.SampleCount
 	}
-	fmt.Fprintf(os.Stderr, "[collect] Collection complete: %d samples in %s\n", sampleCount, actualDuration.Truncate(time.Second))
+	log.Info("Collection complete", "samples", sampleCount, "duration", actualDuration.Truncate(time.Second))
 	if earlyStop {
-		fmt.Fprintf(os.Stderr, "[collect] Early stop: collected %s of planned %s (%.0f%%)\n",
-			actualDuration.Truncate(time.Second), duration,
-			float64(actualDuration)/float64(duration)*100)
+		log.Info("Early stop", "duration", actualDuration.Truncate(time.Second), "planned", duration,
+			"percent", int(float64(actualDuration)/float64(duration)*100))
 	}
 	if !latchResult.Valid {
-		fmt.Fprintf(os.Stderr, "[collect] WARNING: latch data is invalid: %s\n", latchResult.Reason)
+		log.Warn("Latch data is invalid", "reason", latchResult.Reason)
 	}
 
-	path := promonitor.LatchFilePath(*ref)
-	fmt.Fprintf(os.Stderr, "[collect] Saved to %s\n", path)
-
+	path := promonitor.LatchFilePath(ref)
+	log.Info("Latch saved", "path", path)
 	return nil
 }
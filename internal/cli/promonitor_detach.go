@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/promonitor"
+)
+
+// detachLatch runs the latch as a background 'pro-monitor collect' process
+// that outlives the terminal. Its progress goes to a log file and its
+// result to the latch store, for 'pro-monitor analyze --latest'.
+func detachLatch(cmd *cobra.Command, ref *promonitor.WorkloadRef, duration, interval time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate the kubenow binary: %w", err)
+	}
+
+	// collect samples network from the kubelet only
+	networkSource := networkSourceKubelet
+	switch latchConfig.networkSource {
+	case networkSourceNone:
+		networkSource = networkSourceNone
+	case networkSourcePrometheus:
+		logging.Component("pro-monitor").Info("Detached latches sample network from the kubelet, not Prometheus")
+	}
+
+	args := []string{
+		"pro-monitor", "collect", ref.String(),
+		"--namespace", ref.Namespace,
+		"--duration", duration.String(),
+		"--interval", interval.String(),
+		"--network-source", networkSource,
+	}
+	args = append(args, inheritedFlagArgs(cmd)...)
+
+	logPath, err := detachLogPath(*ref, time.Now())
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open latch log: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	child := exec.Command(exe, args...) //nolint:gosec // re-executes this binary with validated arguments
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = detachSysProcAttr()
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start detached latch: %w", err)
+	}
+	pid := child.Process.Pid
+	if err := child.Process.Release(); err != nil {
+		return fmt.Errorf("failed to detach latch: %w", err)
+	}
+
+	fmt.Printf("Latch of %s in %s running in the background (pid %d) for %s\n", ref.String(), ref.Namespace, pid, duration)
+	fmt.Printf("Log: %s\n", logPath)
+	fmt.Printf("When it finishes: kubenow pro-monitor analyze --latest -n %s\n", ref.Namespace)
+	fmt.Printf("If it is killed:  kubenow pro-monitor resume %s -n %s\n", ref.String(), ref.Namespace)
+	return nil
+}
+
+// inheritedFlagArgs returns the global and pro-monitor flags set on the
+// command line, so the detached process uses the same cluster, store, and
+// policy. --namespace is passed separately.
+func inheritedFlagArgs(cmd *cobra.Command) []string {
+	var args []string
+	cmd.InheritedFlags().Visit(func(f *pflag.Flag) {
+		if f.Name == "namespace" {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				args = append(args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return args
+}
+
+// detachLogPath returns ~/.kubenow/logs/<namespace>__<kind>__<name>-<time>.log,
+// creating the directory.
+func detachLogPath(ref promonitor.WorkloadRef, now time.Time) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".kubenow", "logs")
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+	name := fmt.Sprintf("%s__%s__%s-%s.log", ref.Namespace, ref.Kind, ref.Name, now.UTC().Format("20060102T150405Z"))
+	return filepath.Join(dir, name), nil
+}
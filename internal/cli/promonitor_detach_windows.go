@@ -0,0 +1,14 @@
+//go:build windows
+
+package cli
+
+import "syscall"
+
+// detachedProcess is DETACHED_PROCESS: the child gets no console.
+const detachedProcess = 0x00000008
+
+// detachSysProcAttr starts the child without a console in its own process
+// group, so it survives the console closing.
+func detachSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/promonitor"
 	"github.com/ppiankov/kubenow/internal/util"
 )
@@ -37,6 +40,8 @@ Formats:
   json       - Machine-readable AlignmentRecommendation JSON
   kustomize  - Kustomize overlay (kustomization.yaml + strategic merge patch)
   helm       - Helm values.yaml fragment with resource overrides
+  auto       - helm or kustomize when the workload's labels/annotations show it is
+               rendered by Helm (incl. Flux HelmRelease) or kustomize, else patch
 
 Export is always available regardless of admin policy.
 
@@ -57,14 +62,17 @@ Examples:
   kubenow pro-monitor export deployment/payment-api --format kustomize -o patches/
 
   # Export Helm values override
-  kubenow pro-monitor export deployment/payment-api --format helm -o values-override.yaml`,
+  kubenow pro-monitor export deployment/payment-api --format helm -o values-override.yaml
+
+  # Pick the format matching how the workload is deployed
+  kubenow pro-monitor export deployment/payment-api --format auto -o gitops/`,
 	Args: cobra.ExactArgs(1),
 	RunE: runExport,
 }
 
 func init() {
 	proMonitorCmd.AddCommand(exportCmd)
-	exportCmd.Flags().StringVar(&exportConfig.format, "format", "patch", "output format (patch, manifest, diff, json, kustomize, helm)")
+	exportCmd.Flags().StringVar(&exportConfig.format, "format", "patch", "output format (patch, manifest, diff, json, kustomize, helm, auto)")
 	exportCmd.Flags().StringVarP(&exportConfig.output, "output", "o", "", "write to file instead of stdout")
 }
 
@@ -103,17 +111,33 @@ func runExport(_ *cobra.Command, args []string) error {
 	rec := promonitor.Recommend(&promonitor.RecommendInput{
 		Latch:      latch,
 		Containers: containers,
+		OOMHistory: promonitor.FetchOOMHistory(ctx, kubeClient, ref, promonitor.OOMHistoryWindow),
 	})
 
+	log := logging.Component("export")
 	if len(rec.Containers) == 0 {
-		fmt.Fprintf(os.Stderr, "No actionable recommendation produced.\n")
+		log.Info("No actionable recommendation produced.")
 		for _, w := range rec.Warnings {
-			fmt.Fprintf(os.Stderr, "  %s\n", w)
+			log.Info("Recommendation skipped", "reason", w)
 		}
 		return nil
 	}
 
-	format := promonitor.ExportFormat(exportConfig.format)
+	// Detect Helm/kustomize so exports can target the GitOps repo (best-effort)
+	rec.Source, err = promonitor.FetchGitOpsSource(ctx, kubeClient, ref)
+	if err != nil {
+		log.Warn("GitOps source detection failed", "error", err)
+	}
+
+	format := promonitor.ResolveFormat(promonitor.ExportFormat(exportConfig.format), rec.Source)
+	if rec.Source != nil {
+		if exportConfig.format == string(promonitor.FormatAuto) {
+			log.Info("Detected GitOps source", "source", rec.Source.String(), "format", string(format))
+		} else if format == promonitor.FormatPatch {
+			log.Info("Workload is managed by GitOps; use --format auto to export for the GitOps repo",
+				"workload", ref.String(), "source", rec.Source.String())
+		}
+	}
 
 	// For manifest format, fetch the full workload object
 	var currentJSON []byte
@@ -132,12 +156,12 @@ func runExport(_ *cobra.Command, args []string) error {
 	// Write output
 	if exportConfig.output != "" {
 		if format == promonitor.FormatKustomize && isDirectoryPath(exportConfig.output) {
-			return writeKustomizeDirectory(output, exportConfig.output, ref)
+			return writeKustomizeDirectory(log, output, exportConfig.output, ref)
 		}
-		if err := os.WriteFile(exportConfig.output, []byte(output), 0o600); err != nil {
+		if err := cleanup.WriteFile(exportConfig.output, []byte(output), 0o600); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "Written to %s\n", exportConfig.output)
+		log.Info("Export written", "path", exportConfig.output)
 	} else {
 		fmt.Print(output)
 	}
@@ -155,7 +179,7 @@ func isDirectoryPath(path string) bool {
 }
 
 // writeKustomizeDirectory splits kustomize output into separate files in a directory.
-func writeKustomizeDirectory(output, dir string, ref *promonitor.WorkloadRef) error {
+func writeKustomizeDirectory(log *slog.Logger, output, dir string, ref *promonitor.WorkloadRef) error {
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
@@ -163,16 +187,16 @@ func writeKustomizeDirectory(output, dir string, ref *promonitor.WorkloadRef) er
 	kustomization, patch, patchFilename := promonitor.SplitKustomizeOutput(output, *ref)
 
 	kustomizationPath := filepath.Join(dir, "kustomization.yaml")
-	if err := os.WriteFile(kustomizationPath, []byte(kustomization), 0o600); err != nil {
+	if err := cleanup.WriteFile(kustomizationPath, []byte(kustomization), 0o600); err != nil {
 		return fmt.Errorf("failed to write %s: %w", kustomizationPath, err)
 	}
 
 	patchPath := filepath.Join(dir, patchFilename)
-	if err := os.WriteFile(patchPath, []byte(patch), 0o600); err != nil {
+	if err := cleanup.WriteFile(patchPath, []byte(patch), 0o600); err != nil {
 		return fmt.Errorf("failed to write %s: %w", patchPath, err)
 	}
 
-	fmt.Fprintf(os.Stderr, "Written to %s:\n  %s\n  %s\n", dir, "kustomization.yaml", patchFilename)
+	log.Info("Export written", "path", dir, "files", "kustomization.yaml,"+patchFilename)
 	return nil
 }
 
@@ -3,14 +3,15 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"github.com/ppiankov/kubenow/internal/exposure"
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/policy"
 	"github.com/ppiankov/kubenow/internal/promonitor"
@@ -27,6 +28,8 @@ var latchConfig struct {
 	k8sLocalPort       string
 	k8sRemotePort      string
 	portforwardTimeout string
+	networkSource      string
+	detach             bool
 }
 
 var latchCmd = &cobra.Command{
@@ -58,7 +61,11 @@ Examples:
   kubenow pro-monitor latch statefulset/postgres -n databases --duration 30m
 
   # Latch with Linkerd traffic source measurement
-  kubenow pro-monitor latch deployment/payment-api -n prod --prometheus-url http://prometheus:9090`,
+  kubenow pro-monitor latch deployment/payment-api -n prod --prometheus-url http://prometheus:9090
+
+  # Latch for 24 hours in the background, then review the result
+  kubenow pro-monitor latch deployment/payment-api -n prod --duration 24h --detach
+  kubenow pro-monitor analyze --latest -n prod`,
 	Args: cobra.ExactArgs(1),
 	RunE: runLatch,
 }
@@ -68,7 +75,10 @@ func init() {
 	latchCmd.Flags().StringVar(&latchConfig.duration, "duration", "15m", "latch duration (e.g., 15m, 1h, 24h)")
 	latchCmd.Flags().StringVar(&latchConfig.interval, "interval", "5s", "sample interval (e.g., 1s, 5s)")
 	latchCmd.Flags().BoolVar(&latchConfig.acknowledgeHPA, "acknowledge-hpa", false, "acknowledge HPA presence and allow apply despite HPA")
-	latchCmd.Flags().StringVar(&latchConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint for Linkerd traffic metrics (e.g., http://prometheus:9090)")
+	latchCmd.Flags().StringVar(&latchConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint for Linkerd or Istio traffic metrics (e.g., http://prometheus:9090)")
+	latchCmd.Flags().StringVar(&latchConfig.networkSource, "network-source", networkSourceAuto, networkSourceUsage)
+	latchCmd.Flags().BoolVar(&latchConfig.detach, "detach", false,
+		"run the latch as a background process without the TUI; review it with 'pro-monitor analyze --latest'")
 
 	// Kubernetes port-forward flags
 	latchCmd.Flags().StringVar(&latchConfig.k8sService, "k8s-service", "", "Kubernetes service name for port-forward (e.g., 'prometheus-operated')")
@@ -78,7 +88,7 @@ func init() {
 	latchCmd.Flags().StringVar(&latchConfig.portforwardTimeout, "portforward-timeout", "30s", "Timeout for port-forward readiness (e.g., 30s, 1m)")
 }
 
-func runLatch(_ *cobra.Command, args []string) error {
+func runLatch(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Parse workload reference
@@ -94,6 +104,15 @@ func runLatch(_ *cobra.Command, args []string) error {
 	}
 	ref.Namespace = ns
 
+	keys, err := promonitor.NewKeyMap(GetKeyBindings("pro-monitor"))
+	if err != nil {
+		return fmt.Errorf("invalid keybindings.pro-monitor in config: %w", err)
+	}
+	linkTTL, err := parseShareTTL()
+	if err != nil {
+		return err
+	}
+
 	// Parse durations
 	duration, err := time.ParseDuration(latchConfig.duration)
 	if err != nil {
@@ -105,10 +124,8 @@ func runLatch(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid interval %q: %w", latchConfig.interval, err)
 	}
 
-	if IsVerbose() {
-		fmt.Fprintf(os.Stderr, "[pro-monitor] Target: %s in namespace %s\n", ref.String(), ref.Namespace)
-		fmt.Fprintf(os.Stderr, "[pro-monitor] Duration: %s, Interval: %s\n", duration, interval)
-	}
+	log := logging.Component("pro-monitor")
+	log.Debug("Target", "workload", ref.String(), "namespace", ref.Namespace, "duration", duration, "interval", interval)
 
 	// Build K8s clients
 	opts := GetKubeOpts()
@@ -132,25 +149,24 @@ func runLatch(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	if IsVerbose() {
-		fmt.Fprintf(os.Stderr, "[pro-monitor] Workload validated: %s\n", ref.String())
-	}
+	log.Debug("Workload validated", "workload", ref.String())
 
 	// Check metrics-server
 	if err = promonitor.CheckMetricsServer(ctx, metricsClient, ref.Namespace); err != nil { //nolint:gocritic // reuse outer err to avoid govet shadow
 		return fmt.Errorf("metrics-server required for latch: %w", err)
 	}
 
-	if IsVerbose() {
-		fmt.Fprintf(os.Stderr, "[pro-monitor] Metrics-server available\n")
+	log.Debug("Metrics-server available")
+
+	if latchConfig.detach {
+		return detachLatch(cmd, ref, duration, interval)
 	}
 
 	// Detect HPA
 	hpa := promonitor.DetectHPA(ctx, kubeClient, ref)
 	if hpa != nil {
-		fmt.Fprintf(os.Stderr, "[pro-monitor] WARNING: HPA %q targets this workload (min=%d, max=%d)\n",
-			hpa.Name, hpa.MinReplica, hpa.MaxReplica)
-		fmt.Fprintf(os.Stderr, "[pro-monitor] Apply will be blocked unless --acknowledge-hpa is passed.\n")
+		log.Warn("HPA targets this workload", "hpa", hpa.Name, "min", hpa.MinReplica, "max", hpa.MaxReplica)
+		log.Warn("Apply will be blocked unless --acknowledge-hpa is passed.")
 	}
 
 	// Load policy
@@ -160,14 +176,56 @@ func runLatch(_ *cobra.Command, args []string) error {
 	containers, err := promonitor.FetchContainerResources(ctx, kubeClient, ref)
 	if err != nil {
 		// Non-fatal: recommendation will still run but without current values
-		if IsVerbose() {
-			fmt.Fprintf(os.Stderr, "[pro-monitor] Warning: could not read container resources: %v\n", err)
+		log.Debug("Could not read container resources", "error", err)
+	}
+
+	// OOM kills before the latch block memory reductions
+	oomHistory := promonitor.FetchOOMHistory(ctx, kubeClient, ref, promonitor.OOMHistoryWindow)
+	if oomHistory != nil && oomHistory.Kills > 0 {
+		log.Warn("Recent OOMKills; memory will not be reduced", "oom_kills", oomHistory.Kills, "window", promonitor.OOMHistoryWindow)
+	}
+
+	// Setup native port-forward if --k8s-service is specified
+	if latchConfig.k8sService != "" {
+		pfTimeout, pfErr := time.ParseDuration(latchConfig.portforwardTimeout)
+		if pfErr != nil {
+			return fmt.Errorf("invalid --portforward-timeout: %w", pfErr)
+		}
+		pf, pfErr := util.NewPortForward(
+			GetKubeOpts(),
+			latchConfig.k8sService,
+			latchConfig.k8sNamespace,
+			latchConfig.k8sLocalPort,
+			latchConfig.k8sRemotePort,
+			pfTimeout,
+		)
+		if pfErr != nil {
+			return fmt.Errorf("failed to create port-forward: %w", pfErr)
+		}
+		if pfErr = pf.Start(); pfErr != nil {
+			return fmt.Errorf("failed to start port-forward: %w", pfErr)
+		}
+		defer func() {
+			if stopErr := pf.Stop(); stopErr != nil {
+				log.Warn("Failed to stop port-forward", "error", stopErr)
+			}
+		}()
+		if latchConfig.prometheusURL == "" {
+			latchConfig.prometheusURL = fmt.Sprintf("http://localhost:%s", latchConfig.k8sLocalPort)
 		}
+		log.Debug("Port-forward active", "service", latchConfig.k8sNamespace+"/"+latchConfig.k8sService, "url", latchConfig.prometheusURL)
+	}
+
+	networkSampler, err := latchNetworkSampler(latchConfig.networkSource, kubeClient, latchConfig.prometheusURL)
+	if err != nil {
+		return err
 	}
 
 	// Create latch monitor (filtered to target workload).
 	// ProgressFunc is a no-op because the bubbletea TUI renders its own
 	// progress bar; writing to stderr would corrupt the alternate screen.
+	// For the same reason checkpoint errors are not reported.
+	checkpoint := latchCheckpointer(*ref, time.Now(), duration, interval, func(error) {})
 	latchMon, err := metrics.NewLatchMonitor(kubeClient, metrics.LatchConfig{
 		SampleInterval: interval,
 		Duration:       duration,
@@ -175,6 +233,8 @@ func runLatch(_ *cobra.Command, args []string) error {
 		WorkloadFilter: ref.Name,
 		PodLevel:       ref.Kind == "Pod",
 		ProgressFunc:   func(string) {},
+		Network:        networkSampler,
+		Checkpoint:     checkpoint,
 	}, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create latch monitor: %w", err)
@@ -182,9 +242,13 @@ func runLatch(_ *cobra.Command, args []string) error {
 
 	// Create TUI model with recommendation inputs
 	model := promonitor.NewModel(*ref, latchMon, duration, mode, policyMsg, hpa)
+	model.SetKeyMap(keys)
+	model.SetShareTTL(linkTTL)
+	model.SetVersion(version)
 	model.SetLatchStart(time.Now())
 	model.SetInterval(interval)
 	model.SetContainers(containers)
+	model.SetOOMHistory(oomHistory)
 	if bounds != nil {
 		model.SetPolicyBounds(bounds)
 	}
@@ -199,50 +263,15 @@ func runLatch(_ *cobra.Command, args []string) error {
 		}
 		model.SetPolicy(bounds)
 	}
-	// Setup native port-forward if --k8s-service is specified
-	if latchConfig.k8sService != "" {
-		pfTimeout, pfErr := time.ParseDuration(latchConfig.portforwardTimeout)
-		if pfErr != nil {
-			return fmt.Errorf("invalid --portforward-timeout: %w", pfErr)
-		}
-		pf, pfErr := util.NewPortForward(
-			latchConfig.k8sService,
-			latchConfig.k8sNamespace,
-			latchConfig.k8sLocalPort,
-			latchConfig.k8sRemotePort,
-			pfTimeout,
-		)
-		if pfErr != nil {
-			return fmt.Errorf("failed to create port-forward: %w", pfErr)
-		}
-		if pfErr = pf.Start(); pfErr != nil {
-			return fmt.Errorf("failed to start port-forward: %w", pfErr)
-		}
-		defer func() {
-			if stopErr := pf.Stop(); stopErr != nil {
-				fmt.Fprintf(os.Stderr, "[pro-monitor] Warning: failed to stop port-forward: %v\n", stopErr)
-			}
-		}()
-		if latchConfig.prometheusURL == "" {
-			latchConfig.prometheusURL = fmt.Sprintf("http://localhost:%s", latchConfig.k8sLocalPort)
-		}
-		if IsVerbose() {
-			fmt.Fprintf(os.Stderr, "[pro-monitor] Port-forward active: %s/%s → %s\n",
-				latchConfig.k8sNamespace, latchConfig.k8sService, latchConfig.prometheusURL)
-		}
-	}
-
-	// Wire exposure map (structural topology + optional Linkerd traffic)
+	// Wire exposure map (structural topology + optional mesh traffic)
 	exposureCollector := exposure.NewExposureCollector(kubeClient, metricsClient)
 	if latchConfig.prometheusURL != "" {
 		promClient, err := metrics.NewPrometheusClient(metrics.Config{PrometheusURL: latchConfig.prometheusURL})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[pro-monitor] Warning: could not connect to Prometheus: %v\n", err)
+			log.Warn("Could not connect to Prometheus", "error", err)
 		} else {
 			exposureCollector.SetPrometheusAPI(promClient.GetAPI())
-			if IsVerbose() {
-				fmt.Fprintf(os.Stderr, "[pro-monitor] Linkerd traffic metrics enabled via %s\n", latchConfig.prometheusURL)
-			}
+			log.Debug("Mesh traffic metrics enabled", "url", latchConfig.prometheusURL)
 		}
 	}
 	model.SetExposureCollector(exposureCollector)
@@ -279,12 +308,93 @@ func runLatch(_ *cobra.Command, args []string) error {
 	}
 
 	latchCancel()
+	finishLatchSession(*ref, &model, latchMon, checkpoint)
+	printShareLink(&model)
 	return nil
 }
 
+// latchCheckpointer returns a LatchConfig.Checkpoint callback that saves
+// the latch as a resumable session. onErr is called for the first failed
+// save only.
+func latchCheckpointer(
+	ref promonitor.WorkloadRef, startedAt time.Time, planned, interval time.Duration, onErr func(error),
+) func(*metrics.LatchCheckpoint) {
+	failed := false
+	return func(cp *metrics.LatchCheckpoint) {
+		err := promonitor.SaveLatchSession(&promonitor.LatchSession{
+			Workload:        ref,
+			StartedAt:       startedAt,
+			UpdatedAt:       time.Now(),
+			PlannedDuration: planned,
+			Interval:        interval,
+			Checkpoint:      cp,
+		})
+		if err != nil && !failed {
+			failed = true
+			onErr(err)
+		}
+	}
+}
+
+// finishLatchSession drops the checkpoint of a latch whose data was kept.
+// A latch quit mid-way is checkpointed once more and left resumable.
+func finishLatchSession(
+	ref promonitor.WorkloadRef, model *promonitor.Model, latchMon *metrics.LatchMonitor, checkpoint func(*metrics.LatchCheckpoint),
+) {
+	if model.LatchCompleted() {
+		if err := promonitor.DeleteLatchSession(ref); err != nil {
+			logging.Component("pro-monitor").Debug("Could not remove latch checkpoint", "error", err)
+		}
+		return
+	}
+	checkpoint(latchMon.Checkpoint())
+	logging.Component("pro-monitor").Info("Latch interrupted; continue it with kubenow pro-monitor resume",
+		"workload", ref.String(), "namespace", ref.Namespace)
+}
+
+// Network sources for latch network sampling.
+const (
+	networkSourceAuto       = "auto"
+	networkSourceKubelet    = "kubelet"
+	networkSourcePrometheus = "prometheus"
+	networkSourceNone       = "none"
+)
+
+const networkSourceUsage = "Network RX/TX sampling: auto (prometheus with --prometheus-url, else kubelet), " +
+	"kubelet (summary API, needs nodes/proxy), prometheus, none"
+
+// latchNetworkSampler builds the network sampler for --network-source;
+// nil means network is not sampled.
+func latchNetworkSampler(source string, kubeClient kubernetes.Interface, prometheusURL string) (metrics.NetworkSampler, error) {
+	if source == networkSourceAuto {
+		source = networkSourceKubelet
+		if prometheusURL != "" {
+			source = networkSourcePrometheus
+		}
+	}
+	switch source {
+	case networkSourceNone:
+		return nil, nil
+	case networkSourceKubelet:
+		return metrics.NewKubeletNetworkSampler(kubeClient), nil
+	case networkSourcePrometheus:
+		if prometheusURL == "" {
+			return nil, fmt.Errorf("--network-source prometheus requires --prometheus-url")
+		}
+		promClient, err := metrics.NewPrometheusClient(metrics.Config{PrometheusURL: prometheusURL})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Prometheus client for network sampling: %w", err)
+		}
+		return &metrics.PrometheusNetworkSampler{Client: promClient}, nil
+	default:
+		return nil, fmt.Errorf("invalid --network-source %q: must be auto, kubelet, prometheus, or none", source)
+	}
+}
+
 // resolveMode loads the policy and determines the operating mode.
 // Returns the mode, a human-readable status message, optional policy bounds,
-// and the loaded policy (nil if absent/invalid).
+// and the effective policy for ref's namespace, with any namespace override
+// applied (nil if absent/invalid).
 func resolveMode(policyFile string, ref *promonitor.WorkloadRef) (promonitor.Mode, string, *promonitor.PolicyBounds, *policy.Policy) {
 	result := policy.Load(policyFile)
 
@@ -303,12 +413,22 @@ func resolveMode(policyFile string, ref *promonitor.WorkloadRef) (promonitor.Mod
 		return promonitor.ModeObserveOnly, fmt.Sprintf("validation failed (%d errors)", len(vr.Errors)), nil, nil
 	}
 
+	override := ""
+	if p.NamespaceOverride(ref.Namespace) != nil {
+		override = fmt.Sprintf(", namespace override for %q", ref.Namespace)
+		p = p.ForNamespace(ref.Namespace)
+	}
+
 	// Extract policy bounds for recommendation engine
 	bounds := &promonitor.PolicyBounds{
 		MaxRequestDeltaPct: p.Apply.MaxRequestDeltaPct,
 		MaxLimitDeltaPct:   p.Apply.MaxLimitDeltaPct,
 		AllowLimitDecrease: p.Apply.AllowLimitDecrease,
 		MinSafetyRating:    promonitor.ParseSafetyRating(p.Apply.MinSafetyRating),
+
+		MaxCPULimitRatio:    p.Ratios.MaxCPULimitRatio,
+		MaxMemoryLimitRatio: p.Ratios.MaxMemoryLimitRatio,
+		ForbidCPULimits:     p.Ratios.ForbidCPULimits,
 	}
 
 	if !p.Global.Enabled {
@@ -320,8 +440,8 @@ func resolveMode(policyFile string, ref *promonitor.WorkloadRef) (promonitor.Mod
 	}
 
 	if !p.Apply.Enabled {
-		return promonitor.ModeExportOnly, "suggest+export (apply.enabled=false)", bounds, p
+		return promonitor.ModeExportOnly, "suggest+export (apply.enabled=false" + override + ")", bounds, p
 	}
 
-	return promonitor.ModeApplyReady, fmt.Sprintf("loaded from %s", result.Path), bounds, p
+	return promonitor.ModeApplyReady, fmt.Sprintf("loaded from %s%s", result.Path, override), bounds, p
 }
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promonitor"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var resumeConfig struct {
+	networkSource string
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume <kind>/<name>",
+	Short: "Continue a latch that was killed or quit before it finished",
+	Long: `Continue an unfinished latch from its last checkpoint.
+
+'pro-monitor latch' and 'pro-monitor collect' checkpoint their samples every
+minute. If the process is killed, or the TUI is quit before the latch
+completes, resume samples the workload for the rest of the planned duration
+with the original interval, headless like 'pro-monitor collect', and merges
+the new samples into the checkpointed ones. The time the latch was down is
+not counted as missed samples.
+
+On completion (or SIGINT), saves the latch data to ~/.kubenow/latch/ for
+'pro-monitor analyze' or 'pro-monitor export' and removes the checkpoint.
+
+Examples:
+  # Continue an overnight collection after the node running it rebooted
+  kubenow pro-monitor resume deployment/payment-api -n prod
+
+  # Review the result
+  kubenow pro-monitor analyze deployment/payment-api -n prod`,
+	Args: cobra.ExactArgs(1),
+	RunE: runResume,
+}
+
+func init() {
+	proMonitorCmd.AddCommand(resumeCmd)
+	resumeCmd.Flags().StringVar(&resumeConfig.networkSource, "network-source", networkSourceKubelet,
+		"Network RX/TX sampling: kubelet (summary API, needs nodes/proxy) or none")
+}
+
+func runResume(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	ref, err := promonitor.ParseWorkloadRef(args[0])
+	if err != nil {
+		return err
+	}
+
+	ns := GetNamespace()
+	if ns == "" {
+		ns = "default"
+	}
+	ref.Namespace = ns
+
+	session, err := promonitor.LoadLatchSession(*ref)
+	if err != nil {
+		return err
+	}
+	if session.Checkpoint == nil {
+		return fmt.Errorf("latch session for %s has no checkpoint", ref.String())
+	}
+	// Always sample at least once, so critical signals are checked
+	remaining := max(session.Remaining(), session.Interval)
+
+	log := logging.Component("resume")
+	log.Info("Target", "workload", ref.String(), "namespace", ref.Namespace)
+	log.Info("Resuming checkpointed latch",
+		"started", session.StartedAt.Format(time.RFC3339), "checkpointed", session.UpdatedAt.Format(time.RFC3339),
+		"sampled", session.Checkpoint.Elapsed.Truncate(time.Second), "planned", session.PlannedDuration,
+		"remaining", remaining.Truncate(time.Second))
+
+	// Build K8s clients
+	opts := GetKubeOpts()
+	kubeClient, err := util.BuildKubeClientWithOpts(opts)
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+
+	restConfig, err := util.BuildRestConfigWithOpts(opts)
+	if err != nil {
+		return fmt.Errorf("failed to build REST config: %w", err)
+	}
+
+	metricsClient, err := metricsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build metrics client: %w", err)
+	}
+
+	// Validate workload exists
+	if err = promonitor.ValidateWorkload(ctx, kubeClient, ref); err != nil { //nolint:gocritic // reuse outer err to avoid govet shadow
+		return err
+	}
+
+	// Check metrics-server
+	if err = promonitor.CheckMetricsServer(ctx, metricsClient, ref.Namespace); err != nil { //nolint:gocritic // reuse outer err to avoid govet shadow
+		return fmt.Errorf("metrics-server required for resume: %w", err)
+	}
+
+	networkSampler, err := latchNetworkSampler(resumeConfig.networkSource, kubeClient, "")
+	if err != nil {
+		return err
+	}
+
+	latchMon, err := metrics.NewLatchMonitor(kubeClient, metrics.LatchConfig{
+		SampleInterval: session.Interval,
+		Duration:       remaining,
+		Namespaces:     []string{ref.Namespace},
+		WorkloadFilter: ref.Name,
+		PodLevel:       ref.Kind == "Pod",
+		Network:        networkSampler,
+		Checkpoint: latchCheckpointer(*ref, session.StartedAt, session.PlannedDuration, session.Interval, func(err error) {
+			log.Warn("Checkpoint failed, this latch cannot be resumed again", "error", err)
+		}),
+		Resume: session.Checkpoint,
+	}, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create latch monitor: %w", err)
+	}
+
+	// Handle SIGINT for graceful early stop
+	latchCtx, latchCancel := context.WithCancel(ctx)
+	defer latchCancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer cleanup.Graceful()()
+
+	var earlyStop bool
+	go func() {
+		<-sigCh
+		log.Info("Received interrupt — stopping collection and saving data...")
+		earlyStop = true
+		latchMon.Stop()
+	}()
+
+	log.Info("Resuming collection...")
+	latchErr := latchMon.Start(latchCtx)
+	signal.Stop(sigCh)
+
+	if latchErr != nil && latchErr != context.Canceled {
+		return fmt.Errorf("latch error: %w", latchErr)
+	}
+
+	elapsed := latchMon.Checkpoint().Elapsed
+	return saveHeadlessLatch(log, *ref, latchMon, session.PlannedDuration, elapsed, session.Interval, earlyStop)
+}
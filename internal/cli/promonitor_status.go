@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ppiankov/kubenow/internal/promonitor"
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 var statusCmd = &cobra.Command{
@@ -67,14 +68,14 @@ func printLatchStatus(r *promonitor.LatchResult) {
 
 	if r.CPU != nil {
 		stdoutf("  CPU:  avg=%s  p50=%s  p95=%s  p99=%s  max=%s\n",
-			formatCPU(r.CPU.Avg), formatCPU(r.CPU.P50), formatCPU(r.CPU.P95),
-			formatCPU(r.CPU.P99), formatCPU(r.CPU.Max))
+			units.Millicores(r.CPU.Avg), units.Millicores(r.CPU.P50), units.Millicores(r.CPU.P95),
+			units.Millicores(r.CPU.P99), units.Millicores(r.CPU.Max))
 	}
 
 	if r.Memory != nil {
 		stdoutf("  MEM:  avg=%s  p50=%s  p95=%s  p99=%s  max=%s\n",
-			formatMem(r.Memory.Avg), formatMem(r.Memory.P50), formatMem(r.Memory.P95),
-			formatMem(r.Memory.P99), formatMem(r.Memory.Max))
+			units.MemoryMi(r.Memory.Avg), units.MemoryMi(r.Memory.P50), units.MemoryMi(r.Memory.P95),
+			units.MemoryMi(r.Memory.P99), units.MemoryMi(r.Memory.Max))
 	}
 
 	if r.Data != nil {
@@ -96,24 +97,6 @@ func sampleCount(r *promonitor.LatchResult) int {
 	return 0
 }
 
-// formatCPU formats CPU cores as millicores (e.g., 0.07 → "70m").
-func formatCPU(cores float64) string {
-	m := cores * 1000
-	if m < 1 {
-		return "0m"
-	}
-	return fmt.Sprintf("%.0fm", m)
-}
-
-// formatMem formats bytes as Mi (e.g., 178257920 → "170Mi").
-func formatMem(bytes float64) string {
-	mi := bytes / (1024 * 1024)
-	if mi < 1 {
-		return "0Mi"
-	}
-	return fmt.Sprintf("%.0fMi", mi)
-}
-
 func formatLatchAge(d time.Duration) string {
 	switch {
 	case d < time.Minute:
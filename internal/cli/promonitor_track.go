@@ -3,13 +3,13 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/promonitor"
 )
@@ -19,6 +19,7 @@ var trackConfig struct {
 	prometheusURL string
 	format        string
 	since         string
+	staleAfter    string
 }
 
 var trackCmd = &cobra.Command{
@@ -37,6 +38,10 @@ post-apply resource usage, and classifies each apply as:
 
 Without --prometheus-url, all past-24h applies show as NO_DATA.
 
+Applies are also flagged STALE, whatever their outcome, when the applied
+values were computed more than --stale-after ago (default 8w) or their
+trend-derived validity window has ended: re-latch those workloads.
+
 Examples:
   # Track all applies from the last 7 days
   kubenow pro-monitor track --audit-path /var/kubenow/audit --since 7d
@@ -58,6 +63,7 @@ func init() {
 	trackCmd.Flags().StringVar(&trackConfig.prometheusURL, "prometheus-url", "", "Prometheus endpoint for post-apply usage metrics")
 	trackCmd.Flags().StringVar(&trackConfig.format, "format", "table", "output format: table or json")
 	trackCmd.Flags().StringVar(&trackConfig.since, "since", "", "only show applies within this window (e.g., 7d, 30d, 24h)")
+	trackCmd.Flags().StringVar(&trackConfig.staleAfter, "stale-after", "8w", "flag applied values computed longer ago than this (e.g., 4w, 30d; 0 disables)")
 }
 
 func runTrack(_ *cobra.Command, args []string) error {
@@ -85,6 +91,15 @@ func runTrack(_ *cobra.Command, args []string) error {
 		since = d
 	}
 
+	var staleAfter time.Duration
+	if trackConfig.staleAfter != "" && trackConfig.staleAfter != "0" {
+		d, err := parseSinceDuration(trackConfig.staleAfter)
+		if err != nil {
+			return fmt.Errorf("invalid --stale-after %q: %w", trackConfig.staleAfter, err)
+		}
+		staleAfter = d
+	}
+
 	// Optionally connect to Prometheus
 	var metricsProvider metrics.MetricsProvider
 	if trackConfig.prometheusURL != "" {
@@ -104,6 +119,7 @@ func runTrack(_ *cobra.Command, args []string) error {
 		Since:          since,
 		WorkloadFilter: workloadFilter,
 		Now:            now,
+		MaxAge:         staleAfter,
 	})
 	if err != nil {
 		return err
@@ -124,15 +140,26 @@ func runTrack(_ *cobra.Command, args []string) error {
 
 	// Exit non-zero if any WRONG outcomes detected (useful for CI)
 	if summary.Wrong > 0 {
-		fmt.Fprintf(os.Stderr, "[track] %d WRONG outcome(s) detected\n", summary.Wrong)
+		logging.Component("track").Warn("WRONG outcomes detected", "wrong", summary.Wrong)
+	}
+	if summary.Stale > 0 {
+		logging.Component("track").Warn("Stale applies: re-latch to refresh", "stale", summary.Stale)
 	}
 
 	return nil
 }
 
-// parseSinceDuration parses a duration string that supports "d" suffix for days.
-// Examples: "7d" → 168h, "30d" → 720h, "24h" → 24h, "1h30m" → 1h30m
+// parseSinceDuration parses a duration string that supports "d" and "w" suffixes.
+// Examples: "7d" → 168h, "30d" → 720h, "2w" → 336h, "24h" → 24h, "1h30m" → 1h30m
 func parseSinceDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "w") {
+		weeksStr := strings.TrimSuffix(s, "w")
+		weeks, err := strconv.Atoi(weeksStr)
+		if err != nil {
+			return 0, fmt.Errorf("invalid weeks %q: %w", weeksStr, err)
+		}
+		return time.Duration(weeks) * 7 * 24 * time.Hour, nil
+	}
 	if strings.HasSuffix(s, "d") {
 		daysStr := strings.TrimSuffix(s, "d")
 		days, err := strconv.Atoi(daysStr)
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/spf13/cobra"
 
@@ -43,9 +44,8 @@ func runValidatePolicy(_ *cobra.Command, _ []string) error {
 	result := policy.Load(policyPath)
 
 	if result.Absent {
-		stderrf("No policy file found at %s\n", result.Path)
-		stderrf("Pro-monitor will operate in observe-only mode (no apply).\n")
-		stderrf("\nTo create a policy file, see: examples/policy.yaml\n")
+		slog.Info("No policy file found; pro-monitor will operate in observe-only mode (no apply). To create one, see examples/policy.yaml",
+			"path", result.Path)
 		return nil
 	}
 
@@ -77,6 +77,11 @@ func runValidatePolicy(_ *cobra.Command, _ []string) error {
 	stdoutf("  Max limit delta:     %d%%\n", p.Apply.MaxLimitDeltaPct)
 	stdoutf("  Min safety rating:   %s\n", p.Apply.MinSafetyRating)
 	stdoutf("  Rate limit:          %d applies/hour\n", p.RateLimits.MaxAppliesPerHour)
+	for _, o := range p.Overrides {
+		eff := p.ForNamespace(o.Namespaces[0]).Apply
+		stdoutf("  Override %v: apply=%v, max request delta %d%%, max limit delta %d%%\n",
+			o.Namespaces, eff.Enabled, eff.MaxRequestDeltaPct, eff.MaxLimitDeltaPct)
+	}
 
 	if checkPaths && p.Audit.Path != "" {
 		stdoutf("\nPath checks:\n")
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var promptRenderConfig struct {
+	LLMCommandConfig
+	dryRun bool
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the prompts sent to the LLM",
+	Long: `Inspect the prompt templates of the LLM commands and the prompts built from
+them, including custom templates from --prompt-dir and --prompt-file.`,
+}
+
+var promptRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Print a prompt template, or the final prompt with --dry-run",
+	Long: `Print the prompt template of a mode, or with --dry-run the exact prompt an
+LLM command would send, without calling the LLM.
+
+Without --dry-run the template is printed as is, placeholders included.
+With --dry-run a snapshot is collected from the cluster (or read with
+--from-snapshot), acknowledged problems are set aside, secrets are
+redacted, and the prompt is fitted to the model's budget, as in the LLM
+commands.
+
+Template placeholders:
+  {{SNAPSHOT}}       the snapshot JSON (required; {{SNAPSHOT_JSON}} also works)
+  {{HINT}}           the --hint section; appended at the end when absent
+  {{ENHANCEMENTS}}   --enhance-* instructions and snapshot context sections;
+                     inserted before BEGIN_SNAPSHOT (or appended) when absent
+
+Examples:
+  # Show the embedded incident template
+  kubenow prompt render --mode incident
+
+  # Preview the final prompt of a custom template against the cluster
+  kubenow prompt render --mode incident --prompt-dir ./prompts --dry-run
+
+  # Preview against a saved snapshot, trimmed for a small model
+  kubenow prompt render --from-snapshot snapshot.json --model llama3:8b --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runPromptRender(&promptRenderConfig.LLMCommandConfig, promptRenderConfig.dryRun)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.AddCommand(promptRenderCmd)
+
+	config := &promptRenderConfig.LLMCommandConfig
+	promptRenderCmd.Flags().StringVar(&config.Mode, "mode", "default",
+		fmt.Sprintf("Prompt mode: %s, or auto (needs --dry-run)", strings.Join(prompt.Modes, "|")))
+	promptRenderCmd.Flags().BoolVar(&promptRenderConfig.dryRun, "dry-run", false,
+		"Build the final prompt from a snapshot instead of printing the template; the LLM is never called")
+	promptRenderCmd.Flags().StringVar(&config.FromSnapshot, "from-snapshot", "", "Render from a saved snapshot file instead of the live cluster")
+	promptRenderCmd.Flags().StringVar(&config.Model, "model", "", "Model whose context window sets the prompt budget")
+	promptRenderCmd.Flags().IntVar(&config.MaxPromptTokens, "max-prompt-tokens", 0,
+		"Prompt token budget; larger snapshots are trimmed (0 = model context window minus response reserve)")
+	addSnapshotFlags(promptRenderCmd, config)
+	addPromptFlags(promptRenderCmd, config)
+}
+
+// runPromptRender prints the template of config.Mode, or with dryRun the
+// prompt built from a live or saved snapshot.
+func runPromptRender(config *LLMCommandConfig, dryRun bool) error {
+	if config.Mode == prompt.ModeAuto {
+		if !dryRun {
+			return fmt.Errorf("--mode auto picks the mode from a snapshot: add --dry-run")
+		}
+		config.Mode, config.ModeSelection = "default", prompt.ModeAuto
+	}
+	if !slices.Contains(prompt.Modes, config.Mode) {
+		return fmt.Errorf("invalid --mode %q (use %s, or auto)", config.Mode, strings.Join(prompt.Modes, ", "))
+	}
+	if config.Mode == "capacity" && dryRun {
+		return fmt.Errorf("capacity prompts are built from a requests-skew report, not a snapshot: run kubenow capacity")
+	}
+	if _, err := newRedactor(config); err != nil {
+		return err
+	}
+	enhancements, err := promptEnhancements(config)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		slog.Info("Template", "mode", config.Mode, "source", enhancements.Templates.Source(config.Mode))
+		tmpl, err := prompt.Template(config.Mode, enhancements.Templates)
+		if err != nil {
+			return err
+		}
+		printOut(tmpl)
+		return nil
+	}
+
+	snap, err := promptRenderSnapshot(config)
+	if err != nil {
+		return err
+	}
+	p, err := buildLLMPrompt(config, enhancements, snap)
+	if err != nil {
+		return err
+	}
+	slog.Info("Prompt rendered; LLM not called", "mode", p.mode, "source", enhancements.Templates.Source(p.mode),
+		"tokens", prompt.EstimateTokens(p.text), "budget", prompt.PromptBudget(config.Model, config.MaxPromptTokens),
+		"problem_pods", len(snap.ProblemPods))
+	printlnOut(p.text)
+	return nil
+}
+
+// promptRenderSnapshot reads --from-snapshot or collects a live snapshot.
+func promptRenderSnapshot(config *LLMCommandConfig) (*snapshot.Snapshot, error) {
+	if config.FromSnapshot != "" {
+		saved, err := snapshot.Load(config.FromSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Rendering from saved snapshot", "path", config.FromSnapshot,
+			"collected", saved.Snapshot.GeneratedAt.Format(time.RFC3339))
+		return saved.Snapshot, nil
+	}
+
+	clientset, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+	filters := snapshotFilters(config)
+	snap, err := snapshot.BuildSnapshot(context.Background(), clientset, GetNamespace(), config.MaxPods, config.LogLines, config.MaxConcurrent, &filters)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot error: %w", err)
+	}
+	return snap, nil
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"strconv"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// cpuValue is a flag holding CPU cores that also accepts Kubernetes
+// quantities, so "--idle-cpu 10m" and "--idle-cpu 0.01" are the same.
+type cpuValue float64
+
+func newCPUValue(p *float64, def float64) *cpuValue {
+	*p = def
+	return (*cpuValue)(p)
+}
+
+func (v *cpuValue) Set(s string) error {
+	cores, err := units.ParseCPU(s)
+	if err != nil {
+		return err
+	}
+	*v = cpuValue(cores)
+	return nil
+}
+
+func (v *cpuValue) String() string { return strconv.FormatFloat(float64(*v), 'f', -1, 64) }
+
+func (v *cpuValue) Type() string { return "cpu" }
+
+// cpuFlagVar registers a CPU flag on fs; see cpuValue.
+func cpuFlagVar(fs *pflag.FlagSet, p *float64, name string, def float64, usage string) {
+	fs.Var(newCPUValue(p, def), name, usage)
+}
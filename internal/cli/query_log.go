@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ppiankov/kubenow/internal/querylog"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// addIncludeQueriesFlag registers --include-queries on an analyze command.
+func addIncludeQueriesFlag(fs *pflag.FlagSet, p *bool) {
+	fs.BoolVar(p, "include-queries", false,
+		"Append every PromQL query and Kubernetes API request run, with timings and row counts, to the report")
+}
+
+// newQueryLog returns a query log when --include-queries is set, else nil;
+// recording into a nil log is a no-op.
+func newQueryLog(include bool) *querylog.Log {
+	if !include {
+		return nil
+	}
+	return querylog.New()
+}
+
+// queryLogKubeOpts returns opts with the clientset's API requests recorded
+// into log.
+func queryLogKubeOpts(opts util.KubeOpts, log *querylog.Log) util.KubeOpts {
+	if log != nil {
+		opts.WrapTransport = log.WrapTransport
+	}
+	return opts
+}
+
+// yamlComment turns a rendered section into YAML comment lines so it can
+// trail a manifest without breaking kubectl apply.
+func yamlComment(s string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("# " + line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
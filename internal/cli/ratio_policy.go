@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/policy"
+	"github.com/ppiankov/kubenow/internal/result"
+)
+
+// loadRatioPolicy returns the resource_ratios section of the admin policy.
+// An absent policy file yields an empty (disabled) config; an unreadable or
+// invalid one is an error so a mandated audit is never skipped silently.
+func loadRatioPolicy(path string) (policy.RatioConfig, error) {
+	lr := policy.Load(path)
+	if lr.Absent {
+		return policy.RatioConfig{}, nil
+	}
+	if lr.ErrorMsg != "" {
+		return policy.RatioConfig{}, fmt.Errorf("policy %s: %s", lr.Path, lr.ErrorMsg)
+	}
+	if vr := policy.Validate(lr.Policy); !vr.Valid {
+		return policy.RatioConfig{}, fmt.Errorf("policy %s: %s", lr.Path, vr.Errors[0].String())
+	}
+	return lr.Policy.Ratios, nil
+}
+
+// ratioComplianceIssues runs the deterministic ratio audit and converts the
+// violations into compliance issues merged with the LLM findings.
+func ratioComplianceIssues(ctx context.Context, client kubernetes.Interface, namespace string, cfg policy.RatioConfig) ([]result.ComplianceIssue, error) {
+	violations, err := analyzer.AuditResourceRatios(ctx, client, namespace, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]result.ComplianceIssue, 0, len(violations))
+	for i := range violations {
+		v := &violations[i]
+		issues = append(issues, result.ComplianceIssue{
+			Namespace:      v.Namespace,
+			Name:           fmt.Sprintf("%s/%s", v.Kind, v.Workload),
+			Type:           "ResourceRatio",
+			Severity:       "warning",
+			Description:    v.Message,
+			Recommendation: v.Recommendation(),
+		})
+	}
+	return issues, nil
+}
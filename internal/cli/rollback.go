@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/audit"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/policy"
+	"github.com/ppiankov/kubenow/internal/promonitor"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var rollbackConfig struct {
+	bundle    string
+	auditPath string
+	policy    string
+	force     bool
+	dryRun    bool
+	yes       bool
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback --bundle <path|id>",
+	Short: "Undo a pro-monitor apply from its audit bundle",
+	Long: `Restore the container resources a pro-monitor apply changed, from the
+before.yaml recorded in its audit bundle.
+
+The recorded requests and limits are re-applied with Server-Side Apply under
+the kubenow field manager; fields the workload did not set before the apply
+are removed. The workload is read back to verify the restored values, and a
+rollback record is appended to the bundle's decision.json (with the
+read-back object as rollback-<timestamp>.yaml).
+
+--bundle takes a bundle directory, or a bundle ID under the audit path: the
+directory name or a unique prefix such as its timestamp. The audit path
+defaults to audit.path from the policy.
+
+Rollback is a mutation and needs a policy with apply enabled. It is refused
+when the workload's resources changed since the apply (someone else tuned
+them) or a GitOps controller owns the fields; --force overrides the former
+and conflicts with non-GitOps managers, never GitOps.
+
+Examples:
+  # Preview the rollback server-side
+  kubenow rollback --bundle 20260301T120000Z --dry-run
+
+  # Roll back by bundle directory without prompting
+  kubenow rollback --bundle /var/lib/kubenow/audit/20260301T120000Z__prod__deployment__api --yes`,
+	Args: cobra.NoArgs,
+	RunE: runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	f := rollbackCmd.Flags()
+	f.StringVar(&rollbackConfig.bundle, "bundle", "", "audit bundle directory or ID (required)")
+	f.StringVar(&rollbackConfig.auditPath, "audit-path", "", "audit bundle directory for bundle IDs (default: audit.path from the policy)")
+	f.StringVar(&rollbackConfig.policy, "policy", "", "path to admin policy file")
+	f.BoolVar(&rollbackConfig.force, "force", false, "roll back over later resource changes and non-GitOps field conflicts")
+	f.BoolVar(&rollbackConfig.dryRun, "dry-run", false, "show the server-side dry-run diff without changing anything")
+	f.BoolVar(&rollbackConfig.yes, "yes", false, "skip the confirmation prompt")
+	_ = rollbackCmd.MarkFlagRequired("bundle")
+}
+
+func runRollback(_ *cobra.Command, _ []string) error {
+	cfg := rollbackConfig
+
+	auditPath := cfg.auditPath
+	if loaded := policy.Load(cfg.policy); auditPath == "" && loaded.Policy != nil {
+		auditPath = loaded.Policy.Audit.Path
+	}
+
+	dir, err := audit.ResolveBundleDir(auditPath, cfg.bundle)
+	if err != nil {
+		return err
+	}
+	plan, err := promonitor.LoadRollbackPlan(dir)
+	if err != nil {
+		return fmt.Errorf("bundle %s: %w", dir, err)
+	}
+
+	mode, policyMsg, _, _ := resolveMode(cfg.policy, &plan.Workload)
+	if mode != promonitor.ModeApplyReady && !cfg.dryRun {
+		return fmt.Errorf("rollback needs a policy with apply enabled (policy: %s)", policyMsg)
+	}
+
+	stdoutf("Bundle:   %s\n", plan.ID)
+	stdoutf("Workload: %s\n", plan.Workload.FullString())
+	stdoutf("Applied:  %s\n", plan.Decision.AppliedAt)
+	if n := len(plan.Decision.Rollbacks); n > 0 {
+		last := plan.Decision.Rollbacks[n-1]
+		stdoutf("Note:     already rolled back %d time(s), last %s (%s)\n", n, last.Timestamp, last.Status)
+	}
+
+	kubeClient, err := util.BuildKubeClientWithOpts(GetKubeOpts())
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+	client := &promonitor.ClientsetApplier{Client: kubeClient}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	input := &promonitor.RollbackInput{Plan: plan, Force: cfg.force, DryRun: true}
+	preview := promonitor.ExecuteRollback(ctx, client, input)
+	printRollbackChanges(preview)
+	if preview.Error != nil {
+		return fmt.Errorf("rollback refused: %w", preview.Error)
+	}
+	if cfg.dryRun {
+		printRollbackPreview(preview.Preview)
+		return nil
+	}
+	if preview.Preview != nil && preview.Preview.ConflictManager != "" && !cfg.force {
+		return fmt.Errorf("rollback refused: the fields are now managed by %s; pass --force to take them over", preview.Preview.ConflictManager)
+	}
+	if len(preview.Restored) == 0 {
+		stdoutf("\nNothing to roll back: the live resources already match before.yaml.\n")
+		return nil
+	}
+
+	if !cfg.yes && !confirmRollback() {
+		return fmt.Errorf("rollback cancelled")
+	}
+
+	input.DryRun = false
+	result := promonitor.ExecuteRollback(ctx, client, input)
+
+	identity := audit.ResolveIdentity(ctx, kubeClient, GetKubeconfig())
+	record := promonitor.RollbackRecord(result, identity, version, cfg.force)
+	if err := audit.AppendRollback(plan.Dir, record, result.Object, time.Now()); err != nil {
+		logging.Component("rollback").Warn("Failed to record rollback in bundle", "error", err)
+	}
+
+	switch {
+	case result.Error != nil && !result.RolledBack:
+		return fmt.Errorf("rollback failed: %w", result.Error)
+	case result.Error != nil:
+		logging.Component("rollback").Warn("Rollback completed with errors", "error", result.Error)
+	case len(result.Mismatches) > 0:
+		stdoutf("\nRolled back, but the API server admitted different values:\n")
+		for _, c := range result.Mismatches {
+			stdoutf("  %s: wanted %s, got %s\n", c.Path, displayValue(c.Before), displayValue(c.After))
+		}
+		return fmt.Errorf("rollback verification failed for %d field(s)", len(result.Mismatches))
+	}
+	stdoutf("\nRolled back %s; read-back matches before.yaml. Recorded in %s\n", plan.Workload.FullString(), plan.Dir)
+	return nil
+}
+
+func printRollbackChanges(r *promonitor.RollbackResult) {
+	if len(r.ChangedSince) > 0 {
+		stdoutf("\nChanged since the apply (applied → live):\n")
+		for _, c := range r.ChangedSince {
+			stdoutf("  %s: %s → %s\n", c.Path, displayValue(c.Before), displayValue(c.After))
+		}
+	}
+	if len(r.Restored) > 0 {
+		stdoutf("\nRestores (live → before):\n")
+		for _, c := range r.Restored {
+			stdoutf("  %s: %s → %s\n", c.Path, displayValue(c.Before), displayValue(c.After))
+		}
+	}
+}
+
+func printRollbackPreview(p *promonitor.ApplyPreview) {
+	if p == nil {
+		return
+	}
+	if p.ConflictManager != "" {
+		stdoutf("\nConflicts with field manager %s (rollback needs --force)\n", p.ConflictManager)
+	}
+	stdoutf("\nServer-side dry-run (%d change(s)):\n", len(p.Changes))
+	for _, c := range p.Changes {
+		marker := ""
+		if c.Mutated {
+			marker = " (admission)"
+		}
+		stdoutf("  %s: %s → %s%s\n", c.Path, displayValue(c.Before), displayValue(c.After), marker)
+	}
+}
+
+func displayValue(v string) string {
+	if v == "" {
+		return "(unset)"
+	}
+	return v
+}
+
+func confirmRollback() bool {
+	stdoutf("\nType \"rollback\" to confirm: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(line) == "rollback"
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/rollup"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+var rollupConfig struct {
+	output     string
+	top        int
+	exportFile string
+}
+
+var rollupCmd = &cobra.Command{
+	Use:   "rollup REPORT...",
+	Short: "Merge per-cluster requests-skew reports into a fleet summary",
+	Long: `Merge requests-skew JSON exports from several clusters into one fleet-level
+summary: total waste, the top over-provisioned workloads across all clusters,
+and a per-cluster comparison table.
+
+Each report is named by the cluster recorded in its metadata, or by its file
+name for older exports. When a cluster has several reports only the most
+recent one counts. Reports only carry their own top results, so export them
+with --top 0 for complete offender rankings.
+
+Examples:
+  # Export each cluster, then roll up
+  kubenow analyze requests-skew --context prod-eu --prometheus-url http://prom-eu:9090 \
+    --top 0 --output json --export-file reports/prod-eu.json
+  kubenow rollup reports/*.json
+
+  # Fleet summary as JSON
+  kubenow rollup reports/*.json --output json --export-file fleet.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRollup,
+}
+
+func init() {
+	rootCmd.AddCommand(rollupCmd)
+
+	rollupCmd.Flags().StringVar(&rollupConfig.output, "output", "table", "Output format: table|json")
+	rollupCmd.Flags().IntVar(&rollupConfig.top, "top", 20, "Top N offenders across the fleet (0 = all)")
+	rollupCmd.Flags().StringVar(&rollupConfig.exportFile, "export-file", "", "Write to file instead of stdout")
+}
+
+func runRollup(_ *cobra.Command, args []string) error {
+	if rollupConfig.output != "table" && rollupConfig.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", rollupConfig.output)
+	}
+
+	paths, err := rollup.ExpandPaths(args)
+	if err != nil {
+		return err
+	}
+	reports, err := rollup.LoadReports(paths)
+	if err != nil {
+		return err
+	}
+	fleet := rollup.Build(reports, rollupConfig.top)
+
+	for _, src := range fleet.Superseded {
+		slog.Info("Skipping report: a newer report exists for the same cluster", "path", src)
+	}
+	if fleet.MixedWindows {
+		slog.Warn("Reports use different analysis windows; waste figures are not directly comparable")
+	}
+
+	if rollupConfig.output == "json" {
+		data, err := json.MarshalIndent(fleet, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return writeOutputOrStdout(rollupConfig.exportFile, string(data)+"\n")
+	}
+	return writeOutputOrStdout(rollupConfig.exportFile, renderFleetTable(fleet))
+}
+
+func renderFleetTable(f *rollup.Fleet) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\n=== Fleet Summary (%d clusters) ===\n\n", len(f.Clusters))
+	fmt.Fprintf(&b, "  Analyzed workloads: %d\n", f.AnalyzedWorkloads)
+	fmt.Fprintf(&b, "  Wasted CPU (requests): %s cores\n", units.Cores(f.TotalWastedCPU))
+	fmt.Fprintf(&b, "  Wasted memory (requests): %s\n", units.MemoryGi(f.TotalWastedMemoryGi*units.Gi))
+	if f.TotalCurrentMonthly > 0 {
+		fmt.Fprintf(&b, "  Estimated waste: %s (%.1f%% of %s requested)\n",
+			formatMonthlyCost(f.TotalWastedMonthly), f.SavingsPercent, formatMonthlyCost(f.TotalCurrentMonthly))
+	}
+	if len(f.Unpriced) > 0 {
+		fmt.Fprintf(&b, "  Not priced (exported without cost estimates): %s\n", strings.Join(f.Unpriced, ", "))
+	}
+
+	b.WriteString("\nClusters:\n")
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Cluster", "Generated", "Window", "Workloads", "Avg Skew", "Wasted CPU", "Wasted Mem", "Waste/mo", "Share"})
+	for i := range f.Clusters {
+		c := &f.Clusters[i]
+		waste := "-"
+		if c.Priced {
+			waste = formatMonthlyCost(c.WastedMonthly)
+		}
+		appendTableRowBestEffort(table, []string{
+			c.Cluster, c.GeneratedAt.Format("2006-01-02 15:04"), c.Window, strconv.Itoa(c.AnalyzedWorkloads),
+			fmt.Sprintf("%.1fx", c.AvgSkewCPU), units.Cores(c.WastedCPU), units.MemoryGi(c.WastedMemoryGi * units.Gi),
+			waste, fmt.Sprintf("%.1f%%", c.WasteShare),
+		})
+	}
+	renderTableBestEffort(table)
+
+	if len(f.TopOffenders) == 0 {
+		b.WriteString("\nNo over-provisioned workloads in these reports.\n")
+		return b.String()
+	}
+	b.WriteString("\nTop offenders across the fleet:\n")
+	table = tablewriter.NewWriter(&b)
+	table.Header([]string{"Cluster", "Namespace", "Workload", "Req CPU", "P95 CPU", "Skew", "Waste/mo", "Safety"})
+	for i := range f.TopOffenders {
+		o := &f.TopOffenders[i]
+		waste := "-"
+		if o.WastedMonthly > 0 {
+			waste = formatMonthlyCost(o.WastedMonthly)
+		}
+		appendTableRowBestEffort(table, []string{
+			o.Cluster, o.Namespace, o.Type + "/" + o.Workload,
+			units.Cores(o.RequestedCPU), units.Cores(o.P95UsedCPU), fmt.Sprintf("%.1fx", o.SkewCPU),
+			waste, o.Safety,
+		})
+	}
+	renderTableBestEffort(table)
+	return b.String()
+}
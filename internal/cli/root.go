@@ -1,12 +1,22 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/kubernetes"
 
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/settings"
+	"github.com/ppiankov/kubenow/internal/storage"
+	"github.com/ppiankov/kubenow/internal/units"
 	"github.com/ppiankov/kubenow/internal/util"
 )
 
@@ -27,11 +37,25 @@ func SetBuildInfo(v, c, d string) {
 
 var (
 	// Global flags
-	cfgFile     string
-	kubeconfig  string
-	kubecontext string
-	namespace   string
-	verbose     bool
+	cfgFile       string
+	kubeconfig    string
+	kubecontext   string
+	namespace     string
+	allNamespaces bool
+	asUser        string
+	asGroups      []string
+	asUID         string
+	verbose       bool
+	logLevel      string
+	logFormat     string
+	storageURI    string
+	profile       string
+
+	// Config file and active profile, resolved by initConfig; configErr
+	// is reported once the command runs
+	configFile    *settings.File
+	activeProfile string
+	configErr     error
 )
 
 // rootCmd represents the base command
@@ -40,69 +64,205 @@ var rootCmd = &cobra.Command{
 	Short: "Kubernetes cluster analyzer with LLM-powered triage and deterministic cost optimization",
 	Long: `kubenow is a powerful Kubernetes cluster analyzer that provides:
 
-• LLM-Powered Analysis: Incident triage, pod diagnosis, team reports, compliance checks
+• LLM-Powered Analysis: Incident triage, pod diagnosis, team reports, compliance checks, security hardening
 • Deterministic Analysis: Resource optimization, cluster topology simulation
 
 Features:
-  - Multi-mode LLM analysis (incident, pod, teamlead, compliance, chaos)
+  - Multi-mode LLM analysis (incident, pod, teamlead, compliance, chaos, security)
   - requests-skew: Identify over-provisioned resources
   - node-footprint: Simulate alternative cluster topologies
   - Watch mode for continuous monitoring
   - Multi-format export (JSON, Markdown, HTML)`,
 	Version: version,
+	// Fill flags not given on the command line from KUBENOW_* variables
+	// and the config file
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return applyConfig(cmd)
+	},
 	// Disable default completion command
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: true,
 	},
 }
 
-// Execute adds all child commands to the root command and sets flags appropriately
+// Execute adds all child commands to the root command and sets flags appropriately.
+// SIGINT and SIGTERM cancel the command's context and run the registered
+// cleanups (port-forwards, partial export files) before exiting.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := cleanup.HandleSignals(context.Background())
+	defer stop()
+	defer cleanup.Run()
+	registerCompletions(rootCmd)
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	logging.Setup(slog.LevelInfo, logging.FormatText)
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.kubenow.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "",
+		"config file (default is $KUBENOW_CONFIG, $HOME/.kubenow/config.yaml, or $HOME/.kubenow.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "",
+		"config file profile whose values override the file's defaults (default is $KUBENOW_PROFILE or the file's profile key)")
 	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "path to kubeconfig file (default is $KUBECONFIG or $HOME/.kube/config)")
 	rootCmd.PersistentFlags().StringVar(&kubecontext, "context", "", "kubeconfig context to use (default is current-context)")
 	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace to analyze (default is all namespaces)")
+	rootCmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
+		"analyze all namespaces, overriding -n and the config file")
+	rootCmd.PersistentFlags().StringVar(&asUser, "as", "", "username to impersonate for Kubernetes API requests (as kubectl --as)")
+	rootCmd.PersistentFlags().StringArrayVar(&asGroups, "as-group", nil,
+		"group to impersonate; repeat for several groups (as kubectl --as-group)")
+	rootCmd.PersistentFlags().StringVar(&asUID, "as-uid", "", "UID to impersonate (as kubectl --as-uid)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "",
+		"level of progress and diagnostics on stderr: debug, info, warn, or error (default info; debug with -v, warn with --silent)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText,
+		"format of progress and diagnostics on stderr: text, or json for one machine-parsable object per line")
+	rootCmd.PersistentFlags().StringVar(&storageURI, "storage", "",
+		"where latch results and trend snapshots are stored: a directory, s3://bucket/prefix, or configmap://namespace "+
+			"(default is $KUBENOW_STORAGE or ~/.kubenow)")
+	rootCmd.PersistentFlags().Float32("kube-qps", util.DefaultKubeQPS, "client-side Kubernetes API requests per second")
+	rootCmd.PersistentFlags().Int("kube-burst", util.DefaultKubeBurst, "client-side Kubernetes API request burst")
+	rootCmd.PersistentFlags().Duration("kube-cache-ttl", util.DefaultListCacheTTL,
+		"how long namespace, pod, and workload lists are shared between collectors (0 disables the cache)")
 
 	// Bind flags to viper
 	mustBindPFlag("kubeconfig", rootCmd.PersistentFlags().Lookup("kubeconfig"))
 	mustBindPFlag("context", rootCmd.PersistentFlags().Lookup("context"))
 	mustBindPFlag("namespace", rootCmd.PersistentFlags().Lookup("namespace"))
 	mustBindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	mustBindPFlag("storage", rootCmd.PersistentFlags().Lookup("storage"))
+	mustBindPFlag("kube-qps", rootCmd.PersistentFlags().Lookup("kube-qps"))
+	mustBindPFlag("kube-burst", rootCmd.PersistentFlags().Lookup("kube-burst"))
+	mustBindPFlag("kube-cache-ttl", rootCmd.PersistentFlags().Lookup("kube-cache-ttl"))
+
+	configureKubectlPlugin()
 }
 
 // initConfig reads in config file and ENV variables if set
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory
-		home, err := os.UserHomeDir()
-		if err != nil {
-			stderrf("Error finding home directory: %v\n", err)
-			os.Exit(1)
+	path := cfgFile
+	if path == "" {
+		path = os.Getenv(settings.EnvName("config"))
+	}
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = settings.DefaultPath(); err != nil {
+			configErr = err
 		}
+	}
 
-		// Search config in home directory with name ".kubenow" (without extension)
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".kubenow")
+	if _, err := os.Stat(path); path != "" && (explicit || err == nil) {
+		configFile, configErr = settings.Load(path)
+		if configErr == nil {
+			viper.SetConfigFile(path)
+			viper.SetConfigType("yaml")
+			if err := viper.ReadInConfig(); err != nil {
+				configErr = fmt.Errorf("invalid config %s: %w", path, err)
+			}
+		}
 	}
 
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in
-	if err := viper.ReadInConfig(); err == nil && verbose {
-		stderrf("Using config file: %s\n", viper.ConfigFileUsed())
+	// A profile overrides the file's top-level keys, including the
+	// units, keybindings, and storage sections read through viper
+	activeProfile = resolveProfile()
+	if activeProfile != "" && configErr == nil {
+		if configFile == nil {
+			configErr = fmt.Errorf("profile %q: no config file at %s", activeProfile, path)
+		} else if values, err := configFile.Profile(activeProfile); err != nil {
+			configErr = err
+		} else if err := viper.MergeConfigMap(values); err != nil {
+			configErr = fmt.Errorf("profile %q: %w", activeProfile, err)
+		}
+	}
+
+	units.Configure(GetUnitOptions())
+	storage.Configure(GetStorageURI(), storage.Options{
+		KubeClient: func() (kubernetes.Interface, error) {
+			return util.BuildKubeClientWithOpts(GetKubeOpts())
+		},
+	})
+}
+
+// resolveProfile returns the profile from --profile, $KUBENOW_PROFILE, or
+// the config file's profile key.
+func resolveProfile() string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv(settings.EnvProfile); env != "" {
+		return env
+	}
+	if configFile != nil {
+		return configFile.DefaultProfile()
+	}
+	return ""
+}
+
+// applyConfig fills the flags of cmd that were not given on the command
+// line: from KUBENOW_<FLAG> variables first, then from the config file
+// (top-level keys, then commands sections for cmd and its parents, each
+// overridden by the active profile's).
+func applyConfig(cmd *cobra.Command) error {
+	if configErr != nil {
+		return configErr
+	}
+	var values map[string]any
+	if configFile != nil {
+		var err error
+		commandPath := strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+		if values, err = configFile.FlagValues(activeProfile, commandPath); err != nil {
+			return err
+		}
+	}
+	if err := settings.Apply(cmd.Flags(), values, os.LookupEnv); err != nil {
+		return fmt.Errorf("invalid config value: %w", err)
+	}
+
+	if err := setupLogging(cmd); err != nil {
+		return err
+	}
+	if configFile != nil {
+		slog.Debug("Using config file", "path", viper.ConfigFileUsed(), "profile", activeProfile)
+	}
+	if asUser == "" && (len(asGroups) > 0 || asUID != "") {
+		return fmt.Errorf("--as-group and --as-uid require --as")
+	}
+	resolvePluginNamespace()
+	return nil
+}
+
+// setupLogging installs the stderr logger from --log-level and
+// --log-format. Without --log-level, -v selects debug and a command's
+// --silent selects warn, so --silent hides progress but not warnings.
+func setupLogging(cmd *cobra.Command) error {
+	format, err := logging.ParseFormat(logFormat)
+	if err != nil {
+		return err
+	}
+	level := slog.LevelInfo
+	switch {
+	case logLevel != "":
+		if level, err = logging.ParseLevel(logLevel); err != nil {
+			return err
+		}
+	case IsVerbose():
+		level = slog.LevelDebug
+	case isSilent(cmd):
+		level = slog.LevelWarn
 	}
+	logging.Setup(level, format)
+	return nil
+}
+
+// isSilent reports whether the command has a --silent flag that is set.
+func isSilent(cmd *cobra.Command) bool {
+	f := cmd.Flags().Lookup("silent")
+	return f != nil && f.Value.String() == "true"
 }
 
 func mustBindPFlag(key string, flag *pflag.Flag) {
@@ -127,23 +287,61 @@ func GetKubecontext() string {
 	return viper.GetString("context")
 }
 
-// GetKubeOpts returns combined kubeconfig + context options
+// GetKubeOpts returns combined kubeconfig, context, impersonation, rate
+// limit, and list cache options
 func GetKubeOpts() util.KubeOpts {
 	return util.KubeOpts{
-		Kubeconfig: GetKubeconfig(),
-		Context:    GetKubecontext(),
+		Kubeconfig:   GetKubeconfig(),
+		Context:      GetKubecontext(),
+		AsUser:       asUser,
+		AsGroups:     asGroups,
+		AsUID:        asUID,
+		QPS:          float32(viper.GetFloat64("kube-qps")),
+		Burst:        viper.GetInt("kube-burst"),
+		ListCacheTTL: viper.GetDuration("kube-cache-ttl"),
 	}
 }
 
-// GetNamespace returns the namespace from flags or viper
+// GetNamespace returns the namespace from flags or viper; empty (all
+// namespaces) with -A
 func GetNamespace() string {
+	if allNamespaces {
+		return ""
+	}
 	if namespace != "" {
 		return namespace
 	}
 	return viper.GetString("namespace")
 }
 
-// IsVerbose returns the verbose flag value
+// GetStorageURI returns the artifact store from flags, $KUBENOW_STORAGE, or viper
+func GetStorageURI() string {
+	if storageURI != "" {
+		return storageURI
+	}
+	if uri := os.Getenv("KUBENOW_STORAGE"); uri != "" {
+		return uri
+	}
+	return viper.GetString("storage")
+}
+
+// GetKeyBindings returns the key binding overrides for a TUI from the
+// keybindings.<tui> config section, keyed by action name.
+func GetKeyBindings(tui string) map[string][]string {
+	return viper.GetStringMapStringSlice("keybindings." + tui)
+}
+
+// GetUnitOptions returns how CPU and memory values are rendered, from the
+// units.precision and units.decimal_separator config keys.
+func GetUnitOptions() units.Options {
+	o := units.Options{Precision: -1, DecimalSeparator: viper.GetString("units.decimal_separator")}
+	if viper.IsSet("units.precision") {
+		o.Precision = viper.GetInt("units.precision")
+	}
+	return o
+}
+
+// IsVerbose returns the verbose flag value; --log-level debug implies it
 func IsVerbose() bool {
-	return verbose || viper.GetBool("verbose")
+	return verbose || viper.GetBool("verbose") || strings.EqualFold(logLevel, "debug")
 }
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/pipeline"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+var runConfig struct {
+	file       string
+	dryRun     bool
+	output     string
+	exportFile string
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a declarative analysis manifest",
+	Long: `Run the steps of an analysis manifest in order, so recurring analyses live in
+git and are reviewed like code instead of shell scripts full of flags.
+
+A manifest is a YAML file with a name, optional context and namespace applied
+to every step, and a list of steps. Each step sets exactly one of:
+
+  snapshot   collect a cluster snapshot to a file (kubenow --save-snapshot)
+  analyze    run a kubenow analyze subcommand (requests-skew, oom, orphans, ...)
+  llm        run an LLM mode on the latest snapshot (default, pod, incident,
+             teamlead, compliance, chaos, security, or auto)
+  gate       check a value in a JSON report written by an earlier step
+  notify     send failed steps and gates (or a passing summary) to webhooks
+
+Step flags are the command's own flags without the leading dashes. ${VAR}
+references are read from the environment, so API keys stay out of the file.
+
+A failed snapshot, analyze, or llm step stops the run unless it sets
+continue_on_error. A failed gate does not stop the run, so a later notify step
+can report it; kubenow exits with code 1 when any gate failed.
+
+Example manifest:
+
+  version: 1
+  name: weekly-prod
+  context: prod
+  steps:
+    - name: collect
+      snapshot:
+        output: snapshot.json
+    - name: skew
+      analyze:
+        type: requests-skew
+        flags:
+          prometheus-url: http://prometheus:9090
+          output: json
+          export-file: skew.json
+    - name: triage
+      llm:
+        mode: auto
+        flags:
+          llm-provider: anthropic
+          api-key: ${ANTHROPIC_API_KEY}
+    - name: no-unsafe-workloads
+      gate:
+        file: skew.json
+        path: results
+        where:
+          safety.rating: UNSAFE
+        max: 0
+    - name: alert
+      notify:
+        sinks:
+          - url: ${SLACK_WEBHOOK_URL}
+
+Examples:
+  # Run a manifest
+  kubenow run -f analysis.yaml
+
+  # Show each step's command line without running anything
+  kubenow run -f analysis.yaml --dry-run`,
+	RunE: runManifest,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVarP(&runConfig.file, "file", "f", "", "Analysis manifest (YAML)")
+	runCmd.Flags().BoolVar(&runConfig.dryRun, "dry-run", false, "Validate the manifest and print step command lines without running them")
+	runCmd.Flags().StringVar(&runConfig.output, "output", "table", "Step summary format: table|json")
+	runCmd.Flags().StringVar(&runConfig.exportFile, "export-file", "", "Write the step summary to file instead of stdout")
+	_ = runCmd.MarkFlagRequired("file")
+}
+
+func runManifest(cmd *cobra.Command, _ []string) error {
+	if runConfig.output != "table" && runConfig.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", runConfig.output)
+	}
+	m, err := pipeline.Load(runConfig.file)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true // from here on, errors come from steps, not flags
+
+	runner := &pipeline.Runner{
+		Exec:   newStepExecutor(),
+		DryRun: runConfig.dryRun,
+		Progress: func(i int, s *pipeline.Step) {
+			logging.Progress(slog.Default(), "Step", i+1, len(m.Steps), "name", s.Name, "kind", s.Kind())
+		},
+	}
+	result := runner.Run(cmd.Context(), m)
+
+	summary := renderRunTable(result)
+	if runConfig.output == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		summary = string(data) + "\n"
+	}
+	if err := writeOutputOrStdout(runConfig.exportFile, summary); err != nil {
+		return err
+	}
+
+	if result.Failed {
+		return fmt.Errorf("manifest %q stopped at failed step %q", m.Name, result.FailedStep())
+	}
+	if result.GatesFailed > 0 {
+		slog.Error("Gates failed", "gates", result.GatesFailed)
+		util.Exit(util.ExitPolicyFail)
+	}
+	return nil
+}
+
+// newStepExecutor returns a function that runs a kubenow command line
+// in-process. Commands keep their flag values in package variables, so every
+// flag a previous step set is reset to its default first, and the global
+// flags go back to the values `kubenow run` was started with.
+func newStepExecutor() func(ctx context.Context, args []string) error {
+	globals := map[string]string{}
+	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		globals[f.Name] = f.Value.String()
+	})
+
+	return func(ctx context.Context, args []string) error {
+		target, _, err := rootCmd.Find(args)
+		if err != nil {
+			return err
+		}
+		if target == rootCmd || !target.Runnable() {
+			return fmt.Errorf("unknown command %q", strings.Join(args[:min(2, len(args))], " "))
+		}
+		var flagErr error
+		target.Flags().VisitAll(func(f *pflag.Flag) {
+			if err := resetFlag(f); err != nil && flagErr == nil {
+				flagErr = err
+			}
+		})
+		rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+			if value, ok := globals[f.Name]; ok && flagErr == nil {
+				if err := setFlagValue(f, value); err != nil {
+					flagErr = fmt.Errorf("restore global --%s=%q: %w", f.Name, value, err)
+				}
+			}
+		})
+		if flagErr != nil {
+			return fmt.Errorf("%w: %w", pipeline.ErrAbort, flagErr)
+		}
+
+		silenceUsage, silenceErrors := rootCmd.SilenceUsage, rootCmd.SilenceErrors
+		rootCmd.SilenceUsage, rootCmd.SilenceErrors = true, true
+		defer func() {
+			rootCmd.SilenceUsage, rootCmd.SilenceErrors = silenceUsage, silenceErrors
+		}()
+		rootCmd.SetArgs(args)
+		return rootCmd.ExecuteContext(ctx)
+	}
+}
+
+// resetFlag restores a flag set by an earlier step to its default.
+func resetFlag(f *pflag.Flag) error {
+	if !f.Changed {
+		return nil
+	}
+	if err := setFlagValue(f, f.DefValue); err != nil {
+		return fmt.Errorf("reset --%s to %q: %w", f.Name, f.DefValue, err)
+	}
+	f.Changed = false
+	return nil
+}
+
+// setFlagValue sets a flag without marking it changed. Slice flags are
+// replaced rather than appended to.
+func setFlagValue(f *pflag.Flag, value string) error {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		var items []string
+		if trimmed := strings.Trim(value, "[]"); trimmed != "" {
+			items = strings.Split(trimmed, ",")
+		}
+		return sv.Replace(items)
+	}
+	return f.Value.Set(value)
+}
+
+func renderRunTable(r *pipeline.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== kubenow run: %s ===\n\n", r.Name)
+
+	table := tablewriter.NewWriter(&b)
+	table.Header([]string{"Step", "Kind", "Status", "Duration", "Details"})
+	for i := range r.Steps {
+		s := &r.Steps[i]
+		details := s.Message
+		if details == "" && len(s.Args) > 0 {
+			details = "kubenow " + strings.Join(s.Args, " ")
+		}
+		appendTableRowBestEffort(table, []string{s.Name, s.Kind, strings.ToUpper(s.Status), s.Duration, details})
+	}
+	renderTableBestEffort(table)
+	return b.String()
+}
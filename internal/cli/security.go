@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var securityConfig LLMCommandConfig
+
+var securityCmd = &cobra.Command{
+	Use:   "security",
+	Short: "Security hardening report using LLM",
+	Long: `Produce a prioritized security hardening report using LLM.
+
+The snapshot additionally includes the cluster's security posture: roles bound
+to ServiceAccounts, over-broad ClusterRoles (wildcards, secrets access, exec,
+escalate/bind/impersonate), workloads running privileged or with host
+namespaces and hostPath mounts, and each namespace's Pod Security level and
+NetworkPolicy count. Listing RBAC objects, Namespaces, and NetworkPolicies is
+best-effort: parts the credentials cannot read are left out.
+
+Examples:
+  # Cluster-wide hardening report
+  kubenow security --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b
+
+  # One namespace
+  kubenow --namespace production security --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b
+
+  # Export the report
+  kubenow security --llm-endpoint http://localhost:11434/v1 --model mixtral:8x22b --output security.md`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		securityConfig.Mode = "security"
+		if err := RunLLMCommand(cmd, &securityConfig); err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(securityCmd)
+	addLLMFlags(securityCmd, &securityConfig)
+}
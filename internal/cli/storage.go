@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/kubenow/internal/audit"
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/storage"
+)
+
+// kindAudit names audit bundles in --retain. Bundles live under the policy
+// audit path rather than the artifact store, and are only pruned on request.
+const kindAudit = "audit"
+
+var storageGCConfig struct {
+	retain    []string
+	keepLast  int
+	auditPath string
+	dryRun    bool
+	output    string
+}
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Manage persisted kubenow artifacts",
+	Long: `Manage the artifacts kubenow persists between runs: latch results from
+pro-monitor, trend snapshots from requests-skew --track-trends, and evidence
+pages shared from pro-monitor.
+
+Artifacts are stored under ~/.kubenow unless --storage (or $KUBENOW_STORAGE)
+selects another backend:
+
+  /path/to/dir            local directory
+  s3://bucket/prefix      S3 or S3-compatible bucket (?region=..., ?endpoint=https://minio:9000)
+  configmap://namespace   one ConfigMap per artifact, labeled app.kubernetes.io/managed-by=kubenow
+
+S3 credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+AWS_SESSION_TOKEN.`,
+}
+
+var storageGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete artifacts past their retention",
+	Long: `Delete artifacts older than their kind's retention. Defaults: latch results
+and shared evidence pages are kept 30 days, trend snapshots 365 days (the
+newest one is always kept), and checkpoints of unfinished latches 7 days.
+Audit bundles are never pruned unless --retain audit=... and --audit-path are
+given, since they are the record of applied changes.
+
+Examples:
+  # Preview what would be deleted
+  kubenow storage gc --dry-run
+
+  # Keep latch results for a week and trend history for 90 days
+  kubenow storage gc --retain latch=7d --retain trends=90d
+
+  # Prune audit bundles older than 400 days, keeping the newest 50
+  kubenow storage gc --retain audit=400d --audit-path /var/lib/kubenow/audit --keep-last 50
+
+  # Garbage-collect an S3 store
+  kubenow storage gc --storage s3://ops-artifacts/kubenow`,
+	Args: cobra.NoArgs,
+	RunE: runStorageGC,
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+	storageCmd.AddCommand(storageGCCmd)
+
+	f := storageGCCmd.Flags()
+	f.StringSliceVar(&storageGCConfig.retain, "retain", nil, "Retention per kind as kind=age (kinds: latch, trends, shares, sessions, audit; e.g. latch=7d)")
+	f.IntVar(&storageGCConfig.keepLast, "keep-last", 0, "Always keep the newest N artifacts of each kind (overrides the default)")
+	f.StringVar(&storageGCConfig.auditPath, "audit-path", "", "Audit bundle directory, required to prune audit bundles")
+	f.BoolVar(&storageGCConfig.dryRun, "dry-run", false, "List what would be deleted without deleting")
+	f.StringVar(&storageGCConfig.output, "output", "table", "Output format: table|json")
+}
+
+// storageGCReport is the JSON output of storage gc.
+type storageGCReport struct {
+	Store        string            `json:"store"`
+	Artifacts    *storage.GCResult `json:"artifacts"`
+	AuditBundles []string          `json:"audit_bundles,omitempty"`
+}
+
+func runStorageGC(cmd *cobra.Command, _ []string) error {
+	cfg := &storageGCConfig
+	if cfg.output != "table" && cfg.output != "json" {
+		return fmt.Errorf("invalid --output %q: must be table or json", cfg.output)
+	}
+	if cfg.keepLast < 0 {
+		return fmt.Errorf("--keep-last must not be negative")
+	}
+	policy, auditRetention, err := parseRetention(cfg.retain)
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("keep-last") {
+		for kind, r := range policy {
+			r.KeepLast = cfg.keepLast
+			policy[kind] = r
+		}
+		auditRetention.KeepLast = cfg.keepLast
+	}
+	if auditRetention.MaxAge > 0 && cfg.auditPath == "" {
+		return fmt.Errorf("--retain audit=... requires --audit-path")
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+
+	now := time.Now()
+	report := storageGCReport{Store: GetStorageURI()}
+	if report.Store == "" {
+		report.Store = "~/.kubenow"
+	}
+	report.Artifacts, err = storage.GC(context.Background(), store, policy, now, cfg.dryRun)
+	if err != nil {
+		return fmt.Errorf("storage gc failed: %w", err)
+	}
+	if auditRetention.MaxAge > 0 {
+		report.AuditBundles, err = audit.PruneBundles(cfg.auditPath, auditRetention, now, cfg.dryRun)
+		if err != nil {
+			return fmt.Errorf("audit bundle pruning failed: %w", err)
+		}
+	}
+
+	if cfg.output == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		printOut(string(data) + "\n")
+		return nil
+	}
+	printOut(renderStorageGC(&report, store, now))
+	return nil
+}
+
+// parseRetention applies --retain overrides to the default retention and
+// returns the audit bundle retention separately.
+func parseRetention(specs []string) (map[string]storage.Retention, storage.Retention, error) {
+	policy := storage.DefaultRetention()
+	var auditRetention storage.Retention
+	for _, spec := range specs {
+		kind, age, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, auditRetention, fmt.Errorf("invalid --retain %q: expected kind=age", spec)
+		}
+		d, err := metrics.ParseDuration(age)
+		if err != nil || d <= 0 {
+			return nil, auditRetention, fmt.Errorf("invalid --retain %q: age must be a positive duration like 30d", spec)
+		}
+		switch kind {
+		case kindAudit:
+			auditRetention.MaxAge = d
+		case storage.KindLatch, storage.KindTrends, storage.KindShares, storage.KindSessions:
+			r := policy[kind]
+			r.MaxAge = d
+			policy[kind] = r
+		default:
+			return nil, auditRetention, fmt.Errorf("invalid --retain %q: kind must be latch, trends, shares, sessions, or audit", spec)
+		}
+	}
+	return policy, auditRetention, nil
+}
+
+func renderStorageGC(r *storageGCReport, store storage.Store, now time.Time) string {
+	var b strings.Builder
+	verb := "Deleted"
+	if r.Artifacts.DryRun {
+		verb = "Would delete"
+	}
+
+	fmt.Fprintf(&b, "\n=== Storage GC (%s) ===\n\n", r.Store)
+	if len(r.Artifacts.Deleted) > 0 {
+		table := tablewriter.NewWriter(&b)
+		table.Header([]string{"Kind", "Artifact", "Age", "Size"})
+		for _, obj := range r.Artifacts.Deleted {
+			appendTableRowBestEffort(table, []string{
+				storage.KindOf(obj.Key), store.Location(obj.Key),
+				formatDuration(now.Sub(obj.ModTime)), strconv.FormatInt(obj.Size, 10),
+			})
+		}
+		renderTableBestEffort(table)
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%s %d of %d artifact(s), %d bytes; %d kept\n",
+		verb, len(r.Artifacts.Deleted), r.Artifacts.Scanned, r.Artifacts.FreedBytes, r.Artifacts.Kept)
+	for _, dir := range r.AuditBundles {
+		fmt.Fprintf(&b, "%s audit bundle %s\n", verb, dir)
+	}
+	return b.String()
+}
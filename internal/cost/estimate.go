@@ -1,6 +1,9 @@
 package cost
 
-import "math"
+import (
+	"math"
+	"sort"
+)
 
 // WorkloadCostEstimate holds per-workload cost impact.
 type WorkloadCostEstimate struct {
@@ -18,6 +21,15 @@ type SummaryCostEstimate struct {
 	Rates               Rates   `json:"rates"`
 }
 
+// NamespaceCostEstimate holds the aggregate cost impact of one namespace.
+type NamespaceCostEstimate struct {
+	Namespace          string  `json:"namespace"`
+	Workloads          int     `json:"workloads"`
+	CurrentMonthlyCost float64 `json:"current_monthly_cost"`
+	WastedMonthly      float64 `json:"wasted_monthly"`
+	SavingsPercent     float64 `json:"savings_percent"`
+}
+
 // EstimateWorkload computes the cost impact for a single workload based on
 // the difference between requested and observed (P95) resource usage.
 func EstimateWorkload(requestedCPU, p95CPU, requestedMemGi, p95MemGi float64, rates Rates) WorkloadCostEstimate {
@@ -73,6 +85,53 @@ func EstimateSummary(wastedCPU, wastedMemGi, totalRequestedCPU, totalRequestedMe
 	}
 }
 
+// EstimateNamespaces sums per-workload estimates by namespace, sorted by
+// wasted spend (highest first, then by name).
+func EstimateNamespaces(byNamespace map[string][]WorkloadCostEstimate) []NamespaceCostEstimate {
+	out := make([]NamespaceCostEstimate, 0, len(byNamespace))
+	for ns, estimates := range byNamespace {
+		var current, wasted float64
+		for _, e := range estimates {
+			current += e.CurrentMonthlyCost
+			wasted += e.WastedMonthly
+		}
+		var savingsPct float64
+		if current > 0 {
+			savingsPct = wasted / current * 100
+		}
+		out = append(out, NamespaceCostEstimate{
+			Namespace:          ns,
+			Workloads:          len(estimates),
+			CurrentMonthlyCost: roundCents(current),
+			WastedMonthly:      roundCents(wasted),
+			SavingsPercent:     math.Round(savingsPct*10) / 10,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].WastedMonthly != out[j].WastedMonthly {
+			return out[i].WastedMonthly > out[j].WastedMonthly
+		}
+		return out[i].Namespace < out[j].Namespace
+	})
+	return out
+}
+
+// EstimateIdleSavings returns the monthly cost of requests that would be
+// released by scaling a workload to zero for idleFraction of the time.
+func EstimateIdleSavings(requestedCPU, requestedMemGi, idleFraction float64, rates Rates) float64 {
+	return roundCents(monthlyCost(requestedCPU, requestedMemGi, rates) * math.Max(0, math.Min(idleFraction, 1)))
+}
+
+// MonthlyCost returns the monthly cost of the given CPU cores and GiB of
+// memory, e.g. a node's allocatable capacity.
+func MonthlyCost(cpu, memGi float64, rates Rates) float64 {
+	return roundCents(monthlyCost(cpu, memGi, rates))
+}
+
+func monthlyCost(cpu, memGi float64, rates Rates) float64 {
+	return (cpu*rates.CPUPerCoreHour + memGi*rates.MemoryPerGiBHour) * hoursPerMonth
+}
+
 // roundCents rounds to the nearest cent.
 func roundCents(v float64) float64 {
 	return math.Round(v*100) / 100
@@ -5,7 +5,7 @@ package cost
 type Rates struct {
 	CPUPerCoreHour   float64 `json:"cpu_per_core_hour"`
 	MemoryPerGiBHour float64 `json:"memory_per_gib_hour"`
-	Source           string  `json:"source"` // "user", "instance-type", "default"
+	Source           string  `json:"source"` // "user", "instance-type", "node-types", "default"
 }
 
 // hoursPerMonth is the standard cloud billing constant (365.25/12 × 24).
@@ -17,6 +17,9 @@ const sourceUser = "user"
 // sourceInstanceType identifies rates looked up from the pricing table.
 const sourceInstanceType = "instance-type"
 
+// sourceNodeTypes identifies rates blended from the cluster's node instance types.
+const sourceNodeTypes = "node-types"
+
 // sourceDefault identifies fallback rates when no other source is available.
 const sourceDefault = "default"
 
@@ -73,6 +76,44 @@ func ResolveRates(instanceType string, customCPU, customMem float64) Rates {
 	return DefaultRates()
 }
 
+// NodeType is one node's instance type and allocatable capacity, used to
+// blend per-node-type pricing into cluster-wide rates.
+type NodeType struct {
+	InstanceType string
+	CPUCores     float64
+	MemoryGi     float64
+}
+
+// BlendRates returns capacity-weighted rates across nodes whose instance type
+// is in the pricing table: the CPU rate is weighted by cores and the memory
+// rate by GiB, so a cluster of mostly r5 nodes prices memory like r5.
+// Returns false if no node has a known instance type.
+func BlendRates(nodes []NodeType) (Rates, bool) {
+	var cpuCost, cpuCores, memCost, memGi float64
+	for _, n := range nodes {
+		r, ok := LookupRates(n.InstanceType)
+		if !ok {
+			continue
+		}
+		cpuCost += r.CPUPerCoreHour * n.CPUCores
+		cpuCores += n.CPUCores
+		memCost += r.MemoryPerGiBHour * n.MemoryGi
+		memGi += n.MemoryGi
+	}
+	if cpuCores == 0 && memGi == 0 {
+		return Rates{}, false
+	}
+
+	rates := Rates{Source: sourceNodeTypes}
+	if cpuCores > 0 {
+		rates.CPUPerCoreHour = cpuCost / cpuCores
+	}
+	if memGi > 0 {
+		rates.MemoryPerGiBHour = memCost / memGi
+	}
+	return rates, true
+}
+
 // pricingTable maps instance types to derived per-unit-hour rates.
 // Rates are derived from public on-demand pricing (us-east-1 / us-central1)
 // as of Feb 2026. CPU rate = price / vCPUs / hour. Memory rate is the
@@ -81,32 +122,73 @@ func ResolveRates(instanceType string, customCPU, customMem float64) Rates {
 // These are estimates for guidance only, not billing-grade data.
 var pricingTable = map[string]Rates{
 	// AWS EC2 — compute-optimized
-	"c5.large":   {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
-	"c5.xlarge":  {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
-	"c5.2xlarge": {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
-	"c5.4xlarge": {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"c5.large":    {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"c5.xlarge":   {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"c5.2xlarge":  {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"c5.4xlarge":  {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"c6i.large":   {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"c6i.xlarge":  {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"c6i.2xlarge": {CPUPerCoreHour: 0.0425, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
 
 	// AWS EC2 — general-purpose
-	"m5.large":   {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
-	"m5.xlarge":  {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
-	"m5.2xlarge": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"m5.large":    {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"m5.xlarge":   {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"m5.2xlarge":  {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"m6i.large":   {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"m6i.xlarge":  {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"m6i.2xlarge": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"m7g.large":   {CPUPerCoreHour: 0.041, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
+	"m7g.xlarge":  {CPUPerCoreHour: 0.041, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
+	"m7g.2xlarge": {CPUPerCoreHour: 0.041, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
+
+	// AWS EC2 — burstable
+	"t3.medium":  {CPUPerCoreHour: 0.0208, MemoryPerGiBHour: 0.0052, Source: sourceInstanceType},
+	"t3.large":   {CPUPerCoreHour: 0.0208, MemoryPerGiBHour: 0.0052, Source: sourceInstanceType},
+	"t3.xlarge":  {CPUPerCoreHour: 0.0208, MemoryPerGiBHour: 0.0052, Source: sourceInstanceType},
+	"t3.2xlarge": {CPUPerCoreHour: 0.0208, MemoryPerGiBHour: 0.0052, Source: sourceInstanceType},
 
 	// AWS EC2 — memory-optimized
-	"r5.large":   {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
-	"r5.xlarge":  {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
-	"r5.2xlarge": {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"r5.large":    {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"r5.xlarge":   {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"r5.2xlarge":  {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"r6i.large":   {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"r6i.xlarge":  {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"r6i.2xlarge": {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
 
 	// GCP — general-purpose
-	"n2-standard-2": {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
-	"n2-standard-4": {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
-	"n2-standard-8": {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
+	"n2-standard-2":  {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
+	"n2-standard-4":  {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
+	"n2-standard-8":  {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.005, Source: sourceInstanceType},
+	"n2d-standard-2": {CPUPerCoreHour: 0.030, MemoryPerGiBHour: 0.004, Source: sourceInstanceType},
+	"n2d-standard-4": {CPUPerCoreHour: 0.030, MemoryPerGiBHour: 0.004, Source: sourceInstanceType},
+	"n2d-standard-8": {CPUPerCoreHour: 0.030, MemoryPerGiBHour: 0.004, Source: sourceInstanceType},
+
+	// GCP — memory-optimized
+	"n2-highmem-2": {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.0047, Source: sourceInstanceType},
+	"n2-highmem-4": {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.0047, Source: sourceInstanceType},
+	"n2-highmem-8": {CPUPerCoreHour: 0.035, MemoryPerGiBHour: 0.0047, Source: sourceInstanceType},
 
 	// GCP — cost-optimized
+	"e2-medium":     {CPUPerCoreHour: 0.025, MemoryPerGiBHour: 0.003, Source: sourceInstanceType},
 	"e2-standard-2": {CPUPerCoreHour: 0.025, MemoryPerGiBHour: 0.003, Source: sourceInstanceType},
 	"e2-standard-4": {CPUPerCoreHour: 0.025, MemoryPerGiBHour: 0.003, Source: sourceInstanceType},
+	"e2-standard-8": {CPUPerCoreHour: 0.025, MemoryPerGiBHour: 0.003, Source: sourceInstanceType},
 
 	// Azure — general-purpose
 	"Standard_D2s_v3": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
 	"Standard_D4s_v3": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
 	"Standard_D8s_v3": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"Standard_D2s_v5": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"Standard_D4s_v5": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+	"Standard_D8s_v5": {CPUPerCoreHour: 0.048, MemoryPerGiBHour: 0.006, Source: sourceInstanceType},
+
+	// Azure — compute-optimized
+	"Standard_F2s_v2": {CPUPerCoreHour: 0.0423, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"Standard_F4s_v2": {CPUPerCoreHour: 0.0423, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+	"Standard_F8s_v2": {CPUPerCoreHour: 0.0423, MemoryPerGiBHour: 0.0, Source: sourceInstanceType},
+
+	// Azure — memory-optimized
+	"Standard_E2s_v5": {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"Standard_E4s_v5": {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
+	"Standard_E8s_v5": {CPUPerCoreHour: 0.063, MemoryPerGiBHour: 0.008, Source: sourceInstanceType},
 }
@@ -214,3 +214,102 @@ func TestEstimateSummary_NegativeWaste(t *testing.T) {
 		t.Errorf("expected $0 wasted for negative waste, got $%.2f", summary.TotalWastedMonthly)
 	}
 }
+
+func TestBlendRates_WeightedByCapacity(t *testing.T) {
+	// 8 cores of c5 (0.0425/core, 0/GiB) and 8 cores of r5 (0.063/core, 0.008/GiB, 64Gi)
+	r, ok := BlendRates([]NodeType{
+		{InstanceType: "c5.2xlarge", CPUCores: 8, MemoryGi: 16},
+		{InstanceType: "r5.2xlarge", CPUCores: 8, MemoryGi: 64},
+		{InstanceType: "custom-metal", CPUCores: 64, MemoryGi: 512},
+	})
+	if !ok {
+		t.Fatal("expected blended rates")
+	}
+	if r.Source != sourceNodeTypes {
+		t.Errorf("expected source %q, got %q", sourceNodeTypes, r.Source)
+	}
+
+	expectedCPU := (0.0425*8 + 0.063*8) / 16
+	if math.Abs(r.CPUPerCoreHour-expectedCPU) > 1e-9 {
+		t.Errorf("expected CPU rate %f, got %f", expectedCPU, r.CPUPerCoreHour)
+	}
+	expectedMem := (0.0*16 + 0.008*64) / 80
+	if math.Abs(r.MemoryPerGiBHour-expectedMem) > 1e-9 {
+		t.Errorf("expected memory rate %f, got %f", expectedMem, r.MemoryPerGiBHour)
+	}
+}
+
+func TestBlendRates_NoKnownTypes(t *testing.T) {
+	if _, ok := BlendRates([]NodeType{{InstanceType: "unknown", CPUCores: 4, MemoryGi: 16}}); ok {
+		t.Error("expected no rates for unknown instance types")
+	}
+	if _, ok := BlendRates(nil); ok {
+		t.Error("expected no rates for empty node list")
+	}
+}
+
+func TestLookupRates_AllProviders(t *testing.T) {
+	for _, it := range []string{"m6i.xlarge", "t3.large", "n2d-standard-4", "n2-highmem-4", "Standard_E4s_v5", "Standard_F4s_v2"} {
+		if _, ok := LookupRates(it); !ok {
+			t.Errorf("expected %s to be in the pricing table", it)
+		}
+	}
+}
+
+func TestEstimateNamespaces(t *testing.T) {
+	byNS := map[string][]WorkloadCostEstimate{
+		"small": {{CurrentMonthlyCost: 10, WastedMonthly: 2}},
+		"big": {
+			{CurrentMonthlyCost: 100, WastedMonthly: 60},
+			{CurrentMonthlyCost: 50, WastedMonthly: 15},
+		},
+		"a-zero": {{CurrentMonthlyCost: 5, WastedMonthly: 0}},
+		"b-zero": {{CurrentMonthlyCost: 0, WastedMonthly: 0}},
+	}
+
+	got := EstimateNamespaces(byNS)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 namespaces, got %d", len(got))
+	}
+	if got[0].Namespace != "big" || got[1].Namespace != "small" {
+		t.Errorf("expected big, small first, got %s, %s", got[0].Namespace, got[1].Namespace)
+	}
+	if got[2].Namespace != "a-zero" || got[3].Namespace != "b-zero" {
+		t.Errorf("expected ties ordered by name, got %s, %s", got[2].Namespace, got[3].Namespace)
+	}
+	if got[0].Workloads != 2 || got[0].WastedMonthly != 75 || got[0].CurrentMonthlyCost != 150 {
+		t.Errorf("unexpected big totals: %+v", got[0])
+	}
+	if got[0].SavingsPercent != 50 {
+		t.Errorf("expected 50%% savings, got %.1f%%", got[0].SavingsPercent)
+	}
+	if got[3].SavingsPercent != 0 {
+		t.Errorf("expected 0%% savings for zero cost, got %.1f%%", got[3].SavingsPercent)
+	}
+}
+
+func TestEstimateIdleSavings(t *testing.T) {
+	rates := DefaultRates() // 0.031 CPU, 0.004 mem
+
+	// (2 * 0.031 + 4 * 0.004) * 730 = 56.94 per month; half of it idle
+	if got := EstimateIdleSavings(2, 4, 0.5, rates); got != 28.47 {
+		t.Errorf("expected 28.47, got %v", got)
+	}
+	if got := EstimateIdleSavings(2, 4, 0, rates); got != 0 {
+		t.Errorf("expected 0 for no idle time, got %v", got)
+	}
+	if got := EstimateIdleSavings(2, 4, 1.5, rates); got != 56.94 {
+		t.Errorf("expected idle fraction to be capped at 1, got %v", got)
+	}
+}
+
+func TestMonthlyCost(t *testing.T) {
+	rates := DefaultRates()
+
+	if got := MonthlyCost(2, 4, rates); got != 56.94 {
+		t.Errorf("expected 56.94, got %v", got)
+	}
+	if got := MonthlyCost(0, 0, rates); got != 0 {
+		t.Errorf("expected 0 for no capacity, got %v", got)
+	}
+}
@@ -8,17 +8,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ppiankov/kubenow/internal/ack"
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/owners"
+	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/redact"
 	"github.com/ppiankov/kubenow/internal/snapshot"
 )
 
 // Format represents the export format type.
 type Format string
 
-// FormatJSON, FormatHTML, FormatMarkdown, and FormatText define supported export formats.
+// FormatJSON, FormatHTML, FormatMarkdown, FormatSARIF, FormatJUnit, and FormatText define supported export formats.
 const (
 	FormatJSON     Format = "json"
 	FormatHTML     Format = "html"
 	FormatMarkdown Format = "markdown"
+	FormatSARIF    Format = "sarif"
+	FormatJUnit    Format = "junit"
 	FormatText     Format = "text"
 )
 
@@ -26,11 +33,32 @@ const (
 //
 //revive:disable-next-line:exported
 type ExportMetadata struct {
-	GeneratedAt    time.Time        `json:"generatedAt"`
-	KubenowVersion string           `json:"kubenowVersion"`
-	ClusterName    string           `json:"clusterName,omitempty"`
-	Mode           string           `json:"mode"`
-	Filters        snapshot.Filters `json:"filters,omitempty"`
+	GeneratedAt    time.Time          `json:"generatedAt"`
+	KubenowVersion string             `json:"kubenowVersion"`
+	ClusterName    string             `json:"clusterName,omitempty"`
+	Mode           string             `json:"mode"`
+	AutoMode       bool               `json:"autoMode,omitempty"`   // mode was picked by --mode auto
+	ModeReason     string             `json:"modeReason,omitempty"` // why --mode auto picked it
+	Filters        snapshot.Filters   `json:"filters,omitempty"`
+	Truncation     *prompt.Truncation `json:"truncation,omitempty"` // snapshot content cut to fit the prompt budget
+	Redaction      *redact.Summary    `json:"redaction,omitempty"`  // secrets and PII masked before the LLM call
+	LLMCost        *llm.Cost          `json:"llmCost,omitempty"`    // tokens and cost of the LLM calls; nil for a cached answer
+
+	// Acknowledged lists known accepted problems that were left out of the
+	// analysis; reports show them in their own section.
+	Acknowledged []ack.Problem `json:"acknowledged,omitempty"`
+
+	// Owners lists the problem pods per owning team (--owners-file), so the
+	// report can be split and routed per team.
+	Owners []owners.Assignment `json:"owners,omitempty"`
+}
+
+// ModeLabel returns the mode, annotated with the selection reason when it was auto-selected.
+func (m *ExportMetadata) ModeLabel() string {
+	if !m.AutoMode {
+		return m.Mode
+	}
+	return fmt.Sprintf("%s (auto: %s)", m.Mode, m.ModeReason)
 }
 
 // Exporter handles exporting results in various formats.
@@ -49,6 +77,10 @@ func DetectFormat(path string) Format {
 		return FormatHTML
 	case ".md", ".markdown":
 		return FormatMarkdown
+	case ".sarif":
+		return FormatSARIF
+	case ".xml":
+		return FormatJUnit
 	default:
 		return FormatText
 	}
@@ -63,6 +95,10 @@ func (e *Exporter) Export(result interface{}, w io.Writer) error {
 		return e.exportMarkdown(result, w)
 	case FormatHTML:
 		return e.exportHTML(result, w)
+	case FormatSARIF:
+		return exportSARIF(result, &e.Metadata, w)
+	case FormatJUnit:
+		return exportJUnit(result, &e.Metadata, w)
 	case FormatText:
 		return e.exportText(result, w)
 	default:
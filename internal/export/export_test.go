@@ -10,6 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ppiankov/kubenow/internal/ack"
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/owners"
+	"github.com/ppiankov/kubenow/internal/prompt"
 	"github.com/ppiankov/kubenow/internal/result"
 )
 
@@ -23,6 +27,8 @@ func TestDetectFormat(t *testing.T) {
 		{"markdown extension", "output.md", FormatMarkdown},
 		{"markdown full", "output.markdown", FormatMarkdown},
 		{"html extension", "output.html", FormatHTML},
+		{"sarif extension", "output.sarif", FormatSARIF},
+		{"junit extension", "report.xml", FormatJUnit},
 		{"text extension", "output.txt", FormatText},
 		{"unknown extension", "output.xyz", FormatText},
 		{"no extension", "output", FormatText},
@@ -56,6 +62,105 @@ func TestExportJSON(t *testing.T) {
 	assert.Equal(t, exporter.Metadata.Mode, decoded.Metadata.Mode)
 }
 
+func TestExportJSON_AutoMode(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := Exporter{
+		Format: FormatJSON,
+		Metadata: ExportMetadata{
+			Mode:       "incident",
+			AutoMode:   true,
+			ModeReason: "2 fatal problem(s) detected",
+		},
+	}
+
+	require.NoError(t, exporter.Export(map[string]string{"status": "ok"}, &buf))
+
+	var decoded JSONExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.True(t, decoded.Metadata.AutoMode)
+	assert.Equal(t, "2 fatal problem(s) detected", decoded.Metadata.ModeReason)
+	assert.Equal(t, "incident (auto: 2 fatal problem(s) detected)", decoded.Metadata.ModeLabel())
+	assert.Equal(t, "default", (&ExportMetadata{Mode: "default"}).ModeLabel())
+}
+
+func TestExport_Truncation(t *testing.T) {
+	meta := ExportMetadata{
+		Mode:       "default",
+		Truncation: &prompt.Truncation{BudgetTokens: 6000, OriginalTokens: 9000, FinalTokens: 5900, LogLinesDropped: 120},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&Exporter{Format: FormatJSON, Metadata: meta}).Export(map[string]string{"status": "ok"}, &buf))
+	var decoded JSONExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.NotNil(t, decoded.Metadata.Truncation)
+	assert.Equal(t, 120, decoded.Metadata.Truncation.LogLinesDropped)
+
+	buf.Reset()
+	require.NoError(t, (&Exporter{Format: FormatMarkdown, Metadata: meta}).Export(&result.DefaultResult{}, &buf))
+	assert.Contains(t, buf.String(), "**Snapshot truncated:** dropped 120 log lines (~9000 -> ~5900 tokens, budget 6000)")
+}
+
+func TestExport_LLMCost(t *testing.T) {
+	usd := 0.0213
+	meta := ExportMetadata{
+		Mode:    "default",
+		LLMCost: &llm.Cost{Model: "gpt-4o", Usage: llm.Usage{Calls: 1, PromptTokens: 5210, CompletionTokens: 830}, USD: &usd},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&Exporter{Format: FormatJSON, Metadata: meta}).Export(map[string]string{"status": "ok"}, &buf))
+	assert.Contains(t, buf.String(), `"llmCost"`)
+	var decoded JSONExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.NotNil(t, decoded.Metadata.LLMCost)
+	assert.Equal(t, 5210, decoded.Metadata.LLMCost.PromptTokens)
+
+	buf.Reset()
+	require.NoError(t, (&Exporter{Format: FormatMarkdown, Metadata: meta}).Export(&result.DefaultResult{}, &buf))
+	assert.Contains(t, buf.String(), "**LLM usage:** 1 call, 5210 prompt + 830 completion tokens, $0.0213")
+
+	buf.Reset()
+	require.NoError(t, (&Exporter{Format: FormatHTML, Metadata: meta}).Export(map[string]string{"status": "ok"}, &buf))
+	assert.Contains(t, buf.String(), "<strong>LLM usage:</strong> 1 call, 5210 prompt &#43; 830 completion tokens, $0.0213")
+}
+
+func TestExport_Acknowledged(t *testing.T) {
+	until := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	meta := ExportMetadata{
+		Mode: "default",
+		Acknowledged: []ack.Problem{
+			{
+				Namespace: "batch", Pod: "report-1", Container: "app", Type: "CrashLoopBackOff",
+				Reason: "nightly flake", By: "data-team", Until: &until,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&Exporter{Format: FormatMarkdown, Metadata: meta}).Export(&result.DefaultResult{}, &buf))
+	md := buf.String()
+	assert.Contains(t, md, "## Known Accepted Problems")
+	assert.Contains(t, md, "| batch/report-1/app | CrashLoopBackOff | nightly flake (by data-team, until 2026-11-01) |")
+	assert.Less(t, strings.Index(md, "## Cluster Summary"), strings.Index(md, "## Known Accepted Problems"))
+
+	buf.Reset()
+	require.NoError(t, (&Exporter{Format: FormatHTML, Metadata: meta}).Export(map[string]string{"status": "ok"}, &buf))
+	assert.Contains(t, buf.String(), "<h2>Known Accepted Problems</h2>")
+	assert.Contains(t, buf.String(), "<td>batch/report-1/app</td>")
+
+	buf.Reset()
+	require.NoError(t, (&Exporter{Format: FormatJSON, Metadata: meta}).Export(map[string]string{"status": "ok"}, &buf))
+	var decoded JSONExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded.Metadata.Acknowledged, 1)
+	assert.Equal(t, "nightly flake", decoded.Metadata.Acknowledged[0].Reason)
+
+	buf.Reset()
+	require.NoError(t, (&Exporter{Format: FormatMarkdown, Metadata: ExportMetadata{Mode: "default"}}).Export(&result.DefaultResult{}, &buf))
+	assert.NotContains(t, buf.String(), "Known Accepted Problems")
+}
+
 func TestExportMarkdown(t *testing.T) {
 	var buf bytes.Buffer
 	exporter := Exporter{
@@ -80,6 +185,50 @@ func TestExportMarkdown(t *testing.T) {
 	assert.Contains(t, output, "## Cluster Summary")
 }
 
+func TestExportMarkdown_Security(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := Exporter{
+		Format:   FormatMarkdown,
+		Metadata: ExportMetadata{GeneratedAt: time.Now(), KubenowVersion: "1.2.3", Mode: "security"},
+	}
+	err := exporter.Export(&result.SecurityResult{
+		Findings: []result.SecurityFinding{{
+			Priority: 1, Severity: "high", Category: "pod-security", Namespace: "ci",
+			Resource: "Deployment/runner", Issue: "privileged container", Recommendation: "drop privileged",
+		}},
+		Summary: "one privileged workload",
+	}, &buf)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "## Security Hardening")
+	assert.Contains(t, output, "### 1. ci/Deployment/runner - HIGH")
+	assert.Contains(t, output, "**Recommendation:** drop privileged")
+	assert.Contains(t, output, "one privileged workload")
+}
+
+func TestExportMarkdown_Capacity(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := Exporter{
+		Format:   FormatMarkdown,
+		Metadata: ExportMetadata{GeneratedAt: time.Now(), KubenowVersion: "1.2.3", Mode: "capacity"},
+	}
+	r := &result.CapacityResult{
+		Consolidation: []result.CapacityOpportunity{{Target: "shop", Action: "halve api requests", Savings: "$300/month"}},
+		NodePools:     []result.NodePoolAdvice{{Pool: "general", Recommendation: "shrink to 7 nodes"}},
+		Summary:       "Mostly idle.",
+	}
+	r.Headroom.CPU = "36% requested"
+	require.NoError(t, exporter.Export(r, &buf))
+
+	output := buf.String()
+	assert.Contains(t, output, "## Headroom")
+	assert.Contains(t, output, "**CPU:** 36% requested")
+	assert.Contains(t, output, "| shop | halve api requests | $300/month |")
+	assert.Contains(t, output, "### general")
+	assert.Contains(t, output, "Mostly idle.")
+}
+
 func TestExportText(t *testing.T) {
 	var buf bytes.Buffer
 	exporter := Exporter{Format: FormatText}
@@ -103,3 +252,55 @@ func TestExportHTML(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, strings.Contains(buf.String(), "<!DOCTYPE html>"))
 }
+
+func TestExportSARIF_Compliance(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := Exporter{
+		Format:   FormatSARIF,
+		Metadata: ExportMetadata{KubenowVersion: "1.2.3", Mode: "compliance"},
+	}
+
+	cr := &result.ComplianceResult{Issues: []result.ComplianceIssue{
+		{Namespace: "prod", Name: "api", Type: "MissingLimits", Severity: "high", Description: "no memory limit"},
+		{Namespace: "prod", Name: "web", Type: "ResourceRatio", Severity: "warning"},
+	}}
+	require.NoError(t, exporter.Export(cr, &buf))
+
+	var doc struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+				Level  string `json:"level"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "2.1.0", doc.Version)
+	require.Len(t, doc.Runs, 1)
+	require.Len(t, doc.Runs[0].Results, 2)
+	assert.Equal(t, "compliance/missinglimits", doc.Runs[0].Results[0].RuleID)
+	assert.Equal(t, "error", doc.Runs[0].Results[0].Level)
+	assert.Equal(t, "warning", doc.Runs[0].Results[1].Level)
+
+	exporter.Metadata.Mode = "incident"
+	assert.Error(t, exporter.Export(&result.IncidentResult{}, &buf), "incident results have no SARIF form")
+}
+
+func TestExport_Owners(t *testing.T) {
+	meta := ExportMetadata{
+		Mode: "default",
+		Owners: []owners.Assignment{
+			{Team: "payments", Contact: "#payments", Pods: []string{"payments/api-1", "payments/api-2"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&Exporter{Format: FormatMarkdown, Metadata: meta}).Export(&result.DefaultResult{}, &buf))
+	assert.Contains(t, buf.String(), "## Owners")
+	assert.Contains(t, buf.String(), "| payments | #payments | payments/api-1, payments/api-2 |")
+
+	buf.Reset()
+	require.NoError(t, (&Exporter{Format: FormatHTML, Metadata: meta}).Export(map[string]string{"status": "ok"}, &buf))
+	assert.Contains(t, buf.String(), "<td>payments/api-1, payments/api-2</td>")
+}
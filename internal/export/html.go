@@ -2,48 +2,149 @@ package export
 
 import (
 	"fmt"
+	"html/template"
 	"io"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
 )
 
-// exportHTML exports the result as HTML (Phase 2 - placeholder for now).
-func exportHTML(result interface{}, metadata *ExportMetadata, w io.Writer) error {
-	// TODO: Implement beautiful HTML export in Phase 2
-	// For now, return a simple HTML wrapper with JSON
-	html := fmt.Sprintf(`<!DOCTYPE html>
+// layoutHTML is the shared, self-contained page shell for HTML reports:
+// metadata header, footer, styles, and a small script that makes every
+// table with class "sortable" sortable by clicking its headers. Reports
+// define the "title" and "content" templates.
+const layoutHTML = `<!DOCTYPE html>
 <html>
 <head>
-    <title>kubenow Report - %s - %s</title>
+    <title>{{template "title" .}}</title>
     <meta charset="UTF-8">
     <style>
         body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; max-width: 1200px; margin: 40px auto; padding: 20px; }
         h1 { color: #1976d2; }
         .metadata { background: #f5f5f5; padding: 15px; border-left: 4px solid #1976d2; margin-bottom: 20px; }
         pre { background: #f5f5f5; padding: 15px; overflow-x: auto; }
+        table { border-collapse: collapse; width: 100%; margin-bottom: 24px; font-size: 14px; }
+        th, td { border: 1px solid #e0e0e0; padding: 6px 10px; text-align: left; }
+        th { background: #fafafa; }
+        table.sortable th { cursor: pointer; user-select: none; }
+        table.sortable th::after { content: " \21C5"; color: #bdbdbd; }
+        td.num { text-align: right; font-variant-numeric: tabular-nums; }
+        .cards { display: flex; flex-wrap: wrap; gap: 12px; margin-bottom: 24px; }
+        .card { flex: 1 1 160px; background: #f5f5f5; padding: 12px 16px; border-radius: 4px; }
+        .card .value { font-size: 22px; font-weight: 600; color: #1976d2; }
+        .histogram { margin-bottom: 24px; }
+        .bar-row { display: flex; align-items: center; margin: 4px 0; }
+        .bar-label { width: 90px; font-size: 13px; }
+        .bar { background: #1976d2; height: 18px; margin-right: 8px; min-width: 2px; }
+        .bar-count { font-size: 13px; color: #616161; }
+        .safety-UNSAFE { color: #c62828; font-weight: 600; }
+        .safety-RISKY { color: #ef6c00; font-weight: 600; }
+        .safety-CAUTION { color: #f9a825; }
+        .safety-SAFE { color: #2e7d32; }
+        tr.deploy td { background: #e3f2fd; }
+        tr.restart td { background: #fff3e0; }
+        tr.Warning td.reason { color: #c62828; font-weight: 600; }
     </style>
 </head>
 <body>
-    <h1>kubenow Report</h1>
+    <h1>{{template "title" .}}</h1>
     <div class="metadata">
-        <p><strong>Generated:</strong> %s</p>
-        <p><strong>Cluster:</strong> %s</p>
-        <p><strong>Mode:</strong> %s</p>
-        <p><strong>Version:</strong> %s</p>
+        <p><strong>Generated:</strong> {{.Metadata.GeneratedAt.Format "2006-01-02 15:04:05 UTC"}}</p>
+        {{- if .Metadata.ClusterName}}
+        <p><strong>Cluster:</strong> {{.Metadata.ClusterName}}</p>
+        {{- end}}
+        <p><strong>Mode:</strong> {{.Metadata.ModeLabel}}</p>
+        {{- if .Metadata.Truncation}}
+        <p><strong>Snapshot truncated:</strong> {{.Metadata.Truncation.Summary}}</p>
+        {{- end}}
+        {{- if .Metadata.LLMCost}}
+        <p><strong>LLM usage:</strong> {{.Metadata.LLMCost}}</p>
+        {{- end}}
+        <p><strong>Version:</strong> {{.Metadata.KubenowVersion}}</p>
     </div>
-    <h2>Result</h2>
-    <pre>%v</pre>
+{{template "content" .}}
+    {{- with .Metadata.Acknowledged}}
+    <h2>Known Accepted Problems</h2>
+    <p>Already triaged and accepted; not part of the analysis above.</p>
+    <table>
+        <thead><tr><th>Location</th><th>Type</th><th>Accepted</th></tr></thead>
+        <tbody>
+        {{- range .}}
+            <tr><td>{{.Location}}</td><td>{{.Type}}</td><td>{{.Note}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    {{- end}}
+    {{- with .Metadata.Owners}}
+    <h2>Owners</h2>
+    <table>
+        <thead><tr><th>Team</th><th>Contact</th><th>Problem pods</th></tr></thead>
+        <tbody>
+        {{- range .}}
+            <tr><td>{{.Team}}</td><td>{{.Contact}}</td><td>{{range $i, $pod := .Pods}}{{if $i}}, {{end}}{{$pod}}{{end}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    {{- end}}
     <hr>
     <p><em>Generated by <a href="https://github.com/ppiankov/kubenow">kubenow</a></em></p>
+    <script>
+    document.querySelectorAll("table.sortable").forEach(function (table) {
+        table.querySelectorAll("th").forEach(function (th, col) {
+            th.addEventListener("click", function () {
+                var body = table.tBodies[0];
+                var asc = th.dataset.dir !== "asc";
+                th.dataset.dir = asc ? "asc" : "desc";
+                var key = function (row) {
+                    var cell = row.cells[col];
+                    var v = cell.dataset.sort !== undefined ? cell.dataset.sort : cell.textContent.trim();
+                    var n = parseFloat(v);
+                    return isNaN(n) ? v.toLowerCase() : n;
+                };
+                Array.from(body.rows)
+                    .sort(function (a, b) {
+                        var x = key(a), y = key(b);
+                        var c = x < y ? -1 : x > y ? 1 : 0;
+                        return asc ? c : -c;
+                    })
+                    .forEach(function (row) { body.appendChild(row); });
+            });
+        });
+    });
+    </script>
 </body>
-</html>`,
-		metadata.Mode,
-		metadata.GeneratedAt.Format("2006-01-02"),
-		metadata.GeneratedAt.Format("2006-01-02 15:04:05 UTC"),
-		metadata.ClusterName,
-		metadata.Mode,
-		metadata.KubenowVersion,
-		result,
-	)
-
-	_, err := w.Write([]byte(html))
-	return err
+</html>
+`
+
+// genericHTML renders any result as preformatted text.
+const genericHTML = `{{define "title"}}kubenow Report - {{.Metadata.Mode}} - {{.Metadata.GeneratedAt.Format "2006-01-02"}}{{end}}
+{{define "content"}}    <h2>Result</h2>
+    <pre>{{.Body}}</pre>
+{{end}}`
+
+var layoutTemplate = template.Must(template.New("layout").Parse(layoutHTML))
+
+// reportTemplate returns the shared layout with a report's title and content templates.
+func reportTemplate(name, body string, funcs template.FuncMap) *template.Template {
+	t := template.Must(layoutTemplate.Clone())
+	return template.Must(t.New(name).Funcs(funcs).Parse(body))
+}
+
+var genericTemplate = reportTemplate("generic", genericHTML, nil)
+
+// exportHTML exports the result as a self-contained HTML page. Results with a
+// dedicated report (requests-skew) get tables and charts; anything else is
+// shown as preformatted text.
+func exportHTML(result interface{}, metadata *ExportMetadata, w io.Writer) error {
+	if skew, ok := result.(*analyzer.RequestsSkewResult); ok {
+		return ExportRequestsSkewHTML(skew, metadata, w)
+	}
+
+	data := struct {
+		Metadata *ExportMetadata
+		Body     string
+	}{metadata, fmt.Sprintf("%v", result)}
+	if err := genericTemplate.ExecuteTemplate(w, "layout", data); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
 }
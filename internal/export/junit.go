@@ -0,0 +1,32 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/output"
+	"github.com/ppiankov/kubenow/internal/result"
+)
+
+// exportJUnit exports findings as JUnit XML, so CI systems show them in
+// their test report views. Like SARIF, it covers compliance issues and
+// requests-skew workloads.
+func exportJUnit(resultData interface{}, metadata *ExportMetadata, w io.Writer) error {
+	var data []byte
+	var err error
+	switch r := resultData.(type) {
+	case *result.ComplianceResult:
+		data, err = output.GenerateJUnitFromCompliance(r, metadata.KubenowVersion)
+	case *analyzer.RequestsSkewResult:
+		data, err = output.GenerateJUnitFromRequestsSkew(r, nil, metadata.KubenowVersion)
+	default:
+		return fmt.Errorf("JUnit export is supported for compliance and requests-skew results, not %s", metadata.Mode)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate JUnit XML: %w", err)
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
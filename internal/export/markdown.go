@@ -5,6 +5,8 @@ import (
 	"io"
 	"strings"
 
+	"github.com/ppiankov/kubenow/internal/ack"
+	"github.com/ppiankov/kubenow/internal/owners"
 	"github.com/ppiankov/kubenow/internal/result"
 )
 
@@ -18,7 +20,13 @@ func exportMarkdown(resultData interface{}, metadata *ExportMetadata, w io.Write
 	if metadata.ClusterName != "" {
 		sb.WriteString(fmt.Sprintf("**Cluster:** %s\n", metadata.ClusterName))
 	}
-	sb.WriteString(fmt.Sprintf("**Mode:** %s\n", metadata.Mode))
+	sb.WriteString(fmt.Sprintf("**Mode:** %s\n", metadata.ModeLabel()))
+	if metadata.Truncation != nil {
+		sb.WriteString(fmt.Sprintf("**Snapshot truncated:** %s\n", metadata.Truncation.Summary()))
+	}
+	if metadata.LLMCost != nil {
+		sb.WriteString(fmt.Sprintf("**LLM usage:** %s\n", metadata.LLMCost))
+	}
 	sb.WriteString(fmt.Sprintf("**kubenow Version:** %s\n\n", metadata.KubenowVersion))
 	sb.WriteString("---\n\n")
 
@@ -48,10 +56,21 @@ func exportMarkdown(resultData interface{}, metadata *ExportMetadata, w io.Write
 		if ch, ok := resultData.(*result.ChaosResult); ok {
 			renderChaosMarkdown(&sb, ch)
 		}
+	case "security":
+		if sr, ok := resultData.(*result.SecurityResult); ok {
+			renderSecurityMarkdown(&sb, sr)
+		}
+	case "capacity":
+		if cr, ok := resultData.(*result.CapacityResult); ok {
+			renderCapacityMarkdown(&sb, cr)
+		}
 	default:
 		return fmt.Errorf("unsupported mode for markdown export: %s", metadata.Mode)
 	}
 
+	renderAcknowledgedMarkdown(&sb, metadata.Acknowledged)
+	renderOwnersMarkdown(&sb, metadata.Owners)
+
 	// Footer
 	sb.WriteString("\n---\n\n")
 	sb.WriteString("*Generated by [kubenow](https://github.com/ppiankov/kubenow)*\n")
@@ -60,6 +79,34 @@ func exportMarkdown(resultData interface{}, metadata *ExportMetadata, w io.Write
 	return err
 }
 
+// renderAcknowledgedMarkdown lists known accepted problems apart from the
+// analysis, so the report does not re-open them.
+func renderAcknowledgedMarkdown(sb *strings.Builder, problems []ack.Problem) {
+	if len(problems) == 0 {
+		return
+	}
+	sb.WriteString("\n## Known Accepted Problems\n\n")
+	sb.WriteString("Already triaged and accepted; not part of the analysis above.\n\n")
+	sb.WriteString("| Location | Type | Accepted |\n|----------|------|----------|\n")
+	for i := range problems {
+		p := &problems[i]
+		fmt.Fprintf(sb, "| %s | %s | %s |\n", p.Location(), p.Type, strings.ReplaceAll(p.Note(), "|", "\\|"))
+	}
+}
+
+// renderOwnersMarkdown lists the problem pods per owning team, for routing.
+func renderOwnersMarkdown(sb *strings.Builder, assignments []owners.Assignment) {
+	if len(assignments) == 0 {
+		return
+	}
+	sb.WriteString("\n## Owners\n\n")
+	sb.WriteString("| Team | Contact | Problem pods |\n|------|---------|--------------|\n")
+	for i := range assignments {
+		a := &assignments[i]
+		fmt.Fprintf(sb, "| %s | %s | %s |\n", a.Team, a.Contact, strings.Join(a.Pods, ", "))
+	}
+}
+
 func renderIncidentMarkdown(sb *strings.Builder, ir *result.IncidentResult) {
 	if len(ir.RootCauses) > 0 {
 		sb.WriteString("## Root Causes\n\n")
@@ -240,3 +287,71 @@ func renderChaosMarkdown(sb *strings.Builder, ch *result.ChaosResult) {
 		sb.WriteString("\n")
 	}
 }
+
+func renderSecurityMarkdown(sb *strings.Builder, sr *result.SecurityResult) {
+	sb.WriteString("## Security Hardening\n\n")
+
+	if len(sr.Findings) == 0 {
+		sb.WriteString("*No hardening findings.*\n\n")
+	}
+	for _, f := range sr.Findings {
+		resource := f.Resource
+		if f.Namespace != "" {
+			resource = f.Namespace + "/" + resource
+		}
+		fmt.Fprintf(sb, "### %d. %s - %s\n\n", f.Priority, resource, strings.ToUpper(f.Severity))
+		fmt.Fprintf(sb, "**Category:** %s\n", f.Category)
+		fmt.Fprintf(sb, "**Issue:** %s\n", f.Issue)
+		if f.Recommendation != "" {
+			fmt.Fprintf(sb, "**Recommendation:** %s\n", f.Recommendation)
+		}
+		sb.WriteString("\n")
+	}
+
+	if sr.Summary != "" {
+		sb.WriteString("## Summary\n\n")
+		sb.WriteString(sr.Summary + "\n\n")
+	}
+}
+
+func renderCapacityMarkdown(sb *strings.Builder, cr *result.CapacityResult) {
+	if cr.Summary != "" {
+		sb.WriteString("## Summary\n\n")
+		sb.WriteString(cr.Summary + "\n\n")
+	}
+
+	sb.WriteString("## Headroom\n\n")
+	fmt.Fprintf(sb, "- **CPU:** %s\n", cr.Headroom.CPU)
+	fmt.Fprintf(sb, "- **Memory:** %s\n", cr.Headroom.Memory)
+	fmt.Fprintf(sb, "- **Outlook:** %s\n\n", cr.Headroom.Outlook)
+
+	if len(cr.Consolidation) > 0 {
+		sb.WriteString("## Consolidation Opportunities\n\n")
+		sb.WriteString("| Target | Action | Savings |\n")
+		sb.WriteString("|--------|--------|---------|\n")
+		for _, o := range cr.Consolidation {
+			fmt.Fprintf(sb, "| %s | %s | %s |\n", o.Target, o.Action, o.Savings)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(cr.NodePools) > 0 {
+		sb.WriteString("## Node Pool Recommendations\n\n")
+		for _, p := range cr.NodePools {
+			fmt.Fprintf(sb, "### %s\n\n", p.Pool)
+			fmt.Fprintf(sb, "**Recommendation:** %s\n", p.Recommendation)
+			if p.Reason != "" {
+				fmt.Fprintf(sb, "**Reason:** %s\n", p.Reason)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(cr.Risks) > 0 {
+		sb.WriteString("## Risks\n\n")
+		for _, risk := range cr.Risks {
+			fmt.Fprintf(sb, "- %s\n", risk)
+		}
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/output"
+	"github.com/ppiankov/kubenow/internal/result"
+)
+
+// exportSARIF exports findings as SARIF 2.1.0 for GitHub code scanning and
+// other SARIF dashboards. Only results made of per-resource findings have a
+// SARIF form: compliance issues and requests-skew workloads.
+func exportSARIF(resultData interface{}, metadata *ExportMetadata, w io.Writer) error {
+	var data []byte
+	var err error
+	switch r := resultData.(type) {
+	case *result.ComplianceResult:
+		data, err = output.GenerateSARIFFromCompliance(r, metadata.KubenowVersion)
+	case *analyzer.RequestsSkewResult:
+		data, err = output.GenerateSARIFFromRequestsSkew(r, metadata.KubenowVersion)
+	default:
+		return fmt.Errorf("SARIF export is supported for compliance and requests-skew results, not %s", metadata.Mode)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate SARIF: %w", err)
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,238 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+)
+
+// skewBucketBounds are the upper bounds of the skew histogram buckets;
+// the last bucket is open-ended.
+var skewBucketBounds = []float64{1.5, 2, 3, 5, 10}
+
+// skewBucket is one histogram bar.
+type skewBucket struct {
+	Label string
+	Count int
+	Width int // bar width in percent of the largest bucket
+}
+
+// skewNamespaceRow aggregates the reported workloads of one namespace.
+type skewNamespaceRow struct {
+	Namespace         string
+	Workloads         int
+	RequestedCPU      float64
+	P95CPU            float64
+	WastedCPU         float64
+	RequestedMemoryGi float64
+	WastedMemoryGi    float64
+	AvgSkewCPU        float64
+	WastedMonthly     float64
+	HasCost           bool
+}
+
+// skewReport is the template data for the requests-skew HTML report.
+type skewReport struct {
+	Metadata   *ExportMetadata
+	Result     *analyzer.RequestsSkewResult
+	HasCost    bool
+	HasTeams   bool
+	CPUHist    []skewBucket
+	MemoryHist []skewBucket
+	Namespaces []skewNamespaceRow
+}
+
+const requestsSkewHTML = `{{define "title"}}kubenow requests-skew Report{{end}}
+{{define "content"}}{{$r := .Result}}    <div class="cards">
+        <div class="card"><div>Window</div><div class="value">{{$r.Metadata.Window}}</div></div>
+        <div class="card"><div>Analyzed workloads</div><div class="value">{{$r.Summary.AnalyzedWorkloads}}</div></div>
+        <div class="card"><div>Avg CPU skew</div><div class="value">{{printf "%.2fx" $r.Summary.AvgSkewCPU}}</div></div>
+        <div class="card"><div>Avg memory skew</div><div class="value">{{printf "%.2fx" $r.Summary.AvgSkewMemory}}</div></div>
+        <div class="card"><div>Wasted CPU</div><div class="value">{{printf "%.2f" $r.Summary.TotalWastedCPU}} cores</div></div>
+        <div class="card"><div>Wasted memory</div><div class="value">{{printf "%.2f" $r.Summary.TotalWastedMemoryGi}} GiB</div></div>
+        {{- with $r.Summary.CostEstimate}}
+        <div class="card"><div>Estimated waste</div><div class="value">{{money .TotalWastedMonthly}}/mo</div></div>
+        {{- end}}
+    </div>
+
+    <h2>Skew distribution</h2>
+    <div class="histogram">
+        <h3>CPU (requested / P95 used)</h3>
+        {{- range .CPUHist}}
+        <div class="bar-row"><span class="bar-label">{{.Label}}</span><span class="bar" style="width: {{.Width}}%"></span><span class="bar-count">{{.Count}}</span></div>
+        {{- end}}
+        <h3>Memory (requested / P95 used)</h3>
+        {{- range .MemoryHist}}
+        <div class="bar-row"><span class="bar-label">{{.Label}}</span><span class="bar" style="width: {{.Width}}%"></span><span class="bar-count">{{.Count}}</span></div>
+        {{- end}}
+    </div>
+
+    <h2>Namespaces</h2>
+    <table class="sortable">
+        <thead><tr><th>Namespace</th><th>Workloads</th><th>Req CPU</th><th>P95 CPU</th><th>Wasted CPU</th><th>Req Mem (GiB)</th><th>Wasted Mem (GiB)</th><th>Avg CPU Skew</th>{{if .HasCost}}<th>Est. Waste/mo</th>{{end}}</tr></thead>
+        <tbody>
+        {{- range .Namespaces}}
+            <tr><td>{{.Namespace}}</td><td class="num">{{.Workloads}}</td><td class="num">{{printf "%.2f" .RequestedCPU}}</td><td class="num">{{printf "%.2f" .P95CPU}}</td><td class="num">{{printf "%.2f" .WastedCPU}}</td><td class="num">{{printf "%.2f" .RequestedMemoryGi}}</td><td class="num">{{printf "%.2f" .WastedMemoryGi}}</td><td class="num">{{printf "%.1fx" .AvgSkewCPU}}</td>{{if $.HasCost}}<td class="num" data-sort="{{.WastedMonthly}}">{{if .HasCost}}{{money .WastedMonthly}}{{else}}-{{end}}</td>{{end}}</tr>
+        {{- end}}
+        </tbody>
+    </table>
+{{- if $r.Groups}}
+
+    <h2>Waste by {{$r.Metadata.GroupBy}}</h2>
+    <table class="sortable">
+        <thead><tr><th>Group</th><th>Workloads</th><th>Req CPU</th><th>P95 CPU</th><th>Wasted CPU</th><th>Req Mem (GiB)</th><th>Wasted Mem (GiB)</th><th>Avg CPU Skew</th><th>Chart</th>{{if .HasCost}}<th>Est. Waste/mo</th>{{end}}</tr></thead>
+        <tbody>
+        {{- range $r.Groups}}
+            <tr><td>{{.Name}}</td><td class="num">{{.Workloads}}</td><td class="num">{{printf "%.2f" .RequestedCPU}}</td><td class="num">{{printf "%.2f" .P95UsedCPU}}</td><td class="num">{{printf "%.2f" .WastedCPU}}</td><td class="num">{{printf "%.2f" .RequestedMemoryGi}}</td><td class="num">{{printf "%.2f" .WastedMemoryGi}}</td><td class="num">{{printf "%.1fx" .AvgSkewCPU}}</td><td>{{.Chart}}</td>{{if $.HasCost}}<td class="num" data-sort="{{.WastedMonthly}}">{{money .WastedMonthly}}</td>{{end}}</tr>
+        {{- end}}
+        </tbody>
+    </table>
+{{- end}}
+
+    <h2>Workloads</h2>
+    <table class="sortable">
+        <thead><tr><th>Namespace</th><th>Workload</th><th>Type</th><th>Req CPU</th><th>P95 CPU</th><th>CPU Skew</th><th>Req Mem (GiB)</th><th>P95 Mem (GiB)</th><th>Mem Skew</th><th>Safety</th><th>Impact</th>{{if .HasCost}}<th>Est. Waste/mo</th>{{end}}{{if .HasTeams}}<th>Team</th>{{end}}<th>Note</th></tr></thead>
+        <tbody>
+        {{- range $r.Results}}
+            <tr><td>{{.Namespace}}</td><td>{{.Workload}}</td><td>{{.Type}}</td><td class="num">{{printf "%.2f" .RequestedCPU}}</td><td class="num">{{printf "%.2f" .P95UsedCPU}}</td><td class="num">{{printf "%.1fx" .SkewCPU}}</td><td class="num">{{printf "%.2f" .RequestedMemoryGi}}</td><td class="num">{{printf "%.2f" .P95UsedMemoryGi}}</td><td class="num">{{printf "%.1fx" .SkewMemory}}</td>{{with .Safety}}<td class="safety-{{.Rating}}">{{.Rating}}</td>{{else}}<td>?</td>{{end}}<td class="num">{{printf "%.1f" .ImpactScore}}</td>{{if $.HasCost}}{{with .CostEstimate}}<td class="num" data-sort="{{.WastedMonthly}}">{{money .WastedMonthly}}</td>{{else}}<td class="num" data-sort="0">-</td>{{end}}{{end}}{{if $.HasTeams}}<td>{{.Team}}</td>{{end}}<td>{{.Note}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+{{- if $r.WorkloadsWithoutMetrics}}
+
+    <h2>Workloads without metrics ({{len $r.WorkloadsWithoutMetrics}})</h2>
+    <table class="sortable">
+        <thead><tr><th>Namespace</th><th>Workload</th><th>Type</th><th>Cause</th><th>Diagnosis</th><th>Next step</th>{{if .HasTeams}}<th>Team</th>{{end}}</tr></thead>
+        <tbody>
+        {{- range $r.WorkloadsWithoutMetrics}}
+            <tr><td>{{.Namespace}}</td><td>{{.Workload}}</td><td>{{.Type}}</td><td>{{.Cause}}</td><td>{{.Diagnosis}}</td><td>{{.Remediation}}</td>{{if $.HasTeams}}<td>{{.Team}}</td>{{end}}</tr>
+        {{- end}}
+        </tbody>
+    </table>
+{{- end}}
+{{- with $r.Queries}}
+
+    <h2>Query log ({{.PromQLQueries}} PromQL queries, {{.KubernetesRequests}} Kubernetes requests, {{.TotalDurationMs}}ms)</h2>
+    <table class="sortable">
+        <thead><tr><th>#</th><th>Kind</th><th>Query</th><th>Duration (ms)</th><th>Rows</th><th>Error</th></tr></thead>
+        <tbody>
+        {{- range $i, $e := .Entries}}
+            <tr><td class="num">{{inc $i}}</td><td>{{$e.Kind}}</td><td><code>{{$e.Query}}</code></td><td class="num">{{$e.DurationMs}}</td><td class="num">{{$e.Rows}}</td><td>{{$e.Error}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+{{- end}}
+{{end}}`
+
+var requestsSkewTemplate = reportTemplate("requests-skew", requestsSkewHTML, template.FuncMap{
+	"money": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"inc":   func(i int) int { return i + 1 },
+})
+
+// ExportRequestsSkewHTML writes a self-contained HTML report for a
+// requests-skew analysis: summary cards, CPU and memory skew histograms,
+// per-namespace totals, and sortable workload tables.
+func ExportRequestsSkewHTML(result *analyzer.RequestsSkewResult, metadata *ExportMetadata, w io.Writer) error {
+	cpuSkews := make([]float64, 0, len(result.Results))
+	memSkews := make([]float64, 0, len(result.Results))
+	for i := range result.Results {
+		cpuSkews = append(cpuSkews, result.Results[i].SkewCPU)
+		memSkews = append(memSkews, result.Results[i].SkewMemory)
+	}
+
+	report := skewReport{
+		Metadata:   metadata,
+		Result:     result,
+		HasCost:    result.Summary.CostEstimate != nil,
+		HasTeams:   result.HasTeams(),
+		CPUHist:    skewHistogram(cpuSkews),
+		MemoryHist: skewHistogram(memSkews),
+		Namespaces: skewNamespaceRows(result),
+	}
+	if err := requestsSkewTemplate.ExecuteTemplate(w, "layout", report); err != nil {
+		return fmt.Errorf("failed to render requests-skew HTML: %w", err)
+	}
+	return nil
+}
+
+// skewHistogram buckets skew ratios by skewBucketBounds.
+func skewHistogram(skews []float64) []skewBucket {
+	buckets := make([]skewBucket, len(skewBucketBounds)+1)
+	lower := 0.0
+	for i, upper := range skewBucketBounds {
+		if i == 0 {
+			buckets[i].Label = fmt.Sprintf("< %gx", upper)
+		} else {
+			buckets[i].Label = fmt.Sprintf("%g–%gx", lower, upper)
+		}
+		lower = upper
+	}
+	buckets[len(skewBucketBounds)].Label = fmt.Sprintf("≥ %gx", lower)
+
+	for _, s := range skews {
+		idx := sort.SearchFloat64s(skewBucketBounds, s)
+		if idx < len(skewBucketBounds) && s == skewBucketBounds[idx] {
+			idx++ // bounds are exclusive upper limits
+		}
+		buckets[idx].Count++
+	}
+
+	largest := 0
+	for _, b := range buckets {
+		largest = max(largest, b.Count)
+	}
+	if largest > 0 {
+		for i := range buckets {
+			buckets[i].Width = buckets[i].Count * 100 / largest
+		}
+	}
+	return buckets
+}
+
+// skewNamespaceRows totals the reported workloads per namespace, sorted by
+// wasted CPU. Monthly waste comes from the namespace cost estimates, which
+// cover every analyzed workload rather than only the reported top N.
+func skewNamespaceRows(result *analyzer.RequestsSkewResult) []skewNamespaceRow {
+	byNS := map[string]*skewNamespaceRow{}
+	var order []string
+	for i := range result.Results {
+		w := &result.Results[i]
+		row, ok := byNS[w.Namespace]
+		if !ok {
+			row = &skewNamespaceRow{Namespace: w.Namespace}
+			byNS[w.Namespace] = row
+			order = append(order, w.Namespace)
+		}
+		row.Workloads++
+		row.RequestedCPU += w.RequestedCPU
+		row.P95CPU += w.P95UsedCPU
+		row.WastedCPU += max(w.RequestedCPU-w.P95UsedCPU, 0)
+		row.RequestedMemoryGi += w.RequestedMemoryGi
+		row.WastedMemoryGi += max(w.RequestedMemoryGi-w.P95UsedMemoryGi, 0)
+		row.AvgSkewCPU += w.SkewCPU
+	}
+	for i := range result.NamespaceCosts {
+		nc := &result.NamespaceCosts[i]
+		if row, ok := byNS[nc.Namespace]; ok {
+			row.WastedMonthly = nc.WastedMonthly
+			row.HasCost = true
+		}
+	}
+
+	rows := make([]skewNamespaceRow, 0, len(order))
+	for _, ns := range order {
+		row := byNS[ns]
+		row.AvgSkewCPU /= float64(row.Workloads)
+		rows = append(rows, *row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].WastedCPU != rows[j].WastedCPU {
+			return rows[i].WastedCPU > rows[j].WastedCPU
+		}
+		return rows[i].Namespace < rows[j].Namespace
+	})
+	return rows
+}
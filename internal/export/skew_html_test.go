@@ -0,0 +1,128 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/models"
+	"github.com/ppiankov/kubenow/internal/querylog"
+)
+
+func skewFixture() *analyzer.RequestsSkewResult {
+	return &analyzer.RequestsSkewResult{
+		Metadata: analyzer.RequestsSkewMetadata{Window: "30d", Cluster: "prod"},
+		Summary:  analyzer.RequestsSkewSummary{AnalyzedWorkloads: 3, AvgSkewCPU: 4.2, TotalWastedCPU: 3.5},
+		Results: []analyzer.WorkloadSkewAnalysis{
+			{Namespace: "payments", Workload: "api", Type: "Deployment", RequestedCPU: 2, P95UsedCPU: 0.5, SkewCPU: 4, SkewMemory: 1.2,
+				Safety: &models.SafetyAnalysis{Rating: models.SafetyRatingSafe}},
+			{Namespace: "payments", Workload: "worker", Type: "Deployment", RequestedCPU: 1, P95UsedCPU: 0.5, SkewCPU: 2, SkewMemory: 2},
+			{Namespace: "search", Workload: "<script>alert(1)</script>", Type: "StatefulSet", RequestedCPU: 1.5, P95UsedCPU: 0.1, SkewCPU: 15, SkewMemory: 10},
+		},
+	}
+}
+
+func TestExportRequestsSkewHTML(t *testing.T) {
+	var buf bytes.Buffer
+	meta := ExportMetadata{GeneratedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), KubenowVersion: "1.0.0", Mode: "requests-skew"}
+	require.NoError(t, ExportRequestsSkewHTML(skewFixture(), &meta, &buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "<!DOCTYPE html>")
+	assert.Contains(t, out, "kubenow requests-skew Report")
+	assert.Contains(t, out, `class="sortable"`)
+	assert.Contains(t, out, "<td>payments</td>")
+	assert.Contains(t, out, `class="safety-SAFE"`)
+	assert.NotContains(t, out, "<script>alert(1)</script>", "workload names must be escaped")
+	assert.Contains(t, out, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.NotContains(t, out, "Est. Waste/mo", "no cost column without cost estimates")
+}
+
+func TestExportRequestsSkewHTML_WithCost(t *testing.T) {
+	result := skewFixture()
+	analyzer.AttachCostEstimates(result, cost.DefaultRates())
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportRequestsSkewHTML(result, &ExportMetadata{Mode: "requests-skew"}, &buf))
+	assert.Contains(t, buf.String(), "Est. Waste/mo")
+	assert.Contains(t, buf.String(), "Estimated waste")
+}
+
+func TestExportRequestsSkewHTML_QueryLog(t *testing.T) {
+	result := skewFixture()
+	var buf bytes.Buffer
+	require.NoError(t, ExportRequestsSkewHTML(result, &ExportMetadata{Mode: "requests-skew"}, &buf))
+	assert.NotContains(t, buf.String(), "Query log", "no query log section without --include-queries")
+
+	result.Queries = &querylog.Report{
+		PromQLQueries: 1,
+		Entries:       []querylog.Entry{{Kind: querylog.KindRange, Query: `sum(rate(x{namespace="payments"}[5m]))`, DurationMs: 12, Rows: 3}},
+	}
+	buf.Reset()
+	require.NoError(t, ExportRequestsSkewHTML(result, &ExportMetadata{Mode: "requests-skew"}, &buf))
+	assert.Contains(t, buf.String(), "Query log (1 PromQL queries, 0 Kubernetes requests, 0ms)")
+	assert.Contains(t, buf.String(), "<code>sum(rate(x{namespace=&#34;payments&#34;}[5m]))</code>")
+}
+
+func TestExportRequestsSkewHTML_Groups(t *testing.T) {
+	result := skewFixture()
+	result.Metadata.GroupBy = analyzer.GroupByRelease
+	result.Groups = []analyzer.WorkloadGroup{{Name: "payments/api", Chart: "api-1.2.0", Workloads: 2, WastedCPU: 2}}
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportRequestsSkewHTML(result, &ExportMetadata{Mode: "requests-skew"}, &buf))
+	assert.Contains(t, buf.String(), "Waste by release")
+	assert.Contains(t, buf.String(), "<td>payments/api</td>")
+	assert.Contains(t, buf.String(), "<td>api-1.2.0</td>")
+}
+
+func TestExportRequestsSkewHTML_Teams(t *testing.T) {
+	result := skewFixture()
+	var buf bytes.Buffer
+	require.NoError(t, ExportRequestsSkewHTML(result, &ExportMetadata{Mode: "requests-skew"}, &buf))
+	assert.NotContains(t, buf.String(), "<th>Team</th>", "no team column without an owners file")
+
+	result.Results[0].Team = "payments"
+	buf.Reset()
+	require.NoError(t, ExportRequestsSkewHTML(result, &ExportMetadata{Mode: "requests-skew"}, &buf))
+	assert.Contains(t, buf.String(), "<th>Team</th>")
+	assert.Contains(t, buf.String(), "<td>payments</td>")
+}
+
+func TestExporter_HTMLRoutesRequestsSkew(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := Exporter{Format: FormatHTML, Metadata: ExportMetadata{Mode: "requests-skew"}}
+	require.NoError(t, exporter.Export(skewFixture(), &buf))
+	assert.Contains(t, buf.String(), "Skew distribution")
+}
+
+func TestSkewHistogram(t *testing.T) {
+	buckets := skewHistogram([]float64{1.0, 1.5, 1.9, 2.5, 4, 5, 12, 30})
+	require.Len(t, buckets, 6)
+
+	counts := make([]int, len(buckets))
+	for i, b := range buckets {
+		counts[i] = b.Count
+	}
+	assert.Equal(t, []int{1, 2, 1, 1, 1, 2}, counts)
+	assert.Equal(t, "< 1.5x", buckets[0].Label)
+	assert.Equal(t, "≥ 10x", buckets[5].Label)
+	assert.Equal(t, 100, buckets[1].Width)
+	assert.Equal(t, 50, buckets[0].Width)
+}
+
+func TestSkewNamespaceRows(t *testing.T) {
+	rows := skewNamespaceRows(skewFixture())
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, "payments", rows[0].Namespace)
+	assert.Equal(t, 2, rows[0].Workloads)
+	assert.InDelta(t, 2.0, rows[0].WastedCPU, 1e-9)
+	assert.InDelta(t, 3.0, rows[0].AvgSkewCPU, 1e-9)
+	assert.Equal(t, "search", rows[1].Namespace)
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+)
+
+// mermaidTimeFormat is the dateFormat of timeline Gantt charts.
+const mermaidTimeFormat = "2006-01-02 15:04:05"
+
+const eventTimelineHTML = `{{define "title"}}kubenow Events Timeline{{with .Timeline.Workload}} - {{.}}{{end}}{{end}}
+{{define "content"}}{{$t := .Timeline}}    <div class="cards">
+        <div class="card"><div>Window</div><div class="value">{{$t.Window}}</div></div>
+        <div class="card"><div>Deploys</div><div class="value">{{$t.Summary.Deploys}}</div></div>
+        <div class="card"><div>Events</div><div class="value">{{$t.Summary.Events}}</div></div>
+        <div class="card"><div>Warnings</div><div class="value">{{$t.Summary.Warnings}}</div></div>
+        <div class="card"><div>Restarts</div><div class="value">{{$t.Summary.Restarts}}</div></div>
+    </div>
+    <p>{{$t.From.UTC.Format "2006-01-02 15:04:05"}} to {{$t.To.UTC.Format "2006-01-02 15:04:05"}} UTC
+    {{- with $t.Namespace}}, namespace {{.}}{{end}}</p>
+    <table>
+        <thead><tr><th>Time (UTC)</th><th>Kind</th><th>Object</th><th>Reason</th><th>Count</th>
+            <th>Message</th><th>After deploy</th></tr></thead>
+        <tbody>
+        {{- range $t.Entries}}
+            <tr class="{{.Kind}} {{.Type}}"><td>{{.Time.UTC.Format "2006-01-02 15:04:05"}}</td><td>{{.Kind}}</td>
+            <td>{{if not $t.Namespace}}{{.Namespace}}/{{end}}{{.Object}}</td><td class="reason">{{.Reason}}</td>
+            <td class="num">{{if .Count}}{{.Count}}{{end}}</td><td>{{.Message}}</td><td>{{.AfterDeploy}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    <h2>Mermaid</h2>
+    <p>Paste into a Markdown <code>mermaid</code> block to render the timeline.</p>
+    <pre>{{.Mermaid}}</pre>
+{{end}}`
+
+var eventTimelineTemplate = reportTemplate("events-timeline", eventTimelineHTML, nil)
+
+// ExportEventTimelineHTML renders an events timeline as a self-contained
+// HTML page for incident reviews.
+func ExportEventTimelineHTML(tl *analyzer.EventTimeline, metadata *ExportMetadata, w io.Writer) error {
+	data := struct {
+		Metadata *ExportMetadata
+		Timeline *analyzer.EventTimeline
+		Mermaid  string
+	}{metadata, tl, RenderTimelineMermaid(tl)}
+	if err := eventTimelineTemplate.ExecuteTemplate(w, "layout", data); err != nil {
+		return fmt.Errorf("failed to render events timeline HTML: %w", err)
+	}
+	return nil
+}
+
+// RenderTimelineMermaid renders an events timeline as a Mermaid Gantt
+// chart with one milestone per entry, in sections for deploys, warnings,
+// restarts, and normal events. Times are UTC.
+func RenderTimelineMermaid(tl *analyzer.EventTimeline) string {
+	title := "Events timeline"
+	if tl.Workload != "" {
+		title += " " + tl.Namespace + "/" + tl.Workload
+	} else if tl.Namespace != "" {
+		title += " " + tl.Namespace
+	}
+
+	sections := []struct {
+		name    string
+		matches func(e *analyzer.TimelineEntry) bool
+	}{
+		{"Deploys", func(e *analyzer.TimelineEntry) bool { return e.Kind == analyzer.TimelineDeploy }},
+		{"Warnings", func(e *analyzer.TimelineEntry) bool { return e.Kind == analyzer.TimelineEvent && e.Type == "Warning" }},
+		{"Restarts", func(e *analyzer.TimelineEntry) bool { return e.Kind == analyzer.TimelineRestart }},
+		{"Events", func(e *analyzer.TimelineEntry) bool { return e.Kind == analyzer.TimelineEvent && e.Type != "Warning" }},
+	}
+
+	var b strings.Builder
+	b.WriteString("gantt\n")
+	fmt.Fprintf(&b, "  title %s (%s)\n", mermaidText(title), tl.Window)
+	b.WriteString("  dateFormat YYYY-MM-DD HH:mm:ss\n")
+	b.WriteString("  axisFormat %H:%M\n")
+	for _, section := range sections {
+		header := false
+		for i := range tl.Entries {
+			e := &tl.Entries[i]
+			if !section.matches(e) {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(&b, "  section %s\n", section.name)
+				header = true
+			}
+			label := e.Reason + " " + e.Object
+			if e.Kind == analyzer.TimelineDeploy {
+				label, _, _ = strings.Cut(e.Message, ":")
+				label = e.Object + " " + label
+			}
+			if e.Count > 1 {
+				label += fmt.Sprintf(" x%d", e.Count)
+			}
+			fmt.Fprintf(&b, "  %s :milestone, e%d, %s, 0s\n", mermaidText(label), i, e.Time.UTC().Format(mermaidTimeFormat))
+		}
+	}
+	return b.String()
+}
+
+// mermaidText strips the characters that end a Gantt task name or start a
+// comment or entity.
+func mermaidText(s string) string {
+	return strings.NewReplacer(":", " ", ";", " ", "#", "", "%", "").Replace(s)
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+)
+
+func timelineFixture() *analyzer.EventTimeline {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	return &analyzer.EventTimeline{
+		Namespace: "shop", Workload: "Deployment/api", Window: "6h",
+		From: at.Add(-6 * time.Hour), To: at.Add(time.Hour), GeneratedAt: at.Add(time.Hour),
+		Summary: analyzer.TimelineSummary{Deploys: 1, Events: 2, Warnings: 1, Restarts: 1},
+		Entries: []analyzer.TimelineEntry{
+			{Time: at, Kind: analyzer.TimelineDeploy, Namespace: "shop", Object: "Deployment/api", Reason: "Rollout",
+				Message: "revision 7 (api-6b8c): ghcr.io/acme/api:v2"},
+			{Time: at.Add(2 * time.Minute), Kind: analyzer.TimelineEvent, Type: "Normal", Namespace: "shop",
+				Object: "Pod/api-6b8c-aaaaa", Reason: "Pulled", Count: 1, Message: "Successfully pulled image"},
+			{Time: at.Add(5 * time.Minute), Kind: analyzer.TimelineRestart, Namespace: "shop", Object: "Pod/api-6b8c-aaaaa",
+				Reason: "OOMKilled", Count: 3, Message: "container app exited with code 137; 3 restart(s)",
+				AfterDeploy: "5m after revision 7 (api-6b8c)"},
+			{Time: at.Add(6 * time.Minute), Kind: analyzer.TimelineEvent, Type: "Warning", Namespace: "shop",
+				Object: "Pod/api-6b8c-aaaaa", Reason: "BackOff", Count: 12, Message: "Back-off restarting <failed> container"},
+		},
+	}
+}
+
+func TestRenderTimelineMermaid(t *testing.T) {
+	out := RenderTimelineMermaid(timelineFixture())
+	assert.Equal(t, `gantt
+  title Events timeline shop/Deployment/api (6h)
+  dateFormat YYYY-MM-DD HH:mm:ss
+  axisFormat %H:%M
+  section Deploys
+  Deployment/api revision 7 (api-6b8c) :milestone, e0, 2026-03-01 10:00:00, 0s
+  section Warnings
+  BackOff Pod/api-6b8c-aaaaa x12 :milestone, e3, 2026-03-01 10:06:00, 0s
+  section Restarts
+  OOMKilled Pod/api-6b8c-aaaaa x3 :milestone, e2, 2026-03-01 10:05:00, 0s
+  section Events
+  Pulled Pod/api-6b8c-aaaaa :milestone, e1, 2026-03-01 10:02:00, 0s
+`, out)
+}
+
+func TestMermaidText(t *testing.T) {
+	assert.Equal(t, "a b c 50", mermaidText("a:b;c #50%"))
+}
+
+func TestExportEventTimelineHTML(t *testing.T) {
+	var buf bytes.Buffer
+	meta := ExportMetadata{GeneratedAt: time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC), KubenowVersion: "1.0.0", Mode: "events-timeline"}
+	require.NoError(t, ExportEventTimelineHTML(timelineFixture(), &meta, &buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "kubenow Events Timeline - Deployment/api")
+	assert.Contains(t, out, `<tr class="deploy ">`)
+	assert.Contains(t, out, `<tr class="event Warning">`)
+	assert.Contains(t, out, "5m after revision 7 (api-6b8c)")
+	assert.Contains(t, out, "Back-off restarting &lt;failed&gt; container")
+	assert.Contains(t, out, "section Restarts")
+}
@@ -17,14 +17,12 @@ import (
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/promql"
 )
 
 // escapePromLabel escapes a string for safe use in PromQL label matchers.
 func escapePromLabel(s string) string {
-	s = strings.ReplaceAll(s, `\`, `\\`)
-	s = strings.ReplaceAll(s, `"`, `\"`)
-	s = strings.ReplaceAll(s, "\n", `\n`)
-	return `"` + s + `"`
+	return promql.Quote(s)
 }
 
 // ExposureCollector queries Kubernetes APIs to build an ExposureMap.
@@ -44,7 +42,7 @@ func NewExposureCollector(kubeClient kubernetes.Interface, metricsClient metrics
 	}
 }
 
-// SetPrometheusAPI configures the Prometheus client for Linkerd traffic queries.
+// SetPrometheusAPI configures the Prometheus client for mesh traffic queries.
 func (c *ExposureCollector) SetPrometheusAPI(api v1.API) {
 	c.promAPI = api
 }
@@ -71,17 +69,18 @@ func (c *ExposureCollector) Collect(ctx context.Context, namespace, workloadName
 	result.Services = services
 	result.Errors = append(result.Errors, errs...)
 
-	// Step 3: find ingresses for discovered services
+	// Step 3: find ingresses and Gateway API routes for discovered services
 	serviceNames := make([]string, len(services))
 	for i, s := range services {
 		serviceNames[i] = s.Name
 	}
 	ingressMap, errs := c.findIngressesForServices(ctx, namespace, serviceNames)
 	result.Errors = append(result.Errors, errs...)
+	routeMap, errs := c.findGatewayRoutesForServices(ctx, namespace, serviceNames)
+	result.Errors = append(result.Errors, errs...)
 	for i := range result.Services {
-		if routes, ok := ingressMap[result.Services[i].Name]; ok {
-			result.Services[i].Ingresses = routes
-		}
+		name := result.Services[i].Name
+		result.Services[i].Ingresses = append(ingressMap[name], routeMap[name]...)
 	}
 
 	// Step 4: find network policies
@@ -109,7 +108,7 @@ func (c *ExposureCollector) Collect(ctx context.Context, namespace, workloadName
 	return result, nil
 }
 
-// HasPrometheus returns true if a Prometheus API is configured for Linkerd queries.
+// HasPrometheus returns true if a Prometheus API is configured for mesh traffic queries.
 func (c *ExposureCollector) HasPrometheus() bool {
 	return c.promAPI != nil
 }
@@ -246,6 +245,7 @@ func (c *ExposureCollector) findIngressesForServices(ctx context.Context, namesp
 
 				route := IngressRoute{
 					Name:      ing.Name,
+					Kind:      KindIngress,
 					ClassName: className,
 					Hosts:     []string{host},
 					Paths:     []string{pathStr},
@@ -460,53 +460,78 @@ func linkerdTCPOutboundQuery(workload, namespace string) string {
 	return `sum(increase(tcp_open_total{direction="outbound", deployment=` + escapePromLabel(workload) + `, namespace=` + escapePromLabel(namespace) + `}[1h]))`
 }
 
-// CollectTrafficMap queries Linkerd proxy metrics from Prometheus to build
-// a bidirectional traffic map showing inbound sources and outbound destinations.
+// CollectTrafficMap queries service mesh proxy metrics from Prometheus to
+// build a bidirectional traffic map showing inbound sources and outbound
+// destinations. The mesh is auto-detected: Linkerd metrics are tried first,
+// then Istio; the first mesh reporting traffic for the workload wins.
 func (c *ExposureCollector) CollectTrafficMap(ctx context.Context, namespace, workloadName string) (*TrafficMap, error) {
 	if c.promAPI == nil {
 		return nil, fmt.Errorf("prometheus not configured")
 	}
 
-	tm := &TrafficMap{Window: trafficQueryWindow}
+	var first *TrafficMap
+	var lastErr error
+	for i := range meshAdapters {
+		tm, err := c.collectMeshTraffic(ctx, &meshAdapters[i], namespace, workloadName)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if tm.hasTraffic() {
+			return tm, nil
+		}
+		if first == nil {
+			first = tm
+		}
+	}
+	if first == nil {
+		return nil, lastErr
+	}
+	return first, nil
+}
+
+// collectMeshTraffic builds a traffic map from one mesh's proxy metrics.
+func (c *ExposureCollector) collectMeshTraffic(ctx context.Context, a *meshAdapter, namespace, workloadName string) (*TrafficMap, error) {
+	tm := &TrafficMap{Mesh: a.mesh, Window: trafficQueryWindow}
 
 	// Query inbound total + success in sequence (success rate depends on total)
-	inTotal, err := c.queryVector(ctx, linkerdInboundTotalQuery(workloadName, namespace))
+	inTotal, err := c.queryVector(ctx, a.inboundTotal(workloadName, namespace))
 	if err != nil {
 		return nil, fmt.Errorf("inbound total: %w", err)
 	}
-	inSuccess, err := c.queryVector(ctx, linkerdInboundSuccessQuery(workloadName, namespace))
+	inSuccess, err := c.queryVector(ctx, a.inboundSuccess(workloadName, namespace))
 	if err != nil {
 		inSuccess = nil
 	}
 
 	// Query inbound latency (best-effort)
-	inP50, err := c.queryVector(ctx, linkerdInboundLatencyP50Query(workloadName, namespace))
+	inP50, err := c.queryVector(ctx, a.inboundP50(workloadName, namespace))
 	if err != nil {
 		inP50 = nil
 	}
-	inP99, err := c.queryVector(ctx, linkerdInboundLatencyP99Query(workloadName, namespace))
+	inP99, err := c.queryVector(ctx, a.inboundP99(workloadName, namespace))
 	if err != nil {
 		inP99 = nil
 	}
 
-	tm.Inbound = buildEdges(inTotal, inSuccess, inP50, inP99, "deployment", "namespace")
+	tm.Inbound = buildEdges(inTotal, inSuccess, inP50, inP99, a.srcWorkloadLabel, a.srcNSLabel)
 
 	// Query outbound total + success
-	outTotal, err := c.queryVector(ctx, linkerdOutboundTotalQuery(workloadName, namespace))
+	outTotal, err := c.queryVector(ctx, a.outboundTotal(workloadName, namespace))
 	if err != nil {
 		// Outbound query failure is non-fatal — still return inbound data
 		tm.Outbound = []TrafficEdge{}
 	} else {
-		outSuccess, sErr := c.queryVector(ctx, linkerdOutboundSuccessQuery(workloadName, namespace))
+		outSuccess, sErr := c.queryVector(ctx, a.outboundSuccess(workloadName, namespace))
 		if sErr != nil {
 			outSuccess = nil
 		}
-		tm.Outbound = buildEdges(outTotal, outSuccess, nil, nil, "dst_deployment", "dst_namespace")
+		tm.Outbound = buildEdges(outTotal, outSuccess, nil, nil, a.dstWorkloadLabel, a.dstNSLabel)
 	}
 
 	// TCP counts (best-effort)
-	tm.TCPIn = queryScalar(ctx, c, linkerdTCPInboundQuery(workloadName, namespace))
-	tm.TCPOut = queryScalar(ctx, c, linkerdTCPOutboundQuery(workloadName, namespace))
+	tm.TCPIn = queryScalar(ctx, c, a.tcpInbound(workloadName, namespace))
+	tm.TCPOut = queryScalar(ctx, c, a.tcpOutbound(workloadName, namespace))
 
 	return tm, nil
 }
@@ -0,0 +1,403 @@
+// This file discovers Gateway API routes (HTTPRoute, GRPCRoute, TCPRoute)
+// and their parent Gateways that send traffic to a workload's services.
+
+package exposure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Gateway API is a set of CRDs, so it is read raw and skipped when the CRDs
+// are not installed.
+const (
+	gatewayAPIGroup     = "gateway.networking.k8s.io"
+	gatewayAPIGroupPath = "/apis/" + gatewayAPIGroup
+)
+
+// Route kinds, as reported in IngressRoute.Kind.
+const (
+	KindIngress   = "Ingress"
+	KindHTTPRoute = "HTTPRoute"
+	KindGRPCRoute = "GRPCRoute"
+	KindTCPRoute  = "TCPRoute"
+)
+
+// gatewayRouteResources lists the route resources and the API versions to
+// try, newest first, so clusters on older Gateway API releases still resolve.
+var gatewayRouteResources = []struct {
+	kind     string
+	resource string
+	versions []string
+}{
+	{KindHTTPRoute, "httproutes", []string{"v1", "v1beta1"}},
+	{KindGRPCRoute, "grpcroutes", []string{"v1", "v1alpha2"}},
+	{KindTCPRoute, "tcproutes", []string{"v1alpha2"}},
+}
+
+var gatewayVersions = []string{"v1", "v1beta1"}
+
+// gatewayRoute holds the route fields kubenow reads. HTTPRoute, GRPCRoute,
+// and TCPRoute share this shape; match fields absent from a kind stay empty.
+type gatewayRoute struct {
+	Kind     string            `json:"kind"`
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		ParentRefs []gatewayParentRef `json:"parentRefs"`
+		Hostnames  []string           `json:"hostnames"`
+		Rules      []struct {
+			Matches     []gatewayRouteMatch `json:"matches"`
+			BackendRefs []gatewayBackendRef `json:"backendRefs"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// gatewayRouteMatch is an HTTPRoute path match or a GRPCRoute method match.
+type gatewayRouteMatch struct {
+	Path *struct {
+		Value string `json:"value"`
+	} `json:"path,omitempty"`
+	Method *struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+	} `json:"method,omitempty"`
+}
+
+type gatewayBackendRef struct {
+	Group     *string `json:"group,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Name      string  `json:"name"`
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+type gatewayParentRef struct {
+	Group       *string `json:"group,omitempty"`
+	Kind        *string `json:"kind,omitempty"`
+	Namespace   *string `json:"namespace,omitempty"`
+	Name        string  `json:"name"`
+	SectionName *string `json:"sectionName,omitempty"`
+}
+
+// gatewayObject holds the Gateway fields kubenow reads.
+type gatewayObject struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		GatewayClassName string            `json:"gatewayClassName"`
+		Listeners        []gatewayListener `json:"listeners"`
+	} `json:"spec"`
+}
+
+type gatewayListener struct {
+	Name     string  `json:"name"`
+	Hostname *string `json:"hostname,omitempty"`
+	Port     int32   `json:"port"`
+	Protocol string  `json:"protocol"`
+}
+
+// findGatewayRoutesForServices finds Gateway API routes in namespace whose
+// backendRefs point at the given services, resolving each route's parent
+// Gateways for class, listener hostnames, and TLS. A missing Gateway API is
+// not an error.
+func (c *ExposureCollector) findGatewayRoutesForServices(
+	ctx context.Context, namespace string, serviceNames []string,
+) (routes map[string][]IngressRoute, errs []string) {
+	if len(serviceNames) == 0 || c.kubeClient == nil {
+		return nil, nil
+	}
+
+	var all []gatewayRoute
+	for _, r := range gatewayRouteResources {
+		items, err := c.listGatewayRoutes(ctx, namespace, r.resource, r.versions)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", r.resource, err))
+			continue
+		}
+		for i := range items {
+			items[i].Kind = r.kind
+		}
+		all = append(all, items...)
+	}
+	if len(all) == 0 {
+		return nil, errs
+	}
+
+	gateways := make(map[string]*gatewayObject)
+	for i := range all {
+		for _, ref := range all[i].Spec.ParentRefs {
+			ns, ok := gatewayParentKey(&ref, all[i].Metadata.Namespace)
+			if !ok {
+				continue
+			}
+			key := ns + "/" + ref.Name
+			if _, seen := gateways[key]; seen {
+				continue
+			}
+			gw, err := c.getGateway(ctx, ns, ref.Name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Sprintf("gateway %s: %v", key, err))
+			}
+			gateways[key] = gw // nil when missing; the route is still reported
+		}
+	}
+
+	return matchGatewayRoutes(all, gateways, serviceNames), errs
+}
+
+// listGatewayRoutes lists one route resource, trying each API version until
+// one is served. Returns nil without error when no version is installed.
+func (c *ExposureCollector) listGatewayRoutes(
+	ctx context.Context, namespace, resource string, versions []string,
+) ([]gatewayRoute, error) {
+	rc := c.kubeClient.Discovery().RESTClient()
+	if rc == nil {
+		return nil, nil
+	}
+	for _, v := range versions {
+		path := fmt.Sprintf("%s/%s/namespaces/%s/%s", gatewayAPIGroupPath, v, namespace, resource)
+		data, err := rc.Get().AbsPath(path).DoRaw(ctx)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parseGatewayRouteList(data)
+	}
+	return nil, nil
+}
+
+// getGateway fetches a Gateway, trying each served API version.
+func (c *ExposureCollector) getGateway(ctx context.Context, namespace, name string) (*gatewayObject, error) {
+	rc := c.kubeClient.Discovery().RESTClient()
+	if rc == nil {
+		return nil, nil
+	}
+	var lastErr error
+	for _, v := range gatewayVersions {
+		path := fmt.Sprintf("%s/%s/namespaces/%s/gateways/%s", gatewayAPIGroupPath, v, namespace, name)
+		data, err := rc.Get().AbsPath(path).DoRaw(ctx)
+		if err != nil {
+			lastErr = err
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		var gw gatewayObject
+		err = json.Unmarshal(data, &gw)
+		if err != nil {
+			return nil, fmt.Errorf("decode gateway: %w", err)
+		}
+		return &gw, nil
+	}
+	return nil, lastErr
+}
+
+func parseGatewayRouteList(data []byte) ([]gatewayRoute, error) {
+	var list struct {
+		Items []gatewayRoute `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decode route list: %w", err)
+	}
+	return list.Items, nil
+}
+
+// gatewayParentKey returns the namespace of a parentRef that names a
+// Gateway. Other parents (e.g. a Service for mesh routing) are skipped.
+func gatewayParentKey(ref *gatewayParentRef, routeNamespace string) (string, bool) {
+	if ref.Group != nil && *ref.Group != gatewayAPIGroup {
+		return "", false
+	}
+	if ref.Kind != nil && *ref.Kind != "Gateway" {
+		return "", false
+	}
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		return *ref.Namespace, true
+	}
+	return routeNamespace, true
+}
+
+// matchGatewayRoutes builds one IngressRoute per (route, service) pair for
+// routes whose backendRefs name one of the services in the route's namespace.
+func matchGatewayRoutes(routes []gatewayRoute, gateways map[string]*gatewayObject, serviceNames []string) map[string][]IngressRoute {
+	nameSet := make(map[string]bool, len(serviceNames))
+	for _, n := range serviceNames {
+		nameSet[n] = true
+	}
+
+	result := make(map[string][]IngressRoute)
+	for i := range routes {
+		route := &routes[i]
+		listeners, classes, parents := routeParents(route, gateways)
+
+		backends := map[string][]string{} // service → paths
+		var order []string
+		for _, rule := range route.Spec.Rules {
+			paths := rulePaths(route.Kind, rule.Matches)
+			for _, b := range rule.BackendRefs {
+				if !nameSet[b.Name] || !localServiceBackend(&b, route.Metadata.Namespace) {
+					continue
+				}
+				if _, ok := backends[b.Name]; !ok {
+					order = append(order, b.Name)
+				}
+				backends[b.Name] = appendUnique(backends[b.Name], paths...)
+			}
+		}
+
+		hosts := routeHosts(route, listeners)
+		for _, svc := range order {
+			result[svc] = append(result[svc], IngressRoute{
+				Name:      route.Metadata.Name,
+				Kind:      route.Kind,
+				ClassName: strings.Join(classes, ","),
+				Hosts:     hosts,
+				Paths:     backends[svc],
+				TLS:       listenersTerminateTLS(listeners, hosts),
+				Gateways:  parents,
+			})
+		}
+	}
+	return result
+}
+
+// routeParents returns the listeners the route attaches to, the distinct
+// gateway classes, and the parent Gateways as namespace/name.
+func routeParents(route *gatewayRoute, gateways map[string]*gatewayObject) (listeners []gatewayListener, classes, parents []string) {
+	for _, ref := range route.Spec.ParentRefs {
+		ns, ok := gatewayParentKey(&ref, route.Metadata.Namespace)
+		if !ok {
+			continue
+		}
+		key := ns + "/" + ref.Name
+		parents = appendUnique(parents, key)
+		gw := gateways[key]
+		if gw == nil {
+			continue
+		}
+		if gw.Spec.GatewayClassName != "" {
+			classes = appendUnique(classes, gw.Spec.GatewayClassName)
+		}
+		for _, l := range gw.Spec.Listeners {
+			if ref.SectionName != nil && *ref.SectionName != l.Name {
+				continue
+			}
+			listeners = append(listeners, l)
+		}
+	}
+	return listeners, classes, parents
+}
+
+// rulePaths renders a rule's matches: HTTP path prefixes, gRPC
+// /service/method paths, or nothing for TCP routes.
+func rulePaths(kind string, matches []gatewayRouteMatch) []string {
+	if kind == KindTCPRoute {
+		return nil
+	}
+	var paths []string
+	for _, m := range matches {
+		switch {
+		case m.Path != nil && m.Path.Value != "":
+			paths = append(paths, m.Path.Value)
+		case m.Method != nil && m.Method.Service != "":
+			p := "/" + m.Method.Service
+			if m.Method.Method != "" {
+				p += "/" + m.Method.Method
+			}
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	return paths
+}
+
+// routeHosts returns the route's hostnames, falling back to the hostnames
+// of the listeners it attaches to, or "*" when neither restricts them.
+func routeHosts(route *gatewayRoute, listeners []gatewayListener) []string {
+	if len(route.Spec.Hostnames) > 0 {
+		return route.Spec.Hostnames
+	}
+	var hosts []string
+	for _, l := range listeners {
+		if l.Hostname == nil || *l.Hostname == "" {
+			return []string{"*"}
+		}
+		hosts = appendUnique(hosts, *l.Hostname)
+	}
+	if len(hosts) == 0 {
+		return []string{"*"}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// listenersTerminateTLS reports whether a TLS listener (HTTPS or TLS
+// protocol) accepts any of the hosts.
+func listenersTerminateTLS(listeners []gatewayListener, hosts []string) bool {
+	for _, l := range listeners {
+		if l.Protocol != "HTTPS" && l.Protocol != "TLS" {
+			continue
+		}
+		if l.Hostname == nil || *l.Hostname == "" {
+			return true
+		}
+		for _, h := range hosts {
+			if hostnameMatches(*l.Hostname, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hostnameMatches reports whether a listener hostname (possibly a
+// "*.example.com" wildcard) accepts host.
+func hostnameMatches(listener, host string) bool {
+	if host == "*" || listener == host {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(listener, "*"); ok {
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	if suffix, ok := strings.CutPrefix(host, "*"); ok {
+		return strings.HasSuffix(listener, suffix)
+	}
+	return false
+}
+
+// localServiceBackend reports whether a backendRef targets a core Service
+// in the route's own namespace.
+func localServiceBackend(b *gatewayBackendRef, routeNamespace string) bool {
+	if b.Group != nil && *b.Group != "" {
+		return false
+	}
+	if b.Kind != nil && *b.Kind != "Service" {
+		return false
+	}
+	return b.Namespace == nil || *b.Namespace == "" || *b.Namespace == routeNamespace
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
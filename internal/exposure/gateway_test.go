@@ -0,0 +1,137 @@
+package exposure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+const testHTTPRouteList = `{"items":[
+  {"metadata":{"name":"web","namespace":"billing"},
+   "spec":{"parentRefs":[{"name":"public","namespace":"infra","sectionName":"https"}],
+           "hostnames":["billing.example.com"],
+           "rules":[
+             {"matches":[{"path":{"type":"PathPrefix","value":"/api"}}],"backendRefs":[{"name":"worker-svc","port":8080}]},
+             {"matches":[{"path":{"type":"PathPrefix","value":"/static"}}],"backendRefs":[{"name":"cdn-svc","port":80}]}
+           ]}},
+  {"metadata":{"name":"cross-ns","namespace":"billing"},
+   "spec":{"parentRefs":[{"name":"public","namespace":"infra"}],
+           "rules":[{"backendRefs":[{"name":"worker-svc","namespace":"other"}]}]}}
+]}`
+
+const testGRPCRouteList = `{"items":[
+  {"metadata":{"name":"rpc","namespace":"billing"},
+   "spec":{"parentRefs":[{"name":"internal"}],
+           "rules":[{"matches":[{"method":{"service":"billing.v1.Invoices","method":"Create"}}],
+                     "backendRefs":[{"name":"worker-svc","port":9090}]}]}}
+]}`
+
+const testPublicGateway = `{"metadata":{"name":"public","namespace":"infra"},
+  "spec":{"gatewayClassName":"envoy",
+          "listeners":[{"name":"http","port":80,"protocol":"HTTP"},
+                       {"name":"https","hostname":"*.example.com","port":443,"protocol":"HTTPS"}]}}`
+
+const testInternalGateway = `{"metadata":{"name":"internal","namespace":"billing"},
+  "spec":{"gatewayClassName":"istio","listeners":[{"name":"grpc","hostname":"rpc.internal","port":9090,"protocol":"HTTP"}]}}`
+
+func gatewayAPIServer(t *testing.T, responses map[string]string) kubernetes.Interface {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+	return client
+}
+
+func TestFindGatewayRoutesForServices(t *testing.T) {
+	client := gatewayAPIServer(t, map[string]string{
+		"/apis/gateway.networking.k8s.io/v1/namespaces/billing/httproutes":        testHTTPRouteList,
+		"/apis/gateway.networking.k8s.io/v1/namespaces/billing/grpcroutes":        testGRPCRouteList,
+		"/apis/gateway.networking.k8s.io/v1/namespaces/infra/gateways/public":     testPublicGateway,
+		"/apis/gateway.networking.k8s.io/v1/namespaces/billing/gateways/internal": testInternalGateway,
+	})
+	collector := &ExposureCollector{kubeClient: client}
+
+	routes, errs := collector.findGatewayRoutesForServices(context.Background(), "billing", []string{"worker-svc"})
+	assert.Empty(t, errs)
+	require.Len(t, routes["worker-svc"], 2, "cross-namespace backendRef is not this service")
+
+	web := routes["worker-svc"][0]
+	assert.Equal(t, "web", web.Name)
+	assert.Equal(t, KindHTTPRoute, web.Kind)
+	assert.Equal(t, "envoy", web.ClassName)
+	assert.Equal(t, []string{"billing.example.com"}, web.Hosts)
+	assert.Equal(t, []string{"/api"}, web.Paths)
+	assert.Equal(t, []string{"infra/public"}, web.Gateways)
+	assert.True(t, web.TLS, "attached to the HTTPS listener matching *.example.com")
+
+	rpc := routes["worker-svc"][1]
+	assert.Equal(t, KindGRPCRoute, rpc.Kind)
+	assert.Equal(t, "istio", rpc.ClassName)
+	assert.Equal(t, []string{"rpc.internal"}, rpc.Hosts, "hostnames inherited from the listener")
+	assert.Equal(t, []string{"/billing.v1.Invoices/Create"}, rpc.Paths)
+	assert.False(t, rpc.TLS)
+}
+
+func TestFindGatewayRoutesForServices_NotInstalled(t *testing.T) {
+	collector := &ExposureCollector{kubeClient: gatewayAPIServer(t, nil)}
+	routes, errs := collector.findGatewayRoutesForServices(context.Background(), "billing", []string{"worker-svc"})
+	assert.Empty(t, errs)
+	assert.Empty(t, routes)
+
+	// Fake clientsets have no REST client; discovery is skipped.
+	collector = &ExposureCollector{kubeClient: fake.NewClientset()}
+	routes, errs = collector.findGatewayRoutesForServices(context.Background(), "billing", []string{"worker-svc"})
+	assert.Empty(t, errs)
+	assert.Empty(t, routes)
+}
+
+func TestMatchGatewayRoutes_TCPRouteAndMissingGateway(t *testing.T) {
+	routes, err := parseGatewayRouteList([]byte(`{"items":[
+	  {"metadata":{"name":"pg","namespace":"data"},
+	   "spec":{"parentRefs":[{"name":"tcp-gw"},{"kind":"Service","group":"","name":"mesh"}],
+	           "rules":[{"backendRefs":[{"name":"postgres","port":5432}]}]}}]}`))
+	require.NoError(t, err)
+	routes[0].Kind = KindTCPRoute
+
+	result := matchGatewayRoutes(routes, map[string]*gatewayObject{"data/tcp-gw": nil}, []string{"postgres"})
+	require.Len(t, result["postgres"], 1)
+	r := result["postgres"][0]
+	assert.Equal(t, []string{"*"}, r.Hosts)
+	assert.Empty(t, r.Paths)
+	assert.Empty(t, r.ClassName)
+	assert.Equal(t, []string{"data/tcp-gw"}, r.Gateways, "Service parentRefs are not gateways")
+}
+
+func TestHostnameMatches(t *testing.T) {
+	tests := []struct {
+		listener string
+		host     string
+		want     bool
+	}{
+		{"api.example.com", "api.example.com", true},
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"api.example.com", "web.example.com", false},
+		{"api.example.com", "*", true},
+		{"api.example.com", "*.example.com", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, hostnameMatches(tt.listener, tt.host), "%s vs %s", tt.listener, tt.host)
+	}
+}
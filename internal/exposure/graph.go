@@ -0,0 +1,225 @@
+package exposure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GraphFormat is the output format for traffic graph export.
+type GraphFormat string
+
+// GraphFormatDOT and GraphFormatMermaid define supported graph formats.
+const (
+	GraphFormatDOT     GraphFormat = "dot"
+	GraphFormatMermaid GraphFormat = "mermaid"
+)
+
+// Success-rate thresholds used to flag unhealthy edges (same as the TUI).
+const (
+	successRateWarn     = 0.99
+	successRateCritical = 0.95
+)
+
+// GraphEdge is a directed traffic edge between two workloads.
+type GraphEdge struct {
+	From        string  `json:"from"` // namespace/deployment
+	To          string  `json:"to"`   // namespace/deployment
+	RPS         float64 `json:"rps"`
+	SuccessRate float64 `json:"success_rate"` // 0.0-1.0, -1 if unknown
+}
+
+// TrafficGraph is a set of workloads and the measured traffic between them.
+type TrafficGraph struct {
+	Focus  []string      `json:"focus,omitempty"` // nodes to highlight (e.g. the analyzed workload)
+	Nodes  []string      `json:"nodes"`
+	Edges  []GraphEdge   `json:"edges"`
+	Window time.Duration `json:"-"` // query window the edges were measured over
+}
+
+// Degraded reports whether the edge success rate is known and below 99%.
+func (e GraphEdge) Degraded() bool {
+	return e.SuccessRate >= 0 && e.SuccessRate < successRateWarn
+}
+
+// Critical reports whether the edge success rate is known and below 95%.
+func (e GraphEdge) Critical() bool {
+	return e.SuccessRate >= 0 && e.SuccessRate < successRateCritical
+}
+
+// graphNodeID returns the namespace/name identifier of a workload.
+func graphNodeID(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// BuildWorkloadGraph converts a single-workload TrafficMap into a graph with
+// the workload at the center of its inbound and outbound edges.
+func BuildWorkloadGraph(namespace, workload string, tm *TrafficMap) *TrafficGraph {
+	center := graphNodeID(namespace, workload)
+	g := &TrafficGraph{Focus: []string{center}}
+	if tm == nil {
+		g.Nodes = []string{center}
+		return g
+	}
+	g.Window = tm.Window
+
+	for _, e := range tm.Inbound {
+		g.Edges = append(g.Edges, GraphEdge{
+			From:        graphNodeID(e.Namespace, e.Deployment),
+			To:          center,
+			RPS:         e.RPS,
+			SuccessRate: e.SuccessRate,
+		})
+	}
+	for _, e := range tm.Outbound {
+		g.Edges = append(g.Edges, GraphEdge{
+			From:        center,
+			To:          graphNodeID(e.Namespace, e.Deployment),
+			RPS:         e.RPS,
+			SuccessRate: e.SuccessRate,
+		})
+	}
+
+	g.Nodes = collectNodes(g.Focus, g.Edges)
+	return g
+}
+
+// collectNodes returns the sorted, deduplicated set of nodes referenced by
+// the seed list and edges.
+func collectNodes(seed []string, edges []GraphEdge) []string {
+	seen := make(map[string]bool, len(seed)+len(edges)*2)
+	for _, n := range seed {
+		seen[n] = true
+	}
+	for _, e := range edges {
+		seen[e.From] = true
+		seen[e.To] = true
+	}
+	nodes := make([]string, 0, len(seen))
+	for n := range seen {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// RenderGraph renders the graph in the requested format.
+func RenderGraph(g *TrafficGraph, format GraphFormat) (string, error) {
+	if g == nil {
+		return "", fmt.Errorf("no traffic graph to render")
+	}
+	switch format {
+	case GraphFormatDOT:
+		return renderDOT(g), nil
+	case GraphFormatMermaid:
+		return renderMermaid(g), nil
+	default:
+		return "", fmt.Errorf("unsupported graph format: %q (supported: dot, mermaid)", format)
+	}
+}
+
+// EdgeLabel returns the "12.3 rps, 99.5% ok" label for an edge.
+func EdgeLabel(e GraphEdge) string {
+	label := fmt.Sprintf("%.1f rps", e.RPS)
+	if e.SuccessRate >= 0 {
+		label += fmt.Sprintf(", %.1f%% ok", e.SuccessRate*100)
+	}
+	return label
+}
+
+func renderDOT(g *TrafficGraph) string {
+	focus := toSet(g.Focus)
+
+	var b strings.Builder
+	b.WriteString("digraph traffic {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, n := range g.Nodes {
+		attrs := ""
+		if focus[n] {
+			attrs = " [style=bold]"
+		}
+		fmt.Fprintf(&b, "  %s%s;\n", dotQuote(n), attrs)
+	}
+
+	for _, e := range g.Edges {
+		attrs := fmt.Sprintf("label=%s", dotQuote(EdgeLabel(e)))
+		switch {
+		case e.Critical():
+			attrs += ", color=red, fontcolor=red"
+		case e.Degraded():
+			attrs += ", color=orange, fontcolor=orange"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(e.From), dotQuote(e.To), attrs)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func renderMermaid(g *TrafficGraph) string {
+	focus := toSet(g.Focus)
+
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n], mermaidEscape(n))
+	}
+
+	var critical, degraded []int
+	for i, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", ids[e.From], mermaidEscape(EdgeLabel(e)), ids[e.To])
+		switch {
+		case e.Critical():
+			critical = append(critical, i)
+		case e.Degraded():
+			degraded = append(degraded, i)
+		}
+	}
+
+	for _, n := range g.Nodes {
+		if focus[n] {
+			fmt.Fprintf(&b, "  style %s stroke-width:3px\n", ids[n])
+		}
+	}
+	if len(critical) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red,color:red\n", joinInts(critical))
+	}
+	if len(degraded) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:orange,color:orange\n", joinInts(degraded))
+	}
+
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+func joinInts(xs []int) string {
+	parts := make([]string, len(xs))
+	for i, x := range xs {
+		parts[i] = fmt.Sprintf("%d", x)
+	}
+	return strings.Join(parts, ",")
+}
+
+func toSet(items []string) map[string]bool {
+	m := make(map[string]bool, len(items))
+	for _, s := range items {
+		m[s] = true
+	}
+	return m
+}
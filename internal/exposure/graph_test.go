@@ -0,0 +1,89 @@
+package exposure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTrafficMap() *TrafficMap {
+	return &TrafficMap{
+		Inbound: []TrafficEdge{
+			{Deployment: "web", Namespace: "prod", RPS: 12.34, SuccessRate: 0.999},
+			{Deployment: "batch", Namespace: "jobs", RPS: 0.5, SuccessRate: 0.90},
+		},
+		Outbound: []TrafficEdge{
+			{Deployment: "db-proxy", Namespace: "prod", RPS: 3, SuccessRate: -1},
+		},
+	}
+}
+
+func TestBuildWorkloadGraph(t *testing.T) {
+	g := BuildWorkloadGraph("prod", "api", testTrafficMap())
+
+	assert.Equal(t, []string{"prod/api"}, g.Focus)
+	assert.Equal(t, []string{"jobs/batch", "prod/api", "prod/db-proxy", "prod/web"}, g.Nodes)
+	require.Len(t, g.Edges, 3)
+	assert.Equal(t, GraphEdge{From: "prod/web", To: "prod/api", RPS: 12.34, SuccessRate: 0.999}, g.Edges[0])
+	assert.Equal(t, "prod/api", g.Edges[2].From)
+	assert.Equal(t, "prod/db-proxy", g.Edges[2].To)
+}
+
+func TestBuildWorkloadGraph_NilMap(t *testing.T) {
+	g := BuildWorkloadGraph("prod", "api", nil)
+	assert.Equal(t, []string{"prod/api"}, g.Nodes)
+	assert.Empty(t, g.Edges)
+}
+
+func TestEdgeLabel(t *testing.T) {
+	assert.Equal(t, "12.3 rps, 99.9% ok", EdgeLabel(GraphEdge{RPS: 12.34, SuccessRate: 0.999}))
+	assert.Equal(t, "3.0 rps", EdgeLabel(GraphEdge{RPS: 3, SuccessRate: -1}))
+}
+
+func TestGraphEdge_Health(t *testing.T) {
+	assert.False(t, GraphEdge{SuccessRate: -1}.Degraded())
+	assert.False(t, GraphEdge{SuccessRate: 0.995}.Degraded())
+	assert.True(t, GraphEdge{SuccessRate: 0.97}.Degraded())
+	assert.False(t, GraphEdge{SuccessRate: 0.97}.Critical())
+	assert.True(t, GraphEdge{SuccessRate: 0.5}.Critical())
+}
+
+func TestRenderGraph_DOT(t *testing.T) {
+	out, err := RenderGraph(BuildWorkloadGraph("prod", "api", testTrafficMap()), GraphFormatDOT)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(out, "digraph traffic {\n"))
+	assert.Contains(t, out, `"prod/api" [style=bold];`)
+	assert.Contains(t, out, `"prod/web" -> "prod/api" [label="12.3 rps, 99.9% ok"];`)
+	assert.Contains(t, out, `"jobs/batch" -> "prod/api" [label="0.5 rps, 90.0% ok", color=red, fontcolor=red];`)
+	assert.Contains(t, out, `"prod/api" -> "prod/db-proxy" [label="3.0 rps"];`)
+	assert.True(t, strings.HasSuffix(out, "}\n"))
+}
+
+func TestRenderGraph_Mermaid(t *testing.T) {
+	out, err := RenderGraph(BuildWorkloadGraph("prod", "api", testTrafficMap()), GraphFormatMermaid)
+	require.NoError(t, err)
+
+	// Nodes are sorted: jobs/batch=n0, prod/api=n1, prod/db-proxy=n2, prod/web=n3
+	assert.True(t, strings.HasPrefix(out, "graph LR\n"))
+	assert.Contains(t, out, `n1["prod/api"]`)
+	assert.Contains(t, out, `n3 -->|"12.3 rps, 99.9% ok"| n1`)
+	assert.Contains(t, out, `n1 -->|"3.0 rps"| n2`)
+	assert.Contains(t, out, "style n1 stroke-width:3px")
+	assert.Contains(t, out, "linkStyle 1 stroke:red,color:red")
+}
+
+func TestRenderGraph_Errors(t *testing.T) {
+	_, err := RenderGraph(nil, GraphFormatDOT)
+	assert.Error(t, err)
+
+	_, err = RenderGraph(&TrafficGraph{}, "svg")
+	assert.Error(t, err)
+}
+
+func TestQuoting(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c"`, dotQuote(`a"b\c`))
+	assert.Equal(t, "a#quot;b", mermaidEscape(`a"b`))
+}
@@ -0,0 +1,108 @@
+package exposure
+
+import "github.com/prometheus/common/model"
+
+// Mesh identifies the service mesh whose proxy metrics back a traffic map.
+type Mesh string
+
+const (
+	MeshLinkerd Mesh = "Linkerd"
+	MeshIstio   Mesh = "Istio"
+)
+
+// meshAdapter describes how to query one mesh's proxy metrics: the PromQL
+// builders for a workload's traffic and the labels naming the peer workload
+// in inbound (source) and outbound (destination) results.
+type meshAdapter struct {
+	mesh             Mesh
+	inboundTotal     func(workload, namespace string) string
+	inboundSuccess   func(workload, namespace string) string
+	inboundP50       func(workload, namespace string) string
+	inboundP99       func(workload, namespace string) string
+	outboundTotal    func(workload, namespace string) string
+	outboundSuccess  func(workload, namespace string) string
+	tcpInbound       func(workload, namespace string) string
+	tcpOutbound      func(workload, namespace string) string
+	srcWorkloadLabel model.LabelName
+	srcNSLabel       model.LabelName
+	dstWorkloadLabel model.LabelName
+	dstNSLabel       model.LabelName
+}
+
+// meshAdapters lists the supported meshes in detection order.
+var meshAdapters = []meshAdapter{
+	{
+		mesh:             MeshLinkerd,
+		inboundTotal:     linkerdInboundTotalQuery,
+		inboundSuccess:   linkerdInboundSuccessQuery,
+		inboundP50:       linkerdInboundLatencyP50Query,
+		inboundP99:       linkerdInboundLatencyP99Query,
+		outboundTotal:    linkerdOutboundTotalQuery,
+		outboundSuccess:  linkerdOutboundSuccessQuery,
+		tcpInbound:       linkerdTCPInboundQuery,
+		tcpOutbound:      linkerdTCPOutboundQuery,
+		srcWorkloadLabel: "deployment",
+		srcNSLabel:       "namespace",
+		dstWorkloadLabel: "dst_deployment",
+		dstNSLabel:       "dst_namespace",
+	},
+	{
+		mesh:             MeshIstio,
+		inboundTotal:     func(w, ns string) string { return istioInboundQuery(w, ns, false) },
+		inboundSuccess:   func(w, ns string) string { return istioInboundQuery(w, ns, true) },
+		inboundP50:       func(w, ns string) string { return istioInboundLatencyQuery(w, ns, "0.5") },
+		inboundP99:       func(w, ns string) string { return istioInboundLatencyQuery(w, ns, "0.99") },
+		outboundTotal:    func(w, ns string) string { return istioOutboundQuery(w, ns, false) },
+		outboundSuccess:  func(w, ns string) string { return istioOutboundQuery(w, ns, true) },
+		tcpInbound:       istioTCPInboundQuery,
+		tcpOutbound:      istioTCPOutboundQuery,
+		srcWorkloadLabel: "source_workload",
+		srcNSLabel:       "source_workload_namespace",
+		dstWorkloadLabel: "destination_workload",
+		dstNSLabel:       "destination_workload_namespace",
+	},
+}
+
+// hasTraffic reports whether any request or TCP traffic was measured.
+func (tm *TrafficMap) hasTraffic() bool {
+	return len(tm.Inbound) > 0 || len(tm.Outbound) > 0 || tm.TCPIn > 0 || tm.TCPOut > 0
+}
+
+// Istio queries use the server-side (reporter="destination") view for
+// inbound traffic and the client-side (reporter="source") view for outbound,
+// so each request is counted once. Any non-5xx response counts as success.
+
+func istioSuccessMatcher(successOnly bool) string {
+	if successOnly {
+		return `, response_code!~"5.."`
+	}
+	return ""
+}
+
+func istioInboundQuery(workload, namespace string, successOnly bool) string {
+	return `sum by(source_workload, source_workload_namespace)(increase(istio_requests_total{reporter="destination", destination_workload=` +
+		escapePromLabel(workload) + `, destination_workload_namespace=` + escapePromLabel(namespace) +
+		istioSuccessMatcher(successOnly) + `}[1h]))`
+}
+
+func istioOutboundQuery(workload, namespace string, successOnly bool) string {
+	return `sum by(destination_workload, destination_workload_namespace)(increase(istio_requests_total{reporter="source", source_workload=` +
+		escapePromLabel(workload) + `, source_workload_namespace=` + escapePromLabel(namespace) +
+		istioSuccessMatcher(successOnly) + `}[1h]))`
+}
+
+func istioInboundLatencyQuery(workload, namespace, quantile string) string {
+	return `histogram_quantile(` + quantile + `, sum by(le, source_workload, source_workload_namespace)(` +
+		`rate(istio_request_duration_milliseconds_bucket{reporter="destination", destination_workload=` +
+		escapePromLabel(workload) + `, destination_workload_namespace=` + escapePromLabel(namespace) + `}[5m])))`
+}
+
+func istioTCPInboundQuery(workload, namespace string) string {
+	return `sum(increase(istio_tcp_connections_opened_total{reporter="destination", destination_workload=` +
+		escapePromLabel(workload) + `, destination_workload_namespace=` + escapePromLabel(namespace) + `}[1h]))`
+}
+
+func istioTCPOutboundQuery(workload, namespace string) string {
+	return `sum(increase(istio_tcp_connections_opened_total{reporter="source", source_workload=` +
+		escapePromLabel(workload) + `, source_workload_namespace=` + escapePromLabel(namespace) + `}[1h]))`
+}
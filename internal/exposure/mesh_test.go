@@ -0,0 +1,109 @@
+package exposure
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryMatchPromAPI answers each query with the first result whose
+// substrings all appear in the query; other queries get empty vectors.
+type queryMatchPromAPI struct {
+	v1.API
+	results []queryMatch
+}
+
+type queryMatch struct {
+	contains []string
+	value    model.Value
+}
+
+func (m *queryMatchPromAPI) Query(_ context.Context, query string, _ time.Time, _ ...v1.Option) (model.Value, v1.Warnings, error) {
+	for _, r := range m.results {
+		matched := true
+		for _, sub := range r.contains {
+			if !strings.Contains(query, sub) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r.value, nil, nil
+		}
+	}
+	return model.Vector{}, nil, nil
+}
+
+func TestCollectTrafficMap_Istio(t *testing.T) {
+	inbound := `istio_requests_total{reporter="destination"`
+	outbound := `istio_requests_total{reporter="source"`
+	success := `response_code!~"5.."`
+	mock := &queryMatchPromAPI{results: []queryMatch{
+		{[]string{inbound, success}, model.Vector{
+			{Metric: model.Metric{"source_workload": "gateway", "source_workload_namespace": "edge"}, Value: 950},
+		}},
+		{[]string{inbound}, model.Vector{
+			{Metric: model.Metric{"source_workload": "gateway", "source_workload_namespace": "edge"}, Value: 1000},
+		}},
+		{[]string{"histogram_quantile(0.99", "istio_request_duration_milliseconds_bucket"}, model.Vector{
+			{Metric: model.Metric{"source_workload": "gateway", "source_workload_namespace": "edge"}, Value: 120},
+		}},
+		{[]string{outbound}, model.Vector{
+			{Metric: model.Metric{"destination_workload": "postgres", "destination_workload_namespace": "db"}, Value: 400},
+		}},
+		{[]string{`istio_tcp_connections_opened_total{reporter="source"`}, model.Vector{{Value: 7}}},
+	}}
+
+	collector := &ExposureCollector{promAPI: mock}
+	tm, err := collector.CollectTrafficMap(context.Background(), "billing", "worker")
+	require.NoError(t, err)
+
+	assert.Equal(t, MeshIstio, tm.Mesh)
+	require.Len(t, tm.Inbound, 1)
+	assert.Equal(t, "gateway", tm.Inbound[0].Deployment)
+	assert.Equal(t, "edge", tm.Inbound[0].Namespace)
+	assert.InDelta(t, 0.95, tm.Inbound[0].SuccessRate, 0.001)
+	assert.InDelta(t, 120, tm.Inbound[0].LatencyP99, 0.1)
+	assert.Equal(t, float64(-1), tm.Inbound[0].LatencyP50)
+
+	require.Len(t, tm.Outbound, 1)
+	assert.Equal(t, "postgres", tm.Outbound[0].Deployment)
+	assert.InDelta(t, 1.0, tm.Outbound[0].SuccessRate, 0.001)
+	assert.Equal(t, int64(7), tm.TCPOut)
+}
+
+func TestCollectTrafficMap_MeshDetection(t *testing.T) {
+	// Linkerd data wins when both meshes report traffic.
+	mock := &queryMatchPromAPI{results: []queryMatch{
+		{[]string{"response_total"}, model.Vector{
+			{Metric: model.Metric{"deployment": "api", "namespace": "ns"}, Value: 10},
+		}},
+		{[]string{"istio_requests_total"}, model.Vector{
+			{Metric: model.Metric{"source_workload": "api", "source_workload_namespace": "ns"}, Value: 10},
+		}},
+	}}
+	tm, err := (&ExposureCollector{promAPI: mock}).CollectTrafficMap(context.Background(), "ns", "worker")
+	require.NoError(t, err)
+	assert.Equal(t, MeshLinkerd, tm.Mesh)
+
+	// No traffic on either mesh: the empty Linkerd map is returned.
+	tm, err = (&ExposureCollector{promAPI: &queryMatchPromAPI{}}).CollectTrafficMap(context.Background(), "ns", "worker")
+	require.NoError(t, err)
+	assert.Equal(t, MeshLinkerd, tm.Mesh)
+	assert.Empty(t, tm.Inbound)
+	assert.Empty(t, tm.Outbound)
+}
+
+func TestIstioQueries_EscapeLabels(t *testing.T) {
+	q := istioInboundQuery(`wo"rk`, "billing", true)
+	assert.Contains(t, q, `destination_workload="wo\"rk"`)
+	assert.Contains(t, q, `response_code!~"5.."`)
+	assert.NotContains(t, istioOutboundQuery("worker", "billing", false), "response_code")
+	assert.Contains(t, istioInboundLatencyQuery("worker", "billing", "0.5"), "histogram_quantile(0.5, ")
+}
@@ -0,0 +1,115 @@
+package exposure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// maxTopologyEdges caps the namespace service graph to keep output readable.
+const maxTopologyEdges = 200
+
+func linkerdNamespaceOutboundQuery(namespace string, successOnly bool) string {
+	return `sum by(deployment, namespace, dst_deployment, dst_namespace)(increase(response_total{direction="outbound", namespace=` +
+		escapePromLabel(namespace) + successMatcher(successOnly) + `}[1h]))`
+}
+
+func linkerdNamespaceInboundQuery(namespace string, successOnly bool) string {
+	return `sum by(deployment, namespace, dst_deployment, dst_namespace)(increase(response_total{direction="outbound", dst_namespace=` +
+		escapePromLabel(namespace) + `, namespace!=` + escapePromLabel(namespace) + successMatcher(successOnly) + `}[1h]))`
+}
+
+func successMatcher(successOnly bool) string {
+	if successOnly {
+		return `, classification="success"`
+	}
+	return ""
+}
+
+// CollectNamespaceTopology queries Linkerd proxy metrics for every workload in
+// a namespace and builds the full service graph: edges between workloads in
+// the namespace, edges leaving it, and edges entering it from other namespaces.
+// Workloads in the namespace are marked as focus nodes.
+func (c *ExposureCollector) CollectNamespaceTopology(ctx context.Context, namespace string) (*TrafficGraph, error) {
+	if c.promAPI == nil {
+		return nil, fmt.Errorf("prometheus not configured")
+	}
+
+	outTotal, err := c.queryVector(ctx, linkerdNamespaceOutboundQuery(namespace, false))
+	if err != nil {
+		return nil, fmt.Errorf("outbound total: %w", err)
+	}
+	outSuccess, err := c.queryVector(ctx, linkerdNamespaceOutboundQuery(namespace, true))
+	if err != nil {
+		outSuccess = nil
+	}
+
+	// Inbound from other namespaces is best-effort
+	inTotal, err := c.queryVector(ctx, linkerdNamespaceInboundQuery(namespace, false))
+	if err != nil {
+		inTotal = nil
+	}
+	inSuccess, err := c.queryVector(ctx, linkerdNamespaceInboundQuery(namespace, true))
+	if err != nil {
+		inSuccess = nil
+	}
+
+	edges := buildPairEdges(outTotal, outSuccess)
+	edges = append(edges, buildPairEdges(inTotal, inSuccess)...)
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].RPS != edges[j].RPS {
+			return edges[i].RPS > edges[j].RPS
+		}
+		return edges[i].From+edges[i].To < edges[j].From+edges[j].To
+	})
+	if len(edges) > maxTopologyEdges {
+		edges = edges[:maxTopologyEdges]
+	}
+
+	g := &TrafficGraph{Edges: edges, Window: trafficQueryWindow}
+	g.Nodes = collectNodes(nil, edges)
+	for _, n := range g.Nodes {
+		if strings.HasPrefix(n, namespace+"/") {
+			g.Focus = append(g.Focus, n)
+		}
+	}
+	return g, nil
+}
+
+// buildPairEdges converts source→destination vectors into GraphEdges.
+// Samples without a destination deployment (e.g. egress to external hosts)
+// are skipped.
+func buildPairEdges(total, success model.Vector) []GraphEdge {
+	successMap := make(map[string]float64, len(success))
+	for _, s := range success {
+		successMap[pairKey(s.Metric)] = float64(s.Value)
+	}
+
+	edges := make([]GraphEdge, 0, len(total))
+	for _, sample := range total {
+		t := float64(sample.Value)
+		if t <= 0 || sample.Metric["dst_deployment"] == "" || sample.Metric["deployment"] == "" {
+			continue
+		}
+		edge := GraphEdge{
+			From:        graphNodeID(string(sample.Metric["namespace"]), string(sample.Metric["deployment"])),
+			To:          graphNodeID(string(sample.Metric["dst_namespace"]), string(sample.Metric["dst_deployment"])),
+			RPS:         t / trafficQueryWindow.Seconds(),
+			SuccessRate: -1,
+		}
+		if s, ok := successMap[pairKey(sample.Metric)]; ok {
+			edge.SuccessRate = s / t
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+func pairKey(m model.Metric) string {
+	return string(m["namespace"]) + "/" + string(m["deployment"]) + "->" +
+		string(m["dst_namespace"]) + "/" + string(m["dst_deployment"])
+}
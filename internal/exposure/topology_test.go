@@ -0,0 +1,76 @@
+package exposure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectNamespaceTopology(t *testing.T) {
+	ctx := context.Background()
+	mock := &mockPromAPI{
+		results: []model.Value{
+			// Query 1: outbound total from namespace
+			model.Vector{
+				{Metric: model.Metric{"deployment": "web", "namespace": "shop", "dst_deployment": "api", "dst_namespace": "shop"}, Value: 36000},
+				{Metric: model.Metric{"deployment": "api", "namespace": "shop", "dst_deployment": "db", "dst_namespace": "data"}, Value: 7200},
+				// External egress has no dst_deployment — skipped
+				{Metric: model.Metric{"deployment": "api", "namespace": "shop", "dst_namespace": ""}, Value: 100},
+			},
+			// Query 2: outbound success
+			model.Vector{
+				{Metric: model.Metric{"deployment": "web", "namespace": "shop", "dst_deployment": "api", "dst_namespace": "shop"}, Value: 35964},
+				{Metric: model.Metric{"deployment": "api", "namespace": "shop", "dst_deployment": "db", "dst_namespace": "data"}, Value: 6480},
+			},
+			// Query 3: inbound total from other namespaces
+			model.Vector{
+				{Metric: model.Metric{"deployment": "gateway", "namespace": "edge", "dst_deployment": "web", "dst_namespace": "shop"}, Value: 3600},
+			},
+			// Query 4: inbound success (none reported → unknown rate)
+			model.Vector{},
+		},
+	}
+
+	collector := &ExposureCollector{promAPI: mock}
+	g, err := collector.CollectNamespaceTopology(ctx, "shop")
+	require.NoError(t, err)
+
+	require.Len(t, g.Edges, 3)
+	// Sorted by RPS descending
+	assert.Equal(t, GraphEdge{From: "shop/web", To: "shop/api", RPS: 10, SuccessRate: 0.999}, g.Edges[0])
+	assert.Equal(t, "shop/api", g.Edges[1].From)
+	assert.Equal(t, "data/db", g.Edges[1].To)
+	assert.InDelta(t, 0.9, g.Edges[1].SuccessRate, 0.001)
+	assert.True(t, g.Edges[1].Critical())
+	assert.Equal(t, "edge/gateway", g.Edges[2].From)
+	assert.Equal(t, float64(-1), g.Edges[2].SuccessRate)
+
+	assert.Equal(t, []string{"data/db", "edge/gateway", "shop/api", "shop/web"}, g.Nodes)
+	assert.Equal(t, []string{"shop/api", "shop/web"}, g.Focus)
+	assert.Equal(t, trafficQueryWindow, g.Window)
+}
+
+func TestCollectNamespaceTopology_OutboundError(t *testing.T) {
+	mock := &mockPromAPI{errs: []error{errors.New("boom")}}
+	collector := &ExposureCollector{promAPI: mock}
+	_, err := collector.CollectNamespaceTopology(context.Background(), "shop")
+	assert.Error(t, err)
+}
+
+func TestCollectNamespaceTopology_NoPrometheus(t *testing.T) {
+	collector := &ExposureCollector{}
+	_, err := collector.CollectNamespaceTopology(context.Background(), "shop")
+	assert.Error(t, err)
+}
+
+func TestNamespaceTopologyQueries_Escaped(t *testing.T) {
+	q := linkerdNamespaceInboundQuery(`sh"op`, true)
+	assert.Contains(t, q, `dst_namespace="sh\"op"`)
+	assert.Contains(t, q, `namespace!="sh\"op"`)
+	assert.Contains(t, q, `classification="success"`)
+	assert.NotContains(t, linkerdNamespaceOutboundQuery("shop", false), "classification")
+}
@@ -37,12 +37,15 @@ type PortMapping struct {
 	Port       int32
 }
 
-// IngressRoute represents an Ingress rule routing to a service.
+// IngressRoute represents an Ingress rule or Gateway API route
+// (HTTPRoute, GRPCRoute, TCPRoute) routing to a service.
 type IngressRoute struct {
 	Name      string
-	ClassName string
+	Kind      string // Ingress, HTTPRoute, GRPCRoute, TCPRoute
+	ClassName string // IngressClass or the parent Gateways' GatewayClass
 	Hosts     []string
 	Paths     []string
+	Gateways  []string // parent Gateways as namespace/name (routes only)
 	TLS       bool
 }
 
@@ -62,7 +65,7 @@ type NetPolSource struct {
 }
 
 // TrafficEdge represents a measured traffic connection between two workloads,
-// as reported by Linkerd proxy metrics from Prometheus.
+// as reported by service mesh (Linkerd or Istio) proxy metrics from Prometheus.
 type TrafficEdge struct {
 	Deployment  string  // remote deployment name
 	Namespace   string  // remote namespace
@@ -73,8 +76,9 @@ type TrafficEdge struct {
 	LatencyP99  float64 // milliseconds, -1 if unknown
 }
 
-// TrafficMap holds bidirectional Linkerd traffic data for a workload.
+// TrafficMap holds bidirectional mesh traffic data for a workload.
 type TrafficMap struct {
+	Mesh     Mesh          // mesh whose proxy metrics were used
 	Inbound  []TrafficEdge // who sends traffic TO this workload
 	Outbound []TrafficEdge // who this workload sends traffic TO
 	TCPIn    int64         // total inbound TCP connections (1h window)
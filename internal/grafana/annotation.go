@@ -0,0 +1,114 @@
+// Package grafana creates Grafana annotations for kubenow events.
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is used when Client.Timeout is unset.
+const DefaultTimeout = 10 * time.Second
+
+// Client posts annotations to the Grafana HTTP API.
+type Client struct {
+	URL     string        // e.g. https://grafana.example.com
+	Token   string        // service account token or API key
+	Timeout time.Duration // per request timeout
+}
+
+// Annotation is a single Grafana annotation.
+// An empty DashboardUID creates an organization-wide annotation.
+type Annotation struct {
+	DashboardUID string
+	Time         time.Time
+	Tags         []string
+	Text         string
+}
+
+type annotationRequest struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time"`
+	Tags         []string `json:"tags,omitempty"`
+	Text         string   `json:"text"`
+}
+
+// Create posts a single annotation to /api/annotations.
+func (c *Client) Create(ctx context.Context, a *Annotation) error {
+	if c.URL == "" {
+		return fmt.Errorf("grafana URL is empty")
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	payload, err := json.Marshal(annotationRequest{
+		DashboardUID: a.DashboardUID,
+		Time:         a.Time.UnixMilli(),
+		Tags:         a.Tags,
+		Text:         a.Text,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal annotation: %w", err)
+	}
+
+	url := strings.TrimRight(c.URL, "/") + "/api/annotations"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("http do: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Truncate body to prevent leaking sensitive data in error messages
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, 500))
+		if readErr != nil {
+			body = nil
+		}
+		return fmt.Errorf("grafana annotation: %d %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode), string(body))
+	}
+	return nil
+}
+
+// CreateForDashboards posts one annotation per dashboard UID, or a single
+// organization-wide annotation when no dashboards are given. All dashboards
+// are attempted; the first error is returned along with the success count.
+func (c *Client) CreateForDashboards(ctx context.Context, dashboardUIDs []string, a Annotation) (int, error) {
+	if len(dashboardUIDs) == 0 {
+		dashboardUIDs = []string{""}
+	}
+
+	created := 0
+	var firstErr error
+	for _, uid := range dashboardUIDs {
+		a.DashboardUID = uid
+		if err := c.Create(ctx, &a); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		created++
+	}
+	return created, firstErr
+}
@@ -0,0 +1,94 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate_PostsAnnotation(t *testing.T) {
+	var got annotationRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/annotations", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := &Client{URL: srv.URL + "/", Token: "secret-token"}
+	err := c.Create(context.Background(), &Annotation{
+		DashboardUID: "abc",
+		Time:         ts,
+		Tags:         []string{"kubenow"},
+		Text:         "hello",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer secret-token", auth)
+	assert.Equal(t, "abc", got.DashboardUID)
+	assert.Equal(t, ts.UnixMilli(), got.Time)
+	assert.Equal(t, []string{"kubenow"}, got.Tags)
+	assert.Equal(t, "hello", got.Text)
+}
+
+func TestCreate_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"invalid API key"}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{URL: srv.URL}
+	err := c.Create(context.Background(), &Annotation{Time: time.Now(), Text: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Contains(t, err.Error(), "invalid API key")
+}
+
+func TestCreate_EmptyURL(t *testing.T) {
+	c := &Client{}
+	err := c.Create(context.Background(), &Annotation{Text: "x"})
+	require.Error(t, err)
+}
+
+func TestCreateForDashboards(t *testing.T) {
+	var mu sync.Mutex
+	var uids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req annotationRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		uids = append(uids, req.DashboardUID)
+		mu.Unlock()
+		if req.DashboardUID == "bad" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := &Client{URL: srv.URL}
+
+	n, err := c.CreateForDashboards(context.Background(), []string{"a", "bad", "b"}, Annotation{Text: "x"})
+	assert.Error(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"a", "bad", "b"}, uids)
+
+	uids = nil
+	n, err = c.CreateForDashboards(context.Background(), nil, Annotation{Text: "x"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{""}, uids)
+}
@@ -0,0 +1,130 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubURL is the GitHub REST API; GitHub Enterprise Server uses
+// https://<host>/api/v3.
+const DefaultGitHubURL = "https://api.github.com"
+
+// githubPages caps how many pages of open kubenow issues are searched for
+// an existing one.
+const githubPages = 10
+
+// GitHub files issues in one repository. Existing issues are found among
+// the open issues labeled kubenow by the key marker in their body.
+type GitHub struct {
+	Repo    string // owner/name
+	Token   string
+	BaseURL string        // default: DefaultGitHubURL
+	Timeout time.Duration // per request timeout
+
+	open map[string]githubIssue // open kubenow issues by key, loaded once
+}
+
+type githubIssue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Body    string `json:"body"`
+}
+
+type githubIssueRequest struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Validate checks the repository and token.
+func (g *GitHub) Validate() error {
+	owner, name, ok := strings.Cut(g.Repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid GitHub repository %q (use owner/name)", g.Repo)
+	}
+	if g.Token == "" {
+		return fmt.Errorf("a GitHub token is required")
+	}
+	return nil
+}
+
+// Upsert creates the issue, or updates the title and body of the open
+// issue filed earlier with the same key.
+func (g *GitHub) Upsert(ctx context.Context, issue *Issue) (Result, error) {
+	c := g.client()
+	if g.open == nil {
+		if err := g.loadOpen(ctx, c); err != nil {
+			return Result{}, err
+		}
+	}
+
+	req := githubIssueRequest{Title: issue.Title, Body: issue.markdown()}
+	var got githubIssue
+	if existing, ok := g.open[issue.Key]; ok {
+		endpoint := g.repoURL() + "/issues/" + strconv.Itoa(existing.Number)
+		if err := c.do(ctx, http.MethodPatch, endpoint, req, &got); err != nil {
+			return Result{}, err
+		}
+		return Result{ID: "#" + strconv.Itoa(got.Number), URL: got.HTMLURL}, nil
+	}
+
+	req.Labels = []string{Label}
+	if err := c.do(ctx, http.MethodPost, g.repoURL()+"/issues", req, &got); err != nil {
+		return Result{}, err
+	}
+	got.Body = req.Body
+	g.open[issue.Key] = got
+	return Result{ID: "#" + strconv.Itoa(got.Number), URL: got.HTMLURL, Created: true}, nil
+}
+
+// loadOpen indexes the repository's open kubenow issues by key.
+func (g *GitHub) loadOpen(ctx context.Context, c *client) error {
+	g.open = make(map[string]githubIssue)
+	for page := 1; page <= githubPages; page++ {
+		query := url.Values{"state": {"open"}, "labels": {Label}, "per_page": {"100"}, "page": {strconv.Itoa(page)}}
+		var batch []githubIssue
+		if err := c.do(ctx, http.MethodGet, g.repoURL()+"/issues?"+query.Encode(), nil, &batch); err != nil {
+			return err
+		}
+		for _, is := range batch {
+			if key, ok := markerKey(is.Body); ok {
+				g.open[key] = is
+			}
+		}
+		if len(batch) < 100 {
+			break
+		}
+	}
+	return nil
+}
+
+// markerKey extracts the key from an issue body's marker line.
+func markerKey(body string) (string, bool) {
+	const prefix = "<!-- kubenow-issue-key: "
+	_, rest, ok := strings.Cut(body, prefix)
+	if !ok {
+		return "", false
+	}
+	key, _, ok := strings.Cut(rest, " -->")
+	return key, ok && key != ""
+}
+
+func (g *GitHub) repoURL() string {
+	base := g.BaseURL
+	if base == "" {
+		base = DefaultGitHubURL
+	}
+	return strings.TrimSuffix(base, "/") + "/repos/" + g.Repo
+}
+
+func (g *GitHub) client() *client {
+	return &client{name: "GitHub", timeout: g.Timeout, auth: func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+g.Token)
+		r.Header.Set("Accept", "application/vnd.github+json")
+	}}
+}
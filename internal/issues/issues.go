@@ -0,0 +1,203 @@
+// Package issues files kubenow findings as GitHub or Jira issues. Each
+// issue carries a stable key, so a re-run updates the issue it filed
+// before instead of opening a duplicate.
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// Trackers.
+const (
+	TrackerGitHub = "github"
+	TrackerJira   = "jira"
+)
+
+// DefaultTimeout is used when a tracker's Timeout is unset.
+const DefaultTimeout = 30 * time.Second
+
+// Label marks every issue kubenow files.
+const Label = "kubenow"
+
+// Issue is one finding report. Key identifies it across runs, e.g.
+// "requests-skew/prod/namespace/shop"; Title and the body may change.
+type Issue struct {
+	Key     string
+	Title   string
+	Summary []string   // paragraphs above the table
+	Header  []string   // evidence table columns
+	Rows    [][]string // evidence table rows
+	Footer  []string   // paragraphs below the table
+}
+
+// Result is the outcome of filing one issue.
+type Result struct {
+	ID      string // GitHub issue number or Jira issue key
+	URL     string
+	Created bool // false when an existing issue was updated
+}
+
+// Tracker files issues, updating the open issue with the same key.
+type Tracker interface {
+	Upsert(ctx context.Context, issue *Issue) (Result, error)
+}
+
+// markdown renders the issue body as GitHub-flavored Markdown, ending
+// with a hidden marker that finds the issue again.
+func (i *Issue) markdown() string {
+	var b strings.Builder
+	for _, p := range i.Summary {
+		b.WriteString(p + "\n\n")
+	}
+	if len(i.Header) > 0 {
+		b.WriteString("| " + strings.Join(escapeCells(i.Header, "|", `\|`), " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(i.Header)) + "\n")
+		for _, row := range i.Rows {
+			b.WriteString("| " + strings.Join(escapeCells(row, "|", `\|`), " | ") + " |\n")
+		}
+		b.WriteString("\n")
+	}
+	for _, p := range i.Footer {
+		b.WriteString(p + "\n\n")
+	}
+	b.WriteString(marker(i.Key) + "\n")
+	return b.String()
+}
+
+// wiki renders the issue body in Jira wiki markup.
+func (i *Issue) wiki() string {
+	var b strings.Builder
+	for _, p := range i.Summary {
+		b.WriteString(markdownToWiki(p) + "\n\n")
+	}
+	if len(i.Header) > 0 {
+		b.WriteString("||" + strings.Join(escapeCells(i.Header, "|", "&#124;"), "||") + "||\n")
+		for _, row := range i.Rows {
+			b.WriteString("|" + strings.Join(escapeCells(row, "|", "&#124;"), "|") + "|\n")
+		}
+		b.WriteString("\n")
+	}
+	for _, p := range i.Footer {
+		b.WriteString(markdownToWiki(p) + "\n\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// markdownToWiki converts the inline Markdown kubenow writes (`code`,
+// **bold**) to wiki markup.
+func markdownToWiki(s string) string {
+	s = strings.ReplaceAll(s, "**", "*")
+	var b strings.Builder
+	open := false
+	for _, r := range s {
+		if r == '`' {
+			if open {
+				b.WriteString("}}")
+			} else {
+				b.WriteString("{{")
+			}
+			open = !open
+			continue
+		}
+		b.WriteRune(r)
+	}
+	if open {
+		b.WriteString("}}")
+	}
+	return b.String()
+}
+
+func escapeCells(cells []string, sep, escaped string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.ReplaceAll(c, "\n", " ")
+		if c == "" {
+			c = " "
+		}
+		out[i] = strings.ReplaceAll(c, sep, escaped)
+	}
+	return out
+}
+
+// marker is the hidden line in a GitHub issue body that holds its key.
+func marker(key string) string {
+	return "<!-- kubenow-issue-key: " + key + " -->"
+}
+
+// client is the HTTP plumbing shared by the trackers.
+type client struct {
+	name    string // tracker name, for errors
+	timeout time.Duration
+	auth    func(*http.Request)
+}
+
+// do sends a JSON request and decodes a JSON response into out (nil to
+// discard it). Errors never include credentials; the body of an error
+// response is truncated.
+func (c *client) do(ctx context.Context, method, endpoint string, in, out any) error {
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal %s request: %w", c.name, err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.auth(req)
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", c.name, req.URL.Host, util.UnwrapURLError(err))
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, readErr := io.ReadAll(io.LimitReader(resp.Body, 500))
+		if readErr != nil {
+			msg = nil
+		}
+		return &apiError{StatusCode: resp.StatusCode, msg: fmt.Sprintf("%s %s %s: %d %s: %s",
+			c.name, method, req.URL.Path, resp.StatusCode, http.StatusText(resp.StatusCode), string(msg))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s %s: invalid response: %w", c.name, method, req.URL.Path, err)
+	}
+	return nil
+}
+
+// apiError is a non-2xx response.
+type apiError struct {
+	StatusCode int
+	msg        string
+}
+
+func (e *apiError) Error() string { return e.msg }
@@ -0,0 +1,210 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIssue() *Issue {
+	return &Issue{
+		Key:     "requests-skew/prod/namespace/shop",
+		Title:   "kubenow: 1 over-provisioned workload(s) in namespace shop (prod)",
+		Summary: []string{"**1 workload(s)** in namespace `shop` request too much."},
+		Header:  []string{"Workload", "CPU request"},
+		Rows:    [][]string{{"shop/api", "2|4"}},
+		Footer:  []string{"Re-runs update this issue."},
+	}
+}
+
+func TestIssue_Markdown(t *testing.T) {
+	body := testIssue().markdown()
+
+	assert.Contains(t, body, "**1 workload(s)** in namespace `shop`")
+	assert.Contains(t, body, "| Workload | CPU request |\n| --- | --- |\n| shop/api | 2\\|4 |\n")
+	assert.True(t, strings.HasSuffix(body, "<!-- kubenow-issue-key: requests-skew/prod/namespace/shop -->\n"))
+
+	key, ok := markerKey(body)
+	require.True(t, ok)
+	assert.Equal(t, "requests-skew/prod/namespace/shop", key)
+}
+
+func TestIssue_Wiki(t *testing.T) {
+	body := testIssue().wiki()
+
+	assert.Contains(t, body, "*1 workload(s)* in namespace {{shop}}")
+	assert.Contains(t, body, "||Workload||CPU request||\n|shop/api|2&#124;4|\n")
+	assert.NotContains(t, body, "kubenow-issue-key")
+}
+
+func TestMarkerKey_Missing(t *testing.T) {
+	_, ok := markerKey("no marker here")
+	assert.False(t, ok)
+}
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Auth   string
+	Body   map[string]any
+}
+
+// trackerServer records requests and answers them with respond.
+func trackerServer(t *testing.T, respond func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var reqs []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if r.Body != nil && r.ContentLength != 0 {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+		mu.Lock()
+		reqs = append(reqs, recordedRequest{Method: r.Method, Path: r.URL.Path, Auth: r.Header.Get("Authorization"), Body: body})
+		mu.Unlock()
+		respond(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return reqs
+	}
+}
+
+func TestGitHub_CreateThenUpdate(t *testing.T) {
+	var open []map[string]any
+	srv, requests := trackerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "open", r.URL.Query().Get("state"))
+			assert.Equal(t, Label, r.URL.Query().Get("labels"))
+			require.NoError(t, json.NewEncoder(w).Encode(open))
+		case http.MethodPost:
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"number": 7, "html_url": "https://github.example/o/r/issues/7"}))
+		case http.MethodPatch:
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"number": 7, "html_url": "https://github.example/o/r/issues/7"}))
+		}
+	})
+
+	gh := &GitHub{Repo: "o/r", Token: "tok", BaseURL: srv.URL}
+	require.NoError(t, gh.Validate())
+
+	res, err := gh.Upsert(context.Background(), testIssue())
+	require.NoError(t, err)
+	assert.Equal(t, Result{ID: "#7", URL: "https://github.example/o/r/issues/7", Created: true}, res)
+
+	// A later run finds the issue by its marker and updates it
+	open = []map[string]any{
+		{"number": 3, "html_url": "x", "body": "unrelated"},
+		{"number": 7, "html_url": "y", "body": testIssue().markdown()},
+	}
+	rerun := &GitHub{Repo: "o/r", Token: "tok", BaseURL: srv.URL}
+	res, err = rerun.Upsert(context.Background(), testIssue())
+	require.NoError(t, err)
+	assert.False(t, res.Created)
+	assert.Equal(t, "#7", res.ID)
+
+	reqs := requests()
+	require.Len(t, reqs, 4)
+	assert.Equal(t, "/repos/o/r/issues", reqs[0].Path)
+	assert.Equal(t, "Bearer tok", reqs[0].Auth)
+	assert.Equal(t, http.MethodPost, reqs[1].Method)
+	assert.Equal(t, []any{Label}, reqs[1].Body["labels"])
+	assert.Contains(t, reqs[1].Body["body"], marker(testIssue().Key))
+	assert.Equal(t, http.MethodPatch, reqs[3].Method)
+	assert.Equal(t, "/repos/o/r/issues/7", reqs[3].Path)
+	assert.NotContains(t, reqs[3].Body, "labels")
+}
+
+func TestGitHub_Validate(t *testing.T) {
+	assert.Error(t, (&GitHub{Repo: "nope", Token: "t"}).Validate())
+	assert.Error(t, (&GitHub{Repo: "o/r/x", Token: "t"}).Validate())
+	assert.Error(t, (&GitHub{Repo: "o/r"}).Validate())
+}
+
+func TestGitHub_ErrorOmitsToken(t *testing.T) {
+	srv, _ := trackerServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "Bad credentials", http.StatusUnauthorized)
+	})
+	gh := &GitHub{Repo: "o/r", Token: "secret-token", BaseURL: srv.URL}
+	_, err := gh.Upsert(context.Background(), testIssue())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+func TestJira_CreateWithSearchFallback(t *testing.T) {
+	srv, requests := trackerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/rest/api/2/search/jql":
+			http.NotFound(w, r)
+		case r.URL.Path == "/rest/api/2/search":
+			assert.Contains(t, r.URL.Query().Get("jql"), jiraKeyLabel(testIssue().Key))
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"issues": []any{}}))
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+			w.WriteHeader(http.StatusCreated)
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"key": "OPS-12"}))
+		}
+	})
+
+	jira := &Jira{BaseURL: srv.URL + "/", Project: "OPS", User: "me@example.com", Token: "tok"}
+	require.NoError(t, jira.Validate())
+	res, err := jira.Upsert(context.Background(), testIssue())
+	require.NoError(t, err)
+	assert.Equal(t, Result{ID: "OPS-12", URL: srv.URL + "/browse/OPS-12", Created: true}, res)
+
+	reqs := requests()
+	require.Len(t, reqs, 3)
+	assert.True(t, strings.HasPrefix(reqs[0].Auth, "Basic "))
+	fields := reqs[2].Body["fields"].(map[string]any)
+	assert.Equal(t, map[string]any{"key": "OPS"}, fields["project"])
+	assert.Equal(t, map[string]any{"name": DefaultJiraIssueType}, fields["issuetype"])
+	assert.Equal(t, []any{Label, jiraKeyLabel(testIssue().Key)}, fields["labels"])
+	assert.Contains(t, fields["description"], "||Workload||CPU request||")
+}
+
+func TestJira_UpdateExisting(t *testing.T) {
+	srv, requests := trackerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"issues": []any{map[string]any{"key": "OPS-3"}}}))
+		case http.MethodPut:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	jira := &Jira{BaseURL: srv.URL, Project: "OPS", Token: "pat"}
+	res, err := jira.Upsert(context.Background(), testIssue())
+	require.NoError(t, err)
+	assert.Equal(t, Result{ID: "OPS-3", URL: srv.URL + "/browse/OPS-3"}, res)
+
+	reqs := requests()
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "Bearer pat", reqs[0].Auth)
+	assert.Equal(t, "/rest/api/2/search/jql", reqs[0].Path)
+	assert.Equal(t, "/rest/api/2/issue/OPS-3", reqs[1].Path)
+	fields := reqs[1].Body["fields"].(map[string]any)
+	assert.Equal(t, testIssue().Title, fields["summary"])
+	assert.NotContains(t, fields, "labels")
+}
+
+func TestJira_Validate(t *testing.T) {
+	assert.Error(t, (&Jira{BaseURL: "example.atlassian.net", Project: "OPS", Token: "t"}).Validate())
+	assert.Error(t, (&Jira{BaseURL: "https://example.atlassian.net", Token: "t"}).Validate())
+	assert.Error(t, (&Jira{BaseURL: "https://example.atlassian.net", Project: "OPS"}).Validate())
+}
+
+func TestJiraKeyLabel(t *testing.T) {
+	label := jiraKeyLabel("requests-skew/prod/team/payments team")
+	assert.Len(t, label, len("kubenow-")+16)
+	assert.NotContains(t, label, " ")
+	assert.Equal(t, label, jiraKeyLabel("requests-skew/prod/team/payments team"))
+}
@@ -0,0 +1,130 @@
+package issues
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultJiraIssueType is used when Jira.IssueType is unset.
+const DefaultJiraIssueType = "Task"
+
+// Jira files issues in one project through the REST API v2. Existing
+// issues are found by a label derived from the key, among issues not
+// done.
+type Jira struct {
+	BaseURL   string // e.g. https://example.atlassian.net
+	Project   string // project key
+	IssueType string // default: DefaultJiraIssueType
+	User      string // account email for Jira Cloud API tokens; empty sends Token as a bearer token (Data Center PAT)
+	Token     string
+	Timeout   time.Duration // per request timeout
+}
+
+type jiraFields struct {
+	Project     *jiraKey  `json:"project,omitempty"`
+	IssueType   *jiraName `json:"issuetype,omitempty"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Labels      []string  `json:"labels,omitempty"`
+}
+
+type jiraKey struct {
+	Key string `json:"key"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+type jiraSearch struct {
+	Issues []jiraKey `json:"issues"`
+}
+
+// Validate checks the URL, project, and token.
+func (j *Jira) Validate() error {
+	u, err := url.Parse(j.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid Jira URL %q", j.BaseURL)
+	}
+	if j.Project == "" {
+		return fmt.Errorf("a Jira project key is required")
+	}
+	if j.Token == "" {
+		return fmt.Errorf("a Jira API token is required")
+	}
+	return nil
+}
+
+// Upsert creates the issue, or updates the summary and description of the
+// issue filed earlier with the same key that is not done yet.
+func (j *Jira) Upsert(ctx context.Context, issue *Issue) (Result, error) {
+	c := j.client()
+	keyLabel := jiraKeyLabel(issue.Key)
+
+	jql := fmt.Sprintf(`project = "%s" AND labels = "%s" AND statusCategory != Done ORDER BY created DESC`, j.Project, keyLabel)
+	query := url.Values{"jql": {jql}, "fields": {"key"}, "maxResults": {"1"}}
+	// Jira Cloud replaced /search with /search/jql; Data Center only has /search
+	var found jiraSearch
+	err := c.do(ctx, http.MethodGet, j.apiURL()+"/search/jql?"+query.Encode(), nil, &found)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		err = c.do(ctx, http.MethodGet, j.apiURL()+"/search?"+query.Encode(), nil, &found)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	fields := jiraFields{Summary: issue.Title, Description: issue.wiki()}
+	if len(found.Issues) > 0 {
+		key := found.Issues[0].Key
+		if err := c.do(ctx, http.MethodPut, j.apiURL()+"/issue/"+url.PathEscape(key), map[string]any{"fields": fields}, nil); err != nil {
+			return Result{}, err
+		}
+		return Result{ID: key, URL: j.browseURL(key)}, nil
+	}
+
+	issueType := j.IssueType
+	if issueType == "" {
+		issueType = DefaultJiraIssueType
+	}
+	fields.Project = &jiraKey{Key: j.Project}
+	fields.IssueType = &jiraName{Name: issueType}
+	fields.Labels = []string{Label, keyLabel}
+	var created jiraKey
+	if err := c.do(ctx, http.MethodPost, j.apiURL()+"/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return Result{}, err
+	}
+	return Result{ID: created.Key, URL: j.browseURL(created.Key), Created: true}, nil
+}
+
+// jiraKeyLabel turns an issue key into a Jira label: labels cannot hold
+// spaces, and a hash keeps them short and unique.
+func jiraKeyLabel(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "kubenow-" + hex.EncodeToString(sum[:8])
+}
+
+func (j *Jira) apiURL() string {
+	return strings.TrimSuffix(j.BaseURL, "/") + "/rest/api/2"
+}
+
+func (j *Jira) browseURL(key string) string {
+	return strings.TrimSuffix(j.BaseURL, "/") + "/browse/" + key
+}
+
+func (j *Jira) client() *client {
+	return &client{name: "Jira", timeout: j.Timeout, auth: func(r *http.Request) {
+		if j.User != "" {
+			r.SetBasicAuth(j.User, j.Token)
+			return
+		}
+		r.Header.Set("Authorization", "Bearer "+j.Token)
+	}}
+}
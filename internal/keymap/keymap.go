@@ -0,0 +1,166 @@
+// Package keymap maps TUI key presses to actions, with defaults that users
+// can remap from the config file.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action names a TUI command, e.g. "search" or "apply".
+type Action string
+
+// Help is the action that toggles the key binding overlay in every TUI.
+const Help Action = "help"
+
+// ForceQuit is always bound to ctrl+c and cannot be remapped, so a
+// misconfigured key map can never trap the user in a TUI.
+const ForceQuit = "ctrl+c"
+
+// Binding binds keys to an action. Keys use bubbletea's key names
+// ("a", "G", "ctrl+d", "pgdown", "up", "esc"); "space" stands for " ".
+type Binding struct {
+	Action      Action
+	Keys        []string
+	Description string
+}
+
+// Map resolves key presses to actions.
+type Map struct {
+	bindings []Binding
+	byKey    map[string]Action
+}
+
+// New builds a key map from a TUI's default bindings and user overrides
+// keyed by action name. An override replaces all keys of its action.
+// Unknown actions, ctrl+c, and keys bound to two actions are errors.
+func New(defaults []Binding, overrides map[string][]string) (*Map, error) {
+	bindings := make([]Binding, len(defaults))
+	index := make(map[Action]int, len(defaults))
+	for i, b := range defaults {
+		bindings[i] = Binding{Action: b.Action, Keys: append([]string(nil), b.Keys...), Description: b.Description}
+		index[b.Action] = i
+	}
+
+	actions := make([]string, 0, len(overrides))
+	for action := range overrides {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		i, ok := index[Action(action)]
+		if !ok {
+			return nil, fmt.Errorf("unknown action %q (valid: %s)", action, strings.Join(actionNames(defaults), ", "))
+		}
+		keys := make([]string, 0, len(overrides[action]))
+		for _, k := range overrides[action] {
+			k = normalizeKey(k)
+			if k == "" {
+				continue
+			}
+			if k == ForceQuit {
+				return nil, fmt.Errorf("action %q: %s is reserved for quitting", action, ForceQuit)
+			}
+			keys = append(keys, k)
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("action %q: no keys", action)
+		}
+		bindings[i].Keys = keys
+	}
+
+	byKey := make(map[string]Action)
+	for _, b := range bindings {
+		for _, k := range b.Keys {
+			if other, ok := byKey[k]; ok && other != b.Action {
+				return nil, fmt.Errorf("key %q is bound to both %q and %q", DisplayKey(k), other, b.Action)
+			}
+			byKey[k] = b.Action
+		}
+	}
+	return &Map{bindings: bindings, byKey: byKey}, nil
+}
+
+// MustDefault builds a key map from defaults alone. It panics on invalid
+// defaults, which is a programming error.
+func MustDefault(defaults []Binding) *Map {
+	m, err := New(defaults, nil)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Action returns the action bound to a key press (tea.KeyMsg.String()),
+// or "" if the key is unbound.
+func (m *Map) Action(key string) Action {
+	return m.byKey[key]
+}
+
+// Keys returns the keys bound to an action.
+func (m *Map) Keys(action Action) []string {
+	for _, b := range m.bindings {
+		if b.Action == action {
+			return b.Keys
+		}
+	}
+	return nil
+}
+
+// Label returns the first key of an action for inline hints, e.g. "/".
+func (m *Map) Label(action Action) string {
+	keys := m.Keys(action)
+	if len(keys) == 0 {
+		return ""
+	}
+	return DisplayKey(keys[0])
+}
+
+// Bindings returns the active bindings in their default order.
+func (m *Map) Bindings() []Binding {
+	return m.bindings
+}
+
+// HelpLines renders one line per binding for a help overlay.
+func (m *Map) HelpLines() []string {
+	width := len(ForceQuit)
+	keys := make([]string, len(m.bindings))
+	for i, b := range m.bindings {
+		display := make([]string, len(b.Keys))
+		for j, k := range b.Keys {
+			display[j] = DisplayKey(k)
+		}
+		keys[i] = strings.Join(display, ", ")
+		width = max(width, len(keys[i]))
+	}
+	lines := make([]string, len(m.bindings))
+	for i, b := range m.bindings {
+		lines[i] = fmt.Sprintf("%-*s  %s", width, keys[i], b.Description)
+	}
+	lines = append(lines, fmt.Sprintf("%-*s  %s", width, ForceQuit, "quit immediately (not remappable)"))
+	return lines
+}
+
+// DisplayKey renders a key name for the screen.
+func DisplayKey(key string) string {
+	if key == " " {
+		return "space"
+	}
+	return key
+}
+
+func normalizeKey(key string) string {
+	if strings.EqualFold(strings.TrimSpace(key), "space") || key == " " {
+		return " "
+	}
+	return strings.TrimSpace(key)
+}
+
+func actionNames(defaults []Binding) []string {
+	names := make([]string, len(defaults))
+	for i, b := range defaults {
+		names[i] = string(b.Action)
+	}
+	return names
+}
@@ -0,0 +1,72 @@
+package keymap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDefaults = []Binding{
+	{Action: "quit", Keys: []string{"q"}, Description: "quit"},
+	{Action: "search", Keys: []string{"/"}, Description: "search"},
+	{Action: "pause", Keys: []string{"p", " "}, Description: "pause"},
+	{Action: Help, Keys: []string{"?"}, Description: "help"},
+}
+
+func TestNew_Defaults(t *testing.T) {
+	m := MustDefault(testDefaults)
+	assert.Equal(t, Action("search"), m.Action("/"))
+	assert.Equal(t, Action("pause"), m.Action(" "))
+	assert.Equal(t, Action(""), m.Action("x"))
+	assert.Equal(t, "/", m.Label("search"))
+}
+
+func TestNew_Overrides(t *testing.T) {
+	m, err := New(testDefaults, map[string][]string{
+		"search": {"f"},
+		"pause":  {"space"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Action("search"), m.Action("f"))
+	assert.Equal(t, Action(""), m.Action("/"), "an override replaces the default keys")
+	assert.Equal(t, Action("pause"), m.Action(" "))
+	assert.Equal(t, Action(""), m.Action("p"))
+	assert.Equal(t, "space", m.Label("pause"))
+	assert.Equal(t, "f", m.Label("search"))
+
+	// Freeing a default key lets another action take it.
+	m, err = New(testDefaults, map[string][]string{"search": {"f"}, "quit": {"/"}})
+	require.NoError(t, err)
+	assert.Equal(t, Action("quit"), m.Action("/"))
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		want      string
+	}{
+		{"unknown action", map[string][]string{"serch": {"f"}}, `unknown action "serch"`},
+		{"conflict", map[string][]string{"search": {"q"}}, `key "q" is bound to both`},
+		{"ctrl+c reserved", map[string][]string{"quit": {"ctrl+c"}}, "reserved"},
+		{"no keys", map[string][]string{"quit": {""}}, "no keys"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(testDefaults, tt.overrides)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestHelpLines(t *testing.T) {
+	m, err := New(testDefaults, map[string][]string{"search": {"f", "F"}})
+	require.NoError(t, err)
+	lines := m.HelpLines()
+	require.Len(t, lines, len(testDefaults)+1)
+	assert.Equal(t, "f, F      search", lines[1])
+	assert.Equal(t, "p, space  pause", lines[2])
+	assert.Equal(t, "ctrl+c    quit immediately (not remappable)", lines[4])
+}
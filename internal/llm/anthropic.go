@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// anthropicVersion pins the Messages API version.
+	anthropicVersion = "2023-06-01"
+	// DefaultAnthropicMaxTokens is sent when Client.MaxTokens is unset,
+	// because the Messages API requires max_tokens.
+	DefaultAnthropicMaxTokens = 4096
+)
+
+// anthropic speaks the Anthropic Messages API.
+type anthropic struct{}
+
+type anthropicRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	Messages  []chatMessage `json:"messages"`
+	Stream    bool          `json:"stream,omitempty"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *anthropicError `json:"error,omitempty"`
+}
+
+// anthropicEvent is one SSE payload of a streaming message.
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *anthropicError `json:"error,omitempty"`
+}
+
+func (anthropic) name() string            { return ProviderAnthropic }
+func (anthropic) defaultEndpoint() string { return "https://api.anthropic.com/v1" }
+func (anthropic) keyEnv() []string        { return []string{"ANTHROPIC_API_KEY"} }
+
+func (anthropic) request(c *Client, prompt string, stream bool) (string, any) {
+	maxTokens := c.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultAnthropicMaxTokens
+	}
+	return strings.TrimRight(c.Endpoint, "/") + "/messages", anthropicRequest{
+		Model:     c.Model,
+		MaxTokens: maxTokens,
+		Messages:  []chatMessage{{Role: "user", Content: prompt}},
+		Stream:    stream,
+	}
+}
+
+func (anthropic) authorize(req *http.Request, apiKey string) {
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if apiKey != "" {
+		req.Header.Set("x-api-key", apiKey)
+	}
+}
+
+func (anthropic) decode(body []byte) (string, error) {
+	var r anthropicResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", fmt.Errorf("decode response: %w (raw: %s)", err, string(body))
+	}
+	if r.Error != nil {
+		return "", fmt.Errorf("llm error: %s: %s", r.Error.Type, r.Error.Message)
+	}
+	var text strings.Builder
+	for _, block := range r.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no text content in response")
+	}
+	return text.String(), nil
+}
+
+// anthropicUsage is the token usage of a message. Streams report the input
+// tokens in message_start and the output tokens in message_delta.
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+func (anthropic) usage(payload []byte) Usage {
+	var r struct {
+		Usage   *anthropicUsage `json:"usage"`
+		Message struct {
+			Usage *anthropicUsage `json:"usage"`
+		} `json:"message"`
+	}
+	if json.Unmarshal(payload, &r) != nil {
+		return Usage{}
+	}
+	u := r.Usage
+	if u == nil {
+		u = r.Message.Usage
+	}
+	if u == nil {
+		return Usage{}
+	}
+	return Usage{PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens}
+}
+
+func (anthropic) decodeChunk(data string) (string, bool, error) {
+	var ev anthropicEvent
+	if err := json.Unmarshal([]byte(data), &ev); err != nil {
+		return "", false, fmt.Errorf("decode stream chunk: %w (raw: %s)", err, data)
+	}
+	switch ev.Type {
+	case "content_block_delta":
+		if ev.Delta.Type == "text_delta" {
+			return ev.Delta.Text, false, nil
+		}
+	case "message_stop":
+		return "", true, nil
+	case "error":
+		if ev.Error != nil {
+			return "", false, fmt.Errorf("llm error: %s: %s", ev.Error.Type, ev.Error.Message)
+		}
+		return "", false, fmt.Errorf("llm error: %s", data)
+	}
+	return "", false, nil
+}
@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropic_Complete(t *testing.T) {
+	var got anthropicRequest
+	var header http.Header
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header, path = r.Header.Clone(), r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"hello "},{"type":"text","text":"world"}]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Provider: ProviderAnthropic, Endpoint: srv.URL + "/v1", Model: "claude-test", APIKey: "sk-ant-test-key"}
+	out, err := c.Complete(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", out)
+
+	assert.Equal(t, "/v1/messages", path)
+	assert.Equal(t, "sk-ant-test-key", header.Get("x-api-key"))
+	assert.Equal(t, anthropicVersion, header.Get("anthropic-version"))
+	assert.Empty(t, header.Get("Authorization"))
+	assert.Equal(t, "claude-test", got.Model)
+	assert.Equal(t, DefaultAnthropicMaxTokens, got.MaxTokens, "max_tokens is required by the Messages API")
+	require.Len(t, got.Messages, 1)
+	assert.Equal(t, "hi", got.Messages[0].Content)
+}
+
+func TestAnthropic_MaxTokensAndEnvKey(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-from-env")
+	var got anthropicRequest
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("x-api-key")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"ok"}]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Provider: ProviderAnthropic, Endpoint: srv.URL, Model: "m", MaxTokens: 512}
+	_, err := c.Complete(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, 512, got.MaxTokens)
+	assert.Equal(t, "sk-ant-from-env", key)
+}
+
+func TestAnthropic_ErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: too large"}}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Provider: ProviderAnthropic, Endpoint: srv.URL, Model: "m"}
+	_, err := c.Complete(context.Background(), "hi")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+}
+
+func TestAnthropic_Stream(t *testing.T) {
+	var got anthropicRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`{"type":"message_start","message":{"id":"msg_1"}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"{\"summary\""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":": \"ok\"}"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"}}`,
+			`{"type":"message_stop"}`,
+		}
+		for _, ev := range events {
+			_, _ = fmt.Fprintf(w, "event: x\ndata: %s\n\n", ev)
+		}
+	}))
+	defer srv.Close()
+
+	var tokens []string
+	c := Client{Provider: ProviderAnthropic, Endpoint: srv.URL, Model: "m"}
+	out, err := c.Stream(context.Background(), "hi", func(tok string) { tokens = append(tokens, tok) })
+	require.NoError(t, err)
+	assert.True(t, got.Stream)
+	assert.Equal(t, `{"summary": "ok"}`, out)
+	assert.Len(t, tokens, 2)
+}
+
+func TestAnthropic_StreamError(t *testing.T) {
+	body := "data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"par\"}}\n\n" +
+		"data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+	partial, _, err := readSSE(strings.NewReader(body), anthropic{}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Overloaded")
+	assert.Equal(t, "par", partial)
+}
+
+func TestClient_UnknownProvider(t *testing.T) {
+	c := Client{Provider: "cohere", Endpoint: "http://localhost", Model: "m"}
+	_, err := c.Complete(context.Background(), "hi")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown LLM provider")
+	assert.Error(t, ValidateProvider("cohere"))
+	assert.NoError(t, ValidateProvider(""))
+}
+
+func TestDefaultEndpoint(t *testing.T) {
+	assert.Empty(t, DefaultEndpoint(ProviderOpenAI))
+	assert.Equal(t, "https://api.anthropic.com/v1", DefaultEndpoint(ProviderAnthropic))
+	assert.Equal(t, "https://generativelanguage.googleapis.com/v1beta", DefaultEndpoint(ProviderGemini))
+	assert.Empty(t, DefaultEndpoint("unknown"))
+}
+
+func TestOpenAI_MaxTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens int
+		wantField bool
+	}{
+		{"unset omits max_tokens", 0, false},
+		{"set sends max_tokens", 256, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&raw)
+				_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+			}))
+			defer srv.Close()
+
+			c := Client{Endpoint: srv.URL, Model: "m", MaxTokens: tt.maxTokens}
+			_, err := c.Complete(context.Background(), "hi")
+			require.NoError(t, err)
+			_, ok := raw["max_tokens"]
+			assert.Equal(t, tt.wantField, ok)
+		})
+	}
+}
@@ -1,4 +1,5 @@
-// Package llm provides the OpenAI-compatible client used by kubenow.
+// Package llm provides the chat client used by kubenow: OpenAI-compatible
+// endpoints plus the native Anthropic Messages and Google Gemini APIs.
 package llm
 
 import (
@@ -9,22 +10,48 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 )
 
-// Client is a minimal OpenAI-compatible chat client used by kubenow.
+// Client is a minimal chat client used by kubenow.
 type Client struct {
-	Endpoint string        // e.g. https://api.openai.com/v1 or http://localhost:11434/v1
-	Model    string        // e.g. gpt-4.1-mini, mixtral:8x22b
-	APIKey   string        // optional for local; for OpenAI use --api-key or OPENAI_API_KEY
-	Timeout  time.Duration // per request timeout
+	Provider  string        // openai (default, any OpenAI-compatible endpoint), anthropic, or gemini
+	Endpoint  string        // e.g. https://api.openai.com/v1 or http://localhost:11434/v1; empty uses the provider default
+	Model     string        // e.g. gpt-4.1-mini, mixtral:8x22b, claude-sonnet-4-5, gemini-2.5-pro
+	APIKey    string        // optional for local; otherwise --api-key or the provider's environment variable
+	MaxTokens int           // response token cap (0 = provider default; Anthropic requires one)
+	Timeout   time.Duration // per request timeout
+
+	// StrictJSON asks for a JSON response (OpenAI response_format, Gemini
+	// responseMimeType) and re-prompts with the parse error, up to
+	// JSONRetries times, while the answer is not valid JSON.
+	StrictJSON  bool
+	JSONRetries int
+	// OnJSONRetry, when set, is called before each re-prompt.
+	OnJSONRetry func(attempt int, parseErr error)
+
+	// OnUsage, when set, is called with the token counts of every request,
+	// re-prompts included.
+	OnUsage func(Usage)
 }
 
 type chatRequest struct {
-	Model    string        `json:"model"`
-	Messages []chatMessage `json:"messages"`
-	Stream   bool          `json:"stream,omitempty"`
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *streamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// streamOptions asks for a final chunk with the token usage of a stream.
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// responseFormat selects OpenAI JSON mode ("json_object").
+type responseFormat struct {
+	Type string `json:"type"`
 }
 
 type chatMessage struct {
@@ -32,12 +59,20 @@ type chatMessage struct {
 	Content string `json:"content"`
 }
 
+// openAIUsage is the token usage of a completion, or of a stream in its
+// final chunk.
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
 type chatResponse struct {
 	Choices []struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage *openAIUsage `json:"usage,omitempty"`
 
 	Error *struct {
 		Message string      `json:"message"`
@@ -46,80 +81,120 @@ type chatResponse struct {
 	} `json:"error,omitempty"`
 }
 
-// Complete sends a single chat completion request and returns the content of the first choice.
-//
-//nolint:gocyclo // HTTP lifecycle: validate, build, send, read, decode
+// Complete sends a single chat completion request and returns the content
+// of the first choice. With StrictJSON the answer is valid JSON or an error.
 func (c Client) Complete(ctx context.Context, prompt string) (string, error) {
+	a, err := c.adapter()
+	if err != nil {
+		return "", err
+	}
+	answer, err := c.complete(ctx, a, prompt)
+	if err != nil || !c.StrictJSON {
+		return answer, err
+	}
+	return c.ensureJSON(ctx, a, prompt, answer)
+}
+
+// complete sends one buffered completion request.
+func (c Client) complete(ctx context.Context, a adapter, prompt string) (string, error) {
+	resp, err := c.send(ctx, a, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response body: %w", err)
+	}
+
+	if err := checkStatus(resp, body); err != nil {
+		return "", err
+	}
+
+	answer, err := a.decode(body)
+	if err != nil {
+		return "", err
+	}
+	c.reportUsage(prompt, answer, a.usage(body))
+	return answer, nil
+}
+
+// send validates the client, builds the provider's request, and returns
+// the raw HTTP response. The caller must close the body.
+func (c Client) send(ctx context.Context, a adapter, prompt string, stream bool) (*http.Response, error) {
 	if c.Timeout <= 0 {
 		c.Timeout = 60 * time.Second
 	}
+	if c.Endpoint == "" {
+		c.Endpoint = a.defaultEndpoint()
+	}
+	if c.Endpoint == "" {
+		return nil, fmt.Errorf("no endpoint configured for provider %q", a.name())
+	}
 
 	// Resolve API key:
 	// 1) explicit --api-key wins
-	// 2) else OPENAI_API_KEY from env (for any OpenAI-compatible cloud endpoint)
+	// 2) else the provider's environment variable (OPENAI_API_KEY, ANTHROPIC_API_KEY, GEMINI_API_KEY)
 	if c.APIKey == "" {
-		if env := os.Getenv("OPENAI_API_KEY"); env != "" {
-			c.APIKey = env
+		for _, name := range a.keyEnv() {
+			if env := os.Getenv(name); env != "" {
+				c.APIKey = env
+				break
+			}
 		}
 	}
 
 	// Basic validation: reject obviously invalid keys
 	if c.APIKey != "" && len(c.APIKey) < 8 {
-		return "", fmt.Errorf("API key too short (minimum 8 characters)")
-	}
-
-	reqBody := chatRequest{
-		Model: c.Model,
-		Messages: []chatMessage{
-			{Role: "user", Content: prompt},
-		},
+		return nil, fmt.Errorf("API key too short (minimum 8 characters)")
 	}
 
+	url, reqBody := a.request(&c, prompt, stream)
 	payload, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	url := strings.TrimRight(c.Endpoint, "/") + "/chat/completions"
-
 	httpClient := &http.Client{Timeout: c.Timeout}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
-		return "", fmt.Errorf("build request: %w", err)
+		return nil, fmt.Errorf("build request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-
-	// Only set Authorization when we actually have a key.
-	if c.APIKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
 	}
+	a.authorize(req, c.APIKey)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("http do: %w", err)
+		return nil, fmt.Errorf("http do: %w", err)
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			return
-		}
-	}()
+	return resp, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("reading response body: %w", err)
+// checkStatus turns a non-2xx response into an error.
+func checkStatus(resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
 	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		// Truncate body to prevent leaking sensitive data in error messages
-		bodyStr := string(body)
-		if len(bodyStr) > 500 {
-			bodyStr = bodyStr[:500] + "...(truncated)"
-		}
-		return "", fmt.Errorf("%d %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode), bodyStr)
+	// Truncate body to prevent leaking sensitive data in error messages
+	bodyStr := string(body)
+	if len(bodyStr) > 500 {
+		bodyStr = bodyStr[:500] + "...(truncated)"
 	}
+	return fmt.Errorf("%d %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode), bodyStr)
+}
 
+// decodeCompletion extracts the first choice from a buffered chat completion.
+func decodeCompletion(body []byte) (string, error) {
 	var cr chatResponse
 	if err := json.Unmarshal(body, &cr); err != nil {
 		return "", fmt.Errorf("decode response: %w (raw: %s)", err, string(body))
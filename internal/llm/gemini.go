@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// gemini speaks the Google Gemini generateContent API.
+type gemini struct{}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiGenerationConfig struct {
+	MaxOutputTokens  int    `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType string `json:"responseMimeType,omitempty"`
+}
+
+type geminiRequest struct {
+	Contents         []geminiContent         `json:"contents"`
+	GenerationConfig *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// geminiResponse is a buffered response or one streamed chunk.
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+func (gemini) name() string            { return ProviderGemini }
+func (gemini) defaultEndpoint() string { return "https://generativelanguage.googleapis.com/v1beta" }
+func (gemini) keyEnv() []string        { return []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"} }
+
+func (gemini) request(c *Client, prompt string, stream bool) (string, any) {
+	url := strings.TrimRight(c.Endpoint, "/") + "/models/" + strings.TrimPrefix(c.Model, "models/")
+	if stream {
+		url += ":streamGenerateContent?alt=sse"
+	} else {
+		url += ":generateContent"
+	}
+	body := geminiRequest{Contents: []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}}}
+	if c.MaxTokens > 0 || c.StrictJSON {
+		body.GenerationConfig = &geminiGenerationConfig{MaxOutputTokens: c.MaxTokens}
+		if c.StrictJSON {
+			body.GenerationConfig.ResponseMimeType = "application/json"
+		}
+	}
+	return url, body
+}
+
+func (gemini) authorize(req *http.Request, apiKey string) {
+	if apiKey != "" {
+		req.Header.Set("x-goog-api-key", apiKey)
+	}
+}
+
+// text joins the first candidate's parts. Blocked prompts and API errors
+// become errors; an empty candidate is not one, since streams may carry
+// chunks without text.
+func (r *geminiResponse) text() (string, error) {
+	if r.Error != nil {
+		return "", fmt.Errorf("llm error: %s: %s", r.Error.Status, r.Error.Message)
+	}
+	if len(r.Candidates) == 0 {
+		if r.PromptFeedback != nil && r.PromptFeedback.BlockReason != "" {
+			return "", fmt.Errorf("llm error: prompt blocked: %s", r.PromptFeedback.BlockReason)
+		}
+		return "", nil
+	}
+	var text strings.Builder
+	for _, p := range r.Candidates[0].Content.Parts {
+		text.WriteString(p.Text)
+	}
+	return text.String(), nil
+}
+
+func (gemini) decode(body []byte) (string, error) {
+	var r geminiResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", fmt.Errorf("decode response: %w (raw: %s)", err, string(body))
+	}
+	text, err := r.text()
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		reason := "no candidates"
+		if len(r.Candidates) > 0 {
+			reason = "finish reason " + r.Candidates[0].FinishReason
+		}
+		return "", fmt.Errorf("no text content in response (%s)", reason)
+	}
+	return text, nil
+}
+
+// usage reads usageMetadata, which streams repeat with running totals.
+func (gemini) usage(payload []byte) Usage {
+	var r struct {
+		UsageMetadata *struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if json.Unmarshal(payload, &r) != nil || r.UsageMetadata == nil {
+		return Usage{}
+	}
+	return Usage{PromptTokens: r.UsageMetadata.PromptTokenCount, CompletionTokens: r.UsageMetadata.CandidatesTokenCount}
+}
+
+func (gemini) decodeChunk(data string) (string, bool, error) {
+	var r geminiResponse
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		return "", false, fmt.Errorf("decode stream chunk: %w (raw: %s)", err, data)
+	}
+	text, err := r.text()
+	return text, false, err
+}
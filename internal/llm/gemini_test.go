@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGemini_Complete(t *testing.T) {
+	var got geminiRequest
+	var header http.Header
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header, path = r.Header.Clone(), r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"hello "},{"text":"world"}]},` +
+			`"finishReason":"STOP"}]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Provider: ProviderGemini, Endpoint: srv.URL + "/v1beta", Model: "models/gemini-test", APIKey: "AIza-test-key", MaxTokens: 1024}
+	out, err := c.Complete(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", out)
+
+	assert.Equal(t, "/v1beta/models/gemini-test:generateContent", path)
+	assert.Equal(t, "AIza-test-key", header.Get("x-goog-api-key"))
+	assert.Empty(t, header.Get("Authorization"))
+	require.Len(t, got.Contents, 1)
+	assert.Equal(t, "hi", got.Contents[0].Parts[0].Text)
+	require.NotNil(t, got.GenerationConfig)
+	assert.Equal(t, 1024, got.GenerationConfig.MaxOutputTokens)
+}
+
+func TestGemini_EnvKeyAndNoMaxTokens(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "AIza-google-env")
+	var raw map[string]any
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("x-goog-api-key")
+		_ = json.NewDecoder(r.Body).Decode(&raw)
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Provider: ProviderGemini, Endpoint: srv.URL, Model: "gemini-test"}
+	_, err := c.Complete(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "AIza-google-env", key)
+	assert.NotContains(t, raw, "generationConfig")
+}
+
+func TestGemini_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"blocked prompt", `{"promptFeedback":{"blockReason":"SAFETY"}}`, "prompt blocked: SAFETY"},
+		{"empty candidate", `{"candidates":[{"content":{"parts":[]},"finishReason":"MAX_TOKENS"}]}`, "MAX_TOKENS"},
+		{"api error", `{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`, "API key not valid"},
+		{"malformed", `not json`, "decode response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gemini{}.decode([]byte(tt.body))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestGemini_Stream(t *testing.T) {
+	var path, query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, tok := range []string{`{"summary"`, `: "ok"}`} {
+			data, _ := json.Marshal(map[string]any{
+				"candidates": []map[string]any{{"content": map[string]any{"parts": []map[string]string{{"text": tok}}}}},
+			})
+			_, _ = fmt.Fprintf(w, "data: %s\r\n\r\n", data)
+		}
+	}))
+	defer srv.Close()
+
+	var tokens []string
+	c := Client{Provider: ProviderGemini, Endpoint: srv.URL, Model: "gemini-test"}
+	out, err := c.Stream(context.Background(), "hi", func(tok string) { tokens = append(tokens, tok) })
+	require.NoError(t, err)
+	assert.Equal(t, "/models/gemini-test:streamGenerateContent", path)
+	assert.Equal(t, "alt=sse", query)
+	assert.Equal(t, `{"summary": "ok"}`, out)
+	assert.Len(t, tokens, 2)
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxRejectedAnswer bounds how much of an invalid answer is quoted back to
+// the model when re-prompting.
+const maxRejectedAnswer = 2000
+
+// ExtractJSON extracts a JSON object or array from noisy LLM output, e.g.
+// an answer wrapped in prose or a Markdown code fence.
+func ExtractJSON(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("empty LLM output")
+	}
+
+	// If starts with { or [, assume valid JSON
+	if s[0] == '{' || s[0] == '[' {
+		return s, nil
+	}
+
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start == -1 || end == -1 || end <= start {
+		return "", fmt.Errorf("no JSON object detected in output")
+	}
+
+	return s[start : end+1], nil
+}
+
+// ValidateJSON reports why an answer does not hold a parseable JSON value.
+func ValidateJSON(answer string) error {
+	s, err := ExtractJSON(answer)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// ensureJSON re-prompts with the parse error while answer is not valid
+// JSON, up to c.JSONRetries times. Retries are buffered, not streamed.
+func (c Client) ensureJSON(ctx context.Context, a adapter, prompt, answer string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		parseErr := ValidateJSON(answer)
+		if parseErr == nil {
+			return answer, nil
+		}
+		if attempt > c.JSONRetries {
+			return answer, fmt.Errorf("no valid JSON after %d attempt(s): %w", attempt, parseErr)
+		}
+		if c.OnJSONRetry != nil {
+			c.OnJSONRetry(attempt, parseErr)
+		}
+		var err error
+		if answer, err = c.complete(ctx, a, jsonRetryPrompt(prompt, answer, parseErr)); err != nil {
+			return "", err
+		}
+	}
+}
+
+// jsonRetryPrompt repeats the prompt with the rejected answer and why it
+// was rejected.
+func jsonRetryPrompt(prompt, answer string, parseErr error) string {
+	if len(answer) > maxRejectedAnswer {
+		answer = answer[:maxRejectedAnswer] + "...(truncated)"
+	}
+	return prompt + fmt.Sprintf(`
+
+YOUR PREVIOUS ANSWER WAS REJECTED: it could not be parsed as JSON (%v).
+Previous answer:
+%s
+
+Answer again with ONLY the JSON object required above: no prose, no Markdown code fences.
+`, parseErr, answer)
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractJSON(t *testing.T) {
+	out, err := ExtractJSON("  {\"a\":1}\n")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, out)
+
+	out, err = ExtractJSON("Here you go:\n```json\n{\"a\":{\"b\":2}}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":{"b":2}}`, out)
+
+	_, err = ExtractJSON("no json here")
+	assert.Error(t, err)
+	_, err = ExtractJSON("")
+	assert.Error(t, err)
+}
+
+// answerServer replies with the given answers in turn and records the
+// requests it received.
+func answerServer(t *testing.T, answers ...string) (*httptest.Server, *[]chatRequest) {
+	t.Helper()
+	var requests []chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		answer := answers[min(len(requests), len(answers))-1]
+		data, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": answer}}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestComplete_StrictJSONRetries(t *testing.T) {
+	srv, requests := answerServer(t, `Sure! {"summary": "ok",}`, `{"summary": "ok"}`)
+
+	var retries []int
+	c := Client{Endpoint: srv.URL, Model: "test", StrictJSON: true, JSONRetries: 2,
+		OnJSONRetry: func(attempt int, _ error) { retries = append(retries, attempt) }}
+	out, err := c.Complete(context.Background(), "Return JSON")
+	require.NoError(t, err)
+	assert.Equal(t, `{"summary": "ok"}`, out)
+	assert.Equal(t, []int{1}, retries)
+
+	require.Len(t, *requests, 2)
+	first, second := (*requests)[0], (*requests)[1]
+	require.NotNil(t, first.ResponseFormat)
+	assert.Equal(t, "json_object", first.ResponseFormat.Type)
+	assert.Equal(t, "Return JSON", first.Messages[0].Content)
+	assert.Contains(t, second.Messages[0].Content, "YOUR PREVIOUS ANSWER WAS REJECTED")
+	assert.Contains(t, second.Messages[0].Content, `Sure! {"summary": "ok",}`)
+}
+
+func TestComplete_StrictJSONGivesUp(t *testing.T) {
+	srv, requests := answerServer(t, "I cannot help with that")
+
+	c := Client{Endpoint: srv.URL, Model: "test", StrictJSON: true, JSONRetries: 1}
+	out, err := c.Complete(context.Background(), "Return JSON")
+	assert.ErrorContains(t, err, "no valid JSON after 2 attempt(s)")
+	assert.Equal(t, "I cannot help with that", out)
+	assert.Len(t, *requests, 2)
+}
+
+func TestComplete_NotStrict(t *testing.T) {
+	srv, requests := answerServer(t, "plain text")
+
+	c := Client{Endpoint: srv.URL, Model: "test"}
+	out, err := c.Complete(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "plain text", out)
+	require.Len(t, *requests, 1)
+	assert.Nil(t, (*requests)[0].ResponseFormat)
+}
+
+func TestGemini_StrictJSON(t *testing.T) {
+	var got geminiRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"{\"ok\":true}"}]}}]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Provider: ProviderGemini, Endpoint: srv.URL, Model: "gemini-test", APIKey: "AIza-test-key", StrictJSON: true}
+	out, err := c.Complete(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, out)
+	require.NotNil(t, got.GenerationConfig)
+	assert.Equal(t, "application/json", got.GenerationConfig.ResponseMimeType)
+	assert.Zero(t, got.GenerationConfig.MaxOutputTokens)
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Supported providers.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderGemini    = "gemini"
+)
+
+// Providers lists the values accepted for Client.Provider.
+var Providers = []string{ProviderOpenAI, ProviderAnthropic, ProviderGemini}
+
+// adapter translates a prompt into one provider's wire format and back.
+type adapter interface {
+	name() string
+	defaultEndpoint() string
+	keyEnv() []string
+	// request returns the URL and JSON body for a completion.
+	request(c *Client, prompt string, stream bool) (string, any)
+	authorize(req *http.Request, apiKey string)
+	// decode extracts the text of a buffered completion.
+	decode(body []byte) (string, error)
+	// decodeChunk extracts the text of one SSE data payload; done ends the stream.
+	decodeChunk(data string) (token string, done bool, err error)
+	// usage extracts the token counts reported in a buffered completion or
+	// an SSE data payload; counts not reported are zero.
+	usage(payload []byte) Usage
+}
+
+// adapter returns the adapter for c.Provider.
+func (c Client) adapter() (adapter, error) {
+	switch strings.ToLower(c.Provider) {
+	case "", ProviderOpenAI:
+		return openAI{}, nil
+	case ProviderAnthropic:
+		return anthropic{}, nil
+	case ProviderGemini:
+		return gemini{}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q (supported: %s)", c.Provider, strings.Join(Providers, ", "))
+	}
+}
+
+// DefaultEndpoint returns the public API endpoint of a provider, or "" for
+// OpenAI-compatible providers, whose endpoint must be given.
+func DefaultEndpoint(provider string) string {
+	a, err := Client{Provider: provider}.adapter()
+	if err != nil {
+		return ""
+	}
+	return a.defaultEndpoint()
+}
+
+// ValidateProvider reports whether provider is supported.
+func ValidateProvider(provider string) error {
+	_, err := Client{Provider: provider}.adapter()
+	return err
+}
+
+// openAI speaks the OpenAI chat completions API, which most self-hosted
+// servers (Ollama, vLLM, LM Studio) and gateways also implement.
+type openAI struct{}
+
+func (openAI) name() string            { return ProviderOpenAI }
+func (openAI) defaultEndpoint() string { return "" }
+func (openAI) keyEnv() []string        { return []string{"OPENAI_API_KEY"} }
+
+func (openAI) request(c *Client, prompt string, stream bool) (string, any) {
+	req := chatRequest{
+		Model:     c.Model,
+		Messages:  []chatMessage{{Role: "user", Content: prompt}},
+		MaxTokens: c.MaxTokens,
+		Stream:    stream,
+	}
+	if stream {
+		req.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+	if c.StrictJSON {
+		req.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+	return strings.TrimRight(c.Endpoint, "/") + "/chat/completions", req
+}
+
+func (openAI) authorize(req *http.Request, apiKey string) {
+	// Only set Authorization when we actually have a key.
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+}
+
+func (openAI) decode(body []byte) (string, error) {
+	return decodeCompletion(body)
+}
+
+func (openAI) usage(payload []byte) Usage {
+	var r struct {
+		Usage *openAIUsage `json:"usage"`
+	}
+	if json.Unmarshal(payload, &r) != nil || r.Usage == nil {
+		return Usage{}
+	}
+	return Usage{PromptTokens: r.Usage.PromptTokens, CompletionTokens: r.Usage.CompletionTokens}
+}
+
+func (openAI) decodeChunk(data string) (string, bool, error) {
+	if data == "[DONE]" {
+		return "", true, nil
+	}
+	var chunk streamChunk
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return "", false, fmt.Errorf("decode stream chunk: %w (raw: %s)", err, data)
+	}
+	if chunk.Error != nil {
+		return "", false, fmt.Errorf("llm error: %s", chunk.Error.Message)
+	}
+	if len(chunk.Choices) == 0 {
+		return "", false, nil
+	}
+	return chunk.Choices[0].Delta.Content, false, nil
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// streamChunk is one server-sent event of an OpenAI-compatible streaming completion.
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// maxSSELine bounds a single SSE line; chunks carry a few tokens each.
+const maxSSELine = 1024 * 1024
+
+// Stream sends a streaming completion request (SSE) and calls onToken
+// for every content delta as it arrives. It returns the full completion.
+// Endpoints that ignore "stream": true and answer with a buffered JSON
+// completion are handled transparently: onToken is called once with the
+// whole content. With StrictJSON, re-prompts after an invalid answer are
+// buffered and not passed to onToken.
+func (c Client) Stream(ctx context.Context, prompt string, onToken func(string)) (string, error) {
+	a, err := c.adapter()
+	if err != nil {
+		return "", err
+	}
+	answer, err := c.stream(ctx, a, prompt, onToken)
+	if err != nil || !c.StrictJSON {
+		return answer, err
+	}
+	return c.ensureJSON(ctx, a, prompt, answer)
+}
+
+// stream sends one streaming completion request.
+func (c Client) stream(ctx context.Context, a adapter, prompt string, onToken func(string)) (string, error) {
+	resp, err := c.send(ctx, a, prompt, true)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return
+		}
+	}()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("reading response body: %w", err)
+		}
+		if err := checkStatus(resp, body); err != nil {
+			return "", err
+		}
+		content, err := a.decode(body)
+		if err != nil {
+			return "", err
+		}
+		if onToken != nil {
+			onToken(content)
+		}
+		c.reportUsage(prompt, content, a.usage(body))
+		return content, nil
+	}
+
+	if err := checkStatus(resp, nil); err != nil {
+		return "", err
+	}
+
+	content, usage, err := readSSE(resp.Body, a, onToken)
+	if err != nil {
+		return content, err
+	}
+	c.reportUsage(prompt, content, usage)
+	return content, nil
+}
+
+// readSSE accumulates content deltas and reported token counts from an SSE
+// body until the adapter reports the end of the stream or EOF.
+func readSSE(r io.Reader, a adapter, onToken func(string)) (string, Usage, error) {
+	var full strings.Builder
+	var usage Usage
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELine)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue // comments, event names, keep-alives
+		}
+		data = strings.TrimSpace(data)
+
+		token, done, err := a.decodeChunk(data)
+		if err != nil {
+			return full.String(), usage, err
+		}
+		usage.merge(a.usage([]byte(data)))
+		if done {
+			break
+		}
+		if token == "" {
+			continue
+		}
+		full.WriteString(token)
+		if onToken != nil {
+			onToken(token)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return full.String(), usage, fmt.Errorf("reading stream: %w", err)
+	}
+
+	if full.Len() == 0 {
+		return "", usage, fmt.Errorf("no content in streamed response")
+	}
+	return full.String(), usage, nil
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream_SSE(t *testing.T) {
+	var gotStream bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err == nil {
+			gotStream = req.Stream
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, tok := range []string{`{"summary"`, `: "ok"`, `}`} {
+			data, _ := json.Marshal(map[string]any{
+				"choices": []map[string]any{{"delta": map[string]string{"content": tok}}},
+			})
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		_, _ = fmt.Fprint(w, ": keep-alive\n\ndata: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	var tokens []string
+	c := Client{Endpoint: srv.URL, Model: "test"}
+	out, err := c.Stream(context.Background(), "hi", func(tok string) { tokens = append(tokens, tok) })
+	require.NoError(t, err)
+	assert.True(t, gotStream)
+	assert.Equal(t, `{"summary": "ok"}`, out)
+	assert.Len(t, tokens, 3)
+}
+
+func TestStream_BufferedFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"full answer"}}]}`))
+	}))
+	defer srv.Close()
+
+	calls := 0
+	c := Client{Endpoint: srv.URL, Model: "test"}
+	out, err := c.Stream(context.Background(), "hi", func(string) { calls++ })
+	require.NoError(t, err)
+	assert.Equal(t, "full answer", out)
+	assert.Equal(t, 1, calls)
+}
+
+func TestStream_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad key"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := Client{Endpoint: srv.URL, Model: "test"}.Stream(context.Background(), "hi", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestReadSSE_ChunkError(t *testing.T) {
+	body := "data: {\"choices\":[{\"delta\":{\"content\":\"par\"}}]}\n\ndata: {\"error\":{\"message\":\"overloaded\"}}\n\n"
+	partial, _, err := readSSE(strings.NewReader(body), openAI{}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "overloaded")
+	assert.Equal(t, "par", partial)
+}
@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ppiankov/kubenow/internal/prompt"
+)
+
+// Usage counts the tokens of one or more LLM calls.
+type Usage struct {
+	Calls            int  `json:"calls"`
+	PromptTokens     int  `json:"promptTokens"`
+	CompletionTokens int  `json:"completionTokens"`
+	Estimated        bool `json:"estimated,omitempty"` // some counts were estimated locally, not reported by the API
+}
+
+// Add sums o into u.
+func (u *Usage) Add(o Usage) {
+	u.Calls += o.Calls
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.Estimated = u.Estimated || o.Estimated
+}
+
+// merge takes the counts an API reported in one payload. Streams report
+// them in pieces (Anthropic) or cumulatively per chunk (Gemini), so a later
+// non-zero count replaces an earlier one.
+func (u *Usage) merge(o Usage) {
+	if o.PromptTokens > 0 {
+		u.PromptTokens = o.PromptTokens
+	}
+	if o.CompletionTokens > 0 {
+		u.CompletionTokens = o.CompletionTokens
+	}
+}
+
+// String describes the usage in one line, e.g.
+// "2 calls, 5210 prompt + 830 completion tokens (estimated)".
+func (u Usage) String() string {
+	calls := "calls"
+	if u.Calls == 1 {
+		calls = "call"
+	}
+	s := fmt.Sprintf("%d %s, %d prompt + %d completion tokens", u.Calls, calls, u.PromptTokens, u.CompletionTokens)
+	if u.Estimated {
+		s += " (estimated)"
+	}
+	return s
+}
+
+// reportUsage passes the counts of one call to OnUsage. Counts the API did
+// not report are estimated from the prompt and answer text.
+func (c Client) reportUsage(promptText, answer string, u Usage) {
+	if c.OnUsage == nil {
+		return
+	}
+	u.Calls = 1
+	if u.PromptTokens == 0 {
+		u.PromptTokens, u.Estimated = prompt.EstimateTokens(promptText), true
+	}
+	if u.CompletionTokens == 0 && answer != "" {
+		u.CompletionTokens, u.Estimated = prompt.EstimateTokens(answer), true
+	}
+	c.OnUsage(u)
+}
+
+// Price is what a model costs in USD per million tokens.
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Cost returns what u costs at p, in USD.
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.PromptTokens)*p.Input + float64(u.CompletionTokens)*p.Output) / 1e6
+}
+
+// modelPrices lists public list prices by model name prefix; more specific
+// prefixes come first. Self-hosted models have no price.
+var modelPrices = []struct {
+	prefix string
+	price  Price
+}{
+	{"gpt-5-nano", Price{0.05, 0.40}},
+	{"gpt-5-mini", Price{0.25, 2.00}},
+	{"gpt-5", Price{1.25, 10.00}},
+	{"gpt-4.1-nano", Price{0.10, 0.40}},
+	{"gpt-4.1-mini", Price{0.40, 1.60}},
+	{"gpt-4.1", Price{2.00, 8.00}},
+	{"gpt-4o-mini", Price{0.15, 0.60}},
+	{"gpt-4o", Price{2.50, 10.00}},
+	{"gpt-4-turbo", Price{10.00, 30.00}},
+	{"gpt-3.5-turbo", Price{0.50, 1.50}},
+	{"o4-mini", Price{1.10, 4.40}},
+	{"o3-mini", Price{1.10, 4.40}},
+	{"o3", Price{2.00, 8.00}},
+	{"o1-mini", Price{1.10, 4.40}},
+	{"o1", Price{15.00, 60.00}},
+	{"claude-opus-4-5", Price{5.00, 25.00}},
+	{"claude-opus", Price{15.00, 75.00}},
+	{"claude-3-opus", Price{15.00, 75.00}},
+	{"claude-sonnet", Price{3.00, 15.00}},
+	{"claude-3-7-sonnet", Price{3.00, 15.00}},
+	{"claude-3-5-sonnet", Price{3.00, 15.00}},
+	{"claude-haiku-4-5", Price{1.00, 5.00}},
+	{"claude-3-5-haiku", Price{0.80, 4.00}},
+	{"claude-3-haiku", Price{0.25, 1.25}},
+	{"gemini-2.5-pro", Price{1.25, 10.00}},
+	{"gemini-2.5-flash-lite", Price{0.10, 0.40}},
+	{"gemini-2.5-flash", Price{0.30, 2.50}},
+	{"gemini-2.0-flash-lite", Price{0.075, 0.30}},
+	{"gemini-2.0-flash", Price{0.10, 0.40}},
+	{"gemini-1.5-pro", Price{1.25, 5.00}},
+	{"gemini-1.5-flash", Price{0.075, 0.30}},
+}
+
+// ModelPrice returns the list price of a model, matched by name prefix
+// after any provider path ("openai/gpt-4o", "models/gemini-2.5-pro").
+func ModelPrice(model string) (Price, bool) {
+	m := strings.ToLower(model)
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+	for _, p := range modelPrices {
+		if strings.HasPrefix(m, p.prefix) {
+			return p.price, true
+		}
+	}
+	return Price{}, false
+}
+
+// Cost is the token usage of an analysis and what it cost, as recorded in
+// report metadata.
+type Cost struct {
+	Model string `json:"model"`
+	Usage
+	// USD is nil when the model has no known price.
+	USD *float64 `json:"usd,omitempty"`
+}
+
+// String describes the cost in one line, e.g.
+// "1 call, 5210 prompt + 830 completion tokens, $0.0213".
+func (c *Cost) String() string {
+	if c.USD == nil {
+		return c.Usage.String() + ", cost unknown"
+	}
+	return fmt.Sprintf("%s, $%.4f", c.Usage, *c.USD)
+}
+
+// Meter sums the usage of a model's calls. It is safe for concurrent use;
+// pass Record as Client.OnUsage.
+type Meter struct {
+	model  string
+	price  *Price
+	parent *Meter
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewMeter meters calls to model, priced at price, or at the model's list
+// price when price is nil.
+func NewMeter(model string, price *Price) *Meter {
+	if price == nil {
+		if p, ok := ModelPrice(model); ok {
+			price = &p
+		}
+	}
+	return &Meter{model: model, price: price}
+}
+
+// Child returns a meter for a share of m's calls, e.g. one watch
+// iteration: it prices calls like m, and what it records is added to m too.
+func (m *Meter) Child() *Meter {
+	return &Meter{model: m.model, price: m.price, parent: m}
+}
+
+// Record adds the usage of a call.
+func (m *Meter) Record(u Usage) {
+	m.mu.Lock()
+	m.usage.Add(u)
+	m.mu.Unlock()
+	if m.parent != nil {
+		m.parent.Record(u)
+	}
+}
+
+// Cost returns the usage so far and its cost, or nil before any call.
+func (m *Meter) Cost() *Cost {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.usage.Calls == 0 {
+		return nil
+	}
+	c := &Cost{Model: m.model, Usage: m.usage}
+	if m.price != nil {
+		usd := m.price.Cost(m.usage)
+		c.USD = &usd
+	}
+	return c
+}
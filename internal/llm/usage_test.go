@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage_Reported(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		body     string
+		want     Usage
+	}{
+		{
+			name: "openai",
+			body: `{"choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":120,"completion_tokens":30,"total_tokens":150}}`,
+			want: Usage{Calls: 1, PromptTokens: 120, CompletionTokens: 30},
+		},
+		{
+			name:     "anthropic",
+			provider: ProviderAnthropic,
+			body:     `{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":200,"output_tokens":40}}`,
+			want:     Usage{Calls: 1, PromptTokens: 200, CompletionTokens: 40},
+		},
+		{
+			name:     "gemini",
+			provider: ProviderGemini,
+			body: `{"candidates":[{"content":{"parts":[{"text":"ok"}]}}],` +
+				`"usageMetadata":{"promptTokenCount":90,"candidatesTokenCount":12,"totalTokenCount":102}}`,
+			want: Usage{Calls: 1, PromptTokens: 90, CompletionTokens: 12},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			var got []Usage
+			c := Client{Provider: tt.provider, Endpoint: srv.URL, Model: "m", OnUsage: func(u Usage) { got = append(got, u) }}
+			_, err := c.Complete(context.Background(), "hi")
+			require.NoError(t, err)
+			assert.Equal(t, []Usage{tt.want}, got)
+		})
+	}
+}
+
+func TestUsage_EstimatedWhenNotReported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"a short answer"}}]}`))
+	}))
+	defer srv.Close()
+
+	var got Usage
+	c := Client{Endpoint: srv.URL, Model: "m", OnUsage: func(u Usage) { got = u }}
+	_, err := c.Complete(context.Background(), "a prompt of several words")
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Calls)
+	assert.True(t, got.Estimated)
+	assert.Positive(t, got.PromptTokens)
+	assert.Positive(t, got.CompletionTokens)
+}
+
+func TestUsage_Streams(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		events   []string
+		want     Usage
+	}{
+		{
+			name: "openai final usage chunk",
+			events: []string{
+				`{"choices":[{"delta":{"content":"ok"}}]}`,
+				`{"choices":[],"usage":{"prompt_tokens":50,"completion_tokens":7}}`,
+				`[DONE]`,
+			},
+			want: Usage{Calls: 1, PromptTokens: 50, CompletionTokens: 7},
+		},
+		{
+			name:     "anthropic start and delta",
+			provider: ProviderAnthropic,
+			events: []string{
+				`{"type":"message_start","message":{"usage":{"input_tokens":60,"output_tokens":1}}}`,
+				`{"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}`,
+				`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":9}}`,
+				`{"type":"message_stop"}`,
+			},
+			want: Usage{Calls: 1, PromptTokens: 60, CompletionTokens: 9},
+		},
+		{
+			name:     "gemini running totals",
+			provider: ProviderGemini,
+			events: []string{
+				`{"candidates":[{"content":{"parts":[{"text":"o"}]}}],"usageMetadata":{"promptTokenCount":70,"candidatesTokenCount":1}}`,
+				`{"candidates":[{"content":{"parts":[{"text":"k"}]}}],"usageMetadata":{"promptTokenCount":70,"candidatesTokenCount":4}}`,
+			},
+			want: Usage{Calls: 1, PromptTokens: 70, CompletionTokens: 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for _, ev := range tt.events {
+					_, _ = fmt.Fprintf(w, "data: %s\n\n", ev)
+				}
+			}))
+			defer srv.Close()
+
+			var got Usage
+			c := Client{Provider: tt.provider, Endpoint: srv.URL, Model: "m", OnUsage: func(u Usage) { got = u }}
+			out, err := c.Stream(context.Background(), "hi", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "ok", out)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStream_RequestsUsage(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Endpoint: srv.URL, Model: "m"}
+	_, err := c.Stream(context.Background(), "hi", nil)
+	require.NoError(t, err)
+	assert.Contains(t, body, `"stream_options":{"include_usage":true}`)
+
+	_, err = c.Complete(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.NotContains(t, body, "stream_options")
+}
+
+func TestModelPrice(t *testing.T) {
+	p, ok := ModelPrice("gpt-4o-mini-2024-07-18")
+	require.True(t, ok)
+	assert.Equal(t, Price{0.15, 0.60}, p)
+
+	p, ok = ModelPrice("openai/gpt-4o")
+	require.True(t, ok)
+	assert.Equal(t, Price{2.50, 10.00}, p)
+
+	p, ok = ModelPrice("models/gemini-2.5-flash-lite")
+	require.True(t, ok)
+	assert.Equal(t, Price{0.10, 0.40}, p)
+
+	_, ok = ModelPrice("mixtral:8x22b")
+	assert.False(t, ok)
+}
+
+func TestMeter(t *testing.T) {
+	m := NewMeter("gpt-4o", nil)
+	assert.Nil(t, m.Cost())
+
+	m.Record(Usage{Calls: 1, PromptTokens: 1_000_000, CompletionTokens: 100_000})
+	m.Record(Usage{Calls: 1, PromptTokens: 1000, CompletionTokens: 500, Estimated: true})
+	c := m.Cost()
+	require.NotNil(t, c)
+	assert.Equal(t, Usage{Calls: 2, PromptTokens: 1_001_000, CompletionTokens: 100_500, Estimated: true}, c.Usage)
+	require.NotNil(t, c.USD)
+	assert.InDelta(t, 2.5025+1.005, *c.USD, 1e-9)
+	assert.Equal(t, "2 calls, 1001000 prompt + 100500 completion tokens (estimated), $3.5075", c.String())
+
+	local := NewMeter("mixtral", nil)
+	local.Record(Usage{Calls: 1, PromptTokens: 10, CompletionTokens: 5})
+	assert.Nil(t, local.Cost().USD)
+	assert.Equal(t, "1 call, 10 prompt + 5 completion tokens, cost unknown", local.Cost().String())
+
+	custom := NewMeter("mixtral", &Price{Input: 1, Output: 2})
+	custom.Record(Usage{Calls: 1, PromptTokens: 1_000_000, CompletionTokens: 1_000_000})
+	assert.InDelta(t, 3.0, *custom.Cost().USD, 1e-9)
+}
+
+func TestMeter_Child(t *testing.T) {
+	total := NewMeter("gpt-4o", nil)
+	first := total.Child()
+	first.Record(Usage{Calls: 1, PromptTokens: 100, CompletionTokens: 10})
+	second := total.Child()
+	second.Record(Usage{Calls: 1, PromptTokens: 200, CompletionTokens: 20})
+
+	assert.Equal(t, Usage{Calls: 1, PromptTokens: 200, CompletionTokens: 20}, second.Cost().Usage)
+	assert.Equal(t, Usage{Calls: 2, PromptTokens: 300, CompletionTokens: 30}, total.Cost().Usage)
+	require.NotNil(t, second.Cost().USD)
+}
@@ -0,0 +1,165 @@
+// Package llmcache keeps LLM answers on disk, keyed by a hash of the
+// snapshot and the options that shape the prompt, so repeated analyses of
+// an unchanged cluster reuse the answer instead of calling the LLM again.
+package llmcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/storage"
+)
+
+// DefaultTTL is how long a cached answer is reused when no TTL is given.
+const DefaultTTL = time.Hour
+
+// Options are the inputs besides the snapshot that change the answer: the
+// endpoint and model, and everything that shapes the prompt.
+type Options struct {
+	Provider     string                    `json:"provider"`
+	Endpoint     string                    `json:"endpoint"`
+	Model        string                    `json:"model"`
+	MaxTokens    int                       `json:"maxTokens,omitempty"`
+	StrictJSON   bool                      `json:"strictJSON,omitempty"`
+	Mode         string                    `json:"mode"`
+	Hint         string                    `json:"hint,omitempty"`
+	Enhancements prompt.PromptEnhancements `json:"enhancements"`
+	PromptBudget int                       `json:"promptBudget"`
+}
+
+// NewOptions collects the options of an analysis by client in mode, with
+// the prompt fitted to budget tokens.
+func NewOptions(client *llm.Client, mode, hint string, enh prompt.PromptEnhancements, budget int) Options {
+	return Options{
+		Provider:     client.Provider,
+		Endpoint:     client.Endpoint,
+		Model:        client.Model,
+		MaxTokens:    client.MaxTokens,
+		StrictJSON:   client.StrictJSON,
+		Mode:         mode,
+		Hint:         hint,
+		Enhancements: enh,
+		PromptBudget: budget,
+	}
+}
+
+// Entry is a cached answer.
+type Entry struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+	Model     string    `json:"model"`
+	Mode      string    `json:"mode"`
+	Response  string    `json:"response"`
+}
+
+// Key hashes the snapshot and opts. The snapshot's collection time is left
+// out, so two snapshots of an unchanged cluster share a key.
+func Key(snap *snapshot.Snapshot, opts Options) (string, error) {
+	s := *snap
+	s.GeneratedAt = time.Time{}
+	return KeyOf(&s, opts)
+}
+
+// KeyOf hashes any prompt input, e.g. a capacity report, and opts.
+func KeyOf(input any, opts Options) (string, error) {
+	data, err := json.Marshal(struct {
+		Input   any     `json:"snapshot"`
+		Options Options `json:"options"`
+	}{input, opts})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash prompt input: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Cache stores answers as one JSON file per key in a directory.
+type Cache struct {
+	store storage.Store
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// DefaultDir returns ~/.kubenow/cache/llm.
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".kubenow", "cache", "llm"), nil
+}
+
+// New returns a cache in dir whose answers expire after ttl (DefaultTTL
+// when ttl is not positive). The directory is created on the first Put.
+func New(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{store: storage.NewLocal(dir), ttl: ttl, now: time.Now}
+}
+
+func objectKey(key string) string {
+	return key + ".json"
+}
+
+// Get returns the answer cached under key, or false when there is none or
+// it has expired. Expired and unreadable entries are removed.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	ctx := context.Background()
+	data, err := c.store.Get(ctx, objectKey(key))
+	if err != nil {
+		return nil, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key || c.now().Sub(e.CreatedAt) > c.ttl {
+		_ = c.store.Delete(ctx, objectKey(key))
+		return nil, false
+	}
+	return &e, true
+}
+
+// Put caches the answer of model in mode under key.
+func (c *Cache) Put(key, model, mode, response string) error {
+	if key == "" {
+		return errors.New("empty cache key")
+	}
+	data, err := json.Marshal(Entry{Key: key, CreatedAt: c.now().UTC(), Model: model, Mode: mode, Response: response})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := c.store.Put(context.Background(), objectKey(key), data); err != nil {
+		return fmt.Errorf("failed to write LLM cache: %w", err)
+	}
+	return nil
+}
+
+// Complete returns the answer cached under key, or calls complete and
+// caches its answer for model in mode when it is valid JSON. hit is the
+// cached entry when the answer came from the cache. A failed write is only
+// logged, since the answer itself is good.
+func (c *Cache) Complete(key, model, mode string, complete func() (string, error)) (answer string, hit *Entry, err error) {
+	if e, ok := c.Get(key); ok {
+		return e.Response, e, nil
+	}
+	answer, err = complete()
+	if err != nil {
+		return "", nil, err
+	}
+	if llm.ValidateJSON(answer) == nil {
+		if err := c.Put(key, model, mode, answer); err != nil {
+			slog.Warn("Failed to cache LLM answer", "error", err)
+		}
+	}
+	return answer, nil, nil
+}
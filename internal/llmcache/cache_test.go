@@ -0,0 +1,130 @@
+package llmcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+func testSnapshot(at time.Time) *snapshot.Snapshot {
+	return &snapshot.Snapshot{
+		GeneratedAt: at,
+		ProblemPods: []snapshot.PodSnapshot{{Namespace: "prod", Name: "api-0", Phase: "Running", Restarts: 7}},
+	}
+}
+
+func TestKey_IgnoresCollectionTime(t *testing.T) {
+	opts := Options{Provider: "openai", Model: "mixtral", Mode: "incident", PromptBudget: 8000}
+	a, err := Key(testSnapshot(time.Unix(100, 0)), opts)
+	require.NoError(t, err)
+	b, err := Key(testSnapshot(time.Unix(200, 0)), opts)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.Len(t, a, 64)
+}
+
+func TestKey_ChangesWithSnapshotAndOptions(t *testing.T) {
+	opts := Options{Provider: "openai", Model: "mixtral", Mode: "incident"}
+	base, err := Key(testSnapshot(time.Time{}), opts)
+	require.NoError(t, err)
+
+	changed := testSnapshot(time.Time{})
+	changed.ProblemPods[0].Restarts = 8
+	k, err := Key(changed, opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, base, k, "snapshot change")
+
+	variants := []Options{
+		{Provider: "openai", Model: "gpt-4o", Mode: "incident"},
+		{Provider: "openai", Model: "mixtral", Mode: "default"},
+		{Provider: "openai", Model: "mixtral", Mode: "incident", Hint: "memory leak"},
+		{Provider: "openai", Model: "mixtral", Mode: "incident", Enhancements: prompt.PromptEnhancements{Priority: true}},
+		{Provider: "openai", Model: "mixtral", Mode: "incident",
+			Enhancements: prompt.PromptEnhancements{Templates: prompt.Templates{"incident": "{{SNAPSHOT}}"}}},
+		{Provider: "openai", Model: "mixtral", Mode: "incident", PromptBudget: 4000},
+	}
+	for _, v := range variants {
+		k, err := Key(testSnapshot(time.Time{}), v)
+		require.NoError(t, err)
+		assert.NotEqual(t, base, k, "%+v", v)
+	}
+}
+
+func TestCache_PutGet(t *testing.T) {
+	c := New(t.TempDir(), time.Minute)
+	_, ok := c.Get("abc")
+	assert.False(t, ok)
+
+	require.NoError(t, c.Put("abc", "mixtral", "incident", `{"summary":"ok"}`))
+	e, ok := c.Get("abc")
+	require.True(t, ok)
+	assert.Equal(t, `{"summary":"ok"}`, e.Response)
+	assert.Equal(t, "incident", e.Mode)
+	assert.Equal(t, "mixtral", e.Model)
+}
+
+func TestCache_Complete(t *testing.T) {
+	c := New(t.TempDir(), time.Minute)
+	calls := 0
+	complete := func(answer string) func() (string, error) {
+		return func() (string, error) {
+			calls++
+			return answer, nil
+		}
+	}
+
+	answer, hit, err := c.Complete("abc", "mixtral", "default", complete(`{"summary":"ok"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"summary":"ok"}`, answer)
+	assert.Nil(t, hit)
+
+	answer, hit, err = c.Complete("abc", "mixtral", "default", complete("unused"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"summary":"ok"}`, answer)
+	require.NotNil(t, hit)
+	assert.Equal(t, "mixtral", hit.Model)
+	assert.Equal(t, 1, calls)
+
+	// Answers that are not JSON are not cached
+	for i := 0; i < 2; i++ {
+		answer, _, err = c.Complete("prose", "mixtral", "default", complete("no JSON here"))
+		require.NoError(t, err)
+		assert.Equal(t, "no JSON here", answer)
+	}
+	assert.Equal(t, 3, calls)
+
+	_, _, err = c.Complete("failed", "mixtral", "default", func() (string, error) { return "", errors.New("boom") })
+	require.EqualError(t, err, "boom")
+	_, ok := c.Get("failed")
+	assert.False(t, ok)
+}
+
+func TestCache_Expires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := New(t.TempDir(), time.Minute)
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Put("abc", "mixtral", "incident", "{}"))
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("abc")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Get("abc")
+	assert.False(t, ok)
+
+	// The expired entry was removed
+	now = now.Add(-time.Minute)
+	_, ok = c.Get("abc")
+	assert.False(t, ok)
+}
+
+func TestNew_DefaultTTL(t *testing.T) {
+	assert.Equal(t, DefaultTTL, New(t.TempDir(), 0).ttl)
+}
@@ -0,0 +1,214 @@
+// Package logging configures the process-wide slog logger that carries
+// kubenow's progress and diagnostic output on stderr. The text format
+// prints each message on its own line, prefixed with its component, as
+// kubenow always has; the JSON format writes one object per record, with
+// attributes such as namespace, step, and total, for CI log processors.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Log formats accepted by --log-format.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Attribute keys shared by every component.
+const (
+	KeyComponent = "component" // subsystem, shown as the [prefix] in text
+	KeyEvent     = "event"     // EventProgress for progress records
+	KeyStep      = "step"      // current step, for progress records
+	KeyTotal     = "total"     // steps planned, for progress records
+)
+
+// EventProgress marks records that report progress through a run.
+const EventProgress = "progress"
+
+// DefaultComponent prefixes records that name no component.
+const DefaultComponent = "kubenow"
+
+// levelNames maps --log-level values to levels.
+var levelNames = map[string]slog.Level{
+	"debug":   slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"warn":    slog.LevelWarn,
+	"warning": slog.LevelWarn,
+	"error":   slog.LevelError,
+}
+
+// ParseLevel validates a --log-level value.
+func ParseLevel(s string) (slog.Level, error) {
+	level, ok := levelNames[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("invalid log level %q: must be debug, info, warn, or error", s)
+	}
+	return level, nil
+}
+
+// ParseFormat validates a --log-format value.
+func ParseFormat(s string) (string, error) {
+	switch f := strings.ToLower(s); f {
+	case FormatText, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid log format %q: must be text or json", s)
+	}
+}
+
+// NewHandler returns a handler writing records at or above level to w in
+// the given format.
+func NewHandler(w io.Writer, level slog.Leveler, format string) slog.Handler {
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	}
+	return &textHandler{w: w, mu: &sync.Mutex{}, level: level}
+}
+
+// level is the configured minimum level, shared by the default logger and
+// handlers built on Level.
+var level = new(slog.LevelVar)
+
+// Level returns the level set by Setup, for handlers that write somewhere
+// other than stderr, such as a TUI's progress sink.
+func Level() slog.Leveler {
+	return level
+}
+
+// Setup installs the default logger, writing to stderr.
+func Setup(l slog.Level, format string) {
+	level.Set(l)
+	slog.SetDefault(slog.New(NewHandler(os.Stderr, level, format)))
+}
+
+// Component returns the default logger for one subsystem, e.g. "latch".
+// Call it when logging rather than once at init, so it picks up Setup.
+func Component(name string) *slog.Logger {
+	return slog.Default().With(KeyComponent, name)
+}
+
+// Progress logs a progress event for step (1-based) of total.
+func Progress(l *slog.Logger, msg string, step, total int, args ...any) {
+	l.Info(msg, append([]any{KeyEvent, EventProgress, KeyStep, step, KeyTotal, total}, args...)...)
+}
+
+// FuncWriter adapts a per-line callback, such as a TUI's progress sink, to
+// the io.Writer a text handler writes to. Each Write is one line.
+type FuncWriter func(line string)
+
+func (f FuncWriter) Write(p []byte) (int, error) {
+	f(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// textHandler writes "[component] message key=value ..." lines. Warnings
+// and errors get a "Warning: " or "Error: " prefix, and progress events
+// their step/total counters.
+type textHandler struct {
+	w         io.Writer
+	mu        *sync.Mutex
+	level     slog.Leveler
+	component string
+	attrs     []slog.Attr // from WithAttrs, other than the component
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := *h
+	out.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if a.Key == KeyComponent {
+			out.component = a.Value.String()
+		} else {
+			out.attrs = append(out.attrs, a)
+		}
+	}
+	return &out
+}
+
+func (h *textHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	component := h.component
+	var event string
+	var step, total int64
+	attrs := h.attrs
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case KeyComponent:
+			component = a.Value.String()
+		case KeyEvent:
+			event = a.Value.String()
+		case KeyStep:
+			if a.Value.Kind() == slog.KindInt64 {
+				step = a.Value.Int64()
+			}
+		case KeyTotal:
+			if a.Value.Kind() == slog.KindInt64 {
+				total = a.Value.Int64()
+			}
+		default:
+			attrs = append(attrs, a)
+		}
+		return true
+	})
+	if component == "" {
+		component = DefaultComponent
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + component + "] ")
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	}
+	if event == EventProgress && total > 0 {
+		fmt.Fprintf(&b, "[%d/%d] ", step, total)
+	}
+	b.WriteString(r.Message)
+	for _, a := range attrs {
+		writeAttr(&b, "", a)
+	}
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// writeAttr appends " key=value", flattening groups into dotted keys and
+// quoting values that contain spaces, quotes, or "=".
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := prefix + a.Key
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, key+".", ga)
+		}
+		return
+	}
+	value := a.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(" " + key + "=" + value)
+}
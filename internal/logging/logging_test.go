@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	level, err = ParseLevel("debug")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("JSON")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestTextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(NewHandler(&buf, slog.LevelInfo, FormatText))
+
+	l.Info("Discovering namespaces...")
+	l.With(KeyComponent, "latch", "namespace", "shop").Warn("Sample failed", "error", errors.New("read: timeout"))
+	Progress(l, "Analyzing namespace", 3, 10, "namespace", "shop")
+	l.Debug("hidden")
+	l.Error("boom", KeyComponent, "collect", slog.Group("pods", "total", 4), "path", "")
+
+	assert.Equal(t, strings.Join([]string{
+		"[kubenow] Discovering namespaces...",
+		`[latch] Warning: Sample failed namespace=shop error="read: timeout"`,
+		"[kubenow] [3/10] Analyzing namespace namespace=shop",
+		`[collect] Error: boom pods.total=4 path=""`,
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestJSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(NewHandler(&buf, slog.LevelDebug, FormatJSON)).With(KeyComponent, "analyzer")
+
+	Progress(l, "Analyzing namespace shop", 3, 10, "namespace", "shop")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "Analyzing namespace shop", record["msg"])
+	assert.Equal(t, "analyzer", record[KeyComponent])
+	assert.Equal(t, EventProgress, record[KeyEvent])
+	assert.InDelta(t, 3, record[KeyStep], 0)
+	assert.InDelta(t, 10, record[KeyTotal], 0)
+	assert.Equal(t, "shop", record["namespace"])
+}
+
+func TestFuncWriter(t *testing.T) {
+	var lines []string
+	l := slog.New(NewHandler(FuncWriter(func(line string) { lines = append(lines, line) }), slog.LevelInfo, FormatText))
+	l.Info("one", KeyComponent, "latch")
+	l.Info("two")
+	assert.Equal(t, []string{"[latch] one", "[kubenow] two"}, lines)
+}
+
+func TestSetup_Level(t *testing.T) {
+	defer Setup(slog.LevelInfo, FormatText)
+
+	var lines []string
+	l := slog.New(NewHandler(FuncWriter(func(line string) { lines = append(lines, line) }), Level(), FormatText))
+	Setup(slog.LevelWarn, FormatText)
+	l.Info("hidden")
+	l.Warn("shown")
+	Setup(slog.LevelDebug, FormatText)
+	l.Debug("debug")
+	assert.Equal(t, []string{"[kubenow] Warning: shown", "[kubenow] debug"}, lines)
+}
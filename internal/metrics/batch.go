@@ -0,0 +1,236 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/ppiankov/kubenow/internal/promql"
+)
+
+// BatchUsageProvider is implemented by providers that fetch the usage data
+// of every workload in a namespace in a few grouped queries instead of six
+// queries per workload.
+type BatchUsageProvider interface {
+	NamespaceWorkloadUsage(ctx context.Context, namespace string, window time.Duration) (*WorkloadUsageBatch, error)
+}
+
+// WorkloadUsageBatch holds a namespace's per-pod CPU and memory series,
+// requests, limits, and pod ownership, and derives each workload's usage
+// from them client-side, selecting pods the way the per-workload queries do.
+type WorkloadUsageBatch struct {
+	Namespace string
+
+	owners   promql.OwnerMetrics
+	cpu      map[string][]model.SamplePair // pod -> CPU cores per step
+	memory   map[string][]model.SamplePair // pod -> working-set bytes per step
+	requests map[string]podResources
+	limits   map[string]podResources
+	podOwner map[string][]ownerRef // pod -> owners within the window
+	rsOwner  map[string][]ownerRef // replicaset -> owners within the window
+}
+
+type podResources struct {
+	cpu    float64
+	memory float64
+}
+
+type ownerRef struct {
+	kind string
+	name string
+}
+
+// NamespaceWorkloadUsage fetches the data WorkloadUsageBatch.Usage derives
+// workload usage from: CPU and memory range queries grouped by pod, requests
+// and limits grouped by pod and resource, and, when kube-state-metrics
+// exports them, the owners of the window's pods and ReplicaSets.
+func (p *PrometheusClient) NamespaceWorkloadUsage(ctx context.Context, namespace string, window time.Duration) (*WorkloadUsageBatch, error) {
+	end := time.Now()
+	start := end.Add(-window)
+	step := adaptiveStep(window, 1000)
+	qb := p.workloadBuilder(ctx)
+
+	b := &WorkloadUsageBatch{
+		Namespace: namespace,
+		owners:    qb.Owners(),
+		podOwner:  make(map[string][]ownerRef),
+		rsOwner:   make(map[string][]ownerRef),
+	}
+
+	var err error
+	if b.cpu, err = p.podSeries(ctx, qb.NamespacePodCPUUsage(namespace), start, end, step); err != nil {
+		return nil, fmt.Errorf("CPU usage by pod: %w", err)
+	}
+	if b.memory, err = p.podSeries(ctx, qb.NamespacePodMemoryUsage(namespace), start, end, step); err != nil {
+		return nil, fmt.Errorf("memory usage by pod: %w", err)
+	}
+	if b.requests, err = p.podResources(ctx, qb.NamespacePodRequests(namespace), end); err != nil {
+		return nil, fmt.Errorf("requests by pod: %w", err)
+	}
+	if b.limits, err = p.podResources(ctx, qb.NamespacePodLimits(namespace), end); err != nil {
+		return nil, fmt.Errorf("limits by pod: %w", err)
+	}
+	if b.owners.PodOwner {
+		if err := p.ownerRefs(ctx, qb.NamespacePodOwners(namespace, window), "pod", end, b.podOwner); err != nil {
+			return nil, fmt.Errorf("pod owners: %w", err)
+		}
+	}
+	if b.owners.PodOwner && b.owners.ReplicaSetOwner {
+		if err := p.ownerRefs(ctx, qb.NamespaceReplicaSetOwners(namespace, window), "replicaset", end, b.rsOwner); err != nil {
+			return nil, fmt.Errorf("replicaset owners: %w", err)
+		}
+	}
+	return b, nil
+}
+
+func (p *PrometheusClient) podSeries(ctx context.Context, query string, start, end time.Time, step time.Duration) (map[string][]model.SamplePair, error) {
+	matrix, err := p.QueryRange(ctx, query, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	series := make(map[string][]model.SamplePair, len(matrix))
+	for _, stream := range matrix {
+		if pod := string(stream.Metric["pod"]); pod != "" {
+			series[pod] = stream.Values
+		}
+	}
+	return series, nil
+}
+
+func (p *PrometheusClient) podResources(ctx context.Context, query string, ts time.Time) (map[string]podResources, error) {
+	vector, err := p.QueryInstant(ctx, query, ts)
+	if err != nil {
+		return nil, err
+	}
+	resources := make(map[string]podResources, len(vector))
+	for _, sample := range vector {
+		pod := string(sample.Metric["pod"])
+		r := resources[pod]
+		switch sample.Metric["resource"] {
+		case "cpu":
+			r.cpu += float64(sample.Value)
+		case "memory":
+			r.memory += float64(sample.Value)
+		}
+		resources[pod] = r
+	}
+	return resources, nil
+}
+
+func (p *PrometheusClient) ownerRefs(ctx context.Context, query, nameLabel string, ts time.Time, into map[string][]ownerRef) error {
+	vector, err := p.QueryInstant(ctx, query, ts)
+	if err != nil {
+		return err
+	}
+	for _, sample := range vector {
+		name := string(sample.Metric[model.LabelName(nameLabel)])
+		into[name] = append(into[name], ownerRef{kind: string(sample.Metric["owner_kind"]), name: string(sample.Metric["owner_name"])})
+	}
+	return nil
+}
+
+// Usage returns a workload's usage from the batch. It reports false for
+// Jobs and CronJobs, whose usage counts Running pods only and is queried
+// per workload.
+func (b *WorkloadUsageBatch) Usage(workloadName, workloadType string) (*WorkloadUsage, bool) {
+	if IsBatchKind(workloadType) {
+		return nil, false
+	}
+	selected := b.podSelector(workloadName, workloadType)
+
+	usage := &WorkloadUsage{
+		WorkloadName: workloadName,
+		Namespace:    b.Namespace,
+		WorkloadType: workloadType,
+	}
+	if cpu := sumPodSeries(b.cpu, selected); len(cpu) > 0 {
+		usage.CPUAvg = calculateAverage(cpu)
+		usage.CPUP95 = calculatePercentile(cpu, 0.95)
+		usage.CPUP99 = calculatePercentile(cpu, 0.99)
+		usage.CPUMax = calculateMax(cpu)
+	}
+	if mem := sumPodSeries(b.memory, selected); len(mem) > 0 {
+		usage.MemoryAvg = calculateAverage(mem)
+		usage.MemoryP95 = calculatePercentile(mem, 0.95)
+		usage.MemoryP99 = calculatePercentile(mem, 0.99)
+		usage.MemoryMax = calculateMax(mem)
+	}
+	for pod, r := range b.requests {
+		if selected(pod) {
+			usage.CPURequested += r.cpu
+			usage.MemoryRequested += r.memory
+		}
+	}
+	for pod, r := range b.limits {
+		if selected(pod) {
+			usage.CPULimit += r.cpu
+			usage.MemoryLimit += r.memory
+		}
+	}
+
+	if usage.CPUAvg > 0 {
+		usage.CPUSkew = usage.CPURequested / usage.CPUAvg
+	}
+	if usage.MemoryAvg > 0 {
+		usage.MemorySkew = usage.MemoryRequested / usage.MemoryAvg
+	}
+	return usage, true
+}
+
+// podSelector mirrors promql.Builder.PodsOf: pods owned by the workload,
+// directly or through a ReplicaSet, when the owner metrics exist, and pods
+// matching the workload's name pattern otherwise.
+func (b *WorkloadUsageBatch) podSelector(workloadName, workloadType string) func(pod string) bool {
+	if workloadType == promql.KindPod {
+		return func(pod string) bool { return pod == workloadName }
+	}
+	if !b.owners.PodOwner || (workloadType == promql.KindDeployment && !b.owners.ReplicaSetOwner) {
+		// PromQL regex matchers are fully anchored
+		return regexp.MustCompile("^(?:" + promql.WorkloadPods(workloadName, workloadType).Value + ")$").MatchString
+	}
+
+	want := ownerRef{kind: workloadType, name: workloadName}
+	return func(pod string) bool {
+		for _, owner := range b.podOwner[pod] {
+			if owner == want {
+				return true
+			}
+			if owner.kind != "ReplicaSet" {
+				continue
+			}
+			for _, rsOwner := range b.rsOwner[owner.name] {
+				if rsOwner == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// sumPodSeries adds up the selected pods' series step by step, like sum()
+// over the pods in a range query.
+func sumPodSeries(series map[string][]model.SamplePair, selected func(pod string) bool) []model.SamplePair {
+	totals := make(map[model.Time]float64)
+	for pod, values := range series {
+		if !selected(pod) {
+			continue
+		}
+		for _, v := range values {
+			totals[v.Timestamp] += float64(v.Value)
+		}
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+	summed := make([]model.SamplePair, 0, len(totals))
+	for ts, v := range totals {
+		summed = append(summed, model.SamplePair{Timestamp: ts, Value: model.SampleValue(v)})
+	}
+	sort.Slice(summed, func(i, j int) bool { return summed[i].Timestamp < summed[j].Timestamp })
+	return summed
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchServer answers the grouped namespace queries for pods api-1 and
+// api-2 of Deployment api and api-gateway-1 of Deployment api-gateway.
+// withOwners controls whether the kube-state-metrics owner series exist.
+func batchServer(t *testing.T, withOwners bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var queries atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		sample := func(value float64, labels ...string) map[string]any {
+			metric := map[string]string{}
+			for i := 0; i+1 < len(labels); i += 2 {
+				metric[labels[i]] = labels[i+1]
+			}
+			return map[string]any{"metric": metric, "value": []any{1, strconv.FormatFloat(value, 'f', -1, 64)}}
+		}
+		series := func(pod string, values ...float64) map[string]any {
+			points := make([]any, len(values))
+			for i, v := range values {
+				points[i] = []any{1000 + 60*i, strconv.FormatFloat(v, 'f', -1, 64)}
+			}
+			return map[string]any{"metric": map[string]string{"pod": pod}, "values": points}
+		}
+
+		resultType, result := "vector", []any{}
+		switch {
+		case strings.HasPrefix(query, "count(kube_job_owner"):
+			result = append(result, sample(0))
+		case strings.HasPrefix(query, "count(kube_"):
+			if withOwners {
+				result = append(result, sample(1))
+			}
+		case strings.Contains(query, "container_cpu_usage_seconds_total"):
+			queries.Add(1)
+			resultType = "matrix"
+			result = append(result, series("api-1", 1, 2), series("api-2", 1, 1), series("api-gateway-1", 5, 5))
+		case strings.Contains(query, "container_memory_working_set_bytes"):
+			queries.Add(1)
+			resultType = "matrix"
+			result = append(result, series("api-1", 100, 100), series("api-2", 200), series("api-gateway-1", 900, 900))
+		case strings.Contains(query, "kube_pod_container_resource_requests"):
+			queries.Add(1)
+			result = append(result,
+				sample(0.5, "pod", "api-1", "resource", "cpu"), sample(256, "pod", "api-1", "resource", "memory"),
+				sample(0.5, "pod", "api-2", "resource", "cpu"), sample(4, "pod", "api-gateway-1", "resource", "cpu"))
+		case strings.Contains(query, "kube_pod_container_resource_limits"):
+			queries.Add(1)
+			result = append(result, sample(1, "pod", "api-1", "resource", "cpu"), sample(1, "pod", "api-2", "resource", "cpu"))
+		case strings.Contains(query, "kube_pod_owner"):
+			queries.Add(1)
+			result = append(result,
+				sample(1, "pod", "api-1", "owner_kind", "ReplicaSet", "owner_name", "api-7d9f"),
+				sample(1, "pod", "api-2", "owner_kind", "ReplicaSet", "owner_name", "api-6b7c"),
+				sample(1, "pod", "api-gateway-1", "owner_kind", "ReplicaSet", "owner_name", "api-gateway-5c4d"))
+		case strings.Contains(query, "kube_replicaset_owner"):
+			queries.Add(1)
+			result = append(result,
+				sample(1, "replicaset", "api-7d9f", "owner_kind", "Deployment", "owner_name", "api"),
+				sample(1, "replicaset", "api-6b7c", "owner_kind", "Deployment", "owner_name", "api"),
+				sample(1, "replicaset", "api-gateway-5c4d", "owner_kind", "Deployment", "owner_name", "api-gateway"))
+		default:
+			t.Errorf("unexpected query %q", query)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := json.Marshal(map[string]any{
+			"status": "success",
+			"data":   map[string]any{"resultType": resultType, "result": result},
+		})
+		_, _ = w.Write(data)
+	}))
+	return srv, &queries
+}
+
+func TestNamespaceWorkloadUsage_OwnerJoins(t *testing.T) {
+	srv, queries := batchServer(t, true)
+	defer srv.Close()
+	client, err := NewPrometheusClient(Config{PrometheusURL: srv.URL})
+	require.NoError(t, err)
+
+	batch, err := client.NamespaceWorkloadUsage(context.Background(), "prod", 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), queries.Load(), "CPU, memory, requests, limits, pod and ReplicaSet owners")
+
+	usage, ok := batch.Usage("api", "Deployment")
+	require.True(t, ok)
+	assert.InDelta(t, 2.5, usage.CPUAvg, 1e-9, "pods summed per step: 2 then 3")
+	assert.InDelta(t, 3, usage.CPUMax, 1e-9)
+	assert.InDelta(t, 200, usage.MemoryAvg, 1e-9, "api-2 has a point at the first step only: 300 then 100")
+	assert.InDelta(t, 1, usage.CPURequested, 1e-9)
+	assert.InDelta(t, 256, usage.MemoryRequested, 1e-9)
+	assert.InDelta(t, 2, usage.CPULimit, 1e-9)
+	assert.InDelta(t, 0.4, usage.CPUSkew, 1e-9)
+
+	gateway, ok := batch.Usage("api-gateway", "Deployment")
+	require.True(t, ok)
+	assert.InDelta(t, 5, gateway.CPUAvg, 1e-9)
+	assert.InDelta(t, 4, gateway.CPURequested, 1e-9)
+
+	missing, ok := batch.Usage("worker", "Deployment")
+	require.True(t, ok)
+	assert.Zero(t, missing.CPUAvg)
+	assert.Zero(t, missing.MemoryAvg)
+
+	_, ok = batch.Usage("nightly", "CronJob")
+	assert.False(t, ok, "batch kinds are queried per workload")
+}
+
+func TestNamespaceWorkloadUsage_NamePatterns(t *testing.T) {
+	srv, queries := batchServer(t, false)
+	defer srv.Close()
+	client, err := NewPrometheusClient(Config{PrometheusURL: srv.URL})
+	require.NoError(t, err)
+
+	batch, err := client.NamespaceWorkloadUsage(context.Background(), "prod", 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), queries.Load(), "no owner queries without owner metrics")
+
+	// Like the per-workload pod-name regex, "api-.*" also matches api-gateway-1
+	usage, ok := batch.Usage("api", "Deployment")
+	require.True(t, ok)
+	assert.InDelta(t, 7.5, usage.CPUAvg, 1e-9)
+	assert.InDelta(t, 5, usage.CPURequested, 1e-9)
+
+	pod, ok := batch.Usage("api-1", "Pod")
+	require.True(t, ok)
+	assert.InDelta(t, 1.5, pod.CPUAvg, 1e-9)
+}
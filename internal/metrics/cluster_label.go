@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/promql"
+)
+
+// DefaultClusterLabel is the label name assumed by --prometheus-cluster-label auto.
+const DefaultClusterLabel = "cluster"
+
+// clusterLabelAuto asks for the label value to be detected.
+const clusterLabelAuto = "auto"
+
+// maxDetectNodes bounds the node names used to match kube_node_info series.
+const maxDetectNodes = 5
+
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ClusterLabel scopes every generated query to one cluster on a shared
+// (Thanos, Mimir, federated) Prometheus, e.g. cluster="prod-eu".
+type ClusterLabel struct {
+	Name  string
+	Value string // empty until detected when Auto is set
+	Auto  bool
+}
+
+// ParseClusterLabel parses a --prometheus-cluster-label value: "key=value",
+// "key=auto" or "auto" (detect the value of the "cluster" label). An empty
+// spec returns the zero ClusterLabel, which scopes nothing.
+func ParseClusterLabel(spec string) (ClusterLabel, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return ClusterLabel{}, nil
+	}
+	if spec == clusterLabelAuto {
+		return ClusterLabel{Name: DefaultClusterLabel, Auto: true}, nil
+	}
+
+	name, value, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return ClusterLabel{}, fmt.Errorf("invalid cluster label %q: expected key=value or auto", spec)
+	}
+	if !labelNameRe.MatchString(name) {
+		return ClusterLabel{}, fmt.Errorf("invalid cluster label name %q", name)
+	}
+	if value == clusterLabelAuto {
+		return ClusterLabel{Name: name, Auto: true}, nil
+	}
+	return ClusterLabel{Name: name, Value: value}, nil
+}
+
+// IsSet reports whether the label scopes queries (or needs detection).
+func (c ClusterLabel) IsSet() bool {
+	return c.Name != ""
+}
+
+// String renders the label as a matcher, e.g. cluster="prod".
+func (c ClusterLabel) String() string {
+	return promql.Equal(c.Name, c.Value).String()
+}
+
+// QueryOptions returns the builder options that inject the label matcher.
+func (c ClusterLabel) QueryOptions() []promql.Option {
+	if c.Name == "" || c.Value == "" {
+		return nil
+	}
+	return []promql.Option{promql.WithCluster(c.Name, c.Value)}
+}
+
+// DetectClusterLabel finds the value of the named cluster label for the
+// current cluster by matching this cluster's node names against
+// kube_node_info series. When the nodes match several values (reused node
+// names across clusters), the server's own external label decides. An empty
+// result with no error means the series carry no such label, so there is
+// nothing to scope.
+func DetectClusterLabel(ctx context.Context, provider MetricsProvider, name string, nodeNames []string) (string, error) {
+	if len(nodeNames) == 0 {
+		return "", fmt.Errorf("no nodes to match against kube_node_info")
+	}
+	if len(nodeNames) > maxDetectNodes {
+		nodeNames = nodeNames[:maxDetectNodes]
+	}
+	patterns := make([]string, len(nodeNames))
+	for i, n := range nodeNames {
+		patterns[i] = promql.QuoteRegexLiteral(n)
+	}
+	query := promql.Count(promql.Select("kube_node_info", promql.Regex("node", strings.Join(patterns, "|"))).String(), name)
+
+	vec, err := provider.QueryInstant(ctx, query, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to query kube_node_info: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, s := range vec {
+		if v := string(s.Metric[model.LabelName(name)]); v != "" {
+			seen[v] = true
+		}
+	}
+	values := make([]string, 0, len(seen))
+	for v := range seen {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	switch len(values) {
+	case 0:
+		return "", nil
+	case 1:
+		return values[0], nil
+	}
+
+	// Several clusters share node names: prefer the server's own external label.
+	if apiProvider, ok := provider.(APIProvider); ok {
+		if cfg, err := apiProvider.GetAPI().Config(ctx); err == nil {
+			if own := externalLabel(cfg.YAML, name); seen[own] {
+				return own, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("this cluster's nodes match several %q values (%s); set --prometheus-cluster-label %s=<value>",
+		name, strings.Join(values, ", "), name)
+}
+
+// externalLabel extracts global.external_labels[name] from a Prometheus config.
+func externalLabel(configYAML, name string) string {
+	var cfg struct {
+		Global struct {
+			ExternalLabels map[string]string `yaml:"external_labels"`
+		} `yaml:"global"`
+	}
+	if err := yaml.Unmarshal([]byte(configYAML), &cfg); err != nil {
+		return ""
+	}
+	return cfg.Global.ExternalLabels[name]
+}
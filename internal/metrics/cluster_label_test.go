@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClusterLabel(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ClusterLabel
+		wantErr bool
+	}{
+		{"", ClusterLabel{}, false},
+		{"cluster=prod-eu", ClusterLabel{Name: "cluster", Value: "prod-eu"}, false},
+		{" k8s_cluster = prod ", ClusterLabel{Name: "k8s_cluster", Value: "prod"}, false},
+		{"auto", ClusterLabel{Name: DefaultClusterLabel, Auto: true}, false},
+		{"region=auto", ClusterLabel{Name: "region", Auto: true}, false},
+		{"cluster", ClusterLabel{}, true},
+		{"cluster=", ClusterLabel{}, true},
+		{"bad-name=prod", ClusterLabel{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseClusterLabel(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClusterLabel_InjectedIntoQueries(t *testing.T) {
+	label, err := ParseClusterLabel("cluster=prod")
+	require.NoError(t, err)
+
+	qb := NewQueryBuilder(label.QueryOptions()...)
+	for _, q := range []string{
+		qb.WorkloadCPUUsage("default", "api", "Deployment"),
+		qb.WorkloadMemoryRequests("default", "api", "Deployment"),
+		qb.NodeCPUCapacity(),
+		qb.ClusterMemoryUsage(),
+		qb.RestartsByWorkload("default", "api", 0),
+	} {
+		assert.Contains(t, q, `cluster="prod"`, q)
+	}
+
+	assert.Nil(t, ClusterLabel{Name: "cluster", Auto: true}.QueryOptions(), "undetected auto label must not scope")
+}
+
+// clusterLabelServer answers kube_node_info queries with one series per
+// cluster value and serves a Prometheus config with the given external label.
+func clusterLabelServer(t *testing.T, clusters []string, ownCluster string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/status/config":
+			yaml := "global:\n  external_labels:\n    cluster: " + ownCluster + "\n"
+			data, _ := json.Marshal(map[string]any{"status": "success", "data": map[string]string{"yaml": yaml}})
+			_, _ = w.Write(data)
+		default:
+			result := make([]map[string]any, 0, len(clusters))
+			for _, c := range clusters {
+				result = append(result, map[string]any{"metric": map[string]string{"cluster": c}, "value": []any{1, "3"}})
+			}
+			data, _ := json.Marshal(map[string]any{
+				"status": "success",
+				"data":   map[string]any{"resultType": "vector", "result": result},
+			})
+			_, _ = w.Write(data)
+		}
+	}))
+}
+
+func TestDetectClusterLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		clusters []string
+		own      string
+		want     string
+		wantErr  bool
+	}{
+		{"single match", []string{"prod-eu"}, "", "prod-eu", false},
+		{"unlabeled series", nil, "", "", false},
+		{"ambiguous resolved by external label", []string{"prod-eu", "prod-us"}, "prod-us", "prod-us", false},
+		{"ambiguous", []string{"prod-eu", "prod-us"}, "other", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := clusterLabelServer(t, tt.clusters, tt.own)
+			defer srv.Close()
+
+			provider, err := NewProvider(Config{PrometheusURL: srv.URL})
+			require.NoError(t, err)
+
+			got, err := DetectClusterLabel(context.Background(), provider, "cluster", []string{"node-1", "node-2"})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectClusterLabel_NoNodes(t *testing.T) {
+	_, err := DetectClusterLabel(context.Background(), NewMockMetrics(), "cluster", nil)
+	assert.Error(t, err)
+}
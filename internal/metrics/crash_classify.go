@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// CrashClass is a machine-readable crash pattern derived from exit codes and
+// the spike timing around each termination.
+type CrashClass string
+
+const (
+	// CrashOOMSpike is an OOMKill right after a memory spike: demand bursts
+	// above the limit, so more headroom fixes it.
+	CrashOOMSpike CrashClass = "oom-memory-spike"
+	// CrashOOMGrowth is an OOMKill after memory climbed steadily: likely a
+	// leak, so more memory only delays the next kill.
+	CrashOOMGrowth CrashClass = "oom-memory-growth"
+	// CrashOOMFlat is an OOMKill with memory flat near its peak: the limit is
+	// below the working set.
+	CrashOOMFlat CrashClass = "oom-at-limit"
+	// CrashOOMUntimed is an OOMKill outside the sampled window.
+	CrashOOMUntimed CrashClass = "oom-untimed"
+	// CrashNative is a native crash (SIGSEGV, SIGABRT, SIGBUS).
+	CrashNative CrashClass = "native-crash"
+	// CrashExternalKill is a SIGKILL that was not an OOMKill (failed
+	// liveness probe, preemption, node pressure).
+	CrashExternalKill CrashClass = "external-kill"
+	// CrashAppError is any other non-zero exit.
+	CrashAppError CrashClass = "app-error"
+)
+
+// crashClassPriority orders classes when a workload crashed in several ways;
+// the first class present becomes the workload's classification.
+var crashClassPriority = []CrashClass{
+	CrashOOMSpike, CrashOOMGrowth, CrashOOMFlat, CrashOOMUntimed,
+	CrashNative, CrashExternalKill, CrashAppError,
+}
+
+const (
+	// Samples before a termination that count as "right before" it, and
+	// the samples before those that form the baseline a spike is measured
+	// against.
+	crashLookbackSamples = 3
+	crashBaselineSamples = 12
+
+	// Peak/baseline ratios that count as a spike before a crash.
+	crashMemorySpikeRatio = 1.3
+	crashCPUSpikeRatio    = 2.0
+
+	// Late/early memory ratio that counts as steady growth.
+	crashGrowthRatio = 1.25
+
+	// Fewest samples before the kill needed to judge growth.
+	crashMinGrowthSamples = 8
+)
+
+// Termination is one container termination seen during monitoring.
+type Termination struct {
+	Container  string    `json:"container"`
+	Reason     string    `json:"reason"`
+	ExitCode   int       `json:"exit_code"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// CrashClassification is the dominant crash pattern of a workload.
+type CrashClassification struct {
+	Class          CrashClass `json:"class"`
+	ExitCode       int        `json:"exit_code"`
+	Container      string     `json:"container"`
+	Count          int        `json:"count"`           // terminations with this class
+	LoadCorrelated bool       `json:"load_correlated"` // the crash followed a CPU or memory spike
+	Evidence       string     `json:"evidence"`
+}
+
+// ClassifyCrash classifies the workload's terminations by correlating each
+// exit code with the samples taken right before it. Returns nil when no
+// termination was a crash (clean exits and SIGTERM are ignored).
+func ClassifyCrash(data *SpikeData, interval time.Duration) *CrashClassification {
+	if data == nil {
+		return nil
+	}
+	byClass := make(map[CrashClass]*CrashClassification)
+	for _, t := range data.Terminations {
+		c := classifyTermination(data, t, interval)
+		if c == nil {
+			continue
+		}
+		if existing, ok := byClass[c.Class]; ok {
+			existing.Count++
+			continue
+		}
+		c.Count = 1
+		byClass[c.Class] = c
+	}
+	for _, class := range crashClassPriority {
+		if c, ok := byClass[class]; ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// classifyTermination classifies one termination, or returns nil for a
+// clean exit.
+func classifyTermination(data *SpikeData, t Termination, interval time.Duration) *CrashClassification {
+	if t.ExitCode == 0 || t.ExitCode == 143 {
+		return nil
+	}
+	c := &CrashClassification{ExitCode: t.ExitCode, Container: t.Container}
+	idx := sampleIndexAt(data, t.FinishedAt, interval)
+	memRatio := spikeRatioBefore(data.MemSamples, idx, data.AvgMemory)
+	cpuRatio := spikeRatioBefore(data.CPUSamples, idx, data.AvgCPU)
+
+	switch {
+	case t.Reason == "OOMKilled":
+		switch {
+		case idx < 0:
+			c.Class = CrashOOMUntimed
+			c.Evidence = "OOMKilled outside the sampled window"
+		case memRatio >= crashMemorySpikeRatio:
+			c.Class = CrashOOMSpike
+			c.LoadCorrelated = true
+			c.Evidence = fmt.Sprintf("OOMKilled with memory at %.1fx its baseline just before the kill", memRatio)
+		case memoryGrowth(data.MemSamples[:idx+1]) >= crashGrowthRatio:
+			c.Class = CrashOOMGrowth
+			c.Evidence = fmt.Sprintf("OOMKilled after memory grew %.1fx without a spike", memoryGrowth(data.MemSamples[:idx+1]))
+		default:
+			c.Class = CrashOOMFlat
+			c.Evidence = "OOMKilled with memory flat near its peak"
+		}
+		return c
+	case t.ExitCode == 134 || t.ExitCode == 135 || t.ExitCode == 139:
+		c.Class = CrashNative
+	case t.ExitCode == 137:
+		c.Class = CrashExternalKill
+	default:
+		c.Class = CrashAppError
+	}
+
+	meaning := getExitCodeMeaning(t.ExitCode)
+	switch {
+	case idx < 0:
+		c.Evidence = fmt.Sprintf("exit %d (%s) outside the sampled window", t.ExitCode, meaning)
+	case cpuRatio >= crashCPUSpikeRatio || memRatio >= crashMemorySpikeRatio:
+		c.LoadCorrelated = true
+		c.Evidence = fmt.Sprintf("exit %d (%s) after a load spike (CPU %.1fx, memory %.1fx baseline)",
+			t.ExitCode, meaning, cpuRatio, memRatio)
+	default:
+		c.Evidence = fmt.Sprintf("exit %d (%s) independent of load", t.ExitCode, meaning)
+	}
+	return c
+}
+
+// sampleIndexAt returns the index of the last sample taken at or before t,
+// or -1 when t falls outside the retained samples. Samples carry no
+// timestamps, so the index is counted back from LastSeen at the interval,
+// which cannot reach across the pause of a resumed latch.
+func sampleIndexAt(data *SpikeData, t time.Time, interval time.Duration) int {
+	n := len(data.MemSamples)
+	if n == 0 || interval <= 0 || t.Before(data.FirstSeen) || t.After(data.LastSeen.Add(interval)) {
+		return -1
+	}
+	if !data.ResumedAt.IsZero() && t.Before(data.ResumedAt) {
+		return -1
+	}
+	back := 0
+	if t.Before(data.LastSeen) {
+		back = int((data.LastSeen.Sub(t) + interval - 1) / interval)
+	}
+	idx := n - 1 - back
+	if idx < 0 || idx >= len(data.CPUSamples) {
+		return -1
+	}
+	return idx
+}
+
+// spikeRatioBefore returns the highest of the samples right before idx
+// divided by the mean of the samples preceding them. A steady climb keeps
+// the ratio near 1, so growth is not mistaken for a spike. Falls back to avg
+// early in the window; returns 0 when there is nothing to compare.
+func spikeRatioBefore(samples []float64, idx int, avg float64) float64 {
+	if idx < 0 || idx >= len(samples) {
+		return 0
+	}
+	start := max(0, idx-crashLookbackSamples+1)
+	peak := 0.0
+	for _, v := range samples[start : idx+1] {
+		peak = max(peak, v)
+	}
+	baseline := avg
+	if start > 0 {
+		baseline = calculateFloatAverage(samples[max(0, start-crashBaselineSamples):start])
+	}
+	if baseline <= 0 {
+		return 0
+	}
+	return peak / baseline
+}
+
+// memoryGrowth returns the mean of the last quarter of samples divided by
+// the mean of the first quarter, or 0 when there are too few samples.
+func memoryGrowth(samples []float64) float64 {
+	if len(samples) < crashMinGrowthSamples {
+		return 0
+	}
+	quarter := len(samples) / 4
+	early := calculateFloatAverage(samples[:quarter])
+	if early <= 0 {
+		return 0
+	}
+	return calculateFloatAverage(samples[len(samples)-quarter:]) / early
+}
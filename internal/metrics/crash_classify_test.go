@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crashData builds spike data with one sample per interval ending at last.
+func crashData(last time.Time, mem, cpu []float64) *SpikeData {
+	interval := 5 * time.Second
+	return &SpikeData{
+		FirstSeen:  last.Add(-time.Duration(len(mem)-1) * interval),
+		LastSeen:   last,
+		MemSamples: mem,
+		CPUSamples: cpu,
+		AvgMemory:  calculateFloatAverage(mem),
+		AvgCPU:     calculateFloatAverage(cpu),
+	}
+}
+
+func flat(n int, v float64) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+func TestClassifyCrash(t *testing.T) {
+	const interval = 5 * time.Second
+	last := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Kill 5 samples before the end of the window.
+	killAt := last.Add(-5 * interval)
+
+	spiky := flat(40, 100)
+	spiky[33], spiky[34] = 180, 200
+	growing := make([]float64, 40)
+	for i := range growing {
+		growing[i] = 100 + float64(i)*5
+	}
+	cpuSpike := flat(40, 0.2)
+	cpuSpike[34] = 1.5
+
+	tests := []struct {
+		name     string
+		data     *SpikeData
+		term     Termination
+		class    CrashClass
+		loadCorr bool
+	}{
+		{"oom after spike", crashData(last, spiky, flat(40, 0.2)),
+			Termination{Reason: "OOMKilled", ExitCode: 137, FinishedAt: killAt}, CrashOOMSpike, true},
+		{"oom after growth", crashData(last, growing, flat(40, 0.2)),
+			Termination{Reason: "OOMKilled", ExitCode: 137, FinishedAt: killAt}, CrashOOMGrowth, false},
+		{"oom flat", crashData(last, flat(40, 100), flat(40, 0.2)),
+			Termination{Reason: "OOMKilled", ExitCode: 137, FinishedAt: killAt}, CrashOOMFlat, false},
+		{"oom before window", crashData(last, spiky, flat(40, 0.2)),
+			Termination{Reason: "OOMKilled", ExitCode: 137, FinishedAt: last.Add(-time.Hour)}, CrashOOMUntimed, false},
+		{"segfault independent of load", crashData(last, flat(40, 100), flat(40, 0.2)),
+			Termination{Reason: "Error", ExitCode: 139, FinishedAt: killAt}, CrashNative, false},
+		{"sigkill under cpu load", crashData(last, flat(40, 100), cpuSpike),
+			Termination{Reason: "Error", ExitCode: 137, FinishedAt: killAt}, CrashExternalKill, true},
+		{"app error", crashData(last, flat(40, 100), flat(40, 0.2)),
+			Termination{Reason: "Error", ExitCode: 1, FinishedAt: killAt}, CrashAppError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data.Terminations = []Termination{tt.term}
+			c := ClassifyCrash(tt.data, interval)
+			require.NotNil(t, c)
+			assert.Equal(t, tt.class, c.Class)
+			assert.Equal(t, tt.loadCorr, c.LoadCorrelated)
+			assert.Equal(t, 1, c.Count)
+			assert.NotEmpty(t, c.Evidence)
+		})
+	}
+}
+
+func TestClassifyCrash_Priority(t *testing.T) {
+	last := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	data := crashData(last, flat(40, 100), flat(40, 0.2))
+	data.Terminations = []Termination{
+		{Container: "app", Reason: "Error", ExitCode: 1, FinishedAt: last},
+		{Container: "app", Reason: "Error", ExitCode: 139, FinishedAt: last},
+		{Container: "sidecar", Reason: "Error", ExitCode: 139, FinishedAt: last},
+		{Container: "app", Reason: "Completed", ExitCode: 0, FinishedAt: last},
+	}
+	c := ClassifyCrash(data, 5*time.Second)
+	require.NotNil(t, c)
+	assert.Equal(t, CrashNative, c.Class)
+	assert.Equal(t, 2, c.Count)
+
+	data.Terminations = []Termination{{Reason: "Completed"}, {Reason: "Error", ExitCode: 143}}
+	assert.Nil(t, ClassifyCrash(data, 5*time.Second), "clean exits are not crashes")
+	assert.Nil(t, ClassifyCrash(nil, 5*time.Second))
+}
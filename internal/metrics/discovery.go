@@ -9,6 +9,8 @@ import (
 	"time"
 
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/ppiankov/kubenow/internal/promql"
 )
 
 // MetricDiscovery detects available metrics in Prometheus
@@ -127,27 +129,25 @@ func (m *AvailableMetrics) ValidateMetrics() error {
 
 // GetCPUQuery builds a CPU query with the best available metric
 func (m *AvailableMetrics) GetCPUQuery(namespace, workload, _ string) string {
-	ns := escapeLabel(namespace)
-	pod := escapeRegex(workload, "-.*")
+	matchers := []promql.Matcher{promql.Equal("namespace", namespace), promql.WorkloadPods(workload, "")}
 	switch {
 	case strings.Contains(m.CPUMetric, "usage_seconds_total"):
-		return `rate(` + m.CPUMetric + `{namespace=` + ns + `,pod=~` + pod + `}[5m])`
+		return promql.Rate(promql.Select(m.CPUMetric, matchers...), promql.DefaultRateWindow)
 	case strings.Contains(m.CPUMetric, "resource_requests"):
-		return `kube_pod_container_resource_requests{namespace=` + ns + `,pod=~` + pod + `,resource="cpu"}`
+		return promql.Select(promql.MetricResourceRequests, matchers...).With(promql.Equal("resource", "cpu")).String()
 	default:
-		return m.CPUMetric + `{namespace=` + ns + `,pod=~` + pod + `}`
+		return promql.Select(m.CPUMetric, matchers...).String()
 	}
 }
 
 // GetMemoryQuery builds a memory query with the best available metric
 func (m *AvailableMetrics) GetMemoryQuery(namespace, workload, _ string) string {
-	ns := escapeLabel(namespace)
-	pod := escapeRegex(workload, "-.*")
+	matchers := []promql.Matcher{promql.Equal("namespace", namespace), promql.WorkloadPods(workload, "")}
 	switch {
 	case strings.Contains(m.MemoryMetric, "resource_requests"):
-		return `kube_pod_container_resource_requests{namespace=` + ns + `,pod=~` + pod + `,resource="memory"}`
+		return promql.Select(promql.MetricResourceRequests, matchers...).With(promql.Equal("resource", "memory")).String()
 	default:
-		return m.MemoryMetric + `{namespace=` + ns + `,pod=~` + pod + `}`
+		return promql.Select(m.MemoryMetric, matchers...).String()
 	}
 }
 
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/prometheus/common/model"
+
+	"github.com/ppiankov/kubenow/internal/promql"
+	"github.com/ppiankov/kubenow/internal/querylog"
 )
 
 // MetricsProvider defines the interface for querying metrics
@@ -27,6 +30,37 @@ type MetricsProvider interface {
 	// GetWorkloadResourceUsage retrieves CPU and memory usage for a workload (Deployment, StatefulSet, etc.)
 	GetWorkloadResourceUsage(ctx context.Context, namespace, workloadName, workloadType string, window time.Duration) (*WorkloadUsage, error)
 
+	// GetWorkloadCPUSeries retrieves a workload's peak CPU usage (cores) per step between start and end
+	GetWorkloadCPUSeries(ctx context.Context, namespace, workloadName, workloadType string, start, end time.Time, step time.Duration) ([]model.SamplePair, error)
+
+	// GetWorkloadMemorySeries retrieves the peak working-set memory (bytes) per step of each of a
+	// workload's containers between start and end, keyed by container name
+	GetWorkloadMemorySeries(ctx context.Context, namespace, workloadName, workloadType string, start, end time.Time, step time.Duration) (map[string][]model.SamplePair, error)
+
+	// GetHPAReplicaSeries retrieves an HPA's current replica count per step between start and end
+	// (kube-state-metrics)
+	GetHPAReplicaSeries(ctx context.Context, namespace, hpaName string, start, end time.Time, step time.Duration) ([]model.SamplePair, error)
+
+	// GetNodeResourceUsage retrieves each node's container CPU and memory usage (average and the
+	// given quantile) over a time window, keyed by node name
+	GetNodeResourceUsage(ctx context.Context, window time.Duration, quantile float64) (map[string]*NodeUsage, error)
+
+	// GetPodUsageQuantiles retrieves every pod's CPU and memory usage at a quantile over a time
+	// window, keyed by "namespace/pod"
+	GetPodUsageQuantiles(ctx context.Context, window time.Duration, quantile float64) (map[string]PodUsageQuantile, error)
+
+	// GetContainerThrottling retrieves every container's CFS periods, throttled periods, and CPU
+	// usage at a quantile over a time window; namespace "" covers all namespaces
+	GetContainerThrottling(ctx context.Context, namespace string, window time.Duration, quantile float64) ([]ContainerThrottling, error)
+
+	// GetVolumeUsage retrieves every mounted PersistentVolumeClaim's capacity, current usage, and
+	// peak usage over a time window (kubelet volume stats); namespace "" covers all namespaces
+	GetVolumeUsage(ctx context.Context, namespace string, window time.Duration) ([]VolumeUsage, error)
+
+	// GetContainerEphemeralStorage retrieves every container's peak ephemeral storage usage over a
+	// time window; namespace "" covers all namespaces
+	GetContainerEphemeralStorage(ctx context.Context, namespace string, window time.Duration) ([]ContainerStorage, error)
+
 	// HasNamespaceMetrics checks if Prometheus has any container metrics for a namespace
 	HasNamespaceMetrics(ctx context.Context, namespace string) (bool, int, error)
 
@@ -105,11 +139,62 @@ type WorkloadUsage struct {
 	// Number of pods/replicas
 	PodCount int
 
+	// Batch workloads (Job, CronJob): runs seen in the window and the total
+	// time they had Running pods. Usage and requests cover only that time.
+	Runs       int
+	ActiveTime time.Duration
+
 	// Skew ratios
 	CPUSkew    float64 // requested / avg used
 	MemorySkew float64 // requested / avg used
 }
 
+// NodeUsage contains container resource usage on a single node
+type NodeUsage struct {
+	Node string
+
+	// CPU metrics (cores)
+	CPUAvg      float64
+	CPUQuantile float64
+
+	// Memory metrics (bytes)
+	MemoryAvg      float64
+	MemoryQuantile float64
+}
+
+// PodUsageQuantile is a pod's usage at a quantile over a time window
+type PodUsageQuantile struct {
+	CPU    float64 // cores
+	Memory float64 // bytes
+}
+
+// ContainerThrottling is a container's CPU throttling over a time window
+type ContainerThrottling struct {
+	Namespace        string
+	Pod              string
+	Container        string
+	Periods          float64 // CFS enforcement periods (only containers with a CPU limit have them)
+	ThrottledPeriods float64 // periods in which the container hit its quota
+	CPUQuantile      float64 // cores
+}
+
+// VolumeUsage is a PersistentVolumeClaim's filesystem usage
+type VolumeUsage struct {
+	Namespace string
+	PVC       string
+	Capacity  float64 // bytes, filesystem size as seen by the kubelet
+	Used      float64 // bytes, now
+	PeakUsed  float64 // bytes, over the window
+}
+
+// ContainerStorage is a container's ephemeral storage usage over a time window
+type ContainerStorage struct {
+	Namespace string
+	Pod       string
+	Container string
+	PeakUsed  float64 // bytes
+}
+
 // ClusterUsage contains cluster-wide resource usage metrics
 type ClusterUsage struct {
 	// Total cluster capacity
@@ -157,6 +242,25 @@ type Config struct {
 	// Timeout for queries
 	Timeout time.Duration
 
+	// Backend selects the metrics backend dialect (prometheus, victoriametrics, thanos, mimir).
+	// Empty means prometheus.
+	Backend string
+
+	// TenantID is the tenant for multi-tenant backends (Mimir, VictoriaMetrics cluster).
+	TenantID string
+
+	// QueryOptions configure the PromQL builder (extra matchers such as a
+	// cluster label, rate window).
+	QueryOptions []promql.Option
+
 	// Optional: Kubernetes clientset for auto-detection
 	KubeClient interface{}
+
+	// QueryLog, when set, records every range and instant query
+	// (--include-queries).
+	QueryLog *querylog.Log
+
+	// QueryRate caps range and instant queries per second across all
+	// goroutines sharing the client (0 = unlimited).
+	QueryRate float64
 }
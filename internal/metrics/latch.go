@@ -3,7 +3,8 @@ package metrics
 import (
 	"context"
 	"fmt"
-	"os"
+	"log/slog"
+	"maps"
 	"sync"
 	"time"
 
@@ -13,11 +14,15 @@ import (
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 
+	"github.com/ppiankov/kubenow/internal/logging"
 	"github.com/ppiankov/kubenow/internal/util"
 )
 
 const podLabelRefreshInterval = 60 * time.Second
 
+// maxSamples caps each sample buffer at 17280 (24h at 5s intervals) to bound memory.
+const maxSamples = 17280
+
 // LatchConfig holds configuration for spike monitoring
 type LatchConfig struct {
 	SampleInterval time.Duration    // How often to sample (e.g., 1s, 5s)
@@ -25,7 +30,24 @@ type LatchConfig struct {
 	Namespaces     []string         // Namespaces to monitor (empty = all)
 	WorkloadFilter string           // If set, only sample this workload name (pro-monitor mode)
 	PodLevel       bool             // If true, match exact pod name instead of extracting workload name
-	ProgressFunc   func(msg string) // Optional progress callback. If nil, print to stderr.
+	ProgressFunc   func(msg string) // Optional sink for progress lines. If nil, log to the default logger.
+	Recorder       *SampleRecorder  // Optional: keeps timestamped per-container samples for export
+	Network        NetworkSampler   // Optional: samples pod network throughput alongside CPU
+
+	// Checkpoint, if set, receives the latch state every CheckpointInterval
+	// (default 1m) so a killed latch can be resumed from it.
+	Checkpoint         func(*LatchCheckpoint)
+	CheckpointInterval time.Duration
+	// Resume continues the latch from a checkpoint; Duration is then the
+	// time remaining.
+	Resume *LatchCheckpoint
+}
+
+// LatchCheckpoint is the state of an unfinished latch.
+type LatchCheckpoint struct {
+	Elapsed         time.Duration         `json:"elapsed"` // monitoring time covered so far
+	SpikeData       map[string]*SpikeData `json:"spike_data"`
+	RestartBaseline map[string]int32      `json:"restart_baseline"`
 }
 
 // SpikeData contains captured spike information
@@ -45,6 +67,19 @@ type SpikeData struct {
 	CPUSamples   []float64 `json:"cpu_samples"`    // All CPU samples
 	MemSamples   []float64 `json:"memory_samples"` // All memory samples
 
+	// Set when the latch was resumed from a checkpoint: the time not
+	// monitored before the last resume is excluded from gaps, and
+	// terminations before ResumedAt cannot be placed among the samples.
+	Paused    time.Duration `json:"paused,omitempty"`
+	ResumedAt time.Time     `json:"resumed_at,omitzero"`
+
+	// Per-container samples keyed by container name, so sidecars and the
+	// app container can be sized independently.
+	Containers map[string]*ContainerSamples `json:"containers,omitempty"`
+
+	// Per-pod network throughput, when LatchConfig.Network is set.
+	Network []NetworkSample `json:"network_samples,omitempty"`
+
 	// Critical signals during monitoring
 	OOMKills            int            `json:"oom_kills"`             // Number of OOMKills detected
 	Restarts            int            `json:"restarts"`              // Container restarts during monitoring
@@ -54,6 +89,17 @@ type SpikeData struct {
 	TerminationReasons  map[string]int `json:"termination_reasons"`   // Reasons for container terminations
 	ExitCodes           map[int]int    `json:"exit_codes"`            // Exit codes and their frequencies
 	LastTerminationTime *time.Time     `json:"last_termination_time"` // When the last termination happened
+
+	// Terminations seen at the end of monitoring, and the crash pattern
+	// classified from their exit codes and the spike timing before them.
+	Terminations []Termination        `json:"terminations,omitempty"`
+	Crash        *CrashClassification `json:"crash_classification,omitempty"`
+}
+
+// ContainerSamples holds the samples of one container across a workload's pods.
+type ContainerSamples struct {
+	CPUSamples []float64 `json:"cpu_samples"`    // cores
+	MemSamples []float64 `json:"memory_samples"` // bytes
 }
 
 // LatchMonitor monitors for sub-scrape-interval spikes
@@ -63,15 +109,23 @@ type LatchMonitor struct {
 	config        LatchConfig
 	spikeData     map[string]*SpikeData // key: namespace/workload
 	podLabels     map[string]map[string]string
+	podNodes      map[string]string // pod name -> node, for network sampling
+	networkFailed bool              // a network sample error was reported
 	mu            sync.RWMutex
 	stopCh        chan struct{}
 	doneCh        chan struct{}
+	log           *slog.Logger
 
 	// restartBaseline records restart counts at latch start so that
 	// checkAllCriticalSignals only reports restarts that occurred during
 	// the latch window, not historical restarts from before monitoring.
 	// Key: "namespace/pod/container"
 	restartBaseline map[string]int32
+
+	// startedAt is when Start began; elapsedBefore is the monitoring time
+	// carried over from a resumed checkpoint.
+	startedAt     time.Time
+	elapsedBefore time.Duration
 }
 
 // NewLatchMonitor creates a new spike monitor
@@ -98,6 +152,9 @@ func NewLatchMonitor(kubeClient *kubernetes.Clientset, config LatchConfig, kubeO
 	if config.Duration == 0 {
 		config.Duration = 15 * time.Minute
 	}
+	if config.CheckpointInterval == 0 {
+		config.CheckpointInterval = time.Minute
+	}
 
 	return &LatchMonitor{
 		kubeClient:    kubeClient,
@@ -105,34 +162,53 @@ func NewLatchMonitor(kubeClient *kubernetes.Clientset, config LatchConfig, kubeO
 		config:        config,
 		spikeData:     make(map[string]*SpikeData),
 		podLabels:     make(map[string]map[string]string),
+		podNodes:      make(map[string]string),
 		stopCh:        make(chan struct{}),
 		doneCh:        make(chan struct{}),
+		log:           latchLogger(config.ProgressFunc),
 	}, nil
 }
 
-func (m *LatchMonitor) progress(msg string) {
-	if m.config.ProgressFunc != nil {
-		m.config.ProgressFunc(msg)
-	} else {
-		fmt.Fprintln(os.Stderr, msg)
+// latchLogger logs as the "latch" component, in text to ProgressFunc when
+// it is set, or to the default logger, at the configured --log-level.
+func latchLogger(progress func(string)) *slog.Logger {
+	if progress == nil {
+		return logging.Component("latch")
 	}
+	return slog.New(logging.NewHandler(logging.FuncWriter(progress), logging.Level(), logging.FormatText)).
+		With(logging.KeyComponent, "latch")
 }
 
 // Start begins monitoring for spikes
 func (m *LatchMonitor) Start(ctx context.Context) error {
 	m.refreshPodLabels(ctx)
 
-	// Snapshot restart counts before monitoring so we only report
-	// restarts that happen during the latch window.
-	m.recordRestartBaseline(ctx)
+	if m.config.Resume != nil {
+		// Restarts while the latch was down still count, so keep the
+		// original baseline.
+		m.restore(m.config.Resume, time.Now())
+	} else {
+		// Snapshot restart counts before monitoring so we only report
+		// restarts that happen during the latch window.
+		m.recordRestartBaseline(ctx)
+	}
+	m.mu.Lock()
+	m.startedAt = time.Now()
+	m.mu.Unlock()
 
 	ticker := time.NewTicker(m.config.SampleInterval)
 	defer ticker.Stop()
 
+	var checkpointCh <-chan time.Time
+	if m.config.Checkpoint != nil {
+		checkpointTicker := time.NewTicker(m.config.CheckpointInterval)
+		defer checkpointTicker.Stop()
+		checkpointCh = checkpointTicker.C
+	}
+
 	timeout := time.After(m.config.Duration)
 
-	m.progress(fmt.Sprintf("[latch] Starting spike monitoring for %s (sampling every %s)",
-		m.config.Duration, m.config.SampleInterval))
+	m.log.Info("Starting spike monitoring", "duration", m.config.Duration, "interval", m.config.SampleInterval)
 
 	sampleCount := 0
 	expectedSamples := int(m.config.Duration / m.config.SampleInterval)
@@ -147,30 +223,73 @@ func (m *LatchMonitor) Start(ctx context.Context) error {
 			close(m.doneCh)
 			return nil
 		case <-timeout:
-			m.progress(fmt.Sprintf("[latch] Monitoring complete. Captured %d samples.", sampleCount))
-			m.progress("[latch] Checking for critical signals (OOMKills, restarts, evictions)...")
+			m.log.Info("Monitoring complete", "samples", sampleCount)
+			m.log.Info("Checking for critical signals (OOMKills, restarts, evictions)...")
 			m.checkAllCriticalSignals(ctx)
 			close(m.doneCh)
 			return nil
+		case <-checkpointCh:
+			m.config.Checkpoint(m.Checkpoint())
 		case <-ticker.C:
 			if time.Since(lastLabelRefresh) >= podLabelRefreshInterval {
 				m.refreshPodLabels(ctx)
 				lastLabelRefresh = time.Now()
 			}
 			if err := m.sample(ctx); err != nil {
-				m.progress(fmt.Sprintf("[latch] Sample error: %v", err))
+				m.log.Warn("Sample failed", "error", err)
 				continue
 			}
 			sampleCount++
 			// Progress indicator every 10%
 			if expectedSamples > 0 && sampleCount%(expectedSamples/10+1) == 0 {
 				progress := float64(sampleCount) / float64(expectedSamples) * 100
-				m.progress(fmt.Sprintf("[latch] Progress: %.0f%% (%d/%d samples)", progress, sampleCount, expectedSamples))
+				logging.Progress(m.log, "Collecting samples", sampleCount, expectedSamples, "percent", int(progress))
 			}
 		}
 	}
 }
 
+// Checkpoint returns the latch state so far, for resuming it later.
+func (m *LatchMonitor) Checkpoint() *LatchCheckpoint {
+	data := m.GetSpikeData()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	elapsed := m.elapsedBefore
+	if !m.startedAt.IsZero() {
+		elapsed += time.Since(m.startedAt)
+	}
+	return &LatchCheckpoint{
+		Elapsed:         elapsed,
+		SpikeData:       data,
+		RestartBaseline: maps.Clone(m.restartBaseline),
+	}
+}
+
+// restore seeds the monitor with a checkpoint's samples. The time since
+// each workload's last checkpointed sample is recorded as paused, so the
+// downtime is not counted as missed samples.
+func (m *LatchMonitor) restore(cp *LatchCheckpoint, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, data := range cp.SpikeData {
+		if data == nil {
+			continue
+		}
+		if paused := now.Sub(data.LastSeen) - m.config.SampleInterval; paused > 0 {
+			data.Paused += paused
+		}
+		data.ResumedAt = now
+		m.spikeData[key] = data
+	}
+	m.restartBaseline = maps.Clone(cp.RestartBaseline)
+	if m.restartBaseline == nil {
+		m.restartBaseline = make(map[string]int32)
+	}
+	m.elapsedBefore = cp.Elapsed
+}
+
 // Stop stops monitoring
 func (m *LatchMonitor) Stop() {
 	close(m.stopCh)
@@ -211,17 +330,21 @@ func (m *LatchMonitor) refreshPodLabels(ctx context.Context) {
 			return
 		}
 		labels := make(map[string]map[string]string, len(pods.Items))
+		nodes := make(map[string]string, len(pods.Items))
 		for i := range pods.Items {
 			pod := &pods.Items[i]
 			labels[pod.Name] = pod.Labels
+			nodes[pod.Name] = pod.Spec.NodeName
 		}
 		m.mu.Lock()
 		m.podLabels = labels
+		m.podNodes = nodes
 		m.mu.Unlock()
 		return
 	}
 
 	labels := make(map[string]map[string]string)
+	nodes := make(map[string]string)
 	for _, ns := range namespaces {
 		pods, err := m.kubeClient.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
@@ -230,10 +353,12 @@ func (m *LatchMonitor) refreshPodLabels(ctx context.Context) {
 		for i := range pods.Items {
 			pod := &pods.Items[i]
 			labels[pod.Name] = pod.Labels
+			nodes[pod.Name] = pod.Spec.NodeName
 		}
 	}
 	m.mu.Lock()
 	m.podLabels = labels
+	m.podNodes = nodes
 	m.mu.Unlock()
 }
 
@@ -282,6 +407,7 @@ func (m *LatchMonitor) sample(ctx context.Context) error {
 	}
 
 	now := time.Now()
+	var sampled []sampledPod
 
 	for i := range podMetricsList.Items {
 		podMetrics := &podMetricsList.Items[i]
@@ -309,17 +435,6 @@ func (m *LatchMonitor) sample(ctx context.Context) error {
 
 		key := fmt.Sprintf("%s/%s", podMetrics.Namespace, workloadName)
 
-		// Calculate total CPU and memory for pod
-		var totalCPU, totalMemory float64
-		for j := range podMetrics.Containers {
-			container := &podMetrics.Containers[j]
-			cpuQuantity := container.Usage.Cpu()
-			memQuantity := container.Usage.Memory()
-
-			totalCPU += cpuQuantity.AsApproximateFloat64()
-			totalMemory += float64(memQuantity.Value())
-		}
-
 		// Initialize or update spike data
 		m.mu.Lock()
 		data, exists := m.spikeData[key]
@@ -341,14 +456,12 @@ func (m *LatchMonitor) sample(ctx context.Context) error {
 		// Update metrics
 		data.LastSeen = now
 		data.SampleCount++
-		// Cap sample buffer at 17280 (24h at 5s intervals) to bound memory
-		const maxSamples = 17280
-		if len(data.CPUSamples) >= maxSamples {
-			data.CPUSamples = data.CPUSamples[1:]
-			data.MemSamples = data.MemSamples[1:]
+		totalCPU, totalMemory := data.addContainerSamples(podMetrics.Containers)
+		if m.config.Recorder != nil {
+			m.config.Recorder.recordPod(now, podMetrics.Namespace, workloadName, podMetrics.Name, podMetrics.Containers)
 		}
-		data.CPUSamples = append(data.CPUSamples, totalCPU)
-		data.MemSamples = append(data.MemSamples, totalMemory)
+		data.CPUSamples = appendSample(data.CPUSamples, totalCPU)
+		data.MemSamples = appendSample(data.MemSamples, totalMemory)
 
 		// Track max values
 		if totalCPU > data.MaxCPU {
@@ -366,11 +479,128 @@ func (m *LatchMonitor) sample(ctx context.Context) error {
 		data.AvgCPU = calculateFloatAverage(data.CPUSamples)
 		data.AvgMemory = calculateFloatAverage(data.MemSamples)
 		m.mu.Unlock()
+
+		if m.config.Network != nil {
+			sampled = append(sampled, sampledPod{key: key, namespace: podMetrics.Namespace, name: podMetrics.Name, cpu: totalCPU})
+		}
 	}
 
+	m.sampleNetwork(ctx, sampled)
 	return nil
 }
 
+// sampledPod is a pod whose CPU was sampled this round, for pairing with
+// its network throughput.
+type sampledPod struct {
+	key, namespace, name string
+	cpu                  float64
+}
+
+// sampleNetwork records the network throughput of the pods sampled this
+// round. Errors do not fail the sample; only the first one is reported.
+func (m *LatchMonitor) sampleNetwork(ctx context.Context, sampled []sampledPod) {
+	if m.config.Network == nil || len(sampled) == 0 {
+		return
+	}
+	byNamespace := make(map[string]map[string]string)
+	m.mu.RLock()
+	for _, p := range sampled {
+		if byNamespace[p.namespace] == nil {
+			byNamespace[p.namespace] = make(map[string]string)
+		}
+		byNamespace[p.namespace][p.name] = m.podNodes[p.name]
+	}
+	m.mu.RUnlock()
+
+	rates := make(map[string]NetworkRate)
+	for ns, pods := range byNamespace {
+		nsRates, err := m.config.Network.SampleNetwork(ctx, ns, pods)
+		if err != nil {
+			if !m.networkFailed {
+				m.networkFailed = true
+				m.log.Warn("Network sampling failed (further errors not shown)", "error", err)
+			}
+			continue
+		}
+		for pod, r := range nsRates {
+			rates[ns+"/"+pod] = r
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range sampled {
+		r, ok := rates[p.namespace+"/"+p.name]
+		if !ok {
+			continue
+		}
+		if data := m.spikeData[p.key]; data != nil {
+			data.Network = appendNetworkSample(data.Network, NetworkSample{RX: r.RX, TX: r.TX, CPU: p.cpu})
+		}
+	}
+}
+
+// addContainerSamples records one sample per container and returns the pod totals.
+func (d *SpikeData) addContainerSamples(containers []metricsv1beta1.ContainerMetrics) (totalCPU, totalMemory float64) {
+	if d.Containers == nil {
+		d.Containers = make(map[string]*ContainerSamples)
+	}
+	for j := range containers {
+		container := &containers[j]
+		cpuQuantity := container.Usage.Cpu()
+		memQuantity := container.Usage.Memory()
+		cpu := cpuQuantity.AsApproximateFloat64()
+		mem := float64(memQuantity.Value())
+
+		cs, ok := d.Containers[container.Name]
+		if !ok {
+			cs = &ContainerSamples{}
+			d.Containers[container.Name] = cs
+		}
+		cs.CPUSamples = appendSample(cs.CPUSamples, cpu)
+		cs.MemSamples = appendSample(cs.MemSamples, mem)
+
+		totalCPU += cpu
+		totalMemory += mem
+	}
+	return totalCPU, totalMemory
+}
+
+// appendSample appends v, dropping the oldest sample once maxSamples is reached.
+func appendSample(samples []float64, v float64) []float64 {
+	if len(samples) >= maxSamples {
+		samples = samples[1:]
+	}
+	return append(samples, v)
+}
+
+// clone returns a deep copy of the sample buffers.
+func (d *SpikeData) clone() *SpikeData {
+	dataCopy := *d
+	dataCopy.CPUSamples = append([]float64{}, d.CPUSamples...)
+	dataCopy.MemSamples = append([]float64{}, d.MemSamples...)
+	if d.Network != nil {
+		dataCopy.Network = append([]NetworkSample{}, d.Network...)
+	}
+	if d.Terminations != nil {
+		dataCopy.Terminations = append([]Termination{}, d.Terminations...)
+	}
+	if d.Crash != nil {
+		crash := *d.Crash
+		dataCopy.Crash = &crash
+	}
+	if d.Containers != nil {
+		dataCopy.Containers = make(map[string]*ContainerSamples, len(d.Containers))
+		for name, cs := range d.Containers {
+			dataCopy.Containers[name] = &ContainerSamples{
+				CPUSamples: append([]float64{}, cs.CPUSamples...),
+				MemSamples: append([]float64{}, cs.MemSamples...),
+			}
+		}
+	}
+	return &dataCopy
+}
+
 // GetSpikeData returns all captured spike data
 func (m *LatchMonitor) GetSpikeData() map[string]*SpikeData {
 	m.mu.RLock()
@@ -379,11 +609,7 @@ func (m *LatchMonitor) GetSpikeData() map[string]*SpikeData {
 	// Return a copy to avoid concurrent modification
 	result := make(map[string]*SpikeData)
 	for k, v := range m.spikeData {
-		// Deep copy
-		dataCopy := *v
-		dataCopy.CPUSamples = append([]float64{}, v.CPUSamples...)
-		dataCopy.MemSamples = append([]float64{}, v.MemSamples...)
-		result[k] = &dataCopy
+		result[k] = v.clone()
 	}
 	return result
 }
@@ -395,11 +621,7 @@ func (m *LatchMonitor) GetWorkloadSpikeData(namespace, workloadName string) *Spi
 
 	key := fmt.Sprintf("%s/%s", namespace, workloadName)
 	if data, exists := m.spikeData[key]; exists {
-		// Return copy
-		dataCopy := *data
-		dataCopy.CPUSamples = append([]float64{}, data.CPUSamples...)
-		dataCopy.MemSamples = append([]float64{}, data.MemSamples...)
-		return &dataCopy
+		return data.clone()
 	}
 	return nil
 }
@@ -436,7 +658,7 @@ func (m *LatchMonitor) checkAllCriticalSignals(ctx context.Context) {
 	for namespace := range namespacesMap {
 		pods, err := m.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 		if err != nil {
-			m.progress(fmt.Sprintf("[latch] Warning: failed to list pods in namespace %s: %v", namespace, err))
+			m.log.Warn("Failed to list pods", "namespace", namespace, "error", err)
 			continue
 		}
 
@@ -446,6 +668,10 @@ func (m *LatchMonitor) checkAllCriticalSignals(ctx context.Context) {
 
 		m.processNamespaceEvents(ctx, namespace, m.spikeData)
 	}
+
+	for _, data := range m.spikeData {
+		data.Crash = ClassifyCrash(data, m.config.SampleInterval)
+	}
 }
 
 func (m *LatchMonitor) processPodCriticalSignals(pod *corev1.Pod) {
@@ -513,6 +739,9 @@ func (m *LatchMonitor) processTerminatedContainer(data *SpikeData, status corev1
 		data.ExitCodes = make(map[int]int)
 	}
 	data.ExitCodes[exitCode]++
+	data.Terminations = append(data.Terminations, Termination{
+		Container: status.Name, Reason: reason, ExitCode: exitCode, FinishedAt: finishedAt,
+	})
 
 	switch reason {
 	case "OOMKilled":
@@ -618,13 +847,40 @@ func (d *SpikeData) ComputePercentiles() (cpu, mem *Percentiles) {
 	return cpu, mem
 }
 
+// ContainerPercentiles holds the CPU and memory percentiles of one container.
+type ContainerPercentiles struct {
+	CPU    *Percentiles `json:"cpu_percentiles"`
+	Memory *Percentiles `json:"memory_percentiles"`
+}
+
+// ComputeContainerPercentiles computes percentiles per container. Returns nil
+// when no per-container samples were recorded (e.g. latch files from older
+// versions).
+func (d *SpikeData) ComputeContainerPercentiles() map[string]*ContainerPercentiles {
+	if len(d.Containers) == 0 {
+		return nil
+	}
+	result := make(map[string]*ContainerPercentiles, len(d.Containers))
+	for name, cs := range d.Containers {
+		if len(cs.CPUSamples) == 0 {
+			continue
+		}
+		result[name] = &ContainerPercentiles{
+			CPU:    computePercentiles(cs.CPUSamples),
+			Memory: computePercentiles(cs.MemSamples),
+		}
+	}
+	return result
+}
+
 // GapCount returns the number of expected samples that were missed.
-// A gap is defined as expectedSamples - actualSamples.
+// A gap is defined as expectedSamples - actualSamples; time paused between
+// a checkpoint and its resume is not expected to have samples.
 func (d *SpikeData) GapCount(interval time.Duration) int {
 	if interval <= 0 || d.SampleCount == 0 {
 		return 0
 	}
-	duration := d.LastSeen.Sub(d.FirstSeen)
+	duration := d.LastSeen.Sub(d.FirstSeen) - d.Paused
 	if duration <= 0 {
 		return 0
 	}
@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// latchNetworkRateWindow is the rate() window of Prometheus network
+// samples: short, since the latch samples every few seconds.
+const latchNetworkRateWindow = time.Minute
+
+// NetworkSampler reports pod network throughput for the latch.
+type NetworkSampler interface {
+	// SampleNetwork returns the receive and transmit rate of pods in one
+	// namespace, keyed by pod name; pods maps pod names to their node.
+	// Pods without a rate yet (first sample, counter reset) are left out.
+	SampleNetwork(ctx context.Context, namespace string, pods map[string]string) (map[string]NetworkRate, error)
+}
+
+// NetworkRate is a pod's network throughput.
+type NetworkRate struct {
+	RX float64 // bytes/s received
+	TX float64 // bytes/s transmitted
+}
+
+// NetworkSample is one pod's network throughput, recorded with the CPU
+// sample taken at the same time so the two can be correlated.
+type NetworkSample struct {
+	RX  float64 `json:"rx"`  // bytes/s
+	TX  float64 `json:"tx"`  // bytes/s
+	CPU float64 `json:"cpu"` // cores
+}
+
+// KubeletNetworkSampler derives pod network rates from the cumulative
+// counters of the kubelet summary API (/api/v1/nodes/<node>/proxy/stats/summary),
+// read through the API server. It needs the nodes/proxy permission.
+type KubeletNetworkSampler struct {
+	fetch func(ctx context.Context, node string) ([]byte, error)
+
+	mu   sync.Mutex
+	prev map[string]networkCounters // namespace/pod
+}
+
+type networkCounters struct {
+	rx, tx uint64
+	at     time.Time
+}
+
+// kubeletSummary is the part of the kubelet summary API the sampler reads.
+type kubeletSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Network *struct {
+			Time    time.Time `json:"time"`
+			RxBytes *uint64   `json:"rxBytes"`
+			TxBytes *uint64   `json:"txBytes"`
+		} `json:"network"`
+	} `json:"pods"`
+}
+
+// NewKubeletNetworkSampler creates a sampler reading node summaries
+// through client.
+func NewKubeletNetworkSampler(client kubernetes.Interface) *KubeletNetworkSampler {
+	return &KubeletNetworkSampler{
+		fetch: func(ctx context.Context, node string) ([]byte, error) {
+			return client.CoreV1().RESTClient().Get().
+				AbsPath("/api/v1/nodes", node, "proxy", "stats", "summary").DoRaw(ctx)
+		},
+		prev: make(map[string]networkCounters),
+	}
+}
+
+// SampleNetwork implements NetworkSampler, fetching each node's summary once.
+func (s *KubeletNetworkSampler) SampleNetwork(
+	ctx context.Context, namespace string, pods map[string]string,
+) (map[string]NetworkRate, error) {
+	nodes := make(map[string]bool)
+	for _, node := range pods {
+		if node != "" {
+			nodes[node] = true
+		}
+	}
+
+	rates := make(map[string]NetworkRate)
+	var lastErr error
+	for node := range nodes {
+		raw, err := s.fetch(ctx, node)
+		if err != nil {
+			lastErr = fmt.Errorf("kubelet summary of node %s: %w", node, err)
+			continue
+		}
+		var summary kubeletSummary
+		if err := json.Unmarshal(raw, &summary); err != nil {
+			lastErr = fmt.Errorf("kubelet summary of node %s: %w", node, err)
+			continue
+		}
+		s.mu.Lock()
+		for i := range summary.Pods {
+			pod := &summary.Pods[i]
+			net := pod.Network
+			if pod.PodRef.Namespace != namespace || pods[pod.PodRef.Name] != node ||
+				net == nil || net.RxBytes == nil || net.TxBytes == nil {
+				continue
+			}
+			key := namespace + "/" + pod.PodRef.Name
+			cur := networkCounters{rx: *net.RxBytes, tx: *net.TxBytes, at: net.Time}
+			prev, ok := s.prev[key]
+			s.prev[key] = cur
+			elapsed := cur.at.Sub(prev.at).Seconds()
+			if !ok || elapsed <= 0 || cur.rx < prev.rx || cur.tx < prev.tx {
+				continue
+			}
+			rates[pod.PodRef.Name] = NetworkRate{
+				RX: float64(cur.rx-prev.rx) / elapsed,
+				TX: float64(cur.tx-prev.tx) / elapsed,
+			}
+		}
+		s.mu.Unlock()
+	}
+	if len(rates) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return rates, nil
+}
+
+// PrometheusNetworkSampler reads pod network rates from cAdvisor's
+// container_network_*_bytes_total series, which lag by a scrape interval
+// and are smoothed over latchNetworkRateWindow.
+type PrometheusNetworkSampler struct {
+	Client *PrometheusClient
+}
+
+// SampleNetwork implements NetworkSampler.
+func (s *PrometheusNetworkSampler) SampleNetwork(
+	ctx context.Context, namespace string, pods map[string]string,
+) (map[string]NetworkRate, error) {
+	if len(pods) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(pods))
+	for name := range pods {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	sort.Strings(names)
+	podPattern := strings.Join(names, "|")
+
+	now := time.Now()
+	rx, err := s.Client.QueryInstant(ctx, s.Client.builder.PodNetworkReceiveRate(namespace, podPattern, latchNetworkRateWindow), now)
+	if err != nil {
+		return nil, fmt.Errorf("network receive query failed: %w", err)
+	}
+	tx, err := s.Client.QueryInstant(ctx, s.Client.builder.PodNetworkTransmitRate(namespace, podPattern, latchNetworkRateWindow), now)
+	if err != nil {
+		return nil, fmt.Errorf("network transmit query failed: %w", err)
+	}
+
+	rates := make(map[string]NetworkRate, len(rx))
+	for _, sample := range rx {
+		pod := string(sample.Metric["pod"])
+		r := rates[pod]
+		r.RX = float64(sample.Value)
+		rates[pod] = r
+	}
+	for _, sample := range tx {
+		pod := string(sample.Metric["pod"])
+		r := rates[pod]
+		r.TX = float64(sample.Value)
+		rates[pod] = r
+	}
+	return rates, nil
+}
+
+// appendNetworkSample appends v, dropping the oldest sample once maxSamples is reached.
+func appendNetworkSample(samples []NetworkSample, v NetworkSample) []NetworkSample {
+	if len(samples) >= maxSamples {
+		samples = samples[1:]
+	}
+	return append(samples, v)
+}
+
+// ComputeNetworkPercentiles computes percentiles of the receive and
+// transmit rates. Returns nil when no network samples were recorded.
+func (d *SpikeData) ComputeNetworkPercentiles() (rx, tx *Percentiles) {
+	if len(d.Network) == 0 {
+		return nil, nil
+	}
+	rxSamples := make([]float64, len(d.Network))
+	txSamples := make([]float64, len(d.Network))
+	for i, s := range d.Network {
+		rxSamples[i] = s.RX
+		txSamples[i] = s.TX
+	}
+	return computePercentiles(rxSamples), computePercentiles(txSamples)
+}
+
+// NetworkCPUCorrelation returns the Pearson correlation between CPU usage
+// and total network throughput over the network samples: close to 1 when
+// CPU demand follows traffic. Returns 0 when either does not vary.
+func (d *SpikeData) NetworkCPUCorrelation() float64 {
+	n := float64(len(d.Network))
+	if n < 2 {
+		return 0
+	}
+	var sumNet, sumCPU float64
+	for _, s := range d.Network {
+		sumNet += s.RX + s.TX
+		sumCPU += s.CPU
+	}
+	meanNet, meanCPU := sumNet/n, sumCPU/n
+	var cov, varNet, varCPU float64
+	for _, s := range d.Network {
+		dn, dc := s.RX+s.TX-meanNet, s.CPU-meanCPU
+		cov += dn * dc
+		varNet += dn * dn
+		varCPU += dc * dc
+	}
+	if varNet == 0 || varCPU == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varNet*varCPU)
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summaryJSON(at string, rx, tx uint64) []byte {
+	return []byte(fmt.Sprintf(`{"pods":[
+		{"podRef":{"name":"api-1","namespace":"prod"},"network":{"time":%q,"rxBytes":%d,"txBytes":%d}},
+		{"podRef":{"name":"api-1","namespace":"staging"},"network":{"time":%q,"rxBytes":1,"txBytes":1}},
+		{"podRef":{"name":"db-0","namespace":"prod"}}
+	]}`, at, rx, tx, at))
+}
+
+func TestKubeletNetworkSampler(t *testing.T) {
+	responses := [][]byte{
+		summaryJSON("2026-01-01T00:00:00Z", 1000, 500),
+		summaryJSON("2026-01-01T00:00:05Z", 6000, 1500),
+		summaryJSON("2026-01-01T00:00:10Z", 100, 100), // pod restarted: counters reset
+	}
+	var nodes []string
+	s := &KubeletNetworkSampler{
+		fetch: func(_ context.Context, node string) ([]byte, error) {
+			nodes = append(nodes, node)
+			r := responses[0]
+			responses = responses[1:]
+			return r, nil
+		},
+		prev: make(map[string]networkCounters),
+	}
+	pods := map[string]string{"api-1": "node-a", "db-0": "node-a"}
+
+	rates, err := s.SampleNetwork(context.Background(), "prod", pods)
+	require.NoError(t, err)
+	assert.Empty(t, rates, "the first sample only records counters")
+
+	rates, err = s.SampleNetwork(context.Background(), "prod", pods)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]NetworkRate{"api-1": {RX: 1000, TX: 200}}, rates)
+
+	rates, err = s.SampleNetwork(context.Background(), "prod", pods)
+	require.NoError(t, err)
+	assert.Empty(t, rates)
+	assert.Equal(t, []string{"node-a", "node-a", "node-a"}, nodes, "one summary per node and round")
+}
+
+func TestKubeletNetworkSampler_Error(t *testing.T) {
+	s := &KubeletNetworkSampler{
+		fetch: func(context.Context, string) ([]byte, error) { return nil, assert.AnError },
+		prev:  make(map[string]networkCounters),
+	}
+	_, err := s.SampleNetwork(context.Background(), "prod", map[string]string{"api-1": "node-a"})
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestSpikeData_NetworkPercentilesAndCorrelation(t *testing.T) {
+	d := &SpikeData{}
+	rx, tx := d.ComputeNetworkPercentiles()
+	assert.Nil(t, rx)
+	assert.Nil(t, tx)
+	assert.Zero(t, d.NetworkCPUCorrelation())
+
+	for i := 1; i <= 10; i++ {
+		d.Network = appendNetworkSample(d.Network, NetworkSample{RX: float64(i) * 1000, TX: 100, CPU: float64(i) * 0.1})
+	}
+	rx, tx = d.ComputeNetworkPercentiles()
+	require.NotNil(t, rx)
+	assert.InDelta(t, 10000, rx.Max, 0.001)
+	assert.InDelta(t, 100, tx.P95, 0.001)
+	assert.InDelta(t, 1.0, d.NetworkCPUCorrelation(), 0.001)
+
+	flat := &SpikeData{Network: []NetworkSample{{RX: 1, CPU: 0.1}, {RX: 5, CPU: 0.1}}}
+	assert.Zero(t, flat.NetworkCPUCorrelation(), "CPU that does not vary correlates with nothing")
+}
@@ -2,8 +2,13 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
 func TestRestartDelta_WithBaseline(t *testing.T) {
@@ -61,3 +66,94 @@ func TestRestartDelta_NilBaseline(t *testing.T) {
 	delta := m.restartDelta("ns", "pod-e", "app", 4)
 	assert.Equal(t, int32(4), delta)
 }
+
+func containerMetrics(name, cpu, mem string) metricsv1beta1.ContainerMetrics {
+	return metricsv1beta1.ContainerMetrics{
+		Name: name,
+		Usage: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(mem),
+		},
+	}
+}
+
+func TestSpikeData_AddContainerSamples(t *testing.T) {
+	d := &SpikeData{}
+
+	cpu, mem := d.addContainerSamples([]metricsv1beta1.ContainerMetrics{
+		containerMetrics("app", "400m", "256Mi"),
+		containerMetrics("envoy", "100m", "64Mi"),
+	})
+	assert.InDelta(t, 0.5, cpu, 0.001)
+	assert.InDelta(t, float64(320<<20), mem, 1)
+
+	d.addContainerSamples([]metricsv1beta1.ContainerMetrics{
+		containerMetrics("app", "600m", "300Mi"),
+		containerMetrics("envoy", "100m", "64Mi"),
+	})
+
+	require.Len(t, d.Containers, 2)
+	assert.InDeltaSlice(t, []float64{0.4, 0.6}, d.Containers["app"].CPUSamples, 0.001)
+	assert.InDeltaSlice(t, []float64{0.1, 0.1}, d.Containers["envoy"].CPUSamples, 0.001)
+
+	perc := d.ComputeContainerPercentiles()
+	require.Contains(t, perc, "app")
+	assert.InDelta(t, 0.6, perc["app"].CPU.Max, 0.001)
+	assert.InDelta(t, float64(64<<20), perc["envoy"].Memory.P95, 1)
+}
+
+func TestSpikeData_ComputeContainerPercentiles_NoContainers(t *testing.T) {
+	d := &SpikeData{CPUSamples: []float64{1}, MemSamples: []float64{1}}
+	assert.Nil(t, d.ComputeContainerPercentiles())
+}
+
+func TestSpikeData_CloneCopiesContainerSamples(t *testing.T) {
+	d := &SpikeData{}
+	d.addContainerSamples([]metricsv1beta1.ContainerMetrics{containerMetrics("app", "100m", "1Mi")})
+
+	c := d.clone()
+	c.Containers["app"].CPUSamples[0] = 9
+
+	assert.InDelta(t, 0.1, d.Containers["app"].CPUSamples[0], 0.001)
+}
+
+func TestAppendSample_Capped(t *testing.T) {
+	samples := make([]float64, maxSamples)
+	samples = appendSample(samples, 1)
+
+	assert.Len(t, samples, maxSamples)
+	assert.InDelta(t, 1.0, samples[maxSamples-1], 0)
+}
+
+func TestLatchMonitor_CheckpointRestore(t *testing.T) {
+	last := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := &LatchMonitor{
+		config: LatchConfig{SampleInterval: 5 * time.Second},
+		spikeData: map[string]*SpikeData{
+			"ns/api": {FirstSeen: last.Add(-10 * time.Minute), LastSeen: last, SampleCount: 121, CPUSamples: []float64{1, 2}},
+		},
+		restartBaseline: map[string]int32{"ns/api-0/app": 2},
+		elapsedBefore:   10 * time.Minute,
+	}
+	cp := m.Checkpoint()
+	assert.Equal(t, 10*time.Minute, cp.Elapsed, "not started: only carried-over time")
+	assert.Equal(t, 121, cp.SpikeData["ns/api"].SampleCount)
+	cp.SpikeData["ns/api"].CPUSamples[0] = 99
+	assert.Equal(t, 1.0, m.spikeData["ns/api"].CPUSamples[0], "checkpoint is a copy")
+
+	resumed := &LatchMonitor{config: m.config, spikeData: make(map[string]*SpikeData)}
+	resumeAt := last.Add(time.Hour)
+	resumed.restore(cp, resumeAt)
+
+	data := resumed.spikeData["ns/api"]
+	require.NotNil(t, data)
+	assert.Equal(t, time.Hour-5*time.Second, data.Paused)
+	assert.Equal(t, resumeAt, data.ResumedAt)
+	assert.Equal(t, int32(2), resumed.restartBaseline["ns/api-0/app"])
+	assert.Equal(t, 10*time.Minute, resumed.elapsedBefore)
+
+	// Sampling resumes: the hour down is not a gap.
+	data.LastSeen = resumeAt.Add(5 * time.Minute)
+	data.SampleCount += 60
+	assert.Equal(t, 1, data.GapCount(5*time.Second))
+}
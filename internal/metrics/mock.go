@@ -13,6 +13,14 @@ type MockMetrics struct {
 	NamespaceUsages map[string]*NamespaceUsage
 	PodUsages       map[string][]PodUsage
 	WorkloadUsages  map[string]*WorkloadUsage
+	CPUSeries       map[string][]model.SamplePair
+	MemorySeries    map[string]map[string][]model.SamplePair // "namespace/workload" -> container -> samples
+	HPAReplicas     map[string][]model.SamplePair            // "namespace/hpa" -> replica counts
+	NodeUsages      map[string]*NodeUsage
+	PodQuantiles    map[string]PodUsageQuantile
+	Throttling      []ContainerThrottling
+	Volumes         []VolumeUsage
+	Ephemeral       []ContainerStorage
 	ClusterUsage    *ClusterUsage
 
 	// Call tracking
@@ -32,6 +40,11 @@ func NewMockMetrics() *MockMetrics {
 		NamespaceUsages: make(map[string]*NamespaceUsage),
 		PodUsages:       make(map[string][]PodUsage),
 		WorkloadUsages:  make(map[string]*WorkloadUsage),
+		CPUSeries:       make(map[string][]model.SamplePair),
+		MemorySeries:    make(map[string]map[string][]model.SamplePair),
+		HPAReplicas:     make(map[string][]model.SamplePair),
+		NodeUsages:      make(map[string]*NodeUsage),
+		PodQuantiles:    make(map[string]PodUsageQuantile),
 		ClusterUsage:    &ClusterUsage{},
 	}
 }
@@ -127,6 +140,98 @@ func (m *MockMetrics) GetWorkloadResourceUsage(_ context.Context, namespace, wor
 	}, nil
 }
 
+// GetWorkloadCPUSeries implements MetricsProvider
+func (m *MockMetrics) GetWorkloadCPUSeries(_ context.Context, namespace, workloadName, _ string, _, _ time.Time, _ time.Duration) ([]model.SamplePair, error) {
+	m.QueryRangeCalls++
+	if m.QueryRangeError != nil {
+		return nil, m.QueryRangeError
+	}
+	return m.CPUSeries[namespace+"/"+workloadName], nil
+}
+
+// GetWorkloadMemorySeries implements MetricsProvider
+func (m *MockMetrics) GetWorkloadMemorySeries(
+	_ context.Context, namespace, workloadName, _ string, _, _ time.Time, _ time.Duration,
+) (map[string][]model.SamplePair, error) {
+	m.QueryRangeCalls++
+	if m.QueryRangeError != nil {
+		return nil, m.QueryRangeError
+	}
+	return m.MemorySeries[namespace+"/"+workloadName], nil
+}
+
+// GetHPAReplicaSeries implements MetricsProvider
+func (m *MockMetrics) GetHPAReplicaSeries(_ context.Context, namespace, hpaName string, _, _ time.Time, _ time.Duration) ([]model.SamplePair, error) {
+	m.QueryRangeCalls++
+	if m.QueryRangeError != nil {
+		return nil, m.QueryRangeError
+	}
+	return m.HPAReplicas[namespace+"/"+hpaName], nil
+}
+
+// GetNodeResourceUsage implements MetricsProvider
+func (m *MockMetrics) GetNodeResourceUsage(_ context.Context, _ time.Duration, _ float64) (map[string]*NodeUsage, error) {
+	m.QueryInstantCalls++
+	if m.QueryInstantError != nil {
+		return nil, m.QueryInstantError
+	}
+	return m.NodeUsages, nil
+}
+
+// GetContainerThrottling implements MetricsProvider
+func (m *MockMetrics) GetContainerThrottling(_ context.Context, namespace string, _ time.Duration, _ float64) ([]ContainerThrottling, error) {
+	m.QueryInstantCalls++
+	if m.QueryInstantError != nil {
+		return nil, m.QueryInstantError
+	}
+	var out []ContainerThrottling
+	for _, c := range m.Throttling {
+		if namespace == "" || c.Namespace == namespace {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// GetVolumeUsage implements MetricsProvider
+func (m *MockMetrics) GetVolumeUsage(_ context.Context, namespace string, _ time.Duration) ([]VolumeUsage, error) {
+	m.QueryInstantCalls++
+	if m.QueryInstantError != nil {
+		return nil, m.QueryInstantError
+	}
+	var out []VolumeUsage
+	for _, v := range m.Volumes {
+		if namespace == "" || v.Namespace == namespace {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// GetContainerEphemeralStorage implements MetricsProvider
+func (m *MockMetrics) GetContainerEphemeralStorage(_ context.Context, namespace string, _ time.Duration) ([]ContainerStorage, error) {
+	m.QueryInstantCalls++
+	if m.QueryInstantError != nil {
+		return nil, m.QueryInstantError
+	}
+	var out []ContainerStorage
+	for _, c := range m.Ephemeral {
+		if namespace == "" || c.Namespace == namespace {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// GetPodUsageQuantiles implements MetricsProvider
+func (m *MockMetrics) GetPodUsageQuantiles(_ context.Context, _ time.Duration, _ float64) (map[string]PodUsageQuantile, error) {
+	m.QueryInstantCalls++
+	if m.QueryInstantError != nil {
+		return nil, m.QueryInstantError
+	}
+	return m.PodQuantiles, nil
+}
+
 // GetClusterResourceUsage implements MetricsProvider
 func (m *MockMetrics) GetClusterResourceUsage(_ context.Context, _ time.Duration) (*ClusterUsage, error) {
 	if m.ClusterUsage.TotalCPU > 0 {
@@ -183,6 +288,11 @@ func (m *MockMetrics) AddWorkloadUsage(namespace, workloadName string, usage *Wo
 	m.WorkloadUsages[key] = usage
 }
 
+// AddCPUSeries adds a CPU usage series fixture for a workload
+func (m *MockMetrics) AddCPUSeries(namespace, workloadName string, series []model.SamplePair) {
+	m.CPUSeries[namespace+"/"+workloadName] = series
+}
+
 // SetClusterUsage sets fixture data for cluster usage
 func (m *MockMetrics) SetClusterUsage(usage *ClusterUsage) {
 	m.ClusterUsage = usage
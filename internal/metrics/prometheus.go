@@ -3,16 +3,21 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"math"
 	"net"
 	"net/url"
-	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/api"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
+	"golang.org/x/time/rate"
+
+	"github.com/ppiankov/kubenow/internal/promql"
 )
 
 // adaptiveStep calculates a query step that targets approximately maxPoints data points.
@@ -34,6 +39,13 @@ type PrometheusClient struct {
 	api     v1.API
 	config  Config
 	builder *QueryBuilder
+	dialect *Dialect
+	limiter *rate.Limiter // nil = unlimited
+
+	// workloads selects workload pods via owner joins when kube-state-metrics
+	// exports kube_pod_owner; detected on first successful probe.
+	ownersMu  sync.Mutex
+	workloads *QueryBuilder
 }
 
 // NewPrometheusClient creates a new Prometheus client
@@ -50,20 +62,45 @@ func NewPrometheusClient(config Config) (*PrometheusClient, error) {
 		config.Timeout = 30 * time.Second
 	}
 
+	dialect := DialectFor(config.Backend)
+	address, err := dialect.resolveURL(config.PrometheusURL, config.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
 	client, err := api.NewClient(api.Config{
-		Address: config.PrometheusURL,
+		Address:      address,
+		RoundTripper: dialect.roundTripper(config.TenantID),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Prometheus client: %w", err)
 	}
 
+	var limiter *rate.Limiter
+	if config.QueryRate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(config.QueryRate), max(1, int(config.QueryRate)))
+	}
+
 	return &PrometheusClient{
 		api:     v1.NewAPI(client),
 		config:  config,
-		builder: NewQueryBuilder(),
+		builder: NewQueryBuilder(config.QueryOptions...),
+		dialect: dialect,
+		limiter: limiter,
 	}, nil
 }
 
+// wait blocks until the query rate limit admits another query.
+func (p *PrometheusClient) wait(ctx context.Context) error {
+	if p.limiter == nil {
+		return nil
+	}
+	if err := p.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("query rate limit: %w", err)
+	}
+	return nil
+}
+
 // validatePrometheusURL rejects URLs with dangerous schemes or SSRF-prone hosts.
 func validatePrometheusURL(raw string) error {
 	u, err := url.Parse(raw)
@@ -113,8 +150,13 @@ func (p *PrometheusClient) QueryRange(ctx context.Context, query string, start,
 		Step:  step,
 	}
 
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	began := time.Now()
 	result, warnings, err := p.api.QueryRange(ctx, query, r)
 	if err != nil {
+		p.config.QueryLog.RecordRange(query, start, end, step, began, 0, err)
 		return nil, fmt.Errorf("query range failed: %w", err)
 	}
 
@@ -125,16 +167,24 @@ func (p *PrometheusClient) QueryRange(ctx context.Context, query string, start,
 
 	matrix, ok := result.(model.Matrix)
 	if !ok {
-		return nil, fmt.Errorf("unexpected result type: %T", result)
+		err = fmt.Errorf("unexpected result type: %T", result)
+		p.config.QueryLog.RecordRange(query, start, end, step, began, 0, err)
+		return nil, err
 	}
+	p.config.QueryLog.RecordRange(query, start, end, step, began, len(matrix), nil)
 
 	return matrix, nil
 }
 
 // QueryInstant executes an instant query
 func (p *PrometheusClient) QueryInstant(ctx context.Context, query string, ts time.Time) (model.Vector, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	began := time.Now()
 	result, warnings, err := p.api.Query(ctx, query, ts)
 	if err != nil {
+		p.config.QueryLog.RecordInstant(query, ts, began, 0, err)
 		return nil, fmt.Errorf("instant query failed: %w", err)
 	}
 
@@ -144,8 +194,11 @@ func (p *PrometheusClient) QueryInstant(ctx context.Context, query string, ts ti
 
 	vector, ok := result.(model.Vector)
 	if !ok {
-		return nil, fmt.Errorf("unexpected result type: %T", result)
+		err = fmt.Errorf("unexpected result type: %T", result)
+		p.config.QueryLog.RecordInstant(query, ts, began, 0, err)
+		return nil, err
 	}
+	p.config.QueryLog.RecordInstant(query, ts, began, len(vector), nil)
 
 	return vector, nil
 }
@@ -278,6 +331,10 @@ func (p *PrometheusClient) GetPodResourceUsage(ctx context.Context, namespace, p
 
 // GetWorkloadResourceUsage retrieves CPU and memory usage for a workload
 func (p *PrometheusClient) GetWorkloadResourceUsage(ctx context.Context, namespace, workloadName, workloadType string, window time.Duration) (*WorkloadUsage, error) {
+	if IsBatchKind(workloadType) {
+		return p.getBatchWorkloadUsage(ctx, namespace, workloadName, workloadType, window), nil
+	}
+
 	end := time.Now()
 	start := end.Add(-window)
 	step := adaptiveStep(window, 1000)
@@ -288,11 +345,13 @@ func (p *PrometheusClient) GetWorkloadResourceUsage(ctx context.Context, namespa
 		WorkloadType: workloadType,
 	}
 
+	qb := p.workloadBuilder(ctx)
+
 	// Query workload CPU
-	cpuQuery := p.builder.WorkloadCPUUsage(namespace, workloadName, workloadType)
+	cpuQuery := qb.WorkloadCPUUsage(namespace, workloadName, workloadType)
 	cpuMatrix, err := p.QueryRange(ctx, cpuQuery, start, end, step)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: CPU usage query failed for %s/%s: %v\n", namespace, workloadName, err)
+		warnWorkloadQuery("CPU usage", namespace, workloadName, err)
 	} else if len(cpuMatrix) > 0 {
 		usage.CPUAvg = calculateAverage(cpuMatrix[0].Values)
 		usage.CPUP95 = calculatePercentile(cpuMatrix[0].Values, 0.95)
@@ -301,10 +360,10 @@ func (p *PrometheusClient) GetWorkloadResourceUsage(ctx context.Context, namespa
 	}
 
 	// Query workload memory
-	memQuery := p.builder.WorkloadMemoryUsage(namespace, workloadName, workloadType)
+	memQuery := qb.WorkloadMemoryUsage(namespace, workloadName, workloadType)
 	memMatrix, err := p.QueryRange(ctx, memQuery, start, end, step)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: memory usage query failed for %s/%s: %v\n", namespace, workloadName, err)
+		warnWorkloadQuery("memory usage", namespace, workloadName, err)
 	} else if len(memMatrix) > 0 {
 		usage.MemoryAvg = calculateAverage(memMatrix[0].Values)
 		usage.MemoryP95 = calculatePercentile(memMatrix[0].Values, 0.95)
@@ -313,35 +372,35 @@ func (p *PrometheusClient) GetWorkloadResourceUsage(ctx context.Context, namespa
 	}
 
 	// Query resource requests using workload-type-aware queries
-	cpuReqQuery := p.builder.WorkloadCPURequests(namespace, workloadName, workloadType)
+	cpuReqQuery := qb.WorkloadCPURequests(namespace, workloadName, workloadType)
 	cpuReqResult, err := p.QueryInstant(ctx, cpuReqQuery, end)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: CPU requests query failed for %s/%s: %v\n", namespace, workloadName, err)
+		warnWorkloadQuery("CPU requests", namespace, workloadName, err)
 	} else if len(cpuReqResult) > 0 {
 		usage.CPURequested = float64(cpuReqResult[0].Value)
 	}
 
-	memReqQuery := p.builder.WorkloadMemoryRequests(namespace, workloadName, workloadType)
+	memReqQuery := qb.WorkloadMemoryRequests(namespace, workloadName, workloadType)
 	memReqResult, err := p.QueryInstant(ctx, memReqQuery, end)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: memory requests query failed for %s/%s: %v\n", namespace, workloadName, err)
+		warnWorkloadQuery("memory requests", namespace, workloadName, err)
 	} else if len(memReqResult) > 0 {
 		usage.MemoryRequested = float64(memReqResult[0].Value)
 	}
 
 	// Query resource limits
-	cpuLimQuery := p.builder.WorkloadCPULimits(namespace, workloadName, workloadType)
+	cpuLimQuery := qb.WorkloadCPULimits(namespace, workloadName, workloadType)
 	cpuLimResult, err := p.QueryInstant(ctx, cpuLimQuery, end)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: CPU limits query failed for %s/%s: %v\n", namespace, workloadName, err)
+		warnWorkloadQuery("CPU limits", namespace, workloadName, err)
 	} else if len(cpuLimResult) > 0 {
 		usage.CPULimit = float64(cpuLimResult[0].Value)
 	}
 
-	memLimQuery := p.builder.WorkloadMemoryLimits(namespace, workloadName, workloadType)
+	memLimQuery := qb.WorkloadMemoryLimits(namespace, workloadName, workloadType)
 	memLimResult, err := p.QueryInstant(ctx, memLimQuery, end)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: memory limits query failed for %s/%s: %v\n", namespace, workloadName, err)
+		warnWorkloadQuery("memory limits", namespace, workloadName, err)
 	} else if len(memLimResult) > 0 {
 		usage.MemoryLimit = float64(memLimResult[0].Value)
 	}
@@ -357,6 +416,315 @@ func (p *PrometheusClient) GetWorkloadResourceUsage(ctx context.Context, namespa
 	return usage, nil
 }
 
+// warnWorkloadQuery logs a failed workload query; the usage is reported
+// without that query's values.
+func warnWorkloadQuery(what, namespace, workloadName string, err error) {
+	slog.Warn("Workload query failed", "query", what, "namespace", namespace, "workload", workloadName, "error", err)
+}
+
+// getBatchWorkloadUsage computes usage for Jobs and CronJobs over their run
+// intervals only. Every query counts Running pods alone, so the series have
+// points only while a run is in progress, and completed pods that still
+// exist do not inflate requests. Each point is the peak within its step;
+// requests and limits are the peak of any run.
+func (p *PrometheusClient) getBatchWorkloadUsage(ctx context.Context, namespace, workloadName, workloadType string, window time.Duration) *WorkloadUsage {
+	end := time.Now()
+	start := end.Add(-window)
+	step := adaptiveStep(window, 1000)
+	qb := p.workloadBuilder(ctx)
+
+	series := func(what, query string) []model.SamplePair {
+		matrix, err := p.QueryRange(ctx, query, start, end, step)
+		if err != nil {
+			warnWorkloadQuery(what, namespace, workloadName, err)
+			return nil
+		}
+		if len(matrix) == 0 {
+			return nil
+		}
+		return matrix[0].Values
+	}
+
+	usage := &WorkloadUsage{
+		WorkloadName: workloadName,
+		Namespace:    namespace,
+		WorkloadType: workloadType,
+	}
+	if cpu := series("CPU usage", qb.RunningWorkloadCPUUsage(namespace, workloadName, workloadType, step)); len(cpu) > 0 {
+		usage.CPUAvg = calculateAverage(cpu)
+		usage.CPUP95 = calculatePercentile(cpu, 0.95)
+		usage.CPUP99 = calculatePercentile(cpu, 0.99)
+		usage.CPUMax = calculateMax(cpu)
+		usage.Runs, usage.ActiveTime = runIntervals(cpu, step)
+	}
+	if mem := series("memory usage", qb.RunningWorkloadMemoryUsage(namespace, workloadName, workloadType, step)); len(mem) > 0 {
+		usage.MemoryAvg = calculateAverage(mem)
+		usage.MemoryP95 = calculatePercentile(mem, 0.95)
+		usage.MemoryP99 = calculatePercentile(mem, 0.99)
+		usage.MemoryMax = calculateMax(mem)
+	}
+	usage.CPURequested = calculateMax(series("CPU requests", qb.RunningWorkloadRequests("cpu", namespace, workloadName, workloadType, step)))
+	usage.MemoryRequested = calculateMax(series("memory requests", qb.RunningWorkloadRequests("memory", namespace, workloadName, workloadType, step)))
+	usage.CPULimit = calculateMax(series("CPU limits", qb.RunningWorkloadLimits("cpu", namespace, workloadName, workloadType, step)))
+	usage.MemoryLimit = calculateMax(series("memory limits", qb.RunningWorkloadLimits("memory", namespace, workloadName, workloadType, step)))
+
+	if usage.CPUAvg > 0 {
+		usage.CPUSkew = usage.CPURequested / usage.CPUAvg
+	}
+	if usage.MemoryAvg > 0 {
+		usage.MemorySkew = usage.MemoryRequested / usage.MemoryAvg
+	}
+	return usage
+}
+
+// runIntervals counts the runs in a series that has points only while a
+// workload runs: a gap longer than one step starts a new run. active is the
+// number of points times the step.
+func runIntervals(values []model.SamplePair, step time.Duration) (runs int, active time.Duration) {
+	for i := range values {
+		if i == 0 || values[i].Timestamp.Sub(values[i-1].Timestamp) > step {
+			runs++
+		}
+	}
+	return runs, time.Duration(len(values)) * step
+}
+
+// GetWorkloadCPUSeries retrieves a workload's total CPU usage, taking the
+// maximum within each step so short bursts are not sampled away.
+func (p *PrometheusClient) GetWorkloadCPUSeries(
+	ctx context.Context, namespace, workloadName, workloadType string, start, end time.Time, step time.Duration,
+) ([]model.SamplePair, error) {
+	query := p.workloadBuilder(ctx).MaxCPUUsageByWorkload(namespace, workloadName, workloadType, step)
+	matrix, err := p.QueryRange(ctx, query, start, end, step)
+	if err != nil {
+		return nil, fmt.Errorf("CPU usage series query failed for %s/%s: %w", namespace, workloadName, err)
+	}
+	if len(matrix) == 0 {
+		return nil, nil
+	}
+	return matrix[0].Values, nil
+}
+
+// GetWorkloadMemorySeries retrieves the working set of a workload's
+// hottest replica per container, taking the maximum within each step.
+func (p *PrometheusClient) GetWorkloadMemorySeries(
+	ctx context.Context, namespace, workloadName, workloadType string, start, end time.Time, step time.Duration,
+) (map[string][]model.SamplePair, error) {
+	query := p.workloadBuilder(ctx).MaxContainerMemoryByWorkload(namespace, workloadName, workloadType, step)
+	matrix, err := p.QueryRange(ctx, query, start, end, step)
+	if err != nil {
+		return nil, fmt.Errorf("memory series query failed for %s/%s: %w", namespace, workloadName, err)
+	}
+	series := make(map[string][]model.SamplePair, len(matrix))
+	for _, s := range matrix {
+		series[string(s.Metric["container"])] = s.Values
+	}
+	return series, nil
+}
+
+// GetHPAReplicaSeries retrieves an HPA's replica count from
+// kube-state-metrics, sampled at each step.
+func (p *PrometheusClient) GetHPAReplicaSeries(
+	ctx context.Context, namespace, hpaName string, start, end time.Time, step time.Duration,
+) ([]model.SamplePair, error) {
+	matrix, err := p.QueryRange(ctx, p.builder.HPAReplicas(namespace, hpaName), start, end, step)
+	if err != nil {
+		return nil, fmt.Errorf("HPA replica series query failed for %s/%s: %w", namespace, hpaName, err)
+	}
+	if len(matrix) == 0 {
+		return nil, nil
+	}
+	return matrix[0].Values, nil
+}
+
+// GetNodeResourceUsage retrieves per-node container usage. Usage is
+// attributed to nodes through kube_pod_info, so it needs kube-state-metrics.
+func (p *PrometheusClient) GetNodeResourceUsage(ctx context.Context, window time.Duration, quantile float64) (map[string]*NodeUsage, error) {
+	now := time.Now()
+	queries := []struct {
+		query string
+		set   func(u *NodeUsage, v float64)
+	}{
+		{p.builder.NodeCPUQuantile(quantile, window), func(u *NodeUsage, v float64) { u.CPUQuantile = v }},
+		{p.builder.NodeCPUAvg(window), func(u *NodeUsage, v float64) { u.CPUAvg = v }},
+		{p.builder.NodeMemoryQuantile(quantile, window), func(u *NodeUsage, v float64) { u.MemoryQuantile = v }},
+		{p.builder.NodeMemoryAvg(window), func(u *NodeUsage, v float64) { u.MemoryAvg = v }},
+	}
+
+	usage := make(map[string]*NodeUsage)
+	for _, q := range queries {
+		vector, err := p.QueryInstant(ctx, q.query, now)
+		if err != nil {
+			return nil, fmt.Errorf("node usage query failed: %w", err)
+		}
+		for _, sample := range vector {
+			node := string(sample.Metric["node"])
+			if node == "" {
+				continue
+			}
+			u, ok := usage[node]
+			if !ok {
+				u = &NodeUsage{Node: node}
+				usage[node] = u
+			}
+			q.set(u, float64(sample.Value))
+		}
+	}
+	return usage, nil
+}
+
+// GetPodUsageQuantiles retrieves every pod's usage at a quantile in two
+// queries, including pods that have since been deleted.
+func (p *PrometheusClient) GetPodUsageQuantiles(ctx context.Context, window time.Duration, quantile float64) (map[string]PodUsageQuantile, error) {
+	now := time.Now()
+	cpu, err := p.QueryInstant(ctx, p.builder.PodCPUQuantile(quantile, window), now)
+	if err != nil {
+		return nil, fmt.Errorf("pod CPU usage query failed: %w", err)
+	}
+	mem, err := p.QueryInstant(ctx, p.builder.PodMemoryQuantile(quantile, window), now)
+	if err != nil {
+		return nil, fmt.Errorf("pod memory usage query failed: %w", err)
+	}
+
+	usage := make(map[string]PodUsageQuantile, len(cpu))
+	for _, sample := range cpu {
+		key := string(sample.Metric["namespace"]) + "/" + string(sample.Metric["pod"])
+		u := usage[key]
+		u.CPU = float64(sample.Value)
+		usage[key] = u
+	}
+	for _, sample := range mem {
+		key := string(sample.Metric["namespace"]) + "/" + string(sample.Metric["pod"])
+		u := usage[key]
+		u.Memory = float64(sample.Value)
+		usage[key] = u
+	}
+	return usage, nil
+}
+
+// GetContainerThrottling retrieves per-container throttling in three
+// queries. Containers without CFS periods (no CPU limit) are left out.
+func (p *PrometheusClient) GetContainerThrottling(
+	ctx context.Context, namespace string, window time.Duration, quantile float64,
+) ([]ContainerThrottling, error) {
+	now := time.Now()
+	periods, err := p.QueryInstant(ctx, p.builder.ContainerPeriods(namespace, window), now)
+	if err != nil {
+		return nil, fmt.Errorf("CFS periods query failed: %w", err)
+	}
+	throttled, err := p.QueryInstant(ctx, p.builder.ContainerThrottledPeriods(namespace, window), now)
+	if err != nil {
+		return nil, fmt.Errorf("throttled periods query failed: %w", err)
+	}
+	cpu, err := p.QueryInstant(ctx, p.builder.ContainerCPUQuantile(namespace, quantile, window), now)
+	if err != nil {
+		return nil, fmt.Errorf("container CPU usage query failed: %w", err)
+	}
+
+	key := func(m model.Metric) string {
+		return string(m["namespace"]) + "/" + string(m["pod"]) + "/" + string(m["container"])
+	}
+	byKey := make(map[string]*ContainerThrottling, len(periods))
+	out := make([]ContainerThrottling, 0, len(periods))
+	for _, sample := range periods {
+		if sample.Value <= 0 {
+			continue
+		}
+		out = append(out, ContainerThrottling{
+			Namespace: string(sample.Metric["namespace"]),
+			Pod:       string(sample.Metric["pod"]),
+			Container: string(sample.Metric["container"]),
+			Periods:   float64(sample.Value),
+		})
+	}
+	for i := range out {
+		byKey[out[i].Namespace+"/"+out[i].Pod+"/"+out[i].Container] = &out[i]
+	}
+	for _, sample := range throttled {
+		if c, ok := byKey[key(sample.Metric)]; ok {
+			c.ThrottledPeriods = float64(sample.Value)
+		}
+	}
+	for _, sample := range cpu {
+		if c, ok := byKey[key(sample.Metric)]; ok {
+			c.CPUQuantile = float64(sample.Value)
+		}
+	}
+	return out, nil
+}
+
+// GetVolumeUsage retrieves per-PVC volume stats in three queries. Volumes
+// without a capacity (not mounted by any kubelet) are left out.
+func (p *PrometheusClient) GetVolumeUsage(ctx context.Context, namespace string, window time.Duration) ([]VolumeUsage, error) {
+	now := time.Now()
+	capacity, err := p.QueryInstant(ctx, p.builder.VolumeCapacity(namespace), now)
+	if err != nil {
+		return nil, fmt.Errorf("volume capacity query failed: %w", err)
+	}
+	used, err := p.QueryInstant(ctx, p.builder.VolumeUsed(namespace), now)
+	if err != nil {
+		return nil, fmt.Errorf("volume usage query failed: %w", err)
+	}
+	peak, err := p.QueryInstant(ctx, p.builder.MaxVolumeUsed(namespace, window), now)
+	if err != nil {
+		return nil, fmt.Errorf("peak volume usage query failed: %w", err)
+	}
+
+	key := func(m model.Metric) string {
+		return string(m["namespace"]) + "/" + string(m["persistentvolumeclaim"])
+	}
+	out := make([]VolumeUsage, 0, len(capacity))
+	for _, sample := range capacity {
+		if sample.Value <= 0 {
+			continue
+		}
+		out = append(out, VolumeUsage{
+			Namespace: string(sample.Metric["namespace"]),
+			PVC:       string(sample.Metric["persistentvolumeclaim"]),
+			Capacity:  float64(sample.Value),
+		})
+	}
+	byKey := make(map[string]*VolumeUsage, len(out))
+	for i := range out {
+		byKey[out[i].Namespace+"/"+out[i].PVC] = &out[i]
+	}
+	for _, sample := range used {
+		if v, ok := byKey[key(sample.Metric)]; ok {
+			v.Used = float64(sample.Value)
+		}
+	}
+	for _, sample := range peak {
+		if v, ok := byKey[key(sample.Metric)]; ok {
+			v.PeakUsed = float64(sample.Value)
+		}
+	}
+	for i := range out {
+		out[i].PeakUsed = math.Max(out[i].PeakUsed, out[i].Used)
+	}
+	return out, nil
+}
+
+// GetContainerEphemeralStorage retrieves every container's peak
+// container_fs_usage_bytes over the window.
+func (p *PrometheusClient) GetContainerEphemeralStorage(
+	ctx context.Context, namespace string, window time.Duration,
+) ([]ContainerStorage, error) {
+	result, err := p.QueryInstant(ctx, p.builder.MaxContainerFSUsage(namespace, window), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("container filesystem usage query failed: %w", err)
+	}
+	out := make([]ContainerStorage, 0, len(result))
+	for _, sample := range result {
+		out = append(out, ContainerStorage{
+			Namespace: string(sample.Metric["namespace"]),
+			Pod:       string(sample.Metric["pod"]),
+			Container: string(sample.Metric["container"]),
+			PeakUsed:  float64(sample.Value),
+		})
+	}
+	return out, nil
+}
+
 // GetClusterResourceUsage retrieves cluster-wide resource usage
 func (p *PrometheusClient) GetClusterResourceUsage(ctx context.Context, window time.Duration) (*ClusterUsage, error) {
 	end := time.Now()
@@ -387,20 +755,20 @@ func (p *PrometheusClient) GetClusterResourceUsage(ctx context.Context, window t
 	step := adaptiveStep(window, 1000)
 
 	// Query cluster-wide CPU usage (all namespaces)
-	clusterCPUQuery := `sum(rate(container_cpu_usage_seconds_total{container!="",container!="POD"}[5m]))`
+	clusterCPUQuery := p.builder.ClusterCPUUsage()
 	cpuMatrix, err := p.QueryRange(ctx, clusterCPUQuery, end.Add(-window), end, step)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: cluster CPU usage query failed: %v\n", err)
+		slog.Warn("Cluster CPU usage query failed", "error", err)
 	} else if len(cpuMatrix) > 0 {
 		usage.CPUAvg = calculateAverage(cpuMatrix[0].Values)
 		usage.CPUP95 = calculatePercentile(cpuMatrix[0].Values, 0.95)
 	}
 
 	// Query cluster-wide memory usage
-	clusterMemQuery := `sum(container_memory_working_set_bytes{container!="",container!="POD"})`
+	clusterMemQuery := p.builder.ClusterMemoryUsage()
 	memMatrix, err := p.QueryRange(ctx, clusterMemQuery, end.Add(-window), end, step)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[kubenow] Warning: cluster memory usage query failed: %v\n", err)
+		slog.Warn("Cluster memory usage query failed", "error", err)
 	} else if len(memMatrix) > 0 {
 		usage.MemoryAvg = calculateAverage(memMatrix[0].Values)
 		usage.MemoryP95 = calculatePercentile(memMatrix[0].Values, 0.95)
@@ -421,18 +789,64 @@ func (p *PrometheusClient) GetClusterResourceUsage(ctx context.Context, window t
 
 // Health checks if the Prometheus endpoint is reachable
 func (p *PrometheusClient) Health(ctx context.Context) error {
-	// Simple health check: try to query runtime info
-	_, err := p.api.Runtimeinfo(ctx)
+	// Simple health check: try to query runtime info. Backends without the
+	// runtimeinfo endpoint are probed with a trivial instant query instead.
+	var err error
+	if p.dialect != nil && p.dialect.QueryHealthCheck {
+		_, _, err = p.api.Query(ctx, "1", time.Now())
+	} else {
+		_, err = p.api.Runtimeinfo(ctx)
+	}
 	if err != nil {
 		return fmt.Errorf("prometheus health check failed: %w", err)
 	}
 	return nil
 }
 
+// workloadBuilder returns the query builder for per-workload queries. It
+// checks which kube-state-metrics owner series exist so workload pods are
+// joined on owner labels instead of matched by name where possible. The
+// result is cached once all probes succeed; if one fails, this call falls
+// back to name matching and the next call probes again.
+func (p *PrometheusClient) workloadBuilder(ctx context.Context) *QueryBuilder {
+	p.ownersMu.Lock()
+	defer p.ownersMu.Unlock()
+	if p.workloads != nil {
+		return p.workloads
+	}
+
+	var owners promql.OwnerMetrics
+	var err error
+	for _, probe := range []struct {
+		metric string
+		found  *bool
+	}{
+		{promql.MetricPodOwner, &owners.PodOwner},
+		{promql.MetricReplicaSetOwner, &owners.ReplicaSetOwner},
+		{promql.MetricJobOwner, &owners.JobOwner},
+	} {
+		if *probe.found, err = p.hasSeries(ctx, p.builder.SeriesCount(probe.metric)); err != nil {
+			slog.Debug("Owner metric probe failed, matching workload pods by name", "metric", probe.metric, "error", err)
+			return p.builder
+		}
+	}
+	p.workloads = p.builder.WithOwnerMetrics(owners)
+	return p.workloads
+}
+
+// hasSeries reports whether a count query returns a positive value.
+func (p *PrometheusClient) hasSeries(ctx context.Context, countQuery string) (bool, error) {
+	result, err := p.QueryInstant(ctx, countQuery, time.Now())
+	if err != nil {
+		return false, err
+	}
+	return len(result) > 0 && result[0].Value > 0, nil
+}
+
 // HasNamespaceMetrics checks if Prometheus has any container CPU metrics for a namespace.
 // Returns (hasMetrics, seriesCount, error).
 func (p *PrometheusClient) HasNamespaceMetrics(ctx context.Context, namespace string) (hasMetrics bool, seriesCount int, err error) {
-	query := `count(container_cpu_usage_seconds_total{namespace=` + escapeLabel(namespace) + `,container!="",container!="POD"})`
+	query := p.builder.NamespaceCPUSeriesCount(namespace)
 	result, err := p.QueryInstant(ctx, query, time.Now())
 	if err != nil {
 		return false, 0, err
@@ -512,8 +926,20 @@ func (p *PrometheusClient) GetWorkloadSafetyData(ctx context.Context, namespace,
 		results["restarts"] = 0
 	}
 
-	// Query for CPU throttling percentage
-	throttleQuery := p.builder.CPUThrottledPercentByWorkload(namespace, workloadName, window)
+	// Query for restarts that followed an OOM kill
+	oomQuery := p.builder.OOMKillsByWorkload(namespace, workloadName, window)
+	oomVec, err := p.QueryInstant(ctx, oomQuery, end)
+	if err == nil && len(oomVec) > 0 {
+		results["oom_kills"] = math.Round(float64(oomVec[0].Value))
+	} else {
+		results["oom_kills"] = 0
+	}
+
+	// Usage-based queries select the workload's pods like GetWorkloadResourceUsage.
+	qb := p.workloadBuilder(ctx)
+
+	// Query for the share of CFS periods throttled
+	throttleQuery := qb.CPUThrottledPercentByWorkload(namespace, workloadName, workloadType, window)
 	throttleVec, err := p.QueryInstant(ctx, throttleQuery, end)
 	if err == nil && len(throttleVec) > 0 {
 		results["cpu_throttled_percent"] = float64(throttleVec[0].Value)
@@ -531,7 +957,7 @@ func (p *PrometheusClient) GetWorkloadSafetyData(ctx context.Context, namespace,
 	}
 
 	// Query for p99.9 CPU
-	p999CPUQuery := p.builder.CPUP999ByWorkload(namespace, workloadName, workloadType, window)
+	p999CPUQuery := qb.CPUP999ByWorkload(namespace, workloadName, workloadType, window)
 	p999CPUVec, err := p.QueryInstant(ctx, p999CPUQuery, end)
 	if err == nil && len(p999CPUVec) > 0 {
 		results["cpu_p999"] = float64(p999CPUVec[0].Value)
@@ -540,7 +966,7 @@ func (p *PrometheusClient) GetWorkloadSafetyData(ctx context.Context, namespace,
 	}
 
 	// Query for p99.9 memory
-	p999MemQuery := p.builder.MemoryP999ByWorkload(namespace, workloadName, workloadType, window)
+	p999MemQuery := qb.MemoryP999ByWorkload(namespace, workloadName, workloadType, window)
 	p999MemVec, err := p.QueryInstant(ctx, p999MemQuery, end)
 	if err == nil && len(p999MemVec) > 0 {
 		results["memory_p999"] = float64(p999MemVec[0].Value)
@@ -549,7 +975,7 @@ func (p *PrometheusClient) GetWorkloadSafetyData(ctx context.Context, namespace,
 	}
 
 	// Query for max CPU
-	maxCPUQuery := p.builder.MaxCPUUsageByWorkload(namespace, workloadName, workloadType, window)
+	maxCPUQuery := qb.MaxCPUUsageByWorkload(namespace, workloadName, workloadType, window)
 	maxCPUVec, err := p.QueryInstant(ctx, maxCPUQuery, end)
 	if err == nil && len(maxCPUVec) > 0 {
 		results["cpu_max"] = float64(maxCPUVec[0].Value)
@@ -558,7 +984,7 @@ func (p *PrometheusClient) GetWorkloadSafetyData(ctx context.Context, namespace,
 	}
 
 	// Query for max memory
-	maxMemQuery := p.builder.MaxMemoryUsageByWorkload(namespace, workloadName, workloadType, window)
+	maxMemQuery := qb.MaxMemoryUsageByWorkload(namespace, workloadName, workloadType, window)
 	maxMemVec, err := p.QueryInstant(ctx, maxMemQuery, end)
 	if err == nil && len(maxMemVec) > 0 {
 		results["memory_max"] = float64(maxMemVec[0].Value)
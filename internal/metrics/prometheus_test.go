@@ -0,0 +1,243 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ownerMetricsServer reports the given owner metrics as present and records
+// every other query it receives.
+func ownerMetricsServer(t *testing.T, present ...string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		value := "0"
+		for _, m := range present {
+			if query == "count("+m+")" {
+				value = "1"
+			}
+		}
+		if !strings.HasPrefix(query, "count(kube_") {
+			mu.Lock()
+			queries = append(queries, query)
+			mu.Unlock()
+		}
+
+		resultType, result := "vector", []any{map[string]any{"metric": map[string]string{}, "value": []any{1, value}}}
+		if strings.HasSuffix(r.URL.Path, "query_range") {
+			resultType, result = "matrix", []any{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := json.Marshal(map[string]any{
+			"status": "success",
+			"data":   map[string]any{"resultType": resultType, "result": result},
+		})
+		_, _ = w.Write(data)
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), queries...)
+	}
+}
+
+func TestPrometheusClient_WorkloadOwnerJoins(t *testing.T) {
+	tests := []struct {
+		name    string
+		present []string
+		joined  bool
+	}{
+		{"owner metrics present", []string{"kube_pod_owner", "kube_replicaset_owner"}, true},
+		{"owner metrics absent", nil, false},
+		{"replicaset owner absent", []string{"kube_pod_owner"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, queries := ownerMetricsServer(t, tt.present...)
+			defer srv.Close()
+
+			client, err := NewPrometheusClient(Config{PrometheusURL: srv.URL})
+			require.NoError(t, err)
+
+			_, err = client.GetWorkloadResourceUsage(context.Background(), "prod", "api", "Deployment", time.Hour)
+			require.NoError(t, err)
+
+			got := queries()
+			require.NotEmpty(t, got)
+			for _, q := range got {
+				if tt.joined {
+					assert.Contains(t, q, "group_left", q)
+					assert.NotContains(t, q, "pod=~", q)
+				} else {
+					assert.Contains(t, q, `pod=~"api-.*"`, q)
+				}
+			}
+		})
+	}
+}
+
+func TestPrometheusClient_OwnerProbeRetriesAfterError(t *testing.T) {
+	var mu sync.Mutex
+	var probes int
+	failing := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		if strings.HasPrefix(query, "count(kube_") {
+			mu.Lock()
+			probes++
+			fail := failing
+			mu.Unlock()
+			if fail {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := json.Marshal(map[string]any{
+			"status": "success",
+			"data": map[string]any{
+				"resultType": "vector",
+				"result":     []any{map[string]any{"metric": map[string]string{}, "value": []any{1, "1"}}},
+			},
+		})
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewPrometheusClient(Config{PrometheusURL: srv.URL})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NotContains(t, client.workloadBuilder(ctx).MaxCPUUsageByWorkload("prod", "api", "Deployment", time.Minute), "group_left",
+		"a failed probe falls back to name matching")
+	assert.Nil(t, client.workloads, "a failed probe is not cached")
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	assert.Contains(t, client.workloadBuilder(ctx).MaxCPUUsageByWorkload("prod", "api", "Deployment", time.Minute), "group_left")
+
+	mu.Lock()
+	seen := probes
+	mu.Unlock()
+	client.workloadBuilder(ctx)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, seen, probes, "a successful probe is cached")
+}
+
+func TestPrometheusClient_BatchWorkloadUsage(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		resultType, result := "vector", []any{map[string]any{"metric": map[string]string{}, "value": []any{1, "0"}}}
+		if strings.HasSuffix(r.URL.Path, "query_range") {
+			mu.Lock()
+			queries = append(queries, query)
+			mu.Unlock()
+			// Two runs: three one-minute points, then a gap.
+			values := []any{[]any{1000, "0.5"}, []any{1060, "0.5"}, []any{1360, "0.5"}}
+			resultType, result = "matrix", []any{map[string]any{"metric": map[string]string{}, "values": values}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := json.Marshal(map[string]any{
+			"status": "success",
+			"data":   map[string]any{"resultType": resultType, "result": result},
+		})
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewPrometheusClient(Config{PrometheusURL: srv.URL})
+	require.NoError(t, err)
+
+	usage, err := client.GetWorkloadResourceUsage(context.Background(), "batch", "nightly-report", "CronJob", time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, usage.Runs)
+	assert.Equal(t, 3*time.Minute, usage.ActiveTime)
+	assert.InDelta(t, 0.5, usage.CPUAvg, 0.001)
+	assert.InDelta(t, 0.5, usage.CPURequested, 0.001)
+
+	require.Len(t, queries, 6)
+	for _, q := range queries {
+		assert.Contains(t, q, `phase="Running"`, q)
+		assert.Contains(t, q, `pod=~"nightly-report-[0-9]+-.*"`, q)
+		assert.True(t, strings.HasPrefix(q, "max_over_time("), q)
+	}
+}
+
+func TestPrometheusClient_NodeAndPodUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		value := "1"
+		switch {
+		case strings.Contains(query, "container_memory_working_set_bytes"):
+			value = "1073741824"
+		case strings.HasPrefix(query, "avg_over_time("):
+			value = "0.5"
+		}
+		var result []any
+		if strings.Contains(query, "by (node)") {
+			result = []any{
+				map[string]any{"metric": map[string]string{"node": "node-a"}, "value": []any{1, value}},
+				map[string]any{"metric": map[string]string{}, "value": []any{1, value}},
+			}
+		} else {
+			result = []any{map[string]any{"metric": map[string]string{"namespace": "prod", "pod": "api-1"}, "value": []any{1, value}}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := json.Marshal(map[string]any{
+			"status": "success",
+			"data":   map[string]any{"resultType": "vector", "result": result},
+		})
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewPrometheusClient(Config{PrometheusURL: srv.URL})
+	require.NoError(t, err)
+
+	nodes, err := client.GetNodeResourceUsage(context.Background(), 24*time.Hour, 0.95)
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.InDelta(t, 1, nodes["node-a"].CPUQuantile, 0.001)
+	assert.InDelta(t, 0.5, nodes["node-a"].CPUAvg, 0.001)
+	assert.InDelta(t, 1<<30, nodes["node-a"].MemoryQuantile, 1)
+
+	pods, err := client.GetPodUsageQuantiles(context.Background(), 24*time.Hour, 0.95)
+	require.NoError(t, err)
+	assert.Equal(t, PodUsageQuantile{CPU: 1, Memory: 1 << 30}, pods["prod/api-1"])
+}
+
+func TestPrometheusClient_QueryRate(t *testing.T) {
+	srv, queries := ownerMetricsServer(t)
+	defer srv.Close()
+
+	client, err := NewPrometheusClient(Config{PrometheusURL: srv.URL, QueryRate: 1})
+	require.NoError(t, err)
+
+	_, err = client.QueryInstant(context.Background(), "up", time.Now())
+	require.NoError(t, err)
+
+	// The burst is spent: the next query waits for a token, here longer
+	// than its deadline allows, and is never sent.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = client.QueryRange(ctx, "up", time.Now().Add(-time.Hour), time.Now(), time.Minute)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query rate limit")
+	assert.Equal(t, []string{"up"}, queries())
+}
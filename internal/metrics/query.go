@@ -2,223 +2,302 @@ package metrics
 
 import (
 	"fmt"
-	"strings"
 	"time"
+
+	"github.com/ppiankov/kubenow/internal/promql"
 )
 
 // Workload type constants used in PromQL query construction
 const (
-	WorkloadTypeStatefulSet = "StatefulSet"
-	WorkloadTypePod         = "Pod"
+	WorkloadTypeStatefulSet = promql.KindStatefulSet
+	WorkloadTypePod         = promql.KindPod
 )
 
-// QueryBuilder constructs PromQL queries for common metrics
-type QueryBuilder struct{}
+// QueryBuilder constructs PromQL queries for common metrics. It is a
+// kubenow-specific facade over promql.Builder, which owns escaping, the
+// container matcher, extra label matchers and the rate window.
+type QueryBuilder struct {
+	b    *promql.Builder
+	opts []promql.Option
+}
 
 // NewQueryBuilder creates a new query builder
-func NewQueryBuilder() *QueryBuilder {
-	return &QueryBuilder{}
+func NewQueryBuilder(opts ...promql.Option) *QueryBuilder {
+	return &QueryBuilder{b: promql.NewBuilder(opts...), opts: opts}
+}
+
+// WithOwnerMetrics returns a copy of the builder that selects workload pods
+// through the available kube-state-metrics owner series.
+func (qb *QueryBuilder) WithOwnerMetrics(m promql.OwnerMetrics) *QueryBuilder {
+	opts := append(append([]promql.Option{}, qb.opts...), promql.WithOwnerMetrics(m))
+	return NewQueryBuilder(opts...)
+}
+
+func nsMatcher(namespace string) promql.Matcher {
+	return promql.Equal("namespace", namespace)
+}
+
+// namespaceMatchers scopes a query to a namespace; "" matches all namespaces.
+func namespaceMatchers(namespace string) []promql.Matcher {
+	if namespace == "" {
+		return nil
+	}
+	return []promql.Matcher{nsMatcher(namespace)}
 }
 
 // CPUUsageByNamespace returns a query for CPU usage by namespace
 func (qb *QueryBuilder) CPUUsageByNamespace(namespace string) string {
-	return `sum(rate(container_cpu_usage_seconds_total{namespace=` + escapeLabel(namespace) + `,container!="",container!="POD"}[5m])) by (namespace)`
+	return qb.b.CPUUsage([]promql.Matcher{nsMatcher(namespace)}, "namespace")
 }
 
-// CPUUsageByPod returns a query for CPU usage by pod
+// CPUUsageByPod returns a query for CPU usage by pod.
+// podPattern is a trusted regex built by the caller.
 func (qb *QueryBuilder) CPUUsageByPod(namespace, podPattern string) string {
-	return `sum(rate(container_cpu_usage_seconds_total{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(podPattern) + `,container!="",container!="POD"}[5m])) by (pod)`
+	return qb.b.CPUUsage([]promql.Matcher{nsMatcher(namespace), promql.Regex("pod", podPattern)}, "pod")
 }
 
 // MemoryUsageByNamespace returns a query for memory usage by namespace
 func (qb *QueryBuilder) MemoryUsageByNamespace(namespace string) string {
-	return `sum(container_memory_working_set_bytes{namespace=` + escapeLabel(namespace) + `,container!="",container!="POD"}) by (namespace)`
+	return qb.b.MemoryUsage([]promql.Matcher{nsMatcher(namespace)}, "namespace")
 }
 
-// MemoryUsageByPod returns a query for memory usage by pod
+// MemoryUsageByPod returns a query for memory usage by pod.
+// podPattern is a trusted regex built by the caller.
 func (qb *QueryBuilder) MemoryUsageByPod(namespace, podPattern string) string {
-	return `sum(container_memory_working_set_bytes{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(podPattern) + `,container!="",container!="POD"}) by (pod)`
+	return qb.b.MemoryUsage([]promql.Matcher{nsMatcher(namespace), promql.Regex("pod", podPattern)}, "pod")
 }
 
 // CPUAvgOverTime returns a query for average CPU usage over a time window
 func (qb *QueryBuilder) CPUAvgOverTime(namespace string, window time.Duration) string {
-	return `avg_over_time(sum(rate(container_cpu_usage_seconds_total{namespace=` + escapeLabel(namespace) + `,container!="",container!="POD"}[5m]))[` + formatDuration(window) + `:])`
+	return promql.AvgOverTime(qb.b.CPUUsage([]promql.Matcher{nsMatcher(namespace)}), window)
 }
 
 // MemoryAvgOverTime returns a query for average memory usage over a time window
 func (qb *QueryBuilder) MemoryAvgOverTime(namespace string, window time.Duration) string {
-	return `avg_over_time(sum(container_memory_working_set_bytes{namespace=` + escapeLabel(namespace) + `,container!="",container!="POD"})[` + formatDuration(window) + `:])`
+	return promql.AvgOverTime(qb.b.MemoryUsage([]promql.Matcher{nsMatcher(namespace)}), window)
 }
 
 // CPUQuantileOverTime returns a query for CPU usage at a specific percentile
 func (qb *QueryBuilder) CPUQuantileOverTime(namespace string, percentile float64, window time.Duration) string {
-	return fmt.Sprintf(`quantile_over_time(%.2f, sum(rate(container_cpu_usage_seconds_total{namespace=`+escapeLabel(namespace)+`,container!="",container!="POD"}[5m]))[`+formatDuration(window)+`:])`, percentile)
+	return promql.QuantileOverTime(percentile, qb.b.CPUUsage([]promql.Matcher{nsMatcher(namespace)}), window)
 }
 
 // MemoryQuantileOverTime returns a query for memory usage at a specific percentile
 func (qb *QueryBuilder) MemoryQuantileOverTime(namespace string, percentile float64, window time.Duration) string {
-	return fmt.Sprintf(`quantile_over_time(%.2f, sum(container_memory_working_set_bytes{namespace=`+escapeLabel(namespace)+`,container!="",container!="POD"})[`+formatDuration(window)+`:])`, percentile)
+	return promql.QuantileOverTime(percentile, qb.b.MemoryUsage([]promql.Matcher{nsMatcher(namespace)}), window)
 }
 
 // CPURequestsByNamespace returns a query for CPU requests by namespace
 func (qb *QueryBuilder) CPURequestsByNamespace(namespace string) string {
-	return `sum(kube_pod_container_resource_requests{namespace=` + escapeLabel(namespace) + `,resource="cpu"}) by (namespace)`
+	return qb.b.ResourceRequests("cpu", []promql.Matcher{nsMatcher(namespace)}, "namespace")
 }
 
 // MemoryRequestsByNamespace returns a query for memory requests by namespace
 func (qb *QueryBuilder) MemoryRequestsByNamespace(namespace string) string {
-	return `sum(kube_pod_container_resource_requests{namespace=` + escapeLabel(namespace) + `,resource="memory"}) by (namespace)`
+	return qb.b.ResourceRequests("memory", []promql.Matcher{nsMatcher(namespace)}, "namespace")
 }
 
 // CPURequestsByPod returns a query for CPU requests by pod
 func (qb *QueryBuilder) CPURequestsByPod(namespace, podPattern string) string {
-	return `sum(kube_pod_container_resource_requests{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(podPattern) + `,resource="cpu"}) by (pod)`
+	return qb.b.ResourceRequests("cpu", []promql.Matcher{nsMatcher(namespace), promql.Regex("pod", podPattern)}, "pod")
 }
 
 // MemoryRequestsByPod returns a query for memory requests by pod
 func (qb *QueryBuilder) MemoryRequestsByPod(namespace, podPattern string) string {
-	return `sum(kube_pod_container_resource_requests{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(podPattern) + `,resource="memory"}) by (pod)`
+	return qb.b.ResourceRequests("memory", []promql.Matcher{nsMatcher(namespace), promql.Regex("pod", podPattern)}, "pod")
+}
+
+// NamespacePodCPUUsage returns a query for the CPU usage of each pod in a namespace
+func (qb *QueryBuilder) NamespacePodCPUUsage(namespace string) string {
+	return qb.b.CPUUsage([]promql.Matcher{nsMatcher(namespace)}, "pod")
+}
+
+// NamespacePodMemoryUsage returns a query for the memory usage of each pod in a namespace
+func (qb *QueryBuilder) NamespacePodMemoryUsage(namespace string) string {
+	return qb.b.MemoryUsage([]promql.Matcher{nsMatcher(namespace)}, "pod")
+}
+
+// NamespacePodRequests returns a query for the CPU and memory requests of each pod in a namespace
+func (qb *QueryBuilder) NamespacePodRequests(namespace string) string {
+	return qb.b.PodResourceTotals(promql.MetricResourceRequests, []promql.Matcher{nsMatcher(namespace)})
+}
+
+// NamespacePodLimits returns a query for the CPU and memory limits of each pod in a namespace
+func (qb *QueryBuilder) NamespacePodLimits(namespace string) string {
+	return qb.b.PodResourceTotals(promql.MetricResourceLimits, []promql.Matcher{nsMatcher(namespace)})
+}
+
+// NamespacePodOwners returns a query for the owner of every pod of a namespace within the window
+func (qb *QueryBuilder) NamespacePodOwners(namespace string, window time.Duration) string {
+	return qb.b.OwnersOverTime(promql.MetricPodOwner, "pod", []promql.Matcher{nsMatcher(namespace)}, window)
+}
+
+// NamespaceReplicaSetOwners returns a query for the owner of every ReplicaSet of a namespace within the window
+func (qb *QueryBuilder) NamespaceReplicaSetOwners(namespace string, window time.Duration) string {
+	return qb.b.OwnersOverTime(promql.MetricReplicaSetOwner, "replicaset", []promql.Matcher{nsMatcher(namespace)}, window)
+}
+
+// Owners returns the owner metrics the builder selects workload pods through
+func (qb *QueryBuilder) Owners() promql.OwnerMetrics {
+	return qb.b.Owners()
 }
 
 // NodeCPUCapacity returns a query for total node CPU capacity
 func (qb *QueryBuilder) NodeCPUCapacity() string {
-	return `sum(kube_node_status_capacity{resource="cpu"})`
+	return promql.Sum(qb.b.Selector("kube_node_status_capacity", promql.Equal("resource", "cpu")).String())
 }
 
 // NodeMemoryCapacity returns a query for total node memory capacity
 func (qb *QueryBuilder) NodeMemoryCapacity() string {
-	return `sum(kube_node_status_capacity{resource="memory"})`
+	return promql.Sum(qb.b.Selector("kube_node_status_capacity", promql.Equal("resource", "memory")).String())
+}
+
+// NodeCount returns a query for the number of nodes
+func (qb *QueryBuilder) NodeCount() string {
+	return promql.Count(qb.b.Selector("kube_node_info").String())
+}
+
+// ClusterCPUUsage returns a query for cluster-wide container CPU usage
+func (qb *QueryBuilder) ClusterCPUUsage() string {
+	return qb.b.CPUUsage(nil)
+}
+
+// ClusterMemoryUsage returns a query for cluster-wide container memory usage
+func (qb *QueryBuilder) ClusterMemoryUsage() string {
+	return qb.b.MemoryUsage(nil)
+}
+
+// NodeCPUQuantile returns a query for each node's container CPU usage at a quantile over a time window
+func (qb *QueryBuilder) NodeCPUQuantile(quantile float64, window time.Duration) string {
+	return promql.QuantileOverTime(quantile, qb.b.NodeCPUUsage(), window)
+}
+
+// NodeMemoryQuantile returns a query for each node's container memory usage at a quantile over a time window
+func (qb *QueryBuilder) NodeMemoryQuantile(quantile float64, window time.Duration) string {
+	return promql.QuantileOverTime(quantile, qb.b.NodeMemoryUsage(), window)
+}
+
+// NodeCPUAvg returns a query for each node's average container CPU usage over a time window
+func (qb *QueryBuilder) NodeCPUAvg(window time.Duration) string {
+	return promql.AvgOverTime(qb.b.NodeCPUUsage(), window)
+}
+
+// NodeMemoryAvg returns a query for each node's average container memory usage over a time window
+func (qb *QueryBuilder) NodeMemoryAvg(window time.Duration) string {
+	return promql.AvgOverTime(qb.b.NodeMemoryUsage(), window)
+}
+
+// PodCPUQuantile returns a query for every pod's CPU usage at a quantile over a time window
+func (qb *QueryBuilder) PodCPUQuantile(quantile float64, window time.Duration) string {
+	return promql.QuantileOverTime(quantile, qb.b.CPUUsage(nil, "namespace", "pod"), window)
+}
+
+// PodMemoryQuantile returns a query for every pod's memory usage at a quantile over a time window
+func (qb *QueryBuilder) PodMemoryQuantile(quantile float64, window time.Duration) string {
+	return promql.QuantileOverTime(quantile, qb.b.MemoryUsage(nil, "namespace", "pod"), window)
+}
+
+// NamespaceCPUSeriesCount returns a query counting container CPU series in a namespace
+func (qb *QueryBuilder) NamespaceCPUSeriesCount(namespace string) string {
+	return promql.Count(qb.b.ContainerSelector(promql.MetricContainerCPU, nsMatcher(namespace)).String())
+}
+
+// SeriesCount returns a query counting the series of a metric (scoped by the
+// builder's extra matchers, e.g. the cluster label)
+func (qb *QueryBuilder) SeriesCount(metric string) string {
+	return promql.Count(qb.b.Selector(metric).String())
 }
 
-// workloadPodPattern returns a regex pattern for matching pods belonging to a workload
+// workloadPodPattern returns the pod-name regex for a workload (name regex-escaped)
 func workloadPodPattern(workloadName, workloadType string) string {
-	switch workloadType {
-	case WorkloadTypeStatefulSet:
-		return workloadName + "-[0-9]+"
-	case WorkloadTypePod:
-		return workloadName
-	default:
-		// Deployment, DaemonSet, and others use replicaset-hash suffix
-		return workloadName + "-.*"
-	}
+	return promql.WorkloadPods(workloadName, workloadType).Value
 }
 
 // WorkloadCPURequests returns a query for total CPU requests across all pods of a workload
 func (qb *QueryBuilder) WorkloadCPURequests(namespace, workloadName, workloadType string) string {
-	pattern := workloadPodPattern(workloadName, workloadType)
-	return `sum(kube_pod_container_resource_requests{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(pattern) + `,resource="cpu"})`
+	return qb.b.WorkloadRequests("cpu", qb.b.PodsOf(namespace, workloadName, workloadType))
 }
 
 // WorkloadMemoryRequests returns a query for total memory requests across all pods of a workload
 func (qb *QueryBuilder) WorkloadMemoryRequests(namespace, workloadName, workloadType string) string {
-	pattern := workloadPodPattern(workloadName, workloadType)
-	return `sum(kube_pod_container_resource_requests{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(pattern) + `,resource="memory"})`
+	return qb.b.WorkloadRequests("memory", qb.b.PodsOf(namespace, workloadName, workloadType))
 }
 
 // WorkloadCPULimits returns a query for total CPU limits across all pods of a workload
 func (qb *QueryBuilder) WorkloadCPULimits(namespace, workloadName, workloadType string) string {
-	pattern := workloadPodPattern(workloadName, workloadType)
-	return `sum(kube_pod_container_resource_limits{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(pattern) + `,resource="cpu"})`
+	return qb.b.WorkloadLimits("cpu", qb.b.PodsOf(namespace, workloadName, workloadType))
 }
 
 // WorkloadMemoryLimits returns a query for total memory limits across all pods of a workload
 func (qb *QueryBuilder) WorkloadMemoryLimits(namespace, workloadName, workloadType string) string {
-	pattern := workloadPodPattern(workloadName, workloadType)
-	return `sum(kube_pod_container_resource_limits{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeLabel(pattern) + `,resource="memory"})`
+	return qb.b.WorkloadLimits("memory", qb.b.PodsOf(namespace, workloadName, workloadType))
 }
 
-// escapeLabel escapes a string for use in a PromQL label equality matcher (=).
-// Escapes backslashes, double quotes, and newlines.
-func escapeLabel(s string) string {
-	s = strings.ReplaceAll(s, `\`, `\\`)
-	s = strings.ReplaceAll(s, `"`, `\"`)
-	s = strings.ReplaceAll(s, "\n", `\n`)
-	return `"` + s + `"`
+// IsBatchKind reports whether a workload kind runs to completion (Job, CronJob).
+func IsBatchKind(workloadType string) bool {
+	return workloadType == promql.KindJob || workloadType == promql.KindCronJob
 }
 
-// escapeRegex escapes a string for safe embedding in a PromQL regex matcher (=~).
-// The workload/namespace part is escaped for regex metacharacters; the pattern
-// suffix (e.g., "-.*", "-[0-9]+") is appended after escaping.
-func escapeRegex(name, patternSuffix string) string {
-	// Escape regex metacharacters in the name portion
-	metacharReplacer := strings.NewReplacer(
-		`\`, `\\`,
-		`.`, `\.`,
-		`*`, `\*`,
-		`+`, `\+`,
-		`?`, `\?`,
-		`(`, `\(`,
-		`)`, `\)`,
-		`[`, `\[`,
-		`]`, `\]`,
-		`{`, `\{`,
-		`}`, `\}`,
-		`|`, `\|`,
-		`^`, `\^`,
-		`$`, `\$`,
-		`"`, `\"`,
-		"\n", `\n`,
-	)
-	escaped := metacharReplacer.Replace(name)
-	return `"` + escaped + patternSuffix + `"`
+// runningPods selects a workload's pods that are in the Running phase.
+func (qb *QueryBuilder) runningPods(namespace, workloadName, workloadType string) promql.PodFilter {
+	return qb.b.Running(qb.b.PodsOf(namespace, workloadName, workloadType))
 }
 
-// NodeCount returns a query for the number of nodes
-func (qb *QueryBuilder) NodeCount() string {
-	return `count(kube_node_info)`
+// RunningWorkloadCPUUsage returns a workload's peak CPU usage within each
+// step, counting only Running pods, so a range query has points only while
+// the workload runs and short runs are not sampled away.
+func (qb *QueryBuilder) RunningWorkloadCPUUsage(namespace, workloadName, workloadType string, step time.Duration) string {
+	return promql.MaxOverTime(qb.b.WorkloadCPUUsage(qb.runningPods(namespace, workloadName, workloadType)), step)
+}
+
+// RunningWorkloadMemoryUsage returns a workload's peak memory usage within
+// each step, counting only Running pods.
+func (qb *QueryBuilder) RunningWorkloadMemoryUsage(namespace, workloadName, workloadType string, step time.Duration) string {
+	return promql.MaxOverTime(qb.b.WorkloadMemoryUsage(qb.runningPods(namespace, workloadName, workloadType)), step)
+}
+
+// RunningWorkloadRequests returns the peak summed requests (cpu|memory) of a
+// workload's Running pods within each step.
+func (qb *QueryBuilder) RunningWorkloadRequests(resource, namespace, workloadName, workloadType string, step time.Duration) string {
+	return promql.MaxOverTime(qb.b.WorkloadRequests(resource, qb.runningPods(namespace, workloadName, workloadType)), step)
+}
+
+// RunningWorkloadLimits returns the peak summed limits (cpu|memory) of a
+// workload's Running pods within each step.
+func (qb *QueryBuilder) RunningWorkloadLimits(resource, namespace, workloadName, workloadType string, step time.Duration) string {
+	return promql.MaxOverTime(qb.b.WorkloadLimits(resource, qb.runningPods(namespace, workloadName, workloadType)), step)
+}
+
+// escapeLabel quotes a string for use in a PromQL label matcher.
+func escapeLabel(s string) string {
+	return promql.Quote(s)
+}
+
+// escapeRegex quotes a name for a PromQL regex matcher (=~): the name is
+// regex-escaped, the trusted pattern suffix (e.g., "-.*", "-[0-9]+") is
+// appended, and the result is quoted as a PromQL string.
+func escapeRegex(name, patternSuffix string) string {
+	return promql.Quote(promql.QuoteRegexLiteral(name) + patternSuffix)
 }
 
 // PodStartTime returns a query for pod start time
 func (qb *QueryBuilder) PodStartTime(namespace, podName string) string {
-	return `kube_pod_start_time{namespace=` + escapeLabel(namespace) + `,pod=` + escapeLabel(podName) + `}`
+	return qb.b.Selector("kube_pod_start_time", nsMatcher(namespace), promql.Equal("pod", podName)).String()
 }
 
 // WorkloadCPUUsage returns a query for workload CPU usage (aggregated by deployment/statefulset)
 func (qb *QueryBuilder) WorkloadCPUUsage(namespace, workloadName, workloadType string) string {
-	ns := escapeLabel(namespace)
-	switch workloadType {
-	case "Deployment":
-		return `sum(rate(container_cpu_usage_seconds_total{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, "-.*") + `,container!="",container!="POD"}[5m]))`
-	case "StatefulSet":
-		return `sum(rate(container_cpu_usage_seconds_total{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, "-[0-9]+") + `,container!="",container!="POD"}[5m]))`
-	case "DaemonSet":
-		return `sum(rate(container_cpu_usage_seconds_total{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, "-.*") + `,container!="",container!="POD"}[5m]))`
-	case "Pod":
-		return `sum(rate(container_cpu_usage_seconds_total{namespace=` + ns + `,pod=` + escapeLabel(workloadName) + `,container!="",container!="POD"}[5m]))`
-	default:
-		return `sum(rate(container_cpu_usage_seconds_total{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, ".*") + `,container!="",container!="POD"}[5m]))`
-	}
+	return qb.b.WorkloadCPUUsage(qb.b.PodsOf(namespace, workloadName, workloadType))
 }
 
 // WorkloadMemoryUsage returns a query for workload memory usage
 func (qb *QueryBuilder) WorkloadMemoryUsage(namespace, workloadName, workloadType string) string {
-	ns := escapeLabel(namespace)
-	switch workloadType {
-	case "Deployment":
-		return `sum(container_memory_working_set_bytes{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, "-.*") + `,container!="",container!="POD"})`
-	case "StatefulSet":
-		return `sum(container_memory_working_set_bytes{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, "-[0-9]+") + `,container!="",container!="POD"})`
-	case "DaemonSet":
-		return `sum(container_memory_working_set_bytes{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, "-.*") + `,container!="",container!="POD"})`
-	case "Pod":
-		return `sum(container_memory_working_set_bytes{namespace=` + ns + `,pod=` + escapeLabel(workloadName) + `,container!="",container!="POD"})`
-	default:
-		return `sum(container_memory_working_set_bytes{namespace=` + ns + `,pod=~` + escapeRegex(workloadName, ".*") + `,container!="",container!="POD"})`
-	}
+	return qb.b.WorkloadMemoryUsage(qb.b.PodsOf(namespace, workloadName, workloadType))
 }
 
 // formatDuration converts a Go duration to Prometheus duration format
 func formatDuration(d time.Duration) string {
-	switch {
-	case d < time.Minute:
-		return fmt.Sprintf("%ds", int(d.Seconds()))
-	case d < time.Hour:
-		return fmt.Sprintf("%dm", int(d.Minutes()))
-	case d < 24*time.Hour:
-		return fmt.Sprintf("%dh", int(d.Hours()))
-	default:
-		return fmt.Sprintf("%dd", int(d.Hours()/24))
-	}
+	return promql.Duration(d)
 }
 
 // maxDurationDays is the upper bound for parsed durations (1 year).
@@ -276,55 +355,118 @@ func ParseDuration(s string) (time.Duration, error) {
 
 // OOMKillsByWorkload returns a query for OOM kills for a workload over time window
 func (qb *QueryBuilder) OOMKillsByWorkload(namespace, workloadName string, window time.Duration) string {
-	return `sum(increase(kube_pod_container_status_restarts_total{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeRegex(workloadName, ".*") + `}[` + formatDuration(window) + `])) by (pod)`
+	return qb.b.OOMKills([]promql.Matcher{nsMatcher(namespace), promql.PodPrefix(workloadName)}, window)
 }
 
 // RestartsByWorkload returns a query for total container restarts for a workload
 func (qb *QueryBuilder) RestartsByWorkload(namespace, workloadName string, window time.Duration) string {
-	return `sum(increase(kube_pod_container_status_restarts_total{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeRegex(workloadName, ".*") + `}[` + formatDuration(window) + `]))`
+	return qb.b.Restarts([]promql.Matcher{nsMatcher(namespace), promql.PodPrefix(workloadName)}, window)
 }
 
 // CPUThrottledByWorkload returns a query for CPU throttling time for a workload
 func (qb *QueryBuilder) CPUThrottledByWorkload(namespace, workloadName string, window time.Duration) string {
-	return `sum(increase(container_cpu_cfs_throttled_seconds_total{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeRegex(workloadName, ".*") + `,container!="",container!="POD"}[` + formatDuration(window) + `]))`
+	return qb.b.Throttled([]promql.Matcher{nsMatcher(namespace), promql.PodPrefix(workloadName)}, window)
+}
+
+// CPUThrottledPercentByWorkload returns the percentage of a workload's CFS
+// periods in which it was throttled over the time window
+func (qb *QueryBuilder) CPUThrottledPercentByWorkload(namespace, workloadName, workloadType string, window time.Duration) string {
+	return "(" + qb.b.WorkloadThrottledRatio(qb.b.PodsOf(namespace, workloadName, workloadType), window) + ") * 100"
+}
+
+// ContainerThrottledPeriods returns a query for the throttled CFS periods of
+// every container over a time window, by namespace, pod, and container
+func (qb *QueryBuilder) ContainerThrottledPeriods(namespace string, window time.Duration) string {
+	return qb.b.ThrottledPeriods(namespaceMatchers(namespace), window, "namespace", "pod", "container")
+}
+
+// ContainerPeriods returns a query for the CFS periods of every container
+// over a time window, by namespace, pod, and container
+func (qb *QueryBuilder) ContainerPeriods(namespace string, window time.Duration) string {
+	return qb.b.Periods(namespaceMatchers(namespace), window, "namespace", "pod", "container")
+}
+
+// ContainerCPUQuantile returns a query for every container's CPU usage at a
+// quantile over a time window, by namespace, pod, and container
+func (qb *QueryBuilder) ContainerCPUQuantile(namespace string, quantile float64, window time.Duration) string {
+	return promql.QuantileOverTime(quantile, qb.b.CPUUsage(namespaceMatchers(namespace), "namespace", "pod", "container"), window)
 }
 
-// CPUThrottledPercentByWorkload returns CPU throttling as percentage of time window
-func (qb *QueryBuilder) CPUThrottledPercentByWorkload(namespace, workloadName string, window time.Duration) string {
-	windowSeconds := window.Seconds()
-	return fmt.Sprintf(`(sum(increase(container_cpu_cfs_throttled_seconds_total{namespace=`+escapeLabel(namespace)+`,pod=~`+escapeRegex(workloadName, ".*")+`,container!="",container!="POD"}[`+formatDuration(window)+`])) / %f) * 100`, windowSeconds)
+// VolumeCapacity returns a query for the filesystem capacity of every
+// mounted PersistentVolumeClaim, by namespace and persistentvolumeclaim
+func (qb *QueryBuilder) VolumeCapacity(namespace string) string {
+	return qb.b.VolumeStats(promql.MetricVolumeCapacity, namespaceMatchers(namespace))
+}
+
+// VolumeUsed returns a query for the bytes currently used on every mounted
+// PersistentVolumeClaim, by namespace and persistentvolumeclaim
+func (qb *QueryBuilder) VolumeUsed(namespace string) string {
+	return qb.b.VolumeStats(promql.MetricVolumeUsed, namespaceMatchers(namespace))
+}
+
+// MaxVolumeUsed returns a query for the peak bytes used on every mounted
+// PersistentVolumeClaim over a time window
+func (qb *QueryBuilder) MaxVolumeUsed(namespace string, window time.Duration) string {
+	return promql.MaxOverTime(qb.VolumeUsed(namespace), window)
+}
+
+// MaxContainerFSUsage returns a query for every container's peak ephemeral
+// storage usage over a time window, by namespace, pod, and container
+func (qb *QueryBuilder) MaxContainerFSUsage(namespace string, window time.Duration) string {
+	return promql.MaxOverTime(qb.b.ContainerFSUsage(namespaceMatchers(namespace), "namespace", "pod", "container"), window)
+}
+
+// PodNetworkReceiveRate returns a query for the bytes per second received
+// by the pods of a namespace whose name matches podPattern, by pod
+func (qb *QueryBuilder) PodNetworkReceiveRate(namespace, podPattern string, window time.Duration) string {
+	return qb.b.PodNetworkRate(promql.MetricNetworkReceive,
+		[]promql.Matcher{nsMatcher(namespace), promql.Regex("pod", podPattern)}, window)
+}
+
+// PodNetworkTransmitRate returns a query for the bytes per second
+// transmitted by the pods of a namespace whose name matches podPattern, by pod
+func (qb *QueryBuilder) PodNetworkTransmitRate(namespace, podPattern string, window time.Duration) string {
+	return qb.b.PodNetworkRate(promql.MetricNetworkTransmit,
+		[]promql.Matcher{nsMatcher(namespace), promql.Regex("pod", podPattern)}, window)
+}
+
+// HPAReplicas returns a query for an HPA's current replica count
+func (qb *QueryBuilder) HPAReplicas(namespace, hpaName string) string {
+	return qb.b.HPAReplicas(namespace, hpaName)
 }
 
 // MaxCPUUsageByWorkload returns max CPU usage for a workload in time window
 func (qb *QueryBuilder) MaxCPUUsageByWorkload(namespace, workloadName, workloadType string, window time.Duration) string {
-	baseQuery := qb.WorkloadCPUUsage(namespace, workloadName, workloadType)
-	return fmt.Sprintf(`max_over_time((%s)[%s:])`, baseQuery, formatDuration(window))
+	return promql.MaxOverTime(qb.WorkloadCPUUsage(namespace, workloadName, workloadType), window)
+}
+
+// MaxContainerMemoryByWorkload returns the peak working set of each of a
+// workload's containers (hottest replica) within each window.
+func (qb *QueryBuilder) MaxContainerMemoryByWorkload(namespace, workloadName, workloadType string, window time.Duration) string {
+	return promql.MaxOverTime(qb.b.WorkloadContainerMemory(qb.b.PodsOf(namespace, workloadName, workloadType)), window)
 }
 
 // MaxMemoryUsageByWorkload returns max memory usage for a workload in time window
 func (qb *QueryBuilder) MaxMemoryUsageByWorkload(namespace, workloadName, workloadType string, window time.Duration) string {
-	baseQuery := qb.WorkloadMemoryUsage(namespace, workloadName, workloadType)
-	return fmt.Sprintf(`max_over_time((%s)[%s:])`, baseQuery, formatDuration(window))
+	return promql.MaxOverTime(qb.WorkloadMemoryUsage(namespace, workloadName, workloadType), window)
 }
 
 // CPUP999ByWorkload returns 99.9th percentile CPU usage for a workload
 func (qb *QueryBuilder) CPUP999ByWorkload(namespace, workloadName, workloadType string, window time.Duration) string {
-	baseQuery := qb.WorkloadCPUUsage(namespace, workloadName, workloadType)
-	return fmt.Sprintf(`quantile_over_time(0.999, (%s)[%s:])`, baseQuery, formatDuration(window))
+	return promql.QuantileOverTime(0.999, qb.WorkloadCPUUsage(namespace, workloadName, workloadType), window)
 }
 
 // MemoryP999ByWorkload returns 99.9th percentile memory usage for a workload
 func (qb *QueryBuilder) MemoryP999ByWorkload(namespace, workloadName, workloadType string, window time.Duration) string {
-	baseQuery := qb.WorkloadMemoryUsage(namespace, workloadName, workloadType)
-	return fmt.Sprintf(`quantile_over_time(0.999, (%s)[%s:])`, baseQuery, formatDuration(window))
+	return promql.QuantileOverTime(0.999, qb.WorkloadMemoryUsage(namespace, workloadName, workloadType), window)
 }
 
 // PodStatusByWorkload returns current pod status for a workload
 func (qb *QueryBuilder) PodStatusByWorkload(namespace, workloadName string) string {
-	return `kube_pod_status_phase{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeRegex(workloadName, ".*") + `}`
+	return qb.b.Selector("kube_pod_status_phase", nsMatcher(namespace), promql.PodPrefix(workloadName)).String()
 }
 
 // LastTerminatedReasonByWorkload returns the last container termination reason
 func (qb *QueryBuilder) LastTerminatedReasonByWorkload(namespace, workloadName string) string {
-	return `kube_pod_container_status_last_terminated_reason{namespace=` + escapeLabel(namespace) + `,pod=~` + escapeRegex(workloadName, ".*") + `}`
+	return qb.b.Selector("kube_pod_container_status_last_terminated_reason", nsMatcher(namespace), promql.PodPrefix(workloadName)).String()
 }
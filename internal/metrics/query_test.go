@@ -26,6 +26,9 @@ func TestEscapeLabel(t *testing.T) {
 	}
 }
 
+// Regex escapes are themselves escaped inside the PromQL string literal:
+// PromQL rejects unknown string escapes such as \. so the regex \. must be
+// written as \\. between double quotes.
 func TestEscapeRegex(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -33,10 +36,10 @@ func TestEscapeRegex(t *testing.T) {
 		expected string
 	}{
 		{"myapp", "-.*", `"myapp-.*"`},
-		{"my.app", "-.*", `"my\.app-.*"`},
+		{"my.app", "-.*", `"my\\.app-.*"`},
 		{`app"inject`, "-[0-9]+", `"app\"inject-[0-9]+"`},
-		{"app+plus", "-.*", `"app\+plus-.*"`},
-		{"app(parens)", "-.*", `"app\(parens\)-.*"`},
+		{"app+plus", "-.*", `"app\\+plus-.*"`},
+		{"app(parens)", "-.*", `"app\\(parens\\)-.*"`},
 	}
 
 	for _, tt := range tests {
@@ -58,7 +61,7 @@ func TestQueryBuilder_InjectionSafe(t *testing.T) {
 	// Workload name with regex metacharacters
 	maliciousWL := `app.*all`
 	query = qb.WorkloadCPUUsage("prod", maliciousWL, "Deployment")
-	assert.Contains(t, query, `app\.\*all`)
+	assert.Contains(t, query, `app\\.\\*all`)
 }
 
 func TestQueryBuilder_CPUUsageByNamespace(t *testing.T) {
@@ -113,6 +116,52 @@ func TestQueryBuilder_WorkloadCPUUsage(t *testing.T) {
 	}
 }
 
+func TestQueryBuilder_MaxContainerMemoryByWorkload(t *testing.T) {
+	query := NewQueryBuilder().MaxContainerMemoryByWorkload("production", "payment-api", "Deployment", 5*time.Minute)
+	assert.Equal(t,
+		`max_over_time((max(container_memory_working_set_bytes{namespace="production",pod=~"payment-api-.*",container!="",container!="POD"}) by (container))[5m:])`,
+		query)
+}
+
+func TestQueryBuilder_ContainerThrottling(t *testing.T) {
+	qb := NewQueryBuilder()
+	assert.Equal(t,
+		`sum(increase(container_cpu_cfs_periods_total{container!="",container!="POD"}[1h])) by (namespace, pod, container)`,
+		qb.ContainerPeriods("", time.Hour))
+	assert.Equal(t,
+		`sum(increase(container_cpu_cfs_throttled_periods_total{namespace="prod",container!="",container!="POD"}[1h])) by (namespace, pod, container)`,
+		qb.ContainerThrottledPeriods("prod", time.Hour))
+	assert.Contains(t, qb.CPUThrottledPercentByWorkload("prod", "api", "Deployment", time.Hour),
+		`(sum(increase(container_cpu_cfs_throttled_periods_total{namespace="prod",pod=~"api-.*",container!="",container!="POD"}[1h])) / `)
+}
+
+func TestQueryBuilder_StorageUsage(t *testing.T) {
+	qb := NewQueryBuilder()
+	assert.Equal(t,
+		`max(kubelet_volume_stats_capacity_bytes) by (namespace, persistentvolumeclaim)`,
+		qb.VolumeCapacity(""))
+	assert.Equal(t,
+		`max_over_time((max(kubelet_volume_stats_used_bytes{namespace="prod"}) by (namespace, persistentvolumeclaim))[1h:])`,
+		qb.MaxVolumeUsed("prod", time.Hour))
+	assert.Equal(t,
+		`max_over_time((sum(container_fs_usage_bytes{namespace="prod",container!="",container!="POD"}) by (namespace, pod, container))[1h:])`,
+		qb.MaxContainerFSUsage("prod", time.Hour))
+}
+
+func TestQueryBuilder_PodNetworkRate(t *testing.T) {
+	qb := NewQueryBuilder()
+	assert.Equal(t,
+		`sum(max(rate(container_network_receive_bytes_total{namespace="prod",pod=~"api-1|api-2"}[1m])) by (pod, interface)) by (pod)`,
+		qb.PodNetworkReceiveRate("prod", "api-1|api-2", time.Minute))
+	assert.Contains(t, qb.PodNetworkTransmitRate("prod", "api-1", time.Minute), "container_network_transmit_bytes_total")
+}
+
+func TestQueryBuilder_HPAReplicas(t *testing.T) {
+	assert.Equal(t,
+		`max(kube_horizontalpodautoscaler_status_current_replicas{namespace="production",horizontalpodautoscaler="payment-api"})`,
+		NewQueryBuilder().HPAReplicas("production", "payment-api"))
+}
+
 func TestFormatDuration(t *testing.T) {
 	tests := []struct {
 		duration time.Duration
@@ -235,6 +284,7 @@ func TestWorkloadPodPattern(t *testing.T) {
 		{"StatefulSet", "StatefulSet", "myapp-[0-9]+"},
 		{"DaemonSet", "DaemonSet", "myapp-.*"},
 		{"Pod", "Pod", "myapp"},
+		{"CronJob", "CronJob", "myapp-[0-9]+-.*"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// Built-in metrics backends. All speak the Prometheus HTTP API; they differ
+// in URL layout, tenancy and query parameters.
+const (
+	BackendPrometheus      = "prometheus"
+	BackendVictoriaMetrics = "victoriametrics"
+	BackendThanos          = "thanos"
+	BackendMimir           = "mimir"
+)
+
+// APIProvider is implemented by providers backed by a Prometheus-compatible
+// HTTP API, enabling metric discovery and raw queries.
+type APIProvider interface {
+	GetAPI() v1.API
+}
+
+// ProviderFactory creates a MetricsProvider from a Config.
+type ProviderFactory func(config Config) (MetricsProvider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]ProviderFactory{}
+)
+
+func init() {
+	for _, d := range builtinDialects() {
+		RegisterProvider(d.Name, func(config Config) (MetricsProvider, error) {
+			config.Backend = d.Name
+			return NewPrometheusClient(config)
+		})
+	}
+}
+
+// RegisterProvider registers a metrics backend under name, replacing any
+// existing registration with the same name.
+func RegisterProvider(name string, factory ProviderFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(name)] = factory
+}
+
+// Backends returns the sorted names of all registered backends.
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider creates a MetricsProvider for config.Backend (default: prometheus).
+func NewProvider(config Config) (MetricsProvider, error) {
+	backend := strings.ToLower(config.Backend)
+	if backend == "" {
+		backend = BackendPrometheus
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[backend]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown metrics backend %q (supported: %s)", config.Backend, strings.Join(Backends(), ", "))
+	}
+	return factory(config)
+}
+
+// Dialect describes how a Prometheus-compatible backend deviates from
+// vanilla Prometheus.
+type Dialect struct {
+	Name string
+
+	// PathSuffix is appended to the base URL path when not already present
+	// (e.g. Mimir serves the Prometheus API under /prometheus).
+	PathSuffix string
+
+	// TenantPath is the path used when a tenant is given and the base URL has
+	// no path of its own; %s is replaced with the tenant ID.
+	TenantPath string
+
+	// TenantHeader carries the tenant ID on every request.
+	TenantHeader string
+
+	// Params are added to every API request.
+	Params url.Values
+
+	// QueryHealthCheck uses an instant query instead of the runtimeinfo
+	// endpoint, which not every backend implements.
+	QueryHealthCheck bool
+}
+
+func builtinDialects() []*Dialect {
+	return []*Dialect{
+		{Name: BackendPrometheus},
+		{
+			// vmselect (cluster) serves per-tenant APIs under /select/<accountID>/prometheus;
+			// single-node VictoriaMetrics serves /api/v1 at the root. MetricsQL is a
+			// superset of PromQL, so queries are sent unchanged.
+			Name:             BackendVictoriaMetrics,
+			TenantPath:       "/select/%s/prometheus",
+			QueryHealthCheck: true,
+		},
+		{
+			// Deduplicate HA replicas, fail instead of returning partial data, and
+			// let the querier pick downsampled blocks for long windows.
+			Name: BackendThanos,
+			Params: url.Values{
+				"dedup":                 {"true"},
+				"partial_response":      {"false"},
+				"max_source_resolution": {"auto"},
+			},
+		},
+		{
+			Name:             BackendMimir,
+			PathSuffix:       "/prometheus",
+			TenantHeader:     "X-Scope-OrgID",
+			QueryHealthCheck: true,
+		},
+	}
+}
+
+// DialectFor returns the dialect for a backend name. Unknown or empty names
+// get the vanilla Prometheus dialect.
+func DialectFor(backend string) *Dialect {
+	for _, d := range builtinDialects() {
+		if d.Name == strings.ToLower(backend) {
+			return d
+		}
+	}
+	return &Dialect{Name: BackendPrometheus}
+}
+
+// resolveURL applies the dialect's path conventions to the base URL.
+func (d *Dialect) resolveURL(raw, tenant string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid Prometheus URL: %w", err)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+
+	switch {
+	case d.TenantPath != "" && tenant != "" && path == "":
+		path = fmt.Sprintf(d.TenantPath, url.PathEscape(tenant))
+	case d.TenantPath != "" && strings.HasPrefix(path, "/select/") && !strings.HasSuffix(path, "/prometheus"):
+		path += "/prometheus"
+	case d.PathSuffix != "" && !strings.HasSuffix(path, d.PathSuffix):
+		path += d.PathSuffix
+	}
+
+	u.Path = path
+	return u.String(), nil
+}
+
+// dialectTransport injects dialect-specific headers and parameters.
+type dialectTransport struct {
+	base    http.RoundTripper
+	dialect *Dialect
+	tenant  string
+}
+
+func (t *dialectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.dialect.TenantHeader != "" && t.tenant != "" {
+		req.Header.Set(t.dialect.TenantHeader, t.tenant)
+	}
+	if len(t.dialect.Params) > 0 {
+		q := req.URL.Query()
+		for k, vs := range t.dialect.Params {
+			if q.Get(k) == "" {
+				for _, v := range vs {
+					q.Add(k, v)
+				}
+			}
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	return t.base.RoundTrip(req)
+}
+
+// roundTripper returns the transport to use for the dialect, or nil when the
+// default transport is sufficient.
+func (d *Dialect) roundTripper(tenant string) http.RoundTripper {
+	if len(d.Params) == 0 && (d.TenantHeader == "" || tenant == "") {
+		return nil
+	}
+	return &dialectTransport{base: api.DefaultRoundTripper, dialect: d, tenant: tenant}
+}
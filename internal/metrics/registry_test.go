@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackends_Builtins(t *testing.T) {
+	backends := Backends()
+	for _, name := range []string{BackendPrometheus, BackendVictoriaMetrics, BackendThanos, BackendMimir} {
+		assert.Contains(t, backends, name)
+	}
+}
+
+func TestNewProvider_UnknownBackend(t *testing.T) {
+	_, err := NewProvider(Config{PrometheusURL: "http://localhost:9090", Backend: "graphite"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown metrics backend")
+}
+
+func TestNewProvider_DefaultsToPrometheus(t *testing.T) {
+	p, err := NewProvider(Config{PrometheusURL: "http://localhost:9090"})
+	require.NoError(t, err)
+	client, ok := p.(*PrometheusClient)
+	require.True(t, ok)
+	assert.Equal(t, BackendPrometheus, client.dialect.Name)
+}
+
+func TestRegisterProvider_Custom(t *testing.T) {
+	called := false
+	RegisterProvider("Custom-Test", func(config Config) (MetricsProvider, error) {
+		called = true
+		return NewMockMetrics(), nil
+	})
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "custom-test")
+		registryMu.Unlock()
+	}()
+
+	_, err := NewProvider(Config{Backend: "custom-test"})
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestDialect_ResolveURL(t *testing.T) {
+	tests := []struct {
+		backend string
+		url     string
+		tenant  string
+		want    string
+	}{
+		{BackendPrometheus, "http://prom:9090", "", "http://prom:9090"},
+		{BackendMimir, "http://mimir:8080", "team-a", "http://mimir:8080/prometheus"},
+		{BackendMimir, "http://mimir:8080/prometheus/", "", "http://mimir:8080/prometheus"},
+		{BackendVictoriaMetrics, "http://vm:8428", "", "http://vm:8428"},
+		{BackendVictoriaMetrics, "http://vmselect:8481", "42", "http://vmselect:8481/select/42/prometheus"},
+		{BackendVictoriaMetrics, "http://vmselect:8481/select/0", "", "http://vmselect:8481/select/0/prometheus"},
+		{BackendThanos, "http://thanos-query:9090", "", "http://thanos-query:9090"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend+" "+tt.url, func(t *testing.T) {
+			got, err := DialectFor(tt.backend).resolveURL(tt.url, tt.tenant)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewProvider_ThanosParamsAndMimirTenant(t *testing.T) {
+	var gotQuery, gotTenant, gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		gotTenant = r.Header.Get("X-Scope-OrgID")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer srv.Close()
+
+	thanos, err := NewProvider(Config{PrometheusURL: srv.URL, Backend: BackendThanos})
+	require.NoError(t, err)
+	_, err = thanos.QueryInstant(context.Background(), "up", time.Now())
+	require.NoError(t, err)
+	assert.Contains(t, gotQuery, "dedup=true")
+	assert.Contains(t, gotQuery, "partial_response=false")
+
+	mimir, err := NewProvider(Config{PrometheusURL: srv.URL, Backend: BackendMimir, TenantID: "team-a"})
+	require.NoError(t, err)
+	require.NoError(t, mimir.Health(context.Background()))
+	assert.Equal(t, "team-a", gotTenant)
+	assert.Equal(t, "/prometheus/api/v1/query", gotPath)
+}
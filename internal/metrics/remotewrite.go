@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// maxRemoteWriteSamples bounds the samples sent in one remote_write request.
+	maxRemoteWriteSamples = 10000
+	// defaultRemoteWriteTimeout is used when RemoteWriter.Timeout is unset.
+	defaultRemoteWriteTimeout = 30 * time.Second
+)
+
+// DefaultRemoteWriteInterval is how often latch samples are pushed while
+// the latch runs, keeping them inside the receiving TSDB's head window.
+const DefaultRemoteWriteInterval = time.Minute
+
+// RemoteWriter pushes recorded latch series to a Prometheus remote_write
+// endpoint (Prometheus with --web.enable-remote-write-receiver, Mimir,
+// Thanos Receive, VictoriaMetrics). It remembers the newest pushed sample
+// of each series so repeated pushes send only new samples.
+type RemoteWriter struct {
+	URL     string
+	Timeout time.Duration // per request; 30s when zero
+
+	mu     sync.Mutex
+	pushed map[string]time.Time // series key -> newest pushed sample
+}
+
+// NewRemoteWriter validates the endpoint URL and creates a writer.
+func NewRemoteWriter(endpoint string) (*RemoteWriter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote_write URL %q", endpoint)
+	}
+	return &RemoteWriter{URL: endpoint, pushed: make(map[string]time.Time)}, nil
+}
+
+// Push sends the samples of series not pushed before and returns how many
+// were sent. Samples are only marked pushed once their request succeeds.
+func (w *RemoteWriter) Push(ctx context.Context, series []LatchSeries) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pending := w.pending(series)
+	sent := 0
+	for len(pending) > 0 {
+		batch, rest := splitBatch(pending, maxRemoteWriteSamples)
+		if err := w.send(ctx, encodeWriteRequest(batch)); err != nil {
+			return sent, err
+		}
+		for i := range batch {
+			s := &batch[i]
+			w.pushed[s.key()] = s.Points[len(s.Points)-1].Time
+			sent += len(s.Points)
+		}
+		pending = rest
+	}
+	return sent, nil
+}
+
+// Run pushes the recorder's new samples every interval until ctx is done.
+// Failures are reported through onError and retried on the next tick.
+func (w *RemoteWriter) Run(ctx context.Context, rec *SampleRecorder, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Push(ctx, rec.Series()); err != nil && ctx.Err() == nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// pending returns the series trimmed to their samples newer than the last push.
+func (w *RemoteWriter) pending(series []LatchSeries) []LatchSeries {
+	var out []LatchSeries
+	for i := range series {
+		s := series[i]
+		if last, ok := w.pushed[s.key()]; ok {
+			start := 0
+			for start < len(s.Points) && !s.Points[start].Time.After(last) {
+				start++
+			}
+			s.Points = s.Points[start:]
+		}
+		if len(s.Points) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// splitBatch takes series from the front until limit samples are reached,
+// splitting the last series if needed.
+func splitBatch(series []LatchSeries, limit int) (batch, rest []LatchSeries) {
+	n := 0
+	for i := range series {
+		s := series[i]
+		if n+len(s.Points) <= limit {
+			batch = append(batch, s)
+			n += len(s.Points)
+			continue
+		}
+		head, tail := s, s
+		head.Points = s.Points[:limit-n]
+		tail.Points = s.Points[limit-n:]
+		if len(head.Points) > 0 {
+			batch = append(batch, head)
+		}
+		return batch, append([]LatchSeries{tail}, series[i+1:]...)
+	}
+	return batch, nil
+}
+
+func (w *RemoteWriter) send(ctx context.Context, payload []byte) error {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = defaultRemoteWriteTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(snappy.Encode(nil, payload)))
+	if err != nil {
+		return fmt.Errorf("build remote_write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote_write to %s: %w", req.URL.Host, err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, readErr := io.ReadAll(io.LimitReader(resp.Body, 500))
+		if readErr != nil {
+			msg = nil
+		}
+		return fmt.Errorf("remote_write to %s: %s: %s", req.URL.Host, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// encodeWriteRequest encodes series as a remote_write v1 WriteRequest
+// protobuf (prometheus/prompb): repeated TimeSeries timeseries = 1, each
+// with repeated Label labels = 1 (sorted, __name__ first) and repeated
+// Sample samples = 2 (double value = 1, int64 timestamp_ms = 2).
+func encodeWriteRequest(series []LatchSeries) []byte {
+	var buf []byte
+	for i := range series {
+		s := &series[i]
+		var ts []byte
+		ts = appendLabel(ts, "__name__", s.Name)
+		for _, name := range sortedLabelNames(s.Labels) {
+			ts = appendLabel(ts, name, s.Labels[name])
+		}
+		for _, p := range s.Points {
+			var sample []byte
+			sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+			sample = protowire.AppendFixed64(sample, math.Float64bits(p.Value))
+			sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+			sample = protowire.AppendVarint(sample, uint64(p.Time.UnixMilli()))
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, sample)
+		}
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, ts)
+	}
+	return buf
+}
+
+func appendLabel(b []byte, name, value string) []byte {
+	var label []byte
+	label = protowire.AppendTag(label, 1, protowire.BytesType)
+	label = protowire.AppendString(label, name)
+	label = protowire.AppendTag(label, 2, protowire.BytesType)
+	label = protowire.AppendString(label, value)
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, label)
+}
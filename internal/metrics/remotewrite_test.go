@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodedSeries is a TimeSeries decoded from a WriteRequest.
+type decodedSeries struct {
+	labels  [][2]string
+	samples []LatchPoint
+}
+
+// decodeWriteRequest parses the subset of prompb.WriteRequest kubenow sends.
+func decodeWriteRequest(t *testing.T, b []byte) []decodedSeries {
+	t.Helper()
+	var out []decodedSeries
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		require.Equal(t, protowire.Number(1), num)
+		require.Equal(t, protowire.BytesType, typ)
+		b = b[n:]
+		ts, m := protowire.ConsumeBytes(b)
+		require.GreaterOrEqual(t, m, 0)
+		b = b[m:]
+		out = append(out, decodeTimeSeries(t, ts))
+	}
+	return out
+}
+
+func decodeTimeSeries(t *testing.T, b []byte) decodedSeries {
+	t.Helper()
+	var s decodedSeries
+	for len(b) > 0 {
+		num, _, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		field, m := protowire.ConsumeBytes(b)
+		require.GreaterOrEqual(t, m, 0)
+		b = b[m:]
+		fields := map[protowire.Number][]byte{}
+		var values []uint64
+		for len(field) > 0 {
+			fnum, ftyp, k := protowire.ConsumeTag(field)
+			field = field[k:]
+			switch ftyp {
+			case protowire.BytesType:
+				v, l := protowire.ConsumeBytes(field)
+				fields[fnum] = v
+				field = field[l:]
+			case protowire.Fixed64Type:
+				v, l := protowire.ConsumeFixed64(field)
+				values = append(values, v)
+				field = field[l:]
+			case protowire.VarintType:
+				v, l := protowire.ConsumeVarint(field)
+				values = append(values, v)
+				field = field[l:]
+			default:
+				t.Fatalf("unexpected wire type %v", ftyp)
+			}
+		}
+		switch num {
+		case 1:
+			s.labels = append(s.labels, [2]string{string(fields[1]), string(fields[2])})
+		case 2:
+			require.Len(t, values, 2)
+			s.samples = append(s.samples, LatchPoint{
+				Value: math.Float64frombits(values[0]),
+				Time:  time.UnixMilli(int64(values[1])),
+			})
+		}
+	}
+	return s
+}
+
+func TestRemoteWriter_Push(t *testing.T) {
+	var mu sync.Mutex
+	var requests [][]decodedSeries
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		payload, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		mu.Lock()
+		requests = append(requests, decodeWriteRequest(t, payload))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	writer, err := NewRemoteWriter(srv.URL + "/api/v1/write")
+	require.NoError(t, err)
+
+	t0 := time.UnixMilli(1700000000000)
+	series := []LatchSeries{{
+		Name:   LatchCPUMetric,
+		Labels: map[string]string{"pod": "api-1", "container": "app"},
+		Points: []LatchPoint{{Time: t0, Value: 0.25}, {Time: t0.Add(5 * time.Second), Value: 0.5}},
+	}}
+	sent, err := writer.Push(context.Background(), series)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0], 1)
+	got := requests[0][0]
+	assert.Equal(t, [][2]string{{"__name__", LatchCPUMetric}, {"container", "app"}, {"pod", "api-1"}}, got.labels)
+	require.Len(t, got.samples, 2)
+	assert.Equal(t, t0, got.samples[0].Time)
+	assert.InDelta(t, 0.5, got.samples[1].Value, 0.0001)
+
+	// A second push sends only samples newer than the last one pushed.
+	series[0].Points = append(series[0].Points, LatchPoint{Time: t0.Add(10 * time.Second), Value: 0.75})
+	sent, err = writer.Push(context.Background(), series)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, requests, 2)
+	assert.InDelta(t, 0.75, requests[1][0].samples[0].Value, 0.0001)
+
+	// Nothing new: no request.
+	sent, err = writer.Push(context.Background(), series)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Len(t, requests, 2)
+}
+
+func TestRemoteWriter_PushError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("out of order sample\n"))
+	}))
+	defer srv.Close()
+
+	writer, err := NewRemoteWriter(srv.URL)
+	require.NoError(t, err)
+	series := []LatchSeries{{Name: LatchCPUMetric, Points: []LatchPoint{{Time: time.Now(), Value: 1}}}}
+
+	_, err = writer.Push(context.Background(), series)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request: out of order sample")
+	assert.Len(t, writer.pending(series), 1, "failed samples are retried on the next push")
+
+	_, err = NewRemoteWriter("prometheus:9090")
+	assert.Error(t, err)
+}
+
+func TestSplitBatch(t *testing.T) {
+	points := func(n int) []LatchPoint { return make([]LatchPoint, n) }
+	series := []LatchSeries{{Name: "a", Points: points(3)}, {Name: "b", Points: points(4)}, {Name: "c", Points: points(2)}}
+
+	batch, rest := splitBatch(series, 5)
+	require.Len(t, batch, 2)
+	assert.Len(t, batch[1].Points, 2, "b is split")
+	require.Len(t, rest, 2)
+	assert.Equal(t, "b", rest[0].Name)
+	assert.Len(t, rest[0].Points, 2)
+
+	batch, rest = splitBatch(rest, 5)
+	assert.Len(t, batch, 2)
+	assert.Empty(t, rest)
+}
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// Exported latch series names. Values are the raw Metrics API samples, so
+// the central TSDB holds the same sub-scrape-interval data the latch saw.
+const (
+	LatchCPUMetric    = "kubenow_latch_container_cpu_cores"
+	LatchMemoryMetric = "kubenow_latch_container_memory_bytes"
+)
+
+// LatchPoint is one timestamped sample.
+type LatchPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// LatchSeries is a recorded time series: a metric name, its labels, and
+// its samples in time order.
+type LatchSeries struct {
+	Name   string
+	Labels map[string]string
+	Points []LatchPoint
+}
+
+// key identifies the series: the name and its labels in sorted order.
+func (s *LatchSeries) key() string {
+	var sb strings.Builder
+	sb.WriteString(s.Name)
+	for _, name := range sortedLabelNames(s.Labels) {
+		sb.WriteString("," + name + "=" + s.Labels[name])
+	}
+	return sb.String()
+}
+
+func sortedLabelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SampleRecorder keeps every per-container sample taken by a LatchMonitor,
+// with its timestamp, for export to a Prometheus remote_write endpoint or
+// an OpenMetrics file. Each series keeps at most maxSamples points.
+type SampleRecorder struct {
+	mu          sync.Mutex
+	extraLabels map[string]string
+	series      map[string]*LatchSeries
+}
+
+// NewSampleRecorder creates a recorder adding extraLabels (e.g. cluster)
+// to every series.
+func NewSampleRecorder(extraLabels map[string]string) *SampleRecorder {
+	return &SampleRecorder{extraLabels: extraLabels, series: make(map[string]*LatchSeries)}
+}
+
+// recordPod records the CPU and memory of each container of a pod sample.
+func (r *SampleRecorder) recordPod(ts time.Time, namespace, workload, pod string, containers []metricsv1beta1.ContainerMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range containers {
+		c := &containers[i]
+		cpu := c.Usage.Cpu().AsApproximateFloat64()
+		mem := float64(c.Usage.Memory().Value())
+		r.add(LatchCPUMetric, ts, cpu, namespace, workload, pod, c.Name)
+		r.add(LatchMemoryMetric, ts, mem, namespace, workload, pod, c.Name)
+	}
+}
+
+func (r *SampleRecorder) add(name string, ts time.Time, value float64, namespace, workload, pod, container string) {
+	labels := map[string]string{
+		"namespace": namespace,
+		"workload":  workload,
+		"pod":       pod,
+		"container": container,
+	}
+	for k, v := range r.extraLabels {
+		labels[k] = v
+	}
+	s := &LatchSeries{Name: name, Labels: labels}
+	key := s.key()
+	if existing, ok := r.series[key]; ok {
+		s = existing
+	} else {
+		r.series[key] = s
+	}
+	if len(s.Points) >= maxSamples {
+		s.Points = s.Points[1:]
+	}
+	s.Points = append(s.Points, LatchPoint{Time: ts, Value: value})
+}
+
+// Series returns a copy of the recorded series, sorted by name and labels.
+func (r *SampleRecorder) Series() []LatchSeries {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.series))
+	for k := range r.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]LatchSeries, 0, len(keys))
+	for _, k := range keys {
+		s := r.series[k]
+		out = append(out, LatchSeries{
+			Name:   s.Name,
+			Labels: s.Labels,
+			Points: append([]LatchPoint(nil), s.Points...),
+		})
+	}
+	return out
+}
+
+// WriteOpenMetrics writes series in the OpenMetrics text format with
+// explicit timestamps, suitable for `promtool tsdb create-blocks-from
+// openmetrics` to backfill an air-gapped capture into a TSDB.
+func WriteOpenMetrics(w io.Writer, series []LatchSeries) error {
+	bw := bufio.NewWriter(w)
+	help := map[string]string{
+		LatchCPUMetric:    "Container CPU usage sampled by kubenow latch (cores).",
+		LatchMemoryMetric: "Container memory usage sampled by kubenow latch (bytes).",
+	}
+
+	// Series are grouped by metric family; Series() sorts by name.
+	lastName := ""
+	for i := range series {
+		s := &series[i]
+		if s.Name != lastName {
+			lastName = s.Name
+			fmt.Fprintf(bw, "# TYPE %s gauge\n", s.Name)
+			if h, ok := help[s.Name]; ok {
+				fmt.Fprintf(bw, "# HELP %s %s\n", s.Name, h)
+			}
+		}
+		labels := formatOpenMetricsLabels(s.Labels)
+		for _, p := range s.Points {
+			fmt.Fprintf(bw, "%s%s %s %s\n", s.Name, labels,
+				strconv.FormatFloat(p.Value, 'g', -1, 64),
+				strconv.FormatFloat(float64(p.Time.UnixMilli())/1000, 'f', 3, 64))
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+func formatOpenMetricsLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for _, name := range sortedLabelNames(labels) {
+		parts = append(parts, name+"="+strconv.Quote(labels[name]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
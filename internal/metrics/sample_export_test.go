@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestSampleRecorder_Series(t *testing.T) {
+	rec := NewSampleRecorder(map[string]string{"cluster": "prod-eu"})
+	t0 := time.Unix(1700000000, 0)
+	rec.recordPod(t0, "prod", "api", "api-7d9f-abc", []metricsv1beta1.ContainerMetrics{
+		containerMetrics("app", "250m", "128Mi"),
+		containerMetrics("istio-proxy", "10m", "64Mi"),
+	})
+	rec.recordPod(t0.Add(5*time.Second), "prod", "api", "api-7d9f-abc", []metricsv1beta1.ContainerMetrics{
+		containerMetrics("app", "500m", "130Mi"),
+	})
+
+	series := rec.Series()
+	require.Len(t, series, 4, "cpu and memory per container")
+	assert.Equal(t, LatchCPUMetric, series[0].Name)
+	assert.Equal(t, "app", series[0].Labels["container"])
+	assert.Equal(t, "prod-eu", series[0].Labels["cluster"])
+	require.Len(t, series[0].Points, 2)
+	assert.InDelta(t, 0.5, series[0].Points[1].Value, 0.001)
+	assert.Equal(t, t0.Add(5*time.Second), series[0].Points[1].Time)
+	assert.Equal(t, LatchMemoryMetric, series[2].Name)
+	assert.InDelta(t, 128*1024*1024, series[2].Points[0].Value, 1)
+
+	// Series returns copies.
+	series[0].Points[0].Value = 99
+	assert.InDelta(t, 0.25, rec.Series()[0].Points[0].Value, 0.001)
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	series := []LatchSeries{
+		{
+			Name:   LatchCPUMetric,
+			Labels: map[string]string{"namespace": "prod", "pod": "api-1", "container": "app", "workload": "api"},
+			Points: []LatchPoint{{Time: time.UnixMilli(1700000000123), Value: 0.25}, {Time: time.UnixMilli(1700000005000), Value: 1}},
+		},
+		{
+			Name:   LatchMemoryMetric,
+			Labels: map[string]string{"namespace": "prod", "pod": "api-1", "container": "app", "workload": "api"},
+			Points: []LatchPoint{{Time: time.UnixMilli(1700000000000), Value: 134217728}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOpenMetrics(&buf, series))
+	want := `# TYPE kubenow_latch_container_cpu_cores gauge
+# HELP kubenow_latch_container_cpu_cores Container CPU usage sampled by kubenow latch (cores).
+kubenow_latch_container_cpu_cores{container="app",namespace="prod",pod="api-1",workload="api"} 0.25 1700000000.123
+kubenow_latch_container_cpu_cores{container="app",namespace="prod",pod="api-1",workload="api"} 1 1700000005.000
+# TYPE kubenow_latch_container_memory_bytes gauge
+# HELP kubenow_latch_container_memory_bytes Container memory usage sampled by kubenow latch (bytes).
+kubenow_latch_container_memory_bytes{container="app",namespace="prod",pod="api-1",workload="api"} 1.34217728e+08 1700000000.000
+# EOF
+`
+	assert.Equal(t, want, buf.String())
+}
@@ -0,0 +1,106 @@
+package metrics
+
+// Memory recommendation tuning for latch spike data. Memory is incompressible:
+// exceeding the limit means an OOMKill rather than throttling, so the factors
+// are driven by memory variability and observed OOMKills instead of CPU burstiness.
+const (
+	// Per-OOMKill bump to the memory safety factor (MaxMemory was observed
+	// right before the kill, so true demand is higher than what we saw).
+	memoryOOMFactorStep = 0.25
+
+	// Upper bound for the auto-selected memory safety factor.
+	memoryMaxSafetyFactor = 2.0
+
+	// Limit headroom above the recommended request.
+	memoryLimitHeadroom    = 1.2
+	memoryOOMLimitHeadroom = 1.5
+)
+
+// MemoryRecommendation is a recommended memory request/limit derived from
+// latch spike data.
+type MemoryRecommendation struct {
+	RequestBytes float64 `json:"request_bytes"`
+	LimitBytes   float64 `json:"limit_bytes"`
+	SafetyFactor float64 `json:"safety_factor"`
+	OOMAdjusted  bool    `json:"oom_adjusted"` // factor raised because OOMKills were observed
+
+	// LeakSuspected is set when the OOMKills followed steady memory growth:
+	// the factor is not raised for them, since more memory only delays the
+	// next kill.
+	LeakSuspected bool `json:"leak_suspected,omitempty"`
+}
+
+// SpikeCPUSafetyFactor auto-selects the CPU safety factor from the spike ratio.
+func SpikeCPUSafetyFactor(spikeRatio float64) float64 {
+	switch {
+	case spikeRatio >= 20.0:
+		return 2.5
+	case spikeRatio >= 10.0:
+		return 2.0
+	case spikeRatio >= 5.0:
+		return 1.5
+	default:
+		return 1.2
+	}
+}
+
+// SpikeMemorySafetyFactor auto-selects the memory safety factor from the
+// max/avg memory ratio and the number of OOMKills seen during monitoring.
+func SpikeMemorySafetyFactor(memRatio float64, oomKills int) float64 {
+	var factor float64
+	switch {
+	case memRatio >= 2.0:
+		factor = 1.5
+	case memRatio >= 1.5:
+		factor = 1.3
+	default:
+		factor = 1.15
+	}
+
+	factor += float64(oomKills) * memoryOOMFactorStep
+	if factor > memoryMaxSafetyFactor {
+		factor = memoryMaxSafetyFactor
+	}
+	return factor
+}
+
+// RecommendSpikeMemory computes a memory request/limit from MaxMemory and
+// OOMKill signals, skipping the OOMKill bump when the crash classification
+// points at a leak. A non-zero override replaces the auto-selected factor.
+// Returns nil when no memory samples were collected.
+func RecommendSpikeMemory(data *SpikeData, override float64) *MemoryRecommendation {
+	if data == nil || data.MaxMemory <= 0 {
+		return nil
+	}
+
+	memRatio := 1.0
+	if data.AvgMemory > 0 {
+		memRatio = data.MaxMemory / data.AvgMemory
+	}
+
+	// OOMKills from a leak are not sizing signals
+	oomKills := data.OOMKills
+	leak := data.Crash != nil && data.Crash.Class == CrashOOMGrowth
+	if leak {
+		oomKills = 0
+	}
+
+	factor := override
+	if factor == 0 {
+		factor = SpikeMemorySafetyFactor(memRatio, oomKills)
+	}
+
+	headroom := memoryLimitHeadroom
+	if data.OOMKills > 0 {
+		headroom = memoryOOMLimitHeadroom
+	}
+
+	request := data.MaxMemory * factor
+	return &MemoryRecommendation{
+		RequestBytes:  request,
+		LimitBytes:    request * headroom,
+		SafetyFactor:  factor,
+		OOMAdjusted:   override == 0 && oomKills > 0,
+		LeakSuspected: leak,
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpikeCPUSafetyFactor(t *testing.T) {
+	assert.Equal(t, 2.5, SpikeCPUSafetyFactor(25))
+	assert.Equal(t, 2.0, SpikeCPUSafetyFactor(10))
+	assert.Equal(t, 1.5, SpikeCPUSafetyFactor(6))
+	assert.Equal(t, 1.2, SpikeCPUSafetyFactor(2.5))
+}
+
+func TestSpikeMemorySafetyFactor(t *testing.T) {
+	tests := []struct {
+		name     string
+		ratio    float64
+		oomKills int
+		want     float64
+	}{
+		{"stable", 1.1, 0, 1.15},
+		{"moderate growth", 1.6, 0, 1.3},
+		{"high variance", 2.5, 0, 1.5},
+		{"one oomkill", 1.1, 1, 1.4},
+		{"capped", 2.5, 5, memoryMaxSafetyFactor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, SpikeMemorySafetyFactor(tt.ratio, tt.oomKills), 1e-9)
+		})
+	}
+}
+
+func TestRecommendSpikeMemory(t *testing.T) {
+	const mi = 1024 * 1024
+
+	rec := RecommendSpikeMemory(&SpikeData{MaxMemory: 100 * mi, AvgMemory: 90 * mi}, 0)
+	require.NotNil(t, rec)
+	assert.InDelta(t, 115*mi, rec.RequestBytes, 1)
+	assert.InDelta(t, 138*mi, rec.LimitBytes, 1)
+	assert.False(t, rec.OOMAdjusted)
+
+	oom := RecommendSpikeMemory(&SpikeData{MaxMemory: 100 * mi, AvgMemory: 90 * mi, OOMKills: 2}, 0)
+	require.NotNil(t, oom)
+	assert.InDelta(t, 1.65, oom.SafetyFactor, 1e-9)
+	assert.InDelta(t, oom.RequestBytes*1.5, oom.LimitBytes, 1)
+	assert.True(t, oom.OOMAdjusted)
+
+	override := RecommendSpikeMemory(&SpikeData{MaxMemory: 100 * mi, OOMKills: 1}, 1.1)
+	require.NotNil(t, override)
+	assert.Equal(t, 1.1, override.SafetyFactor)
+	assert.False(t, override.OOMAdjusted)
+
+	assert.Nil(t, RecommendSpikeMemory(&SpikeData{}, 0))
+	assert.Nil(t, RecommendSpikeMemory(nil, 0))
+}
+
+func TestRecommendSpikeMemory_Leak(t *testing.T) {
+	const mi = 1024 * 1024
+
+	data := &SpikeData{MaxMemory: 100 * mi, AvgMemory: 90 * mi, OOMKills: 2,
+		Crash: &CrashClassification{Class: CrashOOMGrowth}}
+	rec := RecommendSpikeMemory(data, 0)
+	require.NotNil(t, rec)
+	assert.InDelta(t, 1.15, rec.SafetyFactor, 1e-9, "leak OOMKills do not raise the factor")
+	assert.False(t, rec.OOMAdjusted)
+	assert.True(t, rec.LeakSuspected)
+
+	data.Crash.Class = CrashOOMSpike
+	rec = RecommendSpikeMemory(data, 0)
+	assert.InDelta(t, 1.65, rec.SafetyFactor, 1e-9)
+	assert.False(t, rec.LeakSuspected)
+}
@@ -82,7 +82,7 @@ type SafetyAnalysis struct {
 
 	// CPU metrics
 	CPUThrottledSeconds float64 `json:"cpu_throttled_seconds"` // Total throttled time
-	CPUThrottledPercent float64 `json:"cpu_throttled_percent"` // Percent of time throttled
+	CPUThrottledPercent float64 `json:"cpu_throttled_percent"` // Percent of CFS periods throttled
 
 	// Spike detection
 	CPUP999          float64 `json:"cpu_p999"`           // 99.9th percentile CPU
@@ -348,30 +348,3 @@ type NodeMetrics struct {
 	CPUUsageAvg  float64 `json:"cpu_usage_avg"`
 	MemUsageAvg  float64 `json:"mem_usage_avg"`
 }
-
-// FormatMemoryBytes converts bytes to human-readable format (Gi, Mi, Ki)
-func FormatMemoryBytes(bytes float64) string {
-	const (
-		Ki = 1024
-		Mi = 1024 * Ki
-		Gi = 1024 * Mi
-	)
-
-	switch {
-	case bytes >= Gi:
-		return fmt.Sprintf("%.2fGi", bytes/Gi)
-	case bytes >= Mi:
-		return fmt.Sprintf("%.2fMi", bytes/Mi)
-	case bytes >= Ki:
-		return fmt.Sprintf("%.2fKi", bytes/Ki)
-	default:
-		return fmt.Sprintf("%.0fB", bytes)
-	}
-}
-
-// ParseMemoryString converts human-readable memory to bytes
-func ParseMemoryString(_ string) (float64, error) {
-	// Implementation would parse strings like "8Gi", "512Mi", etc.
-	// For now, placeholder
-	return 0, nil
-}
@@ -101,40 +101,3 @@ func TestDetectAIWorkloadPattern(t *testing.T) {
 	assert.False(t, sa2.WorkloadPatternAI)
 	assert.Empty(t, sa2.WorkloadPatternTags)
 }
-
-func TestFormatMemoryBytes(t *testing.T) {
-	tests := []struct {
-		name  string
-		bytes float64
-		want  string
-	}{
-		{"zero", 0, "0B"},
-		{"bytes", 500, "500B"},
-		{"kilobytes", 1024, "1.00Ki"},
-		{"megabytes", 1024 * 1024, "1.00Mi"},
-		{"gigabytes", 1024 * 1024 * 1024, "1.00Gi"},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, FormatMemoryBytes(tt.bytes))
-		})
-	}
-}
-
-func TestParseMemoryString(t *testing.T) {
-	tests := []struct {
-		name  string
-		input string
-	}{
-		{"megabytes", "100Mi"},
-		{"gigabytes", "2Gi"},
-		{"invalid", "abc"},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := ParseMemoryString(tt.input)
-			assert.NoError(t, err)
-			assert.Equal(t, float64(0), got)
-		})
-	}
-}
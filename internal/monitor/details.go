@@ -0,0 +1,251 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	// detailLogLines is the number of log lines the drill-down shows.
+	detailLogLines = 50
+	// detailEvents is the number of latest events the drill-down shows.
+	detailEvents = 10
+	// detailTimeout bounds the API calls of one drill-down.
+	detailTimeout = 10 * time.Second
+)
+
+// ProblemDetails is the drill-down of one problem, fetched when the user
+// opens it: a condensed pod describe, the latest events of the object, and
+// the tail of the container's logs. Problems about services and routes
+// have events only.
+type ProblemDetails struct {
+	Describe     []string
+	Events       []string
+	LogContainer string
+	PreviousLogs bool // logs of the previous, terminated container instance
+	Logs         []string
+	Errors       []string // sections that could not be fetched
+}
+
+// FetchDetails reads the pod, events, and logs behind a problem. Failures
+// are recorded per section so the rest still shows.
+func (w *Watcher) FetchDetails(ctx context.Context, p *Problem) *ProblemDetails {
+	d := &ProblemDetails{}
+
+	events, err := w.fetchEvents(ctx, p.Namespace, p.PodName)
+	if err != nil {
+		d.Errors = append(d.Errors, fmt.Sprintf("events: %v", err))
+	}
+	d.Events = events
+
+	pod, err := w.clientset.CoreV1().Pods(p.Namespace).Get(ctx, p.PodName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return d
+	case err != nil:
+		d.Errors = append(d.Errors, fmt.Sprintf("pod: %v", err))
+		return d
+	}
+	d.Describe = describePod(pod, time.Now())
+
+	d.LogContainer = logContainer(pod, p.ContainerName)
+	if d.LogContainer == "" {
+		return d
+	}
+	d.PreviousLogs = restarted(pod, d.LogContainer)
+	logs, err := w.fetchLogs(ctx, pod, d.LogContainer, d.PreviousLogs)
+	if err != nil && d.PreviousLogs {
+		// The previous instance's logs may be gone; fall back to the current one
+		d.PreviousLogs = false
+		logs, err = w.fetchLogs(ctx, pod, d.LogContainer, false)
+	}
+	if err != nil {
+		d.Errors = append(d.Errors, fmt.Sprintf("logs: %v", err))
+	}
+	d.Logs = logs
+	return d
+}
+
+// fetchEvents returns the latest events about an object, newest first.
+func (w *Watcher) fetchEvents(ctx context.Context, namespace, name string) ([]string, error) {
+	list, err := w.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", name).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]corev1.Event, 0, len(list.Items))
+	for i := range list.Items {
+		if list.Items[i].InvolvedObject.Name == name {
+			events = append(events, list.Items[i])
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return eventTime(&events[i]).After(eventTime(&events[j])) })
+	if len(events) > detailEvents {
+		events = events[:detailEvents]
+	}
+
+	lines := make([]string, 0, len(events))
+	for i := range events {
+		e := &events[i]
+		line := fmt.Sprintf("%4s ago  %-7s %-20s %s", formatDuration(time.Since(eventTime(e))), e.Type, e.Reason, e.Message)
+		if e.Count > 1 {
+			line += fmt.Sprintf(" (×%d)", e.Count)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (w *Watcher) fetchLogs(ctx context.Context, pod *corev1.Pod, container string, previous bool) ([]string, error) {
+	tail := int64(detailLogLines)
+	raw, err := w.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tail,
+		Previous:  previous,
+	}).Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimRight(string(raw), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	text = strings.NewReplacer("\r", "", "\t", "    ").Replace(text)
+	return strings.Split(text, "\n"), nil
+}
+
+// eventTime is when an event last happened, for events written by the
+// newer events API without LastTimestamp too.
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// logContainer picks the container whose logs to show: the problem's, or
+// the pod's first.
+func logContainer(pod *corev1.Pod, container string) string {
+	if container != "" {
+		return container
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// restarted reports whether a container has a terminated previous instance,
+// whose logs explain a crash better than the current one's.
+func restarted(pod *corev1.Pod, container string) bool {
+	for i := range pod.Status.ContainerStatuses {
+		cs := &pod.Status.ContainerStatuses[i]
+		if cs.Name == container {
+			return cs.RestartCount > 0 && cs.LastTerminationState.Terminated != nil
+		}
+	}
+	return false
+}
+
+// describePod condenses `kubectl describe pod` to the lines that explain a
+// problem: placement, phase, conditions, and each container's state.
+func describePod(pod *corev1.Pod, now time.Time) []string {
+	lines := []string{fmt.Sprintf("Node: %s  Phase: %s  QoS: %s", valueOr(pod.Spec.NodeName, "<none>"), pod.Status.Phase, pod.Status.QOSClass)}
+	if pod.Status.StartTime != nil {
+		lines[0] += fmt.Sprintf("  Started: %s ago", formatDuration(now.Sub(pod.Status.StartTime.Time)))
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller != nil && *owner.Controller {
+			lines = append(lines, fmt.Sprintf("Controlled by: %s/%s", owner.Kind, owner.Name))
+		}
+	}
+	if pod.Status.Reason != "" || pod.Status.Message != "" {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("Status: %s %s", pod.Status.Reason, pod.Status.Message)))
+	}
+
+	conditions := make([]string, 0, len(pod.Status.Conditions))
+	for _, c := range pod.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s", c.Type, c.Status))
+	}
+	if len(conditions) > 0 {
+		lines = append(lines, "Conditions: "+strings.Join(conditions, " "))
+	}
+
+	statuses := make(map[string]*corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for i := range pod.Status.ContainerStatuses {
+		statuses[pod.Status.ContainerStatuses[i].Name] = &pod.Status.ContainerStatuses[i]
+	}
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		lines = append(lines, fmt.Sprintf("Container %s: %s", c.Name, c.Image))
+		if r := formatResources(c.Resources); r != "" {
+			lines = append(lines, "  "+r)
+		}
+		cs := statuses[c.Name]
+		if cs == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  State: %s  Ready: %t  Restarts: %d", containerState(cs.State, now), cs.Ready, cs.RestartCount))
+		if cs.LastTerminationState.Terminated != nil {
+			lines = append(lines, "  Last state: "+containerState(cs.LastTerminationState, now))
+		}
+	}
+	return lines
+}
+
+func containerState(s corev1.ContainerState, now time.Time) string {
+	switch {
+	case s.Running != nil:
+		return fmt.Sprintf("Running (%s)", formatDuration(now.Sub(s.Running.StartedAt.Time)))
+	case s.Waiting != nil:
+		return strings.TrimSpace(fmt.Sprintf("Waiting: %s %s", s.Waiting.Reason, truncate(s.Waiting.Message, 120)))
+	case s.Terminated != nil:
+		state := fmt.Sprintf("Terminated: %s (exit %d)", s.Terminated.Reason, s.Terminated.ExitCode)
+		if !s.Terminated.FinishedAt.IsZero() {
+			state += fmt.Sprintf(" %s ago", formatDuration(now.Sub(s.Terminated.FinishedAt.Time)))
+		}
+		return state
+	default:
+		return "Unknown"
+	}
+}
+
+func formatResources(r corev1.ResourceRequirements) string {
+	var parts []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		req, hasReq := r.Requests[name]
+		lim, hasLim := r.Limits[name]
+		if !hasReq && !hasLim {
+			continue
+		}
+		part := string(name) + ":"
+		if hasReq {
+			part += " request " + req.String()
+		}
+		if hasLim {
+			part += " limit " + lim.String()
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
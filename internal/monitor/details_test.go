@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func crashingPod() *corev1.Pod {
+	controller := true
+	started := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "api-7d9f", Namespace: "prod",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-6b7c", Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-a",
+			Containers: []corev1.Container{{Name: "app", Image: "api:1.2", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+			}}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			StartTime:  &started,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: 4,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Reason: "OOMKilled", ExitCode: 137,
+				}},
+			}},
+		},
+	}
+}
+
+func podEvent(name, object, reason string, ago time.Duration) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: object, Namespace: "prod"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " happened",
+		Count:          3,
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-ago)),
+	}
+}
+
+func TestFetchDetails_Pod(t *testing.T) {
+	client := fake.NewClientset(
+		crashingPod(),
+		podEvent("e1", "api-7d9f", "BackOff", time.Minute),
+		podEvent("e2", "api-7d9f", "Pulled", time.Hour),
+		podEvent("e3", "other", "Failed", time.Second),
+	)
+	w := NewWatcher(client, Config{})
+
+	d := w.FetchDetails(context.Background(), &Problem{Namespace: "prod", PodName: "api-7d9f", Type: "CrashLoopBackOff"})
+	assert.Empty(t, d.Errors)
+	describe := strings.Join(d.Describe, "\n")
+	assert.Contains(t, describe, "Node: node-a  Phase: Running")
+	assert.Contains(t, describe, "Controlled by: ReplicaSet/api-6b7c")
+	assert.Contains(t, describe, "Conditions: Ready=False")
+	assert.Contains(t, describe, "memory: request 256Mi limit 256Mi")
+	assert.Contains(t, describe, "State: Waiting: CrashLoopBackOff  Ready: false  Restarts: 4")
+	assert.Contains(t, describe, "Last state: Terminated: OOMKilled (exit 137)")
+
+	require.Len(t, d.Events, 2, "only events about the pod")
+	assert.Contains(t, d.Events[0], "BackOff happened (×3)", "newest first")
+	assert.Contains(t, d.Events[1], "Pulled")
+
+	assert.Equal(t, "app", d.LogContainer)
+	assert.True(t, d.PreviousLogs, "a restarted container shows the crashed instance's logs")
+	assert.Equal(t, []string{"fake logs"}, d.Logs)
+}
+
+func TestFetchDetails_NotAPod(t *testing.T) {
+	client := fake.NewClientset(podEvent("e1", "checkout", "NoReadyEndpoints", time.Minute))
+	w := NewWatcher(client, Config{})
+
+	d := w.FetchDetails(context.Background(), &Problem{Namespace: "prod", PodName: "checkout", Type: "NoReadyEndpoints"})
+	assert.Empty(t, d.Errors)
+	assert.Nil(t, d.Describe)
+	assert.Empty(t, d.LogContainer)
+	assert.Len(t, d.Events, 1)
+}
+
+func TestModel_DrillDown(t *testing.T) {
+	w := NewWatcher(fake.NewClientset(crashingPod()), Config{})
+	m := NewModel(w)
+	m.stats.Connection = ConnectionOK
+	m.width, m.height = 120, 40
+	m.allProblems = []Problem{
+		{Severity: SeverityWarning, Type: "HighRestarts", Namespace: "prod", PodName: "web-1"},
+		{Severity: SeverityFatal, Type: "CrashLoopBackOff", Namespace: "prod", PodName: "api-7d9f", ContainerName: "app"},
+	}
+	m.filterProblems()
+
+	// Sorted by severity: the crash loop comes first and is selected
+	assert.Contains(t, m.View(), "> ")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.NotNil(t, m.detail)
+	assert.Equal(t, "api-7d9f", m.detail.PodName)
+	assert.Contains(t, m.View(), "Fetching pod, events, and logs")
+
+	m.Update(cmd())
+	view := m.View()
+	assert.Contains(t, view, "Last state: Terminated: OOMKilled")
+	assert.Contains(t, view, "of the previous (terminated) instance")
+	assert.Contains(t, view, "fake logs")
+
+	// A late result for another problem is ignored
+	m.Update(detailsMsg{problem: Problem{PodName: "web-1"}, details: &ProblemDetails{}})
+	assert.Contains(t, m.View(), "fake logs")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, m.detail)
+	assert.Contains(t, m.View(), "2 PROBLEMS")
+
+	// Moving down selects the next problem
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "web-1", m.detail.PodName)
+}
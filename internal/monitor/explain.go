@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExplainFunc asks an LLM to explain a problem from its drill-down and
+// returns the explanation text.
+type ExplainFunc func(ctx context.Context, p *Problem, d *ProblemDetails) (string, error)
+
+// ExplainContext renders a problem and its drill-down as the plain text an
+// LLM explains: the problem summary, the condensed pod describe, the latest
+// events, and the log tail.
+func ExplainContext(p *Problem, d *ProblemDetails, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Problem: %s %s\n", p.Severity, p.Type)
+	fmt.Fprintf(&b, "Object: %s/%s", p.Namespace, p.PodName)
+	if p.ContainerName != "" {
+		fmt.Fprintf(&b, " container %s", p.ContainerName)
+	}
+	b.WriteString("\n")
+	if p.SubReason != "" {
+		fmt.Fprintf(&b, "Reason: %s\n", p.SubReason)
+	}
+	fmt.Fprintf(&b, "Message: %s\n", p.Message)
+	if !p.FirstSeen.IsZero() {
+		fmt.Fprintf(&b, "First seen: %s ago, occurrences: %d\n", formatDuration(now.Sub(p.FirstSeen)), maxInt(1, p.Count))
+	}
+	keys := make([]string, 0, len(p.Details))
+	for k := range p.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", k, p.Details[k])
+	}
+
+	section := func(title string, lines []string) {
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		if len(lines) == 0 {
+			b.WriteString("  (none)\n")
+		}
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	if d.Describe != nil {
+		section("Pod", d.Describe)
+	} else {
+		fmt.Fprintf(&b, "\nNo pod named %s (the problem is about a service or route)\n", p.PodName)
+	}
+	section("Events (newest first)", d.Events)
+	if d.LogContainer != "" {
+		title := fmt.Sprintf("Logs of container %s (last %d lines)", d.LogContainer, detailLogLines)
+		if d.PreviousLogs {
+			title = fmt.Sprintf("Logs of the previous, terminated instance of container %s (last %d lines)", d.LogContainer, detailLogLines)
+		}
+		section(title, d.Logs)
+	}
+	if len(d.Errors) > 0 {
+		section("Could not fetch", d.Errors)
+	}
+	return b.String()
+}
+
+// wrapText breaks text into lines of at most width runes, at spaces where
+// possible, keeping the text's own line breaks.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		para = strings.TrimRight(strings.ReplaceAll(para, "\t", "    "), " \r")
+		indent := para[:len(para)-len(strings.TrimLeft(para, " "))]
+		runes := []rune(para)
+		for width > len(indent)+8 && len(runes) > width {
+			cut := width
+			for i := width; i > len(indent)+width/2; i-- {
+				if runes[i] == ' ' {
+					cut = i
+					break
+				}
+			}
+			lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+			runes = append([]rune(indent), []rune(strings.TrimLeft(string(runes[cut:]), " "))...)
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
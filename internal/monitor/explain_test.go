@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExplainContext(t *testing.T) {
+	now := time.Now()
+	p := &Problem{
+		Severity: SeverityFatal, Type: "CrashLoopBackOff", Namespace: "prod", PodName: "api-7d9f", ContainerName: "app",
+		SubReason: "exit code 137", Message: "back-off restarting failed container", FirstSeen: now.Add(-10 * time.Minute), Count: 4,
+	}
+	d := &ProblemDetails{
+		Describe:     []string{"Node: node-a  Phase: Running"},
+		LogContainer: "app",
+		PreviousLogs: true,
+		Logs:         []string{"panic: out of memory"},
+		Errors:       []string{"events: forbidden"},
+	}
+
+	text := ExplainContext(p, d, now)
+	assert.Contains(t, text, "Problem: FATAL CrashLoopBackOff")
+	assert.Contains(t, text, "Object: prod/api-7d9f container app")
+	assert.Contains(t, text, "Reason: exit code 137")
+	assert.Contains(t, text, "First seen: 10m ago, occurrences: 4")
+	assert.Contains(t, text, "Pod:\n  Node: node-a")
+	assert.Contains(t, text, "Events (newest first):\n  (none)")
+	assert.Contains(t, text, "previous, terminated instance of container app")
+	assert.Contains(t, text, "  panic: out of memory")
+	assert.Contains(t, text, "Could not fetch:\n  events: forbidden")
+
+	text = ExplainContext(&Problem{Namespace: "prod", PodName: "checkout", Type: "NoReadyEndpoints"}, &ProblemDetails{}, now)
+	assert.Contains(t, text, "No pod named checkout")
+	assert.NotContains(t, text, "Logs")
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("CAUSE: the container exceeds its memory limit\n  1. raise the limit to 512Mi", 20)
+	assert.Equal(t, []string{
+		"CAUSE: the container",
+		"exceeds its memory",
+		"limit",
+		"  1. raise the limit",
+		"  to 512Mi",
+	}, lines)
+	for _, line := range wrapText(strings.Repeat("x", 50), 20) {
+		assert.LessOrEqual(t, len(line), 20)
+	}
+}
+
+func TestModel_Explain(t *testing.T) {
+	w := NewWatcher(fake.NewClientset(crashingPod()), Config{})
+	m := NewModel(w)
+	m.stats.Connection = ConnectionOK
+	m.width, m.height = 120, 40
+	m.allProblems = []Problem{{Severity: SeverityFatal, Type: "CrashLoopBackOff", Namespace: "prod", PodName: "api-7d9f", ContainerName: "app"}}
+	m.filterProblems()
+
+	// Without a model the overlay says how to turn explain on
+	key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}
+	_, cmd := m.Update(key)
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.View(), "LLM explain is off")
+	m.Update(key)
+	assert.Nil(t, m.explain)
+
+	var got *ProblemDetails
+	m.SetExplainer("test-model", func(_ context.Context, _ *Problem, d *ProblemDetails) (string, error) {
+		got = d
+		return "CAUSE: OOMKilled at the 256Mi limit\nFIX: raise the memory limit", nil
+	})
+	_, cmd = m.Update(key)
+	require.NotNil(t, cmd)
+	assert.Contains(t, m.View(), "Asking test-model")
+
+	m.Update(cmd())
+	require.NotNil(t, got, "the drill-down is fetched for the LLM")
+	assert.Equal(t, []string{"fake logs"}, got.Logs)
+	view := m.View()
+	assert.Contains(t, view, "LLM explanation (test-model)")
+	assert.Contains(t, view, "CAUSE: OOMKilled at the 256Mi limit")
+
+	// esc closes the overlay back to the list
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, m.explain)
+	assert.Contains(t, m.View(), "1 PROBLEMS")
+
+	// From an open drill-down its details are reused, and errors are shown
+	m.detail = &m.problems[0]
+	m.details = &ProblemDetails{Logs: []string{"cached"}}
+	m.SetExplainer("test-model", func(_ context.Context, _ *Problem, d *ProblemDetails) (string, error) {
+		got = d
+		return "", assert.AnError
+	})
+	_, cmd = m.Update(key)
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	assert.Equal(t, []string{"cached"}, got.Logs)
+	assert.Contains(t, m.View(), "LLM request failed")
+	m.Update(key)
+	assert.Nil(t, m.explain)
+	assert.NotNil(t, m.detail, "closing the explanation returns to the drill-down")
+}
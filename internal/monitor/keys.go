@@ -0,0 +1,51 @@
+package monitor
+
+import "github.com/ppiankov/kubenow/internal/keymap"
+
+// Monitor TUI actions, as named in the keybindings.monitor config section.
+const (
+	actionQuit         keymap.Action = "quit"
+	actionClearFilter  keymap.Action = "clear-filter"
+	actionPause        keymap.Action = "pause"
+	actionSearch       keymap.Action = "search"
+	actionSortSeverity keymap.Action = "sort-severity"
+	actionSortRecency  keymap.Action = "sort-recency"
+	actionSortCount    keymap.Action = "sort-count"
+	actionScrollUp     keymap.Action = "scroll-up"
+	actionScrollDown   keymap.Action = "scroll-down"
+	actionTop          keymap.Action = "top"
+	actionBottom       keymap.Action = "bottom"
+	actionPageUp       keymap.Action = "page-up"
+	actionPageDown     keymap.Action = "page-down"
+	actionDetails      keymap.Action = "details"
+	actionExplain      keymap.Action = "explain"
+	actionExport       keymap.Action = "export"
+	actionCopy         keymap.Action = "copy"
+)
+
+// DefaultKeyBindings are the monitor TUI's bindings before config overrides.
+var DefaultKeyBindings = []keymap.Binding{
+	{Action: actionQuit, Keys: []string{"q"}, Description: "quit"},
+	{Action: actionSearch, Keys: []string{"/"}, Description: "search problems"},
+	{Action: actionClearFilter, Keys: []string{"esc"}, Description: "close explanation or details, or clear search filter"},
+	{Action: actionPause, Keys: []string{"p", " "}, Description: "pause/resume updates"},
+	{Action: actionSortSeverity, Keys: []string{"1"}, Description: "sort by severity"},
+	{Action: actionSortRecency, Keys: []string{"2"}, Description: "sort by recency"},
+	{Action: actionSortCount, Keys: []string{"3"}, Description: "sort by count"},
+	{Action: actionScrollUp, Keys: []string{"up", "k"}, Description: "select previous problem (scroll details)"},
+	{Action: actionScrollDown, Keys: []string{"down", "j"}, Description: "select next problem (scroll details)"},
+	{Action: actionTop, Keys: []string{"home", "g"}, Description: "go to top"},
+	{Action: actionBottom, Keys: []string{"end", "G"}, Description: "go to bottom"},
+	{Action: actionPageUp, Keys: []string{"pgup"}, Description: "page up"},
+	{Action: actionPageDown, Keys: []string{"pgdown"}, Description: "page down"},
+	{Action: actionDetails, Keys: []string{"enter"}, Description: "open/close details: pod describe, events, and logs"},
+	{Action: actionExplain, Keys: []string{"a"}, Description: "open/close LLM explanation of the selected problem"},
+	{Action: actionExport, Keys: []string{"e"}, Description: "export problems to file and exit"},
+	{Action: actionCopy, Keys: []string{"c", "v"}, Description: "print problems to terminal (copyable)"},
+	{Action: keymap.Help, Keys: []string{"?"}, Description: "toggle this help"},
+}
+
+// NewKeyMap builds the monitor key map with config overrides applied.
+func NewKeyMap(overrides map[string][]string) (*keymap.Map, error) {
+	return keymap.New(DefaultKeyBindings, overrides)
+}
@@ -0,0 +1,212 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Sub-reasons turn a problem type into something actionable on the compact
+// line: which probe failed and how, which volume would not mount, which
+// scheduling predicate rejected the nodes. Each extractor returns the
+// sub-reason and the structured details it was built from; an empty
+// sub-reason means the message was not recognized.
+
+var (
+	probeKindRe = regexp.MustCompile(
+		`(?s)^(Liveness|Readiness|Startup) probe (?:failed|errored)(?: and resulted in unknown state)?:?\s*(.*)$`)
+	probeStatusRe = regexp.MustCompile(`statuscode: (\d{3})`)
+	volumeNameRe  = regexp.MustCompile(`for volume "([^"]+)"\s*:\s*(.*)$`)
+	unmountedRe   = regexp.MustCompile(`unmounted volumes=\[([^\]]*)\]`)
+	schedulingRe  = regexp.MustCompile(`^0/(\d+) nodes are available: (.*?)(?:\. preemption:.*)?\.?$`)
+	predicateRe   = regexp.MustCompile(`^(\d+) (.*)$`)
+	braceSuffixRe = regexp.MustCompile(`\s*\{[^}]*\}`)
+	dialErrorRe   = regexp.MustCompile(`connect: (connection refused|no route to host|network is unreachable)`)
+	notFoundRefRe = regexp.MustCompile(`(secret|configmap|persistentvolumeclaim) "([^"]+)" not found`)
+	missingKeyRe  = regexp.MustCompile(`couldn't find key (\S+) in (Secret|ConfigMap) (\S+)`)
+)
+
+// maxSubReasonLen keeps sub-reasons to one compact line.
+const maxSubReasonLen = 120
+
+// eventSubReason extracts a sub-reason from a Warning event.
+func eventSubReason(reason, message string) (string, map[string]string) {
+	switch reason {
+	case "Unhealthy":
+		return probeSubReason(message)
+	case "FailedMount", "FailedAttachVolume":
+		return mountSubReason(reason, message)
+	case "FailedScheduling":
+		return schedulingSubReason(message)
+	default:
+		return "", nil
+	}
+}
+
+// probeSubReason parses kubelet probe failures such as
+// "Liveness probe failed: HTTP probe failed with statuscode: 503".
+func probeSubReason(message string) (string, map[string]string) {
+	m := probeKindRe.FindStringSubmatch(message)
+	if m == nil {
+		return "", nil
+	}
+	probe := strings.ToLower(m[1])
+	output := strings.TrimSpace(m[2])
+	details := map[string]string{"probe": probe}
+
+	var cause string
+	switch {
+	case probeStatusRe.MatchString(output):
+		code := probeStatusRe.FindStringSubmatch(output)[1]
+		details["http_status"] = code
+		cause = "HTTP " + code
+	case dialErrorRe.MatchString(output):
+		cause = dialErrorRe.FindStringSubmatch(output)[1]
+	case strings.Contains(output, "context deadline exceeded"),
+		strings.Contains(output, "Client.Timeout exceeded"),
+		strings.Contains(output, "timeout"):
+		cause = "timeout"
+	case output == "":
+		cause = "failed"
+	default:
+		cause = firstLine(output) // exec probe output
+	}
+	details["probe_result"] = cause
+	return clip(fmt.Sprintf("%s probe: %s", probe, cause)), details
+}
+
+// mountSubReason parses FailedMount and FailedAttachVolume messages such as
+// `MountVolume.SetUp failed for volume "config" : configmap "app" not found`.
+func mountSubReason(reason, message string) (string, map[string]string) {
+	details := map[string]string{}
+	var volume, cause string
+	if m := volumeNameRe.FindStringSubmatch(message); m != nil {
+		volume, cause = m[1], m[2]
+	} else if m := unmountedRe.FindStringSubmatch(message); m != nil && strings.TrimSpace(m[1]) != "" {
+		volume = strings.Fields(m[1])[0]
+		cause = "timed out waiting for mount"
+	} else {
+		return "", nil
+	}
+	if m := notFoundRefRe.FindStringSubmatch(cause); m != nil {
+		cause = fmt.Sprintf("%s %q not found", m[1], m[2])
+		details["missing_"+m[1]] = m[2]
+	}
+	details["volume"] = volume
+	verb := "mount"
+	if reason == "FailedAttachVolume" {
+		verb = "attach"
+	}
+	return clip(fmt.Sprintf("volume %s %s failed: %s", volume, verb, firstLine(cause))), details
+}
+
+// schedulingSubReason summarizes FailedScheduling messages such as
+// "0/5 nodes are available: 3 Insufficient cpu, 2 node(s) had untolerated
+// taint {node-role.kubernetes.io/control-plane: }." into the predicates
+// that rejected nodes, most frequent first.
+func schedulingSubReason(message string) (string, map[string]string) {
+	m := schedulingRe.FindStringSubmatch(strings.TrimSpace(message))
+	if m == nil {
+		return "", nil
+	}
+	type predicate struct {
+		count int
+		name  string
+	}
+	var predicates []predicate
+	for _, part := range strings.Split(m[2], ", ") {
+		pm := predicateRe.FindStringSubmatch(strings.TrimSpace(part))
+		if pm == nil {
+			continue
+		}
+		count, _ := strconv.Atoi(pm[1])
+		name := braceSuffixRe.ReplaceAllString(pm[2], "")
+		name = strings.TrimSuffix(strings.TrimPrefix(name, "node(s) "), ".")
+		predicates = append(predicates, predicate{count: count, name: name})
+	}
+	if len(predicates) == 0 {
+		return "", nil
+	}
+	sort.SliceStable(predicates, func(i, j int) bool { return predicates[i].count > predicates[j].count })
+
+	parts := make([]string, len(predicates))
+	for i, p := range predicates {
+		parts[i] = fmt.Sprintf("%d %s", p.count, p.name)
+	}
+	details := map[string]string{"nodes_total": m[1], "predicate": predicates[0].name}
+	return clip(fmt.Sprintf("0/%s nodes: %s", m[1], strings.Join(parts, ", "))), details
+}
+
+// terminationSubReason describes the last termination of a container,
+// e.g. "last exit 137 (OOMKilled)".
+func terminationSubReason(cs *corev1.ContainerStatus) (string, map[string]string) {
+	t := cs.LastTerminationState.Terminated
+	if t == nil {
+		return "", nil
+	}
+	details := map[string]string{"exit_code": strconv.Itoa(int(t.ExitCode))}
+	sub := fmt.Sprintf("last exit %d", t.ExitCode)
+	if t.Reason != "" {
+		details["termination_reason"] = t.Reason
+		sub += fmt.Sprintf(" (%s)", t.Reason)
+	}
+	if t.Signal != 0 {
+		details["signal"] = strconv.Itoa(int(t.Signal))
+	}
+	return sub, details
+}
+
+// imagePullSubReason classifies image pull failures from the waiting message.
+func imagePullSubReason(message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "manifest unknown"), strings.Contains(lower, "not found"):
+		return "image or tag not found"
+	case strings.Contains(lower, "unauthorized"), strings.Contains(lower, "pull access denied"),
+		strings.Contains(lower, "authentication required"), strings.Contains(lower, "403 forbidden"):
+		return "registry denied access (check imagePullSecrets)"
+	case strings.Contains(lower, "toomanyrequests"), strings.Contains(lower, "rate limit"):
+		return "registry rate limit"
+	case strings.Contains(lower, "no such host"), strings.Contains(lower, "i/o timeout"),
+		strings.Contains(lower, "connection refused"):
+		return "registry unreachable"
+	case strings.HasPrefix(lower, "back-off pulling image"):
+		return "back-off after failed pulls"
+	default:
+		return ""
+	}
+}
+
+// configErrorSubReason parses CreateContainerConfigError messages such as
+// `secret "db" not found` or "couldn't find key password in Secret prod/db".
+func configErrorSubReason(message string) (string, map[string]string) {
+	if m := notFoundRefRe.FindStringSubmatch(message); m != nil {
+		return fmt.Sprintf("%s %q not found", m[1], m[2]), map[string]string{"missing_" + m[1]: m[2]}
+	}
+	if m := missingKeyRe.FindStringSubmatch(message); m != nil {
+		kind := strings.ToLower(m[2])
+		return fmt.Sprintf("key %s missing from %s %s", m[1], kind, m[3]), map[string]string{"missing_key": m[1], kind: m[3]}
+	}
+	if message == "" {
+		return "", nil
+	}
+	return clip(firstLine(message)), nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return strings.TrimSpace(s)
+}
+
+func clip(s string) string {
+	if len(s) <= maxSubReasonLen {
+		return s
+	}
+	return s[:maxSubReasonLen-3] + "..."
+}
@@ -0,0 +1,209 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventSubReason(t *testing.T) {
+	tests := []struct {
+		name        string
+		reason      string
+		message     string
+		wantSub     string
+		wantDetails map[string]string
+	}{
+		{
+			name:        "liveness HTTP status",
+			reason:      "Unhealthy",
+			message:     "Liveness probe failed: HTTP probe failed with statuscode: 503",
+			wantSub:     "liveness probe: HTTP 503",
+			wantDetails: map[string]string{"probe": "liveness", "http_status": "503"},
+		},
+		{
+			name:        "readiness connection refused",
+			reason:      "Unhealthy",
+			message:     `Readiness probe failed: Get "http://10.1.2.3:8080/ready": dial tcp 10.1.2.3:8080: connect: connection refused`,
+			wantSub:     "readiness probe: connection refused",
+			wantDetails: map[string]string{"probe": "readiness", "probe_result": "connection refused"},
+		},
+		{
+			name:    "startup timeout",
+			reason:  "Unhealthy",
+			message: `Startup probe failed: Get "http://10.1.2.3:8080/": context deadline exceeded (Client.Timeout exceeded while awaiting headers)`,
+			wantSub: "startup probe: timeout",
+		},
+		{
+			name:    "exec probe output",
+			reason:  "Unhealthy",
+			message: "Liveness probe failed: pg_isready: no response\nextra",
+			wantSub: "liveness probe: pg_isready: no response",
+		},
+		{
+			name:        "mount missing configmap",
+			reason:      "FailedMount",
+			message:     `MountVolume.SetUp failed for volume "config" : configmap "app-config" not found`,
+			wantSub:     `volume config mount failed: configmap "app-config" not found`,
+			wantDetails: map[string]string{"volume": "config", "missing_configmap": "app-config"},
+		},
+		{
+			name:   "mount timeout",
+			reason: "FailedMount",
+			message: "Unable to attach or mount volumes: unmounted volumes=[data], " +
+				"unattached volumes=[data kube-api-access-x]: timed out waiting for the condition",
+			wantSub:     "volume data mount failed: timed out waiting for mount",
+			wantDetails: map[string]string{"volume": "data"},
+		},
+		{
+			name:    "attach failure",
+			reason:  "FailedAttachVolume",
+			message: `AttachVolume.Attach failed for volume "pvc-123" : rpc error: code = Internal desc = volume is attached to another node`,
+			wantSub: "volume pvc-123 attach failed: rpc error: code = Internal desc = volume is attached to another node",
+		},
+		{
+			name:   "scheduling predicates sorted by count",
+			reason: "FailedScheduling",
+			message: "0/6 nodes are available: 1 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }, " +
+				"5 Insufficient memory. preemption: 0/6 nodes are available: 6 Preemption is not helpful for scheduling.",
+			wantSub:     "0/6 nodes: 5 Insufficient memory, 1 had untolerated taint",
+			wantDetails: map[string]string{"nodes_total": "6", "predicate": "Insufficient memory"},
+		},
+		{
+			name:    "unrecognized probe message",
+			reason:  "Unhealthy",
+			message: "something else entirely",
+		},
+		{
+			name:    "unrelated reason",
+			reason:  "BackOff",
+			message: "Back-off restarting failed container",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, details := eventSubReason(tt.reason, tt.message)
+			assert.Equal(t, tt.wantSub, sub)
+			for k, v := range tt.wantDetails {
+				assert.Equal(t, v, details[k], k)
+			}
+		})
+	}
+}
+
+func TestImagePullSubReason(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{`rpc error: code = NotFound desc = failed to pull and unpack image "x:1": not found`, "image or tag not found"},
+		{`pull access denied for private/app, repository does not exist or may require 'docker login'`,
+			"registry denied access (check imagePullSecrets)"},
+		{`429 Too Many Requests - toomanyrequests: You have reached your pull rate limit`, "registry rate limit"},
+		{`dial tcp: lookup registry.internal: no such host`, "registry unreachable"},
+		{`Back-off pulling image "nginx:1.99"`, "back-off after failed pulls"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, imagePullSubReason(tt.message), tt.message)
+	}
+}
+
+func TestConfigErrorSubReason(t *testing.T) {
+	sub, details := configErrorSubReason(`secret "db-creds" not found`)
+	assert.Equal(t, `secret "db-creds" not found`, sub)
+	assert.Equal(t, "db-creds", details["missing_secret"])
+
+	sub, details = configErrorSubReason("couldn't find key password in Secret prod/db-creds")
+	assert.Equal(t, "key password missing from secret prod/db-creds", sub)
+	assert.Equal(t, "password", details["missing_key"])
+
+	sub, _ = configErrorSubReason("")
+	assert.Empty(t, sub)
+}
+
+func TestProcessPodStatus_SubReasons(t *testing.T) {
+	w := NewWatcher(nil, Config{})
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "prod", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 4,
+					State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 137, Reason: "OOMKilled", FinishedAt: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+					}},
+				},
+				{
+					Name: "sidecar",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason: "CreateContainerConfigError", Message: `secret "sidecar-token" not found`,
+					}},
+				},
+			},
+		},
+	}
+	w.processPodStatus(pod)
+
+	problems, _, _ := w.GetState()
+	byType := map[string]Problem{}
+	for _, p := range problems {
+		byType[p.Type] = p
+	}
+	require.Contains(t, byType, "CrashLoopBackOff")
+	assert.Equal(t, "last exit 137 (OOMKilled)", byType["CrashLoopBackOff"].SubReason)
+	assert.Equal(t, "137", byType["CrashLoopBackOff"].Details["exit_code"])
+	assert.Equal(t, "4", byType["CrashLoopBackOff"].Details["restarts"])
+
+	require.Contains(t, byType, "CreateContainerConfigError")
+	assert.Equal(t, `secret "sidecar-token" not found`, byType["CreateContainerConfigError"].SubReason)
+	assert.Equal(t, "sidecar", byType["CreateContainerConfigError"].ContainerName)
+}
+
+func TestProcessPodStatus_PendingSchedulingSubReason(t *testing.T) {
+	w := NewWatcher(nil, Config{})
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch-1", Namespace: "jobs", CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute))},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable",
+				Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+			}},
+		},
+	}
+	w.processPodStatus(pod)
+
+	problems, _, _ := w.GetState()
+	require.Len(t, problems, 1)
+	assert.Equal(t, "0/3 nodes: 3 Insufficient nvidia.com/gpu", problems[0].SubReason)
+	assert.Equal(t, "Insufficient nvidia.com/gpu", problems[0].Details["predicate"])
+	assert.Equal(t, "Unschedulable", problems[0].Details["reason"])
+}
+
+func TestProcessEvent_ProbeSubReasonUpdates(t *testing.T) {
+	w := NewWatcher(nil, Config{})
+	event := &corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "prod", Name: "api-1"},
+		Reason:         "Unhealthy",
+		Type:           corev1.EventTypeWarning,
+		Message:        "Readiness probe failed: HTTP probe failed with statuscode: 500",
+		Count:          1,
+	}
+	w.processEvent(event)
+	event.Message = "Readiness probe failed: HTTP probe failed with statuscode: 503"
+	w.processEvent(event)
+
+	problems, _, _ := w.GetState()
+	require.Len(t, problems, 1)
+	assert.Equal(t, "readiness probe: HTTP 503", problems[0].SubReason)
+	assert.Equal(t, "503", problems[0].Details["http_status"])
+	assert.Equal(t, 2, problems[0].Count)
+}
@@ -0,0 +1,371 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// Service health constants
+const (
+	// Polling interval for endpoint readiness and ingress error checks
+	serviceHealthPollInterval = 30 * time.Second
+
+	// Ingress 5xx ratio thresholds (over ingress5xxWindow)
+	ingress5xxWarningRatio  = 0.01
+	ingress5xxCriticalRatio = 0.05
+
+	// Ignore ingresses with less traffic than this (requests/sec) to avoid noise
+	ingressMinRPS = 0.1
+
+	ingress5xxWindow = "5m"
+
+	problemTypeNoReadyEndpoints = "NoReadyEndpoints"
+	problemTypeIngress5xx       = "Ingress5xx"
+)
+
+// SetPrometheusAPI enables Prometheus-backed checks (ingress 5xx rates).
+func (w *Watcher) SetPrometheusAPI(api v1.API) {
+	w.promAPI = api
+}
+
+// watchServiceHealth polls Services for ready endpoints and, when Prometheus
+// is configured, ingress controllers for elevated 5xx rates.
+func (w *Watcher) watchServiceHealth(ctx context.Context) {
+	w.checkServiceHealth(ctx)
+
+	ticker := time.NewTicker(serviceHealthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.checkServiceHealth(ctx)
+		}
+	}
+}
+
+// checkServiceHealth runs all service-level checks once.
+func (w *Watcher) checkServiceHealth(ctx context.Context) {
+	services, err := w.clientset.CoreV1().Services(w.config.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return // transient or forbidden — pod/event watchers report connectivity
+	}
+	pods, err := w.listPods(ctx)
+	if err != nil {
+		return
+	}
+	slices, err := w.clientset.DiscoveryV1().EndpointSlices(w.config.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+
+	readyByService := countReadyEndpoints(slices.Items)
+	for i := range services.Items {
+		w.checkServiceEndpoints(&services.Items[i], pods, readyByService)
+	}
+
+	if w.promAPI != nil {
+		w.checkIngress5xx(ctx, services.Items, pods)
+	}
+}
+
+// listPods returns the watched pods from the informer cache once Start
+// has set it up, and from the API otherwise.
+func (w *Watcher) listPods(ctx context.Context) ([]corev1.Pod, error) {
+	if w.pods == nil {
+		list, err := w.clientset.CoreV1().Pods(w.config.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	cached, err := w.pods.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]corev1.Pod, len(cached))
+	for i, p := range cached {
+		pods[i] = *p
+	}
+	return pods, nil
+}
+
+// countReadyEndpoints returns ready endpoint counts keyed by namespace/service.
+// An endpoint with unknown readiness is counted as ready, per the EndpointSlice API.
+func countReadyEndpoints(slices []discoveryv1.EndpointSlice) map[string]int {
+	ready := make(map[string]int)
+	for i := range slices {
+		s := &slices[i]
+		svc := s.Labels[discoveryv1.LabelServiceName]
+		if svc == "" {
+			continue
+		}
+		key := s.Namespace + "/" + svc
+		if _, ok := ready[key]; !ok {
+			ready[key] = 0
+		}
+		for j := range s.Endpoints {
+			if r := s.Endpoints[j].Conditions.Ready; r == nil || *r {
+				ready[key] += len(s.Endpoints[j].Addresses)
+			}
+		}
+	}
+	return ready
+}
+
+// checkServiceEndpoints reports a Service whose selector targets pods but
+// which has zero ready endpoints.
+func (w *Watcher) checkServiceEndpoints(svc *corev1.Service, pods []corev1.Pod, readyByService map[string]int) {
+	if len(svc.Spec.Selector) == 0 || svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return // selector-less services manage endpoints manually
+	}
+	if readyByService[svc.Namespace+"/"+svc.Name] > 0 {
+		return
+	}
+
+	backing := selectServicePods(svc, pods)
+	workload := backingWorkload(backing)
+	readyPods := 0
+	for i := range backing {
+		if isPodReady(backing[i]) {
+			readyPods++
+		}
+	}
+
+	severity := SeverityCritical
+	var message string
+	switch {
+	case len(backing) == 0:
+		// Could be intentionally scaled to zero or a selector typo
+		severity = SeverityWarning
+		message = fmt.Sprintf("service %q has 0 ready endpoints: selector matches no pods", svc.Name)
+	case workload != "":
+		message = fmt.Sprintf("service %q has 0 ready endpoints (backing %s: %d/%d pods ready)",
+			svc.Name, workload, readyPods, len(backing))
+	default:
+		message = fmt.Sprintf("service %q has 0 ready endpoints (%d/%d pods ready)", svc.Name, readyPods, len(backing))
+	}
+
+	details := map[string]string{
+		"service":      svc.Name,
+		"matched_pods": fmt.Sprintf("%d", len(backing)),
+		"ready_pods":   fmt.Sprintf("%d", readyPods),
+		"hint":         fmt.Sprintf("kubectl get endpointslices -n %s -l %s=%s", svc.Namespace, discoveryv1.LabelServiceName, svc.Name),
+	}
+	if workload != "" {
+		details["workload"] = workload
+	}
+
+	w.addProblem(severity, problemTypeNoReadyEndpoints, svc.Namespace, svc.Name, "", message, details)
+}
+
+// checkIngress5xx queries ingress-nginx request metrics and reports services
+// whose 5xx ratio exceeds the warning threshold.
+func (w *Watcher) checkIngress5xx(ctx context.Context, services []corev1.Service, pods []corev1.Pod) {
+	queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	total, err := w.queryIngressRates(queryCtx, false)
+	if err != nil {
+		return
+	}
+	errs, err := w.queryIngressRates(queryCtx, true)
+	if err != nil {
+		return
+	}
+
+	svcIndex := make(map[string]*corev1.Service, len(services))
+	for i := range services {
+		svcIndex[services[i].Namespace+"/"+services[i].Name] = &services[i]
+	}
+
+	for _, r := range computeIngressErrorRates(total, errs) {
+		if w.config.Namespace != "" && r.Namespace != w.config.Namespace {
+			continue
+		}
+		if r.TotalRPS < ingressMinRPS || r.Ratio < ingress5xxWarningRatio {
+			continue
+		}
+
+		severity := SeverityWarning
+		if r.Ratio >= ingress5xxCriticalRatio {
+			severity = SeverityCritical
+		}
+
+		target := r.Service
+		details := map[string]string{
+			"ingress":   r.Ingress,
+			"service":   r.Service,
+			"5xx_ratio": fmt.Sprintf("%.1f%%", r.Ratio*100),
+			"rps":       fmt.Sprintf("%.2f", r.TotalRPS),
+		}
+		message := fmt.Sprintf("ingress %q → service %q: %.1f%% 5xx over %s (%.2f rps)",
+			r.Ingress, r.Service, r.Ratio*100, ingress5xxWindow, r.TotalRPS)
+		if svc, ok := svcIndex[r.Namespace+"/"+r.Service]; ok {
+			if workload := backingWorkload(selectServicePods(svc, pods)); workload != "" {
+				details["workload"] = workload
+				message += fmt.Sprintf(", backing %s", workload)
+			}
+		}
+		if target == "" {
+			target = r.Ingress
+		}
+		details["hint"] = fmt.Sprintf("kubectl describe ingress -n %s %s", r.Namespace, r.Ingress)
+
+		w.addProblem(severity, problemTypeIngress5xx, r.Namespace, target, "", message, details)
+	}
+}
+
+// ingressErrorRate is the 5xx ratio for one ingress → service route.
+type ingressErrorRate struct {
+	Namespace string
+	Ingress   string
+	Service   string
+	TotalRPS  float64
+	Ratio     float64
+}
+
+// ingress5xxQuery builds the ingress-nginx request rate query. Both the
+// native and exported_* label variants are kept so results are correct
+// whether or not the scrape config honors target labels.
+func ingress5xxQuery(errorsOnly bool) string {
+	matcher := ""
+	if errorsOnly {
+		matcher = `{status=~"5.."}`
+	}
+	return `sum by(namespace, exported_namespace, ingress, service, exported_service)(rate(nginx_ingress_controller_requests` +
+		matcher + `[` + ingress5xxWindow + `]))`
+}
+
+func (w *Watcher) queryIngressRates(ctx context.Context, errorsOnly bool) (model.Vector, error) {
+	result, _, err := w.promAPI.Query(ctx, ingress5xxQuery(errorsOnly), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", result)
+	}
+	return vector, nil
+}
+
+// computeIngressErrorRates joins total and 5xx rate vectors into per-route ratios,
+// sorted by ratio descending.
+func computeIngressErrorRates(total, errs model.Vector) []ingressErrorRate {
+	errIndex := make(map[string]float64, len(errs))
+	for _, s := range errs {
+		ns, ing, svc := ingressRouteLabels(s.Metric)
+		errIndex[ns+"/"+ing+"/"+svc] += float64(s.Value)
+	}
+
+	byRoute := make(map[string]*ingressErrorRate)
+	for _, s := range total {
+		ns, ing, svc := ingressRouteLabels(s.Metric)
+		key := ns + "/" + ing + "/" + svc
+		r, ok := byRoute[key]
+		if !ok {
+			r = &ingressErrorRate{Namespace: ns, Ingress: ing, Service: svc}
+			byRoute[key] = r
+		}
+		r.TotalRPS += float64(s.Value)
+	}
+
+	rates := make([]ingressErrorRate, 0, len(byRoute))
+	for key, r := range byRoute {
+		if r.TotalRPS > 0 {
+			r.Ratio = errIndex[key] / r.TotalRPS
+		}
+		rates = append(rates, *r)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Ratio != rates[j].Ratio {
+			return rates[i].Ratio > rates[j].Ratio
+		}
+		return rates[i].Namespace+rates[i].Ingress < rates[j].Namespace+rates[j].Ingress
+	})
+	return rates
+}
+
+// ingressRouteLabels extracts namespace/ingress/service, preferring exported_* labels.
+func ingressRouteLabels(m model.Metric) (namespace, ingress, service string) {
+	namespace = string(m["exported_namespace"])
+	if namespace == "" {
+		namespace = string(m["namespace"])
+	}
+	service = string(m["exported_service"])
+	if service == "" {
+		service = string(m["service"])
+	}
+	return namespace, string(m["ingress"]), service
+}
+
+// selectServicePods returns the pods in the service's namespace matched by its selector.
+func selectServicePods(svc *corev1.Service, pods []corev1.Pod) []*corev1.Pod {
+	if len(svc.Spec.Selector) == 0 {
+		return nil
+	}
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	var matched []*corev1.Pod
+	for i := range pods {
+		p := &pods[i]
+		if p.Namespace == svc.Namespace && selector.Matches(labels.Set(p.Labels)) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// backingWorkload resolves the owning workload ("deployment/name") of a set of
+// pods. When pods belong to several workloads, they are joined with commas.
+func backingWorkload(pods []*corev1.Pod) string {
+	seen := make(map[string]bool)
+	var workloads []string
+	for _, p := range pods {
+		w := podWorkload(p)
+		if !seen[w] {
+			seen[w] = true
+			workloads = append(workloads, w)
+		}
+	}
+	sort.Strings(workloads)
+	return strings.Join(workloads, ",")
+}
+
+// podWorkload resolves a pod's owning workload from its ownerReferences.
+func podWorkload(p *corev1.Pod) string {
+	if len(p.OwnerReferences) == 0 {
+		return "pod/" + p.Name
+	}
+	owner := p.OwnerReferences[0]
+	switch owner.Kind {
+	case "ReplicaSet":
+		return "deployment/" + metrics.ResolveWorkloadName(p.Name, p.Labels)
+	default:
+		return strings.ToLower(owner.Kind) + "/" + owner.Name
+	}
+}
+
+// isPodReady reports whether the pod's Ready condition is true.
+func isPodReady(p *corev1.Pod) bool {
+	for i := range p.Status.Conditions {
+		c := &p.Status.Conditions[i]
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testService(ns, name string, selector map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec:       corev1.ServiceSpec{Selector: selector, Type: corev1.ServiceTypeClusterIP},
+	}
+}
+
+func testPod(ns, name string, podLabels map[string]string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       ns,
+			Labels:          podLabels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-7d8f9c4b6"}},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func testEndpointSlice(ns, svc string, ready ...bool) *discoveryv1.EndpointSlice {
+	s := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svc + "-abc",
+			Namespace: ns,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: svc},
+		},
+	}
+	for _, r := range ready {
+		s.Endpoints = append(s.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{"10.0.0.1"},
+			Conditions: discoveryv1.EndpointConditions{Ready: &r},
+		})
+	}
+	return s
+}
+
+func TestCheckServiceHealth_NoReadyEndpoints(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testService("shop", "api", map[string]string{"app": "api"}),
+		testPod("shop", "api-7d8f9c4b6-abc12", map[string]string{"app": "api"}, false),
+		testPod("shop", "api-7d8f9c4b6-def34", map[string]string{"app": "api"}, false),
+		testEndpointSlice("shop", "api", false, false),
+		// Healthy service — no problem expected
+		testService("shop", "web", map[string]string{"app": "web"}),
+		testEndpointSlice("shop", "web", true),
+		// Selector-less service — skipped
+		testService("shop", "external", nil),
+	)
+	w := NewWatcher(client, Config{})
+	w.checkServiceHealth(context.Background())
+
+	problems, _, _ := w.GetState()
+	require.Len(t, problems, 1)
+	p := problems[0]
+	assert.Equal(t, SeverityCritical, p.Severity)
+	assert.Equal(t, problemTypeNoReadyEndpoints, p.Type)
+	assert.Equal(t, "shop", p.Namespace)
+	assert.Equal(t, "api", p.PodName)
+	assert.Equal(t, "deployment/api", p.Details["workload"])
+	assert.Contains(t, p.Message, "backing deployment/api: 0/2 pods ready")
+}
+
+func TestCheckServiceHealth_SelectorMatchesNoPods(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testService("shop", "typo", map[string]string{"app": "tyop"}),
+	)
+	w := NewWatcher(client, Config{})
+	w.checkServiceHealth(context.Background())
+
+	problems, _, _ := w.GetState()
+	require.Len(t, problems, 1)
+	assert.Equal(t, SeverityWarning, problems[0].Severity)
+	assert.Contains(t, problems[0].Message, "selector matches no pods")
+}
+
+func TestCountReadyEndpoints(t *testing.T) {
+	slices := []discoveryv1.EndpointSlice{
+		*testEndpointSlice("a", "svc", true, false),
+		*testEndpointSlice("a", "svc", true),
+		*testEndpointSlice("a", "down", false),
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "orphan"}},
+	}
+	// nil readiness counts as ready
+	slices = append(slices, discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "a", Labels: map[string]string{discoveryv1.LabelServiceName: "unknown"}},
+		Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.2"}}},
+	})
+
+	got := countReadyEndpoints(slices)
+	assert.Equal(t, map[string]int{"a/svc": 2, "a/down": 0, "a/unknown": 1}, got)
+}
+
+func TestPodWorkload(t *testing.T) {
+	rs := testPod("ns", "api-7d8f9c4b6-abc12", map[string]string{}, true)
+	assert.Equal(t, "deployment/api", podWorkload(rs))
+
+	sts := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "db-0",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db"}},
+	}}
+	assert.Equal(t, "statefulset/db", podWorkload(sts))
+
+	bare := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug"}}
+	assert.Equal(t, "pod/debug", podWorkload(bare))
+}
+
+func TestComputeIngressErrorRates(t *testing.T) {
+	total := model.Vector{
+		{Metric: model.Metric{"exported_namespace": "shop", "namespace": "ingress-nginx", "ingress": "api", "exported_service": "api"}, Value: 10},
+		{Metric: model.Metric{"namespace": "shop", "ingress": "web", "service": "web"}, Value: 5},
+	}
+	errs := model.Vector{
+		{Metric: model.Metric{"exported_namespace": "shop", "namespace": "ingress-nginx", "ingress": "api", "exported_service": "api"}, Value: 1},
+	}
+
+	rates := computeIngressErrorRates(total, errs)
+	require.Len(t, rates, 2)
+	assert.Equal(t, ingressErrorRate{Namespace: "shop", Ingress: "api", Service: "api", TotalRPS: 10, Ratio: 0.1}, rates[0])
+	assert.Equal(t, "web", rates[1].Ingress)
+	assert.Equal(t, 0.0, rates[1].Ratio)
+}
+
+// mockPromAPI returns fixed vectors for ingress rate queries.
+type mockPromAPI struct {
+	v1.API
+	total model.Vector
+	errs  model.Vector
+}
+
+func (m *mockPromAPI) Query(_ context.Context, query string, _ time.Time, _ ...v1.Option) (model.Value, v1.Warnings, error) {
+	if query == ingress5xxQuery(true) {
+		return m.errs, nil, nil
+	}
+	return m.total, nil, nil
+}
+
+func TestCheckServiceHealth_Ingress5xx(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testService("shop", "api", map[string]string{"app": "api"}),
+		testPod("shop", "api-7d8f9c4b6-abc12", map[string]string{"app": "api"}, true),
+		testEndpointSlice("shop", "api", true),
+	)
+	w := NewWatcher(client, Config{})
+	w.SetPrometheusAPI(&mockPromAPI{
+		total: model.Vector{
+			{Metric: model.Metric{"namespace": "shop", "ingress": "api", "service": "api"}, Value: 20},
+			// Low-traffic route below ingressMinRPS is ignored
+			{Metric: model.Metric{"namespace": "shop", "ingress": "quiet", "service": "quiet"}, Value: 0.01},
+		},
+		errs: model.Vector{
+			{Metric: model.Metric{"namespace": "shop", "ingress": "api", "service": "api"}, Value: 2},
+			{Metric: model.Metric{"namespace": "shop", "ingress": "quiet", "service": "quiet"}, Value: 0.01},
+		},
+	})
+	w.checkServiceHealth(context.Background())
+
+	problems, _, _ := w.GetState()
+	require.Len(t, problems, 1)
+	p := problems[0]
+	assert.Equal(t, problemTypeIngress5xx, p.Type)
+	assert.Equal(t, SeverityCritical, p.Severity)
+	assert.Equal(t, "api", p.PodName)
+	assert.Equal(t, "10.0%", p.Details["5xx_ratio"])
+	assert.Equal(t, "deployment/api", p.Details["workload"])
+	assert.Contains(t, p.Message, "backing deployment/api")
+}
+
+func TestCheckServiceHealth_NamespaceFilter(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	w := NewWatcher(client, Config{Namespace: "shop"})
+	w.SetPrometheusAPI(&mockPromAPI{
+		total: model.Vector{{Metric: model.Metric{"namespace": "other", "ingress": "x", "service": "x"}, Value: 10}},
+		errs:  model.Vector{{Metric: model.Metric{"namespace": "other", "ingress": "x", "service": "x"}, Value: 10}},
+	})
+	w.checkServiceHealth(context.Background())
+
+	problems, _, _ := w.GetState()
+	assert.Empty(t, problems)
+}
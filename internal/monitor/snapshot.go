@@ -0,0 +1,176 @@
+package monitor
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+// SnapshotProblems runs the watcher's problem detection on a collected
+// snapshot instead of live informers, so a cluster (or a saved snapshot) can
+// be triaged without an LLM. Pods and nodes in the snapshot are already
+// filtered to problem ones; a problem pod that matches no rule is reported
+// as NotReady, so every pod counted by snapshot.Triage has a problem here.
+// Problems are sorted by severity, then namespace, pod, and container.
+func SnapshotProblems(s *snapshot.Snapshot) []Problem {
+	if s == nil {
+		return nil
+	}
+	var problems []Problem
+	for i := range s.ProblemPods {
+		problems = append(problems, snapshotPodProblems(&s.ProblemPods[i], s)...)
+	}
+	for i := range s.NodeConditions {
+		problems = append(problems, snapshotNodeProblems(&s.NodeConditions[i], s)...)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := &problems[i], &problems[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.PodName != b.PodName {
+			return a.PodName < b.PodName
+		}
+		return a.ContainerName < b.ContainerName
+	})
+	return problems
+}
+
+func severityRank(s Severity) int {
+	switch s {
+	case SeverityFatal:
+		return 2
+	case SeverityCritical:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// snapshotPodProblems mirrors podProblems for a pod snapshot. Snapshots keep
+// container reasons but not their messages, so sub-reasons come from the
+// pod's Warning events.
+func snapshotPodProblems(p *snapshot.PodSnapshot, s *snapshot.Snapshot) []Problem {
+	var problems []Problem
+	add := func(severity Severity, typ, container, message, subReason string, details map[string]string) {
+		problems = append(problems, Problem{
+			Severity:      severity,
+			Type:          typ,
+			Namespace:     p.Namespace,
+			PodName:       p.Name,
+			ContainerName: container,
+			Message:       message,
+			SubReason:     subReason,
+			LastSeen:      s.GeneratedAt,
+			Count:         1,
+			Details:       details,
+		})
+	}
+
+	for i := range p.Containers {
+		c := &p.Containers[i]
+		switch c.StateReason {
+		case "CrashLoopBackOff":
+			subReason, details := podEventSubReason(p, eventSubReason, "Unhealthy")
+			if subReason == "" && c.LastStateReason != "" {
+				subReason = fmt.Sprintf("last terminated: %s", c.LastStateReason)
+			}
+			if details == nil {
+				details = map[string]string{}
+			}
+			details["restarts"] = fmt.Sprintf("%d", c.RestartCount)
+			add(SeverityFatal, "CrashLoopBackOff", c.Name,
+				fmt.Sprintf("Container crashing repeatedly (restarts: %d)", c.RestartCount), subReason, details)
+		case "ImagePullBackOff", "ErrImagePull":
+			subReason, _ := podEventSubReason(p, func(_, message string) (string, map[string]string) {
+				return imagePullSubReason(message), nil
+			}, "Failed", "BackOff")
+			add(SeverityCritical, c.StateReason, c.Name, fmt.Sprintf("Cannot pull image %s", c.Image), subReason,
+				map[string]string{"image": c.Image})
+		case "CreateContainerConfigError", "CreateContainerError", "RunContainerError":
+			subReason, details := podEventSubReason(p, func(_, message string) (string, map[string]string) {
+				return configErrorSubReason(message)
+			}, "Failed")
+			add(SeverityCritical, c.StateReason, c.Name, "Cannot start container", subReason, details)
+		}
+		if c.StateReason == "OOMKilled" || c.LastStateReason == "OOMKilled" {
+			add(SeverityFatal, "OOMKilled", c.Name, "Container killed due to out of memory", "", nil)
+		}
+		if c.RestartCount > 5 {
+			add(SeverityWarning, "HighRestarts", c.Name,
+				fmt.Sprintf("Container has %d restarts (may indicate instability)", c.RestartCount),
+				"", map[string]string{"restart_count": fmt.Sprintf("%d", c.RestartCount)})
+		}
+	}
+
+	switch {
+	case p.Reason == "Evicted":
+		add(SeverityCritical, "Evicted", "", "Pod evicted", "", map[string]string{"eviction_reason": p.Reason})
+	case p.Phase == "Pending":
+		subReason, details := podEventSubReason(p, eventSubReason, "FailedScheduling", "FailedMount", "FailedAttachVolume")
+		add(SeverityCritical, "PodPending", "", "Pod stuck in Pending state", subReason, details)
+	case p.Phase == "Failed":
+		add(SeverityCritical, "PodFailed", "", fmt.Sprintf("Pod failed (reason: %s)", valueOr(p.Reason, "unknown")), "", nil)
+	}
+
+	if len(problems) == 0 {
+		add(SeverityWarning, "NotReady", "", fmt.Sprintf("Pod %s and not ready (restarts: %d)", p.Phase, p.Restarts), "", nil)
+	}
+	return problems
+}
+
+// snapshotNodeProblems reports the node conditions snapshot.NodeUnhealthy
+// flags: not Ready, or under memory, disk, or PID pressure.
+func snapshotNodeProblems(n *snapshot.NodeSnapshot, s *snapshot.Snapshot) []Problem {
+	var problems []Problem
+	for _, c := range n.Conditions {
+		typ := ""
+		switch {
+		case c.Type == "Ready" && c.Status != "True":
+			typ = "NodeNotReady"
+		case (c.Type == "MemoryPressure" || c.Type == "DiskPressure" || c.Type == "PIDPressure") && c.Status == "True":
+			typ = "Node" + c.Type
+		default:
+			continue
+		}
+		problems = append(problems, Problem{
+			Severity:  SeverityCritical,
+			Type:      typ,
+			PodName:   n.Name,
+			Message:   fmt.Sprintf("Node %s: %s=%s", n.Name, c.Type, c.Status),
+			SubReason: clip(firstLine(c.Message)),
+			LastSeen:  s.GeneratedAt,
+			Count:     1,
+			Details:   map[string]string{"node": n.Name, "reason": c.Reason},
+		})
+	}
+	return problems
+}
+
+// podEventSubReason returns the sub-reason of the pod's newest Warning
+// event with one of the reasons that parse recognizes.
+func podEventSubReason(
+	p *snapshot.PodSnapshot, parse func(reason, message string) (string, map[string]string), reasons ...string,
+) (string, map[string]string) {
+	var newest *snapshot.EventSnapshot
+	var subReason string
+	var details map[string]string
+	for i := range p.Events {
+		e := &p.Events[i]
+		if e.Type != "Warning" || !slices.Contains(reasons, e.Reason) {
+			continue
+		}
+		if newest != nil && e.LastTime.Before(newest.LastTime) {
+			continue
+		}
+		if sub, d := parse(e.Reason, e.Message); sub != "" {
+			newest, subReason, details = e, sub, d
+		}
+	}
+	return subReason, details
+}
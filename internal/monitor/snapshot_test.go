@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+func TestSnapshotProblems(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := &snapshot.Snapshot{
+		GeneratedAt: now,
+		ProblemPods: []snapshot.PodSnapshot{
+			{Namespace: "prod", Name: "api-1", Phase: "Running", Restarts: 7, Containers: []snapshot.ContainerSnapshot{
+				{Name: "app", State: "Waiting", StateReason: "CrashLoopBackOff", RestartCount: 7, LastStateReason: "OOMKilled"},
+			}},
+			{Namespace: "prod", Name: "web-1", Phase: "Pending", Containers: []snapshot.ContainerSnapshot{
+				{Name: "web", Image: "web:9.9", State: "Waiting", StateReason: "ImagePullBackOff"},
+			}, Events: []snapshot.EventSnapshot{
+				{Type: "Warning", Reason: "Failed", Message: "Failed to pull image \"web:9.9\": manifest unknown", LastTime: now},
+			}},
+			{Namespace: "batch", Name: "job-1", Phase: "Pending", Events: []snapshot.EventSnapshot{
+				{Type: "Warning", Reason: "FailedScheduling", LastTime: now.Add(-time.Minute),
+					Message: "0/3 nodes are available: 3 Insufficient memory."},
+			}},
+			{Namespace: "prod", Name: "worker-1", Phase: "Running", Containers: []snapshot.ContainerSnapshot{
+				{Name: "worker", State: "Running"},
+			}},
+		},
+		NodeConditions: []snapshot.NodeSnapshot{
+			{Name: "node-a", Conditions: []snapshot.NodeConditionSnapshot{
+				{Type: "Ready", Status: "True"},
+				{Type: "DiskPressure", Status: "True", Message: "ephemeral storage low"},
+			}},
+		},
+	}
+
+	problems := SnapshotProblems(s)
+	got := make([]string, len(problems))
+	for i, p := range problems {
+		got[i] = string(p.Severity) + " " + p.Type + " " + p.PodName + " " + p.SubReason
+	}
+	assert.Equal(t, []string{
+		"FATAL CrashLoopBackOff api-1 last terminated: OOMKilled",
+		"FATAL OOMKilled api-1 ",
+		"CRITICAL NodeDiskPressure node-a ephemeral storage low",
+		"CRITICAL PodPending job-1 0/3 nodes: 3 Insufficient memory",
+		"CRITICAL PodPending web-1 ",
+		"CRITICAL ImagePullBackOff web-1 image or tag not found",
+		"WARNING HighRestarts api-1 ",
+		"WARNING NotReady worker-1 ",
+	}, got)
+
+	require.NotEmpty(t, problems)
+	assert.Equal(t, "7", problems[0].Details["restarts"])
+	assert.Equal(t, now, problems[0].LastSeen)
+	assert.Nil(t, SnapshotProblems(nil))
+}
@@ -1,6 +1,10 @@
 package monitor
 
-import "time"
+import (
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/ack"
+)
 
 // Severity levels for problems
 type Severity string
@@ -21,12 +25,27 @@ type Problem struct {
 	ContainerName string
 	Message       string
 	Reason        string
+	SubReason     string // e.g. "liveness probe: HTTP 503", "volume config mount failed: ..."
 	FirstSeen     time.Time
 	LastSeen      time.Time
 	Count         int
 	Details       map[string]string
 }
 
+// SplitAcknowledged separates problems covered by an unexpired
+// acknowledgement from active ones, keeping the order of each.
+func SplitAcknowledged(problems []Problem, acks *ack.List, now time.Time) (active []Problem, accepted []ack.Problem) {
+	for i := range problems {
+		p := &problems[i]
+		if a := acks.Match(p.Namespace, p.PodName, p.ContainerName, p.Type, now); a != nil {
+			accepted = append(accepted, a.Problem(p.Namespace, p.PodName, p.ContainerName, p.Type))
+			continue
+		}
+		active = append(active, *p)
+	}
+	return active, accepted
+}
+
 // RecentEvent represents a recent event in the cluster
 type RecentEvent struct {
 	Timestamp time.Time
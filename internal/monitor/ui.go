@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -9,6 +10,8 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ppiankov/kubenow/internal/keymap"
 )
 
 // Styles
@@ -40,6 +43,10 @@ var (
 			BorderForeground(lipgloss.Color("240")).
 			Padding(1, 2)
 
+	selectedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39")). // Blue
+			Bold(true)
+
 	disconnectedStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("196")). // Bright red
 				Bold(true)
@@ -65,6 +72,24 @@ type Model struct {
 	searchMode      bool   // True when in search input mode
 	searchQuery     string // Current search filter
 	filteredCount   int    // Number of filtered out problems
+	keys            *keymap.Map
+	showHelp        bool // True while the key binding overlay is shown
+	cursor          int  // Selected problem, index into sortedProblems()
+
+	// Drill-down of the selected problem, fetched when opened
+	detail         *Problem
+	details        *ProblemDetails
+	detailsLoading bool
+	detailOffset   int
+
+	// LLM explanation of a problem, shown over the list or the drill-down
+	explainer     ExplainFunc
+	explainModel  string
+	explain       *Problem
+	explanation   string
+	explainErr    error
+	explaining    bool
+	explainOffset int
 }
 
 // tickMsg is sent on timer tick for heartbeat
@@ -73,6 +98,19 @@ type tickMsg time.Time
 // updateMsg is sent when watcher has new data
 type updateMsg struct{}
 
+// detailsMsg carries the fetched drill-down of a problem.
+type detailsMsg struct {
+	problem Problem
+	details *ProblemDetails
+}
+
+// explainMsg carries the LLM explanation of a problem.
+type explainMsg struct {
+	problem Problem
+	text    string
+	err     error
+}
+
 // NewModel creates a new bubbletea model
 func NewModel(watcher *Watcher) Model {
 	s := spinner.New()
@@ -83,9 +121,22 @@ func NewModel(watcher *Watcher) Model {
 		spinner:    s,
 		lastUpdate: time.Now(),
 		sortMode:   0, // Default: sort by severity
+		keys:       keymap.MustDefault(DefaultKeyBindings),
 	}
 }
 
+// SetKeyMap replaces the default key bindings.
+func (m *Model) SetKeyMap(keys *keymap.Map) {
+	m.keys = keys
+}
+
+// SetExplainer enables the explain action, which sends the selected
+// problem's drill-down to model through explain.
+func (m *Model) SetExplainer(model string, explain ExplainFunc) {
+	m.explainModel = model
+	m.explainer = explain
+}
+
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
 	return tea.Batch(
@@ -129,70 +180,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Normal mode key handling
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if msg.String() == keymap.ForceQuit {
 			m.quitting = true
 			return m, tea.Quit
-		case "esc":
-			// Clear search filter
-			m.searchQuery = ""
-			m.filterProblems()
-			return m, nil
-		case "p", " ": // p or space to pause/resume
-			m.paused = !m.paused
-			return m, nil
-		case "/": // Enter search mode
-			m.searchMode = true
-			m.searchQuery = ""
-			return m, nil
-		case "1": // Sort by severity
-			m.sortMode = 0
-			return m, nil
-		case "2": // Sort by recency
-			m.sortMode = 1
-			return m, nil
-		case "3": // Sort by count
-			m.sortMode = 2
-			return m, nil
-		case "up", "k": // Scroll up
-			if m.scrollOffset > 0 {
-				m.scrollOffset--
-			}
-			return m, nil
-		case "down", "j": // Scroll down
-			// Calculate how many problems we can show
-			problemsPerScreen := m.calculateProblemsPerScreen()
-			maxScroll := maxInt(0, len(m.problems)-problemsPerScreen)
-			if m.scrollOffset < maxScroll {
-				m.scrollOffset++
+		}
+		if m.showHelp {
+			// Any key closes the help overlay; quit still quits.
+			m.showHelp = false
+			if m.keys.Action(msg.String()) != actionQuit {
+				return m, nil
 			}
-			return m, nil
-		case "home", "g": // Go to top
-			m.scrollOffset = 0
-			return m, nil
-		case "end", "G": // Go to bottom
-			problemsPerScreen := m.calculateProblemsPerScreen()
-			m.scrollOffset = maxInt(0, len(m.problems)-problemsPerScreen)
-			return m, nil
-		case "pageup": // Page up
-			problemsPerScreen := m.calculateProblemsPerScreen()
-			m.scrollOffset = maxInt(0, m.scrollOffset-problemsPerScreen)
-			return m, nil
-		case "pagedown": // Page down
-			problemsPerScreen := m.calculateProblemsPerScreen()
-			maxScroll := maxInt(0, len(m.problems)-problemsPerScreen)
-			m.scrollOffset = minInt(maxScroll, m.scrollOffset+problemsPerScreen)
-			return m, nil
-		case "e": // Export to file
-			m.exportRequested = true
-			m.quitting = true
-			return m, tea.Quit
-		case "c", "v": // Print to terminal (copyable)
-			m.printRequested = true
-			m.quitting = true
-			return m, tea.Quit
 		}
+		return m.handleAction(m.keys.Action(msg.String()))
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -206,11 +205,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update data from watcher (only if not paused)
 		if !m.paused {
 			m.allProblems, m.events, m.stats = m.watcher.GetState()
-			m.filterProblems() // Apply current search filter
+			// Apply current search filter, keeping the selection in place
+			cursor, offset := m.cursor, m.scrollOffset
+			m.filterProblems()
+			m.cursor, m.scrollOffset = cursor, offset
+			m.moveCursor(0)
 			m.lastUpdate = time.Now()
 		}
 		return m, waitForUpdate(m.watcher.GetUpdateChannel())
 
+	case detailsMsg:
+		if m.detail != nil && sameProblem(m.detail, &msg.problem) {
+			m.details = msg.details
+			m.detailsLoading = false
+		}
+		return m, nil
+
+	case explainMsg:
+		if m.explain != nil && sameProblem(m.explain, &msg.problem) {
+			m.explanation, m.explainErr = msg.text, msg.err
+			m.explaining = false
+		}
+		return m, nil
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -220,12 +237,195 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleAction runs a key binding's action in normal mode.
+//
+//nolint:gocyclo // BubbleTea key dispatch
+func (m *Model) handleAction(action keymap.Action) (tea.Model, tea.Cmd) {
+	if m.explain != nil && m.handleExplainAction(action) {
+		return m, nil
+	}
+	if m.detail != nil && m.handleDetailAction(action) {
+		return m, nil
+	}
+	switch action {
+	case actionQuit:
+		m.quitting = true
+		return m, tea.Quit
+	case keymap.Help:
+		m.showHelp = true
+	case actionClearFilter:
+		m.searchQuery = ""
+		m.filterProblems()
+	case actionPause:
+		m.paused = !m.paused
+	case actionSearch:
+		m.searchMode = true
+		m.searchQuery = ""
+	case actionSortSeverity:
+		m.sortMode = 0
+	case actionSortRecency:
+		m.sortMode = 1
+	case actionSortCount:
+		m.sortMode = 2
+	case actionScrollUp:
+		m.moveCursor(-1)
+	case actionScrollDown:
+		m.moveCursor(1)
+	case actionTop:
+		m.moveCursor(-len(m.problems))
+	case actionBottom:
+		m.moveCursor(len(m.problems))
+	case actionPageUp:
+		m.moveCursor(-m.calculateProblemsPerScreen())
+	case actionPageDown:
+		m.moveCursor(m.calculateProblemsPerScreen())
+	case actionDetails:
+		return m, m.openDetails()
+	case actionExplain:
+		return m, m.openExplain()
+	case actionExport:
+		m.exportRequested = true
+		m.quitting = true
+		return m, tea.Quit
+	case actionCopy:
+		m.printRequested = true
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// handleDetailAction handles the keys that act on the open drill-down:
+// closing it and scrolling it. It returns false for other actions.
+func (m *Model) handleDetailAction(action keymap.Action) bool {
+	page := m.detailHeight()
+	switch action {
+	case actionDetails, actionClearFilter:
+		m.detail, m.details, m.detailsLoading = nil, nil, false
+	case actionScrollUp:
+		m.detailOffset--
+	case actionScrollDown:
+		m.detailOffset++
+	case actionPageUp:
+		m.detailOffset -= page
+	case actionPageDown:
+		m.detailOffset += page
+	case actionTop:
+		m.detailOffset = 0
+	case actionBottom:
+		m.detailOffset = len(m.detailLines())
+	default:
+		return false
+	}
+	m.detailOffset = maxInt(0, minInt(m.detailOffset, len(m.detailLines())-page))
+	return true
+}
+
+// handleExplainAction handles the keys that act on the explain overlay:
+// closing it and scrolling it. It returns false for other actions.
+func (m *Model) handleExplainAction(action keymap.Action) bool {
+	page := m.detailHeight()
+	switch action {
+	case actionExplain, actionClearFilter:
+		m.explain, m.explanation, m.explainErr, m.explaining = nil, "", nil, false
+	case actionScrollUp:
+		m.explainOffset--
+	case actionScrollDown:
+		m.explainOffset++
+	case actionPageUp:
+		m.explainOffset -= page
+	case actionPageDown:
+		m.explainOffset += page
+	case actionTop:
+		m.explainOffset = 0
+	case actionBottom:
+		m.explainOffset = len(m.explainLines())
+	default:
+		return false
+	}
+	m.explainOffset = maxInt(0, minInt(m.explainOffset, len(m.explainLines())-page))
+	return true
+}
+
+// moveCursor moves the problem selection by delta and scrolls it into view.
+func (m *Model) moveCursor(delta int) {
+	m.cursor = maxInt(0, minInt(m.cursor+delta, len(m.problems)-1))
+	problemsPerScreen := m.calculateProblemsPerScreen()
+	if m.cursor < m.scrollOffset {
+		m.scrollOffset = m.cursor
+	}
+	if m.cursor >= m.scrollOffset+problemsPerScreen {
+		m.scrollOffset = m.cursor - problemsPerScreen + 1
+	}
+}
+
+// openDetails opens the drill-down of the selected problem and fetches it.
+func (m *Model) openDetails() tea.Cmd {
+	sorted := m.sortedProblems()
+	if len(sorted) == 0 {
+		return nil
+	}
+	p := sorted[minInt(m.cursor, len(sorted)-1)]
+	m.detail = &p
+	m.details = nil
+	m.detailsLoading = true
+	m.detailOffset = 0
+	watcher := m.watcher
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), detailTimeout)
+		defer cancel()
+		return detailsMsg{problem: p, details: watcher.FetchDetails(ctx, &p)}
+	}
+}
+
+// openExplain opens the explain overlay for the open drill-down's problem,
+// or else the selected one, and asks the LLM about it. A drill-down that is
+// already loaded is reused; otherwise it is fetched first.
+func (m *Model) openExplain() tea.Cmd {
+	var p Problem
+	switch sorted := m.sortedProblems(); {
+	case m.detail != nil:
+		p = *m.detail
+	case len(sorted) > 0:
+		p = sorted[minInt(m.cursor, len(sorted)-1)]
+	default:
+		return nil
+	}
+	m.explain = &p
+	m.explanation, m.explainErr = "", nil
+	m.explainOffset = 0
+	if m.explainer == nil {
+		return nil
+	}
+	m.explaining = true
+
+	details := m.details
+	if m.detail == nil || !sameProblem(m.detail, &p) {
+		details = nil
+	}
+	watcher, explain := m.watcher, m.explainer
+	return func() tea.Msg {
+		if details == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), detailTimeout)
+			details = watcher.FetchDetails(ctx, &p)
+			cancel()
+		}
+		text, err := explain(context.Background(), &p, details)
+		return explainMsg{problem: p, text: text, err: err}
+	}
+}
+
+func sameProblem(a, b *Problem) bool {
+	return a.Namespace == b.Namespace && a.PodName == b.PodName && a.ContainerName == b.ContainerName && a.Type == b.Type
+}
+
 // filterProblems applies the search query to filter problems
 func (m *Model) filterProblems() {
 	if m.searchQuery == "" {
 		m.problems = m.allProblems
 		m.filteredCount = 0
 		m.scrollOffset = 0 // Reset scroll when clearing filter
+		m.cursor = 0
 		return
 	}
 
@@ -242,7 +442,8 @@ func (m *Model) filterProblems() {
 			strings.Contains(strings.ToLower(problem.Type), query) ||
 			strings.Contains(strings.ToLower(string(problem.Severity)), query) ||
 			strings.Contains(strings.ToLower(problem.Message), query) ||
-			strings.Contains(strings.ToLower(problem.Reason), query) {
+			strings.Contains(strings.ToLower(problem.Reason), query) ||
+			strings.Contains(strings.ToLower(problem.SubReason), query) {
 			filtered = append(filtered, *problem)
 		}
 	}
@@ -250,6 +451,7 @@ func (m *Model) filterProblems() {
 	m.problems = filtered
 	m.filteredCount = len(m.allProblems) - len(filtered)
 	m.scrollOffset = 0 // Reset scroll when applying new filter
+	m.cursor = 0
 }
 
 // View renders the UI
@@ -272,11 +474,27 @@ func (m *Model) View() string {
 		status = "Live"
 	}
 
-	headerLine := fmt.Sprintf("kubenow monitor [%s] | Sort: %s (1/2/3) | /=Search C=Copy Space=Pause ↑↓=Scroll Q=Quit",
-		status, sortName)
+	headerLine := fmt.Sprintf("kubenow monitor [%s] | Sort: %s (%s/%s/%s) | %s=Search %s=Details %s=Explain %s=Copy %s=Pause %s/%s=Scroll %s=Quit %s=Help",
+		status, sortName,
+		m.keys.Label(actionSortSeverity), m.keys.Label(actionSortRecency), m.keys.Label(actionSortCount),
+		m.keys.Label(actionSearch), m.keys.Label(actionDetails), m.keys.Label(actionExplain), m.keys.Label(actionCopy), m.keys.Label(actionPause),
+		m.keys.Label(actionScrollUp), m.keys.Label(actionScrollDown), m.keys.Label(actionQuit), m.keys.Label(keymap.Help))
 	b.WriteString(titleStyle.Render(headerLine))
 	b.WriteString("\n")
 
+	if m.showHelp {
+		b.WriteString(m.renderHelp())
+		return borderStyle.Render(b.String())
+	}
+	if m.explain != nil {
+		b.WriteString(m.renderExplain())
+		return borderStyle.Render(b.String())
+	}
+	if m.detail != nil {
+		b.WriteString(m.renderDetails())
+		return borderStyle.Render(b.String())
+	}
+
 	// Search bar (if active)
 	if m.searchMode {
 		searchStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
@@ -318,6 +536,21 @@ func (m *Model) View() string {
 	return borderStyle.Render(b.String())
 }
 
+// renderHelp renders the key binding overlay from the active bindings.
+func (m *Model) renderHelp() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Key bindings"))
+	b.WriteString("\n")
+	for _, line := range m.keys.HelpLines() {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render("Remap in the keybindings.monitor section of ~/.kubenow/config.yaml. Press any key to close."))
+	b.WriteString("\n")
+	return b.String()
+}
+
 // renderHealthyState renders the healthy state
 func (m *Model) renderHealthyState() string {
 	var b strings.Builder
@@ -360,24 +593,7 @@ func (m *Model) renderDisconnected() string {
 func (m *Model) renderProblems() string {
 	var b strings.Builder
 
-	// Sort problems based on sort mode
-	sorted := make([]Problem, len(m.problems))
-	copy(sorted, m.problems)
-
-	switch m.sortMode {
-	case 0: // Severity
-		sort.Slice(sorted, func(i, j int) bool {
-			return severityWeight(sorted[i].Severity) > severityWeight(sorted[j].Severity)
-		})
-	case 1: // Recency
-		sort.Slice(sorted, func(i, j int) bool {
-			return sorted[i].LastSeen.After(sorted[j].LastSeen)
-		})
-	case 2: // Count
-		sort.Slice(sorted, func(i, j int) bool {
-			return sorted[i].Count > sorted[j].Count
-		})
-	}
+	sorted := m.sortedProblems()
 
 	// Calculate visible window
 	problemsPerScreen := m.calculateProblemsPerScreen()
@@ -393,7 +609,7 @@ func (m *Model) renderProblems() string {
 
 	// Show problems
 	for i := startIdx; i < endIdx; i++ {
-		b.WriteString(m.renderProblemCompact(&sorted[i]))
+		b.WriteString(m.renderProblemCompact(&sorted[i], i == m.cursor))
 	}
 
 	// Scroll hints
@@ -410,6 +626,35 @@ func (m *Model) renderProblems() string {
 	return b.String()
 }
 
+// sortedProblems returns the shown problems in the current sort mode.
+func (m *Model) sortedProblems() []Problem {
+	sorted := make([]Problem, len(m.problems))
+	copy(sorted, m.problems)
+
+	// Ties keep a stable order across watcher updates, so the selection stays put
+	var less func(a, b *Problem) bool
+	switch m.sortMode {
+	case 0: // Severity
+		less = func(a, b *Problem) bool { return severityWeight(a.Severity) > severityWeight(b.Severity) }
+	case 1: // Recency
+		less = func(a, b *Problem) bool { return a.LastSeen.After(b.LastSeen) }
+	case 2: // Count
+		less = func(a, b *Problem) bool { return a.Count > b.Count }
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := &sorted[i], &sorted[j]
+		if less(a, b) || less(b, a) {
+			return less(a, b)
+		}
+		return problemOrderKey(a) < problemOrderKey(b)
+	})
+	return sorted
+}
+
+func problemOrderKey(p *Problem) string {
+	return p.Namespace + "/" + p.PodName + "/" + p.ContainerName + "/" + p.Type
+}
+
 // calculateProblemsPerScreen estimates how many problems fit on screen
 func (m *Model) calculateProblemsPerScreen() int {
 	if m.height < 20 {
@@ -421,7 +666,7 @@ func (m *Model) calculateProblemsPerScreen() int {
 }
 
 // renderProblemCompact renders a problem in compact format
-func (m *Model) renderProblemCompact(p *Problem) string {
+func (m *Model) renderProblemCompact(p *Problem, selected bool) string {
 	var b strings.Builder
 
 	// Severity indicator (text for consistent width)
@@ -460,9 +705,145 @@ func (m *Model) renderProblemCompact(p *Problem) string {
 	// Apply styling only to the type part by replacing it
 	styledLine := strings.Replace(fullLine, typePart, style.Render(typePart), 1)
 
+	if selected {
+		b.WriteString(selectedStyle.Render("> "))
+	} else {
+		b.WriteString("  ")
+	}
 	b.WriteString(styledLine)
+	if p.SubReason != "" {
+		sub := "  · " + p.SubReason
+		if m.width > 0 {
+			sub = truncate(sub, maxInt(10, m.width-len([]rune(fullLine))))
+		}
+		b.WriteString(dimStyle.Render(sub))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// detailHeight is the number of drill-down lines that fit on screen.
+func (m *Model) detailHeight() int {
+	if m.height <= 0 {
+		return 30
+	}
+	return maxInt(5, m.height-8) // Header, problem summary, border
+}
+
+// detailLines renders the drill-down sections of the open problem.
+func (m *Model) detailLines() []string {
+	d := m.details
+	if d == nil {
+		return []string{dimStyle.Render("Fetching pod, events, and logs...")}
+	}
+	// Cut lines before styling them, so escape sequences stay whole
+	fit := func(line string) string {
+		if m.width > 8 {
+			return truncate(line, m.width-6)
+		}
+		return line
+	}
+	var lines []string
+	for _, e := range d.Errors {
+		lines = append(lines, warningStyle.Render(fit("Could not fetch "+e)))
+	}
+	section := func(title string, body []string, empty string) {
+		lines = append(lines, "", selectedStyle.Render(fit(title)))
+		if len(body) == 0 {
+			lines = append(lines, dimStyle.Render("  "+empty))
+		}
+		for _, line := range body {
+			lines = append(lines, fit("  "+line))
+		}
+	}
+	if d.Describe != nil {
+		section("Pod", d.Describe, "")
+	} else {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("No pod named %s: events only", m.detail.PodName)))
+	}
+	section("Events", d.Events, "no events (they expire after an hour by default)")
+	if d.LogContainer != "" {
+		title := fmt.Sprintf("Logs: %s, last %d lines", d.LogContainer, detailLogLines)
+		if d.PreviousLogs {
+			title += " of the previous (terminated) instance"
+		}
+		section(title, d.Logs, "no log output")
+	}
+	return lines
+}
+
+// renderDetails renders the drill-down of the open problem.
+func (m *Model) renderDetails() string {
+	var b strings.Builder
+	p := m.detail
+	b.WriteString(m.renderProblemCompact(p, false))
+	b.WriteString(dimStyle.Render(truncate(p.Message, maxInt(40, m.width-8))))
 	b.WriteString("\n")
 
+	lines := m.detailLines()
+	height := m.detailHeight()
+	start := minInt(m.detailOffset, maxInt(0, len(lines)-1))
+	end := minInt(len(lines), start+height)
+	for _, line := range lines[start:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	hint := fmt.Sprintf("%s/%s=Scroll %s=Explain %s=Close", m.keys.Label(actionScrollUp), m.keys.Label(actionScrollDown),
+		m.keys.Label(actionExplain), m.keys.Label(actionDetails))
+	if start > 0 || end < len(lines) {
+		hint = fmt.Sprintf("lines %d-%d of %d | ", start+1, end, len(lines)) + hint
+	}
+	b.WriteString(dimStyle.Render(hint))
+	return b.String()
+}
+
+// explainLines renders the explain overlay's body.
+func (m *Model) explainLines() []string {
+	width := 100
+	if m.width > 8 {
+		width = m.width - 6
+	}
+	switch {
+	case m.explainer == nil:
+		return wrapText("LLM explain is off. Start the monitor with --model (and --llm-endpoint for OpenAI-compatible servers, --llm-provider anthropic or gemini for their APIs) to ask the LLM about a problem.", width)
+	case m.explaining:
+		return []string{dimStyle.Render(truncate(fmt.Sprintf("Asking %s about the pod, events, and logs...", m.explainModel), width))}
+	case m.explainErr != nil:
+		var lines []string
+		for _, line := range wrapText("LLM request failed: "+m.explainErr.Error(), width) {
+			lines = append(lines, warningStyle.Render(line))
+		}
+		return lines
+	default:
+		return wrapText(m.explanation, width)
+	}
+}
+
+// renderExplain renders the LLM explanation of a problem.
+func (m *Model) renderExplain() string {
+	var b strings.Builder
+	b.WriteString(m.renderProblemCompact(m.explain, false))
+	title := "LLM explanation"
+	if m.explainModel != "" {
+		title += " (" + m.explainModel + ")"
+	}
+	b.WriteString(selectedStyle.Render(title))
+	b.WriteString("\n\n")
+
+	lines := m.explainLines()
+	height := m.detailHeight()
+	start := minInt(m.explainOffset, maxInt(0, len(lines)-1))
+	end := minInt(len(lines), start+height)
+	for _, line := range lines[start:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	hint := fmt.Sprintf("%s/%s=Scroll %s=Close", m.keys.Label(actionScrollUp), m.keys.Label(actionScrollDown), m.keys.Label(actionExplain))
+	if start > 0 || end < len(lines) {
+		hint = fmt.Sprintf("lines %d-%d of %d | ", start+1, end, len(lines)) + hint
+	}
+	b.WriteString(dimStyle.Render(hint))
 	return b.String()
 }
 
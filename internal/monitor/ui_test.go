@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -102,3 +103,37 @@ func TestHeaderStatus_Disconnected(t *testing.T) {
 	view := m.View()
 	assert.Contains(t, view, "DISCONNECTED")
 }
+
+func TestKeyMap_RemappedSearchAndHelp(t *testing.T) {
+	w := &Watcher{
+		problems:   make(map[string]*Problem),
+		events:     make([]RecentEvent, 0),
+		updateChan: make(chan struct{}, 100),
+	}
+	keys, err := NewKeyMap(map[string][]string{"search": {"f"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	m := NewModel(w)
+	m.SetKeyMap(keys)
+	m.stats.Connection = ConnectionOK
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	assert.False(t, m.searchMode, "/ is no longer bound")
+	assert.Contains(t, m.View(), "f=Search")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	assert.True(t, m.showHelp)
+	view := m.View()
+	assert.Contains(t, view, "Key bindings")
+	assert.Contains(t, view, "search problems")
+	assert.NotContains(t, view, "No active problems")
+
+	// Any key closes the overlay without acting.
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	assert.False(t, m.showHelp)
+	assert.False(t, m.searchMode)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	assert.True(t, m.searchMode)
+}
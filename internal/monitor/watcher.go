@@ -7,12 +7,25 @@ import (
 	"sync"
 	"time"
 
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/ppiankov/kubenow/internal/util"
 )
 
+// informerResync is how often the informers replay their cache. A replayed
+// pod refreshes its problems' last-seen time without counting as a change.
+const informerResync = 5 * time.Minute
+
+// statsInterval is how often cluster stats are recomputed from the
+// informer caches and expired problems are dropped.
+const statsInterval = 5 * time.Second
+
 // Watcher watches Kubernetes events and pod status
 type Watcher struct {
 	clientset  kubernetes.Interface
@@ -24,6 +37,11 @@ type Watcher struct {
 	updateChan chan struct{}
 	connStatus ConnectionStatus
 	lastErr    string
+	promAPI    v1.API // optional; enables ingress 5xx checks
+
+	// Informer caches, set by Start
+	pods  corelisters.PodLister
+	nodes corelisters.NodeLister
 }
 
 // NewWatcher creates a new cluster watcher
@@ -39,6 +57,8 @@ func NewWatcher(clientset kubernetes.Interface, config Config) *Watcher {
 
 // Start begins watching cluster events and pods.
 // Performs an initial connectivity probe before starting background watchers.
+// Pods, events, and nodes are watched through shared informers; their
+// handlers fire updates only when state actually changes.
 func (w *Watcher) Start(ctx context.Context) error {
 	// Probe connectivity: a lightweight server version check
 	_, err := w.clientset.Discovery().ServerVersion()
@@ -54,17 +74,25 @@ func (w *Watcher) Start(ctx context.Context) error {
 		w.mu.Unlock()
 	}
 
-	// Start event watcher
-	go w.watchEvents(ctx)
-
-	// Start pod watcher
-	go w.watchPods(ctx)
+	factory := informers.NewSharedInformerFactoryWithOptions(w.clientset, informerResync,
+		informers.WithNamespace(w.config.Namespace), informers.WithTransform(util.StripManagedFields))
+	if err := w.registerInformers(factory); err != nil {
+		return err
+	}
+	factory.Start(ctx.Done())
+	go func() {
+		<-ctx.Done()
+		factory.Shutdown()
+	}()
 
 	// Start service mesh health monitor (unless disabled)
 	if !w.config.DisableMesh {
 		go w.watchServiceMesh(ctx)
 	}
 
+	// Start service endpoint / ingress health monitor
+	go w.watchServiceHealth(ctx)
+
 	// Start stats updater
 	go w.updateStats(ctx)
 
@@ -96,68 +124,71 @@ func (w *Watcher) GetState() ([]Problem, []RecentEvent, ClusterStats) {
 	return problems, events, stats
 }
 
-// watchEvents watches Kubernetes events for problems
-func (w *Watcher) watchEvents(ctx context.Context) {
-	w.watchLoop(
-		ctx,
-		func() (watch.Interface, error) {
-			return w.clientset.CoreV1().Events(w.config.Namespace).Watch(ctx, metav1.ListOptions{Watch: true})
-		},
-		func(event watch.Event) {
-			if k8sEvent, ok := event.Object.(*corev1.Event); ok {
-				w.processEvent(k8sEvent)
-			}
-		},
-	)
-}
-
-// watchPods watches pod status changes
-func (w *Watcher) watchPods(ctx context.Context) {
-	w.watchLoop(
-		ctx,
-		func() (watch.Interface, error) {
-			return w.clientset.CoreV1().Pods(w.config.Namespace).Watch(ctx, metav1.ListOptions{Watch: true})
-		},
-		func(event watch.Event) {
-			if pod, ok := event.Object.(*corev1.Pod); ok {
-				w.processPodStatus(pod)
-			}
-		},
-	)
-}
-
-func (w *Watcher) watchLoop(
-	ctx context.Context,
-	newWatcher func() (watch.Interface, error),
-	handle func(watch.Event),
-) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-
-		watcher, err := newWatcher()
-		if err != nil {
-			w.setConnectionError(err)
-			time.Sleep(5 * time.Second)
-			continue
+// registerInformers wires the pod, event, and node informers to the
+// problem detectors. Failed lists and watches mark the connection down;
+// the informers retry on their own.
+func (w *Watcher) registerInformers(factory informers.SharedInformerFactory) error {
+	podInformer := factory.Core().V1().Pods()
+	eventInformer := factory.Core().V1().Events()
+	nodeInformer := factory.Core().V1().Nodes()
+	w.pods = podInformer.Lister()
+	w.nodes = nodeInformer.Lister()
+
+	onWatchError := func(_ *cache.Reflector, err error) { w.setConnectionError(err) }
+	handlers := []struct {
+		informer cache.SharedIndexInformer
+		handler  cache.ResourceEventHandler
+	}{
+		{podInformer.Informer(), cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj any) {
+				if pod, ok := obj.(*corev1.Pod); ok {
+					w.setConnectionOK()
+					w.processPodStatus(pod)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj any) {
+				oldPod, ok1 := oldObj.(*corev1.Pod)
+				pod, ok2 := newObj.(*corev1.Pod)
+				switch {
+				case !ok1 || !ok2:
+				case oldPod.ResourceVersion == pod.ResourceVersion:
+					w.refreshPodProblems(pod) // resync
+				default:
+					w.setConnectionOK()
+					w.processPodStatus(pod)
+				}
+			},
+		}},
+		{eventInformer.Informer(), cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj any) {
+				if event, ok := obj.(*corev1.Event); ok {
+					w.setConnectionOK()
+					w.processEvent(event)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj any) {
+				oldEvent, ok1 := oldObj.(*corev1.Event)
+				event, ok2 := newObj.(*corev1.Event)
+				if ok1 && ok2 && oldEvent.ResourceVersion != event.ResourceVersion {
+					w.setConnectionOK()
+					w.processEvent(event)
+				}
+			},
+		}},
+	}
+	for _, h := range handlers {
+		if err := h.informer.SetWatchErrorHandler(onWatchError); err != nil {
+			return fmt.Errorf("failed to set watch error handler: %w", err)
 		}
-		w.setConnectionOK()
-
-		for event := range watcher.ResultChan() {
-			if event.Type == watch.Error {
-				break
-			}
-			if event.Type == watch.Added || event.Type == watch.Modified {
-				handle(event)
-			}
+		if _, err := h.informer.AddEventHandler(h.handler); err != nil {
+			return fmt.Errorf("failed to add informer handler: %w", err)
 		}
-
-		watcher.Stop()
-		time.Sleep(1 * time.Second)
 	}
+	// Nodes feed the stats only, which are read from the cache
+	if err := nodeInformer.Informer().SetWatchErrorHandler(onWatchError); err != nil {
+		return fmt.Errorf("failed to set watch error handler: %w", err)
+	}
+	return nil
 }
 
 // processEvent processes a Kubernetes event
@@ -183,12 +214,22 @@ func (w *Watcher) processEvent(event *corev1.Event) {
 	}
 
 	// Create or update problem
+	subReason, details := eventSubReason(event.Reason, event.Message)
 	problemKey := fmt.Sprintf("%s/%s/%s", event.InvolvedObject.Namespace, event.InvolvedObject.Name, event.Reason)
 	if problem, exists := w.problems[problemKey]; exists {
 		problem.Count++
 		problem.LastSeen = event.LastTimestamp.Time
 		problem.Message = event.Message
+		if subReason != "" {
+			problem.SubReason = subReason
+		}
+		for k, v := range details {
+			problem.Details[k] = v
+		}
 	} else {
+		if details == nil {
+			details = make(map[string]string)
+		}
 		w.problems[problemKey] = &Problem{
 			Severity:  severity,
 			Type:      event.Reason,
@@ -196,19 +237,48 @@ func (w *Watcher) processEvent(event *corev1.Event) {
 			PodName:   event.InvolvedObject.Name,
 			Message:   event.Message,
 			Reason:    event.Reason,
+			SubReason: subReason,
 			FirstSeen: event.FirstTimestamp.Time,
 			LastSeen:  event.LastTimestamp.Time,
 			Count:     int(event.Count),
-			Details:   make(map[string]string),
+			Details:   details,
 		}
 	}
 	w.mu.Unlock()
 
-	w.updateChan <- struct{}{}
+	w.notify()
 }
 
 // processPodStatus processes pod status for problems
 func (w *Watcher) processPodStatus(pod *corev1.Pod) {
+	problems := w.podProblems(pod)
+	for i := range problems {
+		w.upsertProblem(&problems[i])
+	}
+	if len(problems) > 0 {
+		w.notify()
+	}
+}
+
+// refreshPodProblems marks the problems a pod still has as seen, without
+// counting a new occurrence, so a pod stuck in one state does not expire.
+func (w *Watcher) refreshPodProblems(pod *corev1.Pod) {
+	problems := w.podProblems(pod)
+	if len(problems) == 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	for i := range problems {
+		if problem, exists := w.problems[problemKey(&problems[i])]; exists {
+			problem.LastSeen = now
+		}
+	}
+}
+
+// podProblems returns the problems a pod's status shows.
+func (w *Watcher) podProblems(pod *corev1.Pod) []Problem {
 	problems := make([]Problem, 0)
 
 	for i := range pod.Status.ContainerStatuses {
@@ -216,6 +286,7 @@ func (w *Watcher) processPodStatus(pod *corev1.Pod) {
 		problems = append(problems, w.checkCrashLoop(pod, containerStatus)...)
 		problems = append(problems, w.checkOOMKill(pod, containerStatus)...)
 		problems = append(problems, w.checkImagePull(pod, containerStatus)...)
+		problems = append(problems, w.checkConfigError(pod, containerStatus)...)
 		problems = append(problems, w.checkHighRestarts(pod, containerStatus)...)
 	}
 
@@ -234,20 +305,7 @@ func (w *Watcher) processPodStatus(pod *corev1.Pod) {
 		})
 	}
 
-	for i := range problems {
-		problem := &problems[i]
-		w.addProblem(
-			problem.Severity,
-			problem.Type,
-			problem.Namespace,
-			problem.PodName,
-			problem.ContainerName,
-			problem.Message,
-			problem.Details,
-		)
-	}
-
-	w.updateChan <- struct{}{}
+	return problems
 }
 
 func (w *Watcher) checkCrashLoop(pod *corev1.Pod, cs *corev1.ContainerStatus) []Problem {
@@ -255,6 +313,12 @@ func (w *Watcher) checkCrashLoop(pod *corev1.Pod, cs *corev1.ContainerStatus) []
 		return nil
 	}
 
+	subReason, details := terminationSubReason(cs)
+	if details == nil {
+		details = map[string]string{}
+	}
+	details["restarts"] = fmt.Sprintf("%d", cs.RestartCount)
+
 	return []Problem{{
 		Severity:      SeverityFatal,
 		Type:          "CrashLoopBackOff",
@@ -262,9 +326,8 @@ func (w *Watcher) checkCrashLoop(pod *corev1.Pod, cs *corev1.ContainerStatus) []
 		PodName:       pod.Name,
 		ContainerName: cs.Name,
 		Message:       fmt.Sprintf("Container crashing repeatedly (restarts: %d)", cs.RestartCount),
-		Details: map[string]string{
-			"restarts": fmt.Sprintf("%d", cs.RestartCount),
-		},
+		SubReason:     subReason,
+		Details:       details,
 	}}
 }
 
@@ -309,12 +372,37 @@ func (w *Watcher) checkImagePull(pod *corev1.Pod, cs *corev1.ContainerStatus) []
 		PodName:       pod.Name,
 		ContainerName: cs.Name,
 		Message:       fmt.Sprintf("Cannot pull image: %s", cs.State.Waiting.Message),
+		SubReason:     imagePullSubReason(cs.State.Waiting.Message),
 		Details: map[string]string{
 			"image": cs.Image,
 		},
 	}}
 }
 
+// checkConfigError reports containers that cannot be created because of
+// their configuration, most often a missing Secret, ConfigMap, or key.
+func (w *Watcher) checkConfigError(pod *corev1.Pod, cs *corev1.ContainerStatus) []Problem {
+	if cs.State.Waiting == nil {
+		return nil
+	}
+	reason := cs.State.Waiting.Reason
+	if reason != "CreateContainerConfigError" && reason != "CreateContainerError" && reason != "RunContainerError" {
+		return nil
+	}
+
+	subReason, details := configErrorSubReason(cs.State.Waiting.Message)
+	return []Problem{{
+		Severity:      SeverityCritical,
+		Type:          reason,
+		Namespace:     pod.Namespace,
+		PodName:       pod.Name,
+		ContainerName: cs.Name,
+		Message:       fmt.Sprintf("Cannot start container: %s", cs.State.Waiting.Message),
+		SubReason:     subReason,
+		Details:       details,
+	}}
+}
+
 func (w *Watcher) checkHighRestarts(pod *corev1.Pod, cs *corev1.ContainerStatus) []Problem {
 	if cs.RestartCount <= 5 {
 		return nil
@@ -353,55 +441,84 @@ func (w *Watcher) checkPendingPod(pod *corev1.Pod) []Problem {
 		}
 	}
 
+	subReason, details := schedulingSubReason(message)
+	if details == nil {
+		details = map[string]string{}
+	}
+	details["reason"] = reason
+	details["pod_age"] = podAge.String()
+	details["node_name"] = pod.Spec.NodeName
+
 	return []Problem{{
 		Severity:  SeverityCritical,
 		Type:      "PodPending",
 		Namespace: pod.Namespace,
 		PodName:   pod.Name,
 		Message:   fmt.Sprintf("Pod pending for %s: %s", formatDuration(podAge), message),
-		Details: map[string]string{
-			"reason":    reason,
-			"pod_age":   podAge.String(),
-			"node_name": pod.Spec.NodeName,
-		},
+		SubReason: subReason,
+		Details:   details,
 	}}
 }
 
-// addProblem adds or updates a problem
+// addProblem adds or updates a problem and notifies the UI
 func (w *Watcher) addProblem(severity Severity, typ, namespace, podName, containerName, message string, details map[string]string) {
+	defer w.notify()
+	w.upsertProblem(&Problem{
+		Severity:      severity,
+		Type:          typ,
+		Namespace:     namespace,
+		PodName:       podName,
+		ContainerName: containerName,
+		Message:       message,
+		Details:       details,
+	})
+}
+
+// upsertProblem records p, or refreshes the problem with the same
+// namespace, pod, container, and type. A newer sub-reason replaces the old.
+func (w *Watcher) upsertProblem(p *Problem) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	problemKey := fmt.Sprintf("%s/%s/%s/%s", namespace, podName, containerName, typ)
+	key := problemKey(p)
 	now := time.Now()
 
-	if problem, exists := w.problems[problemKey]; exists {
+	if problem, exists := w.problems[key]; exists {
 		problem.Count++
 		problem.LastSeen = now
-		problem.Message = message
-		for k, v := range details {
-			problem.Details[k] = v
+		problem.Message = p.Message
+		if p.SubReason != "" {
+			problem.SubReason = p.SubReason
 		}
-	} else {
-		w.problems[problemKey] = &Problem{
-			Severity:      severity,
-			Type:          typ,
-			Namespace:     namespace,
-			PodName:       podName,
-			ContainerName: containerName,
-			Message:       message,
-			Reason:        typ,
-			FirstSeen:     now,
-			LastSeen:      now,
-			Count:         1,
-			Details:       details,
+		if problem.Details == nil {
+			problem.Details = make(map[string]string)
 		}
+		for k, v := range p.Details {
+			problem.Details[k] = v
+		}
+		return
+	}
+
+	stored := *p
+	stored.Reason = p.Type
+	stored.FirstSeen = now
+	stored.LastSeen = now
+	stored.Count = 1
+	if stored.Details == nil {
+		stored.Details = make(map[string]string)
 	}
+	w.problems[key] = &stored
+}
+
+// problemKey identifies a pod problem by namespace, pod, container, and type.
+func problemKey(p *Problem) string {
+	return fmt.Sprintf("%s/%s/%s/%s", p.Namespace, p.PodName, p.ContainerName, p.Type)
 }
 
-// updateStats periodically updates cluster statistics
+// updateStats periodically recomputes cluster statistics from the
+// informer caches and drops expired problems, notifying only on change.
 func (w *Watcher) updateStats(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(statsInterval)
 	defer ticker.Stop()
 
 	for {
@@ -409,25 +526,47 @@ func (w *Watcher) updateStats(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			w.refreshStats()
-			w.cleanupOldProblems()
+			w.probeConnection()
+			changed := w.refreshStats()
+			if w.cleanupOldProblems() || changed {
+				w.notify()
+			}
 		}
 	}
 }
 
-// refreshStats refreshes cluster statistics
-func (w *Watcher) refreshStats() {
-	// Get pod stats
-	pods, err := w.clientset.CoreV1().Pods(w.config.Namespace).List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		w.setConnectionError(err)
+// probeConnection checks a lost connection for recovery, which the
+// informers do not report on their own in a quiet cluster.
+func (w *Watcher) probeConnection() {
+	w.mu.RLock()
+	down := w.connStatus == ConnectionUnreachable
+	w.mu.RUnlock()
+	if !down {
 		return
 	}
+	if _, err := w.clientset.Discovery().ServerVersion(); err == nil {
+		w.setConnectionOK()
+	}
+}
+
+// refreshStats recomputes cluster statistics from the informer caches and
+// reports whether they changed.
+func (w *Watcher) refreshStats() bool {
+	if w.pods == nil || w.nodes == nil {
+		return false
+	}
+	pods, err := w.pods.List(labels.Everything())
+	if err != nil {
+		return false
+	}
+	nodes, err := w.nodes.List(labels.Everything())
+	if err != nil {
+		return false
+	}
 
 	running := 0
 	problem := 0
-	for i := range pods.Items {
-		pod := &pods.Items[i]
+	for _, pod := range pods {
 		switch pod.Status.Phase {
 		case corev1.PodRunning:
 			running++
@@ -436,27 +575,8 @@ func (w *Watcher) refreshStats() {
 		}
 	}
 
-	w.mu.Lock()
-	w.stats.TotalPods = len(pods.Items)
-	w.stats.RunningPods = running
-	w.stats.ProblemPods = problem
-	w.stats.CriticalCount = len(w.problems)
-	w.mu.Unlock()
-
-	w.updateChan <- struct{}{}
-
-	// Get node stats
-	nodes, err := w.clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		w.setConnectionError(err)
-		return
-	}
-
-	w.setConnectionOK()
-
 	ready := 0
-	for i := range nodes.Items {
-		node := &nodes.Items[i]
+	for _, node := range nodes {
 		for j := range node.Status.Conditions {
 			condition := &node.Status.Conditions[j]
 			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
@@ -467,27 +587,35 @@ func (w *Watcher) refreshStats() {
 	}
 
 	w.mu.Lock()
-	w.stats.TotalNodes = len(nodes.Items)
+	defer w.mu.Unlock()
+	before := w.stats
+	w.stats.TotalPods = len(pods)
+	w.stats.RunningPods = running
+	w.stats.ProblemPods = problem
+	w.stats.CriticalCount = len(w.problems)
+	w.stats.TotalNodes = len(nodes)
 	w.stats.ReadyNodes = ready
-	w.stats.NotReadyNodes = len(nodes.Items) - ready
-	w.mu.Unlock()
-
-	w.updateChan <- struct{}{}
+	w.stats.NotReadyNodes = len(nodes) - ready
+	return w.stats != before
 }
 
 // cleanupOldProblems removes problems that haven't been seen in a while
-func (w *Watcher) cleanupOldProblems() {
+// and reports whether any were removed.
+func (w *Watcher) cleanupOldProblems() bool {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	removed := false
 	now := time.Now()
 	maxAge := 15 * time.Minute // Problems disappear after 15 minutes of not being seen
 
 	for key, problem := range w.problems {
 		if now.Sub(problem.LastSeen) > maxAge {
 			delete(w.problems, key)
+			removed = true
 		}
 	}
+	return removed
 }
 
 // setConnectionError records a connection failure and notifies the UI
@@ -496,7 +624,7 @@ func (w *Watcher) setConnectionError(err error) {
 	w.connStatus = ConnectionUnreachable
 	w.lastErr = err.Error()
 	w.mu.Unlock()
-	w.updateChan <- struct{}{}
+	w.notify()
 }
 
 // setConnectionOK marks the connection as healthy
@@ -507,7 +635,16 @@ func (w *Watcher) setConnectionOK() {
 	w.lastErr = ""
 	w.mu.Unlock()
 	if changed {
-		w.updateChan <- struct{}{}
+		w.notify()
+	}
+}
+
+// notify wakes the UI. Updates coalesce: when the UI is behind, it reads
+// the latest state on its next wake-up anyway.
+func (w *Watcher) notify() {
+	select {
+	case w.updateChan <- struct{}{}:
+	default:
 	}
 }
 
@@ -1,9 +1,17 @@
 package monitor
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/ack"
 )
 
 func TestGetState_ConnectionStatus_Propagated(t *testing.T) {
@@ -118,3 +126,93 @@ func TestConnectionStatus_DefaultUnknown(t *testing.T) {
 
 	assert.Equal(t, ConnectionUnknown, w.connStatus)
 }
+
+func TestSplitAcknowledged(t *testing.T) {
+	now := time.Now()
+	acks := &ack.List{Acknowledgements: []ack.Acknowledgement{
+		{Namespace: "batch", Pod: "report-*", Type: "CrashLoopBackOff", Reason: "nightly flake"},
+	}}
+	problems := []Problem{
+		{Type: "CrashLoopBackOff", Namespace: "batch", PodName: "report-1", ContainerName: "app"},
+		{Type: "OOMKilled", Namespace: "batch", PodName: "report-1", ContainerName: "app"},
+		{Type: "CrashLoopBackOff", Namespace: "prod", PodName: "api-1"},
+	}
+
+	active, accepted := SplitAcknowledged(problems, acks, now)
+	require.Len(t, active, 2)
+	assert.Equal(t, "OOMKilled", active[0].Type)
+	assert.Equal(t, "prod", active[1].Namespace)
+	require.Len(t, accepted, 1)
+	assert.Equal(t, "batch/report-1/app", accepted[0].Location())
+	assert.Equal(t, "nightly flake", accepted[0].Reason)
+
+	active, accepted = SplitAcknowledged(problems, nil, now)
+	assert.Len(t, active, 3)
+	assert.Empty(t, accepted)
+}
+
+func TestWatcher_InformersDetectProblems(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pod := crashingPod()
+	pod.ResourceVersion = "1"
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+	}
+	client := fake.NewClientset(pod, node)
+	w := NewWatcher(client, Config{DisableMesh: true})
+	require.NoError(t, w.Start(ctx))
+
+	crashLoop := func() *Problem {
+		problems, _, _ := w.GetState()
+		for i := range problems {
+			if problems[i].Type == "CrashLoopBackOff" {
+				return &problems[i]
+			}
+		}
+		return nil
+	}
+	require.Eventually(t, func() bool { return crashLoop() != nil }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, crashLoop().Count)
+
+	// Stats come from the caches and report a change only once
+	require.Eventually(t, func() bool { return w.refreshStats() }, 5*time.Second, 10*time.Millisecond)
+	_, _, stats := w.GetState()
+	assert.Equal(t, 1, stats.TotalPods)
+	assert.Equal(t, 1, stats.ReadyNodes)
+	assert.False(t, w.refreshStats())
+
+	// A new pod version counts as another occurrence
+	pod.ResourceVersion = "2"
+	pod.Status.ContainerStatuses[0].RestartCount = 5
+	_, err := client.CoreV1().Pods("prod").UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return crashLoop().Count == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRefreshPodProblems_KeepsCount(t *testing.T) {
+	w := NewWatcher(nil, Config{})
+	pod := crashingPod()
+	w.processPodStatus(pod)
+	problems, _, _ := w.GetState()
+	require.NotEmpty(t, problems)
+
+	for _, p := range w.problems {
+		p.LastSeen = time.Now().Add(-time.Hour)
+	}
+	w.refreshPodProblems(pod)
+	for _, p := range w.problems {
+		assert.Equal(t, 1, p.Count, p.Type)
+		assert.WithinDuration(t, time.Now(), p.LastSeen, time.Second, p.Type)
+	}
+	assert.False(t, w.cleanupOldProblems(), "refreshed problems do not expire")
+}
+
+func TestNotify_Coalesces(t *testing.T) {
+	w := &Watcher{updateChan: make(chan struct{}, 1)}
+	w.notify()
+	w.notify() // must not block when the UI is behind
+	assert.Len(t, w.updateChan, 1)
+}
@@ -0,0 +1,255 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Payload formats. The plain formats match the webhook kinds; the card
+// formats are opted into per target.
+const (
+	FormatSlack          = KindSlack
+	FormatTeams          = KindTeams // legacy Office 365 MessageCard
+	FormatTeamsCard      = "teams-card"
+	FormatGoogleChat     = KindGoogleChat
+	FormatGoogleChatCard = "google-chat-card"
+	FormatGeneric        = KindGeneric
+)
+
+// maxCardIssues caps the issues listed on a card; the rest are counted.
+const maxCardIssues = 10
+
+// ValidFormat reports whether f names a payload format.
+func ValidFormat(f string) bool {
+	switch f {
+	case FormatSlack, FormatTeams, FormatTeamsCard, FormatGoogleChat, FormatGoogleChatCard, FormatGeneric:
+		return true
+	default:
+		return false
+	}
+}
+
+// format returns the target's payload format, defaulting to its kind.
+func (t *Target) format() string {
+	if t.Format != "" {
+		return t.Format
+	}
+	return t.Kind
+}
+
+// topAlerts returns the most severe alerts first, at most maxCardIssues,
+// and the number left out.
+func topAlerts(alerts []Alert) (top []Alert, more int) {
+	top = append([]Alert(nil), alerts...)
+	sort.SliceStable(top, func(i, j int) bool { return top[i].Severity > top[j].Severity })
+	if len(top) > maxCardIssues {
+		return top[:maxCardIssues], len(top) - maxCardIssues
+	}
+	return top, 0
+}
+
+// alertText renders "namespace/name - type: summary" for a card line.
+func alertText(a *Alert) string {
+	text := fmt.Sprintf("%s/%s - %s", a.Namespace, a.Name, a.IssueType)
+	if a.Summary != "" {
+		text += ": " + a.Summary
+	}
+	return text
+}
+
+// severityHex is the card color of a severity: red for critical, orange for
+// high, amber for medium, grey for low.
+func severityHex(s Severity) string {
+	switch {
+	case s >= SeverityCritical:
+		return "#D32F2F"
+	case s == SeverityHigh:
+		return "#F57C00"
+	case s == SeverityMedium:
+		return "#FFA000"
+	default:
+		return "#757575"
+	}
+}
+
+// adaptiveColor maps a severity onto the Adaptive Card text color palette.
+func adaptiveColor(s Severity) string {
+	switch {
+	case s >= SeverityCritical:
+		return "Attention"
+	case s >= SeverityMedium:
+		return "Warning"
+	default:
+		return "Default"
+	}
+}
+
+type adaptiveMessage struct {
+	Type        string               `json:"type"`
+	Attachments []adaptiveAttachment `json:"attachments"`
+}
+
+type adaptiveAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Body    []adaptiveElement `json:"body"`
+	Actions []adaptiveAction  `json:"actions,omitempty"`
+}
+
+type adaptiveElement struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Weight string `json:"weight,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Wrap   bool   `json:"wrap,omitempty"`
+}
+
+type adaptiveAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// buildAdaptiveCard renders alerts as a Microsoft Teams Adaptive Card
+// message: the title colored by the highest severity, one line per top
+// issue colored by its own severity, and a button to the report.
+func buildAdaptiveCard(t *Target, title string, alerts []Alert) adaptiveMessage {
+	top, more := topAlerts(alerts)
+	body := []adaptiveElement{{
+		Type: "TextBlock", Text: title, Weight: "Bolder", Size: "Medium",
+		Color: adaptiveColor(maxSeverity(alerts)), Wrap: true,
+	}}
+	for i := range top {
+		a := &top[i]
+		body = append(body, adaptiveElement{
+			Type:  "TextBlock",
+			Text:  fmt.Sprintf("**%s** %s", strings.ToUpper(a.Severity.String()), alertText(a)),
+			Color: adaptiveColor(a.Severity),
+			Wrap:  true,
+		})
+	}
+	if more > 0 {
+		body = append(body, adaptiveElement{Type: "TextBlock", Text: fmt.Sprintf("…and %d more", more), Wrap: true})
+	}
+
+	card := adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+	}
+	if t.ReportURL != "" {
+		card.Actions = []adaptiveAction{{Type: "Action.OpenUrl", Title: "Open report", URL: t.ReportURL}}
+	}
+	return adaptiveMessage{
+		Type:        "message",
+		Attachments: []adaptiveAttachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+	}
+}
+
+type chatMessage struct {
+	Text    string         `json:"text"`
+	CardsV2 []chatCardSlot `json:"cardsV2"`
+}
+
+type chatCardSlot struct {
+	CardID string   `json:"cardId"`
+	Card   chatCard `json:"card"`
+}
+
+type chatCard struct {
+	Header   chatHeader    `json:"header"`
+	Sections []chatSection `json:"sections"`
+}
+
+type chatHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+type chatSection struct {
+	Header  string       `json:"header,omitempty"`
+	Widgets []chatWidget `json:"widgets"`
+}
+
+type chatWidget struct {
+	DecoratedText *chatDecoratedText `json:"decoratedText,omitempty"`
+	TextParagraph *chatText          `json:"textParagraph,omitempty"`
+	ButtonList    *chatButtonList    `json:"buttonList,omitempty"`
+}
+
+type chatDecoratedText struct {
+	TopLabel    string `json:"topLabel"`
+	Text        string `json:"text"`
+	BottomLabel string `json:"bottomLabel,omitempty"`
+	WrapText    bool   `json:"wrapText"`
+}
+
+type chatText struct {
+	Text string `json:"text"`
+}
+
+type chatButtonList struct {
+	Buttons []chatButton `json:"buttons"`
+}
+
+type chatButton struct {
+	Text    string      `json:"text"`
+	OnClick chatOnClick `json:"onClick"`
+}
+
+type chatOnClick struct {
+	OpenLink chatLink `json:"openLink"`
+}
+
+type chatLink struct {
+	URL string `json:"url"`
+}
+
+// buildGoogleChatCard renders alerts as a Google Chat card: a header with
+// the issue count and highest severity, one entry per top issue with its
+// severity in color, and a button to the report. Text is the notification
+// preview.
+func buildGoogleChatCard(t *Target, title string, alerts []Alert) chatMessage {
+	top, more := topAlerts(alerts)
+	highest := maxSeverity(alerts)
+
+	issues := chatSection{Header: "Top issues"}
+	for i := range top {
+		a := &top[i]
+		issues.Widgets = append(issues.Widgets, chatWidget{DecoratedText: &chatDecoratedText{
+			TopLabel:    fmt.Sprintf(`<font color="%s">%s</font>`, severityHex(a.Severity), strings.ToUpper(a.Severity.String())),
+			Text:        fmt.Sprintf("%s/%s - %s", a.Namespace, a.Name, a.IssueType),
+			BottomLabel: a.Summary,
+			WrapText:    true,
+		}})
+	}
+	if more > 0 {
+		issues.Widgets = append(issues.Widgets, chatWidget{TextParagraph: &chatText{Text: fmt.Sprintf("…and %d more", more)}})
+	}
+
+	card := chatCard{
+		Header: chatHeader{
+			Title:    title,
+			Subtitle: fmt.Sprintf("%d issue(s), highest %s", len(alerts), strings.ToUpper(highest.String())),
+		},
+		Sections: []chatSection{issues},
+	}
+	if t.ReportURL != "" {
+		card.Sections = append(card.Sections, chatSection{Widgets: []chatWidget{{ButtonList: &chatButtonList{
+			Buttons: []chatButton{{Text: "Open report", OnClick: chatOnClick{OpenLink: chatLink{URL: t.ReportURL}}}},
+		}}}})
+	}
+	return chatMessage{
+		Text:    title,
+		CardsV2: []chatCardSlot{{CardID: "kubenow-alerts", Card: card}},
+	}
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cardAlerts = []Alert{
+	{Severity: SeverityHigh, Namespace: "prod", Name: "web", IssueType: "ImagePullBackOff"},
+	{Severity: SeverityCritical, Namespace: "prod", Name: "api", IssueType: "CrashLoopBackOff", Summary: "panics on start"},
+}
+
+func TestSinkTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		sink    Sink
+		want    Target
+		wantErr string
+	}{
+		{
+			name: "google chat card with report",
+			sink: Sink{
+				URL:         "https://chat.googleapis.com/v1/spaces/AAA/messages?key=k",
+				MinSeverity: "medium",
+				Format:      FormatGoogleChatCard,
+				ReportURL:   "https://reports.example.com/latest.html",
+			},
+			want: Target{
+				Kind:        KindGoogleChat,
+				URL:         "https://chat.googleapis.com/v1/spaces/AAA/messages?key=k",
+				MinSeverity: SeverityMedium,
+				Format:      FormatGoogleChatCard,
+				ReportURL:   "https://reports.example.com/latest.html",
+			},
+		},
+		{
+			name: "channel kept for slack format only",
+			sink: Sink{URL: "https://contoso.webhook.office.com/webhookb2/abc", Channel: "#oncall"},
+			want: Target{Kind: KindTeams, URL: "https://contoso.webhook.office.com/webhookb2/abc", MinSeverity: SeverityHigh},
+		},
+		{
+			name: "slack-compatible generic endpoint",
+			sink: Sink{URL: "https://mattermost.example.com/hooks/x", Format: FormatSlack, Channel: "#oncall"},
+			want: Target{
+				Kind: KindGeneric, URL: "https://mattermost.example.com/hooks/x", MinSeverity: SeverityHigh,
+				Format: FormatSlack, Channel: "#oncall",
+			},
+		},
+		{name: "bad severity", sink: Sink{URL: "https://example.com/h", MinSeverity: "urgent"}, wantErr: "invalid min_severity"},
+		{name: "bad format", sink: Sink{URL: "https://example.com/h", Format: "card"}, wantErr: "invalid format"},
+		{name: "bad report url", sink: Sink{URL: "https://example.com/h", ReportURL: "report.html"}, wantErr: "invalid report_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.sink.Target()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildAdaptiveCard(t *testing.T) {
+	target := &Target{Kind: KindTeams, Format: FormatTeamsCard, ReportURL: "https://reports.example.com/latest.html"}
+	msg := buildAdaptiveCard(target, "kubenow: 2 new issue(s)", cardAlerts)
+
+	assert.Equal(t, "message", msg.Type)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", msg.Attachments[0].ContentType)
+
+	card := msg.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	require.Len(t, card.Body, 3)
+	assert.Equal(t, "Attention", card.Body[0].Color, "title colored by the highest severity")
+	assert.Equal(t, "**CRITICAL** prod/api - CrashLoopBackOff: panics on start", card.Body[1].Text, "most severe first")
+	assert.Equal(t, "Attention", card.Body[1].Color)
+	assert.Equal(t, "Warning", card.Body[2].Color)
+	require.Len(t, card.Actions, 1)
+	assert.Equal(t, "Action.OpenUrl", card.Actions[0].Type)
+	assert.Equal(t, "https://reports.example.com/latest.html", card.Actions[0].URL)
+}
+
+func TestBuildGoogleChatCard(t *testing.T) {
+	msg := buildGoogleChatCard(&Target{Kind: KindGoogleChat}, "kubenow: 2 new issue(s)", cardAlerts)
+
+	assert.Equal(t, "kubenow: 2 new issue(s)", msg.Text)
+	require.Len(t, msg.CardsV2, 1)
+	card := msg.CardsV2[0].Card
+	assert.Equal(t, "2 issue(s), highest CRITICAL", card.Header.Subtitle)
+	require.Len(t, card.Sections, 1, "no report button without a report URL")
+	widgets := card.Sections[0].Widgets
+	require.Len(t, widgets, 2)
+	assert.Equal(t, `<font color="#D32F2F">CRITICAL</font>`, widgets[0].DecoratedText.TopLabel)
+	assert.Equal(t, "prod/api - CrashLoopBackOff", widgets[0].DecoratedText.Text)
+	assert.Equal(t, "panics on start", widgets[0].DecoratedText.BottomLabel)
+	assert.Equal(t, `<font color="#F57C00">HIGH</font>`, widgets[1].DecoratedText.TopLabel)
+}
+
+func TestTopAlerts_Truncates(t *testing.T) {
+	alerts := make([]Alert, 0, maxCardIssues+3)
+	for i := range maxCardIssues + 3 {
+		alerts = append(alerts, Alert{Severity: SeverityMedium, Namespace: "ns", Name: fmt.Sprintf("pod-%d", i)})
+	}
+	alerts = append(alerts, Alert{Severity: SeverityCritical, Namespace: "ns", Name: "worst"})
+
+	top, more := topAlerts(alerts)
+	require.Len(t, top, maxCardIssues)
+	assert.Equal(t, "worst", top[0].Name)
+	assert.Equal(t, 4, more)
+	assert.Equal(t, "pod-0", alerts[0].Name, "input order is not modified")
+
+	msg := buildGoogleChatCard(&Target{}, "t", alerts)
+	widgets := msg.CardsV2[0].Card.Sections[0].Widgets
+	require.Len(t, widgets, maxCardIssues+1)
+	assert.Equal(t, "…and 4 more", widgets[maxCardIssues].TextParagraph.Text)
+}
+
+func TestSend_CardFormats(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies[r.URL.Path] = body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := &Notifier{Targets: []Target{
+		{Kind: KindTeams, Format: FormatTeamsCard, URL: srv.URL + "/teams", MinSeverity: SeverityLow},
+		{Kind: KindGoogleChat, Format: FormatGoogleChatCard, URL: srv.URL + "/chat", MinSeverity: SeverityLow},
+		{Kind: KindGoogleChat, URL: srv.URL + "/chat-text", MinSeverity: SeverityLow},
+	}}
+	sent, err := n.Send(context.Background(), "kubenow: 2 new issue(s)", cardAlerts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, sent)
+
+	assert.Contains(t, bodies["/teams"], "attachments")
+	assert.Contains(t, bodies["/chat"], "cardsV2")
+	assert.Equal(t,
+		"*kubenow: 2 new issue(s)*\n• [HIGH] prod/web - ImagePullBackOff\n• [CRITICAL] prod/api - CrashLoopBackOff: panics on start",
+		bodies["/chat-text"]["text"])
+}
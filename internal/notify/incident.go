@@ -0,0 +1,295 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// Incident providers.
+const (
+	ProviderPagerDuty = "pagerduty"
+	ProviderOpsgenie  = "opsgenie"
+)
+
+// Default API endpoints. Opsgenie's EU instance is https://api.eu.opsgenie.com.
+const (
+	DefaultPagerDutyURL = "https://events.pagerduty.com"
+	DefaultOpsgenieURL  = "https://api.opsgenie.com"
+)
+
+// opsgenieMessageMax is Opsgenie's limit on an alert message.
+const opsgenieMessageMax = 130
+
+// IncidentService is a PagerDuty Events API v2 integration or an Opsgenie
+// API integration that receives incidents.
+type IncidentService struct {
+	Provider string `yaml:"provider"`          // pagerduty or opsgenie
+	Key      string `yaml:"key,omitempty"`     // PagerDuty routing key or Opsgenie API key
+	KeyEnv   string `yaml:"key_env,omitempty"` // environment variable holding the key
+	URL      string `yaml:"url,omitempty"`     // API base URL; default: the provider's
+}
+
+// Validate checks the provider, resolves KeyEnv, and fills in the default URL.
+func (s *IncidentService) Validate() error {
+	switch s.Provider {
+	case ProviderPagerDuty, ProviderOpsgenie:
+	default:
+		return fmt.Errorf("invalid provider %q (use pagerduty or opsgenie)", s.Provider)
+	}
+	if s.Key == "" && s.KeyEnv != "" {
+		s.Key = os.Getenv(s.KeyEnv)
+		if s.Key == "" {
+			return fmt.Errorf("%s: %s is not set", s.Provider, s.KeyEnv)
+		}
+	}
+	if s.Key == "" {
+		return fmt.Errorf("%s: key or key_env is required", s.Provider)
+	}
+	if s.URL == "" {
+		s.URL = DefaultPagerDutyURL
+		if s.Provider == ProviderOpsgenie {
+			s.URL = DefaultOpsgenieURL
+		}
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: invalid url %q", s.Provider, s.URL)
+	}
+	s.URL = strings.TrimSuffix(s.URL, "/")
+	return nil
+}
+
+// NotificationsFile is the notifications config file (--notify-config).
+type NotificationsFile struct {
+	Incidents []IncidentService `yaml:"incidents"`
+}
+
+// LoadNotificationsFile reads and validates a notifications config file.
+func LoadNotificationsFile(path string) (*NotificationsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifications config: %w", err)
+	}
+	var file NotificationsFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid notifications config %s: %w", path, err)
+	}
+	if len(file.Incidents) == 0 {
+		return nil, fmt.Errorf("notifications config %s defines no incidents", path)
+	}
+	for i := range file.Incidents {
+		if err := file.Incidents[i].Validate(); err != nil {
+			return nil, fmt.Errorf("incidents[%d]: %w", i, err)
+		}
+	}
+	return &file, nil
+}
+
+// Incident is a fatal problem opened as an incident. Fingerprint
+// identifies where it occurs (namespace/pod/container); the same
+// fingerprint always maps to the same dedup key, so repeated triggers
+// update one incident and a resolve closes it.
+type Incident struct {
+	Fingerprint string
+	Namespace   string
+	Name        string // pod, or pod/container
+	IssueType   string
+	Summary     string
+}
+
+// Incidents opens and resolves incidents in every configured service.
+type Incidents struct {
+	Services []IncidentService
+	Cluster  string        // prefixes dedup keys so clusters do not share incidents
+	Timeout  time.Duration // per request timeout
+}
+
+// DedupKey derives the PagerDuty dedup key and Opsgenie alias of an
+// incident from the cluster and the issue fingerprint. Hashing keeps it
+// within both providers' length limits for any pod name.
+func DedupKey(cluster, fingerprint string) string {
+	sum := sha256.Sum256([]byte(cluster + "\x00" + fingerprint))
+	return "kubenow-" + hex.EncodeToString(sum[:16])
+}
+
+// Open triggers the incident in every service. All services are
+// attempted; the first error is returned.
+func (n *Incidents) Open(ctx context.Context, inc *Incident) error {
+	return n.each(func(s *IncidentService) error {
+		if s.Provider == ProviderOpsgenie {
+			return n.opsgenieOpen(ctx, s, inc)
+		}
+		return n.pagerDuty(ctx, s, "trigger", inc)
+	})
+}
+
+// Resolve resolves the incident in every service. Resolving an incident
+// that was never opened is harmless.
+func (n *Incidents) Resolve(ctx context.Context, inc *Incident) error {
+	return n.each(func(s *IncidentService) error {
+		if s.Provider == ProviderOpsgenie {
+			return n.opsgenieClose(ctx, s, inc)
+		}
+		return n.pagerDuty(ctx, s, "resolve", inc)
+	})
+}
+
+func (n *Incidents) each(fn func(*IncidentService) error) error {
+	var firstErr error
+	for i := range n.Services {
+		if err := fn(&n.Services[i]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (inc *Incident) title() string {
+	s := fmt.Sprintf("kubenow: %s %s/%s", inc.IssueType, inc.Namespace, inc.Name)
+	if inc.Summary != "" {
+		s += ": " + inc.Summary
+	}
+	return s
+}
+
+func (inc *Incident) details(cluster string) map[string]string {
+	d := map[string]string{"namespace": inc.Namespace, "name": inc.Name, "issue_type": inc.IssueType}
+	if cluster != "" {
+		d["cluster"] = cluster
+	}
+	return d
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func (n *Incidents) pagerDuty(ctx context.Context, s *IncidentService, action string, inc *Incident) error {
+	ev := pagerDutyEvent{RoutingKey: s.Key, EventAction: action, DedupKey: DedupKey(n.Cluster, inc.Fingerprint)}
+	if action == "trigger" {
+		source := "kubenow"
+		if n.Cluster != "" {
+			source = n.Cluster
+		}
+		ev.Payload = &pagerDutyPayload{
+			Summary:       inc.title(),
+			Source:        source,
+			Severity:      "critical",
+			Component:     inc.Name,
+			Group:         inc.Namespace,
+			Class:         inc.IssueType,
+			CustomDetails: inc.details(n.Cluster),
+		}
+	}
+	return n.post(ctx, s, s.URL+"/v2/enqueue", nil, ev)
+}
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note"`
+}
+
+func (n *Incidents) opsgenieOpen(ctx context.Context, s *IncidentService, inc *Incident) error {
+	message := inc.title()
+	if len(message) > opsgenieMessageMax {
+		message = message[:opsgenieMessageMax-3] + "..."
+	}
+	alert := opsgenieAlert{
+		Message:     message,
+		Alias:       DedupKey(n.Cluster, inc.Fingerprint),
+		Description: inc.title(),
+		Priority:    "P1",
+		Source:      "kubenow",
+		Tags:        []string{"kubenow", inc.IssueType},
+		Details:     inc.details(n.Cluster),
+	}
+	return n.post(ctx, s, s.URL+"/v2/alerts", opsgenieAuth(s), alert)
+}
+
+func (n *Incidents) opsgenieClose(ctx context.Context, s *IncidentService, inc *Incident) error {
+	endpoint := s.URL + "/v2/alerts/" + url.PathEscape(DedupKey(n.Cluster, inc.Fingerprint)) + "/close?identifierType=alias"
+	return n.post(ctx, s, endpoint, opsgenieAuth(s), opsgenieClose{Source: "kubenow", Note: "resolved: problem no longer detected"})
+}
+
+func opsgenieAuth(s *IncidentService) http.Header {
+	return http.Header{"Authorization": []string{"GenieKey " + s.Key}}
+}
+
+func (n *Incidents) post(ctx context.Context, s *IncidentService, endpoint string, header http.Header, payload any) error {
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", s.Provider, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", s.Provider, req.URL.Host, util.UnwrapURLError(err))
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, readErr := io.ReadAll(io.LimitReader(resp.Body, 500))
+		if readErr != nil {
+			msg = nil
+		}
+		return fmt.Errorf("%s %s: %d %s: %s", s.Provider, req.URL.Host, resp.StatusCode, http.StatusText(resp.StatusCode), string(msg))
+	}
+	return nil
+}
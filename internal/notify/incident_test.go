@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	Path string
+	Auth string
+	Body map[string]any
+}
+
+func incidentServer(t *testing.T) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var reqs []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		reqs = append(reqs, recordedRequest{Path: r.URL.RequestURI(), Auth: r.Header.Get("Authorization"), Body: body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return reqs
+	}
+}
+
+func TestIncidents_OpenAndResolve(t *testing.T) {
+	srv, requests := incidentServer(t)
+	n := &Incidents{Cluster: "prod-eu", Services: []IncidentService{
+		{Provider: ProviderPagerDuty, Key: "pd-key", URL: srv.URL},
+		{Provider: ProviderOpsgenie, Key: "og-key", URL: srv.URL},
+	}}
+	inc := &Incident{Fingerprint: "shop/api-1/app", Namespace: "shop", Name: "api-1/app", IssueType: "OOMKilled"}
+	key := DedupKey("prod-eu", "shop/api-1/app")
+
+	require.NoError(t, n.Open(context.Background(), inc))
+	require.NoError(t, n.Resolve(context.Background(), inc))
+
+	reqs := requests()
+	require.Len(t, reqs, 4)
+
+	trigger := reqs[0]
+	assert.Equal(t, "/v2/enqueue", trigger.Path)
+	assert.Equal(t, "pd-key", trigger.Body["routing_key"])
+	assert.Equal(t, "trigger", trigger.Body["event_action"])
+	assert.Equal(t, key, trigger.Body["dedup_key"])
+	payload := trigger.Body["payload"].(map[string]any)
+	assert.Equal(t, "kubenow: OOMKilled shop/api-1/app", payload["summary"])
+	assert.Equal(t, "prod-eu", payload["source"])
+	assert.Equal(t, "critical", payload["severity"])
+
+	alert := reqs[1]
+	assert.Equal(t, "/v2/alerts", alert.Path)
+	assert.Equal(t, "GenieKey og-key", alert.Auth)
+	assert.Equal(t, key, alert.Body["alias"])
+	assert.Equal(t, "P1", alert.Body["priority"])
+
+	resolve := reqs[2]
+	assert.Equal(t, "resolve", resolve.Body["event_action"])
+	assert.Equal(t, key, resolve.Body["dedup_key"])
+	assert.NotContains(t, resolve.Body, "payload")
+
+	closeReq := reqs[3]
+	assert.Equal(t, "/v2/alerts/"+key+"/close?identifierType=alias", closeReq.Path)
+	assert.Equal(t, "GenieKey og-key", closeReq.Auth)
+}
+
+func TestDedupKey(t *testing.T) {
+	key := DedupKey("prod", "shop/api-1/app")
+	assert.Equal(t, key, DedupKey("prod", "shop/api-1/app"))
+	assert.NotEqual(t, key, DedupKey("staging", "shop/api-1/app"))
+	assert.NotEqual(t, key, DedupKey("prod", "shop/api-2/app"))
+	assert.Regexp(t, "^kubenow-[0-9a-f]{32}$", key)
+}
+
+func TestLoadNotificationsFile(t *testing.T) {
+	t.Setenv("KUBENOW_TEST_OPSGENIE_KEY", "from-env")
+	path := filepath.Join(t.TempDir(), "notifications.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`incidents:
+  - provider: pagerduty
+    key: pd-key
+  - provider: opsgenie
+    key_env: KUBENOW_TEST_OPSGENIE_KEY
+    url: https://api.eu.opsgenie.com/
+`), 0o600))
+
+	file, err := LoadNotificationsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []IncidentService{
+		{Provider: ProviderPagerDuty, Key: "pd-key", URL: DefaultPagerDutyURL},
+		{Provider: ProviderOpsgenie, Key: "from-env", KeyEnv: "KUBENOW_TEST_OPSGENIE_KEY", URL: "https://api.eu.opsgenie.com"},
+	}, file.Incidents)
+}
+
+func TestIncidentService_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		service IncidentService
+		wantErr string
+	}{
+		{"unknown provider", IncidentService{Provider: "victorops", Key: "k"}, "invalid provider"},
+		{"no key", IncidentService{Provider: ProviderPagerDuty}, "key or key_env is required"},
+		{"unset env", IncidentService{Provider: ProviderOpsgenie, KeyEnv: "KUBENOW_TEST_UNSET"}, "KUBENOW_TEST_UNSET is not set"},
+		{"bad url", IncidentService{Provider: ProviderOpsgenie, Key: "k", URL: "api.opsgenie.com"}, "invalid url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.service.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,311 @@
+// Package notify pushes kubenow alerts to Slack, Microsoft Teams, Google Chat,
+// and generic webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// DefaultTimeout is used when Notifier.Timeout is unset.
+const DefaultTimeout = 10 * time.Second
+
+// Severity orders alert severities for routing.
+type Severity int
+
+// Severity levels, lowest first. LLM results use low/medium/high/critical;
+// the deterministic monitor levels map WARNING to medium, CRITICAL to high,
+// and FATAL to critical.
+const (
+	SeverityLow Severity = iota + 1
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// DefaultMinSeverity is the routing threshold of targets without an explicit one.
+const DefaultMinSeverity = SeverityHigh
+
+// ParseSeverity parses a severity name case-insensitively. Unknown names
+// return 0, which no target accepts.
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low", "info":
+		return SeverityLow
+	case "medium", "warning":
+		return SeverityMedium
+	case "high":
+		return SeverityHigh
+	case "critical", "fatal":
+		return SeverityCritical
+	default:
+		return 0
+	}
+}
+
+// String returns the lowercase severity name.
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// Webhook kinds, detected from the URL host.
+const (
+	KindSlack      = "slack"
+	KindTeams      = "teams"
+	KindGoogleChat = "google-chat"
+	KindGeneric    = "generic"
+)
+
+// Alert is a single issue to notify about.
+type Alert struct {
+	Severity  Severity `json:"-"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	IssueType string   `json:"issue_type"`
+	Summary   string   `json:"summary,omitempty"`
+	Trend     *Trend   `json:"trend,omitempty"` // usage trend behind a growth alert
+}
+
+// Trend is the usage history behind a rate-of-change alert.
+type Trend struct {
+	Resource           string       `json:"resource"` // "memory" or "cpu"
+	Unit               string       `json:"unit"`     // "bytes" or "cores"
+	RatePercentPerHour float64      `json:"rate_percent_per_hour"`
+	Samples            []TrendPoint `json:"samples"`
+	Limit              float64      `json:"limit,omitempty"`            // per-pod limit, 0 if unset
+	SecondsToLimit     float64      `json:"seconds_to_limit,omitempty"` // projected at the current rate
+}
+
+// TrendPoint is one observed usage value.
+type TrendPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Target is one webhook destination receiving alerts at or above MinSeverity.
+type Target struct {
+	Kind        string
+	URL         string
+	MinSeverity Severity
+	Channel     string // Slack channel override
+	Format      string // payload format (Format*); empty uses the kind's default
+	ReportURL   string // link to the exported report, shown on cards
+}
+
+// ParseTarget parses a --notify-webhook value of the form "[severity=]URL",
+// e.g. "critical=https://hooks.slack.com/services/...". The kind is detected
+// from the host; slackChannel applies to Slack targets.
+func ParseTarget(spec, slackChannel string) (Target, error) {
+	t := Target{MinSeverity: DefaultMinSeverity}
+	raw := spec
+	if sev, rest, ok := strings.Cut(spec, "="); ok && !strings.Contains(sev, "/") {
+		t.MinSeverity = ParseSeverity(sev)
+		if t.MinSeverity == 0 {
+			return Target{}, fmt.Errorf("invalid severity %q in webhook %q (use low, medium, high, or critical)", sev, spec)
+		}
+		raw = rest
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Target{}, fmt.Errorf("invalid webhook URL %q", raw)
+	}
+	t.URL = raw
+	t.Kind = detectKind(u.Hostname())
+	if t.Kind == KindSlack {
+		t.Channel = slackChannel
+	}
+	return t, nil
+}
+
+func detectKind(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case host == "hooks.slack.com":
+		return KindSlack
+	case strings.HasSuffix(host, ".webhook.office.com"), host == "outlook.office.com",
+		strings.HasSuffix(host, ".logic.azure.com"):
+		return KindTeams
+	case host == "chat.googleapis.com":
+		return KindGoogleChat
+	default:
+		return KindGeneric
+	}
+}
+
+// Notifier sends alerts to its targets.
+type Notifier struct {
+	Targets []Target
+	Timeout time.Duration // per request timeout
+}
+
+// Send routes alerts to every target whose threshold they meet. Targets
+// with no matching alerts are skipped. All targets are attempted; the
+// first error is returned along with the number of messages delivered.
+func (n *Notifier) Send(ctx context.Context, title string, alerts []Alert) (int, error) {
+	sent := 0
+	var firstErr error
+	for i := range n.Targets {
+		t := &n.Targets[i]
+		routed := filterAlerts(alerts, t.MinSeverity)
+		if len(routed) == 0 {
+			continue
+		}
+		if err := n.post(ctx, t, buildPayload(t, title, routed)); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, firstErr
+}
+
+func filterAlerts(alerts []Alert, minSeverity Severity) []Alert {
+	var routed []Alert
+	for _, a := range alerts {
+		if a.Severity >= minSeverity {
+			routed = append(routed, a)
+		}
+	}
+	return routed
+}
+
+func (n *Notifier) post(ctx context.Context, t *Target, payload any) error {
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", t.Kind, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		// Webhook URLs embed their secret; report only the host.
+		return fmt.Errorf("%s webhook %s: %w", t.Kind, req.URL.Host, util.UnwrapURLError(err))
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Truncate body to prevent leaking sensitive data in error messages
+		msg, readErr := io.ReadAll(io.LimitReader(resp.Body, 500))
+		if readErr != nil {
+			msg = nil
+		}
+		return fmt.Errorf("%s webhook %s: %d %s: %s", t.Kind, req.URL.Host, resp.StatusCode, http.StatusText(resp.StatusCode), string(msg))
+	}
+	return nil
+}
+
+type slackPayload struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+type teamsPayload struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+	ThemeColor string `json:"themeColor,omitempty"`
+}
+
+type genericAlert struct {
+	Alert
+	Severity string `json:"severity"`
+}
+
+type genericPayload struct {
+	Source string         `json:"source"`
+	Title  string         `json:"title"`
+	Alerts []genericAlert `json:"alerts"`
+}
+
+func buildPayload(t *Target, title string, alerts []Alert) any {
+	switch t.format() {
+	case FormatSlack:
+		return slackPayload{Channel: t.Channel, Text: "*" + title + "*\n" + formatLines(alerts, "•", "\n")}
+	case FormatGoogleChat:
+		return slackPayload{Text: "*" + title + "*\n" + formatLines(alerts, "•", "\n")}
+	case FormatTeamsCard:
+		return buildAdaptiveCard(t, title, alerts)
+	case FormatGoogleChatCard:
+		return buildGoogleChatCard(t, title, alerts)
+	case FormatTeams:
+		color := "FFA000"
+		if maxSeverity(alerts) >= SeverityCritical {
+			color = "D32F2F"
+		}
+		return teamsPayload{
+			Type:       "MessageCard",
+			Context:    "https://schema.org/extensions",
+			Summary:    title,
+			Title:      title,
+			Text:       formatLines(alerts, "-", "\n\n"), // MessageCard markdown needs blank lines
+			ThemeColor: color,
+		}
+	default:
+		p := genericPayload{Source: "kubenow", Title: title}
+		for _, a := range alerts {
+			p.Alerts = append(p.Alerts, genericAlert{Alert: a, Severity: a.Severity.String()})
+		}
+		return p
+	}
+}
+
+// formatLines renders one "[SEVERITY] namespace/name - type: summary" line per alert.
+func formatLines(alerts []Alert, bullet, sep string) string {
+	var sb strings.Builder
+	for i, a := range alerts {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		fmt.Fprintf(&sb, "%s [%s] %s/%s - %s", bullet, strings.ToUpper(a.Severity.String()), a.Namespace, a.Name, a.IssueType)
+		if a.Summary != "" {
+			sb.WriteString(": " + a.Summary)
+		}
+	}
+	return sb.String()
+}
+
+func maxSeverity(alerts []Alert) Severity {
+	var highest Severity
+	for _, a := range alerts {
+		highest = max(highest, a.Severity)
+	}
+	return highest
+}
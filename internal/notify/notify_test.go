@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Severity
+	}{
+		{"low", SeverityLow},
+		{"Medium", SeverityMedium},
+		{"WARNING", SeverityMedium},
+		{"high", SeverityHigh},
+		{"critical", SeverityCritical},
+		{"FATAL", SeverityCritical},
+		{"bogus", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseSeverity(tt.in))
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Target
+		wantErr bool
+	}{
+		{
+			name: "slack default severity",
+			spec: "https://hooks.slack.com/services/T/B/X",
+			want: Target{Kind: KindSlack, URL: "https://hooks.slack.com/services/T/B/X", MinSeverity: SeverityHigh, Channel: "#oncall"},
+		},
+		{
+			name: "teams with severity",
+			spec: "critical=https://contoso.webhook.office.com/webhookb2/abc",
+			want: Target{Kind: KindTeams, URL: "https://contoso.webhook.office.com/webhookb2/abc", MinSeverity: SeverityCritical},
+		},
+		{
+			name: "google chat",
+			spec: "https://chat.googleapis.com/v1/spaces/AAA/messages?key=k",
+			want: Target{Kind: KindGoogleChat, URL: "https://chat.googleapis.com/v1/spaces/AAA/messages?key=k", MinSeverity: SeverityHigh},
+		},
+		{
+			name: "generic with query string",
+			spec: "medium=https://alerts.example.com/hook?token=a=b",
+			want: Target{Kind: KindGeneric, URL: "https://alerts.example.com/hook?token=a=b", MinSeverity: SeverityMedium},
+		},
+		{
+			name: "equals only in query",
+			spec: "https://alerts.example.com/hook?token=x",
+			want: Target{Kind: KindGeneric, URL: "https://alerts.example.com/hook?token=x", MinSeverity: SeverityHigh},
+		},
+		{name: "bad severity", spec: "urgent=https://example.com", wantErr: true},
+		{name: "not a URL", spec: "hooks.slack.com/services/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTarget(tt.spec, "#oncall")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSend_RoutesBySeverity(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		bodies[r.URL.Path] = body
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := &Notifier{Targets: []Target{
+		{Kind: KindSlack, URL: srv.URL + "/slack", MinSeverity: SeverityCritical, Channel: "#oncall"},
+		{Kind: KindTeams, URL: srv.URL + "/teams", MinSeverity: SeverityHigh},
+		{Kind: KindGeneric, URL: srv.URL + "/generic", MinSeverity: SeverityMedium},
+		{Kind: KindGeneric, URL: srv.URL + "/skipped", MinSeverity: SeverityCritical + 1},
+	}}
+	alerts := []Alert{
+		{Severity: SeverityCritical, Namespace: "prod", Name: "api", IssueType: "CrashLoopBackOff", Summary: "panics on start"},
+		{Severity: SeverityHigh, Namespace: "prod", Name: "web", IssueType: "ImagePullBackOff"},
+		{Severity: SeverityMedium, Namespace: "dev", Name: "job", IssueType: "Pending"},
+	}
+
+	sent, err := n.Send(context.Background(), "kubenow: 3 new issue(s)", alerts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, sent)
+
+	slack := bodies["/slack"]
+	assert.Equal(t, "#oncall", slack["channel"])
+	assert.Equal(t, "*kubenow: 3 new issue(s)*\n• [CRITICAL] prod/api - CrashLoopBackOff: panics on start", slack["text"])
+
+	teams := bodies["/teams"]
+	assert.Equal(t, "MessageCard", teams["@type"])
+	assert.Equal(t, "D32F2F", teams["themeColor"])
+	assert.Equal(t, "- [CRITICAL] prod/api - CrashLoopBackOff: panics on start\n\n- [HIGH] prod/web - ImagePullBackOff", teams["text"])
+
+	generic := bodies["/generic"]
+	assert.Equal(t, "kubenow", generic["source"])
+	require.Len(t, generic["alerts"], 3)
+	first := generic["alerts"].([]any)[0].(map[string]any)
+	assert.Equal(t, "critical", first["severity"])
+	assert.Equal(t, "prod", first["namespace"])
+
+	assert.NotContains(t, bodies, "/skipped")
+}
+
+func TestSend_ErrorHidesWebhookPath(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("invalid_token"))
+	}))
+	defer srv.Close()
+
+	n := &Notifier{Targets: []Target{
+		{Kind: KindSlack, URL: srv.URL + "/services/SECRET", MinSeverity: SeverityLow},
+		{Kind: KindGeneric, URL: "http://127.0.0.1:1/hook/SECRET", MinSeverity: SeverityLow},
+	}}
+	sent, err := n.Send(context.Background(), "t", []Alert{{Severity: SeverityHigh, Namespace: "ns", Name: "p", IssueType: "Failed"}})
+	require.Error(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Contains(t, err.Error(), "403")
+	assert.Contains(t, err.Error(), "invalid_token")
+	assert.NotContains(t, err.Error(), "SECRET")
+}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Sink is a webhook destination declared in the watch config file. Unlike
+// --notify-webhook, a sink can choose its payload format and link cards to
+// the exported report.
+type Sink struct {
+	URL         string `yaml:"url"`
+	MinSeverity string `yaml:"min_severity,omitempty"` // default: high
+	Format      string `yaml:"format,omitempty"`       // default: detected from the URL host
+	Channel     string `yaml:"channel,omitempty"`      // Slack channel override
+	ReportURL   string `yaml:"report_url,omitempty"`   // shown as a button on cards
+}
+
+// Target validates the sink and converts it to a notification target.
+func (s *Sink) Target() (Target, error) {
+	t, err := ParseTarget(s.URL, s.Channel)
+	if err != nil {
+		return Target{}, err
+	}
+	if s.MinSeverity != "" {
+		t.MinSeverity = ParseSeverity(s.MinSeverity)
+		if t.MinSeverity == 0 {
+			return Target{}, fmt.Errorf("invalid min_severity %q (use low, medium, high, or critical)", s.MinSeverity)
+		}
+	}
+	if s.Format != "" && !ValidFormat(s.Format) {
+		return Target{}, fmt.Errorf("invalid format %q (use slack, teams, teams-card, google-chat, google-chat-card, or generic)", s.Format)
+	}
+	t.Format = s.Format
+	if s.ReportURL != "" {
+		u, parseErr := url.Parse(s.ReportURL)
+		if parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Target{}, fmt.Errorf("invalid report_url %q", s.ReportURL)
+		}
+		t.ReportURL = s.ReportURL
+	}
+	t.Channel = ""
+	if t.format() == FormatSlack {
+		t.Channel = s.Channel
+	}
+	return t, nil
+}
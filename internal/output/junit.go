@@ -0,0 +1,159 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/models"
+	"github.com/ppiankov/kubenow/internal/result"
+)
+
+// overProvisionedSkew is the CPU skew from which a workload is reported as
+// over-provisioned, in SARIF and JUnit alike.
+const overProvisionedSkew = 2.0
+
+// JUnitTestSuites is the root of a JUnit XML report, in the schema Jenkins
+// and GitLab render in their test report views.
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite groups the test cases of one kind of check.
+type JUnitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Properties []JUnitProperty `xml:"properties>property,omitempty"`
+	Cases      []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitProperty is a name/value pair attached to a suite.
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// JUnitTestCase is one workload, finding, or threshold check.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// JUnitFailure marks a failed test case.
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// add appends a test case and updates the suite counts.
+func (s *JUnitTestSuite) add(tc JUnitTestCase) {
+	s.Cases = append(s.Cases, tc)
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+}
+
+// GenerateJUnitFromRequestsSkew converts requests-skew analysis to JUnit XML:
+// one test case per workload, failing when it is over-provisioned or rated
+// UNSAFE, and one per --fail-on threshold check.
+func GenerateJUnitFromRequestsSkew(result *analyzer.RequestsSkewResult, checks []analyzer.ThresholdCheck, version string) ([]byte, error) {
+	workloads := JUnitTestSuite{
+		Name:       "kubenow.requests-skew",
+		Properties: []JUnitProperty{{Name: "kubenow.version", Value: version}},
+	}
+	for i := range result.Results {
+		w := &result.Results[i]
+		tc := JUnitTestCase{
+			Name:      fmt.Sprintf("%s/%s", w.Type, w.Workload),
+			ClassName: "requests-skew." + w.Namespace,
+			SystemOut: fmt.Sprintf("CPU requests %.2f, P99 usage %.2f, skew %.1fx", w.RequestedCPU, w.P99UsedCPU, w.SkewCPU),
+		}
+		switch {
+		case w.Safety != nil && w.Safety.Rating == models.SafetyRatingUnsafe:
+			tc.Failure = &JUnitFailure{
+				Type:    "unsafe-reduction",
+				Message: "reducing resources would be unsafe",
+				Text:    strings.Join(append(append([]string{}, w.Safety.Reasons...), w.Safety.Warnings...), "\n"),
+			}
+		case w.SkewCPU >= overProvisionedSkew:
+			tc.Failure = &JUnitFailure{
+				Type:    "over-provisioned-cpu",
+				Message: fmt.Sprintf("CPU requests (%.2f) exceed P99 usage (%.2f) by %.1fx", w.RequestedCPU, w.P99UsedCPU, w.SkewCPU),
+			}
+		}
+		workloads.add(tc)
+	}
+
+	suites := []JUnitTestSuite{workloads}
+	if len(checks) > 0 {
+		thresholds := JUnitTestSuite{Name: "kubenow.thresholds"}
+		for _, c := range checks {
+			tc := JUnitTestCase{
+				Name:      c.Threshold,
+				ClassName: "thresholds",
+				SystemOut: fmt.Sprintf("actual %g", c.Actual),
+			}
+			if c.Context != "" {
+				tc.ClassName += "." + c.Context
+			}
+			if c.Failed {
+				tc.Failure = &JUnitFailure{Type: "threshold", Message: fmt.Sprintf("%s crossed: actual %g", c.Threshold, c.Actual)}
+			}
+			thresholds.add(tc)
+		}
+		suites = append(suites, thresholds)
+	}
+	return marshalJUnit(suites)
+}
+
+// GenerateJUnitFromCompliance converts compliance issues to JUnit XML: each
+// issue is a failed test case, and a clean run is one passing case.
+func GenerateJUnitFromCompliance(cr *result.ComplianceResult, version string) ([]byte, error) {
+	suite := JUnitTestSuite{
+		Name:       "kubenow.compliance",
+		Properties: []JUnitProperty{{Name: "kubenow.version", Value: version}},
+	}
+	for i := range cr.Issues {
+		issue := &cr.Issues[i]
+		text := issue.Description
+		if issue.Recommendation != "" {
+			text += "\nRecommendation: " + issue.Recommendation
+		}
+		suite.add(JUnitTestCase{
+			Name:      fmt.Sprintf("%s %s", issue.Type, issue.Name),
+			ClassName: "compliance." + issue.Namespace,
+			Failure: &JUnitFailure{
+				Type:    complianceRuleID(issue.Type),
+				Message: fmt.Sprintf("%s (%s)", issue.Type, issue.Severity),
+				Text:    strings.TrimSpace(text),
+			},
+		})
+	}
+	if len(cr.Issues) == 0 {
+		suite.add(JUnitTestCase{Name: "no compliance issues", ClassName: "compliance"})
+	}
+	return marshalJUnit([]JUnitTestSuite{suite})
+}
+
+func marshalJUnit(suites []JUnitTestSuite) ([]byte, error) {
+	root := JUnitTestSuites{Name: "kubenow", Suites: suites}
+	for _, s := range suites {
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+	}
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/models"
+	"github.com/ppiankov/kubenow/internal/result"
+)
+
+func TestGenerateJUnitFromRequestsSkew(t *testing.T) {
+	skew := &analyzer.RequestsSkewResult{
+		Results: []analyzer.WorkloadSkewAnalysis{
+			{Namespace: "prod", Workload: "api", Type: "Deployment", RequestedCPU: 4, P99UsedCPU: 1, SkewCPU: 4},
+			{Namespace: "prod", Workload: "web", Type: "Deployment", SkewCPU: 1.2},
+			{Namespace: "prod", Workload: "db", Type: "StatefulSet", SkewCPU: 1.5,
+				Safety: &models.SafetyAnalysis{Rating: models.SafetyRatingUnsafe, Reasons: []string{"2 OOMKills"}}},
+		},
+	}
+	checks := []analyzer.ThresholdCheck{
+		{Threshold: "avg-skew-cpu>5", Actual: 2.2},
+		{Threshold: "wasted-cpu>1", Actual: 3, Failed: true},
+	}
+
+	data, err := GenerateJUnitFromRequestsSkew(skew, checks, "1.0.0")
+	require.NoError(t, err)
+
+	var report JUnitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+	assert.Equal(t, 5, report.Tests)
+	assert.Equal(t, 3, report.Failures)
+	require.Len(t, report.Suites, 2)
+
+	workloads := report.Suites[0]
+	require.Len(t, workloads.Cases, 3)
+	assert.Equal(t, "Deployment/api", workloads.Cases[0].Name)
+	assert.Equal(t, "requests-skew.prod", workloads.Cases[0].ClassName)
+	require.NotNil(t, workloads.Cases[0].Failure)
+	assert.Equal(t, "over-provisioned-cpu", workloads.Cases[0].Failure.Type)
+	assert.Nil(t, workloads.Cases[1].Failure)
+	require.NotNil(t, workloads.Cases[2].Failure)
+	assert.Equal(t, "unsafe-reduction", workloads.Cases[2].Failure.Type)
+	assert.Contains(t, workloads.Cases[2].Failure.Text, "2 OOMKills")
+
+	thresholds := report.Suites[1]
+	assert.Equal(t, 2, thresholds.Tests)
+	assert.Equal(t, 1, thresholds.Failures)
+	assert.Nil(t, thresholds.Cases[0].Failure)
+	assert.NotNil(t, thresholds.Cases[1].Failure)
+}
+
+func TestGenerateJUnitFromCompliance(t *testing.T) {
+	data, err := GenerateJUnitFromCompliance(&result.ComplianceResult{Issues: []result.ComplianceIssue{
+		{Namespace: "prod", Name: "api", Type: "MissingLimits", Severity: "high", Recommendation: "set limits"},
+	}}, "1.0.0")
+	require.NoError(t, err)
+
+	var report JUnitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+	assert.Equal(t, 1, report.Failures)
+	require.NotNil(t, report.Suites[0].Cases[0].Failure)
+	assert.Contains(t, report.Suites[0].Cases[0].Failure.Text, "Recommendation: set limits")
+
+	data, err = GenerateJUnitFromCompliance(&result.ComplianceResult{}, "1.0.0")
+	require.NoError(t, err)
+	require.NoError(t, xml.Unmarshal(data, &report))
+	assert.Equal(t, 1, report.Tests, "a clean run is one passing case")
+	assert.Zero(t, report.Failures)
+}
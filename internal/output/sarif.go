@@ -4,14 +4,20 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/models"
 	"github.com/ppiankov/kubenow/internal/monitor"
+	"github.com/ppiankov/kubenow/internal/result"
 )
 
 const severityError = "error"
 
+// sarifSchema is the SARIF 2.1.0 JSON schema every report references.
+const sarifSchema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+
 // SARIF represents the SARIF 2.1.0 format for static analysis results
 // Spec: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
 type SARIF struct {
@@ -89,7 +95,7 @@ type MessageString struct {
 // GenerateSARIFFromRequestsSkew converts requests-skew analysis to SARIF format
 func GenerateSARIFFromRequestsSkew(result *analyzer.RequestsSkewResult, version string) ([]byte, error) {
 	sarif := SARIF{
-		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Schema:  sarifSchema,
 		Version: "2.1.0",
 		Runs: []Run{
 			{
@@ -113,7 +119,7 @@ func GenerateSARIFFromRequestsSkew(result *analyzer.RequestsSkewResult, version
 // GenerateSARIFFromMonitor converts monitor problems to SARIF format
 func GenerateSARIFFromMonitor(problems []monitor.Problem, version string) ([]byte, error) {
 	sarif := SARIF{
-		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Schema:  sarifSchema,
 		Version: "2.1.0",
 		Runs: []Run{
 			{
@@ -134,6 +140,31 @@ func GenerateSARIFFromMonitor(problems []monitor.Problem, version string) ([]byt
 	return json.MarshalIndent(sarif, "", "  ")
 }
 
+// GenerateSARIFFromCompliance converts compliance issues, from the LLM or the
+// deterministic ratio audit, to SARIF format. Each issue type is a rule.
+func GenerateSARIFFromCompliance(cr *result.ComplianceResult, version string) ([]byte, error) {
+	sarif := SARIF{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []Run{
+			{
+				Tool: Tool{
+					Driver: Driver{
+						Name:            "kubenow",
+						Version:         version,
+						InformationURI:  "https://github.com/ppiankov/kubenow",
+						SemanticVersion: version,
+						Rules:           generateComplianceRules(cr.Issues),
+					},
+				},
+				Results: convertComplianceToResults(cr.Issues),
+			},
+		},
+	}
+
+	return json.MarshalIndent(sarif, "", "  ")
+}
+
 func generateRequestsSkewRules() []Rule {
 	return []Rule{
 		{
@@ -150,6 +181,20 @@ func generateRequestsSkewRules() []Rule {
 			},
 			DefaultLevel: "warning",
 		},
+		{
+			ID:   "risky-reduction",
+			Name: "Risky Resource Reduction",
+			ShortDescription: MessageString{
+				Text: "Reducing resources needs review based on usage patterns",
+			},
+			FullDescription: MessageString{
+				Text: "This workload has safety warnings, such as restarts, CPU throttling, or spikes, that make a resource reduction risky.",
+			},
+			Help: MessageString{
+				Text: "Review the safety warnings and usage over a longer window before reducing resources; keep a larger safety margin.",
+			},
+			DefaultLevel: "warning",
+		},
 		{
 			ID:   "unsafe-reduction",
 			Name: "Unsafe Resource Reduction",
@@ -233,18 +278,20 @@ func convertRequestsSkewToResults(result *analyzer.RequestsSkewResult) []Result
 
 	for i := range result.Results {
 		w := &result.Results[i]
-		// Skip if no significant over-provisioning
-		if w.SkewCPU < 2.0 {
-			continue
-		}
-
 		level := "warning"
 		ruleID := "over-provisioned-cpu"
 
-		// Check if reduction would be unsafe
-		if w.Safety != nil && w.Safety.Rating == "UNSAFE" {
+		// Safety warnings are reported whatever the skew, since they are
+		// what makes a reduction unsafe
+		switch {
+		case w.Safety != nil && w.Safety.Rating == models.SafetyRatingUnsafe:
 			level = severityError
 			ruleID = "unsafe-reduction"
+		case w.Safety != nil && w.Safety.Rating == models.SafetyRatingRisky:
+			ruleID = "risky-reduction"
+		case w.SkewCPU < overProvisionedSkew:
+			// Skip if no significant over-provisioning
+			continue
 		}
 
 		message := fmt.Sprintf("Workload %s/%s: CPU requests (%.2f) exceed P99 usage (%.2f) by %.1fx",
@@ -252,6 +299,9 @@ func convertRequestsSkewToResults(result *analyzer.RequestsSkewResult) []Result
 
 		if w.Safety != nil {
 			message += fmt.Sprintf(" | Safety: %s", w.Safety.Rating)
+			if len(w.Safety.Warnings) > 0 {
+				message += " (" + strings.Join(w.Safety.Warnings, "; ") + ")"
+			}
 		}
 
 		result := Result{
@@ -285,6 +335,9 @@ func convertRequestsSkewToResults(result *analyzer.RequestsSkewResult) []Result
 			result.Properties["safety_rating"] = w.Safety.Rating
 			result.Properties["oom_kills"] = w.Safety.OOMKills
 			result.Properties["restarts"] = w.Safety.Restarts
+			if len(w.Safety.Warnings) > 0 {
+				result.Properties["safety_warnings"] = w.Safety.Warnings
+			}
 		}
 
 		results = append(results, result)
@@ -293,6 +346,83 @@ func convertRequestsSkewToResults(result *analyzer.RequestsSkewResult) []Result
 	return results
 }
 
+// complianceRuleID derives a stable rule ID from a compliance issue type,
+// e.g. "ResourceRatio" or "missing limits" -> compliance/resourceratio,
+// compliance/missing-limits.
+func complianceRuleID(issueType string) string {
+	slug := strings.Join(strings.Fields(strings.ToLower(issueType)), "-")
+	if slug == "" {
+		slug = "issue"
+	}
+	return "compliance/" + slug
+}
+
+func generateComplianceRules(issues []result.ComplianceIssue) []Rule {
+	rules := make([]Rule, 0)
+	seen := make(map[string]bool)
+	for i := range issues {
+		id := complianceRuleID(issues[i].Type)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		name := issues[i].Type
+		if name == "" {
+			name = "Compliance issue"
+		}
+		rules = append(rules, Rule{
+			ID:               id,
+			Name:             name,
+			ShortDescription: MessageString{Text: fmt.Sprintf("Compliance issue: %s", name)},
+			FullDescription:  MessageString{Text: fmt.Sprintf("Workloads that violate the %s compliance check.", name)},
+			Help:             MessageString{Text: "See each result's recommendation for the fix."},
+			DefaultLevel:     getSARIFLevelForComplianceSeverity(issues[i].Severity),
+		})
+	}
+	return rules
+}
+
+func convertComplianceToResults(issues []result.ComplianceIssue) []Result {
+	results := make([]Result, 0)
+
+	for i := range issues {
+		issue := &issues[i]
+		message := fmt.Sprintf("%s in %s/%s", issue.Type, issue.Namespace, issue.Name)
+		if issue.Description != "" {
+			message += fmt.Sprintf(": %s", issue.Description)
+		}
+		if issue.Recommendation != "" {
+			message += fmt.Sprintf(" | Recommendation: %s", issue.Recommendation)
+		}
+
+		results = append(results, Result{
+			RuleID: complianceRuleID(issue.Type),
+			Level:  getSARIFLevelForComplianceSeverity(issue.Severity),
+			Message: MessageString{
+				Text: message,
+			},
+			Locations: []Location{
+				{
+					PhysicalLocation: PhysicalLocation{
+						ArtifactLocation: ArtifactLocation{
+							URI: fmt.Sprintf("kubernetes://%s/%s", issue.Namespace, issue.Name),
+						},
+					},
+				},
+			},
+			Properties: map[string]interface{}{
+				"namespace": issue.Namespace,
+				"name":      issue.Name,
+				"type":      issue.Type,
+				"severity":  issue.Severity,
+			},
+		})
+	}
+
+	return results
+}
+
 func convertMonitorToResults(problems []monitor.Problem) []Result {
 	results := make([]Result, 0)
 
@@ -305,6 +435,9 @@ func convertMonitorToResults(problems []monitor.Problem) []Result {
 		if p.ContainerName != "" {
 			message += fmt.Sprintf(" (container: %s)", p.ContainerName)
 		}
+		if p.SubReason != "" {
+			message += fmt.Sprintf(" [%s]", p.SubReason)
+		}
 		if p.Message != "" {
 			message += fmt.Sprintf(": %s", p.Message)
 		}
@@ -335,6 +468,9 @@ func convertMonitorToResults(problems []monitor.Problem) []Result {
 				"last_seen":  p.LastSeen.Format(time.RFC3339),
 			},
 		}
+		if p.SubReason != "" {
+			result.Properties["sub_reason"] = p.SubReason
+		}
 
 		results = append(results, result)
 	}
@@ -369,3 +505,16 @@ func getSARIFLevelForSeverity(severity monitor.Severity) string {
 		return "note"
 	}
 }
+
+// getSARIFLevelForComplianceSeverity maps the compliance prompt's
+// critical|high|medium|low (and the ratio audit's warning) to SARIF levels.
+func getSARIFLevelForComplianceSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return severityError
+	case "medium", "warning":
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ppiankov/kubenow/internal/analyzer"
 	"github.com/ppiankov/kubenow/internal/models"
 	"github.com/ppiankov/kubenow/internal/monitor"
+	"github.com/ppiankov/kubenow/internal/result"
 )
 
 func TestGenerateSARIFFromRequestsSkew_ValidJSON(t *testing.T) {
@@ -70,6 +71,55 @@ func TestGenerateSARIFFromMonitor_ValidJSON(t *testing.T) {
 	assert.Equal(t, "pod-oomkilled", sarif.Runs[0].Results[0].RuleID)
 }
 
+func TestGenerateSARIFFromRequestsSkew_SafetyWarnings(t *testing.T) {
+	result := &analyzer.RequestsSkewResult{
+		Results: []analyzer.WorkloadSkewAnalysis{
+			{Namespace: "prod", Workload: "steady", Type: "Deployment", SkewCPU: 1.2,
+				Safety: &models.SafetyAnalysis{Rating: models.SafetyRatingSafe}},
+			{Namespace: "prod", Workload: "spiky", Type: "Deployment", SkewCPU: 1.1,
+				Safety: &models.SafetyAnalysis{Rating: models.SafetyRatingRisky, Warnings: []string{"CPU throttled 30% of periods"}}},
+			{Namespace: "prod", Workload: "oom", Type: "StatefulSet", SkewCPU: 4,
+				Safety: &models.SafetyAnalysis{Rating: models.SafetyRatingUnsafe, OOMKills: 2}},
+		},
+	}
+
+	data, err := GenerateSARIFFromRequestsSkew(result, "1.0.0")
+	require.NoError(t, err)
+
+	var sarif SARIF
+	require.NoError(t, json.Unmarshal(data, &sarif))
+	results := sarif.Runs[0].Results
+	require.Len(t, results, 2, "low skew is only reported with a safety warning")
+	assert.Equal(t, "risky-reduction", results[0].RuleID)
+	assert.Equal(t, "warning", results[0].Level)
+	assert.Contains(t, results[0].Message.Text, "CPU throttled 30% of periods")
+	assert.Equal(t, "unsafe-reduction", results[1].RuleID)
+	assert.Equal(t, "error", results[1].Level)
+}
+
+func TestGenerateSARIFFromCompliance(t *testing.T) {
+	cr := &result.ComplianceResult{Issues: []result.ComplianceIssue{
+		{Namespace: "prod", Name: "api", Type: "Missing Limits", Severity: "critical", Recommendation: "set limits"},
+		{Namespace: "dev", Name: "web", Type: "Missing Limits", Severity: "low"},
+		{Namespace: "prod", Name: "db", Type: "ResourceRatio", Severity: "warning"},
+	}}
+
+	data, err := GenerateSARIFFromCompliance(cr, "1.0.0")
+	require.NoError(t, err)
+
+	var sarif SARIF
+	require.NoError(t, json.Unmarshal(data, &sarif))
+	run := sarif.Runs[0]
+	require.Len(t, run.Tool.Driver.Rules, 2, "one rule per issue type")
+	assert.Equal(t, "compliance/missing-limits", run.Tool.Driver.Rules[0].ID)
+	require.Len(t, run.Results, 3)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "note", run.Results[1].Level)
+	assert.Equal(t, "warning", run.Results[2].Level)
+	assert.Contains(t, run.Results[0].Message.Text, "Recommendation: set limits")
+	assert.Equal(t, "kubernetes://prod/api", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
 func TestSARIF_StructureSerialization(t *testing.T) {
 	s := SARIF{
 		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
@@ -0,0 +1,160 @@
+// Package owners loads an owners file mapping namespaces and label
+// selectors to the teams that own them, so reports can be split and routed
+// per team.
+package owners
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Unowned names the team of workloads and pods no rule matches.
+const Unowned = "(unowned)"
+
+// Rule assigns a team to the workloads it matches. Namespaces are glob
+// patterns ("*" and "?"); Selector is a Kubernetes label selector matched
+// against workload or pod labels. When both are set, both must match.
+type Rule struct {
+	Team       string   `yaml:"team"`
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	Selector   string   `yaml:"selector,omitempty"`
+	Contact    string   `yaml:"contact,omitempty"` // where to route the team's findings, e.g. a Slack channel
+
+	selector labels.Selector
+}
+
+// File is an owners file. Rules are matched in order; the first match
+// wins, and Default owns what no rule matches.
+type File struct {
+	Owners  []Rule `yaml:"owners"`
+	Default string `yaml:"default,omitempty"`
+}
+
+// Assignment lists the problem pods one team owns in a report.
+type Assignment struct {
+	Team    string   `json:"team"`
+	Contact string   `json:"contact,omitempty"`
+	Pods    []string `json:"pods"` // namespace/pod
+}
+
+// Load reads and validates an owners file.
+func Load(file string) (*File, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read owners file: %w", err)
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse owners file %s: %w", file, err)
+	}
+	for i := range f.Owners {
+		if err := f.Owners[i].compile(); err != nil {
+			return nil, fmt.Errorf("owner rule %d in %s: %w", i+1, file, err)
+		}
+	}
+	return &f, nil
+}
+
+func (r *Rule) compile() error {
+	if strings.TrimSpace(r.Team) == "" {
+		return fmt.Errorf("team is required")
+	}
+	if len(r.Namespaces) == 0 && r.Selector == "" {
+		return fmt.Errorf("namespaces or selector is required")
+	}
+	for _, pattern := range r.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+		}
+	}
+	if r.Selector != "" {
+		selector, err := labels.Parse(r.Selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector %q: %w", r.Selector, err)
+		}
+		r.selector = selector
+	}
+	return nil
+}
+
+// Matches reports whether the rule covers a workload in namespace with the
+// given labels.
+func (r *Rule) Matches(namespace string, lbls map[string]string) bool {
+	if len(r.Namespaces) > 0 && !matchesAny(r.Namespaces, namespace) {
+		return false
+	}
+	return r.selector == nil || r.selector.Matches(labels.Set(lbls))
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Team returns the team owning a workload in namespace with the given
+// labels, or "" when no rule matches and there is no default. A nil file
+// owns nothing.
+func (f *File) Team(namespace string, lbls map[string]string) string {
+	if f == nil {
+		return ""
+	}
+	for i := range f.Owners {
+		if f.Owners[i].Matches(namespace, lbls) {
+			return f.Owners[i].Team
+		}
+	}
+	return f.Default
+}
+
+// Contact returns the contact of the first rule naming team, or "".
+func (f *File) Contact(team string) string {
+	if f == nil {
+		return ""
+	}
+	for i := range f.Owners {
+		if f.Owners[i].Team == team && f.Owners[i].Contact != "" {
+			return f.Owners[i].Contact
+		}
+	}
+	return ""
+}
+
+// Assign groups namespace/pod locations by team, sorted by team with
+// Unowned last. teams holds the team of each location ("" = unowned).
+func (f *File) Assign(locations, teams []string) []Assignment {
+	byTeam := map[string]*Assignment{}
+	for i, location := range locations {
+		team := teams[i]
+		if team == "" {
+			team = Unowned
+		}
+		a, ok := byTeam[team]
+		if !ok {
+			a = &Assignment{Team: team, Contact: f.Contact(team)}
+			byTeam[team] = a
+		}
+		a.Pods = append(a.Pods, location)
+	}
+
+	assignments := make([]Assignment, 0, len(byTeam))
+	for _, a := range byTeam {
+		assignments = append(assignments, *a)
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		if (assignments[i].Team == Unowned) != (assignments[j].Team == Unowned) {
+			return assignments[j].Team == Unowned
+		}
+		return assignments[i].Team < assignments[j].Team
+	})
+	return assignments
+}
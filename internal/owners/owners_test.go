@@ -0,0 +1,68 @@
+package owners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeOwners(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "owners.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func TestLoadAndTeam(t *testing.T) {
+	f, err := Load(writeOwners(t, `
+default: platform
+owners:
+  - team: streaming
+    selector: app.kubernetes.io/part-of=kafka
+  - team: payments
+    contact: "#payments-oncall"
+    namespaces: [payments, "checkout-*"]
+  - team: payments-batch
+    namespaces: [payments]
+    selector: tier=batch
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "streaming", f.Team("data", map[string]string{"app.kubernetes.io/part-of": "kafka"}))
+	assert.Equal(t, "payments", f.Team("checkout-eu", nil))
+	// First match wins: the namespace rule precedes the narrower one
+	assert.Equal(t, "payments", f.Team("payments", map[string]string{"tier": "batch"}))
+	assert.Equal(t, "platform", f.Team("web", nil))
+	assert.Equal(t, "#payments-oncall", f.Contact("payments"))
+	assert.Empty(t, f.Contact("platform"))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no team":      "owners:\n  - namespaces: [a]\n",
+		"no matcher":   "owners:\n  - team: a\n",
+		"bad pattern":  "owners:\n  - team: a\n    namespaces: [\"[\"]\n",
+		"bad selector": "owners:\n  - team: a\n    selector: \"a in (b\"\n",
+	} {
+		_, err := Load(writeOwners(t, content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNilFile(t *testing.T) {
+	var f *File
+	assert.Empty(t, f.Team("payments", nil))
+	assert.Empty(t, f.Contact("payments"))
+}
+
+func TestAssign(t *testing.T) {
+	f := &File{Owners: []Rule{{Team: "web", Contact: "#web"}}}
+	got := f.Assign([]string{"web/api-1", "batch/job-1", "web/api-2"}, []string{"web", "", "web"})
+	assert.Equal(t, []Assignment{
+		{Team: "web", Contact: "#web", Pods: []string{"web/api-1", "web/api-2"}},
+		{Team: Unowned, Pods: []string{"batch/job-1"}},
+	}, got)
+}
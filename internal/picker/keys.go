@@ -0,0 +1,37 @@
+package picker
+
+import "github.com/ppiankov/kubenow/internal/keymap"
+
+// Picker actions, as named in the keybindings.picker config section. The
+// confirmation screen (enter/y to run, esc/n to go back) and search input
+// are not remappable.
+const (
+	actionQuit   keymap.Action = "quit"
+	actionUp     keymap.Action = "up"
+	actionDown   keymap.Action = "down"
+	actionToggle keymap.Action = "toggle"
+	actionAll    keymap.Action = "select-all"
+	actionNone   keymap.Action = "select-none"
+	actionPane   keymap.Action = "switch-pane"
+	actionSearch keymap.Action = "search"
+	actionRun    keymap.Action = "run"
+)
+
+// DefaultKeyBindings are the picker's bindings before config overrides.
+var DefaultKeyBindings = []keymap.Binding{
+	{Action: actionQuit, Keys: []string{"q", "esc"}, Description: "quit without running"},
+	{Action: actionUp, Keys: []string{"up", "k"}, Description: "move up"},
+	{Action: actionDown, Keys: []string{"down", "j"}, Description: "move down"},
+	{Action: actionToggle, Keys: []string{" ", "x"}, Description: "check/uncheck"},
+	{Action: actionAll, Keys: []string{"a"}, Description: "check all shown"},
+	{Action: actionNone, Keys: []string{"n"}, Description: "uncheck all shown"},
+	{Action: actionPane, Keys: []string{"tab"}, Description: "switch between namespaces and workload kinds"},
+	{Action: actionSearch, Keys: []string{"/"}, Description: "filter namespaces by name"},
+	{Action: actionRun, Keys: []string{"enter"}, Description: "review the estimate and run"},
+	{Action: keymap.Help, Keys: []string{"?"}, Description: "toggle this help"},
+}
+
+// NewKeyMap builds the picker key map with config overrides applied.
+func NewKeyMap(overrides map[string][]string) (*keymap.Map, error) {
+	return keymap.New(DefaultKeyBindings, overrides)
+}
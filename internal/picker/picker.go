@@ -0,0 +1,416 @@
+// Package picker is the interactive namespace and workload kind selection
+// shown before an analysis runs.
+package picker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ppiankov/kubenow/internal/keymap"
+)
+
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	cursorStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	warnStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+	boxStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
+)
+
+// Namespace is one pickable namespace with its pod and workload counts.
+type Namespace struct {
+	Name      string
+	Pods      int
+	Workloads map[string]int // kind -> count
+}
+
+// Selection is what the user checked.
+type Selection struct {
+	Namespaces []string
+	Kinds      []string
+}
+
+// Workloads returns the number of selected-kind workloads in the selected
+// namespaces.
+func (s Selection) Workloads(namespaces []Namespace) int {
+	picked := make(map[string]bool, len(s.Namespaces))
+	for _, ns := range s.Namespaces {
+		picked[ns] = true
+	}
+	total := 0
+	for i := range namespaces {
+		if picked[namespaces[i].Name] {
+			total += countKinds(&namespaces[i], s.Kinds)
+		}
+	}
+	return total
+}
+
+// EstimateFunc describes the cost of running a selection, e.g. "~420
+// queries, ~1m".
+type EstimateFunc func(Selection) string
+
+const (
+	paneNamespaces = iota
+	paneKinds
+)
+
+// reservedLines is the screen height taken by everything but the
+// namespace list.
+const reservedLines = 12
+
+// Model is the picker's bubbletea model. Namespaces start unchecked and
+// kinds checked.
+type Model struct {
+	namespaces  []Namespace
+	kinds       []string
+	nsChecked   map[string]bool
+	kindChecked map[string]bool
+	estimate    EstimateFunc
+	keys        *keymap.Map
+
+	pane       int
+	cursor     int // index into visible()
+	kindCursor int
+	offset     int // first visible namespace row
+	height     int
+
+	searchMode bool
+	filter     string
+	confirming bool
+	showHelp   bool
+	status     string
+	confirmed  bool
+}
+
+// NewModel creates a picker over namespaces and workload kinds.
+func NewModel(namespaces []Namespace, kinds []string, estimate EstimateFunc) *Model {
+	sorted := make([]Namespace, len(namespaces))
+	copy(sorted, namespaces)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	m := &Model{
+		namespaces:  sorted,
+		kinds:       kinds,
+		nsChecked:   make(map[string]bool),
+		kindChecked: make(map[string]bool, len(kinds)),
+		estimate:    estimate,
+		keys:        keymap.MustDefault(DefaultKeyBindings),
+	}
+	for _, k := range kinds {
+		m.kindChecked[k] = true
+	}
+	return m
+}
+
+// SetKeyMap replaces the default key bindings.
+func (m *Model) SetKeyMap(keys *keymap.Map) {
+	m.keys = keys
+}
+
+// SetKinds checks only the given kinds, e.g. from a command-line filter.
+func (m *Model) SetKinds(kinds []string) {
+	for _, k := range m.kinds {
+		m.kindChecked[k] = false
+	}
+	for _, k := range kinds {
+		if _, ok := m.kindChecked[k]; ok {
+			m.kindChecked[k] = true
+		}
+	}
+}
+
+// Selection returns the checked namespaces and kinds, and whether the user
+// confirmed the run.
+func (m *Model) Selection() (Selection, bool) {
+	return m.selection(), m.confirmed
+}
+
+func (m *Model) selection() Selection {
+	var sel Selection
+	for i := range m.namespaces {
+		if m.nsChecked[m.namespaces[i].Name] {
+			sel.Namespaces = append(sel.Namespaces, m.namespaces[i].Name)
+		}
+	}
+	for _, k := range m.kinds {
+		if m.kindChecked[k] {
+			sel.Kinds = append(sel.Kinds, k)
+		}
+	}
+	return sel
+}
+
+// visible returns the indexes of the namespaces matching the filter.
+func (m *Model) visible() []int {
+	idx := make([]int, 0, len(m.namespaces))
+	for i := range m.namespaces {
+		if m.filter == "" || strings.Contains(m.namespaces[i].Name, m.filter) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.clampScroll()
+	case tea.KeyMsg:
+		key := msg.String()
+		if key == keymap.ForceQuit {
+			return m, tea.Quit
+		}
+		switch {
+		case m.searchMode:
+			m.handleSearchKey(msg)
+			return m, nil
+		case m.confirming:
+			switch key {
+			case "enter", "y":
+				m.confirmed = true
+				return m, tea.Quit
+			case "esc", "n":
+				m.confirming = false
+			}
+			return m, nil
+		case m.showHelp:
+			// Any key closes the help overlay; quit still quits.
+			m.showHelp = false
+			if m.keys.Action(key) != actionQuit {
+				return m, nil
+			}
+		}
+		return m.handleAction(m.keys.Action(key))
+	}
+	return m, nil
+}
+
+func (m *Model) handleSearchKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.searchMode = false
+		m.filter = ""
+	case tea.KeyEnter:
+		m.searchMode = false
+	case tea.KeyBackspace:
+		if m.filter != "" {
+			m.filter = m.filter[:len(m.filter)-1]
+		}
+	case tea.KeyRunes:
+		m.filter += string(msg.Runes)
+	}
+	m.cursor = 0
+	m.offset = 0
+}
+
+// handleAction runs a key binding's action.
+func (m *Model) handleAction(action keymap.Action) (tea.Model, tea.Cmd) {
+	m.status = ""
+	visible := m.visible()
+	switch action {
+	case actionQuit:
+		return m, tea.Quit
+	case keymap.Help:
+		m.showHelp = true
+	case actionSearch:
+		m.searchMode = true
+		m.pane = paneNamespaces
+	case actionPane:
+		m.pane = (m.pane + 1) % 2
+	case actionUp:
+		if m.pane == paneKinds {
+			m.kindCursor = max(m.kindCursor-1, 0)
+		} else {
+			m.cursor = max(m.cursor-1, 0)
+		}
+	case actionDown:
+		if m.pane == paneKinds {
+			m.kindCursor = min(m.kindCursor+1, len(m.kinds)-1)
+		} else {
+			m.cursor = max(min(m.cursor+1, len(visible)-1), 0)
+		}
+	case actionToggle:
+		if m.pane == paneKinds {
+			if m.kindCursor < len(m.kinds) {
+				k := m.kinds[m.kindCursor]
+				m.kindChecked[k] = !m.kindChecked[k]
+			}
+		} else if m.cursor < len(visible) {
+			name := m.namespaces[visible[m.cursor]].Name
+			m.nsChecked[name] = !m.nsChecked[name]
+		}
+	case actionAll, actionNone:
+		checked := action == actionAll
+		if m.pane == paneKinds {
+			for _, k := range m.kinds {
+				m.kindChecked[k] = checked
+			}
+		} else {
+			for _, i := range visible {
+				m.nsChecked[m.namespaces[i].Name] = checked
+			}
+		}
+	case actionRun:
+		sel := m.selection()
+		switch {
+		case len(sel.Namespaces) == 0:
+			m.status = "check at least one namespace"
+		case len(sel.Kinds) == 0:
+			m.status = "check at least one workload kind"
+		default:
+			m.confirming = true
+		}
+	}
+	m.clampScroll()
+	return m, nil
+}
+
+// listHeight is the number of namespace rows that fit on screen.
+func (m *Model) listHeight() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-reservedLines-len(m.kinds), 3)
+}
+
+func (m *Model) clampScroll() {
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+}
+
+// View implements tea.Model.
+func (m *Model) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Select namespaces and workload kinds to analyze"))
+	b.WriteString("\n\n")
+
+	if m.showHelp {
+		b.WriteString(titleStyle.Render("Key bindings"))
+		b.WriteString("\n")
+		for _, line := range m.keys.HelpLines() {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n" + dimStyle.Render("Remap in the keybindings.picker section of ~/.kubenow/config.yaml. Press any key to close.") + "\n")
+		return boxStyle.Render(b.String())
+	}
+
+	sel := m.selection()
+	if m.confirming {
+		workloads := sel.Workloads(m.namespaces)
+		fmt.Fprintf(&b, "Namespaces (%d): %s\n", len(sel.Namespaces), strings.Join(sel.Namespaces, ", "))
+		fmt.Fprintf(&b, "Kinds: %s\n", strings.Join(sel.Kinds, ", "))
+		fmt.Fprintf(&b, "Workloads: %d\n", workloads)
+		if m.estimate != nil {
+			fmt.Fprintf(&b, "Estimate: %s\n", m.estimate(sel))
+		}
+		b.WriteString("\n" + titleStyle.Render("Run the analysis? [enter/y] run  [esc/n] back") + "\n")
+		return boxStyle.Render(b.String())
+	}
+
+	m.renderNamespaces(&b, sel.Kinds)
+	b.WriteString("\n")
+	m.renderKinds(&b, sel)
+
+	b.WriteString("\n")
+	summary := fmt.Sprintf("%d/%d namespaces, %d workloads", len(sel.Namespaces), len(m.namespaces), sel.Workloads(m.namespaces))
+	if m.estimate != nil && len(sel.Namespaces) > 0 && len(sel.Kinds) > 0 {
+		summary += " · " + m.estimate(sel)
+	}
+	b.WriteString(summary + "\n")
+	if m.status != "" {
+		b.WriteString(warnStyle.Render(m.status) + "\n")
+	}
+	if m.searchMode {
+		b.WriteString("/" + m.filter + "█\n")
+	} else {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("%s check · %s all · %s none · %s switch pane · %s filter · %s run · %s help · %s quit",
+			m.keys.Label(actionToggle), m.keys.Label(actionAll), m.keys.Label(actionNone), m.keys.Label(actionPane),
+			m.keys.Label(actionSearch), m.keys.Label(actionRun), m.keys.Label(keymap.Help), m.keys.Label(actionQuit))) + "\n")
+	}
+	return boxStyle.Render(b.String())
+}
+
+func (m *Model) renderNamespaces(b *strings.Builder, kinds []string) {
+	visible := m.visible()
+	header := "Namespaces"
+	if m.filter != "" {
+		header += fmt.Sprintf(" matching %q (%d)", m.filter, len(visible))
+	}
+	b.WriteString(m.paneTitle(paneNamespaces, header) + "\n")
+	if len(visible) == 0 {
+		b.WriteString(dimStyle.Render("  (none)") + "\n")
+		return
+	}
+
+	width := len("namespace")
+	for _, i := range visible {
+		width = max(width, len(m.namespaces[i].Name))
+	}
+	end := min(m.offset+m.listHeight(), len(visible))
+	if m.offset > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ↑ %d more", m.offset)) + "\n")
+	}
+	for row := m.offset; row < end; row++ {
+		ns := &m.namespaces[visible[row]]
+		line := fmt.Sprintf("%s %-*s %5d pods %5d workloads", checkbox(m.nsChecked[ns.Name]), width, ns.Name, ns.Pods, countKinds(ns, kinds))
+		b.WriteString(m.row(paneNamespaces, row == m.cursor, line) + "\n")
+	}
+	if end < len(visible) {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ↓ %d more", len(visible)-end)) + "\n")
+	}
+}
+
+func (m *Model) renderKinds(b *strings.Builder, sel Selection) {
+	b.WriteString(m.paneTitle(paneKinds, "Workload kinds") + "\n")
+	all := Selection{Namespaces: sel.Namespaces}
+	for i, k := range m.kinds {
+		all.Kinds = []string{k}
+		line := fmt.Sprintf("%s %-12s %5d in selected namespaces", checkbox(m.kindChecked[k]), k, all.Workloads(m.namespaces))
+		b.WriteString(m.row(paneKinds, i == m.kindCursor, line) + "\n")
+	}
+}
+
+func (m *Model) paneTitle(pane int, title string) string {
+	if m.pane == pane {
+		return titleStyle.Render("▸ " + title)
+	}
+	return dimStyle.Render("  " + title)
+}
+
+func (m *Model) row(pane int, atCursor bool, line string) string {
+	if atCursor && m.pane == pane {
+		return cursorStyle.Render("> " + line)
+	}
+	return "  " + line
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func countKinds(ns *Namespace, kinds []string) int {
+	total := 0
+	for _, k := range kinds {
+		total += ns.Workloads[k]
+	}
+	return total
+}
@@ -0,0 +1,125 @@
+package picker
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNamespaces() []Namespace {
+	return []Namespace{
+		{Name: "payments", Pods: 12, Workloads: map[string]int{"Deployment": 4, "StatefulSet": 1}},
+		{Name: "batch", Pods: 3, Workloads: map[string]int{"CronJob": 2}},
+		{Name: "payments-staging", Pods: 5, Workloads: map[string]int{"Deployment": 3}},
+	}
+}
+
+func press(m *Model, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+func TestPicker_SelectAndConfirm(t *testing.T) {
+	var estimated []Selection
+	m := NewModel(testNamespaces(), []string{"Deployment", "StatefulSet", "CronJob"}, func(sel Selection) string {
+		estimated = append(estimated, sel)
+		return "~42 queries"
+	})
+
+	// Sorted by name: batch, payments, payments-staging
+	press(m, "down", " ")
+	sel, ok := m.Selection()
+	assert.False(t, ok)
+	assert.Equal(t, []string{"payments"}, sel.Namespaces)
+	assert.Equal(t, 5, sel.Workloads(m.namespaces))
+
+	// Uncheck StatefulSet in the kinds pane
+	press(m, "tab", "down", " ")
+	sel, _ = m.Selection()
+	assert.Equal(t, []string{"Deployment", "CronJob"}, sel.Kinds)
+	assert.Equal(t, 4, sel.Workloads(m.namespaces))
+	assert.Contains(t, m.View(), "~42 queries")
+
+	press(m, "enter")
+	assert.Contains(t, m.View(), "Run the analysis?")
+	cmd := press(m, "y")
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+	sel, ok = m.Selection()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"payments"}, sel.Namespaces)
+	assert.NotEmpty(t, estimated)
+}
+
+func TestPicker_FilterAndCheckAll(t *testing.T) {
+	m := NewModel(testNamespaces(), []string{"Deployment"}, nil)
+	press(m, "/", "p", "a", "y", "enter", "a")
+	sel, _ := m.Selection()
+	assert.Equal(t, []string{"payments", "payments-staging"}, sel.Namespaces, "only namespaces matching the filter")
+
+	press(m, "n")
+	sel, _ = m.Selection()
+	assert.Empty(t, sel.Namespaces)
+}
+
+func TestPicker_RunNeedsSelection(t *testing.T) {
+	m := NewModel(testNamespaces(), []string{"Deployment"}, nil)
+	press(m, "enter")
+	assert.Contains(t, m.View(), "check at least one namespace")
+
+	press(m, " ", "tab", " ", "enter")
+	assert.Contains(t, m.View(), "check at least one workload kind")
+	assert.NotContains(t, m.View(), "Run the analysis?")
+}
+
+func TestPicker_BackFromConfirmAndQuit(t *testing.T) {
+	m := NewModel(testNamespaces(), []string{"Deployment"}, nil)
+	press(m, " ", "enter", "esc")
+	assert.NotContains(t, m.View(), "Run the analysis?")
+
+	cmd := press(m, "q")
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+	_, ok := m.Selection()
+	assert.False(t, ok)
+}
+
+func TestPicker_SetKindsAndKeyMap(t *testing.T) {
+	m := NewModel(testNamespaces(), []string{"Deployment", "CronJob"}, nil)
+	m.SetKinds([]string{"CronJob", "Unknown"})
+	sel, _ := m.Selection()
+	assert.Equal(t, []string{"CronJob"}, sel.Kinds)
+
+	keys, err := NewKeyMap(map[string][]string{"toggle": {"t"}})
+	require.NoError(t, err)
+	m.SetKeyMap(keys)
+	press(m, " ")
+	sel, _ = m.Selection()
+	assert.Empty(t, sel.Namespaces, "space is no longer bound")
+	press(m, "t")
+	sel, _ = m.Selection()
+	assert.Equal(t, []string{"batch"}, sel.Namespaces)
+
+	_, err = NewKeyMap(map[string][]string{"bogus": {"b"}})
+	assert.Error(t, err)
+}
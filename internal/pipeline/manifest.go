@@ -0,0 +1,350 @@
+// Package pipeline runs declarative analysis manifests: an ordered list of
+// kubenow steps (snapshot, analyze, llm, notify, gate) kept in a YAML file,
+// so recurring analyses are versioned and reviewed like code.
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/notify"
+	"github.com/ppiankov/kubenow/internal/prompt"
+)
+
+// ManifestVersion is the only supported manifest version.
+const ManifestVersion = 1
+
+// Step kinds, as reported in StepResult.Kind.
+const (
+	KindSnapshot = "snapshot"
+	KindAnalyze  = "analyze"
+	KindLLM      = "llm"
+	KindNotify   = "notify"
+	KindGate     = "gate"
+)
+
+// llmModes are the prompt modes an llm step may run; each is a kubenow
+// command except auto, which runs default with --mode auto.
+var llmModes = map[string]bool{
+	"default": true, "pod": true, "incident": true, "teamlead": true,
+	"compliance": true, "chaos": true, "security": true, prompt.ModeAuto: true,
+}
+
+// forbiddenFlags are command flags a step may not set: watch mode never
+// returns, and --fail-on exits the process before later steps run (use a
+// gate step instead).
+var forbiddenFlags = map[string]string{
+	"watch-interval": "a pipeline runs once; schedule it instead",
+	"watch-config":   "a pipeline runs once; schedule it instead",
+	"fail-on":        "it exits before later steps run; use a gate step",
+	"save-snapshot":  "use a snapshot step",
+	"from-snapshot":  "set from_snapshot on the llm step",
+}
+
+// envRef matches ${VAR} references expanded from the environment, so
+// secrets stay out of the manifest.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Manifest is a `kubenow run -f` document.
+type Manifest struct {
+	Version   int    `yaml:"version"`
+	Name      string `yaml:"name"`
+	Context   string `yaml:"context,omitempty"`   // kubeconfig context for every step
+	Namespace string `yaml:"namespace,omitempty"` // namespace for every step; steps may override it in flags
+	Steps     []Step `yaml:"steps"`
+}
+
+// Step is one pipeline stage. Exactly one of the kind fields is set.
+type Step struct {
+	Name            string `yaml:"name"`
+	ContinueOnError bool   `yaml:"continue_on_error,omitempty"` // a failed command does not stop the run
+
+	Snapshot *SnapshotStep `yaml:"snapshot,omitempty"`
+	Analyze  *AnalyzeStep  `yaml:"analyze,omitempty"`
+	LLM      *LLMStep      `yaml:"llm,omitempty"`
+	Notify   *NotifyStep   `yaml:"notify,omitempty"`
+	Gate     *GateStep     `yaml:"gate,omitempty"`
+}
+
+// Flags are command-line flags of a step's command, without the leading
+// dashes. Lists repeat the flag.
+type Flags map[string]any
+
+// SnapshotStep collects a cluster snapshot to a file without calling an LLM.
+type SnapshotStep struct {
+	Output string `yaml:"output"`
+	Flags  Flags  `yaml:"flags,omitempty"` // snapshot filters: max-pods, include-namespaces, ...
+}
+
+// AnalyzeStep runs a deterministic `kubenow analyze` subcommand.
+type AnalyzeStep struct {
+	Type  string `yaml:"type"` // requests-skew, oom, orphans, ...
+	Flags Flags  `yaml:"flags,omitempty"`
+}
+
+// LLMStep runs an LLM analysis mode, on the latest snapshot step's output
+// unless FromSnapshot names another file or Live is set.
+type LLMStep struct {
+	Mode         string `yaml:"mode"`
+	FromSnapshot string `yaml:"from_snapshot,omitempty"`
+	Live         bool   `yaml:"live,omitempty"` // collect a fresh snapshot instead of reusing one
+	Flags        Flags  `yaml:"flags,omitempty"`
+}
+
+// NotifyStep reports the steps run so far to webhook sinks. Failed steps
+// and gates are high-severity alerts; a run without failures sends a
+// low-severity summary, delivered only to sinks with min_severity: low.
+type NotifyStep struct {
+	Sinks []notify.Sink `yaml:"sinks"`
+}
+
+// GateStep checks a value in a JSON report written by an earlier step. The
+// value at Path is a number, or the length of a list or object; with Where,
+// only list items whose fields equal the given values are counted.
+type GateStep struct {
+	File  string            `yaml:"file"`
+	Path  string            `yaml:"path"` // dotted, e.g. summary.resources or results
+	Where map[string]string `yaml:"where,omitempty"`
+	Max   *float64          `yaml:"max,omitempty"`
+	Min   *float64          `yaml:"min,omitempty"`
+}
+
+// Kind returns the step's kind, or "" when none or several are set.
+func (s *Step) Kind() string {
+	kinds := map[string]bool{
+		KindSnapshot: s.Snapshot != nil, KindAnalyze: s.Analyze != nil, KindLLM: s.LLM != nil,
+		KindNotify: s.Notify != nil, KindGate: s.Gate != nil,
+	}
+	kind := ""
+	for k, set := range kinds {
+		if !set {
+			continue
+		}
+		if kind != "" {
+			return ""
+		}
+		kind = k
+	}
+	return kind
+}
+
+// Load reads, expands, and validates a manifest. ${VAR} references are
+// replaced with environment variables; an unset variable is an error.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return Parse(data, os.LookupEnv)
+}
+
+// Parse expands and validates a manifest document; lookup resolves ${VAR}.
+func Parse(data []byte, lookup func(string) (string, bool)) (*Manifest, error) {
+	var missing []string
+	expanded := envRef.ReplaceAllFunc(data, func(ref []byte) []byte {
+		name := string(envRef.FindSubmatch(ref)[1])
+		value, ok := lookup(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return []byte(value)
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("manifest references unset environment variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	var m Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(expanded))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks the manifest's version, step names, and step configs.
+//
+//nolint:gocyclo // one check per step kind
+func (m *Manifest) Validate() error {
+	if m.Version != ManifestVersion {
+		return fmt.Errorf("unsupported manifest version %d (want %d)", m.Version, ManifestVersion)
+	}
+	if m.Name == "" {
+		return fmt.Errorf("manifest name is required")
+	}
+	if len(m.Steps) == 0 {
+		return fmt.Errorf("manifest %q defines no steps", m.Name)
+	}
+
+	seen := map[string]bool{}
+	for i := range m.Steps {
+		s := &m.Steps[i]
+		if s.Name == "" {
+			return fmt.Errorf("steps[%d]: name is required", i)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("steps[%d]: duplicate name %q", i, s.Name)
+		}
+		seen[s.Name] = true
+
+		var err error
+		switch s.Kind() {
+		case KindSnapshot:
+			if s.Snapshot.Output == "" {
+				err = fmt.Errorf("output is required")
+			} else {
+				err = s.Snapshot.Flags.validate()
+			}
+		case KindAnalyze:
+			if s.Analyze.Type == "" {
+				err = fmt.Errorf("type is required")
+			} else {
+				err = s.Analyze.Flags.validate()
+			}
+		case KindLLM:
+			switch {
+			case !llmModes[s.LLM.Mode]:
+				err = fmt.Errorf("invalid mode %q (use default, pod, incident, teamlead, compliance, chaos, security, or auto)", s.LLM.Mode)
+			case s.LLM.Live && s.LLM.FromSnapshot != "":
+				err = fmt.Errorf("live and from_snapshot are mutually exclusive")
+			default:
+				err = s.LLM.Flags.validate()
+			}
+		case KindNotify:
+			if len(s.Notify.Sinks) == 0 {
+				err = fmt.Errorf("sinks are required")
+			}
+			for j := range s.Notify.Sinks {
+				if _, sinkErr := s.Notify.Sinks[j].Target(); sinkErr != nil {
+					err = fmt.Errorf("sinks[%d]: %w", j, sinkErr)
+					break
+				}
+			}
+		case KindGate:
+			switch {
+			case s.Gate.File == "" || s.Gate.Path == "":
+				err = fmt.Errorf("file and path are required")
+			case s.Gate.Max == nil && s.Gate.Min == nil:
+				err = fmt.Errorf("max or min is required")
+			}
+		default:
+			err = fmt.Errorf("set exactly one of snapshot, analyze, llm, notify, or gate")
+		}
+		if err != nil {
+			return fmt.Errorf("step %q: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+func (f Flags) validate() error {
+	for name, value := range f {
+		if reason, ok := forbiddenFlags[name]; ok {
+			return fmt.Errorf("flag %q is not allowed: %s", name, reason)
+		}
+		if strings.HasPrefix(name, "-") {
+			return fmt.Errorf("flag %q: omit the leading dashes", name)
+		}
+		if _, err := flagValues(value); err != nil {
+			return fmt.Errorf("flag %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// args renders the flags as --name=value arguments in name order.
+func (f Flags) args() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		values, _ := flagValues(f[name]) // validated on load
+		for _, v := range values {
+			args = append(args, "--"+name+"="+v)
+		}
+	}
+	return args
+}
+
+// flagValues formats a flag value; lists give one value per element.
+func flagValues(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("value is empty")
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			values, err := flagValues(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) != 1 {
+				return nil, fmt.Errorf("nested lists are not supported")
+			}
+			out = append(out, values[0])
+		}
+		return out, nil
+	case map[string]any, Flags: // yaml decodes nested maps with the parent's type
+		return nil, fmt.Errorf("maps are not supported")
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// Args returns the kubenow command line for a command step (snapshot,
+// analyze, llm), with the manifest's context and namespace first so step
+// flags can override them. snapshot is the latest snapshot output before
+// the step, used by llm steps. Notify and gate steps return nil.
+func (m *Manifest) Args(s *Step, snapshot string) []string {
+	var args []string
+	switch s.Kind() {
+	case KindSnapshot:
+		args = append(args, "default", "--save-snapshot="+s.Snapshot.Output)
+	case KindAnalyze:
+		args = append(args, "analyze", s.Analyze.Type)
+	case KindLLM:
+		if s.LLM.Mode == prompt.ModeAuto {
+			args = append(args, "default", "--mode="+prompt.ModeAuto)
+		} else {
+			args = append(args, s.LLM.Mode)
+		}
+		from := s.LLM.FromSnapshot
+		if from == "" && !s.LLM.Live {
+			from = snapshot
+		}
+		if from != "" {
+			args = append(args, "--from-snapshot="+from)
+		}
+	default:
+		return nil
+	}
+
+	if m.Context != "" {
+		args = append(args, "--context="+m.Context)
+	}
+	if m.Namespace != "" {
+		args = append(args, "--namespace="+m.Namespace)
+	}
+	switch {
+	case s.Snapshot != nil:
+		args = append(args, s.Snapshot.Flags.args()...)
+	case s.Analyze != nil:
+		args = append(args, s.Analyze.Flags.args()...)
+	case s.LLM != nil:
+		args = append(args, s.LLM.Flags.args()...)
+	}
+	return args
+}
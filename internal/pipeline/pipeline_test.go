@@ -0,0 +1,255 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/notify"
+)
+
+const weeklyManifest = `
+version: 1
+name: weekly-prod
+context: prod
+namespace: payments
+steps:
+  - name: collect
+    snapshot:
+      output: snap.json
+      flags:
+        max-pods: 50
+  - name: skew
+    analyze:
+      type: requests-skew
+      flags:
+        prometheus-url: http://prometheus:9090
+        output: json
+        export-file: skew.json
+  - name: triage
+    llm:
+      mode: incident
+      flags:
+        llm-provider: anthropic
+        model: claude
+        api-key: ${LLM_API_KEY}
+        include-namespaces: [payments, checkout]
+  - name: no-unsafe
+    gate:
+      file: skew.json
+      path: results
+      where:
+        safety.rating: UNSAFE
+      max: 0
+`
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func TestParse(t *testing.T) {
+	m, err := Parse([]byte(weeklyManifest), env(map[string]string{"LLM_API_KEY": "secret"}))
+	require.NoError(t, err)
+	require.Len(t, m.Steps, 4)
+	assert.Equal(t, []string{KindSnapshot, KindAnalyze, KindLLM, KindGate},
+		[]string{m.Steps[0].Kind(), m.Steps[1].Kind(), m.Steps[2].Kind(), m.Steps[3].Kind()})
+
+	assert.Equal(t, []string{"default", "--save-snapshot=snap.json", "--context=prod", "--namespace=payments", "--max-pods=50"},
+		m.Args(&m.Steps[0], ""))
+	assert.Equal(t, []string{
+		"analyze", "requests-skew", "--context=prod", "--namespace=payments",
+		"--export-file=skew.json", "--output=json", "--prometheus-url=http://prometheus:9090",
+	}, m.Args(&m.Steps[1], "snap.json"))
+	assert.Equal(t, []string{
+		"incident", "--from-snapshot=snap.json", "--context=prod", "--namespace=payments", "--api-key=secret",
+		"--include-namespaces=payments", "--include-namespaces=checkout", "--llm-provider=anthropic", "--model=claude",
+	}, m.Args(&m.Steps[2], "snap.json"))
+	assert.Nil(t, m.Args(&m.Steps[3], "snap.json"))
+}
+
+func TestParse_AutoAndLiveLLM(t *testing.T) {
+	m := &Manifest{Name: "x"}
+	auto := &Step{Name: "auto", LLM: &LLMStep{Mode: "auto"}}
+	assert.Equal(t, []string{"default", "--mode=auto", "--from-snapshot=s.json"}, m.Args(auto, "s.json"))
+	live := &Step{Name: "live", LLM: &LLMStep{Mode: "pod", Live: true}}
+	assert.Equal(t, []string{"pod"}, m.Args(live, "s.json"))
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"unset env", "version: 1\nname: x\nsteps: [{name: a, analyze: {type: oom, flags: {window: '${WINDOW}'}}}]", "WINDOW"},
+		{"version", "version: 2\nname: x\nsteps: [{name: a, analyze: {type: oom}}]", "unsupported manifest version 2"},
+		{"no steps", "version: 1\nname: x\n", "defines no steps"},
+		{"unknown field", "version: 1\nname: x\nstep: []", "field step not found"},
+		{"two kinds", "version: 1\nname: x\nsteps: [{name: a, analyze: {type: oom}, gate: {file: f, path: p, max: 0}}]", "exactly one"},
+		{"duplicate", "version: 1\nname: x\nsteps: [{name: a, analyze: {type: oom}}, {name: a, analyze: {type: oom}}]", "duplicate name"},
+		{"fail-on", "version: 1\nname: x\nsteps: [{name: a, analyze: {type: requests-skew, flags: {fail-on: unsafe}}}]", "use a gate step"},
+		{"watch", "version: 1\nname: x\nsteps: [{name: a, llm: {mode: default, flags: {watch-interval: 1m}}}]", "runs once"},
+		{"mode", "version: 1\nname: x\nsteps: [{name: a, llm: {mode: summary}}]", `invalid mode "summary"`},
+		{"gate bounds", "version: 1\nname: x\nsteps: [{name: a, gate: {file: f, path: p}}]", "max or min is required"},
+		{"sink", "version: 1\nname: x\nsteps: [{name: a, notify: {sinks: [{url: 'ftp://x'}]}}]", "sinks[0]"},
+		{"map flag", "version: 1\nname: x\nsteps: [{name: a, analyze: {type: oom, flags: {x: {y: 1}}}}]", "maps are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.manifest), env(nil))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func writeJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestEvaluateGate(t *testing.T) {
+	report := writeJSON(t, map[string]any{
+		"summary": map[string]any{"resources": 3, "monthly_savings": 12.5},
+		"results": []any{
+			map[string]any{"name": "api", "safety": map[string]any{"rating": "UNSAFE"}},
+			map[string]any{"name": "web", "safety": map[string]any{"rating": "SAFE"}},
+		},
+	})
+	limit := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		gate    GateStep
+		passed  bool
+		message string
+		err     string
+	}{
+		{"number under max", GateStep{Path: "summary.monthly_savings", Max: limit(20)}, true, "summary.monthly_savings = 12.5", ""},
+		{"number over max", GateStep{Path: "summary.resources", Max: limit(0)}, false, "summary.resources = 3 (above max 0)", ""},
+		{"list length below min", GateStep{Path: "results", Min: limit(5)}, false, "results = 2 (below min 5)", ""},
+		{
+			"where", GateStep{Path: "results", Where: map[string]string{"safety.rating": "UNSAFE"}, Max: limit(0)},
+			false, "results[safety.rating=UNSAFE] = 1 (above max 0)", "",
+		},
+		{"list index", GateStep{Path: "results.1.name", Max: limit(0)}, false, "", `value "web" is not a number`},
+		{"missing path", GateStep{Path: "summary.pods", Max: limit(0)}, false, "", "path summary.pods not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.gate.File = report
+			passed, message, err := EvaluateGate(&tt.gate)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.passed, passed)
+			assert.Equal(t, tt.message, message)
+		})
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	var posted []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		_ = json.Unmarshal(body, &payload)
+		posted = append(posted, payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	report := writeJSON(t, map[string]any{"summary": map[string]any{"resources": 4}})
+	zero := 0.0
+	m := &Manifest{Version: 1, Name: "cleanup", Steps: []Step{
+		{Name: "collect", Snapshot: &SnapshotStep{Output: "snap.json"}},
+		{Name: "orphans", Analyze: &AnalyzeStep{Type: "orphans"}},
+		{Name: "triage", LLM: &LLMStep{Mode: "default"}, ContinueOnError: true},
+		{Name: "nothing-orphaned", Gate: &GateStep{File: report, Path: "summary.resources", Max: &zero}},
+		{Name: "alert", Notify: &NotifyStep{Sinks: []notify.Sink{{URL: server.URL}}}},
+		{Name: "after", Analyze: &AnalyzeStep{Type: "oom"}},
+	}}
+	require.NoError(t, m.Validate())
+
+	var ran [][]string
+	runner := &Runner{Exec: func(_ context.Context, args []string) error {
+		ran = append(ran, args)
+		if args[0] == "default" && len(args) > 1 && args[1] == "--from-snapshot=snap.json" {
+			return errors.New("llm unreachable")
+		}
+		return nil
+	}}
+	result := runner.Run(context.Background(), m)
+
+	require.Len(t, ran, 4)
+	assert.Equal(t, []string{"default", "--from-snapshot=snap.json"}, ran[2])
+	statuses := make([]string, 0, len(result.Steps))
+	for _, s := range result.Steps {
+		statuses = append(statuses, s.Status)
+	}
+	assert.Equal(t, []string{StatusPassed, StatusPassed, StatusFailed, StatusFailed, StatusPassed, StatusPassed}, statuses)
+	assert.False(t, result.Failed, "triage continues on error")
+	assert.Equal(t, 1, result.GatesFailed)
+	assert.Equal(t, "summary.resources = 4 (above max 0)", result.Steps[3].Message)
+
+	require.Len(t, posted, 1)
+	alerts, ok := posted[0]["alerts"].([]any)
+	require.True(t, ok)
+	assert.Len(t, alerts, 2, "one alert per failed step")
+	assert.Contains(t, posted[0]["title"], "kubenow run cleanup: 2 step(s) failed")
+}
+
+func TestRunner_StopsOnFailure(t *testing.T) {
+	m := &Manifest{Version: 1, Name: "x", Steps: []Step{
+		{Name: "a", Analyze: &AnalyzeStep{Type: "oom"}},
+		{Name: "b", Analyze: &AnalyzeStep{Type: "orphans"}},
+	}}
+	calls := 0
+	runner := &Runner{Exec: func(context.Context, []string) error {
+		calls++
+		return errors.New("boom")
+	}}
+	result := runner.Run(context.Background(), m)
+	assert.True(t, result.Failed)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, StatusSkipped, result.Steps[1].Status)
+	assert.Equal(t, "a", result.FailedStep())
+
+	dry := (&Runner{DryRun: true}).Run(context.Background(), m)
+	assert.False(t, dry.Failed)
+	assert.Equal(t, []string{"analyze", "orphans"}, dry.Steps[1].Args)
+}
+
+func TestRunner_AbortIgnoresContinueOnError(t *testing.T) {
+	m := &Manifest{Version: 1, Name: "x", Steps: []Step{
+		{Name: "a", Analyze: &AnalyzeStep{Type: "oom"}, ContinueOnError: true},
+		{Name: "b", Analyze: &AnalyzeStep{Type: "orphans"}},
+	}}
+	runner := &Runner{Exec: func(context.Context, []string) error {
+		return fmt.Errorf("reset --namespace: %w", ErrAbort)
+	}}
+	result := runner.Run(context.Background(), m)
+	assert.True(t, result.Failed)
+	assert.Equal(t, "a", result.FailedStep())
+	assert.Equal(t, StatusSkipped, result.Steps[1].Status)
+}
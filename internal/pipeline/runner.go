@@ -0,0 +1,289 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/notify"
+)
+
+// Step statuses.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	Status   string   `json:"status"`
+	Args     []string `json:"args,omitempty"` // command line of snapshot, analyze, and llm steps
+	Message  string   `json:"message,omitempty"`
+	Duration string   `json:"duration,omitempty"`
+}
+
+// Result is the outcome of a pipeline run.
+type Result struct {
+	Name        string       `json:"name"`
+	Steps       []StepResult `json:"steps"`
+	GatesFailed int          `json:"gates_failed"`
+	Failed      bool         `json:"failed"` // a command step failed and stopped the run
+}
+
+// FailedStep returns the name of the command step that stopped the run, or
+// "" if none did.
+func (r *Result) FailedStep() string {
+	if !r.Failed {
+		return ""
+	}
+	for i := len(r.Steps) - 1; i >= 0; i-- {
+		if r.Steps[i].Status == StatusFailed && r.Steps[i].Args != nil {
+			return r.Steps[i].Name
+		}
+	}
+	return ""
+}
+
+// ErrAbort is wrapped by Exec errors after which no later step can run
+// reliably, such as flags that could not be reset; the run stops even when
+// the step sets continue_on_error.
+var ErrAbort = errors.New("run aborted")
+
+// Runner executes a manifest's steps in order.
+type Runner struct {
+	// Exec runs a kubenow command line in-process.
+	Exec func(ctx context.Context, args []string) error
+	// DryRun resolves command lines and validates sinks without running
+	// commands, sending notifications, or reading gate files.
+	DryRun bool
+	// Progress is called before each step; may be nil.
+	Progress func(index int, s *Step)
+}
+
+// Run executes the steps. A failed command step stops the run unless it
+// sets continue_on_error; the remaining steps are skipped. A failed gate is
+// recorded and the run continues, so a later notify step can report it.
+func (r *Runner) Run(ctx context.Context, m *Manifest) *Result {
+	result := &Result{Name: m.Name, Steps: make([]StepResult, 0, len(m.Steps))}
+	snapshot := ""
+	for i := range m.Steps {
+		s := &m.Steps[i]
+		sr := StepResult{Name: s.Name, Kind: s.Kind(), Status: StatusPassed}
+		if result.Failed {
+			sr.Status = StatusSkipped
+			result.Steps = append(result.Steps, sr)
+			continue
+		}
+		if r.Progress != nil {
+			r.Progress(i, s)
+		}
+
+		start := time.Now()
+		switch sr.Kind {
+		case KindSnapshot, KindAnalyze, KindLLM:
+			sr.Args = m.Args(s, snapshot)
+			if !r.DryRun {
+				if err := r.Exec(ctx, sr.Args); err != nil {
+					sr.Status, sr.Message = StatusFailed, err.Error()
+					result.Failed = !s.ContinueOnError || errors.Is(err, ErrAbort)
+				}
+			}
+			if s.Snapshot != nil && sr.Status == StatusPassed {
+				snapshot = s.Snapshot.Output
+			}
+		case KindGate:
+			if r.DryRun {
+				sr.Message = "not evaluated (dry run)"
+				break
+			}
+			passed, msg, err := EvaluateGate(s.Gate)
+			sr.Message = msg
+			if err != nil {
+				sr.Message = err.Error()
+			}
+			if err != nil || !passed {
+				sr.Status = StatusFailed
+				result.GatesFailed++
+			}
+		case KindNotify:
+			if r.DryRun {
+				sr.Message = fmt.Sprintf("would notify %d sink(s)", len(s.Notify.Sinks))
+				break
+			}
+			sent, err := notifySteps(ctx, s.Notify, m, result.Steps)
+			sr.Message = fmt.Sprintf("%d message(s) sent", sent)
+			if err != nil {
+				sr.Status, sr.Message = StatusFailed, err.Error()
+			}
+		}
+		sr.Duration = time.Since(start).Round(time.Millisecond).String()
+		result.Steps = append(result.Steps, sr)
+	}
+	return result
+}
+
+// notifySteps sends one alert per failed step, or a low-severity summary
+// when nothing failed.
+func notifySteps(ctx context.Context, step *NotifyStep, m *Manifest, steps []StepResult) (int, error) {
+	notifier := &notify.Notifier{}
+	for i := range step.Sinks {
+		target, err := step.Sinks[i].Target()
+		if err != nil {
+			return 0, fmt.Errorf("sinks[%d]: %w", i, err)
+		}
+		notifier.Targets = append(notifier.Targets, target)
+	}
+
+	var alerts []notify.Alert
+	for i := range steps {
+		sr := &steps[i]
+		if sr.Status != StatusFailed {
+			continue
+		}
+		issue := "StepFailed"
+		if sr.Kind == KindGate {
+			issue = "GateFailed"
+		}
+		alerts = append(alerts, notify.Alert{
+			Severity: notify.SeverityHigh, Namespace: m.Namespace, Name: sr.Name, IssueType: issue, Summary: sr.Message,
+		})
+	}
+	title := fmt.Sprintf("kubenow run %s: %d step(s) failed", m.Name, len(alerts))
+	if len(alerts) == 0 {
+		title = fmt.Sprintf("kubenow run %s: all %d step(s) passed", m.Name, len(steps))
+		alerts = append(alerts, notify.Alert{
+			Severity: notify.SeverityLow, Namespace: m.Namespace, Name: m.Name, IssueType: "RunPassed", Summary: title,
+		})
+	}
+	return notifier.Send(ctx, title, alerts)
+}
+
+// EvaluateGate reads the gate's JSON file and compares the value at its
+// path with the bounds. The message describes the observed value.
+func EvaluateGate(g *GateStep) (passed bool, message string, err error) {
+	data, err := os.ReadFile(g.File)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to read gate file: %w", err)
+	}
+	var doc any
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return false, "", fmt.Errorf("gate file %s is not JSON: %w", g.File, err)
+	}
+	node, err := lookup(doc, g.Path)
+	if err != nil {
+		return false, "", err
+	}
+	value, err := gateValue(node, g.Where)
+	if err != nil {
+		return false, "", fmt.Errorf("%s: %w", g.Path, err)
+	}
+
+	subject := g.Path
+	if len(g.Where) > 0 {
+		keys := make([]string, 0, len(g.Where))
+		for k := range g.Where {
+			keys = append(keys, k+"="+g.Where[k])
+		}
+		sort.Strings(keys)
+		subject += "[" + strings.Join(keys, ",") + "]"
+	}
+	message = fmt.Sprintf("%s = %s", subject, strconv.FormatFloat(value, 'f', -1, 64))
+	passed = true
+	if g.Max != nil && value > *g.Max {
+		passed = false
+		message += fmt.Sprintf(" (above max %s)", strconv.FormatFloat(*g.Max, 'f', -1, 64))
+	}
+	if g.Min != nil && value < *g.Min {
+		passed = false
+		message += fmt.Sprintf(" (below min %s)", strconv.FormatFloat(*g.Min, 'f', -1, 64))
+	}
+	return passed, message, nil
+}
+
+// lookup walks a dotted path through objects and list indexes.
+func lookup(node any, path string) (any, error) {
+	if path == "" || path == "." {
+		return node, nil
+	}
+	walked := ""
+	for _, part := range strings.Split(path, ".") {
+		walked = strings.TrimPrefix(walked+"."+part, ".")
+		switch v := node.(type) {
+		case map[string]any:
+			next, ok := v[part]
+			if !ok {
+				return nil, fmt.Errorf("path %s not found", walked)
+			}
+			node = next
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("path %s: no list item %q", walked, part)
+			}
+			node = v[i]
+		default:
+			return nil, fmt.Errorf("path %s not found", walked)
+		}
+	}
+	return node, nil
+}
+
+// gateValue turns a JSON value into a number: numbers as-is, booleans as
+// 0/1, lists and objects as their length, and numeric strings parsed.
+// With where, list items are counted only if every field matches.
+func gateValue(node any, where map[string]string) (float64, error) {
+	if len(where) > 0 {
+		items, ok := node.([]any)
+		if !ok {
+			return 0, fmt.Errorf("where needs a list")
+		}
+		count := 0
+		for _, item := range items {
+			if matchesWhere(item, where) {
+				count++
+			}
+		}
+		return float64(count), nil
+	}
+
+	switch v := node.(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case []any:
+		return float64(len(v)), nil
+	case map[string]any:
+		return float64(len(v)), nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %q is not a number", v)
+		}
+		return f, nil
+	default:
+		return 0, nil // null
+	}
+}
+
+func matchesWhere(item any, where map[string]string) bool {
+	for path, want := range where {
+		got, err := lookup(item, path)
+		if err != nil || got == nil || fmt.Sprint(got) != want {
+			return false
+		}
+	}
+	return true
+}
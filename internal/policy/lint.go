@@ -0,0 +1,439 @@
+package policy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// Schema is the JSON Schema of the policy file, as printed by
+// `kubenow policy schema`.
+//
+//go:embed schema.json
+var Schema []byte
+
+// Severity grades a lint diagnostic.
+type Severity string
+
+// Lint severities. Errors make the policy unusable or wrong; warnings flag
+// settings that load but are unlikely to be what the admin meant.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is one lint finding, located in the policy file when the
+// field is present there (Line 0 = not located).
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Field    string   `json:"field"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint,omitempty"`
+}
+
+// String returns the diagnostic in "line N: severity: field: message" form.
+func (d Diagnostic) String() string {
+	loc := ""
+	if d.Line > 0 {
+		loc = fmt.Sprintf("line %d: ", d.Line)
+	}
+	return fmt.Sprintf("%s%s: %s: %s", loc, d.Severity, d.Field, d.Message)
+}
+
+// schemaNode is the subset of JSON Schema that schema.json uses.
+type schemaNode struct {
+	Type                 string                 `json:"type"`
+	Description          string                 `json:"description"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Required             []string               `json:"required"`
+	Enum                 []string               `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	Pattern              string                 `json:"pattern"`
+	Items                *schemaNode            `json:"items"`
+}
+
+var yamlLineRe = regexp.MustCompile(`line (\d+)`)
+
+// Lint checks a policy file's contents against Schema, then runs Validate
+// and semantic consistency checks on the decoded policy. Diagnostics are
+// sorted by line; an empty result means the policy is clean.
+func Lint(data []byte) []Diagnostic {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		d := Diagnostic{Severity: SeverityError, Field: "policy", Message: err.Error(),
+			Hint: "fix the YAML syntax; indent with spaces, not tabs"}
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+		}
+		return []Diagnostic{d}
+	}
+	if len(doc.Content) == 0 {
+		return []Diagnostic{{Severity: SeverityError, Field: "policy", Message: "file is empty",
+			Hint: "start from examples/policy.yaml"}}
+	}
+	root := doc.Content[0]
+
+	var schema schemaNode
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		panic(fmt.Sprintf("policy: malformed embedded schema: %v", err))
+	}
+
+	l := &linter{}
+	l.checkSchema(&schema, root, "")
+
+	// Type errors are already reported by the schema pass and leave their
+	// fields zero; other decoding errors leave nothing to check.
+	var p Policy
+	var typeErr *yaml.TypeError
+	if err := root.Decode(&p); err == nil || errors.As(err, &typeErr) {
+		reported := make(map[string]bool, len(l.diags))
+		for _, d := range l.diags {
+			reported[d.Field] = true
+		}
+		for _, e := range Validate(&p).Errors {
+			if !reported[e.Field] {
+				l.add(SeverityError, e.Field, lookupNode(root, e.Field), e.Message, validationHints[e.Field])
+			}
+		}
+		l.checkSemantics(&p, root)
+	}
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		a, b := l.diags[i], l.diags[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Field < b.Field
+	})
+	return l.diags
+}
+
+// HasErrors reports whether any diagnostic is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+var validationHints = map[string]string{
+	"audit.path":                             "set a writable directory, e.g. /var/lib/kubenow/audit, or disable apply",
+	"audit.backend":                          `set it to "filesystem"`,
+	"resource_ratios.max_cpu_limit_ratio":    "use 1 or more, or 0 to disable the rule",
+	"resource_ratios.max_memory_limit_ratio": "use 1 or more, or 0 to disable the rule",
+	"resource_ratios.forbid_cpu_limits":      "keep forbid_cpu_limits or max_cpu_limit_ratio, not both",
+}
+
+type linter struct {
+	diags []Diagnostic
+}
+
+func (l *linter) add(sev Severity, field string, node *yaml.Node, message, hint string) {
+	d := Diagnostic{Severity: sev, Field: field, Message: message, Hint: hint}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+	}
+	l.diags = append(l.diags, d)
+}
+
+// checkSchema validates node against s. Null values stand for absent
+// fields, which the policy loader treats as zero values.
+func (l *linter) checkSchema(s *schemaNode, node *yaml.Node, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	field := path
+	if field == "" {
+		field = "policy"
+	}
+
+	if !matchesType(s.Type, node) {
+		l.add(SeverityError, field, node, fmt.Sprintf("expected %s, got %s", typeName(s.Type), describeNode(node)), s.Description)
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			name := key.Value
+			seen[name] = true
+			child := s.Properties[name]
+			if child == nil {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					l.add(SeverityError, joinPath(path, name), key, "unknown field", unknownFieldHint(name, s.Properties))
+				}
+				continue
+			}
+			l.checkSchema(child, val, joinPath(path, name))
+		}
+		for _, name := range s.Required {
+			if !seen[name] {
+				hint := ""
+				if p := s.Properties[name]; p != nil && len(p.Enum) == 1 {
+					hint = fmt.Sprintf("add %s: %s", name, p.Enum[0])
+				}
+				l.add(SeverityError, joinPath(path, name), node, "required field is missing", hint)
+			}
+		}
+	case "array":
+		if s.Items == nil {
+			return
+		}
+		for i, item := range node.Content {
+			l.checkSchema(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string":
+		if len(s.Enum) > 0 && !contains(s.Enum, node.Value) {
+			l.add(SeverityError, field, node, fmt.Sprintf("unsupported value %q", node.Value), "use one of: "+formatEnum(s.Enum))
+			return
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(node.Value) {
+			l.add(SeverityError, field, node, fmt.Sprintf("invalid value %q", node.Value), s.Description)
+		}
+	case "integer", "number":
+		v, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			return
+		}
+		switch {
+		case s.Minimum != nil && v < *s.Minimum:
+			l.add(SeverityError, field, node, fmt.Sprintf("%s is below %g", node.Value, *s.Minimum), rangeHint(s))
+		case s.Maximum != nil && v > *s.Maximum:
+			l.add(SeverityError, field, node, fmt.Sprintf("%s is above %g", node.Value, *s.Maximum), rangeHint(s))
+		}
+	}
+}
+
+func rangeHint(s *schemaNode) string {
+	if s.Minimum != nil && s.Maximum != nil {
+		return fmt.Sprintf("use a value from %g to %g", *s.Minimum, *s.Maximum)
+	}
+	return s.Description
+}
+
+// checkSemantics flags settings that pass the schema but contradict each
+// other or cannot do what they say.
+func (l *linter) checkSemantics(p *Policy, root *yaml.Node) {
+	minLatch, minErr := time.ParseDuration(p.Apply.MinLatchDuration)
+	if p.Apply.MinLatchDuration != "" && minErr == nil && minLatch <= 0 {
+		l.add(SeverityError, "apply.min_latch_duration", lookupNode(root, "apply.min_latch_duration"),
+			"must be greater than 0", "omit it for the 1h default")
+	}
+	if p.Apply.MinLatchDuration != "" && p.Apply.MaxLatchAge != "" && minErr == nil {
+		if maxAge, err := parseDurationWithDays(p.Apply.MaxLatchAge); err == nil && maxAge > 0 && maxAge < minLatch {
+			l.add(SeverityError, "apply.max_latch_age", lookupNode(root, "apply.max_latch_age"),
+				fmt.Sprintf("%s is shorter than min_latch_duration %s: no latch can satisfy both", p.Apply.MaxLatchAge, p.Apply.MinLatchDuration),
+				"raise max_latch_age or lower min_latch_duration")
+		}
+	}
+
+	if p.Apply.Enabled && !p.Global.Enabled {
+		l.add(SeverityWarning, "apply.enabled", lookupNode(root, "apply.enabled"),
+			"apply is enabled but global.enabled is false, so pro-monitor stays disabled", "set global.enabled: true to allow apply")
+	}
+	if p.Apply.Enabled {
+		for _, f := range []struct {
+			field string
+			pct   int
+		}{
+			{"apply.max_request_delta_percent", p.Apply.MaxRequestDeltaPct},
+			{"apply.max_limit_delta_percent", p.Apply.MaxLimitDeltaPct},
+		} {
+			if f.pct == 0 {
+				l.add(SeverityWarning, f.field, lookupNode(root, f.field),
+					"0 leaves changes unbounded while apply is enabled", "set a bound such as 30")
+			}
+		}
+	}
+
+	l.checkNamespaceList("namespaces.deny", p.Namespaces.Deny, root)
+	l.checkNamespaceList("namespaces.allow", p.Namespaces.Allow, root)
+	allowed := make(map[string]bool, len(p.Namespaces.Allow))
+	for _, ns := range p.Namespaces.Allow {
+		allowed[ns] = true
+	}
+	for i, ns := range p.Namespaces.Deny {
+		if allowed[ns] {
+			field := fmt.Sprintf("namespaces.deny[%d]", i)
+			l.add(SeverityWarning, field, lookupNode(root, field),
+				fmt.Sprintf("%q is both denied and allowed; deny wins", ns), "remove it from one of the lists")
+		}
+	}
+
+	for i, o := range p.Overrides {
+		prefix := fmt.Sprintf("namespace_overrides[%d]", i)
+		l.checkNamespaceList(prefix+".namespaces", o.Namespaces, root)
+		for j, ns := range o.Namespaces {
+			if contains(p.Namespaces.Deny, ns) && o.Apply.Enabled != nil && *o.Apply.Enabled {
+				field := fmt.Sprintf("%s.namespaces[%d]", prefix, j)
+				l.add(SeverityWarning, field, lookupNode(root, field),
+					fmt.Sprintf("%q enables apply but is in namespaces.deny; deny wins", ns), "remove it from namespaces.deny or from the override")
+			}
+		}
+		if o.Apply.Enabled != nil && *o.Apply.Enabled && !p.Global.Enabled {
+			field := prefix + ".apply.enabled"
+			l.add(SeverityWarning, field, lookupNode(root, field),
+				"apply is enabled but global.enabled is false, so pro-monitor stays disabled", "set global.enabled: true to allow apply")
+		}
+	}
+}
+
+func (l *linter) checkNamespaceList(field string, names []string, root *yaml.Node) {
+	seen := map[string]bool{}
+	for i, ns := range names {
+		if seen[ns] {
+			f := fmt.Sprintf("%s[%d]", field, i)
+			l.add(SeverityWarning, f, lookupNode(root, f), fmt.Sprintf("%q is listed more than once", ns), "remove the duplicate")
+		}
+		seen[ns] = true
+	}
+}
+
+// lookupNode finds the value node of a dotted field path such as
+// "apply.min_latch_duration" or "namespaces.deny[2]", or of its closest
+// ancestor present in the file, to locate diagnostics on missing fields.
+func lookupNode(root *yaml.Node, field string) *yaml.Node {
+	node := root
+	for _, part := range strings.Split(field, ".") {
+		name, index := part, -1
+		if open := strings.Index(part, "["); open >= 0 && strings.HasSuffix(part, "]") {
+			name = part[:open]
+			index, _ = strconv.Atoi(part[open+1 : len(part)-1])
+		}
+		next := mappingValue(node, name)
+		if next == nil || next.Kind == yaml.ScalarNode && next.Tag == "!!null" {
+			return node
+		}
+		node = next
+		if index >= 0 {
+			if node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+				return node
+			}
+			node = node.Content[index]
+		}
+	}
+	return node
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func matchesType(typ string, node *yaml.Node) bool {
+	switch typ {
+	case "object":
+		return node.Kind == yaml.MappingNode
+	case "array":
+		return node.Kind == yaml.SequenceNode
+	case "string":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!str"
+	case "boolean":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	case "integer":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case "number":
+		return node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float")
+	default:
+		return true
+	}
+}
+
+func typeName(typ string) string {
+	switch typ {
+	case "object":
+		return "a mapping"
+	case "array":
+		return "a list"
+	case "boolean":
+		return "true or false"
+	case "integer":
+		return "a whole number"
+	default:
+		return "a " + typ
+	}
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+func unknownFieldHint(name string, known map[string]*schemaNode) string {
+	names := make([]string, 0, len(known))
+	for k := range known {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	best, bestDist := "", len(name)/2+1
+	for _, k := range names {
+		if d := util.EditDistance(name, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("did you mean %q?", best)
+	}
+	return "known fields: " + strings.Join(names, ", ")
+}
+
+func formatEnum(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			quoted = append(quoted, strconv.Quote(v))
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
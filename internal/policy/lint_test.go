@@ -0,0 +1,224 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintBase = `apiVersion: kubenow/v1alpha1
+kind: Policy
+global:
+  enabled: true
+audit:
+  backend: filesystem
+  path: /var/lib/kubenow/audit
+apply:
+  enabled: true
+  max_request_delta_percent: 30
+  max_limit_delta_percent: 50
+`
+
+func findDiag(diags []Diagnostic, field string) *Diagnostic {
+	for i := range diags {
+		if diags[i].Field == field {
+			return &diags[i]
+		}
+	}
+	return nil
+}
+
+func TestLint_ExamplePolicyIsClean(t *testing.T) {
+	data, err := os.ReadFile("../../examples/policy.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, Lint(data))
+}
+
+func TestLint_Clean(t *testing.T) {
+	assert.Empty(t, Lint([]byte(lintBase)))
+}
+
+func TestLint_SyntaxError(t *testing.T) {
+	diags := Lint([]byte("apiVersion: kubenow/v1alpha1\nkind: [Policy\n"))
+	require.Len(t, diags, 1)
+	assert.Equal(t, SeverityError, diags[0].Severity)
+	assert.Positive(t, diags[0].Line)
+}
+
+func TestLint_Empty(t *testing.T) {
+	diags := Lint(nil)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "empty")
+}
+
+func TestLint_Schema(t *testing.T) {
+	data := strings.Replace(lintBase, "  max_limit_delta_percent: 50\n", `  max_limit_delta_percent: 150
+  min_safety_rating: RISKY
+  max_latch_age: 1week
+  requre_latch: true
+rate_limits:
+  max_applies_per_hour: ten
+namespaces:
+  deny: ["team-.*"]
+`, 1)
+	diags := Lint([]byte(data))
+	assert.True(t, HasErrors(diags))
+
+	d := findDiag(diags, "apply.requre_latch")
+	require.NotNil(t, d)
+	assert.Equal(t, "unknown field", d.Message)
+	assert.Equal(t, `did you mean "require_latch"?`, d.Hint)
+	assert.Equal(t, 14, d.Line)
+
+	d = findDiag(diags, "apply.max_limit_delta_percent")
+	require.NotNil(t, d)
+	assert.Equal(t, "150 is above 100", d.Message)
+	assert.Equal(t, "use a value from 0 to 100", d.Hint)
+	assert.Equal(t, 11, d.Line)
+	assert.Equal(t, 28, d.Column)
+
+	d = findDiag(diags, "apply.min_safety_rating")
+	require.NotNil(t, d)
+	assert.Equal(t, `use one of: "SAFE", "CAUTION"`, d.Hint)
+
+	require.NotNil(t, findDiag(diags, "apply.max_latch_age"))
+	require.NotNil(t, findDiag(diags, "rate_limits.max_applies_per_hour"))
+
+	d = findDiag(diags, "namespaces.deny[0]")
+	require.NotNil(t, d)
+	assert.Contains(t, d.Hint, "regexes are not supported")
+
+	// Schema errors are not repeated by Validate
+	count := 0
+	for _, d := range diags {
+		if d.Field == "apply.min_safety_rating" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestLint_MissingRequired(t *testing.T) {
+	diags := Lint([]byte("global:\n  enabled: true\n"))
+	d := findDiag(diags, "apiVersion")
+	require.NotNil(t, d)
+	assert.Equal(t, "add apiVersion: kubenow/v1alpha1", d.Hint)
+	require.NotNil(t, findDiag(diags, "kind"))
+}
+
+func TestLint_ValidateRules(t *testing.T) {
+	data := strings.Replace(lintBase, "  path: /var/lib/kubenow/audit\n", "", 1)
+	diags := Lint([]byte(data))
+	d := findDiag(diags, "audit.path")
+	require.NotNil(t, d)
+	assert.Equal(t, SeverityError, d.Severity)
+	assert.Equal(t, 6, d.Line, "located on the audit section")
+	assert.NotEmpty(t, d.Hint)
+}
+
+func TestLint_Semantics(t *testing.T) {
+	data := strings.Replace(lintBase, "  enabled: true\naudit", "  enabled: false\naudit", 1) + `  max_latch_age: 30m
+  min_latch_duration: 1h
+namespaces:
+  deny: [kube-system, prod]
+  allow: [prod, staging, staging]
+`
+	diags := Lint([]byte(data))
+
+	d := findDiag(diags, "apply.max_latch_age")
+	require.NotNil(t, d)
+	assert.Equal(t, SeverityError, d.Severity)
+	assert.Contains(t, d.Message, "no latch can satisfy both")
+
+	d = findDiag(diags, "apply.enabled")
+	require.NotNil(t, d)
+	assert.Equal(t, SeverityWarning, d.Severity)
+
+	d = findDiag(diags, "namespaces.deny[1]")
+	require.NotNil(t, d)
+	assert.Contains(t, d.Message, "deny wins")
+
+	d = findDiag(diags, "namespaces.allow[2]")
+	require.NotNil(t, d)
+	assert.Contains(t, d.Message, "more than once")
+}
+
+func TestLint_ZeroMinLatchAndUnboundedDelta(t *testing.T) {
+	data := strings.Replace(lintBase, "  max_request_delta_percent: 30\n", "  max_request_delta_percent: 0\n  min_latch_duration: 0s\n", 1)
+	diags := Lint([]byte(data))
+
+	d := findDiag(diags, "apply.min_latch_duration")
+	require.NotNil(t, d)
+	assert.Equal(t, SeverityError, d.Severity)
+	assert.Equal(t, "must be greater than 0", d.Message)
+
+	d = findDiag(diags, "apply.max_request_delta_percent")
+	require.NotNil(t, d)
+	assert.Equal(t, SeverityWarning, d.Severity)
+	assert.False(t, HasErrors([]Diagnostic{*d}))
+}
+
+func TestLint_NamespaceOverrides(t *testing.T) {
+	data := lintBase + `namespaces:
+  deny: [kube-system]
+namespace_overrides:
+  - namespaces: [prod, prod]
+    apply:
+      enabled: false
+      max_request_delta_percent: 110
+  - namespaces: [kube-system]
+    apply:
+      enabled: true
+      max_safety: SAFE
+`
+	diags := Lint([]byte(data))
+
+	d := findDiag(diags, "namespace_overrides[0].apply.max_request_delta_percent")
+	require.NotNil(t, d)
+	assert.Equal(t, SeverityError, d.Severity)
+	assert.Equal(t, 18, d.Line)
+
+	d = findDiag(diags, "namespace_overrides[1].apply.max_safety")
+	require.NotNil(t, d)
+	assert.Equal(t, "unknown field", d.Message)
+
+	d = findDiag(diags, "namespace_overrides[0].namespaces[1]")
+	require.NotNil(t, d)
+	assert.Contains(t, d.Message, "more than once")
+
+	d = findDiag(diags, "namespace_overrides[1].namespaces[0]")
+	require.NotNil(t, d)
+	assert.Equal(t, SeverityWarning, d.Severity)
+	assert.Contains(t, d.Message, "deny wins")
+}
+
+// Every field the loader accepts must be in the schema, or lint would
+// reject valid policies.
+func TestSchema_CoversPolicy(t *testing.T) {
+	var schema schemaNode
+	require.NoError(t, json.Unmarshal(Schema, &schema))
+
+	var walk func(t *testing.T, typ reflect.Type, s *schemaNode, path string)
+	walk = func(t *testing.T, typ reflect.Type, s *schemaNode, path string) {
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			child := s.Properties[name]
+			if !assert.NotNil(t, child, "schema lacks %s%s", path, name) {
+				continue
+			}
+			switch {
+			case f.Type.Kind() == reflect.Struct:
+				walk(t, f.Type, child, path+name+".")
+			case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct:
+				walk(t, f.Type.Elem(), child.Items, path+name+"[].")
+			}
+		}
+	}
+	walk(t, reflect.TypeOf(Policy{}), &schema, "")
+}
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"fmt"
+	"time"
+)
+
+// NamespaceOverride replaces apply settings for the listed namespaces,
+// e.g. tighter deltas and apply disabled in prod. Fields left unset
+// inherit the top-level apply section.
+type NamespaceOverride struct {
+	Namespaces []string      `yaml:"namespaces"`
+	Apply      ApplyOverride `yaml:"apply"`
+}
+
+// ApplyOverride mirrors ApplyConfig with optional fields; nil inherits.
+type ApplyOverride struct {
+	Enabled            *bool   `yaml:"enabled,omitempty"`
+	RequireLatch       *bool   `yaml:"require_latch,omitempty"`
+	MaxRequestDeltaPct *int    `yaml:"max_request_delta_percent,omitempty"`
+	MaxLimitDeltaPct   *int    `yaml:"max_limit_delta_percent,omitempty"`
+	AllowLimitDecrease *bool   `yaml:"allow_limit_decrease,omitempty"`
+	MinLatchDuration   *string `yaml:"min_latch_duration,omitempty"`
+	MaxLatchAge        *string `yaml:"max_latch_age,omitempty"`
+	MinSafetyRating    *string `yaml:"min_safety_rating,omitempty"`
+}
+
+// NamespaceOverride returns the override listing namespace, or nil.
+// Validate rejects a namespace listed by more than one override.
+func (p *Policy) NamespaceOverride(namespace string) *NamespaceOverride {
+	for i := range p.Overrides {
+		for _, ns := range p.Overrides[i].Namespaces {
+			if ns == namespace {
+				return &p.Overrides[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ForNamespace returns the effective policy for workloads in namespace:
+// a copy of p with the namespace's override merged into the apply
+// section. Without an override it returns p itself.
+func (p *Policy) ForNamespace(namespace string) *Policy {
+	o := p.NamespaceOverride(namespace)
+	if o == nil {
+		return p
+	}
+	eff := *p
+	eff.Apply = o.Apply.merge(p.Apply)
+	return &eff
+}
+
+func (o ApplyOverride) merge(base ApplyConfig) ApplyConfig {
+	if o.Enabled != nil {
+		base.Enabled = *o.Enabled
+	}
+	if o.RequireLatch != nil {
+		base.RequireLatch = *o.RequireLatch
+	}
+	if o.MaxRequestDeltaPct != nil {
+		base.MaxRequestDeltaPct = *o.MaxRequestDeltaPct
+	}
+	if o.MaxLimitDeltaPct != nil {
+		base.MaxLimitDeltaPct = *o.MaxLimitDeltaPct
+	}
+	if o.AllowLimitDecrease != nil {
+		base.AllowLimitDecrease = *o.AllowLimitDecrease
+	}
+	if o.MinLatchDuration != nil {
+		base.MinLatchDuration = *o.MinLatchDuration
+	}
+	if o.MaxLatchAge != nil {
+		base.MaxLatchAge = *o.MaxLatchAge
+	}
+	if o.MinSafetyRating != nil {
+		base.MinSafetyRating = *o.MinSafetyRating
+	}
+	return base
+}
+
+// validateOverrides checks each namespace override like the top-level
+// apply section, and that no namespace is claimed by two overrides.
+func validateOverrides(p *Policy, result *ValidationResult) {
+	claimed := map[string]int{}
+	for i, o := range p.Overrides {
+		prefix := fmt.Sprintf("namespace_overrides[%d]", i)
+		if len(o.Namespaces) == 0 {
+			result.addError(prefix+".namespaces", "must list at least one namespace")
+		}
+		for _, ns := range o.Namespaces {
+			if j, ok := claimed[ns]; ok && j != i {
+				result.addError(prefix+".namespaces", fmt.Sprintf("%q is already overridden by namespace_overrides[%d]", ns, j))
+				continue
+			}
+			claimed[ns] = i
+		}
+
+		a := o.Apply
+		if a.MaxRequestDeltaPct != nil && (*a.MaxRequestDeltaPct < 0 || *a.MaxRequestDeltaPct > 100) {
+			result.addError(prefix+".apply.max_request_delta_percent", "must be 0-100")
+		}
+		if a.MaxLimitDeltaPct != nil && (*a.MaxLimitDeltaPct < 0 || *a.MaxLimitDeltaPct > 100) {
+			result.addError(prefix+".apply.max_limit_delta_percent", "must be 0-100")
+		}
+		if a.MinLatchDuration != nil && *a.MinLatchDuration != "" {
+			if _, err := time.ParseDuration(*a.MinLatchDuration); err != nil {
+				result.addError(prefix+".apply.min_latch_duration", fmt.Sprintf("invalid duration: %v", err))
+			}
+		}
+		if a.MaxLatchAge != nil && *a.MaxLatchAge != "" {
+			if _, err := parseDurationWithDays(*a.MaxLatchAge); err != nil {
+				result.addError(prefix+".apply.max_latch_age", fmt.Sprintf("invalid duration: %v", err))
+			}
+		}
+		if r := a.MinSafetyRating; r != nil && *r != "" && *r != "SAFE" && *r != "CAUTION" {
+			result.addError(prefix+".apply.min_safety_rating", fmt.Sprintf("must be SAFE or CAUTION, got %q", *r))
+		}
+		if a.Enabled != nil && *a.Enabled && !p.Apply.Enabled {
+			if p.Audit.Path == "" {
+				result.addError("audit.path", fmt.Sprintf("required when %s.apply.enabled is true", prefix))
+			}
+			if p.Audit.Backend == "" {
+				result.addError("audit.backend", fmt.Sprintf("required when %s.apply.enabled is true", prefix))
+			}
+		}
+	}
+}
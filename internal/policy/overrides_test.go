@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func overridePolicy(t *testing.T) *Policy {
+	t.Helper()
+	var p Policy
+	require.NoError(t, yaml.Unmarshal([]byte(`apiVersion: kubenow/v1alpha1
+kind: Policy
+global:
+  enabled: true
+audit:
+  backend: filesystem
+  path: /var/lib/kubenow/audit
+apply:
+  enabled: true
+  max_request_delta_percent: 30
+  max_limit_delta_percent: 50
+  min_safety_rating: CAUTION
+namespace_overrides:
+  - namespaces: [prod, payments]
+    apply:
+      enabled: false
+      max_request_delta_percent: 10
+      min_safety_rating: SAFE
+  - namespaces: [dev]
+    apply:
+      max_request_delta_percent: 50
+      max_limit_delta_percent: 50
+`), &p))
+	return &p
+}
+
+func TestForNamespace(t *testing.T) {
+	p := overridePolicy(t)
+	require.True(t, Validate(p).Valid)
+
+	prod := p.ForNamespace("payments")
+	assert.False(t, prod.Apply.Enabled)
+	assert.Equal(t, 10, prod.Apply.MaxRequestDeltaPct)
+	assert.Equal(t, 50, prod.Apply.MaxLimitDeltaPct, "unset fields inherit")
+	assert.Equal(t, "SAFE", prod.Apply.MinSafetyRating)
+	assert.True(t, p.Apply.Enabled, "base policy is not modified")
+
+	dev := p.ForNamespace("dev")
+	assert.True(t, dev.Apply.Enabled)
+	assert.Equal(t, 50, dev.Apply.MaxRequestDeltaPct)
+
+	assert.Same(t, p, p.ForNamespace("staging"))
+	assert.Nil(t, p.NamespaceOverride("staging"))
+}
+
+func TestValidate_NamespaceOverrides(t *testing.T) {
+	p := overridePolicy(t)
+	bad := 120
+	p.Overrides[1].Apply.MaxLimitDeltaPct = &bad
+	age := "forever"
+	p.Overrides[0].Apply.MaxLatchAge = &age
+	p.Overrides = append(p.Overrides, NamespaceOverride{Namespaces: []string{"dev"}}, NamespaceOverride{})
+
+	vr := Validate(p)
+	assert.False(t, vr.Valid)
+	fields := make([]string, 0, len(vr.Errors))
+	for _, e := range vr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"namespace_overrides[0].apply.max_latch_age",
+		"namespace_overrides[1].apply.max_limit_delta_percent",
+		"namespace_overrides[2].namespaces",
+		"namespace_overrides[3].namespaces",
+	}, fields)
+}
+
+func TestValidate_OverrideEnablingApplyNeedsAuditPath(t *testing.T) {
+	enabled := true
+	p := &Policy{
+		APIVersion: CurrentAPIVersion,
+		Kind:       CurrentKind,
+		Overrides:  []NamespaceOverride{{Namespaces: []string{"dev"}, Apply: ApplyOverride{Enabled: &enabled}}},
+	}
+	vr := Validate(p)
+	require.False(t, vr.Valid)
+	assert.Equal(t, "audit.path", vr.Errors[0].Field)
+}
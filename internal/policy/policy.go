@@ -23,14 +23,18 @@ const (
 // Policy is the admin-owned configuration that gates pro-monitor behavior.
 // kubenow reads it. kubenow never writes it. Admins own it.
 type Policy struct {
-	APIVersion string       `yaml:"apiVersion"`
-	Kind       string       `yaml:"kind"`
-	Global     GlobalConfig `yaml:"global"`
-	Audit      AuditConfig  `yaml:"audit"`
-	Apply      ApplyConfig  `yaml:"apply"`
-	Namespaces NSConfig     `yaml:"namespaces"`
-	Identity   IDConfig     `yaml:"identity"`
-	RateLimits RateConfig   `yaml:"rate_limits"`
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Global     GlobalConfig   `yaml:"global"`
+	Audit      AuditConfig    `yaml:"audit"`
+	Apply      ApplyConfig    `yaml:"apply"`
+	Namespaces NSConfig       `yaml:"namespaces"`
+	Identity   IDConfig       `yaml:"identity"`
+	RateLimits RateConfig     `yaml:"rate_limits"`
+	Annotate   AnnotateConfig `yaml:"annotations,omitempty"`
+	Ratios     RatioConfig    `yaml:"resource_ratios,omitempty"`
+
+	Overrides []NamespaceOverride `yaml:"namespace_overrides,omitempty"`
 }
 
 // GlobalConfig contains the master kill switch.
@@ -77,6 +81,52 @@ type RateConfig struct {
 	RateWindow            string `yaml:"rate_window"`
 }
 
+// AnnotateConfig controls external annotations created after a successful apply.
+type AnnotateConfig struct {
+	Grafana GrafanaConfig `yaml:"grafana,omitempty"`
+}
+
+// GrafanaConfig configures Grafana annotations for apply events.
+// The API token is read from the environment variable named by TokenEnv,
+// never from the policy file itself.
+type GrafanaConfig struct {
+	URL           string   `yaml:"url"`
+	TokenEnv      string   `yaml:"token_env,omitempty"`
+	DashboardUIDs []string `yaml:"dashboard_uids,omitempty"`
+	Tags          []string `yaml:"tags,omitempty"`
+}
+
+// DefaultGrafanaTokenEnv is the environment variable read when grafana.token_env is unset.
+const DefaultGrafanaTokenEnv = "GRAFANA_TOKEN"
+
+// Enabled reports whether Grafana annotations are configured.
+func (g GrafanaConfig) Enabled() bool {
+	return g.URL != ""
+}
+
+// Token returns the Grafana API token from the configured environment variable.
+func (g GrafanaConfig) Token() string {
+	env := g.TokenEnv
+	if env == "" {
+		env = DefaultGrafanaTokenEnv
+	}
+	return os.Getenv(env)
+}
+
+// RatioConfig mandates limit/request ratios across workloads
+// (e.g. "limit <= 2x request" or "no CPU limits"). Zero values disable a rule.
+// Containers without a limit never violate a ratio rule.
+type RatioConfig struct {
+	MaxCPULimitRatio    float64 `yaml:"max_cpu_limit_ratio,omitempty"`
+	MaxMemoryLimitRatio float64 `yaml:"max_memory_limit_ratio,omitempty"`
+	ForbidCPULimits     bool    `yaml:"forbid_cpu_limits,omitempty"`
+}
+
+// Enabled reports whether any ratio rule is configured.
+func (r RatioConfig) Enabled() bool {
+	return r.MaxCPULimitRatio > 0 || r.MaxMemoryLimitRatio > 0 || r.ForbidCPULimits
+}
+
 // LoadResult is the outcome of loading a policy file.
 type LoadResult struct {
 	Policy   *Policy
@@ -208,6 +258,26 @@ func Validate(p *Policy) *ValidationResult {
 		}
 	}
 
+	// Annotations validation
+	if g := p.Annotate.Grafana; g.URL != "" {
+		if !strings.HasPrefix(g.URL, "http://") && !strings.HasPrefix(g.URL, "https://") {
+			result.addError("annotations.grafana.url", fmt.Sprintf("must be an http(s) URL, got %q", g.URL))
+		}
+	}
+
+	// Resource ratio validation
+	if r := p.Ratios.MaxCPULimitRatio; r != 0 && r < 1 {
+		result.addError("resource_ratios.max_cpu_limit_ratio", "must be >= 1 (limit cannot be below request)")
+	}
+	if r := p.Ratios.MaxMemoryLimitRatio; r != 0 && r < 1 {
+		result.addError("resource_ratios.max_memory_limit_ratio", "must be >= 1 (limit cannot be below request)")
+	}
+	if p.Ratios.ForbidCPULimits && p.Ratios.MaxCPULimitRatio > 0 {
+		result.addError("resource_ratios.forbid_cpu_limits", "cannot be combined with max_cpu_limit_ratio")
+	}
+
+	validateOverrides(p, result)
+
 	return result
 }
 
@@ -301,6 +371,12 @@ func (r *ValidationResult) addError(field, message string) {
 	r.Errors = append(r.Errors, ValidationError{Field: field, Message: message})
 }
 
+// ResolvePath returns the policy file Load would read: overridePath, else
+// $KUBENOW_POLICY, else DefaultPolicyPath.
+func ResolvePath(overridePath string) string {
+	return resolvePath(overridePath)
+}
+
 func resolvePath(overridePath string) string {
 	path := DefaultPolicyPath
 	if overridePath != "" {
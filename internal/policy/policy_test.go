@@ -568,3 +568,92 @@ func TestResolvePath(t *testing.T) {
 		assert.Equal(t, DefaultPolicyPath, resolvePath(""))
 	})
 }
+
+func TestLoad_GrafanaAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	content := `apiVersion: kubenow/v1alpha1
+kind: Policy
+annotations:
+  grafana:
+    url: https://grafana.example.com
+    token_env: MY_GRAFANA_TOKEN
+    dashboard_uids: [abc, def]
+    tags: [team-x]
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	result := Load(path)
+	require.Empty(t, result.ErrorMsg)
+	require.NotNil(t, result.Policy)
+
+	g := result.Policy.Annotate.Grafana
+	assert.True(t, g.Enabled())
+	assert.Equal(t, "https://grafana.example.com", g.URL)
+	assert.Equal(t, []string{"abc", "def"}, g.DashboardUIDs)
+	assert.Equal(t, []string{"team-x"}, g.Tags)
+
+	t.Setenv("MY_GRAFANA_TOKEN", "tok")
+	assert.Equal(t, "tok", g.Token())
+	assert.True(t, Validate(result.Policy).Valid)
+}
+
+func TestGrafanaConfig_DefaultTokenEnv(t *testing.T) {
+	t.Setenv(DefaultGrafanaTokenEnv, "default-tok")
+	assert.Equal(t, "default-tok", GrafanaConfig{URL: "http://g"}.Token())
+	assert.False(t, GrafanaConfig{}.Enabled())
+}
+
+func TestValidate_InvalidGrafanaURL(t *testing.T) {
+	p := &Policy{
+		APIVersion: CurrentAPIVersion,
+		Kind:       CurrentKind,
+		Annotate:   AnnotateConfig{Grafana: GrafanaConfig{URL: "grafana.local"}},
+	}
+	vr := Validate(p)
+	assert.False(t, vr.Valid)
+	require.Len(t, vr.Errors, 1)
+	assert.Equal(t, "annotations.grafana.url", vr.Errors[0].Field)
+}
+
+func TestLoad_ResourceRatios(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	content := `apiVersion: kubenow/v1alpha1
+kind: Policy
+resource_ratios:
+  max_memory_limit_ratio: 1.5
+  forbid_cpu_limits: true
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	result := Load(path)
+	require.Empty(t, result.ErrorMsg)
+	require.NotNil(t, result.Policy)
+
+	r := result.Policy.Ratios
+	assert.True(t, r.Enabled())
+	assert.Equal(t, 1.5, r.MaxMemoryLimitRatio)
+	assert.True(t, r.ForbidCPULimits)
+	assert.True(t, Validate(result.Policy).Valid)
+	assert.False(t, RatioConfig{}.Enabled())
+}
+
+func TestValidate_ResourceRatios(t *testing.T) {
+	p := &Policy{
+		APIVersion: CurrentAPIVersion,
+		Kind:       CurrentKind,
+		Ratios:     RatioConfig{MaxCPULimitRatio: 2, MaxMemoryLimitRatio: 0.5, ForbidCPULimits: true},
+	}
+	vr := Validate(p)
+	assert.False(t, vr.Valid)
+
+	fields := make([]string, 0, len(vr.Errors))
+	for _, e := range vr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"resource_ratios.max_memory_limit_ratio",
+		"resource_ratios.forbid_cpu_limits",
+	}, fields)
+}
@@ -0,0 +1,52 @@
+package promonitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/grafana"
+	"github.com/ppiankov/kubenow/internal/policy"
+)
+
+// annotationTimeout bounds the total time spent creating annotations after apply.
+const annotationTimeout = 10 * time.Second
+
+// BuildApplyAnnotation builds the Grafana annotation marking a successful apply.
+func BuildApplyAnnotation(rec *AlignmentRecommendation, extraTags []string, ts time.Time) grafana.Annotation {
+	ref := rec.Workload
+	text := fmt.Sprintf("kubenow resource change for %s/%s (safety=%s)",
+		ref.Namespace, ref.String(), rec.Safety)
+	if summaries := containerChangeSummaries(rec); len(summaries) > 0 {
+		text += "\n" + strings.Join(summaries, "\n")
+	}
+
+	tags := []string{
+		"kubenow",
+		"resource-change",
+		"namespace:" + ref.Namespace,
+		"workload:" + ref.String(),
+	}
+	tags = append(tags, extraTags...)
+
+	return grafana.Annotation{
+		Time: ts,
+		Tags: tags,
+		Text: text,
+	}
+}
+
+// AnnotateApply creates Grafana annotations for a successful apply on every
+// configured dashboard. Returns the number of annotations created.
+func AnnotateApply(ctx context.Context, cfg policy.GrafanaConfig, rec *AlignmentRecommendation, ts time.Time) (int, error) {
+	if !cfg.Enabled() || rec == nil {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, annotationTimeout)
+	defer cancel()
+
+	client := &grafana.Client{URL: cfg.URL, Token: cfg.Token()}
+	return client.CreateForDashboards(ctx, cfg.DashboardUIDs, BuildApplyAnnotation(rec, cfg.Tags, ts))
+}
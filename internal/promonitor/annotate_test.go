@@ -0,0 +1,62 @@
+package promonitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/policy"
+)
+
+func TestBuildApplyAnnotation(t *testing.T) {
+	rec := validApplyInput().Recommendation
+	ts := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	a := BuildApplyAnnotation(rec, []string{"team-payments"}, ts)
+
+	assert.Equal(t, ts, a.Time)
+	assert.Contains(t, a.Text, "kubenow resource change for default/deployment/api")
+	assert.Contains(t, a.Text, "safety=SAFE")
+	assert.Contains(t, a.Text, "api: cpu-req 100m→150m")
+	assert.Equal(t, []string{
+		"kubenow", "resource-change", "namespace:default", "workload:deployment/api", "team-payments",
+	}, a.Tags)
+}
+
+func TestAnnotateApply_Disabled(t *testing.T) {
+	n, err := AnnotateApply(context.Background(), policy.GrafanaConfig{}, validApplyInput().Recommendation, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestAnnotateApply_PostsPerDashboard(t *testing.T) {
+	var dashboards []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		uid, ok := body["dashboardUID"].(string)
+		require.True(t, ok)
+		dashboards = append(dashboards, uid)
+		assert.Equal(t, "Bearer test-token-123", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	t.Setenv("KUBENOW_TEST_GRAFANA_TOKEN", "test-token-123")
+	cfg := policy.GrafanaConfig{
+		URL:           srv.URL,
+		TokenEnv:      "KUBENOW_TEST_GRAFANA_TOKEN",
+		DashboardUIDs: []string{"cpu", "mem"},
+	}
+
+	n, err := AnnotateApply(context.Background(), cfg, validApplyInput().Recommendation, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"cpu", "mem"}, dashboards)
+}
@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -15,6 +15,7 @@ import (
 
 	"github.com/ppiankov/kubenow/internal/audit"
 	"github.com/ppiankov/kubenow/internal/policy"
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // KubeApplier abstracts Kubernetes mutations for testability.
@@ -23,6 +24,7 @@ type KubeApplier interface {
 	GetContainerResources(ctx context.Context, ref WorkloadRef) ([]ContainerResources, error)
 	GetManagedFields(ctx context.Context, ref WorkloadRef) ([]metav1.ManagedFieldsEntry, error)
 	GetWorkloadObject(ctx context.Context, ref WorkloadRef) (map[string]interface{}, error)
+	DryRunPatchWorkload(ctx context.Context, ref WorkloadRef, patchJSON []byte, fieldManager string, force bool) (map[string]interface{}, error)
 }
 
 // ClientsetApplier implements KubeApplier using a real Kubernetes clientset.
@@ -123,7 +125,11 @@ func (a *ClientsetApplier) GetWorkloadObject(ctx context.Context, ref WorkloadRe
 	default:
 		return nil, fmt.Errorf("unsupported kind: %s", ref.Kind)
 	}
+	return toObjectMap(raw)
+}
 
+// toObjectMap converts a typed object to a generic object map.
+func toObjectMap(raw interface{}) (map[string]interface{}, error) {
 	data, err := json.Marshal(raw)
 	if err != nil {
 		return nil, fmt.Errorf("marshal workload: %w", err)
@@ -160,6 +166,8 @@ type ApplyResult struct {
 	Requested       map[string]string // container→resource summary
 	Admitted        map[string]string
 	Drifts          []ResourceDrift
+	Annotated       int   // number of Grafana annotations created
+	AnnotationError error // non-fatal: annotation failure never fails the apply
 }
 
 // ResourceDrift records a difference between requested and admitted values.
@@ -237,6 +245,11 @@ func CheckActionable(input *ApplyInput) []string {
 		}
 	}
 
+	// Trend-derived expiry check
+	if input.Recommendation != nil && input.Recommendation.Expired(time.Now()) {
+		reasons = append(reasons, expiryReason(input.Recommendation))
+	}
+
 	// Latch duration check
 	if input.LatchDuration > 0 && input.Policy != nil {
 		minDuration := time.Hour // default
@@ -343,12 +356,12 @@ func buildSSAPatchJSON(rec *AlignmentRecommendation) ([]byte, error) {
 			Name: c.Name,
 			Resources: ssaResources{
 				Requests: ssaResourceValues{
-					CPU:    formatCPUResource(c.Recommended.CPURequest),
-					Memory: formatMemResource(c.Recommended.MemoryRequest),
+					CPU:    units.CPUQuantity(c.Recommended.CPURequest),
+					Memory: units.MemoryQuantity(c.Recommended.MemoryRequest),
 				},
 				Limits: ssaResourceValues{
-					CPU:    formatCPUResource(c.Recommended.CPULimit),
-					Memory: formatMemResource(c.Recommended.MemoryLimit),
+					CPU:    formatCPULimitResource(c.Recommended.CPULimit),
+					Memory: units.MemoryQuantity(c.Recommended.MemoryLimit),
 				},
 			},
 		}
@@ -382,22 +395,27 @@ func buildSSAPatchJSON(rec *AlignmentRecommendation) ([]byte, error) {
 // buildApplyAnnotation creates a human-readable summary of the apply.
 func buildApplyAnnotation(rec *AlignmentRecommendation) string {
 	ts := time.Now().UTC().Format(time.RFC3339)
-	var parts []string
+	base := fmt.Sprintf("%s | safety=%s | %s", ts, rec.Safety, strings.Join(containerChangeSummaries(rec), "; "))
+	if rec.Evidence != nil && rec.Evidence.PlannedDuration > 0 {
+		base += fmt.Sprintf(" | early-stop: %s of %s", rec.Evidence.Duration, rec.Evidence.PlannedDuration)
+	}
+	return base
+}
+
+// containerChangeSummaries returns one "name: cpu-req a→b, ..." entry per container.
+func containerChangeSummaries(rec *AlignmentRecommendation) []string {
+	parts := make([]string, 0, len(rec.Containers))
 	for i := range rec.Containers {
 		c := &rec.Containers[i]
 		parts = append(parts, fmt.Sprintf("%s: cpu-req %s→%s, cpu-lim %s→%s, mem-req %s→%s, mem-lim %s→%s",
 			c.Name,
-			formatCPUResource(c.Current.CPURequest), formatCPUResource(c.Recommended.CPURequest),
-			formatCPUResource(c.Current.CPULimit), formatCPUResource(c.Recommended.CPULimit),
-			formatMemResource(c.Current.MemoryRequest), formatMemResource(c.Recommended.MemoryRequest),
-			formatMemResource(c.Current.MemoryLimit), formatMemResource(c.Recommended.MemoryLimit),
+			units.CPUQuantity(c.Current.CPURequest), units.CPUQuantity(c.Recommended.CPURequest),
+			units.CPUQuantity(c.Current.CPULimit), units.CPUQuantity(c.Recommended.CPULimit),
+			units.MemoryQuantity(c.Current.MemoryRequest), units.MemoryQuantity(c.Recommended.MemoryRequest),
+			units.MemoryQuantity(c.Current.MemoryLimit), units.MemoryQuantity(c.Recommended.MemoryLimit),
 		))
 	}
-	base := fmt.Sprintf("%s | safety=%s | %s", ts, rec.Safety, strings.Join(parts, "; "))
-	if rec.Evidence != nil && rec.Evidence.PlannedDuration > 0 {
-		base += fmt.Sprintf(" | early-stop: %s of %s", rec.Evidence.Duration, rec.Evidence.PlannedDuration)
-	}
-	return base
+	return parts
 }
 
 // SSA patch document structs with JSON tags (parallel to patchDoc in export.go which uses YAML).
@@ -437,7 +455,7 @@ type ssaResources struct {
 }
 
 type ssaResourceValues struct {
-	CPU    string `json:"cpu"`
+	CPU    string `json:"cpu,omitempty"`
 	Memory string `json:"memory"`
 }
 
@@ -503,36 +521,36 @@ func compareResources(recommended []ContainerAlignment, admitted []ContainerReso
 			continue
 		}
 
-		if formatCPUResource(rec.Recommended.CPURequest) != formatCPUResource(adm.CPURequest) {
+		if units.CPUQuantity(rec.Recommended.CPURequest) != units.CPUQuantity(adm.CPURequest) {
 			drifts = append(drifts, ResourceDrift{
 				Container: rec.Name,
 				Field:     "cpu_request",
-				Requested: formatCPUResource(rec.Recommended.CPURequest),
-				Admitted:  formatCPUResource(adm.CPURequest),
+				Requested: units.CPUQuantity(rec.Recommended.CPURequest),
+				Admitted:  units.CPUQuantity(adm.CPURequest),
 			})
 		}
-		if formatCPUResource(rec.Recommended.CPULimit) != formatCPUResource(adm.CPULimit) {
+		if units.CPUQuantity(rec.Recommended.CPULimit) != units.CPUQuantity(adm.CPULimit) {
 			drifts = append(drifts, ResourceDrift{
 				Container: rec.Name,
 				Field:     "cpu_limit",
-				Requested: formatCPUResource(rec.Recommended.CPULimit),
-				Admitted:  formatCPUResource(adm.CPULimit),
+				Requested: units.CPUQuantity(rec.Recommended.CPULimit),
+				Admitted:  units.CPUQuantity(adm.CPULimit),
 			})
 		}
-		if formatMemResource(rec.Recommended.MemoryRequest) != formatMemResource(adm.MemoryRequest) {
+		if units.MemoryQuantity(rec.Recommended.MemoryRequest) != units.MemoryQuantity(adm.MemoryRequest) {
 			drifts = append(drifts, ResourceDrift{
 				Container: rec.Name,
 				Field:     "memory_request",
-				Requested: formatMemResource(rec.Recommended.MemoryRequest),
-				Admitted:  formatMemResource(adm.MemoryRequest),
+				Requested: units.MemoryQuantity(rec.Recommended.MemoryRequest),
+				Admitted:  units.MemoryQuantity(adm.MemoryRequest),
 			})
 		}
-		if formatMemResource(rec.Recommended.MemoryLimit) != formatMemResource(adm.MemoryLimit) {
+		if units.MemoryQuantity(rec.Recommended.MemoryLimit) != units.MemoryQuantity(adm.MemoryLimit) {
 			drifts = append(drifts, ResourceDrift{
 				Container: rec.Name,
 				Field:     "memory_limit",
-				Requested: formatMemResource(rec.Recommended.MemoryLimit),
-				Admitted:  formatMemResource(adm.MemoryLimit),
+				Requested: units.MemoryQuantity(rec.Recommended.MemoryLimit),
+				Admitted:  units.MemoryQuantity(adm.MemoryLimit),
 			})
 		}
 	}
@@ -546,10 +564,10 @@ func buildResourceSummary(containers []ContainerAlignment) map[string]string {
 	for i := range containers {
 		c := &containers[i]
 		m[c.Name] = fmt.Sprintf("cpu=%s/%s mem=%s/%s",
-			formatCPUResource(c.Recommended.CPURequest),
-			formatCPUResource(c.Recommended.CPULimit),
-			formatMemResource(c.Recommended.MemoryRequest),
-			formatMemResource(c.Recommended.MemoryLimit))
+			units.CPUQuantity(c.Recommended.CPURequest),
+			units.CPUQuantity(c.Recommended.CPULimit),
+			units.MemoryQuantity(c.Recommended.MemoryRequest),
+			units.MemoryQuantity(c.Recommended.MemoryLimit))
 	}
 	return m
 }
@@ -560,10 +578,10 @@ func buildContainerResourceSummary(containers []ContainerResources) map[string]s
 	for i := range containers {
 		c := &containers[i]
 		m[c.Name] = fmt.Sprintf("cpu=%s/%s mem=%s/%s",
-			formatCPUResource(c.CPURequest),
-			formatCPUResource(c.CPULimit),
-			formatMemResource(c.MemoryRequest),
-			formatMemResource(c.MemoryLimit))
+			units.CPUQuantity(c.CPURequest),
+			units.CPUQuantity(c.CPULimit),
+			units.MemoryQuantity(c.MemoryRequest),
+			units.MemoryQuantity(c.MemoryLimit))
 	}
 	return m
 }
@@ -633,6 +651,8 @@ func ExecuteApplyWithAudit(ctx context.Context, cfg *AuditApplyConfig) *ApplyRes
 		Recommendation: audit.BundleRecommendation{
 			Safety:     string(cfg.Input.Recommendation.Safety),
 			Confidence: string(cfg.Input.Recommendation.Confidence),
+			ComputedAt: cfg.Input.Recommendation.Timestamp,
+			ValidUntil: cfg.Input.Recommendation.ValidUntil,
 		},
 		Identity: identity,
 		Version:  cfg.Version,
@@ -672,9 +692,7 @@ func ExecuteApplyWithAudit(ctx context.Context, cfg *AuditApplyConfig) *ApplyRes
 		status = "denied"
 	}
 	if err := audit.FinalizeBundle(bundle, afterObj, status, ts, applyResult.Error); err != nil {
-		if _, writeErr := fmt.Fprintf(os.Stderr, "[kubenow] warning: audit finalization failed: %v\n", err); writeErr != nil {
-			return applyResult
-		}
+		slog.Warn("Audit finalization failed", "error", err)
 	}
 
 	return applyResult
@@ -701,26 +719,26 @@ func mapChanges(containers []ContainerAlignment) []audit.BundleChange {
 		changes = append(changes,
 			audit.BundleChange{
 				Field:        fmt.Sprintf("%s/cpu_request", c.Name),
-				Before:       formatCPUResource(c.Current.CPURequest),
-				After:        formatCPUResource(c.Recommended.CPURequest),
+				Before:       units.CPUQuantity(c.Current.CPURequest),
+				After:        units.CPUQuantity(c.Recommended.CPURequest),
 				DeltaPercent: c.Delta.CPURequestPercent,
 			},
 			audit.BundleChange{
 				Field:        fmt.Sprintf("%s/cpu_limit", c.Name),
-				Before:       formatCPUResource(c.Current.CPULimit),
-				After:        formatCPUResource(c.Recommended.CPULimit),
+				Before:       units.CPUQuantity(c.Current.CPULimit),
+				After:        units.CPUQuantity(c.Recommended.CPULimit),
 				DeltaPercent: c.Delta.CPULimitPercent,
 			},
 			audit.BundleChange{
 				Field:        fmt.Sprintf("%s/memory_request", c.Name),
-				Before:       formatMemResource(c.Current.MemoryRequest),
-				After:        formatMemResource(c.Recommended.MemoryRequest),
+				Before:       units.MemoryQuantity(c.Current.MemoryRequest),
+				After:        units.MemoryQuantity(c.Recommended.MemoryRequest),
 				DeltaPercent: c.Delta.MemoryRequestPercent,
 			},
 			audit.BundleChange{
 				Field:        fmt.Sprintf("%s/memory_limit", c.Name),
-				Before:       formatMemResource(c.Current.MemoryLimit),
-				After:        formatMemResource(c.Recommended.MemoryLimit),
+				Before:       units.MemoryQuantity(c.Current.MemoryLimit),
+				After:        units.MemoryQuantity(c.Recommended.MemoryLimit),
 				DeltaPercent: c.Delta.MemoryLimitPercent,
 			},
 		)
@@ -25,6 +25,9 @@ type mockKubeApplier struct {
 	managedErr     error
 	workloadObject map[string]interface{}
 	workloadErr    error
+	dryRunObject   map[string]interface{}
+	dryRunErr      error // error for force=false dry-runs
+	dryRunForced   bool  // true if a dry-run used force=true
 }
 
 func (m *mockKubeApplier) PatchWorkload(_ context.Context, _ WorkloadRef, patchJSON []byte, _ string, force bool) error {
@@ -49,6 +52,17 @@ func (m *mockKubeApplier) GetWorkloadObject(_ context.Context, _ WorkloadRef) (m
 	return m.workloadObject, m.workloadErr
 }
 
+func (m *mockKubeApplier) DryRunPatchWorkload(_ context.Context, _ WorkloadRef, _ []byte, _ string, force bool) (map[string]interface{}, error) {
+	if force {
+		m.dryRunForced = true
+		return m.dryRunObject, nil
+	}
+	if m.dryRunErr != nil {
+		return nil, m.dryRunErr
+	}
+	return m.dryRunObject, nil
+}
+
 func validApplyInput() *ApplyInput {
 	return &ApplyInput{
 		Recommendation: &AlignmentRecommendation{
@@ -0,0 +1,229 @@
+package promonitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Batch entry statuses.
+const (
+	BatchRecommended = "recommended"
+	BatchSkipped     = "skipped"
+)
+
+// BatchTarget is a workload selected for a batch latch, with the inputs
+// the recommendation needs read from the same list call.
+type BatchTarget struct {
+	Ref        WorkloadRef
+	Replicas   int32
+	Containers []ContainerResources
+	HPA        *HPAInfo
+	OOMHistory *OOMHistory
+
+	// Bounds replaces the batch-wide policy bounds for this target, for
+	// namespaces with a policy override (nil = batch-wide bounds).
+	Bounds *PolicyBounds
+
+	selector labels.Selector // pod selector, for the OOM history
+}
+
+// SelectWorkloads lists the Deployments, StatefulSets, and DaemonSets
+// matching the label selector in namespace ("" = all namespaces), sorted
+// by namespace/kind/name. HPAs are listed once per namespace; a missing HPA
+// API is not an error.
+func SelectWorkloads(ctx context.Context, client kubernetes.Interface, namespace, selector string) ([]BatchTarget, error) {
+	opts := metav1.ListOptions{LabelSelector: selector}
+	var targets []BatchTarget
+
+	deps, err := client.AppsV1().Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deps.Items {
+		d := &deps.Items[i]
+		targets = append(targets, newBatchTarget(KindDeployment, &d.ObjectMeta, replicasOrOne(d.Spec.Replicas), d.Spec.Selector, &d.Spec.Template))
+	}
+
+	stss, err := client.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range stss.Items {
+		s := &stss.Items[i]
+		targets = append(targets, newBatchTarget(KindStatefulSet, &s.ObjectMeta, replicasOrOne(s.Spec.Replicas), s.Spec.Selector, &s.Spec.Template))
+	}
+
+	dss, err := client.AppsV1().DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range dss.Items {
+		d := &dss.Items[i]
+		targets = append(targets, newBatchTarget(KindDaemonSet, &d.ObjectMeta, d.Status.DesiredNumberScheduled, d.Spec.Selector, &d.Spec.Template))
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Ref.FullString() < targets[j].Ref.FullString()
+	})
+	attachHPAs(ctx, client, targets)
+	attachOOMHistory(ctx, client, targets, OOMHistoryWindow)
+	return targets, nil
+}
+
+func newBatchTarget(kind string, meta *metav1.ObjectMeta, replicas int32, sel *metav1.LabelSelector, tmpl *corev1.PodTemplateSpec) BatchTarget {
+	t := BatchTarget{
+		Ref:        WorkloadRef{Kind: kind, Name: meta.Name, Namespace: meta.Namespace},
+		Replicas:   replicas,
+		Containers: extractContainerResources(tmpl.Spec.Containers),
+	}
+	if selector, err := metav1.LabelSelectorAsSelector(sel); err == nil && !selector.Empty() {
+		t.selector = selector
+	}
+	return t
+}
+
+func replicasOrOne(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+// attachHPAs sets the HPA of every target that one scales.
+func attachHPAs(ctx context.Context, client kubernetes.Interface, targets []BatchTarget) {
+	listed := map[string]bool{}
+	for i := range targets {
+		ns := targets[i].Ref.Namespace
+		if listed[ns] {
+			continue
+		}
+		listed[ns] = true
+		hpas, err := client.AutoscalingV2().HorizontalPodAutoscalers(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			continue // HPA API may not be available; not a fatal error
+		}
+		for j := range hpas.Items {
+			hpa := &hpas.Items[j]
+			for k := range targets {
+				if targets[k].Ref.Namespace == ns && matchesHPATarget(hpa, &targets[k].Ref) {
+					targets[k].HPA = &HPAInfo{
+						Name:       hpa.Name,
+						MinReplica: replicasOrOne(hpa.Spec.MinReplicas),
+						MaxReplica: hpa.Spec.MaxReplicas,
+					}
+				}
+			}
+		}
+	}
+}
+
+// BatchNamespaces returns the distinct namespaces of the targets, sorted.
+func BatchNamespaces(targets []BatchTarget) []string {
+	seen := map[string]bool{}
+	var namespaces []string
+	for _, t := range targets {
+		if !seen[t.Ref.Namespace] {
+			seen[t.Ref.Namespace] = true
+			namespaces = append(namespaces, t.Ref.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// BatchEntry is one workload's outcome in a batch report.
+type BatchEntry struct {
+	Workload       WorkloadRef              `json:"workload"`
+	Status         string                   `json:"status"`
+	Reason         string                   `json:"reason,omitempty"` // why no recommendation was produced
+	Replicas       int32                    `json:"replicas"`
+	Recommendation *AlignmentRecommendation `json:"recommendation,omitempty"`
+	Patch          string                   `json:"patch,omitempty"`      // SSA patch YAML
+	PatchFile      string                   `json:"patch_file,omitempty"` // set when patches are written to a directory
+}
+
+// BatchSummary totals a batch report. Request deltas are summed over all
+// replicas of the recommended workloads; negative values are savings.
+type BatchSummary struct {
+	Workloads          int     `json:"workloads"`
+	Recommended        int     `json:"recommended"`
+	Skipped            int     `json:"skipped"`
+	CPURequestDelta    float64 `json:"cpu_request_delta"`    // cores
+	MemoryRequestDelta float64 `json:"memory_request_delta"` // bytes
+}
+
+// BatchReport is the consolidated output of `pro-monitor batch`.
+type BatchReport struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Selector  string        `json:"selector"`
+	Namespace string        `json:"namespace,omitempty"` // empty = all namespaces
+	Duration  time.Duration `json:"duration"`
+	Interval  time.Duration `json:"interval"`
+	Policy    string        `json:"policy"`
+	Summary   BatchSummary  `json:"summary"`
+	Workloads []BatchEntry  `json:"workloads"`
+}
+
+// RecommendBatch computes a recommendation and SSA patch for every target
+// from its latch result, applying the same policy bounds as a single
+// latch, or the target's own bounds when set. Targets without a latch result or without an actionable
+// recommendation are reported as skipped with the reason.
+func RecommendBatch(targets []BatchTarget, latches map[WorkloadRef]*LatchResult, bounds *PolicyBounds) ([]BatchEntry, BatchSummary) {
+	entries := make([]BatchEntry, 0, len(targets))
+	var summary BatchSummary
+	for i := range targets {
+		t := &targets[i]
+		entry := BatchEntry{Workload: t.Ref, Status: BatchSkipped, Replicas: t.Replicas}
+		targetBounds := bounds
+		if t.Bounds != nil {
+			targetBounds = t.Bounds
+		}
+		rec := Recommend(&RecommendInput{
+			Latch:      latches[t.Ref],
+			Containers: t.Containers,
+			Bounds:     targetBounds,
+			HPA:        t.HPA,
+			OOMHistory: t.OOMHistory,
+		})
+		rec.Workload = t.Ref
+		entry.Recommendation = rec
+
+		if len(rec.Containers) == 0 {
+			entry.Reason = "no actionable recommendation"
+			if len(rec.Warnings) > 0 {
+				entry.Reason = rec.Warnings[len(rec.Warnings)-1]
+			}
+		} else if patch, err := Export(rec, FormatPatch, nil); err != nil {
+			entry.Reason = err.Error()
+		} else {
+			entry.Status = BatchRecommended
+			entry.Patch = patch
+			for _, c := range rec.Containers {
+				summary.CPURequestDelta += (c.Recommended.CPURequest - c.Current.CPURequest) * float64(t.Replicas)
+				summary.MemoryRequestDelta += (c.Recommended.MemoryRequest - c.Current.MemoryRequest) * float64(t.Replicas)
+			}
+		}
+
+		if entry.Status == BatchRecommended {
+			summary.Recommended++
+		} else {
+			summary.Skipped++
+		}
+		entries = append(entries, entry)
+	}
+	summary.Workloads = len(entries)
+	return entries, summary
+}
+
+// BatchPatchFilename returns the file name of a workload's patch in a
+// batch output directory.
+func BatchPatchFilename(ref WorkloadRef) string {
+	return fmt.Sprintf("%s__%s__%s.yaml", ref.Namespace, ref.Kind, ref.Name)
+}
@@ -0,0 +1,161 @@
+package promonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func batchTemplate() corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+	}}}}
+}
+
+func batchMeta(name, ns string, labels map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels}
+}
+
+func TestSelectWorkloads(t *testing.T) {
+	payments := map[string]string{"app.kubernetes.io/part-of": "payments"}
+	replicas := int32(3)
+	minReplicas := int32(2)
+	client := fake.NewClientset(
+		&appsv1.Deployment{ObjectMeta: batchMeta("api", "prod", payments),
+			Spec: appsv1.DeploymentSpec{Replicas: &replicas, Template: batchTemplate()}},
+		&appsv1.Deployment{ObjectMeta: batchMeta("web", "prod", map[string]string{"app.kubernetes.io/part-of": "storefront"}),
+			Spec: appsv1.DeploymentSpec{Template: batchTemplate()}},
+		&appsv1.StatefulSet{ObjectMeta: batchMeta("ledger", "data", payments),
+			Spec: appsv1.StatefulSetSpec{Template: batchTemplate()}},
+		&appsv1.DaemonSet{ObjectMeta: batchMeta("agent", "prod", payments),
+			Spec: appsv1.DaemonSetSpec{Template: batchTemplate()}, Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 5}},
+		&autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: batchMeta("api-hpa", "prod", nil),
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "api"},
+				MinReplicas:    &minReplicas, MaxReplicas: 10,
+			}},
+	)
+
+	targets, err := SelectWorkloads(context.Background(), client, "", "app.kubernetes.io/part-of=payments")
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+
+	assert.Equal(t, WorkloadRef{Kind: KindStatefulSet, Name: "ledger", Namespace: "data"}, targets[0].Ref)
+	assert.Equal(t, int32(1), targets[0].Replicas, "nil replicas default to 1")
+	assert.Equal(t, WorkloadRef{Kind: KindDaemonSet, Name: "agent", Namespace: "prod"}, targets[1].Ref)
+	assert.Equal(t, int32(5), targets[1].Replicas)
+	assert.Equal(t, WorkloadRef{Kind: KindDeployment, Name: "api", Namespace: "prod"}, targets[2].Ref)
+	assert.Equal(t, int32(3), targets[2].Replicas)
+
+	require.NotNil(t, targets[2].HPA)
+	assert.Equal(t, "api-hpa", targets[2].HPA.Name)
+	assert.Equal(t, int32(2), targets[2].HPA.MinReplica)
+	assert.Nil(t, targets[1].HPA)
+
+	require.Len(t, targets[2].Containers, 1)
+	assert.Equal(t, 1.0, targets[2].Containers[0].CPURequest)
+	assert.Equal(t, []string{"data", "prod"}, BatchNamespaces(targets))
+
+	scoped, err := SelectWorkloads(context.Background(), client, "data", "app.kubernetes.io/part-of=payments")
+	require.NoError(t, err)
+	require.Len(t, scoped, 1)
+	assert.Equal(t, "ledger", scoped[0].Ref.Name)
+}
+
+func TestRecommendBatch(t *testing.T) {
+	ok := WorkloadRef{Kind: KindDeployment, Name: "api", Namespace: "prod"}
+	unsafe := WorkloadRef{Kind: KindDeployment, Name: "crashy", Namespace: "prod"}
+	missing := WorkloadRef{Kind: KindStatefulSet, Name: "ledger", Namespace: "data"}
+	containers := []ContainerResources{{Name: "app", CPURequest: 1, CPULimit: 2, MemoryRequest: 1 << 30, MemoryLimit: 2 << 30}}
+
+	okLatch := testLatch(0.2, 0.3, 0.4, 256*1024*1024, 300*1024*1024, 320*1024*1024, &metrics.SpikeData{SampleCount: 180})
+	okLatch.Workload = ok
+	unsafeLatch := testLatch(0.2, 0.3, 0.4, 256*1024*1024, 300*1024*1024, 320*1024*1024, &metrics.SpikeData{OOMKills: 6})
+	unsafeLatch.Workload = unsafe
+
+	targets := []BatchTarget{
+		{Ref: missing, Replicas: 1, Containers: containers},
+		{Ref: ok, Replicas: 2, Containers: containers},
+		{Ref: unsafe, Replicas: 1, Containers: containers},
+	}
+	latches := map[WorkloadRef]*LatchResult{ok: okLatch, unsafe: unsafeLatch}
+
+	entries, summary := RecommendBatch(targets, latches, nil)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, BatchSkipped, entries[0].Status)
+	assert.Equal(t, "no latch data available", entries[0].Reason)
+	assert.Equal(t, missing, entries[0].Recommendation.Workload)
+
+	assert.Equal(t, BatchRecommended, entries[1].Status)
+	assert.Contains(t, entries[1].Patch, "name: api")
+	assert.Contains(t, entries[1].Patch, "namespace: prod")
+
+	assert.Equal(t, BatchSkipped, entries[2].Status)
+	assert.Contains(t, entries[2].Reason, "UNSAFE")
+	assert.Empty(t, entries[2].Patch)
+
+	assert.Equal(t, 3, summary.Workloads)
+	assert.Equal(t, 1, summary.Recommended)
+	assert.Equal(t, 2, summary.Skipped)
+	rec := entries[1].Recommendation.Containers[0]
+	assert.InDelta(t, (rec.Recommended.CPURequest-1)*2, summary.CPURequestDelta, 1e-9, "deltas scale with replicas")
+	assert.Less(t, summary.CPURequestDelta, 0.0)
+	assert.Less(t, summary.MemoryRequestDelta, 0.0)
+}
+
+func TestRecommendBatch_PolicyBounds(t *testing.T) {
+	ref := WorkloadRef{Kind: KindDeployment, Name: "api", Namespace: "prod"}
+	latch := testLatch(0.1, 0.15, 0.2, 128*1024*1024, 150*1024*1024, 160*1024*1024, &metrics.SpikeData{SampleCount: 180})
+	latch.Workload = ref
+	targets := []BatchTarget{{Ref: ref, Replicas: 1, Containers: []ContainerResources{
+		{Name: "app", CPURequest: 1, CPULimit: 2, MemoryRequest: 1 << 30, MemoryLimit: 2 << 30},
+	}}}
+
+	entries, _ := RecommendBatch(targets, map[WorkloadRef]*LatchResult{ref: latch}, &PolicyBounds{MaxRequestDeltaPct: 20})
+	require.Equal(t, BatchRecommended, entries[0].Status)
+	c := entries[0].Recommendation.Containers[0]
+	assert.True(t, c.Capped)
+	assert.InDelta(t, 0.8, c.Recommended.CPURequest, 1e-9, "request decrease capped at 20%")
+
+	entries, _ = RecommendBatch(targets, map[WorkloadRef]*LatchResult{ref: latch}, &PolicyBounds{MinSafetyRating: SafetyRatingSafe})
+	assert.Equal(t, BatchRecommended, entries[0].Status)
+}
+
+func TestRecommendBatch_TargetBounds(t *testing.T) {
+	ref := WorkloadRef{Kind: KindDeployment, Name: "api", Namespace: "prod"}
+	latch := testLatch(0.1, 0.15, 0.2, 128*1024*1024, 150*1024*1024, 160*1024*1024, &metrics.SpikeData{SampleCount: 180})
+	latch.Workload = ref
+	targets := []BatchTarget{{Ref: ref, Replicas: 1, Containers: []ContainerResources{
+		{Name: "app", CPURequest: 1, CPULimit: 2, MemoryRequest: 1 << 30, MemoryLimit: 2 << 30},
+	}, Bounds: &PolicyBounds{MaxRequestDeltaPct: 10}}}
+
+	entries, _ := RecommendBatch(targets, map[WorkloadRef]*LatchResult{ref: latch}, &PolicyBounds{MaxRequestDeltaPct: 50})
+	require.Equal(t, BatchRecommended, entries[0].Status)
+	assert.InDelta(t, 0.9, entries[0].Recommendation.Containers[0].Recommended.CPURequest, 1e-9, "namespace bounds win")
+}
+
+func TestBatchPatchFilename(t *testing.T) {
+	ref := WorkloadRef{Kind: KindDeployment, Name: "api", Namespace: "prod"}
+	assert.Equal(t, "prod__Deployment__api.yaml", BatchPatchFilename(ref))
+}
@@ -0,0 +1,228 @@
+package promonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DryRunPatchWorkload sends the server-side apply patch with dryRun=All and
+// returns the object the API server would persist, after defaulting and
+// mutating admission webhooks.
+func (a *ClientsetApplier) DryRunPatchWorkload(ctx context.Context, ref WorkloadRef, patchJSON []byte, fm string, force bool) (map[string]interface{}, error) {
+	opts := metav1.PatchOptions{FieldManager: fm, Force: &force, DryRun: []string{metav1.DryRunAll}}
+	var raw interface{}
+	switch ref.Kind {
+	case KindDeployment:
+		obj, err := a.Client.AppsV1().Deployments(ref.Namespace).Patch(ctx, ref.Name, types.ApplyPatchType, patchJSON, opts)
+		if err != nil {
+			return nil, err
+		}
+		raw = obj
+	case KindStatefulSet:
+		obj, err := a.Client.AppsV1().StatefulSets(ref.Namespace).Patch(ctx, ref.Name, types.ApplyPatchType, patchJSON, opts)
+		if err != nil {
+			return nil, err
+		}
+		raw = obj
+	case KindDaemonSet:
+		obj, err := a.Client.AppsV1().DaemonSets(ref.Namespace).Patch(ctx, ref.Name, types.ApplyPatchType, patchJSON, opts)
+		if err != nil {
+			return nil, err
+		}
+		raw = obj
+	case KindPod:
+		return nil, fmt.Errorf("apply is not supported for Pod kind (managed by external controller)")
+	default:
+		return nil, fmt.Errorf("unsupported kind: %s", ref.Kind)
+	}
+	return toObjectMap(raw)
+}
+
+// ApplyPreview is the server-side dry-run of an apply: what would change on
+// the live object, as admitted by the API server.
+type ApplyPreview struct {
+	Changes         []PreviewChange
+	ConflictManager string // field manager the apply would conflict with
+	GitOpsConflict  bool   // conflict with a GitOps controller; the apply will be refused
+	Error           error
+}
+
+// PreviewChange is one field the apply would change.
+type PreviewChange struct {
+	Path   string // e.g. spec.template.spec.containers[api].resources.requests.cpu
+	Before string // empty when the field is added
+	After  string // empty when the field is removed
+	// Mutated marks values kubenow did not send: set, rewritten, or reverted
+	// by defaulting or a mutating admission webhook.
+	Mutated bool
+}
+
+// MutatedCount returns the number of changes made by admission rather than
+// by the patch itself.
+func (p *ApplyPreview) MutatedCount() int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Mutated {
+			n++
+		}
+	}
+	return n
+}
+
+// previewIgnoredPaths are server bookkeeping fields that change on every
+// write, and kubenow's own timestamped apply and rollback annotations.
+var previewIgnoredPaths = []string{
+	"status",
+	"metadata.managedFields",
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.annotations.kubenow.dev/last-apply",
+	"metadata.annotations.kubenow.dev/last-rollback",
+}
+
+// PreviewApply runs the apply's server-side apply patch as a dry run and
+// diffs the admitted object against the live one. A conflict with a
+// non-GitOps manager is previewed with force, as ExecuteApply would retry.
+func PreviewApply(ctx context.Context, client KubeApplier, input *ApplyInput) *ApplyPreview {
+	patchJSON, err := buildSSAPatchJSON(input.Recommendation)
+	if err != nil {
+		return &ApplyPreview{Error: fmt.Errorf("failed to build patch: %w", err)}
+	}
+	return previewPatch(ctx, client, input.Workload, patchJSON)
+}
+
+// previewPatch dry-runs a server-side apply patch and diffs the result
+// against the live object.
+func previewPatch(ctx context.Context, client KubeApplier, ref WorkloadRef, patchJSON []byte) *ApplyPreview {
+	preview := &ApplyPreview{}
+
+	before, err := client.GetWorkloadObject(ctx, ref)
+	if err != nil {
+		preview.Error = fmt.Errorf("fetch live object: %w", err)
+		return preview
+	}
+
+	after, err := client.DryRunPatchWorkload(ctx, ref, patchJSON, fieldManager, false)
+	if err != nil && isConflictError(err) {
+		preview.ConflictManager = detectConflictManager(ctx, client, ref)
+		if isGitOpsManager(preview.ConflictManager) {
+			preview.GitOpsConflict = true
+			preview.Error = fmt.Errorf("ssa conflict: %w", err)
+			return preview
+		}
+		after, err = client.DryRunPatchWorkload(ctx, ref, patchJSON, fieldManager, true)
+	}
+	if err != nil {
+		preview.Error = fmt.Errorf("server dry-run: %w", err)
+		return preview
+	}
+
+	var patch map[string]interface{}
+	if err := json.Unmarshal(patchJSON, &patch); err != nil {
+		preview.Error = fmt.Errorf("decode patch: %w", err)
+		return preview
+	}
+	preview.Changes = diffObjects(flattenObject(before), flattenObject(after), flattenObject(patch))
+	return preview
+}
+
+// diffObjects lists the fields that differ between the live and admitted
+// objects, plus fields kubenow sent that were admitted with another value
+// even if that leaves them unchanged (a webhook reverting the change).
+func diffObjects(before, after, requested map[string]string) []PreviewChange {
+	var changes []PreviewChange
+	seen := make(map[string]bool, len(after))
+	add := func(path string) {
+		if seen[path] || previewIgnored(path) {
+			return
+		}
+		seen[path] = true
+
+		sent, isSent := requested[path]
+		changed := !sameValue(before[path], after[path])
+		mutated := (isSent && !sameValue(sent, after[path])) || (!isSent && changed)
+		if changed || mutated {
+			changes = append(changes, PreviewChange{Path: path, Before: before[path], After: after[path], Mutated: mutated})
+		}
+	}
+	for path := range after {
+		add(path)
+	}
+	for path := range before {
+		add(path)
+	}
+	for path := range requested {
+		add(path)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func previewIgnored(path string) bool {
+	for _, p := range previewIgnoredPaths {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue compares field values, treating equal resource quantities in
+// different notations ("0.5" and "500m") as the same.
+func sameValue(a, b string) bool {
+	if a == b {
+		return true
+	}
+	qa, errA := resource.ParseQuantity(a)
+	qb, errB := resource.ParseQuantity(b)
+	return errA == nil && errB == nil && qa.Cmp(qb) == 0
+}
+
+// flattenObject maps every scalar field of a generic object to its path.
+// List elements with a name (containers, env, ports) are keyed by it, so
+// paths stay stable when the server reorders or injects elements.
+func flattenObject(obj map[string]interface{}) map[string]string {
+	out := map[string]string{}
+	flattenValue("", obj, out)
+	return out
+}
+
+func flattenValue(path string, v interface{}, out map[string]string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			flattenValue(p, child, out)
+		}
+	case []interface{}:
+		for i, child := range val {
+			key := strconv.Itoa(i)
+			if m, ok := child.(map[string]interface{}); ok {
+				if name, ok := m["name"].(string); ok && name != "" {
+					key = name
+				}
+			}
+			flattenValue(fmt.Sprintf("%s[%s]", path, key), child, out)
+		}
+	case nil:
+		out[path] = "null"
+	case string:
+		out[path] = val
+	case float64:
+		out[path] = strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		out[path] = fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,149 @@
+package promonitor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deploymentObject builds a generic Deployment object with one "api"
+// container, plus any extra containers.
+func deploymentObject(resourceVersion string, resources map[string]interface{}, extra ...map[string]interface{}) map[string]interface{} {
+	containers := []interface{}{map[string]interface{}{"name": "api", "image": "api:1", "resources": resources}}
+	for _, c := range extra {
+		containers = append(containers, c)
+	}
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name": "api", "namespace": "default", "resourceVersion": resourceVersion,
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		},
+		"status": map[string]interface{}{"replicas": float64(2)},
+	}
+}
+
+func resources(cpuReq, cpuLim, memReq, memLim string) map[string]interface{} {
+	return map[string]interface{}{
+		"requests": map[string]interface{}{"cpu": cpuReq, "memory": memReq},
+		"limits":   map[string]interface{}{"cpu": cpuLim, "memory": memLim},
+	}
+}
+
+func changesByPath(p *ApplyPreview) map[string]PreviewChange {
+	out := map[string]PreviewChange{}
+	for _, c := range p.Changes {
+		out[c.Path] = c
+	}
+	return out
+}
+
+const apiResources = "spec.template.spec.containers[api].resources."
+
+func TestPreviewApply_AdmittedAsRequested(t *testing.T) {
+	mock := &mockKubeApplier{
+		workloadObject: deploymentObject("1", resources("100m", "500m", "128Mi", "512Mi")),
+		// The server canonicalizes "600m" the same way; only the values change.
+		dryRunObject: deploymentObject("2", resources("150m", "600m", "200Mi", "600Mi")),
+	}
+
+	preview := PreviewApply(context.Background(), mock, validApplyInput())
+	require.NoError(t, preview.Error)
+	assert.False(t, mock.dryRunForced)
+	assert.Equal(t, 0, preview.MutatedCount())
+
+	changes := changesByPath(preview)
+	assert.Len(t, changes, 4, "status and resourceVersion are not part of the diff")
+	assert.Equal(t, PreviewChange{Path: apiResources + "requests.cpu", Before: "100m", After: "150m"}, changes[apiResources+"requests.cpu"])
+	assert.Equal(t, "600Mi", changes[apiResources+"limits.memory"].After)
+}
+
+func TestPreviewApply_WebhookMutations(t *testing.T) {
+	sidecar := map[string]interface{}{"name": "istio-proxy", "image": "proxyv2:1.22"}
+	mock := &mockKubeApplier{
+		workloadObject: deploymentObject("1", resources("100m", "500m", "128Mi", "512Mi")),
+		// A webhook enforces a 1 cpu limit floor, reverts the memory limit,
+		// and injects a sidecar.
+		dryRunObject: deploymentObject("2", resources("150m", "1", "200Mi", "512Mi"), sidecar),
+	}
+
+	preview := PreviewApply(context.Background(), mock, validApplyInput())
+	require.NoError(t, preview.Error)
+
+	changes := changesByPath(preview)
+	assert.True(t, changes[apiResources+"limits.cpu"].Mutated, "admitted 1 instead of the requested 600m")
+	assert.False(t, changes[apiResources+"requests.cpu"].Mutated)
+
+	reverted, ok := changes[apiResources+"limits.memory"]
+	require.True(t, ok, "a reverted change is listed even though the value does not change")
+	assert.True(t, reverted.Mutated)
+	assert.Equal(t, "512Mi", reverted.After)
+
+	injected := changes["spec.template.spec.containers[istio-proxy].image"]
+	assert.True(t, injected.Mutated)
+	assert.Empty(t, injected.Before)
+	assert.Equal(t, 4, preview.MutatedCount())
+}
+
+func TestPreviewApply_Conflicts(t *testing.T) {
+	live := deploymentObject("1", resources("100m", "500m", "128Mi", "512Mi"))
+	admitted := deploymentObject("2", resources("150m", "600m", "200Mi", "600Mi"))
+
+	t.Run("gitops manager", func(t *testing.T) {
+		mock := &mockKubeApplier{
+			workloadObject: live,
+			dryRunObject:   admitted,
+			dryRunErr:      fmt.Errorf("Apply failed with 1 conflict"),
+			managedFields:  []metav1.ManagedFieldsEntry{{Manager: "argocd-controller"}},
+		}
+		preview := PreviewApply(context.Background(), mock, validApplyInput())
+		assert.True(t, preview.GitOpsConflict)
+		assert.Equal(t, "argocd-controller", preview.ConflictManager)
+		assert.Error(t, preview.Error)
+		assert.False(t, mock.dryRunForced)
+	})
+
+	t.Run("other manager is previewed with force", func(t *testing.T) {
+		mock := &mockKubeApplier{
+			workloadObject: live,
+			dryRunObject:   admitted,
+			dryRunErr:      fmt.Errorf("Apply failed with 1 conflict"),
+			managedFields:  []metav1.ManagedFieldsEntry{{Manager: "kubectl-edit"}},
+		}
+		preview := PreviewApply(context.Background(), mock, validApplyInput())
+		require.NoError(t, preview.Error)
+		assert.True(t, mock.dryRunForced)
+		assert.Equal(t, "kubectl-edit", preview.ConflictManager)
+		assert.Len(t, preview.Changes, 4)
+	})
+}
+
+func TestPreviewApply_DryRunRejected(t *testing.T) {
+	mock := &mockKubeApplier{
+		workloadObject: deploymentObject("1", resources("100m", "500m", "128Mi", "512Mi")),
+		dryRunErr:      fmt.Errorf("admission webhook denied the request"),
+	}
+	preview := PreviewApply(context.Background(), mock, validApplyInput())
+	require.Error(t, preview.Error)
+	assert.Contains(t, preview.Error.Error(), "admission webhook denied")
+	assert.Empty(t, preview.Changes)
+}
+
+func TestSameValue(t *testing.T) {
+	assert.True(t, sameValue("500m", "0.5"))
+	assert.True(t, sameValue("1Gi", "1024Mi"))
+	assert.True(t, sameValue("", ""))
+	assert.False(t, sameValue("500m", "600m"))
+	assert.False(t, sameValue("500m", ""))
+	assert.False(t, sameValue("api:1", "api:2"))
+}
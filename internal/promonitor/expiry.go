@@ -0,0 +1,114 @@
+package promonitor
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// Recommendation validity bounds. A recommendation stays valid until its
+// usage trend, extrapolated from the latch, drifts trendDrift above the
+// usage it was sized for.
+const (
+	MaxRecommendationValidity     = 30 * 24 * time.Hour // flat or shrinking usage
+	MinRecommendationValidity     = 24 * time.Hour
+	DefaultRecommendationValidity = 14 * 24 * time.Hour // latch too short to read a trend
+
+	trendDrift      = 0.20
+	minTrendSpan    = time.Hour
+	minTrendSamples = 12
+)
+
+// UsageTrend is the linear growth of workload usage over a latch, as a
+// fraction of mean usage per day (0.05 = +5%/day; negative = shrinking).
+type UsageTrend struct {
+	CPUGrowthPerDay    float64       `json:"cpu_growth_per_day"`
+	MemoryGrowthPerDay float64       `json:"memory_growth_per_day"`
+	Span               time.Duration `json:"span"` // time covered by the samples
+}
+
+// Growth returns the faster of CPU and memory growth.
+func (t *UsageTrend) Growth() float64 {
+	return math.Max(t.CPUGrowthPerDay, t.MemoryGrowthPerDay)
+}
+
+// ComputeUsageTrend fits a least-squares line through the latch's pod-total
+// samples. Returns nil when the samples span too little time for a trend.
+func ComputeUsageTrend(data *metrics.SpikeData) *UsageTrend {
+	if data == nil || data.SampleCount < 2 || len(data.CPUSamples) < minTrendSamples {
+		return nil
+	}
+	step := data.LastSeen.Sub(data.FirstSeen) / time.Duration(data.SampleCount-1)
+	span := step * time.Duration(len(data.CPUSamples)-1)
+	if step <= 0 || span < minTrendSpan {
+		return nil
+	}
+	perDay := float64(24*time.Hour) / float64(step)
+	return &UsageTrend{
+		CPUGrowthPerDay:    relativeSlope(data.CPUSamples) * perDay,
+		MemoryGrowthPerDay: relativeSlope(data.MemSamples) * perDay,
+		Span:               span,
+	}
+}
+
+// relativeSlope is the per-sample slope of samples divided by their mean.
+func relativeSlope(samples []float64) float64 {
+	n := float64(len(samples))
+	if n < 2 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range samples {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	mean := sumY / n
+	denom := n*sumXX - sumX*sumX
+	if mean <= 0 || denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom / mean
+}
+
+// ValidityWindow is how long a recommendation sized on a latch with this
+// trend stays valid: the time for usage to grow trendDrift at the observed
+// rate, between MinRecommendationValidity and MaxRecommendationValidity.
+func ValidityWindow(trend *UsageTrend) time.Duration {
+	if trend == nil {
+		return DefaultRecommendationValidity
+	}
+	growth := trend.Growth()
+	if growth <= 0 {
+		return MaxRecommendationValidity
+	}
+	days := trendDrift / growth
+	if days >= MaxRecommendationValidity.Hours()/24 {
+		return MaxRecommendationValidity
+	}
+	return max(time.Duration(days*float64(24*time.Hour)), MinRecommendationValidity)
+}
+
+// Expired reports whether the recommendation's validity window has ended.
+// Recommendations without a window (saved before expiry existed, or not
+// actionable) never expire.
+func (r *AlignmentRecommendation) Expired(now time.Time) bool {
+	return !r.ValidUntil.IsZero() && now.After(r.ValidUntil)
+}
+
+// expiryReason explains an expired recommendation for denial messages.
+func expiryReason(r *AlignmentRecommendation) string {
+	msg := fmt.Sprintf("recommendation expired at %s", r.ValidUntil.Format(time.RFC3339))
+	if r.Trend != nil && r.Trend.Growth() > 0 {
+		msg += fmt.Sprintf(" (usage growing %s/day)", fmtGrowth(r.Trend.Growth()))
+	}
+	return msg + ": re-latch to refresh it"
+}
+
+func fmtGrowth(g float64) string {
+	return fmt.Sprintf("%+.1f%%", g*100)
+}
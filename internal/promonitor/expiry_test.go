@@ -0,0 +1,97 @@
+package promonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+// trendData samples every 5 minutes over span, with memory growing by
+// memGrowthPerDay of its starting value.
+func trendData(span time.Duration, memGrowthPerDay float64) *metrics.SpikeData {
+	const step = 5 * time.Minute
+	n := int(span/step) + 1
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	data := &metrics.SpikeData{SampleCount: n, FirstSeen: start, LastSeen: start.Add(span)}
+	for i := 0; i < n; i++ {
+		days := float64(time.Duration(i)*step) / float64(24*time.Hour)
+		data.CPUSamples = append(data.CPUSamples, 0.5)
+		data.MemSamples = append(data.MemSamples, 256*1024*1024*(1+memGrowthPerDay*days))
+	}
+	return data
+}
+
+func TestComputeUsageTrend(t *testing.T) {
+	trend := ComputeUsageTrend(trendData(24*time.Hour, 0.10))
+	require.NotNil(t, trend)
+	assert.InDelta(t, 0, trend.CPUGrowthPerDay, 1e-9)
+	// The slope is relative to mean usage (1.05x the start), not the start.
+	assert.InDelta(t, 0.10/1.05, trend.MemoryGrowthPerDay, 0.001)
+	assert.Equal(t, 24*time.Hour, trend.Span)
+
+	assert.Nil(t, ComputeUsageTrend(trendData(30*time.Minute, 0.10)), "too short for a trend")
+	assert.Nil(t, ComputeUsageTrend(nil))
+}
+
+func TestValidityWindow(t *testing.T) {
+	tests := []struct {
+		name  string
+		trend *UsageTrend
+		want  time.Duration
+	}{
+		{"unknown trend", nil, DefaultRecommendationValidity},
+		{"flat", &UsageTrend{}, MaxRecommendationValidity},
+		{"shrinking", &UsageTrend{CPUGrowthPerDay: -0.05, MemoryGrowthPerDay: -0.01}, MaxRecommendationValidity},
+		{"slow growth caps at max", &UsageTrend{MemoryGrowthPerDay: 0.001}, MaxRecommendationValidity},
+		{"2%/day drifts 20% in 10 days", &UsageTrend{MemoryGrowthPerDay: 0.02}, 10 * 24 * time.Hour},
+		{"fastest resource wins", &UsageTrend{CPUGrowthPerDay: 0.05, MemoryGrowthPerDay: 0.02}, 4 * 24 * time.Hour},
+		{"fast growth floors at min", &UsageTrend{CPUGrowthPerDay: 2}, MinRecommendationValidity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidityWindow(tt.trend))
+		})
+	}
+}
+
+func TestRecommend_ValidUntil(t *testing.T) {
+	latch := testLatch(0.1, 0.12, 0.15, 100*1024*1024, 110*1024*1024, 120*1024*1024, trendData(24*time.Hour, 0.10))
+	latch.Timestamp = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	rec := Recommend(&RecommendInput{
+		Latch:      latch,
+		Containers: []ContainerResources{testContainer(0.5, 1.0, 512*1024*1024, 1024*1024*1024)},
+	})
+	require.NotEmpty(t, rec.Containers)
+	require.NotNil(t, rec.Trend)
+
+	// The window runs from the end of the latch: ~2.1 days at ~9.5%/day.
+	window := rec.ValidUntil.Sub(latch.Timestamp)
+	assert.InDelta(t, 2.1*24, window.Hours(), 1)
+	assert.False(t, rec.Expired(latch.Timestamp.Add(24*time.Hour)))
+	assert.True(t, rec.Expired(latch.Timestamp.Add(3*24*time.Hour)))
+}
+
+func TestExpired_NoWindow(t *testing.T) {
+	rec := &AlignmentRecommendation{}
+	assert.False(t, rec.Expired(time.Now()), "recommendations saved before expiry never expire")
+}
+
+func TestCheckActionable_ExpiredRecommendation(t *testing.T) {
+	input := validApplyInput()
+	input.AuditWritable, input.IdentityRecorded, input.RateLimitOK = true, true, true
+	input.Recommendation.ValidUntil = time.Now().Add(-time.Hour)
+	input.Recommendation.Trend = &UsageTrend{MemoryGrowthPerDay: 0.08}
+
+	reasons := CheckActionable(input)
+	require.Len(t, reasons, 1)
+	assert.Contains(t, reasons[0], "recommendation expired")
+	assert.Contains(t, reasons[0], "+8.0%/day")
+
+	input.Recommendation.ValidUntil = time.Now().Add(time.Hour)
+	assert.Empty(t, CheckActionable(input))
+}
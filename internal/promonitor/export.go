@@ -3,12 +3,13 @@ package promonitor
 import (
 	"encoding/json"
 	"fmt"
-	"math"
-	"os"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // ExportFormat represents the output format for export.
@@ -22,8 +23,18 @@ const (
 	FormatJSON      ExportFormat = "json"
 	FormatKustomize ExportFormat = "kustomize"
 	FormatHelm      ExportFormat = "helm"
+	FormatAuto      ExportFormat = "auto" // helm, kustomize, or patch depending on the workload's GitOps source
 )
 
+// ResolveFormat resolves FormatAuto from the detected GitOps source; other
+// formats are returned unchanged.
+func ResolveFormat(format ExportFormat, src *GitOpsSource) ExportFormat {
+	if format == FormatAuto {
+		return FormatForSource(src)
+	}
+	return format
+}
+
 // Export generates output in the requested format.
 // currentJSON is required only for FormatManifest (the full K8s object as JSON).
 func Export(rec *AlignmentRecommendation, format ExportFormat, currentJSON []byte) (string, error) {
@@ -31,7 +42,7 @@ func Export(rec *AlignmentRecommendation, format ExportFormat, currentJSON []byt
 		return "", fmt.Errorf("no recommendation to export")
 	}
 
-	switch format {
+	switch ResolveFormat(format, rec.Source) {
 	case FormatPatch:
 		return exportPatch(rec)
 	case FormatManifest:
@@ -48,7 +59,7 @@ func Export(rec *AlignmentRecommendation, format ExportFormat, currentJSON []byt
 	case FormatHelm:
 		return exportHelm(rec)
 	default:
-		return "", fmt.Errorf("unsupported export format: %q (supported: patch, manifest, diff, json, kustomize, helm)", format)
+		return "", fmt.Errorf("unsupported export format: %q (supported: patch, manifest, diff, json, kustomize, helm, auto)", format)
 	}
 }
 
@@ -62,7 +73,7 @@ func ExportToFile(rec *AlignmentRecommendation, workload WorkloadRef) (string, e
 	ts := time.Now().Format("20060102-150405")
 	filename := fmt.Sprintf("kubenow-patch-%s-%s-%s-%s.yaml",
 		strings.ToLower(workload.Kind), workload.Namespace, workload.Name, ts)
-	if err := os.WriteFile(filename, []byte(output), 0o600); err != nil {
+	if err := cleanup.WriteFile(filename, []byte(output), 0o600); err != nil {
 		return "", fmt.Errorf("failed to write export file: %w", err)
 	}
 	return filename, nil
@@ -117,13 +128,10 @@ func exportPatch(rec *AlignmentRecommendation) (string, error) {
 			Name: c.Name,
 			Resources: patchResources{
 				Requests: map[string]string{
-					"cpu":    formatCPUResource(c.Recommended.CPURequest),
-					"memory": formatMemResource(c.Recommended.MemoryRequest),
-				},
-				Limits: map[string]string{
-					"cpu":    formatCPUResource(c.Recommended.CPULimit),
-					"memory": formatMemResource(c.Recommended.MemoryLimit),
+					"cpu":    units.CPUQuantity(c.Recommended.CPURequest),
+					"memory": units.MemoryQuantity(c.Recommended.MemoryRequest),
 				},
+				Limits: recommendedLimits(c.Recommended),
 			},
 		}
 	}
@@ -225,13 +233,10 @@ func updateContainerResources(obj map[string]interface{}, containers []Container
 
 			container["resources"] = map[string]interface{}{
 				"requests": map[string]interface{}{
-					"cpu":    formatCPUResource(rec.Recommended.CPURequest),
-					"memory": formatMemResource(rec.Recommended.MemoryRequest),
-				},
-				"limits": map[string]interface{}{
-					"cpu":    formatCPUResource(rec.Recommended.CPULimit),
-					"memory": formatMemResource(rec.Recommended.MemoryLimit),
+					"cpu":    units.CPUQuantity(rec.Recommended.CPURequest),
+					"memory": units.MemoryQuantity(rec.Recommended.MemoryRequest),
 				},
+				"limits": recommendedLimits(rec.Recommended),
 			}
 		}
 	}
@@ -250,11 +255,11 @@ func exportDiff(rec *AlignmentRecommendation) string {
 		c := &rec.Containers[i]
 		b.WriteString(fmt.Sprintf("\n  Container: %s\n", c.Name))
 		b.WriteString("    requests:\n")
-		writeDiffLine(&b, "cpu", formatCPUResource(c.Current.CPURequest), formatCPUResource(c.Recommended.CPURequest))
-		writeDiffLine(&b, "memory", formatMemResource(c.Current.MemoryRequest), formatMemResource(c.Recommended.MemoryRequest))
+		writeDiffLine(&b, "cpu", units.CPUQuantity(c.Current.CPURequest), units.CPUQuantity(c.Recommended.CPURequest))
+		writeDiffLine(&b, "memory", units.MemoryQuantity(c.Current.MemoryRequest), units.MemoryQuantity(c.Recommended.MemoryRequest))
 		b.WriteString("    limits:\n")
-		writeDiffLine(&b, "cpu", formatCPUResource(c.Current.CPULimit), formatCPUResource(c.Recommended.CPULimit))
-		writeDiffLine(&b, "memory", formatMemResource(c.Current.MemoryLimit), formatMemResource(c.Recommended.MemoryLimit))
+		writeDiffLine(&b, "cpu", units.CPUQuantity(c.Current.CPULimit), units.CPUQuantity(c.Recommended.CPULimit))
+		writeDiffLine(&b, "memory", units.MemoryQuantity(c.Current.MemoryLimit), units.MemoryQuantity(c.Recommended.MemoryLimit))
 	}
 
 	// Warnings
@@ -298,6 +303,13 @@ func evidenceComments(rec *AlignmentRecommendation) string {
 	if rec.Evidence != nil {
 		b.WriteString(fmt.Sprintf("# Latch: %s (%d samples)\n",
 			rec.Evidence.Duration.String(), rec.Evidence.SampleCount))
+		if ev := rec.Evidence; ev.NetworkRX != nil && ev.NetworkTX != nil {
+			b.WriteString(fmt.Sprintf("# Network p95: rx %s/s, tx %s/s (CPU correlation %.2f)\n",
+				units.Memory(ev.NetworkRX.P95), units.Memory(ev.NetworkTX.P95), ev.NetworkCPUCorrelation))
+		}
+	}
+	if !rec.ValidUntil.IsZero() {
+		b.WriteString(fmt.Sprintf("# Valid until: %s\n", rec.ValidUntil.UTC().Format(time.RFC3339)))
 	}
 
 	// HPA warning
@@ -311,33 +323,20 @@ func evidenceComments(rec *AlignmentRecommendation) string {
 	return b.String()
 }
 
-// formatCPUResource converts CPU cores to a K8s resource string.
-// Examples: 0.1 → "100m", 1.0 → "1", 0.5 → "500m"
-func formatCPUResource(cores float64) string {
-	m := int(math.Round(cores * 1000))
-	if m <= 0 {
-		return "0m"
-	}
-	if m%1000 == 0 {
-		return fmt.Sprintf("%d", m/1000)
+// formatCPULimitResource formats a CPU limit. Zero means "no CPU limit"
+// (e.g. policy forbids CPU limits) and is returned as "" so it is omitted.
+func formatCPULimitResource(cores float64) string {
+	if cores <= 0 {
+		return ""
 	}
-	return fmt.Sprintf("%dm", m)
+	return units.CPUQuantity(cores)
 }
 
-// formatMemResource converts memory bytes to a K8s resource string.
-// Examples: 134217728 → "128Mi", 1073741824 → "1Gi"
-func formatMemResource(bytes float64) string {
-	b := int64(math.Round(bytes))
-	if b <= 0 {
-		return "0"
-	}
-	gi := b / (1024 * 1024 * 1024)
-	if gi > 0 && b%(1024*1024*1024) == 0 {
-		return fmt.Sprintf("%dGi", gi)
-	}
-	mi := b / (1024 * 1024)
-	if mi > 0 {
-		return fmt.Sprintf("%dMi", mi)
+// recommendedLimits builds the limits map for export, omitting an unset CPU limit.
+func recommendedLimits(v ResourceValues) map[string]string {
+	limits := map[string]string{"memory": units.MemoryQuantity(v.MemoryLimit)}
+	if cpu := formatCPULimitResource(v.CPULimit); cpu != "" {
+		limits["cpu"] = cpu
 	}
-	return fmt.Sprintf("%d", b)
+	return limits
 }
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // helmResources holds CPU/memory requests and limits for Helm values output.
@@ -22,6 +24,16 @@ func exportHelm(rec *AlignmentRecommendation) (string, error) {
 	b.WriteString("# kubenow helm values override\n")
 	b.WriteString(fmt.Sprintf("# Workload: %s/%s/%s\n",
 		rec.Workload.Namespace, strings.ToLower(rec.Workload.Kind), rec.Workload.Name))
+	if src := rec.Source; src != nil && src.Tool == ToolHelm {
+		b.WriteString(fmt.Sprintf("# Source: %s\n", src))
+		if src.Release != "" {
+			ns := src.ReleaseNamespace
+			if ns == "" {
+				ns = rec.Workload.Namespace
+			}
+			b.WriteString(fmt.Sprintf("# Apply with: helm upgrade %s <chart> -n %s --reuse-values -f <this-file>\n", src.Release, ns))
+		}
+	}
 
 	if len(rec.Containers) == 1 {
 		b.WriteString("# Place these values at the appropriate path in your chart's values.yaml\n")
@@ -53,12 +65,9 @@ func exportHelm(rec *AlignmentRecommendation) (string, error) {
 func containerHelmResources(c *ContainerAlignment) helmResources {
 	return helmResources{
 		Requests: map[string]string{
-			"cpu":    formatCPUResource(c.Recommended.CPURequest),
-			"memory": formatMemResource(c.Recommended.MemoryRequest),
-		},
-		Limits: map[string]string{
-			"cpu":    formatCPUResource(c.Recommended.CPULimit),
-			"memory": formatMemResource(c.Recommended.MemoryLimit),
+			"cpu":    units.CPUQuantity(c.Recommended.CPURequest),
+			"memory": units.MemoryQuantity(c.Recommended.MemoryRequest),
 		},
+		Limits: recommendedLimits(c.Recommended),
 	}
 }
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // kustomizationDoc represents a kustomization.yaml file.
@@ -67,13 +69,10 @@ func exportKustomize(rec *AlignmentRecommendation) (string, error) {
 			Name: c.Name,
 			Resources: patchResources{
 				Requests: map[string]string{
-					"cpu":    formatCPUResource(c.Recommended.CPURequest),
-					"memory": formatMemResource(c.Recommended.MemoryRequest),
-				},
-				Limits: map[string]string{
-					"cpu":    formatCPUResource(c.Recommended.CPULimit),
-					"memory": formatMemResource(c.Recommended.MemoryLimit),
+					"cpu":    units.CPUQuantity(c.Recommended.CPURequest),
+					"memory": units.MemoryQuantity(c.Recommended.MemoryRequest),
 				},
+				Limits: recommendedLimits(c.Recommended),
 			},
 		}
 	}
@@ -100,6 +99,9 @@ func exportKustomize(rec *AlignmentRecommendation) (string, error) {
 	// Combine as multi-document YAML
 	var b strings.Builder
 	b.WriteString(evidenceComments(rec))
+	if src := rec.Source; src != nil && src.Tool == ToolKustomize {
+		b.WriteString(fmt.Sprintf("# Source: %s\n", src))
+	}
 	b.WriteString("# kustomization.yaml\n")
 	b.Write(kustomizationYAML)
 	b.WriteString("---\n")
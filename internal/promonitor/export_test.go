@@ -96,6 +96,21 @@ func TestExportPatch_ResourceValues(t *testing.T) {
 	assert.Contains(t, output, "memory: 1Gi") // 1024Mi → 1Gi
 }
 
+func TestExportPatch_OmitsZeroCPULimit(t *testing.T) {
+	rec := testRecommendation()
+	rec.Containers[0].Recommended.CPULimit = 0
+	output, err := Export(rec, FormatPatch, nil)
+	require.NoError(t, err)
+
+	var patch map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(output), &patch))
+	spec := patch["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	container := spec["containers"].([]interface{})[0].(map[string]interface{})
+	limits := container["resources"].(map[string]interface{})["limits"].(map[string]interface{})
+	assert.NotContains(t, limits, "cpu")
+	assert.Equal(t, "1Gi", limits["memory"])
+}
+
 func TestExportPatch_MultiContainer(t *testing.T) {
 	rec := testRecommendation()
 	rec.Containers = append(rec.Containers, ContainerAlignment{
@@ -254,50 +269,6 @@ func TestExportManifest_ValidOutput(t *testing.T) {
 	assert.Contains(t, output, "290Mi") // new memory request
 }
 
-// --- Format helpers ---
-
-func TestFormatCPUResource(t *testing.T) {
-	tests := []struct {
-		cores float64
-		want  string
-	}{
-		{0, "0m"},
-		{0.001, "1m"},
-		{0.05, "50m"},
-		{0.1, "100m"},
-		{0.5, "500m"},
-		{1.0, "1"},
-		{2.0, "2"},
-		{2.5, "2500m"},
-	}
-	for _, tt := range tests {
-		t.Run(tt.want, func(t *testing.T) {
-			assert.Equal(t, tt.want, formatCPUResource(tt.cores))
-		})
-	}
-}
-
-func TestFormatMemResource(t *testing.T) {
-	tests := []struct {
-		bytes float64
-		want  string
-	}{
-		{0, "0"},
-		{1024, "1024"},                       // less than 1Mi → raw bytes
-		{64 * 1024 * 1024, "64Mi"},           // 64Mi
-		{128 * 1024 * 1024, "128Mi"},         // 128Mi
-		{512 * 1024 * 1024, "512Mi"},         // 512Mi
-		{1024 * 1024 * 1024, "1Gi"},          // 1Gi
-		{2 * 1024 * 1024 * 1024, "2Gi"},      // 2Gi
-		{1.5 * 1024 * 1024 * 1024, "1536Mi"}, // 1.5Gi → Mi
-	}
-	for _, tt := range tests {
-		t.Run(tt.want, func(t *testing.T) {
-			assert.Equal(t, tt.want, formatMemResource(tt.bytes))
-		})
-	}
-}
-
 // --- Error cases ---
 
 func TestExport_NilRecommendation(t *testing.T) {
@@ -322,6 +293,36 @@ func TestExport_EmptyContainers(t *testing.T) {
 	assert.Contains(t, output, "# kubenow alignment patch")
 }
 
+func TestExportHelm_SourceComments(t *testing.T) {
+	rec := testRecommendation()
+	rec.Source = &GitOpsSource{Tool: ToolHelm, Release: "payment-api", ReleaseNamespace: "payments", Chart: "payment-api-1.4.0"}
+	output, err := Export(rec, FormatHelm, nil)
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "# Source: Helm release payments/payment-api (chart payment-api-1.4.0)")
+	assert.Contains(t, output, "# Apply with: helm upgrade payment-api <chart> -n payments --reuse-values -f <this-file>")
+}
+
+func TestExport_AutoFormat(t *testing.T) {
+	rec := testRecommendation()
+
+	output, err := Export(rec, FormatAuto, nil)
+	require.NoError(t, err)
+	assert.Contains(t, output, "# kubenow alignment patch")
+	assert.NotContains(t, output, "kustomization.yaml")
+
+	rec.Source = &GitOpsSource{Tool: ToolHelm}
+	output, err = Export(rec, FormatAuto, nil)
+	require.NoError(t, err)
+	assert.Contains(t, output, "# kubenow helm values override")
+
+	rec.Source = &GitOpsSource{Tool: ToolKustomize, Kustomization: "apps"}
+	output, err = Export(rec, FormatAuto, nil)
+	require.NoError(t, err)
+	assert.Contains(t, output, "# kustomization.yaml")
+	assert.Contains(t, output, "# Source: Flux Kustomization apps")
+}
+
 // --- Volatile field stripping ---
 
 // --- Kustomize format ---
@@ -0,0 +1,145 @@
+package promonitor
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GitOps tools detected from workload metadata.
+const (
+	ToolHelm      = "helm"
+	ToolKustomize = "kustomize"
+)
+
+// Metadata keys written by Helm, Flux, and kustomize.
+const (
+	annotationHelmRelease          = "meta.helm.sh/release-name"
+	annotationHelmReleaseNamespace = "meta.helm.sh/release-namespace"
+	annotationKustomizeOrigin      = "config.kubernetes.io/origin"
+	labelManagedBy                 = "app.kubernetes.io/managed-by"
+	labelHelmChart                 = "helm.sh/chart"
+	labelFluxHelmRelease           = "helm.toolkit.fluxcd.io/name"
+	labelFluxKustomization         = "kustomize.toolkit.fluxcd.io/name"
+)
+
+// GitOpsSource describes the tool that renders a workload, as detected from
+// its labels and annotations.
+type GitOpsSource struct {
+	Tool             string `json:"tool"`                        // helm or kustomize
+	Release          string `json:"release,omitempty"`           // Helm release name
+	ReleaseNamespace string `json:"release_namespace,omitempty"` // Helm release namespace
+	Chart            string `json:"chart,omitempty"`             // Helm chart (name-version)
+	Kustomization    string `json:"kustomization,omitempty"`     // Flux Kustomization name
+}
+
+// DetectGitOpsSource inspects workload metadata for Helm and kustomize
+// markers. Helm wins when both are present (Flux can post-render Helm
+// charts with kustomize). Returns nil when no tool is detected.
+func DetectGitOpsSource(meta *metav1.ObjectMeta) *GitOpsSource {
+	if meta == nil {
+		return nil
+	}
+	annotations, labels := meta.Annotations, meta.Labels
+
+	release := annotations[annotationHelmRelease]
+	if release == "" {
+		release = labels[labelFluxHelmRelease]
+	}
+	if release != "" || labels[labelManagedBy] == "Helm" || labels[labelHelmChart] != "" {
+		return &GitOpsSource{
+			Tool:             ToolHelm,
+			Release:          release,
+			ReleaseNamespace: annotations[annotationHelmReleaseNamespace],
+			Chart:            labels[labelHelmChart],
+		}
+	}
+
+	if name := labels[labelFluxKustomization]; name != "" {
+		return &GitOpsSource{Tool: ToolKustomize, Kustomization: name}
+	}
+	if annotations[annotationKustomizeOrigin] != "" {
+		return &GitOpsSource{Tool: ToolKustomize}
+	}
+	return nil
+}
+
+// String returns a short human-readable description, e.g.
+// "Helm release payments/payment-api (chart payment-api-1.4.0)".
+func (s *GitOpsSource) String() string {
+	switch s.Tool {
+	case ToolHelm:
+		desc := "Helm"
+		if s.Release != "" {
+			desc += " release "
+			if s.ReleaseNamespace != "" {
+				desc += s.ReleaseNamespace + "/"
+			}
+			desc += s.Release
+		}
+		if s.Chart != "" {
+			desc += fmt.Sprintf(" (chart %s)", s.Chart)
+		}
+		return desc
+	case ToolKustomize:
+		if s.Kustomization != "" {
+			return "Flux Kustomization " + s.Kustomization
+		}
+		return "kustomize"
+	default:
+		return s.Tool
+	}
+}
+
+// FormatForSource returns the export format matching a workload's GitOps
+// tool: Helm values for Helm releases, a Kustomize overlay for kustomize,
+// and an SSA patch otherwise.
+func FormatForSource(src *GitOpsSource) ExportFormat {
+	if src == nil {
+		return FormatPatch
+	}
+	switch src.Tool {
+	case ToolHelm:
+		return FormatHelm
+	case ToolKustomize:
+		return FormatKustomize
+	default:
+		return FormatPatch
+	}
+}
+
+// FetchGitOpsSource reads the workload and detects its GitOps tool.
+func FetchGitOpsSource(ctx context.Context, client kubernetes.Interface, ref *WorkloadRef) (*GitOpsSource, error) {
+	var meta *metav1.ObjectMeta
+	switch ref.Kind {
+	case KindDeployment:
+		obj, err := client.AppsV1().Deployments(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot read deployment: %w", err)
+		}
+		meta = &obj.ObjectMeta
+	case KindStatefulSet:
+		obj, err := client.AppsV1().StatefulSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot read statefulset: %w", err)
+		}
+		meta = &obj.ObjectMeta
+	case KindDaemonSet:
+		obj, err := client.AppsV1().DaemonSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot read daemonset: %w", err)
+		}
+		meta = &obj.ObjectMeta
+	case KindPod:
+		obj, err := client.CoreV1().Pods(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot read pod: %w", err)
+		}
+		meta = &obj.ObjectMeta
+	default:
+		return nil, fmt.Errorf("unsupported kind: %s", ref.Kind)
+	}
+	return DetectGitOpsSource(meta), nil
+}
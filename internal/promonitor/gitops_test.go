@@ -0,0 +1,88 @@
+package promonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDetectGitOpsSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        *GitOpsSource
+	}{
+		{name: "unmanaged", want: nil},
+		{
+			name:        "helm release annotations",
+			labels:      map[string]string{labelManagedBy: "Helm", labelHelmChart: "payment-api-1.4.0"},
+			annotations: map[string]string{annotationHelmRelease: "payment-api", annotationHelmReleaseNamespace: "payments"},
+			want:        &GitOpsSource{Tool: ToolHelm, Release: "payment-api", ReleaseNamespace: "payments", Chart: "payment-api-1.4.0"},
+		},
+		{
+			name:   "managed-by label only",
+			labels: map[string]string{labelManagedBy: "Helm"},
+			want:   &GitOpsSource{Tool: ToolHelm},
+		},
+		{
+			name:   "flux helm release",
+			labels: map[string]string{labelFluxHelmRelease: "payment-api", labelFluxKustomization: "apps"},
+			want:   &GitOpsSource{Tool: ToolHelm, Release: "payment-api"},
+		},
+		{
+			name:   "flux kustomization",
+			labels: map[string]string{labelFluxKustomization: "apps"},
+			want:   &GitOpsSource{Tool: ToolKustomize, Kustomization: "apps"},
+		},
+		{
+			name:        "kustomize build metadata",
+			annotations: map[string]string{annotationKustomizeOrigin: "path: base/deployment.yaml"},
+			want:        &GitOpsSource{Tool: ToolKustomize},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := &metav1.ObjectMeta{Labels: tt.labels, Annotations: tt.annotations}
+			assert.Equal(t, tt.want, DetectGitOpsSource(meta))
+		})
+	}
+}
+
+func TestGitOpsSource_String(t *testing.T) {
+	assert.Equal(t, "Helm release payments/payment-api (chart payment-api-1.4.0)",
+		(&GitOpsSource{Tool: ToolHelm, Release: "payment-api", ReleaseNamespace: "payments", Chart: "payment-api-1.4.0"}).String())
+	assert.Equal(t, "Helm", (&GitOpsSource{Tool: ToolHelm}).String())
+	assert.Equal(t, "Flux Kustomization apps", (&GitOpsSource{Tool: ToolKustomize, Kustomization: "apps"}).String())
+}
+
+func TestResolveFormat(t *testing.T) {
+	assert.Equal(t, FormatPatch, ResolveFormat(FormatAuto, nil))
+	assert.Equal(t, FormatHelm, ResolveFormat(FormatAuto, &GitOpsSource{Tool: ToolHelm}))
+	assert.Equal(t, FormatKustomize, ResolveFormat(FormatAuto, &GitOpsSource{Tool: ToolKustomize}))
+	assert.Equal(t, FormatDiff, ResolveFormat(FormatDiff, &GitOpsSource{Tool: ToolHelm}), "explicit format wins")
+}
+
+func TestFetchGitOpsSource(t *testing.T) {
+	client := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "payment-api",
+			Namespace:   "payments",
+			Annotations: map[string]string{annotationHelmRelease: "payment-api"},
+		},
+	})
+	ref := &WorkloadRef{Kind: KindDeployment, Name: "payment-api", Namespace: "payments"}
+
+	src, err := FetchGitOpsSource(context.Background(), client, ref)
+	require.NoError(t, err)
+	require.NotNil(t, src)
+	assert.Equal(t, ToolHelm, src.Tool)
+
+	_, err = FetchGitOpsSource(context.Background(), client, &WorkloadRef{Kind: KindDeployment, Name: "missing", Namespace: "payments"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package promonitor
+
+import "github.com/ppiankov/kubenow/internal/keymap"
+
+// Pro-monitor TUI actions, as named in the keybindings.pro-monitor config
+// section. The "type apply" confirmation is text input and not remappable.
+const (
+	actionQuit      keymap.Action = "quit"
+	actionStopEarly keymap.Action = "stop-early"
+	actionExport    keymap.Action = "export"
+	actionExposure  keymap.Action = "exposure-map"
+	actionTraffic   keymap.Action = "traffic-map"
+	actionApply     keymap.Action = "apply"
+	actionShare     keymap.Action = "share"
+)
+
+// DefaultKeyBindings are the pro-monitor TUI's bindings before config overrides.
+var DefaultKeyBindings = []keymap.Binding{
+	{Action: actionQuit, Keys: []string{"q"}, Description: "quit"},
+	{Action: actionStopEarly, Keys: []string{"esc"}, Description: "stop latching early (press twice)"},
+	{Action: actionExport, Keys: []string{"e"}, Description: "export recommendation as a patch"},
+	{Action: actionExposure, Keys: []string{"l"}, Description: "toggle exposure map"},
+	{Action: actionTraffic, Keys: []string{"t"}, Description: "toggle Linkerd traffic map"},
+	{Action: actionApply, Keys: []string{"a"}, Description: "apply recommendation (asks for confirmation)"},
+	{Action: actionShare, Keys: []string{"s"}, Description: "publish a read-only evidence page and show its link"},
+	{Action: keymap.Help, Keys: []string{"?"}, Description: "toggle this help"},
+}
+
+// NewKeyMap builds the pro-monitor key map with config overrides applied.
+func NewKeyMap(overrides map[string][]string) (*keymap.Map, error) {
+	return keymap.New(DefaultKeyBindings, overrides)
+}
@@ -13,8 +13,10 @@ import (
 
 	"github.com/ppiankov/kubenow/internal/audit"
 	"github.com/ppiankov/kubenow/internal/exposure"
+	"github.com/ppiankov/kubenow/internal/keymap"
 	"github.com/ppiankov/kubenow/internal/metrics"
 	"github.com/ppiankov/kubenow/internal/policy"
+	"github.com/ppiankov/kubenow/internal/storage"
 )
 
 // Mode describes what operations the policy allows.
@@ -33,6 +35,7 @@ type Model struct {
 	workload     WorkloadRef
 	operatorType string // CRD operator type (e.g. "CNPG", "Strimzi"), empty for standard workloads
 	hpaInfo      *HPAInfo
+	oomHistory   *OOMHistory // OOM kills before the latch
 	mode         Mode
 	policyMsg    string // Short policy status line
 
@@ -61,9 +64,18 @@ type Model struct {
 	exportPath  string // path to exported file
 	exportError error  // export error if any
 
+	// Share state (triggered by 's' key)
+	sharing     bool          // true while the evidence page is being published
+	shareResult *ShareResult  // set after a successful publish
+	shareError  error         // publish error if any
+	shareTTL    time.Duration // share link lifetime (zero = DefaultShareTTL)
+	version     string        // kubenow version, shown on shared pages
+
 	// Apply state
 	confirming      bool            // true when confirmation prompt is active
 	confirmInput    textinput.Model // textinput for "apply" confirmation
+	previewing      bool            // true while the server-side dry-run is in flight
+	preview         *ApplyPreview   // dry-run diff shown in the confirmation prompt
 	applying        bool            // true while SSA patch is in flight
 	applyResult     *ApplyResult    // set after apply completes
 	hpaAcknowledged bool            // set via --acknowledge-hpa
@@ -89,6 +101,8 @@ type Model struct {
 	trafficLoading bool
 
 	// UI state
+	keys     *keymap.Map
+	showHelp bool // key binding overlay (triggered by '?')
 	spinner  spinner.Model
 	width    int
 	height   int
@@ -118,6 +132,20 @@ type applyDoneMsg struct {
 	result *ApplyResult
 }
 
+// shareDoneMsg carries the published evidence page, plus the maps collected
+// for it so the overlays do not query them again.
+type shareDoneMsg struct {
+	result   *ShareResult
+	exposure *exposure.ExposureMap
+	traffic  *exposure.TrafficMap
+	err      error
+}
+
+// previewDoneMsg carries the server-side dry-run of the apply.
+type previewDoneMsg struct {
+	preview *ApplyPreview
+}
+
 // exposureDoneMsg carries the exposure map query result.
 type exposureDoneMsg struct {
 	m   *exposure.ExposureMap
@@ -142,6 +170,7 @@ func NewModel(ref WorkloadRef, latch *metrics.LatchMonitor, duration time.Durati
 		policyMsg:     policyMsg,
 		latch:         latch,
 		latchDuration: duration,
+		keys:          keymap.MustDefault(DefaultKeyBindings),
 		spinner:       s,
 	}
 }
@@ -159,6 +188,7 @@ func NewAnalyzeModel(ref WorkloadRef, mode Mode, policyMsg string, hpa *HPAInfo,
 		policyMsg:      policyMsg,
 		latchDone:      true,
 		recommendation: rec,
+		keys:           keymap.MustDefault(DefaultKeyBindings),
 		spinner:        s,
 	}
 	if latchResult != nil {
@@ -204,6 +234,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateRecommendDone(msg)
 	case exportDoneMsg:
 		return m.updateExportDone(msg)
+	case previewDoneMsg:
+		return m.updatePreviewDone(msg)
+	case shareDoneMsg:
+		return m.updateShareDone(msg)
 	case applyDoneMsg:
 		return m.updateApplyDone(msg)
 	case exposureDoneMsg:
@@ -219,24 +253,37 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m *Model) updateKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
-	if m.shouldDismissEarlyStop(key) {
+	if key == keymap.ForceQuit {
+		return m.handleQuitKey()
+	}
+	action := m.keys.Action(key)
+	if m.shouldDismissEarlyStop(action) {
 		m.earlyStopPending = false
 		return m, nil
 	}
+	if m.showHelp && action != actionQuit {
+		// Any key closes the help overlay; quit still quits.
+		m.showHelp = false
+		return m, nil
+	}
 
-	switch key {
-	case "q", "ctrl+c":
+	switch action {
+	case actionQuit:
 		return m.handleQuitKey()
-	case "esc":
+	case actionStopEarly:
 		return m.handleEscapeKey()
-	case "e":
+	case actionExport:
 		return m.handleExportKey()
-	case "l":
+	case actionExposure:
 		return m.handleExposureToggle()
-	case "t":
+	case actionTraffic:
 		return m.handleTrafficToggle()
-	case "a":
+	case actionApply:
 		return m.handleApplyKey()
+	case actionShare:
+		return m.handleShareKey()
+	case keymap.Help:
+		m.showHelp = true
 	}
 
 	return m, nil
@@ -286,6 +333,39 @@ func (m *Model) updateExportDone(msg exportDoneMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m *Model) updateShareDone(msg shareDoneMsg) (tea.Model, tea.Cmd) {
+	m.sharing = false
+	if m.exposureMap == nil {
+		m.exposureMap = msg.exposure
+	}
+	if m.trafficMap == nil {
+		m.trafficMap = msg.traffic
+	}
+	if msg.err != nil {
+		m.shareError = msg.err
+		return m, nil
+	}
+
+	m.shareError = nil
+	m.shareResult = msg.result
+	return m, nil
+}
+
+func (m *Model) updatePreviewDone(msg previewDoneMsg) (tea.Model, tea.Cmd) {
+	m.previewing = false
+	m.preview = msg.preview
+	if msg.preview.GitOpsConflict {
+		// The apply would be refused for the same reason; report it now.
+		m.applyResult = &ApplyResult{
+			ConflictManager: msg.preview.ConflictManager,
+			GitOpsConflict:  true,
+			Error:           msg.preview.Error,
+		}
+		return m, nil
+	}
+	return m, m.openConfirmation()
+}
+
 func (m *Model) updateApplyDone(msg applyDoneMsg) (tea.Model, tea.Cmd) {
 	m.applying = false
 	m.applyResult = msg.result
@@ -320,17 +400,11 @@ func (m *Model) updateSpinnerTick(msg spinner.TickMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m *Model) shouldDismissEarlyStop(key string) bool {
+func (m *Model) shouldDismissEarlyStop(action keymap.Action) bool {
 	if !m.earlyStopPending {
 		return false
 	}
-
-	switch key {
-	case "esc", "q", "ctrl+c":
-		return false
-	default:
-		return true
-	}
+	return action != actionStopEarly && action != actionQuit
 }
 
 func (m *Model) handleQuitKey() (tea.Model, tea.Cmd) {
@@ -373,7 +447,7 @@ func (m *Model) handleExportKey() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleApplyKey() (tea.Model, tea.Cmd) {
-	if m.recommendation == nil || m.mode != ModeApplyReady || m.applyResult != nil || m.applying {
+	if m.recommendation == nil || m.mode != ModeApplyReady || m.applyResult != nil || m.applying || m.previewing {
 		return m, nil
 	}
 
@@ -384,13 +458,73 @@ func (m *Model) handleApplyKey() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.kubeApplier == nil || input.Workload.Kind == KindPod {
+		return m, m.openConfirmation()
+	}
+
+	// Dry-run the patch server-side first so the prompt shows what the API
+	// server would admit, including webhook mutations.
+	m.previewing = true
+	client := m.kubeApplier
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		return previewDoneMsg{preview: PreviewApply(ctx, client, input)}
+	}
+}
+
+// handleShareKey publishes the recommendation with its evidence to the
+// configured store. Exposure and traffic maps not yet loaded are collected
+// first, best-effort: the page is still shared without them.
+func (m *Model) handleShareKey() (tea.Model, tea.Cmd) {
+	if m.recommendation == nil || m.sharing || m.shareResult != nil {
+		return m, nil
+	}
+
+	m.sharing = true
+	m.shareError = nil
+	ref := m.workload
+	collector := m.exposureCollector
+	bundle := &ShareBundle{
+		Recommendation: m.recommendation,
+		Exposure:       m.exposureMap,
+		Traffic:        m.trafficMap,
+		Version:        m.version,
+	}
+	ttl := m.shareTTL
+	if ttl <= 0 {
+		ttl = DefaultShareTTL
+	}
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if collector != nil && bundle.Exposure == nil {
+			bundle.Exposure, _ = collector.Collect(ctx, ref.Namespace, ref.Name, ref.Kind)
+		}
+		if collector != nil && collector.HasPrometheus() && bundle.Traffic == nil {
+			bundle.Traffic, _ = collector.CollectTrafficMap(ctx, ref.Namespace, ref.Name)
+		}
+
+		msg := shareDoneMsg{exposure: bundle.Exposure, traffic: bundle.Traffic}
+		store, err := storage.Default()
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.result, msg.err = PublishShare(ctx, store, bundle, ttl)
+		return msg
+	}
+}
+
+// openConfirmation shows the "type apply" prompt.
+func (m *Model) openConfirmation() tea.Cmd {
 	ti := textinput.New()
 	ti.Placeholder = `type "apply" to confirm`
 	_ = ti.Focus()
 	ti.CharLimit = 10
 	m.confirmInput = ti
 	m.confirming = true
-	return m, ti.Focus()
+	return ti.Focus()
 }
 
 func (m *Model) refreshLatchData(updateOperator bool) {
@@ -576,6 +710,10 @@ func (m *Model) executeApplyCmd() tea.Cmd {
 		} else {
 			result = ExecuteApply(context.Background(), client, input)
 		}
+		if result.Applied && fullPolicy != nil {
+			result.Annotated, result.AnnotationError = AnnotateApply(
+				context.Background(), fullPolicy.Annotate.Grafana, input.Recommendation, time.Now())
+		}
 		return applyDoneMsg{result: result}
 	}
 }
@@ -595,6 +733,11 @@ func (m *Model) SetContainers(c []ContainerResources) {
 	m.containers = c
 }
 
+// SetOOMHistory sets the workload's OOM kills before the latch.
+func (m *Model) SetOOMHistory(h *OOMHistory) {
+	m.oomHistory = h
+}
+
 // SetPolicyBounds sets the policy guardrails for recommendation.
 func (m *Model) SetPolicyBounds(b *PolicyBounds) {
 	m.policyBounds = b
@@ -605,6 +748,11 @@ func (m *Model) SetInterval(d time.Duration) {
 	m.latchInterval = d
 }
 
+// SetKeyMap replaces the default key bindings.
+func (m *Model) SetKeyMap(keys *keymap.Map) {
+	m.keys = keys
+}
+
 // SetKubeApplier sets the Kubernetes client for SSA apply.
 func (m *Model) SetKubeApplier(a KubeApplier) {
 	m.kubeApplier = a
@@ -645,6 +793,27 @@ func (m *Model) SetExposureCollector(c *exposure.ExposureCollector) {
 	m.exposureCollector = c
 }
 
+// SetShareTTL sets how long share links stay valid.
+func (m *Model) SetShareTTL(ttl time.Duration) {
+	m.shareTTL = ttl
+}
+
+// SetVersion sets the kubenow version shown on shared pages.
+func (m *Model) SetVersion(v string) {
+	m.version = v
+}
+
+// LatchCompleted reports whether the latch ran to its end or was stopped
+// early with Esc, rather than quit before its data was kept.
+func (m *Model) LatchCompleted() bool {
+	return m.latchDone && m.err == nil
+}
+
+// ShareResult returns the evidence page published from the TUI, or nil.
+func (m *Model) ShareResult() *ShareResult {
+	return m.shareResult
+}
+
 func tickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
 		return tickMsg(t)
@@ -662,6 +831,7 @@ func (m *Model) computeRecommendationCmd() tea.Cmd {
 	containers := m.containers
 	bounds := m.policyBounds
 	hpa := m.hpaInfo
+	oomHistory := m.oomHistory
 
 	return func() tea.Msg {
 		// Get latch data for the target workload
@@ -688,6 +858,7 @@ func (m *Model) computeRecommendationCmd() tea.Cmd {
 			Containers: containers,
 			Bounds:     bounds,
 			HPA:        hpa,
+			OOMHistory: oomHistory,
 		})
 
 		// Add early-stop warning
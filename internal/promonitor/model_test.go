@@ -398,11 +398,13 @@ func TestRenderTrafficMap_WithData(t *testing.T) {
 		},
 		TCPIn:  847,
 		TCPOut: 1204,
+		Mesh:   exposure.MeshIstio,
 		Window: time.Hour,
 	}
 
 	view := renderTrafficMap(tm)
-	assert.Contains(t, view, "Traffic Map")
+	assert.Contains(t, view, "Traffic Map (Istio, 1h0m0s window)")
+	assert.Contains(t, view, "Data from Istio proxy metrics")
 	assert.Contains(t, view, "Inbound")
 	assert.Contains(t, view, "payment-api")
 	assert.Contains(t, view, "142.3 rps")
@@ -440,3 +442,108 @@ func TestRenderTrafficMap_UnknownLatency(t *testing.T) {
 	assert.NotContains(t, view, "p50:")
 	assert.NotContains(t, view, "p99:")
 }
+
+func TestModel_Update_PreviewDone_OpensConfirmation(t *testing.T) {
+	ref := WorkloadRef{Kind: "Deployment", Name: "api", Namespace: "default"}
+	m := NewModel(ref, nil, 15*time.Minute, ModeApplyReady, "test", nil)
+	m.recommendation = validApplyInput().Recommendation
+	m.previewing = true
+
+	updated, _ := m.Update(previewDoneMsg{preview: &ApplyPreview{Changes: []PreviewChange{
+		{Path: apiResources + "requests.cpu", Before: "100m", After: "150m"},
+		{Path: apiResources + "limits.cpu", Before: "500m", After: "1", Mutated: true},
+	}}})
+	model := updated.(*Model)
+	assert.False(t, model.previewing)
+	assert.True(t, model.confirming)
+
+	view := model.View()
+	assert.Contains(t, view, "Server dry-run diff:")
+	assert.Contains(t, view, "requests.cpu: 100m → 150m")
+	assert.Contains(t, view, "limits.cpu: 500m → 1  (admission)")
+	assert.Contains(t, view, "1 value(s) set by defaulting or a mutating webhook")
+}
+
+func TestModel_Update_PreviewDone_GitOpsConflict(t *testing.T) {
+	ref := WorkloadRef{Kind: "Deployment", Name: "api", Namespace: "default"}
+	m := NewModel(ref, nil, 15*time.Minute, ModeApplyReady, "test", nil)
+	m.previewing = true
+
+	updated, _ := m.Update(previewDoneMsg{preview: &ApplyPreview{
+		ConflictManager: "flux",
+		GitOpsConflict:  true,
+		Error:           fmt.Errorf("ssa conflict"),
+	}})
+	model := updated.(*Model)
+	assert.False(t, model.confirming, "no confirmation for an apply that would be refused")
+	if assert.NotNil(t, model.applyResult) {
+		assert.True(t, model.applyResult.GitOpsConflict)
+		assert.Equal(t, "flux", model.applyResult.ConflictManager)
+	}
+}
+
+func TestModel_KeyMap_RemappedApplyAndHelp(t *testing.T) {
+	ref := WorkloadRef{Kind: "Deployment", Name: "api", Namespace: "default"}
+	m := NewModel(ref, nil, 15*time.Minute, ModeExportOnly, "test", nil)
+	keys, err := NewKeyMap(map[string][]string{"export": {"x"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	m.SetKeyMap(keys)
+	m.recommendation = &AlignmentRecommendation{Workload: ref, Safety: SafetyRatingSafe, Confidence: ConfidenceHigh}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	assert.Nil(t, cmd, "e is no longer bound")
+	assert.Contains(t, m.View(), "x: export")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	assert.True(t, m.showHelp)
+	assert.Contains(t, m.View(), "export recommendation as a patch")
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	assert.Nil(t, cmd, "the first key closes the help overlay")
+	assert.False(t, m.showHelp)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	assert.NotNil(t, cmd)
+}
+
+func TestModel_Update_ShareKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ref := WorkloadRef{Kind: "Deployment", Name: "payment-api", Namespace: "default"}
+	m := NewModel(ref, nil, 15*time.Minute, ModeExportOnly, "test", nil)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	assert.Nil(t, cmd, "nothing to share before the recommendation")
+
+	m.recommendation = testRecommendation()
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	assert.True(t, m.sharing)
+	assert.Contains(t, m.View(), "Publishing evidence page")
+
+	updated, _ := m.Update(cmd())
+	model := updated.(*Model)
+	assert.False(t, model.sharing)
+	assert.NoError(t, model.shareError)
+	if assert.NotNil(t, model.ShareResult()) {
+		assert.Contains(t, model.View(), "Shared: file://")
+	}
+	assert.NotContains(t, model.View(), "s: share")
+
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	assert.Nil(t, cmd, "shared once per session")
+}
+
+func TestModel_Update_ShareDone_Error(t *testing.T) {
+	ref := WorkloadRef{Kind: "Deployment", Name: "api", Namespace: "default"}
+	m := NewModel(ref, nil, 15*time.Minute, ModeExportOnly, "test", nil)
+	m.recommendation = testRecommendation()
+	m.sharing = true
+	traffic := &exposure.TrafficMap{Window: time.Hour}
+
+	updated, _ := m.Update(shareDoneMsg{traffic: traffic, err: fmt.Errorf("access denied")})
+	model := updated.(*Model)
+	assert.Nil(t, model.ShareResult())
+	assert.Same(t, traffic, model.trafficMap, "maps collected for the page are kept for the overlays")
+	assert.Contains(t, model.View(), "Share failed: access denied")
+	assert.Contains(t, model.View(), "s: share", "a failed share can be retried")
+}
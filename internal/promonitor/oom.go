@@ -0,0 +1,137 @@
+package promonitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// OOMHistoryWindow is how far before the latch pro-monitor looks for OOM
+// kills. Kills during the latch are in the latch data and rate it UNSAFE.
+const OOMHistoryWindow = 7 * 24 * time.Hour
+
+// OOMHistory is the OOM kills of a workload's pods over a window before
+// the latch, as still recorded in their container statuses.
+type OOMHistory struct {
+	Kills    int
+	LastKill time.Time
+	Window   time.Duration
+}
+
+// FetchOOMHistory counts the OOM kills of the workload's current pods in
+// the window before now. Returns nil when the pods cannot be read.
+func FetchOOMHistory(ctx context.Context, client kubernetes.Interface, ref *WorkloadRef, window time.Duration) *OOMHistory {
+	since := time.Now().Add(-window)
+	if ref.Kind == KindPod {
+		pod, err := client.CoreV1().Pods(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		return countOOMKills([]corev1.Pod{*pod}, labels.Everything(), since, window)
+	}
+
+	sel, err := workloadSelector(ctx, client, ref)
+	if err != nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(sel)
+	if err != nil {
+		return nil
+	}
+	pods, err := client.CoreV1().Pods(ref.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil
+	}
+	return countOOMKills(pods.Items, selector, since, window)
+}
+
+func workloadSelector(ctx context.Context, client kubernetes.Interface, ref *WorkloadRef) (*metav1.LabelSelector, error) {
+	switch ref.Kind {
+	case KindDeployment:
+		obj, err := client.AppsV1().Deployments(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return obj.Spec.Selector, nil
+	case KindStatefulSet:
+		obj, err := client.AppsV1().StatefulSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return obj.Spec.Selector, nil
+	case KindDaemonSet:
+		obj, err := client.AppsV1().DaemonSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return obj.Spec.Selector, nil
+	default:
+		return nil, fmt.Errorf("unsupported kind: %s", ref.Kind)
+	}
+}
+
+// countOOMKills counts the OOMKilled container terminations since the
+// given time among the pods matching selector.
+func countOOMKills(pods []corev1.Pod, selector labels.Selector, since time.Time, window time.Duration) *OOMHistory {
+	h := &OOMHistory{Window: window}
+	for i := range pods {
+		pod := &pods[i]
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		for j := range pod.Status.ContainerStatuses {
+			cs := &pod.Status.ContainerStatuses[j]
+			for _, term := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+				if term == nil || term.Reason != "OOMKilled" || term.FinishedAt.Time.Before(since) {
+					continue
+				}
+				h.Kills++
+				if term.FinishedAt.After(h.LastKill) {
+					h.LastKill = term.FinishedAt.Time
+				}
+			}
+		}
+	}
+	return h
+}
+
+// attachOOMHistory sets the OOM history of every target, listing pods
+// once per namespace.
+func attachOOMHistory(ctx context.Context, client kubernetes.Interface, targets []BatchTarget, window time.Duration) {
+	since := time.Now().Add(-window)
+	pods := map[string][]corev1.Pod{}
+	for i := range targets {
+		t := &targets[i]
+		if t.selector == nil {
+			continue
+		}
+		ns := t.Ref.Namespace
+		list, ok := pods[ns]
+		if !ok {
+			if l, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{}); err == nil {
+				list = l.Items
+			}
+			pods[ns] = list
+		}
+		t.OOMHistory = countOOMKills(list, t.selector, since, window)
+	}
+}
+
+// holdMemoryReductions keeps memory requests and limits at their current
+// values wherever the recommendation would lower them. Setting a limit on
+// a container without one counts as lowering it.
+func holdMemoryReductions(a *ContainerAlignment) {
+	if a.Recommended.MemoryRequest < a.Current.MemoryRequest {
+		a.Recommended.MemoryRequest = a.Current.MemoryRequest
+		a.Delta.MemoryRequestPercent = 0
+	}
+	if a.Current.MemoryLimit == 0 || a.Recommended.MemoryLimit < a.Current.MemoryLimit {
+		a.Recommended.MemoryLimit = a.Current.MemoryLimit
+		a.Delta.MemoryLimitPercent = 0
+	}
+}
@@ -0,0 +1,105 @@
+package promonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+func oomPod(name string, labels map[string]string, killedAt ...time.Time) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod", Labels: labels}}
+	for _, at := range killedAt {
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name: "app",
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "OOMKilled", FinishedAt: metav1.NewTime(at),
+			}},
+		})
+	}
+	return pod
+}
+
+func TestFetchOOMHistory(t *testing.T) {
+	now := time.Now()
+	app := map[string]string{"app": "api"}
+	client := fake.NewClientset(
+		&appsv1.Deployment{ObjectMeta: batchMeta("api", "prod", app),
+			Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: app}, Template: batchTemplate()}},
+		oomPod("api-1", app, now.Add(-2*24*time.Hour)),
+		oomPod("api-2", app, now.Add(-3*time.Hour), now.Add(-30*24*time.Hour)),
+		oomPod("worker-1", map[string]string{"app": "worker"}, now.Add(-time.Hour)),
+	)
+
+	ref := &WorkloadRef{Kind: KindDeployment, Name: "api", Namespace: "prod"}
+	h := FetchOOMHistory(context.Background(), client, ref, OOMHistoryWindow)
+	require.NotNil(t, h)
+	assert.Equal(t, 2, h.Kills, "kills outside the window and of other workloads are ignored")
+	assert.WithinDuration(t, now.Add(-3*time.Hour), h.LastKill, time.Second)
+	assert.Equal(t, OOMHistoryWindow, h.Window)
+
+	missing := &WorkloadRef{Kind: KindDeployment, Name: "gone", Namespace: "prod"}
+	assert.Nil(t, FetchOOMHistory(context.Background(), client, missing, OOMHistoryWindow))
+}
+
+func TestSelectWorkloads_OOMHistory(t *testing.T) {
+	app := map[string]string{"app": "api"}
+	client := fake.NewClientset(
+		&appsv1.Deployment{ObjectMeta: batchMeta("api", "prod", app),
+			Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: app}, Template: batchTemplate()}},
+		oomPod("api-1", app, time.Now().Add(-time.Hour)),
+	)
+
+	targets, err := SelectWorkloads(context.Background(), client, "prod", "")
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.NotNil(t, targets[0].OOMHistory)
+	assert.Equal(t, 1, targets[0].OOMHistory.Kills)
+}
+
+func TestRecommend_OOMHistoryBlocksMemoryReduction(t *testing.T) {
+	data := &metrics.SpikeData{SampleCount: 180}
+	latch := testLatch(0.08, 0.12, 0.15, 170e6, 200e6, 220e6, data)
+	container := testContainer(0.5, 1, 512e6, 1024e6)
+
+	rec := Recommend(&RecommendInput{Latch: latch, Containers: []ContainerResources{container}})
+	require.Len(t, rec.Containers, 1)
+	assert.Equal(t, SafetyRatingSafe, rec.Safety)
+	assert.Less(t, rec.Containers[0].Recommended.MemoryRequest, container.MemoryRequest)
+
+	history := &OOMHistory{Kills: 2, LastKill: time.Now().Add(-time.Hour), Window: OOMHistoryWindow}
+	rec = Recommend(&RecommendInput{Latch: latch, Containers: []ContainerResources{container}, OOMHistory: history})
+	require.Len(t, rec.Containers, 1)
+	c := rec.Containers[0]
+	assert.Equal(t, SafetyRatingCaution, rec.Safety, "SAFE is downgraded")
+	assert.Less(t, c.Recommended.CPURequest, container.CPURequest, "CPU is still reduced")
+	assert.Equal(t, container.MemoryRequest, c.Recommended.MemoryRequest)
+	assert.Equal(t, container.MemoryLimit, c.Recommended.MemoryLimit)
+	assert.Zero(t, c.Delta.MemoryRequestPercent)
+	assert.Zero(t, c.Delta.MemoryLimitPercent)
+	assert.Contains(t, rec.Warnings[0], "2 OOMKill(s) in the 1w before the latch")
+
+	// Memory increases still go through
+	small := testContainer(0.5, 1, 64e6, 128e6)
+	rec = Recommend(&RecommendInput{Latch: latch, Containers: []ContainerResources{small}, OOMHistory: history})
+	assert.Greater(t, rec.Containers[0].Recommended.MemoryRequest, small.MemoryRequest)
+	assert.Greater(t, rec.Containers[0].Recommended.MemoryLimit, small.MemoryLimit)
+}
+
+func TestRecommend_OOMHistoryNoLimitAdded(t *testing.T) {
+	latch := testLatch(0.08, 0.12, 0.15, 170e6, 200e6, 220e6, &metrics.SpikeData{SampleCount: 180})
+	container := testContainer(0.5, 0, 512e6, 0)
+	history := &OOMHistory{Kills: 1, LastKill: time.Now(), Window: OOMHistoryWindow}
+
+	rec := Recommend(&RecommendInput{Latch: latch, Containers: []ContainerResources{container}, OOMHistory: history})
+	require.Len(t, rec.Containers, 1)
+	assert.Zero(t, rec.Containers[0].Recommended.MemoryLimit, "no memory limit imposed after OOM kills")
+}
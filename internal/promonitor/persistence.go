@@ -1,13 +1,15 @@
 package promonitor
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/storage"
 )
 
 // LatchResult is the persisted output of a completed latch session.
@@ -23,19 +25,21 @@ type LatchResult struct {
 	Gaps            int                  `json:"gaps"`
 	Valid           bool                 `json:"valid"`
 	Reason          string               `json:"reason,omitempty"` // Why invalid, if applicable
+
+	// Per-container percentiles keyed by container name (nil for latch
+	// files recorded before per-container sampling).
+	Containers map[string]*metrics.ContainerPercentiles `json:"container_percentiles,omitempty"`
+
+	// Per-pod network throughput percentiles (bytes/s) and how closely CPU
+	// follows it; nil and 0 when network was not sampled.
+	NetworkRX             *metrics.Percentiles `json:"network_rx_percentiles,omitempty"`
+	NetworkTX             *metrics.Percentiles `json:"network_tx_percentiles,omitempty"`
+	NetworkCPUCorrelation float64              `json:"network_cpu_correlation,omitempty"`
 }
 
-// latchDir returns the directory for persisted latch files.
-func latchDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
-	}
-	dir := filepath.Join(home, ".kubenow", "latch")
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", fmt.Errorf("cannot create latch directory: %w", err)
-	}
-	return dir, nil
+// latchKey returns the storage key for a workload's latch data.
+func latchKey(ref WorkloadRef) string {
+	return storage.Key(storage.KindLatch, latchFilename(ref))
 }
 
 // latchFilename returns the canonical filename for a workload's latch data.
@@ -43,53 +47,96 @@ func latchFilename(ref WorkloadRef) string {
 	return fmt.Sprintf("%s__%s__%s.json", ref.Namespace, ref.Kind, ref.Name)
 }
 
-// LatchFilePath returns the expected file path for a workload's latch data.
-// Returns a best-effort path; errors in resolving home directory fall back to
-// the filename alone.
+// LatchFilePath returns where a workload's latch data is stored: a file
+// path for local storage, or a URI for remote backends. Errors resolving
+// the store fall back to the filename alone.
 func LatchFilePath(ref WorkloadRef) string {
-	dir, err := latchDir()
+	s, err := storage.Default()
 	if err != nil {
 		return latchFilename(ref)
 	}
-	return filepath.Join(dir, latchFilename(ref))
+	return s.Location(latchKey(ref))
 }
 
-// SaveLatch persists a latch result to disk. Best-effort — errors are returned
-// but should not block the user flow.
+// SaveLatch persists a latch result to the configured store. Best-effort —
+// errors are returned but should not block the user flow.
 func SaveLatch(result *LatchResult) error {
-	dir, err := latchDir()
+	s, err := storage.Default()
 	if err != nil {
 		return err
 	}
 
-	path := filepath.Join(dir, latchFilename(result.Workload))
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal latch result: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0o600); err != nil {
+	if err := s.Put(context.Background(), latchKey(result.Workload), data); err != nil {
 		return fmt.Errorf("failed to write latch file: %w", err)
 	}
 	return nil
 }
 
-// LoadLatch reads a persisted latch result from disk.
+// LoadLatch reads a persisted latch result from the configured store.
 func LoadLatch(ref WorkloadRef) (*LatchResult, error) {
-	dir, err := latchDir()
+	s, err := storage.Default()
 	if err != nil {
 		return nil, err
 	}
 
-	path := filepath.Join(dir, latchFilename(ref))
-	data, err := os.ReadFile(path)
+	data, err := s.Get(context.Background(), latchKey(ref))
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, storage.ErrNotFound) {
 			return nil, fmt.Errorf("no latch data for %s in namespace %s", ref.String(), ref.Namespace)
 		}
 		return nil, fmt.Errorf("failed to read latch file: %w", err)
 	}
+	return parseLatch(data)
+}
 
+// LatestLatch reads the most recently saved latch result, of any workload
+// in namespace, or in any namespace when namespace is empty.
+func LatestLatch(namespace string) (*LatchResult, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	objects, err := s.List(ctx, storage.KindLatch+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list latch data: %w", err)
+	}
+	prefix := storage.KindLatch + "/"
+	if namespace != "" {
+		prefix += namespace + "__"
+	}
+	var latest *storage.Object
+	for i := range objects {
+		obj := &objects[i]
+		if !strings.HasPrefix(obj.Key, prefix) || !strings.HasSuffix(obj.Key, ".json") {
+			continue
+		}
+		if latest == nil || obj.ModTime.After(latest.ModTime) {
+			latest = obj
+		}
+	}
+	if latest == nil {
+		if namespace != "" {
+			return nil, fmt.Errorf("no latch data in namespace %s", namespace)
+		}
+		return nil, errors.New("no latch data")
+	}
+
+	data, err := s.Get(ctx, latest.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read latch file: %w", err)
+	}
+	return parseLatch(data)
+}
+
+// parseLatch decodes a persisted latch result.
+func parseLatch(data []byte) (*LatchResult, error) {
 	var result LatchResult
 	if err := json.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse latch file: %w", err)
@@ -97,6 +144,81 @@ func LoadLatch(ref WorkloadRef) (*LatchResult, error) {
 	return &result, nil
 }
 
+// LatchSession is an unfinished latch, checkpointed while it runs so that
+// it can be resumed after the process is killed.
+type LatchSession struct {
+	Workload        WorkloadRef              `json:"workload"`
+	StartedAt       time.Time                `json:"started_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+	PlannedDuration time.Duration            `json:"planned_duration"`
+	Interval        time.Duration            `json:"interval"`
+	Checkpoint      *metrics.LatchCheckpoint `json:"checkpoint"`
+}
+
+// Remaining returns how much of the planned duration is left to sample.
+func (s *LatchSession) Remaining() time.Duration {
+	if s.Checkpoint == nil {
+		return s.PlannedDuration
+	}
+	return max(0, s.PlannedDuration-s.Checkpoint.Elapsed)
+}
+
+// sessionKey returns the storage key for a workload's unfinished latch.
+func sessionKey(ref WorkloadRef) string {
+	return storage.Key(storage.KindSessions, latchFilename(ref))
+}
+
+// SaveLatchSession checkpoints an unfinished latch, replacing the
+// workload's previous checkpoint.
+func SaveLatchSession(session *LatchSession) error {
+	s, err := storage.Default()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal latch session: %w", err)
+	}
+
+	if err := s.Put(context.Background(), sessionKey(session.Workload), data); err != nil {
+		return fmt.Errorf("failed to write latch session: %w", err)
+	}
+	return nil
+}
+
+// LoadLatchSession reads a workload's unfinished latch.
+func LoadLatchSession(ref WorkloadRef) (*LatchSession, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.Get(context.Background(), sessionKey(ref))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("no unfinished latch for %s in namespace %s", ref.String(), ref.Namespace)
+		}
+		return nil, fmt.Errorf("failed to read latch session: %w", err)
+	}
+
+	var session LatchSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse latch session: %w", err)
+	}
+	return &session, nil
+}
+
+// DeleteLatchSession removes a workload's checkpoint once its latch has
+// finished.
+func DeleteLatchSession(ref WorkloadRef) error {
+	s, err := storage.Default()
+	if err != nil {
+		return err
+	}
+	return s.Delete(context.Background(), sessionKey(ref))
+}
+
 // BuildLatchResult creates a LatchResult from completed latch data.
 func BuildLatchResult(ref WorkloadRef, data *metrics.SpikeData, duration, interval time.Duration) *LatchResult {
 	result := &LatchResult{
@@ -118,6 +240,9 @@ func BuildLatchResult(ref WorkloadRef, data *metrics.SpikeData, duration, interv
 	cpu, mem := data.ComputePercentiles()
 	result.CPU = cpu
 	result.Memory = mem
+	result.Containers = data.ComputeContainerPercentiles()
+	result.NetworkRX, result.NetworkTX = data.ComputeNetworkPercentiles()
+	result.NetworkCPUCorrelation = data.NetworkCPUCorrelation()
 
 	// Detect gaps
 	result.Gaps = data.GapCount(interval)
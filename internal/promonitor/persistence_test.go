@@ -155,6 +155,28 @@ func TestBuildLatchResult_ValidWithPercentiles(t *testing.T) {
 	assert.Greater(t, result.Memory.P95, result.Memory.P50)
 }
 
+func TestBuildLatchResult_ContainerPercentiles(t *testing.T) {
+	ref := WorkloadRef{Kind: "Deployment", Name: "api", Namespace: "prod"}
+	now := time.Now()
+	data := &metrics.SpikeData{
+		SampleCount: 3,
+		FirstSeen:   now.Add(-10 * time.Second),
+		LastSeen:    now,
+		CPUSamples:  []float64{0.3, 0.4, 0.5},
+		MemSamples:  []float64{300e6, 400e6, 500e6},
+		Containers: map[string]*metrics.ContainerSamples{
+			"api":   {CPUSamples: []float64{0.2, 0.3, 0.4}, MemSamples: []float64{200e6, 300e6, 400e6}},
+			"envoy": {CPUSamples: []float64{0.1, 0.1, 0.1}, MemSamples: []float64{100e6, 100e6, 100e6}},
+		},
+	}
+
+	result := BuildLatchResult(ref, data, 10*time.Second, 5*time.Second)
+
+	require.Len(t, result.Containers, 2)
+	assert.InDelta(t, 0.4, result.Containers["api"].CPU.Max, 0.001)
+	assert.InDelta(t, 100e6, result.Containers["envoy"].Memory.P95, 1)
+}
+
 func TestLatchResult_PlannedDuration_Serialization(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
@@ -182,3 +204,67 @@ func TestLatchResult_NormalCompletion_NoPlanedDuration(t *testing.T) {
 	// PlannedDuration should be zero for normal completion
 	assert.Equal(t, time.Duration(0), result.PlannedDuration)
 }
+
+func TestLatchSession_RoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ref := WorkloadRef{Kind: "Deployment", Name: "test-api", Namespace: "default"}
+	_, err := LoadLatchSession(ref)
+	assert.ErrorContains(t, err, "no unfinished latch")
+
+	session := &LatchSession{
+		Workload:        ref,
+		StartedAt:       time.Now().Add(-time.Hour),
+		PlannedDuration: 8 * time.Hour,
+		Interval:        5 * time.Second,
+		Checkpoint: &metrics.LatchCheckpoint{
+			Elapsed:   time.Hour,
+			SpikeData: map[string]*metrics.SpikeData{"default/test-api": {SampleCount: 720}},
+		},
+	}
+	require.NoError(t, SaveLatchSession(session))
+
+	loaded, err := LoadLatchSession(ref)
+	require.NoError(t, err)
+	assert.Equal(t, 7*time.Hour, loaded.Remaining())
+	assert.Equal(t, 720, loaded.Checkpoint.SpikeData["default/test-api"].SampleCount)
+
+	require.NoError(t, DeleteLatchSession(ref))
+	_, err = LoadLatchSession(ref)
+	assert.Error(t, err)
+
+	loaded.Checkpoint.Elapsed = 9 * time.Hour
+	assert.Equal(t, time.Duration(0), loaded.Remaining())
+}
+
+func TestLatestLatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	_, err := LatestLatch("")
+	assert.ErrorContains(t, err, "no latch data")
+
+	refs := []WorkloadRef{
+		{Kind: "Deployment", Name: "old", Namespace: "prod"},
+		{Kind: "Deployment", Name: "new", Namespace: "prod"},
+		{Kind: "StatefulSet", Name: "db", Namespace: "data"},
+	}
+	base := time.Now().Add(-time.Hour)
+	for i, ref := range refs {
+		require.NoError(t, SaveLatch(&LatchResult{Workload: ref, Duration: time.Hour}))
+		path := filepath.Join(tmpDir, ".kubenow", "latch", latchFilename(ref))
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	latest, err := LatestLatch("")
+	require.NoError(t, err)
+	assert.Equal(t, "db", latest.Workload.Name)
+
+	latest, err = LatestLatch("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "new", latest.Workload.Name)
+
+	_, err = LatestLatch("staging")
+	assert.ErrorContains(t, err, "namespace staging")
+}
@@ -3,9 +3,11 @@ package promonitor
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // Safety margin multipliers per rating.
@@ -24,6 +26,15 @@ const memLimitFactor = 1.2
 // Burst cap: limit increases cannot exceed 2x current.
 const burstCapMultiplier = 2.0
 
+// A workload whose CPU follows its network traffic this closely over at
+// least this many network samples is network-bound: its CPU request is
+// sized on p99 instead of p95, since the latch only saw part of the
+// traffic range.
+const (
+	networkBoundCorrelation = 0.7
+	networkBoundMinSamples  = 30
+)
+
 // Safety rating thresholds.
 const (
 	unsafeOOMThreshold      = 5
@@ -140,6 +151,20 @@ func Recommend(input *RecommendInput) *AlignmentRecommendation {
 		return result
 	}
 
+	// OOM kills before the latch mean the quiet latch undersells memory
+	// pressure: rate at least CAUTION and never lower memory.
+	oomBefore := input.OOMHistory != nil && input.OOMHistory.Kills > 0
+	if oomBefore {
+		if safety == SafetyRatingSafe {
+			safety = SafetyRatingCaution
+			result.Safety = safety
+		}
+		h := input.OOMHistory
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"%d OOMKill(s) in the %s before the latch (last %s): memory reductions blocked",
+			h.Kills, formatAge(h.Window), h.LastKill.UTC().Format(time.RFC3339)))
+	}
+
 	// Check against policy minimum safety rating
 	if input.Bounds != nil && input.Bounds.MinSafetyRating != "" {
 		minLevel := SafetyRatingLevel(input.Bounds.MinSafetyRating)
@@ -177,17 +202,44 @@ func Recommend(input *RecommendInput) *AlignmentRecommendation {
 		}
 	}
 
-	// Multi-container warning
-	if len(input.Containers) > 1 {
-		result.Warnings = append(result.Warnings,
-			fmt.Sprintf("multi-container pod (%d containers): recommendations use aggregate pod metrics",
-				len(input.Containers)))
+	networkBound := isNetworkBound(latch)
+	if networkBound {
+		result.Evidence.NetworkBound = true
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"CPU follows network traffic (correlation %.2f, rx p95 %s/s, tx p95 %s/s): CPU request sized on p99 instead of p95",
+			latch.NetworkCPUCorrelation, units.Memory(latch.NetworkRX.P95), units.Memory(latch.NetworkTX.P95)))
 	}
 
-	// Compute recommendation per container
+	// Multi-container pods are sized per container when the latch sampled
+	// each container; otherwise every container falls back to pod totals.
+	var aggregated []string
 	for _, container := range input.Containers {
-		alignment := recommendContainer(container, latch.CPU, latch.Memory, margin, input.Bounds, input.HasProm)
+		cpuPerc, memPerc := latch.CPU, latch.Memory
+		if cp := latch.Containers[container.Name]; cp != nil && cp.CPU != nil && cp.Memory != nil {
+			cpuPerc, memPerc = cp.CPU, cp.Memory
+		} else if len(input.Containers) > 1 {
+			aggregated = append(aggregated, container.Name)
+		}
+		if networkBound {
+			cpuPerc = sizeOnP99(cpuPerc)
+		}
+
+		alignment := recommendContainer(container, cpuPerc, memPerc, margin, input.Bounds, input.HasProm)
+		if oomBefore {
+			holdMemoryReductions(&alignment)
+		}
 		result.Containers = append(result.Containers, alignment)
+
+		if input.Bounds != nil && input.Bounds.ForbidCPULimits && container.CPULimit > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"policy forbids CPU limits: remove the CPU limit from container %q manually", container.Name))
+		}
+	}
+
+	if len(aggregated) > 0 {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("multi-container pod (%d containers): no per-container samples for %s, using aggregate pod metrics",
+				len(input.Containers), strings.Join(aggregated, ", ")))
 	}
 
 	// Set policy result if not already set by HPA
@@ -195,6 +247,15 @@ func Recommend(input *RecommendInput) *AlignmentRecommendation {
 		result.Policy = &PolicyResult{ExportPermitted: true}
 	}
 
+	// The validity window runs from the end of the latch the values are
+	// sized on, not from when they were computed.
+	result.Trend = ComputeUsageTrend(latch.Data)
+	sizedAt := latch.Timestamp
+	if sizedAt.IsZero() {
+		sizedAt = result.Timestamp
+	}
+	result.ValidUntil = sizedAt.Add(ValidityWindow(result.Trend))
+
 	return result
 }
 
@@ -328,6 +389,40 @@ func applyPolicyBounds(a *ContainerAlignment, b *PolicyBounds) {
 		a.Recommended.MemoryLimit = a.Recommended.MemoryRequest
 		a.Delta.MemoryLimitPercent = deltaPercent(a.Current.MemoryLimit, a.Recommended.MemoryLimit)
 	}
+
+	applyRatioBounds(a, b)
+}
+
+// applyRatioBounds enforces limit/request ratio mandates. It runs last so the
+// mandated ratio wins over the limit-decrease guardrail.
+func applyRatioBounds(a *ContainerAlignment, b *PolicyBounds) {
+	// No CPU limit on a container that has none today. Existing CPU limits are
+	// left in place (apply never removes fields) and surfaced as a warning.
+	if b.ForbidCPULimits && a.Current.CPULimit == 0 {
+		a.Recommended.CPULimit = 0
+		a.Delta.CPULimitPercent = 0
+	}
+
+	ratioFields := []struct {
+		maxRatio float64
+		request  float64
+		limit    *float64
+		current  float64
+		delta    *float64
+		name     string
+	}{
+		{b.MaxCPULimitRatio, a.Recommended.CPURequest, &a.Recommended.CPULimit, a.Current.CPULimit, &a.Delta.CPULimitPercent, "cpu_limit_ratio"},
+		{b.MaxMemoryLimitRatio, a.Recommended.MemoryRequest, &a.Recommended.MemoryLimit, a.Current.MemoryLimit, &a.Delta.MemoryLimitPercent, "memory_limit_ratio"},
+	}
+	for _, f := range ratioFields {
+		if f.maxRatio <= 0 || f.request <= 0 || *f.limit <= f.request*f.maxRatio {
+			continue
+		}
+		*f.limit = f.request * f.maxRatio
+		*f.delta = deltaPercent(f.current, *f.limit)
+		a.Capped = true
+		a.CappedFields = append(a.CappedFields, f.name)
+	}
 }
 
 type policyDeltaField struct {
@@ -375,6 +470,23 @@ func capValue(current, maxPct float64, increase bool) float64 {
 	return current * (1 - maxPct/100)
 }
 
+// isNetworkBound reports whether the latch's CPU samples follow its
+// network samples closely enough for CPU percentiles alone to undersize
+// the workload at traffic peaks.
+func isNetworkBound(latch *LatchResult) bool {
+	return latch.Data != nil && len(latch.Data.Network) >= networkBoundMinSamples &&
+		latch.NetworkRX != nil && latch.NetworkTX != nil &&
+		latch.NetworkCPUCorrelation >= networkBoundCorrelation
+}
+
+// sizeOnP99 returns a copy of p whose p95 is raised to its p99, so the
+// request formula sizes on p99.
+func sizeOnP99(p *metrics.Percentiles) *metrics.Percentiles {
+	raised := *p
+	raised.P95 = math.Max(p.P95, p.P99)
+	return &raised
+}
+
 // buildEvidence constructs a LatchEvidence from a LatchResult.
 func buildEvidence(latch *LatchResult) *LatchEvidence {
 	sc := 0
@@ -390,5 +502,10 @@ func buildEvidence(latch *LatchResult) *LatchEvidence {
 		Valid:           latch.Valid,
 		CPU:             latch.CPU,
 		Memory:          latch.Memory,
+		Containers:      latch.Containers,
+
+		NetworkRX:             latch.NetworkRX,
+		NetworkTX:             latch.NetworkTX,
+		NetworkCPUCorrelation: latch.NetworkCPUCorrelation,
 	}
 }
@@ -585,8 +585,89 @@ func TestRecommend_EvidencePopulated(t *testing.T) {
 	assert.NotNil(t, rec.Evidence.Memory)
 }
 
+func TestRecommend_NetworkBoundSizesCPUOnP99(t *testing.T) {
+	data := &metrics.SpikeData{SampleCount: 180}
+	for i := 1; i <= networkBoundMinSamples; i++ {
+		data.Network = append(data.Network, metrics.NetworkSample{RX: float64(i) * 1e6, TX: float64(i) * 1e5, CPU: float64(i) * 0.01})
+	}
+	latch := testLatch(0.08, 0.12, 0.15, 170e6, 200e6, 220e6, data)
+	latch.NetworkRX, latch.NetworkTX = data.ComputeNetworkPercentiles()
+	latch.NetworkCPUCorrelation = data.NetworkCPUCorrelation()
+
+	rec := Recommend(&RecommendInput{Latch: latch, Containers: []ContainerResources{testContainer(0.1, 0.5, 128e6, 512e6)}})
+	require.Len(t, rec.Containers, 1)
+	assert.InDelta(t, 0.12, rec.Containers[0].Recommended.CPURequest, 0.001, "p99, not p95")
+	assert.InDelta(t, 170e6, rec.Containers[0].Recommended.MemoryRequest, 1e5, "memory is unaffected")
+	assert.True(t, rec.Evidence.NetworkBound)
+	assert.NotNil(t, rec.Evidence.NetworkRX)
+	require.NotEmpty(t, rec.Warnings)
+	assert.Contains(t, rec.Warnings[len(rec.Warnings)-1], "CPU follows network traffic (correlation 1.00")
+
+	// Traffic that does not drive CPU leaves the p95 sizing alone
+	for i := range data.Network {
+		data.Network[i].CPU = 0.08
+	}
+	latch.NetworkCPUCorrelation = data.NetworkCPUCorrelation()
+	rec = Recommend(&RecommendInput{Latch: latch, Containers: []ContainerResources{testContainer(0.1, 0.5, 128e6, 512e6)}})
+	assert.InDelta(t, 0.08, rec.Containers[0].Recommended.CPURequest, 0.001)
+	assert.False(t, rec.Evidence.NetworkBound)
+}
+
 // --- Safety Rating Levels ---
 
+func TestRecommend_PerContainerPercentiles(t *testing.T) {
+	data := &metrics.SpikeData{SampleCount: 180}
+	latch := testLatch(0.6, 0.8, 1.0, 600e6, 700e6, 800e6, data) // pod totals
+	latch.Containers = map[string]*metrics.ContainerPercentiles{
+		"api":   {CPU: &metrics.Percentiles{P95: 0.5, P99: 0.6, Max: 0.7}, Memory: &metrics.Percentiles{P95: 500e6, P99: 550e6, Max: 600e6}},
+		"envoy": {CPU: &metrics.Percentiles{P95: 0.1, P99: 0.2, Max: 0.3}, Memory: &metrics.Percentiles{P95: 100e6, P99: 150e6, Max: 200e6}},
+	}
+	sidecar := testContainer(0.5, 1.0, 256e6, 512e6)
+	sidecar.Name = "envoy"
+
+	rec := Recommend(&RecommendInput{
+		Latch:      latch,
+		Containers: []ContainerResources{testContainer(1.0, 2.0, 1e9, 2e9), sidecar},
+	})
+
+	require.Len(t, rec.Containers, 2)
+	assert.InDelta(t, 0.5, rec.Containers[0].Recommended.CPURequest, 0.001)
+	assert.InDelta(t, 500e6, rec.Containers[0].Recommended.MemoryRequest, 1)
+	assert.InDelta(t, 0.1, rec.Containers[1].Recommended.CPURequest, 0.001)
+	assert.InDelta(t, 100e6, rec.Containers[1].Recommended.MemoryRequest, 1)
+	for _, w := range rec.Warnings {
+		assert.NotContains(t, w, "aggregate pod metrics")
+	}
+	assert.Len(t, rec.Evidence.Containers, 2)
+}
+
+func TestRecommend_MultiContainerWithoutSamplesFallsBack(t *testing.T) {
+	data := &metrics.SpikeData{SampleCount: 180}
+	latch := testLatch(0.6, 0.8, 1.0, 600e6, 700e6, 800e6, data)
+	latch.Containers = map[string]*metrics.ContainerPercentiles{
+		"api": {CPU: &metrics.Percentiles{P95: 0.5, P99: 0.6, Max: 0.7}, Memory: &metrics.Percentiles{P95: 500e6, P99: 550e6, Max: 600e6}},
+	}
+	sidecar := testContainer(0.5, 1.0, 256e6, 512e6)
+	sidecar.Name = "envoy"
+
+	rec := Recommend(&RecommendInput{
+		Latch:      latch,
+		Containers: []ContainerResources{testContainer(1.0, 2.0, 1e9, 2e9), sidecar},
+	})
+
+	require.Len(t, rec.Containers, 2)
+	assert.InDelta(t, 0.5, rec.Containers[0].Recommended.CPURequest, 0.001)
+	assert.InDelta(t, 0.6, rec.Containers[1].Recommended.CPURequest, 0.001, "sidecar falls back to pod totals")
+
+	found := false
+	for _, w := range rec.Warnings {
+		if strings.Contains(w, "no per-container samples for envoy") {
+			found = true
+		}
+	}
+	assert.True(t, found, "expected fallback warning, got %v", rec.Warnings)
+}
+
 func TestSafetyRatingLevel(t *testing.T) {
 	assert.Equal(t, 0, SafetyRatingLevel(SafetyRatingSafe))
 	assert.Equal(t, 1, SafetyRatingLevel(SafetyRatingCaution))
@@ -601,3 +682,57 @@ func TestParseSafetyRating(t *testing.T) {
 	assert.Equal(t, SafetyRatingUnsafe, ParseSafetyRating("UNSAFE"))
 	assert.Equal(t, SafetyRatingCaution, ParseSafetyRating("unknown"))
 }
+
+func TestRecommend_PolicyRatioCapsLimits(t *testing.T) {
+	data := &metrics.SpikeData{SampleCount: 180}
+	latch := testLatch(0.2, 0.25, 0.3, 300e6, 400e6, 450e6, data)
+
+	bounds := &PolicyBounds{
+		AllowLimitDecrease:  true,
+		MaxCPULimitRatio:    1.5,
+		MaxMemoryLimitRatio: 1.1,
+	}
+	rec := Recommend(&RecommendInput{
+		Latch:      latch,
+		Containers: []ContainerResources{testContainer(0.1, 0.5, 128e6, 512e6)},
+		Bounds:     bounds,
+	})
+
+	require.Len(t, rec.Containers, 1)
+	c := rec.Containers[0]
+	assert.LessOrEqual(t, c.Recommended.CPULimit, c.Recommended.CPURequest*1.5+1e-9)
+	assert.LessOrEqual(t, c.Recommended.MemoryLimit, c.Recommended.MemoryRequest*1.1+1e-3)
+	assert.Contains(t, c.CappedFields, "cpu_limit_ratio")
+	assert.Contains(t, c.CappedFields, "memory_limit_ratio")
+}
+
+func TestRecommend_PolicyForbidCPULimits(t *testing.T) {
+	data := &metrics.SpikeData{SampleCount: 180}
+	latch := testLatch(0.2, 0.25, 0.3, 300e6, 400e6, 450e6, data)
+	bounds := &PolicyBounds{AllowLimitDecrease: true, ForbidCPULimits: true}
+
+	// No CPU limit today → none recommended
+	rec := Recommend(&RecommendInput{
+		Latch:      latch,
+		Containers: []ContainerResources{testContainer(0.1, 0, 128e6, 512e6)},
+		Bounds:     bounds,
+	})
+	require.Len(t, rec.Containers, 1)
+	assert.Zero(t, rec.Containers[0].Recommended.CPULimit)
+
+	// Existing CPU limit → kept, with a warning to remove it manually
+	rec = Recommend(&RecommendInput{
+		Latch:      latch,
+		Containers: []ContainerResources{testContainer(0.1, 0.5, 128e6, 512e6)},
+		Bounds:     bounds,
+	})
+	require.Len(t, rec.Containers, 1)
+	assert.Positive(t, rec.Containers[0].Recommended.CPULimit)
+	found := false
+	for _, w := range rec.Warnings {
+		if strings.Contains(w, "policy forbids CPU limits") {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,273 @@
+package promonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/audit"
+)
+
+// RollbackPlan is an applied audit bundle loaded for rollback.
+type RollbackPlan struct {
+	Dir      string
+	ID       string // bundle directory name
+	Decision *audit.DecisionJSON
+	Workload WorkloadRef
+	Before   map[string]interface{} // before.yaml: the object kubenow changed
+	After    map[string]interface{} // after.yaml: the object kubenow left
+}
+
+// LoadRollbackPlan reads a bundle and checks that it records an applied
+// change to a workload kind kubenow can patch.
+func LoadRollbackPlan(dir string) (*RollbackPlan, error) {
+	decision, err := audit.ReadDecision(dir)
+	if err != nil {
+		return nil, err
+	}
+	if decision.Status != "applied" {
+		return nil, fmt.Errorf("bundle status is %q: only applied changes can be rolled back", decision.Status)
+	}
+	ref := WorkloadRef{Kind: decision.Workload.Kind, Name: decision.Workload.Name, Namespace: decision.Workload.Namespace}
+	switch ref.Kind {
+	case KindDeployment, KindStatefulSet, KindDaemonSet:
+	default:
+		return nil, fmt.Errorf("rollback is not supported for kind %q", ref.Kind)
+	}
+
+	before, err := audit.ReadObject(dir, "before.yaml")
+	if err != nil {
+		return nil, err
+	}
+	after, err := audit.ReadObject(dir, "after.yaml")
+	if err != nil {
+		return nil, err
+	}
+	return &RollbackPlan{Dir: dir, ID: filepath.Base(dir), Decision: decision, Workload: ref, Before: before, After: after}, nil
+}
+
+// RollbackInput holds the inputs to ExecuteRollback.
+type RollbackInput struct {
+	Plan *RollbackPlan
+	// Force rolls back over resource changes made after the apply, and
+	// over conflicts with non-GitOps field managers.
+	Force  bool
+	DryRun bool // preview the rollback server-side without changing anything
+}
+
+// RollbackResult holds the outcome of a rollback.
+type RollbackResult struct {
+	RolledBack bool
+	Refused    bool // stopped before patching: later changes or a conflict
+	// ChangedSince lists container resources changed on the live object
+	// since the apply (before = what kubenow applied, after = live).
+	ChangedSince    []PreviewChange
+	Preview         *ApplyPreview // dry-run diff (DryRun only)
+	Restored        []PreviewChange
+	Mismatches      []PreviewChange // read-back values differing from before.yaml
+	ConflictManager string
+	GitOpsConflict  bool
+	Object          map[string]interface{} // read-back object
+	Error           error
+}
+
+// ExecuteRollback re-applies the container resources recorded in the
+// bundle's before.yaml with server-side apply under kubenow's field
+// manager, then reads the workload back to verify them. Fields the
+// original object did not set are released and so removed.
+func ExecuteRollback(ctx context.Context, client KubeApplier, input *RollbackInput) *RollbackResult {
+	result := &RollbackResult{}
+	plan := input.Plan
+
+	patchJSON, err := buildRollbackPatchJSON(plan)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to build patch: %w", err)
+		return result
+	}
+
+	live, err := client.GetWorkloadObject(ctx, plan.Workload)
+	if err != nil {
+		result.Error = fmt.Errorf("fetch live object: %w", err)
+		return result
+	}
+	result.ChangedSince = resourceChanges(plan.After, live)
+	if len(result.ChangedSince) > 0 && !input.Force {
+		result.Refused = true
+		result.Error = fmt.Errorf("%d container resource value(s) changed since the apply; pass --force to roll back anyway", len(result.ChangedSince))
+		return result
+	}
+	result.Restored = resourceChanges(live, plan.Before)
+
+	if input.DryRun {
+		result.Preview = previewPatch(ctx, client, plan.Workload, patchJSON)
+		result.ConflictManager = result.Preview.ConflictManager
+		result.GitOpsConflict = result.Preview.GitOpsConflict
+		result.Error = result.Preview.Error
+		return result
+	}
+
+	err = client.PatchWorkload(ctx, plan.Workload, patchJSON, fieldManager, false)
+	if err != nil && isConflictError(err) {
+		result.ConflictManager = detectConflictManager(ctx, client, plan.Workload)
+		if isGitOpsManager(result.ConflictManager) {
+			result.GitOpsConflict = true
+			result.Refused = true
+			result.Error = fmt.Errorf("ssa conflict with %s: roll back through GitOps instead: %w", result.ConflictManager, err)
+			return result
+		}
+		if !input.Force {
+			result.Refused = true
+			result.Error = fmt.Errorf("ssa conflict with %s; pass --force to take over the fields: %w", result.ConflictManager, err)
+			return result
+		}
+		err = client.PatchWorkload(ctx, plan.Workload, patchJSON, fieldManager, true)
+	}
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.RolledBack = true
+
+	readBack, err := client.GetWorkloadObject(ctx, plan.Workload)
+	if err != nil {
+		result.Error = fmt.Errorf("read-back failed (rollback succeeded): %w", err)
+		return result
+	}
+	result.Object = readBack
+	result.Mismatches = resourceChanges(plan.Before, readBack)
+	return result
+}
+
+// RollbackRecord converts a result into the record appended to the bundle.
+func RollbackRecord(result *RollbackResult, identity *audit.Identity, version string, forced bool) audit.RollbackRecord {
+	rec := audit.RollbackRecord{
+		Status:     audit.RollbackStatusRolledBack,
+		Identity:   identity,
+		Version:    version,
+		Forced:     forced,
+		Changes:    bundleChanges(result.Restored),
+		Mismatches: bundleChanges(result.Mismatches),
+	}
+	switch {
+	case result.Refused:
+		rec.Status = audit.RollbackStatusRefused
+	case !result.RolledBack:
+		rec.Status = audit.RollbackStatusFailed
+	}
+	if result.Error != nil {
+		rec.Error = result.Error.Error()
+	}
+	return rec
+}
+
+func bundleChanges(changes []PreviewChange) []audit.BundleChange {
+	out := make([]audit.BundleChange, len(changes))
+	for i, c := range changes {
+		out[i] = audit.BundleChange{Field: c.Path, Before: c.Before, After: c.After}
+	}
+	return out
+}
+
+// buildRollbackPatchJSON builds an SSA patch holding each container's
+// resources exactly as recorded in before.yaml.
+func buildRollbackPatchJSON(plan *RollbackPlan) ([]byte, error) {
+	containers, ok := nestedList(plan.Before, "spec", "template", "spec", "containers")
+	if !ok || len(containers) == 0 {
+		return nil, fmt.Errorf("before.yaml has no containers")
+	}
+
+	patchContainers := make([]interface{}, 0, len(containers))
+	for _, c := range containers {
+		cm, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := cm["name"].(string)
+		if name == "" {
+			continue
+		}
+		resources := map[string]interface{}{}
+		if r, ok := cm["resources"].(map[string]interface{}); ok {
+			for _, k := range []string{"requests", "limits"} {
+				if v, ok := r[k]; ok {
+					resources[k] = v
+				}
+			}
+		}
+		patchContainers = append(patchContainers, map[string]interface{}{"name": name, "resources": resources})
+	}
+
+	apiVersion, _ := plan.Before["apiVersion"].(string)
+	if apiVersion == "" {
+		apiVersion = "apps/v1"
+	}
+	doc := map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       plan.Workload.Kind,
+		"metadata": map[string]interface{}{
+			"name":      plan.Workload.Name,
+			"namespace": plan.Workload.Namespace,
+			"annotations": map[string]interface{}{
+				"kubenow.dev/last-rollback": fmt.Sprintf("%s | bundle=%s", time.Now().UTC().Format(time.RFC3339), plan.ID),
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": patchContainers},
+			},
+		},
+	}
+	return json.Marshal(doc)
+}
+
+// resourceChanges lists the container resource values that differ between
+// two objects, keyed by flattened path.
+func resourceChanges(from, to map[string]interface{}) []PreviewChange {
+	a, b := containerResources(from), containerResources(to)
+	paths := make(map[string]bool, len(a)+len(b))
+	for p := range a {
+		paths[p] = true
+	}
+	for p := range b {
+		paths[p] = true
+	}
+
+	var changes []PreviewChange
+	for p := range paths {
+		if !sameValue(a[p], b[p]) {
+			changes = append(changes, PreviewChange{Path: p, Before: a[p], After: b[p]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// containerResources flattens the requests and limits of an object's pod
+// template containers.
+func containerResources(obj map[string]interface{}) map[string]string {
+	out := map[string]string{}
+	for path, v := range flattenObject(obj) {
+		if strings.HasPrefix(path, "spec.template.spec.containers[") &&
+			(strings.Contains(path, "].resources.requests.") || strings.Contains(path, "].resources.limits.")) {
+			out[path] = v
+		}
+	}
+	return out
+}
+
+func nestedList(obj map[string]interface{}, fields ...string) ([]interface{}, bool) {
+	var cur interface{} = obj
+	for _, f := range fields {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur = m[f]
+	}
+	list, ok := cur.([]interface{})
+	return list, ok
+}
@@ -0,0 +1,212 @@
+package promonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ppiankov/kubenow/internal/audit"
+)
+
+// rollbackApplier serves the live object until a patch succeeds, then the
+// read-back object.
+type rollbackApplier struct {
+	*mockKubeApplier
+	readBack map[string]interface{}
+	patched  bool
+}
+
+func (r *rollbackApplier) PatchWorkload(ctx context.Context, ref WorkloadRef, patchJSON []byte, manager string, force bool) error {
+	err := r.mockKubeApplier.PatchWorkload(ctx, ref, patchJSON, manager, force)
+	if err == nil {
+		r.patched = true
+	}
+	return err
+}
+
+func (r *rollbackApplier) GetWorkloadObject(ctx context.Context, ref WorkloadRef) (map[string]interface{}, error) {
+	if r.patched {
+		return r.readBack, nil
+	}
+	return r.mockKubeApplier.GetWorkloadObject(ctx, ref)
+}
+
+// beforeResources has no CPU limit: the apply added one.
+func beforeResources() map[string]interface{} {
+	return map[string]interface{}{
+		"requests": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+		"limits":   map[string]interface{}{"memory": "512Mi"},
+	}
+}
+
+func testRollbackPlan() *RollbackPlan {
+	return &RollbackPlan{
+		ID:       "20260301T120000Z__default__deployment__api",
+		Workload: WorkloadRef{Kind: KindDeployment, Name: "api", Namespace: "default"},
+		Before:   deploymentObject("1", beforeResources()),
+		After:    deploymentObject("2", resources("150m", "600m", "200Mi", "600Mi")),
+	}
+}
+
+func TestExecuteRollback_Success(t *testing.T) {
+	plan := testRollbackPlan()
+	client := &rollbackApplier{
+		mockKubeApplier: &mockKubeApplier{workloadObject: deploymentObject("2", resources("150m", "600m", "200Mi", "600Mi"))},
+		readBack:        deploymentObject("3", beforeResources()),
+	}
+
+	result := ExecuteRollback(context.Background(), client, &RollbackInput{Plan: plan})
+	require.NoError(t, result.Error)
+	assert.True(t, result.RolledBack)
+	assert.False(t, client.patchForcedAt)
+	assert.Empty(t, result.ChangedSince)
+	assert.Len(t, result.Restored, 4)
+	assert.Empty(t, result.Mismatches)
+	assert.NotNil(t, result.Object)
+
+	rec := RollbackRecord(result, nil, "0.3.0", false)
+	assert.Equal(t, audit.RollbackStatusRolledBack, rec.Status)
+	assert.Len(t, rec.Changes, 4)
+}
+
+func TestExecuteRollback_ReadBackMismatch(t *testing.T) {
+	plan := testRollbackPlan()
+	client := &rollbackApplier{
+		mockKubeApplier: &mockKubeApplier{workloadObject: deploymentObject("2", resources("150m", "600m", "200Mi", "600Mi"))},
+		// A LimitRange default put a CPU limit back.
+		readBack: deploymentObject("3", resources("100m", "1", "128Mi", "512Mi")),
+	}
+
+	result := ExecuteRollback(context.Background(), client, &RollbackInput{Plan: plan})
+	require.NoError(t, result.Error)
+	assert.True(t, result.RolledBack)
+	require.Len(t, result.Mismatches, 1)
+	assert.Equal(t, apiResources+"limits.cpu", result.Mismatches[0].Path)
+	assert.Equal(t, "", result.Mismatches[0].Before)
+	assert.Equal(t, "1", result.Mismatches[0].After)
+}
+
+func TestExecuteRollback_ChangedSinceApply(t *testing.T) {
+	plan := testRollbackPlan()
+	mock := &mockKubeApplier{workloadObject: deploymentObject("5", resources("250m", "600m", "200Mi", "600Mi"))}
+
+	result := ExecuteRollback(context.Background(), mock, &RollbackInput{Plan: plan})
+	require.Error(t, result.Error)
+	assert.True(t, result.Refused)
+	assert.False(t, mock.patchCalled)
+	require.Len(t, result.ChangedSince, 1)
+	assert.Equal(t, "150m", result.ChangedSince[0].Before)
+	assert.Equal(t, "250m", result.ChangedSince[0].After)
+	assert.Equal(t, audit.RollbackStatusRefused, RollbackRecord(result, nil, "", false).Status)
+
+	forced := ExecuteRollback(context.Background(), mock, &RollbackInput{Plan: plan, Force: true})
+	assert.True(t, forced.RolledBack)
+	assert.True(t, mock.patchCalled)
+}
+
+func TestExecuteRollback_Conflicts(t *testing.T) {
+	live := deploymentObject("2", resources("150m", "600m", "200Mi", "600Mi"))
+
+	t.Run("gitops is never forced", func(t *testing.T) {
+		mock := &mockKubeApplier{
+			workloadObject: live,
+			patchErr:       fmt.Errorf("conflict: Apply failed with 1 conflict"),
+			managedFields:  []metav1.ManagedFieldsEntry{{Manager: "argocd", Operation: metav1.ManagedFieldsOperationApply}},
+		}
+		result := ExecuteRollback(context.Background(), mock, &RollbackInput{Plan: testRollbackPlan(), Force: true})
+		assert.True(t, result.Refused)
+		assert.True(t, result.GitOpsConflict)
+		assert.False(t, mock.patchForcedAt)
+	})
+
+	t.Run("non-gitops needs force", func(t *testing.T) {
+		mock := &mockKubeApplier{
+			workloadObject: live,
+			patchErr:       fmt.Errorf("conflict: Apply failed with 1 conflict"),
+			managedFields:  []metav1.ManagedFieldsEntry{{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate}},
+		}
+		result := ExecuteRollback(context.Background(), mock, &RollbackInput{Plan: testRollbackPlan()})
+		assert.True(t, result.Refused)
+		assert.False(t, mock.patchForcedAt)
+
+		result = ExecuteRollback(context.Background(), mock, &RollbackInput{Plan: testRollbackPlan(), Force: true})
+		assert.True(t, result.RolledBack)
+		assert.True(t, mock.patchForcedAt)
+	})
+
+	t.Run("patch failure", func(t *testing.T) {
+		mock := &mockKubeApplier{workloadObject: live, patchErr: fmt.Errorf("forbidden")}
+		result := ExecuteRollback(context.Background(), mock, &RollbackInput{Plan: testRollbackPlan()})
+		assert.False(t, result.RolledBack)
+		assert.Equal(t, audit.RollbackStatusFailed, RollbackRecord(result, nil, "", false).Status)
+	})
+}
+
+func TestExecuteRollback_DryRun(t *testing.T) {
+	plan := testRollbackPlan()
+	mock := &mockKubeApplier{
+		workloadObject: deploymentObject("2", resources("150m", "600m", "200Mi", "600Mi")),
+		dryRunObject:   deploymentObject("2", resources("100m", "", "128Mi", "512Mi")),
+	}
+
+	result := ExecuteRollback(context.Background(), mock, &RollbackInput{Plan: plan, DryRun: true})
+	require.NoError(t, result.Error)
+	assert.False(t, result.RolledBack)
+	assert.False(t, mock.patchCalled)
+	require.NotNil(t, result.Preview)
+	assert.Len(t, result.Restored, 4)
+}
+
+func TestBuildRollbackPatchJSON(t *testing.T) {
+	data, err := buildRollbackPatchJSON(testRollbackPlan())
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "Deployment", doc["kind"])
+	meta := doc["metadata"].(map[string]interface{})
+	assert.Contains(t, meta["annotations"].(map[string]interface{})["kubenow.dev/last-rollback"], "bundle=20260301T120000Z__default__deployment__api")
+
+	containers, ok := nestedList(doc, "spec", "template", "spec", "containers")
+	require.True(t, ok)
+	require.Len(t, containers, 1)
+	c := containers[0].(map[string]interface{})
+	assert.Equal(t, "api", c["name"])
+	assert.NotContains(t, c, "image", "only resources are owned by the rollback")
+	limits := c["resources"].(map[string]interface{})["limits"].(map[string]interface{})
+	assert.NotContains(t, limits, "cpu", "fields unset before the apply stay unset")
+	assert.Equal(t, "512Mi", limits["memory"])
+}
+
+func TestLoadRollbackPlan(t *testing.T) {
+	auditPath := t.TempDir()
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	newBundle := func(kind, status string) string {
+		before := deploymentObject("1", resources("100m", "500m", "128Mi", "512Mi"))
+		bundle, err := audit.CreateBundle(&audit.BundleConfig{
+			AuditPath:    auditPath,
+			Timestamp:    ts,
+			Workload:     audit.BundleWorkload{Kind: kind, Name: "api-" + status, Namespace: "default"},
+			BeforeObject: before,
+		})
+		require.NoError(t, err)
+		require.NoError(t, audit.FinalizeBundle(bundle, before, status, ts, nil))
+		return bundle.Dir
+	}
+
+	plan, err := LoadRollbackPlan(newBundle(KindDeployment, "applied"))
+	require.NoError(t, err)
+	assert.Equal(t, "api-applied", plan.Workload.Name)
+	assert.NotNil(t, plan.Before)
+	assert.NotNil(t, plan.After)
+
+	_, err = LoadRollbackPlan(newBundle(KindDeployment, "failed"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only applied changes")
+}
@@ -0,0 +1,236 @@
+package promonitor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/exposure"
+	"github.com/ppiankov/kubenow/internal/storage"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// DefaultShareTTL is how long a share link stays valid by default. It is
+// also the longest an S3 presigned URL can live.
+const DefaultShareTTL = 7 * 24 * time.Hour
+
+// ShareBundle is everything a shared evidence page shows. Exposure and
+// Traffic are optional; their sections are omitted when nil.
+type ShareBundle struct {
+	Recommendation *AlignmentRecommendation
+	Exposure       *exposure.ExposureMap
+	Traffic        *exposure.TrafficMap
+	GeneratedAt    time.Time
+	Version        string // kubenow version, shown in the footer
+}
+
+// ShareResult describes a published evidence page.
+type ShareResult struct {
+	Key      string // storage key, under shares/
+	Location string // where the page is stored, for messages
+	URL      string // read-only link to the page
+}
+
+// shareKey names a workload's evidence page. The timestamp keeps earlier
+// links working when the page is shared again.
+func shareKey(ref WorkloadRef, at time.Time) string {
+	return storage.Key(storage.KindShares, fmt.Sprintf("%s__%s__%s__%s.html",
+		ref.Namespace, ref.Kind, ref.Name, at.UTC().Format("20060102T150405Z")))
+}
+
+// PublishShare renders the bundle as a self-contained HTML page, stores it
+// under shares/, and returns a read-only link valid for ttl. Stores that
+// cannot hand out links (ConfigMap) fail before anything is written.
+func PublishShare(ctx context.Context, s storage.Store, b *ShareBundle, ttl time.Duration) (*ShareResult, error) {
+	if b == nil || b.Recommendation == nil {
+		return nil, fmt.Errorf("no recommendation to share")
+	}
+	if b.GeneratedAt.IsZero() {
+		b.GeneratedAt = time.Now()
+	}
+	if err := storage.CanShare(s); err != nil {
+		return nil, err
+	}
+	key := shareKey(b.Recommendation.Workload, b.GeneratedAt)
+
+	var page bytes.Buffer
+	if err := RenderShareHTML(b, &page); err != nil {
+		return nil, err
+	}
+	if err := s.Put(ctx, key, page.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to store evidence page: %w", err)
+	}
+	link, err := storage.ShareURL(s, key, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return &ShareResult{Key: key, Location: s.Location(key), URL: link}, nil
+}
+
+// RenderShareHTML writes the bundle as a standalone HTML page: no scripts,
+// no external assets, readable without kubenow or cluster access.
+func RenderShareHTML(b *ShareBundle, w io.Writer) error {
+	tmpl, err := template.New("share").Funcs(template.FuncMap{
+		"cpu":       units.Millicores,
+		"mem":       units.MemoryMi,
+		"bytes":     units.Memory,
+		"delta":     fmtDelta,
+		"duration":  formatDuration,
+		"latency":   fmtLatency,
+		"ports":     formatServicePorts,
+		"route":     formatIngressRoute,
+		"routeKind": ingressRouteLabel,
+		"sources":   formatNetPolSources,
+		"lower":     strings.ToLower,
+		"pct":       func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
+		"rps":       func(f float64) string { return fmt.Sprintf("%.1f", f) },
+	}).Parse(shareHTML)
+	if err != nil {
+		return fmt.Errorf("failed to parse share template: %w", err)
+	}
+	if err := tmpl.Execute(w, b); err != nil {
+		return fmt.Errorf("failed to render share page: %w", err)
+	}
+	return nil
+}
+
+const shareHTML = `<!DOCTYPE html>
+<html>
+<head>
+    <title>kubenow evidence: {{with .Recommendation.Workload}}{{.Namespace}}/{{lower .Kind}}/{{.Name}}{{end}}</title>
+    <meta charset="UTF-8">
+    <meta name="robots" content="noindex">
+    <style>
+        body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; max-width: 1100px; margin: 40px auto; padding: 20px; }
+        h1 { color: #1976d2; }
+        .metadata { background: #f5f5f5; padding: 15px; border-left: 4px solid #1976d2; margin-bottom: 20px; }
+        .warning { background: #fff3e0; padding: 10px 15px; border-left: 4px solid #ef6c00; margin-bottom: 12px; }
+        table { border-collapse: collapse; width: 100%; margin-bottom: 24px; font-size: 14px; }
+        th, td { border: 1px solid #e0e0e0; padding: 6px 10px; text-align: left; }
+        th { background: #fafafa; }
+        td.num { text-align: right; font-variant-numeric: tabular-nums; }
+        .safety-UNSAFE { color: #c62828; font-weight: 600; }
+        .safety-RISKY { color: #ef6c00; font-weight: 600; }
+        .safety-CAUTION { color: #f9a825; }
+        .safety-SAFE { color: #2e7d32; }
+    </style>
+</head>
+<body>
+{{- with .Recommendation}}
+    <h1>{{.Workload.Namespace}}/{{lower .Workload.Kind}}/{{.Workload.Name}}</h1>
+    <div class="metadata">
+        <p><strong>Recommendation computed:</strong> {{.Timestamp.UTC.Format "2006-01-02 15:04:05 UTC"}}</p>
+        <p><strong>Safety:</strong> <span class="safety-{{.Safety}}">{{.Safety}}</span> &nbsp; <strong>Confidence:</strong> {{.Confidence}}</p>
+        {{- with .Source}}
+        <p><strong>Managed by:</strong> {{.Tool}}{{with .Release}} release {{.}}{{end}}{{with .Kustomization}} kustomization {{.}}{{end}}</p>
+        {{- end}}
+        <p>Read-only snapshot. Nothing on this page changes the cluster.</p>
+    </div>
+    {{- with .Policy}}{{if .HPADetected}}
+    <div class="warning">HPA {{.HPAName}} targets this workload: resource changes shift its scaling behavior.</div>
+    {{- end}}{{end}}
+    {{- range .Warnings}}
+    <div class="warning">{{.}}</div>
+    {{- end}}
+
+    <h2>Recommendation</h2>
+    <table>
+        <thead><tr><th>Container</th><th>Resource</th><th>Current</th><th>Recommended</th><th>Change</th></tr></thead>
+        <tbody>
+        {{- range .Containers}}
+            <tr><td>{{.Name}}</td><td>CPU request</td><td class="num">{{cpu .Current.CPURequest}}</td><td class="num">{{cpu .Recommended.CPURequest}}</td><td class="num">{{delta .Delta.CPURequestPercent}}</td></tr>
+            <tr><td>{{.Name}}</td><td>CPU limit</td><td class="num">{{cpu .Current.CPULimit}}</td><td class="num">{{cpu .Recommended.CPULimit}}</td><td class="num">{{delta .Delta.CPULimitPercent}}</td></tr>
+            <tr><td>{{.Name}}</td><td>Memory request</td><td class="num">{{mem .Current.MemoryRequest}}</td><td class="num">{{mem .Recommended.MemoryRequest}}</td><td class="num">{{delta .Delta.MemoryRequestPercent}}</td></tr>
+            <tr><td>{{.Name}}</td><td>Memory limit</td><td class="num">{{mem .Current.MemoryLimit}}</td><td class="num">{{mem .Recommended.MemoryLimit}}</td><td class="num">{{delta .Delta.MemoryLimitPercent}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+
+    {{- with .Evidence}}
+    <h2>Evidence</h2>
+    <p>Observed for {{duration .Duration}}{{if .PlannedDuration}} of a planned {{duration .PlannedDuration}}{{end}}: {{.SampleCount}} samples every {{.SampleInterval}}, {{.Gaps}} gaps{{if not .Valid}} (<strong>not valid for apply</strong>){{end}}.</p>
+    <table>
+        <thead><tr><th>Usage</th><th>p50</th><th>p95</th><th>p99</th><th>max</th><th>avg</th></tr></thead>
+        <tbody>
+        {{- with .CPU}}
+            <tr><td>CPU</td><td class="num">{{cpu .P50}}</td><td class="num">{{cpu .P95}}</td><td class="num">{{cpu .P99}}</td><td class="num">{{cpu .Max}}</td><td class="num">{{cpu .Avg}}</td></tr>
+        {{- end}}
+        {{- with .Memory}}
+            <tr><td>Memory</td><td class="num">{{mem .P50}}</td><td class="num">{{mem .P95}}</td><td class="num">{{mem .P99}}</td><td class="num">{{mem .Max}}</td><td class="num">{{mem .Avg}}</td></tr>
+        {{- end}}
+        {{- with .NetworkRX}}
+            <tr><td>Network RX (/s)</td><td class="num">{{bytes .P50}}</td><td class="num">{{bytes .P95}}</td><td class="num">{{bytes .P99}}</td><td class="num">{{bytes .Max}}</td><td class="num">{{bytes .Avg}}</td></tr>
+        {{- end}}
+        {{- with .NetworkTX}}
+            <tr><td>Network TX (/s)</td><td class="num">{{bytes .P50}}</td><td class="num">{{bytes .P95}}</td><td class="num">{{bytes .P99}}</td><td class="num">{{bytes .Max}}</td><td class="num">{{bytes .Avg}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    {{- if .NetworkBound}}
+    <p>CPU follows network traffic (correlation {{printf "%.2f" .NetworkCPUCorrelation}}): the CPU request is sized on p99 instead of p95.</p>
+    {{- end}}
+    {{- end}}
+{{- end}}
+
+{{- with .Exposure}}
+    <h2>Exposure map</h2>
+    {{- if .Services}}
+    <table>
+        <thead><tr><th>Service</th><th>Type</th><th>Ports</th><th>Routes</th><th>Network policies</th></tr></thead>
+        <tbody>
+        {{- range .Services}}
+            <tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{ports .Ports}}</td>
+                <td>{{range $i, $r := .Ingresses}}{{if $i}}<br>{{end}}{{routeKind $r}}: {{route $r}}{{else}}none{{end}}</td>
+                <td>{{range $i, $p := .NetPols}}{{if $i}}<br>{{end}}{{$p.PolicyName}}: {{sources $p.Sources}}{{else}}none{{end}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    {{- else}}
+    <p>No Service selects this workload.</p>
+    {{- end}}
+    {{- if .Neighbors}}
+    <h3>Namespace neighbors (by CPU)</h3>
+    <table>
+        <thead><tr><th>Workload</th><th>Kind</th><th>Pods</th><th>CPU</th><th>Memory</th></tr></thead>
+        <tbody>
+        {{- range .Neighbors}}
+            <tr><td>{{.WorkloadName}}</td><td>{{.WorkloadKind}}</td><td class="num">{{.PodCount}}</td><td class="num">{{.CPUMillis}}m</td><td class="num">{{.MemoryMi}}Mi</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    {{- end}}
+    {{- range .Errors}}
+    <p><em>Partial data: {{.}}</em></p>
+    {{- end}}
+{{- end}}
+
+{{- with .Traffic}}
+    <h2>Traffic map{{with .Mesh}} ({{.}}){{end}}</h2>
+    <p>Measured over {{.Window}}. TCP connections: {{.TCPIn}} in, {{.TCPOut}} out.</p>
+    <h3>Inbound</h3>
+    {{template "edges" .Inbound}}
+    <h3>Outbound</h3>
+    {{template "edges" .Outbound}}
+{{- end}}
+    <hr>
+    <p><em>Shared {{.GeneratedAt.UTC.Format "2006-01-02 15:04:05 UTC"}} by <a href="https://github.com/ppiankov/kubenow">kubenow</a>{{with .Version}} {{.}}{{end}}</em></p>
+</body>
+</html>
+{{define "edges"}}
+    {{- if .}}
+    <table>
+        <thead><tr><th>Workload</th><th>Namespace</th><th>RPS</th><th>Success</th><th>p50</th><th>p99</th></tr></thead>
+        <tbody>
+        {{- range .}}
+            <tr><td>{{.Deployment}}</td><td>{{.Namespace}}</td><td class="num">{{rps .RPS}}</td><td class="num">{{if ge .SuccessRate 0.0}}{{pct .SuccessRate}}{{else}}—{{end}}</td><td class="num">{{if ge .LatencyP50 0.0}}{{latency .LatencyP50}}{{else}}—{{end}}</td><td class="num">{{if ge .LatencyP99 0.0}}{{latency .LatencyP99}}{{else}}—{{end}}</td></tr>
+        {{- end}}
+        </tbody>
+    </table>
+    {{- else}}
+    <p>No traffic observed.</p>
+    {{- end}}
+{{- end}}`
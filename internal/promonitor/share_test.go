@@ -0,0 +1,94 @@
+package promonitor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/exposure"
+	"github.com/ppiankov/kubenow/internal/storage"
+)
+
+func testShareBundle() *ShareBundle {
+	return &ShareBundle{
+		Recommendation: testRecommendation(),
+		Exposure: &exposure.ExposureMap{
+			Services: []exposure.ServiceExposure{{
+				Name:      "payment-api",
+				Type:      "ClusterIP",
+				Ports:     []exposure.PortMapping{{Name: "http", Port: 8080}},
+				Ingresses: []exposure.IngressRoute{{Hosts: []string{"pay.example.com"}, TLS: true}},
+			}},
+			Neighbors: []exposure.Neighbor{{WorkloadName: "<script>", CPUMillis: 120}},
+		},
+		Traffic: &exposure.TrafficMap{
+			Mesh:    exposure.MeshLinkerd,
+			Inbound: []exposure.TrafficEdge{{Deployment: "checkout", Namespace: "shop", RPS: 12.5, SuccessRate: 0.995, LatencyP50: 3, LatencyP99: -1}},
+			Window:  time.Hour,
+		},
+		GeneratedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Version:     "v1.2.3",
+	}
+}
+
+func TestRenderShareHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderShareHTML(testShareBundle(), &buf))
+	page := buf.String()
+
+	assert.Contains(t, page, "<h1>default/deployment/payment-api</h1>")
+	assert.Contains(t, page, `<span class="safety-CAUTION">CAUTION</span>`)
+	assert.Contains(t, page, "<td>CPU request</td><td class=\"num\">100m</td><td class=\"num\">180m</td><td class=\"num\">&#43;80%</td>")
+	assert.Contains(t, page, "180 samples every 5s")
+	assert.Contains(t, page, "ingress: pay.example.com [TLS]")
+	assert.Contains(t, page, "Traffic map (Linkerd)")
+	assert.Contains(t, page, "<td>checkout</td><td>shop</td><td class=\"num\">12.5</td><td class=\"num\">99.5%</td><td class=\"num\">3ms</td><td class=\"num\">—</td>")
+	assert.Contains(t, page, "No traffic observed.", "empty outbound edges")
+	assert.Contains(t, page, "kubenow</a> v1.2.3")
+	assert.Contains(t, page, "&lt;script&gt;", "cluster data is escaped")
+	assert.NotContains(t, page, "<script>")
+}
+
+func TestRenderShareHTML_RecommendationOnly(t *testing.T) {
+	b := testShareBundle()
+	b.Exposure, b.Traffic = nil, nil
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderShareHTML(b, &buf))
+	assert.NotContains(t, buf.String(), "Exposure map")
+	assert.NotContains(t, buf.String(), "Traffic map")
+}
+
+func TestPublishShare_Local(t *testing.T) {
+	root := t.TempDir()
+	store := storage.NewLocal(root)
+
+	result, err := PublishShare(context.Background(), store, testShareBundle(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "shares/default__Deployment__payment-api__20260301T120000Z.html", result.Key)
+	assert.Equal(t, store.Location(result.Key), result.Location)
+	assert.True(t, strings.HasPrefix(result.URL, "file://"))
+
+	page, err := os.ReadFile(result.Location)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "payment-api")
+}
+
+func TestPublishShare_Errors(t *testing.T) {
+	_, err := PublishShare(context.Background(), storage.NewLocal(t.TempDir()), &ShareBundle{}, time.Hour)
+	assert.ErrorContains(t, err, "no recommendation")
+
+	cm := storage.NewConfigMap(fake.NewClientset(), "kubenow")
+	_, err = PublishShare(context.Background(), cm, testShareBundle(), time.Hour)
+	assert.ErrorContains(t, err, "cannot share")
+	objects, listErr := cm.List(context.Background(), "shares/")
+	require.NoError(t, listErr)
+	assert.Empty(t, objects, "nothing is written when the store cannot share")
+}
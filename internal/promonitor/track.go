@@ -10,6 +10,7 @@ import (
 
 	"github.com/ppiankov/kubenow/internal/audit"
 	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 // Classification thresholds for post-apply outcome assessment.
@@ -36,6 +37,7 @@ type TrackInput struct {
 	Decision *audit.DecisionJSON
 	Usage    *metrics.WorkloadUsage // nil if no Prometheus
 	Now      time.Time
+	MaxAge   time.Duration // flag applied values computed longer ago; zero disables
 }
 
 // TrackResult is the classification output for a single apply.
@@ -50,6 +52,13 @@ type TrackResult struct {
 	AuditDir   string               `json:"audit_dir"`
 	Changes    []audit.BundleChange `json:"changes"`
 	Reason     string               `json:"reason,omitempty"`
+
+	// Stale flags applied values that are due for a re-latch: computed more
+	// than MaxAge ago, or past their trend-derived validity window.
+	ComputedAt  time.Time `json:"computed_at"`
+	ValidUntil  time.Time `json:"valid_until,omitzero"`
+	Stale       bool      `json:"stale,omitempty"`
+	StaleReason string    `json:"stale_reason,omitempty"`
 }
 
 // TrackSummary aggregates results across all scanned applies.
@@ -61,6 +70,7 @@ type TrackSummary struct {
 	Wrong        int           `json:"wrong"`
 	Pending      int           `json:"pending"`
 	NoData       int           `json:"no_data"`
+	Stale        int           `json:"stale"`
 	ScannedAt    time.Time     `json:"scanned_at"`
 }
 
@@ -71,6 +81,7 @@ type TrackConfig struct {
 	Since          time.Duration
 	WorkloadFilter *WorkloadRef // nil = all
 	Now            time.Time
+	MaxAge         time.Duration // flag applied values computed longer ago; zero disables
 }
 
 // ClassifyOutcome is a pure function that determines the post-apply outcome
@@ -97,6 +108,7 @@ func ClassifyOutcome(input *TrackInput) *TrackResult {
 		}
 	}
 	result.AppliedAt = appliedAt
+	classifyStaleness(result, d, input.MaxAge, input.Now)
 
 	// 1. PENDING if less than 24h since apply
 	if input.Now.Sub(appliedAt) < pendingWindow {
@@ -155,83 +167,60 @@ func ClassifyOutcome(input *TrackInput) *TrackResult {
 	return result
 }
 
+// classifyStaleness flags applied values due for a re-latch. Bundles
+// recorded before computed_at existed fall back to the apply time, which
+// understates the age.
+func classifyStaleness(result *TrackResult, d *audit.DecisionJSON, maxAge time.Duration, now time.Time) {
+	result.ComputedAt = result.AppliedAt
+	if ts, err := time.Parse(time.RFC3339, d.Recommendation.ComputedAt); err == nil {
+		result.ComputedAt = ts
+	}
+	if ts, err := time.Parse(time.RFC3339, d.Recommendation.ValidUntil); err == nil {
+		result.ValidUntil = ts
+	}
+
+	switch age := now.Sub(result.ComputedAt); {
+	case maxAge > 0 && !result.ComputedAt.IsZero() && age > maxAge:
+		result.Stale = true
+		result.StaleReason = fmt.Sprintf("values computed %s ago (older than %s)", formatAge(age), formatAge(maxAge))
+	case !result.ValidUntil.IsZero() && now.After(result.ValidUntil):
+		result.Stale = true
+		result.StaleReason = fmt.Sprintf("validity window ended %s", result.ValidUntil.Format("2006-01-02"))
+	}
+}
+
+// formatAge renders long durations in weeks or days.
+func formatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d >= 7*day && d%(7*day) == 0:
+		return fmt.Sprintf("%dw", d/(7*day))
+	case d >= 7*day:
+		return fmt.Sprintf("%.1fw", d.Hours()/24/7)
+	default:
+		return fmt.Sprintf("%dd", d/day)
+	}
+}
+
 // extractNewRequest sums the "After" values across all containers for a given
 // field suffix (e.g., "cpu_request"). The field pattern is "{container}/{suffix}".
 func extractNewRequest(changes []audit.BundleChange, fieldSuffix string) float64 {
 	var total float64
 	for _, c := range changes {
 		if strings.HasSuffix(c.Field, "/"+fieldSuffix) {
+			// Values that do not parse (e.g. "" for no limit) count as 0
+			var v float64
 			if strings.Contains(fieldSuffix, "memory") {
-				total += parseMemResource(c.After)
+				v, _ = units.ParseMemory(c.After)
 			} else {
-				total += parseCPUResource(c.After)
+				v, _ = units.ParseCPU(c.After)
 			}
+			total += v
 		}
 	}
 	return total
 }
 
-// parseCPUResource converts a K8s CPU resource string to cores.
-// Inverse of formatCPUResource in export.go.
-// Examples: "150m" → 0.15, "1" → 1.0, "0m" → 0
-func parseCPUResource(s string) float64 {
-	if s == "" {
-		return 0
-	}
-	if strings.HasSuffix(s, "m") {
-		s = strings.TrimSuffix(s, "m")
-		var m float64
-		if _, err := fmt.Sscanf(s, "%f", &m); err != nil {
-			return 0
-		}
-		return m / 1000
-	}
-	var cores float64
-	if _, err := fmt.Sscanf(s, "%f", &cores); err != nil {
-		return 0
-	}
-	return cores
-}
-
-// parseMemResource converts a K8s memory resource string to bytes.
-// Inverse of formatMemResource in export.go.
-// Examples: "200Mi" → 209715200, "1Gi" → 1073741824, "0" → 0
-func parseMemResource(s string) float64 {
-	if s == "" || s == "0" {
-		return 0
-	}
-	if strings.HasSuffix(s, "Gi") {
-		s = strings.TrimSuffix(s, "Gi")
-		var gi float64
-		if _, err := fmt.Sscanf(s, "%f", &gi); err != nil {
-			return 0
-		}
-		return gi * 1024 * 1024 * 1024
-	}
-	if strings.HasSuffix(s, "Mi") {
-		s = strings.TrimSuffix(s, "Mi")
-		var mi float64
-		if _, err := fmt.Sscanf(s, "%f", &mi); err != nil {
-			return 0
-		}
-		return mi * 1024 * 1024
-	}
-	if strings.HasSuffix(s, "Ki") {
-		s = strings.TrimSuffix(s, "Ki")
-		var ki float64
-		if _, err := fmt.Sscanf(s, "%f", &ki); err != nil {
-			return 0
-		}
-		return ki * 1024
-	}
-	// Plain bytes
-	var b float64
-	if _, err := fmt.Sscanf(s, "%f", &b); err != nil {
-		return 0
-	}
-	return b
-}
-
 // RunTrack orchestrates the full tracking workflow: scan audit bundles,
 // query Prometheus for post-apply usage, classify each apply.
 func RunTrack(ctx context.Context, cfg *TrackConfig) (*TrackSummary, error) {
@@ -279,6 +268,7 @@ func RunTrack(ctx context.Context, cfg *TrackConfig) (*TrackSummary, error) {
 			Decision: &decision,
 			Usage:    usage,
 			Now:      cfg.Now,
+			MaxAge:   cfg.MaxAge,
 		})
 		result.AuditDir = b.Dir
 
@@ -297,6 +287,9 @@ func RunTrack(ctx context.Context, cfg *TrackConfig) (*TrackSummary, error) {
 		case OutcomeNoData:
 			summary.NoData++
 		}
+		if result.Stale {
+			summary.Stale++
+		}
 	}
 
 	return summary, nil
@@ -334,6 +327,17 @@ func FormatTrackTable(summary *TrackSummary) string {
 	b.WriteString(fmt.Sprintf("  Score: %d applied | %d SAFE | %d TIGHT | %d WRONG | %d PENDING | %d NO_DATA\n",
 		summary.TotalApplied, summary.Safe, summary.Tight, summary.Wrong, summary.Pending, summary.NoData))
 
+	if summary.Stale > 0 {
+		b.WriteString(fmt.Sprintf("\n  STALE (%d): re-latch to refresh\n", summary.Stale))
+		for i := range summary.Results {
+			r := &summary.Results[i]
+			if r.Stale {
+				b.WriteString(fmt.Sprintf("  %-26s %s\n",
+					fmt.Sprintf("%s/%s", strings.ToLower(r.Workload.Kind), r.Workload.Name), r.StaleReason))
+			}
+		}
+	}
+
 	return b.String()
 }
 
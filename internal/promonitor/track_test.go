@@ -12,55 +12,6 @@ import (
 	"github.com/ppiankov/kubenow/internal/metrics"
 )
 
-// --- Resource parser tests ---
-
-func TestParseCPUResource(t *testing.T) {
-	tests := []struct {
-		input string
-		want  float64
-	}{
-		{"150m", 0.15},
-		{"1", 1.0},
-		{"1000m", 1.0},
-		{"0m", 0},
-		{"500m", 0.5},
-		{"2", 2.0},
-		{"", 0},
-		{"bad", 0},
-	}
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := parseCPUResource(tt.input)
-			if got != tt.want {
-				t.Errorf("parseCPUResource(%q) = %f, want %f", tt.input, got, tt.want)
-			}
-		})
-	}
-}
-
-func TestParseMemResource(t *testing.T) {
-	tests := []struct {
-		input string
-		want  float64
-	}{
-		{"200Mi", 200 * 1024 * 1024},
-		{"1Gi", 1024 * 1024 * 1024},
-		{"0", 0},
-		{"", 0},
-		{"512Ki", 512 * 1024},
-		{"1048576", 1048576},
-		{"bad", 0},
-	}
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := parseMemResource(tt.input)
-			if got != tt.want {
-				t.Errorf("parseMemResource(%q) = %f, want %f", tt.input, got, tt.want)
-			}
-		})
-	}
-}
-
 // --- Classification tests ---
 
 func makeDecision(appliedAt time.Time, changes []audit.BundleChange) *audit.DecisionJSON {
@@ -291,6 +242,53 @@ func TestClassifyOutcome_ZeroRequest(t *testing.T) {
 
 // --- Formatter tests ---
 
+func TestClassifyOutcome_StaleByAge(t *testing.T) {
+	now := time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC)
+	decision := makeDecision(now.Add(-6*7*24*time.Hour), defaultChanges())
+	decision.Recommendation.ComputedAt = now.Add(-9 * 7 * 24 * time.Hour).Format(time.RFC3339)
+
+	result := ClassifyOutcome(&TrackInput{Decision: decision, Now: now, MaxAge: 8 * 7 * 24 * time.Hour})
+
+	if !result.Stale {
+		t.Fatal("expected values computed 9w ago to be stale with an 8w max age")
+	}
+	if result.StaleReason != "values computed 9w ago (older than 8w)" {
+		t.Errorf("unexpected reason %q", result.StaleReason)
+	}
+	if result.Outcome != OutcomeNoData {
+		t.Errorf("staleness must not replace the outcome, got %s", result.Outcome)
+	}
+}
+
+func TestClassifyOutcome_StaleByValidity(t *testing.T) {
+	now := time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC)
+	decision := makeDecision(now.Add(-10*24*time.Hour), defaultChanges())
+	decision.Recommendation.ValidUntil = now.Add(-2 * 24 * time.Hour).Format(time.RFC3339)
+
+	result := ClassifyOutcome(&TrackInput{Decision: decision, Now: now, MaxAge: 8 * 7 * 24 * time.Hour})
+
+	if !result.Stale {
+		t.Fatal("expected an ended validity window to be stale")
+	}
+	if !contains(result.StaleReason, "validity window ended 2026-04-28") {
+		t.Errorf("unexpected reason %q", result.StaleReason)
+	}
+}
+
+func TestClassifyOutcome_NotStale(t *testing.T) {
+	now := time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC)
+	decision := makeDecision(now.Add(-10*7*24*time.Hour), defaultChanges())
+
+	// Without computed_at the apply time is used; no max age disables the check.
+	result := ClassifyOutcome(&TrackInput{Decision: decision, Now: now})
+	if result.Stale {
+		t.Errorf("expected no staleness without a max age, got %q", result.StaleReason)
+	}
+	if !result.ComputedAt.Equal(result.AppliedAt) {
+		t.Errorf("expected computed_at to fall back to the apply time, got %s", result.ComputedAt)
+	}
+}
+
 func TestFormatTrackTable(t *testing.T) {
 	summary := &TrackSummary{
 		Results: []TrackResult{
@@ -106,6 +106,13 @@ type LatchEvidence struct {
 	Valid           bool                 `json:"valid"`
 	CPU             *metrics.Percentiles `json:"cpu_percentiles"`
 	Memory          *metrics.Percentiles `json:"memory_percentiles"`
+
+	Containers map[string]*metrics.ContainerPercentiles `json:"container_percentiles,omitempty"`
+
+	NetworkRX             *metrics.Percentiles `json:"network_rx_percentiles,omitempty"` // bytes/s per pod
+	NetworkTX             *metrics.Percentiles `json:"network_tx_percentiles,omitempty"` // bytes/s per pod
+	NetworkCPUCorrelation float64              `json:"network_cpu_correlation,omitempty"`
+	NetworkBound          bool                 `json:"network_bound,omitempty"` // CPU sized on p99: it follows traffic
 }
 
 // PolicyBounds holds the policy guardrails relevant to recommendation and apply.
@@ -117,6 +124,11 @@ type PolicyBounds struct {
 	MinSafetyRating    SafetyRating
 	MaxLatchAge        time.Duration
 	MinLatchDuration   time.Duration
+
+	// Limit/request ratio mandates (0 = no mandate)
+	MaxCPULimitRatio    float64
+	MaxMemoryLimitRatio float64
+	ForbidCPULimits     bool
 }
 
 // PolicyResult summarizes policy evaluation for a recommendation.
@@ -139,6 +151,12 @@ type AlignmentRecommendation struct {
 	Evidence   *LatchEvidence       `json:"latch_evidence"`
 	Policy     *PolicyResult        `json:"policy_result"`
 	Warnings   []string             `json:"warnings,omitempty"`
+	Source     *GitOpsSource        `json:"gitops_source,omitempty"` // set by callers that inspected the workload
+
+	// ValidUntil ends the recommendation's validity window, derived from
+	// Trend; zero when no recommendation was produced.
+	ValidUntil time.Time   `json:"valid_until,omitzero"`
+	Trend      *UsageTrend `json:"usage_trend,omitempty"`
 }
 
 // RecommendInput holds all inputs to the recommendation engine.
@@ -147,5 +165,6 @@ type RecommendInput struct {
 	Containers []ContainerResources
 	Bounds     *PolicyBounds // nil = no policy bounds
 	HPA        *HPAInfo
-	HasProm    bool // Whether Prometheus historical data is available
+	OOMHistory *OOMHistory // OOM kills before the latch; nil = not checked
+	HasProm    bool        // Whether Prometheus historical data is available
 }
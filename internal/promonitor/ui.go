@@ -8,6 +8,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/ppiankov/kubenow/internal/exposure"
+	"github.com/ppiankov/kubenow/internal/keymap"
+	"github.com/ppiankov/kubenow/internal/units"
 )
 
 var (
@@ -78,7 +80,8 @@ func renderView(m *Model) string {
 
 	// Early-stop warning
 	if m.earlyStopPending {
-		b.WriteString(warnStyle.Render("Press Esc again to stop latching and proceed with collected data. Any other key to continue."))
+		b.WriteString(warnStyle.Render(fmt.Sprintf("Press %s again to stop latching and proceed with collected data. Any other key to continue.",
+			m.keys.Label(actionStopEarly))))
 		b.WriteString("\n")
 	}
 
@@ -86,10 +89,15 @@ func renderView(m *Model) string {
 	b.WriteString(renderPolicyStatus(m))
 	b.WriteString("\n\n")
 
-	b.WriteString(renderMainContent(m))
+	if m.showHelp {
+		b.WriteString(renderHelp(m))
+	} else {
+		b.WriteString(renderMainContent(m))
+	}
 	b.WriteString("\n\n")
 
 	b.WriteString(renderExportStatus(m))
+	b.WriteString(renderShareStatus(m))
 	b.WriteString(renderApplyStatus(m))
 	b.WriteString(renderKeyBindings(m))
 
@@ -153,10 +161,26 @@ func renderExportStatus(m *Model) string {
 	}
 }
 
+func renderShareStatus(m *Model) string {
+	switch {
+	case m.sharing:
+		return m.spinner.View() + dimStyle.Render(" Publishing evidence page...") + "\n"
+	case m.shareResult != nil:
+		return okStyle.Render("Shared: "+m.shareResult.URL) + "\n" +
+			dimStyle.Render(fmt.Sprintf("  stored at %s; the link is printed again on exit for copying", m.shareResult.Location)) + "\n"
+	case m.shareError != nil:
+		return warnStyle.Render(fmt.Sprintf("Share failed: %v", m.shareError)) + "\n"
+	default:
+		return ""
+	}
+}
+
 func renderApplyStatus(m *Model) string {
 	switch {
 	case m.confirming:
 		return renderConfirmationPrompt(m) + "\n"
+	case m.previewing:
+		return m.spinner.View() + dimStyle.Render(" Running server-side dry-run...") + "\n"
 	case m.applying:
 		return m.spinner.View() + dimStyle.Render(" Applying via Server-Side Apply...") + "\n"
 	case m.applyResult != nil:
@@ -171,49 +195,74 @@ func renderKeyBindings(m *Model) string {
 	var keys []string
 
 	if !m.latchDone && m.latch != nil {
-		keys = append(keys, "esc: stop early")
+		keys = append(keys, keyHint(m, actionStopEarly, "stop early"))
 	}
 	keys = append(keys, renderOverlayKeyBindings(m)...)
 	if canExportRecommendation(m, overlay) {
-		keys = append(keys, "e: export")
+		keys = append(keys, keyHint(m, actionExport, "export"))
 	}
 	if canApplyRecommendation(m, overlay) {
-		keys = append(keys, "a: apply")
+		keys = append(keys, keyHint(m, actionApply, "apply"))
 	}
-	keys = append(keys, "q: quit")
+	if canShareRecommendation(m, overlay) {
+		keys = append(keys, keyHint(m, actionShare, "share"))
+	}
+	keys = append(keys, keyHint(m, keymap.Help, "help"), keyHint(m, actionQuit, "quit"))
 
 	return dimStyle.Render(strings.Join(keys, "  "))
 }
 
+func keyHint(m *Model, action keymap.Action, label string) string {
+	return m.keys.Label(action) + ": " + label
+}
+
 func renderOverlayKeyBindings(m *Model) []string {
 	if m.recommendation == nil || m.exposureCollector == nil {
 		return nil
 	}
 
-	keys := []string{"l: exposure map"}
+	keys := []string{keyHint(m, actionExposure, "exposure map")}
 	if m.showExposure {
-		keys[0] = "l: dismiss"
+		keys[0] = keyHint(m, actionExposure, "dismiss")
 	}
 	if !m.exposureCollector.HasPrometheus() {
 		return keys
 	}
 	if m.showTraffic {
-		keys = append(keys, "t: dismiss")
+		keys = append(keys, keyHint(m, actionTraffic, "dismiss"))
 		return keys
 	}
 
-	return append(keys, "t: traffic map")
+	return append(keys, keyHint(m, actionTraffic, "traffic map"))
+}
+
+// renderHelp renders the key binding overlay from the active bindings.
+func renderHelp(m *Model) string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("--- Key Bindings ---"))
+	b.WriteString("\n")
+	for _, line := range m.keys.HelpLines() {
+		b.WriteString(valueStyle.Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString(dimStyle.Render("Remap in the keybindings.pro-monitor section of ~/.kubenow/config.yaml. Press any key to close."))
+	return b.String()
 }
 
 func canExportRecommendation(m *Model, overlay bool) bool {
 	return m.recommendation != nil && !m.exported && !overlay
 }
 
+func canShareRecommendation(m *Model, overlay bool) bool {
+	return m.recommendation != nil && !m.sharing && m.shareResult == nil && !overlay
+}
+
 func canApplyRecommendation(m *Model, overlay bool) bool {
 	return m.recommendation != nil &&
 		m.mode == ModeApplyReady &&
 		m.applyResult == nil &&
 		!m.applying &&
+		!m.previewing &&
 		!m.confirming &&
 		!overlay
 }
@@ -318,6 +367,11 @@ func renderRecommendation(rec *AlignmentRecommendation) string {
 	b.WriteString(labelStyle.Render("Confidence: "))
 	b.WriteString(confStr)
 	b.WriteString("\n")
+	if !rec.ValidUntil.IsZero() {
+		b.WriteString(labelStyle.Render("Valid until: "))
+		b.WriteString(renderValidity(rec, time.Now()))
+		b.WriteString("\n")
+	}
 
 	// Warnings
 	for _, w := range rec.Warnings {
@@ -347,14 +401,14 @@ func renderRecommendation(rec *AlignmentRecommendation) string {
 		}
 		b.WriteString("\n")
 
-		b.WriteString(renderResourceLine("CPU req", c.Current.CPURequest, c.Recommended.CPURequest, c.Delta.CPURequestPercent, fmtCPU))
-		b.WriteString(renderResourceLine("CPU lim", c.Current.CPULimit, c.Recommended.CPULimit, c.Delta.CPULimitPercent, fmtCPU))
+		b.WriteString(renderResourceLine("CPU req", c.Current.CPURequest, c.Recommended.CPURequest, c.Delta.CPURequestPercent, units.Millicores))
+		b.WriteString(renderResourceLine("CPU lim", c.Current.CPULimit, c.Recommended.CPULimit, c.Delta.CPULimitPercent, units.Millicores))
 		// Only show MEM rows when at least one side has a value set
 		if c.Current.MemoryRequest > 0 || c.Recommended.MemoryRequest > 0 {
-			b.WriteString(renderResourceLine("MEM req", c.Current.MemoryRequest, c.Recommended.MemoryRequest, c.Delta.MemoryRequestPercent, fmtMem))
+			b.WriteString(renderResourceLine("MEM req", c.Current.MemoryRequest, c.Recommended.MemoryRequest, c.Delta.MemoryRequestPercent, units.MemoryMi))
 		}
 		if c.Current.MemoryLimit > 0 || c.Recommended.MemoryLimit > 0 {
-			b.WriteString(renderResourceLine("MEM lim", c.Current.MemoryLimit, c.Recommended.MemoryLimit, c.Delta.MemoryLimitPercent, fmtMem))
+			b.WriteString(renderResourceLine("MEM lim", c.Current.MemoryLimit, c.Recommended.MemoryLimit, c.Delta.MemoryLimitPercent, units.MemoryMi))
 		}
 	}
 
@@ -368,6 +422,11 @@ func renderRecommendation(rec *AlignmentRecommendation) string {
 		}
 		b.WriteString(labelStyle.Render(evidenceStr))
 		b.WriteString("\n")
+		if ev := rec.Evidence; ev.NetworkRX != nil && ev.NetworkTX != nil {
+			b.WriteString(labelStyle.Render(fmt.Sprintf("  Network: rx p95 %s/s, tx p95 %s/s, CPU correlation %.2f",
+				units.Memory(ev.NetworkRX.P95), units.Memory(ev.NetworkTX.P95), ev.NetworkCPUCorrelation)))
+			b.WriteString("\n")
+		}
 	}
 
 	return b.String()
@@ -387,11 +446,15 @@ func renderConfirmationPrompt(m *Model) string {
 			c := &m.recommendation.Containers[i]
 			b.WriteString(fmt.Sprintf("  %s: cpu %s→%s  mem %s→%s\n",
 				c.Name,
-				fmtCPU(c.Current.CPURequest), fmtCPU(c.Recommended.CPURequest),
-				fmtMem(c.Current.MemoryRequest), fmtMem(c.Recommended.MemoryRequest)))
+				units.Millicores(c.Current.CPURequest), units.Millicores(c.Recommended.CPURequest),
+				units.MemoryMi(c.Current.MemoryRequest), units.MemoryMi(c.Recommended.MemoryRequest)))
 		}
 	}
 
+	if m.preview != nil {
+		b.WriteString(renderApplyPreview(m.preview))
+	}
+
 	b.WriteString(warnStyle.Render("This will trigger a rolling restart."))
 	b.WriteString("\n")
 	b.WriteString(m.confirmInput.View())
@@ -401,6 +464,58 @@ func renderConfirmationPrompt(m *Model) string {
 	return b.String()
 }
 
+// maxPreviewChanges caps the dry-run diff lines in the confirmation prompt.
+const maxPreviewChanges = 15
+
+func renderApplyPreview(p *ApplyPreview) string {
+	var b strings.Builder
+
+	if p.Error != nil {
+		b.WriteString(warnStyle.Render(fmt.Sprintf("Server dry-run failed: %v", p.Error)))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(labelStyle.Render("Server dry-run diff:"))
+	b.WriteString("\n")
+	if p.ConflictManager != "" {
+		b.WriteString(warnStyle.Render(fmt.Sprintf("  ! takes ownership of fields from field manager %s", p.ConflictManager)))
+		b.WriteString("\n")
+	}
+	if len(p.Changes) == 0 {
+		b.WriteString(dimStyle.Render("  no changes — the live object already matches"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	for i, c := range p.Changes {
+		if i == maxPreviewChanges {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  ... %d more", len(p.Changes)-i)))
+			b.WriteString("\n")
+			break
+		}
+		line := fmt.Sprintf("  %s: %s → %s", c.Path, previewValue(c.Before), previewValue(c.After))
+		if c.Mutated {
+			b.WriteString(warnStyle.Render(line + "  (admission)"))
+		} else {
+			b.WriteString(valueStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	if n := p.MutatedCount(); n > 0 {
+		b.WriteString(warnStyle.Render(fmt.Sprintf("  %d value(s) set by defaulting or a mutating webhook, not by kubenow", n)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func previewValue(v string) string {
+	if v == "" {
+		return "<none>"
+	}
+	return v
+}
+
 func renderApplyResult(result *ApplyResult) string {
 	var b strings.Builder
 
@@ -453,6 +568,15 @@ func renderApplyResult(result *ApplyResult) string {
 				b.WriteString("\n")
 			}
 		}
+
+		if result.Annotated > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  Grafana: %d annotation(s) created", result.Annotated)))
+			b.WriteString("\n")
+		}
+		if result.AnnotationError != nil {
+			b.WriteString(warnStyle.Render(fmt.Sprintf("  Grafana annotation failed: %v", result.AnnotationError)))
+			b.WriteString("\n")
+		}
 	}
 
 	return b.String()
@@ -485,6 +609,21 @@ func renderSafetyRating(r SafetyRating) string {
 	}
 }
 
+// renderValidity shows when the recommendation expires and the usage trend
+// that set its window.
+func renderValidity(rec *AlignmentRecommendation, now time.Time) string {
+	when := rec.ValidUntil.Local().Format("2006-01-02 15:04")
+	trend := "trend unknown (short latch)"
+	if rec.Trend != nil {
+		trend = fmt.Sprintf("usage trend CPU %s/day, memory %s/day",
+			fmtGrowth(rec.Trend.CPUGrowthPerDay), fmtGrowth(rec.Trend.MemoryGrowthPerDay))
+	}
+	if rec.Expired(now) {
+		return warnStyle.Render(fmt.Sprintf("%s EXPIRED — re-latch before applying", when))
+	}
+	return valueStyle.Render(when) + dimStyle.Render("  "+trend)
+}
+
 func renderConfidence(c Confidence) string {
 	switch c {
 	case ConfidenceHigh:
@@ -508,24 +647,6 @@ func renderDelta(pct float64) string {
 	return dimStyle.Render(s)
 }
 
-// fmtCPU formats CPU cores as millicores (e.g., 0.07 → "70m").
-func fmtCPU(cores float64) string {
-	m := cores * 1000
-	if m < 1 {
-		return "0m"
-	}
-	return fmt.Sprintf("%.0fm", m)
-}
-
-// fmtMem formats bytes as Mi (e.g., 178257920 → "170Mi").
-func fmtMem(bytes float64) string {
-	mi := bytes / (1024 * 1024)
-	if mi < 1 {
-		return "0Mi"
-	}
-	return fmt.Sprintf("%.0fMi", mi)
-}
-
 // fmtDelta formats a percentage delta with sign.
 func fmtDelta(pct float64) string {
 	if pct > 0 {
@@ -599,7 +720,8 @@ func renderExposureServices(b *strings.Builder, services []exposure.ServiceExpos
 			continue
 		}
 		for i := range svc.Ingresses {
-			b.WriteString(okStyle.Render(fmt.Sprintf("    ← ingress: %s", formatIngressRoute(&svc.Ingresses[i]))))
+			ing := &svc.Ingresses[i]
+			b.WriteString(okStyle.Render(fmt.Sprintf("    ← %s: %s", ingressRouteLabel(ing), formatIngressRoute(ing))))
 			b.WriteString("\n")
 		}
 	}
@@ -631,11 +753,15 @@ func renderExposureNetPols(b *strings.Builder, services []exposure.ServiceExposu
 	}
 }
 
-// renderTrafficMap renders the dedicated Linkerd traffic map screen.
+// renderTrafficMap renders the dedicated mesh traffic map screen.
 func renderTrafficMap(tm *exposure.TrafficMap) string {
 	var b strings.Builder
 
-	b.WriteString(headerStyle.Render(fmt.Sprintf("--- Traffic Map (Linkerd, %s window) ---", tm.Window)))
+	mesh := string(tm.Mesh)
+	if mesh == "" {
+		mesh = "mesh"
+	}
+	b.WriteString(headerStyle.Render(fmt.Sprintf("--- Traffic Map (%s, %s window) ---", mesh, tm.Window)))
 	b.WriteString("\n\n")
 
 	// Inbound section
@@ -673,7 +799,7 @@ func renderTrafficMap(tm *exposure.TrafficMap) string {
 
 	// Footer
 	b.WriteString("\n")
-	b.WriteString(dimStyle.Render("Data from Linkerd proxy metrics via Prometheus (window is independent of latch duration)"))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("Data from %s proxy metrics via Prometheus (window is independent of latch duration)", mesh)))
 
 	return b.String()
 }
@@ -767,7 +893,20 @@ func formatIngressRoute(ing *exposure.IngressRoute) string {
 	if ing.ClassName != "" {
 		cls = fmt.Sprintf(" (%s)", ing.ClassName)
 	}
-	return fmt.Sprintf("%s%s%s", hosts, tls, cls)
+	via := ""
+	if len(ing.Gateways) > 0 {
+		via = " via " + strings.Join(ing.Gateways, ", ")
+	}
+	return fmt.Sprintf("%s%s%s%s", hosts, tls, cls, via)
+}
+
+// ingressRouteLabel names the route type: "ingress" for Ingress rules,
+// "httproute/NAME" etc. for Gateway API routes.
+func ingressRouteLabel(ing *exposure.IngressRoute) string {
+	if ing.Kind == "" || ing.Kind == exposure.KindIngress {
+		return "ingress"
+	}
+	return strings.ToLower(ing.Kind) + "/" + ing.Name
 }
 
 func formatServicePorts(ports []exposure.PortMapping) string {
@@ -50,17 +50,6 @@ func TestRenderRecommendation_WithContainers(t *testing.T) {
 	assert.Contains(t, output, "SAFE")
 }
 
-func TestFmtCPU(t *testing.T) {
-	assert.Equal(t, "0m", fmtCPU(0))
-	assert.Equal(t, "100m", fmtCPU(0.1))
-	assert.Equal(t, "1000m", fmtCPU(1.0))
-}
-
-func TestFmtMem(t *testing.T) {
-	assert.Equal(t, "0Mi", fmtMem(0))
-	assert.Equal(t, "100Mi", fmtMem(100*1024*1024))
-}
-
 func TestFmtDelta(t *testing.T) {
 	assert.Equal(t, "+50%", fmtDelta(50))
 	assert.Equal(t, "-20%", fmtDelta(-20))
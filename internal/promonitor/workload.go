@@ -45,7 +45,7 @@ func ParseWorkloadRef(ref string) (*WorkloadRef, error) {
 		return nil, fmt.Errorf("invalid workload ref %q: expected <kind>/<name> (e.g., deployment/payment-api)", ref)
 	}
 
-	kind, err := normalizeKind(parts[0])
+	kind, err := NormalizeKind(parts[0])
 	if err != nil {
 		return nil, err
 	}
@@ -56,8 +56,9 @@ func ParseWorkloadRef(ref string) (*WorkloadRef, error) {
 	}, nil
 }
 
-// normalizeKind maps user input to canonical Kubernetes kind.
-func normalizeKind(input string) (string, error) {
+// NormalizeKind maps user input, including the short and plural forms
+// (deploy, sts, ds, po), to the canonical Kubernetes kind.
+func NormalizeKind(input string) (string, error) {
 	switch strings.ToLower(input) {
 	case "deployment", "deploy", "deployments":
 		return KindDeployment, nil
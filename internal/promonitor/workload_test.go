@@ -94,7 +94,7 @@ func TestNormalizeKind(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			got, err := normalizeKind(tt.input)
+			got, err := NormalizeKind(tt.input)
 			if tt.err {
 				assert.Error(t, err)
 			} else {
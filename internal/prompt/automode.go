@@ -0,0 +1,25 @@
+package prompt
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+// ModeAuto selects the prompt mode from deterministic triage results.
+const ModeAuto = "auto"
+
+// SelectMode picks the prompt mode for --mode auto. Compliance and security
+// requests keep their prompt; otherwise fatal problems select the incident
+// prompt and everything else the default prompt. Returns the mode and a
+// short reason.
+func SelectMode(triage snapshot.TriageSummary, requested string) (mode, reason string) {
+	switch {
+	case requested == "compliance" || requested == "security":
+		return requested, requested + " analysis requested"
+	case triage.Fatal > 0:
+		return "incident", fmt.Sprintf("%d fatal problem(s) detected", triage.Fatal)
+	default:
+		return "default", fmt.Sprintf("no fatal problems (%d critical, %d warning)", triage.Critical, triage.Warning)
+	}
+}
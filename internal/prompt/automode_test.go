@@ -0,0 +1,32 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+func TestSelectMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		triage    snapshot.TriageSummary
+		requested string
+		want      string
+	}{
+		{"fatal selects incident", snapshot.TriageSummary{Fatal: 2, Warning: 1}, "default", "incident"},
+		{"no fatal selects default", snapshot.TriageSummary{Critical: 3}, "default", "default"},
+		{"empty selects default", snapshot.TriageSummary{}, "default", "default"},
+		{"compliance wins over fatal", snapshot.TriageSummary{Fatal: 1}, "compliance", "compliance"},
+		{"security wins over fatal", snapshot.TriageSummary{Fatal: 1}, "security", "security"},
+		{"pod request is re-selected", snapshot.TriageSummary{Fatal: 1}, "pod", "incident"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, reason := SelectMode(tt.triage, tt.requested)
+			assert.Equal(t, tt.want, mode)
+			assert.NotEmpty(t, reason)
+		})
+	}
+}
@@ -0,0 +1,430 @@
+// This file fits snapshots into the model's context window.
+
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+const (
+	// DefaultContextWindow is assumed for models not in contextWindows.
+	DefaultContextWindow = 8192
+	// maxResponseReserve caps the tokens kept free for the model's answer.
+	maxResponseReserve = 4096
+)
+
+// contextWindows lists context sizes by model name prefix; more specific
+// prefixes come first.
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"claude", 200000},
+	{"gemini", 1048576},
+	{"llama3.1", 131072},
+	{"llama3.2", 131072},
+	{"llama3.3", 131072},
+	{"llama3", 8192},
+	{"llama2", 4096},
+	{"mixtral", 32768},
+	{"mistral", 32768},
+	{"qwen", 32768},
+	{"deepseek", 65536},
+	{"gemma3", 131072},
+	{"gemma", 8192},
+	{"phi4", 16384},
+	{"phi3", 4096},
+}
+
+// errorMarkers flag log lines kept when logs are trimmed.
+var errorMarkers = []string{
+	"error", "fatal", "panic", "exception", "fail", "traceback", "oom", "killed", "refused", "denied", "timeout", "timed out",
+}
+
+// Truncation records what FitPrompt removed from a snapshot to fit the
+// prompt budget.
+type Truncation struct {
+	BudgetTokens    int      `json:"budgetTokens"`
+	OriginalTokens  int      `json:"originalTokens"`
+	FinalTokens     int      `json:"finalTokens"`
+	NodesDropped    int      `json:"nodesDropped,omitempty"` // nodes with healthy conditions
+	LogLinesDropped int      `json:"logLinesDropped,omitempty"`
+	EventsDropped   int      `json:"eventsDropped,omitempty"`
+	ContextDropped  int      `json:"contextDropped,omitempty"` // rollout and workload scaling entries
+	PodsDropped     []string `json:"podsDropped,omitempty"`    // namespace/name, least severe first
+	OverBudget      bool     `json:"overBudget,omitempty"`     // still too large after trimming
+}
+
+// Summary describes the truncation in one line.
+func (t *Truncation) Summary() string {
+	var parts []string
+	if t.LogLinesDropped > 0 {
+		parts = append(parts, fmt.Sprintf("%d log lines", t.LogLinesDropped))
+	}
+	if t.EventsDropped > 0 {
+		parts = append(parts, fmt.Sprintf("%d events", t.EventsDropped))
+	}
+	if t.NodesDropped > 0 {
+		parts = append(parts, fmt.Sprintf("%d healthy nodes", t.NodesDropped))
+	}
+	if t.ContextDropped > 0 {
+		parts = append(parts, fmt.Sprintf("%d rollout/scaling entries", t.ContextDropped))
+	}
+	if len(t.PodsDropped) > 0 {
+		parts = append(parts, fmt.Sprintf("%d pods", len(t.PodsDropped)))
+	}
+	dropped := "nothing"
+	if len(parts) > 0 {
+		dropped = strings.Join(parts, ", ")
+	}
+	return fmt.Sprintf("dropped %s (~%d -> ~%d tokens, budget %d)", dropped, t.OriginalTokens, t.FinalTokens, t.BudgetTokens)
+}
+
+// ContextWindow returns the context size of a model, matched by name
+// prefix after any provider path ("openai/gpt-4o", "llama3.1:70b").
+func ContextWindow(model string) int {
+	m := strings.ToLower(model)
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+	for _, w := range contextWindows {
+		if strings.HasPrefix(m, w.prefix) {
+			return w.tokens
+		}
+	}
+	return DefaultContextWindow
+}
+
+// PromptBudget returns the prompt token budget: maxTokens when set,
+// otherwise the model's context window minus room for the response.
+//
+//revive:disable-next-line:exported
+func PromptBudget(model string, maxTokens int) int {
+	if maxTokens > 0 {
+		return maxTokens
+	}
+	window := ContextWindow(model)
+	return window - min(window/4, maxResponseReserve)
+}
+
+// EstimateTokens approximates the token count of s for BPE tokenizers such
+// as cl100k and o200k: a word costs a token per six letters, a number a
+// token per three digits, and every other character a token of its own. A
+// single space merges into the following word. The estimate errs high on
+// JSON, which keeps budgets safe.
+func EstimateTokens(s string) int {
+	tokens, kind, n := 0, runNone, 0
+	for _, r := range s {
+		k := classifyRune(r)
+		if k == kind {
+			n++
+			continue
+		}
+		tokens += runTokens(kind, n)
+		kind, n = k, 1
+	}
+	return tokens + runTokens(kind, n)
+}
+
+// Character classes for EstimateTokens.
+const (
+	runNone = iota
+	runLetter
+	runDigit
+	runSpace
+	runOther
+)
+
+func classifyRune(r rune) int {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return runLetter
+	case r >= '0' && r <= '9':
+		return runDigit
+	case r == ' ' || r == '\t' || r == '\n':
+		return runSpace
+	}
+	return runOther
+}
+
+func runTokens(kind, n int) int {
+	switch kind {
+	case runLetter:
+		return (n + 5) / 6
+	case runDigit:
+		return (n + 2) / 3
+	case runSpace:
+		if n > 1 {
+			return 1
+		}
+	case runOther:
+		return n
+	}
+	return 0
+}
+
+// FitPrompt renders the prompt for a snapshot like LoadPrompt. When it
+// exceeds budget tokens, a copy of the snapshot is trimmed until it fits,
+// least useful data first: healthy nodes, log lines (error lines are kept
+// longest), events (warnings are kept longest), rollout and scaling context,
+// and finally whole pods, least severe first. The returned Truncation is nil
+// when nothing was removed; budget <= 0 disables trimming.
+func FitPrompt(snap *snapshot.Snapshot, budget int, mode, problemHint string, enh PromptEnhancements) (string, *Truncation, error) {
+	render := func(s *snapshot.Snapshot) (string, int, error) {
+		snapJSON, err := json.Marshal(s)
+		if err != nil {
+			return "", 0, fmt.Errorf("snapshot marshal error: %w", err)
+		}
+		out, err := LoadPrompt(mode, string(snapJSON), problemHint, enh)
+		if err != nil {
+			return "", 0, err
+		}
+		return out, EstimateTokens(out), nil
+	}
+
+	out, tokens, err := render(snap)
+	if err != nil || budget <= 0 || tokens <= budget {
+		return out, nil, err
+	}
+
+	work, err := cloneSnapshot(snap)
+	if err != nil {
+		return "", nil, err
+	}
+	tr := &trimmer{snap: work, t: &Truncation{BudgetTokens: budget, OriginalTokens: tokens}}
+	for _, stage := range []func(excess int) bool{tr.dropHealthyNodes, tr.trimLogs, tr.trimEvents, tr.dropContext, tr.dropPods} {
+		for tokens > budget && stage(tokens-budget) {
+			if out, tokens, err = render(work); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	tr.t.FinalTokens = tokens
+	tr.t.OverBudget = tokens > budget
+	return out, tr.t, nil
+}
+
+func cloneSnapshot(snap *snapshot.Snapshot) (*snapshot.Snapshot, error) {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot marshal error: %w", err)
+	}
+	var clone snapshot.Snapshot
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("snapshot copy error: %w", err)
+	}
+	return &clone, nil
+}
+
+// trimmer removes snapshot content step by step. Each step reports whether
+// it removed anything, so FitPrompt can re-measure and stop when it fits.
+type trimmer struct {
+	snap *snapshot.Snapshot
+	t    *Truncation
+
+	// logLines holds the remaining lines and dropped counts per pod index,
+	// set on the first log trimming step.
+	logLines   [][]string
+	logDropped []int
+}
+
+// dropHealthyNodes drops nodes that are Ready, without pressure, and had no
+// recent Warning events.
+func (tr *trimmer) dropHealthyNodes(excess int) bool {
+	var dropped []snapshot.NodeSnapshot
+	tr.snap.NodeConditions, dropped = dropItems(tr.snap.NodeConditions, excess, func(n *snapshot.NodeSnapshot) bool {
+		return !snapshot.NodeUnhealthy(n) && len(n.RecentEvents) == 0
+	})
+	tr.t.NodesDropped += len(dropped)
+	return len(dropped) > 0
+}
+
+// trimLogs halves the longest pod log, keeping error lines over other lines
+// and recent lines over older ones. Each trimmed log starts with a marker
+// counting the dropped lines.
+func (tr *trimmer) trimLogs(_ int) bool {
+	pods := tr.snap.ProblemPods
+	if tr.logLines == nil {
+		tr.logLines = make([][]string, len(pods))
+		tr.logDropped = make([]int, len(pods))
+		for i := range pods {
+			// Placeholders such as "<unable to fetch logs>" are not logs
+			if logs := strings.TrimRight(pods[i].Logs, "\n"); logs != "" && !strings.HasPrefix(logs, "<") {
+				tr.logLines[i] = strings.Split(logs, "\n")
+			}
+		}
+	}
+
+	longest := 0
+	for _, lines := range tr.logLines {
+		longest = max(longest, len(lines))
+	}
+	if longest == 0 {
+		return false
+	}
+	limit := longest / 2
+	for i, lines := range tr.logLines {
+		if len(lines) <= limit {
+			continue
+		}
+		kept := keepLogLines(lines, limit)
+		tr.logDropped[i] += len(lines) - len(kept)
+		tr.t.LogLinesDropped += len(lines) - len(kept)
+		tr.logLines[i] = kept
+		pods[i].Logs = fmt.Sprintf("<%d log lines truncated by kubenow, error lines kept>\n%s",
+			tr.logDropped[i], strings.Join(kept, "\n"))
+	}
+	return true
+}
+
+// keepLogLines returns at most limit lines in their original order,
+// choosing the most recent error lines first and filling up with the most
+// recent remaining lines.
+func keepLogLines(lines []string, limit int) []string {
+	keep := make([]bool, len(lines))
+	n := 0
+	for _, errorsOnly := range []bool{true, false} {
+		for i := len(lines) - 1; i >= 0 && n < limit; i-- {
+			if keep[i] || (errorsOnly && !isErrorLine(lines[i])) {
+				continue
+			}
+			keep[i] = true
+			n++
+		}
+	}
+	kept := make([]string, 0, n)
+	for i, line := range lines {
+		if keep[i] {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+func isErrorLine(line string) bool {
+	line = strings.ToLower(line)
+	for _, m := range errorMarkers {
+		if strings.Contains(line, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimEvents drops non-warning events first, then halves the longest event
+// list, keeping the most recent events.
+func (tr *trimmer) trimEvents(_ int) bool {
+	pods := tr.snap.ProblemPods
+	dropped := 0
+	for i := range pods {
+		warnings := pods[i].Events[:0]
+		for _, e := range pods[i].Events {
+			if e.Type == "Warning" || e.Type == "" {
+				warnings = append(warnings, e)
+			}
+		}
+		dropped += len(pods[i].Events) - len(warnings)
+		pods[i].Events = warnings
+	}
+	if dropped > 0 {
+		tr.t.EventsDropped += dropped
+		return true
+	}
+
+	longest := 0
+	for i := range pods {
+		longest = max(longest, len(pods[i].Events))
+	}
+	if longest == 0 {
+		return false
+	}
+	limit := longest / 2
+	for i := range pods {
+		events := pods[i].Events
+		if len(events) <= limit {
+			continue
+		}
+		sort.SliceStable(events, func(a, b int) bool { return events[a].LastTime.After(events[b].LastTime) })
+		tr.t.EventsDropped += len(events) - limit
+		pods[i].Events = events[:limit]
+	}
+	return true
+}
+
+// dropContext drops completed rollouts, then workload scaling entries, then
+// the remaining rollouts.
+func (tr *trimmer) dropContext(excess int) bool {
+	var rollouts []snapshot.RolloutSnapshot
+	tr.snap.Rollouts, rollouts = dropItems(tr.snap.Rollouts, excess, func(r *snapshot.RolloutSnapshot) bool {
+		return r.Status == snapshot.RolloutComplete
+	})
+	if len(rollouts) == 0 {
+		var scaling []snapshot.WorkloadScalingSnapshot
+		tr.snap.WorkloadScaling, scaling = dropItems(tr.snap.WorkloadScaling, excess, func(*snapshot.WorkloadScalingSnapshot) bool { return true })
+		tr.t.ContextDropped += len(scaling)
+		if len(scaling) > 0 {
+			return true
+		}
+		tr.snap.Rollouts, rollouts = dropItems(tr.snap.Rollouts, excess, func(*snapshot.RolloutSnapshot) bool { return true })
+	}
+	tr.t.ContextDropped += len(rollouts)
+	return len(rollouts) > 0
+}
+
+// dropPods drops whole pods, warnings before critical before fatal ones.
+func (tr *trimmer) dropPods(excess int) bool {
+	for rank := 0; rank <= 2; rank++ {
+		var dropped []snapshot.PodSnapshot
+		tr.snap.ProblemPods, dropped = dropItems(tr.snap.ProblemPods, excess, func(p *snapshot.PodSnapshot) bool {
+			return snapshot.PodRank(p) == rank
+		})
+		for i := range dropped {
+			tr.t.PodsDropped = append(tr.t.PodsDropped, dropped[i].Namespace+"/"+dropped[i].Name)
+		}
+		if len(dropped) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dropItems removes droppable items from the end of the list until their
+// estimated size covers excess tokens. It returns the kept and dropped items.
+func dropItems[T any](items []T, excess int, droppable func(*T) bool) (kept, dropped []T) {
+	remove := make([]bool, len(items))
+	freed := 0
+	for i := len(items) - 1; i >= 0 && freed < excess; i-- {
+		if !droppable(&items[i]) {
+			continue
+		}
+		data, err := json.Marshal(items[i])
+		if err != nil {
+			continue
+		}
+		remove[i] = true
+		freed += EstimateTokens(string(data)) + 1
+	}
+	kept = items[:0:0]
+	for i := range items {
+		if remove[i] {
+			dropped = append(dropped, items[i])
+		} else {
+			kept = append(kept, items[i])
+		}
+	}
+	return kept, dropped
+}
@@ -0,0 +1,139 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"hello world", 2},
+		{"lastStateReason", 3},
+		{"1234567", 3},
+		{`{"a":1}`, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, EstimateTokens(tt.in))
+		})
+	}
+}
+
+func TestPromptBudget(t *testing.T) {
+	assert.Equal(t, 5000, PromptBudget("gpt-4o", 5000))
+	assert.Equal(t, 128000-4096, PromptBudget("openai/gpt-4o-mini", 0))
+	assert.Equal(t, 32768-4096, PromptBudget("mixtral:8x22b", 0))
+	assert.Equal(t, 8192-2048, PromptBudget("llama3:8b", 0))
+	assert.Equal(t, 131072-4096, PromptBudget("llama3.1:70b", 0))
+	assert.Equal(t, DefaultContextWindow-2048, PromptBudget("my-finetune", 0))
+}
+
+func budgetSnapshot() *snapshot.Snapshot {
+	var logs []string
+	for i := 0; i < 200; i++ {
+		logs = append(logs, fmt.Sprintf("2026-01-01T00:00:%02d INFO handled request id=%d path=/api/v1/items", i%60, i))
+	}
+	logs[150] = "2026-01-01T00:02:30 ERROR connection refused to db:5432"
+
+	now := time.Now()
+	return &snapshot.Snapshot{
+		ProblemPods: []snapshot.PodSnapshot{
+			{
+				Namespace: "prod", Name: "api-1", Phase: "Running",
+				Containers: []snapshot.ContainerSnapshot{{Name: "api", StateReason: "CrashLoopBackOff"}},
+				Events: []snapshot.EventSnapshot{
+					{Type: "Normal", Reason: "Pulled", Message: "pulled image", LastTime: now},
+					{Type: "Warning", Reason: "BackOff", Message: "back-off restarting", LastTime: now},
+				},
+				Logs: strings.Join(logs, "\n"),
+			},
+			{Namespace: "prod", Name: "worker-1", Phase: "Pending", Logs: strings.Repeat("waiting for queue\n", 100)},
+		},
+		NodeConditions: []snapshot.NodeSnapshot{
+			{Name: "node-1", Conditions: []snapshot.NodeConditionSnapshot{{Type: "Ready", Status: "True"}}},
+			{Name: "node-2", Conditions: []snapshot.NodeConditionSnapshot{{Type: "Ready", Status: "False"}}},
+		},
+	}
+}
+
+func TestFitPrompt_UnderBudget(t *testing.T) {
+	snap := budgetSnapshot()
+	out, tr, err := FitPrompt(snap, 1_000_000, "default", "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Nil(t, tr)
+	assert.Contains(t, out, "handled request id=0 ")
+
+	_, tr, err = FitPrompt(snap, 0, "default", "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Nil(t, tr)
+}
+
+func TestFitPrompt_TrimsLogsKeepingErrors(t *testing.T) {
+	snap := budgetSnapshot()
+	full, _, err := FitPrompt(snap, 0, "default", "", PromptEnhancements{})
+	require.NoError(t, err)
+	budget := EstimateTokens(full) / 2
+
+	out, tr, err := FitPrompt(snap, budget, "default", "", PromptEnhancements{})
+	require.NoError(t, err)
+	require.NotNil(t, tr)
+	assert.LessOrEqual(t, EstimateTokens(out), budget)
+	assert.LessOrEqual(t, tr.FinalTokens, budget)
+	assert.False(t, tr.OverBudget)
+	assert.Equal(t, 1, tr.NodesDropped)
+	assert.Positive(t, tr.LogLinesDropped)
+	assert.Empty(t, tr.PodsDropped)
+	assert.Contains(t, out, "ERROR connection refused")
+	assert.Contains(t, out, "log lines truncated by kubenow")
+	assert.Contains(t, out, "node-2")
+	assert.NotContains(t, out, "node-1")
+
+	// The caller's snapshot is untouched
+	assert.Len(t, snap.NodeConditions, 2)
+	assert.Len(t, strings.Split(snap.ProblemPods[0].Logs, "\n"), 200)
+}
+
+func TestFitPrompt_DropsLeastSeverePods(t *testing.T) {
+	// What remains once logs, events, and the warning-level pod are gone
+	fatalOnly := budgetSnapshot()
+	fatalOnly.ProblemPods = fatalOnly.ProblemPods[:1]
+	fatalOnly.ProblemPods[0].Events = nil
+	fatalOnly.ProblemPods[0].Logs = "<200 log lines truncated by kubenow, error lines kept>\n"
+	fatalOnly.NodeConditions = fatalOnly.NodeConditions[1:]
+	remaining, _, err := FitPrompt(fatalOnly, 0, "default", "", PromptEnhancements{})
+	require.NoError(t, err)
+
+	_, tr, err := FitPrompt(budgetSnapshot(), EstimateTokens(remaining)+5, "default", "", PromptEnhancements{})
+	require.NoError(t, err)
+	require.NotNil(t, tr)
+	assert.Equal(t, []string{"prod/worker-1"}, tr.PodsDropped)
+	assert.Equal(t, 2, tr.EventsDropped)
+	assert.False(t, tr.OverBudget)
+	assert.Contains(t, tr.Summary(), "1 pods")
+}
+
+func TestFitPrompt_OverBudget(t *testing.T) {
+	_, tr, err := FitPrompt(budgetSnapshot(), 10, "default", "", PromptEnhancements{})
+	require.NoError(t, err)
+	require.NotNil(t, tr)
+	assert.True(t, tr.OverBudget)
+	assert.Len(t, tr.PodsDropped, 2)
+}
+
+func TestKeepLogLines(t *testing.T) {
+	lines := []string{"a", "panic: nil map", "b", "c", "level=error msg=x", "d"}
+	assert.Equal(t, []string{"panic: nil map", "level=error msg=x", "d"}, keepLogLines(lines, 3))
+	assert.Equal(t, []string{"level=error msg=x"}, keepLogLines(lines, 1))
+	assert.Empty(t, keepLogLines(lines, 0))
+}
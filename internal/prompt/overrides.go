@@ -0,0 +1,106 @@
+// This file loads user prompt templates that replace the embedded ones.
+
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Placeholders a template can use. The snapshot placeholder is required.
+// Without {{ENHANCEMENTS}}, enhancement and snapshot context sections are
+// inserted before the BEGIN_SNAPSHOT marker (or appended); without {{HINT}},
+// the problem hint is appended.
+const (
+	PlaceholderSnapshot     = "{{SNAPSHOT}}" // {{SNAPSHOT_JSON}} is accepted too
+	PlaceholderHint         = "{{HINT}}"
+	PlaceholderEnhancements = "{{ENHANCEMENTS}}"
+)
+
+// Modes lists the prompt modes, each with an embedded template.
+var Modes = []string{"default", "pod", "incident", "teamlead", "compliance", "chaos", "security", "capacity"}
+
+// templateExt is the file extension of templates in a --prompt-dir.
+const templateExt = ".tmpl"
+
+// anyMode keys the --prompt-file template, which applies to every mode.
+const anyMode = "*"
+
+// Templates overrides embedded templates by mode. A nil Templates uses the
+// embedded ones.
+type Templates map[string]string
+
+// LoadTemplates reads <mode>.tmpl files from dir and the template in file,
+// which takes precedence for every mode. Either may be empty. It fails when
+// dir holds no template for any mode or a template has no snapshot
+// placeholder.
+func LoadTemplates(dir, file string) (Templates, error) {
+	t := Templates{}
+	if dir != "" {
+		for _, mode := range Modes {
+			path := filepath.Join(dir, mode+templateExt)
+			data, err := os.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read prompt template: %w", err)
+			}
+			if err := validateTemplate(path, string(data)); err != nil {
+				return nil, err
+			}
+			t[mode] = string(data)
+		}
+		if len(t) == 0 {
+			return nil, fmt.Errorf("--prompt-dir %s has no templates (expected <mode>%s for a mode of: %s)",
+				dir, templateExt, strings.Join(Modes, ", "))
+		}
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		if err := validateTemplate(file, string(data)); err != nil {
+			return nil, err
+		}
+		t[anyMode] = string(data)
+	}
+	if len(t) == 0 {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func validateTemplate(path, tmpl string) error {
+	if !strings.Contains(tmpl, PlaceholderSnapshot) && !strings.Contains(tmpl, "{{SNAPSHOT_JSON}}") {
+		return fmt.Errorf("prompt template %s has no %s placeholder", path, PlaceholderSnapshot)
+	}
+	return nil
+}
+
+// Source names where the template of a mode comes from: a user file, or
+// "embedded".
+func (t Templates) Source(mode string) string {
+	switch {
+	case t[anyMode] != "":
+		return "--prompt-file"
+	case t[mode] != "":
+		return "--prompt-dir " + mode + templateExt
+	default:
+		return "embedded"
+	}
+}
+
+// lookup returns the user template for a mode, if any.
+func (t Templates) lookup(mode string) (string, bool) {
+	if tmpl, ok := t[anyMode]; ok {
+		return tmpl, true
+	}
+	tmpl, ok := t[mode]
+	return tmpl, ok
+}
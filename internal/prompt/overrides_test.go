@@ -0,0 +1,78 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplate(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "incident.tmpl"), "INCIDENT {{SNAPSHOT}}")
+	writeTemplate(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	tmpls, err := LoadTemplates(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, Templates{"incident": "INCIDENT {{SNAPSHOT}}"}, tmpls)
+	assert.Equal(t, "--prompt-dir incident.tmpl", tmpls.Source("incident"))
+	assert.Equal(t, "embedded", tmpls.Source("pod"))
+
+	out, err := LoadPrompt("incident", `{"problemPods":[]}`, "", PromptEnhancements{Templates: tmpls})
+	require.NoError(t, err)
+	assert.Equal(t, `INCIDENT {"problemPods":[]}`, out)
+
+	out, err = LoadPrompt("pod", "{}", "", PromptEnhancements{Templates: tmpls})
+	require.NoError(t, err)
+	assert.Contains(t, out, "pod triage engine", "modes without a file keep the embedded template")
+
+	file := filepath.Join(t.TempDir(), "all.tmpl")
+	writeTemplate(t, file, "ANY {{SNAPSHOT_JSON}}")
+	tmpls, err = LoadTemplates(dir, file)
+	require.NoError(t, err)
+	assert.Equal(t, "--prompt-file", tmpls.Source("incident"))
+	out, err = LoadPrompt("incident", "{}", "", PromptEnhancements{Templates: tmpls})
+	require.NoError(t, err)
+	assert.Equal(t, "ANY {}", out, "--prompt-file wins over --prompt-dir")
+
+	tmpls, err = LoadTemplates("", "")
+	require.NoError(t, err)
+	assert.Nil(t, tmpls)
+}
+
+func TestLoadTemplates_Errors(t *testing.T) {
+	_, err := LoadTemplates(t.TempDir(), "")
+	assert.ErrorContains(t, err, "has no templates")
+
+	file := filepath.Join(t.TempDir(), "bad.tmpl")
+	writeTemplate(t, file, "no placeholder here")
+	_, err = LoadTemplates("", file)
+	assert.ErrorContains(t, err, "{{SNAPSHOT}}")
+
+	_, err = LoadTemplates("", filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.Error(t, err)
+}
+
+func TestLoadPrompt_Placeholders(t *testing.T) {
+	tmpls := Templates{anyMode: "HINT[{{HINT}}]\nEXTRA[{{ENHANCEMENTS}}]\nBEGIN_SNAPSHOT\n{{SNAPSHOT}}\n"}
+	snap := `{"workloadScaling":[],"logs":"{{HINT}}"}`
+
+	out, err := LoadPrompt("default", snap, "memory leak", PromptEnhancements{Remediation: true, Templates: tmpls})
+	require.NoError(t, err)
+	assert.Contains(t, out, "HINT[PROBLEM HINT: The user suspects this may be related to: memory leak")
+	assert.Contains(t, out, "EXTRA[\nENHANCED OUTPUT REQUIREMENTS:")
+	assert.Contains(t, out, "WORKLOAD SCALING CONTEXT:")
+	assert.Contains(t, out, `"logs":"{{HINT}}"`, "placeholders inside the snapshot stay literal")
+	assert.NotContains(t, out[len(out)-len(snap)-1:], "PROBLEM HINT", "hint is not appended again")
+
+	out, err = LoadPrompt("default", "{}", "", PromptEnhancements{Templates: tmpls})
+	require.NoError(t, err)
+	assert.Equal(t, "HINT[]\nEXTRA[]\nBEGIN_SNAPSHOT\n{}\n", out)
+}
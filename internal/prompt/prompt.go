@@ -13,10 +13,14 @@ type PromptEnhancements struct {
 	Technical   bool // Add technical depth (stack traces, config diffs, deeper analysis)
 	Priority    bool // Add priority scoring (numerical scores, SLO impact, blast radius)
 	Remediation bool // Add detailed remediation (step-by-step fixes, rollback, prevention)
+
+	// Templates replaces embedded mode templates (--prompt-dir, --prompt-file)
+	Templates Templates
 }
 
-// LoadPrompt loads the prompt template for the requested mode.
-func LoadPrompt(mode, snapshotJSON, problemHint string, enhancements PromptEnhancements) (string, error) {
+// Template returns the template of a mode: the user template from
+// templates when there is one, the embedded one otherwise.
+func Template(mode string, templates Templates) (string, error) {
 	var tmpl string
 
 	switch mode {
@@ -32,41 +36,88 @@ func LoadPrompt(mode, snapshotJSON, problemHint string, enhancements PromptEnhan
 		tmpl = PromptCompliance
 	case "chaos":
 		tmpl = PromptChaos
+	case "security":
+		tmpl = PromptSecurity
+	case "capacity":
+		tmpl = PromptCapacity
 	default:
 		return "", fmt.Errorf("invalid mode: %s", mode)
 	}
+	if custom, ok := templates.lookup(mode); ok {
+		tmpl = custom
+	}
+	return tmpl, nil
+}
+
+// LoadPrompt loads the prompt template for the requested mode (see
+// Template) and fills in the snapshot, hint, and enhancement placeholders.
+func LoadPrompt(mode, snapshotJSON, problemHint string, enhancements PromptEnhancements) (string, error) {
+	tmpl, err := Template(mode, enhancements.Templates)
+	if err != nil {
+		return "", err
+	}
 
-	// Inject enhancements before snapshot if any are enabled
+	var sections strings.Builder
+	// Enhancement instructions, if any are enabled
 	if enhancements.Technical || enhancements.Priority || enhancements.Remediation {
-		tmpl = injectEnhancements(tmpl, enhancements)
+		sections.WriteString(buildEnhancementSection(enhancements))
 	}
 
-	out := strings.ReplaceAll(tmpl, "{{SNAPSHOT_JSON}}", snapshotJSON)
-	out = strings.ReplaceAll(out, "{{SNAPSHOT}}", snapshotJSON)
+	// Explain existing autoscaling objects so the model does not suggest duplicates
+	if strings.Contains(snapshotJSON, `"workloadScaling"`) {
+		sections.WriteString(ScalingContext)
+	}
+	if strings.Contains(snapshotJSON, `"rollouts"`) {
+		sections.WriteString(RolloutContext)
+	}
+	if strings.Contains(snapshotJSON, `"taints"`) || strings.Contains(snapshotJSON, `"allocation"`) ||
+		strings.Contains(snapshotJSON, `"recentEvents"`) {
+		sections.WriteString(NodeContext)
+	}
+	if strings.Contains(snapshotJSON, `"storage"`) {
+		sections.WriteString(StorageContext)
+	}
+	if strings.Contains(snapshotJSON, `"acknowledgedProblems"`) {
+		sections.WriteString(AcknowledgedContext)
+	}
+	if strings.Contains(snapshotJSON, `"team":`) {
+		sections.WriteString(OwnersContext)
+	}
+	switch {
+	case strings.Contains(tmpl, PlaceholderEnhancements):
+		tmpl = strings.ReplaceAll(tmpl, PlaceholderEnhancements, sections.String())
+	case sections.Len() > 0:
+		tmpl = injectBeforeSnapshot(tmpl, sections.String())
+	}
 
 	// Add problem hint if provided
+	var hintSection string
 	if problemHint != "" {
-		hintSection := fmt.Sprintf("\n\nPROBLEM HINT: The user suspects this may be related to: %s\nPlease prioritize analysis in this direction while still identifying other issues.\n", problemHint)
+		hintSection = fmt.Sprintf("\n\nPROBLEM HINT: The user suspects this may be related to: %s\nPlease prioritize analysis in this direction while still identifying other issues.\n", problemHint)
+	}
+	hintInline := strings.Contains(tmpl, PlaceholderHint)
+	if hintInline {
+		tmpl = strings.ReplaceAll(tmpl, PlaceholderHint, strings.TrimSpace(hintSection))
+	}
+
+	// The snapshot goes in last so placeholders in its logs stay literal
+	out := strings.ReplaceAll(tmpl, "{{SNAPSHOT_JSON}}", snapshotJSON)
+	out = strings.ReplaceAll(out, PlaceholderSnapshot, snapshotJSON)
+	if !hintInline {
 		out += hintSection
 	}
 
 	return out, nil
 }
 
-// injectEnhancements injects enhancement instructions into the prompt template.
-func injectEnhancements(tmpl string, enh PromptEnhancements) string {
-	// Find injection point - before BEGIN_SNAPSHOT marker
-	marker := "BEGIN_SNAPSHOT"
-	idx := strings.Index(tmpl, marker)
-
+// injectBeforeSnapshot inserts a section before the BEGIN_SNAPSHOT marker,
+// or appends it when the template has no marker.
+func injectBeforeSnapshot(tmpl, section string) string {
+	idx := strings.Index(tmpl, "BEGIN_SNAPSHOT")
 	if idx == -1 {
-		// Fallback: append at the end if marker not found
-		return tmpl + buildEnhancementSection(enh)
+		return tmpl + section
 	}
-
-	// Inject before BEGIN_SNAPSHOT
-	enhancementSection := buildEnhancementSection(enh)
-	return tmpl[:idx] + enhancementSection + tmpl[idx:]
+	return tmpl[:idx] + section + tmpl[idx:]
 }
 
 // buildEnhancementSection builds the enhancement instructions based on flags.
@@ -95,3 +146,9 @@ func buildEnhancementSection(enh PromptEnhancements) string {
 
 	return sb.String()
 }
+
+// LoadExplainPrompt fills the explain template with the text description of
+// one monitor problem: its summary, pod describe, events, and logs.
+func LoadExplainPrompt(problem string) string {
+	return strings.ReplaceAll(PromptExplain, "{{PROBLEM}}", problem)
+}
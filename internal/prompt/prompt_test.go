@@ -1,6 +1,7 @@
 package prompt
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,7 +9,7 @@ import (
 )
 
 func TestLoadPrompt_AllModes(t *testing.T) {
-	modes := []string{"default", "pod", "incident", "teamlead", "compliance", "chaos"}
+	modes := []string{"default", "pod", "incident", "teamlead", "compliance", "chaos", "security", "capacity"}
 	for _, mode := range modes {
 		t.Run(mode, func(t *testing.T) {
 			out, err := LoadPrompt(mode, "{}", "", PromptEnhancements{})
@@ -38,3 +39,74 @@ func TestLoadPrompt_UnknownMode(t *testing.T) {
 	_, err := LoadPrompt("nonexistent", "{}", "", PromptEnhancements{})
 	assert.Error(t, err)
 }
+
+func TestLoadPrompt_ScalingContext(t *testing.T) {
+	out, err := LoadPrompt("default", "{}", "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "WORKLOAD SCALING CONTEXT")
+
+	snap := `{"workloadScaling":[{"namespace":"prod","kind":"Deployment","name":"api","hpa":{"name":"api"}}]}`
+	out, err = LoadPrompt("incident", snap, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "WORKLOAD SCALING CONTEXT")
+	assert.Less(t, strings.Index(out, "WORKLOAD SCALING CONTEXT"), strings.Index(out, "BEGIN_SNAPSHOT"))
+}
+
+func TestLoadPrompt_RolloutContext(t *testing.T) {
+	out, err := LoadPrompt("incident", "{}", "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "ROLLOUT CONTEXT")
+
+	out, err = LoadPrompt("incident", `{"rollouts":[{"name":"api","status":"Stuck"}]}`, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "ROLLOUT CONTEXT")
+}
+
+func TestLoadPrompt_NodeContext(t *testing.T) {
+	out, err := LoadPrompt("incident", `{"nodeConditions":[{"name":"n1","conditions":[]}]}`, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "NODE CONTEXT")
+
+	out, err = LoadPrompt("incident", `{"nodeConditions":[{"name":"n1","taints":["dedicated=gpu:NoSchedule"]}]}`, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "NODE CONTEXT")
+}
+
+func TestLoadPrompt_StorageContext(t *testing.T) {
+	out, err := LoadPrompt("incident", "{}", "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "STORAGE CONTEXT")
+
+	out, err = LoadPrompt("incident", `{"storage":{"claims":[{"namespace":"prod","name":"data","phase":"Pending"}]}}`, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "STORAGE CONTEXT")
+}
+
+func TestLoadPrompt_AcknowledgedContext(t *testing.T) {
+	out, err := LoadPrompt("default", "{}", "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "ACKNOWLEDGED PROBLEMS")
+
+	snap := `{"problemPods":[],"acknowledgedProblems":[{"namespace":"batch","pod":"report-1","type":"CrashLoopBackOff","reason":"flaky"}]}`
+	out, err = LoadPrompt("incident", snap, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "ACKNOWLEDGED PROBLEMS")
+	assert.Less(t, strings.Index(out, "ACKNOWLEDGED PROBLEMS"), strings.Index(out, "BEGIN_SNAPSHOT"))
+}
+
+func TestLoadPrompt_OwnersContext(t *testing.T) {
+	out, err := LoadPrompt("default", `{"problemPods":[{"namespace":"web","name":"api-1"}]}`, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "TEAM OWNERSHIP")
+
+	out, err = LoadPrompt("incident", `{"problemPods":[{"namespace":"web","name":"api-1","team":"web"}]}`, "", PromptEnhancements{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "TEAM OWNERSHIP")
+}
+
+func TestLoadExplainPrompt(t *testing.T) {
+	out := LoadExplainPrompt("Problem: FATAL CrashLoopBackOff\n")
+	assert.Contains(t, out, "BEGIN_PROBLEM\nProblem: FATAL CrashLoopBackOff\n")
+	assert.NotContains(t, out, "{{PROBLEM}}")
+	assert.Contains(t, out, "FIX:")
+}
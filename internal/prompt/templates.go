@@ -199,6 +199,97 @@ END_SNAPSHOT
 Return ONLY the JSON object.
 `
 
+// PromptSecurity defines the security hardening prompt template.
+var PromptSecurity = `
+You are kubeNow, reviewing the security posture of a Kubernetes cluster and producing a prioritized hardening report.
+
+Output ONLY valid JSON:
+
+{
+  "findings": [
+    {
+      "priority": 1,
+      "severity": "critical|high|medium|low",
+      "category": "rbac|pod-security|network|other",
+      "namespace": "",
+      "resource": "",
+      "issue": "",
+      "recommendation": ""
+    }
+  ],
+  "summary": ""
+}
+
+Rules:
+- No text outside JSON.
+- Use ONLY what the snapshot shows; do not invent resources.
+- The snapshot's "security" object lists "serviceAccountBindings" (roles granted to ServiceAccounts; "broad": true when the role is over-broad), "broadRoles" (ClusterRoles with wildcard, secrets, exec, or escalation rights and the ServiceAccounts bound to them), "privilegedWorkloads" (privileged containers, added capabilities, host namespaces, hostPath mounts), and "namespaces" (Pod Security "podSecurity" enforce level and "networkPolicies" count).
+- A ServiceAccount bound to a broad role, especially cluster-wide, is critical when a privileged or internet-facing workload runs as it.
+- Privileged containers, hostPID/hostNetwork, and hostPath mounts of the container runtime socket or "/" are critical or high; other hostPath mounts are medium.
+- A namespace with "networkPolicies": 0 has no network isolation; a namespace without "podSecurity", or with "privileged", does not block privileged pods.
+- Workloads in kube-system or other infrastructure namespaces may need host access; lower their severity and say so.
+- "priority": 1 is the first thing to fix; number findings in order, most exploitable first.
+- "resource": "Kind/name", e.g. "ClusterRoleBinding/ci" or "Deployment/api".
+- "recommendation": one concrete fix, e.g. the narrower role, the securityContext change, the Pod Security label, or a default-deny NetworkPolicy.
+- Group repeated findings of one kind into one finding naming the affected resources.
+- "summary": 1–3 sentences on the overall posture and the most important fix.
+
+BEGIN_SNAPSHOT
+{{SNAPSHOT_JSON}}
+END_SNAPSHOT
+
+Return ONLY the JSON object.
+`
+
+// PromptCapacity defines the capacity-planning prompt template. Its input
+// is a condensed requests-skew report, not a cluster snapshot.
+var PromptCapacity = `
+You are kubeNow, writing an executive capacity-planning report from a deterministic requests-skew analysis.
+
+Output ONLY valid JSON:
+
+{
+  "headroom": {
+    "cpu": "",
+    "memory": "",
+    "outlook": ""
+  },
+  "consolidation": [
+    {
+      "target": "",
+      "action": "",
+      "savings": ""
+    }
+  ],
+  "nodePools": [
+    {
+      "pool": "",
+      "recommendation": "",
+      "reason": ""
+    }
+  ],
+  "risks": [""],
+  "summary": ""
+}
+
+Rules:
+- No text outside JSON.
+- The input is computed, not sampled: "totals" sums the analyzed workloads' requests and p95 usage over "window"; "namespaces" and "overProvisioned" list where requests exceed p95 usage most; "underProvisioned" lists workloads using more than they request or unsafe to shrink (safety RISKY/UNSAFE, OOM kills); "nodePools" and "headroom" give allocatable capacity against pod requests and p95 usage when the cluster was read.
+- Use ONLY these numbers; do not invent workloads, pools, or prices. Say so when "headroom" or "nodePools" is missing.
+- "headroom.cpu" / "headroom.memory": one sentence each with the request and p95 usage share of allocatable capacity and what is left.
+- "headroom.outlook": how much growth the cluster absorbs before requests or usage run out, stated as an estimate.
+- "consolidation": the biggest right-sizing and bin-packing opportunities first, e.g. a namespace or workload to shrink with its idle cores/GiB and monthly waste when given. Never suggest shrinking an "underProvisioned" workload.
+- "nodePools": per pool, keep, shrink, grow, or change instance type, based on its request share and the workload mix (memory-heavy vs CPU-heavy).
+- "risks": under-provisioned workloads, pools near full requests, and gaps such as workloads without metrics.
+- "summary": 3–5 sentences for a non-specialist reader: current utilization, the main saving, and the main risk.
+
+BEGIN_SNAPSHOT
+{{SNAPSHOT_JSON}}
+END_SNAPSHOT
+
+Return ONLY the JSON object.
+`
+
 // Enhancement templates - injected conditionally based on flags
 
 // EnhancementTechnical adds technical depth to analysis
@@ -236,3 +327,88 @@ Provide comprehensive fix guidance for each issue:
 
 Add these to a "remediationSteps" array, "rollbackProcedure" string, "preventionTips" array, and optionally a "detailedRemediation" object.
 `
+
+// ScalingContext explains the snapshot's workloadScaling section. Injected
+// when the snapshot lists HPA/VPA/PDB objects.
+const ScalingContext = `WORKLOAD SCALING CONTEXT:
+The snapshot's "workloadScaling" array lists the HPAs, VPAs, and PodDisruptionBudgets that already target each workload.
+- Never recommend adding an HPA, VPA, or PDB to a workload that already has one; recommend tuning it instead (e.g. raise maxReplicas, change the metric target, relax minAvailable).
+- Workloads missing from "workloadScaling" have none of these objects.
+- When an HPA is at maxReplicas (currentReplicas == maxReplicas), treat it as a likely capacity limit.
+- When a PDB has disruptionsAllowed == 0, mention that it blocks node drains and evictions.
+- An HPA on CPU together with a VPA in Auto/Recreate mode on the same workload conflict; flag it.
+
+`
+
+// RolloutContext explains the snapshot's rollouts section. Injected when the
+// snapshot lists Deployment rollouts.
+const RolloutContext = `ROLLOUT CONTEXT:
+The snapshot's "rollouts" array lists Deployments that are mid-rollout, stuck, recently rolled out, or own a problem pod, with their last revisions (newest first: revision, replicaSet, podTemplateHash, images, imageChanges, createdAt).
+Problem pods carry their owning "workload" and, for Deployment pods, the "revision" they run.
+- When problems started around a revision's createdAt, name that deploy explicitly (time and image) as a probable cause instead of speculating.
+- Use a revision's "imageChanges" to say what changed; a revision without image changes was a config-only rollout (env, resources, probes).
+- Failing pods all on the newest revision while the previous revision's pods were healthy point to a bad deploy; failures across revisions, workloads, or on the same node point to an infrastructure issue. Say which it is.
+- A rollout with status "Stuck" exceeded its progress deadline; recommend "kubectl rollout undo deployment/<name> -n <namespace>" when the previous revision was healthy.
+
+`
+
+// NodeContext explains the node details of the snapshot's nodeConditions.
+// Injected when nodes carry taints, allocation, or recent events.
+const NodeContext = `NODE CONTEXT:
+Each entry of "nodeConditions" may carry "taints", "unschedulable" (cordoned), "allocation" (allocatable CPU/memory/pods against the requests of the pods scheduled on it, with percentages), and "recentEvents" (the node's Warning events from the last hour).
+- Attribute a pod failure to its node (the pod's "nodeName") when that node is NotReady, under Memory/Disk/PIDPressure, or has recent events such as SystemOOM, EvictionThresholdMet, or NodeNotReady; say so instead of blaming the workload.
+- Evictions on a node with MemoryPressure or DiskPressure are a node problem; recommend fixing node capacity or the noisy neighbours.
+- Pending pods on a cluster whose nodes are near 100% CPU or memory allocation, tainted, or cordoned point to capacity or scheduling constraints, not the pod itself.
+
+`
+
+// StorageContext explains the snapshot's storage section. Injected when
+// storage was collected (--include-storage).
+const StorageContext = `STORAGE CONTEXT:
+The snapshot's "storage" object lists the PersistentVolumeClaims mounted by problem pods ("usedBy") and any claim that is not Bound, with their Warning events, bound "volumes", "storageClasses" (provisioner, volumeBindingMode), and CSI volume "attachments".
+- Pods stuck in ContainerCreating or Pending with a claim that is Pending, Lost, or has ProvisioningFailed/FailedBinding events have a storage problem; name the claim and the cause.
+- A claim Pending on a WaitForFirstConsumer class is normal until its pod is scheduled; look at the pod's scheduling instead.
+- An attachment with an attachError, or a detachError on another node, explains FailedAttachVolume/Multi-Attach errors; recommend checking the CSI driver or the node still holding the volume.
+- A Released or Failed volume, or a claim whose class's provisioner does not exist, needs manual action; say which.
+
+`
+
+// AcknowledgedContext explains the snapshot's acknowledgedProblems section.
+// Injected when problems were accepted via an acknowledgements file.
+const AcknowledgedContext = `ACKNOWLEDGED PROBLEMS:
+The snapshot's "acknowledgedProblems" array lists problems the team already triaged and accepted, with the reason, who accepted it, and until when.
+- Do not report them as issues, do not rank them, and do not re-argue the decision; their pods are not in "problemPods".
+- Only mention one when an active problem in "problemPods" is plausibly caused by it, and say it is a known accepted problem.
+
+`
+
+// OwnersContext explains the "team" field of problem pods. Injected when
+// pods were attributed to teams via an owners file.
+const OwnersContext = `TEAM OWNERSHIP:
+Problem pods may carry a "team" field naming the team that owns them.
+- Name the owning team next to each issue you report so findings can be routed to it.
+- When one root cause spans pods of several teams, list every affected team.
+
+`
+
+// PromptExplain asks for a plain-text explanation of one monitor problem,
+// shown in the monitor TUI's explain overlay rather than parsed.
+var PromptExplain = `
+You are kubeNow, a Kubernetes triage assistant. An engineer selected one problem in a live cluster monitor and wants to know why it happens and how to fix it.
+
+Answer in plain text (no JSON, no markdown tables), at most 40 lines, in exactly these sections:
+
+CAUSE: the most likely root cause in 1–3 sentences, citing the event, state, or log line that shows it.
+EVIDENCE: up to 5 short bullets quoting the decisive facts from the data below.
+FIX: numbered remediation steps, most likely to work first, with exact kubectl commands using the real namespace and names.
+CHECK: one command that confirms the fix worked.
+
+Rules:
+- Only use facts from the data below; say "unknown" rather than guessing names or values.
+- If the data is insufficient, say what to inspect next.
+- Be concise. No theory.
+
+BEGIN_PROBLEM
+{{PROBLEM}}
+END_PROBLEM
+`
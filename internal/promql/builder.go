@@ -0,0 +1,353 @@
+package promql
+
+import "time"
+
+// DefaultRateWindow is the rate() window used when none is configured.
+const DefaultRateWindow = 5 * time.Minute
+
+// Metric names used by the container and kube-state-metrics queries.
+const (
+	MetricContainerCPU       = "container_cpu_usage_seconds_total"
+	MetricContainerMemory    = "container_memory_working_set_bytes"
+	MetricContainerThrottled = "container_cpu_cfs_throttled_seconds_total"
+	MetricContainerPeriods   = "container_cpu_cfs_periods_total"
+	MetricThrottledPeriods   = "container_cpu_cfs_throttled_periods_total"
+	MetricContainerRestarts  = "kube_pod_container_status_restarts_total"
+	MetricLastTerminated     = "kube_pod_container_status_last_terminated_reason"
+	MetricResourceRequests   = "kube_pod_container_resource_requests"
+	MetricResourceLimits     = "kube_pod_container_resource_limits"
+	MetricPodOwner           = "kube_pod_owner"
+	MetricReplicaSetOwner    = "kube_replicaset_owner"
+	MetricJobOwner           = "kube_job_owner"
+	MetricPodPhase           = "kube_pod_status_phase"
+	MetricPodInfo            = "kube_pod_info"
+	MetricHPAReplicas        = "kube_horizontalpodautoscaler_status_current_replicas"
+	MetricVolumeUsed         = "kubelet_volume_stats_used_bytes"
+	MetricVolumeCapacity     = "kubelet_volume_stats_capacity_bytes"
+	MetricContainerFSUsage   = "container_fs_usage_bytes"
+	MetricNetworkReceive     = "container_network_receive_bytes_total"
+	MetricNetworkTransmit    = "container_network_transmit_bytes_total"
+)
+
+// OwnerMetrics records which kube-state-metrics owner series are available.
+// With them, a workload's pods are selected by joining on owner labels
+// instead of matching pod names, so "api" no longer picks up "api-gateway".
+type OwnerMetrics struct {
+	PodOwner        bool // kube_pod_owner
+	ReplicaSetOwner bool // kube_replicaset_owner
+	JobOwner        bool // kube_job_owner
+}
+
+// Builder composes container resource queries. Extra matchers (for example
+// cluster="prod" on a multi-cluster Thanos or Mimir) are added to every
+// selector; the container matcher defaults to "all real containers".
+type Builder struct {
+	rateWindow time.Duration
+	extra      []Matcher
+	container  string
+	owners     OwnerMetrics
+}
+
+// Option configures a Builder.
+type Option func(*Builder)
+
+// WithRateWindow sets the rate() window (default 5m). Non-positive values are ignored.
+func WithRateWindow(d time.Duration) Option {
+	return func(b *Builder) {
+		if d > 0 {
+			b.rateWindow = d
+		}
+	}
+}
+
+// WithMatchers adds label matchers to every selector the builder produces.
+func WithMatchers(matchers ...Matcher) Option {
+	return func(b *Builder) {
+		b.extra = append(b.extra, matchers...)
+	}
+}
+
+// WithCluster scopes every selector to one cluster, e.g. WithCluster("cluster", "prod").
+// An empty value is ignored.
+func WithCluster(label, value string) Option {
+	return func(b *Builder) {
+		if label != "" && value != "" {
+			b.extra = append(b.extra, Equal(label, value))
+		}
+	}
+}
+
+// WithContainer restricts container metrics to a single container name
+// instead of every non-pause container.
+func WithContainer(name string) Option {
+	return func(b *Builder) {
+		b.container = name
+	}
+}
+
+// WithOwnerMetrics selects workload pods through kube-state-metrics owner
+// series where they exist; otherwise workload pods are matched by name.
+func WithOwnerMetrics(m OwnerMetrics) Option {
+	return func(b *Builder) {
+		b.owners = m
+	}
+}
+
+// NewBuilder creates a query builder.
+func NewBuilder(opts ...Option) *Builder {
+	b := &Builder{rateWindow: DefaultRateWindow}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// RateWindow returns the configured rate() window.
+func (b *Builder) RateWindow() time.Duration {
+	return b.rateWindow
+}
+
+// Selector builds a selector with the builder's extra matchers appended.
+func (b *Builder) Selector(metric string, matchers ...Matcher) Selector {
+	return Select(metric, matchers...).With(b.extra...)
+}
+
+// ContainerSelector builds a selector for per-container cAdvisor metrics,
+// excluding the pod-level aggregate and pause containers unless a single
+// container was configured.
+func (b *Builder) ContainerSelector(metric string, matchers ...Matcher) Selector {
+	sel := Select(metric, matchers...)
+	if b.container != "" {
+		sel = sel.With(Equal("container", b.container))
+	} else {
+		sel = sel.With(NotEqual("container", ""), NotEqual("container", "POD"))
+	}
+	return sel.With(b.extra...)
+}
+
+// CPUUsage returns the summed CPU usage rate of the matched containers.
+func (b *Builder) CPUUsage(matchers []Matcher, by ...string) string {
+	return Sum(Rate(b.ContainerSelector(MetricContainerCPU, matchers...), b.rateWindow), by...)
+}
+
+// MemoryUsage returns the summed working-set memory of the matched containers.
+func (b *Builder) MemoryUsage(matchers []Matcher, by ...string) string {
+	return Sum(b.ContainerSelector(MetricContainerMemory, matchers...).String(), by...)
+}
+
+// ResourceRequests returns summed kube-state-metrics requests for a resource (cpu|memory).
+func (b *Builder) ResourceRequests(resource string, matchers []Matcher, by ...string) string {
+	return Sum(b.resourceSelector(MetricResourceRequests, resource, matchers).String(), by...)
+}
+
+// ResourceLimits returns summed kube-state-metrics limits for a resource (cpu|memory).
+func (b *Builder) ResourceLimits(resource string, matchers []Matcher, by ...string) string {
+	return Sum(b.resourceSelector(MetricResourceLimits, resource, matchers).String(), by...)
+}
+
+func (b *Builder) resourceSelector(metric, resource string, matchers []Matcher) Selector {
+	return Select(metric, matchers...).With(Equal("resource", resource)).With(b.extra...)
+}
+
+// PodResourceTotals returns kube-state-metrics requests or limits (metric)
+// summed per pod and resource, for CPU and memory at once.
+func (b *Builder) PodResourceTotals(metric string, matchers []Matcher) string {
+	sel := Select(metric, matchers...).With(Regex("resource", "cpu|memory")).With(b.extra...)
+	return Sum(sel.String(), "pod", "resource")
+}
+
+// OwnersOverTime returns the owner (owner_kind, owner_name) of every object
+// of an owner metric (kube_pod_owner, kube_replicaset_owner) that existed at
+// any time in the window; nameLabel is the object's label (pod, replicaset).
+func (b *Builder) OwnersOverTime(metric, nameLabel string, matchers []Matcher, window time.Duration) string {
+	return Max("max_over_time("+b.Selector(metric, matchers...).Range(window)+")", nameLabel, "owner_kind", "owner_name")
+}
+
+// Owners returns the owner metrics the builder joins on.
+func (b *Builder) Owners() OwnerMetrics {
+	return b.owners
+}
+
+// Throttled returns the summed CPU throttling seconds of the matched containers over the window.
+func (b *Builder) Throttled(matchers []Matcher, window time.Duration) string {
+	return Sum(Increase(b.ContainerSelector(MetricContainerThrottled, matchers...), window))
+}
+
+// ThrottledPeriods returns the summed CFS periods in which the matched
+// containers were throttled over the window.
+func (b *Builder) ThrottledPeriods(matchers []Matcher, window time.Duration, by ...string) string {
+	return Sum(Increase(b.ContainerSelector(MetricThrottledPeriods, matchers...), window), by...)
+}
+
+// Periods returns the summed CFS periods of the matched containers over the
+// window. Only containers with a CPU limit (a CFS quota) report periods.
+func (b *Builder) Periods(matchers []Matcher, window time.Duration, by ...string) string {
+	return Sum(Increase(b.ContainerSelector(MetricContainerPeriods, matchers...), window), by...)
+}
+
+// HPAReplicas returns the current replica count kube-state-metrics reports
+// for a HorizontalPodAutoscaler.
+func (b *Builder) HPAReplicas(namespace, name string) string {
+	return Max(b.Selector(MetricHPAReplicas, Equal("namespace", namespace), Equal("horizontalpodautoscaler", name)).String())
+}
+
+// VolumeStats returns a kubelet volume stats metric (used or capacity bytes)
+// per PersistentVolumeClaim. Every kubelet mounting a volume reports it, so
+// duplicates are collapsed with max.
+func (b *Builder) VolumeStats(metric string, matchers []Matcher) string {
+	return Max(b.Selector(metric, matchers...).String(), "namespace", "persistentvolumeclaim")
+}
+
+// ContainerFSUsage returns the writable-layer and log bytes of the matched
+// containers, the part of ephemeral storage cAdvisor attributes to them.
+func (b *Builder) ContainerFSUsage(matchers []Matcher, by ...string) string {
+	return Sum(b.ContainerSelector(MetricContainerFSUsage, matchers...).String(), by...)
+}
+
+// PodNetworkRate returns the per-pod rate of a cAdvisor network counter
+// (receive or transmit bytes). Network series belong to the pod sandbox,
+// which some runtimes report twice (container="" and "POD"), so each
+// interface is counted once before summing.
+func (b *Builder) PodNetworkRate(metric string, matchers []Matcher, window time.Duration) string {
+	return Sum(Max(Rate(b.Selector(metric, matchers...), window), "pod", "interface"), "pod")
+}
+
+// NodeCPUUsage returns container CPU usage summed per node.
+func (b *Builder) NodeCPUUsage() string {
+	return Sum(b.onNode(Rate(b.ContainerSelector(MetricContainerCPU), b.rateWindow)), "node")
+}
+
+// NodeMemoryUsage returns container working-set memory summed per node.
+func (b *Builder) NodeMemoryUsage() string {
+	return Sum(b.onNode(b.ContainerSelector(MetricContainerMemory).String()), "node")
+}
+
+// onNode labels per-pod series with the node from kube_pod_info, so cAdvisor
+// series need no node label of their own. topk keeps one node for a pod name
+// briefly reported on two, such as a recreated StatefulSet pod.
+func (b *Builder) onNode(expr string) string {
+	podNodes := TopK(1, Max(b.Selector(MetricPodInfo, NotEqual("node", "")).String(), "namespace", "pod", "node"), "namespace", "pod")
+	return GroupLeftInclude(expr, podNodes, []string{"node"}, "namespace", "pod")
+}
+
+// Restarts returns the summed container restarts of the matched pods over the window.
+func (b *Builder) Restarts(matchers []Matcher, window time.Duration, by ...string) string {
+	return Sum(Increase(b.Selector(MetricContainerRestarts, matchers...), window), by...)
+}
+
+// OOMKills returns the container restarts of the matched pods over the
+// window that followed an OOM kill: restarts of containers whose last
+// termination reported OOMKilled at some point in the window. A container
+// that also restarted for other reasons is over-counted, one killed and
+// replaced without restarting is missed.
+func (b *Builder) OOMKills(matchers []Matcher, window time.Duration, by ...string) string {
+	restarts := Increase(b.Selector(MetricContainerRestarts, matchers...), window)
+	oomMatchers := append(append([]Matcher{}, matchers...), Equal("reason", "OOMKilled"))
+	oomKilled := "max_over_time(" + b.Selector(MetricLastTerminated, oomMatchers...).Range(window) + ")"
+	return Sum(GroupLeft(restarts, oomKilled, "namespace", "pod", "container"), by...)
+}
+
+// PodFilter selects the pods of a workload: label matchers applied to the
+// metric selector, plus an optional owner expression joined on (namespace, pod).
+type PodFilter struct {
+	Matchers []Matcher
+	Owner    string
+}
+
+// Joined reports whether the filter selects pods through owner metadata.
+func (f PodFilter) Joined() bool {
+	return f.Owner != ""
+}
+
+func (f PodFilter) apply(expr string) string {
+	if f.Owner == "" {
+		return expr
+	}
+	return GroupLeft(expr, f.Owner, "namespace", "pod")
+}
+
+// PodsOf returns the filter for a workload's pods. Bare pods match by name.
+// Other kinds join on kube_pod_owner (directly owned pods) and, when
+// kube_replicaset_owner exists, on pods owned through a ReplicaSet
+// (Deployments, Argo Rollouts). CronJob pods are owned through a Job and
+// join on kube_job_owner. Without owner metrics — or for a Deployment
+// without kube_replicaset_owner or a CronJob without kube_job_owner — it
+// falls back to pod-name regex matching.
+func (b *Builder) PodsOf(namespace, name, kind string) PodFilter {
+	ns := Equal("namespace", namespace)
+	switch {
+	case kind == KindPod, !b.owners.PodOwner:
+		return PodFilter{Matchers: []Matcher{ns, WorkloadPods(name, kind)}}
+	case !b.owners.ReplicaSetOwner && kind == KindDeployment, !b.owners.JobOwner && kind == KindCronJob:
+		return PodFilter{Matchers: []Matcher{ns, WorkloadPods(name, kind)}}
+	}
+
+	owner := Equal("owner_name", name)
+	if kind == KindCronJob {
+		return PodFilter{Matchers: []Matcher{ns}, Owner: "(" + b.podsOwnedThrough(ns, owner, kind, MetricJobOwner, "job_name", KindJob) + ")"}
+	}
+	direct := Max(b.Selector(MetricPodOwner, ns, Equal("owner_kind", kind), owner).String(), "namespace", "pod")
+	if !b.owners.ReplicaSetOwner {
+		return PodFilter{Matchers: []Matcher{ns}, Owner: "(" + direct + ")"}
+	}
+	viaReplicaSet := b.podsOwnedThrough(ns, owner, kind, MetricReplicaSetOwner, "replicaset", "ReplicaSet")
+	return PodFilter{Matchers: []Matcher{ns}, Owner: "(" + direct + " or " + viaReplicaSet + ")"}
+}
+
+// podsOwnedThrough selects pods owned by an intermediate object (ReplicaSet,
+// Job) that the workload owns: the intermediate's name label is renamed to
+// "owner_name" to join with kube_pod_owner.
+func (b *Builder) podsOwnedThrough(ns, owner Matcher, kind, ownerMetric, nameLabel, intermediateKind string) string {
+	intermediates := Max(
+		LabelReplace(b.Selector(ownerMetric, ns, Equal("owner_kind", kind), owner).String(), "owner_name", "$1", nameLabel, "(.*)"),
+		"namespace", "owner_name")
+	return Max(
+		GroupLeft(b.Selector(MetricPodOwner, ns, Equal("owner_kind", intermediateKind)).String(), intermediates, "namespace", "owner_name"),
+		"namespace", "pod")
+}
+
+// Running restricts a filter to pods currently in the Running phase, so
+// finished Job pods, whose requests kube-state-metrics keeps reporting until
+// they are deleted, drop out.
+func (b *Builder) Running(f PodFilter) PodFilter {
+	matchers := append(append([]Matcher{}, f.Matchers...), Equal("phase", "Running"))
+	running := Max(b.Selector(MetricPodPhase, matchers...).String()+" == 1", "namespace", "pod")
+	if f.Owner == "" {
+		return PodFilter{Matchers: f.Matchers, Owner: "(" + running + ")"}
+	}
+	return PodFilter{Matchers: f.Matchers, Owner: "(" + f.Owner + " and on (namespace, pod) " + running + ")"}
+}
+
+// WorkloadCPUUsage returns the summed CPU usage rate of a workload's containers.
+func (b *Builder) WorkloadCPUUsage(f PodFilter) string {
+	return Sum(f.apply(Rate(b.ContainerSelector(MetricContainerCPU, f.Matchers...), b.rateWindow)))
+}
+
+// WorkloadMemoryUsage returns the summed working-set memory of a workload's containers.
+func (b *Builder) WorkloadMemoryUsage(f PodFilter) string {
+	return Sum(f.apply(b.ContainerSelector(MetricContainerMemory, f.Matchers...).String()))
+}
+
+// WorkloadContainerMemory returns the working-set memory of a workload's
+// hottest replica per container name.
+func (b *Builder) WorkloadContainerMemory(f PodFilter) string {
+	return Max(f.apply(b.ContainerSelector(MetricContainerMemory, f.Matchers...).String()), "container")
+}
+
+// WorkloadThrottledRatio returns the share of a workload's CFS periods in
+// which its containers were throttled over the window (0-1).
+func (b *Builder) WorkloadThrottledRatio(f PodFilter, window time.Duration) string {
+	throttled := Sum(f.apply(Increase(b.ContainerSelector(MetricThrottledPeriods, f.Matchers...), window)))
+	periods := Sum(f.apply(Increase(b.ContainerSelector(MetricContainerPeriods, f.Matchers...), window)))
+	return throttled + " / " + periods
+}
+
+// WorkloadRequests returns a workload's summed requests for a resource (cpu|memory).
+func (b *Builder) WorkloadRequests(resource string, f PodFilter) string {
+	return Sum(f.apply(b.resourceSelector(MetricResourceRequests, resource, f.Matchers).String()))
+}
+
+// WorkloadLimits returns a workload's summed limits for a resource (cpu|memory).
+func (b *Builder) WorkloadLimits(resource string, f PodFilter) string {
+	return Sum(f.apply(b.resourceSelector(MetricResourceLimits, resource, f.Matchers).String()))
+}
@@ -0,0 +1,257 @@
+package promql
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Defaults(t *testing.T) {
+	b := NewBuilder()
+	ns := []Matcher{Equal("namespace", "prod")}
+
+	assert.Equal(t, DefaultRateWindow, b.RateWindow())
+	assert.Equal(t,
+		`sum(rate(container_cpu_usage_seconds_total{namespace="prod",container!="",container!="POD"}[5m]))`,
+		b.CPUUsage(ns))
+	assert.Equal(t,
+		`sum(container_memory_working_set_bytes{namespace="prod",container!="",container!="POD"}) by (pod)`,
+		b.MemoryUsage(ns, "pod"))
+	assert.Equal(t,
+		`sum(kube_pod_container_resource_requests{namespace="prod",resource="cpu"})`,
+		b.ResourceRequests("cpu", ns))
+	assert.Equal(t,
+		`sum(kube_pod_container_resource_limits{namespace="prod",resource="memory"})`,
+		b.ResourceLimits("memory", ns))
+	assert.Equal(t,
+		`sum(increase(kube_pod_container_status_restarts_total{namespace="prod"}[1h])) by (pod)`,
+		b.Restarts(ns, time.Hour, "pod"))
+	assert.Equal(t,
+		`sum(increase(kube_pod_container_status_restarts_total{namespace="prod"}[1h]) * on (namespace, pod, container) group_left () `+
+			`max_over_time(kube_pod_container_status_last_terminated_reason{namespace="prod",reason="OOMKilled"}[1h]))`,
+		b.OOMKills(ns, time.Hour))
+}
+
+func TestBuilder_Options(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		contains []string
+		absent   []string
+	}{
+		{
+			name:     "cluster label",
+			opts:     []Option{WithCluster("cluster", "prod")},
+			contains: []string{`cluster="prod"`},
+		},
+		{
+			name:   "empty cluster ignored",
+			opts:   []Option{WithCluster("cluster", "")},
+			absent: []string{"cluster="},
+		},
+		{
+			name:     "single container",
+			opts:     []Option{WithContainer("app")},
+			contains: []string{`container="app"`},
+			absent:   []string{`container!=""`, `container!="POD"`},
+		},
+		{
+			name:     "rate window",
+			opts:     []Option{WithRateWindow(2 * time.Minute)},
+			contains: []string{"[2m]"},
+			absent:   []string{"[5m]"},
+		},
+		{
+			name:     "non-positive rate window ignored",
+			opts:     []Option{WithRateWindow(0)},
+			contains: []string{"[5m]"},
+		},
+		{
+			name:     "extra matchers",
+			opts:     []Option{WithMatchers(NotEqual("job", "test"))},
+			contains: []string{`job!="test"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewBuilder(tt.opts...).CPUUsage([]Matcher{Equal("namespace", "ns")})
+			for _, s := range tt.contains {
+				assert.Contains(t, q, s)
+			}
+			for _, s := range tt.absent {
+				assert.NotContains(t, q, s)
+			}
+		})
+	}
+}
+
+func TestBuilder_InjectionSafe(t *testing.T) {
+	b := NewBuilder(WithCluster("cluster", "prod"))
+	q := b.CPUUsage([]Matcher{Equal("namespace", `ns"}) or vector(1) #`), WorkloadPods(`api"`, "Deployment")})
+
+	assert.Contains(t, q, `namespace="ns\"}) or vector(1) #"`)
+	assert.Contains(t, q, `pod=~"api\"-.*"`)
+	// The cluster scope is still applied after the hostile values.
+	assert.Contains(t, q, `cluster="prod"}`)
+}
+
+func TestBuilder_PodsOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		owners   OwnerMetrics
+		kind     string
+		contains []string
+		absent   []string
+	}{
+		{
+			name:     "no owner metrics falls back to regex",
+			kind:     KindDeployment,
+			contains: []string{`pod=~"api-.*"`},
+			absent:   []string{MetricPodOwner},
+		},
+		{
+			name:     "bare pod always matches by name",
+			owners:   OwnerMetrics{PodOwner: true, ReplicaSetOwner: true},
+			kind:     KindPod,
+			contains: []string{`pod="api"`},
+			absent:   []string{MetricPodOwner},
+		},
+		{
+			name:     "deployment without replicaset owner falls back to regex",
+			owners:   OwnerMetrics{PodOwner: true},
+			kind:     KindDeployment,
+			contains: []string{`pod=~"api-.*"`},
+			absent:   []string{MetricPodOwner},
+		},
+		{
+			name:   "statefulset joins directly owned pods",
+			owners: OwnerMetrics{PodOwner: true},
+			kind:   KindStatefulSet,
+			contains: []string{
+				`* on (namespace, pod) group_left () (max(kube_pod_owner{namespace="prod",owner_kind="StatefulSet",owner_name="api"}) by (namespace, pod))`,
+			},
+			absent: []string{`pod=~`, MetricReplicaSetOwner},
+		},
+		{
+			name:   "deployment joins pods through replicasets",
+			owners: OwnerMetrics{PodOwner: true, ReplicaSetOwner: true},
+			kind:   KindDeployment,
+			contains: []string{
+				`max(kube_pod_owner{namespace="prod",owner_kind="Deployment",owner_name="api"}) by (namespace, pod) or `,
+				`kube_pod_owner{namespace="prod",owner_kind="ReplicaSet"} * on (namespace, owner_name) group_left () `,
+				`label_replace(kube_replicaset_owner{namespace="prod",owner_kind="Deployment",owner_name="api"}, "owner_name", "$1", "replicaset", "(.*)")`,
+			},
+			absent: []string{`pod=~`},
+		},
+		{
+			name:     "cronjob without job owner falls back to regex",
+			owners:   OwnerMetrics{PodOwner: true, ReplicaSetOwner: true},
+			kind:     KindCronJob,
+			contains: []string{`pod=~"api-[0-9]+-.*"`},
+			absent:   []string{MetricPodOwner},
+		},
+		{
+			name:   "cronjob joins pods through jobs",
+			owners: OwnerMetrics{PodOwner: true, ReplicaSetOwner: true, JobOwner: true},
+			kind:   KindCronJob,
+			contains: []string{
+				`kube_pod_owner{namespace="prod",owner_kind="Job"} * on (namespace, owner_name) group_left () `,
+				`label_replace(kube_job_owner{namespace="prod",owner_kind="CronJob",owner_name="api"}, "owner_name", "$1", "job_name", "(.*)")`,
+			},
+			absent: []string{`pod=~`, MetricReplicaSetOwner},
+		},
+		{
+			name:   "job joins directly owned pods",
+			owners: OwnerMetrics{PodOwner: true, ReplicaSetOwner: true, JobOwner: true},
+			kind:   KindJob,
+			contains: []string{
+				`max(kube_pod_owner{namespace="prod",owner_kind="Job",owner_name="api"}) by (namespace, pod)`,
+			},
+			absent: []string{`pod=~`, MetricJobOwner},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuilder(WithOwnerMetrics(tt.owners))
+			q := b.WorkloadCPUUsage(b.PodsOf("prod", "api", tt.kind))
+			for _, s := range tt.contains {
+				assert.Contains(t, q, s)
+			}
+			for _, s := range tt.absent {
+				assert.NotContains(t, q, s)
+			}
+		})
+	}
+}
+
+func TestBuilder_PodsOf_ScopesOwnerSelectors(t *testing.T) {
+	b := NewBuilder(WithCluster("cluster", "prod"), WithOwnerMetrics(OwnerMetrics{PodOwner: true, ReplicaSetOwner: true}))
+	f := b.PodsOf("default", "api", KindDeployment)
+
+	assert.True(t, f.Joined())
+	assert.Equal(t, 3, strings.Count(f.Owner, `cluster="prod"`), f.Owner)
+	assert.Contains(t, b.WorkloadRequests("cpu", f), `kube_pod_container_resource_requests{namespace="default",resource="cpu",cluster="prod"} * on (namespace, pod)`)
+}
+
+func TestBuilder_Running(t *testing.T) {
+	running := `max(kube_pod_status_phase{namespace="prod",phase="Running"} == 1) by (namespace, pod)`
+
+	b := NewBuilder(WithOwnerMetrics(OwnerMetrics{PodOwner: true}))
+	f := b.Running(b.PodsOf("prod", "report", KindJob))
+	assert.Contains(t, f.Owner, `by (namespace, pod)) and on (namespace, pod) `+running)
+	assert.Contains(t, b.WorkloadRequests("cpu", f), `kube_pod_container_resource_requests{namespace="prod",resource="cpu"} * on (namespace, pod) group_left () ((max(kube_pod_owner`)
+
+	// Name-matched pods get the phase filter as their only join.
+	b = NewBuilder()
+	f = b.Running(b.PodsOf("prod", "report", KindCronJob))
+	assert.Equal(t, `(max(kube_pod_status_phase{namespace="prod",pod=~"report-[0-9]+-.*",phase="Running"} == 1) by (namespace, pod))`, f.Owner)
+	assert.Len(t, f.Matchers, 2)
+}
+
+func TestBuilder_WorkloadContainerMemory(t *testing.T) {
+	b := NewBuilder()
+	assert.Equal(t,
+		`max(container_memory_working_set_bytes{namespace="prod",pod=~"api-[0-9]+",container!="",container!="POD"}) by (container)`,
+		b.WorkloadContainerMemory(b.PodsOf("prod", "api", KindStatefulSet)))
+
+	b = NewBuilder(WithOwnerMetrics(OwnerMetrics{PodOwner: true}))
+	assert.Contains(t, b.WorkloadContainerMemory(b.PodsOf("prod", "api", KindStatefulSet)),
+		`container_memory_working_set_bytes{namespace="prod",container!="",container!="POD"} * on (namespace, pod) group_left () (max(kube_pod_owner`)
+}
+
+func TestBuilder_Throttling(t *testing.T) {
+	b := NewBuilder()
+	assert.Equal(t,
+		`sum(increase(container_cpu_cfs_throttled_periods_total{container!="",container!="POD"}[1h])) by (namespace, pod, container)`,
+		b.ThrottledPeriods(nil, time.Hour, "namespace", "pod", "container"))
+	assert.Equal(t,
+		`sum(increase(container_cpu_cfs_periods_total{namespace="prod",container!="",container!="POD"}[1h]))`,
+		b.Periods([]Matcher{Equal("namespace", "prod")}, time.Hour))
+	assert.Equal(t,
+		`sum(increase(container_cpu_cfs_throttled_periods_total{namespace="prod",pod=~"api-[0-9]+",container!="",container!="POD"}[1d])) / `+
+			`sum(increase(container_cpu_cfs_periods_total{namespace="prod",pod=~"api-[0-9]+",container!="",container!="POD"}[1d]))`,
+		b.WorkloadThrottledRatio(b.PodsOf("prod", "api", KindStatefulSet), 24*time.Hour))
+}
+
+func TestBuilder_HPAReplicas(t *testing.T) {
+	b := NewBuilder(WithCluster("cluster", "prod"))
+	assert.Equal(t,
+		`max(kube_horizontalpodautoscaler_status_current_replicas{namespace="shop",horizontalpodautoscaler="api",cluster="prod"})`,
+		b.HPAReplicas("shop", "api"))
+}
+
+func TestBuilder_NodeUsage(t *testing.T) {
+	b := NewBuilder(WithCluster("cluster", "prod"))
+	podNodes := `topk(1, max(kube_pod_info{node!="",cluster="prod"}) by (namespace, pod, node)) by (namespace, pod)`
+
+	assert.Equal(t,
+		`sum(rate(container_cpu_usage_seconds_total{container!="",container!="POD",cluster="prod"}[5m]) * on (namespace, pod) group_left (node) `+
+			podNodes+`) by (node)`,
+		b.NodeCPUUsage())
+	assert.Equal(t,
+		`sum(container_memory_working_set_bytes{container!="",container!="POD",cluster="prod"} * on (namespace, pod) group_left (node) `+
+			podNodes+`) by (node)`,
+		b.NodeMemoryUsage())
+}
@@ -0,0 +1,258 @@
+// Package promql builds PromQL expressions with guaranteed label escaping.
+//
+// Every label value passes through Quote (or QuoteRegexLiteral for names
+// embedded in regex matchers), so values taken from the cluster — namespace,
+// workload, container names — can never close a matcher and inject another.
+package promql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MatchOp is a PromQL label matching operator.
+type MatchOp string
+
+// Label matching operators.
+const (
+	OpEqual    MatchOp = "="
+	OpNotEqual MatchOp = "!="
+	OpRegex    MatchOp = "=~"
+	OpNotRegex MatchOp = "!~"
+)
+
+// Workload kinds that change how pods are matched.
+const (
+	KindStatefulSet = "StatefulSet"
+	KindPod         = "Pod"
+	KindDeployment  = "Deployment" // pods are owned through a ReplicaSet
+	KindJob         = "Job"
+	KindCronJob     = "CronJob" // pods are owned through a Job
+)
+
+// Matcher is a single label matcher, e.g. namespace="prod".
+type Matcher struct {
+	Name  string
+	Op    MatchOp
+	Value string // raw value; quoted and escaped by String
+}
+
+// Equal matches a label exactly.
+func Equal(name, value string) Matcher {
+	return Matcher{Name: name, Op: OpEqual, Value: value}
+}
+
+// NotEqual excludes an exact label value.
+func NotEqual(name, value string) Matcher {
+	return Matcher{Name: name, Op: OpNotEqual, Value: value}
+}
+
+// Regex matches a label against a trusted regular expression. Use
+// QuoteRegexLiteral (or WorkloadPods) for untrusted parts of the pattern.
+func Regex(name, pattern string) Matcher {
+	return Matcher{Name: name, Op: OpRegex, Value: pattern}
+}
+
+// NotRegex excludes label values matching a trusted regular expression.
+func NotRegex(name, pattern string) Matcher {
+	return Matcher{Name: name, Op: OpNotRegex, Value: pattern}
+}
+
+// WorkloadPods matches the pods of a workload by name: StatefulSet pods are
+// "<name>-<ordinal>", CronJob pods are "<name>-<schedule time>-<suffix>",
+// bare pods match exactly, and everything else (Deployment, DaemonSet, Job,
+// operators) is "<name>-<suffix>". The name is regex-escaped.
+func WorkloadPods(workloadName, workloadKind string) Matcher {
+	switch workloadKind {
+	case KindPod:
+		return Equal("pod", workloadName)
+	case KindStatefulSet:
+		return Regex("pod", QuoteRegexLiteral(workloadName)+"-[0-9]+")
+	case KindCronJob:
+		return Regex("pod", QuoteRegexLiteral(workloadName)+"-[0-9]+-.*")
+	default:
+		return Regex("pod", QuoteRegexLiteral(workloadName)+"-.*")
+	}
+}
+
+// PodPrefix matches every pod whose name starts with the (regex-escaped) prefix.
+func PodPrefix(prefix string) Matcher {
+	return Regex("pod", QuoteRegexLiteral(prefix)+".*")
+}
+
+// String renders the matcher with its value quoted and escaped.
+func (m Matcher) String() string {
+	return m.Name + string(m.Op) + Quote(m.Value)
+}
+
+// Selector is a metric name with label matchers, e.g. up{job="node"}.
+type Selector struct {
+	Metric   string
+	Matchers []Matcher
+}
+
+// Select builds a selector.
+func Select(metric string, matchers ...Matcher) Selector {
+	return Selector{Metric: metric, Matchers: matchers}
+}
+
+// With returns a copy of the selector with additional matchers.
+func (s Selector) With(matchers ...Matcher) Selector {
+	out := Selector{Metric: s.Metric, Matchers: make([]Matcher, 0, len(s.Matchers)+len(matchers))}
+	out.Matchers = append(out.Matchers, s.Matchers...)
+	out.Matchers = append(out.Matchers, matchers...)
+	return out
+}
+
+// String renders the selector.
+func (s Selector) String() string {
+	if len(s.Matchers) == 0 {
+		return s.Metric
+	}
+	parts := make([]string, len(s.Matchers))
+	for i, m := range s.Matchers {
+		parts[i] = m.String()
+	}
+	return s.Metric + "{" + strings.Join(parts, ",") + "}"
+}
+
+// Range renders a range-vector selector, e.g. metric{...}[5m].
+func (s Selector) Range(window time.Duration) string {
+	return s.String() + "[" + Duration(window) + "]"
+}
+
+// Rate wraps a range selector in rate().
+func Rate(s Selector, window time.Duration) string {
+	return "rate(" + s.Range(window) + ")"
+}
+
+// Increase wraps a range selector in increase().
+func Increase(s Selector, window time.Duration) string {
+	return "increase(" + s.Range(window) + ")"
+}
+
+// Sum aggregates an expression, optionally by labels.
+func Sum(expr string, by ...string) string {
+	return aggregate("sum", expr, by)
+}
+
+// Max takes the maximum of an expression, optionally by labels.
+func Max(expr string, by ...string) string {
+	return aggregate("max", expr, by)
+}
+
+// Count counts series of an expression, optionally by labels.
+func Count(expr string, by ...string) string {
+	return aggregate("count", expr, by)
+}
+
+func aggregate(op, expr string, by []string) string {
+	out := op + "(" + expr + ")"
+	if len(by) > 0 {
+		out += " by (" + strings.Join(by, ", ") + ")"
+	}
+	return out
+}
+
+// GroupLeft joins left with right on the given labels, keeping left's labels:
+// left * on (labels) group_left () right. right should be a 1-valued filter
+// (an info-style series) so the product preserves left's values.
+func GroupLeft(left, right string, on ...string) string {
+	return GroupLeftInclude(left, right, nil, on...)
+}
+
+// GroupLeftInclude is GroupLeft that also copies the include labels from
+// right onto the result: left * on (labels) group_left (include) right.
+func GroupLeftInclude(left, right string, include []string, on ...string) string {
+	return left + " * on (" + strings.Join(on, ", ") + ") group_left (" + strings.Join(include, ", ") + ") " + right
+}
+
+// TopK keeps the k largest series of an expression per group of labels.
+func TopK(k int, expr string, by ...string) string {
+	return aggregate("topk", strconv.Itoa(k)+", "+expr, by)
+}
+
+// LabelReplace renders label_replace(expr, dst, replacement, src, regex).
+// replacement and regex are trusted patterns and are only string-quoted.
+func LabelReplace(expr, dst, replacement, src, regex string) string {
+	return "label_replace(" + expr + ", " + Quote(dst) + ", " + Quote(replacement) + ", " + Quote(src) + ", " + Quote(regex) + ")"
+}
+
+// Subquery renders expr as a subquery over the window at the default resolution.
+func Subquery(expr string, window time.Duration) string {
+	return "(" + expr + ")[" + Duration(window) + ":]"
+}
+
+// AvgOverTime averages an instant expression over the window.
+func AvgOverTime(expr string, window time.Duration) string {
+	return "avg_over_time(" + Subquery(expr, window) + ")"
+}
+
+// MaxOverTime takes the maximum of an instant expression over the window.
+func MaxOverTime(expr string, window time.Duration) string {
+	return "max_over_time(" + Subquery(expr, window) + ")"
+}
+
+// QuantileOverTime takes a quantile of an instant expression over the window.
+// Quantiles keep up to three decimals, so 0.999 is not rounded to 1.
+func QuantileOverTime(q float64, expr string, window time.Duration) string {
+	return "quantile_over_time(" + formatQuantile(q) + ", " + Subquery(expr, window) + ")"
+}
+
+func formatQuantile(q float64) string {
+	s := fmt.Sprintf("%.3f", q)
+	s = strings.TrimRight(s, "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	return s
+}
+
+// Duration converts a Go duration to the largest whole PromQL unit (s, m, h, d).
+func Duration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// Quote renders a label value as a double-quoted PromQL string, escaping
+// backslashes, double quotes and newlines.
+func Quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// regexMeta escapes RE2 metacharacters.
+var regexMeta = strings.NewReplacer(
+	`\`, `\\`,
+	`.`, `\.`,
+	`*`, `\*`,
+	`+`, `\+`,
+	`?`, `\?`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`|`, `\|`,
+	`^`, `\^`,
+	`$`, `\$`,
+)
+
+// QuoteRegexLiteral escapes regex metacharacters so s matches literally inside
+// a regex matcher. The result is unquoted; Matcher.String adds the string quoting.
+func QuoteRegexLiteral(s string) string {
+	return regexMeta.Replace(s)
+}
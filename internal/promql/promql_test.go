@@ -0,0 +1,130 @@
+package promql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{"plain", "payments", `"payments"`},
+		{"double quote", `a"b`, `"a\"b"`},
+		{"backslash", `a\b`, `"a\\b"`},
+		{"newline", "a\nb", `"a\nb"`},
+		{"matcher breakout", `x",namespace=~".*`, `"x\",namespace=~\".*"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Quote(tt.in))
+		})
+	}
+}
+
+func TestQuoteRegexLiteral(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"myapp", "myapp"},
+		{"my.app", `my\.app`},
+		{"a+b*c?", `a\+b\*c\?`},
+		{"(x)[y]{z}", `\(x\)\[y\]\{z\}`},
+		{"a|b^c$", `a\|b\^c\$`},
+		{`back\slash`, `back\\slash`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.expected, QuoteRegexLiteral(tt.in))
+		})
+	}
+}
+
+func TestMatcher_String(t *testing.T) {
+	assert.Equal(t, `namespace="prod"`, Equal("namespace", "prod").String())
+	assert.Equal(t, `container!="POD"`, NotEqual("container", "POD").String())
+	assert.Equal(t, `pod=~"api-.*"`, Regex("pod", "api-.*").String())
+	assert.Equal(t, `pod!~"tmp-.*"`, NotRegex("pod", "tmp-.*").String())
+	// Regex escapes survive string quoting as doubled backslashes.
+	assert.Equal(t, `pod=~"my\\.app.*"`, PodPrefix("my.app").String())
+}
+
+func TestWorkloadPods(t *testing.T) {
+	tests := []struct {
+		kind     string
+		expected string
+	}{
+		{"Deployment", `pod=~"web-.*"`},
+		{"DaemonSet", `pod=~"web-.*"`},
+		{KindStatefulSet, `pod=~"web-[0-9]+"`},
+		{KindPod, `pod="web"`},
+		{KindJob, `pod=~"web-.*"`},
+		{KindCronJob, `pod=~"web-[0-9]+-.*"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WorkloadPods("web", tt.kind).String())
+		})
+	}
+}
+
+func TestSelector_WithDoesNotAlias(t *testing.T) {
+	base := Select("up", Equal("job", "node"))
+	a := base.With(Equal("instance", "a"))
+	b := base.With(Equal("instance", "b"))
+
+	assert.Equal(t, `up{job="node"}`, base.String())
+	assert.Equal(t, `up{job="node",instance="a"}`, a.String())
+	assert.Equal(t, `up{job="node",instance="b"}`, b.String())
+	assert.Equal(t, "up", Select("up").String())
+}
+
+func TestFunctions(t *testing.T) {
+	sel := Select("m", Equal("namespace", "ns"))
+
+	assert.Equal(t, `rate(m{namespace="ns"}[5m])`, Rate(sel, 5*time.Minute))
+	assert.Equal(t, `increase(m{namespace="ns"}[1h])`, Increase(sel, time.Hour))
+	assert.Equal(t, "sum(x)", Sum("x"))
+	assert.Equal(t, "sum(x) by (namespace, pod)", Sum("x", "namespace", "pod"))
+	assert.Equal(t, "count(x) by (namespace)", Count("x", "namespace"))
+	assert.Equal(t, "avg_over_time((x)[7d:])", AvgOverTime("x", 7*24*time.Hour))
+	assert.Equal(t, "max_over_time((x)[30m:])", MaxOverTime("x", 30*time.Minute))
+	assert.Equal(t, "topk(1, x) by (pod)", TopK(1, "x", "pod"))
+	assert.Equal(t, "a * on (pod) group_left () b", GroupLeft("a", "b", "pod"))
+	assert.Equal(t, "a * on (namespace, pod) group_left (node) b", GroupLeftInclude("a", "b", []string{"node"}, "namespace", "pod"))
+}
+
+func TestQuantileOverTime(t *testing.T) {
+	tests := []struct {
+		q        float64
+		expected string
+	}{
+		{0.95, "quantile_over_time(0.95, (x)[1d:])"},
+		{0.999, "quantile_over_time(0.999, (x)[1d:])"},
+		{0.5, "quantile_over_time(0.5, (x)[1d:])"},
+		{1, "quantile_over_time(1.0, (x)[1d:])"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, QuantileOverTime(tt.q, "x", 24*time.Hour))
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		in       time.Duration
+		expected string
+	}{
+		{30 * time.Second, "30s"},
+		{5 * time.Minute, "5m"},
+		{2 * time.Hour, "2h"},
+		{7 * 24 * time.Hour, "7d"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Duration(tt.in))
+	}
+}
@@ -0,0 +1,117 @@
+// Package querylog records the PromQL queries and Kubernetes API requests
+// an analysis executes, with timings and row counts, so a report can carry
+// the evidence needed to re-run them by hand.
+package querylog
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of recorded requests.
+const (
+	KindRange      = "promql_range"
+	KindInstant    = "promql_instant"
+	KindKubernetes = "kubernetes"
+)
+
+// Entry is one executed query or API request.
+type Entry struct {
+	Kind       string     `json:"kind"`
+	Query      string     `json:"query"`           // PromQL as sent, or "GET /api/v1/pods?..."
+	Start      *time.Time `json:"start,omitempty"` // range queries
+	End        *time.Time `json:"end,omitempty"`
+	Step       string     `json:"step,omitempty"`
+	Time       *time.Time `json:"time,omitempty"` // instant queries
+	StartedAt  time.Time  `json:"started_at"`
+	DurationMs int64      `json:"duration_ms"`
+	Rows       int        `json:"rows"` // series (range), samples (instant), items (list)
+	Error      string     `json:"error,omitempty"`
+}
+
+// Report is the query log attached to an exported report.
+type Report struct {
+	PrometheusURL      string  `json:"prometheus_url,omitempty"`
+	PromQLQueries      int     `json:"promql_queries"`
+	KubernetesRequests int     `json:"kubernetes_requests"`
+	TotalDurationMs    int64   `json:"total_duration_ms"`
+	Entries            []Entry `json:"entries"`
+}
+
+// Log collects entries from concurrent queries. A nil *Log records nothing,
+// so callers need not check whether --include-queries is set.
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// New returns an empty log.
+func New() *Log {
+	return &Log{}
+}
+
+// RecordRange records a PromQL range query that began at began.
+func (l *Log) RecordRange(query string, start, end time.Time, step time.Duration, began time.Time, rows int, err error) {
+	if l == nil {
+		return
+	}
+	e := newEntry(KindRange, query, began, rows, err)
+	e.Start, e.End, e.Step = &start, &end, step.String()
+	l.record(e)
+}
+
+// RecordInstant records a PromQL instant query evaluated at ts.
+func (l *Log) RecordInstant(query string, ts, began time.Time, rows int, err error) {
+	if l == nil {
+		return
+	}
+	e := newEntry(KindInstant, query, began, rows, err)
+	e.Time = &ts
+	l.record(e)
+}
+
+func newEntry(kind, query string, began time.Time, rows int, err error) Entry {
+	e := Entry{
+		Kind:       kind,
+		Query:      query,
+		StartedAt:  began,
+		DurationMs: time.Since(began).Milliseconds(),
+		Rows:       rows,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+func (l *Log) record(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+}
+
+// Report returns the entries in the order they started, or nil for a nil
+// log. prometheusURL is the endpoint the PromQL entries ran against.
+func (l *Log) Report(prometheusURL string) *Report {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	entries := append([]Entry(nil), l.entries...)
+	l.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedAt.Before(entries[j].StartedAt)
+	})
+	r := &Report{PrometheusURL: prometheusURL, Entries: entries}
+	for i := range entries {
+		if entries[i].Kind == KindKubernetes {
+			r.KubernetesRequests++
+		} else {
+			r.PromQLQueries++
+		}
+		r.TotalDurationMs += entries[i].DurationMs
+	}
+	return r
+}
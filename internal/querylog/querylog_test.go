@@ -0,0 +1,120 @@
+package querylog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_NilIsNoop(t *testing.T) {
+	var l *Log
+	l.RecordRange("up", time.Now(), time.Now(), time.Minute, time.Now(), 1, nil)
+	l.RecordInstant("up", time.Now(), time.Now(), 1, nil)
+	assert.Nil(t, l.Report("http://prom:9090"))
+
+	rt := http.DefaultTransport
+	assert.Equal(t, rt, l.WrapTransport(rt))
+}
+
+func TestLog_Report(t *testing.T) {
+	l := New()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l.RecordInstant("count(up)", base, base.Add(time.Second), 4, nil)
+	l.RecordRange("rate(x[5m])", base.Add(-time.Hour), base, time.Minute, base, 2, errors.New("timeout"))
+	l.record(Entry{Kind: KindKubernetes, Query: "GET /api/v1/pods", StartedAt: base.Add(2 * time.Second), DurationMs: 5})
+
+	r := l.Report("http://prom:9090")
+	require.NotNil(t, r)
+	assert.Equal(t, 2, r.PromQLQueries)
+	assert.Equal(t, 1, r.KubernetesRequests)
+	require.Len(t, r.Entries, 3)
+	assert.Equal(t, "rate(x[5m])", r.Entries[0].Query, "entries are ordered by start time")
+	assert.Equal(t, "timeout", r.Entries[0].Error)
+	assert.Equal(t, "1m0s", r.Entries[0].Step)
+	assert.Equal(t, KindInstant, r.Entries[1].Kind)
+	assert.Equal(t, 4, r.Entries[1].Rows)
+}
+
+func TestWrapTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/pods":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"kind":"PodList","items":[{},{},{}]}`))
+		case "/api/v1/namespaces/default":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"kind":"Namespace"}`))
+		default:
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	l := New()
+	client := &http.Client{Transport: l.WrapTransport(http.DefaultTransport)}
+	for _, path := range []string{"/api/v1/pods?limit=500", "/api/v1/namespaces/default", "/apis/secret"} {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	r := l.Report("")
+	require.Len(t, r.Entries, 3)
+	assert.Equal(t, 3, r.KubernetesRequests)
+	assert.Equal(t, "GET /api/v1/pods?limit=500", r.Entries[0].Query)
+	assert.Equal(t, 3, r.Entries[0].Rows)
+	assert.Equal(t, 1, r.Entries[1].Rows)
+	assert.Equal(t, "403 Forbidden", r.Entries[2].Error)
+}
+
+func TestWrapTransport_BodyStillReadable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[]}`))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: New().WrapTransport(http.DefaultTransport)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	buf := make([]byte, 64)
+	n, _ := resp.Body.Read(buf)
+	assert.Equal(t, `{"items":[]}`, string(buf[:n]))
+}
+
+func TestCommand(t *testing.T) {
+	start := time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	assert.Equal(t,
+		"promtool query range --start=2026-03-01T11:00:00Z --end=2026-03-01T12:00:00Z --step=1m0s 'http://prom:9090' 'sum(x{a='\\''b'\\''})'",
+		Command(&Entry{Kind: KindRange, Query: "sum(x{a='b'})", Start: &start, End: &end, Step: "1m0s"}, "http://prom:9090"))
+	assert.Equal(t,
+		"promtool query instant --time=2026-03-01T12:00:00Z 'http://prom:9090' 'up'",
+		Command(&Entry{Kind: KindInstant, Query: "up", Time: &end}, "http://prom:9090"))
+	assert.Equal(t, "kubectl get --raw '/api/v1/pods?limit=500'",
+		Command(&Entry{Kind: KindKubernetes, Query: "GET /api/v1/pods?limit=500"}, ""))
+	assert.Empty(t, Command(&Entry{Kind: KindKubernetes, Query: "POST /apis/x"}, ""))
+}
+
+func TestRender(t *testing.T) {
+	assert.Empty(t, Render(nil))
+
+	out := Render(&Report{
+		PrometheusURL:      "http://prom:9090",
+		PromQLQueries:      0,
+		KubernetesRequests: 1,
+		TotalDurationMs:    7,
+		Entries:            []Entry{{Kind: KindKubernetes, Query: "GET /api/v1/nodes", DurationMs: 7, Rows: 2, Error: "boom"}},
+	})
+	assert.Contains(t, out, "=== Query Log (0 PromQL queries, 1 Kubernetes requests, 7ms total) ===")
+	assert.Contains(t, out, "   1. kubernetes  7ms  2 rows  error: boom")
+	assert.Contains(t, out, "$ kubectl get --raw '/api/v1/nodes'")
+}
@@ -0,0 +1,63 @@
+package querylog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Command returns a shell command that re-runs e: promtool for PromQL
+// against prometheusURL, kubectl get --raw for Kubernetes API requests.
+func Command(e *Entry, prometheusURL string) string {
+	switch e.Kind {
+	case KindRange:
+		return fmt.Sprintf("promtool query range --start=%s --end=%s --step=%s %s %s",
+			formatTime(e.Start), formatTime(e.End), e.Step, shellQuote(prometheusURL), shellQuote(e.Query))
+	case KindInstant:
+		return fmt.Sprintf("promtool query instant --time=%s %s %s",
+			formatTime(e.Time), shellQuote(prometheusURL), shellQuote(e.Query))
+	case KindKubernetes:
+		if path, ok := strings.CutPrefix(e.Query, "GET "); ok {
+			return "kubectl get --raw " + shellQuote(path)
+		}
+	}
+	return ""
+}
+
+// Render formats the log as a text section for table and markdown output.
+func Render(r *Report) string {
+	if r == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== Query Log (%d PromQL queries, %d Kubernetes requests, %dms total) ===\n\n",
+		r.PromQLQueries, r.KubernetesRequests, r.TotalDurationMs)
+	if r.PrometheusURL != "" {
+		fmt.Fprintf(&b, "Prometheus: %s\n\n", r.PrometheusURL)
+	}
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		fmt.Fprintf(&b, "%4d. %s  %dms  %d rows", i+1, e.Kind, e.DurationMs, e.Rows)
+		if e.Error != "" {
+			fmt.Fprintf(&b, "  error: %s", e.Error)
+		}
+		b.WriteString("\n")
+		if cmd := Command(e, r.PrometheusURL); cmd != "" {
+			fmt.Fprintf(&b, "      $ %s\n", cmd)
+		} else {
+			fmt.Fprintf(&b, "      %s\n", e.Query)
+		}
+	}
+	return b.String()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,88 @@
+package querylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// WrapTransport returns a RoundTripper that records every Kubernetes API
+// request sent through next, counting the items of JSON list responses.
+// It has the signature of rest.Config.WrapTransport.
+func (l *Log) WrapTransport(next http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		return next
+	}
+	return &transport{log: l, next: next}
+}
+
+type transport struct {
+	log  *Log
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	began := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.log.record(newKubeEntry(req, began, 0, err))
+		return nil, err
+	}
+
+	rows := 0
+	var status error
+	switch {
+	case resp.StatusCode >= http.StatusBadRequest:
+		status = httpError(resp.Status)
+	case countable(req, resp):
+		if rows, err = countItems(resp); err != nil {
+			t.log.record(newKubeEntry(req, began, 0, err))
+			return nil, err
+		}
+	}
+	t.log.record(newKubeEntry(req, began, rows, status))
+	return resp, nil
+}
+
+func newKubeEntry(req *http.Request, began time.Time, rows int, err error) Entry {
+	return newEntry(KindKubernetes, req.Method+" "+req.URL.RequestURI(), began, rows, err)
+}
+
+type httpError string
+
+func (e httpError) Error() string { return string(e) }
+
+// countable reports whether the response is a complete JSON document worth
+// counting: not a watch or a log stream.
+func countable(req *http.Request, resp *http.Response) bool {
+	if req.Method != http.MethodGet || req.URL.Query().Get("watch") == "true" || resp.Body == nil {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// countItems reads the body, counts the items of a list (1 for a single
+// object), and restores the body for the caller.
+func countItems(resp *http.Response) (int, error) {
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return 0, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	var list struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if json.Unmarshal(data, &list) != nil {
+		return 0, nil
+	}
+	if list.Items != nil {
+		return len(list.Items), nil
+	}
+	return 1, nil
+}
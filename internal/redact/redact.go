@@ -0,0 +1,157 @@
+// Package redact masks secrets and personal data in free text (pod logs,
+// event and condition messages) before snapshots are sent to an LLM
+// endpoint. Rules are regular expressions, some keyed on the name of the
+// value (password=..., "apiKey": ...); profiles pick how aggressive they are.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Profile selects the redaction rules.
+type Profile string
+
+// ProfileOff disables redaction, ProfileStandard masks credentials, tokens,
+// connection-string passwords, and emails, and ProfileStrict additionally
+// masks every env var value, IP addresses, and long opaque strings.
+const (
+	ProfileOff      Profile = "off"
+	ProfileStandard Profile = "standard"
+	ProfileStrict   Profile = "strict"
+)
+
+// ParseProfile validates a --redact-profile value.
+func ParseProfile(s string) (Profile, error) {
+	switch p := Profile(strings.ToLower(s)); p {
+	case ProfileOff, ProfileStandard, ProfileStrict:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid redact profile %q (use strict, standard, or off)", s)
+	}
+}
+
+// rule masks its matches. With a "secret" capture group, only that group is
+// replaced and the rest of the match (e.g. the key name) is kept.
+type rule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Standard rules run first, in this order: whole-token rules before the
+// key-name rule, so "Authorization: Bearer <token>" keeps its scheme.
+var standardRules = []rule{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"connection-string", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^:/@\s]+:(?P<secret>[^@\s/]+)@`)},
+	{"token", regexp.MustCompile(`(?i)\b(?:bearer|basic)\s+(?P<secret>[A-Za-z0-9._~+/=-]{8,})`)},
+	{"token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}`)},
+	{"token", regexp.MustCompile(`\b(?:AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|xox[abprs]-[A-Za-z0-9-]{10,}|sk-[A-Za-z0-9_-]{20,}|AIza[0-9A-Za-z_-]{35})\b`)},
+	{"secret", regexp.MustCompile(`(?i)[A-Za-z0-9_.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?|auth)[A-Za-z0-9_.-]*"?\s*[:=]\s*"?(?P<secret>[^\s"',;&}]+)`)},
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+}
+
+var strictRules = []rule{
+	{"env-value", regexp.MustCompile(`\b[A-Z][A-Z0-9_]{2,}=(?P<secret>[^\s"']+)`)},
+	{"ip-address", regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)},
+	{"opaque", regexp.MustCompile(`\b(?:[a-fA-F0-9]{32,}|[A-Za-z0-9+/]{40,}={0,2})`)},
+}
+
+// Redactor masks matches of its profile's rules and any custom patterns.
+type Redactor struct {
+	profile Profile
+	rules   []rule
+}
+
+// New returns a redactor for the profile, plus custom regular expressions
+// whose whole matches are masked. It returns nil for ProfileOff without
+// custom patterns.
+func New(profile Profile, patterns []string) (*Redactor, error) {
+	r := &Redactor{profile: profile}
+	switch profile {
+	case ProfileStrict:
+		r.rules = append(append(r.rules, standardRules...), strictRules...)
+	case ProfileStandard:
+		r.rules = append(r.rules, standardRules...)
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.rules = append(r.rules, rule{"custom", re})
+	}
+	if len(r.rules) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// Summary counts what was masked, per rule. It is recorded in report
+// metadata so readers know the model saw redacted input.
+type Summary struct {
+	Profile    string         `json:"profile"`
+	Redactions int            `json:"redactions"`
+	ByRule     map[string]int `json:"byRule,omitempty"`
+}
+
+// NewSummary returns an empty summary for the redactor's profile.
+func (r *Redactor) NewSummary() *Summary {
+	return &Summary{Profile: string(r.profile), ByRule: map[string]int{}}
+}
+
+// String describes the summary in one line, e.g. "12 values (secret 9, email 3)".
+func (s *Summary) String() string {
+	names := make([]string, 0, len(s.ByRule))
+	for name := range s.ByRule {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %d", name, s.ByRule[name]))
+	}
+	return fmt.Sprintf("%d values (%s)", s.Redactions, strings.Join(parts, ", "))
+}
+
+// Redact masks the text, counting the masked values in summary.
+func (r *Redactor) Redact(text string, summary *Summary) string {
+	if text == "" {
+		return text
+	}
+	for i := range r.rules {
+		text = r.rules[i].apply(text, summary)
+	}
+	return text
+}
+
+func (ru *rule) apply(text string, summary *Summary) string {
+	matches := ru.re.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+	secret := ru.re.SubexpIndex("secret")
+	marker := "[REDACTED:" + ru.name + "]"
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if secret > 0 && m[2*secret] >= 0 {
+			start, end = m[2*secret], m[2*secret+1]
+		}
+		value := text[start:end]
+		// Leave values an earlier rule masked, and auth schemes, alone
+		if strings.HasPrefix(value, "[REDACTED") || strings.EqualFold(value, "bearer") || strings.EqualFold(value, "basic") {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(marker)
+		last = end
+		summary.Redactions++
+		summary.ByRule[ru.name]++
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
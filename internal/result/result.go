@@ -61,14 +61,17 @@ type TeamleadResult struct {
 
 // ComplianceResult represents the prompt result for compliance mode.
 type ComplianceResult struct {
-	Issues []struct {
-		Namespace      string `json:"namespace"`
-		Name           string `json:"name"`
-		Type           string `json:"type"`
-		Severity       string `json:"severity"`
-		Description    string `json:"description"`
-		Recommendation string `json:"recommendation"`
-	} `json:"issues"`
+	Issues []ComplianceIssue `json:"issues"`
+}
+
+// ComplianceIssue is a single compliance finding, from the LLM or a deterministic audit.
+type ComplianceIssue struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Severity       string `json:"severity"`
+	Description    string `json:"description"`
+	Recommendation string `json:"recommendation"`
 }
 
 // ChaosResult represents the prompt result for chaos mode.
@@ -82,6 +85,52 @@ type ChaosResult struct {
 	ImpactNotes []string `json:"impact_notes"`
 }
 
+// SecurityResult represents the prompt result for security mode: a
+// hardening report with findings ordered by priority.
+type SecurityResult struct {
+	Findings []SecurityFinding `json:"findings"`
+	Summary  string            `json:"summary"`
+}
+
+// SecurityFinding is one hardening recommendation.
+type SecurityFinding struct {
+	Priority       int    `json:"priority"`
+	Severity       string `json:"severity"` // critical|high|medium|low
+	Category       string `json:"category"` // rbac|pod-security|network|other
+	Namespace      string `json:"namespace,omitempty"`
+	Resource       string `json:"resource"`
+	Issue          string `json:"issue"`
+	Recommendation string `json:"recommendation"`
+}
+
+// CapacityResult represents the prompt result for capacity mode: a
+// capacity-planning narrative over a requests-skew report.
+type CapacityResult struct {
+	Headroom struct {
+		CPU     string `json:"cpu"`
+		Memory  string `json:"memory"`
+		Outlook string `json:"outlook"`
+	} `json:"headroom"`
+	Consolidation []CapacityOpportunity `json:"consolidation"`
+	NodePools     []NodePoolAdvice      `json:"nodePools"`
+	Risks         []string              `json:"risks"`
+	Summary       string                `json:"summary"`
+}
+
+// CapacityOpportunity is a right-sizing or consolidation opportunity.
+type CapacityOpportunity struct {
+	Target  string `json:"target"`
+	Action  string `json:"action"`
+	Savings string `json:"savings"`
+}
+
+// NodePoolAdvice is the recommendation for one node pool.
+type NodePoolAdvice struct {
+	Pool           string `json:"pool"`
+	Recommendation string `json:"recommendation"`
+	Reason         string `json:"reason"`
+}
+
 // DefaultResult represents the prompt result for default mode.
 type DefaultResult struct {
 	Summary struct {
@@ -298,6 +347,85 @@ func RenderChaosHuman(w io.Writer, r *ChaosResult) error {
 	return ew.err
 }
 
+// RenderSecurityHuman renders security-mode results in a human-readable format.
+func RenderSecurityHuman(w io.Writer, r *SecurityResult) error {
+	ew := errWriter{w: w}
+
+	if len(r.Findings) == 0 {
+		ew.fprintln("Security: no hardening findings.")
+		if r.Summary != "" {
+			ew.fprintf("\n%s\n", r.Summary)
+		}
+		return ew.err
+	}
+
+	ew.fprintln("===== SECURITY HARDENING REPORT =====")
+	for _, f := range r.Findings {
+		ew.fprintln("──────────────────────────────")
+		ew.fprintf("Priority:     %d\n", f.Priority)
+		ew.fprintf("Severity:     %s\n", f.Severity)
+		ew.fprintf("Category:     %s\n", f.Category)
+		if f.Namespace != "" {
+			ew.fprintf("Namespace:    %s\n", f.Namespace)
+		}
+		ew.fprintf("Resource:     %s\n\n", f.Resource)
+		ew.fprintf("Issue:        %s\n", f.Issue)
+		ew.fprintf("Recommendation:\n  %s\n", f.Recommendation)
+	}
+
+	if r.Summary != "" {
+		ew.fprintf("\nSummary:\n  %s\n", r.Summary)
+	}
+
+	return ew.err
+}
+
+// RenderCapacityHuman renders capacity-mode results in a human-readable format.
+func RenderCapacityHuman(w io.Writer, r *CapacityResult) error {
+	ew := errWriter{w: w}
+
+	ew.fprintln("===== CAPACITY PLAN =====")
+	if r.Summary != "" {
+		ew.fprintf("%s\n", r.Summary)
+	}
+
+	ew.fprintln("\nHeadroom:")
+	ew.fprintf("  CPU:     %s\n", r.Headroom.CPU)
+	ew.fprintf("  Memory:  %s\n", r.Headroom.Memory)
+	ew.fprintf("  Outlook: %s\n", r.Headroom.Outlook)
+
+	if len(r.Consolidation) > 0 {
+		ew.fprintln("\nConsolidation opportunities:")
+		for _, o := range r.Consolidation {
+			ew.fprintln("────────────────────────")
+			ew.fprintf("Target:  %s\n", o.Target)
+			ew.fprintf("Action:  %s\n", o.Action)
+			if o.Savings != "" {
+				ew.fprintf("Savings: %s\n", o.Savings)
+			}
+		}
+	}
+
+	if len(r.NodePools) > 0 {
+		ew.fprintln("\nNode pools:")
+		for _, p := range r.NodePools {
+			ew.fprintf("  - %s: %s\n", p.Pool, p.Recommendation)
+			if p.Reason != "" {
+				ew.fprintf("    %s\n", p.Reason)
+			}
+		}
+	}
+
+	if len(r.Risks) > 0 {
+		ew.fprintln("\nRisks:")
+		for _, risk := range r.Risks {
+			ew.fprintf("  - %s\n", risk)
+		}
+	}
+
+	return ew.err
+}
+
 // RenderDefaultHuman renders default-mode results in a human-readable format.
 func RenderDefaultHuman(w io.Writer, r *DefaultResult) error {
 	ew := errWriter{w: w}
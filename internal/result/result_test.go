@@ -106,14 +106,7 @@ func TestRenderTeamleadHuman(t *testing.T) {
 func TestRenderComplianceHuman(t *testing.T) {
 	var buf bytes.Buffer
 	r := &ComplianceResult{
-		Issues: []struct {
-			Namespace      string `json:"namespace"`
-			Name           string `json:"name"`
-			Type           string `json:"type"`
-			Severity       string `json:"severity"`
-			Description    string `json:"description"`
-			Recommendation string `json:"recommendation"`
-		}{
+		Issues: []ComplianceIssue{
 			{
 				Namespace:      "default",
 				Name:           "api",
@@ -155,6 +148,50 @@ func TestRenderChaosHuman(t *testing.T) {
 	assert.Contains(t, out, "expect brief blip")
 }
 
+func TestRenderSecurityHuman(t *testing.T) {
+	var buf bytes.Buffer
+	r := &SecurityResult{
+		Findings: []SecurityFinding{{
+			Priority:       1,
+			Severity:       "critical",
+			Category:       "rbac",
+			Resource:       "ClusterRoleBinding/ci",
+			Issue:          "ci/runner is bound to a wildcard ClusterRole",
+			Recommendation: "grant a namespaced Role with the verbs the pipeline uses",
+		}},
+		Summary: "one critical RBAC finding",
+	}
+	require.NoError(t, RenderSecurityHuman(&buf, r))
+	out := buf.String()
+	assert.Contains(t, out, "SECURITY HARDENING REPORT")
+	assert.Contains(t, out, "ClusterRoleBinding/ci")
+	assert.NotContains(t, out, "Namespace:")
+	assert.Contains(t, out, "one critical RBAC finding")
+
+	buf.Reset()
+	require.NoError(t, RenderSecurityHuman(&buf, &SecurityResult{}))
+	assert.Contains(t, buf.String(), "no hardening findings")
+}
+
+func TestRenderCapacityHuman(t *testing.T) {
+	var buf bytes.Buffer
+	r := &CapacityResult{
+		Consolidation: []CapacityOpportunity{{Target: "namespace shop", Action: "halve api requests", Savings: "6 cores, $300/month"}},
+		NodePools:     []NodePoolAdvice{{Pool: "general", Recommendation: "shrink from 10 to 7 nodes", Reason: "38% of CPU requested"}},
+		Risks:         []string{"db/pg was OOM killed twice"},
+		Summary:       "The cluster is mostly idle.",
+	}
+	r.Headroom.CPU = "36% requested, 27% used at p95"
+	require.NoError(t, RenderCapacityHuman(&buf, r))
+	out := buf.String()
+	assert.Contains(t, out, "CAPACITY PLAN")
+	assert.Contains(t, out, "The cluster is mostly idle.")
+	assert.Contains(t, out, "36% requested")
+	assert.Contains(t, out, "6 cores, $300/month")
+	assert.Contains(t, out, "general: shrink from 10 to 7 nodes")
+	assert.Contains(t, out, "OOM killed twice")
+}
+
 func TestRenderDefaultHuman(t *testing.T) {
 	var buf bytes.Buffer
 	r := &DefaultResult{}
@@ -0,0 +1,287 @@
+// Package rollup merges per-cluster requests-skew reports into a fleet summary.
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+)
+
+// ClusterReport is one requests-skew JSON export and the cluster it describes.
+type ClusterReport struct {
+	Cluster string
+	Source  string // file the report was read from
+	Result  *analyzer.RequestsSkewResult
+}
+
+// ClusterSummary compares one cluster against the rest of the fleet.
+type ClusterSummary struct {
+	Cluster           string    `json:"cluster"`
+	Source            string    `json:"source"`
+	GeneratedAt       time.Time `json:"generated_at"`
+	Window            string    `json:"window"`
+	AnalyzedWorkloads int       `json:"analyzed_workloads"`
+	AvgSkewCPU        float64   `json:"avg_skew_cpu"`
+	AvgSkewMemory     float64   `json:"avg_skew_memory"`
+	WastedCPU         float64   `json:"wasted_cpu"`
+	WastedMemoryGi    float64   `json:"wasted_memory_gi"`
+	WastedMonthly     float64   `json:"wasted_monthly"`
+	CurrentMonthly    float64   `json:"current_monthly"`
+	Priced            bool      `json:"priced"`
+	WasteShare        float64   `json:"waste_share_percent"` // share of fleet monthly waste (CPU waste unless every report is priced)
+}
+
+// Offender is an over-provisioned workload ranked across the whole fleet.
+type Offender struct {
+	Cluster           string  `json:"cluster"`
+	Namespace         string  `json:"namespace"`
+	Workload          string  `json:"workload"`
+	Type              string  `json:"type"`
+	RequestedCPU      float64 `json:"requested_cpu"`
+	P95UsedCPU        float64 `json:"p95_used_cpu"`
+	RequestedMemoryGi float64 `json:"requested_memory_gi"`
+	P95UsedMemoryGi   float64 `json:"p95_used_memory_gi"`
+	SkewCPU           float64 `json:"skew_cpu"`
+	SkewMemory        float64 `json:"skew_memory"`
+	ImpactScore       float64 `json:"impact_score"`
+	WastedMonthly     float64 `json:"wasted_monthly"`
+	Safety            string  `json:"safety,omitempty"`
+}
+
+// Fleet is the merged summary of several cluster reports.
+type Fleet struct {
+	GeneratedAt         time.Time        `json:"generated_at"`
+	Clusters            []ClusterSummary `json:"clusters"`
+	AnalyzedWorkloads   int              `json:"analyzed_workloads"`
+	TotalWastedCPU      float64          `json:"total_wasted_cpu"`
+	TotalWastedMemoryGi float64          `json:"total_wasted_memory_gi"`
+	TotalWastedMonthly  float64          `json:"total_wasted_monthly"`
+	TotalCurrentMonthly float64          `json:"total_current_monthly"`
+	SavingsPercent      float64          `json:"savings_percent"`
+	TopOffenders        []Offender       `json:"top_offenders"`
+	Unpriced            []string         `json:"unpriced_clusters,omitempty"`  // clusters exported without cost estimates
+	Superseded          []string         `json:"superseded_reports,omitempty"` // older reports of a cluster that appears twice
+	MixedWindows        bool             `json:"mixed_windows,omitempty"`
+}
+
+// ExpandPaths resolves glob patterns (for shells that do not expand them)
+// and returns the matching files in argument order without duplicates.
+func ExpandPaths(args []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no reports match %q", arg)
+			}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				paths = append(paths, m)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// LoadReports reads requests-skew JSON exports. The cluster name comes from
+// the report metadata, falling back to the file name for reports exported
+// without one.
+func LoadReports(paths []string) ([]ClusterReport, error) {
+	reports := make([]ClusterReport, 0, len(paths))
+	for _, path := range paths {
+		r, err := loadReport(path)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *r)
+	}
+	return reports, nil
+}
+
+func loadReport(path string) (*ClusterReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	// Baselines and trend snapshots share field names but carry no summary
+	var probe struct {
+		Metadata json.RawMessage `json:"metadata"`
+		Summary  json.RawMessage `json:"summary"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if probe.Metadata == nil || probe.Summary == nil {
+		return nil, fmt.Errorf("%s is not a requests-skew JSON report (export with --output json)", path)
+	}
+
+	var result analyzer.RequestsSkewResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	cluster := result.Metadata.Cluster
+	if cluster == "" || cluster == "unknown" {
+		cluster = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &ClusterReport{Cluster: cluster, Source: path, Result: &result}, nil
+}
+
+// Build merges the reports into a fleet summary with the top offenders
+// across all clusters (top <= 0 keeps every workload). When a cluster has
+// several reports only the most recent one counts.
+func Build(reports []ClusterReport, top int) *Fleet {
+	fleet := &Fleet{
+		GeneratedAt:  time.Now().UTC(),
+		Clusters:     []ClusterSummary{},
+		TopOffenders: []Offender{},
+	}
+
+	latest := make(map[string]ClusterReport)
+	for _, r := range reports {
+		prev, ok := latest[r.Cluster]
+		switch {
+		case !ok:
+			latest[r.Cluster] = r
+		case r.Result.Metadata.GeneratedAt.After(prev.Result.Metadata.GeneratedAt):
+			fleet.Superseded = append(fleet.Superseded, prev.Source)
+			latest[r.Cluster] = r
+		default:
+			fleet.Superseded = append(fleet.Superseded, r.Source)
+		}
+	}
+
+	windows := map[string]bool{}
+	for _, r := range latest {
+		cs := summarizeCluster(r)
+		fleet.Clusters = append(fleet.Clusters, cs)
+		fleet.AnalyzedWorkloads += cs.AnalyzedWorkloads
+		fleet.TotalWastedCPU += cs.WastedCPU
+		fleet.TotalWastedMemoryGi += cs.WastedMemoryGi
+		fleet.TotalWastedMonthly += cs.WastedMonthly
+		fleet.TotalCurrentMonthly += cs.CurrentMonthly
+		if !cs.Priced {
+			fleet.Unpriced = append(fleet.Unpriced, cs.Cluster)
+		}
+		windows[cs.Window] = true
+		fleet.TopOffenders = append(fleet.TopOffenders, offenders(r)...)
+	}
+	fleet.MixedWindows = len(windows) > 1
+	if fleet.TotalCurrentMonthly > 0 {
+		fleet.SavingsPercent = math.Round(fleet.TotalWastedMonthly/fleet.TotalCurrentMonthly*1000) / 10
+	}
+	fleet.TotalWastedMonthly = roundCents(fleet.TotalWastedMonthly)
+	fleet.TotalCurrentMonthly = roundCents(fleet.TotalCurrentMonthly)
+
+	for i := range fleet.Clusters {
+		c := &fleet.Clusters[i]
+		switch {
+		case fleet.TotalWastedMonthly > 0 && len(fleet.Unpriced) == 0:
+			c.WasteShare = math.Round(c.WastedMonthly/fleet.TotalWastedMonthly*1000) / 10
+		case fleet.TotalWastedCPU > 0:
+			c.WasteShare = math.Round(c.WastedCPU/fleet.TotalWastedCPU*1000) / 10
+		}
+	}
+	sort.Slice(fleet.Clusters, func(i, j int) bool {
+		a, b := fleet.Clusters[i], fleet.Clusters[j]
+		if a.WasteShare != b.WasteShare {
+			return a.WasteShare > b.WasteShare
+		}
+		return a.Cluster < b.Cluster
+	})
+
+	sort.Slice(fleet.TopOffenders, func(i, j int) bool {
+		a, b := fleet.TopOffenders[i], fleet.TopOffenders[j]
+		if a.WastedMonthly != b.WastedMonthly {
+			return a.WastedMonthly > b.WastedMonthly
+		}
+		if a.ImpactScore != b.ImpactScore {
+			return a.ImpactScore > b.ImpactScore
+		}
+		return a.Cluster+"/"+a.Namespace+"/"+a.Workload < b.Cluster+"/"+b.Namespace+"/"+b.Workload
+	})
+	if top > 0 && len(fleet.TopOffenders) > top {
+		fleet.TopOffenders = fleet.TopOffenders[:top]
+	}
+	sort.Strings(fleet.Unpriced)
+	sort.Strings(fleet.Superseded)
+	return fleet
+}
+
+func summarizeCluster(r ClusterReport) ClusterSummary {
+	res := r.Result
+	cs := ClusterSummary{
+		Cluster:           r.Cluster,
+		Source:            r.Source,
+		GeneratedAt:       res.Metadata.GeneratedAt,
+		Window:            res.Metadata.Window,
+		AnalyzedWorkloads: res.Summary.AnalyzedWorkloads,
+		AvgSkewCPU:        res.Summary.AvgSkewCPU,
+		AvgSkewMemory:     res.Summary.AvgSkewMemory,
+		WastedCPU:         res.Summary.TotalWastedCPU,
+		WastedMemoryGi:    res.Summary.TotalWastedMemoryGi,
+	}
+	if cs.AnalyzedWorkloads == 0 {
+		cs.AnalyzedWorkloads = len(res.Results)
+	}
+	if est := res.Summary.CostEstimate; est != nil {
+		cs.Priced = true
+		cs.WastedMonthly = est.TotalWastedMonthly
+		cs.CurrentMonthly = est.TotalCurrentMonthly
+	}
+	return cs
+}
+
+// offenders returns the over-provisioned workloads of a report. Reports
+// only carry their own top-N results, so export with --top 0 for full
+// coverage.
+func offenders(r ClusterReport) []Offender {
+	var out []Offender
+	for i := range r.Result.Results {
+		w := &r.Result.Results[i]
+		if w.RequestedCPU <= w.P95UsedCPU && w.RequestedMemoryGi <= w.P95UsedMemoryGi {
+			continue
+		}
+		o := Offender{
+			Cluster:           r.Cluster,
+			Namespace:         w.Namespace,
+			Workload:          w.Workload,
+			Type:              w.Type,
+			RequestedCPU:      w.RequestedCPU,
+			P95UsedCPU:        w.P95UsedCPU,
+			RequestedMemoryGi: w.RequestedMemoryGi,
+			P95UsedMemoryGi:   w.P95UsedMemoryGi,
+			SkewCPU:           w.SkewCPU,
+			SkewMemory:        w.SkewMemory,
+			ImpactScore:       w.ImpactScore,
+		}
+		if w.CostEstimate != nil {
+			o.WastedMonthly = w.CostEstimate.WastedMonthly
+		}
+		if w.Safety != nil {
+			o.Safety = string(w.Safety.Rating)
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,149 @@
+package rollup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/cost"
+	"github.com/ppiankov/kubenow/internal/models"
+)
+
+func writeReport(t *testing.T, dir, name string, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func skewReport(cluster string, at time.Time, wastedMonthly float64, workloads ...analyzer.WorkloadSkewAnalysis) *analyzer.RequestsSkewResult {
+	r := &analyzer.RequestsSkewResult{
+		Metadata: analyzer.RequestsSkewMetadata{Cluster: cluster, Window: "30d", GeneratedAt: at},
+		Summary:  analyzer.RequestsSkewSummary{AnalyzedWorkloads: len(workloads)},
+		Results:  workloads,
+	}
+	for _, w := range workloads {
+		r.Summary.TotalWastedCPU += w.RequestedCPU - w.P95UsedCPU
+		r.Summary.TotalWastedMemoryGi += w.RequestedMemoryGi - w.P95UsedMemoryGi
+	}
+	if wastedMonthly > 0 {
+		r.Summary.CostEstimate = &cost.SummaryCostEstimate{TotalWastedMonthly: wastedMonthly, TotalCurrentMonthly: wastedMonthly * 2}
+	}
+	return r
+}
+
+func workload(ns, name string, reqCPU, p95CPU, wastedMonthly float64) analyzer.WorkloadSkewAnalysis {
+	return analyzer.WorkloadSkewAnalysis{
+		Namespace: ns, Workload: name, Type: "Deployment",
+		RequestedCPU: reqCPU, P95UsedCPU: p95CPU, SkewCPU: reqCPU / p95CPU,
+		CostEstimate: &cost.WorkloadCostEstimate{WastedMonthly: wastedMonthly},
+	}
+}
+
+func TestLoadReports(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	named := writeReport(t, dir, "a.json", skewReport("prod-eu", now, 10))
+	unnamed := writeReport(t, dir, "staging-us.json", skewReport("unknown", now, 0))
+
+	reports, err := LoadReports([]string{named, unnamed})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, "prod-eu", reports[0].Cluster)
+	assert.Equal(t, "staging-us", reports[1].Cluster)
+}
+
+func TestLoadReports_RejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	bl := writeReport(t, dir, "baseline.json", map[string]any{"metadata": map[string]string{"window": "30d"}, "results": []any{}})
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte("{"), 0o600))
+
+	_, err := LoadReports([]string{bl})
+	assert.ErrorContains(t, err, "not a requests-skew JSON report")
+	_, err = LoadReports([]string{bad})
+	assert.ErrorContains(t, err, "failed to parse")
+	_, err = LoadReports([]string{filepath.Join(dir, "missing.json")})
+	assert.Error(t, err)
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	a := writeReport(t, dir, "a.json", map[string]any{})
+	b := writeReport(t, dir, "b.json", map[string]any{})
+
+	paths, err := ExpandPaths([]string{filepath.Join(dir, "*.json"), a})
+	require.NoError(t, err)
+	assert.Equal(t, []string{a, b}, paths)
+
+	_, err = ExpandPaths([]string{filepath.Join(dir, "*.yaml")})
+	assert.ErrorContains(t, err, "no reports match")
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Now()
+	risky := workload("payments", "api", 8, 1, 120)
+	risky.Safety = &models.SafetyAnalysis{Rating: models.SafetyRatingRisky}
+
+	reports := []ClusterReport{
+		{Cluster: "prod-eu", Source: "eu.json", Result: skewReport("prod-eu", now, 300,
+			risky, workload("web", "frontend", 2, 1, 40), workload("web", "tight", 1, 1, 0))},
+		{Cluster: "prod-us", Source: "us.json", Result: skewReport("prod-us", now, 100,
+			workload("batch", "etl", 4, 1, 60))},
+		{Cluster: "prod-us", Source: "us-old.json", Result: skewReport("prod-us", now.Add(-48*time.Hour), 999,
+			workload("batch", "etl", 40, 1, 900))},
+	}
+
+	fleet := Build(reports, 2)
+
+	require.Len(t, fleet.Clusters, 2)
+	assert.Equal(t, []string{"us-old.json"}, fleet.Superseded)
+	assert.Equal(t, 4, fleet.AnalyzedWorkloads)
+	assert.InDelta(t, 400, fleet.TotalWastedMonthly, 0.001)
+	assert.InDelta(t, 50, fleet.SavingsPercent, 0.001)
+	assert.InDelta(t, 7+1+3, fleet.TotalWastedCPU, 0.001)
+	assert.False(t, fleet.MixedWindows)
+	assert.Empty(t, fleet.Unpriced)
+
+	assert.Equal(t, "prod-eu", fleet.Clusters[0].Cluster)
+	assert.InDelta(t, 75, fleet.Clusters[0].WasteShare, 0.001)
+	assert.InDelta(t, 25, fleet.Clusters[1].WasteShare, 0.001)
+
+	require.Len(t, fleet.TopOffenders, 2)
+	assert.Equal(t, "api", fleet.TopOffenders[0].Workload)
+	assert.Equal(t, "RISKY", fleet.TopOffenders[0].Safety)
+	assert.Equal(t, "prod-us", fleet.TopOffenders[1].Cluster)
+	assert.Equal(t, "etl", fleet.TopOffenders[1].Workload)
+}
+
+func TestBuild_Unpriced(t *testing.T) {
+	now := time.Now()
+	unpriced := skewReport("dev", now, 0, workload("a", "x", 3, 1, 0))
+	unpriced.Metadata.Window = "7d"
+	for i := range unpriced.Results {
+		unpriced.Results[i].CostEstimate = nil
+	}
+	reports := []ClusterReport{
+		{Cluster: "dev", Source: "dev.json", Result: unpriced},
+		{Cluster: "prod", Source: "prod.json", Result: skewReport("prod", now, 50, workload("b", "y", 2, 1, 50))},
+	}
+
+	fleet := Build(reports, 0)
+
+	assert.Equal(t, []string{"dev"}, fleet.Unpriced)
+	assert.True(t, fleet.MixedWindows)
+	require.Len(t, fleet.Clusters, 2)
+	assert.Equal(t, "dev", fleet.Clusters[0].Cluster) // ranked by CPU waste when a report is unpriced
+	assert.InDelta(t, 66.7, fleet.Clusters[0].WasteShare, 0.001)
+	require.Len(t, fleet.TopOffenders, 2)
+	assert.Equal(t, "y", fleet.TopOffenders[0].Workload)
+	assert.Equal(t, "x", fleet.TopOffenders[1].Workload)
+}
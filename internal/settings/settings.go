@@ -0,0 +1,247 @@
+// Package settings layers command flag values from the kubenow config
+// file, named profiles in it, and KUBENOW_* environment variables, so
+// repeated flags such as --prometheus-url or --llm-endpoint need not be
+// passed on every run. Precedence, lowest first: file, profile,
+// environment, command line.
+package settings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes the environment variable of every flag, e.g.
+// KUBENOW_PROMETHEUS_URL for --prometheus-url.
+const EnvPrefix = "KUBENOW_"
+
+// EnvProfile selects a profile when --profile is not given.
+const EnvProfile = EnvPrefix + "PROFILE"
+
+// Reserved top-level keys; every other key is a flag value.
+const (
+	keyProfile  = "profile"  // default profile
+	keyProfiles = "profiles" // named profiles
+	keyCommands = "commands" // flag values for one command path
+)
+
+// skipFlags are never set from the file or environment.
+var skipFlags = []string{"config", "profile", "help", "version"}
+
+// File is a parsed config file. Top-level keys are flag values shared by
+// every command that has the flag; "commands" scopes values to a command
+// path ("analyze", "analyze requests-skew"); "profiles" holds named sets
+// of the same keys; "profile" names the default profile.
+type File struct {
+	Path   string
+	values map[string]any
+}
+
+// DefaultPath returns ~/.kubenow/config.yaml, or the older ~/.kubenow.yaml
+// when only that exists. The new path is returned when neither exists.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	path := filepath.Join(home, ".kubenow", "config.yaml")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		legacy := filepath.Join(home, ".kubenow.yaml")
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy, nil
+		}
+	}
+	return path, nil
+}
+
+// Load reads a config file. An empty file is valid.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	values := map[string]any{}
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	f := &File{Path: path, values: values}
+	if _, err := commandSections(f.values, ""); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if p, ok := values[keyProfiles]; ok {
+		if _, ok := p.(map[string]any); !ok {
+			return nil, fmt.Errorf("invalid config %s: %s must be a map of profile names", path, keyProfiles)
+		}
+	}
+	return f, nil
+}
+
+// DefaultProfile returns the profile named by the file's "profile" key.
+func (f *File) DefaultProfile() string {
+	s, ok := f.values[keyProfile].(string)
+	if !ok {
+		return ""
+	}
+	return s
+}
+
+// Profiles lists the profile names in the file.
+func (f *File) Profiles() []string {
+	profiles, ok := f.values[keyProfiles].(map[string]any)
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the keys of a named profile.
+func (f *File) Profile(name string) (map[string]any, error) {
+	profiles, ok := f.values[keyProfiles].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found: %s defines no profiles", name, f.Path)
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in %s (available: %s)", name, f.Path, strings.Join(f.Profiles(), ", "))
+	}
+	if p == nil {
+		return map[string]any{}, nil
+	}
+	values, ok := p.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profile %q in %s must be a map", name, f.Path)
+	}
+	return values, nil
+}
+
+// FlagValues returns the flag values for a command path (without the
+// binary name, e.g. "analyze requests-skew") under a profile ("" for
+// none). Later layers override earlier ones: top-level keys, then
+// commands sections from the least to the most specific path, then the
+// profile's keys and commands sections in the same order.
+func (f *File) FlagValues(profile, commandPath string) (map[string]any, error) {
+	layers := []map[string]any{f.values}
+	if profile != "" {
+		p, err := f.Profile(profile)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, p)
+	}
+
+	out := map[string]any{}
+	for _, layer := range layers {
+		sections, err := commandSections(layer, commandPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, section := range sections {
+			for k, v := range section {
+				if k == keyProfile || k == keyProfiles || k == keyCommands {
+					continue
+				}
+				out[k] = v
+			}
+		}
+	}
+	return out, nil
+}
+
+// commandSections returns a layer followed by its commands sections that apply
+// to commandPath, least specific first. It also checks their shape.
+func commandSections(layer map[string]any, commandPath string) ([]map[string]any, error) {
+	out := []map[string]any{layer}
+	raw, ok := layer[keyCommands]
+	if !ok || raw == nil {
+		return out, nil
+	}
+	commands, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a map of command paths", keyCommands)
+	}
+	for path, section := range commands {
+		if _, ok := section.(map[string]any); !ok && section != nil {
+			return nil, fmt.Errorf("%s.%s must be a map of flag values", keyCommands, path)
+		}
+	}
+	words := strings.Fields(commandPath)
+	for i := 1; i <= len(words); i++ {
+		if section, ok := commands[strings.Join(words[:i], " ")].(map[string]any); ok {
+			out = append(out, section)
+		}
+	}
+	return out, nil
+}
+
+// EnvName returns the environment variable for a flag.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// Apply sets every flag not given on the command line from its
+// environment variable or, failing that, from values. Keys that are not
+// flags of this command are ignored, so one file serves every command.
+// Flags set this way count as changed.
+func Apply(flags *pflag.FlagSet, values map[string]any, lookupEnv func(string) (string, bool)) error {
+	var errs []error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || flag.Deprecated != "" || slices.Contains(skipFlags, flag.Name) {
+			return
+		}
+		if env, ok := lookupEnv(EnvName(flag.Name)); ok && env != "" {
+			if err := flags.Set(flag.Name, env); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", EnvName(flag.Name), err))
+			}
+			return
+		}
+		v, ok := values[flag.Name]
+		if !ok || v == nil {
+			return
+		}
+		if err := setValue(flags, flag, v); err != nil {
+			errs = append(errs, fmt.Errorf("config key %q: %w", flag.Name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// setValue sets a flag from a YAML value: a scalar, or a list for slice
+// flags (or a comma-joined list for other flags).
+func setValue(flags *pflag.FlagSet, flag *pflag.Flag, v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		return fmt.Errorf("expected a value, got a map")
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			if _, ok := item.(map[string]any); ok {
+				return fmt.Errorf("expected a list of values, got a map")
+			}
+			items[i] = fmt.Sprint(item)
+		}
+		if sv, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := sv.Replace(items); err != nil {
+				return err
+			}
+			flag.Changed = true
+			return nil
+		}
+		return flags.Set(flag.Name, strings.Join(items, ","))
+	default:
+		return flags.Set(flag.Name, fmt.Sprint(v))
+	}
+}
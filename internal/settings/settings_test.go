@@ -0,0 +1,159 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+profile: staging
+prometheus-url: http://localhost:9090
+top: 10
+commands:
+  analyze:
+    window: 7d
+  analyze requests-skew:
+    top: 20
+    namespace-exclude: [kube-system, monitoring]
+keybindings:
+  monitor:
+    quit: [x]
+profiles:
+  staging:
+    context: staging
+  prod:
+    prometheus-url: https://prometheus.prod
+    commands:
+      analyze:
+        top: 50
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func testFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("prometheus-url", "", "")
+	flags.String("window", "30d", "")
+	flags.Int("top", 5, "")
+	flags.String("namespace-exclude", "", "")
+	flags.StringSlice("labels", nil, "")
+	flags.Bool("silent", false, "")
+	flags.String("config", "", "")
+	return flags
+}
+
+func noEnv(string) (string, bool) { return "", false }
+
+func TestFlagValues_Layers(t *testing.T) {
+	f, err := Load(writeConfig(t, testConfig))
+	require.NoError(t, err)
+	assert.Equal(t, "staging", f.DefaultProfile())
+	assert.Equal(t, []string{"prod", "staging"}, f.Profiles())
+
+	values, err := f.FlagValues("", "analyze requests-skew")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9090", values["prometheus-url"])
+	assert.Equal(t, "7d", values["window"])
+	assert.Equal(t, 20, values["top"])
+	assert.NotContains(t, values, "commands")
+	assert.NotContains(t, values, "profiles")
+
+	// The profile's commands section overrides the file's more specific one
+	values, err = f.FlagValues("prod", "analyze requests-skew")
+	require.NoError(t, err)
+	assert.Equal(t, "https://prometheus.prod", values["prometheus-url"])
+	assert.Equal(t, 50, values["top"])
+
+	values, err = f.FlagValues("prod", "monitor")
+	require.NoError(t, err)
+	assert.Equal(t, 10, values["top"])
+	assert.NotContains(t, values, "window")
+
+	_, err = f.FlagValues("nope", "monitor")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: prod, staging")
+}
+
+func TestApply(t *testing.T) {
+	f, err := Load(writeConfig(t, testConfig))
+	require.NoError(t, err)
+	values, err := f.FlagValues("", "analyze requests-skew")
+	require.NoError(t, err)
+	values["labels"] = []any{"a", "b"}
+	values["silent"] = true
+	values["config"] = "/elsewhere"
+
+	flags := testFlags()
+	require.NoError(t, flags.Parse([]string{"--window", "1d"}))
+	env := map[string]string{"KUBENOW_PROMETHEUS_URL": "http://env:9090"}
+	require.NoError(t, Apply(flags, values, func(k string) (string, bool) { v, ok := env[k]; return v, ok }))
+
+	get := func(name string) string { return flags.Lookup(name).Value.String() }
+	assert.Equal(t, "1d", get("window"), "command line wins")
+	assert.Equal(t, "http://env:9090", get("prometheus-url"), "environment beats the file")
+	assert.Equal(t, "20", get("top"))
+	assert.Equal(t, "kube-system,monitoring", get("namespace-exclude"))
+	assert.Equal(t, "[a,b]", get("labels"))
+	assert.Equal(t, "true", get("silent"))
+	assert.Empty(t, get("config"), "config is never read from the file")
+	assert.True(t, flags.Changed("top"))
+	assert.True(t, flags.Changed("labels"))
+}
+
+func TestApply_InvalidValue(t *testing.T) {
+	flags := testFlags()
+	err := Apply(flags, map[string]any{"top": "many", "window": map[string]any{"a": 1}}, noEnv)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `config key "top"`)
+	assert.Contains(t, err.Error(), `config key "window"`)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(writeConfig(t, "commands: [monitor]\n"))
+	assert.Error(t, err)
+
+	_, err = Load(writeConfig(t, "profiles: prod\n"))
+	assert.Error(t, err)
+
+	_, err = Load(writeConfig(t, "top: [\n"))
+	assert.Error(t, err)
+
+	f, err := Load(writeConfig(t, ""))
+	require.NoError(t, err)
+	assert.Empty(t, f.Profiles())
+}
+
+func TestDefaultPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path, err := DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".kubenow", "config.yaml"), path)
+
+	legacy := filepath.Join(home, ".kubenow.yaml")
+	require.NoError(t, os.WriteFile(legacy, nil, 0o600))
+	path, err = DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, legacy, path)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".kubenow"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".kubenow", "config.yaml"), nil, 0o600))
+	path, err = DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".kubenow", "config.yaml"), path)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "KUBENOW_PROMETHEUS_URL", EnvName("prometheus-url"))
+}
@@ -0,0 +1,495 @@
+// Package skewbrowser is the interactive browser for requests-skew results:
+// scroll, filter, and sort workloads, expand one for its details, and
+// export a selection.
+package skewbrowser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/keymap"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	cursorStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	okStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	warnStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+	boxStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
+)
+
+// reservedLines is the screen height taken by everything but the
+// workload list.
+const reservedLines = 10
+
+// ExportFunc writes a report and returns where it went.
+type ExportFunc func(result *analyzer.RequestsSkewResult) (string, error)
+
+// exportDoneMsg signals that an export finished.
+type exportDoneMsg struct {
+	workloads int
+	path      string
+	err       error
+}
+
+// Model is the browser's bubbletea model.
+type Model struct {
+	result   *analyzer.RequestsSkewResult
+	rows     []analyzer.WorkloadSkewAnalysis // result.Results in sortBy order
+	sortBy   string
+	selected map[string]bool // workloadKey
+	export   ExportFunc
+	keys     *keymap.Map
+
+	cursor int // index into visible()
+	offset int // first visible row
+	height int
+
+	expanded   bool
+	searchMode bool
+	filter     string
+	showHelp   bool
+	exporting  bool
+	status     string
+	statusErr  bool
+}
+
+// NewModel creates a browser over a requests-skew report, sorted by one of
+// analyzer.SortOptions. export writes the selected subset.
+func NewModel(result *analyzer.RequestsSkewResult, sortBy string, export ExportFunc) *Model {
+	if !slices.Contains(analyzer.SortOptions, sortBy) {
+		sortBy = analyzer.SortOptions[0]
+	}
+	m := &Model{
+		result:   result,
+		rows:     slices.Clone(result.Results),
+		sortBy:   sortBy,
+		selected: make(map[string]bool),
+		export:   export,
+		keys:     keymap.MustDefault(DefaultKeyBindings),
+	}
+	analyzer.SortWorkloads(m.rows, sortBy)
+	return m
+}
+
+// SetKeyMap replaces the default key bindings.
+func (m *Model) SetKeyMap(keys *keymap.Map) {
+	m.keys = keys
+}
+
+func workloadKey(w *analyzer.WorkloadSkewAnalysis) string {
+	return w.Namespace + "/" + w.Type + "/" + w.Workload
+}
+
+// visible returns the indexes of the rows whose namespace matches the filter.
+func (m *Model) visible() []int {
+	idx := make([]int, 0, len(m.rows))
+	for i := range m.rows {
+		if m.filter == "" || strings.Contains(m.rows[i].Namespace, m.filter) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// chosen returns the report restricted to the selected workloads, or to
+// the shown ones when none is selected, in the browser's sort order.
+func (m *Model) chosen() *analyzer.RequestsSkewResult {
+	keep := make(map[string]bool, len(m.selected))
+	for key, ok := range m.selected {
+		if ok {
+			keep[key] = true
+		}
+	}
+	if len(keep) == 0 {
+		for _, i := range m.visible() {
+			keep[workloadKey(&m.rows[i])] = true
+		}
+	}
+	subset := m.result.Subset(func(w *analyzer.WorkloadSkewAnalysis) bool { return keep[workloadKey(w)] })
+	analyzer.SortWorkloads(subset.Results, m.sortBy)
+	return subset
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.clampScroll()
+	case exportDoneMsg:
+		m.exporting = false
+		if msg.err != nil {
+			m.status, m.statusErr = fmt.Sprintf("Export failed: %v", msg.err), true
+		} else {
+			m.status, m.statusErr = fmt.Sprintf("Exported %d workload(s) to %s", msg.workloads, msg.path), false
+		}
+	case tea.KeyMsg:
+		key := msg.String()
+		if key == keymap.ForceQuit {
+			return m, tea.Quit
+		}
+		switch {
+		case m.searchMode:
+			m.handleSearchKey(msg)
+			return m, nil
+		case m.showHelp:
+			// Any key closes the help overlay; quit still quits.
+			m.showHelp = false
+			if m.keys.Action(key) != actionQuit {
+				return m, nil
+			}
+		}
+		return m.handleAction(m.keys.Action(key))
+	}
+	return m, nil
+}
+
+func (m *Model) handleSearchKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.searchMode = false
+		m.filter = ""
+	case tea.KeyEnter:
+		m.searchMode = false
+	case tea.KeyBackspace:
+		if m.filter != "" {
+			m.filter = m.filter[:len(m.filter)-1]
+		}
+	case tea.KeyRunes:
+		m.filter += string(msg.Runes)
+	}
+	m.cursor = 0
+	m.offset = 0
+}
+
+// handleAction runs a key binding's action.
+//
+//nolint:gocyclo // BubbleTea key dispatch
+func (m *Model) handleAction(action keymap.Action) (tea.Model, tea.Cmd) {
+	if action != "" && !m.exporting {
+		m.status = ""
+	}
+	visible := m.visible()
+	switch action {
+	case actionQuit:
+		return m, tea.Quit
+	case keymap.Help:
+		m.showHelp = true
+	case actionSearch:
+		m.searchMode = true
+		m.expanded = false
+	case actionClearFilter:
+		if m.expanded {
+			m.expanded = false
+		} else {
+			m.filter = ""
+			m.cursor = 0
+		}
+	case actionUp:
+		m.cursor = max(m.cursor-1, 0)
+	case actionDown:
+		m.cursor = max(min(m.cursor+1, len(visible)-1), 0)
+	case actionPageUp:
+		m.cursor = max(m.cursor-m.listHeight(), 0)
+	case actionPageDown:
+		m.cursor = max(min(m.cursor+m.listHeight(), len(visible)-1), 0)
+	case actionExpand:
+		m.expanded = !m.expanded && len(visible) > 0
+	case actionToggle:
+		if m.cursor < len(visible) {
+			key := workloadKey(&m.rows[visible[m.cursor]])
+			m.selected[key] = !m.selected[key]
+		}
+	case actionAll:
+		for _, i := range visible {
+			m.selected[workloadKey(&m.rows[i])] = true
+		}
+	case actionNone:
+		clear(m.selected)
+	case actionSort:
+		m.cycleSort()
+	case actionExport:
+		return m, m.startExport()
+	}
+	m.clampScroll()
+	return m, nil
+}
+
+// cycleSort re-sorts by the next of analyzer.SortOptions, keeping the
+// cursor on the same workload.
+func (m *Model) cycleSort() {
+	var current string
+	if visible := m.visible(); m.cursor < len(visible) {
+		current = workloadKey(&m.rows[visible[m.cursor]])
+	}
+	i := slices.Index(analyzer.SortOptions, m.sortBy)
+	m.sortBy = analyzer.SortOptions[(i+1)%len(analyzer.SortOptions)]
+	analyzer.SortWorkloads(m.rows, m.sortBy)
+	for row, idx := range m.visible() {
+		if workloadKey(&m.rows[idx]) == current {
+			m.cursor = row
+			break
+		}
+	}
+}
+
+func (m *Model) startExport() tea.Cmd {
+	if m.export == nil || m.exporting {
+		return nil
+	}
+	subset := m.chosen()
+	if len(subset.Results) == 0 {
+		m.status, m.statusErr = "nothing to export", true
+		return nil
+	}
+	m.exporting = true
+	m.status, m.statusErr = fmt.Sprintf("Exporting %d workload(s)...", len(subset.Results)), false
+	export := m.export
+	return func() tea.Msg {
+		path, err := export(subset)
+		return exportDoneMsg{workloads: len(subset.Results), path: path, err: err}
+	}
+}
+
+// listHeight is the number of workload rows that fit on screen.
+func (m *Model) listHeight() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-reservedLines, 3)
+}
+
+func (m *Model) clampScroll() {
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+}
+
+// View implements tea.Model.
+func (m *Model) View() string {
+	var b strings.Builder
+	r := m.result
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Requests-Skew · window %s · %d workloads · sorted by %s",
+		r.Metadata.Window, len(m.rows), m.sortBy)))
+	b.WriteString("\n\n")
+
+	if m.showHelp {
+		b.WriteString(titleStyle.Render("Key bindings"))
+		b.WriteString("\n")
+		for _, line := range m.keys.HelpLines() {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n" + dimStyle.Render("Remap in the keybindings.requests-skew section of ~/.kubenow/config.yaml. "+
+			"Press any key to close.") + "\n")
+		return boxStyle.Render(b.String())
+	}
+
+	visible := m.visible()
+	if m.expanded && m.cursor < len(visible) {
+		b.WriteString(m.renderDetails(&m.rows[visible[m.cursor]]))
+	} else {
+		m.renderList(&b, visible)
+	}
+
+	b.WriteString("\n")
+	selected := 0
+	for _, ok := range m.selected {
+		if ok {
+			selected++
+		}
+	}
+	summary := fmt.Sprintf("%d shown, %d selected", len(visible), selected)
+	if m.filter != "" {
+		summary += fmt.Sprintf(" · namespace filter %q", m.filter)
+	}
+	b.WriteString(summary + "\n")
+	if m.status != "" {
+		style := okStyle
+		if m.statusErr {
+			style = warnStyle
+		}
+		b.WriteString(style.Render(m.status) + "\n")
+	}
+	if m.searchMode {
+		b.WriteString("namespace/" + m.filter + "█\n")
+	} else {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("%s details · %s select · %s sort · %s filter · %s export · %s help · %s quit",
+			m.keys.Label(actionExpand), m.keys.Label(actionToggle), m.keys.Label(actionSort), m.keys.Label(actionSearch),
+			m.keys.Label(actionExport), m.keys.Label(keymap.Help), m.keys.Label(actionQuit))) + "\n")
+	}
+	return boxStyle.Render(b.String())
+}
+
+func (m *Model) renderList(b *strings.Builder, visible []int) {
+	if len(visible) == 0 {
+		b.WriteString(dimStyle.Render("  (no workloads)") + "\n")
+		return
+	}
+
+	nsWidth, nameWidth := len("NAMESPACE"), len("WORKLOAD")
+	for _, i := range visible {
+		nsWidth = max(nsWidth, len(m.rows[i].Namespace))
+		nameWidth = max(nameWidth, len(workloadName(&m.rows[i])))
+	}
+	header := fmt.Sprintf("    %-*s %-*s %9s %9s %7s %9s %9s %-10s %6s",
+		nsWidth, "NAMESPACE", nameWidth, "WORKLOAD", "REQ CPU", "P99 CPU", "SKEW", "REQ MEM", "P99 MEM", "SAFETY", "IMPACT")
+	b.WriteString(dimStyle.Render(header) + "\n")
+
+	end := min(m.offset+m.listHeight(), len(visible))
+	if m.offset > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ↑ %d more", m.offset)) + "\n")
+	}
+	for row := m.offset; row < end; row++ {
+		w := &m.rows[visible[row]]
+		line := fmt.Sprintf("%s %-*s %-*s %9s %9s %6.1fx %9s %9s %-10s %6.1f",
+			checkbox(m.selected[workloadKey(w)]), nsWidth, w.Namespace, nameWidth, workloadName(w),
+			units.Cores(w.RequestedCPU), units.Cores(w.P99UsedCPU), w.SkewCPU,
+			units.MemoryGi(w.RequestedMemoryGi*units.Gi), units.MemoryGi(w.P99UsedMemoryGi*units.Gi),
+			safetyRating(w), w.ImpactScore)
+		if row == m.cursor {
+			b.WriteString(cursorStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if end < len(visible) {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ↓ %d more", len(visible)-end)) + "\n")
+	}
+}
+
+// renderDetails shows a workload's percentiles, safety analysis, and
+// namespace quota context.
+func (m *Model) renderDetails(w *analyzer.WorkloadSkewAnalysis) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s/%s (%s)\n", checkbox(m.selected[workloadKey(w)]), w.Namespace, workloadName(w), w.Type)
+	if w.Runtime != "" {
+		fmt.Fprintf(&b, "Runtime: %s", w.Runtime)
+		if w.Runs > 0 {
+			fmt.Fprintf(&b, " · %d runs, active %s (%.0f%% of the window)", w.Runs, w.ActiveTime, w.ActiveFraction*100)
+		}
+		b.WriteString("\n")
+	}
+	if w.Team != "" {
+		fmt.Fprintf(&b, "Team: %s\n", w.Team)
+	}
+
+	b.WriteString("\n" + titleStyle.Render("Usage") + "\n")
+	fmt.Fprintf(&b, "  %-7s %9s %9s %9s %9s %9s %9s %9s\n", "", "REQUEST", "LIMIT", "AVG", "P95", "P99", "P99.9", "MAX")
+	fmt.Fprintf(&b, "  %-7s %9s %9s %9s %9s %9s %9s %9s\n", "CPU",
+		units.Cores(w.RequestedCPU), optional(w.LimitCPU, units.Cores), units.Cores(w.AvgUsedCPU), units.Cores(w.P95UsedCPU),
+		units.Cores(w.P99UsedCPU), units.Cores(w.P999UsedCPU), units.Cores(w.MaxUsedCPU))
+	gi := func(v float64) string { return units.MemoryGi(v * units.Gi) }
+	fmt.Fprintf(&b, "  %-7s %9s %9s %9s %9s %9s %9s %9s\n", "Memory",
+		gi(w.RequestedMemoryGi), optional(w.LimitMemoryGi, gi), gi(w.AvgUsedMemoryGi), gi(w.P95UsedMemoryGi),
+		gi(w.P99UsedMemoryGi), gi(w.P999UsedMemoryGi), gi(w.MaxUsedMemoryGi))
+	fmt.Fprintf(&b, "  Skew: CPU %.1fx, memory %.1fx", w.SkewCPU, w.SkewMemory)
+	if w.LimitSkewCPU > 0 || w.LimitSkewMemory > 0 {
+		fmt.Fprintf(&b, " · limit skew: CPU %.1fx, memory %.1fx", w.LimitSkewCPU, w.LimitSkewMemory)
+	}
+	fmt.Fprintf(&b, " · impact %.1f\n", w.ImpactScore)
+	if w.CostEstimate != nil {
+		fmt.Fprintf(&b, "  Cost: $%.2f/mo, $%.2f/mo wasted (%.0f%%)\n",
+			w.CostEstimate.CurrentMonthlyCost, w.CostEstimate.WastedMonthly, w.CostEstimate.SavingsPercent)
+	}
+
+	b.WriteString("\n" + titleStyle.Render("Safety") + "\n")
+	if s := w.Safety; s == nil {
+		b.WriteString(dimStyle.Render("  not analyzed") + "\n")
+	} else {
+		fmt.Fprintf(&b, "  %s · %d OOMKill(s), %d restart(s), %.1f%% CPU throttled", s.Rating, s.OOMKills, s.Restarts, s.CPUThrottledPercent)
+		if s.SafeMargin > 0 {
+			fmt.Fprintf(&b, " · safe margin %.1fx", s.SafeMargin)
+		}
+		b.WriteString("\n")
+		for _, warning := range s.Warnings {
+			b.WriteString(warnStyle.Render("  ⚠ "+warning) + "\n")
+		}
+		for _, reason := range s.Reasons {
+			b.WriteString("  • " + reason + "\n")
+		}
+	}
+
+	b.WriteString("\n" + titleStyle.Render("Quota") + "\n")
+	b.WriteString(m.renderQuota(w))
+
+	if w.Note != "" {
+		b.WriteString("\n" + w.Note + "\n")
+	}
+	if len(w.Instances) > 1 {
+		fmt.Fprintf(&b, "\nTemplate %s, %d instances: %s\n", w.Template, len(w.Instances), strings.Join(w.Instances, ", "))
+	}
+	return b.String()
+}
+
+func (m *Model) renderQuota(w *analyzer.WorkloadSkewAnalysis) string {
+	var b strings.Builder
+	if w.QuotaContext != "" {
+		b.WriteString("  " + w.QuotaContext + "\n")
+	}
+	if w.UsingDefaultRequests {
+		b.WriteString("  Requests come from LimitRange defaults, not the workload spec\n")
+	}
+	for i := range m.result.NamespaceQuotas {
+		q := &m.result.NamespaceQuotas[i]
+		if q.Namespace != w.Namespace || !q.HasResourceQuota {
+			continue
+		}
+		if q.QuotaCPU.Hard != "" {
+			fmt.Fprintf(&b, "  CPU quota: %s used / %s hard (%.0f%%)\n", q.QuotaCPU.Used, q.QuotaCPU.Hard, q.QuotaCPU.Utilization)
+		}
+		if q.QuotaMemory.Hard != "" {
+			fmt.Fprintf(&b, "  Memory quota: %s used / %s hard (%.0f%%)\n", q.QuotaMemory.Used, q.QuotaMemory.Hard, q.QuotaMemory.Utilization)
+		}
+	}
+	if b.Len() == 0 {
+		return dimStyle.Render("  no ResourceQuota or LimitRange defaults") + "\n"
+	}
+	return b.String()
+}
+
+// workloadName names a row, with the number of instances it stands for
+// when grouped by template.
+func workloadName(w *analyzer.WorkloadSkewAnalysis) string {
+	if len(w.Instances) > 1 {
+		return fmt.Sprintf("%s (×%d)", w.Workload, len(w.Instances))
+	}
+	return w.Workload
+}
+
+func safetyRating(w *analyzer.WorkloadSkewAnalysis) string {
+	if w.Safety == nil {
+		return "?"
+	}
+	return string(w.Safety.Rating)
+}
+
+func optional(v float64, format func(float64) string) string {
+	if v <= 0 {
+		return "-"
+	}
+	return format(v)
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
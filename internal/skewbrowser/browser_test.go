@@ -0,0 +1,144 @@
+package skewbrowser
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/analyzer"
+	"github.com/ppiankov/kubenow/internal/models"
+)
+
+func testResult() *analyzer.RequestsSkewResult {
+	return &analyzer.RequestsSkewResult{
+		Metadata: analyzer.RequestsSkewMetadata{Window: "30d"},
+		Results: []analyzer.WorkloadSkewAnalysis{
+			{Namespace: "payments", Workload: "api", Type: "Deployment", RequestedCPU: 4, P95UsedCPU: 0.5, P99UsedCPU: 0.8,
+				SkewCPU: 8, ImpactScore: 40, QuotaContext: "Namespace has quota: 80% utilized",
+				Safety: &models.SafetyAnalysis{Rating: models.SafetyRatingSafe, Warnings: []string{"2 restarts"}}},
+			{Namespace: "batch", Workload: "etl", Type: "CronJob", RequestedCPU: 2, P95UsedCPU: 1, SkewCPU: 2, ImpactScore: 5},
+			{Namespace: "payments-staging", Workload: "api", Type: "Deployment", RequestedCPU: 1, P95UsedCPU: 0.1,
+				SkewCPU: 10, ImpactScore: 12},
+		},
+		NamespaceQuotas: []analyzer.NamespaceQuotaInfo{{Namespace: "payments", HasResourceQuota: true,
+			QuotaCPU: analyzer.QuotaUsage{Hard: "10", Used: "8", Utilization: 80}}},
+	}
+}
+
+func press(m *Model, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+func rowNames(m *Model) []string {
+	var names []string
+	for _, i := range m.visible() {
+		names = append(names, m.rows[i].Namespace+"/"+m.rows[i].Workload)
+	}
+	return names
+}
+
+func TestBrowser_SortAndFilter(t *testing.T) {
+	m := NewModel(testResult(), "impact", nil)
+	assert.Equal(t, []string{"payments/api", "payments-staging/api", "batch/etl"}, rowNames(m))
+
+	// Cursor on payments-staging/api stays on it across the re-sort
+	press(m, "down", "s")
+	assert.Equal(t, "skew", m.sortBy)
+	assert.Equal(t, []string{"payments-staging/api", "payments/api", "batch/etl"}, rowNames(m))
+	assert.Equal(t, 0, m.cursor)
+	assert.Contains(t, m.View(), "sorted by skew")
+
+	press(m, "/", "p", "a", "y", "enter")
+	assert.Equal(t, []string{"payments-staging/api", "payments/api"}, rowNames(m))
+	press(m, "esc")
+	assert.Len(t, rowNames(m), 3)
+}
+
+func TestBrowser_ExpandDetails(t *testing.T) {
+	m := NewModel(testResult(), "impact", nil)
+	press(m, "enter")
+	view := m.View()
+	assert.Contains(t, view, "payments/api (Deployment)")
+	assert.Contains(t, view, "P99.9")
+	assert.Contains(t, view, "2 restarts")
+	assert.Contains(t, view, "CPU quota: 8 used / 10 hard (80%)")
+	assert.Contains(t, view, "Namespace has quota: 80% utilized")
+
+	// Moving keeps the details open on the next workload; esc closes them
+	press(m, "down")
+	assert.Contains(t, m.View(), "payments-staging/api (Deployment)")
+	press(m, "esc")
+	assert.False(t, m.expanded)
+	assert.Contains(t, m.View(), "WORKLOAD")
+}
+
+func TestBrowser_ExportSelection(t *testing.T) {
+	var exported *analyzer.RequestsSkewResult
+	m := NewModel(testResult(), "impact", func(r *analyzer.RequestsSkewResult) (string, error) {
+		exported = r
+		return "out.json", nil
+	})
+
+	// Nothing selected: export everything shown
+	press(m, "/", "p", "a", "y", "enter")
+	cmd := press(m, "e")
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	require.NotNil(t, exported)
+	assert.Len(t, exported.Results, 2)
+	assert.Contains(t, m.View(), "Exported 2 workload(s) to out.json")
+
+	press(m, "esc", "down", "down", " ", "up", "up", " ")
+	cmd = press(m, "e")
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	require.Len(t, exported.Results, 2)
+	assert.Equal(t, "api", exported.Results[0].Workload, "exported in the browser's sort order")
+	assert.Equal(t, "etl", exported.Results[1].Workload)
+	assert.InDelta(t, 4.5, exported.Summary.TotalWastedCPU, 0.001)
+
+	press(m, "n")
+	assert.Contains(t, m.View(), "0 selected")
+}
+
+func TestBrowser_ExportFailure(t *testing.T) {
+	m := NewModel(testResult(), "impact", func(*analyzer.RequestsSkewResult) (string, error) {
+		return "", assert.AnError
+	})
+	cmd := press(m, "e")
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	assert.Contains(t, m.View(), "Export failed")
+}
+
+func TestBrowser_KeyMapOverride(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{"sort": {"o"}})
+	require.NoError(t, err)
+	m := NewModel(testResult(), "", nil)
+	m.SetKeyMap(keys)
+	press(m, "o")
+	assert.Equal(t, "skew", m.sortBy)
+
+	cmd := press(m, "q")
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
@@ -0,0 +1,44 @@
+package skewbrowser
+
+import "github.com/ppiankov/kubenow/internal/keymap"
+
+// Browser actions, as named in the keybindings.requests-skew config
+// section. Filter input is text and not remappable.
+const (
+	actionQuit        keymap.Action = "quit"
+	actionUp          keymap.Action = "up"
+	actionDown        keymap.Action = "down"
+	actionPageUp      keymap.Action = "page-up"
+	actionPageDown    keymap.Action = "page-down"
+	actionExpand      keymap.Action = "expand"
+	actionToggle      keymap.Action = "toggle"
+	actionAll         keymap.Action = "select-all"
+	actionNone        keymap.Action = "select-none"
+	actionSort        keymap.Action = "sort"
+	actionSearch      keymap.Action = "search"
+	actionClearFilter keymap.Action = "clear-filter"
+	actionExport      keymap.Action = "export"
+)
+
+// DefaultKeyBindings are the browser's bindings before config overrides.
+var DefaultKeyBindings = []keymap.Binding{
+	{Action: actionQuit, Keys: []string{"q"}, Description: "quit"},
+	{Action: actionUp, Keys: []string{"up", "k"}, Description: "move up"},
+	{Action: actionDown, Keys: []string{"down", "j"}, Description: "move down"},
+	{Action: actionPageUp, Keys: []string{"pgup"}, Description: "page up"},
+	{Action: actionPageDown, Keys: []string{"pgdown"}, Description: "page down"},
+	{Action: actionExpand, Keys: []string{"enter"}, Description: "show/hide percentiles, safety, and quota details"},
+	{Action: actionToggle, Keys: []string{" ", "x"}, Description: "select/unselect for export"},
+	{Action: actionAll, Keys: []string{"a"}, Description: "select all shown"},
+	{Action: actionNone, Keys: []string{"n"}, Description: "unselect all"},
+	{Action: actionSort, Keys: []string{"s"}, Description: "cycle sort: impact, skew, cpu, memory, name"},
+	{Action: actionSearch, Keys: []string{"/"}, Description: "filter by namespace"},
+	{Action: actionClearFilter, Keys: []string{"esc"}, Description: "close details, then clear the filter"},
+	{Action: actionExport, Keys: []string{"e"}, Description: "export selected workloads (all shown if none selected)"},
+	{Action: keymap.Help, Keys: []string{"?"}, Description: "toggle this help"},
+}
+
+// NewKeyMap builds the browser key map with config overrides applied.
+func NewKeyMap(overrides map[string][]string) (*keymap.Map, error) {
+	return keymap.New(DefaultKeyBindings, overrides)
+}
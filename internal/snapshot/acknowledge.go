@@ -0,0 +1,85 @@
+// This file separates acknowledged problem pods from active ones.
+
+package snapshot
+
+import (
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/ack"
+)
+
+// podIssue is one problem of a pod: a container state or a pod-level reason.
+type podIssue struct {
+	container string
+	kind      string
+}
+
+// podIssues lists the problems that put a pod in the snapshot, using the
+// same types as the monitor and watch mode: a container's waiting or
+// terminated reason (or its last termination reason after restarts), and
+// the pod's reason or phase. Pods with none of these are "NotReady" or
+// "Restarts".
+func podIssues(p *PodSnapshot) []podIssue {
+	var issues []podIssue
+	for i := range p.Containers {
+		c := &p.Containers[i]
+		switch {
+		case c.State != "" && c.State != "Running":
+			kind := c.State
+			if c.StateReason != "" {
+				kind = c.StateReason
+			}
+			issues = append(issues, podIssue{container: c.Name, kind: kind})
+		case c.RestartCount > 0 && c.LastStateReason != "":
+			issues = append(issues, podIssue{container: c.Name, kind: c.LastStateReason})
+		}
+	}
+
+	podKind := p.Phase
+	if p.Reason != "" {
+		podKind = p.Reason
+	}
+	if podKind != "" && podKind != "Running" && podKind != "Succeeded" {
+		issues = append(issues, podIssue{kind: podKind})
+	}
+	if len(issues) == 0 {
+		kind := "Restarts"
+		if !p.Ready {
+			kind = "NotReady"
+		}
+		issues = append(issues, podIssue{kind: kind})
+	}
+	return issues
+}
+
+// SplitAcknowledged moves problem pods whose every issue is covered by an
+// unexpired acknowledgement out of ProblemPods and records them, with the
+// acceptance reason, in AcknowledgedProblems. A pod with any unacknowledged
+// issue stays active. It returns the number of pods moved.
+func (s *Snapshot) SplitAcknowledged(acks *ack.List, now time.Time) int {
+	if acks == nil || len(s.ProblemPods) == 0 {
+		return 0
+	}
+	active := s.ProblemPods[:0]
+	moved := 0
+	for i := range s.ProblemPods {
+		pod := s.ProblemPods[i]
+		issues := podIssues(&pod)
+		accepted := make([]ack.Problem, 0, len(issues))
+		for _, issue := range issues {
+			a := acks.Match(pod.Namespace, pod.Name, issue.container, issue.kind, now)
+			if a == nil {
+				break
+			}
+			accepted = append(accepted, a.Problem(pod.Namespace, pod.Name, issue.container, issue.kind))
+		}
+		if len(accepted) < len(issues) {
+			active = append(active, pod)
+			continue
+		}
+		s.AcknowledgedProblems = append(s.AcknowledgedProblems, accepted...)
+		moved++
+	}
+	s.ProblemPods = active
+	return moved
+}
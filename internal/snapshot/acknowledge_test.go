@@ -0,0 +1,54 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/ack"
+)
+
+func TestSplitAcknowledged(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+	acks := &ack.List{Acknowledgements: []ack.Acknowledgement{
+		{Namespace: "batch", Pod: "report-*", Type: "CrashLoopBackOff", Reason: "nightly flake", By: "data-team"},
+		{Namespace: "prod", Pod: "legacy-*", Reason: "decommissioning"},
+		{Namespace: "prod", Pod: "api-*", Type: "OOMKilled", Reason: "snoozed", Until: &expired},
+	}}
+	s := &Snapshot{ProblemPods: []PodSnapshot{
+		{Namespace: "batch", Name: "report-1", Phase: "Running",
+			Containers: []ContainerSnapshot{{Name: "app", State: "Waiting", StateReason: "CrashLoopBackOff"}}},
+		{Namespace: "batch", Name: "report-2", Phase: "Running", Containers: []ContainerSnapshot{
+			{Name: "app", State: "Waiting", StateReason: "CrashLoopBackOff"},
+			{Name: "sidecar", State: "Waiting", StateReason: "ImagePullBackOff"},
+		}},
+		{Namespace: "prod", Name: "legacy-0", Phase: "Pending"},
+		{Namespace: "prod", Name: "api-1", Phase: "Running", Ready: true,
+			Containers: []ContainerSnapshot{{Name: "app", State: "Running", RestartCount: 3, LastStateReason: "OOMKilled"}}},
+	}}
+
+	moved := s.SplitAcknowledged(acks, now)
+	assert.Equal(t, 2, moved)
+
+	require.Len(t, s.ProblemPods, 2)
+	assert.Equal(t, "report-2", s.ProblemPods[0].Name, "unacknowledged sidecar issue keeps the pod active")
+	assert.Equal(t, "api-1", s.ProblemPods[1].Name, "expired snooze is active again")
+
+	require.Len(t, s.AcknowledgedProblems, 2)
+	assert.Equal(t, ack.Problem{
+		Namespace: "batch", Pod: "report-1", Container: "app", Type: "CrashLoopBackOff", Reason: "nightly flake", By: "data-team",
+	}, s.AcknowledgedProblems[0])
+	assert.Equal(t, "Pending", s.AcknowledgedProblems[1].Type)
+	assert.Equal(t, "decommissioning", s.AcknowledgedProblems[1].Reason)
+
+	assert.Zero(t, (&Snapshot{ProblemPods: []PodSnapshot{{Name: "x"}}}).SplitAcknowledged(nil, now))
+}
+
+func TestPodIssues(t *testing.T) {
+	assert.Equal(t, []podIssue{{kind: "NotReady"}}, podIssues(&PodSnapshot{Phase: "Running"}))
+	assert.Equal(t, []podIssue{{kind: "Restarts"}}, podIssues(&PodSnapshot{Phase: "Running", Ready: true}))
+	assert.Equal(t, []podIssue{{kind: "Evicted"}}, podIssues(&PodSnapshot{Phase: "Failed", Reason: "Evicted"}))
+}
@@ -0,0 +1,161 @@
+// This file gathers node taints, request allocation, and recent node events.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	recentNodeEventWindow = time.Hour
+	maxNodeEvents         = 5
+)
+
+// NodeAllocation compares a node's allocatable resources with the requests
+// of the pods scheduled on it.
+type NodeAllocation struct {
+	AllocatableCPU    string `json:"allocatableCPU"`
+	AllocatableMemory string `json:"allocatableMemory"`
+	RequestedCPU      string `json:"requestedCPU"`
+	RequestedMemory   string `json:"requestedMemory"`
+	CPUPercent        int    `json:"cpuPercent"`
+	MemoryPercent     int    `json:"memoryPercent"`
+	Pods              int    `json:"pods"`
+	AllocatablePods   int64  `json:"allocatablePods"`
+}
+
+// nodeTaints renders taints as kubectl shows them: key=value:Effect.
+func nodeTaints(node *corev1.Node) []string {
+	taints := make([]string, 0, len(node.Spec.Taints))
+	for i := range node.Spec.Taints {
+		t := &node.Spec.Taints[i]
+		if t.Value != "" {
+			taints = append(taints, fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect))
+		} else {
+			taints = append(taints, fmt.Sprintf("%s:%s", t.Key, t.Effect))
+		}
+	}
+	return taints
+}
+
+// nodeAllocations sums the requests of the non-terminated pods per node.
+// pods must cover the whole cluster for the totals to be right.
+func nodeAllocations(nodes []*corev1.Node, pods []*corev1.Pod) map[string]*NodeAllocation {
+	type requested struct {
+		cpu, memory resource.Quantity
+		pods        int
+	}
+	perNode := make(map[string]*requested, len(nodes))
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		r := perNode[pod.Spec.NodeName]
+		if r == nil {
+			r = &requested{}
+			perNode[pod.Spec.NodeName] = r
+		}
+		cpu, memory := PodRequests(pod)
+		r.cpu.Add(cpu)
+		r.memory.Add(memory)
+		r.pods++
+	}
+
+	allocations := make(map[string]*NodeAllocation, len(nodes))
+	for _, node := range nodes {
+		r := perNode[node.Name]
+		if r == nil {
+			r = &requested{}
+		}
+		alloc := node.Status.Allocatable
+		allocations[node.Name] = &NodeAllocation{
+			AllocatableCPU:    alloc.Cpu().String(),
+			AllocatableMemory: alloc.Memory().String(),
+			RequestedCPU:      r.cpu.String(),
+			RequestedMemory:   r.memory.String(),
+			CPUPercent:        percentOf(r.cpu.MilliValue(), alloc.Cpu().MilliValue()),
+			MemoryPercent:     percentOf(r.memory.Value(), alloc.Memory().Value()),
+			Pods:              r.pods,
+			AllocatablePods:   alloc.Pods().Value(),
+		}
+	}
+	return allocations
+}
+
+// PodRequests returns a pod's effective CPU and memory requests, as the
+// scheduler counts them: the larger of the containers' sum and any single
+// init container, plus pod overhead.
+func PodRequests(pod *corev1.Pod) (cpu, memory resource.Quantity) {
+	for i := range pod.Spec.Containers {
+		cpu.Add(*pod.Spec.Containers[i].Resources.Requests.Cpu())
+		memory.Add(*pod.Spec.Containers[i].Resources.Requests.Memory())
+	}
+	for i := range pod.Spec.InitContainers {
+		req := pod.Spec.InitContainers[i].Resources.Requests
+		if req.Cpu().Cmp(cpu) > 0 {
+			cpu = req.Cpu().DeepCopy()
+		}
+		if req.Memory().Cmp(memory) > 0 {
+			memory = req.Memory().DeepCopy()
+		}
+	}
+	cpu.Add(*pod.Spec.Overhead.Cpu())
+	memory.Add(*pod.Spec.Overhead.Memory())
+	return cpu, memory
+}
+
+func percentOf(part, whole int64) int {
+	if whole <= 0 {
+		return 0
+	}
+	return int(part * 100 / whole)
+}
+
+// recentNodeEvents returns the Warning events of each node from the last
+// hour, newest first. Listing errors leave the events out.
+func recentNodeEvents(ctx context.Context, clientset kubernetes.Interface, now time.Time) map[string][]EventSnapshot {
+	evts, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Node,type=Warning",
+	})
+	if err != nil {
+		return nil
+	}
+
+	events := map[string][]EventSnapshot{}
+	for i := range evts.Items {
+		event := &evts.Items[i]
+		if event.InvolvedObject.Kind != "Node" || event.Type != corev1.EventTypeWarning {
+			continue
+		}
+		last := event.LastTimestamp.Time
+		if last.IsZero() {
+			last = event.EventTime.Time
+		}
+		if now.Sub(last) > recentNodeEventWindow {
+			continue
+		}
+		events[event.InvolvedObject.Name] = append(events[event.InvolvedObject.Name], EventSnapshot{
+			Type:      event.Type,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     event.Count,
+			FirstTime: event.FirstTimestamp.Time,
+			LastTime:  last,
+		})
+	}
+	for node, list := range events {
+		sort.Slice(list, func(i, j int) bool { return list[i].LastTime.After(list[j].LastTime) })
+		if len(list) > maxNodeEvents {
+			events[node] = list[:maxNodeEvents]
+		}
+	}
+	return events
+}
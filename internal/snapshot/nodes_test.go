@@ -0,0 +1,112 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeTaints(t *testing.T) {
+	node := &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/disk-pressure", Effect: corev1.TaintEffectNoSchedule},
+	}}}
+	assert.Equal(t, []string{"dedicated=gpu:NoSchedule", "node.kubernetes.io/disk-pressure:NoSchedule"}, nodeTaints(node))
+}
+
+func TestNodeAllocations(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+			corev1.ResourcePods:   resource.MustParse("110"),
+		}},
+	}
+	pod := func(phase corev1.PodPhase, cpu, memory string, initCPU string) *corev1.Pod {
+		p := &corev1.Pod{
+			Spec: corev1.PodSpec{NodeName: "n1", Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+			}}}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		if initCPU != "" {
+			p.Spec.InitContainers = []corev1.Container{{Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(initCPU)},
+			}}}
+		}
+		return p
+	}
+
+	got := nodeAllocations([]*corev1.Node{node}, []*corev1.Pod{
+		pod(corev1.PodRunning, "1", "2Gi", ""),
+		pod(corev1.PodPending, "500m", "2Gi", "2"),
+		pod(corev1.PodSucceeded, "4", "8Gi", ""),
+	})
+	require.Contains(t, got, "n1")
+	alloc := got["n1"]
+	assert.Equal(t, "3", alloc.RequestedCPU, "the init container's 2 cores outweigh 500m")
+	assert.Equal(t, "4Gi", alloc.RequestedMemory)
+	assert.Equal(t, 75, alloc.CPUPercent)
+	assert.Equal(t, 50, alloc.MemoryPercent)
+	assert.Equal(t, 2, alloc.Pods, "finished pods hold no requests")
+	assert.Equal(t, int64(110), alloc.AllocatablePods)
+}
+
+func TestRecentNodeEvents(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	event := func(name, node, kind, typ, reason string, last time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: node},
+			Type:           typ,
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(last),
+		}
+	}
+	client := fake.NewSimpleClientset(
+		event("e1", "n1", "Node", "Warning", "SystemOOM", now.Add(-10*time.Minute)),
+		event("e2", "n1", "Node", "Warning", "NodeNotReady", now.Add(-5*time.Minute)),
+		event("e3", "n1", "Node", "Warning", "Rebooted", now.Add(-2*time.Hour)),
+		event("e4", "n1", "Node", "Normal", "NodeReady", now.Add(-time.Minute)),
+		event("e5", "api-1", "Pod", "Warning", "BackOff", now.Add(-time.Minute)),
+	)
+
+	got := recentNodeEvents(context.Background(), client, now)
+	require.Len(t, got["n1"], 2, "old, Normal, and pod events are left out")
+	assert.Equal(t, "NodeNotReady", got["n1"][0].Reason, "newest first")
+	assert.Equal(t, "SystemOOM", got["n1"][1].Reason)
+	assert.NotContains(t, got, "api-1")
+}
+
+func TestPodRequests(t *testing.T) {
+	requests := func(cpu, memory string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "app", Resources: requests("500m", "256Mi")},
+			{Name: "sidecar", Resources: requests("100m", "64Mi")},
+		},
+		// The init container needs more CPU than the app containers together
+		InitContainers: []corev1.Container{{Name: "migrate", Resources: requests("1", "128Mi")}},
+		Overhead: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("50m"),
+			corev1.ResourceMemory: resource.MustParse("16Mi"),
+		},
+	}}
+
+	cpu, memory := PodRequests(pod)
+	assert.Equal(t, int64(1050), cpu.MilliValue())
+	assert.Equal(t, int64(336<<20), memory.Value())
+}
@@ -0,0 +1,43 @@
+// This file attributes problem pods to the teams that own them.
+
+package snapshot
+
+import (
+	"github.com/ppiankov/kubenow/internal/owners"
+)
+
+// AssignOwners sets the owning team of every problem pod that has none,
+// matching its namespace and labels against the owners file. Pods of a
+// replayed snapshot have no labels, so only namespace rules match them;
+// teams recorded when the snapshot was saved are kept. It returns the
+// number of pods with a team.
+func (s *Snapshot) AssignOwners(f *owners.File) int {
+	if f == nil {
+		return 0
+	}
+	owned := 0
+	for i := range s.ProblemPods {
+		pod := &s.ProblemPods[i]
+		if pod.Team == "" {
+			pod.Team = f.Team(pod.Namespace, pod.labels)
+		}
+		if pod.Team != "" {
+			owned++
+		}
+	}
+	return owned
+}
+
+// OwnerAssignments lists the problem pods per owning team, for reports.
+func (s *Snapshot) OwnerAssignments(f *owners.File) []owners.Assignment {
+	if len(s.ProblemPods) == 0 {
+		return nil
+	}
+	locations := make([]string, len(s.ProblemPods))
+	teams := make([]string, len(s.ProblemPods))
+	for i := range s.ProblemPods {
+		locations[i] = s.ProblemPods[i].Namespace + "/" + s.ProblemPods[i].Name
+		teams[i] = s.ProblemPods[i].Team
+	}
+	return f.Assign(locations, teams)
+}
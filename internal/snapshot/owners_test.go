@@ -0,0 +1,37 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ppiankov/kubenow/internal/owners"
+)
+
+func TestAssignOwners(t *testing.T) {
+	f := &owners.File{Owners: []owners.Rule{
+		{Team: "payments", Contact: "#payments", Namespaces: []string{"payments"}},
+	}}
+	s := &Snapshot{ProblemPods: []PodSnapshot{
+		{Namespace: "payments", Name: "api-1"},
+		{Namespace: "batch", Name: "report-1"},
+		{Namespace: "batch", Name: "report-2", Team: "data"}, // recorded when the snapshot was saved
+	}}
+
+	assert.Equal(t, 2, s.AssignOwners(f))
+	assert.Equal(t, "payments", s.ProblemPods[0].Team)
+	assert.Empty(t, s.ProblemPods[1].Team)
+	assert.Equal(t, "data", s.ProblemPods[2].Team)
+
+	assert.Equal(t, []owners.Assignment{
+		{Team: "data", Pods: []string{"batch/report-2"}},
+		{Team: "payments", Contact: "#payments", Pods: []string{"payments/api-1"}},
+		{Team: owners.Unowned, Pods: []string{"batch/report-1"}},
+	}, s.OwnerAssignments(f))
+}
+
+func TestAssignOwners_NilFile(t *testing.T) {
+	s := &Snapshot{ProblemPods: []PodSnapshot{{Namespace: "payments", Name: "api-1"}}}
+	assert.Zero(t, s.AssignOwners(nil))
+	assert.Empty(t, s.ProblemPods[0].Team)
+}
@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
+)
+
+// SchemaVersion is the on-disk snapshot format version. Bump it when an
+// existing field changes meaning; additive fields do not need a bump.
+const SchemaVersion = 1
+
+// File is the on-disk envelope for a saved snapshot, so it can be replayed
+// offline (without cluster access) by a later kubenow run.
+type File struct {
+	SchemaVersion  int       `json:"schemaVersion"`
+	KubenowVersion string    `json:"kubenowVersion,omitempty"`
+	Cluster        string    `json:"cluster,omitempty"`
+	SavedAt        time.Time `json:"savedAt"`
+	Snapshot       *Snapshot `json:"snapshot"`
+}
+
+// Save writes the snapshot to path. The file holds pod logs and events, so it
+// is created with owner-only permissions.
+func Save(path string, snap *Snapshot, cluster, kubenowVersion string) error {
+	if snap == nil {
+		return fmt.Errorf("snapshot is nil")
+	}
+
+	f := File{
+		SchemaVersion:  SchemaVersion,
+		KubenowVersion: kubenowVersion,
+		Cluster:        cluster,
+		SavedAt:        time.Now().UTC(),
+		Snapshot:       snap,
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := cleanup.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a snapshot saved by Save. Files without a schema version or
+// written by a newer kubenow are rejected rather than half-parsed.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	switch {
+	case f.SchemaVersion == 0:
+		return nil, fmt.Errorf("%s is not a kubenow snapshot file (missing schemaVersion)", path)
+	case f.SchemaVersion > SchemaVersion:
+		return nil, fmt.Errorf("snapshot schema version %d is newer than supported version %d; upgrade kubenow", f.SchemaVersion, SchemaVersion)
+	case f.Snapshot == nil:
+		return nil, fmt.Errorf("snapshot file %s has no snapshot data", path)
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoad_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snap.json")
+	snap := &Snapshot{
+		GeneratedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Namespace:   "prod",
+		ProblemPods: []PodSnapshot{{
+			Namespace: "prod",
+			Name:      "api-0",
+			Phase:     "Running",
+			Logs:      "panic: boom",
+			Containers: []ContainerSnapshot{
+				{Name: "api", StateReason: "CrashLoopBackOff", RestartCount: 7},
+			},
+		}},
+		NodeConditions: []NodeSnapshot{{Name: "node-1"}},
+	}
+
+	require.NoError(t, Save(path, snap, "prod-cluster", "v1.2.3"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	f, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, f.SchemaVersion)
+	assert.Equal(t, "prod-cluster", f.Cluster)
+	assert.Equal(t, "v1.2.3", f.KubenowVersion)
+	assert.False(t, f.SavedAt.IsZero())
+	assert.Equal(t, snap, f.Snapshot)
+}
+
+func TestSave_NilSnapshot(t *testing.T) {
+	assert.Error(t, Save(filepath.Join(t.TempDir(), "snap.json"), nil, "", ""))
+}
+
+func TestLoad_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{"corrupted", "{not json", "failed to parse snapshot"},
+		{"missing version", `{"snapshot":{"problemPods":[]}}`, "missing schemaVersion"},
+		{"newer version", `{"schemaVersion":99,"snapshot":{"problemPods":[]}}`, "upgrade kubenow"},
+		{"no snapshot", `{"schemaVersion":1}`, "no snapshot data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snap.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			_, err := Load(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
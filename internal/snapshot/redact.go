@@ -0,0 +1,47 @@
+package snapshot
+
+import "github.com/ppiankov/kubenow/internal/redact"
+
+// Redact masks secrets and personal data in the snapshot's free text: pod
+// logs, event, condition, and rollout messages, and storage errors. Names,
+// images, and states are kept so the analysis still matches the cluster.
+func (s *Snapshot) Redact(r *redact.Redactor) *redact.Summary {
+	if r == nil {
+		return nil
+	}
+	summary := r.NewSummary()
+	text := func(t *string) { *t = r.Redact(*t, summary) }
+	events := func(evts []EventSnapshot) {
+		for i := range evts {
+			text(&evts[i].Message)
+		}
+	}
+
+	for i := range s.ProblemPods {
+		text(&s.ProblemPods[i].Logs)
+		events(s.ProblemPods[i].Events)
+	}
+	for i := range s.NodeConditions {
+		node := &s.NodeConditions[i]
+		for j := range node.Conditions {
+			text(&node.Conditions[j].Message)
+		}
+		events(node.RecentEvents)
+	}
+	for i := range s.Rollouts {
+		text(&s.Rollouts[i].Message)
+	}
+	if s.Storage != nil {
+		for i := range s.Storage.Claims {
+			events(s.Storage.Claims[i].Events)
+		}
+		for i := range s.Storage.Volumes {
+			text(&s.Storage.Volumes[i].Message)
+		}
+		for i := range s.Storage.Attachments {
+			text(&s.Storage.Attachments[i].AttachError)
+			text(&s.Storage.Attachments[i].DetachError)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,38 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/redact"
+)
+
+func TestSnapshotRedact(t *testing.T) {
+	s := &Snapshot{
+		ProblemPods: []PodSnapshot{{
+			Namespace: "prod", Name: "api-1", Phase: "Running",
+			Logs:   "panic: dial postgres://app:hunter2@db:5432/orders",
+			Events: []EventSnapshot{{Reason: "Failed", Message: "token=abcd1234 rejected"}},
+		}},
+		Rollouts: []RolloutSnapshot{{Name: "api", Message: "contact ops@example.com"}},
+		Storage: &StorageSnapshot{Attachments: []AttachmentSnapshot{
+			{AttachError: "rpc error: credentials=topsecret invalid"},
+		}},
+	}
+
+	r, err := redact.New(redact.ProfileStandard, nil)
+	require.NoError(t, err)
+	summary := s.Redact(r)
+
+	require.NotNil(t, summary)
+	assert.Equal(t, 4, summary.Redactions)
+	assert.Equal(t, "panic: dial postgres://app:[REDACTED:connection-string]@db:5432/orders", s.ProblemPods[0].Logs)
+	assert.Equal(t, "token=[REDACTED:secret] rejected", s.ProblemPods[0].Events[0].Message)
+	assert.Equal(t, "contact [REDACTED:email]", s.Rollouts[0].Message)
+	assert.Equal(t, "rpc error: credentials=[REDACTED:secret] invalid", s.Storage.Attachments[0].AttachError)
+	assert.Equal(t, "api-1", s.ProblemPods[0].Name, "names are kept")
+
+	assert.Nil(t, s.Redact(nil), "no redactor, no summary")
+}
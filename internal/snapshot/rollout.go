@@ -0,0 +1,289 @@
+// This file gathers Deployment rollout status and revision history.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Rollout states reported in RolloutSnapshot.Status.
+const (
+	RolloutComplete    = "Complete"
+	RolloutProgressing = "Progressing"
+	RolloutStuck       = "Stuck"
+	RolloutPaused      = "Paused"
+)
+
+const (
+	// revisionAnnotation is set by the Deployment controller on each ReplicaSet.
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+	// podTemplateHashLabel is the hash of the Deployment's pod template, set
+	// on each ReplicaSet and its pods and appended to the ReplicaSet name.
+	podTemplateHashLabel = "pod-template-hash"
+	// reasonProgressDeadlineExceeded marks a rollout that stopped progressing.
+	reasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+	maxRevisionHistory  = 3
+	maxRollouts         = 20
+	recentRolloutWindow = 6 * time.Hour
+)
+
+// RevisionSnapshot is one entry of a Deployment's revision history.
+type RevisionSnapshot struct {
+	Revision        int64     `json:"revision"`
+	ReplicaSet      string    `json:"replicaSet"`
+	PodTemplateHash string    `json:"podTemplateHash,omitempty"`
+	Images          []string  `json:"images"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Replicas        int32     `json:"replicas"`
+	ReadyReplicas   int32     `json:"readyReplicas"`
+
+	// ImageChanges lists the container images that differ from the previous
+	// revision, as "container: old -> new"; empty for config-only rollouts.
+	ImageChanges []string `json:"imageChanges,omitempty"`
+
+	containers []corev1.Container // for ImageChanges; not saved
+}
+
+// RolloutSnapshot is a Deployment's rollout status plus its latest revisions.
+type RolloutSnapshot struct {
+	Namespace         string             `json:"namespace"`
+	Name              string             `json:"name"`
+	Status            string             `json:"status"` // Complete|Progressing|Stuck|Paused
+	Message           string             `json:"message,omitempty"`
+	Replicas          int32              `json:"replicas"`
+	UpdatedReplicas   int32              `json:"updatedReplicas"`
+	ReadyReplicas     int32              `json:"readyReplicas"`
+	AvailableReplicas int32              `json:"availableReplicas"`
+	History           []RevisionSnapshot `json:"history,omitempty"` // newest first
+}
+
+// BuildRollouts returns rollout status for Deployments that are not fully
+// rolled out, rolled out within the last few hours, or own one of the
+// problem pods (problemReplicaSets holds "namespace/replicaset" keys).
+// Stuck rollouts come first, then the most recently changed. Listing
+// errors leave the section empty.
+func BuildRollouts(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace string,
+	problemReplicaSets map[string]bool,
+	now time.Time,
+) []RolloutSnapshot {
+	deps, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil || len(deps.Items) == 0 {
+		return nil
+	}
+	rsList, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	owned := map[string][]*appsv1.ReplicaSet{} // deployment UID -> ReplicaSets
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.Kind == "Deployment" {
+			owned[string(owner.UID)] = append(owned[string(owner.UID)], rs)
+		}
+	}
+
+	var result []RolloutSnapshot
+	for i := range deps.Items {
+		dep := &deps.Items[i]
+		rollout := buildRolloutSnapshot(dep, owned[string(dep.UID)])
+
+		ownsProblemPod := false
+		for _, rs := range owned[string(dep.UID)] {
+			if problemReplicaSets[rs.Namespace+"/"+rs.Name] {
+				ownsProblemPod = true
+				break
+			}
+		}
+		recent := len(rollout.History) > 0 && now.Sub(rollout.History[0].CreatedAt) <= recentRolloutWindow
+		if rollout.Status == RolloutComplete && !ownsProblemPod && !recent {
+			continue
+		}
+		result = append(result, rollout)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		si, sj := result[i].Status == RolloutStuck, result[j].Status == RolloutStuck
+		if si != sj {
+			return si
+		}
+		return latestChange(&result[i]).After(latestChange(&result[j]))
+	})
+	if len(result) > maxRollouts {
+		result = result[:maxRollouts]
+	}
+	return result
+}
+
+func buildRolloutSnapshot(dep *appsv1.Deployment, replicaSets []*appsv1.ReplicaSet) RolloutSnapshot {
+	status, message := rolloutStatus(dep)
+	rollout := RolloutSnapshot{
+		Namespace:         dep.Namespace,
+		Name:              dep.Name,
+		Status:            status,
+		Message:           message,
+		Replicas:          dep.Status.Replicas,
+		UpdatedReplicas:   dep.Status.UpdatedReplicas,
+		ReadyReplicas:     dep.Status.ReadyReplicas,
+		AvailableReplicas: dep.Status.AvailableReplicas,
+	}
+
+	for _, rs := range replicaSets {
+		rev, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		rollout.History = append(rollout.History, RevisionSnapshot{
+			Revision:        rev,
+			ReplicaSet:      rs.Name,
+			PodTemplateHash: rs.Labels[podTemplateHashLabel],
+			Images:          containerImages(rs.Spec.Template.Spec.Containers),
+			CreatedAt:       rs.CreationTimestamp.UTC(),
+			Replicas:        rs.Status.Replicas,
+			ReadyReplicas:   rs.Status.ReadyReplicas,
+			containers:      rs.Spec.Template.Spec.Containers,
+		})
+	}
+	sort.Slice(rollout.History, func(i, j int) bool {
+		return rollout.History[i].Revision > rollout.History[j].Revision
+	})
+	// Diff before trimming, so the oldest kept revision still has a predecessor
+	for i := 0; i+1 < len(rollout.History); i++ {
+		rollout.History[i].ImageChanges = imageChanges(rollout.History[i+1].containers, rollout.History[i].containers)
+	}
+	if len(rollout.History) > maxRevisionHistory {
+		rollout.History = rollout.History[:maxRevisionHistory]
+	}
+	return rollout
+}
+
+// rolloutStatus classifies a Deployment the way `kubectl rollout status` does:
+// stuck once the progress deadline is exceeded, progressing while replicas
+// are still being updated or becoming available, complete otherwise.
+func rolloutStatus(dep *appsv1.Deployment) (status, message string) {
+	for i := range dep.Status.Conditions {
+		c := &dep.Status.Conditions[i]
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == reasonProgressDeadlineExceeded {
+			return RolloutStuck, c.Message
+		}
+	}
+	if dep.Spec.Paused {
+		return RolloutPaused, ""
+	}
+
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	st := dep.Status
+	if st.ObservedGeneration < dep.Generation ||
+		st.UpdatedReplicas < desired ||
+		st.Replicas > st.UpdatedReplicas ||
+		st.AvailableReplicas < st.UpdatedReplicas {
+		return RolloutProgressing, ""
+	}
+	return RolloutComplete, ""
+}
+
+func latestChange(r *RolloutSnapshot) time.Time {
+	if len(r.History) == 0 {
+		return time.Time{}
+	}
+	return r.History[0].CreatedAt
+}
+
+func containerImages(containers []corev1.Container) []string {
+	images := make([]string, 0, len(containers))
+	for i := range containers {
+		images = append(images, containers[i].Image)
+	}
+	return images
+}
+
+// imageChanges describes the containers whose image differs between two
+// revisions' pod templates, including added and removed containers.
+func imageChanges(prev, cur []corev1.Container) []string {
+	prevImages := make(map[string]string, len(prev))
+	for i := range prev {
+		prevImages[prev[i].Name] = prev[i].Image
+	}
+	var changes []string
+	for i := range cur {
+		c := &cur[i]
+		old, ok := prevImages[c.Name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s: added %s", c.Name, c.Image))
+		case old != c.Image:
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", c.Name, old, c.Image))
+		}
+		delete(prevImages, c.Name)
+	}
+	for i := range prev {
+		if _, removed := prevImages[prev[i].Name]; removed {
+			changes = append(changes, fmt.Sprintf("%s: removed %s", prev[i].Name, prev[i].Image))
+		}
+	}
+	return changes
+}
+
+// owningWorkload names the workload controlling a pod as "Kind/name". Pods
+// of a ReplicaSet named after its pod template hash belong to the Deployment
+// the name is derived from.
+func owningWorkload(pod *corev1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return ""
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash := pod.Labels[podTemplateHashLabel]; hash != "" {
+			if dep, ok := strings.CutSuffix(owner.Name, "-"+hash); ok {
+				return "Deployment/" + dep
+			}
+		}
+	}
+	return owner.Kind + "/" + owner.Name
+}
+
+// linkRevisions sets the rollout revision of Deployment pods whose pod
+// template hash matches one of the snapshot's listed revisions.
+func linkRevisions(snap *Snapshot) {
+	revisions := make(map[string]int64) // namespace/deployment/hash -> revision
+	for i := range snap.Rollouts {
+		r := &snap.Rollouts[i]
+		for j := range r.History {
+			if hash := r.History[j].PodTemplateHash; hash != "" {
+				revisions[r.Namespace+"/Deployment/"+r.Name+"/"+hash] = r.History[j].Revision
+			}
+		}
+	}
+	for i := range snap.ProblemPods {
+		p := &snap.ProblemPods[i]
+		if p.PodTemplateHash != "" {
+			p.Revision = revisions[p.Namespace+"/"+p.Workload+"/"+p.PodTemplateHash]
+		}
+	}
+}
+
+// replicaSetOwner returns the "namespace/name" key of the ReplicaSet that
+// controls a pod, or "" when the pod is not owned by a ReplicaSet.
+func replicaSetOwner(pod *corev1.Pod) string {
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "ReplicaSet" {
+		return pod.Namespace + "/" + owner.Name
+	}
+	return ""
+}
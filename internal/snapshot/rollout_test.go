@@ -0,0 +1,161 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var rolloutNow = time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+func testDeployment(name string, uid types.UID, status appsv1.DeploymentStatus) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod", UID: uid, Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		Status:     status,
+	}
+}
+
+func testReplicaSet(name, dep string, uid types.UID, revision, image string, created time.Time) *appsv1.ReplicaSet {
+	isController := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "prod",
+			Annotations:       map[string]string{revisionAnnotation: revision},
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "Deployment", Name: dep, UID: uid, Controller: &isController,
+			}},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: image}},
+		}}},
+	}
+}
+
+var completeStatus = appsv1.DeploymentStatus{
+	ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2,
+}
+
+func TestBuildRollouts(t *testing.T) {
+	stuck := appsv1.DeploymentStatus{
+		ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 1, ReadyReplicas: 2, AvailableReplicas: 2,
+		Conditions: []appsv1.DeploymentCondition{{
+			Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse,
+			Reason: reasonProgressDeadlineExceeded, Message: `ReplicaSet "api-v5" has timed out progressing.`,
+		}},
+	}
+	old := rolloutNow.Add(-72 * time.Hour)
+
+	client := fake.NewSimpleClientset(
+		testDeployment("api", "api-uid", stuck),
+		testReplicaSet("api-v2", "api", "api-uid", "2", "api:1.0", old.Add(-48*time.Hour)),
+		testReplicaSet("api-v3", "api", "api-uid", "3", "api:1.1", old.Add(-24*time.Hour)),
+		testReplicaSet("api-v4", "api", "api-uid", "4", "api:1.2", old),
+		testReplicaSet("api-v5", "api", "api-uid", "5", "api:1.3", rolloutNow.Add(-28*time.Minute)),
+		testDeployment("web", "web-uid", completeStatus),
+		testReplicaSet("web-v7", "web", "web-uid", "7", "web:2.0", rolloutNow.Add(-time.Hour)),
+		testDeployment("idle", "idle-uid", completeStatus),
+		testReplicaSet("idle-v1", "idle", "idle-uid", "1", "idle:1.0", old),
+		testDeployment("worker", "worker-uid", completeStatus),
+		testReplicaSet("worker-v9", "worker", "worker-uid", "9", "worker:3.0", old),
+	)
+
+	got := BuildRollouts(context.Background(), client, "prod", map[string]bool{"prod/worker-v9": true}, rolloutNow)
+	require.Len(t, got, 3, "idle deployment is complete, old, and healthy")
+
+	assert.Equal(t, "api", got[0].Name)
+	assert.Equal(t, RolloutStuck, got[0].Status)
+	assert.Contains(t, got[0].Message, "timed out")
+	require.Len(t, got[0].History, maxRevisionHistory)
+	assert.Equal(t, int64(5), got[0].History[0].Revision)
+	assert.Equal(t, []string{"api:1.3"}, got[0].History[0].Images)
+	assert.Equal(t, int64(3), got[0].History[2].Revision)
+
+	assert.Equal(t, "web", got[1].Name, "recent rollout")
+	assert.Equal(t, RolloutComplete, got[1].Status)
+	assert.Equal(t, "worker", got[2].Name, "owns a problem pod")
+}
+
+func TestRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*appsv1.Deployment)
+		want   string
+	}{
+		{"complete", func(*appsv1.Deployment) {}, RolloutComplete},
+		{"paused", func(d *appsv1.Deployment) { d.Spec.Paused = true }, RolloutPaused},
+		{"generation not observed", func(d *appsv1.Deployment) { d.Generation = 2 }, RolloutProgressing},
+		{"old replicas remain", func(d *appsv1.Deployment) { d.Status.Replicas = 3 }, RolloutProgressing},
+		{"updated not available", func(d *appsv1.Deployment) { d.Status.AvailableReplicas = 1 }, RolloutProgressing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := testDeployment("api", "uid", completeStatus)
+			tt.mutate(dep)
+			got, _ := rolloutStatus(dep)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReplicaSetOwner(t *testing.T) {
+	isController := true
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "api-v5-abcde", Namespace: "prod",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-v5", Controller: &isController}},
+	}}
+	assert.Equal(t, "prod/api-v5", replicaSetOwner(pod))
+	assert.Empty(t, replicaSetOwner(&corev1.Pod{}))
+}
+
+func TestBuildRolloutSnapshot_ImageChanges(t *testing.T) {
+	v1 := testReplicaSet("api-5d8f", "api", "api-uid", "1", "api:1.0", rolloutNow.Add(-2*time.Hour))
+	v2 := testReplicaSet("api-7b9c", "api", "api-uid", "2", "api:1.1", rolloutNow.Add(-time.Hour))
+	v2.Labels = map[string]string{podTemplateHashLabel: "7b9c"}
+	v2.Spec.Template.Spec.Containers = append(v2.Spec.Template.Spec.Containers, corev1.Container{Name: "proxy", Image: "envoy:1.30"})
+	v3 := testReplicaSet("api-9a1e", "api", "api-uid", "3", "api:1.1", rolloutNow)
+
+	rollout := buildRolloutSnapshot(testDeployment("api", "api-uid", completeStatus), []*appsv1.ReplicaSet{v1, v2, v3})
+	require.Len(t, rollout.History, 3)
+	assert.Equal(t, []string{"proxy: removed envoy:1.30"}, rollout.History[0].ImageChanges)
+	assert.Equal(t, []string{"app: api:1.0 -> api:1.1", "proxy: added envoy:1.30"}, rollout.History[1].ImageChanges)
+	assert.Equal(t, "7b9c", rollout.History[1].PodTemplateHash)
+	assert.Empty(t, rollout.History[2].ImageChanges, "no earlier revision to compare with")
+}
+
+func TestOwningWorkloadAndRevision(t *testing.T) {
+	isController := true
+	pod := func(name, ownerKind, ownerName, hash string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "prod", Labels: map[string]string{podTemplateHashLabel: hash},
+			OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &isController}},
+		}}
+	}
+	assert.Equal(t, "Deployment/api", owningWorkload(pod("api-7b9c-x1", "ReplicaSet", "api-7b9c", "7b9c")))
+	assert.Equal(t, "ReplicaSet/batch", owningWorkload(pod("batch-x1", "ReplicaSet", "batch", "")))
+	assert.Equal(t, "StatefulSet/db", owningWorkload(pod("db-0", "StatefulSet", "db", "")))
+	assert.Empty(t, owningWorkload(&corev1.Pod{}))
+
+	snap := &Snapshot{
+		ProblemPods: []PodSnapshot{
+			{Namespace: "prod", Name: "api-7b9c-x1", Workload: "Deployment/api", PodTemplateHash: "7b9c"},
+			{Namespace: "prod", Name: "db-0", Workload: "StatefulSet/db"},
+		},
+		Rollouts: []RolloutSnapshot{{Namespace: "prod", Name: "api", History: []RevisionSnapshot{
+			{Revision: 4, PodTemplateHash: "9a1e"}, {Revision: 3, PodTemplateHash: "7b9c"},
+		}}},
+	}
+	linkRevisions(snap)
+	assert.Equal(t, int64(3), snap.ProblemPods[0].Revision, "the pod runs the previous revision")
+	assert.Zero(t, snap.ProblemPods[1].Revision)
+}
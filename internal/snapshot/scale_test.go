@@ -0,0 +1,62 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/synthetic"
+)
+
+// Snapshot API calls must not grow with cluster size: a fixed set of
+// list calls (including one node event list) plus one event list and one
+// log fetch per problem pod, and problem pods are capped by maxPods.
+func TestBuildSnapshot_APICallBudget(t *testing.T) {
+	const maxPods = 20
+	for _, tt := range []struct {
+		name string
+		cfg  synthetic.Config
+	}{
+		{"small", synthetic.Small},
+		{"medium", synthetic.Medium},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := synthetic.Generate(tt.cfg).Clientset()
+			snap, err := BuildSnapshot(context.Background(), client, "", maxPods, 50, 5, &Filters{})
+			require.NoError(t, err)
+			require.NotEmpty(t, snap.ProblemPods)
+			assert.LessOrEqual(t, len(snap.ProblemPods), maxPods)
+
+			counts := synthetic.CountActions(client)
+			assert.Equal(t, 1, counts["list pods"])
+			assert.Equal(t, 1, counts["list nodes"])
+			assert.Equal(t, len(snap.ProblemPods)+1, counts["list events"], "per problem pod, plus node events")
+			assert.Equal(t, len(snap.ProblemPods), counts["get pods/log"])
+			assert.LessOrEqual(t, len(client.Actions()), 8+2*maxPods)
+		})
+	}
+}
+
+func BenchmarkBuildSnapshot(b *testing.B) {
+	for _, bb := range []struct {
+		name string
+		cfg  synthetic.Config
+	}{
+		{"small", synthetic.Small},
+		{"medium", synthetic.Medium},
+		{"large", synthetic.Large},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			client := synthetic.Generate(bb.cfg).Clientset()
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := BuildSnapshot(ctx, client, "", 20, 50, 5, &Filters{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// This file gathers HPA, VPA, and PDB summaries per workload.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// vpaAPIPath is the VerticalPodAutoscaler API group path. VPA is a CRD, so it
+// is read raw and skipped when the CRD is not installed.
+const vpaAPIPath = "/apis/autoscaling.k8s.io/v1"
+
+// HPASnapshot summarizes a HorizontalPodAutoscaler.
+type HPASnapshot struct {
+	Name            string   `json:"name"`
+	MinReplicas     int32    `json:"minReplicas"`
+	MaxReplicas     int32    `json:"maxReplicas"`
+	CurrentReplicas int32    `json:"currentReplicas"`
+	DesiredReplicas int32    `json:"desiredReplicas"`
+	Metrics         []string `json:"metrics,omitempty"` // e.g. "cpu 70%", "memory 512Mi"
+}
+
+// VPASnapshot summarizes a VerticalPodAutoscaler.
+type VPASnapshot struct {
+	Name       string `json:"name"`
+	UpdateMode string `json:"updateMode,omitempty"` // Off|Initial|Recreate|InPlaceOrRecreate|Auto
+}
+
+// PDBSnapshot summarizes a PodDisruptionBudget.
+type PDBSnapshot struct {
+	Name               string `json:"name"`
+	MinAvailable       string `json:"minAvailable,omitempty"`
+	MaxUnavailable     string `json:"maxUnavailable,omitempty"`
+	DisruptionsAllowed int32  `json:"disruptionsAllowed"`
+}
+
+// WorkloadScalingSnapshot lists the autoscaling and disruption objects that
+// target one workload.
+type WorkloadScalingSnapshot struct {
+	Namespace string        `json:"namespace"`
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	HPA       *HPASnapshot  `json:"hpa,omitempty"`
+	VPA       *VPASnapshot  `json:"vpa,omitempty"`
+	PDBs      []PDBSnapshot `json:"pdbs,omitempty"`
+}
+
+// BuildScaling collects HPA, VPA, and PDB summaries keyed by the workload they
+// target. Collection is best-effort: an API that cannot be listed (missing
+// RBAC, VPA CRD not installed) contributes nothing. PDBs are attributed to
+// the Deployments and StatefulSets whose pod template they select.
+func BuildScaling(ctx context.Context, clientset kubernetes.Interface, namespace string) []WorkloadScalingSnapshot {
+	byWorkload := map[string]*WorkloadScalingSnapshot{}
+	entry := func(ns, kind, name string) *WorkloadScalingSnapshot {
+		key := ns + "/" + kind + "/" + name
+		ws, ok := byWorkload[key]
+		if !ok {
+			ws = &WorkloadScalingSnapshot{Namespace: ns, Kind: kind, Name: name}
+			byWorkload[key] = ws
+		}
+		return ws
+	}
+
+	if hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range hpas.Items {
+			hpa := &hpas.Items[i]
+			ref := hpa.Spec.ScaleTargetRef
+			entry(hpa.Namespace, ref.Kind, ref.Name).HPA = buildHPASnapshot(hpa)
+		}
+	}
+
+	for _, vpa := range listVPAs(ctx, clientset, namespace) {
+		ref := vpa.Spec.TargetRef
+		if ref.Kind == "" || ref.Name == "" {
+			continue
+		}
+		snap := &VPASnapshot{Name: vpa.Metadata.Name}
+		if vpa.Spec.UpdatePolicy != nil {
+			snap.UpdateMode = vpa.Spec.UpdatePolicy.UpdateMode
+		}
+		entry(vpa.Metadata.Namespace, ref.Kind, ref.Name).VPA = snap
+	}
+
+	if pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{}); err == nil && len(pdbs.Items) > 0 {
+		for _, w := range listPodTemplates(ctx, clientset, namespace) {
+			for i := range pdbs.Items {
+				pdb := &pdbs.Items[i]
+				if pdb.Namespace == w.namespace && pdbSelects(pdb, w.labels) {
+					ws := entry(w.namespace, w.kind, w.name)
+					ws.PDBs = append(ws.PDBs, buildPDBSnapshot(pdb))
+				}
+			}
+		}
+	}
+
+	result := make([]WorkloadScalingSnapshot, 0, len(byWorkload))
+	for _, ws := range byWorkload {
+		result = append(result, *ws)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
+
+func buildHPASnapshot(hpa *autoscalingv2.HorizontalPodAutoscaler) *HPASnapshot {
+	snap := &HPASnapshot{
+		Name:            hpa.Name,
+		MinReplicas:     1,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+	}
+	if hpa.Spec.MinReplicas != nil {
+		snap.MinReplicas = *hpa.Spec.MinReplicas
+	}
+	for i := range hpa.Spec.Metrics {
+		if m := hpaMetricSummary(&hpa.Spec.Metrics[i]); m != "" {
+			snap.Metrics = append(snap.Metrics, m)
+		}
+	}
+	return snap
+}
+
+// hpaMetricSummary renders a metric target compactly, e.g. "cpu 70%".
+func hpaMetricSummary(m *autoscalingv2.MetricSpec) string {
+	switch m.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if m.Resource != nil {
+			return resourceTarget(string(m.Resource.Name), m.Resource.Target)
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if m.ContainerResource != nil {
+			return resourceTarget(m.ContainerResource.Container+"/"+string(m.ContainerResource.Name), m.ContainerResource.Target)
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		if m.Pods != nil {
+			return "pods " + m.Pods.Metric.Name
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		if m.Object != nil {
+			return "object " + m.Object.Metric.Name
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		if m.External != nil {
+			return "external " + m.External.Metric.Name
+		}
+	}
+	return string(m.Type)
+}
+
+func resourceTarget(name string, target autoscalingv2.MetricTarget) string {
+	switch {
+	case target.AverageUtilization != nil:
+		return fmt.Sprintf("%s %d%%", name, *target.AverageUtilization)
+	case target.AverageValue != nil:
+		return fmt.Sprintf("%s %s", name, target.AverageValue.String())
+	case target.Value != nil:
+		return fmt.Sprintf("%s %s", name, target.Value.String())
+	}
+	return name
+}
+
+func buildPDBSnapshot(pdb *policyv1.PodDisruptionBudget) PDBSnapshot {
+	snap := PDBSnapshot{Name: pdb.Name, DisruptionsAllowed: pdb.Status.DisruptionsAllowed}
+	if pdb.Spec.MinAvailable != nil {
+		snap.MinAvailable = pdb.Spec.MinAvailable.String()
+	}
+	if pdb.Spec.MaxUnavailable != nil {
+		snap.MaxUnavailable = pdb.Spec.MaxUnavailable.String()
+	}
+	return snap
+}
+
+// pdbSelects reports whether a PDB's selector matches pod template labels.
+// An empty selector selects every pod in the namespace and is not
+// attributed to individual workloads.
+func pdbSelects(pdb *policyv1.PodDisruptionBudget, podLabels map[string]string) bool {
+	if pdb.Spec.Selector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil || selector.Empty() {
+		return false
+	}
+	return selector.Matches(labels.Set(podLabels))
+}
+
+// podTemplate is a workload's identity and pod template labels.
+type podTemplate struct {
+	namespace, kind, name string
+	labels                map[string]string
+}
+
+func listPodTemplates(ctx context.Context, clientset kubernetes.Interface, namespace string) []podTemplate {
+	var result []podTemplate
+	if deps, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range deps.Items {
+			d := &deps.Items[i]
+			result = append(result, podTemplate{d.Namespace, "Deployment", d.Name, d.Spec.Template.Labels})
+		}
+	}
+	if sts, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range sts.Items {
+			s := &sts.Items[i]
+			result = append(result, podTemplate{s.Namespace, "StatefulSet", s.Name, s.Spec.Template.Labels})
+		}
+	}
+	return result
+}
+
+// vpaObject holds the VPA fields kubenow reads.
+type vpaObject struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		TargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"targetRef"`
+		UpdatePolicy *struct {
+			UpdateMode string `json:"updateMode"`
+		} `json:"updatePolicy,omitempty"`
+	} `json:"spec"`
+}
+
+func listVPAs(ctx context.Context, clientset kubernetes.Interface, namespace string) []vpaObject {
+	rc := clientset.Discovery().RESTClient()
+	if rc == nil {
+		return nil
+	}
+	path := vpaAPIPath + "/verticalpodautoscalers"
+	if namespace != corev1.NamespaceAll {
+		path = vpaAPIPath + "/namespaces/" + namespace + "/verticalpodautoscalers"
+	}
+	data, err := rc.Get().AbsPath(path).DoRaw(ctx)
+	if err != nil {
+		return nil
+	}
+	return parseVPAList(data)
+}
+
+func parseVPAList(data []byte) []vpaObject {
+	var list struct {
+		Items []vpaObject `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil
+	}
+	return list.Items
+}
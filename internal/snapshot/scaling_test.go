@@ -0,0 +1,142 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func TestBuildScaling(t *testing.T) {
+	minAvailable := intstr.FromInt32(2)
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api"}},
+			}},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+			Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+			}},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-hpa", Namespace: "prod"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "api"},
+				MinReplicas:    int32Ptr(2),
+				MaxReplicas:    10,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name:   corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: int32Ptr(70)},
+					},
+				}},
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 10, DesiredReplicas: 10},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-pdb", Namespace: "prod"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+		},
+	)
+
+	got := BuildScaling(context.Background(), client, "prod")
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "Deployment", got[0].Kind)
+	assert.Equal(t, "api", got[0].Name)
+	require.NotNil(t, got[0].HPA)
+	assert.Equal(t, int32(2), got[0].HPA.MinReplicas)
+	assert.Equal(t, int32(10), got[0].HPA.CurrentReplicas)
+	assert.Equal(t, []string{"cpu 70%"}, got[0].HPA.Metrics)
+	assert.Empty(t, got[0].PDBs)
+
+	assert.Equal(t, "StatefulSet", got[1].Kind)
+	assert.Nil(t, got[1].HPA)
+	require.Len(t, got[1].PDBs, 1)
+	assert.Equal(t, PDBSnapshot{Name: "db-pdb", MinAvailable: "2"}, got[1].PDBs[0])
+}
+
+func TestBuildScaling_Empty(t *testing.T) {
+	assert.Empty(t, BuildScaling(context.Background(), fake.NewSimpleClientset(), ""))
+}
+
+func TestHPAMetricSummary(t *testing.T) {
+	avg := resource.MustParse("512Mi")
+	tests := []struct {
+		name string
+		spec autoscalingv2.MetricSpec
+		want string
+	}{
+		{
+			name: "memory average value",
+			spec: autoscalingv2.MetricSpec{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceMemory,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &avg},
+				},
+			},
+			want: "memory 512Mi",
+		},
+		{
+			name: "container resource",
+			spec: autoscalingv2.MetricSpec{
+				Type: autoscalingv2.ContainerResourceMetricSourceType,
+				ContainerResource: &autoscalingv2.ContainerResourceMetricSource{
+					Name:      corev1.ResourceCPU,
+					Container: "app",
+					Target:    autoscalingv2.MetricTarget{AverageUtilization: int32Ptr(60)},
+				},
+			},
+			want: "app/cpu 60%",
+		},
+		{
+			name: "external",
+			spec: autoscalingv2.MetricSpec{
+				Type:     autoscalingv2.ExternalMetricSourceType,
+				External: &autoscalingv2.ExternalMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: "queue_depth"}},
+			},
+			want: "external queue_depth",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hpaMetricSummary(&tt.spec))
+		})
+	}
+}
+
+func TestParseVPAList(t *testing.T) {
+	data := []byte(`{"items":[
+		{"metadata":{"name":"api-vpa","namespace":"prod"},"spec":{"targetRef":{"kind":"Deployment","name":"api"},"updatePolicy":{"updateMode":"Auto"}}},
+		{"metadata":{"name":"bare","namespace":"prod"},"spec":{"targetRef":{"kind":"Deployment","name":"worker"}}}
+	]}`)
+
+	vpas := parseVPAList(data)
+	require.Len(t, vpas, 2)
+	assert.Equal(t, "api", vpas[0].Spec.TargetRef.Name)
+	require.NotNil(t, vpas[0].Spec.UpdatePolicy)
+	assert.Equal(t, "Auto", vpas[0].Spec.UpdatePolicy.UpdateMode)
+	assert.Nil(t, vpas[1].Spec.UpdatePolicy)
+
+	assert.Nil(t, parseVPAList([]byte("not json")))
+}
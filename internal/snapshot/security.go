@@ -0,0 +1,413 @@
+// This file gathers the RBAC, pod security, and network isolation posture
+// that the security prompt turns into a hardening report.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// podSecurityEnforceLabel carries a namespace's Pod Security Admission level.
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	// bootstrapRoleLabel marks the ClusterRoles Kubernetes creates itself.
+	bootstrapRoleLabel = "kubernetes.io/bootstrapping"
+
+	maxBindings            = 50
+	maxBroadRoles          = 30
+	maxPrivilegedWorkloads = 50
+	maxSecurityNamespaces  = 100
+)
+
+// SecuritySnapshot holds the cluster's security posture (collected in
+// security mode).
+type SecuritySnapshot struct {
+	Bindings   []BindingSnapshot           `json:"serviceAccountBindings,omitempty"`
+	BroadRoles []BroadRoleSnapshot         `json:"broadRoles,omitempty"`
+	Workloads  []WorkloadSecuritySnapshot  `json:"privilegedWorkloads,omitempty"`
+	Namespaces []NamespaceSecuritySnapshot `json:"namespaces,omitempty"`
+}
+
+// BindingSnapshot is a RoleBinding or ClusterRoleBinding that grants a role
+// to ServiceAccounts.
+type BindingSnapshot struct {
+	Kind            string   `json:"kind"` // RoleBinding|ClusterRoleBinding
+	Namespace       string   `json:"namespace,omitempty"`
+	Name            string   `json:"name"`
+	Role            string   `json:"role"`            // "ClusterRole/name" or "Role/name"
+	ServiceAccounts []string `json:"serviceAccounts"` // "namespace/name"
+	Broad           bool     `json:"broad,omitempty"` // the role is in BroadRoles
+}
+
+// BroadRoleSnapshot is a ClusterRole whose rules grant more than most
+// workloads need.
+type BroadRoleSnapshot struct {
+	Name    string   `json:"name"`
+	Reasons []string `json:"reasons"`
+	BoundTo []string `json:"boundTo,omitempty"` // ServiceAccounts, "namespace/name"
+}
+
+// WorkloadSecuritySnapshot is a workload whose pods run privileged, share
+// host namespaces, or mount host paths.
+type WorkloadSecuritySnapshot struct {
+	Namespace      string   `json:"namespace"`
+	Workload       string   `json:"workload"` // "Kind/name", or "Pod/name" for bare pods
+	ServiceAccount string   `json:"serviceAccount,omitempty"`
+	Issues         []string `json:"issues"`
+}
+
+// NamespaceSecuritySnapshot is the Pod Security level and NetworkPolicy
+// coverage of a namespace with pods.
+type NamespaceSecuritySnapshot struct {
+	Name            string `json:"name"`
+	Pods            int    `json:"pods"`
+	PodSecurity     string `json:"podSecurity,omitempty"` // enforce level; empty when unset
+	NetworkPolicies int    `json:"networkPolicies"`
+}
+
+// BuildSecurity returns the ServiceAccount role bindings, over-broad
+// ClusterRoles, privileged or host-mounting workloads, and per-namespace
+// Pod Security levels and NetworkPolicy counts of the filtered namespaces.
+// Each list is best-effort: missing RBAC leaves that part out.
+func BuildSecurity(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace string,
+	pods []*corev1.Pod,
+	filters *Filters,
+) *SecuritySnapshot {
+	sec := &SecuritySnapshot{}
+	sec.Bindings, sec.BroadRoles = buildRBAC(ctx, clientset, namespace, filters)
+	sec.Workloads = privilegedWorkloads(pods, filters)
+	sec.Namespaces = namespaceSecurity(ctx, clientset, namespace, pods, filters)
+	if len(sec.Bindings) == 0 && len(sec.BroadRoles) == 0 && len(sec.Workloads) == 0 && len(sec.Namespaces) == 0 {
+		return nil
+	}
+	return sec
+}
+
+// buildRBAC lists the bindings that grant roles to ServiceAccounts of the
+// filtered namespaces, and the broad ClusterRoles among them. Built-in
+// system: bindings and roles are left out; the built-in cluster-admin,
+// admin, and edit roles are listed only when bound to a ServiceAccount.
+func buildRBAC(ctx context.Context, clientset kubernetes.Interface, namespace string, filters *Filters) ([]BindingSnapshot, []BroadRoleSnapshot) {
+	var bindings []BindingSnapshot
+	if crbs, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range crbs.Items {
+			crb := &crbs.Items[i]
+			if b, ok := bindingSnapshot("ClusterRoleBinding", "", crb.Name, crb.RoleRef, crb.Subjects, namespace, filters); ok {
+				bindings = append(bindings, b)
+			}
+		}
+	}
+	if rbs, err := clientset.RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range rbs.Items {
+			rb := &rbs.Items[i]
+			if !filters.MatchesNamespace(rb.Namespace) {
+				continue
+			}
+			if b, ok := bindingSnapshot("RoleBinding", rb.Namespace, rb.Name, rb.RoleRef, rb.Subjects, namespace, filters); ok {
+				bindings = append(bindings, b)
+			}
+		}
+	}
+
+	boundTo := map[string][]string{} // ClusterRole name -> ServiceAccounts
+	for _, b := range bindings {
+		if name, ok := strings.CutPrefix(b.Role, "ClusterRole/"); ok {
+			boundTo[name] = append(boundTo[name], b.ServiceAccounts...)
+		}
+	}
+
+	var broad []BroadRoleSnapshot
+	if roles, err := clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range roles.Items {
+			role := &roles.Items[i]
+			if strings.HasPrefix(role.Name, "system:") {
+				continue
+			}
+			sas := boundTo[role.Name]
+			if role.Labels[bootstrapRoleLabel] != "" && len(sas) == 0 {
+				continue
+			}
+			reasons := broadRuleReasons(role.Rules)
+			if len(reasons) == 0 {
+				continue
+			}
+			broad = append(broad, BroadRoleSnapshot{Name: role.Name, Reasons: reasons, BoundTo: uniqueSorted(sas)})
+		}
+	}
+	broadNames := make(map[string]bool, len(broad))
+	for _, r := range broad {
+		broadNames[r.Name] = true
+	}
+	for i := range bindings {
+		if name, ok := strings.CutPrefix(bindings[i].Role, "ClusterRole/"); ok {
+			bindings[i].Broad = broadNames[name]
+		}
+	}
+
+	// Bound broad roles first, then by name
+	sort.SliceStable(broad, func(i, j int) bool {
+		if (len(broad[i].BoundTo) > 0) != (len(broad[j].BoundTo) > 0) {
+			return len(broad[i].BoundTo) > 0
+		}
+		return broad[i].Name < broad[j].Name
+	})
+	sort.SliceStable(bindings, func(i, j int) bool {
+		if bindings[i].Broad != bindings[j].Broad {
+			return bindings[i].Broad
+		}
+		if bindings[i].Namespace != bindings[j].Namespace {
+			return bindings[i].Namespace < bindings[j].Namespace
+		}
+		return bindings[i].Name < bindings[j].Name
+	})
+	if len(broad) > maxBroadRoles {
+		broad = broad[:maxBroadRoles]
+	}
+	if len(bindings) > maxBindings {
+		bindings = bindings[:maxBindings]
+	}
+	return bindings, broad
+}
+
+// bindingSnapshot keeps a binding's ServiceAccount subjects in the scanned
+// namespace and the filtered namespaces. It reports false for system:
+// bindings and bindings without such subjects.
+func bindingSnapshot(
+	kind, ns, name string,
+	ref rbacv1.RoleRef,
+	subjects []rbacv1.Subject,
+	namespace string,
+	filters *Filters,
+) (BindingSnapshot, bool) {
+	if strings.HasPrefix(name, "system:") {
+		return BindingSnapshot{}, false
+	}
+	var sas []string
+	for _, s := range subjects {
+		if s.Kind != rbacv1.ServiceAccountKind {
+			continue
+		}
+		saNamespace := s.Namespace
+		if saNamespace == "" {
+			saNamespace = ns
+		}
+		if (namespace != "" && saNamespace != namespace) || !filters.MatchesNamespace(saNamespace) {
+			continue
+		}
+		sas = append(sas, saNamespace+"/"+s.Name)
+	}
+	if len(sas) == 0 {
+		return BindingSnapshot{}, false
+	}
+	return BindingSnapshot{
+		Kind:            kind,
+		Namespace:       ns,
+		Name:            name,
+		Role:            ref.Kind + "/" + ref.Name,
+		ServiceAccounts: uniqueSorted(sas),
+	}, true
+}
+
+// broadRuleReasons describes what makes a role's rules over-broad: wildcard
+// verbs or resources, reading secrets, privilege escalation verbs, and
+// exec into pods.
+func broadRuleReasons(rules []rbacv1.PolicyRule) []string {
+	reasons := map[string]bool{}
+	for _, r := range rules {
+		if len(r.NonResourceURLs) > 0 && len(r.Resources) == 0 {
+			continue
+		}
+		allVerbs := slices.Contains(r.Verbs, "*")
+		if allVerbs {
+			reasons["wildcard verbs"] = true
+		}
+		if slices.Contains(r.Resources, "*") {
+			reasons["wildcard resources"] = true
+		}
+		if slices.Contains(r.APIGroups, "*") {
+			reasons["wildcard API groups"] = true
+		}
+		for _, v := range []string{"escalate", "bind", "impersonate"} {
+			if slices.Contains(r.Verbs, v) {
+				reasons["can "+v] = true
+			}
+		}
+		if slices.Contains(r.Resources, "secrets") &&
+			(allVerbs || slices.Contains(r.Verbs, "get") || slices.Contains(r.Verbs, "list") || slices.Contains(r.Verbs, "watch")) {
+			reasons["reads secrets"] = true
+		}
+		if slices.Contains(r.Resources, "pods/exec") && (allVerbs || slices.Contains(r.Verbs, "create")) {
+			reasons["can exec into pods"] = true
+		}
+	}
+	out := make([]string, 0, len(reasons))
+	for r := range reasons {
+		out = append(out, r)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// privilegedWorkloads lists the workloads of the filtered namespaces whose
+// pods run privileged or escalating containers, add capabilities, share
+// host namespaces, or mount host paths; one entry per workload.
+func privilegedWorkloads(pods []*corev1.Pod, filters *Filters) []WorkloadSecuritySnapshot {
+	seen := map[string]bool{}
+	var out []WorkloadSecuritySnapshot
+	for _, pod := range pods {
+		if !filters.MatchesNamespace(pod.Namespace) {
+			continue
+		}
+		issues := podSecurityIssues(pod)
+		if len(issues) == 0 {
+			continue
+		}
+		workload := owningWorkload(pod)
+		if workload == "" {
+			workload = "Pod/" + pod.Name
+		}
+		key := pod.Namespace + "/" + workload
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, WorkloadSecuritySnapshot{
+			Namespace:      pod.Namespace,
+			Workload:       workload,
+			ServiceAccount: pod.Spec.ServiceAccountName,
+			Issues:         issues,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Workload < out[j].Workload
+	})
+	if len(out) > maxPrivilegedWorkloads {
+		out = out[:maxPrivilegedWorkloads]
+	}
+	return out
+}
+
+// podSecurityIssues describes the privileged settings of a pod's spec.
+func podSecurityIssues(pod *corev1.Pod) []string {
+	var issues []string
+	if pod.Spec.HostNetwork {
+		issues = append(issues, "hostNetwork")
+	}
+	if pod.Spec.HostPID {
+		issues = append(issues, "hostPID")
+	}
+	if pod.Spec.HostIPC {
+		issues = append(issues, "hostIPC")
+	}
+	for i := range pod.Spec.Volumes {
+		if hp := pod.Spec.Volumes[i].HostPath; hp != nil {
+			issues = append(issues, "hostPath "+hp.Path)
+		}
+	}
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for i := range containers {
+		c := &containers[i]
+		sc := c.SecurityContext
+		if sc == nil {
+			continue
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			issues = append(issues, fmt.Sprintf("container %s privileged", c.Name))
+		}
+		if sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation {
+			issues = append(issues, fmt.Sprintf("container %s allows privilege escalation", c.Name))
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				issues = append(issues, fmt.Sprintf("container %s adds %s", c.Name, capability))
+			}
+		}
+	}
+	return issues
+}
+
+// namespaceSecurity lists the filtered namespaces that have pods with
+// their Pod Security enforce level and NetworkPolicy count.
+func namespaceSecurity(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace string,
+	pods []*corev1.Pod,
+	filters *Filters,
+) []NamespaceSecuritySnapshot {
+	podCounts := map[string]int{}
+	for _, pod := range pods {
+		if filters.MatchesNamespace(pod.Namespace) {
+			podCounts[pod.Namespace]++
+		}
+	}
+	if len(podCounts) == 0 {
+		return nil
+	}
+
+	levels := map[string]string{}
+	if namespace != "" {
+		if ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err == nil {
+			levels[ns.Name] = ns.Labels[podSecurityEnforceLabel]
+		}
+	} else if nsList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range nsList.Items {
+			levels[nsList.Items[i].Name] = nsList.Items[i].Labels[podSecurityEnforceLabel]
+		}
+	}
+	policies, err := clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// Without NetworkPolicy counts every namespace would look unisolated
+		return nil
+	}
+	policyCounts := map[string]int{}
+	for i := range policies.Items {
+		policyCounts[policies.Items[i].Namespace]++
+	}
+
+	out := make([]NamespaceSecuritySnapshot, 0, len(podCounts))
+	for ns, n := range podCounts {
+		out = append(out, NamespaceSecuritySnapshot{
+			Name:            ns,
+			Pods:            n,
+			PodSecurity:     levels[ns],
+			NetworkPolicies: policyCounts[ns],
+		})
+	}
+	// Unisolated namespaces first, then by name
+	sort.Slice(out, func(i, j int) bool {
+		if (out[i].NetworkPolicies == 0) != (out[j].NetworkPolicies == 0) {
+			return out[i].NetworkPolicies == 0
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > maxSecurityNamespaces {
+		out = out[:maxSecurityNamespaces]
+	}
+	return out
+}
+
+// uniqueSorted returns values sorted without duplicates.
+func uniqueSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := slices.Clone(values)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
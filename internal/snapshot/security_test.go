@@ -0,0 +1,149 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testSecurityPod(ns, name, sa string, mutate func(*corev1.Pod)) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: sa,
+			Containers:         []corev1.Container{{Name: "app"}},
+		},
+	}
+	if mutate != nil {
+		mutate(pod)
+	}
+	return pod
+}
+
+func TestBuildSecurity(t *testing.T) {
+	privileged := true
+	client := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci-deployer"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin", Labels: map[string]string{bootstrapRoleLabel: "rbac-defaults"}},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-reader"},
+			Rules:      []rbacv1.PolicyRule{{Resources: []string{"pods"}, Verbs: []string{"get", "list"}}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "system:controller:foo"},
+			Rules:      []rbacv1.PolicyRule{{Resources: []string{"secrets"}, Verbs: []string{"get"}}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "ci-deployer"},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Namespace: "ci", Name: "runner"},
+				{Kind: rbacv1.UserKind, Name: "alice"},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "system:controller:foo"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "system:controller:foo"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "kube-system", Name: "foo"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "read-pods", Namespace: "prod"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "pod-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "api"}},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{podSecurityEnforceLabel: "restricted"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ci"}},
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "prod"}},
+	)
+	pods := []*corev1.Pod{
+		testSecurityPod("prod", "api-0", "api", nil),
+		testSecurityPod("ci", "runner-0", "runner", func(p *corev1.Pod) {
+			p.Spec.HostNetwork = true
+			p.Spec.Volumes = []corev1.Volume{{Name: "docker", VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"},
+			}}}
+			p.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+				Privileged:   &privileged,
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}},
+			}
+		}),
+	}
+
+	sec := BuildSecurity(context.Background(), client, "", pods, &Filters{})
+	require.NotNil(t, sec)
+
+	require.Len(t, sec.Bindings, 2)
+	assert.Equal(t, BindingSnapshot{
+		Kind: "ClusterRoleBinding", Name: "ci", Role: "ClusterRole/ci-deployer",
+		ServiceAccounts: []string{"ci/runner"}, Broad: true,
+	}, sec.Bindings[0])
+	assert.Equal(t, "read-pods", sec.Bindings[1].Name)
+	assert.Equal(t, []string{"prod/api"}, sec.Bindings[1].ServiceAccounts)
+	assert.False(t, sec.Bindings[1].Broad)
+
+	require.Len(t, sec.BroadRoles, 1, "unbound built-in and system: roles are left out")
+	assert.Equal(t, "ci-deployer", sec.BroadRoles[0].Name)
+	assert.Equal(t, []string{"wildcard API groups", "wildcard resources", "wildcard verbs"}, sec.BroadRoles[0].Reasons)
+	assert.Equal(t, []string{"ci/runner"}, sec.BroadRoles[0].BoundTo)
+
+	require.Len(t, sec.Workloads, 1)
+	assert.Equal(t, "Pod/runner-0", sec.Workloads[0].Workload)
+	assert.Equal(t, "runner", sec.Workloads[0].ServiceAccount)
+	assert.Equal(t, []string{
+		"hostNetwork", "hostPath /var/run/docker.sock", "container app privileged", "container app adds SYS_ADMIN",
+	}, sec.Workloads[0].Issues)
+
+	assert.Equal(t, []NamespaceSecuritySnapshot{
+		{Name: "ci", Pods: 1, NetworkPolicies: 0},
+		{Name: "prod", Pods: 1, PodSecurity: "restricted", NetworkPolicies: 1},
+	}, sec.Namespaces)
+}
+
+func TestBuildSecurity_NamespaceFilter(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "ci", Name: "runner"}},
+		},
+	)
+	pods := []*corev1.Pod{testSecurityPod("ci", "runner-0", "runner", func(p *corev1.Pod) { p.Spec.HostPID = true })}
+
+	sec := BuildSecurity(context.Background(), client, "", pods, &Filters{ExcludeNamespaces: "ci"})
+	assert.Nil(t, sec)
+}
+
+func TestBroadRuleReasons(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []rbacv1.PolicyRule
+		want  []string
+	}{
+		{"read only", []rbacv1.PolicyRule{{Resources: []string{"pods"}, Verbs: []string{"get"}}}, []string{}},
+		{"secrets", []rbacv1.PolicyRule{{Resources: []string{"secrets"}, Verbs: []string{"list"}}}, []string{"reads secrets"}},
+		{"exec", []rbacv1.PolicyRule{{Resources: []string{"pods/exec"}, Verbs: []string{"create"}}}, []string{"can exec into pods"}},
+		{"escalation verbs", []rbacv1.PolicyRule{{Resources: []string{"clusterroles"}, Verbs: []string{"bind", "escalate"}}},
+			[]string{"can bind", "can escalate"}},
+		{"non-resource urls", []rbacv1.PolicyRule{{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, broadRuleReasons(tt.rules))
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// This file gathers pods, logs, events, node conditions.
+// This file gathers pods, logs, events, nodes, rollouts, workload scaling objects, and storage.
 
 // Package snapshot collects deterministic Kubernetes cluster snapshots.
 package snapshot
@@ -14,6 +14,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/ack"
 )
 
 // ContainerSnapshot describes a single container in a pod.
@@ -50,6 +52,15 @@ type PodSnapshot struct {
 	Containers []ContainerSnapshot `json:"containers"`
 	Events     []EventSnapshot     `json:"events,omitempty"`
 	Logs       string              `json:"logs,omitempty"`
+	Team       string              `json:"team,omitempty"` // owning team (see AssignOwners)
+
+	// Workload is the owning workload as "Kind/name", e.g. "Deployment/api";
+	// PodTemplateHash and Revision tie the pod to a rollout revision.
+	Workload        string `json:"workload,omitempty"`
+	PodTemplateHash string `json:"podTemplateHash,omitempty"`
+	Revision        int64  `json:"revision,omitempty"`
+
+	labels map[string]string // pod labels, for owners file selectors; not saved
 }
 
 // NodeConditionSnapshot flattens node conditions.
@@ -60,10 +71,18 @@ type NodeConditionSnapshot struct {
 	Message string `json:"message,omitempty"`
 }
 
-// NodeSnapshot is a node + its conditions.
+// NodeSnapshot is a node + its conditions, taints, request allocation, and
+// recent Warning events.
 type NodeSnapshot struct {
-	Name       string                  `json:"name"`
-	Conditions []NodeConditionSnapshot `json:"conditions"`
+	Name          string                  `json:"name"`
+	Conditions    []NodeConditionSnapshot `json:"conditions"`
+	Unschedulable bool                    `json:"unschedulable,omitempty"` // cordoned
+	Taints        []string                `json:"taints,omitempty"`        // key=value:Effect
+
+	// Allocation is only set for cluster-wide snapshots, where the pods on
+	// each node are known.
+	Allocation   *NodeAllocation `json:"allocation,omitempty"`
+	RecentEvents []EventSnapshot `json:"recentEvents,omitempty"`
 }
 
 // Snapshot is the whole thing the model sees.
@@ -72,6 +91,27 @@ type Snapshot struct {
 	Namespace      string         `json:"namespace,omitempty"`
 	ProblemPods    []PodSnapshot  `json:"problemPods"`
 	NodeConditions []NodeSnapshot `json:"nodeConditions"`
+
+	// WorkloadScaling lists existing HPAs, VPAs, and PDBs per workload so
+	// the model does not recommend creating ones that already exist.
+	WorkloadScaling []WorkloadScalingSnapshot `json:"workloadScaling,omitempty"`
+
+	// Rollouts holds Deployments that are mid-rollout, stuck, recently
+	// rolled out, or own a problem pod, with their latest revisions.
+	Rollouts []RolloutSnapshot `json:"rollouts,omitempty"`
+
+	// Storage holds the claims, volumes, storage classes, and attachments
+	// behind problem pods' volumes; only collected with Filters.IncludeStorage.
+	Storage *StorageSnapshot `json:"storage,omitempty"`
+
+	// Security holds ServiceAccount role bindings, over-broad ClusterRoles,
+	// privileged workloads, and namespace isolation; only collected with
+	// Filters.IncludeSecurity.
+	Security *SecuritySnapshot `json:"security,omitempty"`
+
+	// AcknowledgedProblems lists problems that were triaged and accepted
+	// (see SplitAcknowledged); their pods are not in ProblemPods.
+	AcknowledgedProblems []ack.Problem `json:"acknowledgedProblems,omitempty"`
 }
 
 // Filters controls what pods and content to include/exclude.
@@ -82,22 +122,64 @@ type Filters struct {
 	ExcludeNamespaces string
 	IncludeKeywords   string // comma-separated keywords to search in logs/events
 	ExcludeKeywords   string
+	IncludeStorage    bool // collect PVC, PV, StorageClass, and VolumeAttachment state
+	IncludeSecurity   bool // collect RBAC, privileged workload, and NetworkPolicy state
 }
 
 // BuildSnapshot collects:
 // - non-Running pods / pods with restarts / not-ready
 // - last N log lines for each bad pod
-// - all node conditions
+// - all node conditions, taints, request allocation, and recent node events
+// - HPA/VPA/PDB summaries per workload
+// - rollout status, revision history, and image changes of relevant Deployments
+// - each problem pod's owning workload and rollout revision
+// - with IncludeStorage, PVC, PV, StorageClass, and VolumeAttachment state
+// - with IncludeSecurity, RBAC bindings, privileged pods, and NetworkPolicy coverage
 // - applies include/exclude filters
 func BuildSnapshot(
 	ctx context.Context,
-	clientset *kubernetes.Clientset,
+	clientset kubernetes.Interface,
 	namespace string,
 	maxPods int,
 	logLines int,
 	maxConcurrent int,
 	filters *Filters,
 ) (*Snapshot, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	nodePtrs := make([]*corev1.Node, len(nodes.Items))
+	for i := range nodes.Items {
+		nodePtrs[i] = &nodes.Items[i]
+	}
+	podPtrs := make([]*corev1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		podPtrs[i] = &pods.Items[i]
+	}
+	return BuildSnapshotFrom(ctx, clientset, namespace, nodePtrs, podPtrs, maxPods, logLines, maxConcurrent, filters), nil
+}
+
+// BuildSnapshotFrom is BuildSnapshot on nodes and pods that are already at
+// hand, e.g. in an informer cache, so only problem pods' events and logs,
+// scaling objects, and rollouts are read from the API. Pods are taken in
+// the given order until maxPods problem pods are found.
+func BuildSnapshotFrom(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace string,
+	nodes []*corev1.Node,
+	pods []*corev1.Pod,
+	maxPods int,
+	logLines int,
+	maxConcurrent int,
+	filters *Filters,
+) *Snapshot {
 	if maxPods <= 0 {
 		maxPods = 20
 	}
@@ -114,13 +196,19 @@ func BuildSnapshot(
 	}
 
 	// --- Nodes ---
-	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("list nodes: %w", err)
+	var allocations map[string]*NodeAllocation
+	if namespace == "" {
+		allocations = nodeAllocations(nodes, pods)
 	}
-	for i := range nodes.Items {
-		node := &nodes.Items[i]
-		ns := NodeSnapshot{Name: node.Name}
+	nodeEvents := recentNodeEvents(ctx, clientset, snap.GeneratedAt)
+	for _, node := range nodes {
+		ns := NodeSnapshot{
+			Name:          node.Name,
+			Unschedulable: node.Spec.Unschedulable,
+			Taints:        nodeTaints(node),
+			Allocation:    allocations[node.Name],
+			RecentEvents:  nodeEvents[node.Name],
+		}
 		for j := range node.Status.Conditions {
 			condition := &node.Status.Conditions[j]
 			ns.Conditions = append(ns.Conditions, NodeConditionSnapshot{
@@ -134,19 +222,9 @@ func BuildSnapshot(
 	}
 
 	// --- Pods ---
-	podOpts := metav1.ListOptions{}
-	var podList *corev1.PodList
-	if namespace != "" {
-		podList, err = clientset.CoreV1().Pods(namespace).List(ctx, podOpts)
-	} else {
-		podList, err = clientset.CoreV1().Pods("").List(ctx, podOpts)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("list pods: %w", err)
-	}
-
-	for i := range podList.Items {
-		pod := &podList.Items[i]
+	problemReplicaSets := map[string]bool{}
+	claimPods := map[string][]string{} // namespace/claim -> problem pods
+	for _, pod := range pods {
 		if len(snap.ProblemPods) >= maxPods {
 			break
 		}
@@ -157,6 +235,12 @@ func BuildSnapshot(
 		}
 
 		snap.ProblemPods = append(snap.ProblemPods, *ps)
+		if rs := replicaSetOwner(pod); rs != "" {
+			problemReplicaSets[rs] = true
+		}
+		for _, claim := range problemPodClaims(pod) {
+			claimPods[claim] = append(claimPods[claim], pod.Name)
+		}
 	}
 
 	// Fetch logs concurrently with controlled parallelism to avoid API throttling
@@ -198,12 +282,51 @@ func BuildSnapshot(
 	}
 	wg.Wait()
 
-	return snap, nil
+	// --- Autoscaling and disruption budgets ---
+	for _, ws := range BuildScaling(ctx, clientset, namespace) {
+		if matchesFilter(ws.Namespace, filters.IncludeNamespaces, filters.ExcludeNamespaces) {
+			snap.WorkloadScaling = append(snap.WorkloadScaling, ws)
+		}
+	}
+
+	// --- Rollouts ---
+	for _, r := range BuildRollouts(ctx, clientset, namespace, problemReplicaSets, snap.GeneratedAt) {
+		if matchesFilter(r.Namespace, filters.IncludeNamespaces, filters.ExcludeNamespaces) {
+			snap.Rollouts = append(snap.Rollouts, r)
+		}
+	}
+	linkRevisions(snap)
+
+	// --- Storage ---
+	if filters.IncludeStorage {
+		snap.Storage = BuildStorage(ctx, clientset, namespace, claimPods, filters)
+	}
+
+	// --- Security ---
+	if filters.IncludeSecurity {
+		snap.Security = BuildSecurity(ctx, clientset, namespace, pods, filters)
+	}
+
+	return snap
+}
+
+// IsProblemPod reports whether a pod belongs in a snapshot: it is not
+// running, has restarted, or has a container that is not ready.
+func IsProblemPod(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return true
+	}
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].RestartCount > 0 || !pod.Status.ContainerStatuses[i].Ready {
+			return true
+		}
+	}
+	return false
 }
 
 func buildPodSnapshot(
 	ctx context.Context,
-	clientset *kubernetes.Clientset,
+	clientset kubernetes.Interface,
 	pod *corev1.Pod,
 	filters *Filters,
 ) (*PodSnapshot, bool) {
@@ -227,7 +350,7 @@ func buildPodSnapshot(
 		}
 	}
 
-	if phase == "Running" && restarts == 0 && allReady {
+	if !IsProblemPod(pod) {
 		return nil, true
 	}
 
@@ -239,6 +362,10 @@ func buildPodSnapshot(
 		Ready:     allReady,
 		Restarts:  restarts,
 		Reason:    status.Reason,
+		labels:    pod.Labels,
+
+		Workload:        owningWorkload(pod),
+		PodTemplateHash: pod.Labels[podTemplateHashLabel],
 	}
 
 	for i := range status.ContainerStatuses {
@@ -303,6 +430,11 @@ func buildContainerSnapshot(cs corev1.ContainerStatus) ContainerSnapshot {
 	return snap
 }
 
+// MatchesNamespace reports whether the namespace filters select ns.
+func (f *Filters) MatchesNamespace(ns string) bool {
+	return matchesFilter(ns, f.IncludeNamespaces, f.ExcludeNamespaces)
+}
+
 // matchesFilter checks if a string matches the include/exclude patterns.
 // Patterns are comma-separated and support wildcard matching.
 func matchesFilter(value, includePatterns, excludePatterns string) bool {
@@ -0,0 +1,306 @@
+// This file gathers PersistentVolumeClaim, PersistentVolume, StorageClass,
+// and VolumeAttachment state for pods stuck on their volumes.
+
+package snapshot
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// defaultClassAnnotation marks the cluster's default StorageClass.
+	defaultClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+	maxClaims      = 50
+	maxClaimEvents = 5
+)
+
+// StorageSnapshot holds the storage objects behind problem pods' volumes
+// (collected with --include-storage).
+type StorageSnapshot struct {
+	Claims         []ClaimSnapshot        `json:"claims,omitempty"`
+	Volumes        []VolumeSnapshot       `json:"volumes,omitempty"`
+	StorageClasses []StorageClassSnapshot `json:"storageClasses,omitempty"`
+	Attachments    []AttachmentSnapshot   `json:"attachments,omitempty"`
+}
+
+// ClaimSnapshot is a PersistentVolumeClaim with its binding status.
+type ClaimSnapshot struct {
+	Namespace    string          `json:"namespace"`
+	Name         string          `json:"name"`
+	Phase        string          `json:"phase"` // Pending|Bound|Lost
+	StorageClass string          `json:"storageClass,omitempty"`
+	Volume       string          `json:"volume,omitempty"`
+	Requested    string          `json:"requested,omitempty"`
+	Capacity     string          `json:"capacity,omitempty"`
+	AccessModes  []string        `json:"accessModes,omitempty"`
+	UsedBy       []string        `json:"usedBy,omitempty"` // problem pods mounting the claim
+	Events       []EventSnapshot `json:"events,omitempty"`
+}
+
+// VolumeSnapshot is a PersistentVolume bound to one of the claims.
+type VolumeSnapshot struct {
+	Name          string `json:"name"`
+	Phase         string `json:"phase"` // Available|Bound|Released|Failed
+	Reason        string `json:"reason,omitempty"`
+	Message       string `json:"message,omitempty"`
+	StorageClass  string `json:"storageClass,omitempty"`
+	ReclaimPolicy string `json:"reclaimPolicy,omitempty"`
+	Driver        string `json:"driver,omitempty"` // CSI driver
+	NodeAffinity  bool   `json:"nodeAffinity,omitempty"`
+}
+
+// StorageClassSnapshot is a StorageClass the claims use.
+type StorageClassSnapshot struct {
+	Name              string `json:"name"`
+	Provisioner       string `json:"provisioner"`
+	VolumeBindingMode string `json:"volumeBindingMode,omitempty"`
+	ReclaimPolicy     string `json:"reclaimPolicy,omitempty"`
+	AllowExpansion    bool   `json:"allowVolumeExpansion,omitempty"`
+	Default           bool   `json:"default,omitempty"`
+}
+
+// AttachmentSnapshot is a CSI VolumeAttachment of one of the volumes.
+type AttachmentSnapshot struct {
+	Volume      string `json:"volume"`
+	Node        string `json:"node"`
+	Attached    bool   `json:"attached"`
+	AttachError string `json:"attachError,omitempty"`
+	DetachError string `json:"detachError,omitempty"`
+}
+
+// problemPodClaims returns the PersistentVolumeClaims a pod mounts as
+// "namespace/claim" keys.
+func problemPodClaims(pod *corev1.Pod) []string {
+	var claims []string
+	for i := range pod.Spec.Volumes {
+		if pvc := pod.Spec.Volumes[i].PersistentVolumeClaim; pvc != nil {
+			claims = append(claims, pod.Namespace+"/"+pvc.ClaimName)
+		}
+	}
+	return claims
+}
+
+// BuildStorage returns the claims mounted by problem pods (claimPods maps
+// "namespace/claim" to pod names) plus every claim in the filtered
+// namespaces that is not Bound, with their Warning events, bound volumes,
+// storage classes, and attachments. Each list is best-effort: missing RBAC
+// leaves that part out.
+func BuildStorage(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace string,
+	claimPods map[string][]string,
+	filters *Filters,
+) *StorageSnapshot {
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	storage := &StorageSnapshot{}
+	classes := map[string]bool{}
+	volumes := map[string]bool{}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		key := pvc.Namespace + "/" + pvc.Name
+		usedBy := claimPods[key]
+		if len(usedBy) == 0 && (pvc.Status.Phase == corev1.ClaimBound || !filters.MatchesNamespace(pvc.Namespace)) {
+			continue
+		}
+		claim := ClaimSnapshot{
+			Namespace: pvc.Namespace,
+			Name:      pvc.Name,
+			Phase:     string(pvc.Status.Phase),
+			Volume:    pvc.Spec.VolumeName,
+			UsedBy:    usedBy,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			claim.StorageClass = *pvc.Spec.StorageClassName
+			classes[claim.StorageClass] = true
+		}
+		if req, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			claim.Requested = req.String()
+		}
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			claim.Capacity = capacity.String()
+		}
+		for _, mode := range pvc.Spec.AccessModes {
+			claim.AccessModes = append(claim.AccessModes, string(mode))
+		}
+		if claim.Volume != "" {
+			volumes[claim.Volume] = true
+		}
+		storage.Claims = append(storage.Claims, claim)
+	}
+	if len(storage.Claims) == 0 {
+		return nil
+	}
+
+	// Claims of problem pods first, then by name
+	sort.SliceStable(storage.Claims, func(i, j int) bool {
+		ui, uj := len(storage.Claims[i].UsedBy) > 0, len(storage.Claims[j].UsedBy) > 0
+		if ui != uj {
+			return ui
+		}
+		return storage.Claims[i].Namespace+"/"+storage.Claims[i].Name < storage.Claims[j].Namespace+"/"+storage.Claims[j].Name
+	})
+	if len(storage.Claims) > maxClaims {
+		storage.Claims = storage.Claims[:maxClaims]
+	}
+
+	addClaimEvents(ctx, clientset, namespace, storage.Claims)
+	storage.Volumes = buildVolumes(ctx, clientset, volumes, classes)
+	storage.StorageClasses = buildStorageClasses(ctx, clientset, classes)
+	storage.Attachments = buildAttachments(ctx, clientset, volumes)
+	return storage
+}
+
+// addClaimEvents attaches Warning events (ProvisioningFailed,
+// FailedBinding, ...) to the claims.
+func addClaimEvents(ctx context.Context, clientset kubernetes.Interface, namespace string, claims []ClaimSnapshot) {
+	evts, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=PersistentVolumeClaim",
+	})
+	if err != nil {
+		return
+	}
+	index := make(map[string]*ClaimSnapshot, len(claims))
+	for i := range claims {
+		index[claims[i].Namespace+"/"+claims[i].Name] = &claims[i]
+	}
+	for i := range evts.Items {
+		event := &evts.Items[i]
+		claim := index[event.InvolvedObject.Namespace+"/"+event.InvolvedObject.Name]
+		if claim == nil || event.InvolvedObject.Kind != "PersistentVolumeClaim" || event.Type != corev1.EventTypeWarning {
+			continue
+		}
+		claim.Events = append(claim.Events, EventSnapshot{
+			Type:      event.Type,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     event.Count,
+			FirstTime: event.FirstTimestamp.Time,
+			LastTime:  event.LastTimestamp.Time,
+		})
+	}
+	for i := range claims {
+		events := claims[i].Events
+		sort.Slice(events, func(a, b int) bool { return events[a].LastTime.After(events[b].LastTime) })
+		if len(events) > maxClaimEvents {
+			claims[i].Events = events[:maxClaimEvents]
+		}
+	}
+}
+
+// buildVolumes returns the named PersistentVolumes, adding their storage
+// classes to classes.
+func buildVolumes(ctx context.Context, clientset kubernetes.Interface, names, classes map[string]bool) []VolumeSnapshot {
+	if len(names) == 0 {
+		return nil
+	}
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	var volumes []VolumeSnapshot
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if !names[pv.Name] {
+			continue
+		}
+		v := VolumeSnapshot{
+			Name:          pv.Name,
+			Phase:         string(pv.Status.Phase),
+			Reason:        pv.Status.Reason,
+			Message:       pv.Status.Message,
+			StorageClass:  pv.Spec.StorageClassName,
+			ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+			NodeAffinity:  pv.Spec.NodeAffinity != nil,
+		}
+		if pv.Spec.CSI != nil {
+			v.Driver = pv.Spec.CSI.Driver
+		}
+		if v.StorageClass != "" {
+			classes[v.StorageClass] = true
+		}
+		volumes = append(volumes, v)
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes
+}
+
+// buildStorageClasses returns the named StorageClasses and the default one,
+// which claims without a class are provisioned from.
+func buildStorageClasses(ctx context.Context, clientset kubernetes.Interface, names map[string]bool) []StorageClassSnapshot {
+	list, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	var classes []StorageClassSnapshot
+	for i := range list.Items {
+		sc := &list.Items[i]
+		isDefault := sc.Annotations[defaultClassAnnotation] == "true"
+		if !names[sc.Name] && !isDefault {
+			continue
+		}
+		c := StorageClassSnapshot{
+			Name:        sc.Name,
+			Provisioner: sc.Provisioner,
+			Default:     isDefault,
+		}
+		if sc.VolumeBindingMode != nil {
+			c.VolumeBindingMode = string(*sc.VolumeBindingMode)
+		}
+		if sc.ReclaimPolicy != nil {
+			c.ReclaimPolicy = string(*sc.ReclaimPolicy)
+		}
+		if sc.AllowVolumeExpansion != nil {
+			c.AllowExpansion = *sc.AllowVolumeExpansion
+		}
+		classes = append(classes, c)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes
+}
+
+// buildAttachments returns the VolumeAttachments of the named volumes.
+func buildAttachments(ctx context.Context, clientset kubernetes.Interface, volumes map[string]bool) []AttachmentSnapshot {
+	if len(volumes) == 0 {
+		return nil
+	}
+	list, err := clientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	var attachments []AttachmentSnapshot
+	for i := range list.Items {
+		va := &list.Items[i]
+		pv := va.Spec.Source.PersistentVolumeName
+		if pv == nil || !volumes[*pv] {
+			continue
+		}
+		attachments = append(attachments, AttachmentSnapshot{
+			Volume:      *pv,
+			Node:        va.Spec.NodeName,
+			Attached:    va.Status.Attached,
+			AttachError: volumeError(va.Status.AttachError),
+			DetachError: volumeError(va.Status.DetachError),
+		})
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].Volume < attachments[j].Volume })
+	return attachments
+}
+
+func volumeError(err *storagev1.VolumeError) string {
+	if err == nil {
+		return ""
+	}
+	return err.Message
+}
@@ -0,0 +1,120 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testClaim(ns, name, class, volume string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &class,
+			VolumeName:       volume,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse("10Gi"),
+			}},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func TestBuildStorage(t *testing.T) {
+	waitForConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	pvName := "pv-db"
+	client := fake.NewSimpleClientset(
+		testClaim("prod", "db-data", "fast", "pv-db", corev1.ClaimBound),
+		testClaim("prod", "cache", "fast", "", corev1.ClaimPending),
+		testClaim("prod", "logs", "fast", "pv-logs", corev1.ClaimBound),
+		testClaim("kube-system", "etcd-backup", "fast", "", corev1.ClaimPending),
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-db"},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName: "fast",
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com"},
+				},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: "fast"},
+			Provisioner:       "ebs.csi.aws.com",
+			VolumeBindingMode: &waitForConsumer,
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{defaultClassAnnotation: "true"}},
+			Provisioner: "kubernetes.io/no-provisioner",
+		},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "unused"}, Provisioner: "nfs"},
+		&storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-1"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				NodeName: "node-a",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+			},
+			Status: storagev1.VolumeAttachmentStatus{AttachError: &storagev1.VolumeError{Message: "volume is attached to node-b"}},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "e1", Namespace: "prod"},
+			InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "prod", Name: "cache"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "ProvisioningFailed",
+			LastTimestamp:  metav1.NewTime(time.Now()),
+		},
+	)
+
+	storage := BuildStorage(context.Background(), client, "", map[string][]string{"prod/db-data": {"db-0"}},
+		&Filters{ExcludeNamespaces: "kube-system"})
+	require.NotNil(t, storage)
+
+	require.Len(t, storage.Claims, 2, "bound claims without problem pods and excluded namespaces are left out")
+	assert.Equal(t, "db-data", storage.Claims[0].Name, "problem pods' claims first")
+	assert.Equal(t, []string{"db-0"}, storage.Claims[0].UsedBy)
+	assert.Equal(t, "10Gi", storage.Claims[0].Requested)
+	assert.Equal(t, "cache", storage.Claims[1].Name)
+	assert.Equal(t, "Pending", storage.Claims[1].Phase)
+	require.Len(t, storage.Claims[1].Events, 1)
+	assert.Equal(t, "ProvisioningFailed", storage.Claims[1].Events[0].Reason)
+
+	require.Len(t, storage.Volumes, 1)
+	assert.Equal(t, "ebs.csi.aws.com", storage.Volumes[0].Driver)
+
+	require.Len(t, storage.StorageClasses, 2, "used and default classes")
+	assert.Equal(t, "WaitForFirstConsumer", storage.StorageClasses[0].VolumeBindingMode)
+	assert.True(t, storage.StorageClasses[1].Default)
+
+	require.Len(t, storage.Attachments, 1)
+	assert.Equal(t, "volume is attached to node-b", storage.Attachments[0].AttachError)
+}
+
+func TestBuildSnapshot_IncludeStorage(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "prod"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "db-data"},
+		}}}},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	client := fake.NewSimpleClientset(pod, testClaim("prod", "db-data", "fast", "pv-db", corev1.ClaimBound))
+
+	snap, err := BuildSnapshot(context.Background(), client, "", 10, 10, 1, &Filters{})
+	require.NoError(t, err)
+	assert.Nil(t, snap.Storage, "storage is opt-in")
+
+	snap, err = BuildSnapshot(context.Background(), client, "", 10, 10, 1, &Filters{IncludeStorage: true})
+	require.NoError(t, err)
+	require.NotNil(t, snap.Storage)
+	require.Len(t, snap.Storage.Claims, 1)
+	assert.Equal(t, []string{"db-0"}, snap.Storage.Claims[0].UsedBy)
+}
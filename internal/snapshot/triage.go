@@ -0,0 +1,92 @@
+package snapshot
+
+// TriageSummary counts snapshot problems by deterministic severity.
+// Each pod is counted once, at its highest severity.
+type TriageSummary struct {
+	Fatal    int `json:"fatal"`
+	Critical int `json:"critical"`
+	Warning  int `json:"warning"`
+}
+
+// Total returns the number of triaged problems.
+func (t TriageSummary) Total() int {
+	return t.Fatal + t.Critical + t.Warning
+}
+
+// Triage classifies problem pods and node conditions using the same rules as
+// the real-time monitor: crash loops and OOMKills are fatal; image pull
+// failures, evictions, failed pods and unhealthy nodes are critical; anything
+// else that made it into the snapshot is a warning.
+func Triage(s *Snapshot) TriageSummary {
+	var t TriageSummary
+	if s == nil {
+		return t
+	}
+
+	for i := range s.ProblemPods {
+		switch podSeverity(&s.ProblemPods[i]) {
+		case severityFatal:
+			t.Fatal++
+		case severityCritical:
+			t.Critical++
+		default:
+			t.Warning++
+		}
+	}
+
+	for i := range s.NodeConditions {
+		if NodeUnhealthy(&s.NodeConditions[i]) {
+			t.Critical++
+		}
+	}
+
+	return t
+}
+
+type triageSeverity int
+
+const (
+	severityWarning triageSeverity = iota
+	severityCritical
+	severityFatal
+)
+
+// PodRank orders problem pods by triage severity: 2 for fatal, 1 for
+// critical, 0 for warning.
+func PodRank(p *PodSnapshot) int {
+	return int(podSeverity(p))
+}
+
+func podSeverity(p *PodSnapshot) triageSeverity {
+	sev := severityWarning
+	if p.Phase == "Failed" || p.Reason == "Evicted" {
+		sev = severityCritical
+	}
+	for i := range p.Containers {
+		c := &p.Containers[i]
+		switch {
+		case c.StateReason == "CrashLoopBackOff" || c.LastStateReason == "OOMKilled" || c.StateReason == "OOMKilled":
+			return severityFatal
+		case c.StateReason == "ImagePullBackOff" || c.StateReason == "ErrImagePull":
+			sev = severityCritical
+		}
+	}
+	return sev
+}
+
+// NodeUnhealthy reports a node that is not Ready or under resource pressure.
+func NodeUnhealthy(n *NodeSnapshot) bool {
+	for _, c := range n.Conditions {
+		switch c.Type {
+		case "Ready":
+			if c.Status != "True" {
+				return true
+			}
+		case "MemoryPressure", "DiskPressure", "PIDPressure":
+			if c.Status == "True" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriage(t *testing.T) {
+	s := &Snapshot{
+		ProblemPods: []PodSnapshot{
+			{Name: "crash", Phase: "Running", Containers: []ContainerSnapshot{{StateReason: "CrashLoopBackOff"}}},
+			{Name: "oom", Phase: "Running", Containers: []ContainerSnapshot{{LastStateReason: "OOMKilled"}}},
+			{Name: "pull", Phase: "Pending", Containers: []ContainerSnapshot{{StateReason: "ImagePullBackOff"}}},
+			{Name: "evicted", Phase: "Failed", Reason: "Evicted"},
+			{Name: "notready", Phase: "Running", Restarts: 1},
+		},
+		NodeConditions: []NodeSnapshot{
+			{Name: "ok", Conditions: []NodeConditionSnapshot{{Type: "Ready", Status: "True"}}},
+			{Name: "down", Conditions: []NodeConditionSnapshot{{Type: "Ready", Status: "Unknown"}}},
+			{Name: "pressure", Conditions: []NodeConditionSnapshot{
+				{Type: "Ready", Status: "True"},
+				{Type: "MemoryPressure", Status: "True"},
+			}},
+		},
+	}
+
+	got := Triage(s)
+	assert.Equal(t, TriageSummary{Fatal: 2, Critical: 4, Warning: 1}, got)
+	assert.Equal(t, 7, got.Total())
+}
+
+func TestTriage_Nil(t *testing.T) {
+	assert.Equal(t, TriageSummary{}, Triage(nil))
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigMap metadata identifying stored artifacts.
+const (
+	managedByLabel      = "app.kubernetes.io/managed-by"
+	managedByValue      = "kubenow"
+	artifactKindLabel   = "kubenow.io/artifact-kind"
+	artifactKeyAnno     = "kubenow.io/artifact-key"
+	artifactUpdatedAnno = "kubenow.io/artifact-updated"
+	configMapDataKey    = "data"
+
+	// maxConfigMapData leaves headroom under the 1 MiB object limit for metadata.
+	maxConfigMapData = 1000 * 1024
+)
+
+// ConfigMapStore keeps each object in its own ConfigMap, so artifacts live
+// in the cluster they describe and follow its RBAC and backups.
+type ConfigMapStore struct {
+	client    kubernetes.Interface
+	namespace string
+	now       func() time.Time
+}
+
+// NewConfigMap returns a store writing ConfigMaps in namespace.
+func NewConfigMap(client kubernetes.Interface, namespace string) *ConfigMapStore {
+	return &ConfigMapStore{client: client, namespace: namespace, now: time.Now}
+}
+
+// configMapName derives a stable, DNS-safe name from the key. The key
+// itself is kept in an annotation.
+func configMapName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	kind := strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') || r == '-' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(KindOf(key)))
+	return "kubenow-" + kind + "-" + hex.EncodeToString(sum[:8])
+}
+
+// Put creates or updates the ConfigMap for key.
+func (s *ConfigMapStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if len(data) > maxConfigMapData {
+		return fmt.Errorf("%s is %d bytes, too large for a ConfigMap (max %d)", key, len(data), maxConfigMapData)
+	}
+
+	cms := s.client.CoreV1().ConfigMaps(s.namespace)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName(key),
+			Namespace: s.namespace,
+			Labels:    map[string]string{managedByLabel: managedByValue, artifactKindLabel: KindOf(key)},
+			Annotations: map[string]string{
+				artifactKeyAnno:     key,
+				artifactUpdatedAnno: s.now().UTC().Format(time.RFC3339),
+			},
+		},
+		BinaryData: map[string][]byte{configMapDataKey: data},
+	}
+	_, err := cms.Create(ctx, cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		existing, getErr := cms.Get(ctx, cm.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return fmt.Errorf("failed to update ConfigMap %s/%s: %w", s.namespace, cm.Name, getErr)
+		}
+		cm.ResourceVersion = existing.ResourceVersion
+		_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write ConfigMap %s/%s: %w", s.namespace, cm.Name, err)
+	}
+	return nil
+}
+
+// Get reads the ConfigMap for key.
+func (s *ConfigMapStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	name := configMapName(key)
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ConfigMap %s/%s: %w", s.namespace, name, err)
+	}
+	if cm.Annotations[artifactKeyAnno] != key {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return cm.BinaryData[configMapDataKey], nil
+}
+
+// List returns the kubenow-managed ConfigMaps whose key has the prefix.
+func (s *ConfigMapStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	cms, err := s.client.CoreV1().ConfigMaps(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabel + "=" + managedByValue,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ConfigMaps in %s: %w", s.namespace, err)
+	}
+	var objects []Object
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		key, ok := cm.Annotations[artifactKeyAnno]
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		modTime := cm.CreationTimestamp.Time
+		if t, err := time.Parse(time.RFC3339, cm.Annotations[artifactUpdatedAnno]); err == nil {
+			modTime = t
+		}
+		objects = append(objects, Object{Key: key, Size: int64(len(cm.BinaryData[configMapDataKey])), ModTime: modTime})
+	}
+	return objects, nil
+}
+
+// Delete removes the ConfigMap for key.
+func (s *ConfigMapStore) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	name := configMapName(key)
+	err := s.client.CoreV1().ConfigMaps(s.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ConfigMap %s/%s: %w", s.namespace, name, err)
+	}
+	return nil
+}
+
+// Location returns a configmap:// reference for key.
+func (s *ConfigMapStore) Location(key string) string {
+	return "configmap://" + s.namespace + "/" + configMapName(key)
+}
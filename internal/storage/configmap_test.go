@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigMapStore(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	s := NewConfigMap(client, "kubenow")
+	s.now = func() time.Time { return time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC) }
+
+	key := "latch/prod__Deployment__api.json"
+	require.NoError(t, s.Put(ctx, key, []byte("v1")))
+	require.NoError(t, s.Put(ctx, key, []byte("v2")))
+	require.NoError(t, s.Put(ctx, "trends/2026-01-01T000000Z.json", []byte("t")))
+
+	data, err := s.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+
+	cm, err := client.CoreV1().ConfigMaps("kubenow").Get(ctx, configMapName(key), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "latch", cm.Labels[artifactKindLabel])
+	assert.Equal(t, key, cm.Annotations[artifactKeyAnno])
+	assert.Regexp(t, `^kubenow-latch-[0-9a-f]{16}$`, cm.Name)
+
+	objects, err := s.List(ctx, "latch/")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, key, objects[0].Key)
+	assert.Equal(t, int64(2), objects[0].Size)
+	assert.Equal(t, s.now(), objects[0].ModTime)
+
+	require.NoError(t, s.Delete(ctx, key))
+	require.NoError(t, s.Delete(ctx, key))
+	_, err = s.Get(ctx, key)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	err = s.Put(ctx, "trends/huge.json", make([]byte, maxConfigMapData+1))
+	assert.ErrorContains(t, err, "too large for a ConfigMap")
+}
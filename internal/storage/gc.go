@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Retention limits how long artifacts of one kind are kept.
+type Retention struct {
+	MaxAge   time.Duration // objects older than this are deleted (0 = keep forever)
+	KeepLast int           // the newest N objects are kept regardless of age
+}
+
+// DefaultRetention returns the retention applied by `kubenow storage gc`
+// when no overrides are given. Latch results older than a month no longer
+// describe the workload; trend history is kept for a year. Shared evidence
+// pages outlive their links by a few weeks at most. Checkpoints of
+// unfinished latches are only worth resuming for a week.
+func DefaultRetention() map[string]Retention {
+	return map[string]Retention{
+		KindLatch:    {MaxAge: 30 * 24 * time.Hour, KeepLast: 0},
+		KindTrends:   {MaxAge: 365 * 24 * time.Hour, KeepLast: 1},
+		KindShares:   {MaxAge: 30 * 24 * time.Hour, KeepLast: 0},
+		KindSessions: {MaxAge: 7 * 24 * time.Hour, KeepLast: 0},
+	}
+}
+
+// GCResult reports what a garbage collection removed.
+type GCResult struct {
+	DryRun     bool     `json:"dry_run"`
+	Scanned    int      `json:"scanned"`
+	Kept       int      `json:"kept"`
+	Deleted    []Object `json:"deleted"`
+	FreedBytes int64    `json:"freed_bytes"`
+}
+
+// GC deletes the objects each kind's retention expires, as of now. Kinds
+// without a policy, or whose MaxAge is zero, are left alone. With dryRun
+// set nothing is deleted, but the result lists what would be.
+func GC(ctx context.Context, s Store, policy map[string]Retention, now time.Time, dryRun bool) (*GCResult, error) {
+	kinds := make([]string, 0, len(policy))
+	for kind := range policy {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	result := &GCResult{DryRun: dryRun, Deleted: []Object{}}
+	for _, kind := range kinds {
+		r := policy[kind]
+		if r.MaxAge <= 0 {
+			continue
+		}
+		objects, err := s.List(ctx, kind+"/")
+		if err != nil {
+			return nil, err
+		}
+		result.Scanned += len(objects)
+		for _, obj := range Expired(objects, r, now) {
+			if !dryRun {
+				if err := s.Delete(ctx, obj.Key); err != nil {
+					return result, fmt.Errorf("gc %s: %w", kind, err)
+				}
+			}
+			result.Deleted = append(result.Deleted, obj)
+			result.FreedBytes += obj.Size
+		}
+	}
+	result.Kept = result.Scanned - len(result.Deleted)
+	return result, nil
+}
+
+// Expired returns the objects the retention removes as of now, newest first.
+func Expired(objects []Object, r Retention, now time.Time) []Object {
+	sorted := append([]Object(nil), objects...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ModTime.After(sorted[j].ModTime)
+	})
+	var expired []Object
+	for i, obj := range sorted {
+		if i < r.KeepLast || r.MaxAge <= 0 {
+			continue
+		}
+		if now.Sub(obj.ModTime) > r.MaxAge {
+			expired = append(expired, obj)
+		}
+	}
+	return expired
+}
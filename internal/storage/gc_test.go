@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	objects := []Object{
+		{Key: "a", ModTime: now.Add(-40 * day)},
+		{Key: "b", ModTime: now.Add(-1 * day)},
+		{Key: "c", ModTime: now.Add(-35 * day)},
+		{Key: "d", ModTime: now.Add(-50 * day)},
+	}
+	tests := []struct {
+		name string
+		r    Retention
+		want []string
+	}{
+		{"age only", Retention{MaxAge: 30 * day}, []string{"c", "a", "d"}},
+		{"keep last protects newest", Retention{MaxAge: 30 * day, KeepLast: 3}, []string{"d"}},
+		{"no max age keeps everything", Retention{KeepLast: 1}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, o := range Expired(objects, tt.r, now) {
+				got = append(got, o.Key)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGC(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s := NewLocal(root)
+	now := time.Now()
+	old := now.Add(-60 * 24 * time.Hour)
+
+	for _, key := range []string{"latch/old.json", "latch/new.json", "trends/old.json"} {
+		require.NoError(t, s.Put(ctx, key, []byte("12345")))
+	}
+	for _, key := range []string{"latch/old.json", "trends/old.json"} {
+		require.NoError(t, os.Chtimes(filepath.Join(root, key), old, old))
+	}
+
+	policy := map[string]Retention{KindLatch: {MaxAge: 30 * 24 * time.Hour}, KindTrends: {MaxAge: 0}}
+
+	res, err := GC(ctx, s, policy, now, true)
+	require.NoError(t, err)
+	assert.True(t, res.DryRun)
+	assert.Equal(t, 2, res.Scanned)
+	require.Len(t, res.Deleted, 1)
+	assert.Equal(t, "latch/old.json", res.Deleted[0].Key)
+	_, err = s.Get(ctx, "latch/old.json")
+	require.NoError(t, err)
+
+	res, err = GC(ctx, s, policy, now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Kept)
+	assert.Equal(t, int64(5), res.FreedBytes)
+	_, err = s.Get(ctx, "latch/old.json")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(ctx, "trends/old.json")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s := NewLocal(root)
+
+	require.NoError(t, s.Put(ctx, "latch/a.json", []byte(`{"a":1}`)))
+	require.NoError(t, s.Put(ctx, "latch/b.json", []byte(`{}`)))
+	require.NoError(t, s.Put(ctx, "trends/t.json", []byte(`[]`)))
+	require.NoError(t, s.Put(ctx, "latch/a.json", []byte(`{"a":2}`)))
+
+	data, err := s.Get(ctx, "latch/a.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":2}`, string(data))
+
+	info, err := os.Stat(filepath.Join(root, "latch", "a.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	objects, err := s.List(ctx, "latch/")
+	require.NoError(t, err)
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"latch/a.json", "latch/b.json"}, keys)
+
+	objects, err = s.List(ctx, "latch/b")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, int64(2), objects[0].Size)
+
+	objects, err = s.List(ctx, "audit/")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	require.NoError(t, s.Delete(ctx, "latch/a.json"))
+	require.NoError(t, s.Delete(ctx, "latch/a.json"))
+	_, err = s.Get(ctx, "latch/a.json")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	assert.Equal(t, filepath.Join(root, "trends", "t.json"), s.Location("trends/t.json"))
+}
+
+func TestShareURL(t *testing.T) {
+	root := t.TempDir()
+	link, err := ShareURL(NewLocal(root), "shares/a.html", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+filepath.ToSlash(filepath.Join(root, "shares", "a.html")), link)
+
+	_, err = ShareURL(NewConfigMap(fake.NewClientset(), "kubenow"), "shares/a.html", time.Hour)
+	assert.ErrorContains(t, err, "cannot share objects")
+}
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"latch/a.json", "trends/2026-01-01T000000Z.json"} {
+		assert.NoError(t, validateKey(key), key)
+	}
+	for _, key := range []string{"", "/etc/passwd", "latch/../../x", "latch//a", `latch\a`, "./a"} {
+		assert.Error(t, validateKey(key), key)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	s, err := Open("", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(os.Getenv("HOME"), ".kubenow", "latch", "x.json"), s.Location("latch/x.json"))
+
+	s, err = Open("file:///var/lib/kubenow", Options{})
+	require.NoError(t, err)
+	assert.IsType(t, &LocalStore{}, s)
+
+	s, err = Open("s3://bucket/kubenow/prod?region=eu-west-1", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "s3://bucket/kubenow/prod/latch/x.json", s.Location("latch/x.json"))
+	assert.Equal(t, "https://s3.eu-west-1.amazonaws.com", s.(*S3Store).cfg.Endpoint)
+
+	_, err = Open("configmap://kubenow", Options{})
+	assert.ErrorContains(t, err, "requires a Kubernetes client")
+	_, err = Open("configmap://", Options{})
+	assert.ErrorContains(t, err, "needs a namespace")
+	_, err = Open("gcs://bucket", Options{})
+	assert.ErrorContains(t, err, "unsupported storage URI")
+}
@@ -0,0 +1,219 @@
+// Package storage persists kubenow artifacts (latch results, trend
+// snapshots) behind a single interface with local-directory, S3, and
+// in-cluster ConfigMap backends.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Artifact kinds. Every key is "<kind>/<name>", and retention is set per kind.
+const (
+	KindLatch    = "latch"
+	KindTrends   = "trends"
+	KindShares   = "shares"
+	KindSessions = "sessions"
+)
+
+// ErrNotFound is returned by Get when no object exists for a key.
+var ErrNotFound = errors.New("object not found")
+
+// Object describes a stored artifact.
+type Object struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Store is a flat key/value store for artifacts. Keys use forward slashes
+// regardless of backend.
+type Store interface {
+	// Put creates or replaces the object at key.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the object at key, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the objects whose key starts with prefix, in no particular order.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Delete removes the object at key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// Location describes where key is stored, for user-facing messages.
+	Location(key string) string
+}
+
+// Sharer is implemented by stores that can hand out a URL for reading one
+// object without kubenow or store credentials.
+type Sharer interface {
+	// ShareURL returns a URL that serves key until ttl elapses. Backends
+	// that cannot expire a link ignore ttl.
+	ShareURL(key string, ttl time.Duration) (string, error)
+}
+
+// CanShare returns an error if the store's backend cannot hand out share
+// links, so callers can fail before writing anything.
+func CanShare(s Store) error {
+	if _, ok := s.(Sharer); !ok {
+		return errors.New("this storage backend cannot share objects: use an s3:// or local store")
+	}
+	return nil
+}
+
+// ShareURL returns a read-only URL for key, or an error if the store's
+// backend cannot share objects.
+func ShareURL(s Store, key string, ttl time.Duration) (string, error) {
+	if err := CanShare(s); err != nil {
+		return "", err
+	}
+	return s.(Sharer).ShareURL(key, ttl)
+}
+
+// Key joins an artifact kind and name into a store key.
+func Key(kind, name string) string {
+	return kind + "/" + name
+}
+
+// KindOf returns the artifact kind of a key.
+func KindOf(key string) string {
+	kind, _, _ := strings.Cut(key, "/")
+	return kind
+}
+
+// validateKey rejects keys that could escape a backend's root.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
+
+// Options configures Open.
+type Options struct {
+	// KubeClient builds the client for configmap:// stores. It is only
+	// called when such a store is opened.
+	KubeClient func() (kubernetes.Interface, error)
+}
+
+// Open returns the store described by uri:
+//
+//	/path/to/dir, file:///path    local directory
+//	s3://bucket/prefix            S3 or S3-compatible bucket (?region=, ?endpoint=)
+//	configmap://namespace         one ConfigMap per artifact in namespace
+//
+// An empty uri selects the local store under ~/.kubenow.
+func Open(uri string, opts Options) (Store, error) {
+	switch {
+	case uri == "":
+		root, err := DefaultRoot()
+		if err != nil {
+			return nil, err
+		}
+		return NewLocal(root), nil
+	case strings.HasPrefix(uri, "s3://"):
+		return openS3(uri)
+	case strings.HasPrefix(uri, "configmap://"):
+		ns := strings.Trim(strings.TrimPrefix(uri, "configmap://"), "/")
+		if ns == "" {
+			return nil, fmt.Errorf("configmap storage needs a namespace: configmap://<namespace>")
+		}
+		if opts.KubeClient == nil {
+			return nil, fmt.Errorf("configmap storage requires a Kubernetes client")
+		}
+		client, err := opts.KubeClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build Kubernetes client for configmap storage: %w", err)
+		}
+		return NewConfigMap(client, ns), nil
+	case strings.HasPrefix(uri, "file://"):
+		return NewLocal(strings.TrimPrefix(uri, "file://")), nil
+	case strings.Contains(uri, "://"):
+		return nil, fmt.Errorf("unsupported storage URI %q: use a path, file://, s3://, or configmap://", uri)
+	default:
+		return NewLocal(expandHome(uri)), nil
+	}
+}
+
+func openS3(uri string) (Store, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage URI %q: %w", uri, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("s3 storage needs a bucket: s3://<bucket>/<prefix>")
+	}
+	q := u.Query()
+	return NewS3(S3Config{
+		Bucket:   u.Host,
+		Prefix:   strings.TrimPrefix(u.Path, "/"),
+		Region:   q.Get("region"),
+		Endpoint: q.Get("endpoint"),
+	})
+}
+
+// DefaultRoot returns ~/.kubenow, the root of the default local store.
+func DefaultRoot() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".kubenow"), nil
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+var defaultStore struct {
+	mu    sync.Mutex
+	uri   string
+	opts  Options
+	store Store
+}
+
+// Configure sets the URI that Default opens on first use.
+func Configure(uri string, opts Options) {
+	defaultStore.mu.Lock()
+	defer defaultStore.mu.Unlock()
+	defaultStore.uri = uri
+	defaultStore.opts = opts
+	defaultStore.store = nil
+}
+
+// Default returns the store selected with Configure, or the local store
+// under ~/.kubenow when none was configured. The home directory is resolved
+// on every call so the default follows $HOME.
+func Default() (Store, error) {
+	defaultStore.mu.Lock()
+	defer defaultStore.mu.Unlock()
+	if defaultStore.uri == "" {
+		return Open("", Options{})
+	}
+	if defaultStore.store == nil {
+		s, err := Open(defaultStore.uri, defaultStore.opts)
+		if err != nil {
+			return nil, err
+		}
+		defaultStore.store = s
+	}
+	return defaultStore.store, nil
+}
@@ -0,0 +1,280 @@
+// Package synthetic generates fake clusters for benchmarks and scale tests:
+// a fake clientset holding namespaces, nodes, workloads, and their pods,
+// and a mock MetricsProvider with usage for every workload, pod, and node.
+// Generation is deterministic for a given Config.
+package synthetic
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+)
+
+const gib = 1024 * 1024 * 1024
+
+// Config sizes a synthetic cluster.
+type Config struct {
+	Namespaces               int
+	DeploymentsPerNamespace  int
+	StatefulSetsPerNamespace int
+	PodsPerWorkload          int
+	Nodes                    int
+	ProblemPodEvery          int    // every Nth pod is in CrashLoopBackOff (0 = none)
+	Seed                     uint64 // usage and request randomness
+}
+
+// Preset sizes for benchmarks: about 150, 2,000, and 12,500 pods.
+var (
+	Small = Config{
+		Namespaces: 5, DeploymentsPerNamespace: 10, StatefulSetsPerNamespace: 2, PodsPerWorkload: 3,
+		Nodes: 10, ProblemPodEvery: 50,
+	}
+	Medium = Config{
+		Namespaces: 20, DeploymentsPerNamespace: 25, StatefulSetsPerNamespace: 5, PodsPerWorkload: 4,
+		Nodes: 50, ProblemPodEvery: 100,
+	}
+	Large = Config{
+		Namespaces: 50, DeploymentsPerNamespace: 40, StatefulSetsPerNamespace: 10, PodsPerWorkload: 5,
+		Nodes: 200, ProblemPodEvery: 200,
+	}
+)
+
+// Cluster is a generated cluster.
+type Cluster struct {
+	Config    Config
+	Objects   []runtime.Object
+	Metrics   *metrics.MockMetrics
+	Workloads int
+	Pods      int
+	Created   time.Time // creation timestamp of every object
+}
+
+// Generate builds the objects and metrics for cfg. Workload objects are
+// created 30 days ago so they pass requests-skew's minimum runtime.
+func Generate(cfg Config) *Cluster {
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed^0x9e3779b97f4a7c15))
+	c := &Cluster{
+		Config:  cfg,
+		Metrics: metrics.NewMockMetrics(),
+		Created: time.Now().Add(-30 * 24 * time.Hour).Truncate(time.Second),
+	}
+	g := &generator{cluster: c, rng: rng}
+
+	nodeNames := make([]string, cfg.Nodes)
+	for i := range nodeNames {
+		nodeNames[i] = fmt.Sprintf("node-%04d", i)
+		c.Objects = append(c.Objects, g.node(nodeNames[i]))
+	}
+	g.nodes = nodeNames
+
+	for n := 0; n < cfg.Namespaces; n++ {
+		ns := fmt.Sprintf("team-%03d", n)
+		c.Objects = append(c.Objects, &corev1.Namespace{ObjectMeta: g.meta(ns, "")})
+		for d := 0; d < cfg.DeploymentsPerNamespace; d++ {
+			g.deployment(ns, fmt.Sprintf("svc-%03d", d))
+		}
+		for s := 0; s < cfg.StatefulSetsPerNamespace; s++ {
+			g.statefulSet(ns, fmt.Sprintf("db-%03d", s))
+		}
+	}
+
+	for _, name := range nodeNames {
+		c.Metrics.NodeUsages[name] = &metrics.NodeUsage{
+			Node: name, CPUQuantile: 2 + rng.Float64()*4, CPUAvg: 1 + rng.Float64()*2,
+			MemoryQuantile: (8 + rng.Float64()*16) * gib, MemoryAvg: (6 + rng.Float64()*10) * gib,
+		}
+	}
+	return c
+}
+
+// Clientset returns a new fake clientset holding the cluster's objects.
+// Each call starts with an empty action log.
+func (c *Cluster) Clientset() *fake.Clientset {
+	return fake.NewClientset(c.Objects...)
+}
+
+// CountActions tallies the API calls recorded by a fake clientset as
+// "verb resource" (for example "list pods").
+func CountActions(client *fake.Clientset) map[string]int {
+	counts := map[string]int{}
+	for _, a := range client.Actions() {
+		resource := a.GetResource().Resource
+		if sub := a.GetSubresource(); sub != "" {
+			resource += "/" + sub
+		}
+		counts[a.GetVerb()+" "+resource]++
+	}
+	return counts
+}
+
+type generator struct {
+	cluster *Cluster
+	rng     *rand.Rand
+	nodes   []string
+	podSeq  int
+}
+
+func (g *generator) meta(name, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         namespace,
+		UID:               types.UID(namespace + "/" + name),
+		CreationTimestamp: metav1.NewTime(g.cluster.Created),
+		Labels:            map[string]string{"app": name},
+	}
+}
+
+func (g *generator) node(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(g.cluster.Created),
+			Labels:            map[string]string{corev1.LabelInstanceTypeStable: "m5.2xlarge"},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("32Gi"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+// podTemplate returns a single-container spec with random requests between
+// 100m–2 cores and 128Mi–4Gi.
+func (g *generator) podTemplate(name string) (corev1.PodTemplateSpec, float64, float64) {
+	cpuMilli := 100 + g.rng.IntN(1900)
+	memMi := 128 + g.rng.IntN(3968)
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Image: "registry.example.com/" + name + ":1.0.0",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpuMilli), resource.DecimalSI),
+				corev1.ResourceMemory: *resource.NewQuantity(int64(memMi)*1024*1024, resource.BinarySI),
+			}},
+		}}},
+	}, float64(cpuMilli) / 1000, float64(memMi) * 1024 * 1024
+}
+
+func (g *generator) deployment(ns, name string) {
+	tmpl, cpu, mem := g.podTemplate(name)
+	replicas := int32(g.cluster.Config.PodsPerWorkload)
+	dep := &appsv1.Deployment{
+		ObjectMeta: g.meta(name, ns),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: tmpl,
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1, Replicas: replicas, UpdatedReplicas: replicas,
+			ReadyReplicas: replicas, AvailableReplicas: replicas,
+		},
+	}
+	dep.Generation = 1
+
+	rsName := name + "-7d9f8b6c5"
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: g.meta(rsName, ns),
+		Spec:       appsv1.ReplicaSetSpec{Replicas: &replicas, Template: tmpl},
+		Status:     appsv1.ReplicaSetStatus{Replicas: replicas, ReadyReplicas: replicas},
+	}
+	rs.Annotations = map[string]string{"deployment.kubernetes.io/revision": "1"}
+	rs.OwnerReferences = []metav1.OwnerReference{controllerRef("Deployment", dep.ObjectMeta)}
+
+	g.cluster.Objects = append(g.cluster.Objects, dep, rs)
+	g.pods(ns, name, rsName, "ReplicaSet", rs.ObjectMeta, &tmpl, "Deployment", cpu, mem)
+}
+
+func (g *generator) statefulSet(ns, name string) {
+	tmpl, cpu, mem := g.podTemplate(name)
+	replicas := int32(g.cluster.Config.PodsPerWorkload)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: g.meta(name, ns),
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: tmpl,
+		},
+		Status: appsv1.StatefulSetStatus{Replicas: replicas, ReadyReplicas: replicas},
+	}
+	g.cluster.Objects = append(g.cluster.Objects, sts)
+	g.pods(ns, name, name, "StatefulSet", sts.ObjectMeta, &tmpl, "StatefulSet", cpu, mem)
+}
+
+// pods creates the workload's pods and records pod and workload usage.
+func (g *generator) pods(
+	ns, workload, podPrefix, ownerKind string, owner metav1.ObjectMeta, tmpl *corev1.PodTemplateSpec,
+	workloadType string, cpu, mem float64,
+) {
+	c := g.cluster
+	replicas := c.Config.PodsPerWorkload
+	usageShare := 0.1 + g.rng.Float64()*0.6 // workloads use 10–70% of their requests
+
+	for i := 0; i < replicas; i++ {
+		name := fmt.Sprintf("%s-%d", podPrefix, i)
+		pod := &corev1.Pod{
+			ObjectMeta: g.meta(name, ns),
+			Spec:       *tmpl.Spec.DeepCopy(),
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: "app", Image: tmpl.Spec.Containers[0].Image, Ready: true,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				}},
+			},
+		}
+		pod.Labels = map[string]string{"app": workload}
+		pod.OwnerReferences = []metav1.OwnerReference{controllerRef(ownerKind, owner)}
+		if len(g.nodes) > 0 {
+			pod.Spec.NodeName = g.nodes[g.podSeq%len(g.nodes)]
+		}
+		g.podSeq++
+		if every := c.Config.ProblemPodEvery; every > 0 && g.podSeq%every == 0 {
+			cs := &pod.Status.ContainerStatuses[0]
+			cs.Ready = false
+			cs.RestartCount = 5 + int32(g.rng.IntN(20))
+			cs.State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+		}
+		c.Objects = append(c.Objects, pod)
+		c.Pods++
+		c.Metrics.PodQuantiles[ns+"/"+name] = metrics.PodUsageQuantile{CPU: cpu * usageShare, Memory: mem * usageShare}
+	}
+
+	c.Workloads++
+	n := float64(replicas)
+	c.Metrics.WorkloadUsages[ns+"/"+workload] = &metrics.WorkloadUsage{
+		WorkloadName:    workload,
+		WorkloadType:    workloadType,
+		Namespace:       ns,
+		CPUAvg:          cpu * n * usageShare * 0.7,
+		CPUP95:          cpu * n * usageShare,
+		CPUP99:          cpu * n * usageShare * 1.1,
+		CPUMax:          cpu * n * usageShare * 1.3,
+		MemoryAvg:       mem * n * usageShare * 0.8,
+		MemoryP95:       mem * n * usageShare,
+		MemoryP99:       mem * n * usageShare * 1.05,
+		MemoryMax:       mem * n * usageShare * 1.1,
+		CPURequested:    cpu * n,
+		MemoryRequested: mem * n,
+		PodCount:        replicas,
+	}
+}
+
+func controllerRef(kind string, owner metav1.ObjectMeta) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{Kind: kind, Name: owner.Name, UID: owner.UID, Controller: &controller}
+}
@@ -0,0 +1,58 @@
+package synthetic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := Config{
+		Namespaces: 2, DeploymentsPerNamespace: 3, StatefulSetsPerNamespace: 1, PodsPerWorkload: 2,
+		Nodes: 4, ProblemPodEvery: 4, Seed: 7,
+	}
+	c := Generate(cfg)
+
+	assert.Equal(t, 8, c.Workloads)
+	assert.Equal(t, 16, c.Pods)
+	assert.Len(t, c.Metrics.WorkloadUsages, 8)
+	assert.Len(t, c.Metrics.PodQuantiles, 16)
+	assert.Len(t, c.Metrics.NodeUsages, 4)
+
+	client := c.Clientset()
+	ctx := context.Background()
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, pods.Items, 16)
+
+	problems := 0
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		assert.NotEmpty(t, p.Spec.NodeName)
+		if !p.Status.ContainerStatuses[0].Ready {
+			problems++
+		}
+	}
+	assert.Equal(t, 4, problems)
+
+	deps, err := client.AppsV1().Deployments("team-001").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, deps.Items, 3)
+
+	counts := CountActions(client)
+	assert.Equal(t, 1, counts["list pods"])
+	assert.Equal(t, 1, counts["list deployments"])
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	a, b := Generate(Small), Generate(Small)
+	assert.Equal(t, a.Metrics.WorkloadUsages, b.Metrics.WorkloadUsages)
+	assert.Equal(t, a.Metrics.NodeUsages, b.Metrics.NodeUsages)
+
+	other := Small
+	other.Seed = 1
+	assert.NotEqual(t, a.Metrics.WorkloadUsages, Generate(other).Metrics.WorkloadUsages)
+}
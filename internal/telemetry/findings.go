@@ -0,0 +1,129 @@
+package telemetry
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sources of published findings, used as the source label of
+// kubenow_problem_count and kubenow_findings_updated_timestamp_seconds.
+// Watch schedules publish as "watch/<schedule>".
+const (
+	SourceMonitor      = "monitor"
+	SourceWatch        = "watch"
+	SourceRequestsSkew = "requests-skew"
+)
+
+// WorkloadSkew is one requests-skew finding published as gauges.
+type WorkloadSkew struct {
+	Namespace  string
+	Workload   string
+	Kind       string
+	SkewCPU    float64
+	SkewMemory float64
+	WastedCPU  float64 // requested - p95 cores, 0 when under-requested
+}
+
+// SkewSummary is the cluster-wide requests-skew result.
+type SkewSummary struct {
+	AnalyzedWorkloads int
+	WastedCPU         float64 // cores
+	WastedMemoryBytes float64
+}
+
+// Findings exposes kubenow's deterministic findings as gauges, so existing
+// alerting stacks can alert on them. Each Set call replaces the previous
+// values of its gauges: workloads and severities that disappeared stop
+// being exported. A nil *Findings records nothing.
+type Findings struct {
+	ProblemCount      *prometheus.GaugeVec
+	WorkloadSkewCPU   *prometheus.GaugeVec
+	WorkloadSkewMem   *prometheus.GaugeVec
+	WorkloadWastedCPU *prometheus.GaugeVec
+	AnalyzedWorkloads prometheus.Gauge
+	WastedCPU         prometheus.Gauge
+	WastedMemory      prometheus.Gauge
+	Updated           *prometheus.GaugeVec
+}
+
+// NewFindings creates and registers the findings gauges.
+func NewFindings(reg prometheus.Registerer) *Findings {
+	workloadLabels := []string{"namespace", "workload", "kind"}
+	f := &Findings{
+		ProblemCount: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubenow_problem_count",
+			Help: "Current problems by severity (fatal, critical, warning) and the source that found them.",
+		}, []string{"severity", "source"}),
+		WorkloadSkewCPU: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubenow_workload_skew_cpu",
+			Help: "CPU requests divided by p95 CPU usage of a reported workload.",
+		}, workloadLabels),
+		WorkloadSkewMem: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubenow_workload_skew_memory",
+			Help: "Memory requests divided by p95 memory usage of a reported workload.",
+		}, workloadLabels),
+		WorkloadWastedCPU: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubenow_workload_wasted_cpu_cores",
+			Help: "CPU cores requested above p95 usage by a reported workload.",
+		}, workloadLabels),
+		AnalyzedWorkloads: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "kubenow_analyzed_workloads",
+			Help: "Workloads analyzed by the last requests-skew run.",
+		}),
+		WastedCPU: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "kubenow_wasted_cpu_cores",
+			Help: "CPU cores requested above p95 usage across all analyzed workloads.",
+		}),
+		WastedMemory: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "kubenow_wasted_memory_bytes",
+			Help: "Memory requested above p95 usage across all analyzed workloads.",
+		}),
+		Updated: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubenow_findings_updated_timestamp_seconds",
+			Help: "Unix time the findings of a source were last updated.",
+		}, []string{"source"}),
+	}
+
+	reg.MustRegister(f.ProblemCount, f.WorkloadSkewCPU, f.WorkloadSkewMem, f.WorkloadWastedCPU,
+		f.AnalyzedWorkloads, f.WastedCPU, f.WastedMemory, f.Updated)
+	return f
+}
+
+// SetProblemCounts replaces the problem counts of source, keyed by severity.
+// Severities are lowercased; fatal, critical and warning are always
+// exported, as 0 when absent, so alerts on them do not go stale.
+func (f *Findings) SetProblemCounts(source string, counts map[string]int) {
+	if f == nil {
+		return
+	}
+	f.ProblemCount.DeletePartialMatch(prometheus.Labels{"source": source})
+	for _, severity := range []string{"fatal", "critical", "warning"} {
+		f.ProblemCount.WithLabelValues(severity, source).Set(0)
+	}
+	for severity, n := range counts {
+		f.ProblemCount.WithLabelValues(strings.ToLower(severity), source).Add(float64(n))
+	}
+	f.Updated.WithLabelValues(source).Set(float64(time.Now().Unix()))
+}
+
+// SetRequestsSkew publishes a requests-skew run.
+func (f *Findings) SetRequestsSkew(summary SkewSummary, workloads []WorkloadSkew) {
+	if f == nil {
+		return
+	}
+	f.WorkloadSkewCPU.Reset()
+	f.WorkloadSkewMem.Reset()
+	f.WorkloadWastedCPU.Reset()
+	for i := range workloads {
+		w := &workloads[i]
+		f.WorkloadSkewCPU.WithLabelValues(w.Namespace, w.Workload, w.Kind).Set(w.SkewCPU)
+		f.WorkloadSkewMem.WithLabelValues(w.Namespace, w.Workload, w.Kind).Set(w.SkewMemory)
+		f.WorkloadWastedCPU.WithLabelValues(w.Namespace, w.Workload, w.Kind).Set(w.WastedCPU)
+	}
+	f.AnalyzedWorkloads.Set(float64(summary.AnalyzedWorkloads))
+	f.WastedCPU.Set(summary.WastedCPU)
+	f.WastedMemory.Set(summary.WastedMemoryBytes)
+	f.Updated.WithLabelValues(SourceRequestsSkew).Set(float64(time.Now().Unix()))
+}
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindings_NilIsNoop(t *testing.T) {
+	var f *Findings
+	f.SetProblemCounts(SourceMonitor, map[string]int{"FATAL": 1})
+	f.SetRequestsSkew(SkewSummary{}, nil)
+}
+
+func TestFindings_SetProblemCounts(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	f := NewFindings(reg)
+
+	f.SetProblemCounts(SourceMonitor, map[string]int{"FATAL": 2, "WARNING": 1})
+	f.SetProblemCounts("watch/prod", map[string]int{"critical": 3})
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(f.ProblemCount.WithLabelValues("fatal", SourceMonitor)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(f.ProblemCount.WithLabelValues("critical", SourceMonitor)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(f.ProblemCount.WithLabelValues("critical", "watch/prod")))
+
+	// A later update replaces only its own source
+	f.SetProblemCounts(SourceMonitor, nil)
+	assert.Equal(t, 0.0, testutil.ToFloat64(f.ProblemCount.WithLabelValues("fatal", SourceMonitor)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(f.ProblemCount.WithLabelValues("critical", "watch/prod")))
+	assert.Equal(t, 6, testutil.CollectAndCount(f.ProblemCount))
+	assert.Positive(t, testutil.ToFloat64(f.Updated.WithLabelValues(SourceMonitor)))
+}
+
+func TestFindings_SetRequestsSkew(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	f := NewFindings(reg)
+
+	f.SetRequestsSkew(SkewSummary{AnalyzedWorkloads: 12, WastedCPU: 3.5, WastedMemoryBytes: 1 << 30}, []WorkloadSkew{
+		{Namespace: "payments", Workload: "api", Kind: "Deployment", SkewCPU: 4, SkewMemory: 1.5, WastedCPU: 1.5},
+		{Namespace: "search", Workload: "es", Kind: "StatefulSet", SkewCPU: 10, SkewMemory: 2, WastedCPU: 2},
+	})
+	assert.Equal(t, 4.0, testutil.ToFloat64(f.WorkloadSkewCPU.WithLabelValues("payments", "api", "Deployment")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(f.WorkloadWastedCPU.WithLabelValues("search", "es", "StatefulSet")))
+	assert.Equal(t, 3.5, testutil.ToFloat64(f.WastedCPU))
+	assert.Equal(t, float64(1<<30), testutil.ToFloat64(f.WastedMemory))
+	assert.Equal(t, 12.0, testutil.ToFloat64(f.AnalyzedWorkloads))
+
+	// Workloads missing from the next run stop being exported
+	f.SetRequestsSkew(SkewSummary{AnalyzedWorkloads: 1}, []WorkloadSkew{
+		{Namespace: "payments", Workload: "api", Kind: "Deployment", SkewCPU: 2},
+	})
+	assert.Equal(t, 1, testutil.CollectAndCount(f.WorkloadSkewCPU))
+	assert.Equal(t, 2.0, testutil.ToFloat64(f.WorkloadSkewCPU.WithLabelValues("payments", "api", "Deployment")))
+}
+
+func TestServer_Findings(t *testing.T) {
+	srv := NewServer(0)
+	require.NotNil(t, srv.Findings())
+}
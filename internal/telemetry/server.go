@@ -1,4 +1,5 @@
-// Package telemetry provides an opt-in Prometheus metrics endpoint for kubenow self-monitoring.
+// Package telemetry provides an opt-in Prometheus metrics endpoint for kubenow
+// self-monitoring and its deterministic findings.
 package telemetry
 
 import (
@@ -70,6 +71,7 @@ func (m *Metrics) RecordAnalysis(command string, duration time.Duration) {
 type Server struct {
 	httpServer *http.Server
 	metrics    *Metrics
+	findings   *Findings
 }
 
 // NewServer creates a metrics server on the given port.
@@ -77,6 +79,7 @@ func NewServer(port int) *Server {
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(collectors.NewGoCollector())
 	m := NewMetrics(reg)
+	f := NewFindings(reg)
 
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
@@ -87,7 +90,8 @@ func NewServer(port int) *Server {
 			Handler:           mux,
 			ReadHeaderTimeout: 10 * time.Second,
 		},
-		metrics: m,
+		metrics:  m,
+		findings: f,
 	}
 }
 
@@ -96,6 +100,11 @@ func (s *Server) Metrics() *Metrics {
 	return s.metrics
 }
 
+// Findings returns the findings gauges for publishing.
+func (s *Server) Findings() *Findings {
+	return s.findings
+}
+
 // Start begins serving metrics. Blocks until context is canceled.
 func (s *Server) Start(ctx context.Context) error {
 	go func() {
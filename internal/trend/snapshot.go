@@ -2,12 +2,15 @@
 package trend
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"sort"
+	"strings"
 	"time"
+
+	"github.com/ppiankov/kubenow/internal/storage"
 )
 
 // WorkloadSnapshot captures a single workload's skew at a point in time.
@@ -36,27 +39,14 @@ type TotalWaste struct {
 	CostMo float64 `json:"cost_monthly,omitempty"`
 }
 
-// trendDir returns the directory for persisted trend files.
-func trendDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
-	}
-	dir := filepath.Join(home, ".kubenow", "trends")
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", fmt.Errorf("cannot create trend directory: %w", err)
-	}
-	return dir, nil
-}
-
 // snapshotFilename generates a filename from timestamp.
 func snapshotFilename(t time.Time) string {
 	return t.UTC().Format("2006-01-02T150405Z") + ".json"
 }
 
-// SaveSnapshot persists an analysis snapshot to disk.
+// SaveSnapshot persists an analysis snapshot to the configured store.
 func SaveSnapshot(snap *Snapshot) error {
-	dir, err := trendDir()
+	s, err := storage.Default()
 	if err != nil {
 		return err
 	}
@@ -66,8 +56,8 @@ func SaveSnapshot(snap *Snapshot) error {
 		return fmt.Errorf("failed to marshal snapshot: %w", err)
 	}
 
-	path := filepath.Join(dir, snapshotFilename(snap.Timestamp))
-	if err := os.WriteFile(path, data, 0o600); err != nil {
+	key := storage.Key(storage.KindTrends, snapshotFilename(snap.Timestamp))
+	if err := s.Put(context.Background(), key, data); err != nil {
 		return fmt.Errorf("failed to write snapshot: %w", err)
 	}
 	return nil
@@ -75,29 +65,26 @@ func SaveSnapshot(snap *Snapshot) error {
 
 // LoadHistory loads all snapshots from the last N days, sorted by timestamp.
 func LoadHistory(days int) ([]Snapshot, error) {
-	dir, err := trendDir()
+	s, err := storage.Default()
 	if err != nil {
 		return nil, err
 	}
 
 	cutoff := time.Now().AddDate(0, 0, -days)
 
-	entries, err := os.ReadDir(dir)
+	ctx := context.Background()
+	objects, err := s.List(ctx, storage.KindTrends+"/")
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, fmt.Errorf("failed to read trend directory: %w", err)
 	}
 
 	var snapshots []Snapshot
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+	for _, obj := range objects {
+		if path.Ext(obj.Key) != ".json" || strings.Count(obj.Key, "/") != 1 {
 			continue
 		}
 
-		path := filepath.Join(dir, entry.Name())
-		data, err := os.ReadFile(path)
+		data, err := s.Get(ctx, obj.Key)
 		if err != nil {
 			continue
 		}
@@ -0,0 +1,99 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// CPUQuantity formats cores as a Kubernetes CPU quantity for manifests and
+// patches: whole cores without a suffix, anything else in millicores.
+// Examples: 0.1 → "100m", 1.0 → "1", 2.5 → "2500m".
+func CPUQuantity(cores float64) string {
+	m := int64(math.Round(cores * 1000))
+	if m <= 0 {
+		return "0m"
+	}
+	if m%1000 == 0 {
+		return strconv.FormatInt(m/1000, 10)
+	}
+	return strconv.FormatInt(m, 10) + "m"
+}
+
+// MemoryQuantity formats bytes as a Kubernetes memory quantity: whole Gi
+// when exact, else whole Mi (rounded down), else bytes.
+// Examples: 134217728 → "128Mi", 1073741824 → "1Gi", 1.5Gi → "1536Mi".
+func MemoryQuantity(bytes float64) string {
+	b := int64(math.Round(bytes))
+	if b <= 0 {
+		return "0"
+	}
+	if b >= Gi && b%Gi == 0 {
+		return strconv.FormatInt(b/Gi, 10) + "Gi"
+	}
+	if b >= Mi {
+		return strconv.FormatInt(b/Mi, 10) + "Mi"
+	}
+	return strconv.FormatInt(b, 10)
+}
+
+// ParseCPU parses a CPU value as written in a manifest or typed by a user
+// ("250m", "0.5", "2") and returns cores.
+func ParseCPU(s string) (float64, error) {
+	q, err := parse(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPU value %q: %w", s, err)
+	}
+	return q.AsApproximateFloat64(), nil
+}
+
+// ParseMemory parses a memory value and returns bytes. Besides Kubernetes
+// quantities ("512Mi", "1.5Gi", "1G", "1048576") it accepts the IEC and SI
+// spellings people type: "512MiB", "1GiB", "500MB".
+func ParseMemory(s string) (float64, error) {
+	q, err := parse(normalizeMemorySuffix(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory value %q: %w", s, err)
+	}
+	return q.AsApproximateFloat64(), nil
+}
+
+func parse(s string) (resource.Quantity, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return resource.Quantity{}, fmt.Errorf("empty value")
+	}
+	if sep := Current().DecimalSeparator; sep != "" && sep != "." {
+		s = strings.Replace(s, sep, ".", 1)
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	if q.Sign() < 0 {
+		return resource.Quantity{}, fmt.Errorf("must not be negative")
+	}
+	return q, nil
+}
+
+// normalizeMemorySuffix rewrites "MiB" → "Mi", "MB" → "M", "KB" → "k" and
+// "512B" → "512", leaving Kubernetes suffixes untouched.
+func normalizeMemorySuffix(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "iB") {
+		return strings.TrimSuffix(s, "B")
+	}
+	if len(s) < 2 || s[len(s)-1] != 'B' {
+		return s
+	}
+	switch prefix := s[len(s)-2]; {
+	case prefix == 'k' || prefix == 'K':
+		return s[:len(s)-2] + "k"
+	case strings.IndexByte("MGTPE0123456789", prefix) >= 0:
+		return s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+// Package units formats and parses CPU and memory quantities. Tables, the
+// pro-monitor TUI, exports and analyzer notes all render through it, so a
+// value reads the same in every report.
+package units
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Binary memory multipliers, in bytes.
+const (
+	Ki = 1024
+	Mi = 1024 * Ki
+	Gi = 1024 * Mi
+)
+
+// DefaultPrecision is the number of decimals shown for cores and Gi.
+const DefaultPrecision = 2
+
+// Options controls how values are rendered for people. Kubernetes
+// quantities (CPUQuantity, MemoryQuantity) always use the API format.
+type Options struct {
+	// Precision is the number of decimals in fractional values (cores,
+	// Gi, Mi, Ki). Millicores are always whole. Negative means default.
+	Precision int
+	// DecimalSeparator replaces "." in fractional values, e.g. "," for
+	// locales that write 1,5Gi. Empty means ".".
+	DecimalSeparator string
+}
+
+var (
+	mu      sync.RWMutex
+	current = Options{Precision: DefaultPrecision}
+)
+
+// Configure sets the options used by the package-level formatters and
+// accepted by ParseCPU and ParseMemory.
+func Configure(o Options) {
+	if o.Precision < 0 {
+		o.Precision = DefaultPrecision
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = o
+}
+
+// Current returns the options set with Configure.
+func Current() Options {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Cores formats CPU cores with the configured precision, e.g. "0.25".
+func Cores(cores float64) string { return Current().Cores(cores) }
+
+// Millicores formats CPU cores as whole millicores, e.g. 0.07 → "70m".
+func Millicores(cores float64) string { return Current().Millicores(cores) }
+
+// Memory formats bytes in the largest binary unit that fits, e.g. "1.50Gi".
+func Memory(bytes float64) string { return Current().Memory(bytes) }
+
+// MemoryMi formats bytes as whole Mi, e.g. 178257920 → "170Mi".
+func MemoryMi(bytes float64) string { return Current().MemoryMi(bytes) }
+
+// MemoryGi formats bytes as Gi with the configured precision, e.g. "0.50Gi".
+func MemoryGi(bytes float64) string { return Current().MemoryGi(bytes) }
+
+// WithPrecision returns o with Precision set, for values that need more
+// (or fewer) decimals than the configured default, e.g. tiny CPU samples.
+func (o Options) WithPrecision(precision int) Options {
+	o.Precision = precision
+	return o
+}
+
+// Cores formats CPU cores with o's precision.
+func (o Options) Cores(cores float64) string {
+	return o.number(cores, o.precision())
+}
+
+// Millicores formats CPU cores as whole millicores. Values below 1m
+// render as "0m".
+func (o Options) Millicores(cores float64) string {
+	m := cores * 1000
+	if m < 1 {
+		return "0m"
+	}
+	return strconv.FormatFloat(m, 'f', 0, 64) + "m"
+}
+
+// Memory formats bytes in the largest binary unit that fits: Gi, Mi, Ki,
+// or whole bytes ("B").
+func (o Options) Memory(bytes float64) string {
+	switch {
+	case bytes >= Gi:
+		return o.number(bytes/Gi, o.precision()) + "Gi"
+	case bytes >= Mi:
+		return o.number(bytes/Mi, o.precision()) + "Mi"
+	case bytes >= Ki:
+		return o.number(bytes/Ki, o.precision()) + "Ki"
+	default:
+		return o.number(bytes, 0) + "B"
+	}
+}
+
+// MemoryMi formats bytes as whole Mi. Values below 1Mi render as "0Mi".
+func (o Options) MemoryMi(bytes float64) string {
+	mi := bytes / Mi
+	if mi < 1 {
+		return "0Mi"
+	}
+	return o.number(mi, 0) + "Mi"
+}
+
+// MemoryGi formats bytes as Gi with o's precision, for columns that
+// compare values in one unit.
+func (o Options) MemoryGi(bytes float64) string {
+	return o.number(bytes/Gi, o.precision()) + "Gi"
+}
+
+func (o Options) precision() int {
+	if o.Precision < 0 {
+		return DefaultPrecision
+	}
+	return o.Precision
+}
+
+func (o Options) number(v float64, precision int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(v, 'f', precision, 64)
+	if o.DecimalSeparator != "" && o.DecimalSeparator != "." {
+		s = strings.Replace(s, ".", o.DecimalSeparator, 1)
+	}
+	return s
+}
@@ -0,0 +1,173 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCores(t *testing.T) {
+	assert.Equal(t, "0.25", Cores(0.25))
+	assert.Equal(t, "1.00", Cores(1))
+	assert.Equal(t, "0.007", Options{Precision: 3}.Cores(0.0071))
+	assert.Equal(t, "2", Options{Precision: 0}.Cores(1.9))
+}
+
+func TestMillicores(t *testing.T) {
+	assert.Equal(t, "0m", Millicores(0))
+	assert.Equal(t, "0m", Millicores(0.0004))
+	assert.Equal(t, "70m", Millicores(0.07))
+	assert.Equal(t, "100m", Millicores(0.1))
+	assert.Equal(t, "1000m", Millicores(1.0))
+}
+
+func TestMemory(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes float64
+		want  string
+	}{
+		{"zero", 0, "0B"},
+		{"bytes", 500, "500B"},
+		{"kibibytes", 1024, "1.00Ki"},
+		{"mebibytes", Mi, "1.00Mi"},
+		{"gibibytes", Gi, "1.00Gi"},
+		{"fractional", 1.5 * Gi, "1.50Gi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Memory(tt.bytes))
+		})
+	}
+}
+
+func TestMemoryFixedUnits(t *testing.T) {
+	assert.Equal(t, "0Mi", MemoryMi(0))
+	assert.Equal(t, "100Mi", MemoryMi(100*Mi))
+	assert.Equal(t, "170Mi", MemoryMi(178257920))
+	assert.Equal(t, "0.50Gi", MemoryGi(512*Mi))
+	assert.Equal(t, "2.0Gi", Options{Precision: 1}.MemoryGi(2*Gi))
+}
+
+func TestOptions_DecimalSeparator(t *testing.T) {
+	o := Options{Precision: 1, DecimalSeparator: ","}
+	assert.Equal(t, "1,5Gi", o.Memory(1.5*Gi))
+	assert.Equal(t, "0,3", o.Cores(0.26))
+	assert.Equal(t, "250m", o.Millicores(0.25))
+}
+
+func TestConfigure(t *testing.T) {
+	defer Configure(Options{Precision: DefaultPrecision})
+
+	Configure(Options{Precision: 1, DecimalSeparator: ","})
+	assert.Equal(t, "0,5", Cores(0.5))
+	assert.Equal(t, "1,5Gi", Memory(1.5*Gi))
+
+	got, err := ParseMemory("1,5Gi")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5*Gi, got)
+
+	Configure(Options{Precision: -1})
+	assert.Equal(t, "0.50", Cores(0.5))
+}
+
+func TestCPUQuantity(t *testing.T) {
+	tests := []struct {
+		cores float64
+		want  string
+	}{
+		{0, "0m"},
+		{0.001, "1m"},
+		{0.05, "50m"},
+		{0.1, "100m"},
+		{0.5, "500m"},
+		{1.0, "1"},
+		{2.0, "2"},
+		{2.5, "2500m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, CPUQuantity(tt.cores))
+		})
+	}
+}
+
+func TestMemoryQuantity(t *testing.T) {
+	tests := []struct {
+		bytes float64
+		want  string
+	}{
+		{0, "0"},
+		{1024, "1024"},         // less than 1Mi → raw bytes
+		{64 * Mi, "64Mi"},      // 64Mi
+		{512 * Mi, "512Mi"},    // 512Mi
+		{Gi, "1Gi"},            // 1Gi
+		{2 * Gi, "2Gi"},        // 2Gi
+		{1.5 * Gi, "1536Mi"},   // 1.5Gi → Mi
+		{100*Mi + 10, "100Mi"}, // rounded down to whole Mi
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, MemoryQuantity(tt.bytes))
+		})
+	}
+}
+
+func TestParseCPU(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"150m", 0.15},
+		{"1", 1.0},
+		{"1000m", 1.0},
+		{"0m", 0},
+		{"0.5", 0.5},
+		{" 2 ", 2.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCPU(tt.input)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+
+	for _, bad := range []string{"", "bad", "-1", "1 core"} {
+		_, err := ParseCPU(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"200Mi", 200 * Mi},
+		{"1Gi", Gi},
+		{"1.5Gi", 1.5 * Gi},
+		{"512Ki", 512 * Ki},
+		{"1048576", 1048576},
+		{"0", 0},
+		{"512MiB", 512 * Mi},
+		{"1GiB", Gi},
+		{"500MB", 500e6},
+		{"1G", 1e9},
+		{"2KB", 2000},
+		{"512B", 512},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMemory(tt.input)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-6)
+		})
+	}
+
+	for _, bad := range []string{"", "bad", "-1Gi", "1XB"} {
+		_, err := ParseMemory(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,13 @@
+package util
+
+import "net/url"
+
+// UnwrapURLError drops the *url.Error wrapper an HTTP client returns, whose
+// message includes the full request URL. Webhook and API URLs often carry
+// tokens, so errors shown to the user name the host instead.
+func UnwrapURLError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return ue.Err
+	}
+	return err
+}
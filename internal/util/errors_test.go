@@ -0,0 +1,19 @@
+package util
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnwrapURLError(t *testing.T) {
+	inner := errors.New("connection refused")
+	err := &url.Error{Op: "Post", URL: "https://hooks.example.com/services/T000/B000/secret", Err: inner}
+
+	assert.Equal(t, inner, UnwrapURLError(err))
+	assert.NotContains(t, UnwrapURLError(err).Error(), "secret")
+	assert.Equal(t, inner, UnwrapURLError(inner))
+	assert.NoError(t, UnwrapURLError(nil))
+}
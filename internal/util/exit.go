@@ -4,6 +4,8 @@ package util
 import (
 	"fmt"
 	"os"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
 )
 
 // Standard exit codes aligned with specter tools family
@@ -12,7 +14,6 @@ const (
 	ExitOK = 0
 
 	// ExitPolicyFail indicates policy violations or threshold breaches
-	// (reserved for future use)
 	ExitPolicyFail = 1
 
 	// ExitInvalidInput indicates validation errors or invalid parameters
@@ -22,8 +23,10 @@ const (
 	ExitRuntimeError = 3
 )
 
-// Exit terminates the program with the given exit code
+// Exit runs the registered cleanups and terminates the program with the
+// given exit code
 func Exit(code int) {
+	cleanup.Run()
 	os.Exit(code)
 }
 
@@ -4,10 +4,14 @@ package util
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -17,6 +21,23 @@ import (
 type KubeOpts struct {
 	Kubeconfig string // explicit path to kubeconfig file
 	Context    string // explicit context override (empty = current-context)
+
+	// Impersonation, as kubectl --as, --as-group, and --as-uid
+	AsUser   string
+	AsGroups []string
+	AsUID    string
+
+	// QPS and Burst are the client-side request rate limits (0 = client-go
+	// defaults). Throttled (429) requests are retried with backoff.
+	QPS   float32
+	Burst int
+	// ListCacheTTL is how long namespace, pod, and workload lists are
+	// served from the process-wide list cache (0 = no cache).
+	ListCacheTTL time.Duration
+
+	// WrapTransport, when set, wraps the clientset's HTTP transport, e.g. to
+	// record API requests.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
 }
 
 // expandTilde replaces a leading ~ with the user's home directory.
@@ -32,67 +53,61 @@ func expandTilde(path string) string {
 	return filepath.Join(home, path[2:])
 }
 
-// buildConfigFromOpts builds a rest.Config using clientcmd loading rules
-// that respect both kubeconfig path and context overrides.
-func buildConfigFromOpts(kubeconfigPath, contextOverride string) (*rest.Config, error) {
+// ClientConfig returns the kubeconfig loader kubectl would use: the
+// explicit path, else the $KUBECONFIG chain, else ~/.kube/config, else
+// in-cluster config when no kubeconfig is found, with the context and
+// impersonation overrides applied.
+func ClientConfig(opts KubeOpts) clientcmd.ClientConfig {
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
-	if kubeconfigPath != "" {
-		rules.ExplicitPath = expandTilde(kubeconfigPath)
+	if opts.Kubeconfig != "" {
+		rules.ExplicitPath = expandTilde(opts.Kubeconfig)
 	}
 
-	overrides := &clientcmd.ConfigOverrides{}
-	if contextOverride != "" {
-		overrides.CurrentContext = contextOverride
-	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.Context}
+	overrides.AuthInfo.Impersonate = opts.AsUser
+	overrides.AuthInfo.ImpersonateGroups = opts.AsGroups
+	overrides.AuthInfo.ImpersonateUID = opts.AsUID
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
 
-	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+// ContextNamespace returns the namespace kubectl would use without -n: the
+// context's namespace, the in-cluster service account's, or "default".
+func ContextNamespace(opts KubeOpts) (string, error) {
+	ns, _, err := ClientConfig(opts).Namespace()
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("kubeconfig namespace: %w", err)
 	}
-	return cfg, nil
+	return ns, nil
 }
 
 // BuildRestConfig builds a Kubernetes rest config.
 //
-// Priority:
-// 1. explicit kubeconfig flag
-// 2. $KUBECONFIG
-// 3. in-cluster config
-//
 // Deprecated: use BuildRestConfigWithOpts for context support.
 func BuildRestConfig(kubeconfig string) (*rest.Config, error) {
 	return BuildRestConfigWithOpts(KubeOpts{Kubeconfig: kubeconfig})
 }
 
-// BuildRestConfigWithOpts builds a Kubernetes rest config with context support.
-//
-// Priority:
-// 1. explicit kubeconfig path + context override
-// 2. $KUBECONFIG + context override
-// 3. default ~/.kube/config + context override
-// 4. in-cluster config (context override ignored)
+// BuildRestConfigWithOpts builds a Kubernetes rest config the way kubectl
+// does (see ClientConfig), with the client-side rate limits and list cache.
 func BuildRestConfigWithOpts(opts KubeOpts) (*rest.Config, error) {
-	// If context is specified, always use clientcmd loader (not in-cluster)
-	if opts.Context != "" {
-		return buildConfigFromOpts(opts.Kubeconfig, opts.Context)
-	}
-
-	if opts.Kubeconfig != "" {
-		return buildConfigFromOpts(opts.Kubeconfig, "")
+	cfg, err := loadRestConfig(opts)
+	if err != nil {
+		return nil, err
 	}
+	applyClientLayer(cfg, opts)
+	return cfg, nil
+}
 
-	if env := os.Getenv("KUBECONFIG"); env != "" {
-		return buildConfigFromOpts(env, "")
+func loadRestConfig(opts KubeOpts) (*rest.Config, error) {
+	cfg, err := ClientConfig(opts).ClientConfig()
+	if err != nil {
+		return nil, err
 	}
-
-	// Try in-cluster first, fall back to default kubeconfig
-	cfg, err := rest.InClusterConfig()
-	if err == nil {
-		return cfg, nil
+	// The in-cluster fallback ignores kubeconfig overrides
+	if opts.AsUser != "" && cfg.Impersonate.UserName == "" {
+		cfg.Impersonate = rest.ImpersonationConfig{UserName: opts.AsUser, UID: opts.AsUID, Groups: opts.AsGroups}
 	}
-
-	// Fall back to default kubeconfig location
-	return buildConfigFromOpts("", "")
+	return cfg, nil
 }
 
 // BuildKubeClient builds a Kubernetes clientset.
@@ -108,6 +123,9 @@ func BuildKubeClientWithOpts(opts KubeOpts) (*kubernetes.Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	if opts.WrapTransport != nil {
+		cfg.Wrap(opts.WrapTransport)
+	}
 
 	clientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
@@ -115,3 +133,39 @@ func BuildKubeClientWithOpts(opts KubeOpts) (*kubernetes.Clientset, error) {
 	}
 	return clientset, nil
 }
+
+// KubeContext is a kubeconfig context and the cluster it points at.
+type KubeContext struct {
+	Name    string
+	Cluster string
+}
+
+// ListKubeContexts returns the contexts of the kubeconfig the client
+// builders would load (explicit path, else $KUBECONFIG, else
+// ~/.kube/config), sorted by name.
+func ListKubeContexts(kubeconfigPath string) ([]KubeContext, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfigPath != "" {
+		rules.ExplicitPath = expandTilde(kubeconfigPath)
+	}
+	cfg, err := rules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+
+	contexts := make([]KubeContext, 0, len(cfg.Contexts))
+	for name, c := range cfg.Contexts {
+		contexts = append(contexts, KubeContext{Name: name, Cluster: c.Cluster})
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+	return contexts, nil
+}
+
+// StripManagedFields is an informer transform that drops managedFields,
+// which kubenow never reads, to keep informer caches small on big clusters.
+func StripManagedFields(obj any) (any, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
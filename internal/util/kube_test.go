@@ -36,3 +36,84 @@ func TestExpandTilde_TildeInMiddle(t *testing.T) {
 	result := expandTilde("/home/user/~/config")
 	assert.Equal(t, "/home/user/~/config", result)
 }
+
+func TestListKubeContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+  - name: eu-1
+    cluster: {server: https://eu.example.com}
+  - name: stg
+    cluster: {server: https://stg.example.com}
+contexts:
+  - name: staging
+    context: {cluster: stg, user: admin}
+  - name: prod-eu
+    context: {cluster: eu-1, user: admin}
+users:
+  - name: admin
+    user: {token: x}
+`), 0o600))
+
+	contexts, err := ListKubeContexts(path)
+	require.NoError(t, err)
+	assert.Equal(t, []KubeContext{{Name: "prod-eu", Cluster: "eu-1"}, {Name: "staging", Cluster: "stg"}}, contexts)
+}
+
+const namespacedKubeconfig = `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+  - name: stg
+    cluster: {server: https://stg.example.com}
+  - name: eu-1
+    cluster: {server: https://eu.example.com}
+contexts:
+  - name: staging
+    context: {cluster: stg, user: admin, namespace: shop}
+  - name: prod-eu
+    context: {cluster: eu-1, user: admin}
+users:
+  - name: admin
+    user: {token: x}
+`
+
+func writeKubeconfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestContextNamespace(t *testing.T) {
+	path := writeKubeconfig(t, "config", namespacedKubeconfig)
+
+	ns, err := ContextNamespace(KubeOpts{Kubeconfig: path})
+	require.NoError(t, err)
+	assert.Equal(t, "shop", ns)
+
+	ns, err = ContextNamespace(KubeOpts{Kubeconfig: path, Context: "prod-eu"})
+	require.NoError(t, err)
+	assert.Equal(t, "default", ns)
+}
+
+func TestBuildRestConfig_KubeconfigChainAndImpersonation(t *testing.T) {
+	// $KUBECONFIG lists several files, merged as kubectl does
+	first := writeKubeconfig(t, "first", "apiVersion: v1\nkind: Config\ncurrent-context: prod-eu\n")
+	second := writeKubeconfig(t, "second", namespacedKubeconfig)
+	t.Setenv("KUBECONFIG", first+string(os.PathListSeparator)+second)
+
+	cfg, err := BuildRestConfigWithOpts(KubeOpts{AsUser: "jane", AsGroups: []string{"devs", "ops"}, AsUID: "42"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://eu.example.com", cfg.Host)
+	assert.Equal(t, "jane", cfg.Impersonate.UserName)
+	assert.Equal(t, []string{"devs", "ops"}, cfg.Impersonate.Groups)
+	assert.Equal(t, "42", cfg.Impersonate.UID)
+
+	cfg, err = BuildRestConfigWithOpts(KubeOpts{Context: "staging"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://stg.example.com", cfg.Host)
+	assert.Empty(t, cfg.Impersonate.UserName)
+}
@@ -0,0 +1,286 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// DefaultKubeQPS and DefaultKubeBurst are the client-side rate limits of
+	// the --kube-qps and --kube-burst flags.
+	DefaultKubeQPS   = 20
+	DefaultKubeBurst = 40
+
+	// DefaultListCacheTTL is how long --kube-cache-ttl keeps list responses.
+	DefaultListCacheTTL = 30 * time.Second
+
+	// maxThrottleRetries is how often a request the API server throttled
+	// (HTTP 429) is retried before the 429 is returned.
+	maxThrottleRetries = 4
+	// throttleBackoff is the first retry delay; it doubles per retry.
+	throttleBackoff = 500 * time.Millisecond
+	// maxThrottleDelay caps a retry delay, including one from Retry-After.
+	maxThrottleDelay = 30 * time.Second
+)
+
+// cachedListPath matches the collection paths of the lists analyzers,
+// latch, and exposure collectors read repeatedly: namespaces, pods, and
+// workloads, cluster-wide or in one namespace. A path prefix (API proxies)
+// is allowed.
+var cachedListPath = regexp.MustCompile(`(/api/v1/namespaces|/api/v1/(namespaces/[^/]+/)?pods|` +
+	`/apis/apps/v1/(namespaces/[^/]+/)?(deployments|statefulsets|daemonsets|replicasets)|` +
+	`/apis/batch/v1/(namespaces/[^/]+/)?(jobs|cronjobs))$`)
+
+// applyClientLayer sets the client-side rate limits and wraps the
+// transport with throttle retries and the list cache.
+func applyClientLayer(cfg *rest.Config, opts KubeOpts) {
+	if opts.QPS > 0 {
+		cfg.QPS = opts.QPS
+	}
+	if opts.Burst > 0 {
+		cfg.Burst = opts.Burst
+	}
+	cache := sharedListCache
+	ttl := opts.ListCacheTTL
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		rt = &throttleRetry{next: rt, sleep: sleepContext}
+		if ttl > 0 {
+			// The transport is built from the final config, impersonation included
+			rt = &listCacheTransport{next: rt, cache: cache, identity: clientIdentity(cfg), ttl: ttl, now: time.Now}
+		}
+		return rt
+	})
+}
+
+// throttleRetry retries requests the API server answered with 429 Too Many
+// Requests, waiting for its Retry-After or an exponential backoff.
+type throttleRetry struct {
+	next  http.RoundTripper
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func (t *throttleRetry) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := throttleBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt == maxThrottleRetries {
+			return resp, err
+		}
+		// A request whose body cannot be replayed cannot be retried
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		wait := delay
+		if after, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && after > 0 {
+			wait = time.Duration(after) * time.Second
+		}
+		wait = min(wait, maxThrottleDelay)
+		delay *= 2
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// sharedListCache is shared by every client of the process, so the
+// analyzers, latch, and exposure collectors of one run list each
+// namespace's pods and workloads once per TTL. Entries are keyed by client
+// identity, so clients with different credentials or impersonation never
+// see each other's lists.
+var sharedListCache = &listCache{entries: make(map[string]listCacheEntry)}
+
+// listCache holds successful list responses by client identity, cluster,
+// URL, and Accept header.
+type listCache struct {
+	mu      sync.Mutex
+	entries map[string]listCacheEntry
+	group   singleflight.Group
+}
+
+type listCacheEntry struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+func (c *listCache) get(key string, now time.Time) (listCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || now.After(e.expires) {
+		return listCacheEntry{}, false
+	}
+	return e, true
+}
+
+func (c *listCache) put(key string, e listCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = e
+}
+
+// invalidate drops every entry: a write may change any cached list.
+func (c *listCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// listCacheTransport serves repeated namespace, pod, and workload lists
+// from the shared cache for ttl. Concurrent identical lists share one
+// request. Watches, paginated lists (informers), lists at a resource
+// version, and every other request pass through; writes clear the cache.
+type listCacheTransport struct {
+	next     http.RoundTripper
+	cache    *listCache
+	identity string // clientIdentity of the client's config
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func (t *listCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		t.cache.invalidate()
+		return t.next.RoundTrip(req)
+	}
+	if !cacheableList(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	key := t.identity + "|" + req.URL.Scheme + "://" + req.URL.Host + req.URL.RequestURI() + "|" + req.Header.Get("Accept")
+	if e, ok := t.cache.get(key, t.now()); ok {
+		return e.response(req), nil
+	}
+
+	v, err, _ := t.cache.group.Do(key, func() (any, error) {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		e := listCacheEntry{status: resp.StatusCode, header: resp.Header.Clone(), body: body, expires: t.now().Add(t.ttl)}
+		if resp.StatusCode == http.StatusOK {
+			t.cache.put(key, e)
+		}
+		return e, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(listCacheEntry).response(req), nil
+}
+
+// clientIdentity digests what a config authenticates and impersonates as:
+// basic auth, bearer token, client certificate, exec and auth provider
+// plugins, and impersonated user, UID, groups, and extra fields. Auth
+// headers are added outside the cache transport, so the key cannot be
+// taken from the request.
+func clientIdentity(cfg *rest.Config) string {
+	h := sha256.New()
+	write := func(field string, values ...string) {
+		fmt.Fprintf(h, "%s=%q;", field, values)
+	}
+	sortedMap := func(m map[string]string) []string {
+		out := make([]string, 0, len(m))
+		for k, v := range m {
+			out = append(out, k+"="+v)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	write("host", cfg.Host)
+	write("basic", cfg.Username, cfg.Password)
+	write("token", cfg.BearerToken, cfg.BearerTokenFile)
+	write("cert", cfg.CertFile, cfg.KeyFile, string(cfg.CertData), string(cfg.KeyData))
+	if cfg.ExecProvider != nil {
+		write("exec", append([]string{cfg.ExecProvider.Command}, cfg.ExecProvider.Args...)...)
+		for _, env := range cfg.ExecProvider.Env {
+			write("exec-env", env.Name, env.Value)
+		}
+	}
+	if cfg.AuthProvider != nil {
+		write("auth-provider", append([]string{cfg.AuthProvider.Name}, sortedMap(cfg.AuthProvider.Config)...)...)
+	}
+	imp := cfg.Impersonate
+	write("as", imp.UserName, imp.UID)
+	write("as-group", imp.Groups...)
+	extra := make([]string, 0, len(imp.Extra))
+	for k, v := range imp.Extra {
+		extra = append(extra, k+"="+strings.Join(v, ","))
+	}
+	sort.Strings(extra)
+	write("as-extra", extra...)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheableList reports whether req is a plain list of a cached collection.
+func cacheableList(req *http.Request) bool {
+	if req.Method != http.MethodGet || !cachedListPath.MatchString(req.URL.Path) {
+		return false
+	}
+	q := req.URL.Query()
+	for _, param := range []string{"watch", "limit", "continue", "resourceVersion"} {
+		if q.Has(param) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e listCacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,197 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func response(status int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestThrottleRetry(t *testing.T) {
+	calls := 0
+	var waits []time.Duration
+	rt := &throttleRetry{
+		next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			switch calls {
+			case 1:
+				return response(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"3"}}), nil
+			case 2:
+				return response(http.StatusTooManyRequests, "", nil), nil
+			default:
+				return response(http.StatusOK, "ok", nil), nil
+			}
+		}),
+		sleep: func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://cluster/api/v1/pods", http.NoBody)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{3 * time.Second, 2 * throttleBackoff}, waits, "Retry-After wins, then the doubled backoff")
+}
+
+func TestThrottleRetry_GivesUp(t *testing.T) {
+	calls := 0
+	rt := &throttleRetry{
+		next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			return response(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"600"}}), nil
+		}),
+		sleep: func(_ context.Context, d time.Duration) error {
+			assert.LessOrEqual(t, d, maxThrottleDelay)
+			return nil
+		},
+	}
+
+	resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://cluster/api/v1/pods", http.NoBody))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, maxThrottleRetries+1, calls)
+}
+
+func TestCacheableList(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"/api/v1/pods", true},
+		{"/api/v1/namespaces/prod/pods?labelSelector=app%3Dapi", true},
+		{"/api/v1/namespaces", true},
+		{"/apis/apps/v1/namespaces/prod/deployments", true},
+		{"/apis/batch/v1/cronjobs", true},
+		{"/k8s/clusters/c-1/api/v1/pods", true},
+		{"/api/v1/namespaces/prod", false},
+		{"/api/v1/namespaces/prod/pods/api-1", false},
+		{"/api/v1/namespaces/prod/events", false},
+		{"/apis/metrics.k8s.io/v1beta1/pods", false},
+		{"/api/v1/pods?watch=true", false},
+		{"/api/v1/pods?limit=500&resourceVersion=0", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "https://cluster"+tt.url, http.NoBody)
+		assert.Equal(t, tt.want, cacheableList(req), tt.url)
+	}
+}
+
+func TestListCacheTransport(t *testing.T) {
+	calls := 0
+	now := time.Now()
+	rt := &listCacheTransport{
+		next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			return response(http.StatusOK, `{"items":[]}`, nil), nil
+		}),
+		cache: &listCache{entries: make(map[string]listCacheEntry)},
+		ttl:   30 * time.Second,
+		now:   func() time.Time { return now },
+	}
+	get := func(url string) string {
+		resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://cluster"+url, http.NoBody))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	assert.JSONEq(t, `{"items":[]}`, get("/api/v1/pods"))
+	assert.JSONEq(t, `{"items":[]}`, get("/api/v1/pods"), "a cached body is replayed in full")
+	assert.Equal(t, 1, calls)
+
+	get("/api/v1/namespaces/prod/pods/api-1")
+	get("/api/v1/namespaces/prod/pods/api-1")
+	assert.Equal(t, 3, calls, "single objects are not cached")
+
+	_, err := rt.RoundTrip(httptest.NewRequest(http.MethodDelete, "https://cluster/api/v1/namespaces/prod/pods/api-1", http.NoBody))
+	require.NoError(t, err)
+	get("/api/v1/pods")
+	assert.Equal(t, 5, calls, "a write clears the cache")
+
+	now = now.Add(31 * time.Second)
+	get("/api/v1/pods")
+	assert.Equal(t, 6, calls, "entries expire after the TTL")
+}
+
+func TestApplyClientLayer_SharesListsAcrossClients(t *testing.T) {
+	var lists atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lists.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"kind":"PodList","apiVersion":"v1","items":[{"metadata":{"name":"api-1","namespace":"prod"}}]}`)
+	}))
+	defer srv.Close()
+
+	opts := KubeOpts{QPS: 50, Burst: 100, ListCacheTTL: time.Minute}
+	for range 2 {
+		cfg := &rest.Config{Host: srv.URL}
+		applyClientLayer(cfg, opts)
+		assert.InDelta(t, 50, cfg.QPS, 0)
+		assert.Equal(t, 100, cfg.Burst)
+
+		client, err := kubernetes.NewForConfig(cfg)
+		require.NoError(t, err)
+		pods, err := client.CoreV1().Pods("prod").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, pods.Items, 1)
+		assert.Equal(t, "api-1", pods.Items[0].Name)
+	}
+	assert.Equal(t, int32(1), lists.Load(), "the second client reads the first one's list")
+}
+
+func TestApplyClientLayer_SeparatesIdentities(t *testing.T) {
+	var lists atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lists.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Impersonate-User") == "jane" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"kind":"PodList","apiVersion":"v1","items":[{"metadata":{"name":"api-1","namespace":"prod"}}]}`)
+	}))
+	defer srv.Close()
+
+	list := func(cfg *rest.Config) error {
+		applyClientLayer(cfg, KubeOpts{ListCacheTTL: time.Minute})
+		client, err := kubernetes.NewForConfig(cfg)
+		require.NoError(t, err)
+		_, err = client.CoreV1().Pods("prod").List(context.Background(), metav1.ListOptions{})
+		return err
+	}
+
+	require.NoError(t, list(&rest.Config{Host: srv.URL, BearerToken: "admin"}))
+	assert.Error(t, list(&rest.Config{Host: srv.URL, BearerToken: "admin", Impersonate: rest.ImpersonationConfig{UserName: "jane"}}),
+		"an impersonated client does not read the admin's cached list")
+	require.NoError(t, list(&rest.Config{Host: srv.URL, BearerToken: "other"}))
+	assert.Equal(t, int32(3), lists.Load())
+
+	require.NoError(t, list(&rest.Config{Host: srv.URL, BearerToken: "admin"}))
+	assert.Equal(t, int32(3), lists.Load(), "the same identity still shares the cache")
+}
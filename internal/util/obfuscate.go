@@ -70,6 +70,14 @@ func (o *Obfuscator) Workload(name string) string {
 	return o.obfuscate("wl", name)
 }
 
+// Cluster obfuscates a cluster name
+func (o *Obfuscator) Cluster(name string) string {
+	if !o.enabled || name == "" {
+		return name
+	}
+	return o.obfuscate("cluster", name)
+}
+
 // Image obfuscates an image name
 func (o *Obfuscator) Image(name string) string {
 	if !o.enabled || name == "" {
@@ -78,6 +86,22 @@ func (o *Obfuscator) Image(name string) string {
 	return o.obfuscate("img", name)
 }
 
+// Release obfuscates a Helm release name
+func (o *Obfuscator) Release(name string) string {
+	if !o.enabled || name == "" {
+		return name
+	}
+	return o.obfuscate("rel", name)
+}
+
+// Team obfuscates a team name
+func (o *Obfuscator) Team(name string) string {
+	if !o.enabled || name == "" {
+		return name
+	}
+	return o.obfuscate("team", name)
+}
+
 // obfuscate generates a deterministic fake name from a real name
 func (o *Obfuscator) obfuscate(prefix, realName string) string {
 	o.mu.RLock()
@@ -5,17 +5,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
+
+	"github.com/ppiankov/kubenow/internal/cleanup"
 )
 
 // PortForwardStatus represents the current state of port-forward
@@ -67,23 +66,15 @@ type PortForward struct {
 	lastError    error
 	startTime    time.Time
 	restartCount int
+	release      func() // unregisters Stop from the exit cleanups
 }
 
-// NewPortForward creates a new native Go port-forward manager.
+// NewPortForward creates a new native Go port-forward manager, connecting
+// with the same kubeconfig, context, and impersonation as the other clients.
 // Pass 0 for timeout to use DefaultPortForwardTimeout.
-func NewPortForward(service, namespace, localPort, remotePort string, timeout time.Duration) (*PortForward, error) {
-	// Load kubeconfig
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		kubeconfig = filepath.Join(home, ".kube", "config")
-	}
-
-	// Build config from kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+func NewPortForward(opts KubeOpts, service, namespace, localPort, remotePort string, timeout time.Duration) (*PortForward, error) {
+	// SPDY upgrades bypass the client layer, so use the plain config
+	config, err := loadRestConfig(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
 	}
@@ -118,6 +109,7 @@ func (pf *PortForward) Start() error {
 		return fmt.Errorf("port-forward already running")
 	}
 	pf.status = StatusStarting
+	pf.release = cleanup.Register(fmt.Sprintf("port-forward to %s/%s", pf.namespace, pf.service), pf.Stop)
 	pf.mu.Unlock()
 
 	// Get service to find a pod
@@ -204,6 +196,10 @@ func (pf *PortForward) setupPortForward(podName string) error {
 	case <-pf.readyChan:
 		return nil
 	case <-time.After(pf.timeout):
+		pf.mu.Lock()
+		close(pf.stopChan)
+		pf.stopChan = nil
+		pf.mu.Unlock()
 		return fmt.Errorf("timeout waiting for port-forward to be ready (waited %s)", pf.timeout)
 	}
 }
@@ -213,6 +209,10 @@ func (pf *PortForward) Stop() error {
 	pf.mu.Lock()
 	defer pf.mu.Unlock()
 
+	if pf.release != nil {
+		pf.release()
+		pf.release = nil
+	}
 	if pf.status == StatusStopped {
 		return nil
 	}
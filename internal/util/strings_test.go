@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"namespace", "namespace", 0},
+		{"namespces", "namespaces", 1},
+		{"1.25.3", "1.25.4", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, EditDistance(tt.a, tt.b), "%q -> %q", tt.a, tt.b)
+		assert.Equal(t, tt.want, EditDistance(tt.b, tt.a), "%q -> %q", tt.b, tt.a)
+	}
+}
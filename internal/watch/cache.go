@@ -0,0 +1,216 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/util"
+)
+
+// informerResync is how often the informers replay their cache. Replays
+// carry no new state and do not count as changes.
+const informerResync = 10 * time.Minute
+
+// cacheSyncTimeout bounds the informers' first list.
+const cacheSyncTimeout = 2 * time.Minute
+
+// clusterCache keeps pods and nodes in shared informers, so iterations read
+// them from memory instead of listing the cluster, and records which
+// namespaces had a problem-relevant change, so unchanged iterations can skip
+// the analysis.
+type clusterCache struct {
+	factory informers.SharedInformerFactory
+	pods    corelisters.PodLister
+	nodes   corelisters.NodeLister
+
+	mu         sync.Mutex
+	generation uint64            // bumped on every recorded change
+	namespaces map[string]uint64 // generation of each namespace's last pod change
+	nodeGen    uint64            // generation of the last node change
+}
+
+// newClusterCache builds the informers for pods in namespace ("" for all)
+// and nodes. Start them with startClusterCache.
+func newClusterCache(clientset kubernetes.Interface, namespace string) *clusterCache {
+	c := &clusterCache{
+		factory: informers.NewSharedInformerFactoryWithOptions(clientset, informerResync,
+			informers.WithNamespace(namespace), informers.WithTransform(util.StripManagedFields)),
+		namespaces: make(map[string]uint64),
+	}
+
+	podInformer := c.factory.Core().V1().Pods()
+	c.pods = podInformer.Lister()
+	// Registration handles are only needed to remove handlers
+	_, _ = podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			if pod, ok := obj.(*corev1.Pod); ok && snapshot.IsProblemPod(pod) {
+				c.podChanged(pod.Namespace)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldPod, ok1 := oldObj.(*corev1.Pod)
+			newPod, ok2 := newObj.(*corev1.Pod)
+			if ok1 && ok2 && podSignature(oldPod) != podSignature(newPod) {
+				c.podChanged(newPod.Namespace)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if tomb, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tomb.Obj
+			}
+			if pod, ok := obj.(*corev1.Pod); ok && snapshot.IsProblemPod(pod) {
+				c.podChanged(pod.Namespace)
+			}
+		},
+	})
+
+	nodeInformer := c.factory.Core().V1().Nodes()
+	c.nodes = nodeInformer.Lister()
+	_, _ = nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(any) { c.nodeChanged() },
+		UpdateFunc: func(oldObj, newObj any) {
+			oldNode, ok1 := oldObj.(*corev1.Node)
+			newNode, ok2 := newObj.(*corev1.Node)
+			if ok1 && ok2 && nodeSignature(oldNode) != nodeSignature(newNode) {
+				c.nodeChanged()
+			}
+		},
+		DeleteFunc: func(any) { c.nodeChanged() },
+	})
+	return c
+}
+
+// startClusterCache starts the informers and waits up to cacheSyncTimeout
+// for their first list. The returned function stops them.
+func startClusterCache(ctx context.Context, clientset kubernetes.Interface, namespace string) (*clusterCache, func(), error) {
+	c := newClusterCache(clientset, namespace)
+	cacheCtx, cancel := context.WithCancel(ctx)
+	stop := func() {
+		cancel()
+		c.factory.Shutdown()
+	}
+	c.factory.Start(cacheCtx.Done())
+
+	syncCtx, cancelSync := context.WithTimeout(cacheCtx, cacheSyncTimeout)
+	defer cancelSync()
+	for typ, synced := range c.factory.WaitForCacheSync(syncCtx.Done()) {
+		if !synced {
+			stop()
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			return nil, nil, fmt.Errorf("timed out syncing the %v informer cache (needs list and watch on pods and nodes)", typ)
+		}
+	}
+	return c, stop, nil
+}
+
+// currentGeneration returns the change generation, to pass to
+// changedSince at the next iteration.
+func (c *clusterCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// changedSince reports whether nodes, or pods in a namespace selected by
+// namespace and filters, changed after generation gen.
+func (c *clusterCache) changedSince(gen uint64, namespace string, filters *snapshot.Filters) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nodeGen > gen {
+		return true
+	}
+	for ns, changed := range c.namespaces {
+		if changed > gen && (namespace == "" || ns == namespace) && filters.MatchesNamespace(ns) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *clusterCache) podChanged(namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.namespaces[namespace] = c.generation
+}
+
+func (c *clusterCache) nodeChanged() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.nodeGen = c.generation
+}
+
+// buildSnapshot builds a snapshot from the cached nodes and pods of namespace
+// ("" for all), in namespace/name order like a list call returns them.
+func (c *clusterCache) buildSnapshot(ctx context.Context, clientset kubernetes.Interface, config *Config) (*snapshot.Snapshot, error) {
+	nodes, err := c.nodes.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list cached nodes: %w", err)
+	}
+	var pods []*corev1.Pod
+	if config.Namespace != "" {
+		pods, err = c.pods.Pods(config.Namespace).List(labels.Everything())
+	} else {
+		pods, err = c.pods.List(labels.Everything())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list cached pods: %w", err)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return snapshot.BuildSnapshotFrom(ctx, clientset, config.Namespace, nodes, pods,
+		config.MaxPods, config.LogLines, config.MaxConcurrent, &config.Filters), nil
+}
+
+// podSignature summarizes the pod state a snapshot reports on; healthy pods
+// share the empty signature, so their churn is not a change.
+func podSignature(pod *corev1.Pod) string {
+	if !snapshot.IsProblemPod(pod) {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s/%s", pod.Status.Phase, pod.Status.Reason)
+	for i := range pod.Status.ContainerStatuses {
+		cs := &pod.Status.ContainerStatuses[i]
+		fmt.Fprintf(&b, "|%s:%t:%d", cs.Name, cs.Ready, cs.RestartCount)
+		switch {
+		case cs.State.Waiting != nil:
+			b.WriteString(":" + cs.State.Waiting.Reason)
+		case cs.State.Terminated != nil:
+			b.WriteString(":" + cs.State.Terminated.Reason)
+		}
+	}
+	return b.String()
+}
+
+// nodeSignature summarizes a node's condition statuses; heartbeat updates
+// leave it unchanged.
+func nodeSignature(node *corev1.Node) string {
+	var b strings.Builder
+	for i := range node.Status.Conditions {
+		c := &node.Status.Conditions[i]
+		fmt.Fprintf(&b, "%s=%s/%s;", c.Type, c.Status, c.Reason)
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package watch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/synthetic"
+)
+
+func testPod(namespace, name string, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: restarts == 0, RestartCount: restarts}},
+		},
+	}
+}
+
+func testNode(ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}},
+	}
+}
+
+func TestClusterCache_ChangedSince(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := fake.NewClientset(testPod("prod", "api-1", 0), testPod("dev", "web-1", 2), testNode(corev1.ConditionTrue))
+	c, stop, err := startClusterCache(ctx, client, "")
+	require.NoError(t, err)
+	defer stop()
+
+	all := &snapshot.Filters{}
+	prodOnly := &snapshot.Filters{IncludeNamespaces: "prod"}
+	assert.True(t, c.changedSince(0, "", all), "the initial list counts as a change")
+	gen := c.currentGeneration()
+	assert.False(t, c.changedSince(gen, "", all))
+
+	// Healthy pod churn and node heartbeats are not changes
+	healthy := testPod("prod", "api-1", 0)
+	healthy.Labels = map[string]string{"rollout": "2"}
+	_, err = client.CoreV1().Pods("prod").Update(ctx, healthy, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	node := testNode(corev1.ConditionTrue)
+	node.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+	_, err = client.CoreV1().Nodes().UpdateStatus(ctx, node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Never(t, func() bool { return c.changedSince(gen, "", all) }, 200*time.Millisecond, 10*time.Millisecond)
+
+	// A restart in dev changes dev only
+	_, err = client.CoreV1().Pods("dev").UpdateStatus(ctx, testPod("dev", "web-1", 3), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return c.changedSince(gen, "", all) }, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, c.changedSince(gen, "", prodOnly))
+	assert.False(t, c.changedSince(gen, "prod", all))
+
+	// A node going NotReady concerns every namespace
+	_, err = client.CoreV1().Nodes().UpdateStatus(ctx, testNode(corev1.ConditionFalse), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return c.changedSince(gen, "prod", prodOnly) }, 5*time.Second, 10*time.Millisecond)
+}
+
+// Iterations read pods and nodes from the informers: only the informers'
+// initial list hits the API, however many snapshots are built.
+func TestClusterCache_BuildSnapshotWithoutLists(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := synthetic.Generate(synthetic.Small).Clientset()
+	c, stop, err := startClusterCache(ctx, client, "")
+	require.NoError(t, err)
+	defer stop()
+
+	config := &Config{MaxPods: 5}
+	listed, err := snapshot.BuildSnapshot(ctx, client, "", 5, 50, 5, &snapshot.Filters{})
+	require.NoError(t, err)
+	before := synthetic.CountActions(client)
+
+	for i := 0; i < 3; i++ {
+		snap, err := c.buildSnapshot(ctx, client, config)
+		require.NoError(t, err)
+		assert.Equal(t, podNames(listed), podNames(snap), "same problem pods, in list order")
+	}
+	after := synthetic.CountActions(client)
+	assert.Equal(t, before["list pods"], after["list pods"])
+	assert.Equal(t, before["list nodes"], after["list nodes"])
+}
+
+func podNames(snap *snapshot.Snapshot) []string {
+	names := make([]string, 0, len(snap.ProblemPods))
+	for i := range snap.ProblemPods {
+		names = append(names, snap.ProblemPods[i].Namespace+"/"+snap.ProblemPods[i].Name)
+	}
+	return names
+}
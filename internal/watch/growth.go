@@ -0,0 +1,332 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/ppiankov/kubenow/internal/metrics"
+	"github.com/ppiankov/kubenow/internal/notify"
+	"github.com/ppiankov/kubenow/internal/units"
+)
+
+// DefaultGrowthIterations is how many consecutive increases a growth
+// detector needs before it fires.
+const DefaultGrowthIterations = 3
+
+// GrowthConfig enables rate-of-change detectors on workload usage. Rates
+// are percent per hour, so they do not depend on the watch interval; zero
+// disables a detector.
+type GrowthConfig struct {
+	MemoryRate float64
+	CPURate    float64
+	Iterations int // consecutive growing intervals required; DefaultGrowthIterations if zero
+}
+
+// Enabled reports whether any detector is configured.
+func (g GrowthConfig) Enabled() bool {
+	return g.MemoryRate > 0 || g.CPURate > 0
+}
+
+func (g GrowthConfig) iterations() int {
+	if g.Iterations > 0 {
+		return g.Iterations
+	}
+	return DefaultGrowthIterations
+}
+
+// WorkloadUsage is the usage of a workload's hottest pod: the pod using
+// the most memory (or CPU) is the one that runs out first.
+type WorkloadUsage struct {
+	Namespace   string
+	Workload    string
+	CPU         float64 // cores, highest pod
+	Memory      float64 // bytes, highest pod
+	MemoryLimit float64 // bytes, limit of the highest-memory pod; 0 if any container is unlimited
+}
+
+// UsageSource reports current workload usage for the growth detectors.
+type UsageSource interface {
+	WorkloadUsage(ctx context.Context, namespace string) ([]WorkloadUsage, error)
+}
+
+// MetricsUsageSource reads usage from the Metrics API and memory limits
+// from pod specs.
+type MetricsUsageSource struct {
+	Kube    kubernetes.Interface
+	Metrics metricsclientset.Interface
+}
+
+// WorkloadUsage lists pod metrics in namespace (all when empty) and keeps
+// the highest CPU and memory pod of each workload.
+func (s *MetricsUsageSource) WorkloadUsage(ctx context.Context, namespace string) ([]WorkloadUsage, error) {
+	podMetrics, err := s.Metrics.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+	pods, err := s.Kube.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	type podInfo struct {
+		labels   map[string]string
+		memLimit float64
+	}
+	infos := make(map[string]podInfo, len(pods.Items))
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		var limit float64
+		for j := range p.Spec.Containers {
+			l, ok := p.Spec.Containers[j].Resources.Limits["memory"]
+			if !ok {
+				limit = 0
+				break
+			}
+			limit += float64(l.Value())
+		}
+		infos[p.Namespace+"/"+p.Name] = podInfo{labels: p.Labels, memLimit: limit}
+	}
+
+	byWorkload := make(map[string]*WorkloadUsage)
+	for i := range podMetrics.Items {
+		pm := &podMetrics.Items[i]
+		info := infos[pm.Namespace+"/"+pm.Name]
+		workload, _ := metrics.ResolveWorkloadIdentity(pm.Name, info.labels)
+		var cpu, mem float64
+		for j := range pm.Containers {
+			cpu += pm.Containers[j].Usage.Cpu().AsApproximateFloat64()
+			mem += float64(pm.Containers[j].Usage.Memory().Value())
+		}
+		key := pm.Namespace + "/" + workload
+		u, ok := byWorkload[key]
+		if !ok {
+			u = &WorkloadUsage{Namespace: pm.Namespace, Workload: workload}
+			byWorkload[key] = u
+		}
+		u.CPU = max(u.CPU, cpu)
+		if mem > u.Memory {
+			u.Memory, u.MemoryLimit = mem, info.memLimit
+		}
+	}
+
+	out := make([]WorkloadUsage, 0, len(byWorkload))
+	for _, u := range byWorkload {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Namespace+"/"+out[i].Workload < out[j].Namespace+"/"+out[j].Workload
+	})
+	return out, nil
+}
+
+// usagePoint is one observation of a workload.
+type usagePoint struct {
+	time        time.Time
+	cpu, memory float64
+	memoryLimit float64
+}
+
+// GrowthAlert is a workload whose usage grew faster than the configured
+// rate over consecutive iterations.
+type GrowthAlert struct {
+	Namespace string
+	Workload  string
+	Trend     notify.Trend
+}
+
+// growthTracker keeps each workload's recent usage across iterations and
+// fires once when a detector starts matching; it fires again only after
+// the growth stopped.
+type growthTracker struct {
+	config  GrowthConfig
+	history map[string][]usagePoint
+	firing  map[string]bool // workload key + "/" + resource
+}
+
+func newGrowthTracker(config GrowthConfig) *growthTracker {
+	return &growthTracker{config: config, history: make(map[string][]usagePoint), firing: make(map[string]bool)}
+}
+
+// observe records an iteration's usage and returns the newly firing alerts.
+func (t *growthTracker) observe(now time.Time, usage []WorkloadUsage) []GrowthAlert {
+	keep := t.config.iterations() + 1
+	seen := make(map[string]bool, len(usage))
+	var alerts []GrowthAlert
+	for _, u := range usage {
+		key := u.Namespace + "/" + u.Workload
+		seen[key] = true
+		h := append(t.history[key], usagePoint{time: now, cpu: u.CPU, memory: u.Memory, memoryLimit: u.MemoryLimit})
+		if len(h) > keep {
+			h = h[len(h)-keep:]
+		}
+		t.history[key] = h
+		if len(h) < keep {
+			continue
+		}
+		if trend, ok := t.check(key, "memory", t.config.MemoryRate, h); ok {
+			alerts = append(alerts, GrowthAlert{Namespace: u.Namespace, Workload: u.Workload, Trend: trend})
+		}
+		if trend, ok := t.check(key, "cpu", t.config.CPURate, h); ok {
+			alerts = append(alerts, GrowthAlert{Namespace: u.Namespace, Workload: u.Workload, Trend: trend})
+		}
+	}
+	for key := range t.history {
+		if !seen[key] {
+			delete(t.history, key)
+			delete(t.firing, key+"/memory")
+			delete(t.firing, key+"/cpu")
+		}
+	}
+	return alerts
+}
+
+// check evaluates one detector on a full history window. It fires when
+// every interval grew at least rate percent per hour and the detector was
+// not already firing.
+func (t *growthTracker) check(key, resource string, rate float64, h []usagePoint) (notify.Trend, bool) {
+	if rate <= 0 {
+		return notify.Trend{}, false
+	}
+	value := func(p usagePoint) float64 {
+		if resource == "memory" {
+			return p.memory
+		}
+		return p.cpu
+	}
+
+	firingKey := key + "/" + resource
+	for i := 1; i < len(h); i++ {
+		if ratePerHour(value(h[i-1]), value(h[i]), h[i].time.Sub(h[i-1].time)) < rate {
+			delete(t.firing, firingKey)
+			return notify.Trend{}, false
+		}
+	}
+	if t.firing[firingKey] {
+		return notify.Trend{}, false
+	}
+	t.firing[firingKey] = true
+
+	first, last := h[0], h[len(h)-1]
+	elapsed := last.time.Sub(first.time)
+	trend := notify.Trend{
+		Resource:           resource,
+		Unit:               "cores",
+		RatePercentPerHour: ratePerHour(value(first), value(last), elapsed),
+	}
+	for _, p := range h {
+		trend.Samples = append(trend.Samples, notify.TrendPoint{Time: p.time, Value: value(p)})
+	}
+	if resource == "memory" {
+		trend.Unit = "bytes"
+		trend.Limit = last.memoryLimit
+		if perSecond := (last.memory - first.memory) / elapsed.Seconds(); trend.Limit > last.memory && perSecond > 0 {
+			trend.SecondsToLimit = (trend.Limit - last.memory) / perSecond
+		}
+	}
+	return trend, true
+}
+
+// ratePerHour is the relative growth from prev to curr in percent per hour.
+// A zero baseline or interval yields 0.
+func ratePerHour(prev, curr float64, d time.Duration) float64 {
+	if prev <= 0 || d <= 0 {
+		return 0
+	}
+	return (curr - prev) / prev * 100 / d.Hours()
+}
+
+// checkGrowth samples workload usage and reports and notifies about
+// workloads whose usage grows faster than the configured rates. It runs
+// independently of the LLM analysis.
+func checkGrowth(ctx context.Context, config *Config, now time.Time) {
+	if config.growth == nil || config.Usage == nil {
+		return
+	}
+	usage, err := config.Usage.WorkloadUsage(ctx, config.Namespace)
+	if err != nil {
+		logger(config).Warn("Usage growth check skipped", "error", err)
+		return
+	}
+	filtered := usage[:0]
+	for _, u := range usage {
+		if config.Filters.MatchesNamespace(u.Namespace) {
+			filtered = append(filtered, u)
+		}
+	}
+
+	alerts := config.growth.observe(now, filtered)
+	if len(alerts) == 0 {
+		return
+	}
+	for i := range alerts {
+		logger(config).Warn("Usage growth detected", "namespace", alerts[i].Namespace, "workload", alerts[i].Workload,
+			"growth", growthSummary(&alerts[i].Trend))
+	}
+
+	if config.Notifier == nil {
+		return
+	}
+	title := fmt.Sprintf("kubenow: %d usage growth alert(s)", len(alerts))
+	if config.Namespace != "" {
+		title += " in " + config.Namespace
+	}
+	sent, err := config.Notifier.Send(ctx, title, growthAlerts(alerts))
+	if err != nil {
+		logger(config).Warn("Notification failed", "error", err)
+	}
+	if sent > 0 {
+		logger(config).Info("Sent notifications", "sent", sent)
+	}
+}
+
+// growthAlerts converts growth alerts to notifications. Memory growth is
+// high severity, critical when the limit is projected within an hour; CPU
+// growth is medium.
+func growthAlerts(alerts []GrowthAlert) []notify.Alert {
+	out := make([]notify.Alert, 0, len(alerts))
+	for i := range alerts {
+		a := &alerts[i]
+		severity, issueType := notify.SeverityMedium, "CPUGrowth"
+		if a.Trend.Resource == "memory" {
+			severity, issueType = notify.SeverityHigh, "MemoryGrowth"
+			if a.Trend.SecondsToLimit > 0 && a.Trend.SecondsToLimit <= time.Hour.Seconds() {
+				severity = notify.SeverityCritical
+			}
+		}
+		trend := a.Trend
+		out = append(out, notify.Alert{
+			Severity:  severity,
+			Namespace: a.Namespace,
+			Name:      a.Workload,
+			IssueType: issueType,
+			Summary:   growthSummary(&trend),
+			Trend:     &trend,
+		})
+	}
+	return out
+}
+
+// growthSummary renders e.g. "memory +25.0%/h over 3 checks (400.00Mi -> 512.00Mi), limit 1.00Gi in ~1h0m".
+func growthSummary(t *notify.Trend) string {
+	format := func(v float64) string {
+		if t.Resource == "memory" {
+			return units.Memory(v)
+		}
+		return units.Current().WithPrecision(3).Cores(v) + " cores"
+	}
+	first, last := t.Samples[0].Value, t.Samples[len(t.Samples)-1].Value
+	s := fmt.Sprintf("%s +%.1f%%/h over %d checks (%s -> %s)",
+		t.Resource, t.RatePercentPerHour, len(t.Samples)-1, format(first), format(last))
+	if t.Limit > 0 {
+		s += ", limit " + format(t.Limit)
+		if t.SecondsToLimit > 0 {
+			s += fmt.Sprintf(" in ~%s", (time.Duration(t.SecondsToLimit) * time.Second).Round(time.Minute))
+		}
+	}
+	return s
+}
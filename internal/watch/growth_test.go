@@ -0,0 +1,181 @@
+package watch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+
+	"github.com/ppiankov/kubenow/internal/notify"
+)
+
+const mi = 1024 * 1024
+
+func TestGrowthTracker(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * 10 * time.Minute) }
+	api := func(mem float64) []WorkloadUsage {
+		return []WorkloadUsage{{Namespace: "prod", Workload: "api", CPU: 0.5, Memory: mem, MemoryLimit: 512 * mi}}
+	}
+
+	tracker := newGrowthTracker(GrowthConfig{MemoryRate: 20, CPURate: 20})
+
+	// +5% per 10 minutes is ~30%/h; three growing intervals are needed.
+	mem := []float64{400 * mi, 420 * mi, 441 * mi}
+	for i, m := range mem {
+		assert.Empty(t, tracker.observe(at(i), api(m)), "iteration %d", i)
+	}
+	alerts := tracker.observe(at(3), api(463*mi))
+	require.Len(t, alerts, 1)
+	trend := alerts[0].Trend
+	assert.Equal(t, "api", alerts[0].Workload)
+	assert.Equal(t, "memory", trend.Resource)
+	assert.Equal(t, "bytes", trend.Unit)
+	assert.Len(t, trend.Samples, 4)
+	assert.InDelta(t, 31.5, trend.RatePercentPerHour, 0.1)
+	assert.Equal(t, float64(512*mi), trend.Limit)
+	// 63Mi in 30 minutes leaves 49Mi, about 23 minutes.
+	assert.InDelta(t, 23*60, trend.SecondsToLimit, 30)
+
+	// Still growing: no repeat while firing.
+	assert.Empty(t, tracker.observe(at(4), api(486*mi)))
+
+	// A flat interval clears the detector; it fires again after three more.
+	assert.Empty(t, tracker.observe(at(5), api(486*mi)))
+	m := 486.0 * mi
+	for i := 6; i < 8; i++ {
+		m *= 1.06
+		assert.Empty(t, tracker.observe(at(i), api(m)))
+	}
+	m *= 1.06
+	assert.Len(t, tracker.observe(at(8), api(m)), 1)
+}
+
+func TestGrowthTracker_SlowGrowthAndDisabledDetectors(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newGrowthTracker(GrowthConfig{MemoryRate: 50, Iterations: 2})
+
+	// Memory grows ~30%/h, below the threshold; CPU doubles but is disabled.
+	cpu, mem := 0.1, 100.0*mi
+	for i := 0; i < 5; i++ {
+		usage := []WorkloadUsage{{Namespace: "prod", Workload: "api", CPU: cpu, Memory: mem}}
+		assert.Empty(t, tracker.observe(start.Add(time.Duration(i)*10*time.Minute), usage))
+		cpu *= 2
+		mem *= 1.05
+	}
+}
+
+func TestGrowthTracker_ForgetsGoneWorkloads(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newGrowthTracker(GrowthConfig{CPURate: 10, Iterations: 1})
+
+	tracker.observe(start, []WorkloadUsage{{Namespace: "prod", Workload: "api", CPU: 1}})
+	tracker.observe(start.Add(time.Minute), []WorkloadUsage{{Namespace: "prod", Workload: "web", CPU: 1}})
+	assert.NotContains(t, tracker.history, "prod/api")
+
+	// api reappears with a fresh history, so one higher sample is not enough.
+	alerts := tracker.observe(start.Add(2*time.Minute), []WorkloadUsage{
+		{Namespace: "prod", Workload: "api", CPU: 2},
+		{Namespace: "prod", Workload: "web", CPU: 2},
+	})
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "web", alerts[0].Workload)
+	assert.Equal(t, "cpu", alerts[0].Trend.Resource)
+	assert.Equal(t, "cores", alerts[0].Trend.Unit)
+}
+
+func TestGrowthAlerts(t *testing.T) {
+	samples := []notify.TrendPoint{{Value: 400 * mi}, {Value: 500 * mi}}
+	tests := []struct {
+		name      string
+		trend     notify.Trend
+		severity  notify.Severity
+		issueType string
+		summary   string
+	}{
+		{
+			name:      "memory near limit",
+			trend:     notify.Trend{Resource: "memory", RatePercentPerHour: 25, Samples: samples, Limit: 512 * mi, SecondsToLimit: 720},
+			severity:  notify.SeverityCritical,
+			issueType: "MemoryGrowth",
+			summary:   "memory +25.0%/h over 1 checks (400.00Mi -> 500.00Mi), limit 512.00Mi in ~12m0s",
+		},
+		{
+			name:      "memory without limit",
+			trend:     notify.Trend{Resource: "memory", RatePercentPerHour: 25, Samples: samples},
+			severity:  notify.SeverityHigh,
+			issueType: "MemoryGrowth",
+			summary:   "memory +25.0%/h over 1 checks (400.00Mi -> 500.00Mi)",
+		},
+		{
+			name:      "cpu",
+			trend:     notify.Trend{Resource: "cpu", RatePercentPerHour: 40, Samples: []notify.TrendPoint{{Value: 0.25}, {Value: 0.35}}},
+			severity:  notify.SeverityMedium,
+			issueType: "CPUGrowth",
+			summary:   "cpu +40.0%/h over 1 checks (0.250 cores -> 0.350 cores)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := growthAlerts([]GrowthAlert{{Namespace: "prod", Workload: "api", Trend: tt.trend}})
+			require.Len(t, alerts, 1)
+			assert.Equal(t, tt.severity, alerts[0].Severity)
+			assert.Equal(t, tt.issueType, alerts[0].IssueType)
+			assert.Equal(t, tt.summary, alerts[0].Summary)
+			require.NotNil(t, alerts[0].Trend)
+			assert.Equal(t, tt.trend.RatePercentPerHour, alerts[0].Trend.RatePercentPerHour)
+		})
+	}
+}
+
+func TestMetricsUsageSource(t *testing.T) {
+	pod := func(name string, limits ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "prod", Labels: map[string]string{"app.kubernetes.io/name": "api"},
+		}}
+		for _, l := range limits {
+			c := corev1.Container{Name: "c"}
+			if l != "" {
+				c.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(l)}
+			}
+			p.Spec.Containers = append(p.Spec.Containers, c)
+		}
+		return p
+	}
+	usage := func(name, cpu, mem string) metricsv1beta1.PodMetrics {
+		return metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "c", Usage: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem),
+			}}},
+		}
+	}
+
+	kube := fake.NewClientset(pod("api-7d9f8b6c4-abcde", "256Mi", "256Mi"), pod("api-7d9f8b6c4-fghij", "512Mi", ""))
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{
+			usage("api-7d9f8b6c4-abcde", "200m", "300Mi"),
+			usage("api-7d9f8b6c4-fghij", "400m", "100Mi"),
+		}}, nil
+	})
+
+	src := &MetricsUsageSource{Kube: kube, Metrics: metricsClient}
+	got, err := src.WorkloadUsage(context.Background(), "prod")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "api", got[0].Workload)
+	assert.InDelta(t, 0.4, got[0].CPU, 0.001)
+	assert.Equal(t, float64(300*mi), got[0].Memory)
+	assert.Equal(t, float64(512*mi), got[0].MemoryLimit)
+}
@@ -0,0 +1,211 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/notify"
+	"github.com/ppiankov/kubenow/internal/result"
+)
+
+// notifyIssues pushes the findings for new or changed issues to the
+// configured webhooks. LLM findings are used when the response carries
+// per-issue severities; otherwise the issues themselves are sent with the
+// deterministic monitor severity.
+func notifyIssues(ctx context.Context, config *Config, issues []IssueIdentity, raw, mode string) {
+	if config.Notifier == nil || len(issues) == 0 {
+		return
+	}
+
+	alerts := matchFindings(llmAlerts(raw, mode), issues)
+	if len(alerts) == 0 {
+		alerts = issueAlerts(issues)
+	}
+
+	title := fmt.Sprintf("kubenow: %d new issue(s)", len(issues))
+	if config.Namespace != "" {
+		title += " in " + config.Namespace
+	}
+	sent, err := config.Notifier.Send(ctx, title, alerts)
+	if err != nil {
+		logger(config).Warn("Notification failed", "error", err)
+	}
+	if sent > 0 {
+		logger(config).Info("Sent notifications", "sent", sent)
+	}
+}
+
+// llmAlerts extracts findings with severities from an LLM response.
+// Modes without per-issue severities (teamlead, chaos) yield none.
+func llmAlerts(raw, mode string) []notify.Alert {
+	jsonStr, err := llm.ExtractJSON(raw)
+	if err != nil {
+		return nil
+	}
+
+	var alerts []notify.Alert
+	add := func(severity, namespace, name, issueType, summary string) {
+		alerts = append(alerts, notify.Alert{
+			Severity:  notify.ParseSeverity(severity),
+			Namespace: namespace,
+			Name:      name,
+			IssueType: issueType,
+			Summary:   summary,
+		})
+	}
+
+	switch mode {
+	case "pod":
+		var pr result.PodResult
+		if json.Unmarshal([]byte(jsonStr), &pr) == nil {
+			for _, p := range pr.Pods {
+				add(p.Severity, p.Namespace, p.Name, p.IssueType, p.Summary)
+			}
+		}
+	case "incident":
+		var ir result.IncidentResult
+		if json.Unmarshal([]byte(jsonStr), &ir) == nil {
+			for _, i := range ir.TopIssues {
+				add(i.Severity, i.Namespace, i.Name, i.IssueType, i.Summary)
+			}
+		}
+	case "compliance":
+		var cr result.ComplianceResult
+		if json.Unmarshal([]byte(jsonStr), &cr) == nil {
+			for _, i := range cr.Issues {
+				add(i.Severity, i.Namespace, i.Name, i.Type, i.Description)
+			}
+		}
+	case "security":
+		var sr result.SecurityResult
+		if json.Unmarshal([]byte(jsonStr), &sr) == nil {
+			for _, f := range sr.Findings {
+				add(f.Severity, f.Namespace, f.Resource, f.Category, f.Issue)
+			}
+		}
+	case "teamlead", "chaos":
+	default:
+		var dr result.DefaultResult
+		if json.Unmarshal([]byte(jsonStr), &dr) == nil {
+			for _, i := range dr.Issues {
+				add(i.Severity, i.Namespace, i.Name, i.IssueType, i.ShortSummary)
+			}
+		}
+	}
+	return alerts
+}
+
+// matchFindings keeps the findings that refer to one of the issues' pods,
+// either by pod name or by the owning workload's name prefix.
+func matchFindings(alerts []notify.Alert, issues []IssueIdentity) []notify.Alert {
+	var matched []notify.Alert
+	for _, a := range alerts {
+		for _, issue := range issues {
+			if a.Namespace == issue.Namespace && (a.Name == issue.PodName || strings.HasPrefix(issue.PodName, a.Name+"-")) {
+				matched = append(matched, a)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// issueAlerts converts issues to alerts using the real-time monitor's
+// classification (see snapshot.Triage).
+func issueAlerts(issues []IssueIdentity) []notify.Alert {
+	alerts := make([]notify.Alert, 0, len(issues))
+	for _, issue := range issues {
+		name := issue.PodName
+		if issue.ContainerName != "" {
+			name += "/" + issue.ContainerName
+		}
+		alerts = append(alerts, notify.Alert{
+			Severity:  issueSeverity(issue.IssueType),
+			Namespace: issue.Namespace,
+			Name:      name,
+			IssueType: issue.IssueType,
+		})
+	}
+	return alerts
+}
+
+func issueSeverity(issueType string) notify.Severity {
+	switch issueType {
+	case "CrashLoopBackOff", "OOMKilled":
+		return notify.SeverityCritical
+	case "ImagePullBackOff", "ErrImagePull", "Failed", "Evicted":
+		return notify.SeverityHigh
+	default:
+		return notify.SeverityMedium
+	}
+}
+
+// syncIncidents opens an incident for each new fatal issue and resolves
+// the incidents of fatal issues that went away or are no longer fatal.
+// Issues keep their incident across type changes because the dedup key
+// derives from the fingerprint.
+func syncIncidents(ctx context.Context, config *Config, diff IssueDiff) {
+	if config.Incidents == nil {
+		return
+	}
+	var open, resolve []IssueIdentity
+	for _, issue := range diff.NewIssues {
+		if isFatal(issue) {
+			open = append(open, issue)
+		}
+	}
+	for _, change := range diff.ChangedIssues {
+		switch {
+		case isFatal(change.Current):
+			open = append(open, change.Current)
+		case isFatal(change.Previous):
+			resolve = append(resolve, change.Previous)
+		}
+	}
+	for _, issue := range diff.ResolvedIssues {
+		if isFatal(issue) {
+			resolve = append(resolve, issue)
+		}
+	}
+
+	opened, resolved := 0, 0
+	for _, issue := range open {
+		if err := config.Incidents.Open(ctx, issueIncident(issue)); err != nil {
+			logger(config).Warn("Opening incident failed", "error", err)
+			continue
+		}
+		opened++
+	}
+	for _, issue := range resolve {
+		if err := config.Incidents.Resolve(ctx, issueIncident(issue)); err != nil {
+			logger(config).Warn("Resolving incident failed", "error", err)
+			continue
+		}
+		resolved++
+	}
+	if opened > 0 || resolved > 0 {
+		logger(config).Info("Incidents synced", "opened", opened, "resolved", resolved)
+	}
+}
+
+// isFatal reports whether an issue is fatal in the real-time monitor's
+// classification (CrashLoopBackOff, OOMKilled).
+func isFatal(issue IssueIdentity) bool {
+	return issueSeverity(issue.IssueType) == notify.SeverityCritical
+}
+
+func issueIncident(issue IssueIdentity) *notify.Incident {
+	name := issue.PodName
+	if issue.ContainerName != "" {
+		name += "/" + issue.ContainerName
+	}
+	return &notify.Incident{
+		Fingerprint: issue.Fingerprint(),
+		Namespace:   issue.Namespace,
+		Name:        name,
+		IssueType:   issue.IssueType,
+	}
+}
@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/notify"
+)
+
+func TestLLMAlerts(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		mode string
+		want []notify.Alert
+	}{
+		{
+			name: "incident",
+			raw:  `Here you go: {"top_issues":[{"namespace":"prod","name":"api","severity":"critical","issue_type":"CrashLoopBackOff","summary":"bad config"}]}`,
+			mode: "incident",
+			want: []notify.Alert{{Severity: notify.SeverityCritical, Namespace: "prod", Name: "api", IssueType: "CrashLoopBackOff", Summary: "bad config"}},
+		},
+		{
+			name: "default",
+			raw:  `{"issues":[{"namespace":"dev","name":"job-1","issue_type":"Pending","severity":"medium","short_summary":"no nodes"}]}`,
+			mode: "default",
+			want: []notify.Alert{{Severity: notify.SeverityMedium, Namespace: "dev", Name: "job-1", IssueType: "Pending", Summary: "no nodes"}},
+		},
+		{name: "teamlead has no severities", raw: `{"business_risk":["x"]}`, mode: "teamlead"},
+		{name: "no json", raw: "plain text", mode: "incident"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, llmAlerts(tt.raw, tt.mode))
+		})
+	}
+}
+
+func TestMatchFindings(t *testing.T) {
+	issues := []IssueIdentity{
+		{Namespace: "prod", PodName: "api-7d9f8-x2k4p", ContainerName: "app", IssueType: "CrashLoopBackOff"},
+		{Namespace: "prod", PodName: "db-0", IssueType: "Pending"},
+	}
+	findings := []notify.Alert{
+		{Namespace: "prod", Name: "api", Severity: notify.SeverityCritical},
+		{Namespace: "prod", Name: "db-0", Severity: notify.SeverityHigh},
+		{Namespace: "prod", Name: "ap", Severity: notify.SeverityHigh},
+		{Namespace: "dev", Name: "api", Severity: notify.SeverityHigh},
+	}
+	assert.Equal(t, findings[:2], matchFindings(findings, issues))
+}
+
+func TestIssueAlerts(t *testing.T) {
+	got := issueAlerts([]IssueIdentity{
+		{Namespace: "prod", PodName: "api-1", ContainerName: "app", IssueType: "OOMKilled"},
+		{Namespace: "prod", PodName: "web-1", ContainerName: "web", IssueType: "ErrImagePull"},
+		{Namespace: "dev", PodName: "job-1", IssueType: "Pending"},
+	})
+	assert.Equal(t, []notify.Alert{
+		{Severity: notify.SeverityCritical, Namespace: "prod", Name: "api-1/app", IssueType: "OOMKilled"},
+		{Severity: notify.SeverityHigh, Namespace: "prod", Name: "web-1/web", IssueType: "ErrImagePull"},
+		{Severity: notify.SeverityMedium, Namespace: "dev", Name: "job-1", IssueType: "Pending"},
+	}, got)
+}
+
+func TestSyncIncidents(t *testing.T) {
+	var mu sync.Mutex
+	var actions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			EventAction string `json:"event_action"`
+			DedupKey    string `json:"dedup_key"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		actions = append(actions, body.EventAction+" "+body.DedupKey)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	config := &Config{Incidents: &notify.Incidents{
+		Cluster:  "prod",
+		Services: []notify.IncidentService{{Provider: notify.ProviderPagerDuty, Key: "k", URL: srv.URL}},
+	}}
+	oom := IssueIdentity{Namespace: "shop", PodName: "api-1", ContainerName: "app", IssueType: "OOMKilled"}
+	crash := IssueIdentity{Namespace: "shop", PodName: "web-1", ContainerName: "web", IssueType: "CrashLoopBackOff"}
+	pull := IssueIdentity{Namespace: "shop", PodName: "web-1", ContainerName: "web", IssueType: "ImagePullBackOff"}
+	pending := IssueIdentity{Namespace: "shop", PodName: "job-1", IssueType: "Pending"}
+	key := func(issue IssueIdentity) string { return notify.DedupKey("prod", issue.Fingerprint()) }
+
+	// First iteration: fatal issues open incidents, others do not
+	syncIncidents(context.Background(), config, compareIssues(nil, []IssueIdentity{oom, crash, pending}))
+	assert.Equal(t, []string{"trigger " + key(oom), "trigger " + key(crash)}, actions)
+
+	// OOM resolved, crash loop became an image pull failure: both resolve
+	actions = nil
+	syncIncidents(context.Background(), config, compareIssues([]IssueIdentity{oom, crash, pending}, []IssueIdentity{pull, pending}))
+	assert.ElementsMatch(t, []string{"resolve " + key(oom), "resolve " + key(crash)}, actions)
+}
@@ -0,0 +1,213 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ppiankov/kubenow/internal/notify"
+	"github.com/ppiankov/kubenow/internal/prompt"
+)
+
+// validScheduleModes are the prompt modes a schedule may select.
+var validScheduleModes = map[string]bool{
+	"default": true, "pod": true, "incident": true, "teamlead": true,
+	"compliance": true, "chaos": true, "security": true, prompt.ModeAuto: true,
+}
+
+// ScheduleFile is the --watch-config document: watch schedules and the
+// notification sinks shared by all of them.
+type ScheduleFile struct {
+	Schedules []Schedule    `yaml:"schedules"`
+	Sinks     []notify.Sink `yaml:"sinks,omitempty"`
+}
+
+// Schedule watches a group of namespaces on its own interval and prompt
+// mode. Unset fields inherit the command-line flags.
+type Schedule struct {
+	Name         string   `yaml:"name"`
+	Namespaces   []string `yaml:"namespaces,omitempty"` // names or wildcard patterns; empty watches all
+	Interval     string   `yaml:"interval,omitempty"`   // e.g. "1m", "30m"
+	Mode         string   `yaml:"mode,omitempty"`       // default|pod|incident|teamlead|compliance|chaos|security|auto
+	AlertNewOnly *bool    `yaml:"alert_new_only,omitempty"`
+}
+
+// LoadWatchConfig reads and validates a watch config file. A file with
+// sinks only is valid; the watch then runs on --watch-interval.
+func LoadWatchConfig(path string) (*ScheduleFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch config: %w", err)
+	}
+
+	var file ScheduleFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid watch config %s: %w", path, err)
+	}
+	if len(file.Schedules) == 0 && len(file.Sinks) == 0 {
+		return nil, fmt.Errorf("watch config %s defines no schedules or sinks", path)
+	}
+	for i := range file.Sinks {
+		if _, err := file.Sinks[i].Target(); err != nil {
+			return nil, fmt.Errorf("sinks[%d]: %w", i, err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range file.Schedules {
+		s := &file.Schedules[i]
+		if s.Name == "" {
+			return nil, fmt.Errorf("schedules[%d]: name is required", i)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("schedules[%d]: duplicate name %q", i, s.Name)
+		}
+		seen[s.Name] = true
+		if s.Interval != "" {
+			if d, err := time.ParseDuration(s.Interval); err != nil || d <= 0 {
+				return nil, fmt.Errorf("schedule %q: invalid interval %q", s.Name, s.Interval)
+			}
+		}
+		if s.Mode != "" && !validScheduleModes[s.Mode] {
+			return nil, fmt.Errorf("schedule %q: invalid mode %q", s.Name, s.Mode)
+		}
+	}
+	return &file, nil
+}
+
+// Apply derives the watch configuration of a schedule from the base
+// (command-line) configuration.
+func (s *Schedule) Apply(base *Config) (*Config, error) {
+	cfg := *base
+	cfg.Label = s.Name
+	cfg.StateScope = base.StateScope + "/" + s.Name
+
+	if s.Interval != "" {
+		d, err := time.ParseDuration(s.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: invalid interval %q", s.Name, s.Interval)
+		}
+		cfg.Interval = d
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("schedule %q: no interval (set interval or --watch-interval)", s.Name)
+	}
+
+	switch s.Mode {
+	case "":
+	case prompt.ModeAuto:
+		cfg.AutoMode = true
+	default:
+		cfg.Mode = s.Mode
+		cfg.AutoMode = false
+	}
+	cfg.Filters.IncludeSecurity = cfg.Mode == "security"
+	if s.AlertNewOnly != nil {
+		cfg.AlertNewOnly = *s.AlertNewOnly
+	}
+
+	// A single plain namespace is scoped at the API; groups and patterns
+	// list cluster-wide and filter.
+	switch {
+	case len(s.Namespaces) == 1 && !strings.ContainsAny(s.Namespaces[0], "*?"):
+		cfg.Namespace = s.Namespaces[0]
+	case len(s.Namespaces) > 0:
+		cfg.Namespace = ""
+		cfg.Filters.IncludeNamespaces = strings.Join(s.Namespaces, ",")
+	}
+	return &cfg, nil
+}
+
+// RunSchedules runs one watch loop per schedule in a single process. The
+// loops share the state database (one bucket per schedule) and take turns
+// collecting and analyzing so their output stays readable. It returns when
+// every loop has finished or the context is canceled.
+func RunSchedules(ctx context.Context, clientset *kubernetes.Clientset, base *Config, schedules []Schedule) error {
+	configs := make([]*Config, 0, len(schedules))
+	for i := range schedules {
+		cfg, err := schedules[i].Apply(base)
+		if err != nil {
+			return err
+		}
+		configs = append(configs, cfg)
+	}
+
+	store, closeStore := openState(base)
+	defer closeStore()
+
+	// One set of informers serves every schedule: scoped to a namespace when
+	// all schedules watch the same one, cluster-wide otherwise
+	cacheNamespace := configs[0].Namespace
+	for _, cfg := range configs {
+		if cfg.Namespace != cacheNamespace {
+			cacheNamespace = ""
+		}
+	}
+	slog.Info("Starting pod and node informers")
+	cache, stopCache, err := startClusterCache(ctx, clientset, cacheNamespace)
+	if err != nil {
+		return err
+	}
+	defer stopCache()
+
+	var mu sync.Mutex
+	for _, cfg := range configs {
+		cfg.iterationMu = &mu
+		cfg.cache = cache
+		cfg.StatePath = ""
+		if store != nil {
+			scoped, err := store.Scope(cfg.StateScope)
+			if err != nil {
+				logger(cfg).Warn("Continuing without persistent state", "error", err)
+				continue
+			}
+			cfg.state = scoped
+		}
+	}
+
+	errs := make([]error, len(configs))
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		logger(cfg).Info("Schedule started", "interval", cfg.Interval, "scope", describeScope(cfg))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Run(ctx, clientset, cfg); err != nil && !errors.Is(err, context.Canceled) {
+				errs[i] = fmt.Errorf("schedule %q: %w", cfg.Label, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// describeScope summarizes the namespaces and mode of a schedule.
+func describeScope(cfg *Config) string {
+	ns := "all namespaces"
+	switch {
+	case cfg.Filters.IncludeNamespaces != "":
+		ns = "namespaces " + cfg.Filters.IncludeNamespaces
+	case cfg.Namespace != "":
+		ns = "namespace " + cfg.Namespace
+	}
+	mode := cfg.Mode
+	if cfg.AutoMode {
+		mode = prompt.ModeAuto
+	}
+	return ns + ", mode " + mode
+}
@@ -0,0 +1,150 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/snapshot"
+)
+
+func writeScheduleFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "watch.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadWatchConfig(t *testing.T) {
+	path := writeScheduleFile(t, `
+schedules:
+  - name: prod
+    namespaces: [prod, payments]
+    interval: 1m
+    mode: incident
+    alert_new_only: true
+  - name: dev
+    namespaces: ["dev-*"]
+    interval: 30m
+`)
+	file, err := LoadWatchConfig(path)
+	require.NoError(t, err)
+	schedules := file.Schedules
+	require.Len(t, schedules, 2)
+	assert.Equal(t, "prod", schedules[0].Name)
+	assert.Equal(t, []string{"prod", "payments"}, schedules[0].Namespaces)
+	assert.Equal(t, "incident", schedules[0].Mode)
+	require.NotNil(t, schedules[0].AlertNewOnly)
+	assert.True(t, *schedules[0].AlertNewOnly)
+	assert.Nil(t, schedules[1].AlertNewOnly)
+}
+
+func TestLoadWatchConfig_SinksOnly(t *testing.T) {
+	path := writeScheduleFile(t, `
+sinks:
+  - url: https://contoso.webhook.office.com/webhookb2/abc
+    min_severity: critical
+    format: teams-card
+    report_url: https://reports.example.com/kubenow/latest.html
+`)
+	file, err := LoadWatchConfig(path)
+	require.NoError(t, err)
+	assert.Empty(t, file.Schedules)
+	require.Len(t, file.Sinks, 1)
+	assert.Equal(t, "teams-card", file.Sinks[0].Format)
+}
+
+func TestLoadWatchConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"empty", "schedules: []\n", "defines no schedules"},
+		{"missing name", "schedules:\n  - interval: 1m\n", "name is required"},
+		{"duplicate name", "schedules:\n  - name: a\n  - name: a\n", `duplicate name "a"`},
+		{"bad interval", "schedules:\n  - name: a\n    interval: soon\n", `invalid interval "soon"`},
+		{"bad mode", "schedules:\n  - name: a\n    mode: panic\n", `invalid mode "panic"`},
+		{"unknown field", "schedules:\n  - name: a\n    namespace: prod\n", "field namespace not found"},
+		{"bad sink format", "sinks:\n  - url: https://chat.googleapis.com/v1/spaces/x\n    format: card\n", `sinks[0]: invalid format "card"`},
+		{"bad sink url", "sinks:\n  - url: chat.googleapis.com/v1\n", "sinks[0]: invalid webhook URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadWatchConfig(writeScheduleFile(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestScheduleApply(t *testing.T) {
+	base := &Config{
+		Interval:   5 * time.Minute,
+		Mode:       "default",
+		Namespace:  "kube-system",
+		Filters:    snapshot.Filters{ExcludePods: "debug-*"},
+		StateScope: "prod-cluster/*",
+	}
+	yes := true
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		check    func(t *testing.T, cfg *Config)
+	}{
+		{
+			name:     "single namespace scoped at the API",
+			schedule: Schedule{Name: "prod", Namespaces: []string{"prod"}, Interval: "1m", Mode: "incident", AlertNewOnly: &yes},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, time.Minute, cfg.Interval)
+				assert.Equal(t, "incident", cfg.Mode)
+				assert.Equal(t, "prod", cfg.Namespace)
+				assert.Empty(t, cfg.Filters.IncludeNamespaces)
+				assert.True(t, cfg.AlertNewOnly)
+				assert.Equal(t, "prod", cfg.Label)
+				assert.Equal(t, "prod-cluster/*/prod", cfg.StateScope)
+			},
+		},
+		{
+			name:     "namespace group filters cluster-wide",
+			schedule: Schedule{Name: "dev", Namespaces: []string{"dev-*", "staging"}},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 5*time.Minute, cfg.Interval)
+				assert.Equal(t, "default", cfg.Mode)
+				assert.Empty(t, cfg.Namespace)
+				assert.Equal(t, "dev-*,staging", cfg.Filters.IncludeNamespaces)
+				assert.Equal(t, "debug-*", cfg.Filters.ExcludePods)
+			},
+		},
+		{
+			name:     "auto mode and inherited namespace",
+			schedule: Schedule{Name: "all", Mode: "auto"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.True(t, cfg.AutoMode)
+				assert.Equal(t, "kube-system", cfg.Namespace)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.schedule.Apply(base)
+			require.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+
+	// The base configuration is left untouched
+	assert.Equal(t, "kube-system", base.Namespace)
+	assert.Empty(t, base.Label)
+}
+
+func TestScheduleApply_RequiresInterval(t *testing.T) {
+	_, err := (&Schedule{Name: "prod"}).Apply(&Config{Mode: "default"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--watch-interval")
+}
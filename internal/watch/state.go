@@ -0,0 +1,160 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// stateOpenTimeout bounds how long Open waits for another watcher holding
+// the database lock.
+const stateOpenTimeout = time.Second
+
+// IssueRecord is the persisted state of an issue, keyed by its fingerprint.
+type IssueRecord struct {
+	Issue     IssueIdentity `json:"issue"`
+	FirstSeen time.Time     `json:"firstSeen"`
+	LastSeen  time.Time     `json:"lastSeen"`
+}
+
+// StateStore persists the issues seen by watch mode so that restarts keep
+// diffing against the last observed state. Each scope (cluster and
+// namespace) is stored in its own bucket.
+type StateStore struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+// DefaultStatePath returns ~/.kubenow/watch/state.db.
+func DefaultStatePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".kubenow", "watch", "state.db"), nil
+}
+
+// OpenStateStore opens (or creates) the state database at path and selects
+// the bucket for scope.
+func OpenStateStore(path, scope string) (*StateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create watch state directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: stateOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open watch state %s: %w", path, err)
+	}
+	s := &StateStore{db: db, bucket: []byte(scope)}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(s.bucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("cannot initialize watch state: %w", err)
+	}
+	return s, nil
+}
+
+// Scope returns a store for another bucket of the same database. The
+// returned store shares the database: close only the store it came from.
+func (s *StateStore) Scope(scope string) (*StateStore, error) {
+	scoped := &StateStore{db: s.db, bucket: []byte(scope)}
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(scoped.bucket)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("cannot initialize watch state: %w", err)
+	}
+	return scoped, nil
+}
+
+// Close releases the database.
+func (s *StateStore) Close() error {
+	return s.db.Close()
+}
+
+// Load returns the persisted issue records of the scope, keyed by fingerprint.
+func (s *StateStore) Load() (map[string]IssueRecord, error) {
+	records := map[string]IssueRecord{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).ForEach(func(k, v []byte) error {
+			var rec IssueRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return fmt.Errorf("corrupt record %q: %w", k, err)
+			}
+			records[string(k)] = rec
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load watch state: %w", err)
+	}
+	return records, nil
+}
+
+// Save replaces the scope's records with the current issues: resolved
+// issues are removed, new ones recorded, and ongoing ones keep their
+// first-seen time. It returns the records as stored.
+func (s *StateStore) Save(issues []IssueIdentity, now time.Time) (map[string]IssueRecord, error) {
+	records := map[string]IssueRecord{}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		prev := map[string]IssueRecord{}
+		if err := b.ForEach(func(k, v []byte) error {
+			var rec IssueRecord
+			if json.Unmarshal(v, &rec) == nil {
+				prev[string(k)] = rec
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			key := issue.Fingerprint()
+			rec := IssueRecord{Issue: issue, FirstSeen: now, LastSeen: now}
+			if old, ok := prev[key]; ok && old.Issue == issue {
+				rec.FirstSeen = old.FirstSeen
+			}
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(key), data); err != nil {
+				return err
+			}
+			records[key] = rec
+		}
+		for key := range prev {
+			if _, ok := records[key]; !ok {
+				if err := b.Delete([]byte(key)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save watch state: %w", err)
+	}
+	return records, nil
+}
+
+// issuesOf returns the issues of a record set in fingerprint order.
+func issuesOf(records map[string]IssueRecord) []IssueIdentity {
+	keys := make([]string, 0, len(records))
+	for k := range records {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	issues := make([]IssueIdentity, 0, len(keys))
+	for _, k := range keys {
+		issues = append(issues, records[k].Issue)
+	}
+	return issues
+}
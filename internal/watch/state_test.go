@@ -0,0 +1,100 @@
+package watch
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateStore_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch", "state.db")
+	crash := IssueIdentity{Namespace: "prod", PodName: "api-1", ContainerName: "app", IssueType: "CrashLoopBackOff"}
+	pending := IssueIdentity{Namespace: "prod", PodName: "worker-1", IssueType: "Pending"}
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := OpenStateStore(path, "prod-cluster/prod")
+	require.NoError(t, err)
+	_, err = store.Save([]IssueIdentity{crash, pending}, t0)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = OpenStateStore(path, "prod-cluster/prod")
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	records, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []IssueIdentity{crash, pending}, issuesOf(records))
+
+	// Ongoing issues keep their first-seen time; resolved ones are dropped.
+	t1 := t0.Add(time.Minute)
+	records, err = store.Save([]IssueIdentity{crash}, t1)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, t0, records[crash.Fingerprint()].FirstSeen)
+	assert.Equal(t, t1, records[crash.Fingerprint()].LastSeen)
+
+	records, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []IssueIdentity{crash}, issuesOf(records))
+}
+
+func TestStateStore_ChangedIssueResetsFirstSeen(t *testing.T) {
+	store, err := OpenStateStore(filepath.Join(t.TempDir(), "state.db"), "c/ns")
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	crash := IssueIdentity{Namespace: "ns", PodName: "p", ContainerName: "c", IssueType: "CrashLoopBackOff"}
+	oom := crash
+	oom.IssueType = "OOMKilled"
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+
+	_, err = store.Save([]IssueIdentity{crash}, t0)
+	require.NoError(t, err)
+	records, err := store.Save([]IssueIdentity{oom}, t1)
+	require.NoError(t, err)
+	assert.Equal(t, oom, records[oom.Fingerprint()].Issue)
+	assert.Equal(t, t1, records[oom.Fingerprint()].FirstSeen)
+}
+
+func TestStateStore_ScopesAreIsolated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	issue := IssueIdentity{Namespace: "a", PodName: "p", IssueType: "Failed"}
+
+	store, err := OpenStateStore(path, "cluster-a/*")
+	require.NoError(t, err)
+	_, err = store.Save([]IssueIdentity{issue}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = OpenStateStore(path, "cluster-b/*")
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	records, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestStateStore_Scope(t *testing.T) {
+	store, err := OpenStateStore(filepath.Join(t.TempDir(), "state.db"), "c/*")
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	prod, err := store.Scope("c/*/prod")
+	require.NoError(t, err)
+	issue := IssueIdentity{Namespace: "prod", PodName: "api-1", IssueType: "Failed"}
+	_, err = prod.Save([]IssueIdentity{issue}, time.Now())
+	require.NoError(t, err)
+
+	records, err := prod.Load()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	records, err = store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
@@ -5,29 +5,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"sync"
 	"time"
 
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/ppiankov/kubenow/internal/ack"
 	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/llmcache"
+	"github.com/ppiankov/kubenow/internal/logging"
+	"github.com/ppiankov/kubenow/internal/notify"
+	"github.com/ppiankov/kubenow/internal/owners"
 	"github.com/ppiankov/kubenow/internal/prompt"
+	"github.com/ppiankov/kubenow/internal/redact"
 	"github.com/ppiankov/kubenow/internal/result"
 	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/telemetry"
 )
 
-func stderrf(format string, args ...any) {
-	if _, err := fmt.Fprintf(os.Stderr, format, args...); err != nil {
-		return
-	}
-}
-
-func stderrln(args ...any) {
-	if _, err := fmt.Fprintln(os.Stderr, args...); err != nil {
-		return
-	}
-}
-
 func printlnOut(args ...any) {
 	if _, err := fmt.Println(args...); err != nil {
 		return
@@ -45,9 +42,66 @@ type Config struct {
 	MaxConcurrent int
 	Filters       snapshot.Filters
 	Mode          string
+	AutoMode      bool // pick Mode per iteration from deterministic triage
 	ProblemHint   string
 	Enhancements  prompt.PromptEnhancements
 	LLMClient     *llm.Client
+
+	// MaxPromptTokens caps the prompt size; 0 derives it from the model's
+	// context window.
+	MaxPromptTokens int
+
+	// StatePath is the bolt database that remembers seen issues across
+	// restarts; empty keeps state in memory only. StateScope selects the
+	// bucket, e.g. "<cluster>/<namespace>".
+	StatePath  string
+	StateScope string
+
+	// Notifier pushes findings for new and changed issues to webhooks; nil
+	// disables notifications.
+	Notifier *notify.Notifier
+
+	// Incidents opens PagerDuty or Opsgenie incidents for new fatal issues
+	// and resolves them when the issue goes away; nil disables incidents.
+	Incidents *notify.Incidents
+
+	// Acks are known accepted problems: their pods are left out of the
+	// analysis, issue diffs, and notifications; nil acknowledges nothing.
+	Acks *ack.List
+
+	// Owners attributes problem pods to owning teams in the snapshot the
+	// model sees; nil attributes none.
+	Owners *owners.File
+
+	// Redactor masks secrets and PII in each snapshot before the LLM call;
+	// nil redacts nothing.
+	Redactor *redact.Redactor
+
+	// LLMUsage sums the tokens and cost of the LLM calls of all iterations;
+	// each iteration prints its own usage and the running total. nil
+	// disables accounting.
+	LLMUsage *llm.Meter
+
+	// Cache reuses the answer of an earlier iteration or run when the
+	// snapshot and prompt options are unchanged; nil always calls the LLM.
+	Cache *llmcache.Cache
+
+	// Label names the schedule in output when several run in one process.
+	Label string
+
+	// Findings publishes each iteration's triage counts as
+	// kubenow_problem_count; nil disables publishing.
+	Findings *telemetry.Findings
+
+	// Usage feeds the growth detectors; they run only when Growth enables
+	// one and Usage is set.
+	Usage  UsageSource
+	Growth GrowthConfig
+
+	state       *StateStore    // shared store set by RunSchedules
+	cache       *clusterCache  // pod and node informers, shared by RunSchedules
+	growth      *growthTracker // usage history, set by Run
+	iterationMu *sync.Mutex    // serializes iterations across schedules
 }
 
 // IssueIdentity uniquely identifies an issue for diff detection.
@@ -58,76 +112,89 @@ type IssueIdentity struct {
 	ContainerName string
 }
 
+// Fingerprint keys an issue by where it occurs, so a pod or container whose
+// issue type changes is reported as changed rather than new and resolved.
+func (id IssueIdentity) Fingerprint() string {
+	return id.Namespace + "/" + id.PodName + "/" + id.ContainerName
+}
+
+// IssueChange is an issue whose type changed between iterations.
+type IssueChange struct {
+	Previous IssueIdentity
+	Current  IssueIdentity
+}
+
 // IssueDiff represents the difference between two snapshots.
 type IssueDiff struct {
 	NewIssues      []IssueIdentity
 	ResolvedIssues []IssueIdentity
+	ChangedIssues  []IssueChange
 	OngoingIssues  []IssueIdentity
 }
 
-// Run executes the watch loop.
+// Run executes the watch loop. Pods and nodes come from informers rather
+// than a list call per iteration, and an iteration whose namespaces saw no
+// pod or node change since the last complete analysis skips it.
 func Run(ctx context.Context, clientset *kubernetes.Clientset, config *Config) error {
-	var prevSnapshot *snapshot.Snapshot
+	var prevIssues []IssueIdentity
+	havePrev := false
+
+	if config.cache == nil {
+		logger(config).Info("Starting pod and node informers")
+		cache, stopCache, err := startClusterCache(ctx, clientset, config.Namespace)
+		if err != nil {
+			return err
+		}
+		defer stopCache()
+		config.cache = cache
+	}
+
+	store, closeStore := openState(config)
+	defer closeStore()
+	if store != nil {
+		if records, err := store.Load(); err != nil {
+			logger(config).Warn("Loading watch state failed", "error", err)
+		} else if len(records) > 0 {
+			prevIssues, havePrev = issuesOf(records), true
+			logger(config).Info("Loaded known issues from watch state", "issues", len(records))
+		}
+	}
+
+	if config.Growth.Enabled() && config.Usage != nil {
+		config.growth = newGrowthTracker(config.Growth)
+	}
+
 	ticker := time.NewTicker(config.Interval)
 	defer ticker.Stop()
 
 	iteration := 0
+	var analyzedGen uint64 // cache generation of the last complete analysis
+	analyzed := false
 	for {
 		iteration++
-		timestamp := time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
-
-		stderrf("\n[%s] Iteration %d", timestamp, iteration)
-		if config.MaxIterations > 0 {
-			stderrf("/%d", config.MaxIterations)
+		gen := config.cache.currentGeneration()
+		changed := !analyzed || config.cache.changedSince(analyzedGen, config.Namespace, &config.Filters)
+		currIssues, ok, complete := runIteration(ctx, clientset, config, store, iteration, prevIssues, havePrev, changed)
+		if ok {
+			prevIssues, havePrev = currIssues, true
 		}
-		stderrln()
-		stderrln("----------------------------------------")
-
-		// Build current snapshot
-		stderrln("[kubenow] Collecting cluster snapshot...")
-		currSnapshot, err := snapshot.BuildSnapshot(ctx, clientset, config.Namespace, config.MaxPods, config.LogLines, config.MaxConcurrent, &config.Filters)
-		if err != nil {
-			stderrf("snapshot error: %v\n", err)
-			// Continue watching even if snapshot fails
-		} else {
-			// Compare with previous snapshot if it exists
-			if prevSnapshot != nil {
-				diff := compareSnapshots(prevSnapshot, currSnapshot)
-
-				if config.AlertNewOnly && len(diff.NewIssues) == 0 {
-					stderrln("[kubenow] No new issues detected")
-					prevSnapshot = currSnapshot
-				} else {
-					printDiff(diff, config.AlertNewOnly)
-
-					if err := runLLMAnalysis(ctx, config, currSnapshot); err != nil {
-						stderrf("%v\n", err)
-					}
-
-					prevSnapshot = currSnapshot
-				}
-			} else {
-				if err := runLLMAnalysis(ctx, config, currSnapshot); err != nil {
-					stderrf("%v\n", err)
-				}
-
-				prevSnapshot = currSnapshot
-			}
+		if complete {
+			analyzedGen, analyzed = gen, true
 		}
 
 		// Check if we've reached max iterations
 		if config.MaxIterations > 0 && iteration >= config.MaxIterations {
-			stderrln("\n[kubenow] Max iterations reached. Exiting watch mode.")
+			logger(config).Info("Max iterations reached, exiting watch mode")
 			break
 		}
 
 		// Wait for next tick or context cancellation
-		stderrf("\nNext check in %s... (Ctrl+C to stop)\n", config.Interval)
+		logger(config).Info("Waiting for the next check (Ctrl+C to stop)", "interval", config.Interval)
 		select {
 		case <-ticker.C:
 			continue
 		case <-ctx.Done():
-			stderrln("\n[kubenow] Watch mode stopped.")
+			logger(config).Info("Watch mode stopped")
 			return ctx.Err()
 		}
 	}
@@ -135,58 +202,237 @@ func Run(ctx context.Context, clientset *kubernetes.Clientset, config *Config) e
 	return nil
 }
 
-func runLLMAnalysis(ctx context.Context, config *Config, snapshotData interface{}) error {
-	snapJSON, err := json.Marshal(snapshotData)
-	if err != nil {
-		return fmt.Errorf("snapshot marshal error: %w", err)
+// runIteration collects a snapshot, reports and analyzes it, and persists
+// the observed issues. Without cluster changes it only runs the growth
+// detectors. ok is false when the snapshot failed, and complete is false
+// when the iteration should run again even without changes.
+func runIteration(
+	ctx context.Context, clientset *kubernetes.Clientset, config *Config, store *StateStore,
+	iteration int, prevIssues []IssueIdentity, havePrev, changed bool,
+) (currIssues []IssueIdentity, ok, complete bool) {
+	// Schedules sharing a process take turns so their output does not interleave
+	if config.iterationMu != nil {
+		config.iterationMu.Lock()
+		defer config.iterationMu.Unlock()
 	}
 
-	finalPrompt, err := prompt.LoadPrompt(config.Mode, string(snapJSON), config.ProblemHint, config.Enhancements)
-	if err != nil {
-		return fmt.Errorf("prompt error: %w", err)
+	log := logger(config)
+	started := time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
+	if config.MaxIterations > 0 {
+		logging.Progress(log, "Iteration", iteration, config.MaxIterations, "started", started)
+	} else {
+		log.Info("Iteration", "iteration", iteration, "started", started)
+	}
+
+	// Growth detectors run on their own usage samples, with or without an LLM
+	checkGrowth(ctx, config, time.Now())
+
+	if !changed {
+		log.Info("No pod or node changes since the last analysis, skipping it")
+		return prevIssues, havePrev, true
 	}
 
-	stderrf("[kubenow] Calling LLM endpoint...\n")
-	raw, err := config.LLMClient.Complete(ctx, finalPrompt)
+	// Build current snapshot
+	log.Info("Collecting cluster snapshot")
+	currSnapshot, err := config.cache.buildSnapshot(ctx, clientset, config)
 	if err != nil {
-		return fmt.Errorf("llm error: %w", err)
+		// Continue watching even if snapshot fails
+		log.Error("Snapshot failed", "error", err)
+		return nil, false, false
 	}
 
-	if err := renderOutput(raw, config.Mode); err != nil {
-		return fmt.Errorf("render error: %w", err)
+	if n := currSnapshot.SplitAcknowledged(config.Acks, time.Now()); n > 0 {
+		log.Info("Skipped acknowledged problem pods", "pods", n)
 	}
+	currSnapshot.AssignOwners(config.Owners)
 
-	return nil
+	publishFindings(config, currSnapshot)
+
+	currIssues = extractIssues(currSnapshot)
+	complete = processIteration(ctx, config, currSnapshot, prevIssues, currIssues, havePrev)
+	if store != nil {
+		if _, err := store.Save(currIssues, time.Now().UTC()); err != nil {
+			log.Warn("Saving watch state failed", "error", err)
+		}
+	}
+	return currIssues, true, complete
 }
 
-// compareSnapshots compares two snapshots and returns the diff.
-func compareSnapshots(prev, curr *snapshot.Snapshot) IssueDiff {
-	prevIssues := extractIssues(prev)
-	currIssues := extractIssues(curr)
+// publishFindings exports the snapshot's problem counts by triage severity,
+// per schedule when several run in one process.
+func publishFindings(config *Config, snap *snapshot.Snapshot) {
+	if config.Findings == nil {
+		return
+	}
+	source := telemetry.SourceWatch
+	if config.Label != "" {
+		source += "/" + config.Label
+	}
+	t := snapshot.Triage(snap)
+	config.Findings.SetProblemCounts(source, map[string]int{
+		"fatal":    t.Fatal,
+		"critical": t.Critical,
+		"warning":  t.Warning,
+	})
+}
 
-	var diff IssueDiff
+// logger returns the watch logger, tagged with the schedule label for named
+// schedules.
+func logger(config *Config) *slog.Logger {
+	if config.Label == "" {
+		return slog.Default()
+	}
+	return slog.With("schedule", config.Label)
+}
 
-	// Find new issues (in current but not in previous)
-	for _, issue := range currIssues {
-		if !containsIssue(prevIssues, issue) {
-			diff.NewIssues = append(diff.NewIssues, issue)
+// processIteration reports the delta against the previous issues, runs the
+// LLM analysis unless --watch-alert-new-only has nothing to report, and
+// notifies about new and changed issues. It returns false when the LLM
+// analysis failed.
+func processIteration(
+	ctx context.Context, config *Config, snap *snapshot.Snapshot,
+	prevIssues, currIssues []IssueIdentity, havePrev bool,
+) bool {
+	syncIncidents(ctx, config, compareIssues(prevIssues, currIssues))
+
+	fresh := currIssues // issues not seen before: notification candidates
+	if havePrev {
+		diff := compareIssues(prevIssues, currIssues)
+		fresh = diff.NewIssues
+		for _, change := range diff.ChangedIssues {
+			fresh = append(fresh, change.Current)
+		}
+		if config.AlertNewOnly && len(fresh) == 0 {
+			logger(config).Info("No new issues detected")
+			return true
 		}
+		printDiff(logger(config), diff, config.AlertNewOnly)
+	}
+
+	raw, mode, err := runLLMAnalysis(ctx, config, snap)
+	if err != nil {
+		logger(config).Error("LLM analysis failed", "error", err)
+	}
+	notifyIssues(ctx, config, fresh, raw, mode)
+	return err == nil
+}
+
+// openState opens the configured state store, or returns the one shared by
+// RunSchedules. Persistence is best-effort: on failure watch mode warns and
+// keeps state in memory.
+func openState(config *Config) (*StateStore, func()) {
+	if config.state != nil {
+		return config.state, func() {}
+	}
+	if config.StatePath == "" {
+		return nil, func() {}
+	}
+	store, err := OpenStateStore(config.StatePath, config.StateScope)
+	if err != nil {
+		logger(config).Warn("Continuing without persistent state", "error", err)
+		return nil, func() {}
+	}
+	return store, func() { _ = store.Close() }
+}
+
+// runLLMAnalysis analyzes a snapshot and renders the result. It returns the
+// raw response and the prompt mode used.
+func runLLMAnalysis(ctx context.Context, config *Config, snap *snapshot.Snapshot) (raw, mode string, err error) {
+	log := logger(config)
+	mode = config.Mode
+	if config.AutoMode {
+		var reason string
+		mode, reason = prompt.SelectMode(snapshot.Triage(snap), config.Mode)
+		log.Info("Auto-selected mode", "mode", mode, "reason", reason)
 	}
 
-	// Find resolved issues (in previous but not in current)
+	if r := snap.Redact(config.Redactor); r != nil && r.Redactions > 0 {
+		log.Info("Redacted the snapshot before sending it", "redacted", r.String())
+	}
+
+	budget := prompt.PromptBudget(config.LLMClient.Model, config.MaxPromptTokens)
+	finalPrompt, truncation, err := prompt.FitPrompt(snap, budget, mode, config.ProblemHint, config.Enhancements)
+	if err != nil {
+		return "", mode, fmt.Errorf("prompt error: %w", err)
+	}
+	if truncation != nil {
+		log.Info("Snapshot trimmed to fit the prompt budget", "trimmed", truncation.Summary())
+	}
+
+	client := *config.LLMClient
+	var iteration *llm.Meter
+	if config.LLMUsage != nil {
+		iteration = config.LLMUsage.Child()
+		client.OnUsage = iteration.Record
+	}
+	raw, err = completeCached(ctx, config, &client, snap, mode, budget, finalPrompt)
+	if err != nil {
+		return "", mode, fmt.Errorf("llm error: %w", err)
+	}
+	if iteration != nil && iteration.Cost() != nil {
+		log.Info("LLM usage", "cost", iteration.Cost().String(), "since_start", config.LLMUsage.Cost().String())
+	}
+
+	if err := renderOutput(log, raw, mode); err != nil {
+		return raw, mode, fmt.Errorf("render error: %w", err)
+	}
+
+	return raw, mode, nil
+}
+
+// completeCached returns the cached answer for the snapshot when there is
+// one, and otherwise calls the LLM and caches a valid JSON answer.
+func completeCached(
+	ctx context.Context, config *Config, client *llm.Client, snap *snapshot.Snapshot, mode string, budget int, finalPrompt string,
+) (string, error) {
+	log := logger(config)
+	complete := func() (string, error) {
+		log.Info("Calling LLM endpoint")
+		return client.Complete(ctx, finalPrompt)
+	}
+	if config.Cache == nil {
+		return complete()
+	}
+
+	opts := llmcache.NewOptions(client, mode, config.ProblemHint, config.Enhancements, budget)
+	key, err := llmcache.Key(snap, opts)
+	if err != nil {
+		return "", err
+	}
+	raw, hit, err := config.Cache.Complete(key, client.Model, mode, complete)
+	if hit != nil {
+		log.Info("Using cached LLM answer (snapshot unchanged)", "cached", hit.CreatedAt.Local().Format(time.RFC3339))
+	}
+	return raw, err
+}
+
+// compareIssues diffs two issue sets by fingerprint.
+func compareIssues(prevIssues, currIssues []IssueIdentity) IssueDiff {
+	prevByKey := make(map[string]IssueIdentity, len(prevIssues))
 	for _, issue := range prevIssues {
-		if !containsIssue(currIssues, issue) {
-			diff.ResolvedIssues = append(diff.ResolvedIssues, issue)
-		}
+		prevByKey[issue.Fingerprint()] = issue
 	}
+	currKeys := make(map[string]bool, len(currIssues))
 
-	// Find ongoing issues (in both)
+	var diff IssueDiff
 	for _, issue := range currIssues {
-		if containsIssue(prevIssues, issue) {
+		key := issue.Fingerprint()
+		currKeys[key] = true
+		prev, seen := prevByKey[key]
+		switch {
+		case !seen:
+			diff.NewIssues = append(diff.NewIssues, issue)
+		case prev.IssueType != issue.IssueType:
+			diff.ChangedIssues = append(diff.ChangedIssues, IssueChange{Previous: prev, Current: issue})
+		default:
 			diff.OngoingIssues = append(diff.OngoingIssues, issue)
 		}
 	}
-
+	for _, issue := range prevIssues {
+		if !currKeys[issue.Fingerprint()] {
+			diff.ResolvedIssues = append(diff.ResolvedIssues, issue)
+		}
+	}
 	return diff
 }
 
@@ -233,64 +479,41 @@ func extractIssues(snap *snapshot.Snapshot) []IssueIdentity {
 	return issues
 }
 
-// containsIssue checks if an issue list contains a specific issue.
-func containsIssue(issues []IssueIdentity, target IssueIdentity) bool {
-	for _, issue := range issues {
-		if issue.Namespace == target.Namespace &&
-			issue.PodName == target.PodName &&
-			issue.IssueType == target.IssueType &&
-			issue.ContainerName == target.ContainerName {
-			return true
-		}
+// issueLocation formats where an issue occurs.
+func issueLocation(issue IssueIdentity) string {
+	if issue.ContainerName != "" {
+		return fmt.Sprintf("%s/%s (container: %s)", issue.Namespace, issue.PodName, issue.ContainerName)
 	}
-	return false
+	return issue.Namespace + "/" + issue.PodName
 }
 
-// printDiff prints the diff between snapshots.
-func printDiff(diff IssueDiff, newOnly bool) {
-	if len(diff.NewIssues) > 0 {
-		stderrf("\n\033[1;31mNEW ISSUES DETECTED: %d\033[0m\n", len(diff.NewIssues))
-		for _, issue := range diff.NewIssues {
-			if issue.ContainerName != "" {
-				stderrf("  [NEW] %s/%s (container: %s) - %s\n", issue.Namespace, issue.PodName, issue.ContainerName, issue.IssueType)
-			} else {
-				stderrf("  [NEW] %s/%s - %s\n", issue.Namespace, issue.PodName, issue.IssueType)
-			}
-		}
+// printDiff logs the diff between snapshots, one record per issue. New and
+// changed issues are warnings.
+func printDiff(log *slog.Logger, diff IssueDiff, newOnly bool) {
+	for _, issue := range diff.NewIssues {
+		log.Warn("New issue", "location", issueLocation(issue), "issue", issue.IssueType)
 	}
-
-	if len(diff.ResolvedIssues) > 0 {
-		stderrf("\n\033[1;32mRESOLVED ISSUES: %d\033[0m\n", len(diff.ResolvedIssues))
-		for _, issue := range diff.ResolvedIssues {
-			if issue.ContainerName != "" {
-				stderrf("  [RESOLVED] %s/%s (container: %s) - %s\n", issue.Namespace, issue.PodName, issue.ContainerName, issue.IssueType)
-			} else {
-				stderrf("  [RESOLVED] %s/%s - %s\n", issue.Namespace, issue.PodName, issue.IssueType)
-			}
-		}
+	for _, change := range diff.ChangedIssues {
+		log.Warn("Changed issue", "location", issueLocation(change.Current),
+			"previous", change.Previous.IssueType, "issue", change.Current.IssueType)
 	}
-
-	if !newOnly && len(diff.OngoingIssues) > 0 {
-		stderrf("\n\033[1;33mONGOING ISSUES: %d\033[0m\n", len(diff.OngoingIssues))
+	for _, issue := range diff.ResolvedIssues {
+		log.Info("Resolved issue", "location", issueLocation(issue), "issue", issue.IssueType)
+	}
+	if !newOnly {
 		for _, issue := range diff.OngoingIssues {
-			if issue.ContainerName != "" {
-				stderrf("  [ONGOING] %s/%s (container: %s) - %s\n", issue.Namespace, issue.PodName, issue.ContainerName, issue.IssueType)
-			} else {
-				stderrf("  [ONGOING] %s/%s - %s\n", issue.Namespace, issue.PodName, issue.IssueType)
-			}
+			log.Info("Ongoing issue", "location", issueLocation(issue), "issue", issue.IssueType)
 		}
 	}
-
-	stderrln()
 }
 
 // renderOutput renders the LLM output to stdout.
-func renderOutput(raw, mode string) error {
+func renderOutput(log *slog.Logger, raw, mode string) error {
 	// Extract and parse JSON
-	jsonStr, jerr := extractJSON(raw)
+	jsonStr, jerr := llm.ExtractJSON(raw)
 	if jerr != nil {
 		// No JSON: show raw response
-		stderrln("[kubenow] No JSON detected in LLM output, showing raw response")
+		log.Warn("No JSON detected in LLM output, showing raw response")
 		printlnOut(raw)
 		return nil
 	}
@@ -299,7 +522,7 @@ func renderOutput(raw, mode string) error {
 	case "pod":
 		var pr result.PodResult
 		if err := json.Unmarshal([]byte(jsonStr), &pr); err != nil {
-			stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+			log.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 			printlnOut(raw)
 			return nil
 		}
@@ -307,7 +530,7 @@ func renderOutput(raw, mode string) error {
 	case "incident":
 		var ir result.IncidentResult
 		if err := json.Unmarshal([]byte(jsonStr), &ir); err != nil {
-			stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+			log.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 			printlnOut(raw)
 			return nil
 		}
@@ -315,7 +538,7 @@ func renderOutput(raw, mode string) error {
 	case "teamlead":
 		var tr result.TeamleadResult
 		if err := json.Unmarshal([]byte(jsonStr), &tr); err != nil {
-			stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+			log.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 			printlnOut(raw)
 			return nil
 		}
@@ -323,7 +546,7 @@ func renderOutput(raw, mode string) error {
 	case "compliance":
 		var cr result.ComplianceResult
 		if err := json.Unmarshal([]byte(jsonStr), &cr); err != nil {
-			stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+			log.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 			printlnOut(raw)
 			return nil
 		}
@@ -331,50 +554,26 @@ func renderOutput(raw, mode string) error {
 	case "chaos":
 		var ch result.ChaosResult
 		if err := json.Unmarshal([]byte(jsonStr), &ch); err != nil {
-			stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+			log.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 			printlnOut(raw)
 			return nil
 		}
 		return result.RenderChaosHuman(os.Stdout, &ch)
+	case "security":
+		var sr result.SecurityResult
+		if err := json.Unmarshal([]byte(jsonStr), &sr); err != nil {
+			log.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
+			printlnOut(raw)
+			return nil
+		}
+		return result.RenderSecurityHuman(os.Stdout, &sr)
 	default:
 		var dr result.DefaultResult
 		if err := json.Unmarshal([]byte(jsonStr), &dr); err != nil {
-			stderrf("[kubenow] Failed to parse %s JSON, showing raw response\nError: %v\n", mode, err)
+			log.Warn("Failed to parse LLM JSON, showing raw response", "mode", mode, "error", err)
 			printlnOut(raw)
 			return nil
 		}
 		return result.RenderDefaultHuman(os.Stdout, &dr)
 	}
 }
-
-// extractJSON is a helper copied from main.go to avoid circular dependency.
-func extractJSON(s string) (string, error) {
-	if s == "" {
-		return "", fmt.Errorf("empty LLM output")
-	}
-
-	if s[0] == '{' || s[0] == '[' {
-		return s, nil
-	}
-
-	start := 0
-	end := len(s) - 1
-	for i, c := range s {
-		if c == '{' {
-			start = i
-			break
-		}
-	}
-	for i := len(s) - 1; i >= 0; i-- {
-		if s[i] == '}' {
-			end = i
-			break
-		}
-	}
-
-	if start >= end {
-		return "", fmt.Errorf("no JSON object detected in output")
-	}
-
-	return s[start : end+1], nil
-}
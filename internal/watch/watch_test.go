@@ -0,0 +1,111 @@
+package watch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ppiankov/kubenow/internal/llm"
+	"github.com/ppiankov/kubenow/internal/llmcache"
+	"github.com/ppiankov/kubenow/internal/snapshot"
+	"github.com/ppiankov/kubenow/internal/telemetry"
+)
+
+func TestCompareIssues(t *testing.T) {
+	crash := IssueIdentity{Namespace: "prod", PodName: "api-1", ContainerName: "app", IssueType: "CrashLoopBackOff"}
+	oom := IssueIdentity{Namespace: "prod", PodName: "api-1", ContainerName: "app", IssueType: "OOMKilled"}
+	pending := IssueIdentity{Namespace: "prod", PodName: "worker-1", IssueType: "Pending"}
+	pull := IssueIdentity{Namespace: "dev", PodName: "web-1", ContainerName: "web", IssueType: "ImagePullBackOff"}
+
+	diff := compareIssues([]IssueIdentity{crash, pending}, []IssueIdentity{oom, pull, pending})
+
+	assert.Equal(t, []IssueIdentity{pull}, diff.NewIssues)
+	assert.Equal(t, []IssueChange{{Previous: crash, Current: oom}}, diff.ChangedIssues)
+	assert.Equal(t, []IssueIdentity{pending}, diff.OngoingIssues)
+	assert.Empty(t, diff.ResolvedIssues)
+
+	diff = compareIssues([]IssueIdentity{oom, pull, pending}, nil)
+	assert.Equal(t, []IssueIdentity{oom, pull, pending}, diff.ResolvedIssues)
+	assert.Empty(t, diff.NewIssues)
+}
+
+func TestExtractIssues(t *testing.T) {
+	snap := &snapshot.Snapshot{ProblemPods: []snapshot.PodSnapshot{{
+		Namespace: "prod",
+		Name:      "api-1",
+		Phase:     "Running",
+		Containers: []snapshot.ContainerSnapshot{
+			{Name: "app", State: "Waiting", StateReason: "CrashLoopBackOff"},
+			{Name: "proxy", State: "Running"},
+		},
+	}, {
+		Namespace: "prod",
+		Name:      "worker-1",
+		Phase:     "Pending",
+		Reason:    "Unschedulable",
+	}}}
+
+	assert.Equal(t, []IssueIdentity{
+		{Namespace: "prod", PodName: "api-1", ContainerName: "app", IssueType: "CrashLoopBackOff"},
+		{Namespace: "prod", PodName: "worker-1", IssueType: "Unschedulable"},
+	}, extractIssues(snap))
+}
+
+func TestPublishFindings(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	findings := telemetry.NewFindings(reg)
+	snap := &snapshot.Snapshot{ProblemPods: []snapshot.PodSnapshot{
+		{Name: "crash", Phase: "Running", Containers: []snapshot.ContainerSnapshot{{StateReason: "CrashLoopBackOff"}}},
+		{Name: "pending", Phase: "Pending"},
+	}}
+
+	publishFindings(&Config{Findings: findings, Label: "prod"}, snap)
+	assert.Equal(t, 1.0, testutil.ToFloat64(findings.ProblemCount.WithLabelValues("fatal", "watch/prod")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(findings.ProblemCount.WithLabelValues("warning", "watch/prod")))
+
+	publishFindings(&Config{}, snap) // no endpoint: nothing to do
+}
+
+func TestCompleteCached_ReusesAnswerForUnchangedSnapshot(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"{\"summary\":\"ok\"}"}}]}`))
+	}))
+	defer srv.Close()
+
+	config := &Config{
+		LLMClient: &llm.Client{Provider: llm.ProviderOpenAI, Endpoint: srv.URL, Model: "mixtral", Timeout: time.Second},
+		Cache:     llmcache.New(t.TempDir(), time.Minute),
+	}
+	snap := func() *snapshot.Snapshot {
+		return &snapshot.Snapshot{
+			GeneratedAt: time.Now(),
+			ProblemPods: []snapshot.PodSnapshot{{Namespace: "prod", Name: "api-0", Phase: "Pending"}},
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		raw, err := completeCached(context.Background(), config, config.LLMClient, snap(), "default", 8000, "prompt")
+		require.NoError(t, err)
+		assert.Equal(t, `{"summary":"ok"}`, raw)
+	}
+	assert.Equal(t, 1, calls)
+
+	// Another mode is another prompt
+	_, err := completeCached(context.Background(), config, config.LLMClient, snap(), "incident", 8000, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	config.Cache = nil
+	_, err = completeCached(context.Background(), config, config.LLMClient, snap(), "default", 8000, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}